@@ -0,0 +1,382 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package asset
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"azul3d.org/engine/audio"
+	"azul3d.org/engine/audio/wav"
+	"azul3d.org/engine/gfx"
+)
+
+// writeFile writes the named file of the directory, with a modification time
+// in the future by the given amount (such that rewrites are detected even on
+// file systems with coarse timestamps).
+func writeFile(t *testing.T, dir, name, data string, future time.Duration) {
+	p := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(p, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mod := time.Now().Add(future)
+	if err := os.Chtimes(p, mod, mod); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// textLoader loads text files as strings, counting the values unloaded.
+type textLoader struct {
+	sync.Mutex
+	loads    int
+	unloaded []string
+}
+
+func (l *textLoader) Load(fs FileSystem, p string) (interface{}, error) {
+	l.Lock()
+	l.loads++
+	l.Unlock()
+	data, err := readFile(fs, p)
+	if err != nil {
+		return nil, err
+	}
+	s := string(data)
+	if strings.HasPrefix(s, "include ") {
+		// Depend on another file.
+		inc, err := readFile(fs, strings.TrimPrefix(s, "include "))
+		if err != nil {
+			return nil, err
+		}
+		s = string(inc)
+	}
+	return s, nil
+}
+
+func (l *textLoader) Unload(v interface{}) {
+	l.Lock()
+	l.unloaded = append(l.unloaded, v.(string))
+	l.Unlock()
+}
+
+func TestManager(t *testing.T) {
+	dir, err := ioutil.TempDir("", "asset")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeFile(t, dir, "a.txt", "hello", 0)
+
+	m := NewManager(Dir(dir))
+	defer m.Close()
+	l := &textLoader{}
+	m.Register(".TXT", l)
+
+	// Loading the same asset concurrently loads it once.
+	var wg sync.WaitGroup
+	assets := make([]*Asset, 8)
+	for i := range assets {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			a, err := m.Load("/sub/../a.txt")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			assets[i] = a
+		}(i)
+	}
+	wg.Wait()
+	if t.Failed() {
+		return
+	}
+	if l.loads != 1 || m.Len() != 1 {
+		t.Fatalf("%d loads of %d assets, want 1", l.loads, m.Len())
+	}
+	a := assets[0]
+	if a.Path() != "a.txt" || a.Value() != "hello" {
+		t.Fatalf("asset %q = %v", a.Path(), a.Value())
+	}
+	for _, other := range assets {
+		if other != a {
+			t.Fatal("loads returned different assets")
+		}
+	}
+
+	// The asset is unloaded once every reference is released.
+	for _, a := range assets[1:] {
+		a.Release()
+	}
+	if len(l.unloaded) != 0 || m.Len() != 1 {
+		t.Fatalf("unloaded %v with a reference left", l.unloaded)
+	}
+	a.Release()
+	if len(l.unloaded) != 1 || m.Len() != 0 {
+		t.Fatalf("unloaded %v, %d assets left", l.unloaded, m.Len())
+	}
+
+	if _, err := m.Load("missing.txt"); err == nil {
+		t.Fatal("loaded a missing file")
+	}
+	if _, err := m.Load("a.unknown"); err == nil {
+		t.Fatal("loaded a file with no loader")
+	}
+	if m.Len() != 0 {
+		t.Fatalf("%d assets left after failed loads", m.Len())
+	}
+}
+
+func TestHotReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "asset")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeFile(t, dir, "a.txt", "include b/c.txt", 0)
+	writeFile(t, dir, "b/c.txt", "one", 0)
+
+	m := NewManager(Dir(dir))
+	defer m.Close()
+	l := &textLoader{}
+	m.Register(".txt", l)
+	a, err := m.Load("a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Value() != "one" {
+		t.Fatalf("value %v", a.Value())
+	}
+
+	// Nothing changed.
+	m.poll()
+	if reloaded, errs := m.Update(); len(reloaded) != 0 || len(errs) != 0 {
+		t.Fatalf("Update = %v, %v", reloaded, errs)
+	}
+
+	// Changing the included file reloads the asset, but the value is swapped
+	// in by Update only.
+	writeFile(t, dir, "b/c.txt", "two", time.Hour)
+	m.poll()
+	if a.Value() != "one" {
+		t.Fatal("value changed before Update")
+	}
+	reloaded, errs := m.Update()
+	if len(reloaded) != 1 || reloaded[0] != a || len(errs) != 0 || a.Value() != "two" {
+		t.Fatalf("Update = %v, %v; value %v", reloaded, errs, a.Value())
+	}
+	if len(l.unloaded) != 1 || l.unloaded[0] != "one" {
+		t.Fatalf("unloaded %v, want the old value", l.unloaded)
+	}
+
+	// A failed reload is reported once, and the old value stays.
+	writeFile(t, dir, "a.txt", "include missing.txt", 2*time.Hour)
+	m.poll()
+	m.poll()
+	if reloaded, errs := m.Update(); len(reloaded) != 0 || len(errs) != 1 || a.Value() != "two" {
+		t.Fatalf("Update = %v, %v; value %v", reloaded, errs, a.Value())
+	}
+	a.Release()
+}
+
+func TestLoaders(t *testing.T) {
+	dir, err := ioutil.TempDir("", "asset")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Texture.
+	f, err := os.Create(filepath.Join(dir, "tex.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, image.NewRGBA(image.Rect(0, 0, 4, 2))); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	// Shader.
+	writeFile(t, dir, "basic.vert", "void main() {}", 0)
+	writeFile(t, dir, "basic.frag", "void main() {}", 0)
+
+	// Mesh, a quad with texture coordinates.
+	writeFile(t, dir, "quad.obj", `# A quad.
+v 0 0 0
+v 1 0 0
+v 1 1 0
+v 0 1 0
+vt 0 0
+vt 1 1
+f 1/1 2/1 3/2 4/2
+`, 0)
+
+	// Sound.
+	f, err = os.Create(filepath.Join(dir, "beep.wav"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := wav.NewEncoder(f, audio.Config{SampleRate: 8000, Channels: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := enc.Write(audio.Float64{0, 0.5, -0.5, 0}); err != nil {
+		t.Fatal(err)
+	}
+	enc.Close()
+	f.Close()
+
+	m := NewManager(Dir(dir))
+	defer m.Close()
+	load := func(p string) interface{} {
+		a, err := m.Load(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return a.Value()
+	}
+
+	tex := load("tex.png").(*gfx.Texture)
+	if tex.Bounds != image.Rect(0, 0, 4, 2) || tex.Source == nil {
+		t.Fatalf("texture bounds %v", tex.Bounds)
+	}
+	shader := load("basic.glsl").(*gfx.Shader)
+	if shader.Name != "basic" || string(shader.GLSL.Vertex) != "void main() {}" {
+		t.Fatalf("shader %q", shader.Name)
+	}
+	mesh := load("quad.obj").(*gfx.Mesh)
+	if len(mesh.Vertices) != 4 || len(mesh.Indices) != 6 || len(mesh.TexCoords) != 1 || mesh.Normals != nil {
+		t.Fatalf("mesh with %d vertices and %d indices", len(mesh.Vertices), len(mesh.Indices))
+	}
+	// OBJ's Y-up is converted to Z-up.
+	if mesh.Vertices[2] != (gfx.Vec3{X: 1, Z: 1}) {
+		t.Fatalf("vertex %v", mesh.Vertices[2])
+	}
+	sound := load("beep.wav").(*Sound)
+	if sound.Config.SampleRate != 8000 || len(sound.Samples) != 4 {
+		t.Fatalf("sound %v with %d samples", sound.Config, len(sound.Samples))
+	}
+}
+
+func TestDecodeOBJ(t *testing.T) {
+	m, err := DecodeOBJ(strings.NewReader(`
+v 0 0 0
+v 1 0 0
+v 1 1 0
+v 0 1 0
+v 0.5 2 0
+vn 0 0 1
+f 1//1 2//1 3//1 4//1 -1//-1
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Vertices) != 5 || len(m.Indices) != 9 || len(m.Normals) != 5 {
+		t.Fatalf("%d vertices, %d indices and %d normals", len(m.Vertices), len(m.Indices), len(m.Normals))
+	}
+	if m.Normals[0] != (gfx.Vec3{Y: -1}) {
+		t.Fatalf("normal %v", m.Normals[0])
+	}
+
+	for _, bad := range []string{
+		"v 0 0\n",
+		"v 0 0 0\nf 1 1\n",
+		"v 0 0 0\nf 1 2 3\n",
+		"v 0 0 x\n",
+	} {
+		if _, err := DecodeOBJ(strings.NewReader(bad)); err == nil {
+			t.Errorf("decoded %q", bad)
+		}
+	}
+}
+
+// encodeDDS encodes a DDS file of BGRA pixels with the given mipmap count.
+func encodeDDS(width, height, count int) []byte {
+	h := make([]byte, 4+ddsHeaderSize)
+	copy(h, ddsMagic)
+	put := func(off int, v uint32) {
+		binary.LittleEndian.PutUint32(h[4+off:], v)
+	}
+	put(0, ddsHeaderSize)
+	put(4, ddsFlagMipmaps)
+	put(8, uint32(height))
+	put(12, uint32(width))
+	put(24, uint32(count))
+	put(76, ddsPFRGB|ddsPFAlpha)
+	put(84, 32)
+	put(88, 0xFF0000)
+	put(92, 0xFF00)
+	put(96, 0xFF)
+	put(100, 0xFF000000)
+	for i := 0; i < count; i++ {
+		for p := 0; p < width*height; p++ {
+			h = append(h, 1, 2, 3, 4) // B, G, R, A
+		}
+		width, height = width/2, height/2
+	}
+	return h
+}
+
+func TestDecodeDDS(t *testing.T) {
+	tex, err := DecodeDDS(bytes.NewReader(encodeDDS(4, 2, 2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tex.Format != gfx.RGBA || tex.Bounds != image.Rect(0, 0, 4, 2) || len(tex.Mipmaps) != 2 {
+		t.Fatalf("format %v, bounds %v, %d mipmaps", tex.Format, tex.Bounds, len(tex.Mipmaps))
+	}
+	l := tex.Mipmaps[1]
+	if l.Width != 2 || l.Height != 1 || !bytes.Equal(l.Data, []byte{3, 2, 1, 4, 3, 2, 1, 4}) {
+		t.Fatalf("level 1 %dx%d %v", l.Width, l.Height, l.Data)
+	}
+
+	truncated := encodeDDS(4, 2, 2)
+	if _, err := DecodeDDS(bytes.NewReader(truncated[:len(truncated)-1])); err == nil {
+		t.Fatal("decoded truncated file")
+	}
+}
+
+func TestDecodeKTX(t *testing.T) {
+	// A 8x4 DXT5 texture with four mipmap levels, and key/value data.
+	var buf bytes.Buffer
+	buf.WriteString(ktxIdentifier)
+	kv := []byte("key\x00val\x00")
+	for _, v := range []uint32{0x04030201, 0, 1, 0, 0x83F3, 0x1908, 8, 4, 0, 0, 1, 4, uint32(len(kv))} {
+		binary.Write(&buf, binary.LittleEndian, v)
+	}
+	buf.Write(kv)
+	for _, size := range []uint32{32, 16, 16, 16} {
+		binary.Write(&buf, binary.LittleEndian, size)
+		buf.Write(make([]byte, size))
+	}
+	tex, err := DecodeKTX(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tex.Format != gfx.DXT5 || len(tex.Mipmaps) != 4 || tex.Mipmaps[3].Width != 1 {
+		t.Fatalf("format %v, %d mipmaps", tex.Format, len(tex.Mipmaps))
+	}
+	if size := gfx.MipSize(tex.Mipmaps, 1); size != 48 {
+		t.Fatalf("mipmap size %d, want 48", size)
+	}
+
+	// The wrong size for the first level.
+	data := buf.Bytes()
+	binary.LittleEndian.PutUint32(data[len(data)-3*(16+4)-4-32:], 31)
+	if _, err := DecodeKTX(bytes.NewReader(data)); err == nil {
+		t.Fatal("decoded invalid level size")
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package asset
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"io/ioutil"
+
+	"azul3d.org/engine/gfx"
+)
+
+// DDS header flags and pixel format flags used by DecodeDDS.
+const (
+	ddsMagic       = "DDS "
+	ddsHeaderSize  = 124
+	ddsFlagMipmaps = 0x20000
+	ddsPFFourCC    = 0x4
+	ddsPFRGB       = 0x40
+	ddsPFAlpha     = 0x1
+	ddsCapsCubemap = 0x200
+	ddsCapsVolume  = 0x200000
+)
+
+// DecodeDDS decodes a DirectDraw Surface (DDS) file into a texture with
+// pre-built Mipmaps (see gfx.Texture), whose Format is one of DXT1RGBA, DXT3,
+// DXT5 (for the DXT1, DXT3 and DXT5 four-character codes), or RGBA (for
+// uncompressed 32-bit RGBA or BGRA files, which are converted to RGBA).
+//
+// Only 2D textures are supported: cube maps, volume textures and files with
+// the DX10 extended header are rejected.
+func DecodeDDS(r io.Reader) (*gfx.Texture, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 4+ddsHeaderSize || string(data[:4]) != ddsMagic {
+		return nil, errors.New("dds: not a DDS file")
+	}
+	h := data[4 : 4+ddsHeaderSize]
+	u32 := func(off int) uint32 {
+		return binary.LittleEndian.Uint32(h[off:])
+	}
+	if u32(0) != ddsHeaderSize {
+		return nil, errors.New("dds: invalid header size")
+	}
+	var (
+		flags  = u32(4)
+		height = int(u32(8))
+		width  = int(u32(12))
+		count  = 1
+	)
+	if flags&ddsFlagMipmaps != 0 && u32(24) > 1 {
+		count = int(u32(24))
+	}
+	if u32(108)&(ddsCapsCubemap|ddsCapsVolume) != 0 {
+		return nil, errors.New("dds: cube maps and volume textures are not supported")
+	}
+
+	// Determine the format from the pixel format structure.
+	var (
+		format    gfx.TexFormat
+		blockSize int
+		bgra      bool
+		pfFlags   = u32(76)
+	)
+	switch {
+	case pfFlags&ddsPFFourCC != 0:
+		switch fourCC := string(h[80:84]); fourCC {
+		case "DXT1":
+			// DXT1 blocks may use 1-bit alpha, opaque ones look the same.
+			format, blockSize = gfx.DXT1RGBA, 8
+		case "DXT3":
+			format, blockSize = gfx.DXT3, 16
+		case "DXT5":
+			format, blockSize = gfx.DXT5, 16
+		default:
+			return nil, fmt.Errorf("dds: unsupported format %q", fourCC)
+		}
+	case pfFlags&ddsPFRGB != 0 && pfFlags&ddsPFAlpha != 0 && u32(84) == 32:
+		switch rMask := u32(88); {
+		case rMask == 0xFF && u32(92) == 0xFF00 && u32(96) == 0xFF0000:
+		case rMask == 0xFF0000 && u32(92) == 0xFF00 && u32(96) == 0xFF:
+			bgra = true
+		default:
+			return nil, errors.New("dds: unsupported RGBA channel order")
+		}
+		format = gfx.RGBA
+	default:
+		return nil, errors.New("dds: unsupported pixel format")
+	}
+
+	levels, err := mipLevels(data[4+ddsHeaderSize:], width, height, count, blockSize, nil)
+	if err != nil {
+		return nil, fmt.Errorf("dds: %v", err)
+	}
+	if bgra {
+		for _, l := range levels {
+			swapRB(l.Data)
+		}
+	}
+	t := gfx.NewTexture()
+	t.Mipmaps = levels
+	t.Format = format
+	t.Bounds = image.Rect(0, 0, width, height)
+	return t, nil
+}
+
+// mipSize returns the size in bytes of a mipmap level, of 4x4 pixel blocks
+// of the given size, or of four byte pixels if blockSize == 0.
+func mipSize(width, height, blockSize int) int {
+	if blockSize == 0 {
+		return width * height * 4
+	}
+	return ((width + 3) / 4) * ((height + 3) / 4) * blockSize
+}
+
+// mipLevels slices count consecutive mipmap levels of the given base size out
+// of data (see mipSize). If next is non-nil, it is called before each level
+// to consume any data preceding it, returning the remaining data.
+func mipLevels(data []byte, width, height, count, blockSize int, next func(data []byte, size int) ([]byte, error)) ([]gfx.MipLevel, error) {
+	if width <= 0 || height <= 0 {
+		return nil, errors.New("invalid size")
+	}
+	if count > 32 {
+		// More levels than any 32-bit size could have.
+		return nil, fmt.Errorf("invalid mipmap count %d", count)
+	}
+	levels := make([]gfx.MipLevel, count)
+	for i := range levels {
+		size := mipSize(width, height, blockSize)
+		if next != nil {
+			var err error
+			if data, err = next(data, size); err != nil {
+				return nil, err
+			}
+		}
+		if len(data) < size {
+			return nil, fmt.Errorf("mipmap level %d is truncated", i)
+		}
+		levels[i] = gfx.MipLevel{
+			Width:  width,
+			Height: height,
+			Data:   data[:size:size],
+		}
+		data = data[size:]
+		if width > 1 {
+			width /= 2
+		}
+		if height > 1 {
+			height /= 2
+		}
+	}
+	return levels, nil
+}
+
+// swapRB swaps the red and blue components of four byte pixels in-place.
+func swapRB(pix []byte) {
+	for i := 0; i+3 < len(pix); i += 4 {
+		pix[i], pix[i+2] = pix[i+2], pix[i]
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package asset implements loading, sharing and hot reloading of assets.
+//
+// A Manager loads assets (textures, meshes, shaders, sounds, or any other
+// type with a registered Loader) by their logical path from a FileSystem.
+// Each asset is loaded only once, and is reference counted: it is unloaded
+// (e.g. freeing it's graphics memory) once every user has released it.
+//
+//  assets := asset.NewManager(asset.Dir("data"))
+//  grass, err := assets.Load("textures/grass.png")
+//  if err != nil {
+//      // Handle error.
+//  }
+//  defer grass.Release()
+//  obj.Textures = []*gfx.Texture{grass.Value().(*gfx.Texture)}
+//
+// During development, the manager can watch the files of the loaded assets
+// and reload those that change. Reloaded assets are swapped in during a call
+// to Update, typically made once per frame:
+//
+//  assets.Watch(time.Second)
+//  ...
+//  for {
+//      _, errs := assets.Update()
+//      for _, err := range errs {
+//          log.Println(err)
+//      }
+//      ...
+//  }
+//
+// The built-in loaders update their assets in-place (see Swapper), such that
+// e.g. a reloaded texture appears on every object it is attached to.
+package asset // import "azul3d.org/engine/asset"
@@ -0,0 +1,60 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package asset
+
+import (
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// FileSystem is a file system that assets are loaded from. Paths are logical
+// paths, slash-separated and relative to the root of the file system (e.g.
+// "textures/grass.png").
+type FileSystem interface {
+	// Open opens the named file for reading.
+	Open(name string) (io.ReadCloser, error)
+
+	// Stat returns information about the named file, whose modification time
+	// is used to detect changed files.
+	Stat(name string) (os.FileInfo, error)
+}
+
+// Dir is a FileSystem of the files in a directory of the operating system's
+// file system. An empty Dir is treated as ".".
+type Dir string
+
+// path returns the operating system path of the named file.
+func (d Dir) path(name string) string {
+	dir := string(d)
+	if dir == "" {
+		dir = "."
+	}
+	return filepath.Join(dir, filepath.FromSlash(path.Clean("/"+name)))
+}
+
+// Open implements the FileSystem interface.
+func (d Dir) Open(name string) (io.ReadCloser, error) {
+	return os.Open(d.path(name))
+}
+
+// Stat implements the FileSystem interface.
+func (d Dir) Stat(name string) (os.FileInfo, error) {
+	return os.Stat(d.path(name))
+}
+
+// recorder is a file system which records the names of the files opened
+// through it, such that an asset's files can be watched for changes.
+type recorder struct {
+	FileSystem
+	opened []string
+}
+
+// Open implements the FileSystem interface.
+func (r *recorder) Open(name string) (io.ReadCloser, error) {
+	r.opened = append(r.opened, name)
+	return r.FileSystem.Open(name)
+}
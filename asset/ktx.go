@@ -0,0 +1,108 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package asset
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"io/ioutil"
+
+	"azul3d.org/engine/gfx"
+)
+
+// ktxIdentifier is the identifier at the start of every KTX (version 1) file.
+const ktxIdentifier = "\xABKTX 11\xBB\r\n\x1A\n"
+
+// The OpenGL internal formats supported by DecodeKTX, and their formats.
+var ktxFormats = map[uint32]struct {
+	format    gfx.TexFormat
+	blockSize int
+}{
+	0x8058: {gfx.RGBA, 0},     // GL_RGBA8
+	0x8C43: {gfx.SRGBA, 0},    // GL_SRGB8_ALPHA8
+	0x83F0: {gfx.DXT1, 8},     // GL_COMPRESSED_RGB_S3TC_DXT1_EXT
+	0x83F1: {gfx.DXT1RGBA, 8}, // GL_COMPRESSED_RGBA_S3TC_DXT1_EXT
+	0x83F2: {gfx.DXT3, 16},    // GL_COMPRESSED_RGBA_S3TC_DXT3_EXT
+	0x83F3: {gfx.DXT5, 16},    // GL_COMPRESSED_RGBA_S3TC_DXT5_EXT
+}
+
+// DecodeKTX decodes a Khronos texture (KTX version 1) file into a texture
+// with pre-built Mipmaps (see gfx.Texture). The internal format of the file
+// must be one of GL_RGBA8 (of unsigned bytes), GL_SRGB8_ALPHA8, or one of the
+// S3TC (DXT) compressed formats.
+//
+// Only 2D textures are supported: array, cube map and 3D textures are
+// rejected.
+func DecodeKTX(r io.Reader) (*gfx.Texture, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	const headerSize = len(ktxIdentifier) + 13*4
+	if len(data) < headerSize || string(data[:len(ktxIdentifier)]) != ktxIdentifier {
+		return nil, errors.New("ktx: not a KTX file")
+	}
+
+	// Files are written in the endianness of the machine that wrote them.
+	var order binary.ByteOrder = binary.LittleEndian
+	h := data[len(ktxIdentifier):headerSize]
+	switch binary.LittleEndian.Uint32(h) {
+	case 0x04030201:
+	case 0x01020304:
+		order = binary.BigEndian
+	default:
+		return nil, errors.New("ktx: invalid endianness")
+	}
+	field := func(i int) uint32 {
+		return order.Uint32(h[i*4:])
+	}
+	var (
+		glType         = field(1)
+		internalFormat = field(4)
+		width          = int(field(6))
+		height         = int(field(7))
+		count          = int(field(11))
+		keyValueBytes  = int(field(12))
+	)
+	if field(8) != 0 || field(9) != 0 || field(10) != 1 {
+		return nil, errors.New("ktx: only 2D textures are supported")
+	}
+	f, ok := ktxFormats[internalFormat]
+	if !ok || (f.blockSize == 0 && glType != 0x1401) { // GL_UNSIGNED_BYTE
+		return nil, fmt.Errorf("ktx: unsupported internal format %#x", internalFormat)
+	}
+	if count == 0 {
+		// The file asks for mipmaps to be generated, we use just the one.
+		count = 1
+	}
+	data = data[headerSize:]
+	if keyValueBytes > len(data) {
+		return nil, errors.New("ktx: key/value data is truncated")
+	}
+	data = data[keyValueBytes:]
+
+	// Each level is preceded by it's size. The sizes of the supported
+	// formats are multiples of four, so there is never any padding.
+	levels, err := mipLevels(data, width, height, count, f.blockSize, func(data []byte, size int) ([]byte, error) {
+		if len(data) < 4 {
+			return nil, errors.New("missing mipmap level")
+		}
+		if imageSize := int(order.Uint32(data)); imageSize != size {
+			return nil, fmt.Errorf("mipmap level size is %d, expected %d", imageSize, size)
+		}
+		return data[4:], nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ktx: %v", err)
+	}
+	t := gfx.NewTexture()
+	t.Mipmaps = levels
+	t.Format = f.format
+	t.Bounds = image.Rect(0, 0, width, height)
+	return t, nil
+}
@@ -0,0 +1,235 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package asset
+
+import (
+	"image"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"azul3d.org/engine/audio"
+	"azul3d.org/engine/gfx"
+)
+
+// TextureLoader loads *gfx.Texture assets from image files. As usual, you will
+// also need to import the image decoders, e.g. for png:
+//
+//  import _ "image/png"
+//
+// As with gfxutil.OpenTexture, the textures have a MinFilter ==
+// LinearMipmapLinear (trilinear filtering), a MagFilter == Linear, and
+// Format == DXT1.
+//
+// DDS and KTX files (see DecodeDDS and DecodeKTX) are instead loaded with
+// their pre-built mipmaps and format, such that devices implementing
+// gfx.MipStreamer can stream their levels in and out (see the texstream
+// package).
+type TextureLoader struct{}
+
+// Load implements the Loader interface.
+func (TextureLoader) Load(fs FileSystem, p string) (interface{}, error) {
+	f, err := fs.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var decode func(io.Reader) (*gfx.Texture, error)
+	switch strings.ToLower(path.Ext(p)) {
+	case ".dds":
+		decode = DecodeDDS
+	case ".ktx":
+		decode = DecodeKTX
+	}
+	if decode != nil {
+		tex, err := decode(f)
+		if err != nil {
+			return nil, err
+		}
+		tex.MinFilter = gfx.LinearMipmapLinear
+		tex.MagFilter = gfx.Linear
+		tex.Label = p
+		return tex, nil
+	}
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+	tex := gfx.NewTexture()
+	tex.Source = img
+	tex.Bounds = img.Bounds()
+	tex.MinFilter = gfx.LinearMipmapLinear
+	tex.MagFilter = gfx.Linear
+	tex.Format = gfx.DXT1
+	tex.Label = p
+	return tex, nil
+}
+
+// Swap implements the Swapper interface, the old texture is reloaded by the
+// device the next time it is drawn.
+func (TextureLoader) Swap(old, new interface{}) {
+	o, n := old.(*gfx.Texture), new.(*gfx.Texture)
+	if o.NativeTexture != nil {
+		o.NativeTexture.Destroy()
+		o.NativeTexture = nil
+	}
+	o.Source = n.Source
+	o.Mipmaps = n.Mipmaps
+	o.Format = n.Format
+	o.Bounds = n.Bounds
+	o.Loaded = false
+	n.Source = nil
+	n.Mipmaps = nil
+	n.Destroy()
+}
+
+// Unload implements the Unloader interface.
+func (TextureLoader) Unload(v interface{}) {
+	v.(*gfx.Texture).Destroy()
+}
+
+// ShaderLoader loads *gfx.Shader assets from GLSL shader files. The asset
+// path names the shader, not a file: the shader "shaders/basic.glsl" is
+// composed of the two GLSL sources (see gfxutil.OpenShader):
+//
+//  shaders/basic.vert
+//  shaders/basic.frag
+//
+type ShaderLoader struct{}
+
+// Load implements the Loader interface.
+func (ShaderLoader) Load(fs FileSystem, p string) (interface{}, error) {
+	base := strings.TrimSuffix(p, path.Ext(p))
+	vert, err := readFile(fs, base+".vert")
+	if err != nil {
+		return nil, err
+	}
+	frag, err := readFile(fs, base+".frag")
+	if err != nil {
+		return nil, err
+	}
+	shader := gfx.NewShader(path.Base(base))
+	shader.GLSL = &gfx.GLSLSources{
+		Vertex:   vert,
+		Fragment: frag,
+	}
+	return shader, nil
+}
+
+// Swap implements the Swapper interface, the old shader is recompiled by the
+// device the next time it is drawn.
+func (ShaderLoader) Swap(old, new interface{}) {
+	o, n := old.(*gfx.Shader), new.(*gfx.Shader)
+	if o.NativeShader != nil {
+		o.NativeShader.Destroy()
+		o.NativeShader = nil
+	}
+	o.GLSL = n.GLSL
+	o.Error = nil
+	o.Loaded = false
+	n.GLSL = nil
+	n.Destroy()
+}
+
+// Unload implements the Unloader interface.
+func (ShaderLoader) Unload(v interface{}) {
+	v.(*gfx.Shader).Destroy()
+}
+
+// MeshLoader loads *gfx.Mesh assets from Wavefront OBJ files (see DecodeOBJ).
+type MeshLoader struct{}
+
+// Load implements the Loader interface.
+func (MeshLoader) Load(fs FileSystem, p string) (interface{}, error) {
+	f, err := fs.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	m, err := DecodeOBJ(f)
+	if err != nil {
+		return nil, err
+	}
+	m.Label = p
+	return m, nil
+}
+
+// Swap implements the Swapper interface, the old mesh's data is replaced and
+// uploaded by the device again the next time it is drawn.
+func (MeshLoader) Swap(old, new interface{}) {
+	o, n := old.(*gfx.Mesh), new.(*gfx.Mesh)
+	o.AABB = n.AABB
+	o.Indices, o.IndicesChanged = n.Indices, true
+	o.Vertices, o.VerticesChanged = n.Vertices, true
+	o.Colors, o.ColorsChanged = n.Colors, true
+	o.Normals, o.NormalsChanged = n.Normals, true
+	o.Bary, o.BaryChanged = n.Bary, true
+	o.TexCoords = n.TexCoords
+	for i := range o.TexCoords {
+		o.TexCoords[i].Changed = true
+	}
+	n.Indices, n.Vertices, n.Colors, n.Normals, n.Bary, n.TexCoords = nil, nil, nil, nil, nil, nil
+	n.Destroy()
+}
+
+// Unload implements the Unloader interface.
+func (MeshLoader) Unload(v interface{}) {
+	v.(*gfx.Mesh).Destroy()
+}
+
+// Sound is a sound decoded entirely into memory, for short sound effects.
+type Sound struct {
+	// The configuration of the audio samples.
+	Config audio.Config
+
+	// The interleaved audio samples.
+	Samples audio.Float64
+}
+
+// SoundLoader loads *Sound assets from audio files. As with audio.NewDecoder,
+// you will also need to import the audio decoders, e.g. for wav:
+//
+//  import _ "azul3d.org/engine/audio/wav"
+//
+type SoundLoader struct{}
+
+// Load implements the Loader interface.
+func (SoundLoader) Load(fs FileSystem, p string) (interface{}, error) {
+	f, err := fs.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	dec, _, err := audio.NewDecoder(f)
+	if err != nil {
+		return nil, err
+	}
+	buf := audio.NewBuffer(audio.Float64{})
+	if _, err := buf.ReadFrom(dec); err != nil {
+		return nil, err
+	}
+	return &Sound{
+		Config:  dec.Config(),
+		Samples: buf.Samples().(audio.Float64),
+	}, nil
+}
+
+// Swap implements the Swapper interface.
+func (SoundLoader) Swap(old, new interface{}) {
+	*old.(*Sound) = *new.(*Sound)
+}
+
+// readFile reads the named file from the file system.
+func readFile(fs FileSystem, name string) ([]byte, error) {
+	f, err := fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
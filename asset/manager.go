@@ -0,0 +1,348 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package asset
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Loader loads assets of a type (see Manager.Register).
+type Loader interface {
+	// Load loads the asset at the given path from the file system. Every file
+	// the loader opens through the file system (e.g. the vertex and fragment
+	// sources of a shader) is watched for changes.
+	Load(fs FileSystem, path string) (interface{}, error)
+}
+
+// LoaderFunc is an adapter to allow the use of an ordinary function as a
+// Loader.
+type LoaderFunc func(fs FileSystem, path string) (interface{}, error)
+
+// Load implements the Loader interface by calling f(fs, path).
+func (f LoaderFunc) Load(fs FileSystem, path string) (interface{}, error) {
+	return f(fs, path)
+}
+
+// Swapper is implemented by loaders whose assets can be updated in-place when
+// they are reloaded, such that everything using the old value sees the
+// change (e.g. a *gfx.Texture attached to objects).
+type Swapper interface {
+	// Swap updates the old value of an asset in-place to the newly loaded one,
+	// which is discarded afterwards.
+	Swap(old, new interface{})
+}
+
+// Unloader is implemented by loaders whose assets must be freed once they
+// are no longer used (e.g. to free graphics memory).
+type Unloader interface {
+	// Unload frees the value of an asset.
+	Unload(v interface{})
+}
+
+// Asset is a reference counted asset, loaded by a Manager.
+type Asset struct {
+	m      *Manager
+	path   string
+	loader Loader
+
+	// Closed once the first load has completed, successfully or not.
+	ready chan struct{}
+	err   error
+
+	// The following are protected by the manager's lock.
+	refs     int
+	value    interface{}
+	modTimes map[string]time.Time
+	reloaded interface{}
+}
+
+// Path returns the logical path of the asset.
+func (a *Asset) Path() string {
+	return a.path
+}
+
+// Value returns the value of the asset (e.g. a *gfx.Texture). If the asset's
+// loader does not implement Swapper, the value changes when the asset is
+// reloaded (see Manager.Update).
+func (a *Asset) Value() interface{} {
+	a.m.access.Lock()
+	defer a.m.access.Unlock()
+	return a.value
+}
+
+// Release releases a reference to the asset. Once every reference returned by
+// Manager.Load is released, the asset is unloaded.
+func (a *Asset) Release() {
+	m := a.m
+	m.access.Lock()
+	a.refs--
+	if a.refs > 0 {
+		m.access.Unlock()
+		return
+	}
+	if a.refs < 0 {
+		m.access.Unlock()
+		panic("asset: Release called too many times")
+	}
+	delete(m.assets, a.path)
+	value, reloaded := a.value, a.reloaded
+	a.value, a.reloaded = nil, nil
+	m.access.Unlock()
+
+	if u, ok := a.loader.(Unloader); ok {
+		u.Unload(value)
+		if reloaded != nil {
+			u.Unload(reloaded)
+		}
+	}
+}
+
+// load loads the asset using it's loader, and returns it's value along with
+// the modification times of the files it was loaded from.
+func (a *Asset) load() (interface{}, map[string]time.Time, error) {
+	rec := &recorder{FileSystem: a.m.fs}
+	v, err := a.loader.Load(rec, a.path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", a.path, err)
+	}
+	modTimes := make(map[string]time.Time, len(rec.opened))
+	for _, name := range rec.opened {
+		if fi, err := a.m.fs.Stat(name); err == nil {
+			modTimes[name] = fi.ModTime()
+		}
+	}
+	return v, modTimes, nil
+}
+
+// Manager loads assets by their logical path through loaders registered for
+// their file extensions, and deduplicates them: loading the same path twice
+// returns the same asset, with an incremented reference count.
+//
+// When watching for changes (see Watch), assets whose files have changed are
+// reloaded in the background, and swapped in during the next call to Update.
+//
+// A manager is safe for use from multiple goroutines concurrently.
+type Manager struct {
+	fs   FileSystem
+	stop chan struct{}
+
+	access  sync.Mutex
+	loaders map[string]Loader
+	assets  map[string]*Asset
+	errs    []error
+}
+
+// Register registers the loader for assets whose path has the given file
+// extension (e.g. ".png"), replacing any loader previously registered for it.
+// Extensions are case insensitive.
+func (m *Manager) Register(ext string, l Loader) {
+	m.access.Lock()
+	m.loaders[strings.ToLower(ext)] = l
+	m.access.Unlock()
+}
+
+// Load returns the asset at the given logical path, loading it if it is not
+// already loaded, and increments it's reference count. Each successful call
+// to Load must be paired with a call to the asset's Release method.
+//
+// If multiple goroutines load the same asset at once, it is loaded only once
+// and the others wait for it.
+func (m *Manager) Load(p string) (*Asset, error) {
+	p = path.Clean(strings.TrimPrefix(p, "/"))
+
+	m.access.Lock()
+	if a, ok := m.assets[p]; ok {
+		a.refs++
+		m.access.Unlock()
+		<-a.ready
+		if a.err != nil {
+			return nil, a.err
+		}
+		return a, nil
+	}
+	l, ok := m.loaders[strings.ToLower(path.Ext(p))]
+	if !ok {
+		m.access.Unlock()
+		return nil, fmt.Errorf("asset: no loader registered for %q", p)
+	}
+	a := &Asset{
+		m:      m,
+		path:   p,
+		loader: l,
+		ready:  make(chan struct{}),
+		refs:   1,
+	}
+	m.assets[p] = a
+	m.access.Unlock()
+
+	v, modTimes, err := a.load()
+	m.access.Lock()
+	if err != nil {
+		a.err = err
+		delete(m.assets, p)
+	} else {
+		a.value, a.modTimes = v, modTimes
+	}
+	m.access.Unlock()
+	close(a.ready)
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Len returns the number of assets currently loaded.
+func (m *Manager) Len() int {
+	m.access.Lock()
+	defer m.access.Unlock()
+	return len(m.assets)
+}
+
+// Watch begins checking the files of each loaded asset for changes at the
+// given interval (e.g. one second), reloading the assets whose files have
+// changed in the background. It is typically only used during development.
+func (m *Manager) Watch(interval time.Duration) {
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				m.poll()
+			case <-m.stop:
+				return
+			}
+		}
+	}()
+}
+
+// poll reloads each loaded asset whose files have changed.
+func (m *Manager) poll() {
+	m.access.Lock()
+	var changed []*Asset
+	for _, a := range m.assets {
+		if a.modTimes == nil {
+			// Still loading.
+			continue
+		}
+		for name, modTime := range a.modTimes {
+			fi, err := m.fs.Stat(name)
+			if err == nil && !fi.ModTime().Equal(modTime) {
+				changed = append(changed, a)
+				break
+			}
+		}
+	}
+	m.access.Unlock()
+
+	for _, a := range changed {
+		v, modTimes, err := a.load()
+		m.access.Lock()
+		if err != nil {
+			// Report the error once, and try again when the files change next
+			// (e.g. once a syntax error is fixed).
+			for name := range a.modTimes {
+				if fi, err := m.fs.Stat(name); err == nil {
+					a.modTimes[name] = fi.ModTime()
+				}
+			}
+			m.errs = append(m.errs, err)
+			m.access.Unlock()
+			continue
+		}
+		a.modTimes = modTimes
+		old := a.reloaded
+		a.reloaded = v
+		released := a.refs <= 0
+		if released {
+			a.reloaded = nil
+		}
+		m.access.Unlock()
+
+		// Free a reload which was never swapped in, or one whose asset was
+		// released meanwhile.
+		if u, ok := a.loader.(Unloader); ok {
+			if old != nil {
+				u.Unload(old)
+			}
+			if released {
+				u.Unload(v)
+			}
+		}
+	}
+}
+
+// Update swaps each reloaded asset in place of the old one, and returns the
+// assets which were reloaded along with any errors that occurred while
+// reloading assets since the last call.
+//
+// It must be called from the goroutine which uses the assets (e.g. once per
+// frame, before drawing), as assets whose loader implements Swapper are
+// modified in-place.
+func (m *Manager) Update() (reloaded []*Asset, errs []error) {
+	m.access.Lock()
+	type swap struct {
+		a        *Asset
+		old, new interface{}
+	}
+	var swaps []swap
+	for _, a := range m.assets {
+		if a.reloaded == nil {
+			continue
+		}
+		swaps = append(swaps, swap{a, a.value, a.reloaded})
+		if _, ok := a.loader.(Swapper); !ok {
+			a.value = a.reloaded
+		}
+		a.reloaded = nil
+		reloaded = append(reloaded, a)
+	}
+	errs = m.errs
+	m.errs = nil
+	m.access.Unlock()
+
+	for _, s := range swaps {
+		if sw, ok := s.a.loader.(Swapper); ok {
+			sw.Swap(s.old, s.new)
+		} else if u, ok := s.a.loader.(Unloader); ok {
+			u.Unload(s.old)
+		}
+	}
+	return
+}
+
+// Close stops watching for changes to asset files.
+func (m *Manager) Close() {
+	close(m.stop)
+}
+
+// NewManager returns a new asset manager which loads assets from the given
+// file system, with the default loaders registered:
+//
+//  .png .jpg .jpeg .gif .dds .ktx: TextureLoader
+//  .glsl: ShaderLoader
+//  .obj: MeshLoader
+//  .wav .flac: SoundLoader
+//
+func NewManager(fs FileSystem) *Manager {
+	m := &Manager{
+		fs:      fs,
+		stop:    make(chan struct{}),
+		loaders: make(map[string]Loader),
+		assets:  make(map[string]*Asset),
+	}
+	for _, ext := range []string{".png", ".jpg", ".jpeg", ".gif", ".dds", ".ktx"} {
+		m.loaders[ext] = TextureLoader{}
+	}
+	m.loaders[".glsl"] = ShaderLoader{}
+	m.loaders[".obj"] = MeshLoader{}
+	m.loaders[".wav"] = SoundLoader{}
+	m.loaders[".flac"] = SoundLoader{}
+	return m
+}
@@ -0,0 +1,157 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package asset
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"azul3d.org/engine/gfx"
+)
+
+// objIndex is the position, texture coordinate and normal indices of an OBJ
+// face vertex, or -1 for those not present.
+type objIndex struct {
+	v, vt, vn int
+}
+
+// DecodeOBJ decodes the geometry of a Wavefront OBJ file into a single
+// indexed triangle mesh. Polygonal faces are triangulated as fans, and all
+// groups and objects of the file are merged. Materials are ignored.
+//
+// OBJ files are Y-up, so positions and normals are converted to the Z-up
+// coordinate system of the engine. Texture coordinates are flipped
+// vertically, since OBJ places V=0 at the bottom of the image.
+func DecodeOBJ(r io.Reader) (*gfx.Mesh, error) {
+	var (
+		positions, normals []gfx.Vec3
+		texCoords          []gfx.TexCoord
+		indices            []uint32
+		lookup             = make(map[objIndex]uint32)
+		out                []objIndex
+	)
+
+	// resolve converts a (1-based, or negative relative) OBJ index of a list
+	// of the given length to a 0-based one.
+	resolve := func(s string, n int) (int, error) {
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, err
+		}
+		if i < 0 {
+			i += n
+		} else {
+			i--
+		}
+		if i < 0 || i >= n {
+			return 0, fmt.Errorf("index %s out of range", s)
+		}
+		return i, nil
+	}
+
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		errorf := func(format string, args ...interface{}) error {
+			return fmt.Errorf("obj: line %d: %s", line, fmt.Sprintf(format, args...))
+		}
+		switch fields[0] {
+		case "v", "vn", "vt":
+			var f [3]float32
+			n := 3
+			if fields[0] == "vt" {
+				n = 2
+			}
+			if len(fields) < n+1 {
+				return nil, errorf("too few components")
+			}
+			for i := 0; i < n; i++ {
+				x, err := strconv.ParseFloat(fields[i+1], 32)
+				if err != nil {
+					return nil, errorf("%v", err)
+				}
+				f[i] = float32(x)
+			}
+			switch fields[0] {
+			case "v":
+				positions = append(positions, gfx.Vec3{X: f[0], Y: -f[2], Z: f[1]})
+			case "vn":
+				normals = append(normals, gfx.Vec3{X: f[0], Y: -f[2], Z: f[1]})
+			default:
+				texCoords = append(texCoords, gfx.TexCoord{U: f[0], V: 1 - f[1]})
+			}
+
+		case "f":
+			if len(fields) < 4 {
+				return nil, errorf("face with less than three vertices")
+			}
+			face := make([]uint32, 0, len(fields)-1)
+			for _, vert := range fields[1:] {
+				parts := strings.Split(vert, "/")
+				idx := objIndex{-1, -1, -1}
+				var err error
+				if idx.v, err = resolve(parts[0], len(positions)); err != nil {
+					return nil, errorf("%v", err)
+				}
+				if len(parts) > 1 && parts[1] != "" {
+					if idx.vt, err = resolve(parts[1], len(texCoords)); err != nil {
+						return nil, errorf("%v", err)
+					}
+				}
+				if len(parts) > 2 && parts[2] != "" {
+					if idx.vn, err = resolve(parts[2], len(normals)); err != nil {
+						return nil, errorf("%v", err)
+					}
+				}
+				i, ok := lookup[idx]
+				if !ok {
+					i = uint32(len(out))
+					lookup[idx] = i
+					out = append(out, idx)
+				}
+				face = append(face, i)
+			}
+			for i := 1; i+1 < len(face); i++ {
+				indices = append(indices, face[0], face[i], face[i+1])
+			}
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	// Build the vertex data, including texture coordinates and normals only
+	// if every vertex has them.
+	m := gfx.NewMesh()
+	m.Indices = indices
+	m.Vertices = make([]gfx.Vec3, len(out))
+	hasNormals, hasTexCoords := len(out) > 0, len(out) > 0
+	for i, idx := range out {
+		m.Vertices[i] = positions[idx.v]
+		hasNormals = hasNormals && idx.vn >= 0
+		hasTexCoords = hasTexCoords && idx.vt >= 0
+	}
+	if hasNormals {
+		m.Normals = make([]gfx.Vec3, len(out))
+		for i, idx := range out {
+			m.Normals[i] = normals[idx.vn]
+		}
+	}
+	if hasTexCoords {
+		set := gfx.TexCoordSet{Slice: make([]gfx.TexCoord, len(out))}
+		for i, idx := range out {
+			set.Slice[i] = texCoords[idx.vt]
+		}
+		m.TexCoords = []gfx.TexCoordSet{set}
+	}
+	m.CalculateBounds()
+	return m, nil
+}
@@ -24,6 +24,11 @@ func init() {
 	audio.RegisterFormat("flac", "fLaC", newDecoder)
 }
 
+// ErrUnsupported defines an error for decoding FLAC data that is valid (by
+// the FLAC specification) but not supported by the decoder, i.e. samples that
+// are not 8, 16 or 24-bit.
+var ErrUnsupported = errors.New("flac: data format is valid but not supported by decoder")
+
 // decoder is capable of decoding the audio samples of a FLAC stream.
 type decoder struct {
 	// The FLAC audio stream.
@@ -39,9 +44,9 @@ type decoder struct {
 // newDecoder returns a FLAC audio decoder, which may be used to decode the
 // encoded audio samples of the io.Reader or io.ReadSeeker r.
 //
-// It returns either [audio.Decoder, nil] or [nil, audio.ErrInvalidData] upon
-// being called where the returned decoder is used to decode the encoded audio
-// data of r.
+// It returns either [audio.Decoder, nil], [nil, audio.ErrInvalidData] or [nil,
+// ErrUnsupported] upon being called where the returned decoder is used to
+// decode the encoded audio data of r.
 func newDecoder(r interface{}) (audio.Decoder, error) {
 	rr, ok := r.(io.Reader)
 	if !ok {
@@ -52,6 +57,11 @@ func newDecoder(r interface{}) (audio.Decoder, error) {
 	if err != nil {
 		return nil, audio.ErrInvalidData
 	}
+	switch stream.Info.BitsPerSample {
+	case 8, 16, 24:
+	default:
+		return nil, ErrUnsupported
+	}
 
 	return &decoder{
 		stream: stream,
@@ -160,7 +170,8 @@ func intToFloat64(sample int32, bps uint8) float64 {
 		// Signed 32-bit PCM audio sample.
 		return audio.Int32ToFloat64(sample)
 	default:
-		panic(fmt.Sprintf("not yet implemented; conversion from %d-bit PCM to F64", bps))
+		// Unreachable, newDecoder rejects streams of other bit depths.
+		return 0
 	}
 }
 
@@ -399,7 +399,7 @@ func (d *decoder) Read(b audio.Slice) (read int, err error) {
 		case 32:
 			return d.readInt32(b)
 		default:
-			panic("invalid bits per sample")
+			return 0, ErrUnsupported
 		}
 
 	case wave_FORMAT_IEEE_FLOAT:
@@ -409,7 +409,7 @@ func (d *decoder) Read(b audio.Slice) (read int, err error) {
 		case 64:
 			return d.readFloat64(b)
 		default:
-			panic("invalid bits per sample")
+			return 0, ErrUnsupported
 		}
 
 	case wave_FORMAT_MULAW:
@@ -417,9 +417,8 @@ func (d *decoder) Read(b audio.Slice) (read int, err error) {
 	case wave_FORMAT_ALAW:
 		return d.readALaw(b)
 	default:
-		panic("invalid format")
+		return 0, ErrUnsupported
 	}
-	return
 }
 
 func (d *decoder) Config() audio.Config {
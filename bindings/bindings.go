@@ -0,0 +1,265 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package bindings maps named actions to physical inputs.
+//
+// Games generally respond to actions ("jump", "fire") rather than to specific
+// keys, such that players can rebind them. A Map binds each action to any
+// number of keyboard keys, mouse buttons, and gamepad inputs:
+//
+//  b := bindings.NewMap()
+//  b.Bind("jump", bindings.Key(keyboard.Space), bindings.PadButton(gamepad.A))
+//  b.Bind("fire", bindings.MouseButton(mouse.Left))
+//
+// Once per frame the map is updated from the current input state (a window
+// implements the Source interface), after which actions can be queried:
+//
+//  b.Update(w)
+//  if b.Pressed("jump") {
+//      player.Jump()
+//  }
+//
+// Bindings can be saved and loaded as JSON, e.g. as part of a settings file.
+package bindings // import "azul3d.org/engine/bindings"
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"sort"
+	"sync"
+
+	"azul3d.org/engine/gamepad"
+	"azul3d.org/engine/keyboard"
+	"azul3d.org/engine/mouse"
+)
+
+// Source is a source of input state, for instance a window.Window.
+type Source interface {
+	Keyboard() *keyboard.Watcher
+	Mouse() *mouse.Watcher
+	Gamepads() *gamepad.Watcher
+}
+
+// Threshold is the value above which an analog input is considered down.
+const Threshold = 0.5
+
+type actionState struct {
+	value         float64
+	down, wasDown bool
+}
+
+// Map maps named actions to physical inputs. It is safe for use concurrently
+// from multiple goroutines.
+type Map struct {
+	access   sync.RWMutex
+	bindings map[string][]Input
+	states   map[string]*actionState
+	gamepad  int
+}
+
+// Bind binds the given inputs to the action, in addition to any inputs already
+// bound to it.
+func (m *Map) Bind(action string, inputs ...Input) {
+	m.access.Lock()
+	m.bindings[action] = append(m.bindings[action], inputs...)
+	m.access.Unlock()
+}
+
+// Rebind replaces all inputs bound to the action with the given ones.
+func (m *Map) Rebind(action string, inputs ...Input) {
+	m.access.Lock()
+	m.bindings[action] = append([]Input(nil), inputs...)
+	m.access.Unlock()
+}
+
+// Unbind removes the given input from every action it is bound to. This is
+// useful before binding it to another action, so that it only triggers one.
+func (m *Map) Unbind(in Input) {
+	m.access.Lock()
+	for action, inputs := range m.bindings {
+		kept := inputs[:0]
+		for _, bound := range inputs {
+			if bound != in {
+				kept = append(kept, bound)
+			}
+		}
+		m.bindings[action] = kept
+	}
+	m.access.Unlock()
+}
+
+// Bindings returns a copy of the inputs bound to the action.
+func (m *Map) Bindings(action string) []Input {
+	m.access.RLock()
+	inputs := append([]Input(nil), m.bindings[action]...)
+	m.access.RUnlock()
+	return inputs
+}
+
+// Actions returns the names of all actions that have been bound, in sorted
+// order.
+func (m *Map) Actions() []string {
+	m.access.RLock()
+	actions := make([]string, 0, len(m.bindings))
+	for action := range m.bindings {
+		actions = append(actions, action)
+	}
+	m.access.RUnlock()
+	sort.Strings(actions)
+	return actions
+}
+
+// SetGamepad sets the ID of the gamepad whose inputs trigger actions, or -1
+// (the default) for inputs of any connected gamepad.
+func (m *Map) SetGamepad(id int) {
+	m.access.Lock()
+	m.gamepad = id
+	m.access.Unlock()
+}
+
+// value returns the value of a single input, in the range of 0 to 1 (or -1 to
+// +1 for entire gamepad axes).
+func (m *Map) value(in Input, s Source) float64 {
+	switch in.Device {
+	case Keyboard:
+		if s.Keyboard().Down(in.Key) {
+			return 1
+		}
+	case Mouse:
+		if s.Mouse().Down(in.Mouse) {
+			return 1
+		}
+	case GamepadButton, GamepadAxis:
+		pads := s.Gamepads()
+		ids := []int{m.gamepad}
+		if m.gamepad < 0 {
+			ids = pads.Connected()
+		}
+		var best float64
+		for _, id := range ids {
+			var v float64
+			if in.Device == GamepadButton {
+				if pads.Down(id, in.Button) {
+					v = 1
+				}
+			} else {
+				v = pads.Axis(id, in.Axis)
+				switch {
+				case in.Dir > 0:
+					v = math.Max(v, 0)
+				case in.Dir < 0:
+					v = math.Max(-v, 0)
+				}
+			}
+			if math.Abs(v) > math.Abs(best) {
+				best = v
+			}
+		}
+		return best
+	}
+	return 0
+}
+
+// Update updates the state of every action from the current state of the given
+// input source. It should be called once per frame, before querying actions.
+func (m *Map) Update(s Source) {
+	m.access.Lock()
+	defer m.access.Unlock()
+	for action, inputs := range m.bindings {
+		st, ok := m.states[action]
+		if !ok {
+			st = &actionState{}
+			m.states[action] = st
+		}
+
+		// The input with the largest magnitude wins.
+		var value float64
+		for _, in := range inputs {
+			if v := m.value(in, s); math.Abs(v) > math.Abs(value) {
+				value = v
+			}
+		}
+		st.wasDown = st.down
+		st.value = value
+		st.down = math.Abs(value) > Threshold
+	}
+}
+
+func (m *Map) state(action string) actionState {
+	m.access.RLock()
+	defer m.access.RUnlock()
+	if st, ok := m.states[action]; ok {
+		return *st
+	}
+	return actionState{}
+}
+
+// Down tells if the action is currently active, i.e. any of its inputs are
+// held down.
+func (m *Map) Down(action string) bool {
+	return m.state(action).down
+}
+
+// Pressed tells if the action became active during the last Update.
+func (m *Map) Pressed(action string) bool {
+	st := m.state(action)
+	return st.down && !st.wasDown
+}
+
+// Released tells if the action became inactive during the last Update.
+func (m *Map) Released(action string) bool {
+	st := m.state(action)
+	return !st.down && st.wasDown
+}
+
+// Value returns the analog value of the action: 0 or 1 for digital inputs, and
+// the value of the axis for gamepad axis inputs (in the range of -1 to +1 for
+// entire axes).
+func (m *Map) Value(action string) float64 {
+	return m.state(action).value
+}
+
+// Save writes the bindings as JSON to the given writer, in the form of:
+//
+//  {
+//      "fire": ["mouse:One"],
+//      "jump": ["key:Space", "pad:A"]
+//  }
+//
+func (m *Map) Save(w io.Writer) error {
+	m.access.RLock()
+	data, err := json.MarshalIndent(m.bindings, "", "\t")
+	m.access.RUnlock()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Load reads bindings as JSON (see Save) from the given reader. The loaded
+// bindings replace the existing bindings of each action present, other
+// actions keep their bindings (e.g. defaults for actions added later).
+func (m *Map) Load(r io.Reader) error {
+	var loaded map[string][]Input
+	if err := json.NewDecoder(r).Decode(&loaded); err != nil {
+		return err
+	}
+	m.access.Lock()
+	for action, inputs := range loaded {
+		m.bindings[action] = inputs
+	}
+	m.access.Unlock()
+	return nil
+}
+
+// NewMap returns a new, empty, map of bindings.
+func NewMap() *Map {
+	return &Map{
+		bindings: make(map[string][]Input),
+		states:   make(map[string]*actionState),
+		gamepad:  -1,
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bindings
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"azul3d.org/engine/gamepad"
+	"azul3d.org/engine/keyboard"
+	"azul3d.org/engine/mouse"
+)
+
+type source struct {
+	kb   *keyboard.Watcher
+	m    *mouse.Watcher
+	pads *gamepad.Watcher
+}
+
+func (s source) Keyboard() *keyboard.Watcher { return s.kb }
+func (s source) Mouse() *mouse.Watcher       { return s.m }
+func (s source) Gamepads() *gamepad.Watcher  { return s.pads }
+
+func newSource() source {
+	return source{keyboard.NewWatcher(), mouse.NewWatcher(), gamepad.NewWatcher()}
+}
+
+func TestMap(t *testing.T) {
+	s := newSource()
+	b := NewMap()
+	b.Bind("jump", Key(keyboard.Space), PadButton(gamepad.A))
+	b.Bind("fire", MouseButton(mouse.Left))
+	b.Bind("right", PadAxis(gamepad.LeftX, 1))
+
+	b.Update(s)
+	if b.Down("jump") || b.Pressed("jump") {
+		t.Fatal("expected jump to be inactive")
+	}
+
+	s.kb.SetState(keyboard.Space, keyboard.Down)
+	b.Update(s)
+	if !b.Down("jump") || !b.Pressed("jump") {
+		t.Fatal("expected jump to be pressed")
+	}
+	b.Update(s)
+	if !b.Down("jump") || b.Pressed("jump") {
+		t.Fatal("expected jump to be held, not pressed")
+	}
+	s.kb.SetState(keyboard.Space, keyboard.Up)
+	b.Update(s)
+	if b.Down("jump") || !b.Released("jump") {
+		t.Fatal("expected jump to be released")
+	}
+
+	// Gamepad inputs.
+	now := time.Now()
+	s.pads.Connect(0, "Pad", now)
+	var snap gamepad.Snapshot
+	snap.Buttons[gamepad.A] = true
+	snap.Axes[gamepad.LeftX] = 1
+	s.pads.Update(0, snap, now)
+	b.Update(s)
+	if !b.Down("jump") || b.Value("right") != 1 {
+		t.Fatalf("expected gamepad inputs to activate actions, right=%v", b.Value("right"))
+	}
+
+	// Rebinding.
+	b.Unbind(PadButton(gamepad.A))
+	b.Update(s)
+	if b.Down("jump") {
+		t.Fatal("expected jump to be inactive after unbinding")
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	b := NewMap()
+	b.Bind("jump", Key(keyboard.Space), PadButton(gamepad.A))
+	b.Bind("look", PadAxis(gamepad.RightX, 0), PadAxis(gamepad.LeftTrigger, -1))
+
+	var buf bytes.Buffer
+	if err := b.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewMap()
+	loaded.Bind("other", Key(keyboard.E))
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, action := range []string{"jump", "look"} {
+		if !reflect.DeepEqual(loaded.Bindings(action), b.Bindings(action)) {
+			t.Errorf("%s: got %v, want %v", action, loaded.Bindings(action), b.Bindings(action))
+		}
+	}
+	if got := loaded.Actions(); !reflect.DeepEqual(got, []string{"jump", "look", "other"}) {
+		t.Fatalf("Actions() = %v", got)
+	}
+
+	if _, err := ParseInput("key:NoSuchKey"); err == nil {
+		t.Fatal("expected error for invalid key")
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bindings
+
+import (
+	"fmt"
+	"strings"
+
+	"azul3d.org/engine/gamepad"
+	"azul3d.org/engine/keyboard"
+	"azul3d.org/engine/mouse"
+)
+
+// Device is the kind of input device an Input belongs to.
+type Device uint8
+
+// Input device constants.
+const (
+	Keyboard Device = iota
+	Mouse
+	GamepadButton
+	GamepadAxis
+)
+
+// Input is a single physical input which can be bound to an action.
+type Input struct {
+	Device Device
+
+	// The key, for keyboard inputs.
+	Key keyboard.Key
+
+	// The button, for mouse inputs.
+	Mouse mouse.Button
+
+	// The button, for gamepad button inputs.
+	Button gamepad.Button
+
+	// The axis, for gamepad axis inputs.
+	Axis gamepad.Axis
+
+	// For gamepad axis inputs, the direction of the axis which activates the
+	// action: +1 for the positive half, -1 for the negative half, or 0 for
+	// the entire axis (whose value is then used as-is).
+	Dir int
+}
+
+// Key returns a keyboard input.
+func Key(k keyboard.Key) Input {
+	return Input{Device: Keyboard, Key: k}
+}
+
+// MouseButton returns a mouse button input.
+func MouseButton(b mouse.Button) Input {
+	return Input{Device: Mouse, Mouse: b}
+}
+
+// PadButton returns a gamepad button input.
+func PadButton(b gamepad.Button) Input {
+	return Input{Device: GamepadButton, Button: b}
+}
+
+// PadAxis returns a gamepad axis input, see Input.Dir for details about dir.
+func PadAxis(a gamepad.Axis, dir int) Input {
+	return Input{Device: GamepadAxis, Axis: a, Dir: dir}
+}
+
+// String returns the string form of the input, which is used when saving
+// bindings. For example:
+//
+//  "key:Space"
+//  "mouse:One"
+//  "pad:A"
+//  "axis:+LeftX"
+//  "axis:RightTrigger"
+//
+func (in Input) String() string {
+	switch in.Device {
+	case Keyboard:
+		return "key:" + in.Key.String()
+	case Mouse:
+		return "mouse:" + in.Mouse.String()
+	case GamepadButton:
+		return "pad:" + in.Button.String()
+	case GamepadAxis:
+		sign := ""
+		if in.Dir > 0 {
+			sign = "+"
+		} else if in.Dir < 0 {
+			sign = "-"
+		}
+		return "axis:" + sign + in.Axis.String()
+	}
+	return fmt.Sprintf("Input(Device=%d)", in.Device)
+}
+
+var (
+	keyNames    = make(map[string]keyboard.Key)
+	mouseNames  = make(map[string]mouse.Button)
+	buttonNames = make(map[string]gamepad.Button)
+	axisNames   = make(map[string]gamepad.Axis)
+)
+
+func init() {
+	for k := keyboard.Invalid + 1; k <= keyboard.EraseEOF; k++ {
+		keyNames[k.String()] = k
+	}
+	for b := mouse.One; b <= mouse.Eight; b++ {
+		mouseNames[b.String()] = b
+	}
+	for b := gamepad.A; int(b) < gamepad.NumButtons; b++ {
+		buttonNames[b.String()] = b
+	}
+	for a := gamepad.LeftX; int(a) < gamepad.NumAxes; a++ {
+		axisNames[a.String()] = a
+	}
+}
+
+// ParseInput parses the string form of an input, as returned by
+// Input.String.
+func ParseInput(s string) (Input, error) {
+	colon := strings.IndexByte(s, ':')
+	if colon < 0 {
+		return Input{}, fmt.Errorf("bindings: invalid input %q", s)
+	}
+	kind, name := s[:colon], s[colon+1:]
+	switch kind {
+	case "key":
+		if k, ok := keyNames[name]; ok {
+			return Key(k), nil
+		}
+	case "mouse":
+		if b, ok := mouseNames[name]; ok {
+			return MouseButton(b), nil
+		}
+	case "pad":
+		if b, ok := buttonNames[name]; ok {
+			return PadButton(b), nil
+		}
+	case "axis":
+		dir := 0
+		if strings.HasPrefix(name, "+") {
+			dir, name = 1, name[1:]
+		} else if strings.HasPrefix(name, "-") {
+			dir, name = -1, name[1:]
+		}
+		if a, ok := axisNames[name]; ok {
+			return PadAxis(a, dir), nil
+		}
+	}
+	return Input{}, fmt.Errorf("bindings: invalid input %q", s)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (in Input) MarshalText() ([]byte, error) {
+	return []byte(in.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (in *Input) UnmarshalText(text []byte) error {
+	parsed, err := ParseInput(string(text))
+	if err != nil {
+		return err
+	}
+	*in = parsed
+	return nil
+}
@@ -0,0 +1,381 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"strings"
+
+	"azul3d.org/engine/console"
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/gizmo"
+	"azul3d.org/engine/lmath"
+	"azul3d.org/engine/scene"
+)
+
+// errNoSelection is returned by commands which need a selected node.
+var errNoSelection = errors.New("no node is selected")
+
+// quote quotes s as a single argument of a console command.
+func quote(s string) string {
+	return `"` + strings.Replace(strings.Replace(s, `\`, `\\`, -1), `"`, `\"`, -1) + `"`
+}
+
+// nodePath returns the slash-separated path of the given node in the scene
+// (see scene.Scene.Find), or an empty string if it is not part of it.
+func nodePath(s *scene.Scene, target *scene.Node) string {
+	var find func(nodes []*scene.Node, prefix string) string
+	find = func(nodes []*scene.Node, prefix string) string {
+		for _, n := range nodes {
+			if n == target {
+				return prefix + n.Name
+			}
+			if p := find(n.Children, prefix+n.Name+"/"); p != "" {
+				return p
+			}
+		}
+		return ""
+	}
+	return find(s.Nodes, "")
+}
+
+// removeNode removes the given node from the nodes, or from their children,
+// returning whether it was found.
+func removeNode(nodes *[]*scene.Node, target *scene.Node) bool {
+	for i, n := range *nodes {
+		if n == target {
+			*nodes = append((*nodes)[:i], (*nodes)[i+1:]...)
+			return true
+		}
+		if removeNode(&n.Children, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// siblings returns the slice of nodes holding the given node, i.e. the root
+// nodes of the scene or the children of it's parent.
+func siblings(s *scene.Scene, target *scene.Node) *[]*scene.Node {
+	var find func(nodes *[]*scene.Node) *[]*scene.Node
+	find = func(nodes *[]*scene.Node) *[]*scene.Node {
+		for _, n := range *nodes {
+			if n == target {
+				return nodes
+			}
+			if found := find(&n.Children); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	return find(&s.Nodes)
+}
+
+// copyNode returns a deep copy of the given node and it's children.
+func copyNode(n *scene.Node) *scene.Node {
+	c := *n
+	if n.Material != nil {
+		m := *n.Material
+		m.Textures = append([]string(nil), m.Textures...)
+		c.Material = &m
+	}
+	if n.Light != nil {
+		l := *n.Light
+		c.Light = &l
+	}
+	if n.Camera != nil {
+		cam := *n.Camera
+		c.Camera = &cam
+	}
+	if n.Probe != nil {
+		p := *n.Probe
+		c.Probe = &p
+	}
+	if n.Properties != nil {
+		c.Properties = make(map[string]string, len(n.Properties))
+		for k, v := range n.Properties {
+			c.Properties[k] = v
+		}
+	}
+	c.Children = make([]*scene.Node, len(n.Children))
+	for i, child := range n.Children {
+		c.Children[i] = copyNode(child)
+	}
+	return &c
+}
+
+// completeNodes completes the paths of the nodes of the scene.
+func (e *editor) completeNodes(c *console.Console, args []string) []string {
+	prefix := args[len(args)-1]
+	var paths []string
+	e.scene.Walk(func(n *scene.Node) bool {
+		if p := nodePath(e.scene, n); strings.HasPrefix(p, prefix) {
+			paths = append(paths, p)
+		}
+		return true
+	})
+	return paths
+}
+
+// addNode adds the given node to the scene at the target of the camera, as a
+// child of the selected node (if any), and selects it.
+func (e *editor) addNode(n *scene.Node) error {
+	parent := e.selected
+	n.Transform.Pos = e.orbit.Target
+	if parent != nil {
+		// Relative to the selected node.
+		o := e.inst.Object(parent)
+		n.Transform.Pos = o.ConvertPos(n.Transform.Pos, gfx.WorldToLocal)
+		parent.Children = append(parent.Children, n)
+	} else {
+		e.scene.Nodes = append(e.scene.Nodes, n)
+	}
+	e.selected = n
+	if err := e.rebuild(); err != nil {
+		// Don't leave a node whose assets fail to load in the scene, the
+		// previous instance is still in use.
+		removeNode(&e.scene.Nodes, n)
+		e.selected = parent
+		return err
+	}
+	return nil
+}
+
+// registerCommands registers the commands of the editor with it's console.
+func (e *editor) registerCommands() {
+	commands := []*console.Command{
+		{
+			Name: "new",
+			Help: "new - replaces the scene with an empty one, discarding unsaved changes",
+			Run: func(c *console.Console, args []string) error {
+				e.clear()
+				return nil
+			},
+		},
+		{
+			Name: "open",
+			Help: "open <path> - opens a scene, discarding unsaved changes",
+			Run: func(c *console.Console, args []string) error {
+				if len(args) != 1 {
+					return console.ErrUsage
+				}
+				if err := e.open(args[0]); err != nil {
+					return err
+				}
+				c.Printf("opened %s", args[0])
+				return nil
+			},
+		},
+		{
+			Name: "save",
+			Help: "save [path] - saves the scene, to the given path if any",
+			Run: func(c *console.Console, args []string) error {
+				path := e.path
+				switch len(args) {
+				case 0:
+				case 1:
+					path = args[0]
+				default:
+					return console.ErrUsage
+				}
+				if err := e.save(path); err != nil {
+					return err
+				}
+				c.Printf("saved %s", path)
+				return nil
+			},
+		},
+		{
+			Name: "nodes",
+			Help: "nodes - lists the nodes of the scene",
+			Run: func(c *console.Console, args []string) error {
+				e.scene.Walk(func(n *scene.Node) bool {
+					mark := " "
+					if n == e.selected {
+						mark = "*"
+					}
+					c.Printf("%s %s", mark, nodePath(e.scene, n))
+					return true
+				})
+				return nil
+			},
+		},
+		{
+			Name: "select",
+			Help: "select [path] - selects the node at the given path, or deselects",
+			Run: func(c *console.Console, args []string) error {
+				switch len(args) {
+				case 0:
+					e.selectNode(nil)
+					return nil
+				case 1:
+					n := e.scene.Find(args[0])
+					if n == nil {
+						return errors.New("no node " + args[0])
+					}
+					e.selectNode(n)
+					return nil
+				}
+				return console.ErrUsage
+			},
+			Complete: e.completeNodes,
+		},
+		{
+			Name: "add",
+			Help: "add <name> [mesh] - adds a node, with the mesh at the given asset path if any",
+			Run: func(c *console.Console, args []string) error {
+				if len(args) < 1 || len(args) > 2 {
+					return console.ErrUsage
+				}
+				n := scene.NewNode(args[0])
+				if len(args) == 2 {
+					n.Mesh = args[1]
+				}
+				return e.addNode(n)
+			},
+		},
+		{
+			Name: "prefab",
+			Help: "prefab <name> <scene> - adds a node instancing the scene at the given asset path",
+			Run: func(c *console.Console, args []string) error {
+				if len(args) != 2 {
+					return console.ErrUsage
+				}
+				n := scene.NewNode(args[0])
+				n.Prefab = args[1]
+				return e.addNode(n)
+			},
+		},
+		{
+			Name: "duplicate",
+			Help: "duplicate - duplicates the selected node next to it",
+			Run: func(c *console.Console, args []string) error {
+				if e.selected == nil {
+					return errNoSelection
+				}
+				e.inst.Store()
+				dup := copyNode(e.selected)
+				nodes := siblings(e.scene, e.selected)
+				*nodes = append(*nodes, dup)
+				e.selected = dup
+				return e.rebuild()
+			},
+		},
+		{
+			Name: "delete",
+			Help: "delete - deletes the selected node and it's children",
+			Run: func(c *console.Console, args []string) error {
+				if e.selected == nil {
+					return errNoSelection
+				}
+				removeNode(&e.scene.Nodes, e.selected)
+				e.selected = nil
+				return e.rebuild()
+			},
+		},
+		{
+			Name: "rename",
+			Help: "rename <name> - renames the selected node",
+			Run: func(c *console.Console, args []string) error {
+				if len(args) != 1 {
+					return console.ErrUsage
+				}
+				if e.selected == nil {
+					return errNoSelection
+				}
+				e.selected.Name = args[0]
+				e.dirty = true
+				return nil
+			},
+		},
+		{
+			Name: "prop",
+			Help: "prop <key> [value] - prints or sets a property of the selected node",
+			Run: func(c *console.Console, args []string) error {
+				if len(args) < 1 || len(args) > 2 {
+					return console.ErrUsage
+				}
+				n := e.selected
+				if n == nil {
+					return errNoSelection
+				}
+				if len(args) == 1 {
+					c.Printf("%s = %q", args[0], n.Properties[args[0]])
+					return nil
+				}
+				if n.Properties == nil {
+					n.Properties = make(map[string]string)
+				}
+				n.Properties[args[0]] = args[1]
+				e.dirty = true
+				return nil
+			},
+		},
+		{
+			Name: "mode",
+			Help: "mode translate|rotate|scale - sets the mode of the gizmo",
+			Run: func(c *console.Console, args []string) error {
+				if len(args) != 1 {
+					return console.ErrUsage
+				}
+				for _, m := range []gizmo.Mode{gizmo.Translate, gizmo.Rotate, gizmo.Scale} {
+					if strings.EqualFold(args[0], m.String()) {
+						if e.gizmo.Active == gizmo.None {
+							e.gizmo.Mode = m
+						}
+						return nil
+					}
+				}
+				return console.ErrUsage
+			},
+			Complete: func(c *console.Console, args []string) []string {
+				var modes []string
+				for _, m := range []string{"translate", "rotate", "scale"} {
+					if strings.HasPrefix(m, args[len(args)-1]) {
+						modes = append(modes, m)
+					}
+				}
+				return modes
+			},
+		},
+		{
+			Name: "focus",
+			Help: "focus - moves the camera to look at the selected node",
+			Run: func(c *console.Console, args []string) error {
+				if e.selected == nil {
+					return errNoSelection
+				}
+				e.focus()
+				return nil
+			},
+		},
+		{
+			Name: "identity",
+			Help: "identity - resets the transform of the selected node to the identity",
+			Run: func(c *console.Console, args []string) error {
+				if e.selected == nil {
+					return errNoSelection
+				}
+				e.inst.Object(e.selected).SetTRS(lmath.TransformIdentity)
+				e.dirty = true
+				return nil
+			},
+		},
+		{
+			Name: "quit",
+			Help: "quit - closes the editor, discarding unsaved changes",
+			Run: func(c *console.Console, args []string) error {
+				if e.quit != nil {
+					e.quit()
+				}
+				return nil
+			},
+		},
+	}
+	for _, cmd := range commands {
+		e.con.Register(cmd)
+	}
+}
@@ -0,0 +1,521 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"azul3d.org/engine/asset"
+	"azul3d.org/engine/console"
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/camera"
+	"azul3d.org/engine/gfx/camera/control"
+	"azul3d.org/engine/gfx/debugdraw"
+	"azul3d.org/engine/gfx/gizmo"
+	"azul3d.org/engine/gfx/glsl"
+	"azul3d.org/engine/gfx/profile"
+	"azul3d.org/engine/gfx/shaders"
+	"azul3d.org/engine/gfx/window"
+	"azul3d.org/engine/keyboard"
+	"azul3d.org/engine/lmath"
+	"azul3d.org/engine/mouse"
+	"azul3d.org/engine/scene"
+	"azul3d.org/engine/text"
+)
+
+var (
+	// The color the canvas is cleared to.
+	background = gfx.Color{R: 0.18, G: 0.19, B: 0.21, A: 1}
+
+	// The colors of the grid lines, and of the lines of every tenth unit.
+	gridColor      = gfx.Color{R: 0.3, G: 0.3, B: 0.32, A: 1}
+	gridMajorColor = gfx.Color{R: 0.42, G: 0.42, B: 0.45, A: 1}
+
+	// The colors of the bounds of the selected node, and of the lights,
+	// cameras and reflection probes of the scene.
+	selectedColor = gfx.Color{R: 1, G: 0.85, B: 0.1, A: 1}
+	lightColor    = gfx.Color{R: 1, G: 0.95, B: 0.6, A: 1}
+	cameraColor   = gfx.Color{R: 0.6, G: 0.8, B: 1, A: 1}
+	probeColor    = gfx.Color{R: 0.5, G: 1, B: 0.8, A: 1}
+)
+
+// gridSize is the number of units that the grid extends from the origin along
+// the X and Y axes.
+const gridSize = 20
+
+// editor is a level editor. It is independent of any window: the events of
+// the window are given to it's handleEvent method, and it is drawn to a canvas
+// by it's draw method.
+type editor struct {
+	// The directory that assets are loaded from and scenes are saved to, and
+	// the path of the scene being edited relative to it (or an empty string
+	// if the scene has not been saved yet).
+	dir, path string
+
+	assets *asset.Manager
+	scene  *scene.Scene
+	inst   *scene.Instance
+
+	// The node being manipulated, or nil, and whether the scene has changed
+	// since it was last opened or saved.
+	selected *scene.Node
+	dirty    bool
+
+	cam   *camera.Camera
+	orbit *control.Orbit
+	gizmo *gizmo.Gizmo
+	debug *debugdraw.Drawer
+
+	// The shader and texture of objects whose nodes have no material.
+	shader *gfx.Shader
+	white  *gfx.Texture
+
+	// The console, and it's view (nil if no font was given), drawn using the
+	// orthographic camera.
+	con   *console.Console
+	view  *console.View
+	ortho *camera.Camera
+
+	prof    *profile.Profiler
+	overlay *profile.Overlay
+
+	// The variables of the console which control the editor.
+	snap, snapAngle, local, grid, stats *console.Var
+
+	// The position of the cursor in window coordinates, and the state of the
+	// left mouse button and the control keys.
+	cursor        lmath.Vec2
+	down, wasDown bool
+	ctrl          bool
+
+	// quit, if non-nil, is called by the quit command.
+	quit func()
+}
+
+// open opens the scene at the given path, relative to the directory of the
+// editor, replacing the current scene.
+func (e *editor) open(path string) error {
+	f, err := os.Open(filepath.Join(e.dir, filepath.FromSlash(path)))
+	if err != nil {
+		return err
+	}
+	s, err := scene.Load(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	inst, err := e.instantiate(s)
+	if err != nil {
+		return err
+	}
+	e.release()
+	e.scene, e.inst, e.path, e.dirty = s, inst, path, false
+	e.selectNode(nil)
+	return nil
+}
+
+// save saves the scene to the given path, relative to the directory of the
+// editor, which becomes the path of the scene.
+func (e *editor) save(path string) error {
+	if path == "" {
+		return fmt.Errorf("scene has no path yet, use: save <path>")
+	}
+	e.inst.Store()
+	name := filepath.Join(e.dir, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := e.scene.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	e.path, e.dirty = path, false
+	return nil
+}
+
+// clear replaces the scene with a new, empty, one.
+func (e *editor) clear() {
+	s := &scene.Scene{}
+	inst, err := s.Instantiate(e.assets)
+	if err != nil {
+		// Only possible with a bug, an empty scene has no assets.
+		panic(err)
+	}
+	e.release()
+	e.scene, e.inst, e.path, e.dirty = s, inst, "", false
+	e.selectNode(nil)
+}
+
+// instantiate instantiates the given scene for editing: objects without a
+// shader or textures are given the editor's default ones, and the data of
+// meshes is kept after loading such that the objects can be picked.
+func (e *editor) instantiate(s *scene.Scene) (*scene.Instance, error) {
+	inst, err := s.Instantiate(e.assets)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range inst.Objects {
+		if o.Shader == nil {
+			o.Shader = e.shader
+		}
+		if len(o.Textures) == 0 {
+			o.Textures = []*gfx.Texture{e.white}
+		}
+		for _, m := range o.Meshes {
+			m.KeepDataOnLoad = true
+		}
+	}
+	return inst, nil
+}
+
+// rebuild instantiates the scene again after it's structure has changed (e.g.
+// a node was added), keeping the selected node.
+func (e *editor) rebuild() error {
+	e.inst.Store()
+	inst, err := e.instantiate(e.scene)
+	if err != nil {
+		return err
+	}
+	e.inst.Release()
+	e.inst, e.dirty = inst, true
+	e.selectNode(e.selected)
+	return nil
+}
+
+// release releases the instance of the current scene, if any.
+func (e *editor) release() {
+	if e.inst != nil {
+		e.inst.Release()
+		e.inst = nil
+	}
+}
+
+// selectNode selects the given node, or deselects if it is nil or is not part
+// of the scene.
+func (e *editor) selectNode(n *scene.Node) {
+	var o *gfx.Object
+	if n != nil && e.inst != nil {
+		o = e.inst.Object(n)
+	}
+	if o == nil {
+		e.selected, e.gizmo.Target = nil, nil
+		return
+	}
+	e.selected, e.gizmo.Target = n, o.Transform
+}
+
+// nodeOf returns the node of the scene that the given object belongs to, i.e.
+// the node of the object itself, or of the nearest of it's parents (for the
+// objects of prefabs), or nil.
+func (e *editor) nodeOf(o *gfx.Object) *scene.Node {
+	if o == nil {
+		return nil
+	}
+	nodes := make(map[*gfx.Transform]*scene.Node)
+	e.scene.Walk(func(n *scene.Node) bool {
+		if no := e.inst.Object(n); no != nil {
+			nodes[no.Transform] = n
+		}
+		return true
+	})
+	for t := o.Transform; t != nil; {
+		if n, ok := nodes[t]; ok {
+			return n
+		}
+		p := t.Parent()
+		if p == nil {
+			break
+		}
+		t = p.Transform()
+	}
+	return nil
+}
+
+// pick selects the node under the given position of the cursor, or deselects
+// if there is none.
+func (e *editor) pick(cursor lmath.Vec2) {
+	o, _ := gfx.Pick(e.cam.PickRay(cursor), e.inst.Objects...)
+	e.selectNode(e.nodeOf(o))
+}
+
+// focus moves the camera to look at the selected node.
+func (e *editor) focus() {
+	if e.selected == nil {
+		return
+	}
+	o := e.inst.Object(e.selected)
+	e.orbit.Target = o.ConvertPos(lmath.Vec3Zero, gfx.LocalToWorld)
+	e.orbit.Update(0)
+}
+
+// handleEvent handles the given window event.
+func (e *editor) handleEvent(ev window.Event) {
+	if e.view != nil && e.view.HandleEvent(ev) {
+		return
+	}
+	switch ev := ev.(type) {
+	case window.CursorMoved:
+		if !ev.Delta {
+			e.cursor = lmath.Vec2{X: ev.X, Y: ev.Y}
+		}
+	case mouse.ButtonEvent:
+		if ev.Button == mouse.Left {
+			e.down = ev.State == mouse.Down
+			return
+		}
+	case keyboard.ButtonEvent:
+		if ev.Key == keyboard.LeftCtrl || ev.Key == keyboard.RightCtrl {
+			e.ctrl = ev.State == keyboard.Down
+		}
+		if ev.State == keyboard.Down {
+			e.key(ev.Key)
+		}
+		return
+	case window.ItemsDropped:
+		for _, item := range ev.Items {
+			e.drop(item)
+		}
+		return
+	}
+	e.orbit.HandleEvent(ev)
+}
+
+// key handles the given key being pressed.
+func (e *editor) key(k keyboard.Key) {
+	var line string
+	switch {
+	case e.ctrl && k == keyboard.S:
+		line = "save"
+	case e.ctrl && k == keyboard.N:
+		line = "new"
+	case e.ctrl && k == keyboard.D:
+		line = "duplicate"
+	case e.ctrl:
+		return
+	case k == keyboard.W:
+		line = "mode translate"
+	case k == keyboard.E:
+		line = "mode rotate"
+	case k == keyboard.R:
+		line = "mode scale"
+	case k == keyboard.L:
+		line = "toggle ed_local"
+	case k == keyboard.G:
+		line = "toggle ed_grid"
+	case k == keyboard.F:
+		line = "focus"
+	case k == keyboard.Delete:
+		line = "delete"
+	default:
+		return
+	}
+	e.con.Submit(line)
+}
+
+// drop handles the file at the given path being dropped onto the window:
+// scenes are opened, and meshes added as new nodes.
+func (e *editor) drop(item string) {
+	rel, err := filepath.Rel(e.dir, item)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		e.con.Printf("%s is not in the data directory %s", item, e.dir)
+		return
+	}
+	rel = filepath.ToSlash(rel)
+	switch filepath.Ext(rel) {
+	case ".scene":
+		e.con.Submit("open " + quote(rel))
+	case ".obj":
+		e.con.Submit("add " + quote(filepath.Base(rel)) + " " + quote(rel))
+	default:
+		e.con.Printf("cannot open %s", rel)
+	}
+}
+
+// update updates the editor by the given time in seconds since the last
+// update.
+func (e *editor) update(dt float64) {
+	e.prof.Begin("update")
+	defer e.prof.End()
+
+	e.gizmo.Snap = e.snap.Float()
+	e.gizmo.SnapAngle = lmath.Radians(e.snapAngle.Float())
+	e.gizmo.Local = e.local.Bool()
+
+	pressed := e.down && !e.wasDown
+	e.wasDown = e.down
+	if e.selected != nil {
+		if d, ok := e.gizmo.Update(e.cam, e.cursor, e.down); ok {
+			d.Apply(e.gizmo.Target)
+			e.dirty = true
+		}
+		if e.gizmo.Active != gizmo.None {
+			pressed = false
+		}
+	}
+	if pressed {
+		e.pick(e.cursor)
+	}
+	e.orbit.Update(dt)
+	if e.view != nil {
+		e.view.Update(dt)
+	}
+}
+
+// drawHelpers adds the debug lines of the grid, the selected node, and the
+// lights, cameras and probes of the scene.
+func (e *editor) drawHelpers() {
+	if e.grid.Bool() {
+		for i := -gridSize; i <= gridSize; i++ {
+			c := gridColor
+			if i%10 == 0 {
+				c = gridMajorColor
+			}
+			f := float64(i)
+			e.debug.AddLine(lmath.Vec3{X: f, Y: -gridSize}, lmath.Vec3{X: f, Y: gridSize}, c)
+			e.debug.AddLine(lmath.Vec3{X: -gridSize, Y: f}, lmath.Vec3{X: gridSize, Y: f}, c)
+		}
+	}
+	for _, l := range e.inst.Lights {
+		r := l.Range
+		if r == 0 {
+			r = 0.25
+		}
+		center := l.Object.ConvertPos(lmath.Vec3Zero, gfx.LocalToWorld)
+		e.debug.AddSphere(lmath.Sphere{Center: center, Radius: r}, lightColor)
+	}
+	for _, c := range e.inst.Cameras {
+		c.Update(e.cam.View)
+		e.debug.AddFrustum(c, cameraColor)
+	}
+	for _, p := range e.inst.Probes {
+		e.debug.AddAABB(p.Box, probeColor)
+	}
+	if e.selected == nil {
+		return
+	}
+	o := e.inst.Object(e.selected)
+	if len(o.Meshes) == 0 {
+		e.debug.AddAxes(o.Convert(gfx.LocalToWorld), 1)
+		return
+	}
+	var bounds lmath.Rect3
+	for i, corner := range o.Bounds().Corners() {
+		p := o.ConvertPos(corner, gfx.LocalToWorld)
+		if i == 0 {
+			bounds = lmath.Rect3{Min: p, Max: p}
+		} else {
+			bounds = bounds.Union(lmath.Rect3{Min: p, Max: p})
+		}
+	}
+	e.debug.AddAABB(bounds, selectedColor)
+}
+
+// draw draws the scene, the helpers, the gizmo and the debug UI to the given
+// canvas.
+func (e *editor) draw(c gfx.Canvas) {
+	e.prof.Begin("draw")
+	b := c.Bounds()
+	if b != e.cam.View {
+		e.cam.Update(b)
+		e.ortho.Update(b)
+	}
+	c.Clear(b, background)
+	c.ClearDepth(b, 1.0)
+	for _, o := range e.inst.Objects {
+		c.Draw(b, o, e.cam)
+	}
+	e.drawHelpers()
+	e.debug.Draw(c, b, e.cam)
+	if e.selected != nil {
+		e.gizmo.Draw(c, b, e.cam)
+	}
+	e.prof.End()
+	e.prof.EndFrame()
+
+	if e.stats.Bool() {
+		var gpu gfx.FrameStats
+		if p, ok := c.(gfx.Profiler); ok {
+			gpu = p.FrameStats()
+		}
+		e.overlay.Update(e.prof.Last(), gpu)
+		e.overlay.Draw(c, b.Inset(8), e.ortho)
+	}
+	if e.view != nil {
+		e.view.Draw(c, e.ortho)
+	}
+}
+
+// destroy releases the scene and destroys the objects of the editor.
+func (e *editor) destroy() {
+	e.release()
+	e.gizmo.Destroy()
+	e.debug.Destroy()
+	e.overlay.Destroy()
+	e.cam.Destroy()
+	e.ortho.Destroy()
+	e.assets.Close()
+}
+
+// newEditor returns a new editor of an empty scene, loading assets from the
+// given directory. If the font is nil, the console is not drawn.
+func newEditor(dir string, font *text.Font) *editor {
+	shader := shaders.New(shaders.BlinnPhong, glsl.GL2)
+	shader.Inputs["LightDirection"] = gfx.Vec3{X: -0.4, Y: 0.6, Z: -0.7}
+	shader.Inputs["LightColor"] = gfx.Color{R: 1, G: 1, B: 1, A: 1}
+	shader.Inputs["AmbientColor"] = gfx.Color{R: 0.25, G: 0.25, B: 0.28, A: 1}
+	shader.Inputs["Shininess"] = float32(32)
+
+	white := gfx.NewTexture()
+	src := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	src.Pix = []uint8{255, 255, 255, 255}
+	white.Source = src
+	white.Bounds = src.Bounds()
+	white.Format = gfx.RGBA
+
+	assets := asset.NewManager(asset.Dir(dir))
+	assets.Register(".scene", scene.Loader{})
+
+	cam := camera.New(image.Rect(0, 0, 640, 480))
+	orbit := control.NewOrbit(cam, lmath.Vec3Zero, 10)
+	orbit.RotateButton = mouse.Right
+
+	con := console.New()
+	e := &editor{
+		dir:       dir,
+		assets:    assets,
+		cam:       cam,
+		orbit:     orbit,
+		gizmo:     gizmo.New(nil, glsl.GL2),
+		debug:     debugdraw.New(),
+		shader:    shader,
+		white:     white,
+		con:       con,
+		ortho:     camera.NewOrtho(cam.View),
+		prof:      profile.New(),
+		overlay:   profile.NewOverlay(font),
+		snap:      con.FloatVar("ed_snap", 0, "translation and scale snapping increment, 0 to disable"),
+		snapAngle: con.FloatVar("ed_snapangle", 0, "rotation snapping increment in degrees, 0 to disable"),
+		local:     con.BoolVar("ed_local", false, "whether the gizmo uses the local axes of the selected node"),
+		grid:      con.BoolVar("ed_grid", true, "whether the grid is drawn"),
+		stats:     con.BoolVar("ed_stats", false, "whether the frame statistics overlay is drawn"),
+	}
+	if font != nil {
+		e.view = console.NewView(con, font)
+	}
+	e.registerCommands()
+	e.clear()
+	return e
+}
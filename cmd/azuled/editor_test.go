@@ -0,0 +1,173 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/gizmo"
+	"azul3d.org/engine/gfx/window"
+	"azul3d.org/engine/lmath"
+	"azul3d.org/engine/mouse"
+)
+
+// cube is a unit cube centered at the origin.
+const cube = `
+v -0.5 -0.5 -0.5
+v 0.5 -0.5 -0.5
+v 0.5 0.5 -0.5
+v -0.5 0.5 -0.5
+v -0.5 -0.5 0.5
+v 0.5 -0.5 0.5
+v 0.5 0.5 0.5
+v -0.5 0.5 0.5
+f 1 3 2
+f 1 4 3
+f 5 6 7
+f 5 7 8
+f 1 2 6
+f 1 6 5
+f 2 3 7
+f 2 7 6
+f 3 4 8
+f 3 8 7
+f 4 1 5
+f 4 5 8
+`
+
+// setup returns an editor of a temporary data directory holding cube.obj,
+// which the returned function removes.
+func setup(t *testing.T) (*editor, func()) {
+	dir, err := ioutil.TempDir("", "azuled")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "cube.obj"), []byte(cube), 0644); err != nil {
+		t.Fatal(err)
+	}
+	e := newEditor(dir, nil)
+	return e, func() {
+		e.destroy()
+		os.RemoveAll(dir)
+	}
+}
+
+// exec executes the given line in the console of the editor.
+func exec(t *testing.T, e *editor, line string) {
+	if err := e.con.Exec(line); err != nil {
+		t.Fatalf("%s: %v", line, err)
+	}
+}
+
+func TestCommands(t *testing.T) {
+	e, done := setup(t)
+	defer done()
+
+	exec(t, e, "add box cube.obj; add lid; prop team red")
+	if e.selected == nil || e.selected.Name != "lid" || e.scene.Find("box/lid") != e.selected {
+		t.Fatal("lid not added as a child of the box", e.selected)
+	}
+	if len(e.inst.Objects) != 1 || e.inst.Objects[0].Shader != e.shader {
+		t.Fatal("box not instantiated with the default shader")
+	}
+	if err := e.con.Exec("add bad missing.obj"); err == nil || e.scene.Find("box/lid/bad") != nil {
+		t.Fatal("node with a missing mesh added")
+	}
+	exec(t, e, "save levels/one.scene")
+	if e.dirty || e.path != "levels/one.scene" {
+		t.Fatal("not saved")
+	}
+
+	exec(t, e, "new; open levels/one.scene; select box; duplicate; rename copy")
+	if len(e.scene.Nodes) != 2 || e.scene.Find("copy/lid") == nil {
+		t.Fatal("box not duplicated", e.scene.Nodes)
+	}
+	if e.scene.Find("box/lid").Properties["team"] != "red" {
+		t.Fatal("property not saved")
+	}
+	if e.scene.Find("copy/lid") == e.scene.Find("box/lid") {
+		t.Fatal("duplicate shares it's children")
+	}
+	exec(t, e, "delete")
+	if len(e.scene.Nodes) != 1 || e.selected != nil || len(e.inst.Objects) != 1 {
+		t.Fatal("copy not deleted")
+	}
+	if err := e.con.Exec("delete"); err == nil {
+		t.Fatal("deleted without a selection", err)
+	}
+
+	// Dropped meshes are added, files outside of the data directory are not.
+	e.handleEvent(window.ItemsDropped{Items: []string{
+		filepath.Join(e.dir, "cube.obj"),
+		filepath.Join(filepath.Dir(e.dir), "cube.obj"),
+	}})
+	if len(e.scene.Nodes) != 2 || e.scene.Nodes[1].Mesh != "cube.obj" {
+		t.Fatal("dropped mesh not added")
+	}
+}
+
+// cursorAt returns the window position of the given world space point.
+func cursorAt(t *testing.T, e *editor, p lmath.Vec3) lmath.Vec2 {
+	ndc, ok := e.cam.Project(p)
+	if !ok {
+		t.Fatal("point not visible", p)
+	}
+	v := e.cam.View
+	return lmath.Vec2{
+		X: float64(v.Min.X) + (ndc.X+1)/2*float64(v.Dx()),
+		Y: float64(v.Min.Y) + (1-ndc.Y)/2*float64(v.Dy()),
+	}
+}
+
+// click moves the cursor to the given position and presses or releases the
+// left mouse button, updating the editor.
+func click(e *editor, cursor lmath.Vec2, state mouse.State) {
+	e.handleEvent(window.CursorMoved{X: cursor.X, Y: cursor.Y})
+	e.handleEvent(mouse.ButtonEvent{Button: mouse.Left, State: state})
+	e.update(1.0 / 60)
+}
+
+func TestPickAndDrag(t *testing.T) {
+	e, done := setup(t)
+	defer done()
+	exec(t, e, "add box cube.obj; select; ed_snap 0.5")
+
+	// Clicking the cube selects it, and clicking nothing deselects.
+	center := cursorAt(t, e, lmath.Vec3Zero)
+	click(e, center.Add(lmath.Vec2{X: 300}), mouse.Down)
+	click(e, center.Add(lmath.Vec2{X: 300}), mouse.Up)
+	if e.selected != nil {
+		t.Fatal("selected", e.selected.Name)
+	}
+	click(e, center, mouse.Down)
+	click(e, center, mouse.Up)
+	if e.selected == nil || e.selected.Name != "box" {
+		t.Fatal("box not selected")
+	}
+
+	// Dragging the center handle of the gizmo moves the box in the plane of
+	// the screen, snapped to half units.
+	click(e, center, mouse.Down)
+	if e.gizmo.Active != gizmo.Center {
+		t.Fatal("gizmo not dragged", e.gizmo.Active)
+	}
+	click(e, cursorAt(t, e, lmath.Vec3{X: 1.1}), mouse.Down)
+	click(e, cursorAt(t, e, lmath.Vec3{X: 1.1}), mouse.Up)
+	if !e.dirty || e.selected == nil {
+		t.Fatal("drag ended the selection")
+	}
+	exec(t, e, "save one.scene")
+	pos := e.scene.Find("box").Transform.Pos
+	if !pos.AlmostEquals(lmath.Vec3{X: 1}, 1e-9) {
+		t.Fatalf("box moved to %v, want X=1", pos)
+	}
+
+	exec(t, e, "ed_stats 1")
+	e.draw(gfx.Nil())
+}
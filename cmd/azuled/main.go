@@ -0,0 +1,193 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Azuled is a minimal level editor for scene files (see package scene).
+//
+// It composes the window, scene, asset, gizmo, console and profiling packages
+// of the engine into a reference application, such that it serves both as a
+// tool and as an example of using those packages together.
+//
+// Usage:
+//
+//  azuled [flags] [scene]
+//
+// Assets and scenes are loaded from, and saved to, the data directory; the
+// scene to open is given by it's path relative to it, e.g.:
+//
+//  azuled -data ./data -font fonts/mono.fnt levels/one.scene
+//
+// The flags are:
+//
+//  -data dir    the data directory (default ".")
+//  -font file   a BMFont file to draw the console with (see package text)
+//  -exec file   a command file to execute on startup
+//
+// Controls
+//
+// Clicking an object selects it's node, and dragging the handles of the gizmo
+// moves, rotates or scales it. Dragging with the right mouse button orbits the
+// camera, the middle button pans, and scrolling zooms. The keys are:
+//
+//  W, E, R  - translate, rotate or scale mode
+//  L        - toggle between the local axes of the node and those of it's parent
+//  G        - toggle the grid
+//  F        - focus the camera on the selected node
+//  Delete   - delete the selected node
+//  Ctrl+S   - save the scene
+//  Ctrl+N   - new scene
+//  Ctrl+D   - duplicate the selected node
+//  ~        - toggle the console
+//
+// Scenes and meshes (.obj) dropped onto the window are opened, or added as new
+// nodes, respectively.
+//
+// Console
+//
+// Every action of the editor is a command of it's console (see package
+// console), which may also be executed from a command file:
+//
+//  new                   - replaces the scene with an empty one
+//  open <path>           - opens a scene
+//  save [path]           - saves the scene, to the given path if any
+//  nodes                 - lists the nodes of the scene
+//  select [path]         - selects the node at the given path, or deselects
+//  add <name> [mesh]     - adds a node, with the mesh at the given asset path if any
+//  prefab <name> <scene> - adds a node instancing the scene at the given asset path
+//  duplicate             - duplicates the selected node
+//  delete                - deletes the selected node and it's children
+//  rename <name>         - renames the selected node
+//  prop <key> [value]    - prints or sets a property of the selected node
+//  mode <mode>           - sets the mode of the gizmo (translate, rotate or scale)
+//  focus                 - focuses the camera on the selected node
+//  identity              - resets the transform of the selected node
+//  quit                  - closes the editor
+//
+// New nodes are added at the point that the camera orbits, as children of the
+// selected node. The variables of the editor are:
+//
+//  ed_snap       - translation and scale snapping increment, 0 to disable
+//  ed_snapangle  - rotation snapping increment in degrees, 0 to disable
+//  ed_local      - whether the gizmo uses the local axes of the selected node
+//  ed_grid       - whether the grid is drawn
+//  ed_stats      - whether the frame statistics overlay is drawn
+//
+// Nodes without a material are drawn with a default lit shader.
+package main // import "azul3d.org/engine/cmd/azuled"
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	_ "image/jpeg"
+	_ "image/png"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/clock"
+	"azul3d.org/engine/gfx/window"
+	"azul3d.org/engine/text"
+)
+
+var (
+	dataFlag = flag.String("data", ".", "the data directory")
+	fontFlag = flag.String("font", "", "a BMFont file to draw the console with")
+	execFlag = flag.String("exec", "", "a command file to execute on startup")
+)
+
+// title returns the title of the window for the given editor.
+func title(e *editor) string {
+	name := e.path
+	if name == "" {
+		name = "untitled"
+	}
+	if e.dirty {
+		name += "*"
+	}
+	return fmt.Sprintf("%s - azuled - {FPS} FPS", name)
+}
+
+// gfxLoop is the graphics loop of the editor.
+func gfxLoop(w window.Window, d gfx.Device) {
+	var font *text.Font
+	if *fontFlag != "" {
+		f, err := text.LoadBMFontFile(*fontFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+		font = f
+	}
+
+	e := newEditor(*dataFlag, font)
+	defer e.destroy()
+	closed := false
+	e.quit = func() { closed = true }
+	if flag.NArg() > 0 {
+		e.con.Submit("open " + quote(flag.Arg(0)))
+	}
+	if *execFlag != "" {
+		if err := e.con.ExecFile(*execFlag); err != nil {
+			e.con.Println(err)
+		}
+	}
+
+	// Without a font, the output of the console is printed to stdout instead.
+	printOutput := func() {
+		if e.view != nil {
+			return
+		}
+		lines := e.con.Lines()
+		if len(lines) > 0 {
+			fmt.Println(strings.Join(lines, "\n"))
+		}
+		e.con.Clear()
+	}
+
+	events := make(chan window.Event, 256)
+	w.Notify(events, window.CloseEvents|window.CursorMovedEvents|window.MouseButtonEvents|
+		window.MouseScrolledEvents|window.KeyboardButtonEvents|window.KeyboardTypedEvents|
+		window.ItemsDroppedEvents)
+	defer w.Notify(events, window.NoEvents)
+
+	clk := clock.New()
+	lastTitle := ""
+	for {
+		window.PollCoalesced(events, func(ev window.Event) {
+			if _, ok := ev.(window.Close); ok {
+				closed = true
+				return
+			}
+			e.handleEvent(ev)
+		})
+		e.update(clk.Dt())
+		printOutput()
+		if closed {
+			w.Close()
+			return
+		}
+
+		if t := title(e); t != lastTitle {
+			props := w.Props()
+			props.SetTitle(t)
+			w.Request(props)
+			lastTitle = t
+		}
+		e.draw(d)
+		d.Render()
+		clk.Tick()
+	}
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: azuled [flags] [scene]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	props := window.NewProps()
+	props.SetTitle("azuled - {FPS} FPS")
+	window.Run(gfxLoop, props)
+}
@@ -0,0 +1,127 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package codec
+
+import (
+	"errors"
+	"math/bits"
+)
+
+// ErrShort is returned when reading past the end of the data.
+var ErrShort = errors.New("codec: unexpected end of data")
+
+// bitsFor returns the number of bits needed to encode values in the range
+// [0, n].
+func bitsFor(n uint64) uint {
+	return uint(bits.Len64(n))
+}
+
+// BitWriter writes values of any number of bits to a byte slice, least
+// significant bits first.
+type BitWriter struct {
+	buf     []byte
+	scratch uint64
+	n       uint
+}
+
+// WriteBits writes the low n bits of v, where n is at most 64.
+func (w *BitWriter) WriteBits(v uint64, n uint) {
+	if n > 32 {
+		w.WriteBits(v&0xFFFFFFFF, 32)
+		v >>= 32
+		n -= 32
+	}
+	if n < 64 {
+		v &= 1<<n - 1
+	}
+	w.scratch |= v << w.n
+	w.n += n
+	for w.n >= 8 {
+		w.buf = append(w.buf, byte(w.scratch))
+		w.scratch >>= 8
+		w.n -= 8
+	}
+}
+
+// WriteBool writes a single bit.
+func (w *BitWriter) WriteBool(v bool) {
+	var b uint64
+	if v {
+		b = 1
+	}
+	w.WriteBits(b, 1)
+}
+
+// Align pads the data with zero bits to the next byte boundary.
+func (w *BitWriter) Align() {
+	if w.n > 0 {
+		w.WriteBits(0, 8-w.n)
+	}
+}
+
+// Len returns the number of bits written.
+func (w *BitWriter) Len() int {
+	return len(w.buf)*8 + int(w.n)
+}
+
+// Bytes returns the data written, with the last byte padded with zero bits.
+func (w *BitWriter) Bytes() []byte {
+	if w.n == 0 {
+		return w.buf
+	}
+	return append(w.buf[:len(w.buf):len(w.buf)], byte(w.scratch))
+}
+
+// BitReader reads values written by a BitWriter. Once an error occurs all
+// further reads return zero.
+type BitReader struct {
+	buf     []byte
+	scratch uint64
+	n       uint
+	err     error
+}
+
+// ReadBits reads an n bit value, where n is at most 64.
+func (r *BitReader) ReadBits(n uint) uint64 {
+	if n > 32 {
+		lo := r.ReadBits(32)
+		return lo | r.ReadBits(n-32)<<32
+	}
+	for r.n < n {
+		if len(r.buf) == 0 {
+			r.err = ErrShort
+		}
+		if r.err != nil {
+			return 0
+		}
+		r.scratch |= uint64(r.buf[0]) << r.n
+		r.buf = r.buf[1:]
+		r.n += 8
+	}
+	v := r.scratch & (1<<n - 1)
+	r.scratch >>= n
+	r.n -= n
+	return v
+}
+
+// ReadBool reads a single bit.
+func (r *BitReader) ReadBool() bool {
+	return r.ReadBits(1) == 1
+}
+
+// Align skips the padding bits up to the next byte boundary.
+func (r *BitReader) Align() {
+	r.ReadBits(r.n % 8)
+}
+
+// Err returns the first error encountered while reading, if any.
+func (r *BitReader) Err() error {
+	return r.err
+}
+
+// NewBitReader returns a new reader of the given data.
+func NewBitReader(data []byte) *BitReader {
+	return &BitReader{buf: data}
+}
@@ -0,0 +1,100 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package codec
+
+import (
+	"math"
+	"testing"
+
+	"azul3d.org/engine/lmath"
+)
+
+type player struct {
+	Name   string
+	Pos    lmath.Vec3
+	Rot    lmath.Quat
+	Health int32
+	Score  int64
+	Speed  float32
+	Crouch bool
+}
+
+func (p *player) Serialize(s Stream) {
+	s.String(&p.Name, 32)
+	s.Vec3(&p.Pos, -1024, 1024, 0.01)
+	s.Quat(&p.Rot, 12)
+	s.Int(&p.Health, -10, 100)
+	s.Varint(&p.Score)
+	s.Float32(&p.Speed)
+	if s.Version() >= 2 {
+		s.Bool(&p.Crouch)
+	}
+}
+
+func TestBits(t *testing.T) {
+	var w BitWriter
+	w.WriteBits(5, 3)
+	w.WriteBool(true)
+	w.WriteBits(math.MaxUint64, 64)
+	w.WriteBits(0x1234, 13)
+	if w.Len() != 81 {
+		t.Fatalf("got %d bits, want 81", w.Len())
+	}
+	r := NewBitReader(w.Bytes())
+	if r.ReadBits(3) != 5 || !r.ReadBool() || r.ReadBits(64) != math.MaxUint64 || r.ReadBits(13) != 0x1234 {
+		t.Fatal("read back wrong values")
+	}
+	r.ReadBits(8)
+	if r.Err() != ErrShort {
+		t.Fatalf("got %v, want ErrShort", r.Err())
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	in := &player{
+		Name:   "gopher",
+		Pos:    lmath.Vec3{X: 1.234, Y: -500.5, Z: 2000},
+		Rot:    lmath.Quat{W: 0.5, X: -0.5, Y: 0.5, Z: 0.5},
+		Health: -3,
+		Score:  -123456789,
+		Speed:  3.5,
+		Crouch: true,
+	}
+	for _, version := range []int{1, 2} {
+		data, err := Marshal(in, version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out player
+		if err := Unmarshal(data, &out); err != nil {
+			t.Fatal(err)
+		}
+		want := lmath.Vec3{X: 1.23, Y: -500.5, Z: 1024}
+		if !out.Pos.AlmostEquals(want, 0.006) {
+			t.Fatalf("got pos %v, want %v", out.Pos, want)
+		}
+		if !out.Rot.AlmostEquals(in.Rot, 0.001) {
+			t.Fatalf("got rot %v, want %v", out.Rot, in.Rot)
+		}
+		if out.Name != in.Name || out.Health != in.Health || out.Score != in.Score || out.Speed != in.Speed {
+			t.Fatalf("got %+v, want %+v", out, in)
+		}
+		if out.Crouch != (version >= 2) {
+			t.Fatalf("version %d: got crouch %v", version, out.Crouch)
+		}
+	}
+
+	// Out of range values.
+	in.Health = 101
+	if _, err := Marshal(in, 1); err != ErrRange {
+		t.Fatalf("got %v, want ErrRange", err)
+	}
+	in.Health = 0
+	data, _ := Marshal(in, 1)
+	var out player
+	if err := Unmarshal(data[:len(data)/2], &out); err != ErrShort {
+		t.Fatalf("got %v, want ErrShort", err)
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package codec implements compact, versioned binary encoding of gameplay
+// data for networking and replays.
+//
+// Types are encoded without reflection: each implements Serializer with a
+// single Serialize method which both reads and writes it, depending on the
+// Stream it is given. Values are bit-packed: integers use only the bits their
+// declared range needs, and floats may be quantized to a range and
+// resolution:
+//
+//  type Player struct {
+//      Pos    lmath.Vec3
+//      Rot    lmath.Quat
+//      Health int32
+//      Crouch bool
+//  }
+//
+//  func (p *Player) Serialize(s codec.Stream) {
+//      s.Vec3(&p.Pos, -1024, 1024, 0.01)
+//      s.Quat(&p.Rot, 10)
+//      s.Int(&p.Health, 0, 100)
+//      if s.Version() >= 2 {
+//          s.Bool(&p.Crouch)
+//      }
+//  }
+//
+//  data, err := codec.Marshal(player, 2)
+//  ...
+//  err = codec.Unmarshal(data, player)
+//
+// The version given to Marshal is stored with the data and returned by
+// Stream.Version while reading, such that fields added in later versions may
+// be skipped when reading older data.
+//
+// To record values in a replay (which uses encoding/gob) a type may implement
+// gob.GobEncoder and gob.GobDecoder using Marshal and Unmarshal.
+package codec // import "azul3d.org/engine/codec"
@@ -0,0 +1,475 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package codec
+
+import (
+	"errors"
+	"math"
+
+	"azul3d.org/engine/lmath"
+)
+
+// ErrRange is returned when a value is outside of it's declared range, when
+// writing, or the data holds such a value, when reading.
+var ErrRange = errors.New("codec: value out of range")
+
+// Stream reads or writes the fields of a value. Each method writes the value
+// pointed to when writing, and stores the value read through the pointer when
+// reading, such that a single Serialize method does both. Once an error
+// occurs the remaining calls are no-op.
+type Stream interface {
+	// Reading tells if the stream is reading rather than writing.
+	Reading() bool
+
+	// Version returns the version of the data, see Marshal.
+	Version() int
+
+	// Bits reads or writes the low n bits of *v, where n is at most 64.
+	Bits(v *uint64, n uint)
+
+	// Bool reads or writes a single bit.
+	Bool(v *bool)
+
+	// Int reads or writes an integer in the range [min, max], using only the
+	// bits the range needs.
+	Int(v *int32, min, max int32)
+
+	// Uint reads or writes an unsigned integer in the range [min, max],
+	// using only the bits the range needs.
+	Uint(v *uint32, min, max uint32)
+
+	// Varint reads or writes an integer of any size, using fewer bits for
+	// values closer to zero.
+	Varint(v *int64)
+
+	// Float32 and Float64 read or write floats exactly.
+	Float32(v *float32)
+	Float64(v *float64)
+
+	// Float reads or writes a float quantized to the range [min, max] with
+	// the given resolution, e.g. 0.01 for centimetres. Values outside of
+	// the range are clamped.
+	Float(v *float64, min, max, res float64)
+
+	// Vec3 reads or writes a vector, each component quantized as by Float.
+	Vec3(v *lmath.Vec3, min, max, res float64)
+
+	// Quat reads or writes a unit quaternion, using the "smallest three"
+	// compression: the largest component is dropped (and recomputed when
+	// reading), and the others are quantized to the given number of bits.
+	Quat(v *lmath.Quat, bits uint)
+
+	// String and Bytes read or write data of at most max bytes.
+	String(v *string, max int)
+	Bytes(v *[]byte, max int)
+
+	// Err returns the first error encountered, if any.
+	Err() error
+}
+
+// Serializer is implemented by values which may be encoded.
+type Serializer interface {
+	// Serialize reads or writes the fields of the value to the stream.
+	Serialize(s Stream)
+}
+
+// quantize returns the number of steps of the given resolution covering the
+// range [min, max], and the bits needed to encode them.
+func quantize(min, max, res float64) (uint64, uint) {
+	steps := uint64(math.Ceil((max - min) / res))
+	return steps, bitsFor(steps)
+}
+
+// smallestThree is the range of the three smallest components of a unit
+// quaternion.
+const smallestThree = math.Sqrt2 / 2
+
+// quatComponents returns pointers to the components of the quaternion.
+func quatComponents(q *lmath.Quat) [4]*float64 {
+	return [4]*float64{&q.W, &q.X, &q.Y, &q.Z}
+}
+
+// Writer is a Stream which writes values.
+type Writer struct {
+	bits    BitWriter
+	version int
+	err     error
+}
+
+// Reading implements the Stream interface.
+func (w *Writer) Reading() bool { return false }
+
+// Version implements the Stream interface.
+func (w *Writer) Version() int { return w.version }
+
+// Err implements the Stream interface.
+func (w *Writer) Err() error { return w.err }
+
+// Bits implements the Stream interface.
+func (w *Writer) Bits(v *uint64, n uint) {
+	if w.err == nil {
+		w.bits.WriteBits(*v, n)
+	}
+}
+
+// Bool implements the Stream interface.
+func (w *Writer) Bool(v *bool) {
+	if w.err == nil {
+		w.bits.WriteBool(*v)
+	}
+}
+
+// Int implements the Stream interface.
+func (w *Writer) Int(v *int32, min, max int32) {
+	if w.err != nil {
+		return
+	}
+	if *v < min || *v > max {
+		w.err = ErrRange
+		return
+	}
+	w.bits.WriteBits(uint64(int64(*v)-int64(min)), bitsFor(uint64(int64(max)-int64(min))))
+}
+
+// Uint implements the Stream interface.
+func (w *Writer) Uint(v *uint32, min, max uint32) {
+	if w.err != nil {
+		return
+	}
+	if *v < min || *v > max {
+		w.err = ErrRange
+		return
+	}
+	w.bits.WriteBits(uint64(*v-min), bitsFor(uint64(max-min)))
+}
+
+// Varint implements the Stream interface.
+func (w *Writer) Varint(v *int64) {
+	if w.err != nil {
+		return
+	}
+	// Zig-zag encode, then write groups of seven bits each preceded by a bit
+	// telling if more follow.
+	u := uint64(*v<<1) ^ uint64(*v>>63)
+	for u >= 0x80 {
+		w.bits.WriteBool(true)
+		w.bits.WriteBits(u&0x7F, 7)
+		u >>= 7
+	}
+	w.bits.WriteBool(false)
+	w.bits.WriteBits(u, 7)
+}
+
+// Float32 implements the Stream interface.
+func (w *Writer) Float32(v *float32) {
+	if w.err == nil {
+		w.bits.WriteBits(uint64(math.Float32bits(*v)), 32)
+	}
+}
+
+// Float64 implements the Stream interface.
+func (w *Writer) Float64(v *float64) {
+	if w.err == nil {
+		w.bits.WriteBits(math.Float64bits(*v), 64)
+	}
+}
+
+// Float implements the Stream interface.
+func (w *Writer) Float(v *float64, min, max, res float64) {
+	if w.err != nil {
+		return
+	}
+	steps, n := quantize(min, max, res)
+	f := math.Round((lmath.Clamp(*v, min, max) - min) / res)
+	q := uint64(0)
+	if f > 0 {
+		q = uint64(f)
+	}
+	if q > steps {
+		q = steps
+	}
+	w.bits.WriteBits(q, n)
+}
+
+// Vec3 implements the Stream interface.
+func (w *Writer) Vec3(v *lmath.Vec3, min, max, res float64) {
+	w.Float(&v.X, min, max, res)
+	w.Float(&v.Y, min, max, res)
+	w.Float(&v.Z, min, max, res)
+}
+
+// Quat implements the Stream interface.
+func (w *Writer) Quat(v *lmath.Quat, bits uint) {
+	if w.err != nil {
+		return
+	}
+	q := v.Normalized()
+	c := quatComponents(&q)
+	largest := 0
+	for i := range c {
+		if math.Abs(*c[i]) > math.Abs(*c[largest]) {
+			largest = i
+		}
+	}
+	sign := 1.0
+	if *c[largest] < 0 {
+		sign = -1
+	}
+	w.bits.WriteBits(uint64(largest), 2)
+	for i := range c {
+		if i == largest {
+			continue
+		}
+		f := *c[i] * sign
+		w.Float(&f, -smallestThree, smallestThree, 2*smallestThree/float64(uint64(1)<<bits-1))
+	}
+}
+
+// String implements the Stream interface.
+func (w *Writer) String(v *string, max int) {
+	if w.err != nil {
+		return
+	}
+	if len(*v) > max {
+		w.err = ErrRange
+		return
+	}
+	w.bits.WriteBits(uint64(len(*v)), bitsFor(uint64(max)))
+	for i := 0; i < len(*v); i++ {
+		w.bits.WriteBits(uint64((*v)[i]), 8)
+	}
+}
+
+// Bytes implements the Stream interface.
+func (w *Writer) Bytes(v *[]byte, max int) {
+	s := string(*v)
+	w.String(&s, max)
+}
+
+// Data returns the data written, padded to a whole number of bytes.
+func (w *Writer) Data() []byte {
+	return w.bits.Bytes()
+}
+
+// NewWriter returns a new writer of data of the given version, which it does
+// not write itself (see Marshal).
+func NewWriter(version int) *Writer {
+	return &Writer{version: version}
+}
+
+// Reader is a Stream which reads values.
+type Reader struct {
+	bits    *BitReader
+	version int
+	err     error
+}
+
+// Reading implements the Stream interface.
+func (r *Reader) Reading() bool { return true }
+
+// Version implements the Stream interface.
+func (r *Reader) Version() int { return r.version }
+
+// Err implements the Stream interface.
+func (r *Reader) Err() error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.bits.Err()
+}
+
+// read reads n bits, or returns false if an error has occurred.
+func (r *Reader) read(n uint) (uint64, bool) {
+	v := r.bits.ReadBits(n)
+	return v, r.Err() == nil
+}
+
+// Bits implements the Stream interface.
+func (r *Reader) Bits(v *uint64, n uint) {
+	if u, ok := r.read(n); ok {
+		*v = u
+	}
+}
+
+// Bool implements the Stream interface.
+func (r *Reader) Bool(v *bool) {
+	if u, ok := r.read(1); ok {
+		*v = u == 1
+	}
+}
+
+// Int implements the Stream interface.
+func (r *Reader) Int(v *int32, min, max int32) {
+	span := uint64(int64(max) - int64(min))
+	u, ok := r.read(bitsFor(span))
+	if !ok {
+		return
+	}
+	if u > span {
+		r.err = ErrRange
+		return
+	}
+	*v = int32(int64(min) + int64(u))
+}
+
+// Uint implements the Stream interface.
+func (r *Reader) Uint(v *uint32, min, max uint32) {
+	span := uint64(max - min)
+	u, ok := r.read(bitsFor(span))
+	if !ok {
+		return
+	}
+	if u > span {
+		r.err = ErrRange
+		return
+	}
+	*v = min + uint32(u)
+}
+
+// Varint implements the Stream interface.
+func (r *Reader) Varint(v *int64) {
+	var u uint64
+	for shift := uint(0); ; shift += 7 {
+		more, ok := r.read(1)
+		if !ok {
+			return
+		}
+		group, ok := r.read(7)
+		if !ok {
+			return
+		}
+		if shift > 63 {
+			r.err = ErrRange
+			return
+		}
+		u |= group << shift
+		if more == 0 {
+			break
+		}
+	}
+	*v = int64(u>>1) ^ -int64(u&1)
+}
+
+// Float32 implements the Stream interface.
+func (r *Reader) Float32(v *float32) {
+	if u, ok := r.read(32); ok {
+		*v = math.Float32frombits(uint32(u))
+	}
+}
+
+// Float64 implements the Stream interface.
+func (r *Reader) Float64(v *float64) {
+	if u, ok := r.read(64); ok {
+		*v = math.Float64frombits(u)
+	}
+}
+
+// Float implements the Stream interface.
+func (r *Reader) Float(v *float64, min, max, res float64) {
+	steps, n := quantize(min, max, res)
+	u, ok := r.read(n)
+	if !ok {
+		return
+	}
+	if u > steps {
+		r.err = ErrRange
+		return
+	}
+	*v = math.Min(min+float64(u)*res, max)
+}
+
+// Vec3 implements the Stream interface.
+func (r *Reader) Vec3(v *lmath.Vec3, min, max, res float64) {
+	r.Float(&v.X, min, max, res)
+	r.Float(&v.Y, min, max, res)
+	r.Float(&v.Z, min, max, res)
+}
+
+// Quat implements the Stream interface.
+func (r *Reader) Quat(v *lmath.Quat, bits uint) {
+	u, ok := r.read(2)
+	if !ok {
+		return
+	}
+	largest := int(u)
+	var q lmath.Quat
+	c := quatComponents(&q)
+	sum := 0.0
+	for i := range c {
+		if i == largest {
+			continue
+		}
+		r.Float(c[i], -smallestThree, smallestThree, 2*smallestThree/float64(uint64(1)<<bits-1))
+		sum += *c[i] * *c[i]
+	}
+	if r.Err() != nil {
+		return
+	}
+	*c[largest] = math.Sqrt(math.Max(0, 1-sum))
+	*v = q
+}
+
+// String implements the Stream interface.
+func (r *Reader) String(v *string, max int) {
+	var b []byte
+	r.Bytes(&b, max)
+	if r.Err() == nil {
+		*v = string(b)
+	}
+}
+
+// Bytes implements the Stream interface.
+func (r *Reader) Bytes(v *[]byte, max int) {
+	n, ok := r.read(bitsFor(uint64(max)))
+	if !ok {
+		return
+	}
+	if n > uint64(max) {
+		r.err = ErrRange
+		return
+	}
+	b := make([]byte, n)
+	for i := range b {
+		c, ok := r.read(8)
+		if !ok {
+			return
+		}
+		b[i] = byte(c)
+	}
+	*v = b
+}
+
+// NewReader returns a new reader of the given data of the given version,
+// which it does not read itself (see Unmarshal).
+func NewReader(data []byte, version int) *Reader {
+	return &Reader{bits: NewBitReader(data), version: version}
+}
+
+// Marshal encodes the value, prefixed with the given version of it's
+// encoding.
+func Marshal(v Serializer, version int) ([]byte, error) {
+	w := NewWriter(version)
+	ver := int64(version)
+	w.Varint(&ver)
+	v.Serialize(w)
+	if w.err != nil {
+		return nil, w.err
+	}
+	return w.Data(), nil
+}
+
+// Unmarshal decodes a value encoded by Marshal into v, providing the version
+// it was encoded with through Stream.Version.
+func Unmarshal(data []byte, v Serializer) error {
+	r := NewReader(data, 0)
+	var ver int64
+	r.Varint(&ver)
+	if err := r.Err(); err != nil {
+		return err
+	}
+	r.version = int(ver)
+	v.Serialize(r)
+	return r.Err()
+}
@@ -0,0 +1,201 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package collision
+
+import (
+	"math"
+	"sort"
+
+	"azul3d.org/engine/lmath"
+)
+
+// bvhLeafSize is the maximum number of items in a leaf node of a BVH.
+const bvhLeafSize = 4
+
+// bvhNode is a node of a BVH. Leaf nodes have a non-zero count of items
+// starting at the given index into the items slice, interior nodes have two
+// children, the first of which directly follows the node.
+type bvhNode struct {
+	bounds       lmath.Rect3
+	start, count int
+	right        int
+}
+
+// BVH is a static bounding volume hierarchy, a binary tree of bounding boxes
+// for finding the items (e.g. triangles of level geometry) near a query
+// quickly.
+//
+// A BVH is immutable once built, and is safe for use concurrently by
+// multiple goroutines.
+type BVH struct {
+	nodes  []bvhNode
+	items  []int
+	bounds []lmath.Rect3
+}
+
+// NewBVH builds a new BVH over items with the given bounding boxes. Queries
+// report items by their index into the bounds slice.
+func NewBVH(bounds []lmath.Rect3) *BVH {
+	b := &BVH{
+		items:  make([]int, len(bounds)),
+		bounds: append([]lmath.Rect3(nil), bounds...),
+	}
+	if len(bounds) == 0 {
+		return b
+	}
+	centers := make([]lmath.Vec3, len(bounds))
+	for i, r := range bounds {
+		b.items[i] = i
+		centers[i] = r.Center()
+	}
+	b.nodes = make([]bvhNode, 0, 2*len(bounds)/bvhLeafSize+1)
+	b.build(centers, 0, len(bounds))
+	return b
+}
+
+// build builds the subtree over items [start, end), returning the index of
+// it's root node.
+func (b *BVH) build(centers []lmath.Vec3, start, end int) int {
+	bounds := b.bounds
+	index := len(b.nodes)
+	b.nodes = append(b.nodes, bvhNode{})
+	items := b.items[start:end]
+
+	node := bvhNode{bounds: bounds[items[0]]}
+	centerBounds := lmath.Rect3{Min: centers[items[0]], Max: centers[items[0]]}
+	for _, i := range items[1:] {
+		node.bounds = node.bounds.Union(bounds[i])
+		centerBounds.Min = centerBounds.Min.Min(centers[i])
+		centerBounds.Max = centerBounds.Max.Max(centers[i])
+	}
+	if len(items) <= bvhLeafSize {
+		node.start, node.count = start, len(items)
+		b.nodes[index] = node
+		return index
+	}
+
+	// Split at the median along the longest axis of the item centers.
+	size := centerBounds.Size()
+	axis := func(v lmath.Vec3) float64 { return v.X }
+	if size.Y > size.X && size.Y >= size.Z {
+		axis = func(v lmath.Vec3) float64 { return v.Y }
+	} else if size.Z > size.X && size.Z > size.Y {
+		axis = func(v lmath.Vec3) float64 { return v.Z }
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return axis(centers[items[i]]) < axis(centers[items[j]])
+	})
+	mid := start + len(items)/2
+	b.build(centers, start, mid)
+	node.right = b.build(centers, mid, end)
+	b.nodes[index] = node
+	return index
+}
+
+// Len returns the number of items in the BVH.
+func (b *BVH) Len() int {
+	return len(b.items)
+}
+
+// Bounds returns the bounding box of all items in the BVH.
+func (b *BVH) Bounds() lmath.Rect3 {
+	if len(b.nodes) == 0 {
+		return lmath.Rect3{}
+	}
+	return b.nodes[0].bounds
+}
+
+// Query calls fn with the index of each item whose bounding box overlaps the
+// given one, until fn returns false.
+func (b *BVH) Query(r lmath.Rect3, fn func(i int) bool) {
+	if len(b.nodes) == 0 {
+		return
+	}
+	stack := make([]int, 1, 32)
+	for len(stack) > 0 {
+		index := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		n := &b.nodes[index]
+		if !touches(n.bounds, r) {
+			continue
+		}
+		if n.count > 0 {
+			for _, i := range b.items[n.start : n.start+n.count] {
+				if touches(b.bounds[i], r) && !fn(i) {
+					return
+				}
+			}
+			continue
+		}
+		stack = append(stack, n.right, index+1)
+	}
+}
+
+// touches tells if the two boxes overlap or touch, unlike Rect3.Overlaps it
+// includes boxes which share only a face (e.g. the bounds of flat triangles).
+func touches(a, b lmath.Rect3) bool {
+	return a.Min.X <= b.Max.X && b.Min.X <= a.Max.X &&
+		a.Min.Y <= b.Max.Y && b.Min.Y <= a.Max.Y &&
+		a.Min.Z <= b.Max.Z && b.Min.Z <= a.Max.Z
+}
+
+// RayCast finds the item nearest along the ray, up to the distance maxT
+// (in units of the length of the ray's direction). The hit function is
+// called for items whose bounding box the ray intersects, and must return
+// the distance along the ray to the item if the ray hits it.
+func (b *BVH) RayCast(r lmath.Ray, maxT float64, hit func(i int) (t float64, ok bool)) (item int, t float64, ok bool) {
+	if len(b.nodes) == 0 {
+		return -1, 0, false
+	}
+	t = maxT
+	item = -1
+	stack := make([]int, 1, 32)
+	for len(stack) > 0 {
+		index := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		n := &b.nodes[index]
+		if enter, hitBox := r.IntersectRect3(n.bounds); !hitBox || enter > t {
+			continue
+		}
+		if n.count > 0 {
+			for _, i := range b.items[n.start : n.start+n.count] {
+				if enter, hitBox := r.IntersectRect3(b.bounds[i]); !hitBox || enter > t {
+					continue
+				}
+				if it, hitItem := hit(i); hitItem && it <= t {
+					item, t, ok = i, it, true
+				}
+			}
+			continue
+		}
+
+		// Visit the nearer child first, such that the farther one is more
+		// likely to be culled.
+		near, far := index+1, n.right
+		if rayDist(r, b.nodes[far].bounds) < rayDist(r, b.nodes[near].bounds) {
+			near, far = far, near
+		}
+		stack = append(stack, far, near)
+	}
+	if !ok {
+		return -1, 0, false
+	}
+	return
+}
+
+// rayDist returns the distance along the ray at which it enters the box, or
+// infinity if it misses it.
+func rayDist(r lmath.Ray, box lmath.Rect3) float64 {
+	if t, ok := r.IntersectRect3(box); ok {
+		return t
+	}
+	return math.Inf(1)
+}
+
+// sweptBounds returns the bounding box of the shape over the given motion.
+func sweptBounds(s Convex, motion lmath.Vec3) lmath.Rect3 {
+	b := s.Bounds()
+	return b.Union(b.Add(motion))
+}
@@ -0,0 +1,272 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package collision
+
+import (
+	"math"
+
+	"azul3d.org/engine/lmath"
+)
+
+// Geometry is static geometry that a character can collide with. TriMesh
+// implements it, and so can a game's own world representation (e.g. one
+// which combines several meshes).
+type Geometry interface {
+	// Sweep moves the shape s along the motion vector, and returns the first
+	// contact with the geometry (see TriMesh.Sweep).
+	Sweep(s Convex, motion lmath.Vec3) (hit Hit, tri int, ok bool)
+
+	// Contacts calls fn with each contact between the shape s and the
+	// geometry, until fn returns false (see TriMesh.Contacts).
+	Contacts(s Convex, fn func(tri int, c Contact) bool)
+}
+
+// HitFlags describes which sides of a character hit something while moving.
+type HitFlags uint8
+
+const (
+	// HitSides is set when the character hit a wall or a slope too steep to
+	// walk on.
+	HitSides HitFlags = 1 << iota
+
+	// HitAbove is set when the character hit a ceiling.
+	HitAbove
+
+	// HitBelow is set when the character hit walkable ground.
+	HitBelow
+)
+
+// Character is a kinematic character controller: a capsule which moves
+// through static geometry by sliding along it, climbs steps and walkable
+// slopes, and keeps track of whether it stands on the ground.
+//
+// The character does not have a velocity of it's own, instead the game moves
+// it each frame by a motion vector (e.g. the sum of the player's input and
+// gravity times the frame's delta time).
+type Character struct {
+	// The position of the character's feet, i.e. the bottom of the capsule.
+	Position lmath.Vec3
+
+	// The unit up vector of the world.
+	Up lmath.Vec3
+
+	// The height of the capsule (including both caps) and it's radius. The
+	// height must be at least twice the radius.
+	Height, Radius float64
+
+	// The height of the tallest step which the character climbs when moving
+	// along the ground, and also the distance below the character at which
+	// it sticks to the ground when walking down slopes and stairs.
+	StepOffset float64
+
+	// The steepest angle of the ground (in radians) which the character can
+	// walk on. It slides down steeper slopes, and cannot climb them.
+	MaxSlope float64
+
+	// The distance kept between the character and the geometry, which avoids
+	// starting each sweep while touching it.
+	SkinWidth float64
+
+	// The maximum number of surfaces the character slides along per phase of
+	// a move.
+	MaxSlides int
+
+	grounded     bool
+	groundNormal lmath.Vec3
+}
+
+// NewCharacter returns a new character with the given height and radius, and
+// the default properties:
+//
+//  Up: lmath.Vec3{Z: 1}
+//  StepOffset: 0.3
+//  MaxSlope: lmath.Radians(45)
+//  SkinWidth: 0.01
+//  MaxSlides: 4
+//
+func NewCharacter(height, radius float64) *Character {
+	return &Character{
+		Up:         lmath.Vec3{Z: 1},
+		Height:     height,
+		Radius:     radius,
+		StepOffset: 0.3,
+		MaxSlope:   lmath.Radians(45),
+		SkinWidth:  0.01,
+		MaxSlides:  4,
+	}
+}
+
+// Capsule returns the capsule of the character at it's current position.
+func (c *Character) Capsule() *Capsule {
+	return &Capsule{
+		A:      c.Position.Add(c.Up.MulScalar(c.Radius)),
+		B:      c.Position.Add(c.Up.MulScalar(c.Height - c.Radius)),
+		Radius: c.Radius,
+	}
+}
+
+// OnGround tells if the character stood on walkable ground at the end of the
+// last move.
+func (c *Character) OnGround() bool {
+	return c.grounded
+}
+
+// GroundNormal returns the normal of the ground the character stands on, or
+// the zero vector if it is not on the ground.
+func (c *Character) GroundNormal() lmath.Vec3 {
+	return c.groundNormal
+}
+
+// walkable tells if the surface with the given normal is walkable.
+func (c *Character) walkable(n lmath.Vec3) bool {
+	return n.Dot(c.Up) >= math.Cos(c.MaxSlope)-lmath.EPSILON
+}
+
+// Move moves the character through the geometry by the given motion vector,
+// and returns which sides of it hit something.
+//
+// The move happens in three phases: the character first moves up (by it's
+// step offset if it is on the ground, and by the upward part of the motion),
+// then sideways sliding along walls, and finally back down by as much as it
+// moved up plus the downward part of the motion. If stepping up does not
+// land the character on walkable ground (e.g. it would climb onto a steep
+// slope), the move is made again without stepping. Any overlap with the
+// geometry at the start of the move (e.g. after teleporting) is resolved
+// first.
+func (c *Character) Move(g Geometry, motion lmath.Vec3) (flags HitFlags) {
+	c.depenetrate(g)
+
+	vertical := motion.Dot(c.Up)
+	horizontal := motion.Sub(c.Up.MulScalar(vertical))
+	wasGrounded := c.grounded && vertical <= 0
+
+	if wasGrounded && c.StepOffset > 0 && horizontal.LengthSq() > 0 {
+		start := c.Position
+		flags = c.move(g, vertical, horizontal, c.StepOffset)
+		if c.grounded {
+			return
+		}
+		c.Position = start
+	}
+	flags = c.move(g, vertical, horizontal, 0)
+
+	// Stick to the ground when walking down slopes and stairs, but never
+	// snap down when walking off a ledge.
+	if wasGrounded && !c.grounded && c.StepOffset > 0 {
+		snap := c.Up.MulScalar(-c.StepOffset)
+		if hit, _, ok := g.Sweep(c.Capsule(), snap); ok && c.walkable(hit.Normal) {
+			c.sweep(g, snap)
+			c.grounded = true
+			c.groundNormal = hit.Normal
+			flags |= HitBelow
+		}
+	}
+	return
+}
+
+// move performs the three phases of a move (see Move), stepping up by the
+// given height.
+func (c *Character) move(g Geometry, vertical float64, horizontal lmath.Vec3, step float64) (flags HitFlags) {
+	// Move up, by the step and the upward part of the motion.
+	up := step + math.Max(vertical, 0)
+	if up > 0 {
+		if hit, ok := c.sweep(g, c.Up.MulScalar(up)); ok {
+			step = math.Min(step, up*hit.Time)
+			if vertical > 0 {
+				flags |= HitAbove
+			}
+		}
+	}
+
+	// Move sideways, sliding along walls.
+	flags |= c.slide(g, horizontal, true)
+
+	// Move down, by the step and the downward part of the motion.
+	c.grounded = false
+	c.groundNormal = lmath.Vec3Zero
+	down := step + math.Max(-vertical, 0)
+	flags |= c.slide(g, c.Up.MulScalar(-down), false)
+	return
+}
+
+// sweep moves the character along the motion vector, up to the first hit
+// with the geometry (keeping the skin width from it), which it returns.
+func (c *Character) sweep(g Geometry, motion lmath.Vec3) (Hit, bool) {
+	hit, _, ok := g.Sweep(c.Capsule(), motion)
+	if !ok {
+		c.Position = c.Position.Add(motion)
+		return hit, false
+	}
+	c.Position = c.Position.Add(motion.MulScalar(hit.Time)).Add(hit.Normal.MulScalar(c.SkinWidth))
+	return hit, true
+}
+
+// slide moves the character along the motion vector, sliding along the
+// surfaces it hits. Moving sideways, it treats steep slopes as vertical walls
+// (such that it cannot climb them); moving down, it stops on walkable ground
+// and slides down steep slopes.
+func (c *Character) slide(g Geometry, motion lmath.Vec3, sideways bool) (flags HitFlags) {
+	var planes []lmath.Vec3
+	for i := 0; i < c.MaxSlides && motion.LengthSq() > lmath.EPSILON*lmath.EPSILON; i++ {
+		hit, ok := c.sweep(g, motion)
+		if !ok {
+			return
+		}
+		n := hit.Normal
+		switch {
+		case c.walkable(n):
+			flags |= HitBelow
+			if !sideways {
+				c.grounded = true
+				c.groundNormal = n
+				return
+			}
+		case n.Dot(c.Up) < -lmath.EPSILON:
+			flags |= HitAbove
+		default:
+			flags |= HitSides
+			if sideways {
+				if flat, ok := n.Sub(c.Up.MulScalar(n.Dot(c.Up))).Normalized(); ok {
+					n = flat
+				}
+			}
+		}
+
+		// Slide the rest of the motion along the plane that was hit, or along
+		// the crease of two planes when sliding into a corner.
+		rest := motion.MulScalar(1 - hit.Time)
+		motion = rest.Sub(n.MulScalar(rest.Dot(n)))
+		for _, p := range planes {
+			if motion.Dot(p) >= 0 {
+				continue
+			}
+			crease, ok := p.Cross(n).Normalized()
+			if !ok {
+				return
+			}
+			motion = crease.MulScalar(crease.Dot(rest))
+			break
+		}
+		planes = append(planes, n)
+	}
+	return
+}
+
+// depenetrate pushes the character out of any geometry it overlaps.
+func (c *Character) depenetrate(g Geometry) {
+	for i := 0; i < c.MaxSlides; i++ {
+		var deepest Contact
+		g.Contacts(c.Capsule(), func(tri int, ct Contact) bool {
+			if ct.Depth > deepest.Depth {
+				deepest = ct
+			}
+			return true
+		})
+		if deepest.Depth <= 0 {
+			return
+		}
+		c.Position = c.Position.Sub(deepest.Normal.MulScalar(deepest.Depth + c.SkinWidth))
+	}
+}
@@ -0,0 +1,424 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package collision
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"azul3d.org/engine/lmath"
+)
+
+func sphere(x, y, z, r float64) *Sphere {
+	return &Sphere{lmath.Sphere{Center: lmath.Vec3{X: x, Y: y, Z: z}, Radius: r}}
+}
+
+func box(x, y, z, half float64) *Box {
+	return &Box{lmath.OBB{
+		Center:   lmath.Vec3{X: x, Y: y, Z: z},
+		Axes:     [3]lmath.Vec3{{X: 1}, {Y: 1}, {Z: 1}},
+		HalfSize: lmath.Vec3{X: half, Y: half, Z: half},
+	}}
+}
+
+// rotatedBox returns a box rotated by 45 degrees about the Z axis.
+func rotatedBox(x, y, z, half float64) *Box {
+	b := box(x, y, z, half)
+	s := math.Sqrt2 / 2
+	b.Axes = [3]lmath.Vec3{{X: s, Y: s}, {X: -s, Y: s}, {Z: 1}}
+	return b
+}
+
+var distanceTests = []struct {
+	name string
+	a, b Convex
+	dist float64
+}{
+	{"spheres", sphere(0, 0, 0, 1), sphere(3, 0, 0, 1), 1},
+	{"overlapping spheres", sphere(0, 0, 0, 1), sphere(1, 0, 0, 1), 0},
+	{"boxes", box(0, 0, 0, 1), box(3, 0.5, 0, 1), 1},
+	{"box edge", box(0, 0, 0, 1), rotatedBox(3, 0, 0, 1), 2 - math.Sqrt2},
+	{"sphere and box corner", box(0, 0, 0, 1), sphere(2, 2, 2, 1), math.Sqrt(3) - 1},
+	{"capsule and box", &Capsule{A: lmath.Vec3{X: -5, Z: 2}, B: lmath.Vec3{X: 5, Z: 2}, Radius: 0.5}, box(0, 0, 0, 1), 0.5},
+	{"triangle and sphere", &Triangle{lmath.Vec3{X: -1, Y: -1}, lmath.Vec3{X: 1, Y: -1}, lmath.Vec3{Y: 1}}, sphere(0, 0, 3, 1), 2},
+	{"hull and box", &Hull{[]lmath.Vec3{{X: 5}, {X: 6}, {X: 5, Y: 1}, {X: 5, Z: 1}}}, box(0, 0, 0, 1), 4},
+	{"box inside box", box(0, 0, 0, 2), box(0.5, 0, 0, 1), 0},
+}
+
+func TestDistance(t *testing.T) {
+	for _, tst := range distanceTests {
+		dist, pa, pb := Distance(tst.a, tst.b)
+		if !lmath.AlmostEqual(dist, tst.dist, 1e-6) {
+			t.Errorf("%s: distance = %v, want %v", tst.name, dist, tst.dist)
+			continue
+		}
+		if dist > 0 && !lmath.AlmostEqual(pb.Sub(pa).Length(), dist, 1e-6) {
+			t.Errorf("%s: closest points %v and %v are not %v apart", tst.name, pa, pb, dist)
+		}
+		if got := Intersect(tst.a, tst.b); got != (tst.dist == 0) {
+			t.Errorf("%s: Intersect = %v", tst.name, got)
+		}
+	}
+}
+
+var penetrateTests = []struct {
+	name   string
+	a, b   Convex
+	normal lmath.Vec3
+	depth  float64
+}{
+	{"spheres", sphere(0, 0, 0, 1), sphere(1.5, 0, 0, 1), lmath.Vec3{X: 1}, 0.5},
+	{"boxes", box(0, 0, 0, 1), box(0, 0, 1.75, 1), lmath.Vec3{Z: 1}, 0.25},
+	{"box and rotated box", box(0, 0, 0, 1), rotatedBox(-2.2, 0, 0, 1), lmath.Vec3{X: -1}, math.Sqrt2 - 1.2},
+	{"sphere inside box", box(0, 0, 0, 1), sphere(0, 0.8, 0, 0.5), lmath.Vec3{Y: 1}, 0.7},
+	{"capsule inside box", box(0, 0, 0, 1), &Capsule{A: lmath.Vec3{X: -0.5, Y: -0.9}, B: lmath.Vec3{X: 0.5, Y: -0.9}, Radius: 0.25}, lmath.Vec3{Y: -1}, 0.35},
+}
+
+func TestPenetrate(t *testing.T) {
+	for _, tst := range penetrateTests {
+		c, ok := Penetrate(tst.a, tst.b)
+		if !ok {
+			t.Errorf("%s: no penetration", tst.name)
+			continue
+		}
+		if !c.Normal.AlmostEquals(tst.normal, 1e-3) || !lmath.AlmostEqual(c.Depth, tst.depth, 1e-3) {
+			t.Errorf("%s: normal %v depth %v, want %v and %v", tst.name, c.Normal, c.Depth, tst.normal, tst.depth)
+		}
+		if d := c.PointA.Sub(c.PointB).Dot(c.Normal); !lmath.AlmostEqual(d, c.Depth, 1e-3) {
+			t.Errorf("%s: points %v and %v are %v apart along the normal, want %v", tst.name, c.PointA, c.PointB, d, c.Depth)
+		}
+	}
+	if _, ok := Penetrate(sphere(0, 0, 0, 1), sphere(3, 0, 0, 1)); ok {
+		t.Error("separate spheres penetrate")
+	}
+}
+
+func TestPenetrateRandom(t *testing.T) {
+	// Moving the second shape by the contact normal times the depth must
+	// (just) separate the shapes.
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		a := rotatedBox(0, 0, 0, 1)
+		var b Convex = box(r.Float64()*3-1.5, r.Float64()*3-1.5, r.Float64()*3-1.5, 0.5+r.Float64())
+		if i%2 == 1 {
+			b = &Capsule{
+				A:      lmath.Vec3{X: r.Float64()*3 - 1.5, Y: r.Float64()*3 - 1.5},
+				B:      lmath.Vec3{Y: r.Float64()*3 - 1.5, Z: r.Float64()*3 - 1.5},
+				Radius: 0.1 + r.Float64()*0.5,
+			}
+		}
+		c, ok := Penetrate(a, b)
+		if ok != Intersect(a, b) {
+			t.Fatalf("%d: Penetrate = %v, Intersect disagrees", i, ok)
+		}
+		if !ok {
+			continue
+		}
+		if c.Depth < 0 || !lmath.AlmostEqual(c.Normal.Length(), 1, 1e-9) {
+			t.Fatalf("%d: contact %+v", i, c)
+		}
+		moved := translated{Convex: b, offset: c.Normal.MulScalar(c.Depth + 1e-3)}
+		if dist, _, _ := Distance(a, moved); dist <= 0 || dist > 2e-3 {
+			t.Fatalf("%d: %v apart after resolving %+v", i, dist, c)
+		}
+	}
+}
+
+// floor returns a 20x20 meter floor at Z=0, made up of a grid of triangles.
+func floor() *TriMesh {
+	var vertices []lmath.Vec3
+	var indices []uint32
+	const n = 10
+	for y := 0; y <= n; y++ {
+		for x := 0; x <= n; x++ {
+			vertices = append(vertices, lmath.Vec3{X: float64(x*2 - n), Y: float64(y*2 - n)})
+		}
+	}
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			i := uint32(y*(n+1) + x)
+			indices = append(indices, i, i+1, i+n+2, i, i+n+2, i+n+1)
+		}
+	}
+	return NewTriMesh(vertices, indices)
+}
+
+func TestSweep(t *testing.T) {
+	wall := &Triangle{lmath.Vec3{X: 5, Y: -5, Z: -5}, lmath.Vec3{X: 5, Y: 5, Z: -5}, lmath.Vec3{X: 5, Z: 5}}
+	capsule := &Capsule{A: lmath.Vec3{Z: -1}, B: lmath.Vec3{Z: 1}, Radius: 0.5}
+
+	hit, ok := Sweep(capsule, lmath.Vec3{X: 9}, wall)
+	if !ok || !lmath.AlmostEqual(hit.Time, 0.5, 1e-4) {
+		t.Fatalf("Sweep = %+v, %v; want a hit at time 0.5", hit, ok)
+	}
+	if !hit.Normal.AlmostEquals(lmath.Vec3{X: -1}, 1e-4) || !lmath.AlmostEqual(hit.Point.X, 5, 1e-4) {
+		t.Fatalf("Sweep hit point %v normal %v", hit.Point, hit.Normal)
+	}
+
+	if _, ok := Sweep(capsule, lmath.Vec3{X: 4}, wall); ok {
+		t.Fatal("Sweep hit, the motion is too short")
+	}
+	if _, ok := Sweep(capsule, lmath.Vec3{X: -9}, wall); ok {
+		t.Fatal("Sweep hit, moving away")
+	}
+
+	// Touching the wall, sliding along it or moving away does not hit, but
+	// moving into it does.
+	touching := &Capsule{A: lmath.Vec3{X: 4.5, Z: -1}, B: lmath.Vec3{X: 4.5, Z: 1}, Radius: 0.5}
+	if _, ok := Sweep(touching, lmath.Vec3{Y: 1}, wall); ok {
+		t.Fatal("Sweep hit, sliding along the wall")
+	}
+	if hit, ok := Sweep(touching, lmath.Vec3{X: 1}, wall); !ok || hit.Time != 0 {
+		t.Fatalf("Sweep = %+v, %v; want a hit at time 0", hit, ok)
+	}
+}
+
+func TestTriMesh(t *testing.T) {
+	m := floor()
+	if len(m.Triangles) != 200 {
+		t.Fatalf("got %d triangles, want 200", len(m.Triangles))
+	}
+
+	tt, tri, ok := m.RayCast(lmath.Ray{Origin: lmath.Vec3{X: 1.5, Y: -2.5, Z: 10}, Dir: lmath.Vec3{Z: -1}})
+	if !ok || !lmath.AlmostEqual(tt, 10, 1e-9) {
+		t.Fatalf("RayCast = %v, %v, %v; want a hit at 10", tt, tri, ok)
+	}
+	if _, _, ok := m.RayCast(lmath.Ray{Origin: lmath.Vec3{X: 20, Z: 10}, Dir: lmath.Vec3{Z: -1}}); ok {
+		t.Fatal("RayCast hit outside of the floor")
+	}
+
+	ball := sphere(0.3, 0.7, 3, 1)
+	hit, tri, ok := m.Sweep(ball, lmath.Vec3{X: 1, Z: -4})
+	if !ok || !lmath.AlmostEqual(hit.Time, 0.5, 1e-4) || !hit.Normal.AlmostEquals(lmath.Vec3{Z: 1}, 1e-4) {
+		t.Fatalf("Sweep = %+v, %v, %v; want a hit at time 0.5 facing up", hit, tri, ok)
+	}
+	if !m.Triangles[tri].Bounds().Inset(-1e-9).Contains(hit.Point) {
+		t.Fatalf("hit point %v is not on triangle %d", hit.Point, tri)
+	}
+
+	// A ball sunk into the floor has contacts pushing it straight up.
+	var contacts int
+	m.Contacts(sphere(1, 1, 0.75, 1), func(tri int, c Contact) bool {
+		contacts++
+		if !c.Normal.AlmostEquals(lmath.Vec3{Z: -1}, 1e-3) || !lmath.AlmostEqual(c.Depth, 0.25, 1e-3) {
+			t.Errorf("triangle %d contact normal %v depth %v", tri, c.Normal, c.Depth)
+		}
+		return true
+	})
+	if contacts == 0 {
+		t.Fatal("no contacts")
+	}
+}
+
+func TestBVH(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	vec := func(scale float64) lmath.Vec3 {
+		return lmath.Vec3{X: r.Float64() * scale, Y: r.Float64() * scale, Z: r.Float64() * scale}
+	}
+	bounds := make([]lmath.Rect3, 500)
+	for i := range bounds {
+		min := vec(100)
+		bounds[i] = lmath.Rect3{Min: min, Max: min.Add(vec(5))}
+	}
+	bvh := NewBVH(bounds)
+	if bvh.Len() != len(bounds) {
+		t.Fatalf("Len() = %d, want %d", bvh.Len(), len(bounds))
+	}
+
+	for q := 0; q < 100; q++ {
+		min := vec(100)
+		query := lmath.Rect3{Min: min, Max: min.Add(vec(20))}
+		found := map[int]bool{}
+		bvh.Query(query, func(i int) bool {
+			found[i] = true
+			return true
+		})
+		for i, b := range bounds {
+			if touches(b, query) != found[i] {
+				t.Fatalf("query %v: item %d found = %v", query, i, found[i])
+			}
+		}
+
+		ray := lmath.Ray{Origin: vec(100), Dir: vec(1).SubScalar(0.5)}
+		hit := func(i int) (float64, bool) { return ray.IntersectRect3(bounds[i]) }
+		item, tt, ok := bvh.RayCast(ray, math.Inf(1), hit)
+		want, wantT, wantOk := -1, math.Inf(1), false
+		for i := range bounds {
+			if it, hitOk := hit(i); hitOk && it < wantT {
+				want, wantT, wantOk = i, it, true
+			}
+		}
+		if ok != wantOk || (ok && tt != wantT) {
+			t.Fatalf("RayCast = %v, %v, %v; want %v, %v, %v", item, tt, ok, want, wantT, wantOk)
+		}
+	}
+}
+
+// quad returns the vertices of the two triangles of the quad (a, b, c, d).
+func quad(a, b, c, d lmath.Vec3) []lmath.Vec3 {
+	return []lmath.Vec3{a, b, c, a, c, d}
+}
+
+// level returns a mesh of the floor and the given boxes (with their minimum
+// and maximum corners) and quads.
+func level(boxes [][2]lmath.Vec3, quads ...[]lmath.Vec3) *TriMesh {
+	var vertices []lmath.Vec3
+	for _, tri := range floor().Triangles {
+		vertices = append(vertices, tri.A, tri.B, tri.C)
+	}
+	for _, b := range boxes {
+		v := func(x, y, z int) lmath.Vec3 {
+			return lmath.Vec3{X: b[x].X, Y: b[y].Y, Z: b[z].Z}
+		}
+		vertices = append(vertices, quad(v(0, 0, 0), v(1, 0, 0), v(1, 1, 0), v(0, 1, 0))...)
+		vertices = append(vertices, quad(v(0, 0, 1), v(1, 0, 1), v(1, 1, 1), v(0, 1, 1))...)
+		vertices = append(vertices, quad(v(0, 0, 0), v(1, 0, 0), v(1, 0, 1), v(0, 0, 1))...)
+		vertices = append(vertices, quad(v(0, 1, 0), v(1, 1, 0), v(1, 1, 1), v(0, 1, 1))...)
+		vertices = append(vertices, quad(v(0, 0, 0), v(0, 1, 0), v(0, 1, 1), v(0, 0, 1))...)
+		vertices = append(vertices, quad(v(1, 0, 0), v(1, 1, 0), v(1, 1, 1), v(1, 0, 1))...)
+	}
+	for _, q := range quads {
+		vertices = append(vertices, q...)
+	}
+	return NewTriMesh(vertices, nil)
+}
+
+// walk moves the character n times by the given motion plus some gravity.
+func walk(c *Character, g Geometry, motion lmath.Vec3, n int) (flags HitFlags) {
+	for i := 0; i < n; i++ {
+		flags |= c.Move(g, motion.Add(lmath.Vec3{Z: -0.05}))
+	}
+	return
+}
+
+func TestCharacterFall(t *testing.T) {
+	c := NewCharacter(1.8, 0.4)
+	c.Position = lmath.Vec3{Z: 2}
+	flags := c.Move(floor(), lmath.Vec3{Z: -5})
+	if flags != HitBelow || !c.OnGround() || !c.GroundNormal().AlmostEquals(c.Up, 1e-6) {
+		t.Fatalf("flags %v, on ground %v, ground normal %v", flags, c.OnGround(), c.GroundNormal())
+	}
+	if !lmath.AlmostEqual(c.Position.Z, c.SkinWidth, 1e-4) {
+		t.Fatalf("landed at %v", c.Position)
+	}
+
+	// Jumping leaves the ground, falling off a ledge does not snap down.
+	c.Move(floor(), lmath.Vec3{Z: 1})
+	if c.OnGround() || !lmath.AlmostEqual(c.Position.Z, 1+c.SkinWidth, 1e-4) {
+		t.Fatalf("jumped to %v, on ground %v", c.Position, c.OnGround())
+	}
+	m := level([][2]lmath.Vec3{{{X: -2, Y: -2}, {X: 2, Y: 2, Z: 1}}})
+	c.Position = lmath.Vec3{X: 1.5, Z: 1.5}
+	c.Move(m, lmath.Vec3{Z: -1})
+	walk(c, m, lmath.Vec3{X: 0.5}, 3)
+	if c.OnGround() || c.Position.Z < 0.5 {
+		t.Fatalf("walked off the ledge to %v, on ground %v", c.Position, c.OnGround())
+	}
+
+	// A character stuck in the floor is pushed out of it.
+	c.Position = lmath.Vec3{X: 5, Z: -0.3}
+	c.Move(floor(), lmath.Vec3{Z: -0.1})
+	if !c.OnGround() || !lmath.AlmostEqual(c.Position.Z, c.SkinWidth, 1e-3) {
+		t.Fatalf("pushed out of the floor to %v, on ground %v", c.Position, c.OnGround())
+	}
+}
+
+func TestCharacterWall(t *testing.T) {
+	m := level([][2]lmath.Vec3{{{X: 3, Y: -10}, {X: 4, Y: 10, Z: 3}}})
+	c := NewCharacter(1.8, 0.4)
+	c.Move(m, lmath.Vec3{Z: -1})
+
+	if flags := walk(c, m, lmath.Vec3{X: 1}, 5); flags != HitSides|HitBelow {
+		t.Fatalf("flags %v", flags)
+	}
+	if !lmath.AlmostEqual(c.Position.X, 3-c.Radius-c.SkinWidth, 1e-3) || !c.OnGround() {
+		t.Fatalf("walked into the wall to %v, on ground %v", c.Position, c.OnGround())
+	}
+
+	// Walking diagonally into the wall slides along it.
+	walk(c, m, lmath.Vec3{X: 1, Y: 1}, 2)
+	if !lmath.AlmostEqual(c.Position.Y, 2, 1e-3) || c.Position.X > 3-c.Radius {
+		t.Fatalf("slid along the wall to %v", c.Position)
+	}
+}
+
+func TestCharacterStep(t *testing.T) {
+	m := level([][2]lmath.Vec3{
+		{{X: 2, Y: -10}, {X: 4, Y: 10, Z: 0.2}},
+		{{X: 4, Y: -10}, {X: 6, Y: 10, Z: 0.7}},
+	})
+	c := NewCharacter(1.8, 0.4)
+	c.Move(m, lmath.Vec3{Z: -1})
+
+	// The first step is climbed, the second is too tall.
+	walk(c, m, lmath.Vec3{X: 0.25}, 20)
+	if !c.OnGround() || !lmath.AlmostEqual(c.Position.Z, 0.2+c.SkinWidth, 1e-3) {
+		t.Fatalf("climbed the step to %v, on ground %v", c.Position, c.OnGround())
+	}
+	if !lmath.AlmostEqual(c.Position.X, 4-c.Radius-c.SkinWidth, 1e-3) {
+		t.Fatalf("walked into the tall step to %v", c.Position)
+	}
+
+	// Walking back down the step sticks to the ground.
+	walk(c, m, lmath.Vec3{X: -0.25}, 12)
+	if !c.OnGround() || !lmath.AlmostEqual(c.Position.Z, c.SkinWidth, 1e-3) {
+		t.Fatalf("walked down the step to %v, on ground %v", c.Position, c.OnGround())
+	}
+}
+
+func TestCharacterSlope(t *testing.T) {
+	ramp := func(x, degrees float64) []lmath.Vec3 {
+		rise := 10 * math.Tan(lmath.Radians(degrees))
+		return quad(
+			lmath.Vec3{X: x, Y: -10},
+			lmath.Vec3{X: x + 10, Y: -10, Z: rise},
+			lmath.Vec3{X: x + 10, Y: 10, Z: rise},
+			lmath.Vec3{X: x, Y: 10},
+		)
+	}
+	for _, tst := range []struct {
+		degrees float64
+		climbs  bool
+	}{
+		{30, true},
+		{60, false},
+	} {
+		m := level(nil, ramp(1, tst.degrees))
+		c := NewCharacter(1.8, 0.4)
+		c.Move(m, lmath.Vec3{Z: -1})
+		walk(c, m, lmath.Vec3{X: 0.25}, 20)
+		if climbs := c.Position.Z > 1; climbs != tst.climbs || !c.OnGround() {
+			t.Errorf("%v degree slope: walked to %v, on ground %v", tst.degrees, c.Position, c.OnGround())
+			continue
+		}
+		if tst.climbs && !c.GroundNormal().AlmostEquals(lmath.Vec3{X: -0.5, Z: math.Sqrt(3) / 2}, 1e-3) {
+			t.Errorf("%v degree slope: ground normal %v", tst.degrees, c.GroundNormal())
+		}
+		if !tst.climbs {
+			// Placed on the steep slope, the character slides down it.
+			c.Position = lmath.Vec3{X: 4, Z: 3*math.Sqrt(3) + 0.1}
+			walk(c, m, lmath.Vec3{Z: -0.5}, 50)
+			if c.Position.X > 1 || !c.OnGround() {
+				t.Errorf("%v degree slope: slid down to %v, on ground %v", tst.degrees, c.Position, c.OnGround())
+			}
+		}
+	}
+}
+
+func BenchmarkDistance(b *testing.B) {
+	a, c := box(0, 0, 0, 1), rotatedBox(3, 0.5, 0.5, 1)
+	for i := 0; i < b.N; i++ {
+		Distance(a, c)
+	}
+}
+
+func BenchmarkTriMeshSweep(b *testing.B) {
+	m := floor()
+	capsule := &Capsule{A: lmath.Vec3{Z: 0.6}, B: lmath.Vec3{Z: 1.6}, Radius: 0.5}
+	for i := 0; i < b.N; i++ {
+		m.Sweep(capsule, lmath.Vec3{X: 0.1, Z: -0.2})
+	}
+}
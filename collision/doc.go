@@ -0,0 +1,35 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package collision implements 3D collision detection.
+//
+// Convex shapes (spheres, capsules, boxes, triangles and convex hulls) are
+// described by their support function, which the GJK algorithm uses to find
+// the distance and closest points between any two of them (see Distance and
+// Intersect), and the EPA algorithm uses to find the penetration depth and
+// normal of overlapping ones (see Penetrate).
+//
+// Spheres and capsules are handled as a point and a line segment with a
+// margin (radius) around them, which keeps GJK exact and fast for them.
+//
+// Sweep finds the time of impact of a convex shape moving along a straight
+// line against another one, which is the basis of the kinematic character
+// controller, Character:
+//
+//  level := collision.NewTriMesh(vertices, indices)
+//  player := collision.NewCharacter(1.8, 0.4)
+//  player.Position = spawn
+//  ...
+//  if player.OnGround() {
+//      velocity.Z = 0
+//  }
+//  velocity.Z -= gravity * dt
+//  player.Move(level, velocity.MulScalar(dt))
+//
+// A TriMesh stores static level geometry in a bounding volume hierarchy (see
+// BVH), such that only the triangles near a query are tested.
+//
+// The package depends only on lmath, such that it can be used standalone or
+// with any physics engine.
+package collision // import "azul3d.org/engine/collision"
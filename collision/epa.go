@@ -0,0 +1,252 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package collision
+
+import (
+	"math"
+
+	"azul3d.org/engine/lmath"
+)
+
+const (
+	// The maximum number of EPA iterations, which is reached with curved
+	// shapes (e.g. deeply overlapping spheres), whose Minkowski difference
+	// is only ever approximated by the polytope.
+	epaMaxIterations = 64
+
+	// The tolerance of the EPA penetration depth.
+	epaTolerance = 1e-6
+)
+
+// Contact describes the penetration of two shapes.
+type Contact struct {
+	// The contact normal, pointing from the first shape towards the second.
+	// Moving the second shape by Normal*Depth (or the first by the negated
+	// amount) separates them.
+	Normal lmath.Vec3
+
+	// The penetration depth.
+	Depth float64
+
+	// The deepest points of each shape inside of the other.
+	PointA, PointB lmath.Vec3
+}
+
+// Penetrate returns the contact between the two shapes, if they intersect
+// (or touch).
+func Penetrate(a, b Convex) (c Contact, ok bool) {
+	s, d := gjk(a, b)
+	margin := a.Margin() + b.Margin()
+	if d > margin {
+		return c, false
+	}
+	if d > 0 {
+		// Only the margins overlap, the closest points of the cores give the
+		// contact exactly.
+		pa, pb := s.points()
+		c.Normal = pb.Sub(pa).DivScalar(d)
+		c.Depth = margin - d
+		c.PointA = pa.Add(c.Normal.MulScalar(a.Margin()))
+		c.PointB = pb.Sub(c.Normal.MulScalar(b.Margin()))
+		return c, true
+	}
+	return epa(a, b, s), true
+}
+
+// epaFace is a triangular face of the EPA polytope, with it's outward unit
+// normal and distance from the origin.
+type epaFace struct {
+	v    [3]int
+	n    lmath.Vec3
+	dist float64
+}
+
+// epaEdge is a directed edge of the EPA polytope.
+type epaEdge struct {
+	a, b int
+}
+
+// epaPolytope is a convex polytope inside of the Minkowski difference of two
+// shapes, containing the origin.
+type epaPolytope struct {
+	a, b     Convex
+	vertices []simplexVertex
+	faces    []epaFace
+	interior lmath.Vec3
+}
+
+// support returns the vertex of the Minkowski difference of the shapes,
+// including their margins, furthest along the given direction.
+func (p *epaPolytope) support(dir lmath.Vec3) simplexVertex {
+	pa := supportMargin(p.a, dir)
+	pb := supportMargin(p.b, dir.MulScalar(-1))
+	return simplexVertex{a: pa, b: pb, w: pa.Sub(pb)}
+}
+
+// addFace adds the face with the given vertices, oriented away from the
+// interior point of the polytope. It reports false for degenerate faces.
+func (p *epaPolytope) addFace(i, j, k int) bool {
+	a, b, c := p.vertices[i].w, p.vertices[j].w, p.vertices[k].w
+	n, ok := b.Sub(a).Cross(c.Sub(a)).Normalized()
+	if !ok {
+		return false
+	}
+	if n.Dot(a.Sub(p.interior)) < 0 {
+		n = n.MulScalar(-1)
+		j, k = k, j
+	}
+	p.faces = append(p.faces, epaFace{
+		v:    [3]int{i, j, k},
+		n:    n,
+		dist: n.Dot(a),
+	})
+	return true
+}
+
+// expandSimplex grows the GJK simplex (which contains the origin) into a
+// tetrahedron, using supports of the shapes including their margins.
+func (p *epaPolytope) expandSimplex() bool {
+	axes := []lmath.Vec3{
+		{X: 1}, {X: -1}, {Y: 1}, {Y: -1}, {Z: 1}, {Z: -1},
+	}
+	if len(p.vertices) == 1 {
+		for _, axis := range axes {
+			v := p.support(axis)
+			if v.w.Sub(p.vertices[0].w).LengthSq() > gjkEpsilon {
+				p.vertices = append(p.vertices, v)
+				break
+			}
+		}
+	}
+	if len(p.vertices) == 2 {
+		// Search the directions perpendicular to the segment.
+		d, _ := p.vertices[1].w.Sub(p.vertices[0].w).Normalized()
+		for _, axis := range axes {
+			perp := d.Cross(axis)
+			if perp.LengthSq() < 0.1 {
+				continue
+			}
+			v := p.support(perp)
+			if d.Cross(v.w.Sub(p.vertices[0].w)).LengthSq() > gjkEpsilon {
+				p.vertices = append(p.vertices, v)
+				break
+			}
+		}
+	}
+	if len(p.vertices) == 3 {
+		a, b, c := p.vertices[0].w, p.vertices[1].w, p.vertices[2].w
+		n := b.Sub(a).Cross(c.Sub(a))
+		for _, dir := range []lmath.Vec3{n, n.MulScalar(-1)} {
+			v := p.support(dir)
+			if math.Abs(n.Dot(v.w.Sub(a))) > gjkEpsilon {
+				p.vertices = append(p.vertices, v)
+				break
+			}
+		}
+	}
+	return len(p.vertices) == 4
+}
+
+// epa runs the expanding polytope algorithm on two intersecting shapes,
+// starting with the final GJK simplex.
+func epa(a, b Convex, s simplex) Contact {
+	p := &epaPolytope{a: a, b: b}
+	p.vertices = append(p.vertices, s.v[:s.n]...)
+	if !p.expandSimplex() {
+		// The shapes only touch, with no volume of overlap.
+		pa, pb := s.points()
+		n, ok := b.Bounds().Center().Sub(a.Bounds().Center()).Normalized()
+		if !ok {
+			n = lmath.Vec3{Z: 1}
+		}
+		return Contact{Normal: n, PointA: pa, PointB: pb}
+	}
+	for _, v := range p.vertices {
+		p.interior = p.interior.Add(v.w.MulScalar(0.25))
+	}
+	p.addFace(0, 1, 2)
+	p.addFace(0, 3, 1)
+	p.addFace(0, 2, 3)
+	p.addFace(1, 3, 2)
+
+	var closest epaFace
+	for i := 0; i < epaMaxIterations && len(p.faces) > 0; i++ {
+		// Find the face closest to the origin.
+		ci := 0
+		for j, f := range p.faces {
+			if f.dist < p.faces[ci].dist {
+				ci = j
+			}
+		}
+		closest = p.faces[ci]
+
+		v := p.support(closest.n)
+		if v.w.Dot(closest.n)-closest.dist < epaTolerance {
+			// The face is on the boundary of the Minkowski difference.
+			break
+		}
+
+		// Remove the faces visible from the new vertex, keeping the edges on
+		// their horizon (those not shared by two removed faces).
+		var horizon []epaEdge
+		kept := p.faces[:0]
+		for _, f := range p.faces {
+			if f.n.Dot(v.w.Sub(p.vertices[f.v[0]].w)) <= 0 {
+				kept = append(kept, f)
+				continue
+			}
+			for e := 0; e < 3; e++ {
+				edge := epaEdge{f.v[e], f.v[(e+1)%3]}
+				shared := false
+				for h, other := range horizon {
+					if other.a == edge.b && other.b == edge.a {
+						horizon = append(horizon[:h], horizon[h+1:]...)
+						shared = true
+						break
+					}
+				}
+				if !shared {
+					horizon = append(horizon, edge)
+				}
+			}
+		}
+		p.faces = kept
+
+		// Connect the horizon to the new vertex.
+		p.vertices = append(p.vertices, v)
+		vi := len(p.vertices) - 1
+		for _, e := range horizon {
+			p.addFace(e.a, e.b, vi)
+		}
+	}
+
+	// The contact points are the barycentric combination of the support
+	// points at the projection of the origin onto the closest face.
+	a0 := p.vertices[closest.v[0]]
+	a1 := p.vertices[closest.v[1]]
+	a2 := p.vertices[closest.v[2]]
+	u, v, w := barycentric(closest.n.MulScalar(closest.dist), a0.w, a1.w, a2.w)
+	return Contact{
+		Normal: closest.n,
+		Depth:  closest.dist,
+		PointA: a0.a.MulScalar(u).Add(a1.a.MulScalar(v)).Add(a2.a.MulScalar(w)),
+		PointB: a0.b.MulScalar(u).Add(a1.b.MulScalar(v)).Add(a2.b.MulScalar(w)),
+	}
+}
+
+// barycentric returns the barycentric coordinates of the point p (which is
+// on the plane of the triangle) with respect to the triangle (a, b, c).
+func barycentric(p, a, b, c lmath.Vec3) (u, v, w float64) {
+	v0, v1, v2 := b.Sub(a), c.Sub(a), p.Sub(a)
+	d00, d01, d11 := v0.Dot(v0), v0.Dot(v1), v1.Dot(v1)
+	d20, d21 := v2.Dot(v0), v2.Dot(v1)
+	denom := d00*d11 - d01*d01
+	if math.Abs(denom) < gjkEpsilon {
+		return 1, 0, 0
+	}
+	v = (d11*d20 - d01*d21) / denom
+	w = (d00*d21 - d01*d20) / denom
+	return 1 - v - w, v, w
+}
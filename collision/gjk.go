@@ -0,0 +1,270 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package collision
+
+import (
+	"math"
+
+	"azul3d.org/engine/lmath"
+)
+
+const (
+	// The maximum number of GJK iterations, which is only reached due to
+	// floating point rounding.
+	gjkMaxIterations = 64
+
+	// The relative tolerance of the GJK distance.
+	gjkTolerance = 1e-10
+
+	// The squared distance below which the origin is considered to be inside
+	// of the simplex.
+	gjkEpsilon = 1e-16
+)
+
+// simplexVertex is a vertex of the Minkowski difference of two shapes, w =
+// a - b, along with the support points of each shape that produced it.
+type simplexVertex struct {
+	a, b, w lmath.Vec3
+}
+
+// support returns the vertex of the Minkowski difference of the cores of
+// two shapes furthest along the given direction.
+func support(a, b Convex, dir lmath.Vec3) simplexVertex {
+	pa := a.Support(dir)
+	pb := b.Support(dir.MulScalar(-1))
+	return simplexVertex{a: pa, b: pb, w: pa.Sub(pb)}
+}
+
+// simplex is a GJK simplex of up to four vertices, along with the
+// barycentric coordinates of the point closest to the origin.
+type simplex struct {
+	v    [4]simplexVertex
+	bary [4]float64
+	n    int
+}
+
+// closest finds the point of the simplex closest to the origin, and reduces
+// the simplex to the smallest sub-simplex containing it.
+func (s *simplex) closest() lmath.Vec3 {
+	switch s.n {
+	case 1:
+		s.bary[0] = 1
+	case 2:
+		s.closestSegment(0, 1)
+	case 3:
+		s.closestTriangle(0, 1, 2)
+	case 4:
+		s.closestTetrahedron()
+	}
+
+	// Drop the vertices that do not contribute to the closest point.
+	var p lmath.Vec3
+	n := 0
+	for i := 0; i < s.n; i++ {
+		if s.bary[i] > 0 {
+			s.v[n], s.bary[n] = s.v[i], s.bary[i]
+			p = p.Add(s.v[n].w.MulScalar(s.bary[n]))
+			n++
+		}
+	}
+	s.n = n
+	return p
+}
+
+// set sets the barycentric coordinates of the given vertices, and zeroes
+// the others.
+func (s *simplex) set(idx []int, bary []float64) {
+	s.bary = [4]float64{}
+	for i, j := range idx {
+		s.bary[j] = bary[i]
+	}
+}
+
+// closestSegment finds the point of the segment (i, j) closest to the origin.
+func (s *simplex) closestSegment(i, j int) {
+	a, b := s.v[i].w, s.v[j].w
+	ab := b.Sub(a)
+	t := -a.Dot(ab)
+	if t <= 0 {
+		s.set([]int{i}, []float64{1})
+		return
+	}
+	denom := ab.Dot(ab)
+	if t >= denom {
+		s.set([]int{j}, []float64{1})
+		return
+	}
+	t /= denom
+	s.set([]int{i, j}, []float64{1 - t, t})
+}
+
+// closestTriangle finds the point of the triangle (i, j, k) closest to the
+// origin, see Christer Ericson's "Real-Time Collision Detection".
+func (s *simplex) closestTriangle(i, j, k int) {
+	a, b, c := s.v[i].w, s.v[j].w, s.v[k].w
+	ab, ac := b.Sub(a), c.Sub(a)
+
+	ap := a.MulScalar(-1)
+	d1, d2 := ab.Dot(ap), ac.Dot(ap)
+	if d1 <= 0 && d2 <= 0 {
+		s.set([]int{i}, []float64{1})
+		return
+	}
+
+	bp := b.MulScalar(-1)
+	d3, d4 := ab.Dot(bp), ac.Dot(bp)
+	if d3 >= 0 && d4 <= d3 {
+		s.set([]int{j}, []float64{1})
+		return
+	}
+
+	vc := d1*d4 - d3*d2
+	if vc <= 0 && d1 >= 0 && d3 <= 0 {
+		v := d1 / (d1 - d3)
+		s.set([]int{i, j}, []float64{1 - v, v})
+		return
+	}
+
+	cp := c.MulScalar(-1)
+	d5, d6 := ab.Dot(cp), ac.Dot(cp)
+	if d6 >= 0 && d5 <= d6 {
+		s.set([]int{k}, []float64{1})
+		return
+	}
+
+	vb := d5*d2 - d1*d6
+	if vb <= 0 && d2 >= 0 && d6 <= 0 {
+		w := d2 / (d2 - d6)
+		s.set([]int{i, k}, []float64{1 - w, w})
+		return
+	}
+
+	va := d3*d6 - d5*d4
+	if va <= 0 && d4-d3 >= 0 && d5-d6 >= 0 {
+		w := (d4 - d3) / ((d4 - d3) + (d5 - d6))
+		s.set([]int{j, k}, []float64{1 - w, w})
+		return
+	}
+
+	denom := 1 / (va + vb + vc)
+	v, w := vb*denom, vc*denom
+	s.set([]int{i, j, k}, []float64{1 - v - w, v, w})
+}
+
+// outsidePlane tells if the origin is on the opposite side of the plane
+// through a, b and c from d, or if the tetrahedron is degenerate.
+func outsidePlane(a, b, c, d lmath.Vec3) bool {
+	n := b.Sub(a).Cross(c.Sub(a))
+	signP := a.MulScalar(-1).Dot(n)
+	signD := d.Sub(a).Dot(n)
+	if signD*signD < gjkEpsilon {
+		return true
+	}
+	return signP*signD < 0
+}
+
+// closestTetrahedron finds the point of the tetrahedron closest to the
+// origin.
+func (s *simplex) closestTetrahedron() {
+	faces := [4][4]int{
+		{0, 1, 2, 3},
+		{0, 2, 3, 1},
+		{0, 3, 1, 2},
+		{1, 3, 2, 0},
+	}
+	var (
+		best     [4]float64
+		bestDist = math.Inf(1)
+		inside   = true
+	)
+	for _, f := range faces {
+		if !outsidePlane(s.v[f[0]].w, s.v[f[1]].w, s.v[f[2]].w, s.v[f[3]].w) {
+			continue
+		}
+		inside = false
+		s.closestTriangle(f[0], f[1], f[2])
+		var p lmath.Vec3
+		for i := 0; i < 4; i++ {
+			p = p.Add(s.v[i].w.MulScalar(s.bary[i]))
+		}
+		if d := p.LengthSq(); d < bestDist {
+			best, bestDist = s.bary, d
+		}
+	}
+	if inside {
+		// The origin is inside of the tetrahedron, the exact barycentric
+		// coordinates are not needed as the shapes intersect.
+		s.bary = [4]float64{0.25, 0.25, 0.25, 0.25}
+		return
+	}
+	s.bary = best
+}
+
+// points returns the closest points on the cores of each shape, as
+// described by the barycentric coordinates of the simplex.
+func (s *simplex) points() (pa, pb lmath.Vec3) {
+	for i := 0; i < s.n; i++ {
+		pa = pa.Add(s.v[i].a.MulScalar(s.bary[i]))
+		pb = pb.Add(s.v[i].b.MulScalar(s.bary[i]))
+	}
+	return
+}
+
+// gjk runs the GJK algorithm on the cores of two shapes, returning the final
+// simplex, and the distance between the cores (zero if they intersect).
+func gjk(a, b Convex) (s simplex, dist float64) {
+	dir := a.Bounds().Center().Sub(b.Bounds().Center())
+	if dir.LengthSq() < gjkEpsilon {
+		dir = lmath.Vec3{X: 1}
+	}
+	s.v[0] = support(a, b, dir.MulScalar(-1))
+	s.n = 1
+
+	for i := 0; i < gjkMaxIterations; i++ {
+		v := s.closest()
+		vv := v.LengthSq()
+		if vv < gjkEpsilon || s.n == 4 {
+			// The origin is inside of the simplex.
+			return s, 0
+		}
+
+		w := support(a, b, v.MulScalar(-1))
+		if vv-v.Dot(w.w) <= gjkTolerance*vv {
+			// No significant progress towards the origin, converged.
+			return s, math.Sqrt(vv)
+		}
+		for j := 0; j < s.n; j++ {
+			if s.v[j].w.AlmostEquals(w.w, lmath.EPSILON) {
+				// Already in the simplex, converged.
+				return s, math.Sqrt(vv)
+			}
+		}
+		s.v[s.n] = w
+		s.n++
+	}
+	return s, s.closest().Length()
+}
+
+// Distance returns the distance between the two shapes, and the closest
+// points on each of them. If the shapes intersect, the distance is zero and
+// the points are undefined (see Penetrate).
+func Distance(a, b Convex) (dist float64, pa, pb lmath.Vec3) {
+	s, d := gjk(a, b)
+	pa, pb = s.points()
+	margin := a.Margin() + b.Margin()
+	if d <= margin {
+		return 0, pa, pb
+	}
+	n := pb.Sub(pa).DivScalar(d)
+	pa = pa.Add(n.MulScalar(a.Margin()))
+	pb = pb.Sub(n.MulScalar(b.Margin()))
+	return d - margin, pa, pb
+}
+
+// Intersect tells if the two shapes intersect (or touch).
+func Intersect(a, b Convex) bool {
+	_, d := gjk(a, b)
+	return d <= a.Margin()+b.Margin()
+}
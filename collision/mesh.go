@@ -0,0 +1,100 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package collision
+
+import (
+	"math"
+
+	"azul3d.org/engine/lmath"
+)
+
+// TriMesh is a static triangle mesh (e.g. level geometry), whose triangles
+// are stored in a BVH for fast queries.
+//
+// A TriMesh is immutable once built, and is safe for use concurrently by
+// multiple goroutines.
+type TriMesh struct {
+	// The triangles of the mesh, in world space.
+	Triangles []Triangle
+
+	bvh *BVH
+}
+
+// NewTriMesh returns a new triangle mesh with the given vertices, and
+// indices of each triangle's three vertices. If indices is nil, each three
+// consecutive vertices form a triangle.
+func NewTriMesh(vertices []lmath.Vec3, indices []uint32) *TriMesh {
+	m := &TriMesh{}
+	if indices == nil {
+		for i := 0; i+2 < len(vertices); i += 3 {
+			m.Triangles = append(m.Triangles, Triangle{vertices[i], vertices[i+1], vertices[i+2]})
+		}
+	} else {
+		for i := 0; i+2 < len(indices); i += 3 {
+			m.Triangles = append(m.Triangles, Triangle{
+				vertices[indices[i]],
+				vertices[indices[i+1]],
+				vertices[indices[i+2]],
+			})
+		}
+	}
+	bounds := make([]lmath.Rect3, len(m.Triangles))
+	for i := range m.Triangles {
+		bounds[i] = m.Triangles[i].Bounds()
+	}
+	m.bvh = NewBVH(bounds)
+	return m
+}
+
+// Bounds returns the bounding box of the mesh.
+func (m *TriMesh) Bounds() lmath.Rect3 {
+	return m.bvh.Bounds()
+}
+
+// Query calls fn with the index of each triangle whose bounding box overlaps
+// the given one, until fn returns false.
+func (m *TriMesh) Query(r lmath.Rect3, fn func(tri int) bool) {
+	m.bvh.Query(r, fn)
+}
+
+// RayCast returns the distance along the ray (in units of the length of it's
+// direction) to the nearest triangle it intersects, from either side, and
+// the index of the triangle.
+func (m *TriMesh) RayCast(r lmath.Ray) (t float64, tri int, ok bool) {
+	tri, t, ok = m.bvh.RayCast(r, math.Inf(1), func(i int) (float64, bool) {
+		tr := &m.Triangles[i]
+		t, _, _, ok := r.IntersectTriangle(tr.A, tr.B, tr.C)
+		return t, ok
+	})
+	return
+}
+
+// Sweep moves the shape s along the given motion vector, and returns the
+// first contact with the mesh, and the index of the triangle that was hit.
+// See the Sweep function for details.
+func (m *TriMesh) Sweep(s Convex, motion lmath.Vec3) (hit Hit, tri int, ok bool) {
+	tri = -1
+	m.bvh.Query(sweptBounds(s, motion), func(i int) bool {
+		h, hitOk := Sweep(s, motion, &m.Triangles[i])
+		if hitOk && (!ok || h.Time < hit.Time) {
+			hit, tri, ok = h, i, true
+		}
+		return true
+	})
+	return
+}
+
+// Contacts calls fn with the index of each triangle of the mesh which the
+// shape s intersects, and the contact between them (see Penetrate), until
+// fn returns false. Pushing the shape out along each negated contact normal
+// resolves the overlap (e.g. for a character controller).
+func (m *TriMesh) Contacts(s Convex, fn func(tri int, c Contact) bool) {
+	m.bvh.Query(s.Bounds(), func(i int) bool {
+		if c, ok := Penetrate(s, &m.Triangles[i]); ok {
+			return fn(i, c)
+		}
+		return true
+	})
+}
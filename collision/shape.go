@@ -0,0 +1,199 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package collision
+
+import (
+	"math"
+
+	"azul3d.org/engine/lmath"
+)
+
+// Convex is a convex shape in world space, described by a core shape and a
+// margin around it: a sphere is a point with a margin of it's radius, for
+// example.
+type Convex interface {
+	// Support returns the point of the core shape furthest along the given
+	// direction (which is not necessarily a unit vector).
+	Support(dir lmath.Vec3) lmath.Vec3
+
+	// Margin returns the radius of the margin around the core shape.
+	Margin() float64
+
+	// Bounds returns the bounding box of the shape, including it's margin.
+	Bounds() lmath.Rect3
+}
+
+// supportMargin returns the point of the shape, including it's margin,
+// furthest along the given direction.
+func supportMargin(s Convex, dir lmath.Vec3) lmath.Vec3 {
+	p := s.Support(dir)
+	if m := s.Margin(); m > 0 {
+		if n, ok := dir.Normalized(); ok {
+			p = p.Add(n.MulScalar(m))
+		}
+	}
+	return p
+}
+
+// Sphere is a solid sphere.
+type Sphere struct {
+	lmath.Sphere
+}
+
+// Support implements the Convex interface.
+func (s *Sphere) Support(dir lmath.Vec3) lmath.Vec3 {
+	return s.Center
+}
+
+// Margin implements the Convex interface.
+func (s *Sphere) Margin() float64 {
+	return s.Radius
+}
+
+// Bounds implements the Convex interface.
+func (s *Sphere) Bounds() lmath.Rect3 {
+	return s.Rect3()
+}
+
+// Capsule is a solid capsule: a cylinder with hemispherical caps, i.e. the
+// line segment from A to B with a radius around it.
+type Capsule struct {
+	A, B   lmath.Vec3
+	Radius float64
+}
+
+// Support implements the Convex interface.
+func (c *Capsule) Support(dir lmath.Vec3) lmath.Vec3 {
+	if dir.Dot(c.B.Sub(c.A)) > 0 {
+		return c.B
+	}
+	return c.A
+}
+
+// Margin implements the Convex interface.
+func (c *Capsule) Margin() float64 {
+	return c.Radius
+}
+
+// Bounds implements the Convex interface.
+func (c *Capsule) Bounds() lmath.Rect3 {
+	return lmath.Rect3{Min: c.A.Min(c.B), Max: c.A.Max(c.B)}.Inset(-c.Radius)
+}
+
+// Box is a solid oriented box.
+type Box struct {
+	lmath.OBB
+}
+
+// Support implements the Convex interface.
+func (b *Box) Support(dir lmath.Vec3) lmath.Vec3 {
+	p := b.Center
+	half := [3]float64{b.HalfSize.X, b.HalfSize.Y, b.HalfSize.Z}
+	for i, axis := range b.Axes {
+		if dir.Dot(axis) >= 0 {
+			p = p.Add(axis.MulScalar(half[i]))
+		} else {
+			p = p.Sub(axis.MulScalar(half[i]))
+		}
+	}
+	return p
+}
+
+// Margin implements the Convex interface.
+func (b *Box) Margin() float64 {
+	return 0
+}
+
+// Bounds implements the Convex interface.
+func (b *Box) Bounds() lmath.Rect3 {
+	return b.Rect3()
+}
+
+// Triangle is a triangle, which has no volume.
+type Triangle struct {
+	A, B, C lmath.Vec3
+}
+
+// Support implements the Convex interface.
+func (t *Triangle) Support(dir lmath.Vec3) lmath.Vec3 {
+	a, b, c := dir.Dot(t.A), dir.Dot(t.B), dir.Dot(t.C)
+	switch {
+	case a >= b && a >= c:
+		return t.A
+	case b >= c:
+		return t.B
+	}
+	return t.C
+}
+
+// Margin implements the Convex interface.
+func (t *Triangle) Margin() float64 {
+	return 0
+}
+
+// Bounds implements the Convex interface.
+func (t *Triangle) Bounds() lmath.Rect3 {
+	return lmath.Rect3{
+		Min: t.A.Min(t.B).Min(t.C),
+		Max: t.A.Max(t.B).Max(t.C),
+	}
+}
+
+// Normal returns the unit normal of the triangle, facing the side from which
+// the vertices are in counter-clockwise order.
+func (t *Triangle) Normal() lmath.Vec3 {
+	n, _ := t.B.Sub(t.A).Cross(t.C.Sub(t.A)).Normalized()
+	return n
+}
+
+// Hull is the convex hull of a set of points.
+type Hull struct {
+	Points []lmath.Vec3
+}
+
+// Support implements the Convex interface.
+func (h *Hull) Support(dir lmath.Vec3) lmath.Vec3 {
+	best, bestDot := lmath.Vec3Zero, math.Inf(-1)
+	for _, p := range h.Points {
+		if d := dir.Dot(p); d > bestDot {
+			best, bestDot = p, d
+		}
+	}
+	return best
+}
+
+// Margin implements the Convex interface.
+func (h *Hull) Margin() float64 {
+	return 0
+}
+
+// Bounds implements the Convex interface.
+func (h *Hull) Bounds() lmath.Rect3 {
+	if len(h.Points) == 0 {
+		return lmath.Rect3{}
+	}
+	b := lmath.Rect3{Min: h.Points[0], Max: h.Points[0]}
+	for _, p := range h.Points[1:] {
+		b.Min = b.Min.Min(p)
+		b.Max = b.Max.Max(p)
+	}
+	return b
+}
+
+// translated is a convex shape translated by an offset, for sweeping.
+type translated struct {
+	Convex
+	offset lmath.Vec3
+}
+
+// Support implements the Convex interface.
+func (t translated) Support(dir lmath.Vec3) lmath.Vec3 {
+	return t.Convex.Support(dir).Add(t.offset)
+}
+
+// Bounds implements the Convex interface.
+func (t translated) Bounds() lmath.Rect3 {
+	return t.Convex.Bounds().Add(t.offset)
+}
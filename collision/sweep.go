@@ -0,0 +1,73 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package collision
+
+import "azul3d.org/engine/lmath"
+
+const (
+	// The maximum number of conservative advancement iterations.
+	sweepMaxIterations = 32
+
+	// The distance at which a swept shape is considered to hit another.
+	sweepTolerance = 1e-5
+)
+
+// Hit describes the first contact of a swept shape.
+type Hit struct {
+	// The time of impact, as a fraction of the motion in the range of [0,
+	// 1].
+	Time float64
+
+	// The contact point on the surface that was hit.
+	Point lmath.Vec3
+
+	// The unit surface normal at the contact point, facing the swept shape.
+	Normal lmath.Vec3
+}
+
+// Sweep moves the shape a along the given motion vector, and returns the
+// first contact with the shape b. If the shapes touch or intersect at the
+// start of the motion, the time of impact is zero and the normal is that of
+// the minimum translation out of b (see Penetrate).
+//
+// Shapes that are moving apart (or along each other's surface) never hit,
+// such that a shape resting on a surface can slide along or leave it.
+func Sweep(a Convex, motion lmath.Vec3, b Convex) (hit Hit, ok bool) {
+	// Conservative advancement: the shapes cannot touch before a moves by the
+	// current distance along the separating direction, so advance by that
+	// much until they do.
+	var t float64
+	for i := 0; i < sweepMaxIterations; i++ {
+		moved := translated{Convex: a, offset: motion.MulScalar(t)}
+		dist, pa, pb := Distance(moved, b)
+		if dist <= sweepTolerance {
+			if i == 0 {
+				// Touching or intersecting at the start, unless moving out.
+				n, _ := pa.Sub(pb).Normalized()
+				point := pb
+				if c, ok := Penetrate(moved, b); ok {
+					n, point = c.Normal.MulScalar(-1), c.PointB
+				}
+				if n.Dot(motion) >= -sweepTolerance*motion.Length() {
+					return hit, false
+				}
+				return Hit{Time: 0, Point: point, Normal: n}, true
+			}
+			hit.Point = pb
+			return hit, true
+		}
+		n := pb.Sub(pa).DivScalar(dist)
+		closing := motion.Dot(n)
+		if closing <= 0 {
+			return hit, false
+		}
+		t += dist / closing
+		if t > 1 {
+			return hit, false
+		}
+		hit = Hit{Time: t, Point: pb, Normal: n.MulScalar(-1)}
+	}
+	return hit, true
+}
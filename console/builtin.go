@@ -0,0 +1,133 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package console
+
+import (
+	"fmt"
+	"strings"
+)
+
+// builtins are the commands registered with every new console.
+var builtins = []*Command{
+	{
+		Name:     "help",
+		Help:     "help [name] - lists commands and variables, or describes one",
+		Run:      help,
+		Complete: CompleteNames,
+	},
+	{
+		Name: "set",
+		Help: "set <name> <value> - sets a variable",
+		Run: func(c *Console, args []string) error {
+			if len(args) < 2 {
+				return ErrUsage
+			}
+			v := c.Var(args[0])
+			if v == nil {
+				return fmt.Errorf("unknown variable %q", args[0])
+			}
+			return v.Set(strings.Join(args[1:], " "))
+		},
+		Complete: CompleteVars,
+	},
+	{
+		Name: "toggle",
+		Help: "toggle <name> - toggles a boolean variable",
+		Run: func(c *Console, args []string) error {
+			if len(args) != 1 {
+				return ErrUsage
+			}
+			v := c.Var(args[0])
+			if v == nil {
+				return fmt.Errorf("unknown variable %q", args[0])
+			}
+			if v.Bool() {
+				return v.Set("0")
+			}
+			return v.Set("1")
+		},
+		Complete: CompleteVars,
+	},
+	{
+		Name: "reset",
+		Help: "reset <name> - resets a variable to it's default value",
+		Run: func(c *Console, args []string) error {
+			if len(args) != 1 {
+				return ErrUsage
+			}
+			v := c.Var(args[0])
+			if v == nil {
+				return fmt.Errorf("unknown variable %q", args[0])
+			}
+			v.Reset()
+			return nil
+		},
+		Complete: CompleteVars,
+	},
+	{
+		Name: "echo",
+		Help: "echo [args...] - prints it's arguments",
+		Run: func(c *Console, args []string) error {
+			c.Println(strings.Join(args, " "))
+			return nil
+		},
+	},
+	{
+		Name: "exec",
+		Help: "exec <file> - executes a command file",
+		Run: func(c *Console, args []string) error {
+			if len(args) != 1 {
+				return ErrUsage
+			}
+			return c.ExecFile(args[0])
+		},
+	},
+	{
+		Name: "clear",
+		Help: "clear - clears the output",
+		Run: func(c *Console, args []string) error {
+			c.Clear()
+			return nil
+		},
+	},
+	{
+		Name: "history",
+		Help: "history - prints the command history",
+		Run: func(c *Console, args []string) error {
+			for i, line := range c.History() {
+				c.Printf("%4d  %s", i+1, line)
+			}
+			return nil
+		},
+	},
+}
+
+// help implements the help command.
+func help(c *Console, args []string) error {
+	switch len(args) {
+	case 0:
+		c.Println("commands:")
+		for _, name := range c.Commands() {
+			c.Printf("  %s", c.Command(name).Help)
+		}
+		c.Println("variables:")
+		for _, name := range c.Vars() {
+			v := c.Var(name)
+			c.Printf("  %s = %q - %s", name, v.String(), v.Help)
+		}
+		return nil
+	case 1:
+		if cmd := c.Command(args[0]); cmd != nil {
+			c.Println(cmd.Help)
+			return nil
+		}
+		if v := c.Var(args[0]); v != nil {
+			c.Printf("%s (%s, default %q) - %s", v.Name, v.Kind, v.Default, v.Help)
+			return nil
+		}
+		return fmt.Errorf("unknown command or variable %q", args[0])
+	}
+	return ErrUsage
+}
@@ -0,0 +1,438 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package console
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Command is a command which may be executed by a console.
+type Command struct {
+	// The name the command is invoked by, which must not contain spaces.
+	Name string
+
+	// A short description of the command, printed by the help command.
+	Help string
+
+	// Run is called with the arguments of the command (excluding it's name)
+	// each time it is executed. Errors returned are printed to the console.
+	Run func(c *Console, args []string) error
+
+	// Complete, if non-nil, returns the completions of the last of the given
+	// arguments (which may be an empty string), for tab completion.
+	Complete func(c *Console, args []string) []string
+}
+
+// ErrUsage may be returned by a command when it's arguments are invalid, such
+// that the console prints the help of the command.
+var ErrUsage = errors.New("invalid usage")
+
+// MaxDepth is the maximum depth of nested command file execution, which guards
+// against command files which execute themselves.
+const MaxDepth = 16
+
+// Console is a set of commands and variables, executing lines of text which
+// invoke them. It's methods are safe to call from multiple goroutines
+// concurrently, and commands may execute other lines themselves.
+type Console struct {
+	// Open opens the named command file for ExecFile, it is os.Open by
+	// default. It may be changed to e.g. open files of a virtual file system.
+	Open func(name string) (io.ReadCloser, error)
+
+	// The maximum number of output lines and history entries retained, older
+	// ones are discarded.
+	MaxLines, MaxHistory int
+
+	access   sync.RWMutex
+	commands map[string]*Command
+	vars     map[string]*Var
+
+	outAccess sync.Mutex
+	lines     []string
+	history   []string
+	depth     int
+}
+
+// Register registers the given command, replacing any existing command of the
+// same name. It panics if the name is empty, contains spaces, or is the name
+// of a variable.
+func (c *Console) Register(cmd *Command) {
+	c.checkName(cmd.Name)
+	c.access.Lock()
+	defer c.access.Unlock()
+	if _, ok := c.vars[cmd.Name]; ok {
+		panic(fmt.Sprintf("console: Register: %q is a variable", cmd.Name))
+	}
+	c.commands[cmd.Name] = cmd
+}
+
+// Unregister removes the named command, if any.
+func (c *Console) Unregister(name string) {
+	c.access.Lock()
+	delete(c.commands, name)
+	c.access.Unlock()
+}
+
+// Command returns the named command, or nil if there is none.
+func (c *Console) Command(name string) *Command {
+	c.access.RLock()
+	defer c.access.RUnlock()
+	return c.commands[name]
+}
+
+// Commands returns the names of all registered commands, sorted.
+func (c *Console) Commands() []string {
+	c.access.RLock()
+	defer c.access.RUnlock()
+	names := make([]string, 0, len(c.commands))
+	for name := range c.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkName panics if the given command or variable name is invalid.
+func (c *Console) checkName(name string) {
+	if name == "" || strings.ContainsAny(name, " \t\r\n;\"") {
+		panic(fmt.Sprintf("console: invalid name %q", name))
+	}
+}
+
+// Printf formats according to a format specifier and prints the result to the
+// output of the console. Each line of the result becomes a separate output
+// line.
+func (c *Console) Printf(format string, args ...interface{}) {
+	c.print(fmt.Sprintf(format, args...))
+}
+
+// Println formats it's arguments like fmt.Sprint and prints the result to the
+// output of the console.
+func (c *Console) Println(args ...interface{}) {
+	c.print(fmt.Sprint(args...))
+}
+
+// Write implements io.Writer by printing p to the output of the console, such
+// that e.g. a log.Logger may write to it.
+func (c *Console) Write(p []byte) (int, error) {
+	c.print(string(p))
+	return len(p), nil
+}
+
+func (c *Console) print(s string) {
+	s = strings.TrimSuffix(s, "\n")
+	c.outAccess.Lock()
+	c.lines = append(c.lines, strings.Split(s, "\n")...)
+	if c.MaxLines > 0 && len(c.lines) > c.MaxLines {
+		c.lines = append(c.lines[:0], c.lines[len(c.lines)-c.MaxLines:]...)
+	}
+	c.outAccess.Unlock()
+}
+
+// Lines returns a copy of the output lines of the console, oldest first.
+func (c *Console) Lines() []string {
+	c.outAccess.Lock()
+	defer c.outAccess.Unlock()
+	return append([]string(nil), c.lines...)
+}
+
+// Clear clears the output of the console.
+func (c *Console) Clear() {
+	c.outAccess.Lock()
+	c.lines = nil
+	c.outAccess.Unlock()
+}
+
+// History returns a copy of the lines entered by the user (see Submit),
+// oldest first.
+func (c *Console) History() []string {
+	c.outAccess.Lock()
+	defer c.outAccess.Unlock()
+	return append([]string(nil), c.history...)
+}
+
+// Submit echoes the given line (as entered by the user) to the output, adds it
+// to the history, and executes it. Errors are printed rather than returned.
+func (c *Console) Submit(line string) {
+	line = strings.TrimSpace(line)
+	c.Printf("> %s", line)
+	if line == "" {
+		return
+	}
+	c.outAccess.Lock()
+	if n := len(c.history); n == 0 || c.history[n-1] != line {
+		c.history = append(c.history, line)
+		if c.MaxHistory > 0 && len(c.history) > c.MaxHistory {
+			c.history = append(c.history[:0], c.history[len(c.history)-c.MaxHistory:]...)
+		}
+	}
+	c.outAccess.Unlock()
+	if err := c.Exec(line); err != nil {
+		c.Println(err)
+	}
+}
+
+// Exec executes each command in the given line, stopping at the first which
+// fails. Empty commands are ignored.
+func (c *Console) Exec(line string) error {
+	for _, args := range split(line) {
+		if len(args) == 0 {
+			continue
+		}
+		if err := c.run(args[0], args[1:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// run runs the named command or variable with the given arguments.
+func (c *Console) run(name string, args []string) error {
+	c.access.RLock()
+	cmd := c.commands[name]
+	v := c.vars[name]
+	c.access.RUnlock()
+
+	switch {
+	case cmd != nil:
+		err := cmd.Run(c, args)
+		if err == ErrUsage {
+			return fmt.Errorf("%s: usage: %s", name, cmd.Help)
+		} else if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		return nil
+	case v != nil:
+		if len(args) == 0 {
+			c.Printf("%s = %q (default %q)", v.Name, v.String(), v.Default)
+			return nil
+		}
+		if err := v.Set(strings.Join(args, " ")); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("%s: unknown command", name)
+}
+
+// ExecReader executes each line read from r, as a command file. Errors are
+// printed and execution continues with the next line. An error is returned
+// only if reading fails.
+func (c *Console) ExecReader(r io.Reader) error {
+	c.outAccess.Lock()
+	if c.depth >= MaxDepth {
+		c.outAccess.Unlock()
+		return errors.New("command files nested too deeply")
+	}
+	c.depth++
+	c.outAccess.Unlock()
+	defer func() {
+		c.outAccess.Lock()
+		c.depth--
+		c.outAccess.Unlock()
+	}()
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if err := c.Exec(scanner.Text()); err != nil {
+			c.Println(err)
+		}
+	}
+	return scanner.Err()
+}
+
+// ExecFile opens the named command file (see the Open field) and executes it
+// using ExecReader.
+func (c *Console) ExecFile(name string) error {
+	f, err := c.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.ExecReader(f)
+}
+
+// Complete returns the completions of the given (partial) line: whole lines
+// which the line may be completed to, sorted. If the line has no arguments the
+// names of commands and variables are completed, otherwise the last argument
+// is completed by the command's Complete function.
+func (c *Console) Complete(line string) []string {
+	// Only the last command of the line is completed.
+	prefix := ""
+	if i := strings.LastIndex(line, ";"); i >= 0 {
+		prefix, line = line[:i+1]+" ", strings.TrimLeft(line[i+1:], " \t")
+	}
+	cmds := split(line)
+	var args []string
+	if len(cmds) > 0 {
+		args = cmds[len(cmds)-1]
+	}
+	if line == "" || strings.HasSuffix(line, " ") || strings.HasSuffix(line, "\t") {
+		args = append(args, "")
+	}
+
+	var matches []string
+	if len(args) <= 1 {
+		partial := ""
+		if len(args) == 1 {
+			partial = args[0]
+		}
+		c.access.RLock()
+		for name := range c.commands {
+			if strings.HasPrefix(name, partial) {
+				matches = append(matches, prefix+name+" ")
+			}
+		}
+		for name := range c.vars {
+			if strings.HasPrefix(name, partial) {
+				matches = append(matches, prefix+name+" ")
+			}
+		}
+		c.access.RUnlock()
+		sort.Strings(matches)
+		return matches
+	}
+
+	c.access.RLock()
+	cmd := c.commands[args[0]]
+	v := c.vars[args[0]]
+	c.access.RUnlock()
+
+	var opts []string
+	switch {
+	case cmd != nil && cmd.Complete != nil:
+		opts = cmd.Complete(c, args[1:])
+	case v != nil && len(args) == 2:
+		opts = v.completions()
+	}
+	last := args[len(args)-1]
+	head := prefix + join(args[:len(args)-1]) + " "
+	for _, opt := range opts {
+		if strings.HasPrefix(opt, last) {
+			matches = append(matches, head+quote(opt))
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// CompleteNames is a Command.Complete function which completes the names of
+// commands and variables, e.g. for commands which take one as an argument.
+func CompleteNames(c *Console, args []string) []string {
+	if len(args) != 1 {
+		return nil
+	}
+	c.access.RLock()
+	defer c.access.RUnlock()
+	var names []string
+	for name := range c.commands {
+		names = append(names, name)
+	}
+	for name := range c.vars {
+		names = append(names, name)
+	}
+	return names
+}
+
+// CompleteVars is a Command.Complete function which completes the names of
+// variables.
+func CompleteVars(c *Console, args []string) []string {
+	if len(args) != 1 {
+		return nil
+	}
+	return c.Vars()
+}
+
+// quote quotes s if it is empty or contains characters which would split it
+// into multiple arguments.
+func quote(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t;\"") || strings.Contains(s, "//") {
+		return `"` + strings.Replace(s, `"`, `\"`, -1) + `"`
+	}
+	return s
+}
+
+// join joins the given arguments into a line, quoting them where needed.
+func join(args []string) string {
+	q := make([]string, len(args))
+	for i, a := range args {
+		q[i] = quote(a)
+	}
+	return strings.Join(q, " ")
+}
+
+// split splits the given line into commands (separated by semicolons), and
+// each command into it's arguments. Arguments are separated by spaces or tabs
+// and may be double quoted (within which a backslash escapes the following
+// character). The remainder of the line after "//" is ignored.
+func split(line string) [][]string {
+	var (
+		cmds    [][]string
+		args    []string
+		arg     []rune
+		inArg   bool
+		inQuote bool
+		escape  bool
+	)
+	endArg := func() {
+		if inArg {
+			args = append(args, string(arg))
+			arg, inArg = arg[:0], false
+		}
+	}
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case escape:
+			arg = append(arg, r)
+			escape = false
+		case inQuote && r == '\\':
+			escape = true
+		case r == '"':
+			inQuote = !inQuote
+			inArg = true
+		case inQuote:
+			arg = append(arg, r)
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '/':
+			i = len(runes)
+		case r == ' ' || r == '\t' || r == '\r' || r == '\n':
+			endArg()
+		case r == ';':
+			endArg()
+			cmds = append(cmds, args)
+			args = nil
+		default:
+			arg = append(arg, r)
+			inArg = true
+		}
+	}
+	endArg()
+	return append(cmds, args)
+}
+
+// New returns a new console with only the built in commands registered.
+func New() *Console {
+	c := &Console{
+		Open: func(name string) (io.ReadCloser, error) {
+			return os.Open(name)
+		},
+		MaxLines:   1024,
+		MaxHistory: 256,
+		commands:   make(map[string]*Command),
+		vars:       make(map[string]*Var),
+	}
+	for _, cmd := range builtins {
+		c.Register(cmd)
+	}
+	return c
+}
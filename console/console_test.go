@@ -0,0 +1,157 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package console
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		line string
+		want [][]string
+	}{
+		{"", [][]string{nil}},
+		{"r_wireframe 1", [][]string{{"r_wireframe", "1"}}},
+		{"a 1;b  2 ;", [][]string{{"a", "1"}, {"b", "2"}, nil}},
+		{`echo "hello; world" x // comment`, [][]string{{"echo", "hello; world", "x"}}},
+		{`echo "a \"b\"" ""`, [][]string{{"echo", `a "b"`, ""}}},
+	}
+	for _, tst := range tests {
+		got := split(tst.line)
+		if !reflect.DeepEqual(got, tst.want) {
+			t.Errorf("split(%q) = %q, want %q", tst.line, got, tst.want)
+		}
+	}
+}
+
+func TestVars(t *testing.T) {
+	c := New()
+	wire := c.BoolVar("r_wireframe", false, "wireframe")
+	vol := c.FloatVar("snd_volume", 1, "volume")
+	changed := 0
+	vol.OnChange = func(v *Var) { changed++ }
+
+	if err := c.Exec("r_wireframe 1; snd_volume 0.5"); err != nil {
+		t.Fatal(err)
+	}
+	if !wire.Bool() || vol.Float() != 0.5 || changed != 1 {
+		t.Fatal("vars not set", wire.Bool(), vol.Float(), changed)
+	}
+	if err := c.Exec("snd_volume loud"); err == nil {
+		t.Fatal("expected error for invalid float")
+	}
+	if vol.Float() != 0.5 {
+		t.Fatal("invalid value was set")
+	}
+	c.Exec("toggle r_wireframe; reset snd_volume")
+	if wire.Bool() || vol.Float() != 1 {
+		t.Fatal("toggle/reset failed", wire.Bool(), vol.Float())
+	}
+
+	// Re-adding a variable keeps it's value.
+	c.Exec("r_wireframe 1")
+	wire = c.BoolVar("r_wireframe", false, "wireframe")
+	if !wire.Bool() {
+		t.Fatal("value not kept")
+	}
+}
+
+func TestCommands(t *testing.T) {
+	c := New()
+	var got []string
+	c.Register(&Command{
+		Name: "spawn",
+		Help: "spawn <name>",
+		Run: func(c *Console, args []string) error {
+			if len(args) != 1 {
+				return ErrUsage
+			}
+			got = append(got, args[0])
+			return nil
+		},
+	})
+	if err := c.Exec("spawn a; spawn b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Exec("spawn"); err == nil || !strings.Contains(err.Error(), "usage") {
+		t.Fatal("expected usage error, got", err)
+	}
+	if err := c.Exec("nope"); err == nil {
+		t.Fatal("expected unknown command error")
+	}
+	if !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Fatal("got", got)
+	}
+
+	c.Submit("echo hello")
+	c.Submit("echo hello")
+	lines := c.Lines()
+	if lines[len(lines)-1] != "hello" || len(c.History()) != 1 {
+		t.Fatal("bad output or history", lines, c.History())
+	}
+}
+
+func TestExecFile(t *testing.T) {
+	c := New()
+	v := c.IntVar("fps_max", 60, "")
+	files := map[string]string{
+		"autoexec.cfg": "// Settings.\nfps_max 144\nexec other.cfg\nbogus\n",
+		"other.cfg":    "echo other",
+		"loop.cfg":     "exec loop.cfg",
+	}
+	c.Open = func(name string) (io.ReadCloser, error) {
+		s, ok := files[name]
+		if !ok {
+			return nil, errors.New("not found")
+		}
+		return ioutil.NopCloser(strings.NewReader(s)), nil
+	}
+	if err := c.ExecFile("autoexec.cfg"); err != nil {
+		t.Fatal(err)
+	}
+	if v.Int() != 144 {
+		t.Fatal("fps_max not set")
+	}
+	out := strings.Join(c.Lines(), "\n")
+	if !strings.Contains(out, "other") || !strings.Contains(out, "bogus: unknown command") {
+		t.Fatal("bad output", out)
+	}
+	if err := c.ExecFile("loop.cfg"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(strings.Join(c.Lines(), "\n"), "nested too deeply") {
+		t.Fatal("recursion not stopped")
+	}
+}
+
+func TestComplete(t *testing.T) {
+	c := New()
+	c.BoolVar("r_wireframe", false, "")
+	c.FloatVar("r_gamma", 2.2, "")
+	c.FloatVar("snd_volume", 1, "")
+
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"r_", []string{"r_gamma ", "r_wireframe "}},
+		{"echo 1; snd", []string{"echo 1; snd_volume "}},
+		{"toggle r_w", []string{"toggle r_wireframe"}},
+		{"r_wireframe ", []string{"r_wireframe 0", "r_wireframe 1"}},
+		{"zzz", nil},
+	}
+	for _, tst := range tests {
+		got := c.Complete(tst.line)
+		if !reflect.DeepEqual(got, tst.want) {
+			t.Errorf("Complete(%q) = %q, want %q", tst.line, got, tst.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package console implements an in-engine debug console.
+//
+// Packages register commands and variables (cvars) with a Console, and the
+// user (or a command file) executes lines of text which invoke them:
+//
+//  con := console.New()
+//  wireframe := con.BoolVar("r_wireframe", false, "draw meshes as wireframes")
+//  volume := con.FloatVar("snd_volume", 1, "master volume")
+//  con.Register(&console.Command{
+//      Name: "spawn",
+//      Help: "spawn <prefab> - spawns a prefab at the player",
+//      Run: func(c *console.Console, args []string) error {
+//          ...
+//      },
+//  })
+//
+//  con.Exec("r_wireframe 1; snd_volume 0.5")
+//  con.ExecFile("autoexec.cfg")
+//
+// A line holds one or more commands separated by semicolons. Arguments are
+// separated by spaces and may be double quoted, and the rest of a line after
+// "//" is a comment. Naming a variable prints it's value, and naming it with
+// an argument sets it. A few commands are built in:
+//
+//  help [name]     - lists commands and variables, or describes one
+//  set name value  - sets a variable
+//  toggle name     - toggles a boolean variable
+//  reset name      - resets a variable to it's default value
+//  echo args...    - prints it's arguments
+//  exec file       - executes a command file
+//  clear           - clears the output
+//  history         - prints the command history
+//
+// A View draws the console as a drop-down overlay using the text package, and
+// handles the keyboard input of the user (editing, history and tab
+// completion) while it is open.
+package console // import "azul3d.org/engine/console"
@@ -0,0 +1,218 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package console
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Kind is the kind of value a variable holds.
+type Kind uint8
+
+const (
+	String Kind = iota
+	Bool
+	Int
+	Float
+)
+
+// String returns a string name for this kind. For example:
+//
+//  Float.String() == "Float"
+//
+func (k Kind) String() string {
+	switch k {
+	case String:
+		return "String"
+	case Bool:
+		return "Bool"
+	case Int:
+		return "Int"
+	case Float:
+		return "Float"
+	}
+	return fmt.Sprintf("Kind(%d)", k)
+}
+
+// Var is a console variable (cvar): a named value which the user may view and
+// change. It's methods are safe to call from multiple goroutines
+// concurrently.
+type Var struct {
+	// The name and kind of the variable.
+	Name string
+	Kind Kind
+
+	// A short description of the variable, printed by the help command.
+	Help string
+
+	// The default value of the variable.
+	Default string
+
+	// OnChange, if non-nil, is called after the value of the variable has
+	// been changed.
+	OnChange func(v *Var)
+
+	access sync.RWMutex
+	value  string
+	b      bool
+	i      int64
+	f      float64
+}
+
+// parse parses the given value according to the kind of the variable.
+func (v *Var) parse(s string) (b bool, i int64, f float64, err error) {
+	switch v.Kind {
+	case Bool:
+		b, err = strconv.ParseBool(s)
+	case Int:
+		i, err = strconv.ParseInt(s, 0, 64)
+		f = float64(i)
+		b = i != 0
+	case Float:
+		f, err = strconv.ParseFloat(s, 64)
+		i = int64(f)
+		b = f != 0
+	}
+	if err != nil {
+		err = fmt.Errorf("invalid %s value %q", v.Kind, s)
+	}
+	return
+}
+
+// Set sets the value of the variable from it's string form, returning an
+// error if it is not a valid value of the variable's kind. Bool variables
+// accept the values of strconv.ParseBool, e.g. "1", "0", "true" and "false".
+func (v *Var) Set(s string) error {
+	b, i, f, err := v.parse(s)
+	if err != nil {
+		return err
+	}
+	v.access.Lock()
+	v.value, v.b, v.i, v.f = s, b, i, f
+	v.access.Unlock()
+	if v.OnChange != nil {
+		v.OnChange(v)
+	}
+	return nil
+}
+
+// Reset resets the variable to it's default value.
+func (v *Var) Reset() {
+	v.Set(v.Default)
+}
+
+// String returns the value of the variable in string form.
+func (v *Var) String() string {
+	v.access.RLock()
+	defer v.access.RUnlock()
+	return v.value
+}
+
+// Bool returns the value of a Bool variable, or whether the value of an Int or
+// Float variable is non-zero.
+func (v *Var) Bool() bool {
+	v.access.RLock()
+	defer v.access.RUnlock()
+	return v.b
+}
+
+// Int returns the value of an Int variable, or the truncated value of a Float
+// variable.
+func (v *Var) Int() int {
+	v.access.RLock()
+	defer v.access.RUnlock()
+	return int(v.i)
+}
+
+// Float returns the value of a Float or Int variable.
+func (v *Var) Float() float64 {
+	v.access.RLock()
+	defer v.access.RUnlock()
+	return v.f
+}
+
+// completions returns the tab completions of the variable's value.
+func (v *Var) completions() []string {
+	if v.Kind == Bool {
+		return []string{"0", "1"}
+	}
+	return []string{v.Default}
+}
+
+// AddVar adds the given variable to the console, setting it to it's default
+// value, and returns it. It panics if the default value is invalid, or if the
+// name is invalid or that of a command. If a variable of the same name
+// already exists it is replaced, but keeps the existing value if valid (such
+// that a command file executed before a package registers it's variables
+// still takes effect).
+func (c *Console) AddVar(v *Var) *Var {
+	c.checkName(v.Name)
+	if err := v.Set(v.Default); err != nil {
+		panic(fmt.Sprintf("console: AddVar: %s: %v", v.Name, err))
+	}
+	c.access.Lock()
+	if _, ok := c.commands[v.Name]; ok {
+		c.access.Unlock()
+		panic(fmt.Sprintf("console: AddVar: %q is a command", v.Name))
+	}
+	old := c.vars[v.Name]
+	c.vars[v.Name] = v
+	c.access.Unlock()
+	if old != nil {
+		v.Set(old.String())
+	}
+	return v
+}
+
+// Var returns the named variable, or nil if there is none.
+func (c *Console) Var(name string) *Var {
+	c.access.RLock()
+	defer c.access.RUnlock()
+	return c.vars[name]
+}
+
+// Vars returns the names of all variables, sorted.
+func (c *Console) Vars() []string {
+	c.access.RLock()
+	defer c.access.RUnlock()
+	names := make([]string, 0, len(c.vars))
+	for name := range c.vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// StringVar adds a String variable with the given name, default value and
+// help to the console.
+func (c *Console) StringVar(name, value, help string) *Var {
+	return c.AddVar(&Var{Name: name, Kind: String, Help: help, Default: value})
+}
+
+// BoolVar adds a Bool variable with the given name, default value and help to
+// the console.
+func (c *Console) BoolVar(name string, value bool, help string) *Var {
+	def := "0"
+	if value {
+		def = "1"
+	}
+	return c.AddVar(&Var{Name: name, Kind: Bool, Help: help, Default: def})
+}
+
+// IntVar adds an Int variable with the given name, default value and help to
+// the console.
+func (c *Console) IntVar(name string, value int, help string) *Var {
+	return c.AddVar(&Var{Name: name, Kind: Int, Help: help, Default: strconv.Itoa(value)})
+}
+
+// FloatVar adds a Float variable with the given name, default value and help
+// to the console.
+func (c *Console) FloatVar(name string, value float64, help string) *Var {
+	def := strconv.FormatFloat(value, 'g', -1, 64)
+	return c.AddVar(&Var{Name: name, Kind: Float, Help: help, Default: def})
+}
@@ -0,0 +1,438 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package console
+
+import (
+	"image"
+	"math"
+	"strings"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/window"
+	"azul3d.org/engine/keyboard"
+	"azul3d.org/engine/lmath"
+	"azul3d.org/engine/text"
+)
+
+var (
+	glslVert = []byte(`
+#version 120
+
+attribute vec3 Vertex;
+attribute vec4 Color;
+
+uniform mat4 MVP;
+
+varying vec4 frontColor;
+
+void main()
+{
+	frontColor = Color;
+	gl_Position = MVP * vec4(Vertex, 1.0);
+}
+`)
+
+	glslFrag = []byte(`
+#version 120
+
+varying vec4 frontColor;
+
+void main()
+{
+	gl_FragColor = frontColor;
+}
+`)
+)
+
+// backgroundShader is the shader used to draw the background of views.
+var backgroundShader = &gfx.Shader{
+	Name: "console.backgroundShader",
+	GLSL: &gfx.GLSLSources{
+		Vertex:   glslVert,
+		Fragment: glslFrag,
+	},
+}
+
+// ViewEvents is the event mask of the window events which views handle, for
+// use with Window.Notify.
+const ViewEvents = window.KeyboardButtonEvents | window.KeyboardTypedEvents
+
+// View draws a console as a drop-down overlay at the top of a canvas, and
+// edits and submits lines from the keyboard input of the user while it is
+// open:
+//
+//  view := console.NewView(con, font)
+//  for {
+//      ... for each pending event ...
+//          if view.HandleEvent(ev) {
+//              continue // Consumed by the console.
+//          }
+//      view.Update(clock.Dt())
+//      ... render the scene ...
+//      view.Draw(canvas, orthoCamera)
+//  }
+//
+// The view is drawn in pixels in the X/Z plane (like the text package), and
+// should be drawn with an orthographic camera whose view is the bounds of the
+// canvas (see camera.NewOrtho).
+//
+// The keys of an open view are:
+//
+//  Enter            - submit the input line
+//  Tab              - complete the input line (repeatedly, to cycle)
+//  ArrowUp/Down     - browse the history
+//  ArrowLeft/Right  - move the cursor, Home and End to either end
+//  Backspace/Delete - delete a character
+//  PageUp/Down      - scroll the output
+//  Escape           - close the console
+//
+// A view is not safe for access from multiple goroutines concurrently.
+type View struct {
+	// The console which is viewed.
+	Console *Console
+
+	// The key which toggles the view open and closed.
+	ToggleKey keyboard.Key
+
+	// The fraction of the canvas height covered by the open view, and the
+	// fraction of it which the view slides per second when opening or
+	// closing.
+	Height, Speed float64
+
+	// The colors of the background and the text of the view.
+	Background, Color gfx.Color
+
+	font     *text.Font
+	open     bool
+	slide    float64
+	input    []rune
+	cursor   int
+	scroll   int
+	history  int
+	saved    string
+	complete []string
+	next     int
+
+	bg     *gfx.Object
+	output *text.Text
+	prompt *text.Text
+}
+
+// Open tells whether the view is open (or opening).
+func (v *View) Open() bool {
+	return v.open
+}
+
+// SetOpen opens or closes the view.
+func (v *View) SetOpen(open bool) {
+	v.open = open
+}
+
+// Toggle opens the view if it is closed, or closes it if it is open.
+func (v *View) Toggle() {
+	v.open = !v.open
+}
+
+// Visible tells whether any part of the view is visible, i.e. whether it is
+// open or has not yet finished closing.
+func (v *View) Visible() bool {
+	return v.open || v.slide > 0
+}
+
+// Input returns the current input line.
+func (v *View) Input() string {
+	return string(v.input)
+}
+
+// SetInput sets the input line, placing the cursor at it's end.
+func (v *View) SetInput(s string) {
+	v.input = []rune(s)
+	v.cursor = len(v.input)
+	v.complete = nil
+}
+
+// insert inserts s at the cursor.
+func (v *View) insert(s string) {
+	r := []rune(s)
+	v.input = append(v.input[:v.cursor], append(r, v.input[v.cursor:]...)...)
+	v.cursor += len(r)
+	v.complete = nil
+}
+
+// browse moves through the history by delta entries (negative is older).
+func (v *View) browse(delta int) {
+	// An index of len(hist) is the line being typed, which is saved when
+	// browsing away from it.
+	hist := v.Console.History()
+	if v.history >= len(hist) {
+		v.history = len(hist)
+		if delta > 0 {
+			return
+		}
+		v.saved = string(v.input)
+	}
+	v.history += delta
+	if v.history < 0 {
+		v.history = 0
+	}
+	if v.history >= len(hist) {
+		v.history = len(hist)
+		v.SetInput(v.saved)
+		return
+	}
+	v.SetInput(hist[v.history])
+}
+
+// tab completes the input line, cycling through the completions on repeated
+// presses. If there are many completions, they are printed.
+func (v *View) tab() {
+	if v.complete == nil {
+		v.complete = v.Console.Complete(string(v.input))
+		v.next = 0
+		if len(v.complete) == 0 {
+			return
+		}
+		if len(v.complete) > 1 {
+			// Complete the common prefix first, if it extends the input.
+			prefix := commonPrefix(v.complete)
+			if len(prefix) > len(string(v.input)) {
+				matches := v.complete
+				v.input = []rune(prefix)
+				v.cursor = len(v.input)
+				v.complete = matches
+				return
+			}
+			for _, m := range v.complete {
+				v.Console.Println("  " + strings.TrimSpace(m))
+			}
+		}
+	}
+	if len(v.complete) == 0 {
+		return
+	}
+	matches, next := v.complete, v.next
+	v.input = []rune(matches[next])
+	v.cursor = len(v.input)
+	v.complete, v.next = matches, (next+1)%len(matches)
+}
+
+// commonPrefix returns the longest common prefix of the given strings.
+func commonPrefix(s []string) string {
+	prefix := s[0]
+	for _, x := range s[1:] {
+		for !strings.HasPrefix(x, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+// HandleEvent handles a single window event, returning true if the event was
+// consumed by the view (i.e. the view is open, or the event toggled it) and
+// should not be handled by the application. Events other than keyboard ones
+// are never consumed.
+func (v *View) HandleEvent(ev window.Event) bool {
+	switch e := ev.(type) {
+	case keyboard.ButtonEvent:
+		if e.Key == v.ToggleKey {
+			if e.State == keyboard.Down {
+				v.Toggle()
+			}
+			return true
+		}
+		if !v.open {
+			return false
+		}
+		if e.State != keyboard.Down {
+			return true
+		}
+		switch e.Key {
+		case keyboard.Enter:
+			line := string(v.input)
+			v.SetInput("")
+			v.scroll = 0
+			v.Console.Submit(line)
+			v.history = len(v.Console.History())
+		case keyboard.Tab:
+			v.tab()
+		case keyboard.ArrowUp:
+			v.browse(-1)
+		case keyboard.ArrowDown:
+			v.browse(1)
+		case keyboard.ArrowLeft:
+			if v.cursor > 0 {
+				v.cursor--
+			}
+		case keyboard.ArrowRight:
+			if v.cursor < len(v.input) {
+				v.cursor++
+			}
+		case keyboard.Home:
+			v.cursor = 0
+		case keyboard.End:
+			v.cursor = len(v.input)
+		case keyboard.Backspace:
+			if v.cursor > 0 {
+				v.input = append(v.input[:v.cursor-1], v.input[v.cursor:]...)
+				v.cursor--
+				v.complete = nil
+			}
+		case keyboard.Delete:
+			if v.cursor < len(v.input) {
+				v.input = append(v.input[:v.cursor], v.input[v.cursor+1:]...)
+				v.complete = nil
+			}
+		case keyboard.PageUp:
+			v.scroll += 4
+		case keyboard.PageDown:
+			v.scroll -= 4
+			if v.scroll < 0 {
+				v.scroll = 0
+			}
+		case keyboard.Escape:
+			v.open = false
+		}
+		return true
+	case keyboard.Typed:
+		if !v.open {
+			return false
+		}
+		// Drop control characters, and the character of the toggle key.
+		s := strings.Map(func(r rune) rune {
+			if r < ' ' || r == 0x7f {
+				return -1
+			}
+			return r
+		}, e.S)
+		if v.ToggleKey == keyboard.Tilde {
+			s = strings.NewReplacer("`", "", "~", "").Replace(s)
+		}
+		if s != "" {
+			v.insert(s)
+		}
+		return true
+	}
+	return false
+}
+
+// Update slides the view towards being open or closed, given the time in
+// seconds since the last update.
+func (v *View) Update(dt float64) {
+	if v.open {
+		v.slide = math.Min(1, v.slide+v.Speed*dt)
+	} else {
+		v.slide = math.Max(0, v.slide-v.Speed*dt)
+	}
+}
+
+// Draw draws the view (if visible) to the top of the given canvas, using the
+// given orthographic camera.
+func (v *View) Draw(canvas gfx.Canvas, cam gfx.Camera) {
+	if !v.Visible() {
+		return
+	}
+	b := canvas.Bounds()
+	w, h := float64(b.Dx()), float64(b.Dy())*v.Height
+	bottom := float64(b.Dy()) - h*v.slide
+
+	// The background quad.
+	m := v.bg.Meshes[0]
+	l, r, top, btm := float32(0), float32(w), float32(bottom+h), float32(bottom)
+	m.Vertices = append(m.Vertices[:0],
+		gfx.Vec3{l, 0, top}, gfx.Vec3{l, 0, btm}, gfx.Vec3{r, 0, btm},
+		gfx.Vec3{l, 0, top}, gfx.Vec3{r, 0, btm}, gfx.Vec3{r, 0, top},
+	)
+	m.Colors = m.Colors[:0]
+	for i := 0; i < 6; i++ {
+		m.Colors = append(m.Colors, v.Background)
+	}
+	m.VerticesChanged = true
+	m.ColorsChanged = true
+	m.AABB = lmath.Rect3Zero
+	v.bg.CachedBounds = nil
+
+	// The output lines which fit above the prompt, scrolled.
+	lh := v.font.LineHeight
+	if lh <= 0 {
+		lh = 1
+	}
+	pad := float64(lh) / 2
+	rows := int((h-pad*2)/float64(lh)) - 1
+	lines := v.Console.Lines()
+	end := len(lines) - v.scroll
+	if end < 0 {
+		end = 0
+	}
+	start := end - rows
+	if start < 0 {
+		start = 0
+	}
+	out := strings.Join(lines[start:end], "\n")
+	if v.output.String() != out || v.output.Color != v.Color {
+		v.output.Color = v.Color
+		v.output.Set(out)
+	}
+	nOut := end - start
+	v.output.Transform.SetPos(lmath.Vec3{
+		X: pad,
+		Z: bottom + h - pad - float64(v.font.Base) - float64((rows-nOut)*lh),
+	})
+
+	// The prompt, with a cursor.
+	in := string(v.input[:v.cursor]) + "_" + string(v.input[v.cursor:])
+	if v.prompt.String() != "> "+in || v.prompt.Color != v.Color {
+		v.prompt.Color = v.Color
+		v.prompt.Set("> " + in)
+	}
+	v.prompt.Transform.SetPos(lmath.Vec3{
+		X: pad,
+		Z: bottom + pad + float64(lh-v.font.Base),
+	})
+
+	canvas.Draw(image.Rect(0, 0, 0, 0), v.bg, cam)
+	for _, o := range v.output.Objects {
+		canvas.Draw(image.Rect(0, 0, 0, 0), o, cam)
+	}
+	for _, o := range v.prompt.Objects {
+		canvas.Draw(image.Rect(0, 0, 0, 0), o, cam)
+	}
+}
+
+// NewView returns a new, closed, view of the given console drawn with the
+// given font, sharing one set of page textures (see text.New). It is toggled
+// by the Tilde key and covers half of the canvas when open.
+func NewView(c *Console, f *text.Font) *View {
+	m := gfx.NewMesh()
+	m.Dynamic = true
+	bg := gfx.NewObject()
+	bg.Shader = backgroundShader
+	bg.Meshes = []*gfx.Mesh{m}
+	bg.State = gfx.NewState()
+	bg.State.FaceCulling = gfx.NoFaceCulling
+	bg.State.AlphaMode = gfx.AlphaBlend
+	bg.State.DepthTest = false
+	bg.State.DepthWrite = false
+
+	textures := f.Textures()
+	output := text.New(f, textures)
+	prompt := text.New(f, textures)
+	for _, o := range append(output.Objects, prompt.Objects...) {
+		o.State.DepthTest = false
+		o.State.DepthWrite = false
+	}
+	return &View{
+		Console:    c,
+		ToggleKey:  keyboard.Tilde,
+		Height:     0.5,
+		Speed:      4,
+		Background: gfx.Color{0, 0, 0, 0.75},
+		Color:      gfx.Color{1, 1, 1, 1},
+		font:       f,
+		history:    len(c.History()),
+		bg:         bg,
+		output:     output,
+		prompt:     prompt,
+	}
+}
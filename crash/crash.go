@@ -0,0 +1,286 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crash
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/window"
+	"azul3d.org/engine/log"
+)
+
+// Report is a crash report.
+type Report struct {
+	// The time at which the report was captured.
+	Time time.Time
+
+	// The panic value, formatted as a string.
+	Panic string
+
+	// The stacks of all goroutines, as formatted by runtime.Stack.
+	Stacks string
+
+	// The operating system, architecture, Go version and number of CPUs.
+	GOOS, GOARCH, GoVersion string
+	NumCPU                  int
+
+	// The graphics device information, or nil if there is no device.
+	Device *gfx.DeviceInfo
+
+	// The window properties, formatted as lines of "name: value".
+	Window []string
+
+	// The most recently logged lines, oldest first.
+	Log []string
+}
+
+// WriteTo writes the report to w in a human readable form. It implements the
+// io.WriterTo interface.
+func (r *Report) WriteTo(w io.Writer) (int64, error) {
+	cw := &countWriter{w: bufio.NewWriter(w)}
+	section := func(name string) {
+		fmt.Fprintf(cw, "\n== %s ==\n", name)
+	}
+
+	fmt.Fprintf(cw, "Crash report %s\n", r.Time.Format(time.RFC3339))
+	fmt.Fprintf(cw, "panic: %s\n", r.Panic)
+
+	section("System")
+	fmt.Fprintf(cw, "OS/Arch: %s/%s\n", r.GOOS, r.GOARCH)
+	fmt.Fprintf(cw, "Go: %s\n", r.GoVersion)
+	fmt.Fprintf(cw, "CPUs: %d\n", r.NumCPU)
+
+	section("Graphics")
+	if r.Device == nil {
+		fmt.Fprintln(cw, "(no device)")
+	} else {
+		d := r.Device
+		fmt.Fprintf(cw, "Name: %s\n", d.Name)
+		fmt.Fprintf(cw, "Vendor: %s\n", d.Vendor)
+		if d.GL != nil {
+			fmt.Fprintf(cw, "GL: %s\n", d.GL)
+		}
+		if d.GLSL != nil {
+			fmt.Fprintf(cw, "GLSL: %s\n", d.GLSL)
+		}
+		fmt.Fprintf(cw, "MaxTextureSize: %d\n", d.MaxTextureSize)
+		fmt.Fprintf(cw, "NPOT: %v\n", d.NPOT)
+		if d.GL != nil {
+			fmt.Fprintf(cw, "Extensions: %s\n", strings.Join(d.GL.Extensions, " "))
+		}
+	}
+
+	section("Window")
+	if len(r.Window) == 0 {
+		fmt.Fprintln(cw, "(no window)")
+	}
+	for _, line := range r.Window {
+		fmt.Fprintln(cw, line)
+	}
+
+	section("Log")
+	for _, line := range r.Log {
+		fmt.Fprintln(cw, line)
+	}
+
+	section("Goroutines")
+	fmt.Fprint(cw, r.Stacks)
+
+	if err := cw.w.(*bufio.Writer).Flush(); err != nil && cw.err == nil {
+		cw.err = err
+	}
+	return cw.n, cw.err
+}
+
+// countWriter counts the bytes written to w, and remembers the first error.
+type countWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
+
+// stacks returns the stacks of all goroutines.
+func stacks() string {
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return string(buf[:n])
+		}
+		buf = make([]byte, len(buf)*2)
+	}
+}
+
+// windowProps returns the properties of the window formatted as lines.
+func windowProps(p *window.Props) []string {
+	w, h := p.Size()
+	fw, fh := p.FramebufferSize()
+	x, y := p.Pos()
+	prec := p.Precision()
+	return []string{
+		fmt.Sprintf("Title: %q", p.Title()),
+		fmt.Sprintf("Size: %dx%d (framebuffer %dx%d)", w, h, fw, fh),
+		fmt.Sprintf("Pos: %d,%d", x, y),
+		fmt.Sprintf("Fullscreen: %v", p.Fullscreen()),
+		fmt.Sprintf("Monitor: %q", p.Monitor()),
+		fmt.Sprintf("VSync: %v", p.VSync()),
+		fmt.Sprintf("SRGB: %v", p.SRGB()),
+		fmt.Sprintf("Precision: RGBA %d/%d/%d/%d, depth %d, stencil %d, %d samples",
+			prec.RedBits, prec.GreenBits, prec.BlueBits, prec.AlphaBits,
+			prec.DepthBits, prec.StencilBits, prec.Samples),
+	}
+}
+
+// Handler captures and writes crash reports. It's fields should be set before
+// the goroutines whose panics it reports are started.
+type Handler struct {
+	// The directory which reports are written to, it is created if it does not
+	// exist.
+	Dir string
+
+	// The graphics device and window whose information is included in
+	// reports, or nil.
+	Device gfx.Device
+	Window window.Window
+
+	// Upload, if non-nil, is called with each report after it has been
+	// written to the file at path, e.g. to send it to a server. It should only
+	// be set if the user has opted in to uploading reports.
+	Upload func(r *Report, path string) error
+
+	// The ring which recently logged lines are taken from, it is added as a
+	// log sink by New.
+	Log *log.Ring
+}
+
+// Capture captures and returns a report of the given panic value. It does not
+// write the report.
+func (h *Handler) Capture(v interface{}) *Report {
+	r := &Report{
+		Time:      time.Now(),
+		Panic:     fmt.Sprint(v),
+		Stacks:    stacks(),
+		GOOS:      runtime.GOOS,
+		GOARCH:    runtime.GOARCH,
+		GoVersion: runtime.Version(),
+		NumCPU:    runtime.NumCPU(),
+	}
+	if err, ok := v.(error); ok {
+		r.Panic = fmt.Sprintf("%v (%T)", err, err)
+	}
+	if h.Device != nil {
+		info := h.Device.Info()
+		r.Device = &info
+	}
+	if h.Window != nil {
+		r.Window = windowProps(h.Window.Props())
+	}
+	if h.Log != nil {
+		r.Log = h.Log.Lines()
+	}
+	return r
+}
+
+// Write writes the given report to a new file in the directory of the handler,
+// named by the time of the report, and returns it's path. If Upload is set it
+// is called with the report afterwards, and it's error is returned.
+func (h *Handler) Write(r *Report) (path string, err error) {
+	if err := os.MkdirAll(h.Dir, 0755); err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("crash-%s.txt", r.Time.Format("20060102-150405.000"))
+	path = filepath.Join(h.Dir, name)
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if _, err := r.WriteTo(f); err != nil {
+		f.Close()
+		return path, err
+	}
+	if err := f.Close(); err != nil {
+		return path, err
+	}
+	if h.Upload != nil {
+		return path, h.Upload(r, path)
+	}
+	return path, nil
+}
+
+// report captures and writes a report of the given panic value, logging the
+// outcome.
+func (h *Handler) report(v interface{}) {
+	path, err := h.Write(h.Capture(v))
+	switch {
+	case path == "":
+		logger.Errorf("failed to write crash report: %v", err)
+	case err != nil:
+		logger.Errorf("crash report written to %s, but: %v", path, err)
+	default:
+		logger.Errorf("crash report written to %s", path)
+	}
+}
+
+// Recover reports a panic of the calling goroutine, if there is one, and then
+// continues panicking. It must be deferred directly:
+//
+//  defer h.Recover()
+//
+func (h *Handler) Recover() {
+	if v := recover(); v != nil {
+		h.report(v)
+		panic(v)
+	}
+}
+
+// Go runs fn in a new goroutine, reporting it's panics (see Recover).
+func (h *Handler) Go(fn func()) {
+	go func() {
+		defer h.Recover()
+		fn()
+	}()
+}
+
+// Destroy removes the log ring of the handler from the log sinks.
+func (h *Handler) Destroy() {
+	if h.Log != nil {
+		log.RemoveSink(h.Log)
+	}
+}
+
+var logger = log.New("crash")
+
+// LogLines is the number of recently logged lines included in reports by
+// handlers returned from New.
+const LogLines = 200
+
+// New returns a new handler which writes reports to the given directory. It
+// adds a log ring of the last LogLines entries as a log sink (see Destroy).
+func New(dir string) *Handler {
+	h := &Handler{
+		Dir: dir,
+		Log: log.NewRing(LogLines),
+	}
+	log.AddSink(h.Log)
+	return h
+}
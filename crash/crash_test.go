@@ -0,0 +1,63 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crash
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/log"
+)
+
+func TestRecover(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	h := New(dir)
+	defer h.Destroy()
+	h.Device = gfx.Nil()
+	var uploaded string
+	h.Upload = func(r *Report, path string) error {
+		uploaded = path
+		return nil
+	}
+	log.New("test").Errorf("something went wrong")
+
+	func() {
+		defer func() {
+			if v := recover(); v != "boom" {
+				t.Fatal("expected the panic to continue, got", v)
+			}
+		}()
+		defer h.Recover()
+		panic("boom")
+	}()
+
+	if uploaded == "" {
+		t.Fatal("report not uploaded")
+	}
+	data, err := ioutil.ReadFile(uploaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := string(data)
+	for _, want := range []string{
+		"panic: boom",
+		"== Graphics ==",
+		"(no window)",
+		"[ERROR] test: something went wrong",
+		"crash.TestRecover",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report does not contain %q:\n%s", want, report)
+		}
+	}
+}
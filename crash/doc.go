@@ -0,0 +1,29 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package crash writes crash reports when the program panics.
+//
+// A report holds the panic value, a dump of the stacks of all goroutines, the
+// graphics device information (GPU, driver and OpenGL versions), the window
+// properties, and the most recently logged lines (see the log package). It is
+// written to a file such that users may attach it to bug reports, and may
+// optionally be uploaded:
+//
+//  crashes := crash.New("crashes")
+//  crashes.Upload = func(r *crash.Report, path string) error {
+//      ... only if the user opted in ...
+//  }
+//
+//  func gfxLoop(w window.Window, d gfx.Device) {
+//      crashes.Window = w
+//      crashes.Device = d
+//      defer crashes.Recover()
+//      ...
+//  }
+//
+// Go only allows recovering from a panic on the goroutine which panicked, so
+// Recover must be deferred by (or Go used to start) each goroutine whose
+// panics should be reported. After the report is written the panic continues,
+// such that the program still crashes as it would otherwise.
+package crash // import "azul3d.org/engine/crash"
@@ -0,0 +1,217 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package event
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Subscription is a handler subscribed to a bus.
+type Subscription struct {
+	bus     *Bus
+	typ     reflect.Type
+	handler reflect.Value
+	any     func(interface{})
+}
+
+// Type returns the type of events the subscription receives: the argument
+// type of it's handler.
+func (s *Subscription) Type() reflect.Type {
+	return s.typ
+}
+
+// Cancel unsubscribes the handler from the bus. It will not be called for
+// events published afterwards, including queued events which have not yet
+// been dispatched. Cancelling a subscription more than once is no-op.
+func (s *Subscription) Cancel() {
+	s.bus.cancel(s)
+}
+
+// call calls the subscription's handler with the given event.
+func (s *Subscription) call(ev reflect.Value) {
+	if s.any != nil {
+		s.any(ev.Interface())
+		return
+	}
+	s.handler.Call([]reflect.Value{ev})
+}
+
+// Bus is an event bus, delivering published events to the handlers whose
+// argument type the events are assignable to. It's methods are safe to call
+// from multiple goroutines concurrently, and handlers may publish events and
+// subscribe or cancel subscriptions themselves.
+type Bus struct {
+	access sync.RWMutex
+	subs   []*Subscription
+
+	// The subscriptions matching each concrete event type, built on demand
+	// and cleared whenever subscriptions change.
+	cache map[reflect.Type][]*Subscription
+
+	queueAccess sync.Mutex
+	queue       []reflect.Value
+}
+
+var anyType = reflect.TypeOf((*interface{})(nil)).Elem()
+
+// Subscribe subscribes the given handler, which must be a function of a
+// single argument with no results, to the bus. It receives each event which
+// is assignable to it's argument type: events of exactly that type or, if it
+// is an interface type, events which implement it (func(interface{})
+// receives all events). Handlers are called in the order they subscribed.
+//
+// Subscribe panics if handler is not such a function.
+func (b *Bus) Subscribe(handler interface{}) *Subscription {
+	v := reflect.ValueOf(handler)
+	if v.Kind() != reflect.Func || v.Type().NumIn() != 1 || v.Type().NumOut() != 0 || v.Type().IsVariadic() {
+		panic(fmt.Sprintf("event: Subscribe: handler must be a func of one argument, got %T", handler))
+	}
+	t := v.Type()
+	s := &Subscription{bus: b, typ: t.In(0), handler: v}
+	if f, ok := handler.(func(interface{})); ok {
+		s.any = f
+	}
+	b.access.Lock()
+	b.subs = append(b.subs, s)
+	b.cache = nil
+	b.access.Unlock()
+	return s
+}
+
+// cancel removes the subscription.
+func (b *Bus) cancel(s *Subscription) {
+	b.access.Lock()
+	for i, o := range b.subs {
+		if o == s {
+			b.subs = append(b.subs[:i:i], b.subs[i+1:]...)
+			b.cache = nil
+			break
+		}
+	}
+	b.access.Unlock()
+}
+
+// handlers returns the subscriptions receiving events of the given concrete
+// type.
+func (b *Bus) handlers(t reflect.Type) []*Subscription {
+	b.access.RLock()
+	subs, ok := b.cache[t]
+	b.access.RUnlock()
+	if ok {
+		return subs
+	}
+
+	b.access.Lock()
+	defer b.access.Unlock()
+	for _, s := range b.subs {
+		if t.AssignableTo(s.typ) {
+			subs = append(subs, s)
+		}
+	}
+	if b.cache == nil {
+		b.cache = make(map[reflect.Type][]*Subscription)
+	}
+	b.cache[t] = subs
+	return subs
+}
+
+// deliver delivers the event to it's handlers.
+func (b *Bus) deliver(ev reflect.Value) {
+	for _, s := range b.handlers(ev.Type()) {
+		b.access.RLock()
+		live := b.live(s)
+		b.access.RUnlock()
+		if live {
+			s.call(ev)
+		}
+	}
+}
+
+// live tells if the subscription has not been cancelled. b.access must be
+// held.
+func (b *Bus) live(s *Subscription) bool {
+	for _, o := range b.subs {
+		if o == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Publish delivers the event to it's handlers synchronously: they have all
+// been called by the time Publish returns. Nil events are ignored.
+func (b *Bus) Publish(ev interface{}) {
+	if ev == nil {
+		return
+	}
+	b.deliver(reflect.ValueOf(ev))
+}
+
+// Post queues the event for delivery to it's handlers by the next call to
+// Dispatch. Nil events are ignored.
+func (b *Bus) Post(ev interface{}) {
+	if ev == nil {
+		return
+	}
+	b.queueAccess.Lock()
+	b.queue = append(b.queue, reflect.ValueOf(ev))
+	b.queueAccess.Unlock()
+}
+
+// PostFrom posts each event that can be received from the given channel
+// without blocking, and returns the number of events posted. It panics if ch
+// is not a channel which can be received from.
+func (b *Bus) PostFrom(ch interface{}) int {
+	v := reflect.ValueOf(ch)
+	if v.Kind() != reflect.Chan || v.Type().ChanDir()&reflect.RecvDir == 0 {
+		panic(fmt.Sprintf("event: PostFrom: %T is not a receivable channel", ch))
+	}
+	n := 0
+	for {
+		ev, ok := v.TryRecv()
+		if !ok {
+			return n
+		}
+		if ev.Kind() == reflect.Interface {
+			if ev.IsNil() {
+				continue
+			}
+			ev = ev.Elem()
+		}
+		b.queueAccess.Lock()
+		b.queue = append(b.queue, ev)
+		b.queueAccess.Unlock()
+		n++
+	}
+}
+
+// Len returns the number of queued events, waiting for Dispatch.
+func (b *Bus) Len() int {
+	b.queueAccess.Lock()
+	n := len(b.queue)
+	b.queueAccess.Unlock()
+	return n
+}
+
+// Dispatch delivers the queued events to their handlers in the order that
+// they were posted, and returns the number of events delivered. Events posted
+// by handlers during Dispatch are delivered by the next call to Dispatch.
+func (b *Bus) Dispatch() int {
+	b.queueAccess.Lock()
+	queue := b.queue
+	b.queue = nil
+	b.queueAccess.Unlock()
+	for _, ev := range queue {
+		b.deliver(ev)
+	}
+	return len(queue)
+}
+
+// NewBus returns a new event bus, with no subscriptions.
+func NewBus() *Bus {
+	return &Bus{}
+}
@@ -0,0 +1,114 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package event
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type hit struct {
+	damage int
+}
+
+type named string
+
+func (n named) String() string { return string(n) }
+
+func TestBus(t *testing.T) {
+	b := NewBus()
+	var got []string
+	b.Subscribe(func(h hit) {
+		got = append(got, fmt.Sprint("hit ", h.damage))
+	})
+	b.Subscribe(func(h *hit) {
+		got = append(got, "ptr")
+	})
+	b.Subscribe(func(s fmt.Stringer) {
+		got = append(got, "stringer "+s.String())
+	})
+	all := b.Subscribe(func(ev interface{}) {
+		got = append(got, fmt.Sprintf("any %T", ev))
+	})
+	if all.Type().Kind() != reflect.Interface {
+		t.Fatal("bad subscription type")
+	}
+
+	// Synchronous delivery.
+	b.Publish(hit{3})
+	b.Publish(named("x"))
+	b.Publish(nil)
+	want := []string{"hit 3", "any event.hit", "stringer x", "any event.named"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	// Queued delivery, in order.
+	got = nil
+	all.Cancel()
+	all.Cancel()
+	b.Post(hit{1})
+	b.Post(&hit{2})
+	if b.Len() != 2 || len(got) != 0 {
+		t.Fatal("events delivered before Dispatch")
+	}
+	if n := b.Dispatch(); n != 2 || b.Len() != 0 {
+		t.Fatalf("dispatched %d events", n)
+	}
+	if want := []string{"hit 1", "ptr"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestBusHandlers(t *testing.T) {
+	b := NewBus()
+
+	// Events posted by handlers wait for the next Dispatch, and cancelled
+	// subscriptions no longer receive events.
+	var ints []int
+	var sub *Subscription
+	sub = b.Subscribe(func(i int) {
+		ints = append(ints, i)
+		if i < 3 {
+			b.Post(i + 1)
+		} else {
+			sub.Cancel()
+		}
+	})
+	b.Post(1)
+	for b.Dispatch() > 0 {
+	}
+	b.Publish(4)
+	if !reflect.DeepEqual(ints, []int{1, 2, 3}) {
+		t.Fatalf("got %v", ints)
+	}
+
+	// Events are received from channels of interfaces.
+	ch := make(chan fmt.Stringer, 4)
+	ch <- named("a")
+	ch <- nil
+	ch <- named("b")
+	var names []string
+	b.Subscribe(func(n named) { names = append(names, string(n)) })
+	if n := b.PostFrom(ch); n != 2 {
+		t.Fatalf("posted %d events", n)
+	}
+	b.Dispatch()
+	if !reflect.DeepEqual(names, []string{"a", "b"}) {
+		t.Fatalf("got %v", names)
+	}
+
+	for _, bad := range []interface{}{nil, 1, func(a, b int) {}, func(int) int { return 0 }} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("subscribed %T", bad)
+				}
+			}()
+			b.Subscribe(bad)
+		}()
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package event implements a typed publish/subscribe event bus.
+//
+// Subsystems (input, gameplay, audio, UI, etc) communicate through a Bus
+// rather than with channels between packages. Events are plain values of any
+// type, and handlers are functions of a single argument which receive the
+// events assignable to it's type:
+//
+//  type Explosion struct {
+//      Pos lmath.Vec3
+//  }
+//
+//  bus := event.NewBus()
+//  bus.Subscribe(func(e Explosion) {
+//      playSound("boom", e.Pos)
+//  })
+//  bus.Subscribe(func(e *keyboard.ButtonEvent) {
+//      ...
+//  })
+//
+// Events are either delivered synchronously, to all handlers before Publish
+// returns, or queued by Post until Dispatch is called (e.g. once per frame on
+// the main goroutine):
+//
+//  bus.Publish(Explosion{Pos: pos}) // Handlers have run.
+//  bus.Post(Explosion{Pos: pos})    // Handlers run on the next Dispatch.
+//
+// Events from a channel (such as the events of a window) are queued using
+// PostFrom:
+//
+//  events := make(chan window.Event, 256)
+//  w.Notify(events, window.AllEvents)
+//  for {
+//      bus.PostFrom(events)
+//      bus.Dispatch()
+//      ...
+//  }
+package event // import "azul3d.org/engine/event"
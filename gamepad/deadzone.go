@@ -0,0 +1,46 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gamepad
+
+import "math"
+
+// Axial applies an axial deadzone to a single axis value, v, in the range of
+// -1 to +1. Values whose magnitude is below the deadzone become zero, the rest
+// are rescaled such that the output still smoothly covers the entire range:
+//
+//  Axial(0.1, 0.2) == 0
+//  Axial(0.6, 0.2) == 0.5
+//  Axial(1.0, 0.2) == 1.0
+//
+func Axial(v, deadzone float64) float64 {
+	a := math.Abs(v)
+	if a <= deadzone || deadzone >= 1 {
+		return 0
+	}
+	a = (a - deadzone) / (1 - deadzone)
+	if a > 1 {
+		a = 1
+	}
+	return math.Copysign(a, v)
+}
+
+// Radial applies a scaled radial deadzone to the two axes of an analog stick.
+// Unlike applying an axial deadzone to each axis separately, this does not
+// cause the stick to snap onto the X and Y axes near the center.
+//
+// The length of the resulting vector is rescaled like Axial does, and clamped
+// to at most one.
+func Radial(x, y, deadzone float64) (float64, float64) {
+	length := math.Hypot(x, y)
+	if length <= deadzone || deadzone >= 1 {
+		return 0, 0
+	}
+	scaled := (length - deadzone) / (1 - deadzone)
+	if scaled > 1 {
+		scaled = 1
+	}
+	scale := scaled / length
+	return x * scale, y * scale
+}
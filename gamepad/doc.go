@@ -0,0 +1,27 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//go:generate stringer -type=State,Button,Axis -output=stringers.go
+
+// Package gamepad implements various gamepad and joystick related data types.
+//
+// Raw joystick inputs (numbered buttons and axes) vary wildly between devices
+// and platforms. This package maps them onto a standardized gamepad layout,
+// that of an Xbox 360 controller, using mappings in the format of the
+// community maintained SDL_GameControllerDB:
+//
+//  https://github.com/gabomdq/SDL_GameControllerDB
+//
+// Mappings can be loaded into the default database:
+//
+//  f, err := os.Open("gamecontrollerdb.txt")
+//  ... handle err ...
+//  err = gamepad.DefaultDB.Load(f)
+//
+// Deadzones
+//
+// Analog sticks rarely rest at exactly zero. A Watcher applies a (scaled
+// radial) deadzone to both sticks and an axial one to the triggers, such that
+// small amounts of noise do not generate events. See the Radial and Axial
+// functions for details.
+package gamepad // import "azul3d.org/engine/gamepad"
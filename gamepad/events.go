@@ -0,0 +1,96 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gamepad
+
+import (
+	"fmt"
+	"time"
+)
+
+// Event represents a single gamepad event.
+type Event interface {
+	// Time returns the time at which the event occured.
+	Time() time.Time
+
+	// String returns a string representation of the event.
+	String() string
+}
+
+// Connected is an event where a gamepad was connected to the system.
+type Connected struct {
+	T time.Time
+
+	// The ID of the gamepad, which is unique among all connected gamepads.
+	Gamepad int
+
+	// The name of the gamepad, e.g. "Xbox 360 Controller".
+	Name string
+}
+
+// Time implements the Event interface.
+func (c Connected) Time() time.Time {
+	return c.T
+}
+
+// String returns a string representation of this event.
+func (c Connected) String() string {
+	return fmt.Sprintf("Connected(Gamepad=%v, Name=%q, Time=%v)", c.Gamepad, c.Name, c.T)
+}
+
+// Disconnected is an event where a gamepad was disconnected from the system.
+type Disconnected struct {
+	T time.Time
+
+	// The ID of the gamepad.
+	Gamepad int
+}
+
+// Time implements the Event interface.
+func (d Disconnected) Time() time.Time {
+	return d.T
+}
+
+// String returns a string representation of this event.
+func (d Disconnected) String() string {
+	return fmt.Sprintf("Disconnected(Gamepad=%v, Time=%v)", d.Gamepad, d.T)
+}
+
+// ButtonEvent represents a single gamepad button event.
+type ButtonEvent struct {
+	T       time.Time
+	Gamepad int
+	Button  Button
+	State   State
+}
+
+// Time implements the Event interface.
+func (b ButtonEvent) Time() time.Time {
+	return b.T
+}
+
+// String returns a string representation of this event.
+func (b ButtonEvent) String() string {
+	return fmt.Sprintf("ButtonEvent(Gamepad=%v, Button=%v, State=%v, Time=%v)", b.Gamepad, b.Button, b.State, b.T)
+}
+
+// AxisMoved is an event where a gamepad axis has moved.
+type AxisMoved struct {
+	T       time.Time
+	Gamepad int
+	Axis    Axis
+
+	// The new value of the axis, after applying the deadzone.
+	Value float64
+}
+
+// Time implements the Event interface.
+func (a AxisMoved) Time() time.Time {
+	return a.T
+}
+
+// String returns a string representation of this event.
+func (a AxisMoved) String() string {
+	return fmt.Sprintf("AxisMoved(Gamepad=%v, Axis=%v, Value=%f, Time=%v)", a.Gamepad, a.Axis, a.Value, a.T)
+}
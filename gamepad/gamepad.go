@@ -0,0 +1,74 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gamepad
+
+// State represents a single gamepad button state, such as Up or Down.
+type State uint8
+
+// Gamepad button state constants, Down implies the button is currently pressed
+// down, and up implies it is not. The InvalidState is declared to help users
+// detect uninitialized variables.
+const (
+	InvalidState State = iota
+	Down
+	Up
+)
+
+// Button represents a single button of a standardized gamepad.
+type Button uint8
+
+// Gamepad button constants, named after the buttons of an Xbox 360 controller.
+// The Invalid button is declared to help users detect uninitialized variables.
+const (
+	Invalid Button = iota
+	A
+	B
+	X
+	Y
+	Back
+	Guide
+	Start
+	LeftStick
+	RightStick
+	LeftShoulder
+	RightShoulder
+	DPadUp
+	DPadDown
+	DPadLeft
+	DPadRight
+
+	// NumButtons is the number of Button values, including Invalid.
+	NumButtons = int(iota)
+)
+
+// Axis represents a single analog axis of a standardized gamepad.
+type Axis uint8
+
+// Gamepad axis constants. Stick axes are in the range of -1 to +1 (with +Y
+// pointing down, as is convention), triggers are in the range of 0 to +1. The
+// InvalidAxis is declared to help users detect uninitialized variables.
+const (
+	InvalidAxis Axis = iota
+	LeftX
+	LeftY
+	RightX
+	RightY
+	LeftTrigger
+	RightTrigger
+
+	// NumAxes is the number of Axis values, including InvalidAxis.
+	NumAxes = int(iota)
+)
+
+// Snapshot is the state of a single gamepad at an instant in time. Both arrays
+// are indexed by Button and Axis values respectively:
+//
+//	if s.Buttons[gamepad.A] {
+//	    fmt.Println("A is pressed, the left stick is at", s.Axes[gamepad.LeftX])
+//	}
+type Snapshot struct {
+	Buttons [NumButtons]bool
+	Axes    [NumAxes]float64
+}
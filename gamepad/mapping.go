@@ -0,0 +1,336 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gamepad
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// InputKind is the kind of a raw joystick input.
+type InputKind uint8
+
+const (
+	// RawButton is a raw numbered joystick button.
+	RawButton InputKind = iota
+
+	// RawAxis is a raw numbered joystick axis.
+	RawAxis
+
+	// RawHat is a raw numbered joystick hat (i.e. a digital directional pad).
+	RawHat
+)
+
+// Input describes a single raw joystick input that a standardized gamepad
+// button or axis is mapped to.
+type Input struct {
+	// The kind of the raw input.
+	Kind InputKind
+
+	// The index of the raw button, axis, or hat.
+	Index int
+
+	// For hats, the bitmask of the hat direction (1=up, 2=right, 4=down,
+	// 8=left).
+	Mask uint8
+
+	// For axes, whether only the positive (+1) or negative (-1) half of the
+	// axis is used, or the entire axis (0).
+	Half int
+
+	// For axes, whether the axis is inverted.
+	Invert bool
+}
+
+// value returns the value of the input given the raw joystick state, in the
+// range of -1 to +1 (or 0 to +1 for half axes, buttons and hats).
+func (in Input) value(axes []float64, buttons []bool, hats []uint8) float64 {
+	switch in.Kind {
+	case RawButton:
+		if in.Index < len(buttons) && buttons[in.Index] {
+			return 1
+		}
+	case RawHat:
+		if in.Index < len(hats) && hats[in.Index]&in.Mask != 0 {
+			return 1
+		}
+	case RawAxis:
+		if in.Index >= len(axes) {
+			return 0
+		}
+		v := axes[in.Index]
+		if in.Invert {
+			v = -v
+		}
+		switch {
+		case in.Half > 0:
+			if v < 0 {
+				v = 0
+			}
+		case in.Half < 0:
+			if v > 0 {
+				v = 0
+			}
+			v = -v
+		}
+		return v
+	}
+	return 0
+}
+
+// Mapping maps the raw inputs of a joystick onto a standardized gamepad.
+type Mapping struct {
+	// The SDL GUID of the joystick, if any.
+	GUID string
+
+	// The name of the joystick, e.g. "Xbox 360 Controller".
+	Name string
+
+	// The platform the mapping is for, e.g. "Linux", or an empty string if it
+	// is for all platforms.
+	Platform string
+
+	// The raw inputs that each standardized button and axis is mapped to.
+	Buttons map[Button]Input
+	Axes    map[Axis]Input
+}
+
+// ButtonThreshold is the value above which a raw axis that is mapped to a
+// standardized button is considered pressed down.
+const ButtonThreshold = 0.5
+
+// Map maps the given raw joystick inputs onto a standardized gamepad snapshot.
+// Hats are given as bitmasks in the same format as Input.Mask, hats may be nil
+// if the joystick API does not report them.
+func (m *Mapping) Map(axes []float64, buttons []bool, hats []uint8) Snapshot {
+	var s Snapshot
+	for b, in := range m.Buttons {
+		s.Buttons[b] = in.value(axes, buttons, hats) > ButtonThreshold
+	}
+	for a, in := range m.Axes {
+		v := in.value(axes, buttons, hats)
+		if (a == LeftTrigger || a == RightTrigger) && in.Kind == RawAxis && in.Half == 0 {
+			// A full axis mapped to a trigger rests at -1.
+			v = (v + 1) / 2
+		}
+		s.Axes[a] = v
+	}
+	return s
+}
+
+var (
+	mappingButtons = map[string]Button{
+		"a":             A,
+		"b":             B,
+		"x":             X,
+		"y":             Y,
+		"back":          Back,
+		"guide":         Guide,
+		"start":         Start,
+		"leftstick":     LeftStick,
+		"rightstick":    RightStick,
+		"leftshoulder":  LeftShoulder,
+		"rightshoulder": RightShoulder,
+		"dpup":          DPadUp,
+		"dpdown":        DPadDown,
+		"dpleft":        DPadLeft,
+		"dpright":       DPadRight,
+	}
+	mappingAxes = map[string]Axis{
+		"leftx":        LeftX,
+		"lefty":        LeftY,
+		"rightx":       RightX,
+		"righty":       RightY,
+		"lefttrigger":  LeftTrigger,
+		"righttrigger": RightTrigger,
+	}
+)
+
+// parseInput parses a raw input like "b0", "+a2", "a1~" or "h0.4".
+func parseInput(s string) (Input, error) {
+	var in Input
+	if strings.HasSuffix(s, "~") {
+		in.Invert = true
+		s = s[:len(s)-1]
+	}
+	if len(s) > 0 && (s[0] == '+' || s[0] == '-') {
+		in.Half = 1
+		if s[0] == '-' {
+			in.Half = -1
+		}
+		s = s[1:]
+	}
+	if len(s) < 2 {
+		return in, fmt.Errorf("invalid input %q", s)
+	}
+	var err error
+	switch s[0] {
+	case 'b':
+		in.Kind = RawButton
+		in.Index, err = strconv.Atoi(s[1:])
+	case 'a':
+		in.Kind = RawAxis
+		in.Index, err = strconv.Atoi(s[1:])
+	case 'h':
+		in.Kind = RawHat
+		dot := strings.IndexByte(s, '.')
+		if dot < 0 {
+			return in, fmt.Errorf("invalid hat %q", s)
+		}
+		in.Index, err = strconv.Atoi(s[1:dot])
+		if err == nil {
+			var mask int
+			mask, err = strconv.Atoi(s[dot+1:])
+			in.Mask = uint8(mask)
+		}
+	default:
+		return in, fmt.Errorf("invalid input %q", s)
+	}
+	return in, err
+}
+
+// ParseMapping parses a single mapping line in the SDL_GameControllerDB
+// format, for example:
+//
+//  "030000005e0400008e02000014010000,Xbox 360 Controller,a:b0,b:b1,leftx:a0,platform:Linux,"
+//
+// Unknown buttons and axes (e.g. ones introduced by newer versions of the
+// database) are ignored.
+func ParseMapping(line string) (*Mapping, error) {
+	fields := strings.Split(strings.TrimSpace(line), ",")
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("gamepad: invalid mapping %q", line)
+	}
+	m := &Mapping{
+		GUID:    fields[0],
+		Name:    fields[1],
+		Buttons: make(map[Button]Input),
+		Axes:    make(map[Axis]Input),
+	}
+	for _, field := range fields[2:] {
+		if field == "" {
+			continue
+		}
+		colon := strings.IndexByte(field, ':')
+		if colon < 0 {
+			return nil, fmt.Errorf("gamepad: invalid mapping field %q", field)
+		}
+		key, value := field[:colon], field[colon+1:]
+		if key == "platform" {
+			m.Platform = value
+			continue
+		}
+		key = strings.TrimLeft(key, "+-")
+		b, isButton := mappingButtons[key]
+		a, isAxis := mappingAxes[key]
+		if !isButton && !isAxis {
+			continue
+		}
+		in, err := parseInput(value)
+		if err != nil {
+			return nil, fmt.Errorf("gamepad: %s: %v", key, err)
+		}
+		if isButton {
+			m.Buttons[b] = in
+		} else {
+			m.Axes[a] = in
+		}
+	}
+	return m, nil
+}
+
+// platform is the SDL_GameControllerDB name of the current platform.
+var platform = map[string]string{
+	"windows": "Windows",
+	"darwin":  "Mac OS X",
+	"linux":   "Linux",
+	"android": "Android",
+}[runtime.GOOS]
+
+// DB is a database of gamepad mappings. It is safe for use concurrently from
+// multiple goroutines.
+type DB struct {
+	access         sync.RWMutex
+	byGUID, byName map[string]*Mapping
+}
+
+// Add adds the given mapping to the database, replacing an existing one with
+// the same GUID or name. Mappings for other platforms are ignored.
+func (db *DB) Add(m *Mapping) {
+	if m.Platform != "" && m.Platform != platform {
+		return
+	}
+	db.access.Lock()
+	if m.GUID != "" {
+		db.byGUID[m.GUID] = m
+	}
+	if m.Name != "" {
+		db.byName[m.Name] = m
+	}
+	db.access.Unlock()
+}
+
+// Load loads each mapping line from the given reader (e.g. the
+// gamecontrollerdb.txt file) into the database. Blank lines and comments
+// starting with '#' are skipped.
+func (db *DB) Load(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		m, err := ParseMapping(line)
+		if err != nil {
+			return err
+		}
+		db.Add(m)
+	}
+	return scanner.Err()
+}
+
+// Lookup looks up the mapping for the joystick with the given GUID, falling
+// back to a lookup by name (some joystick APIs do not expose GUIDs, in which
+// case guid may be an empty string). If no mapping is found, nil is returned.
+func (db *DB) Lookup(guid, name string) *Mapping {
+	db.access.RLock()
+	defer db.access.RUnlock()
+	if m, ok := db.byGUID[guid]; ok && guid != "" {
+		return m
+	}
+	return db.byName[name]
+}
+
+// NewDB returns a new, empty, database of gamepad mappings.
+func NewDB() *DB {
+	return &DB{
+		byGUID: make(map[string]*Mapping),
+		byName: make(map[string]*Mapping),
+	}
+}
+
+// XInput is the mapping of an XInput (Xbox 360 compatible) controller as
+// reported by the GLFW joystick API on Windows. It is used as the fallback for
+// joysticks without a known mapping.
+var XInput *Mapping
+
+// DefaultDB is the default database of gamepad mappings.
+var DefaultDB = NewDB()
+
+func init() {
+	var err error
+	XInput, err = ParseMapping("xinput,XInput Controller," +
+		"a:b0,b:b1,x:b2,y:b3,leftshoulder:b4,rightshoulder:b5,back:b6,start:b7," +
+		"leftstick:b8,rightstick:b9,dpup:b10,dpright:b11,dpdown:b12,dpleft:b13," +
+		"leftx:a0,lefty:a1~,rightx:a2,righty:a3~,lefttrigger:a4,righttrigger:a5,")
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gamepad
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseMapping(t *testing.T) {
+	m, err := ParseMapping("0300,Test Pad,a:b1,dpup:h0.1,leftx:a0,lefty:a1~,lefttrigger:+a2,righttrigger:a3,misc1:b9,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.GUID != "0300" || m.Name != "Test Pad" {
+		t.Fatalf("got GUID=%q Name=%q", m.GUID, m.Name)
+	}
+	want := map[Axis]Input{
+		LeftX:        {Kind: RawAxis, Index: 0},
+		LeftY:        {Kind: RawAxis, Index: 1, Invert: true},
+		LeftTrigger:  {Kind: RawAxis, Index: 2, Half: 1},
+		RightTrigger: {Kind: RawAxis, Index: 3},
+	}
+	for a, in := range want {
+		if m.Axes[a] != in {
+			t.Errorf("%v = %+v, want %+v", a, m.Axes[a], in)
+		}
+	}
+	if in := m.Buttons[DPadUp]; in != (Input{Kind: RawHat, Index: 0, Mask: 1}) {
+		t.Errorf("DPadUp = %+v", in)
+	}
+
+	s := m.Map([]float64{0.5, 0.25, -0.5, -1}, []bool{false, true}, []uint8{1})
+	if !s.Buttons[A] || !s.Buttons[DPadUp] || s.Buttons[B] {
+		t.Errorf("unexpected buttons %v", s.Buttons)
+	}
+	if s.Axes[LeftX] != 0.5 || s.Axes[LeftY] != -0.25 || s.Axes[LeftTrigger] != 0 || s.Axes[RightTrigger] != 0 {
+		t.Errorf("unexpected axes %v", s.Axes)
+	}
+
+	if _, err := ParseMapping("0300,Bad,a:q1"); err == nil {
+		t.Error("expected error for invalid input")
+	}
+}
+
+func TestDB(t *testing.T) {
+	db := NewDB()
+	err := db.Load(strings.NewReader("# comment\n\nabc,Pad One,a:b0,\ndef,Pad Two,a:b1,platform:NoSuchOS,\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := db.Lookup("abc", ""); m == nil || m.Name != "Pad One" {
+		t.Fatalf("Lookup by GUID = %v", m)
+	}
+	if m := db.Lookup("", "Pad One"); m == nil {
+		t.Fatal("Lookup by name failed")
+	}
+	if m := db.Lookup("def", "Pad Two"); m != nil {
+		t.Fatal("expected mapping for another platform to be ignored")
+	}
+}
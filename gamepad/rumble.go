@@ -0,0 +1,27 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gamepad
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNoRumble is returned by Rumble when the gamepad does not support rumble.
+var ErrNoRumble = errors.New("gamepad: rumble is not supported")
+
+// Rumbler is implemented by platforms that support gamepad vibration. Use a
+// type assertion to check for support, for instance on a window:
+//
+//  r, ok := win.(gamepad.Rumbler)
+//  if ok {
+//      r.Rumble(id, 0.5, 0.5, 200*time.Millisecond)
+//  }
+//
+type Rumbler interface {
+	// Rumble vibrates the low and high frequency motors of the given gamepad
+	// at the given strengths (in the range of 0 to 1) for the given duration.
+	Rumble(gamepad int, low, high float64, d time.Duration) error
+}
@@ -0,0 +1,201 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gamepad
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Default deadzones used by new watchers.
+const (
+	DefaultStickDeadzone   = 0.2
+	DefaultTriggerDeadzone = 0.05
+)
+
+// axisEpsilon is the minimum change in an axis value for which AxisMoved
+// events are generated.
+const axisEpsilon = 1.0 / 256
+
+type pad struct {
+	name     string
+	snapshot Snapshot
+}
+
+// Watcher watches the state of connected gamepads, their buttons, and axes.
+type Watcher struct {
+	access             sync.RWMutex
+	pads               map[int]*pad
+	stickDZ, triggerDZ float64
+}
+
+// String returns a multi-line string representation of this gamepad watcher
+// and it's connected gamepads.
+func (w *Watcher) String() string {
+	bb := new(bytes.Buffer)
+	fmt.Fprintf(bb, "gamepad.Watcher(\n")
+	for _, id := range w.Connected() {
+		w.access.RLock()
+		p := w.pads[id]
+		fmt.Fprintf(bb, "\t%v: %q,\n", id, p.name)
+		w.access.RUnlock()
+	}
+	fmt.Fprintf(bb, ")")
+	return bb.String()
+}
+
+// SetDeadzone sets the radial deadzone applied to both analog sticks, and the
+// axial deadzone applied to both triggers.
+func (w *Watcher) SetDeadzone(stick, trigger float64) {
+	w.access.Lock()
+	w.stickDZ, w.triggerDZ = stick, trigger
+	w.access.Unlock()
+}
+
+// Deadzone returns the deadzones previously set via SetDeadzone.
+func (w *Watcher) Deadzone() (stick, trigger float64) {
+	w.access.RLock()
+	stick, trigger = w.stickDZ, w.triggerDZ
+	w.access.RUnlock()
+	return
+}
+
+// Connect marks the gamepad with the given ID as connected, and returns the
+// Connected event.
+func (w *Watcher) Connect(id int, name string, t time.Time) Event {
+	w.access.Lock()
+	w.pads[id] = &pad{name: name}
+	w.access.Unlock()
+	return Connected{T: t, Gamepad: id, Name: name}
+}
+
+// Disconnect marks the gamepad with the given ID as disconnected, and returns
+// the Disconnected event.
+func (w *Watcher) Disconnect(id int, t time.Time) Event {
+	w.access.Lock()
+	delete(w.pads, id)
+	w.access.Unlock()
+	return Disconnected{T: t, Gamepad: id}
+}
+
+// Update updates the state of the gamepad with the given ID. The deadzones are
+// applied to the raw snapshot, and the events describing the changes from the
+// previous state are returned.
+//
+// If the gamepad is not connected, no events are returned.
+func (w *Watcher) Update(id int, raw Snapshot, t time.Time) []Event {
+	w.access.Lock()
+	defer w.access.Unlock()
+
+	p, ok := w.pads[id]
+	if !ok {
+		return nil
+	}
+
+	// Apply deadzones.
+	s := raw
+	s.Axes[LeftX], s.Axes[LeftY] = Radial(raw.Axes[LeftX], raw.Axes[LeftY], w.stickDZ)
+	s.Axes[RightX], s.Axes[RightY] = Radial(raw.Axes[RightX], raw.Axes[RightY], w.stickDZ)
+	s.Axes[LeftTrigger] = Axial(raw.Axes[LeftTrigger], w.triggerDZ)
+	s.Axes[RightTrigger] = Axial(raw.Axes[RightTrigger], w.triggerDZ)
+
+	var events []Event
+	for b := A; int(b) < NumButtons; b++ {
+		if s.Buttons[b] == p.snapshot.Buttons[b] {
+			continue
+		}
+		state := Up
+		if s.Buttons[b] {
+			state = Down
+		}
+		events = append(events, ButtonEvent{T: t, Gamepad: id, Button: b, State: state})
+	}
+	for a := LeftX; int(a) < NumAxes; a++ {
+		v, last := s.Axes[a], p.snapshot.Axes[a]
+		if v == last {
+			continue
+		}
+		if v-last < axisEpsilon && last-v < axisEpsilon && v != 0 {
+			// Insignificant change, keep the last value such that slow
+			// movements still accumulate into an event eventually.
+			s.Axes[a] = last
+			continue
+		}
+		events = append(events, AxisMoved{T: t, Gamepad: id, Axis: a, Value: v})
+	}
+	p.snapshot = s
+	return events
+}
+
+// Connected returns the IDs of the connected gamepads, in ascending order.
+func (w *Watcher) Connected() []int {
+	w.access.RLock()
+	ids := make([]int, 0, len(w.pads))
+	for id := range w.pads {
+		ids = append(ids, id)
+	}
+	w.access.RUnlock()
+	sort.Ints(ids)
+	return ids
+}
+
+// Name returns the name of the given gamepad, or an empty string if it is not
+// connected.
+func (w *Watcher) Name(id int) string {
+	w.access.RLock()
+	defer w.access.RUnlock()
+	if p, ok := w.pads[id]; ok {
+		return p.name
+	}
+	return ""
+}
+
+// Snapshot returns the current state of the given gamepad (with deadzones
+// applied). The zero value is returned if it is not connected.
+func (w *Watcher) Snapshot(id int) Snapshot {
+	w.access.RLock()
+	defer w.access.RUnlock()
+	if p, ok := w.pads[id]; ok {
+		return p.snapshot
+	}
+	return Snapshot{}
+}
+
+// State returns the current state of the given button of the given gamepad.
+func (w *Watcher) State(id int, b Button) State {
+	if w.Snapshot(id).Buttons[b] {
+		return Down
+	}
+	return Up
+}
+
+// Down tells if the given button of the given gamepad is currently down.
+func (w *Watcher) Down(id int, b Button) bool {
+	return w.State(id, b) == Down
+}
+
+// Up tells if the given button of the given gamepad is currently up.
+func (w *Watcher) Up(id int, b Button) bool {
+	return w.State(id, b) == Up
+}
+
+// Axis returns the current value of the given axis of the given gamepad (with
+// deadzones applied).
+func (w *Watcher) Axis(id int, a Axis) float64 {
+	return w.Snapshot(id).Axes[a]
+}
+
+// NewWatcher returns a new, initialized, gamepad watcher using the default
+// deadzones.
+func NewWatcher() *Watcher {
+	return &Watcher{
+		pads:      make(map[int]*pad),
+		stickDZ:   DefaultStickDeadzone,
+		triggerDZ: DefaultTriggerDeadzone,
+	}
+}
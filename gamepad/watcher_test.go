@@ -0,0 +1,68 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gamepad
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWatcher(t *testing.T) {
+	w := NewWatcher()
+	now := time.Now()
+	if ev := w.Connect(2, "Pad", now); ev != (Connected{T: now, Gamepad: 2, Name: "Pad"}) {
+		t.Fatalf("Connect returned %v", ev)
+	}
+
+	var raw Snapshot
+	raw.Buttons[A] = true
+	raw.Axes[LeftX] = 0.1 // Within the deadzone.
+	raw.Axes[RightTrigger] = 1
+	events := w.Update(2, raw, now)
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2: %v", len(events), events)
+	}
+	if ev := events[0].(ButtonEvent); ev.Button != A || ev.State != Down {
+		t.Fatalf("got %v, want A Down", ev)
+	}
+	if ev := events[1].(AxisMoved); ev.Axis != RightTrigger || ev.Value != 1 {
+		t.Fatalf("got %v, want RightTrigger=1", ev)
+	}
+	if !w.Down(2, A) || !w.Up(2, B) || w.Axis(2, LeftX) != 0 {
+		t.Fatal("unexpected watcher state")
+	}
+
+	// No changes, no events.
+	if events := w.Update(2, raw, now); len(events) != 0 {
+		t.Fatalf("got %v, want no events", events)
+	}
+
+	if got := w.Connected(); len(got) != 1 || got[0] != 2 {
+		t.Fatalf("Connected() = %v, want [2]", got)
+	}
+	w.Disconnect(2, now)
+	if w.Name(2) != "" || len(w.Connected()) != 0 {
+		t.Fatal("expected gamepad to be disconnected")
+	}
+	if events := w.Update(2, raw, now); events != nil {
+		t.Fatalf("got %v, want no events for disconnected gamepad", events)
+	}
+}
+
+func TestDeadzone(t *testing.T) {
+	if v := Axial(0.1, 0.2); v != 0 {
+		t.Fatalf("Axial(0.1, 0.2) = %v, want 0", v)
+	}
+	if v := Axial(-0.6, 0.2); v < -0.5001 || v > -0.4999 {
+		t.Fatalf("Axial(-0.6, 0.2) = %v, want -0.5", v)
+	}
+	x, y := Radial(0.6, 0.8, 0.5)
+	if x < 0.5999 || x > 0.6001 || y < 0.7999 || y > 0.8001 {
+		t.Fatalf("Radial(0.6, 0.8, 0.5) = %v, %v, want 0.6, 0.8", x, y)
+	}
+	if x, y := Radial(0.1, 0.1, 0.2); x != 0 || y != 0 {
+		t.Fatalf("Radial(0.1, 0.1, 0.2) = %v, %v, want 0, 0", x, y)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package billboard implements billboards, textured quads which always face
+// the camera, and impostors: billboards showing a mesh captured from many
+// directions, drawn in place of it in the distance (e.g. trees and props).
+//
+// Billboards are turned towards the camera by their shader (see package
+// shaders), as such they cost no more than any other quad:
+//
+//  b := billboard.New(tex, 2, 4, billboard.Cylindrical, glsl.GL2)
+//  b.SetPos(lmath.Vec3{X: 10, Y: 20})
+//
+// An impostor is baked once from the mesh's object, using a render-to-texture
+// canvas, and then drawn in place of the object beyond some distance:
+//
+//  imp, err := billboard.Bake(d, tree, billboard.Config{})
+//  if err != nil {
+//      log.Fatal(err)
+//  }
+//  far := imp.Object(glsl.GL2)
+//  far.Transform = tree.Transform
+package billboard // import "azul3d.org/engine/gfx/billboard"
+
+import (
+	"fmt"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/glsl"
+	"azul3d.org/engine/gfx/shaders"
+)
+
+// Constraint constrains how a billboard turns to face the camera.
+type Constraint int
+
+const (
+	// Spherical billboards turn freely, always facing the camera head on
+	// (e.g. particles and sprites).
+	Spherical Constraint = iota
+
+	// Cylindrical billboards only turn about their Z axis, staying upright
+	// when viewed from above or below (e.g. trees and grass).
+	Cylindrical
+)
+
+// String returns the name of the constraint, e.g. "Spherical".
+func (c Constraint) String() string {
+	switch c {
+	case Spherical:
+		return "Spherical"
+	case Cylindrical:
+		return "Cylindrical"
+	}
+	return fmt.Sprintf("Constraint(%d)", int(c))
+}
+
+// Kind returns the kind of shader which draws billboards of the constraint.
+func (c Constraint) Kind() shaders.Kind {
+	if c == Cylindrical {
+		return shaders.CylindricalBillboard
+	}
+	return shaders.Billboard
+}
+
+// Quad returns a new quad mesh on the X/Z plane spanning the given
+// rectangle, with the texture coordinates spanning the whole texture.
+func Quad(minX, minZ, maxX, maxZ float64) *gfx.Mesh {
+	m := gfx.NewMesh()
+	x0, z0, x1, z1 := float32(minX), float32(minZ), float32(maxX), float32(maxZ)
+	m.Vertices = []gfx.Vec3{
+		{X: x0, Z: z0},
+		{X: x1, Z: z0},
+		{X: x1, Z: z1},
+		{X: x0, Z: z1},
+	}
+	m.TexCoords = []gfx.TexCoordSet{{Slice: []gfx.TexCoord{
+		{U: 0, V: 0},
+		{U: 1, V: 0},
+		{U: 1, V: 1},
+		{U: 0, V: 1},
+	}}}
+	m.Indices = []uint32{0, 1, 2, 0, 2, 3}
+	return m
+}
+
+// New returns a new billboard object of the given width and height, drawing
+// the given texture with a shader of the given constraint, preprocessed for
+// the given target. Spherical billboards are centered on the object's
+// position, and cylindrical ones stand on it.
+func New(t *gfx.Texture, width, height float64, c Constraint, target glsl.Target) *gfx.Object {
+	o := gfx.NewObject()
+	hw := width / 2
+	if c == Cylindrical {
+		o.Meshes = []*gfx.Mesh{Quad(-hw, 0, hw, height)}
+	} else {
+		o.Meshes = []*gfx.Mesh{Quad(-hw, -height/2, hw, height/2)}
+	}
+	o.Textures = []*gfx.Texture{t}
+	o.Shader = shaders.New(c.Kind(), target)
+	o.State = gfx.NewState()
+	o.State.AlphaMode = gfx.BinaryAlpha
+	o.State.FaceCulling = gfx.NoFaceCulling
+	return o
+}
@@ -0,0 +1,82 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package billboard
+
+import (
+	"image"
+	"testing"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/glsl"
+	"azul3d.org/engine/gfx/internal/pass"
+	"azul3d.org/engine/lmath"
+)
+
+func TestNew(t *testing.T) {
+	tex := gfx.NewTexture()
+	for _, tc := range []struct {
+		c        Constraint
+		min, max lmath.Vec3
+	}{
+		{Spherical, lmath.Vec3{X: -1, Z: -2}, lmath.Vec3{X: 1, Z: 2}},
+		{Cylindrical, lmath.Vec3{X: -1}, lmath.Vec3{X: 1, Z: 4}},
+	} {
+		o := New(tex, 2, 4, tc.c, glsl.GL2)
+		if o.Shader.Name != tc.c.Kind().String() || o.Textures[0] != tex {
+			t.Errorf("%v: wrong shader %q", tc.c, o.Shader.Name)
+		}
+		if b := o.Meshes[0].Bounds(); b.Min != tc.min || b.Max != tc.max {
+			t.Errorf("%v: quad bounds %v", tc.c, b)
+		}
+	}
+	if Constraint(5).String() != "Constraint(5)" {
+		t.Error("wrong invalid constraint name")
+	}
+}
+
+func TestBake(t *testing.T) {
+	// A 2x2x4 box standing on the origin.
+	m := gfx.NewMesh()
+	m.Vertices = []gfx.Vec3{{X: -1, Y: -1}, {X: 1, Y: 1, Z: 4}}
+	o := gfx.NewObject()
+	o.Meshes = []*gfx.Mesh{m}
+
+	if _, err := Bake(gfx.Nil(), o, Config{}); err == nil {
+		t.Fatal("expected an error without render-to-texture support")
+	}
+	if _, err := Bake(&pass.RTTDevice{Device: gfx.Nil()}, gfx.NewObject(), Config{}); err == nil {
+		t.Fatal("expected an error without meshes")
+	}
+
+	d := &pass.RTTDevice{Device: gfx.Nil()}
+	imp, err := Bake(d, o, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each frame is 2*sqrt(2) units wide and 4 units high.
+	if want := image.Rect(0, 0, 91*8, 128); d.Config.Bounds != want || imp.Texture != d.Config.Color {
+		t.Fatalf("atlas of %v, want %v", d.Config.Bounds, want)
+	}
+	if f := imp.Object(glsl.GL2).Shader.Inputs["Frames"]; f != float32(8) {
+		t.Fatalf("got %v frames", f)
+	}
+
+	for _, tc := range []struct {
+		frame   int
+		forward lmath.Vec3
+	}{
+		{0, lmath.Vec3{X: -1}},
+		{2, lmath.Vec3{Y: -1}},
+		{4, lmath.Vec3{X: 1}},
+	} {
+		mat := imp.Camera(tc.frame).T.Mat4()
+		if got := (lmath.Vec3{Y: 1}).TransformVecMat4(mat); !got.AlmostEquals(tc.forward, 1e-9) {
+			t.Errorf("frame %d: camera looks along %v, want %v", tc.frame, got, tc.forward)
+		}
+		if got := (lmath.Vec3{}).TransformMat4(mat); !lmath.AlmostEqual(got.Z, 2, 1e-9) {
+			t.Errorf("frame %d: camera at %v", tc.frame, got)
+		}
+	}
+}
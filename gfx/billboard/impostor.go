@@ -0,0 +1,160 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package billboard
+
+import (
+	"errors"
+	"image"
+	"math"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/glsl"
+	"azul3d.org/engine/gfx/portal"
+	"azul3d.org/engine/gfx/shaders"
+	"azul3d.org/engine/lmath"
+)
+
+// Config configures the baking of an impostor. Zero fields take their
+// default values.
+type Config struct {
+	// Frames is the number of directions about the object's Z axis that it
+	// is captured from. The default is 8.
+	Frames int
+
+	// Size is the height of each frame of the atlas in pixels, the width
+	// follows the aspect ratio of the object. The default is 128.
+	Size int
+}
+
+// withDefaults returns the configuration with zero fields set to their
+// default values.
+func (c Config) withDefaults() Config {
+	if c.Frames <= 0 {
+		c.Frames = 8
+	}
+	if c.Size <= 0 {
+		c.Size = 128
+	}
+	return c
+}
+
+// Impostor is a mesh captured from a number of directions about it's Z axis
+// into the frames of an atlas, drawn as a cylindrical billboard showing the
+// frame nearest to the direction that it is viewed from.
+type Impostor struct {
+	// Frames is the number of frames of the atlas, laid out horizontally.
+	// The first frame views the object from it's +X axis, and each frame
+	// after it is rotated counter-clockwise about the Z axis.
+	Frames int
+
+	// Texture is the atlas, whose alpha channel is zero where the object was
+	// not drawn.
+	Texture *gfx.Texture
+
+	// Mesh is the quad that the atlas is drawn onto, in the object's space.
+	Mesh *gfx.Mesh
+
+	// The radius of the object about it's Z axis, and it's extent along it.
+	radius, minZ, maxZ float64
+}
+
+// Camera returns the orthographic camera that the given frame of the atlas is
+// captured with, in the object's space.
+func (i *Impostor) Camera(frame int) *portal.Camera {
+	angle := 2 * math.Pi * float64(frame) / float64(i.Frames)
+	center := lmath.Vec3{Z: (i.minZ + i.maxZ) / 2}
+	dir := lmath.Vec3{X: math.Cos(angle), Y: math.Sin(angle)}
+
+	// The object lies between one and three radii away from the camera.
+	t := gfx.NewTransform()
+	t.SetPos(center.Add(dir.MulScalar(2 * i.radius)))
+	t.LookAt(center, lmath.Vec3{Z: 1})
+	h := (i.maxZ - i.minZ) / 2
+	return &portal.Camera{
+		T: t,
+		P: gfx.ConvertMat4(lmath.Mat4Ortho(-i.radius, i.radius, -h, h, i.radius/2, 4*i.radius)),
+	}
+}
+
+// Object returns a new object drawing the impostor, using the
+// shaders.Impostor shader preprocessed for the given target. It should be
+// given the transform of the object that the impostor was baked from.
+func (i *Impostor) Object(target glsl.Target) *gfx.Object {
+	o := gfx.NewObject()
+	o.Meshes = []*gfx.Mesh{i.Mesh}
+	o.Textures = []*gfx.Texture{i.Texture}
+	o.Shader = shaders.New(shaders.Impostor, target)
+	o.Shader.Inputs["Frames"] = float32(i.Frames)
+	o.State = gfx.NewState()
+	o.State.AlphaMode = gfx.BinaryAlpha
+	o.State.FaceCulling = gfx.NoFaceCulling
+	return o
+}
+
+// Bake captures an impostor of the given object, drawing it into a
+// render-to-texture canvas of the given device from each direction. The
+// object is captured in it's own space, as such it's transform does not
+// matter. An error is returned if the object has no meshes, or if the device
+// does not support render-to-texture.
+func Bake(d gfx.Device, o *gfx.Object, c Config) (*Impostor, error) {
+	c = c.withDefaults()
+	if len(o.Meshes) == 0 {
+		return nil, errors.New("billboard: object has no meshes")
+	}
+	var b lmath.Rect3
+	for i, m := range o.Meshes {
+		if i == 0 {
+			b = m.Bounds()
+			continue
+		}
+		b = b.Union(m.Bounds())
+	}
+
+	// The radius of the cylinder about the Z axis which holds the object.
+	imp := &Impostor{Frames: c.Frames, minZ: b.Min.Z, maxZ: b.Max.Z}
+	for _, x := range []float64{b.Min.X, b.Max.X} {
+		for _, y := range []float64{b.Min.Y, b.Max.Y} {
+			imp.radius = math.Max(imp.radius, math.Hypot(x, y))
+		}
+	}
+	if imp.radius == 0 || imp.maxZ <= imp.minZ {
+		return nil, errors.New("billboard: object has empty bounds")
+	}
+	imp.Mesh = Quad(-imp.radius, imp.minZ, imp.radius, imp.maxZ)
+	width := int(math.Ceil(float64(c.Size) * 2 * imp.radius / (imp.maxZ - imp.minZ)))
+	if width < 1 {
+		width = 1
+	}
+
+	// The atlas needs an alpha channel, regardless of the device's.
+	p := d.Precision()
+	p.AlphaBits = 8
+	cfg := d.Info().RTTFormats.ChooseConfig(p, false)
+	if cfg.ColorFormat == gfx.ZeroTexFormat {
+		return nil, errors.New("billboard: render-to-texture is not supported")
+	}
+	cfg.Bounds = image.Rect(0, 0, width*c.Frames, c.Size)
+	cfg.Color = gfx.NewTexture()
+	cfg.Color.MinFilter = gfx.Linear
+	cfg.Color.MagFilter = gfx.Linear
+	cfg.Color.WrapU = gfx.Clamp
+	cfg.Color.WrapV = gfx.Clamp
+	canvas := d.RenderToTexture(cfg)
+	if canvas == nil {
+		return nil, errors.New("billboard: render-to-texture is not supported")
+	}
+	imp.Texture = cfg.Color
+
+	for f := 0; f < c.Frames; f++ {
+		r := image.Rect(f*width, 0, (f+1)*width, c.Size)
+		cam := imp.Camera(f)
+		cam.T.SetParent(o.Transform)
+		canvas.Clear(r, gfx.Color{})
+		canvas.ClearDepth(r, 1.0)
+		canvas.Draw(r, o, cam)
+	}
+	canvas.Render()
+	return imp, nil
+}
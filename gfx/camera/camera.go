@@ -14,7 +14,11 @@
 // invoking Update.
 //
 // The type of camera can be switched at runtime by changing mycam.Ortho = true
-// as needed, and then calling Update.
+// as needed, and then calling Update. To switch smoothly (e.g. in an editor)
+// use MatchOrtho or MatchPerspective with a LensTransition instead.
+//
+// Cameras can be moved by user input using the stock controllers of package
+// control.
 package camera // import "azul3d.org/engine/gfx/camera"
 
 import (
@@ -56,6 +60,18 @@ type Camera struct {
 	// it is a projection (3D) camera.
 	Ortho bool
 
+	// OrthoHeight is the height of the area (in world units) viewed by an
+	// orthographic camera, centered on the camera, with the width following
+	// the aspect ratio of the view. If zero, one world unit equals one pixel
+	// and the origin is at the bottom-left of the view (e.g. for 2D
+	// interfaces).
+	OrthoHeight float64
+
+	// Jitter is an offset of the projection in pixels, applied by Update.
+	// It is changed each frame by a subpixel amount for temporal
+	// anti-aliasing (see package taa), and is typically zero otherwise.
+	Jitter lmath.Vec2
+
 	// P is the calculated projection matrix of the camera, as returned by the
 	// Projection method.
 	P gfx.Mat4
@@ -81,13 +97,69 @@ func (c *Camera) Projection() gfx.Mat4 {
 // If ok=false is returned then the point is outside of the camera's view and
 // the returned point may not be meaningful.
 func (c *Camera) Project(p3 lmath.Vec3) (p2 lmath.Vec2, ok bool) {
+	p2, ok = c.viewProjection().Project(p3)
+	return
+}
+
+// viewProjection returns the combined view and projection matrix of the
+// camera, which transforms world space points into clip space.
+func (c *Camera) viewProjection() lmath.Mat4 {
 	cameraInv, _ := c.Object.Transform.Mat4().Inverse()
 	cameraInv = cameraInv.Mul(zUpRightToYUpRight)
+	return cameraInv.Mul(c.P.Mat4())
+}
 
-	vp := cameraInv.Mul(c.P.Mat4())
+// Frustum returns the world space viewing frustum of the camera, e.g. for
+// culling objects outside of the camera's view.
+func (c *Camera) Frustum() lmath.Frustum {
+	return lmath.FrustumFromMat4(c.viewProjection())
+}
 
-	p2, ok = vp.Project(p3)
-	return
+// Unproject returns the 3D point in the world given a 2D point in normalized
+// device space coordinates and a depth in the range of [-1, 1] (where -1 is
+// the near plane and 1 is the far plane). It is the inverse of Project.
+//
+// If ok=false is returned then the projection is not invertible and the
+// returned point is not meaningful.
+func (c *Camera) Unproject(p2 lmath.Vec2, depth float64) (p3 lmath.Vec3, ok bool) {
+	vpInv, ok := c.viewProjection().Inverse()
+	if !ok {
+		return lmath.Vec3Zero, false
+	}
+	p4 := lmath.Vec4{X: p2.X, Y: p2.Y, Z: depth, W: 1.0}.Transform(vpInv)
+	if p4.W == 0 {
+		return lmath.Vec3Zero, false
+	}
+	recipW := 1.0 / p4.W
+	return lmath.Vec3{X: p4.X * recipW, Y: p4.Y * recipW, Z: p4.Z * recipW}, true
+}
+
+// NDC returns the normalized device space coordinates of the given point in
+// window coordinates (i.e. pixels relative to the upper-left corner of the
+// window, as with window.CursorMoved events), using the camera's View.
+func (c *Camera) NDC(windowPos lmath.Vec2) lmath.Vec2 {
+	w, h := float64(c.View.Dx()), float64(c.View.Dy())
+	return lmath.Vec2{
+		X: 2*(windowPos.X-float64(c.View.Min.X))/w - 1,
+		Y: 1 - 2*(windowPos.Y-float64(c.View.Min.Y))/h,
+	}
+}
+
+// PickRay returns the world space ray from the camera through the given point
+// in window coordinates (see NDC), for instance to pick objects under the
+// mouse cursor:
+//
+//  ray := cam.PickRay(lmath.Vec2{X: cursorX, Y: cursorY})
+//  picked, _ := gfx.Pick(ray, objects...)
+//
+// The ray starts at the near plane and has a normalized direction. For
+// orthographic cameras all rays are parallel.
+func (c *Camera) PickRay(windowPos lmath.Vec2) lmath.Ray {
+	ndc := c.NDC(windowPos)
+	near, _ := c.Unproject(ndc, -1)
+	far, _ := c.Unproject(ndc, 1)
+	dir, _ := far.Sub(near).Normalized()
+	return lmath.Ray{Origin: near, Dir: dir}
 }
 
 // Copy returns a new copy of this Camera.
@@ -106,21 +178,26 @@ func (c *Camera) Update(view image.Rectangle) {
 		c.debugUpdate()
 	}
 
-	if c.Ortho {
-		// An orthographic camera projection.
-		w := float64(c.View.Dx())
-		w = float64(int((w / 2.0)) * 2)
-		h := float64(c.View.Dy())
-		h = float64(int((h / 2.0)) * 2)
-		m := lmath.Mat4Ortho(0, w, 0, h, c.Near, c.Far)
-		c.P = gfx.ConvertMat4(m)
-		return
+	c.P = gfx.ConvertMat4(c.Lens().projection(c.View).Mul(c.jitter()))
+}
+
+// jitter returns the matrix offsetting clip space coordinates by the camera's
+// Jitter.
+func (c *Camera) jitter() lmath.Mat4 {
+	if c.Jitter == lmath.Vec2Zero || c.View.Empty() {
+		return lmath.Mat4Identity
 	}
+	return lmath.Mat4FromTranslation(lmath.Vec3{
+		X: 2 * c.Jitter.X / float64(c.View.Dx()),
+		Y: 2 * c.Jitter.Y / float64(c.View.Dy()),
+	})
+}
 
-	// An perspective camera projection.
-	aspectRatio := float64(c.View.Dx()) / float64(c.View.Dy())
-	m := lmath.Mat4Perspective(c.FOV, aspectRatio, c.Near, c.Far)
-	c.P = gfx.ConvertMat4(m)
+// Unjittered returns the projection matrix of the camera without it's Jitter
+// applied.
+func (c *Camera) Unjittered() gfx.Mat4 {
+	inv, _ := c.jitter().Inverse()
+	return gfx.ConvertMat4(c.P.Mat4().Mul(inv))
 }
 
 // Destroy destroys this camera for use by other callees to New. You must not
@@ -148,6 +225,7 @@ var camPool = sync.Pool{
 //   Near = 0.1
 //   Far = 1000
 //   Ortho = false
+//   OrthoHeight = 0
 //
 func New(view image.Rectangle) *Camera {
 	c := camPool.Get().(*Camera)
@@ -155,6 +233,8 @@ func New(view image.Rectangle) *Camera {
 	c.Far = 1000
 	c.FOV = 75
 	c.Ortho = false
+	c.OrthoHeight = 0
+	c.Jitter = lmath.Vec2Zero
 	c.Update(view)
 	return c
 }
@@ -166,6 +246,7 @@ func New(view image.Rectangle) *Camera {
 //   Far = 1000
 //   FOV = 75
 //   Ortho = true
+//   OrthoHeight = 0
 //
 func NewOrtho(view image.Rectangle) *Camera {
 	c := camPool.Get().(*Camera)
@@ -173,6 +254,8 @@ func NewOrtho(view image.Rectangle) *Camera {
 	c.Far = 1000
 	c.FOV = 75
 	c.Ortho = true
+	c.OrthoHeight = 0
+	c.Jitter = lmath.Vec2Zero
 	c.Update(view)
 	return c
 }
@@ -6,9 +6,127 @@ package camera
 
 import (
 	"image"
+	"testing"
 
 	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/lmath"
 )
 
 // A check for whether or not *camera.Camera implements gfx.Camera properly.
 var _ gfx.Camera = New(image.Rectangle{})
+
+func TestUnproject(t *testing.T) {
+	c := New(image.Rect(0, 0, 200, 100))
+	c.Transform().SetPos(lmath.Vec3{X: 1, Y: -5, Z: 2})
+	c.Transform().SetRot(lmath.Vec3{X: 10, Z: 30})
+	p := lmath.Vec3{X: 2, Y: 3, Z: 1}
+	p2, ok := c.Project(p)
+	if !ok {
+		t.Fatal("point not in view")
+	}
+
+	// Unprojecting at any depth must lie on the line through the point.
+	near, _ := c.Unproject(p2, -1)
+	far, _ := c.Unproject(p2, 1)
+	a, _ := p.Sub(near).Normalized()
+	b, _ := far.Sub(near).Normalized()
+	if !a.AlmostEquals(b, 1e-6) {
+		t.Fatalf("unprojected line %v -> %v does not pass through %v", near, far, p)
+	}
+}
+
+func TestPickRay(t *testing.T) {
+	c := New(image.Rect(0, 0, 200, 100))
+	c.Transform().SetPos(lmath.Vec3{Y: -10})
+
+	// The center of the window looks straight ahead.
+	r := c.PickRay(lmath.Vec2{X: 100, Y: 50})
+	if !r.Dir.AlmostEquals(lmath.Vec3{Y: 1}, 1e-6) {
+		t.Fatal("center ray is not forward:", r)
+	}
+	if !lmath.AlmostEqual(r.Origin.Y, -10+c.Near, 1e-6) {
+		t.Fatal("ray does not start at the near plane:", r)
+	}
+
+	// The upper-left corner looks left and up.
+	r = c.PickRay(lmath.Vec2{X: 0, Y: 0})
+	if r.Dir.X >= 0 || r.Dir.Z <= 0 {
+		t.Fatal("upper-left ray is not left and up:", r)
+	}
+
+	// Pick a cube in front of the camera.
+	cube := gfx.NewObject()
+	cube.Meshes = []*gfx.Mesh{cubeMesh()}
+	cube.Transform.SetPos(lmath.Vec3{Y: 5})
+	other := gfx.NewObject()
+	other.Meshes = []*gfx.Mesh{cubeMesh()}
+	other.Transform.SetPos(lmath.Vec3{X: 50})
+	picked, dist := gfx.Pick(c.PickRay(lmath.Vec2{X: 100, Y: 50}), other, cube)
+	if picked != cube {
+		t.Fatal("did not pick the cube")
+	}
+	if want := 15 - 1 - c.Near; !lmath.AlmostEqual(dist, want, 1e-6) {
+		t.Fatalf("got distance %v, want %v", dist, want)
+	}
+}
+
+// cubeMesh returns a indexed 2x2x2 cube mesh centered at the origin.
+func cubeMesh() *gfx.Mesh {
+	m := gfx.NewMesh()
+	for i := 0; i < 8; i++ {
+		v := gfx.Vec3{X: -1, Y: -1, Z: -1}
+		if i&1 != 0 {
+			v.X = 1
+		}
+		if i&2 != 0 {
+			v.Y = 1
+		}
+		if i&4 != 0 {
+			v.Z = 1
+		}
+		m.Vertices = append(m.Vertices, v)
+	}
+	m.Indices = []uint32{
+		0, 1, 3, 0, 3, 2, // -Z
+		4, 6, 7, 4, 7, 5, // +Z
+		0, 4, 5, 0, 5, 1, // -Y
+		2, 3, 7, 2, 7, 6, // +Y
+		0, 2, 6, 0, 6, 4, // -X
+		1, 5, 7, 1, 7, 3, // +X
+	}
+	return m
+}
+
+func TestFrustum(t *testing.T) {
+	c := New(image.Rect(0, 0, 200, 100))
+	c.Transform().SetPos(lmath.Vec3{Y: -10})
+	f := c.Frustum()
+	if !f.Contains(lmath.Vec3{}) {
+		t.Fatal("point in front of the camera not contained")
+	}
+	if f.Contains(lmath.Vec3{Y: -20}) {
+		t.Fatal("point behind the camera contained")
+	}
+	if f.OverlapsSphere(lmath.Sphere{Center: lmath.Vec3{X: 100}, Radius: 1}) {
+		t.Fatal("sphere to the right of the camera overlaps")
+	}
+}
+
+func TestJitter(t *testing.T) {
+	c := New(image.Rect(0, 0, 200, 100))
+	c.Transform().SetPos(lmath.Vec3{Y: -10})
+	p := lmath.Vec3{X: 1, Z: 2}
+	before, _ := c.Project(p)
+	unjittered := c.P
+
+	// Each pixel of the 200x100 view spans 0.01x0.02 in NDC space.
+	c.Jitter = lmath.Vec2{X: 0.5, Y: -1}
+	c.Update(c.View)
+	after, _ := c.Project(p)
+	if !after.Sub(before).AlmostEquals(lmath.Vec2{X: 0.005, Y: -0.02}, 1e-6) {
+		t.Fatalf("jitter moved the point from %v to %v", before, after)
+	}
+	if !c.Unjittered().Mat4().AlmostEquals(unjittered.Mat4(), 1e-6) {
+		t.Fatal("unjittered projection differs")
+	}
+}
@@ -0,0 +1,436 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package control implements controllers which move cameras in response to
+// user input: Orbit (for model viewers and editors), Fly (for free-flying
+// around a scene) and FirstPerson (for FPS style movement).
+//
+// It is separate from package camera such that using cameras does not require
+// the window package (and it's cgo dependencies).
+package control // import "azul3d.org/engine/gfx/camera/control"
+
+import (
+	"math"
+
+	"azul3d.org/engine/gfx/camera"
+	"azul3d.org/engine/gfx/window"
+	"azul3d.org/engine/keyboard"
+	"azul3d.org/engine/lmath"
+	"azul3d.org/engine/mouse"
+)
+
+// Events is the event mask of the window events which controllers handle, for
+// use with Window.Notify.
+const Events = window.CursorMovedEvents | window.MouseEvents | window.KeyboardButtonEvents
+
+// Controller moves a camera in response to user input. Window events are
+// passed to HandleEvent as they arrive, and Update is called once per frame:
+//
+//  events := make(chan window.Event, 256)
+//  w.Notify(events, control.Events)
+//  ctrl := control.NewFly(cam)
+//  for {
+//      ... for each pending event ...
+//          ctrl.HandleEvent(ev)
+//      ctrl.Update(clock.Dt())
+//      ... render ...
+//  }
+//
+// Controllers, like cameras, are not safe for access from multiple goroutines
+// concurrently.
+type Controller interface {
+	// HandleEvent handles a single window event, other types of events are
+	// ignored.
+	HandleEvent(ev window.Event)
+
+	// Update updates the camera, given the time in seconds since the last
+	// update.
+	Update(dt float64)
+}
+
+// input tracks the user input (held keys and mouse buttons, cursor motion, and
+// scrolling) that occurs between updates.
+type input struct {
+	keys    map[keyboard.Key]bool
+	buttons map[mouse.Button]bool
+
+	// The last absolute cursor position, if any.
+	cursorX, cursorY float64
+	haveCursor       bool
+
+	// Cursor motion and scrolling since the last call to take.
+	dx, dy, scroll float64
+}
+
+// handle handles the given window event.
+func (in *input) handle(ev window.Event) {
+	switch e := ev.(type) {
+	case keyboard.ButtonEvent:
+		if in.keys == nil {
+			in.keys = make(map[keyboard.Key]bool)
+		}
+		in.keys[e.Key] = e.State == keyboard.Down
+	case mouse.ButtonEvent:
+		if in.buttons == nil {
+			in.buttons = make(map[mouse.Button]bool)
+		}
+		in.buttons[e.Button] = e.State == mouse.Down
+	case mouse.Scrolled:
+		in.scroll += e.Y
+	case window.CursorMoved:
+		if e.Delta {
+			in.dx += e.X
+			in.dy += e.Y
+			return
+		}
+		if in.haveCursor {
+			in.dx += e.X - in.cursorX
+			in.dy += e.Y - in.cursorY
+		}
+		in.cursorX, in.cursorY = e.X, e.Y
+		in.haveCursor = true
+	}
+}
+
+// key tells whether the given key is held down.
+func (in *input) key(k keyboard.Key) bool {
+	return in.keys[k]
+}
+
+// button tells whether the given mouse button is held down. The Invalid
+// button is always considered held down.
+func (in *input) button(b mouse.Button) bool {
+	return b == mouse.Invalid || in.buttons[b]
+}
+
+// take returns and resets the cursor motion and scrolling since the last call.
+func (in *input) take() (dx, dy, scroll float64) {
+	dx, dy, scroll = in.dx, in.dy, in.scroll
+	in.dx, in.dy, in.scroll = 0, 0, 0
+	return
+}
+
+// axis returns 1, -1, or 0 depending on which of the given keys are held.
+func (in *input) axis(pos, neg keyboard.Key) float64 {
+	var v float64
+	if in.key(pos) {
+		v++
+	}
+	if in.key(neg) {
+		v--
+	}
+	return v
+}
+
+// clamp clamps v to the range [min, max].
+func clamp(v, min, max float64) float64 {
+	return math.Max(min, math.Min(max, v))
+}
+
+// directions returns the forward, right, and up directions of a camera with
+// the given yaw and pitch (in degrees).
+func directions(yaw, pitch float64) (forward, right, up lmath.Vec3) {
+	y, p := lmath.Radians(yaw), lmath.Radians(pitch)
+	sy, cy := math.Sincos(y)
+	sp, cp := math.Sincos(p)
+	forward = lmath.Vec3{X: -sy * cp, Y: cy * cp, Z: sp}
+	right = lmath.Vec3{X: cy, Y: sy}
+	up = right.Cross(forward)
+	return
+}
+
+// look applies the given yaw and pitch (in degrees) to the camera.
+func look(c *camera.Camera, yaw, pitch float64) {
+	c.Transform().SetRot(lmath.Vec3{X: pitch, Z: yaw})
+}
+
+// Orbit is a controller which orbits the camera around a target point: it
+// rotates while RotateButton is held, pans the target while PanButton is held,
+// and zooms when scrolling.
+type Orbit struct {
+	input
+
+	// The camera to control.
+	Camera *camera.Camera
+
+	// The point to orbit around, and the distance from it.
+	Target   lmath.Vec3
+	Distance float64
+
+	// The limits of Distance when zooming.
+	MinDistance, MaxDistance float64
+
+	// The rotation around the target, in degrees. Pitch is limited to the
+	// range of [-MaxPitch, MaxPitch].
+	Yaw, Pitch, MaxPitch float64
+
+	// The rotation speed in degrees per pixel of cursor motion, the pan speed
+	// as a fraction of Distance per pixel of cursor motion, and the fraction
+	// by which Distance changes per unit of scrolling.
+	RotateSpeed, PanSpeed, ZoomSpeed float64
+
+	// The mouse buttons which rotate and pan when held.
+	RotateButton, PanButton mouse.Button
+}
+
+// HandleEvent implements the Controller interface.
+func (o *Orbit) HandleEvent(ev window.Event) {
+	o.input.handle(ev)
+}
+
+// Update implements the Controller interface.
+func (o *Orbit) Update(dt float64) {
+	dx, dy, scroll := o.take()
+	switch {
+	case o.button(o.RotateButton):
+		o.Yaw -= dx * o.RotateSpeed
+		o.Pitch += dy * o.RotateSpeed
+	case o.button(o.PanButton):
+		_, right, up := directions(o.Yaw, o.Pitch)
+		scale := o.Distance * o.PanSpeed
+		o.Target = o.Target.Sub(right.MulScalar(dx * scale)).Add(up.MulScalar(dy * scale))
+	}
+	o.Pitch = clamp(o.Pitch, -o.MaxPitch, o.MaxPitch)
+	if scroll != 0 {
+		o.Distance *= math.Pow(1-o.ZoomSpeed, scroll)
+	}
+	o.Distance = clamp(o.Distance, o.MinDistance, o.MaxDistance)
+
+	// The camera looks down at the target from above when pitched up.
+	forward, _, _ := directions(o.Yaw, -o.Pitch)
+	o.Camera.Transform().SetPos(o.Target.Sub(forward.MulScalar(o.Distance)))
+	look(o.Camera, o.Yaw, -o.Pitch)
+}
+
+// NewOrbit returns a new orbit controller for the given camera, orbiting the
+// given target at the given distance. It has the following properties:
+//
+//  MinDistance = 0.1 * distance
+//  MaxDistance = 10 * distance
+//  Pitch = 30
+//  MaxPitch = 89
+//  RotateSpeed = 0.3
+//  PanSpeed = 0.002
+//  ZoomSpeed = 0.1
+//  RotateButton = mouse.Left
+//  PanButton = mouse.Middle
+//
+func NewOrbit(c *camera.Camera, target lmath.Vec3, distance float64) *Orbit {
+	o := &Orbit{
+		Camera:       c,
+		Target:       target,
+		Distance:     distance,
+		MinDistance:  0.1 * distance,
+		MaxDistance:  10 * distance,
+		Pitch:        30,
+		MaxPitch:     89,
+		RotateSpeed:  0.3,
+		PanSpeed:     0.002,
+		ZoomSpeed:    0.1,
+		RotateButton: mouse.Left,
+		PanButton:    mouse.Middle,
+	}
+	o.Update(0)
+	return o
+}
+
+// Fly is a controller which flies the camera freely: the movement keys (WASD
+// by default) move in the direction the camera is looking, Up and Down move
+// vertically, and moving the cursor while LookButton is held looks around.
+//
+// Setting LookButton to mouse.Invalid always looks around, which is best used
+// with a grabbed cursor (see window.Props.SetCursorGrabbed).
+type Fly struct {
+	input
+
+	// The camera to control.
+	Camera *camera.Camera
+
+	// The look direction, in degrees. Pitch is limited to the range of
+	// [-MaxPitch, MaxPitch].
+	Yaw, Pitch, MaxPitch float64
+
+	// The movement speed in units per second, and the multiplier applied to it
+	// while the Fast key is held.
+	Speed, FastMultiplier float64
+
+	// The look sensitivity in degrees per pixel of cursor motion.
+	Sensitivity float64
+
+	// Whether or not to invert the vertical look direction.
+	InvertY bool
+
+	// The movement keys.
+	Forward, Back, Left, Right, Up, Down, Fast keyboard.Key
+
+	// The mouse button which looks around when held, or mouse.Invalid.
+	LookButton mouse.Button
+}
+
+// HandleEvent implements the Controller interface.
+func (f *Fly) HandleEvent(ev window.Event) {
+	f.input.handle(ev)
+}
+
+// Update implements the Controller interface.
+func (f *Fly) Update(dt float64) {
+	f.Yaw, f.Pitch = updateLook(&f.input, f.LookButton, f.Yaw, f.Pitch, f.MaxPitch, f.Sensitivity, f.InvertY)
+	forward, right, _ := directions(f.Yaw, f.Pitch)
+
+	speed := f.Speed * dt
+	if f.key(f.Fast) {
+		speed *= f.FastMultiplier
+	}
+	move := forward.MulScalar(f.axis(f.Forward, f.Back))
+	move = move.Add(right.MulScalar(f.axis(f.Right, f.Left)))
+	move = move.Add(lmath.Vec3{Z: f.axis(f.Up, f.Down)})
+	translate(f.Camera, move, speed)
+	look(f.Camera, f.Yaw, f.Pitch)
+}
+
+// NewFly returns a new fly controller for the given camera, starting from it's
+// current position and rotation. It has the following properties:
+//
+//  MaxPitch = 89
+//  Speed = 5
+//  FastMultiplier = 4
+//  Sensitivity = 0.2
+//  Forward, Back, Left, Right = keyboard.W, keyboard.S, keyboard.A, keyboard.D
+//  Up, Down = keyboard.E, keyboard.Q
+//  Fast = keyboard.LeftShift
+//  LookButton = mouse.Right
+//
+func NewFly(c *camera.Camera) *Fly {
+	rot := c.Transform().Rot()
+	return &Fly{
+		Camera:         c,
+		Yaw:            rot.Z,
+		Pitch:          rot.X,
+		MaxPitch:       89,
+		Speed:          5,
+		FastMultiplier: 4,
+		Sensitivity:    0.2,
+		Forward:        keyboard.W,
+		Back:           keyboard.S,
+		Left:           keyboard.A,
+		Right:          keyboard.D,
+		Up:             keyboard.E,
+		Down:           keyboard.Q,
+		Fast:           keyboard.LeftShift,
+		LookButton:     mouse.Right,
+	}
+}
+
+// FirstPerson is a controller for first-person (FPS) style cameras: the
+// movement keys (WASD by default) move along the ground plane regardless of
+// the pitch, and moving the cursor looks around with the pitch clamped so the
+// view cannot flip over.
+//
+// It is best used with a grabbed cursor (see window.Props.SetCursorGrabbed),
+// as LookButton is mouse.Invalid by default.
+type FirstPerson struct {
+	input
+
+	// The camera to control.
+	Camera *camera.Camera
+
+	// The look direction, in degrees. Pitch is limited to the range of
+	// [MinPitch, MaxPitch].
+	Yaw, Pitch, MinPitch, MaxPitch float64
+
+	// The movement speed in units per second, and the multiplier applied to it
+	// while the Run key is held.
+	Speed, RunMultiplier float64
+
+	// The look sensitivity in degrees per pixel of cursor motion.
+	Sensitivity float64
+
+	// Whether or not to invert the vertical look direction.
+	InvertY bool
+
+	// The movement keys.
+	Forward, Back, Left, Right, Run keyboard.Key
+
+	// The mouse button which looks around when held, or mouse.Invalid.
+	LookButton mouse.Button
+}
+
+// HandleEvent implements the Controller interface.
+func (f *FirstPerson) HandleEvent(ev window.Event) {
+	f.input.handle(ev)
+}
+
+// Update implements the Controller interface.
+func (f *FirstPerson) Update(dt float64) {
+	f.Yaw, f.Pitch = updateLook(&f.input, f.LookButton, f.Yaw, f.Pitch, f.MaxPitch, f.Sensitivity, f.InvertY)
+	f.Pitch = clamp(f.Pitch, f.MinPitch, f.MaxPitch)
+
+	// Move along the ground plane, ignoring the pitch.
+	forward, right, _ := directions(f.Yaw, 0)
+	speed := f.Speed * dt
+	if f.key(f.Run) {
+		speed *= f.RunMultiplier
+	}
+	move := forward.MulScalar(f.axis(f.Forward, f.Back))
+	move = move.Add(right.MulScalar(f.axis(f.Right, f.Left)))
+	translate(f.Camera, move, speed)
+	look(f.Camera, f.Yaw, f.Pitch)
+}
+
+// NewFirstPerson returns a new first-person controller for the given camera,
+// starting from it's current position and rotation. It has the following
+// properties:
+//
+//  MinPitch, MaxPitch = -85, 85
+//  Speed = 3
+//  RunMultiplier = 2
+//  Sensitivity = 0.15
+//  Forward, Back, Left, Right = keyboard.W, keyboard.S, keyboard.A, keyboard.D
+//  Run = keyboard.LeftShift
+//  LookButton = mouse.Invalid
+//
+func NewFirstPerson(c *camera.Camera) *FirstPerson {
+	rot := c.Transform().Rot()
+	return &FirstPerson{
+		Camera:        c,
+		Yaw:           rot.Z,
+		Pitch:         rot.X,
+		MinPitch:      -85,
+		MaxPitch:      85,
+		Speed:         3,
+		RunMultiplier: 2,
+		Sensitivity:   0.15,
+		Forward:       keyboard.W,
+		Back:          keyboard.S,
+		Left:          keyboard.A,
+		Right:         keyboard.D,
+		Run:           keyboard.LeftShift,
+	}
+}
+
+// updateLook applies the cursor motion to the given yaw and pitch, if the look
+// button is held, and returns them.
+func updateLook(in *input, button mouse.Button, yaw, pitch, maxPitch, sensitivity float64, invertY bool) (float64, float64) {
+	dx, dy, _ := in.take()
+	if !in.button(button) {
+		return yaw, pitch
+	}
+	if invertY {
+		dy = -dy
+	}
+	yaw -= dx * sensitivity
+	pitch = clamp(pitch-dy*sensitivity, -maxPitch, maxPitch)
+	return yaw, pitch
+}
+
+// translate moves the camera in the given direction (which is normalized) by
+// the given distance.
+func translate(c *camera.Camera, dir lmath.Vec3, distance float64) {
+	if dir == lmath.Vec3Zero {
+		return
+	}
+	dir, _ = dir.Normalized()
+	t := c.Transform()
+	t.SetPos(t.Pos().Add(dir.MulScalar(distance)))
+}
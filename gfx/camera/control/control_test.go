@@ -0,0 +1,122 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package control
+
+import (
+	"image"
+	"testing"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/camera"
+	"azul3d.org/engine/gfx/window"
+	"azul3d.org/engine/keyboard"
+	"azul3d.org/engine/lmath"
+	"azul3d.org/engine/mouse"
+)
+
+var (
+	_ Controller = &Orbit{}
+	_ Controller = &Fly{}
+	_ Controller = &FirstPerson{}
+)
+
+// lookDir returns the direction the camera is looking in world space.
+func lookDir(c *camera.Camera) lmath.Vec3 {
+	t := c.Transform()
+	p := t.ConvertPos(lmath.Vec3{Y: 1}, gfx.LocalToWorld)
+	return p.Sub(t.ConvertPos(lmath.Vec3Zero, gfx.LocalToWorld))
+}
+
+func TestDirections(t *testing.T) {
+	c := camera.New(image.Rect(0, 0, 100, 100))
+	for _, tst := range []struct{ yaw, pitch float64 }{
+		{0, 0}, {90, 0}, {-45, 30}, {170, -60},
+	} {
+		look(c, tst.yaw, tst.pitch)
+		forward, _, _ := directions(tst.yaw, tst.pitch)
+		if got := lookDir(c); !got.AlmostEquals(forward, 1e-6) {
+			t.Errorf("yaw=%v pitch=%v: camera looks at %v, want %v", tst.yaw, tst.pitch, got, forward)
+		}
+	}
+}
+
+func TestOrbit(t *testing.T) {
+	c := camera.New(image.Rect(0, 0, 100, 100))
+	target := lmath.Vec3{X: 1, Y: 2, Z: 3}
+	o := NewOrbit(c, target, 10)
+
+	check := func() {
+		pos := c.Transform().Pos()
+		if d := pos.Sub(target).Length(); !lmath.AlmostEqual(d, o.Distance, 1e-6) {
+			t.Fatalf("camera is %v from the target, want %v", d, o.Distance)
+		}
+		toTarget, _ := target.Sub(pos).Normalized()
+		if !lookDir(c).AlmostEquals(toTarget, 1e-6) {
+			t.Fatal("camera does not look at the target")
+		}
+	}
+	check()
+	if c.Transform().Pos().Z <= target.Z {
+		t.Fatal("camera is not above the target")
+	}
+
+	// Rotate, then zoom in.
+	o.HandleEvent(mouse.ButtonEvent{Button: mouse.Left, State: mouse.Down})
+	o.HandleEvent(window.CursorMoved{X: 10, Y: 10})
+	o.HandleEvent(window.CursorMoved{X: 110, Y: 500})
+	o.HandleEvent(mouse.Scrolled{Y: 2})
+	o.Update(0.1)
+	if o.Pitch != o.MaxPitch {
+		t.Fatal("pitch not clamped:", o.Pitch)
+	}
+	if o.Distance >= 10 {
+		t.Fatal("did not zoom in:", o.Distance)
+	}
+	check()
+}
+
+func TestFly(t *testing.T) {
+	c := camera.New(image.Rect(0, 0, 100, 100))
+	f := NewFly(c)
+	f.Pitch = 45
+	f.HandleEvent(keyboard.ButtonEvent{Key: keyboard.W, State: keyboard.Down})
+	f.Update(1)
+	if pos := c.Transform().Pos(); !lmath.AlmostEqual(pos.Length(), f.Speed, 1e-6) || pos.Z <= 0 {
+		t.Fatal("did not fly forward and up:", pos)
+	}
+
+	// Looking requires the look button.
+	f.HandleEvent(keyboard.ButtonEvent{Key: keyboard.W, State: keyboard.Up})
+	f.HandleEvent(window.CursorMoved{X: 10, Y: 0, Delta: true})
+	f.Update(1)
+	if f.Yaw != 0 {
+		t.Fatal("looked around without the look button")
+	}
+	f.HandleEvent(mouse.ButtonEvent{Button: mouse.Right, State: mouse.Down})
+	f.HandleEvent(window.CursorMoved{X: 10, Y: 0, Delta: true})
+	f.Update(1)
+	if f.Yaw != -10*f.Sensitivity {
+		t.Fatal("did not look around:", f.Yaw)
+	}
+}
+
+func TestFirstPerson(t *testing.T) {
+	c := camera.New(image.Rect(0, 0, 100, 100))
+	f := NewFirstPerson(c)
+	f.HandleEvent(window.CursorMoved{X: 0, Y: -10000, Delta: true})
+	f.HandleEvent(keyboard.ButtonEvent{Key: keyboard.W, State: keyboard.Down})
+	f.HandleEvent(keyboard.ButtonEvent{Key: keyboard.D, State: keyboard.Down})
+	f.Update(1)
+	if f.Pitch != f.MaxPitch {
+		t.Fatal("pitch not clamped:", f.Pitch)
+	}
+	pos := c.Transform().Pos()
+	if pos.Z != 0 || pos.X <= 0 || pos.Y <= 0 {
+		t.Fatal("did not move forward and right along the ground:", pos)
+	}
+	if !lmath.AlmostEqual(pos.Length(), f.Speed, 1e-6) {
+		t.Fatal("diagonal movement is faster:", pos.Length())
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package camera
+
+import (
+	"image"
+	"math"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/lmath"
+)
+
+// Lens describes the projection parameters of a camera (see the fields of the
+// Camera type with the same names).
+type Lens struct {
+	Near, Far   float64
+	FOV         float64
+	Ortho       bool
+	OrthoHeight float64
+}
+
+// projection returns the projection matrix of the lens for the given view.
+func (l Lens) projection(view image.Rectangle) lmath.Mat4 {
+	if l.Ortho {
+		// An orthographic camera projection.
+		if l.OrthoHeight > 0 {
+			h := l.OrthoHeight / 2
+			w := h * float64(view.Dx()) / float64(view.Dy())
+			return lmath.Mat4Ortho(-w, w, -h, h, l.Near, l.Far)
+		}
+		w := float64(view.Dx())
+		w = float64(int((w / 2.0)) * 2)
+		h := float64(view.Dy())
+		h = float64(int((h / 2.0)) * 2)
+		return lmath.Mat4Ortho(0, w, 0, h, l.Near, l.Far)
+	}
+
+	// An perspective camera projection.
+	aspectRatio := float64(view.Dx()) / float64(view.Dy())
+	return lmath.Mat4Perspective(l.FOV, aspectRatio, l.Near, l.Far)
+}
+
+// Lerp returns the lens linearly interpolated towards b by t. The Ortho field
+// switches at t=0.5, use a LensTransition to smoothly transition between
+// orthographic and perspective lenses.
+func (l Lens) Lerp(b Lens, t float64) Lens {
+	r := Lens{
+		Near:        lmath.Lerp(l.Near, b.Near, t),
+		Far:         lmath.Lerp(l.Far, b.Far, t),
+		FOV:         lmath.Lerp(l.FOV, b.FOV, t),
+		Ortho:       l.Ortho,
+		OrthoHeight: lmath.Lerp(l.OrthoHeight, b.OrthoHeight, t),
+	}
+	if t >= 0.5 {
+		r.Ortho = b.Ortho
+	}
+	return r
+}
+
+// Lens returns the projection parameters of the camera.
+func (c *Camera) Lens() Lens {
+	return Lens{
+		Near:        c.Near,
+		Far:         c.Far,
+		FOV:         c.FOV,
+		Ortho:       c.Ortho,
+		OrthoHeight: c.OrthoHeight,
+	}
+}
+
+// SetLens sets the projection parameters of the camera, and updates it.
+func (c *Camera) SetLens(l Lens) {
+	c.Near, c.Far = l.Near, l.Far
+	c.FOV = l.FOV
+	c.Ortho = l.Ortho
+	c.OrthoHeight = l.OrthoHeight
+	c.Update(c.View)
+}
+
+// FrustumHeight returns the height (in world units) of the area viewed by the
+// camera at the given distance in front of it. For orthographic cameras it is
+// OrthoHeight (or the height of the view in pixels) regardless of distance.
+func (c *Camera) FrustumHeight(distance float64) float64 {
+	if c.Ortho {
+		if c.OrthoHeight > 0 {
+			return c.OrthoHeight
+		}
+		return float64(c.View.Dy())
+	}
+	return 2 * distance * math.Tan(lmath.Radians(c.FOV)/2)
+}
+
+// FOVForHeight returns the vertical field of view (in degrees) at which a
+// perspective camera views an area of the given height at the given distance.
+func FOVForHeight(height, distance float64) float64 {
+	return lmath.Degrees(2 * math.Atan(height/(2*distance)))
+}
+
+// DistanceForHeight returns the distance at which a perspective camera with
+// the given vertical field of view (in degrees) views an area of the given
+// height.
+func DistanceForHeight(height, fov float64) float64 {
+	return height / (2 * math.Tan(lmath.Radians(fov)/2))
+}
+
+// MatchOrtho returns an orthographic lens which frames the area at the given
+// distance in front of the camera the same as the camera's current lens.
+func (c *Camera) MatchOrtho(distance float64) Lens {
+	l := c.Lens()
+	l.OrthoHeight = c.FrustumHeight(distance)
+	l.Ortho = true
+	return l
+}
+
+// MatchPerspective returns a perspective lens which frames the area at the
+// given distance in front of the camera the same as the camera's current
+// lens.
+func (c *Camera) MatchPerspective(distance float64) Lens {
+	l := c.Lens()
+	l.FOV = FOVForHeight(c.FrustumHeight(distance), distance)
+	l.Ortho = false
+	return l
+}
+
+// DollyZoom changes the field of view of the perspective camera to fov (in
+// degrees), while moving it towards or away from the target such that the
+// target's framing stays the same (i.e. the "Vertigo" effect). The camera is
+// updated.
+func (c *Camera) DollyZoom(target lmath.Vec3, fov float64) {
+	t := c.Transform()
+	toTarget := target.Sub(t.Pos())
+	dir, ok := toTarget.Normalized()
+	if !ok || c.Ortho {
+		return
+	}
+	height := c.FrustumHeight(toTarget.Length())
+	c.FOV = fov
+	t.SetPos(target.Sub(dir.MulScalar(DistanceForHeight(height, fov))))
+	c.Update(c.View)
+}
+
+// LensTransition smoothly transitions a camera from one lens to another over
+// time, e.g. to animate the field of view or to toggle between perspective and
+// orthographic views in an editor:
+//
+//  tr := camera.NewLensTransition(cam, cam.MatchOrtho(distanceToFocus), 0.3)
+//  ...
+//  // Each frame:
+//  if tr != nil && tr.Update(clock.Dt()) {
+//      tr = nil
+//  }
+//
+// Transitions between perspective and orthographic lenses blend the
+// projection matrices, otherwise the lens parameters are interpolated.
+type LensTransition struct {
+	// The camera, and the lenses to transition between.
+	Camera   *Camera
+	From, To Lens
+
+	// The duration of the transition in seconds.
+	Duration float64
+
+	// Ease maps the linear progress of the transition in the range of [0, 1]
+	// to the interpolation factor (e.g. curve.InOutCubic from the lmath/curve
+	// package), or nil for smoothstep easing.
+	Ease func(t float64) float64
+
+	elapsed float64
+}
+
+// Update advances the transition by dt seconds and updates the camera, it
+// returns true once the transition is complete (at which point the camera has
+// exactly the To lens).
+func (tr *LensTransition) Update(dt float64) (done bool) {
+	tr.elapsed += dt
+	t := 1.0
+	if tr.Duration > 0 {
+		t = lmath.Clamp(tr.elapsed/tr.Duration, 0, 1)
+	}
+	if t >= 1 {
+		tr.Camera.SetLens(tr.To)
+		return true
+	}
+	if tr.Ease != nil {
+		t = tr.Ease(t)
+	} else {
+		t = t * t * (3 - 2*t)
+	}
+
+	c := tr.Camera
+	if tr.From.Ortho == tr.To.Ortho {
+		c.SetLens(tr.From.Lerp(tr.To, t))
+		return false
+	}
+
+	// Blend the projection matrices.
+	from := tr.From.projection(c.View)
+	to := tr.To.projection(c.View)
+	var m lmath.Mat4
+	for row := range m {
+		for col := range m[row] {
+			m[row][col] = lmath.Lerp(from[row][col], to[row][col], t)
+		}
+	}
+	c.P = gfx.ConvertMat4(m)
+	return false
+}
+
+// NewLensTransition returns a new transition of the given camera from it's
+// current lens to the given one, over the given duration in seconds.
+func NewLensTransition(c *Camera, to Lens, duration float64) *LensTransition {
+	return &LensTransition{
+		Camera:   c,
+		From:     c.Lens(),
+		To:       to,
+		Duration: duration,
+	}
+}
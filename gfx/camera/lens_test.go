@@ -0,0 +1,79 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package camera
+
+import (
+	"image"
+	"testing"
+
+	"azul3d.org/engine/lmath"
+)
+
+func TestMatchOrtho(t *testing.T) {
+	c := New(image.Rect(0, 0, 200, 100))
+	c.Transform().SetPos(lmath.Vec3{Y: -10})
+
+	// A point at the edge of the view at the focus distance remains there
+	// when switching to a matched orthographic lens and back.
+	p := lmath.Vec3{Z: c.FrustumHeight(10) / 2}
+	before, _ := c.Project(p)
+	c.SetLens(c.MatchOrtho(10))
+	after, _ := c.Project(p)
+	if !before.AlmostEquals(after, 1e-6) {
+		t.Fatalf("ortho framing differs: %v != %v", before, after)
+	}
+	c.SetLens(c.MatchPerspective(10))
+	if !lmath.AlmostEqual(c.FOV, 75, 1e-6) {
+		t.Fatal("perspective FOV not restored:", c.FOV)
+	}
+}
+
+func TestDollyZoom(t *testing.T) {
+	c := New(image.Rect(0, 0, 200, 100))
+	c.Transform().SetPos(lmath.Vec3{Y: -10})
+	target := lmath.Vec3Zero
+	p := lmath.Vec3{X: 1, Z: 2}
+	before, _ := c.Project(p)
+
+	c.DollyZoom(target, 30)
+	if c.FOV != 30 {
+		t.Fatal("FOV not changed")
+	}
+	if c.Transform().Pos().Y >= -10 {
+		t.Fatal("camera did not move back:", c.Transform().Pos())
+	}
+	after, _ := c.Project(p)
+	if !before.AlmostEquals(after, 1e-6) {
+		t.Fatalf("framing at the target differs: %v != %v", before, after)
+	}
+}
+
+func TestLensTransition(t *testing.T) {
+	c := New(image.Rect(0, 0, 200, 100))
+	to := c.Lens()
+	to.FOV = 45
+	tr := NewLensTransition(c, to, 1)
+	if tr.Update(0.5) {
+		t.Fatal("transition done early")
+	}
+	if !lmath.AlmostEqual(c.FOV, 60, 1e-6) {
+		t.Fatal("FOV not halfway:", c.FOV)
+	}
+	if !tr.Update(0.5) || c.FOV != 45 {
+		t.Fatal("transition not done")
+	}
+
+	// Blending to an orthographic lens.
+	start := c.P
+	tr = NewLensTransition(c, c.MatchOrtho(10), 1)
+	tr.Update(0.5)
+	if c.P == start || c.Ortho {
+		t.Fatal("projection not blended")
+	}
+	tr.Update(0.5)
+	if !c.Ortho {
+		t.Fatal("camera not orthographic after transition")
+	}
+}
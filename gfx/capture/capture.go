@@ -0,0 +1,182 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package capture records the frames rendered to a canvas as video.
+//
+// A recorder downloads each frame from the canvas asynchronously (i.e. without
+// stalling the graphics pipeline, if the canvas implements the
+// gfx.AsyncDownloader interface) and passes it to an encoder in a separate
+// goroutine:
+//
+//  f, err := os.Create("demo.y4m")
+//  if err != nil {
+//      log.Fatal(err)
+//  }
+//  rec := capture.New(d, capture.NewY4M(f, 60))
+//  for {
+//      ... draw the frame ...
+//      rec.Frame()
+//      d.Render()
+//  }
+//
+//  // Later, in a goroutine other than the one rendering frames:
+//  if err := rec.Close(); err != nil {
+//      log.Fatal(err)
+//  }
+//  f.Close()
+//
+// Y4M files can be converted to a compressed video format using external
+// tools, e.g.:
+//
+//  ffmpeg -i demo.y4m demo.mp4
+//
+package capture // import "azul3d.org/engine/gfx/capture"
+
+import (
+	"errors"
+	"image"
+	"sync"
+
+	"azul3d.org/engine/gfx"
+)
+
+// ErrDownload is the error returned when a frame could not be downloaded from
+// the canvas (e.g. because the device does not support downloading).
+var ErrDownload = errors.New("capture: frame download failed")
+
+// MaxPending is the maximum number of frames which may be pending (i.e. being
+// downloaded, or waiting to be encoded) at once. If the encoder cannot keep up
+// and more frames than this would be pending, new frames are dropped instead.
+const MaxPending = 8
+
+// Encoder encodes a sequence of frames.
+type Encoder interface {
+	// Encode encodes a single frame. Frames are encoded in order and are
+	// generally expected to all be the same size.
+	Encode(img image.Image) error
+
+	// Close is called after the last frame has been encoded, and should
+	// finish encoding (it does not close any underlying io.Writer).
+	Close() error
+}
+
+// Recorder records the frames of a canvas using an encoder.
+//
+// A recorder's methods are safe to call from multiple goroutines
+// concurrently.
+type Recorder struct {
+	canvas gfx.Canvas
+	enc    Encoder
+	frames chan image.Image
+	done   chan struct{}
+	wg     sync.WaitGroup
+
+	access           sync.Mutex
+	pending, dropped int
+	closed           bool
+	err              error
+}
+
+// Frame captures the current contents of the canvas as the next frame. It
+// should be called once per frame, after drawing and just before calling the
+// canvas's Render method.
+//
+// If the recorder is closed, or the encoder has returned an error, it is
+// no-op.
+func (r *Recorder) Frame() {
+	r.access.Lock()
+	if r.closed || r.err != nil {
+		r.access.Unlock()
+		return
+	}
+	if r.pending == MaxPending {
+		r.dropped++
+		r.access.Unlock()
+		return
+	}
+	r.pending++
+	r.wg.Add(1)
+	r.access.Unlock()
+
+	if a, ok := r.canvas.(gfx.AsyncDownloader); ok {
+		a.DownloadAsync(r.canvas.Bounds(), r.frames)
+		return
+	}
+	r.canvas.Download(r.canvas.Bounds(), r.frames)
+}
+
+// Dropped returns the number of frames that have been dropped because the
+// encoder could not keep up.
+func (r *Recorder) Dropped() int {
+	r.access.Lock()
+	dropped := r.dropped
+	r.access.Unlock()
+	return dropped
+}
+
+// Close waits for all pending frames to be encoded, closes the encoder, and
+// returns the first error encountered (if any).
+//
+// Because pending frames only complete as the canvas renders, Close must not
+// be called from the goroutine which renders frames, or it may block forever.
+func (r *Recorder) Close() error {
+	r.access.Lock()
+	if r.closed {
+		err := r.err
+		r.access.Unlock()
+		return err
+	}
+	r.closed = true
+	r.access.Unlock()
+
+	r.wg.Wait()
+	close(r.frames)
+	<-r.done
+
+	r.access.Lock()
+	defer r.access.Unlock()
+	if err := r.enc.Close(); err != nil && r.err == nil {
+		r.err = err
+	}
+	return r.err
+}
+
+// encode encodes each frame sent over the frames channel until it is closed.
+func (r *Recorder) encode() {
+	for img := range r.frames {
+		r.access.Lock()
+		r.pending--
+		failed := r.err != nil
+		r.access.Unlock()
+
+		if !failed {
+			var err error
+			if img == nil {
+				err = ErrDownload
+			} else {
+				err = r.enc.Encode(img)
+			}
+			if err != nil {
+				r.access.Lock()
+				r.err = err
+				r.access.Unlock()
+			}
+		}
+		r.wg.Done()
+	}
+	close(r.done)
+}
+
+// New returns a new recorder which records frames of the given canvas using
+// the given encoder.
+func New(c gfx.Canvas, enc Encoder) *Recorder {
+	r := &Recorder{
+		canvas: c,
+		enc:    enc,
+		frames: make(chan image.Image, MaxPending),
+		done:   make(chan struct{}),
+	}
+	go r.encode()
+	return r
+}
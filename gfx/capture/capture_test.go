@@ -0,0 +1,140 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package capture
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"strings"
+	"testing"
+
+	"azul3d.org/engine/gfx"
+)
+
+// testCanvas is a canvas whose downloads produce a solid red image.
+type testCanvas struct {
+	gfx.Device
+	fail bool
+}
+
+func (c *testCanvas) Bounds() image.Rectangle {
+	return image.Rect(0, 0, 4, 3)
+}
+
+func (c *testCanvas) Download(r image.Rectangle, complete chan image.Image) {
+	if c.fail {
+		complete <- nil
+		return
+	}
+	img := image.NewRGBA(r)
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+3] = 255, 255
+	}
+	complete <- img
+}
+
+// countEncoder counts the number of frames it encodes.
+type countEncoder struct {
+	frames int
+	closed bool
+}
+
+func (e *countEncoder) Encode(img image.Image) error {
+	e.frames++
+	return nil
+}
+
+func (e *countEncoder) Close() error {
+	e.closed = true
+	return nil
+}
+
+func TestRecorder(t *testing.T) {
+	enc := &countEncoder{}
+	rec := New(&testCanvas{Device: gfx.Nil()}, enc)
+	for i := 0; i < 5; i++ {
+		rec.Frame()
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if enc.frames+rec.Dropped() != 5 {
+		t.Fatalf("got %d frames and %d dropped, want 5 total", enc.frames, rec.Dropped())
+	}
+	if !enc.closed {
+		t.Fatal("encoder not closed")
+	}
+
+	// Frames after closing are ignored.
+	rec.Frame()
+}
+
+func TestRecorderDownloadFailed(t *testing.T) {
+	rec := New(&testCanvas{Device: gfx.Nil(), fail: true}, &countEncoder{})
+	rec.Frame()
+	if err := rec.Close(); err != ErrDownload {
+		t.Fatalf("got error %v, want ErrDownload", err)
+	}
+}
+
+func TestY4M(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 3))
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 3; x++ {
+			img.Set(x, y, color.RGBA{255, 255, 255, 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := NewY4M(&buf, 30)
+	for i := 0; i < 2; i++ {
+		if err := enc.Encode(img); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	header := "YUV4MPEG2 W3 H3 F30:1 Ip A1:1 C420jpeg\n"
+	if !strings.HasPrefix(buf.String(), header) {
+		t.Fatalf("got header %q, want %q", buf.String()[:len(header)], header)
+	}
+
+	// Each frame is a FRAME line, 3x3 luma, and two 2x2 chroma planes.
+	frameSize := len("FRAME\n") + 9 + 4 + 4
+	if want := len(header) + 2*frameSize; buf.Len() != want {
+		t.Fatalf("got %d bytes, want %d", buf.Len(), want)
+	}
+	frame := buf.Bytes()[len(header)+len("FRAME\n"):]
+	if frame[0] != 255 || frame[9] != 128 || frame[13] != 128 {
+		t.Fatalf("got Y=%d Cb=%d Cr=%d, want Y=255 Cb=128 Cr=128", frame[0], frame[9], frame[13])
+	}
+
+	if err := enc.Encode(image.NewRGBA(image.Rect(0, 0, 4, 4))); err == nil {
+		t.Fatal("expected error for frame size change")
+	}
+}
+
+func TestMJPEG(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewMJPEG(&buf, nil)
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	if err := enc.Encode(img); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := jpeg.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Bounds() != img.Bounds() {
+		t.Fatalf("got bounds %v, want %v", got.Bounds(), img.Bounds())
+	}
+}
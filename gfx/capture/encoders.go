@@ -0,0 +1,137 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package capture
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+)
+
+type mjpegEncoder struct {
+	w io.Writer
+	o *jpeg.Options
+}
+
+func (e *mjpegEncoder) Encode(img image.Image) error {
+	return jpeg.Encode(e.w, img, e.o)
+}
+
+func (e *mjpegEncoder) Close() error {
+	return nil
+}
+
+// NewMJPEG returns an encoder which writes each frame as a JPEG image to w,
+// one after another, forming a Motion JPEG stream (which most video players
+// and tools, e.g. ffmpeg, can read directly).
+//
+// If o is nil, the default JPEG options are used.
+func NewMJPEG(w io.Writer, o *jpeg.Options) Encoder {
+	return &mjpegEncoder{w: w, o: o}
+}
+
+type pngSequenceEncoder struct {
+	pattern string
+	n       int
+}
+
+func (e *pngSequenceEncoder) Encode(img image.Image) error {
+	f, err := os.Create(fmt.Sprintf(e.pattern, e.n))
+	if err != nil {
+		return err
+	}
+	e.n++
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (e *pngSequenceEncoder) Close() error {
+	return nil
+}
+
+// NewPNGSequence returns an encoder which writes each frame to a separate PNG
+// file, named by formatting the pattern with the frame number (starting at
+// zero), e.g. "frames/%05d.png".
+func NewPNGSequence(pattern string) Encoder {
+	return &pngSequenceEncoder{pattern: pattern}
+}
+
+type y4mEncoder struct {
+	w             *bufio.Writer
+	fps           int
+	width, height int
+	y, cb, cr     []byte
+}
+
+func (e *y4mEncoder) Encode(img image.Image) error {
+	b := img.Bounds()
+	if e.y == nil {
+		// Write the stream header, using the size of the first frame.
+		e.width, e.height = b.Dx(), b.Dy()
+		_, err := fmt.Fprintf(e.w, "YUV4MPEG2 W%d H%d F%d:1 Ip A1:1 C420jpeg\n", e.width, e.height, e.fps)
+		if err != nil {
+			return err
+		}
+		cw, ch := (e.width+1)/2, (e.height+1)/2
+		e.y = make([]byte, e.width*e.height)
+		e.cb = make([]byte, cw*ch)
+		e.cr = make([]byte, cw*ch)
+	} else if b.Dx() != e.width || b.Dy() != e.height {
+		return fmt.Errorf("capture: y4m frame size changed from %dx%d to %dx%d", e.width, e.height, b.Dx(), b.Dy())
+	}
+
+	// Convert to YCbCr, averaging the chroma of each 2x2 block of pixels.
+	cw := (e.width + 1) / 2
+	cbSum := make([]int, len(e.cb))
+	crSum := make([]int, len(e.cr))
+	count := make([]int, len(e.cb))
+	for y := 0; y < e.height; y++ {
+		for x := 0; x < e.width; x++ {
+			c := color.RGBAModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.RGBA)
+			yy, cb, cr := color.RGBToYCbCr(c.R, c.G, c.B)
+			e.y[y*e.width+x] = yy
+			ci := (y/2)*cw + x/2
+			cbSum[ci] += int(cb)
+			crSum[ci] += int(cr)
+			count[ci]++
+		}
+	}
+	for i, n := range count {
+		e.cb[i] = uint8((cbSum[i] + n/2) / n)
+		e.cr[i] = uint8((crSum[i] + n/2) / n)
+	}
+
+	if _, err := io.WriteString(e.w, "FRAME\n"); err != nil {
+		return err
+	}
+	for _, plane := range [][]byte{e.y, e.cb, e.cr} {
+		if _, err := e.w.Write(plane); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *y4mEncoder) Close() error {
+	return e.w.Flush()
+}
+
+// NewY4M returns an encoder which writes frames to w as an uncompressed
+// YUV4MPEG2 (y4m) video stream with 4:2:0 chroma subsampling, at the given
+// number of frames per second. All frames must be the same size.
+//
+// Y4M files are large, but can be read by most video tools (e.g. piped into
+// ffmpeg for compression).
+func NewY4M(w io.Writer, fps int) Encoder {
+	return &y4mEncoder{w: bufio.NewWriter(w), fps: fps}
+}
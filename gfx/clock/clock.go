@@ -20,6 +20,12 @@ type Clock struct {
 
 	avgSamples                                                []float64
 	frameRate, maxFrameRate, avgFrameRate, frameRateDeviation float64
+
+	// Time scaling and pausing: the scaled time at scaleStart (in real time)
+	// is scaleBase, and it advances at timeScale from there unless paused.
+	timeScale             float64
+	scaleStart, scaleBase time.Duration
+	paused                bool
 }
 
 // FrameRate returns the number of frames per second according to this Clock.
@@ -163,11 +169,85 @@ func (c *Clock) FixedDelta() time.Duration {
 	return c.fixedDelta
 }
 
+// rebase records the scaled time up to now, such that the time scale or paused
+// state can be changed from now on. It must be called with the lock held.
+func (c *Clock) rebase() {
+	now := getTime()
+	c.scaleBase = c.scaledTime(now)
+	c.scaleStart = now
+}
+
+// scaledTime returns the scaled time at the given real time. It must be called
+// with the lock held.
+func (c *Clock) scaledTime(now time.Duration) time.Duration {
+	if c.paused {
+		return c.scaleBase
+	}
+	return c.scaleBase + time.Duration(float64(now-c.scaleStart)*c.timeScale)
+}
+
+// SetTimeScale specifies a multiplier for the passing of time, as returned by
+// the Delta, Dt, and Time methods. For instance 0.5 causes time to pass at half
+// speed (i.e. slow-motion) and 2 at double speed. The frame rate is unaffected.
+//
+// If scale is less than zero, an panic occurs.
+func (c *Clock) SetTimeScale(scale float64) {
+	c.access.Lock()
+	defer c.access.Unlock()
+
+	if scale < 0 {
+		panic("Clock.SetTimeScale(): Time scale cannot be less than zero!")
+	}
+	c.rebase()
+	c.timeScale = scale
+}
+
+// TimeScale returns the time scale of this Clock, as it was set previously by
+// a call to the SetTimeScale method.
+func (c *Clock) TimeScale() float64 {
+	c.access.RLock()
+	defer c.access.RUnlock()
+
+	return c.timeScale
+}
+
+// Pause pauses this Clock: until Resume is called, Delta and Dt return zero and
+// Time does not advance. Tick should still be called each frame, the frame
+// rate continues to be measured.
+func (c *Clock) Pause() {
+	c.access.Lock()
+	defer c.access.Unlock()
+
+	if !c.paused {
+		c.rebase()
+		c.paused = true
+	}
+}
+
+// Resume resumes this Clock after a previous call to Pause.
+func (c *Clock) Resume() {
+	c.access.Lock()
+	defer c.access.Unlock()
+
+	if c.paused {
+		c.rebase()
+		c.paused = false
+	}
+}
+
+// Paused tells if this Clock is currently paused.
+func (c *Clock) Paused() bool {
+	c.access.RLock()
+	defer c.access.RUnlock()
+
+	return c.paused
+}
+
 // Delta returns the time between the start of the current frame and the start
-// of the last frame. If the clock is using a fixed delta value then that value
-// is returned instead.
+// of the last frame, multiplied by TimeScale, or zero if the clock is paused.
+// If the clock is using a fixed delta value then that value is used instead.
 //
-// The value returned will be clamped to MaxDelta.
+// The value returned will be clamped to MaxDelta before scaling.
 //
 // The duration returned will never be less than zero as long as Tick has been
 // called at least once.
@@ -175,6 +255,23 @@ func (c *Clock) Delta() time.Duration {
 	c.access.RLock()
 	defer c.access.RUnlock()
 
+	if c.paused {
+		return 0
+	}
+	return time.Duration(float64(c.unscaledDelta()) * c.timeScale)
+}
+
+// UnscaledDelta is just like Delta, except it is not affected by TimeScale or
+// pausing. It is useful for things which should keep moving in real time, e.g.
+// the animations of a pause menu.
+func (c *Clock) UnscaledDelta() time.Duration {
+	c.access.RLock()
+	defer c.access.RUnlock()
+
+	return c.unscaledDelta()
+}
+
+func (c *Clock) unscaledDelta() time.Duration {
 	if c.fixedDelta != 0 {
 		return c.fixedDelta
 	}
@@ -297,33 +394,49 @@ func (c *Clock) Tick() {
 }
 
 // Time returns the duration of time that has passed since this clock started
-// or was last reset.
+// or was last reset, multiplied by TimeScale and excluding any time the clock
+// was paused.
 func (c *Clock) Time() time.Duration {
 	c.access.RLock()
 	defer c.access.RUnlock()
 
+	return c.scaledTime(getTime())
+}
+
+// RealTime returns the duration of real time that has passed since this clock
+// started or was last reset, unaffected by TimeScale or pausing.
+func (c *Clock) RealTime() time.Duration {
+	c.access.RLock()
+	defer c.access.RUnlock()
+
 	return getTime() - c.startTime
 }
 
-// Reset resets this clock's starting time, as if it had just been created.
+// Reset resets this clock's starting time, as if it had just been created. The
+// time scale and paused state are retained.
 func (c *Clock) Reset() {
 	c.access.Lock()
 	defer c.access.Unlock()
 	c.startTime = getTime()
+	c.scaleStart = c.startTime
+	c.scaleBase = 0
 }
 
 // New initializes and returns a new Clock. The returned clock has it's start
-// time set to the current time, has it's maximum frame rate set to 75, and
-// it's number of average frame rate samples set to 120.
+// time set to the current time, has it's maximum frame rate set to 75, it's
+// number of average frame rate samples set to 120, and a time scale of 1.
 //
 // A maximum frame rate of 75 is a good choice because it is slightly above the
 // refresh rate of most screens, and not all hardware supports high resolution
 // clocks so the limit also ensures that Delta never returns values equal to
 // zero.
 func New() *Clock {
+	now := getTime()
 	return &Clock{
-		startTime:    getTime(),
+		startTime:    now,
 		maxFrameRate: 75,
 		avgSamples:   make([]float64, 120),
+		timeScale:    1,
+		scaleStart:   now,
 	}
 }
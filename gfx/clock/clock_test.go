@@ -72,3 +72,51 @@ func TestFrameRateStall(t *testing.T) {
 		}
 	}
 }
+
+func TestTimeScale(t *testing.T) {
+	c := New()
+	c.SetFixedDelta(10 * time.Millisecond)
+	c.SetTimeScale(0.5)
+	if d := c.Delta(); d != 5*time.Millisecond {
+		t.Fatal("got delta", d, "expected 5ms")
+	}
+	if d := c.UnscaledDelta(); d != 10*time.Millisecond {
+		t.Fatal("got unscaled delta", d, "expected 10ms")
+	}
+
+	c.SetTimeScale(0)
+	before := c.Time()
+	time.Sleep(20 * time.Millisecond)
+	if after := c.Time(); after != before {
+		t.Fatal("time advanced with zero time scale", before, after)
+	}
+	if c.RealTime() < 20*time.Millisecond {
+		t.Fatal("expected real time to advance")
+	}
+}
+
+func TestPause(t *testing.T) {
+	c := New()
+	c.SetFixedDelta(10 * time.Millisecond)
+	c.Pause()
+	if !c.Paused() {
+		t.Fatal("expected clock to be paused")
+	}
+	if d := c.Delta(); d != 0 {
+		t.Fatal("got delta", d, "expected zero while paused")
+	}
+	before := c.Time()
+	time.Sleep(20 * time.Millisecond)
+	if after := c.Time(); after != before {
+		t.Fatal("time advanced while paused", before, after)
+	}
+
+	c.Resume()
+	time.Sleep(20 * time.Millisecond)
+	if after := c.Time(); after < before+20*time.Millisecond || after > c.RealTime()-20*time.Millisecond {
+		t.Fatal("got time", after, "expected it to advance since resuming only")
+	}
+	if d := c.Delta(); d != 10*time.Millisecond {
+		t.Fatal("got delta", d, "expected 10ms after resuming")
+	}
+}
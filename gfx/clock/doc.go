@@ -9,4 +9,23 @@
 //
 // When using a maximum frame rate, Tick blocks just long enough to ensure that
 // the application is at max running at MaxFrameRate.
+//
+// A Clock can be paused (e.g. for a pause menu) and its time scaled (e.g. for
+// slow-motion) using the Pause, Resume, and SetTimeScale methods, which affect
+// the Delta, Dt, and Time methods but not the frame rate.
+//
+// Game Loops
+//
+// A window leaves timing entirely to the user. The Loop type drives a game
+// loop with fixed timestep updates, interpolated rendering, an optional frame
+// rate cap, and frame time (jitter) statistics:
+//
+//  loop := clock.NewLoop(func(step time.Duration) {
+//      world.Step(step.Seconds())
+//  }, func(alpha float64) {
+//      world.Draw(d, alpha)
+//      d.Render()
+//  })
+//  loop.Run(stop)
+//
 package clock
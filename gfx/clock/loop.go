@@ -0,0 +1,236 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package clock
+
+import (
+	"math"
+	"runtime"
+	"time"
+)
+
+// Pacing is a strategy for waiting until the next frame when the frame rate
+// of a Loop is capped.
+type Pacing int
+
+const (
+	// SleepSpin sleeps until shortly before the next frame (see
+	// Loop.SpinThreshold) and then spins for the remaining time. It is both
+	// precise and light on the CPU, and the default.
+	SleepSpin Pacing = iota
+
+	// Sleep sleeps until the next frame. It uses the least CPU time, but the
+	// precision depends on the operating system's timer resolution (up to
+	// 15ms on some systems).
+	Sleep
+
+	// Spin busy-waits until the next frame. It is the most precise, but uses
+	// an entire CPU core while waiting.
+	Spin
+)
+
+// Stats are frame time statistics of a Loop, over the last frames.
+type Stats struct {
+	// The mean, minimum, and maximum frame time.
+	Mean, Min, Max time.Duration
+
+	// The standard deviation of the frame times, i.e. the frame time jitter.
+	Jitter time.Duration
+
+	// The total number of fixed timestep updates that were dropped because a
+	// frame took too long (see Loop.MaxSteps).
+	Dropped uint64
+}
+
+// Loop drives a game loop with fixed timestep updates and variable rate
+// rendering, as outlined in the "Fix Your Timestep!" article:
+//
+//  https://gafferongames.com/post/fix_your_timestep/
+//
+// Game logic (e.g. physics) runs in Update at a fixed rate regardless of the
+// frame rate, which keeps it deterministic and stable. Render is called once
+// per frame with an interpolation factor, alpha, which tells how far in time
+// (as a fraction of Step) the frame is between the last two updates:
+//
+//  state := previous.Lerp(current, alpha)
+//
+// A Loop is not safe for use concurrently from multiple goroutines.
+type Loop struct {
+	// Step is the fixed timestep at which Update is called.
+	Step time.Duration
+
+	// MaxSteps is the maximum number of updates per frame. If the updates fall
+	// further behind than this (e.g. the application was stalled), the
+	// remaining time is dropped instead of attempting to catch up, which would
+	// only slow the application down further.
+	MaxSteps int
+
+	// MaxFrameRate caps the frame rate, zero means uncapped (e.g. to rely on
+	// vertical sync instead).
+	MaxFrameRate float64
+
+	// Pacing is the strategy used to wait for the next frame when the frame
+	// rate is capped.
+	Pacing Pacing
+
+	// SpinThreshold is the time before the next frame at which the SleepSpin
+	// pacing strategy stops sleeping and starts spinning.
+	SpinThreshold time.Duration
+
+	// Update is called with Step for each fixed timestep, or nil.
+	Update func(step time.Duration)
+
+	// Render is called once per frame with the interpolation factor in the
+	// range of [0, 1), or nil.
+	Render func(alpha float64)
+
+	started           bool
+	last, accumulator time.Duration
+	samples           []time.Duration
+	next              int
+	dropped           uint64
+
+	// Time source and sleep function, swapped out by tests.
+	now   func() time.Duration
+	sleep func(time.Duration)
+}
+
+// Alpha returns the current interpolation factor, as passed to Render.
+func (l *Loop) Alpha() float64 {
+	if l.Step <= 0 {
+		return 0
+	}
+	return float64(l.accumulator) / float64(l.Step)
+}
+
+// wait waits until the given time, using the pacing strategy.
+func (l *Loop) wait(until time.Duration) {
+	for {
+		remaining := until - l.now()
+		if remaining <= 0 {
+			return
+		}
+		switch l.Pacing {
+		case Sleep:
+			l.sleep(remaining)
+			return
+		case SleepSpin:
+			if remaining > l.SpinThreshold {
+				l.sleep(remaining - l.SpinThreshold)
+				continue
+			}
+			runtime.Gosched()
+		default:
+			runtime.Gosched()
+		}
+	}
+}
+
+// Frame runs a single frame of the loop: it waits for the frame rate cap (if
+// any), runs the fixed timestep updates that are due, and then renders.
+func (l *Loop) Frame() {
+	if !l.started {
+		l.started = true
+		l.last = l.now()
+	}
+
+	// Cap the frame rate.
+	if l.MaxFrameRate > 0 {
+		l.wait(l.last + time.Duration(float64(time.Second)/l.MaxFrameRate))
+	}
+
+	now := l.now()
+	frameTime := now - l.last
+	l.last = now
+	l.samples[l.next] = frameTime
+	l.next = (l.next + 1) % len(l.samples)
+
+	// Run the fixed timestep updates.
+	if l.Step > 0 {
+		l.accumulator += frameTime
+		steps := 0
+		for l.accumulator >= l.Step {
+			if steps == l.MaxSteps && l.MaxSteps > 0 {
+				dropped := l.accumulator / l.Step
+				l.dropped += uint64(dropped)
+				l.accumulator -= dropped * l.Step
+				break
+			}
+			if l.Update != nil {
+				l.Update(l.Step)
+			}
+			l.accumulator -= l.Step
+			steps++
+		}
+	}
+
+	if l.Render != nil {
+		l.Render(l.Alpha())
+	}
+}
+
+// Run runs frames until the given channel is closed (or receives).
+func (l *Loop) Run(stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		l.Frame()
+	}
+}
+
+// Stats returns statistics about the frame times of the loop, over the last
+// frames.
+func (l *Loop) Stats() Stats {
+	s := Stats{Dropped: l.dropped}
+	var n int
+	for _, sample := range l.samples {
+		if sample == 0 {
+			continue
+		}
+		if n == 0 || sample < s.Min {
+			s.Min = sample
+		}
+		if sample > s.Max {
+			s.Max = sample
+		}
+		s.Mean += sample
+		n++
+	}
+	if n == 0 {
+		return s
+	}
+	s.Mean /= time.Duration(n)
+
+	var variance float64
+	for _, sample := range l.samples {
+		if sample == 0 {
+			continue
+		}
+		diff := float64(sample - s.Mean)
+		variance += diff * diff
+	}
+	s.Jitter = time.Duration(math.Sqrt(variance / float64(n)))
+	return s
+}
+
+// NewLoop returns a new loop calling the given update and render functions
+// (either may be nil). The loop has a Step of 1/60th of a second, MaxSteps of
+// 5, no frame rate cap, SleepSpin pacing with a SpinThreshold of 2ms, and
+// collects statistics over the last 120 frames.
+func NewLoop(update func(step time.Duration), render func(alpha float64)) *Loop {
+	return &Loop{
+		Step:          time.Second / 60,
+		MaxSteps:      5,
+		Pacing:        SleepSpin,
+		SpinThreshold: 2 * time.Millisecond,
+		Update:        update,
+		Render:        render,
+		samples:       make([]time.Duration, 120),
+		now:           getTime,
+		sleep:         time.Sleep,
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeLoop returns a loop whose time advances by frameTime each frame, and
+// only through sleeping otherwise.
+func fakeLoop(frameTime time.Duration, update func(time.Duration), render func(float64)) (*Loop, *time.Duration) {
+	l := NewLoop(update, render)
+	now := new(time.Duration)
+	l.now = func() time.Duration { return *now }
+	l.sleep = func(d time.Duration) { *now += d }
+	l.Render = func(alpha float64) {
+		if render != nil {
+			render(alpha)
+		}
+		*now += frameTime
+	}
+	return l, now
+}
+
+func TestLoopFixedStep(t *testing.T) {
+	var updates int
+	var alphas []float64
+	l, _ := fakeLoop(25*time.Millisecond, func(step time.Duration) {
+		if step != 10*time.Millisecond {
+			t.Fatalf("got step %v want 10ms", step)
+		}
+		updates++
+	}, func(alpha float64) {
+		alphas = append(alphas, alpha)
+	})
+	l.Step = 10 * time.Millisecond
+
+	for i := 0; i < 5; i++ {
+		l.Frame()
+	}
+	// 4 frames of 25ms have elapsed (the first frame has zero time).
+	if updates != 10 {
+		t.Fatalf("got %d updates want 10", updates)
+	}
+	want := []float64{0, 0.5, 0, 0.5, 0}
+	for i, a := range alphas {
+		if a != want[i] {
+			t.Fatalf("frame %d: got alpha %v want %v", i, a, want[i])
+		}
+	}
+}
+
+func TestLoopMaxSteps(t *testing.T) {
+	var updates int
+	l, now := fakeLoop(0, func(time.Duration) { updates++ }, nil)
+	l.Step = 10 * time.Millisecond
+	l.MaxSteps = 3
+	l.Frame()
+	*now += 105 * time.Millisecond
+	l.Frame()
+	if updates != 3 {
+		t.Fatalf("got %d updates want 3", updates)
+	}
+	if s := l.Stats(); s.Dropped != 7 {
+		t.Fatalf("got %d dropped updates want 7", s.Dropped)
+	}
+	if a := l.Alpha(); a < 0.49 || a > 0.51 {
+		t.Fatalf("got alpha %v want 0.5", a)
+	}
+}
+
+func TestLoopMaxFrameRate(t *testing.T) {
+	for _, pacing := range []Pacing{Sleep, SleepSpin} {
+		l, now := fakeLoop(2*time.Millisecond, nil, nil)
+		l.MaxFrameRate = 100
+		l.Pacing = pacing
+		l.now = func() time.Duration {
+			// Time passes while spinning.
+			*now += time.Microsecond
+			return *now
+		}
+		for i := 0; i < 11; i++ {
+			l.Frame()
+		}
+		if *now < 100*time.Millisecond {
+			t.Fatalf("pacing %v: 10 frames took %v, want at least 100ms", pacing, *now)
+		}
+		s := l.Stats()
+		if s.Max > 11*time.Millisecond || s.Jitter > time.Millisecond {
+			t.Fatalf("pacing %v: got stats %+v", pacing, s)
+		}
+	}
+}
+
+func TestLoopStats(t *testing.T) {
+	l, now := fakeLoop(0, nil, nil)
+	l.Frame()
+	for _, d := range []time.Duration{10, 20, 30} {
+		*now += d * time.Millisecond
+		l.Frame()
+	}
+	s := l.Stats()
+	if s.Mean != 20*time.Millisecond || s.Min != 10*time.Millisecond || s.Max != 30*time.Millisecond {
+		t.Fatalf("got stats %+v", s)
+	}
+	if s.Jitter < 8*time.Millisecond || s.Jitter > 9*time.Millisecond {
+		t.Fatalf("got jitter %v want ~8.16ms", s.Jitter)
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import "image"
+
+// cmdOp is the operation of a single recorded command.
+type cmdOp uint8
+
+const (
+	cmdClear cmdOp = iota
+	cmdClearDepth
+	cmdClearStencil
+	cmdDraw
+	cmdSetPipeline
+	cmdPushDebugGroup
+	cmdPopDebugGroup
+)
+
+// command is a single recorded command, only the fields relevant to it's
+// operation are used.
+type command struct {
+	op       cmdOp
+	rect     image.Rectangle
+	bg       Color
+	depth    float64
+	stencil  int
+	obj      *Object
+	cam      Camera
+	pipeline *Pipeline
+	name     string
+}
+
+// CommandList is a list of recorded canvas commands, which are executed later
+// and in order when the list is submitted to a canvas (see Submit).
+//
+// Recording commands does not touch the canvas or the objects drawn at all,
+// so multiple goroutines may each record their own list concurrently (e.g.
+// while traversing different parts of a scene), with the lists then being
+// submitted by a single goroutine:
+//
+//  // On each worker goroutine:
+//  l.SetPipeline(opaque)
+//  for _, obj := range visible {
+//      l.Draw(rect, obj, cam)
+//  }
+//
+//  // On the render goroutine:
+//  gfx.Submit(device, lists...)
+//  device.Render()
+//
+// Like a call to Canvas.Draw, recording a draw command passes ownership of
+// the object to the list; ownership passes to the canvas once the list is
+// submitted.
+//
+// A command list and it's methods are not safe for access from multiple
+// goroutines concurrently.
+type CommandList struct {
+	cmds []command
+}
+
+// Clear records a clear command, see Canvas.Clear.
+func (l *CommandList) Clear(r image.Rectangle, bg Color) {
+	l.cmds = append(l.cmds, command{op: cmdClear, rect: r, bg: bg})
+}
+
+// ClearDepth records a depth clear command, see Canvas.ClearDepth.
+func (l *CommandList) ClearDepth(r image.Rectangle, depth float64) {
+	l.cmds = append(l.cmds, command{op: cmdClearDepth, rect: r, depth: depth})
+}
+
+// ClearStencil records a stencil clear command, see Canvas.ClearStencil.
+func (l *CommandList) ClearStencil(r image.Rectangle, stencil int) {
+	l.cmds = append(l.cmds, command{op: cmdClearStencil, rect: r, stencil: stencil})
+}
+
+// Draw records a draw command, see Canvas.Draw.
+func (l *CommandList) Draw(r image.Rectangle, o *Object, c Camera) {
+	l.cmds = append(l.cmds, command{op: cmdDraw, rect: r, obj: o, cam: c})
+}
+
+// SetPipeline records a command which sets the pipeline of the objects drawn
+// by the draw commands that follow it. When executed, each such draw command
+// assigns the pipeline to the Pipeline field of it's object before drawing
+// it.
+//
+// If the pipeline is nil, the objects drawn by the draw commands that follow
+// are left untouched (i.e. drawn with their own pipeline, if any).
+func (l *CommandList) SetPipeline(p *Pipeline) {
+	l.cmds = append(l.cmds, command{op: cmdSetPipeline, pipeline: p})
+}
+
+// PushDebugGroup records a command which pushes a debug group, see
+// DebugGrouper. If the canvas does not implement DebugGrouper it is ignored.
+func (l *CommandList) PushDebugGroup(name string) {
+	l.cmds = append(l.cmds, command{op: cmdPushDebugGroup, name: name})
+}
+
+// PopDebugGroup records a command which pops a debug group, see DebugGrouper.
+// If the canvas does not implement DebugGrouper it is ignored.
+func (l *CommandList) PopDebugGroup() {
+	l.cmds = append(l.cmds, command{op: cmdPopDebugGroup})
+}
+
+// Len returns the number of commands in the list.
+func (l *CommandList) Len() int {
+	return len(l.cmds)
+}
+
+// Reset resets the list to empty, such that it may be reused for recording
+// (e.g. the next frame) without reallocating.
+func (l *CommandList) Reset() {
+	for i := range l.cmds {
+		l.cmds[i] = command{}
+	}
+	l.cmds = l.cmds[:0]
+}
+
+// Execute executes each command of the list, in order, by issuing it to the
+// given canvas. Most callers should use Submit instead, which allows the
+// canvas to execute the list more efficiently.
+func (l *CommandList) Execute(c Canvas) {
+	grouper, _ := c.(DebugGrouper)
+	var pipeline *Pipeline
+	for _, cmd := range l.cmds {
+		switch cmd.op {
+		case cmdClear:
+			c.Clear(cmd.rect, cmd.bg)
+		case cmdClearDepth:
+			c.ClearDepth(cmd.rect, cmd.depth)
+		case cmdClearStencil:
+			c.ClearStencil(cmd.rect, cmd.stencil)
+		case cmdDraw:
+			if pipeline != nil {
+				cmd.obj.Pipeline = pipeline
+			}
+			c.Draw(cmd.rect, cmd.obj, cmd.cam)
+		case cmdSetPipeline:
+			pipeline = cmd.pipeline
+		case cmdPushDebugGroup:
+			if grouper != nil {
+				grouper.PushDebugGroup(cmd.name)
+			}
+		case cmdPopDebugGroup:
+			if grouper != nil {
+				grouper.PopDebugGroup()
+			}
+		}
+	}
+}
+
+// NewCommandList returns a new, empty, command list.
+func NewCommandList() *CommandList {
+	return &CommandList{}
+}
+
+// CommandSubmitter is an optional interface that a Canvas may implement in
+// order to execute command lists more efficiently than it would the commands
+// individually (e.g. by handing all of them to it's rendering thread at
+// once).
+type CommandSubmitter interface {
+	// Submit executes each command of the given list, in order, with the
+	// same effect as l.Execute(c) would have.
+	//
+	// The list itself is not retained, and may be reset and reused once
+	// Submit returns.
+	Submit(l *CommandList)
+}
+
+// Submit submits each of the given command lists, in order, to the canvas. If
+// the canvas implements CommandSubmitter then it's Submit method is used,
+// otherwise each list is executed with it's Execute method.
+func Submit(c Canvas, lists ...*CommandList) {
+	s, ok := c.(CommandSubmitter)
+	for _, l := range lists {
+		if ok {
+			s.Submit(l)
+			continue
+		}
+		l.Execute(c)
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"fmt"
+	"image"
+	"reflect"
+	"testing"
+)
+
+// logCanvas is a canvas which logs the commands issued to it.
+type logCanvas struct {
+	Canvas
+	log []string
+}
+
+func (c *logCanvas) Clear(r image.Rectangle, bg Color) {
+	c.log = append(c.log, fmt.Sprintf("Clear %v", bg))
+}
+
+func (c *logCanvas) ClearDepth(r image.Rectangle, depth float64) {
+	c.log = append(c.log, fmt.Sprintf("ClearDepth %v", depth))
+}
+
+func (c *logCanvas) ClearStencil(r image.Rectangle, stencil int) {
+	c.log = append(c.log, fmt.Sprintf("ClearStencil %v", stencil))
+}
+
+func (c *logCanvas) Draw(r image.Rectangle, o *Object, cam Camera) {
+	name := "<nil>"
+	if o.Pipeline != nil {
+		name = o.Pipeline.Shader.Name
+	}
+	c.log = append(c.log, fmt.Sprintf("Draw %s %s", o.Label, name))
+}
+
+func (c *logCanvas) PushDebugGroup(name string) {
+	c.log = append(c.log, "Push "+name)
+}
+
+func (c *logCanvas) PopDebugGroup() {
+	c.log = append(c.log, "Pop")
+}
+
+func TestCommandList(t *testing.T) {
+	rect := image.Rect(0, 0, 4, 4)
+	a, b := NewObject(), NewObject()
+	a.Label, b.Label = "a", "b"
+	p := NewPipeline(NewShader("p"), NewState())
+
+	l := NewCommandList()
+	l.PushDebugGroup("scene")
+	l.Clear(rect, Color{1, 1, 1, 1})
+	l.ClearDepth(rect, 1)
+	l.ClearStencil(rect, 0)
+	l.Draw(rect, a, nil)
+	l.SetPipeline(p)
+	l.Draw(rect, b, nil)
+	l.PopDebugGroup()
+	if l.Len() != 8 {
+		t.Fatalf("got %d commands, want 8", l.Len())
+	}
+
+	c := &logCanvas{}
+	Submit(c, l)
+	want := []string{
+		"Push scene",
+		"Clear {1 1 1 1}",
+		"ClearDepth 1",
+		"ClearStencil 0",
+		"Draw a <nil>",
+		"Draw b p",
+		"Pop",
+	}
+	if !reflect.DeepEqual(c.log, want) {
+		t.Fatalf("got %q\nwant %q", c.log, want)
+	}
+
+	l.Reset()
+	if l.Len() != 0 {
+		t.Fatal("expected empty list after Reset")
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package color implements color space conversions, gradients and palettes of
+// gfx.Color values.
+//
+// Unless stated otherwise, colors are sRGB encoded (as picked in an image
+// editor, or written in hex), and the functions of this package convert them
+// to linear space (for lighting, see package shaders), HSV and HSL (for hue
+// and saturation adjustments) or Oklab (for perceptually even mixing) as
+// needed:
+//
+//  orange, _ := color.Hex("#ff8800")
+//  light := color.ToLinear(orange) // For e.g. LightColor.
+//  h, s, v := color.HSV(orange)
+//  muted := color.FromHSV(h, s*0.5, v)
+//
+// Gradients evaluate colors along a range, e.g. for the color of a particle
+// over it's lifetime or for a heat map of debug values:
+//
+//  heat := color.Viridis.Gradient(color.OklabSpace)
+//  c := heat.At(cost / maxCost)
+//
+// Alpha is never converted, only interpolated.
+package color // import "azul3d.org/engine/gfx/color"
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"azul3d.org/engine/gfx"
+)
+
+// SRGBToLinear converts the given sRGB encoded color component, in the range
+// of [0, 1], into linear space.
+func SRGBToLinear(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// LinearToSRGB converts the given linear color component, in the range of
+// [0, 1], into sRGB encoded space. It is the inverse of SRGBToLinear.
+func LinearToSRGB(v float64) float64 {
+	if v <= 0.0031308 {
+		return v * 12.92
+	}
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
+
+// ToLinear converts the RGB components of the given sRGB encoded color into
+// linear space.
+func ToLinear(c gfx.Color) gfx.Color {
+	return gfx.Color{
+		R: float32(SRGBToLinear(float64(c.R))),
+		G: float32(SRGBToLinear(float64(c.G))),
+		B: float32(SRGBToLinear(float64(c.B))),
+		A: c.A,
+	}
+}
+
+// ToSRGB converts the RGB components of the given linear color into sRGB
+// encoded space. It is the inverse of ToLinear.
+func ToSRGB(c gfx.Color) gfx.Color {
+	return gfx.Color{
+		R: float32(LinearToSRGB(float64(c.R))),
+		G: float32(LinearToSRGB(float64(c.G))),
+		B: float32(LinearToSRGB(float64(c.B))),
+		A: c.A,
+	}
+}
+
+// Luminance returns the relative luminance of the given sRGB encoded color,
+// in the range of [0, 1], e.g. to choose a legible text color for a
+// background.
+func Luminance(c gfx.Color) float64 {
+	l := ToLinear(c)
+	return 0.2126*float64(l.R) + 0.7152*float64(l.G) + 0.0722*float64(l.B)
+}
+
+// Clamp returns the color with each component clamped to the range of [0, 1].
+func Clamp(c gfx.Color) gfx.Color {
+	clamp := func(v float32) float32 {
+		if v < 0 {
+			return 0
+		}
+		if v > 1 {
+			return 1
+		}
+		return v
+	}
+	return gfx.Color{R: clamp(c.R), G: clamp(c.G), B: clamp(c.B), A: clamp(c.A)}
+}
+
+// Premultiply returns the color with it's RGB components multiplied by it's
+// alpha, as expected by premultiplied alpha blending (see
+// gfx.DefaultBlendState).
+func Premultiply(c gfx.Color) gfx.Color {
+	return gfx.Color{R: c.R * c.A, G: c.G * c.A, B: c.B * c.A, A: c.A}
+}
+
+// Hex parses the given hexadecimal color of the form "#rgb", "#rgba",
+// "#rrggbb" or "#rrggbbaa" (the "#" is optional). Colors without alpha are
+// opaque.
+func Hex(s string) (gfx.Color, error) {
+	h := strings.TrimPrefix(s, "#")
+	switch len(h) {
+	case 3, 4:
+		// Each digit is repeated, e.g. "f80" is "ff8800".
+		var long []byte
+		for i := 0; i < len(h); i++ {
+			long = append(long, h[i], h[i])
+		}
+		h = string(long)
+	case 6, 8:
+	default:
+		return gfx.Color{}, fmt.Errorf("color: invalid hex color %q", s)
+	}
+	if len(h) == 6 {
+		h += "ff"
+	}
+	v, err := strconv.ParseUint(h, 16, 32)
+	if err != nil {
+		return gfx.Color{}, fmt.Errorf("color: invalid hex color %q", s)
+	}
+	return gfx.Color{
+		R: float32(v>>24&0xff) / 255,
+		G: float32(v>>16&0xff) / 255,
+		B: float32(v>>8&0xff) / 255,
+		A: float32(v&0xff) / 255,
+	}, nil
+}
+
+// MustHex is like Hex, but panics if the color is invalid. It is intended for
+// colors which are constant, e.g. of a theme.
+func MustHex(s string) gfx.Color {
+	c, err := Hex(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// ToHex returns the hexadecimal form of the given color, "#rrggbb" if it is
+// opaque or "#rrggbbaa" otherwise. Components are clamped to [0, 1].
+func ToHex(c gfx.Color) string {
+	c = Clamp(c)
+	b := func(v float32) uint8 {
+		return uint8(math.Floor(float64(v)*255 + 0.5))
+	}
+	if c.A == 1 {
+		return fmt.Sprintf("#%02x%02x%02x", b(c.R), b(c.G), b(c.B))
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", b(c.R), b(c.G), b(c.B), b(c.A))
+}
+
+// Temperature returns the sRGB encoded color of a black body radiating at the
+// given temperature in Kelvin, e.g. 1900 for candle light, 2700 for an
+// incandescent bulb or 6500 for daylight. The approximation is valid between
+// 1000 and 40000 Kelvin, outside of which the temperature is clamped.
+func Temperature(kelvin float64) gfx.Color {
+	// An approximation by curve fitting the CIE 1964 10 degree color matching
+	// functions, by Tanner Helland.
+	t := math.Min(math.Max(kelvin, 1000), 40000) / 100
+	var r, g, b float64
+	if t <= 66 {
+		r = 255
+		g = 99.4708025861*math.Log(t) - 161.1195681661
+	} else {
+		r = 329.698727446 * math.Pow(t-60, -0.1332047592)
+		g = 288.1221695283 * math.Pow(t-60, -0.0755148492)
+	}
+	switch {
+	case t >= 66:
+		b = 255
+	case t <= 19:
+		b = 0
+	default:
+		b = 138.5177312231*math.Log(t-10) - 305.0447927307
+	}
+	return Clamp(gfx.Color{
+		R: float32(r / 255),
+		G: float32(g / 255),
+		B: float32(b / 255),
+		A: 1,
+	})
+}
@@ -0,0 +1,162 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package color
+
+import (
+	"math"
+	"testing"
+
+	"azul3d.org/engine/gfx"
+)
+
+// near tells whether the RGBA components of a and b are within eps.
+func near(a, b gfx.Color, eps float32) bool {
+	abs := func(v float32) float32 {
+		if v < 0 {
+			return -v
+		}
+		return v
+	}
+	return abs(a.R-b.R) <= eps && abs(a.G-b.G) <= eps && abs(a.B-b.B) <= eps && abs(a.A-b.A) <= eps
+}
+
+func TestSRGB(t *testing.T) {
+	for _, v := range []float64{0, 0.01, 0.04045, 0.2, 0.5, 1} {
+		if got := LinearToSRGB(SRGBToLinear(v)); math.Abs(got-v) > 1e-6 {
+			t.Errorf("round trip of %v = %v", v, got)
+		}
+	}
+	if l := SRGBToLinear(0.5); math.Abs(l-0.214) > 1e-3 {
+		t.Fatalf("SRGBToLinear(0.5) = %v, want 0.214", l)
+	}
+	c := gfx.Color{R: 0.2, G: 0.5, B: 0.8, A: 0.5}
+	if got := ToSRGB(ToLinear(c)); !near(got, c, 1e-6) {
+		t.Fatalf("round trip of %v = %v", c, got)
+	}
+	if l := Luminance(gfx.Color{R: 1, G: 1, B: 1, A: 1}); math.Abs(l-1) > 1e-6 {
+		t.Fatalf("luminance of white = %v", l)
+	}
+}
+
+func TestHex(t *testing.T) {
+	for _, tc := range []struct {
+		s    string
+		want gfx.Color
+	}{
+		{"#ff8000", gfx.Color{R: 1, G: 128.0 / 255, B: 0, A: 1}},
+		{"f80", gfx.Color{R: 1, G: 136.0 / 255, B: 0, A: 1}},
+		{"#00000080", gfx.Color{A: 128.0 / 255}},
+	} {
+		got, err := Hex(tc.s)
+		if err != nil || got != tc.want {
+			t.Errorf("Hex(%q) = %v, %v, want %v", tc.s, got, err, tc.want)
+		}
+	}
+	for _, s := range []string{"", "#12345", "#gggggg"} {
+		if _, err := Hex(s); err == nil {
+			t.Errorf("Hex(%q) did not fail", s)
+		}
+	}
+	if h := ToHex(MustHex("#4e79a7")); h != "#4e79a7" {
+		t.Fatalf("ToHex = %q", h)
+	}
+	if h := ToHex(gfx.Color{R: 2, A: 0.5}); h != "#ff000080" {
+		t.Fatalf("ToHex = %q", h)
+	}
+}
+
+func TestHSVAndHSL(t *testing.T) {
+	orange := gfx.Color{R: 1, G: 0.5, B: 0, A: 1}
+	h, s, v := HSV(orange)
+	if math.Abs(h-30) > 1e-6 || s != 1 || v != 1 {
+		t.Fatalf("HSV = %v %v %v, want 30 1 1", h, s, v)
+	}
+	h, s, l := HSL(orange)
+	if math.Abs(h-30) > 1e-6 || s != 1 || l != 0.5 {
+		t.Fatalf("HSL = %v %v %v, want 30 1 0.5", h, s, l)
+	}
+	for _, c := range []gfx.Color{
+		{R: 0.2, G: 0.4, B: 0.9, A: 1},
+		{R: 0.9, G: 0.1, B: 0.3, A: 1},
+		{R: 0.5, G: 0.5, B: 0.5, A: 1},
+	} {
+		if got := FromHSV(HSV(c)); !near(got, c, 1e-6) {
+			t.Errorf("HSV round trip of %v = %v", c, got)
+		}
+		if got := FromHSL(HSL(c)); !near(got, c, 1e-6) {
+			t.Errorf("HSL round trip of %v = %v", c, got)
+		}
+	}
+	if got := FromHSV(360+120, 1, 1); !near(got, gfx.Color{G: 1, A: 1}, 1e-6) {
+		t.Fatalf("hue did not wrap around: %v", got)
+	}
+}
+
+func TestOklab(t *testing.T) {
+	white := ToOklab(gfx.Color{R: 1, G: 1, B: 1, A: 1})
+	if math.Abs(white.L-1) > 1e-4 || math.Abs(white.A) > 1e-4 || math.Abs(white.B) > 1e-4 {
+		t.Fatalf("white = %+v", white)
+	}
+	c := gfx.Color{R: 0.2, G: 0.6, B: 0.3, A: 1}
+	o := ToOklab(c)
+	if got := o.Color(); !near(got, c, 1e-5) {
+		t.Fatalf("round trip of %v = %v", c, got)
+	}
+	if got := OklabFromLCh(o.LCh()); got.Distance(o) > 1e-9 {
+		t.Fatalf("LCh round trip of %+v = %+v", o, got)
+	}
+}
+
+func TestTemperature(t *testing.T) {
+	if c := Temperature(6600); !near(c, gfx.Color{R: 1, G: 1, B: 1, A: 1}, 0.02) {
+		t.Fatalf("6600K = %v, want white", c)
+	}
+	candle := Temperature(1900)
+	if candle.R != 1 || candle.B > 0.1 || candle.G > 0.6 {
+		t.Fatalf("1900K = %v, want orange", candle)
+	}
+	if sky := Temperature(15000); sky.B != 1 || sky.R > 0.8 {
+		t.Fatalf("15000K = %v, want blue", sky)
+	}
+}
+
+func TestGradient(t *testing.T) {
+	red, blue := gfx.Color{R: 1, A: 1}, gfx.Color{B: 1, A: 0}
+	g := NewGradient(SRGBSpace, red, blue)
+	if g.At(-1) != red || g.At(2) != blue {
+		t.Fatal("not clamped to the end stops")
+	}
+	if got := g.At(0.5); !near(got, gfx.Color{R: 0.5, B: 0.5, A: 0.5}, 1e-6) {
+		t.Fatalf("At(0.5) = %v", got)
+	}
+
+	// Mixing in linear space is brighter than in sRGB space.
+	g.Space = LinearSpace
+	if got := g.At(0.5); math.Abs(float64(got.R)-LinearToSRGB(0.5)) > 1e-6 {
+		t.Fatalf("linear At(0.5) = %v", got)
+	}
+	g.Space = OklabSpace
+	if got := g.At(0.25); got.R <= got.B {
+		t.Fatalf("oklab At(0.25) = %v", got)
+	}
+
+	if (Gradient{}).At(0.5) != (gfx.Color{}) {
+		t.Fatal("empty gradient is not transparent")
+	}
+	v := Viridis.Gradient(OklabSpace)
+	if !near(v.At(1), Viridis[len(Viridis)-1], 1e-6) {
+		t.Fatal("viridis does not end with it's last color")
+	}
+}
+
+func TestPalette(t *testing.T) {
+	p := Palettes["categorical"]
+	if len(p) != 10 || p.At(10) != p[0] || p.At(-1) != p[9] {
+		t.Fatal("palette does not wrap around")
+	}
+	if (Palette{}).At(3) != (gfx.Color{}) {
+		t.Fatal("empty palette is not transparent")
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package color
+
+import (
+	"fmt"
+	"sort"
+
+	"azul3d.org/engine/gfx"
+)
+
+// Space is a color space that colors are mixed in.
+type Space uint8
+
+const (
+	// SRGBSpace mixes the sRGB encoded components, as most image editors do.
+	// Mixes of saturated colors appear darker than either color.
+	SRGBSpace Space = iota
+
+	// LinearSpace mixes the components in linear space, as light mixes.
+	LinearSpace
+
+	// OklabSpace mixes the colors in the Oklab color space, such that the
+	// perceived lightness and hue change evenly.
+	OklabSpace
+)
+
+// String returns the name of the color space, e.g. "OklabSpace".
+func (s Space) String() string {
+	switch s {
+	case SRGBSpace:
+		return "SRGBSpace"
+	case LinearSpace:
+		return "LinearSpace"
+	case OklabSpace:
+		return "OklabSpace"
+	}
+	return fmt.Sprintf("Space(%d)", s)
+}
+
+// Mix returns the mix of the sRGB encoded colors a and b, in the given color
+// space, where t=0 returns a and t=1 returns b. Alpha is mixed linearly.
+func Mix(a, b gfx.Color, t float64, s Space) gfx.Color {
+	lerp := func(a, b float32) float32 {
+		return a + (b-a)*float32(t)
+	}
+	var c gfx.Color
+	switch s {
+	case LinearSpace:
+		la, lb := ToLinear(a), ToLinear(b)
+		c = ToSRGB(gfx.Color{R: lerp(la.R, lb.R), G: lerp(la.G, lb.G), B: lerp(la.B, lb.B)})
+	case OklabSpace:
+		oa, ob := ToOklab(a), ToOklab(b)
+		c = Oklab{
+			L: oa.L + (ob.L-oa.L)*t,
+			A: oa.A + (ob.A-oa.A)*t,
+			B: oa.B + (ob.B-oa.B)*t,
+		}.Color()
+	default:
+		c = gfx.Color{R: lerp(a.R, b.R), G: lerp(a.G, b.G), B: lerp(a.B, b.B)}
+	}
+	c.A = lerp(a.A, b.A)
+	return c
+}
+
+// Stop is a color stop of a gradient.
+type Stop struct {
+	// The position of the stop along the gradient.
+	Pos float64
+
+	// The sRGB encoded color at the position.
+	Color gfx.Color
+}
+
+// Gradient is a gradient of colors between stops.
+type Gradient struct {
+	// The stops of the gradient, sorted by their position.
+	Stops []Stop
+
+	// The color space that colors between the stops are mixed in.
+	Space Space
+}
+
+// At returns the color of the gradient at the given position. Positions
+// before the first stop, or after the last, take it's color. If the gradient
+// has no stops, the zero color (i.e. transparent black) is returned.
+func (g Gradient) At(t float64) gfx.Color {
+	n := len(g.Stops)
+	switch {
+	case n == 0:
+		return gfx.Color{}
+	case t <= g.Stops[0].Pos:
+		return g.Stops[0].Color
+	case t >= g.Stops[n-1].Pos:
+		return g.Stops[n-1].Color
+	}
+	i := sort.Search(n, func(i int) bool {
+		return g.Stops[i].Pos > t
+	}) - 1
+	a, b := g.Stops[i], g.Stops[i+1]
+	return Mix(a.Color, b.Color, (t-a.Pos)/(b.Pos-a.Pos), g.Space)
+}
+
+// NewGradient returns a gradient of the given colors evenly spaced over the
+// range of [0, 1], mixed in the given color space.
+func NewGradient(s Space, colors ...gfx.Color) Gradient {
+	g := Gradient{
+		Stops: make([]Stop, len(colors)),
+		Space: s,
+	}
+	for i, c := range colors {
+		if len(colors) > 1 {
+			g.Stops[i].Pos = float64(i) / float64(len(colors)-1)
+		}
+		g.Stops[i].Color = c
+	}
+	return g
+}
@@ -0,0 +1,75 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package color
+
+import "azul3d.org/engine/gfx"
+
+// Palette is a list of sRGB encoded colors.
+type Palette []gfx.Color
+
+// At returns the i'th color of the palette, wrapping around such that any
+// index (e.g. of a debug visualization's object) has a color. If the palette
+// is empty, the zero color (i.e. transparent black) is returned.
+func (p Palette) At(i int) gfx.Color {
+	if len(p) == 0 {
+		return gfx.Color{}
+	}
+	i %= len(p)
+	if i < 0 {
+		i += len(p)
+	}
+	return p[i]
+}
+
+// Gradient returns a gradient of the colors of the palette, evenly spaced over
+// the range of [0, 1] and mixed in the given color space.
+func (p Palette) Gradient(s Space) Gradient {
+	return NewGradient(s, p...)
+}
+
+// hexPalette returns a palette of the given hexadecimal colors.
+func hexPalette(colors ...string) Palette {
+	p := make(Palette, len(colors))
+	for i, c := range colors {
+		p[i] = MustHex(c)
+	}
+	return p
+}
+
+var (
+	// Viridis is a perceptually uniform sequential palette from dark blue to
+	// yellow, legible to color blind viewers and in grayscale, e.g. for heat
+	// maps.
+	Viridis = hexPalette(
+		"#440154", "#482878", "#3e4989", "#31688e", "#26828e",
+		"#1f9e89", "#35b779", "#6ece58", "#b5de2b", "#fde725",
+	)
+
+	// Magma is a perceptually uniform sequential palette from black through
+	// purple to pale yellow.
+	Magma = hexPalette(
+		"#000004", "#180f3d", "#440f76", "#721f81", "#9e2f7f",
+		"#cd4071", "#f1605d", "#fd9668", "#feca8d", "#fcfdbf",
+	)
+
+	// Categorical is a palette of ten distinct colors (Tableau 10), e.g. for
+	// telling apart the objects or passes of a debug visualization.
+	Categorical = hexPalette(
+		"#4e79a7", "#f28e2b", "#e15759", "#76b7b2", "#59a14f",
+		"#edc948", "#b07aa1", "#ff9da7", "#9c755f", "#bab0ac",
+	)
+
+	// Grayscale is a palette from black to white.
+	Grayscale = hexPalette("#000000", "#ffffff")
+)
+
+// Palettes maps the lower-case names of the palettes of this package (e.g.
+// "viridis") to them, e.g. for choosing one from a console variable.
+var Palettes = map[string]Palette{
+	"viridis":     Viridis,
+	"magma":       Magma,
+	"categorical": Categorical,
+	"grayscale":   Grayscale,
+}
@@ -0,0 +1,178 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package color
+
+import (
+	"math"
+
+	"azul3d.org/engine/gfx"
+)
+
+// hue returns the hue in degrees, in the range of [0, 360), of the given RGB
+// components whose maximum and minimum are given.
+func hue(r, g, b, max, min float64) float64 {
+	d := max - min
+	if d == 0 {
+		return 0
+	}
+	var h float64
+	switch max {
+	case r:
+		h = (g - b) / d
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	h *= 60
+	if h < 0 {
+		h += 360
+	}
+	return h
+}
+
+// rgbOfHue returns the RGB components of the given hue in degrees, with the
+// given chroma, lifted by m.
+func rgbOfHue(h, chroma, m float64) gfx.Color {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	h /= 60
+	x := chroma * (1 - math.Abs(math.Mod(h, 2)-1))
+	var r, g, b float64
+	switch {
+	case h < 1:
+		r, g, b = chroma, x, 0
+	case h < 2:
+		r, g, b = x, chroma, 0
+	case h < 3:
+		r, g, b = 0, chroma, x
+	case h < 4:
+		r, g, b = 0, x, chroma
+	case h < 5:
+		r, g, b = x, 0, chroma
+	default:
+		r, g, b = chroma, 0, x
+	}
+	return gfx.Color{
+		R: float32(r + m),
+		G: float32(g + m),
+		B: float32(b + m),
+		A: 1,
+	}
+}
+
+// HSV returns the hue in degrees (in the range of [0, 360)), saturation and
+// value (in the range of [0, 1]) of the RGB components of the given color.
+func HSV(c gfx.Color) (h, s, v float64) {
+	r, g, b := float64(c.R), float64(c.G), float64(c.B)
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	h = hue(r, g, b, max, min)
+	if max > 0 {
+		s = (max - min) / max
+	}
+	return h, s, max
+}
+
+// FromHSV returns the opaque color of the given hue in degrees (which wraps
+// around), saturation and value (in the range of [0, 1]). It is the inverse
+// of HSV.
+func FromHSV(h, s, v float64) gfx.Color {
+	chroma := v * s
+	return rgbOfHue(h, chroma, v-chroma)
+}
+
+// HSL returns the hue in degrees (in the range of [0, 360)), saturation and
+// lightness (in the range of [0, 1]) of the RGB components of the given
+// color.
+func HSL(c gfx.Color) (h, s, l float64) {
+	r, g, b := float64(c.R), float64(c.G), float64(c.B)
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	h = hue(r, g, b, max, min)
+	l = (max + min) / 2
+	if d := 1 - math.Abs(2*l-1); d > 0 {
+		s = (max - min) / d
+	}
+	return h, s, l
+}
+
+// FromHSL returns the opaque color of the given hue in degrees (which wraps
+// around), saturation and lightness (in the range of [0, 1]). It is the
+// inverse of HSL.
+func FromHSL(h, s, l float64) gfx.Color {
+	chroma := (1 - math.Abs(2*l-1)) * s
+	return rgbOfHue(h, chroma, l-chroma/2)
+}
+
+// Oklab is a color in the Oklab perceptual color space, in which euclidean
+// distances match perceived differences of colors, and whose lightness and
+// hue are perceptually uniform (unlike those of HSV or HSL).
+type Oklab struct {
+	// L is the perceived lightness, in the range of [0, 1].
+	L float64
+
+	// A and B are the green-red and blue-yellow axes, roughly in the range of
+	// [-0.4, 0.4].
+	A, B float64
+}
+
+// ToOklab converts the RGB components of the given sRGB encoded color into
+// the Oklab color space.
+func ToOklab(c gfx.Color) Oklab {
+	lin := ToLinear(c)
+	r, g, b := float64(lin.R), float64(lin.G), float64(lin.B)
+
+	// The cone responses, and their non-linear compression.
+	l := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*b)
+	m := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*b)
+	s := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*b)
+	return Oklab{
+		L: 0.2104542553*l + 0.7936177850*m - 0.0040720468*s,
+		A: 1.9779984951*l - 2.4285922050*m + 0.4505937099*s,
+		B: 0.0259040371*l + 0.7827717662*m - 0.8086757660*s,
+	}
+}
+
+// Color returns the opaque sRGB encoded color of the Oklab color. Colors
+// outside of the sRGB gamut are clamped. It is the inverse of ToOklab.
+func (o Oklab) Color() gfx.Color {
+	l := o.L + 0.3963377774*o.A + 0.2158037573*o.B
+	m := o.L - 0.1055613458*o.A - 0.0638541728*o.B
+	s := o.L - 0.0894841775*o.A - 1.2914855480*o.B
+	l, m, s = l*l*l, m*m*m, s*s*s
+	return ToSRGB(Clamp(gfx.Color{
+		R: float32(4.0767416621*l - 3.3077115913*m + 0.2309699292*s),
+		G: float32(-1.2684380046*l + 2.6097574011*m - 0.3413193965*s),
+		B: float32(-0.0041960863*l - 0.7034186147*m + 1.7076147010*s),
+		A: 1,
+	}))
+}
+
+// LCh returns the lightness, chroma and hue in degrees (in the range of
+// [0, 360)) of the Oklab color, i.e. it's polar form.
+func (o Oklab) LCh() (l, c, h float64) {
+	h = math.Atan2(o.B, o.A) * 180 / math.Pi
+	if h < 0 {
+		h += 360
+	}
+	return o.L, math.Hypot(o.A, o.B), h
+}
+
+// OklabFromLCh returns the Oklab color of the given lightness, chroma and hue
+// in degrees. It is the inverse of Oklab.LCh.
+func OklabFromLCh(l, c, h float64) Oklab {
+	sin, cos := math.Sincos(h * math.Pi / 180)
+	return Oklab{L: l, A: c * cos, B: c * sin}
+}
+
+// Distance returns the perceptual difference between the two Oklab colors.
+// Differences below roughly 0.02 are hard to notice.
+func (o Oklab) Distance(b Oklab) float64 {
+	dl, da, db := o.L-b.L, o.A-b.A, o.B-b.B
+	return math.Sqrt(dl*dl + da*da + db*db)
+}
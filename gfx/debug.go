@@ -0,0 +1,29 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+// DebugGrouper is an optional interface that a Device may implement in order to
+// annotate the commands it issues with named (and possibly nested) groups,
+// such that frame captures taken with graphics debuggers (e.g. RenderDoc,
+// apitrace) are readable:
+//
+//  if g, ok := d.(gfx.DebugGrouper); ok {
+//      g.PushDebugGroup("shadows")
+//      defer g.PopDebugGroup()
+//  }
+//
+// If the device does not support debug groups (see DeviceInfo.DebugLabels)
+// these methods are no-op.
+//
+// Like the Device interface, these methods are safe to call from multiple
+// goroutines concurrently (although groups are only meaningful when pushed and
+// popped in order).
+type DebugGrouper interface {
+	// PushDebugGroup pushes a new debug group with the given name.
+	PushDebugGroup(name string)
+
+	// PopDebugGroup pops the most recently pushed debug group.
+	PopDebugGroup()
+}
@@ -0,0 +1,276 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package debugdraw provides an immediate-mode debug line drawing layer.
+//
+// Lines, wireframe boxes, spheres, axes and camera frustums are accumulated
+// into a single dynamic line mesh over the course of a frame, and then flushed
+// to a canvas all at once using Draw:
+//
+//  dd := debugdraw.New()
+//  ...
+//  dd.AddAABB(body.Bounds(), gfx.Color{1, 0, 0, 1})
+//  dd.AddAxes(obj.Transform.Mat4(), 1)
+//  dd.Draw(d, d.Bounds(), cam)
+//
+// This is primarily useful for visualizing physics, culling and other data
+// which otherwise has no visual representation.
+//
+// Lines are one pixel wide by default. Setting the drawer's Width draws them
+// wider and antialiased using package lines, which unlike wide OpenGL lines
+// also works in core profiles and OpenGL ES:
+//
+//  dd.Width = 2
+package debugdraw // import "azul3d.org/engine/gfx/debugdraw"
+
+import (
+	"image"
+	"math"
+	"sync"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/glsl"
+	"azul3d.org/engine/gfx/lines"
+	"azul3d.org/engine/lmath"
+)
+
+var (
+	// Get an matrix which will translate our matrix from ZUpRight to YUpRight
+	zUpRightToYUpRight = lmath.CoordSysZUpRight.ConvertMat4(lmath.CoordSysYUpRight)
+
+	// The twelve edges of a box whose eight corners are ordered such that bit
+	// zero, one and two of the index select the X, Y and Z extremes
+	// respectively (i.e. the order returned by lmath.Rect3.Corners).
+	boxEdges = [12][2]int{
+		{0, 1}, {2, 3}, {4, 5}, {6, 7},
+		{0, 2}, {1, 3}, {4, 6}, {5, 7},
+		{0, 4}, {1, 5}, {2, 6}, {3, 7},
+	}
+)
+
+// SphereSegments is the number of line segments used to draw each of the three
+// circles that make up a wireframe sphere.
+const SphereSegments = 24
+
+// Drawer accumulates debug lines and draws them all at once.
+//
+// The Add methods of a drawer may be called from multiple goroutines
+// concurrently, such that e.g. a physics simulation can add lines while the
+// scene is being built.
+type Drawer struct {
+	// The object which is drawn. Unlike most objects, the drawer owns it and
+	// you should not modify it's meshes directly.
+	*gfx.Object
+
+	// Width is the width of the lines in pixels. If greater than one, they
+	// are drawn by a batch of package lines instead of the object above. The
+	// default is zero (i.e. one pixel wide).
+	Width float64
+
+	access sync.Mutex
+	mesh   *gfx.Mesh
+	wide   *lines.Batch
+}
+
+// AddLine adds a single line from a to b with the given color.
+func (d *Drawer) AddLine(a, b lmath.Vec3, c gfx.Color) {
+	d.access.Lock()
+	d.addLine(a, b, c)
+	d.access.Unlock()
+}
+
+// addLine is the lock-free implementation of AddLine.
+func (d *Drawer) addLine(a, b lmath.Vec3, c gfx.Color) {
+	d.mesh.Vertices = append(d.mesh.Vertices, gfx.ConvertVec3(a), gfx.ConvertVec3(b))
+	d.mesh.Colors = append(d.mesh.Colors, c, c)
+}
+
+// addBox adds the twelve edges of a box given it's eight corners, see the
+// boxEdges variable for the order of the corners.
+func (d *Drawer) addBox(corners [8]lmath.Vec3, c gfx.Color) {
+	for _, e := range boxEdges {
+		d.addLine(corners[e[0]], corners[e[1]], c)
+	}
+}
+
+// AddAABB adds a wireframe axis-aligned bounding box with the given color.
+func (d *Drawer) AddAABB(r lmath.Rect3, c gfx.Color) {
+	d.access.Lock()
+	d.addBox(r.Corners(), c)
+	d.access.Unlock()
+}
+
+// AddSphere adds a wireframe sphere, drawn as three circles (one about each
+// axis), with the given color.
+func (d *Drawer) AddSphere(s lmath.Sphere, c gfx.Color) {
+	d.access.Lock()
+	var prev [3]lmath.Vec3
+	for i := 0; i <= SphereSegments; i++ {
+		angle := float64(i) / SphereSegments * 2 * math.Pi
+		sin, cos := math.Sincos(angle)
+		sin *= s.Radius
+		cos *= s.Radius
+		cur := [3]lmath.Vec3{
+			s.Center.Add(lmath.Vec3{cos, sin, 0}),
+			s.Center.Add(lmath.Vec3{cos, 0, sin}),
+			s.Center.Add(lmath.Vec3{0, cos, sin}),
+		}
+		if i > 0 {
+			for n := range cur {
+				d.addLine(prev[n], cur[n], c)
+			}
+		}
+		prev = cur
+	}
+	d.access.Unlock()
+}
+
+// AddAxes adds the X, Y, and Z axes (as red, green, and blue lines,
+// respectively) of the given transformation matrix, each of the given length.
+func (d *Drawer) AddAxes(m lmath.Mat4, size float64) {
+	d.access.Lock()
+	origin := lmath.Vec3Zero.TransformMat4(m)
+	d.addLine(origin, lmath.Vec3{size, 0, 0}.TransformMat4(m), gfx.Color{1, 0, 0, 1})
+	d.addLine(origin, lmath.Vec3{0, size, 0}.TransformMat4(m), gfx.Color{0, 1, 0, 1})
+	d.addLine(origin, lmath.Vec3{0, 0, size}.TransformMat4(m), gfx.Color{0, 0, 1, 1})
+	d.access.Unlock()
+}
+
+// AddFrustum adds the wireframe viewing frustum of the given camera with the
+// given color. The camera's Update method must have been called already, such
+// that it's projection matrix is valid.
+//
+// If the camera's view-projection matrix is not invertible, no lines are
+// added.
+func (d *Drawer) AddFrustum(cam gfx.Camera, c gfx.Color) {
+	view, ok := cam.Transform().Mat4().Inverse()
+	if !ok {
+		return
+	}
+	vp := view.Mul(zUpRightToYUpRight).Mul(cam.Projection().Mat4())
+	inv, ok := vp.Inverse()
+	if !ok {
+		return
+	}
+
+	// Unproject each corner of the normalized device coordinate cube.
+	var corners [8]lmath.Vec3
+	for i := range corners {
+		ndc := lmath.Vec4{-1, -1, -1, 1}
+		if i&1 != 0 {
+			ndc.X = 1
+		}
+		if i&2 != 0 {
+			ndc.Y = 1
+		}
+		if i&4 != 0 {
+			ndc.Z = 1
+		}
+		p := ndc.Transform(inv)
+		if p.W == 0 {
+			return
+		}
+		corners[i] = lmath.Vec3{p.X / p.W, p.Y / p.W, p.Z / p.W}
+	}
+
+	d.access.Lock()
+	d.addBox(corners, c)
+	d.access.Unlock()
+}
+
+// Len returns the number of lines that have been added since the last call to
+// Draw or Reset.
+func (d *Drawer) Len() int {
+	d.access.Lock()
+	n := len(d.mesh.Vertices) / 2
+	d.access.Unlock()
+	return n
+}
+
+// Reset discards all of the lines that have been added since the last call to
+// Draw or Reset.
+func (d *Drawer) Reset() {
+	d.access.Lock()
+	d.reset()
+	d.access.Unlock()
+}
+
+// reset is the lock-free implementation of Reset.
+func (d *Drawer) reset() {
+	d.mesh.Vertices = d.mesh.Vertices[:0]
+	d.mesh.Colors = d.mesh.Colors[:0]
+}
+
+// Draw flushes all of the lines that have been added since the last call to
+// Draw or Reset to the given canvas, using the given camera. Afterwards, the
+// drawer is reset so that lines for the next frame may be added.
+//
+// If no lines have been added, this method is no-op.
+func (d *Drawer) Draw(canvas gfx.Canvas, r image.Rectangle, cam gfx.Camera) {
+	d.access.Lock()
+	defer d.access.Unlock()
+	if len(d.mesh.Vertices) == 0 {
+		return
+	}
+
+	// Mark the mesh data as changed, and invalidate the cached bounds as the
+	// vertices have (most likely) changed since the last frame.
+	d.mesh.VerticesChanged = true
+	d.mesh.ColorsChanged = true
+	d.mesh.AABB = lmath.Rect3{}
+	d.Object.CachedBounds = nil
+
+	if d.Width > 1 {
+		d.drawWide(canvas, r, cam)
+	} else {
+		canvas.Draw(r, d.Object, cam)
+	}
+	d.reset()
+}
+
+// drawWide draws the lines using the wide line batch, creating it if needed.
+func (d *Drawer) drawWide(canvas gfx.Canvas, r image.Rectangle, cam gfx.Camera) {
+	if d.wide == nil {
+		// The drawer's own shader is GLSL 1.20 as well.
+		d.wide = lines.New(glsl.GL2)
+		d.wide.State.DepthTest = d.Object.State.DepthTest
+	}
+	style := lines.Style{Width: d.Width, Cap: lines.RoundCap}
+	v := d.mesh.Vertices
+	for i := 0; i+1 < len(v); i += 2 {
+		d.wide.AddLine(v[i].Vec3(), v[i+1].Vec3(), d.mesh.Colors[i], style)
+	}
+	d.wide.Transform = d.Object.Transform
+	d.wide.Draw(canvas, r, cam)
+}
+
+// Destroy destroys this drawer and the object it owns. You must not use it
+// after calling this method.
+func (d *Drawer) Destroy() {
+	d.access.Lock()
+	d.Object.Destroy()
+	if d.wide != nil {
+		d.wide.Destroy()
+	}
+	d.access.Unlock()
+}
+
+// New returns a new debug drawer, with no lines added.
+func New() *Drawer {
+	m := gfx.NewMesh()
+	m.Primitive = gfx.Lines
+	m.Dynamic = true
+	m.KeepDataOnLoad = true
+
+	o := gfx.NewObject()
+	o.State = gfx.NewState()
+	o.State.DepthWrite = false
+	o.Shader = shader
+	o.Meshes = []*gfx.Mesh{m}
+
+	return &Drawer{
+		Object: o,
+		mesh:   m,
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugdraw
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/camera"
+	"azul3d.org/engine/lmath"
+)
+
+func TestDrawerLen(t *testing.T) {
+	white := gfx.Color{1, 1, 1, 1}
+	cam := camera.New(image.Rect(0, 0, 640, 480))
+
+	dd := New()
+	dd.AddLine(lmath.Vec3Zero, lmath.Vec3One, white)
+	dd.AddAABB(lmath.Rect3{Min: lmath.Vec3Zero, Max: lmath.Vec3One}, white)
+	dd.AddSphere(lmath.Sphere{Radius: 1}, white)
+	dd.AddAxes(lmath.Mat4Identity, 1)
+	dd.AddFrustum(cam, white)
+
+	want := 1 + 12 + 3*SphereSegments + 3 + 12
+	if got := dd.Len(); got != want {
+		t.Fatalf("got %d lines, want %d", got, want)
+	}
+	if len(dd.mesh.Colors) != len(dd.mesh.Vertices) {
+		t.Fatalf("got %d colors for %d vertices", len(dd.mesh.Colors), len(dd.mesh.Vertices))
+	}
+
+	d := gfx.Nil()
+	dd.Draw(d, d.Bounds(), cam)
+	if got := dd.Len(); got != 0 {
+		t.Fatalf("got %d lines after Draw, want 0", got)
+	}
+}
+
+func TestDrawerAABB(t *testing.T) {
+	r := lmath.Rect3{
+		Min: lmath.Vec3{-1, -2, -3},
+		Max: lmath.Vec3{1, 2, 3},
+	}
+	dd := New()
+	dd.AddAABB(r, gfx.Color{1, 1, 1, 1})
+
+	// Every line must be axis-aligned and lie on the box.
+	for i := 0; i < len(dd.mesh.Vertices); i += 2 {
+		a := dd.mesh.Vertices[i].Vec3()
+		b := dd.mesh.Vertices[i+1].Vec3()
+		if r.Closest(a) != a || r.Closest(b) != b {
+			t.Fatalf("line %v-%v is not on %v", a, b, r)
+		}
+		diff := 0
+		if a.X != b.X {
+			diff++
+		}
+		if a.Y != b.Y {
+			diff++
+		}
+		if a.Z != b.Z {
+			diff++
+		}
+		if diff != 1 {
+			t.Fatalf("line %v-%v is not axis-aligned", a, b)
+		}
+	}
+}
+
+func TestDrawerFrustum(t *testing.T) {
+	cam := camera.New(image.Rect(0, 0, 640, 480))
+	dd := New()
+	dd.AddFrustum(cam, gfx.Color{1, 1, 1, 1})
+
+	// Every corner of the frustum must project back onto the edge of the
+	// normalized device space.
+	for _, v := range dd.mesh.Vertices {
+		p, _ := cam.Project(v.Vec3())
+		if !lmath.AlmostEqual(math.Abs(p.X), 1, 1e-6) || !lmath.AlmostEqual(math.Abs(p.Y), 1, 1e-6) {
+			t.Fatalf("frustum corner %v projects to %v", v, p)
+		}
+	}
+}
+
+func TestDrawerWidth(t *testing.T) {
+	cam := camera.New(image.Rect(0, 0, 640, 480))
+	dd := New()
+	dd.Width = 3
+	dd.AddAxes(lmath.Mat4Identity, 1)
+
+	d := gfx.Nil()
+	dd.Draw(d, d.Bounds(), cam)
+	if dd.wide == nil || dd.Len() != 0 {
+		t.Fatal("wide lines not drawn")
+	}
+	if got := dd.wide.Shader.Inputs["Viewport"]; got != (gfx.TexCoord{U: 640, V: 480}) {
+		t.Fatalf("viewport %v", got)
+	}
+}
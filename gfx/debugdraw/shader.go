@@ -0,0 +1,42 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugdraw
+
+import "azul3d.org/engine/gfx"
+
+var vertShader = []byte(`
+#version 120
+
+attribute vec3 Vertex;
+attribute vec4 Color;
+uniform mat4 MVP;
+
+varying vec4 color;
+
+void main(void) {
+	gl_Position = MVP * vec4(Vertex, 1.0);
+	color = Color;
+}
+`)
+
+var fragShader = []byte(`
+#version 120
+
+varying vec4 color;
+
+void main(void) {
+	gl_FragColor = color;
+}
+`)
+
+var shader *gfx.Shader
+
+func init() {
+	shader = gfx.NewShader("debugdraw")
+	shader.GLSL = &gfx.GLSLSources{
+		Vertex:   vertShader,
+		Fragment: fragShader,
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package decal projects runtime decals (e.g. bullet holes, blood splatters
+// or tire tracks) onto scene geometry.
+//
+// A decal is projected by clipping the triangles of the geometry it covers
+// against it's box (see Project), which produces a mesh that is drawn on top
+// of the geometry. A Manager keeps the number of decals within a budget and
+// fades them out once they are too old, or when newer decals replace them:
+//
+//  m := decal.NewManager(64)
+//  m.Shader = shaders.New(shaders.Decal, glsl.GL2)
+//
+//  // On each bullet hit.
+//  box := gfx.NewTransform()
+//  box.SetPos(hit.Sub(dir.MulScalar(0.1)))
+//  box.SetQuat(lmath.QuatFromAxisAngle(...)) // Forward (+Y) along dir.
+//  box.SetScale(lmath.Vec3{0.2, 0.2, 0.2})
+//  vertices, indices := decal.Geometry(wall)
+//  if mesh := decal.Project(box, vertices, indices, 0.001); mesh != nil {
+//      m.Add(decal.New(mesh, bulletHole), 30, 2)
+//  }
+//
+//  // Each frame.
+//  m.Update(dt)
+//  m.Draw(canvas, image.Rect(0, 0, 0, 0), cam)
+//
+// Decals fade out through the alpha of their vertex colors, which the
+// shaders.Decal shader multiplies their texture with.
+package decal // import "azul3d.org/engine/gfx/decal"
+
+import (
+	"image"
+	"math"
+
+	"azul3d.org/engine/gfx"
+)
+
+// Decal is a single decal.
+type Decal struct {
+	// The object drawn for the decal, with a single mesh. It is drawn with
+	// the shader and state of the manager, and with it's own textures.
+	Object *gfx.Object
+
+	// Lifetime is the age (in seconds) at which the decal is removed, or zero
+	// if it lives until it is evicted to stay within the budget.
+	Lifetime float64
+
+	// Fade is the duration (in seconds) over which the decal fades out at the
+	// end of it's life.
+	Fade float64
+
+	// The age of the decal, and the age at which it was evicted (or infinity
+	// if it was not).
+	age, evicted float64
+
+	// The last alpha the vertex colors were set to.
+	alpha float32
+}
+
+// Age returns the age of the decal, in seconds.
+func (d *Decal) Age() float64 {
+	return d.age
+}
+
+// end returns the age at which the decal is removed, which may be infinite.
+func (d *Decal) end() float64 {
+	end := math.Inf(1)
+	if d.Lifetime > 0 {
+		end = d.Lifetime
+	}
+	return math.Min(end, d.evicted+d.Fade)
+}
+
+// setAlpha sets the alpha of the decal's vertex colors.
+func (d *Decal) setAlpha(a float32) {
+	if a == d.alpha {
+		return
+	}
+	d.alpha = a
+	m := d.Object.Meshes[0]
+	for i := range m.Colors {
+		m.Colors[i].A = a
+	}
+	m.ColorsChanged = true
+}
+
+// New returns a new decal of the given mesh (see Project) and textures,
+// which keeps it's data after loading such that it may be faded out.
+func New(m *gfx.Mesh, textures ...*gfx.Texture) *Decal {
+	m.KeepDataOnLoad = true
+	o := gfx.NewObject()
+	o.Meshes = []*gfx.Mesh{m}
+	o.Textures = append(o.Textures, textures...)
+	return &Decal{Object: o, alpha: 1}
+}
+
+// Manager manages the decals of a scene.
+//
+// A manager is not safe for access from multiple goroutines concurrently.
+type Manager struct {
+	// Budget is the maximum number of decals which are not fading out after
+	// being evicted. When a decal is added beyond it, the oldest one is
+	// evicted: it fades out (over it's Fade duration) and is then removed.
+	Budget int
+
+	// The shader and state that the decals are drawn with, e.g.
+	// shaders.Decal. By default, the state blends the decals into the scene
+	// without writing depth.
+	Shader *gfx.Shader
+	State  *gfx.State
+
+	// The decals, oldest first.
+	decals []*Decal
+}
+
+// Len returns the number of decals of the manager, including those which are
+// fading out.
+func (m *Manager) Len() int {
+	return len(m.decals)
+}
+
+// Add adds the given decal, with the given lifetime and fade duration (see
+// the Lifetime and Fade fields of Decal). If the budget is exceeded, the
+// oldest decal which is not yet evicted is evicted.
+func (m *Manager) Add(d *Decal, lifetime, fade float64) {
+	d.Lifetime = lifetime
+	d.Fade = fade
+	d.evicted = math.Inf(1)
+	m.decals = append(m.decals, d)
+
+	live := 0
+	for _, d := range m.decals {
+		if math.IsInf(d.evicted, 1) {
+			live++
+		}
+	}
+	for _, d := range m.decals {
+		if live <= m.Budget {
+			break
+		}
+		if math.IsInf(d.evicted, 1) {
+			d.evicted = d.age
+			live--
+		}
+	}
+}
+
+// Update ages each decal by the given time (in seconds), fading out and
+// removing those at the end of their life.
+func (m *Manager) Update(dt float64) {
+	kept := m.decals[:0]
+	for _, d := range m.decals {
+		d.age += dt
+		end := d.end()
+		if d.age >= end {
+			d.destroy()
+			continue
+		}
+		alpha := 1.0
+		if left := end - d.age; left < d.Fade {
+			alpha = left / d.Fade
+		}
+		d.setAlpha(float32(alpha))
+		kept = append(kept, d)
+	}
+	for i := len(kept); i < len(m.decals); i++ {
+		m.decals[i] = nil
+	}
+	m.decals = kept
+}
+
+// Draw draws each decal to the canvas, using the given camera.
+func (m *Manager) Draw(canvas gfx.Canvas, r image.Rectangle, cam gfx.Camera) {
+	for _, d := range m.decals {
+		d.Object.Shader = m.Shader
+		d.Object.State = m.State
+		canvas.Draw(r, d.Object, cam)
+	}
+}
+
+// Clear removes all of the decals at once.
+func (m *Manager) Clear() {
+	for i, d := range m.decals {
+		d.destroy()
+		m.decals[i] = nil
+	}
+	m.decals = m.decals[:0]
+}
+
+// destroy destroys the decal's object and mesh, leaving it's textures as-is.
+func (d *Decal) destroy() {
+	m := d.Object.Meshes[0]
+	d.Object.Destroy()
+	m.Destroy()
+}
+
+// NewManager returns a new manager with the given budget.
+func NewManager(budget int) *Manager {
+	s := gfx.NewState()
+	s.AlphaMode = gfx.AlphaBlend
+	s.DepthWrite = false
+	s.DepthCmp = gfx.LessOrEqual
+	return &Manager{
+		Budget: budget,
+		State:  s,
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package decal
+
+import (
+	"math"
+	"testing"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/lmath"
+)
+
+// wall returns a 4x4 quad on the X/Z plane at Y=0.2, facing -Y.
+func wall() ([]lmath.Vec3, []uint32) {
+	return []lmath.Vec3{
+		{X: -2, Y: 0.2, Z: -2},
+		{X: 2, Y: 0.2, Z: -2},
+		{X: 2, Y: 0.2, Z: 2},
+		{X: -2, Y: 0.2, Z: 2},
+	}, []uint32{0, 1, 2, 0, 2, 3}
+}
+
+func TestProject(t *testing.T) {
+	vertices, indices := wall()
+	box := gfx.NewTransform()
+	m := Project(box, vertices, indices, 0.01)
+	if m == nil {
+		t.Fatal("decal missed the wall")
+	}
+
+	// The wall is clipped to the unit square, whose area is one.
+	var area float64
+	for i := 0; i < len(m.Indices); i += 3 {
+		a := m.Vertices[m.Indices[i]].Vec3()
+		b := m.Vertices[m.Indices[i+1]].Vec3()
+		c := m.Vertices[m.Indices[i+2]].Vec3()
+		area += b.Sub(a).Cross(c.Sub(a)).Length() / 2
+	}
+	if math.Abs(area-1) > 1e-6 {
+		t.Fatalf("got area %v, want 1", area)
+	}
+	for i, v := range m.Vertices {
+		tc := m.TexCoords[0].Slice[i]
+		if math.Abs(float64(v.Y)-0.19) > 1e-6 || tc.U < 0 || tc.U > 1 || tc.V < 0 || tc.V > 1 {
+			t.Fatalf("vertex %v with texture coordinate %v", v, tc)
+		}
+		if want := 0.5 - float64(v.Z); math.Abs(float64(tc.V)-want) > 1e-6 {
+			t.Fatalf("vertex %v: got V=%v, want %v", v, tc.V, want)
+		}
+	}
+
+	// Facing away from the decal.
+	if Project(box, vertices, []uint32{0, 2, 1, 0, 3, 2}, 0.01) != nil {
+		t.Fatal("decal projected onto the back of the wall")
+	}
+
+	// Out of reach of the decal.
+	box.SetPos(lmath.Vec3{Y: -1})
+	if Project(box, vertices, indices, 0.01) != nil {
+		t.Fatal("decal projected onto a distant wall")
+	}
+}
+
+func TestManager(t *testing.T) {
+	vertices, indices := wall()
+	decal := func() *Decal {
+		return New(Project(gfx.NewTransform(), vertices, indices, 0))
+	}
+	alpha := func(d *Decal) float32 {
+		return d.Object.Meshes[0].Colors[0].A
+	}
+
+	m := NewManager(2)
+	first := decal()
+	m.Add(first, 0, 1)
+	m.Add(decal(), 0, 1)
+	short := decal()
+	m.Add(short, 2, 1)
+	if m.Len() != 3 {
+		t.Fatalf("got %d decals", m.Len())
+	}
+
+	// The first decal was evicted, and fades out.
+	m.Update(0.5)
+	if alpha(first) != 0.5 || alpha(short) != 1 {
+		t.Fatalf("got alpha %v and %v", alpha(first), alpha(short))
+	}
+	m.Update(1)
+	if m.Len() != 2 || alpha(short) != 0.5 {
+		t.Fatalf("got %d decals, alpha %v", m.Len(), alpha(short))
+	}
+
+	// The short-lived decal reaches the end of it's life.
+	m.Update(1)
+	if m.Len() != 1 {
+		t.Fatalf("got %d decals", m.Len())
+	}
+	m.Clear()
+	if m.Len() != 0 {
+		t.Fatalf("got %d decals after Clear", m.Len())
+	}
+}
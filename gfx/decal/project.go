@@ -0,0 +1,136 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package decal
+
+import (
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/lmath"
+)
+
+// axis returns the component of v along the given axis (0, 1, or 2).
+func axis(v lmath.Vec3, a int) float64 {
+	switch a {
+	case 0:
+		return v.X
+	case 1:
+		return v.Y
+	}
+	return v.Z
+}
+
+// clip clips the convex polygon against the plane where the component along
+// the given axis, multiplied by sign, is at most one half (i.e. one face of
+// the decal's box). The result is appended to dst.
+func clip(dst, poly []lmath.Vec3, a int, sign float64) []lmath.Vec3 {
+	dist := func(v lmath.Vec3) float64 {
+		return sign*axis(v, a) - 0.5
+	}
+	for i, cur := range poly {
+		prev := poly[(i+len(poly)-1)%len(poly)]
+		dc, dp := dist(cur), dist(prev)
+		if (dc <= 0) != (dp <= 0) {
+			// The edge crosses the plane.
+			t := dp / (dp - dc)
+			dst = append(dst, prev.Add(cur.Sub(prev).MulScalar(t)))
+		}
+		if dc <= 0 {
+			dst = append(dst, cur)
+		}
+	}
+	return dst
+}
+
+// Project returns a mesh of the parts of the given world space triangles
+// (e.g. from Geometry, or terrain.Geometry) which lie within the decal's box,
+// textured such that the decal's texture covers the box. If no triangle lies
+// within the box, nil is returned.
+//
+// The box is the unit cube centered at the origin, transformed by the given
+// transform: the decal is projected along it's forward (+Y) axis, with the
+// top of the texture toward it's up (+Z) axis, and it's scale sets the size
+// of the box. Triangles which face away from the decal are left out.
+//
+// The vertices of the mesh are pushed away from the surface along it's
+// normal by the given offset (in world units), to avoid z-fighting, and it's
+// vertex colors are white, see Manager.
+func Project(t *gfx.Transform, vertices []lmath.Vec3, indices []uint32, offset float64) *gfx.Mesh {
+	world := t.Mat4()
+	local, ok := world.Inverse()
+	if !ok {
+		return nil
+	}
+	forward, _ := lmath.Vec3{Y: 1}.TransformVecMat4(world).Normalized()
+
+	var (
+		m         *gfx.Mesh
+		poly, tmp []lmath.Vec3
+	)
+	for i := 0; i+2 < len(indices); i += 3 {
+		a, b, c := vertices[indices[i]], vertices[indices[i+1]], vertices[indices[i+2]]
+		n, ok := b.Sub(a).Cross(c.Sub(a)).Normalized()
+		if !ok || n.Dot(forward) >= 0 {
+			continue
+		}
+
+		// Clip the triangle against each face of the box, in local space.
+		poly = append(poly[:0], a.TransformMat4(local), b.TransformMat4(local), c.TransformMat4(local))
+		for ax := 0; ax < 3 && len(poly) > 0; ax++ {
+			for _, sign := range []float64{-1, 1} {
+				tmp = clip(tmp[:0], poly, ax, sign)
+				poly, tmp = tmp, poly
+			}
+		}
+		if len(poly) < 3 {
+			continue
+		}
+
+		// Emit the polygon as a fan of triangles.
+		if m == nil {
+			m = gfx.NewMesh()
+			m.TexCoords = make([]gfx.TexCoordSet, 1)
+		}
+		base := uint32(len(m.Vertices))
+		for _, p := range poly {
+			w := p.TransformMat4(world).Add(n.MulScalar(offset))
+			m.Vertices = append(m.Vertices, gfx.ConvertVec3(w))
+			m.Colors = append(m.Colors, gfx.Color{R: 1, G: 1, B: 1, A: 1})
+			m.TexCoords[0].Slice = append(m.TexCoords[0].Slice, gfx.TexCoord{
+				U: float32(p.X + 0.5),
+				V: float32(0.5 - p.Z),
+			})
+		}
+		for j := 2; j < len(poly); j++ {
+			m.Indices = append(m.Indices, base, base+uint32(j-1), base+uint32(j))
+		}
+	}
+	return m
+}
+
+// Geometry returns the world space triangles of the given object's meshes,
+// for projecting decals onto (see Project). Meshes whose primitive is not
+// Triangles are left out, as are meshes whose data was not kept after
+// loading (see Mesh.KeepDataOnLoad).
+func Geometry(o *gfx.Object) (vertices []lmath.Vec3, indices []uint32) {
+	mat := o.Transform.Mat4()
+	for _, m := range o.Meshes {
+		if m.Primitive != gfx.Triangles {
+			continue
+		}
+		base := uint32(len(vertices))
+		for _, v := range m.Vertices {
+			vertices = append(vertices, v.Vec3().TransformMat4(mat))
+		}
+		if m.Indices != nil {
+			for _, i := range m.Indices {
+				indices = append(indices, base+i)
+			}
+			continue
+		}
+		for i := range m.Vertices {
+			indices = append(indices, base+uint32(i))
+		}
+	}
+	return
+}
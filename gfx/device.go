@@ -122,14 +122,18 @@ type Canvas interface {
 	// of loaded meshes, such that the object has a chance to determine it's
 	// bounding box.
 	//
-	// The object will not be drawn if any of the following cases are true:
+	// If the object has a pipeline which is not loaded, and the device is a
+	// PipelineLoader, the pipeline is loaded first.
 	//
-	//  o.State == nil
-	//  o.Shader == nil
-	//  len(o.Shader.Error) > 0
+	// The object will not be drawn if any of the following cases are true
+	// (where shader, state := o.DrawState()):
+	//
+	//  state == nil
+	//  shader == nil
+	//  len(shader.Error) > 0
 	//  len(o.Meshes) == 0
 	//  !o.Meshes[N].Loaded && len(o.Meshes[N].Vertices) == 0
-	//  !o.Textures[n].Loaded && o.Textures[N].Source == nil
+	//  !o.Textures[n].Loaded && o.Textures[N].Source == nil && o.Textures[N].Mipmaps == nil
 	//
 	// If the rectangle is empty this function is no-op.
 	Draw(r image.Rectangle, o *Object, c Camera)
@@ -199,6 +203,36 @@ type DeviceInfo struct {
 	// samples passed, but not how many specifically).
 	OcclusionQueryBits int
 
+	// Whether or not debug labels (see e.g. Object.Label) and debug groups
+	// (see DebugGrouper) are supported. If false, they are simply ignored.
+	DebugLabels bool
+
+	// Whether or not timer queries are supported. If false, then the GPU
+	// times reported by a Profiler will always be zero.
+	TimerQuery bool
+
+	// Whether or not the data of dynamic meshes (see Mesh.Dynamic) is
+	// uploaded through persistently mapped buffers, such that updating it
+	// each frame does not stall waiting for the GPU to finish drawing with
+	// the previous data.
+	PersistentMapping bool
+
+	// Whether or not the draw commands of objects (see Object.Indirect) are
+	// drawn with a single multi-draw-indirect call. If false, each command
+	// that is not culled is drawn with a draw call of it's own.
+	DrawIndirect bool
+
+	// Whether or not the draw commands of objects are culled against the
+	// view frustum by a compute shader, on the GPU. If false, they are culled
+	// on the CPU at each draw (see Indirect.Cull).
+	ComputeCulling bool
+
+	// Whether or not instanced drawing is supported, i.e. whether the draw
+	// commands of objects may draw more than one instance (see
+	// DrawCommand.InstanceCount). If false, each command draws a single
+	// instance.
+	Instancing bool
+
 	// The name of the graphics hardware, or an empty string if not available.
 	// For example it may look something like:
 	//
@@ -236,6 +270,56 @@ type DeviceInfo struct {
 	//
 	// (Desktop) OpenGL 2 always supports BorderColor.
 	TexWrapBorderColor bool
+
+	// Whether or not the sRGB texture formats (SRGB and SRGBA) are supported.
+	// If false, textures using them are loaded as linear RGB/RGBA textures
+	// instead (i.e. no conversion occurs when sampling them).
+	SRGBTextures bool
+
+	// Whether or not sRGB framebuffers are supported, see SRGBCanvas.
+	SRGBFramebuffer bool
+
+	// The maximum degree of anisotropic filtering supported (see
+	// Texture.MaxAnisotropy), or one if anisotropic filtering is not
+	// supported.
+	MaxAnisotropy float32
+
+	// The maximum absolute texture level of detail bias supported (see
+	// Texture.LODBias), or zero if it is not supported.
+	MaxLODBias float32
+
+	// MaxTextures is the maximum number of textures that a single object may
+	// use (i.e. the maximum length of Object.Textures), or -1 if not
+	// available.
+	MaxTextures int
+
+	// MaxVertexAttribs is the maximum number of vertex attributes that a
+	// shader may use (including the default ones such as Vertex and Color),
+	// or -1 if not available.
+	MaxVertexAttribs int
+
+	// MaxSamples is the maximum number of samples per pixel supported for
+	// multisample anti-aliasing (see Canvas.SetMSAA and Precision.Samples),
+	// or zero if MSAA is not supported.
+	MaxSamples int
+
+	// The compressed texture formats (e.g. DXT1) supported by the device.
+	// Textures using other compressed formats are stored uncompressed.
+	CompressedFormats []TexFormat
+}
+
+// Compressed tells if the given compressed texture format is supported by
+// the device, i.e. if it is in i.CompressedFormats.
+//
+// Applications can use this along with e.g. MaxTextureSize to select the
+// quality tier of assets to load.
+func (i DeviceInfo) Compressed(f TexFormat) bool {
+	for _, c := range i.CompressedFormats {
+		if c == f {
+			return true
+		}
+	}
+	return false
 }
 
 // Device represents a graphics device and is capable of loading meshes,
@@ -280,6 +364,10 @@ type Device interface {
 	// if != nil, and as long as sending would not block (i.e. ensure a buffer
 	// size of at least one).
 	//
+	// If t.Streaming is true, the texture is uploaded in the background and
+	// only marked as loaded during a later call to Render (see the Streaming
+	// field of Texture).
+	//
 	// Upon calling this method, ownership of the mesh is transferred to the
 	// device itself and you may no longer access it safely until the device
 	// passes ownership back to you over the done channel.
@@ -0,0 +1,17 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import "testing"
+
+func TestDeviceInfoCompressed(t *testing.T) {
+	info := DeviceInfo{CompressedFormats: []TexFormat{DXT1, DXT5}}
+	if !info.Compressed(DXT5) {
+		t.Fatal("expected DXT5 to be supported")
+	}
+	if info.Compressed(DXT3) {
+		t.Fatal("expected DXT3 to be unsupported")
+	}
+}
@@ -14,6 +14,15 @@
 // Texture coordinates do not follow OpenGL convention but rather Go convention
 // where the origin (0, 0) is the top-left corner of the texture.
 //
+// Color Spaces
+//
+// Colors (e.g. Color values, vertex colors, and shader inputs) are not
+// converted between color spaces by devices. For physically correct lighting,
+// shaders should operate in linear space: textures with sRGB encoded images
+// (i.e. most images) should use the SRGB or SRGBA formats, such that they are
+// converted to linear space when sampled, and the canvas should convert the
+// shader's linear output back to sRGB (see SRGBCanvas).
+//
 // Examples
 //
 // The examples repository contains several examples which utilize the gfx core
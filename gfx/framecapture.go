@@ -0,0 +1,158 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// FrameCapturer is an optional interface that a Device may implement in order
+// to record the native graphics API calls (e.g. OpenGL calls) it makes while
+// rendering a frame, such that differences between devices can be debugged
+// without external tools:
+//
+//  if fc, ok := d.(gfx.FrameCapturer); ok {
+//      captured := fc.CaptureFrame()
+//      go func() {
+//          c := <-captured
+//          c.WriteTo(os.Stdout)
+//      }()
+//  }
+//
+// Like the Device interface, it's methods are safe to call from multiple
+// goroutines concurrently.
+type FrameCapturer interface {
+	// CaptureFrame requests that the calls made during the next frame (i.e.
+	// those made after the current frame has been rendered, up to and
+	// including the rendering of the next one) are recorded. The returned
+	// channel receives the capture once that frame has been rendered.
+	CaptureFrame() <-chan *FrameCapture
+}
+
+// CapturedCall is a single native graphics API call of a frame capture.
+type CapturedCall struct {
+	// The name of the function called, e.g. "glBindTexture".
+	Name string
+
+	// The arguments of the call. Enums implement fmt.Stringer, e.g. as
+	// "GL_TEXTURE_2D".
+	Args []interface{}
+
+	// The result of the call, or nil if it has none.
+	Result interface{}
+
+	// The depth of nested debug groups (see DebugGrouper) the call was made
+	// in.
+	Depth int
+}
+
+// String returns the call formatted like C source, for example:
+//
+//  glBindTexture(GL_TEXTURE_2D, 3)
+//  glCreateShader(GL_VERTEX_SHADER) = 7
+//
+func (c CapturedCall) String() string {
+	args := make([]string, len(c.Args))
+	for i, a := range c.Args {
+		if s, ok := a.(string); ok {
+			args[i] = fmt.Sprintf("%q", s)
+			continue
+		}
+		args[i] = fmt.Sprint(a)
+	}
+	s := fmt.Sprintf("%s(%s)", c.Name, strings.Join(args, ", "))
+	if c.Result != nil {
+		s += fmt.Sprintf(" = %v", c.Result)
+	}
+	return s
+}
+
+// FrameCapture is the calls recorded while rendering a single frame, see
+// FrameCapturer.
+type FrameCapture struct {
+	// The device the frame was rendered with, e.g. it's OpenGL version string.
+	Device string
+
+	// The calls made during the frame, in order.
+	Calls []CapturedCall
+}
+
+// WriteTo writes a readable dump of the capture to w, one call per line
+// indented by it's debug group depth. It implements the io.WriterTo
+// interface.
+func (f *FrameCapture) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	var n int64
+	write := func(format string, args ...interface{}) {
+		c, _ := fmt.Fprintf(bw, format, args...)
+		n += int64(c)
+	}
+	write("// %s, %d calls\n", f.Device, len(f.Calls))
+	for i, c := range f.Calls {
+		write("%5d %s%s\n", i, strings.Repeat("  ", c.Depth), c)
+	}
+	return n, bw.Flush()
+}
+
+// WriteTrace writes the capture to w as a machine readable trace, for
+// comparison or processing by other tools: a JSON object per line, the first
+// of which describes the device, followed by each call:
+//
+//  {"device":"OpenGL v2.1 - Mesa 10.5.0","calls":2}
+//  {"name":"glBindTexture","args":["GL_TEXTURE_2D",3]}
+//  {"name":"glCreateShader","args":["GL_VERTEX_SHADER"],"result":7}
+//
+// Enums are written as their names, and values which cannot be represented
+// in JSON (such as pointers) are written as formatted by fmt.Sprint.
+func (f *FrameCapture) WriteTrace(w io.Writer) error {
+	type header struct {
+		Device string `json:"device"`
+		Calls  int    `json:"calls"`
+	}
+	type call struct {
+		Name   string        `json:"name"`
+		Args   []interface{} `json:"args"`
+		Result interface{}   `json:"result,omitempty"`
+		Depth  int           `json:"depth,omitempty"`
+	}
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	if err := enc.Encode(header{Device: f.Device, Calls: len(f.Calls)}); err != nil {
+		return err
+	}
+	for _, c := range f.Calls {
+		args := make([]interface{}, len(c.Args))
+		for i, a := range c.Args {
+			args[i] = traceValue(a)
+		}
+		err := enc.Encode(call{
+			Name:   c.Name,
+			Args:   args,
+			Result: traceValue(c.Result),
+			Depth:  c.Depth,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// traceValue returns v in a form which can be encoded as JSON.
+func traceValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case nil, bool, string, int, int32, uint32, int64, uint64, float32, []uint32:
+		return x
+	case float64:
+		return x
+	case fmt.Stringer:
+		return x.String()
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,51 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"bytes"
+	"testing"
+)
+
+type testEnum uint32
+
+func (e testEnum) String() string { return "GL_TEXTURE_2D" }
+
+func TestFrameCapture(t *testing.T) {
+	c := &FrameCapture{
+		Device: "test",
+		Calls: []CapturedCall{
+			{Name: "glPushDebugGroup", Args: []interface{}{"shadows"}},
+			{Name: "glBindTexture", Args: []interface{}{testEnum(0x0DE1), uint32(3)}, Depth: 1},
+			{Name: "glCreateShader", Result: uint32(7)},
+		},
+	}
+
+	var buf bytes.Buffer
+	if _, err := c.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `// test, 3 calls
+    0 glPushDebugGroup("shadows")
+    1   glBindTexture(GL_TEXTURE_2D, 3)
+    2 glCreateShader() = 7
+`
+	if buf.String() != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := c.WriteTrace(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want = `{"device":"test","calls":3}
+{"name":"glPushDebugGroup","args":["shadows"]}
+{"name":"glBindTexture","args":["GL_TEXTURE_2D",3],"depth":1}
+{"name":"glCreateShader","args":[],"result":7}
+`
+	if buf.String() != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfxutil
+
+import (
+	"image"
+	"sort"
+
+	"azul3d.org/engine/gfx"
+)
+
+// ClipStack is a stack of nested clipping rectangles, as used when drawing
+// e.g. user interface widgets which must be clipped to the bounds of their
+// parent widgets.
+//
+// The zero value is an empty stack, whose Top is the empty rectangle.
+type ClipStack struct {
+	rects []image.Rectangle
+}
+
+// Push pushes the given rectangle onto the stack. The new clipping rectangle
+// (which is returned) is the intersection of the given rectangle and the
+// previous one, i.e. children can never draw outside of their parent.
+//
+// If the stack is empty, the rectangle is pushed as-is.
+func (s *ClipStack) Push(r image.Rectangle) image.Rectangle {
+	if len(s.rects) > 0 {
+		r = r.Intersect(s.rects[len(s.rects)-1])
+	}
+	s.rects = append(s.rects, r)
+	return r
+}
+
+// Pop pops the last rectangle pushed onto the stack. It panics if the stack is
+// empty.
+func (s *ClipStack) Pop() {
+	if len(s.rects) == 0 {
+		panic("gfxutil: Pop called on empty ClipStack")
+	}
+	s.rects = s.rects[:len(s.rects)-1]
+}
+
+// Top returns the current clipping rectangle, or the empty rectangle if the
+// stack is empty.
+func (s *ClipStack) Top() image.Rectangle {
+	if len(s.rects) == 0 {
+		return image.ZR
+	}
+	return s.rects[len(s.rects)-1]
+}
+
+// Len returns the number of rectangles on the stack.
+func (s *ClipStack) Len() int {
+	return len(s.rects)
+}
+
+// Reset empties the stack.
+func (s *ClipStack) Reset() {
+	s.rects = s.rects[:0]
+}
+
+// compositeOp is a single draw operation queued by a Compositor.
+type compositeOp struct {
+	layer int
+	clip  image.Rectangle
+	o     *gfx.Object
+	c     gfx.Camera
+}
+
+// byLayer sorts composite operations by layer.
+type byLayer []compositeOp
+
+func (b byLayer) Len() int           { return len(b) }
+func (b byLayer) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byLayer) Less(i, j int) bool { return b[i].layer < b[j].layer }
+
+// Compositor is a layered 2D compositor, used for drawing e.g. user interfaces
+// and sprites.
+//
+// Objects are queued using Draw along with the current clipping rectangle (see
+// the embedded ClipStack) and Layer. Flush then draws the queued objects to a
+// canvas, in order of ascending layer (and, within a layer, in the order they
+// were queued), each clipped to the clipping rectangle it was queued with:
+//
+//  comp := gfxutil.NewCompositor(d.Bounds())
+//  comp.Push(panel.Bounds)
+//  comp.Draw(panel.Object, cam)
+//  comp.Layer = 1
+//  comp.Draw(tooltip.Object, cam) // Drawn after everything on layer zero.
+//  comp.Pop()
+//  comp.Flush(d)
+//
+// A compositor and it's methods are not safe for access from multiple
+// goroutines concurrently.
+type Compositor struct {
+	ClipStack
+
+	// The layer that objects passed to Draw are drawn on, higher layers are
+	// drawn on top of lower ones.
+	Layer int
+
+	bounds image.Rectangle
+	ops    []compositeOp
+}
+
+// Draw queues the given object to be drawn using the given camera, on the
+// current layer and clipped to the current clipping rectangle. Objects that
+// would be entirely clipped away are not queued.
+func (c *Compositor) Draw(o *gfx.Object, cam gfx.Camera) {
+	clip := c.Top()
+	if clip.Empty() {
+		return
+	}
+	c.ops = append(c.ops, compositeOp{
+		layer: c.Layer,
+		clip:  clip,
+		o:     o,
+		c:     cam,
+	})
+}
+
+// Flush draws all of the queued objects to the given canvas and then resets
+// the compositor, such that only the canvas bounds remain on the clip stack
+// and the layer is zero.
+func (c *Compositor) Flush(canvas gfx.Canvas) {
+	sort.Stable(byLayer(c.ops))
+	for i, op := range c.ops {
+		canvas.Draw(op.clip, op.o, op.c)
+		c.ops[i] = compositeOp{} // Release references.
+	}
+	c.ops = c.ops[:0]
+	c.Reset(c.bounds)
+}
+
+// Reset discards all queued objects, empties the clip stack and pushes the
+// given canvas bounds onto it, and sets the layer to zero.
+func (c *Compositor) Reset(bounds image.Rectangle) {
+	for i := range c.ops {
+		c.ops[i] = compositeOp{}
+	}
+	c.ops = c.ops[:0]
+	c.ClipStack.Reset()
+	c.Push(bounds)
+	c.bounds = bounds
+	c.Layer = 0
+}
+
+// NewCompositor returns a new compositor whose clip stack initially contains
+// just the given canvas bounds.
+func NewCompositor(bounds image.Rectangle) *Compositor {
+	c := &Compositor{}
+	c.Reset(bounds)
+	return c
+}
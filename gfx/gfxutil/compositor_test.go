@@ -0,0 +1,68 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfxutil
+
+import (
+	"image"
+	"testing"
+
+	"azul3d.org/engine/gfx"
+)
+
+// drawRecorder is a canvas which records draw operations.
+type drawRecorder struct {
+	gfx.Canvas
+	rects   []image.Rectangle
+	objects []*gfx.Object
+}
+
+func (d *drawRecorder) Draw(r image.Rectangle, o *gfx.Object, c gfx.Camera) {
+	d.rects = append(d.rects, r)
+	d.objects = append(d.objects, o)
+}
+
+func TestClipStack(t *testing.T) {
+	var s ClipStack
+	if !s.Top().Empty() {
+		t.Fatal("expected empty top")
+	}
+	s.Push(image.Rect(0, 0, 100, 100))
+	got := s.Push(image.Rect(50, 50, 150, 150))
+	if want := image.Rect(50, 50, 100, 100); got != want {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	s.Pop()
+	if want := image.Rect(0, 0, 100, 100); s.Top() != want {
+		t.Fatalf("got %v want %v", s.Top(), want)
+	}
+}
+
+func TestCompositor(t *testing.T) {
+	bounds := image.Rect(0, 0, 640, 480)
+	c := NewCompositor(bounds)
+	a, b, d := gfx.NewObject(), gfx.NewObject(), gfx.NewObject()
+
+	c.Layer = 1
+	c.Draw(a, nil)
+	c.Layer = 0
+	c.Push(image.Rect(10, 10, 20, 20))
+	c.Draw(b, nil)
+	c.Push(image.Rect(30, 30, 40, 40))
+	c.Draw(d, nil) // Entirely clipped.
+	c.Pop()
+	c.Pop()
+
+	rec := &drawRecorder{}
+	c.Flush(rec)
+	if len(rec.objects) != 2 || rec.objects[0] != b || rec.objects[1] != a {
+		t.Fatalf("unexpected draw order %v", rec.objects)
+	}
+	if rec.rects[0] != image.Rect(10, 10, 20, 20) || rec.rects[1] != bounds {
+		t.Fatalf("unexpected clip rectangles %v", rec.rects)
+	}
+	if c.Top() != bounds || c.Len() != 1 || c.Layer != 0 {
+		t.Fatal("compositor not reset after flush")
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfxutil
+
+import (
+	"bufio"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"io"
+	"math"
+
+	gfxcolor "azul3d.org/engine/gfx/color"
+)
+
+// EncodeEXR writes the image m to w in the OpenEXR format, as an uncompressed
+// scanline image with 16-bit floating point (half) RGBA channels.
+//
+// The image's color values are assumed to be sRGB encoded, and are converted
+// to linear values (as is convention for OpenEXR images). Images whose color
+// values are 16-bit (e.g. *image.RGBA64, as the gl2 device downloads canvases
+// with more than 8 bits per color component) retain their full precision.
+func EncodeEXR(w io.Writer, m image.Image) error {
+	b := m.Bounds()
+	bw := bufio.NewWriter(w)
+	le := binary.LittleEndian
+
+	var hdr []byte
+	u32 := func(v uint32) {
+		var buf [4]byte
+		le.PutUint32(buf[:], v)
+		hdr = append(hdr, buf[:]...)
+	}
+	attr := func(name, typ string, size int) {
+		hdr = append(hdr, name...)
+		hdr = append(hdr, 0)
+		hdr = append(hdr, typ...)
+		hdr = append(hdr, 0)
+		u32(uint32(size))
+	}
+	box := func() {
+		u32(0)
+		u32(0)
+		u32(uint32(b.Dx() - 1))
+		u32(uint32(b.Dy() - 1))
+	}
+
+	// Magic number and version (2, single-part scanline).
+	u32(20000630)
+	u32(2)
+
+	// Channels, which must be in alphabetical order. Each channel is the name,
+	// pixel type (1 is half), pLinear and three reserved bytes, and x/y
+	// sampling.
+	channels := []string{"A", "B", "G", "R"}
+	attr("channels", "chlist", len(channels)*(2+4+4+4+4)+1)
+	for _, c := range channels {
+		hdr = append(hdr, c...)
+		hdr = append(hdr, 0)
+		u32(1)
+		u32(0)
+		u32(1)
+		u32(1)
+	}
+	hdr = append(hdr, 0)
+
+	attr("compression", "compression", 1)
+	hdr = append(hdr, 0) // NO_COMPRESSION
+	attr("dataWindow", "box2i", 16)
+	box()
+	attr("displayWindow", "box2i", 16)
+	box()
+	attr("lineOrder", "lineOrder", 1)
+	hdr = append(hdr, 0) // INCREASING_Y
+	attr("pixelAspectRatio", "float", 4)
+	u32(math.Float32bits(1))
+	attr("screenWindowCenter", "v2f", 8)
+	u32(0)
+	u32(0)
+	attr("screenWindowWidth", "float", 4)
+	u32(math.Float32bits(1))
+	hdr = append(hdr, 0) // End of header.
+
+	if _, err := bw.Write(hdr); err != nil {
+		return err
+	}
+
+	// Offset table, one entry per scanline pointing to the start of the
+	// scanline block (the y coordinate, data size, and pixel data).
+	lineSize := b.Dx() * len(channels) * 2
+	blockSize := 4 + 4 + lineSize
+	start := len(hdr) + 8*b.Dy()
+	var buf [8]byte
+	for y := 0; y < b.Dy(); y++ {
+		le.PutUint64(buf[:], uint64(start+y*blockSize))
+		if _, err := bw.Write(buf[:]); err != nil {
+			return err
+		}
+	}
+
+	// Scanline blocks, each of which holds the channels in the same order as
+	// the header describes them.
+	line := make([]byte, lineSize)
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			c := color.RGBA64Model.Convert(m.At(b.Min.X+x, b.Min.Y+y)).(color.RGBA64)
+			alpha := float32(c.A) / 0xffff
+			values := [4]float32{
+				alpha,
+				srgbToLinear(c.B, c.A),
+				srgbToLinear(c.G, c.A),
+				srgbToLinear(c.R, c.A),
+			}
+			for ch, v := range values {
+				i := (ch*b.Dx() + x) * 2
+				le.PutUint16(line[i:], float32ToHalf(v))
+			}
+		}
+		le.PutUint32(buf[:4], uint32(y))
+		le.PutUint32(buf[4:], uint32(lineSize))
+		if _, err := bw.Write(buf[:]); err != nil {
+			return err
+		}
+		if _, err := bw.Write(line); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// srgbToLinear converts the given alpha-premultiplied sRGB color component
+// into a alpha-premultiplied linear one.
+func srgbToLinear(v, a uint16) float32 {
+	if a == 0 {
+		return 0
+	}
+	// Un-premultiply, convert, and premultiply again.
+	alpha := float64(a) / 0xffff
+	s := gfxcolor.SRGBToLinear(float64(v) / 0xffff / alpha)
+	return float32(s * alpha)
+}
+
+// float32ToHalf converts the given 32-bit floating point number into a 16-bit
+// (half) floating point one, rounding to the nearest value.
+func float32ToHalf(f float32) uint16 {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int32(bits>>23) & 0xff
+	mant := bits & 0x7fffff
+
+	switch {
+	case exp == 0xff:
+		// Infinity or NaN.
+		if mant != 0 {
+			return sign | 0x7e00
+		}
+		return sign | 0x7c00
+	case exp-127 > 15:
+		// Overflow, clamp to infinity.
+		return sign | 0x7c00
+	case exp-127 >= -14:
+		// Normal number.
+		h := uint32(exp-127+15)<<10 | mant>>13
+		if mant&0x1000 != 0 {
+			h++ // Round to nearest; may carry into the exponent.
+		}
+		return sign | uint16(h)
+	case exp-127 >= -24:
+		// Subnormal number.
+		mant |= 0x800000
+		shift := uint32(-(exp - 127) - 14 + 13)
+		h := mant >> shift
+		if mant&(1<<(shift-1)) != 0 {
+			h++
+		}
+		return sign | uint16(h)
+	}
+	// Underflow to zero.
+	return sign
+}
@@ -0,0 +1,81 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfxutil
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"azul3d.org/engine/gfx"
+)
+
+// CaptureScreenshot downloads the entire contents of the given canvas and
+// returns it as an image. It blocks until the download completes, which
+// generally means until the next frame is rendered, so it should not be called
+// from the goroutine which renders frames.
+//
+// If the canvas cannot be downloaded (e.g. the device does not support it), a
+// nil image is returned.
+func CaptureScreenshot(c gfx.Canvas) image.Image {
+	complete := make(chan image.Image, 1)
+	c.Download(c.Bounds(), complete)
+	return <-complete
+}
+
+// SaveImage encodes the image to the named file, the format of which is chosen
+// based on the file extension:
+//
+//  ".png" -- PNG, 16 bits per channel if the image is (e.g. *image.RGBA64).
+//  ".exr" -- OpenEXR, see EncodeEXR.
+//
+// Any other file extension results in an error.
+func SaveImage(path string, img image.Image) error {
+	var encode func(f *os.File) error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png":
+		encode = func(f *os.File) error {
+			return png.Encode(f, img)
+		}
+	case ".exr":
+		encode = func(f *os.File) error {
+			return EncodeEXR(f, img)
+		}
+	default:
+		return fmt.Errorf("SaveImage: unknown image file extension %q", filepath.Ext(path))
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := encode(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// SaveScreenshot captures a screenshot of the given canvas (see
+// CaptureScreenshot) and saves it to the named file (see SaveImage).
+//
+// It returns immediately, performing both the capture and the (often slow)
+// image encoding in a separate goroutine. Once complete, the error (or nil) is
+// sent over the returned channel.
+func SaveScreenshot(c gfx.Canvas, path string) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		img := CaptureScreenshot(c)
+		if img == nil {
+			done <- fmt.Errorf("SaveScreenshot: canvas download failed")
+			return
+		}
+		done <- SaveImage(path, img)
+	}()
+	return done
+}
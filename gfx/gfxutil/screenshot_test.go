@@ -0,0 +1,100 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfxutil
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFloat32ToHalf(t *testing.T) {
+	tests := []struct {
+		f    float32
+		want uint16
+	}{
+		{0, 0x0000},
+		{1, 0x3c00},
+		{-2, 0xc000},
+		{0.5, 0x3800},
+		{65504, 0x7bff},
+		{1e6, 0x7c00},
+	}
+	for _, tst := range tests {
+		got := float32ToHalf(tst.f)
+		if got != tst.want {
+			t.Errorf("float32ToHalf(%v) = %#04x, want %#04x", tst.f, got, tst.want)
+		}
+	}
+}
+
+func TestEncodeEXR(t *testing.T) {
+	img := image.NewRGBA64(image.Rect(0, 0, 3, 2))
+	img.Set(1, 1, color.RGBA64{0xffff, 0, 0, 0xffff})
+
+	var buf bytes.Buffer
+	if err := EncodeEXR(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if magic := binary.LittleEndian.Uint32(data); magic != 20000630 {
+		t.Fatalf("got magic number %d, want 20000630", magic)
+	}
+
+	// The file ends with two scanline blocks, each with a y coordinate and
+	// size followed by 3 pixels of 4 half-float channels.
+	lineSize := 3 * 4 * 2
+	last := data[len(data)-lineSize:]
+	if y := binary.LittleEndian.Uint32(data[len(data)-lineSize-8:]); y != 1 {
+		t.Fatalf("last scanline y = %d, want 1", y)
+	}
+
+	// Channels are stored A, B, G, R; check the red pixel at x=1.
+	half := func(ch, x int) uint16 {
+		return binary.LittleEndian.Uint16(last[(ch*3+x)*2:])
+	}
+	if a, b, r := half(0, 1), half(1, 1), half(3, 1); a != 0x3c00 || b != 0 || r != 0x3c00 {
+		t.Fatalf("got A=%#04x B=%#04x R=%#04x, want A=0x3c00 B=0 R=0x3c00", a, b, r)
+	}
+}
+
+func TestSaveImage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gfxutil")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	img := image.NewRGBA64(image.Rect(0, 0, 4, 4))
+	img.Set(2, 3, color.RGBA64{0x1234, 0x5678, 0x9abc, 0xffff})
+
+	path := filepath.Join(dir, "test.png")
+	if err := SaveImage(path, img); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	got, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, g, b, a := got.At(2, 3).RGBA()
+	if (color.RGBA64{uint16(r), uint16(g), uint16(b), uint16(a)}) != img.RGBA64At(2, 3) {
+		t.Fatalf("got %v, want %v (16-bit precision lost)", got.At(2, 3), img.At(2, 3))
+	}
+
+	if err := SaveImage(filepath.Join(dir, "test.bmp"), img); err == nil {
+		t.Fatal("expected error for unknown file extension")
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfxutil
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"azul3d.org/engine/gfx"
+)
+
+// watchedShader is a single shader watched by a ShaderWatcher.
+type watchedShader struct {
+	basePath string
+	shader   *gfx.Shader
+	modTime  time.Time
+
+	// The reloaded shader waiting to be swapped in by Update, or nil.
+	reloaded *gfx.Shader
+}
+
+// ShaderWatcher opens GLSL shaders (see OpenShader) and watches their source
+// files for changes. When a source file changes, the shader is recompiled in
+// the background and, if compilation succeeded, is swapped in place of the
+// old one during the next call to Update.
+//
+// Because the *gfx.Shader returned by Open is updated in-place, every object
+// using it sees the changes -- and if compilation fails, the old shader simply
+// remains in use while the error is reported by Update.
+//
+// A shader watcher is safe for use from multiple goroutines concurrently.
+type ShaderWatcher struct {
+	dev  gfx.Device
+	stop chan struct{}
+
+	access  sync.Mutex
+	shaders []*watchedShader
+	errs    []error
+}
+
+// sourceModTime returns the latest modification time of the shader's source
+// files.
+func (w *watchedShader) sourceModTime() (time.Time, error) {
+	var latest time.Time
+	for _, ext := range []string{".vert", ".frag"} {
+		fi, err := os.Stat(w.basePath + ext)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if fi.ModTime().After(latest) {
+			latest = fi.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// Open opens the GLSL shader files specified by the given base path (see
+// OpenShader) and begins watching them for changes.
+func (w *ShaderWatcher) Open(basePath string) (*gfx.Shader, error) {
+	s, err := OpenShader(basePath)
+	if err != nil {
+		return nil, err
+	}
+	ws := &watchedShader{
+		basePath: basePath,
+		shader:   s,
+	}
+	ws.modTime, err = ws.sourceModTime()
+	if err != nil {
+		return nil, err
+	}
+	w.access.Lock()
+	w.shaders = append(w.shaders, ws)
+	w.access.Unlock()
+	return s, nil
+}
+
+// poll checks each watched shader for changed source files, recompiling the
+// ones which have changed.
+func (w *ShaderWatcher) poll() {
+	w.access.Lock()
+	shaders := make([]*watchedShader, len(w.shaders))
+	copy(shaders, w.shaders)
+	w.access.Unlock()
+
+	for _, ws := range shaders {
+		modTime, err := ws.sourceModTime()
+		if err != nil {
+			// The files may be in the middle of being saved, try again later.
+			continue
+		}
+		if !modTime.After(ws.modTime) {
+			continue
+		}
+		ws.modTime = modTime
+
+		s, err := OpenShader(ws.basePath)
+		if err != nil {
+			w.report(err)
+			continue
+		}
+		// Keep the sources until Update, where the old shader's KeepDataOnLoad
+		// field is honored.
+		s.KeepDataOnLoad = true
+
+		done := make(chan *gfx.Shader, 1)
+		w.dev.LoadShader(s, done)
+		<-done
+		if len(s.Error) > 0 {
+			w.report(fmt.Errorf("%s: shader reload failed:\n%s", ws.basePath, s.Error))
+			continue
+		}
+
+		w.access.Lock()
+		if ws.reloaded != nil && ws.reloaded.NativeShader != nil {
+			// Never swapped in, free it.
+			ws.reloaded.NativeShader.Destroy()
+		}
+		ws.reloaded = s
+		w.access.Unlock()
+	}
+}
+
+// report records the given error, to be returned by the next call to Update.
+func (w *ShaderWatcher) report(err error) {
+	w.access.Lock()
+	w.errs = append(w.errs, err)
+	w.access.Unlock()
+}
+
+// Update swaps each successfully reloaded shader in place of the old one, and
+// returns the shaders which were reloaded along with any errors (e.g. compiler
+// errors) that occurred while reloading shaders since the last call.
+//
+// It must be called from the goroutine which draws objects using the shaders
+// (e.g. once per frame), as the shaders are modified in-place.
+func (w *ShaderWatcher) Update() (reloaded []*gfx.Shader, errs []error) {
+	w.access.Lock()
+	defer w.access.Unlock()
+	for _, ws := range w.shaders {
+		if ws.reloaded == nil {
+			continue
+		}
+		old := ws.shader
+		if old.NativeShader != nil {
+			old.NativeShader.Destroy()
+		}
+		old.NativeShader = ws.reloaded.NativeShader
+		old.Loaded = true
+		old.GLSL = ws.reloaded.GLSL
+		old.Error = nil
+		old.ClearData()
+		ws.reloaded = nil
+		reloaded = append(reloaded, old)
+	}
+	errs = w.errs
+	w.errs = nil
+	return
+}
+
+// Close stops watching for changes to shader source files.
+func (w *ShaderWatcher) Close() {
+	close(w.stop)
+}
+
+// NewShaderWatcher returns a new shader watcher which loads shaders using the
+// given device and checks for changed source files at the given interval
+// (e.g. one second).
+func NewShaderWatcher(d gfx.Device, interval time.Duration) *ShaderWatcher {
+	w := &ShaderWatcher{
+		dev:  d,
+		stop: make(chan struct{}),
+	}
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				w.poll()
+			case <-w.stop:
+				return
+			}
+		}
+	}()
+	return w
+}
@@ -0,0 +1,66 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfxutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"azul3d.org/engine/gfx"
+)
+
+func TestShaderWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gfxutil")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	base := filepath.Join(dir, "basic")
+	write := func(src string, modTime time.Time) {
+		for _, ext := range []string{".vert", ".frag"} {
+			if err := ioutil.WriteFile(base+ext, []byte(src), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chtimes(base+ext, modTime, modTime); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	now := time.Now()
+	write("v1", now)
+
+	// Use a long interval, we poll manually.
+	w := NewShaderWatcher(gfx.Nil(), time.Hour)
+	defer w.Close()
+	s, err := w.Open(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.KeepDataOnLoad = true
+
+	// Unchanged files are not reloaded.
+	w.poll()
+	if reloaded, errs := w.Update(); len(reloaded) != 0 || len(errs) != 0 {
+		t.Fatalf("got %d reloaded, %v errors; want none", len(reloaded), errs)
+	}
+
+	// Changed files are reloaded, and swapped in by Update.
+	write("v2", now.Add(time.Second))
+	w.poll()
+	if string(s.GLSL.Vertex) != "v1" {
+		t.Fatal("shader modified before Update")
+	}
+	reloaded, errs := w.Update()
+	if len(reloaded) != 1 || reloaded[0] != s || len(errs) != 0 {
+		t.Fatalf("got %d reloaded, %v errors; want 1 reloaded", len(reloaded), errs)
+	}
+	if string(s.GLSL.Vertex) != "v2" || !s.Loaded {
+		t.Fatalf("got source %q Loaded=%v, want \"v2\" and true", s.GLSL.Vertex, s.Loaded)
+	}
+}
@@ -0,0 +1,258 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package gizmo implements transform gizmos: handles drawn over an object in
+// an editor, which translate, rotate or scale it when dragged with the mouse.
+//
+// Each frame the gizmo is updated with the position of the cursor and the
+// state of the mouse button, and emits the change to the transform since the
+// last update, which the editor applies (e.g. recording it for undo):
+//
+//  g := gizmo.New(selected.Transform, glsl.GL2)
+//  g.Mode = gizmo.Rotate
+//  for {
+//      down := mouse.Down(mouse.Left)
+//      if d, ok := g.Update(cam, cursor, down); ok {
+//          d.Apply(selected.Transform)
+//      }
+//      ...
+//      g.Draw(d, d.Bounds(), cam)
+//      d.Render()
+//  }
+//
+// The handles are drawn over the scene at a constant size on the screen (see
+// package lines), and are picked on the screen as well, such that they are
+// easy to grab at any distance.
+package gizmo // import "azul3d.org/engine/gfx/gizmo"
+
+import (
+	"fmt"
+	"image"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/camera"
+	"azul3d.org/engine/gfx/glsl"
+	"azul3d.org/engine/gfx/lines"
+	"azul3d.org/engine/lmath"
+)
+
+// Mode is the kind of transformation that a gizmo performs.
+type Mode int
+
+const (
+	// Translate moves the target along an axis, or in the plane of the
+	// screen using the center handle.
+	Translate Mode = iota
+
+	// Rotate rotates the target about an axis.
+	Rotate
+
+	// Scale scales the target along one of it's local axes, or uniformly
+	// using the center handle.
+	Scale
+)
+
+// String returns the name of the mode, e.g. "Translate".
+func (m Mode) String() string {
+	switch m {
+	case Translate:
+		return "Translate"
+	case Rotate:
+		return "Rotate"
+	case Scale:
+		return "Scale"
+	}
+	return fmt.Sprintf("Mode(%d)", int(m))
+}
+
+// Handle is a handle of a gizmo.
+type Handle int
+
+const (
+	// None is no handle.
+	None Handle = iota
+
+	// X, Y and Z are the handles of each axis: arrows when translating or
+	// scaling, and rings about the axis when rotating.
+	X
+	Y
+	Z
+
+	// Center is the handle at the center of the gizmo, when translating or
+	// scaling.
+	Center
+)
+
+// String returns the name of the handle, e.g. "X".
+func (h Handle) String() string {
+	switch h {
+	case None:
+		return "None"
+	case X:
+		return "X"
+	case Y:
+		return "Y"
+	case Z:
+		return "Z"
+	case Center:
+		return "Center"
+	}
+	return fmt.Sprintf("Handle(%d)", int(h))
+}
+
+// Delta is a change to a transform, in the space of it's parent.
+type Delta struct {
+	// Translation is added to the position.
+	Translation lmath.Vec3
+
+	// Rotation is applied after the rotation.
+	Rotation lmath.Quat
+
+	// Scale multiplies the scale, component-wise.
+	Scale lmath.Vec3
+}
+
+// NoDelta is the delta which does not change a transform.
+var NoDelta = Delta{
+	Rotation: lmath.QuatIdentity,
+	Scale:    lmath.Vec3One,
+}
+
+// Apply applies the delta to the given transform.
+func (d Delta) Apply(t *gfx.Transform) {
+	if d.Translation != lmath.Vec3Zero {
+		t.SetPos(t.Pos().Add(d.Translation))
+	}
+	if d.Rotation != lmath.QuatIdentity {
+		t.SetQuat(t.Quat().Mul(d.Rotation))
+	}
+	if d.Scale != lmath.Vec3One {
+		t.SetScale(t.Scale().Mul(d.Scale))
+	}
+}
+
+// The colors of the handles of each axis, and of the hovered or dragged
+// handle.
+var (
+	axisColors = [3]gfx.Color{
+		{R: 0.9, G: 0.2, B: 0.2, A: 1},
+		{R: 0.2, G: 0.8, B: 0.2, A: 1},
+		{R: 0.2, G: 0.4, B: 0.9, A: 1},
+	}
+	centerColor = gfx.Color{R: 0.9, G: 0.9, B: 0.9, A: 1}
+	activeColor = gfx.Color{R: 1, G: 0.85, B: 0.1, A: 1}
+)
+
+// Gizmo is a transform gizmo.
+//
+// A gizmo and it's methods are not safe for access from multiple goroutines
+// concurrently.
+type Gizmo struct {
+	// Target is the transform which is manipulated, whose position the gizmo
+	// is drawn at.
+	Target *gfx.Transform
+
+	// The mode of the gizmo. It must not be changed while dragging.
+	Mode Mode
+
+	// Local tells whether the axes of the gizmo follow the rotation of the
+	// target, instead of the axes of it's parent. Scaling always uses the
+	// target's local axes.
+	Local bool
+
+	// Size is the length of the handles on the screen, in pixels. If zero, a
+	// length of 100 pixels is used.
+	Size float64
+
+	// Snap is the increment that translations (in units) and scale factors
+	// are rounded to, and SnapAngle the one that rotations (in radians) are
+	// rounded to. Zero disables snapping.
+	Snap, SnapAngle float64
+
+	// Hover is the handle under the cursor as of the last update, and Active
+	// the one being dragged (None when not dragging).
+	Hover, Active Handle
+
+	batch *lines.Batch
+	down  bool
+	drag  drag
+}
+
+// Update updates the gizmo with the position of the cursor in window
+// coordinates (see camera.Camera.NDC) and whether the mouse button which
+// drags the handles is down.
+//
+// Pressing the button over a handle starts dragging it, and releasing it
+// stops. While dragging, the change to the target since the last update is
+// returned with ok=true; the gizmo does not apply it itself (see the Apply
+// method of Delta). Otherwise NoDelta and ok=false are returned.
+func (g *Gizmo) Update(cam *camera.Camera, cursor lmath.Vec2, down bool) (d Delta, ok bool) {
+	pressed := down && !g.down
+	g.down = down
+	if g.Active != None && !down {
+		g.Active = None
+	}
+	if g.Active == None {
+		g.Hover = g.Pick(cam, cursor)
+		if pressed && g.Hover != None {
+			g.begin(cam, cursor, g.Hover)
+		}
+		return NoDelta, false
+	}
+	return g.dragTo(cam, cursor)
+}
+
+// Draw draws the gizmo to the given rectangle of the given canvas, using the
+// given camera. It should be drawn after the scene, as it is drawn over it.
+func (g *Gizmo) Draw(canvas gfx.Canvas, r image.Rectangle, cam *camera.Camera) {
+	f, ok := g.frame(cam)
+	if !ok {
+		return
+	}
+	color := func(h Handle) gfx.Color {
+		if h == g.Active || (g.Active == None && h == g.Hover) {
+			return activeColor
+		}
+		if h == Center {
+			return centerColor
+		}
+		return axisColors[h-X]
+	}
+	style := lines.Style{Width: 3, Cap: lines.RoundCap, Join: lines.RoundJoin}
+	for i, h := range []Handle{X, Y, Z} {
+		axis := f.axes[i]
+		if g.Mode == Rotate {
+			g.batch.AddPolyline(f.ring(i), true, color(h), style)
+			continue
+		}
+		tip := f.origin.Add(axis.MulScalar(f.length))
+		g.batch.AddLine(f.origin, tip, color(h), style)
+		tipStyle := lines.Style{Width: 10, Cap: lines.RoundCap}
+		if g.Mode == Scale {
+			tipStyle.Cap = lines.SquareCap
+		}
+		g.batch.AddPoint(tip, color(h), tipStyle)
+	}
+	if g.Mode != Rotate {
+		g.batch.AddPoint(f.origin, color(Center), lines.Style{Width: 12, Cap: lines.SquareCap})
+	}
+	g.batch.Draw(canvas, r, cam)
+}
+
+// Destroy destroys the objects that the gizmo draws with. You must not use it
+// after calling this method.
+func (g *Gizmo) Destroy() {
+	g.batch.Destroy()
+}
+
+// New returns a new gizmo manipulating the given transform, in the Translate
+// mode, whose shader is preprocessed for the given target.
+func New(target *gfx.Transform, t glsl.Target) *Gizmo {
+	b := lines.New(t)
+	b.State.DepthTest = false
+	return &Gizmo{
+		Target: target,
+		batch:  b,
+	}
+}
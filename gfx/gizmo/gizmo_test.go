@@ -0,0 +1,142 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gizmo
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/camera"
+	"azul3d.org/engine/gfx/glsl"
+	"azul3d.org/engine/lmath"
+)
+
+// setup returns a gizmo manipulating a transform at the origin, viewed by a
+// camera ten units in front of it, and the frame of the gizmo.
+func setup(t *testing.T, m Mode) (*Gizmo, *camera.Camera, frame) {
+	cam := camera.New(image.Rect(0, 0, 640, 480))
+	cam.Transform().SetPos(lmath.Vec3{Y: -10})
+	g := New(gfx.NewTransform(), glsl.GL2)
+	g.Mode = m
+	f, ok := g.frame(cam)
+	if !ok {
+		t.Fatal("target not visible")
+	}
+	return g, cam, f
+}
+
+// cursorAt returns the window position of the given world space point.
+func cursorAt(t *testing.T, f frame, p lmath.Vec3) lmath.Vec2 {
+	w, ok := f.window(p)
+	if !ok {
+		t.Fatal("point not visible", p)
+	}
+	return w
+}
+
+// ringPoint returns the point on the Y ring at the given angle in degrees,
+// from the Z axis towards the X axis.
+func ringPoint(f frame, degrees float64) lmath.Vec3 {
+	sin, cos := math.Sincos(lmath.Radians(degrees))
+	return lmath.Vec3{X: sin, Z: cos}.MulScalar(f.length)
+}
+
+func TestPick(t *testing.T) {
+	g, cam, f := setup(t, Translate)
+	if got := cursorAt(t, f, f.origin.Add(lmath.Vec3{X: f.length})).Sub(cursorAt(t, f, f.origin)).Length(); math.Abs(got-100) > 1e-6 {
+		t.Fatalf("handles are %v pixels long, want 100", got)
+	}
+	for _, tc := range []struct {
+		p    lmath.Vec3
+		want Handle
+	}{
+		{lmath.Vec3{}, Center},
+		{lmath.Vec3{X: f.length / 2}, X},
+		{lmath.Vec3{Z: f.length / 2}, Z},
+		{lmath.Vec3{X: f.length, Z: f.length}, None},
+	} {
+		if got := g.Pick(cam, cursorAt(t, f, tc.p)); got != tc.want {
+			t.Errorf("Pick(%v) = %v, want %v", tc.p, got, tc.want)
+		}
+	}
+
+	// The rings of the Rotate mode have the length of the handles as their
+	// radius. Seen from the front, the X and Z rings are edge-on.
+	g.Mode = Rotate
+	if got := g.Pick(cam, cursorAt(t, f, ringPoint(f, 45))); got != Y {
+		t.Errorf("Pick on the Y ring = %v", got)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	g, cam, f := setup(t, Translate)
+	start := cursorAt(t, f, lmath.Vec3{X: f.length / 2})
+	if _, ok := g.Update(cam, start, true); ok || g.Active != X {
+		t.Fatal("dragging did not start", g.Active)
+	}
+
+	// Two units along X, in two steps.
+	var total lmath.Vec3
+	for _, x := range []float64{1, 2} {
+		d, ok := g.Update(cam, cursorAt(t, f, lmath.Vec3{X: f.length/2 + x}), true)
+		if !ok {
+			t.Fatal("no delta")
+		}
+		total = total.Add(d.Translation)
+		d.Apply(g.Target)
+	}
+	if !total.AlmostEquals(lmath.Vec3{X: 2}, 1e-6) || !g.Target.Pos().AlmostEquals(total, 1e-12) {
+		t.Fatalf("translated by %v, want 2 along X", total)
+	}
+
+	// Releasing the button stops dragging.
+	if _, ok := g.Update(cam, start, false); ok || g.Active != None {
+		t.Fatal("dragging did not stop")
+	}
+}
+
+func TestRotateSnap(t *testing.T) {
+	g, cam, f := setup(t, Rotate)
+	g.SnapAngle = math.Pi / 4
+
+	// Almost a quarter turn about Y, snapped to a quarter turn.
+	g.Update(cam, cursorAt(t, f, ringPoint(f, 45)), true)
+	if g.Active != Y {
+		t.Fatal("dragging did not start", g.Active)
+	}
+	d, ok := g.Update(cam, cursorAt(t, f, ringPoint(f, 130)), true)
+	if !ok {
+		t.Fatal("no delta")
+	}
+	got := d.Rotation.TransformVec3(lmath.Vec3{Z: 1})
+	if !got.AlmostEquals(lmath.Vec3{X: 1}, 1e-6) {
+		t.Fatalf("rotated Z to %v, want X", got)
+	}
+}
+
+func TestScale(t *testing.T) {
+	g, cam, f := setup(t, Scale)
+	start := cursorAt(t, f, f.origin)
+	g.Update(cam, start, true)
+	if g.Active != Center {
+		t.Fatal("dragging did not start", g.Active)
+	}
+	d, ok := g.Update(cam, start.Add(lmath.Vec2{X: 50}), true)
+	if !ok || !d.Scale.AlmostEquals(lmath.Vec3{X: 1.5, Y: 1.5, Z: 1.5}, 1e-9) {
+		t.Fatalf("scaled by %v, want 1.5", d.Scale)
+	}
+	if d.Translation != lmath.Vec3Zero || d.Rotation != lmath.QuatIdentity {
+		t.Fatal("scaling moved or rotated", d)
+	}
+
+	d2 := gfx.NewTransform()
+	g.Draw(gfx.Nil(), image.Rect(0, 0, 0, 0), cam)
+	d.Apply(d2)
+	if d2.Scale() != (lmath.Vec3{X: 1.5, Y: 1.5, Z: 1.5}) {
+		t.Fatal("wrong scale applied", d2.Scale())
+	}
+}
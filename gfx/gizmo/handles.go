@@ -0,0 +1,359 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gizmo
+
+import (
+	"math"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/camera"
+	"azul3d.org/engine/lmath"
+)
+
+// ringSegments is the number of line segments that each ring of the Rotate
+// mode is drawn and picked with.
+const ringSegments = 48
+
+// pickDistance is the distance from a handle, in pixels, within which the
+// cursor picks it.
+const pickDistance = 8
+
+// frame is the world space frame of a gizmo, as seen by a camera.
+type frame struct {
+	// The world space position of the target, and the world space unit
+	// vectors of the axes of the gizmo.
+	origin lmath.Vec3
+	axes   [3]lmath.Vec3
+
+	// The axes of the gizmo in the space of the target's parent.
+	parentAxes [3]lmath.Vec3
+
+	// The world space length of the handles.
+	length float64
+
+	cam *camera.Camera
+}
+
+// frame returns the frame of the gizmo, as seen by the given camera. If the
+// target is not in front of the camera, ok=false is returned.
+func (g *Gizmo) frame(cam *camera.Camera) (f frame, ok bool) {
+	f.cam = cam
+	parentToWorld := g.Target.Convert(gfx.ParentToWorld)
+	f.origin = g.Target.Pos().TransformMat4(parentToWorld)
+	q := g.Target.Quat()
+	for i := range f.axes {
+		var axis lmath.Vec3
+		switch i {
+		case 0:
+			axis.X = 1
+		case 1:
+			axis.Y = 1
+		case 2:
+			axis.Z = 1
+		}
+		if g.Local || g.Mode == Scale {
+			axis = q.TransformVec3(axis)
+		}
+		f.parentAxes[i] = axis
+		world, ok := axis.TransformVecMat4(parentToWorld).Normalized()
+		if !ok {
+			world = axis
+		}
+		f.axes[i] = world
+	}
+
+	// The length of the handles in world units, such that they span the
+	// gizmo's size in pixels.
+	right, ok := lmath.Vec3{X: 1}.TransformVecMat4(cam.Transform().Mat4()).Normalized()
+	if !ok {
+		return f, false
+	}
+	a, ok := f.window(f.origin)
+	if !ok {
+		return f, false
+	}
+	b, ok := f.window(f.origin.Add(right))
+	if !ok {
+		return f, false
+	}
+	pixels := b.Sub(a).Length()
+	if pixels == 0 {
+		return f, false
+	}
+	f.length = g.size() / pixels
+	return f, true
+}
+
+// size returns the length of the handles on the screen, in pixels.
+func (g *Gizmo) size() float64 {
+	if g.Size <= 0 {
+		return 100
+	}
+	return g.Size
+}
+
+// window returns the window coordinates of the given world space point.
+func (f frame) window(p lmath.Vec3) (lmath.Vec2, bool) {
+	ndc, ok := f.cam.Project(p)
+	v := f.cam.View
+	return lmath.Vec2{
+		X: float64(v.Min.X) + (ndc.X+1)/2*float64(v.Dx()),
+		Y: float64(v.Min.Y) + (1-ndc.Y)/2*float64(v.Dy()),
+	}, ok
+}
+
+// ring returns the world space points of the ring about the given axis.
+func (f frame) ring(axis int) []lmath.Vec3 {
+	u, v := f.axes[(axis+1)%3], f.axes[(axis+2)%3]
+	points := make([]lmath.Vec3, ringSegments)
+	for i := range points {
+		sin, cos := math.Sincos(2 * math.Pi * float64(i) / ringSegments)
+		p := u.MulScalar(cos).Add(v.MulScalar(sin))
+		points[i] = f.origin.Add(p.MulScalar(f.length))
+	}
+	return points
+}
+
+// segmentDistance returns the distance from p to the segment from a to b.
+func segmentDistance(p, a, b lmath.Vec2) float64 {
+	ab := b.Sub(a)
+	t := 0.0
+	if l := ab.LengthSq(); l > 0 {
+		t = lmath.Clamp(p.Sub(a).Dot(ab)/l, 0, 1)
+	}
+	return p.Sub(a.Add(ab.MulScalar(t))).Length()
+}
+
+// polylineDistance returns the distance from p to the given world space
+// polyline on the screen, or +Inf if it is not in front of the camera.
+func (f frame) polylineDistance(p lmath.Vec2, points []lmath.Vec3, closed bool) float64 {
+	best := math.Inf(1)
+	n := len(points)
+	segments := n - 1
+	if closed {
+		segments = n
+	}
+	for i := 0; i < segments; i++ {
+		a, okA := f.window(points[i])
+		b, okB := f.window(points[(i+1)%n])
+		if !okA || !okB {
+			continue
+		}
+		best = math.Min(best, segmentDistance(p, a, b))
+	}
+	return best
+}
+
+// Pick returns the handle under the given position of the cursor in window
+// coordinates (see camera.Camera.NDC), or None.
+func (g *Gizmo) Pick(cam *camera.Camera, cursor lmath.Vec2) Handle {
+	f, ok := g.frame(cam)
+	if !ok {
+		return None
+	}
+	if g.Mode != Rotate {
+		// The center handle is on top of the axes.
+		if c, ok := f.window(f.origin); ok && cursor.Sub(c).Length() <= pickDistance {
+			return Center
+		}
+	}
+	nearest, best := None, float64(pickDistance)
+	for i, h := range []Handle{X, Y, Z} {
+		var d float64
+		if g.Mode == Rotate {
+			d = f.polylineDistance(cursor, f.ring(i), true)
+		} else {
+			tip := f.origin.Add(f.axes[i].MulScalar(f.length))
+			d = f.polylineDistance(cursor, []lmath.Vec3{f.origin, tip}, false)
+		}
+		if d <= best {
+			nearest, best = h, d
+		}
+	}
+	return nearest
+}
+
+// drag is the state of a gizmo while dragging a handle.
+type drag struct {
+	// The frame of the gizmo when dragging started.
+	frame frame
+
+	// The cursor position, and the parameter (the distance along the axis,
+	// angle about it, or point on the plane of the screen) of the ray
+	// through it, when dragging started (or, when rotating, as of the last
+	// update).
+	cursor lmath.Vec2
+	param  lmath.Vec3
+
+	// The angle rotated by so far, when rotating.
+	angle float64
+
+	// The total delta emitted so far.
+	emitted Delta
+}
+
+// axisParam returns the distance along the given world space axis (through
+// the origin) of the point nearest to the given ray.
+func axisParam(r lmath.Ray, origin, axis lmath.Vec3) (float64, bool) {
+	// The nearest points of two lines.
+	w := origin.Sub(r.Origin)
+	b := axis.Dot(r.Dir)
+	denom := axis.Dot(axis)*r.Dir.Dot(r.Dir) - b*b
+	if math.Abs(denom) < 1e-9 {
+		// The ray is parallel to the axis.
+		return 0, false
+	}
+	return (b*r.Dir.Dot(w) - r.Dir.Dot(r.Dir)*axis.Dot(w)) / denom, true
+}
+
+// planePoint returns the intersection of the given ray with the plane
+// through the origin with the given normal.
+func planePoint(r lmath.Ray, origin, normal lmath.Vec3) (lmath.Vec3, bool) {
+	t, ok := r.IntersectPlane(lmath.Plane{Normal: normal, D: -normal.Dot(origin)})
+	if !ok {
+		return lmath.Vec3{}, false
+	}
+	return r.At(t), true
+}
+
+// param returns the parameter of the ray through the given cursor position
+// for the active handle, see the drag type.
+func (g *Gizmo) param(f frame, cursor lmath.Vec2) (lmath.Vec3, bool) {
+	r := f.cam.PickRay(cursor)
+	if g.Active == Center {
+		forward, _ := lmath.Vec3{Y: 1}.TransformVecMat4(f.cam.Transform().Mat4()).Normalized()
+		return planePoint(r, f.origin, forward)
+	}
+	axis := f.axes[g.Active-X]
+	if g.Mode != Rotate {
+		t, ok := axisParam(r, f.origin, axis)
+		return lmath.Vec3{X: t}, ok
+	}
+
+	// The angle of the point on the plane of the ring.
+	p, ok := planePoint(r, f.origin, axis)
+	if !ok {
+		return lmath.Vec3{}, false
+	}
+	i := int(g.Active - X)
+	d := p.Sub(f.origin)
+	u, v := f.axes[(i+1)%3], f.axes[(i+2)%3]
+	return lmath.Vec3{X: math.Atan2(d.Dot(v), d.Dot(u))}, true
+}
+
+// snap rounds v to the nearest multiple of the given increment, if it is not
+// zero.
+func snap(v, increment float64) float64 {
+	if increment <= 0 {
+		return v
+	}
+	return math.Floor(v/increment+0.5) * increment
+}
+
+// begin starts dragging the given handle.
+func (g *Gizmo) begin(cam *camera.Camera, cursor lmath.Vec2, h Handle) {
+	f, ok := g.frame(cam)
+	if !ok {
+		return
+	}
+	g.Active = h
+	param, ok := g.param(f, cursor)
+	if !ok {
+		g.Active = None
+		return
+	}
+	g.drag = drag{
+		frame:   f,
+		cursor:  cursor,
+		param:   param,
+		emitted: NoDelta,
+	}
+}
+
+// total returns the total delta of dragging the active handle to the given
+// cursor position since dragging started.
+func (g *Gizmo) total(cursor lmath.Vec2) (Delta, bool) {
+	f := g.drag.frame
+	total := NoDelta
+	if g.Mode == Scale && g.Active == Center {
+		// Dragging up and to the right grows the target.
+		moved := cursor.Sub(g.drag.cursor)
+		factor := 1 + (moved.X-moved.Y)/g.size()
+		factor = math.Max(snap(factor, g.Snap), 0.01)
+		total.Scale = lmath.Vec3{X: factor, Y: factor, Z: factor}
+		return total, true
+	}
+	param, ok := g.param(f, cursor)
+	if !ok {
+		return total, false
+	}
+
+	// The world to parent space conversion of points.
+	worldToParent := g.Target.Convert(gfx.WorldToParent)
+	toParent := func(p lmath.Vec3) lmath.Vec3 {
+		return p.TransformMat4(worldToParent)
+	}
+	if g.Active == Center {
+		moved := toParent(param).Sub(toParent(g.drag.param))
+		total.Translation = lmath.Vec3{
+			X: snap(moved.X, g.Snap),
+			Y: snap(moved.Y, g.Snap),
+			Z: snap(moved.Z, g.Snap),
+		}
+		return total, true
+	}
+
+	i := int(g.Active - X)
+	switch g.Mode {
+	case Translate:
+		// The distance along the world space axis, in the parent's units.
+		world := f.axes[i].MulScalar(param.X - g.drag.param.X)
+		moved := toParent(f.origin.Add(world)).Sub(toParent(f.origin)).Length()
+		if param.X < g.drag.param.X {
+			moved = -moved
+		}
+		total.Translation = f.parentAxes[i].MulScalar(snap(moved, g.Snap))
+	case Rotate:
+		// The angle is unwrapped, such that the target can be rotated by
+		// more than half a turn.
+		g.drag.angle += math.Remainder(param.X-g.drag.param.X, 2*math.Pi)
+		g.drag.param = param
+		angle := snap(g.drag.angle, g.SnapAngle)
+		total.Rotation = lmath.QuatFromAxisAngle(f.parentAxes[i], angle)
+	case Scale:
+		factor := 1 + (param.X-g.drag.param.X)/f.length
+		factor = math.Max(snap(factor, g.Snap), 0.01)
+		switch i {
+		case 0:
+			total.Scale.X = factor
+		case 1:
+			total.Scale.Y = factor
+		case 2:
+			total.Scale.Z = factor
+		}
+	}
+	return total, true
+}
+
+// dragTo drags the active handle to the given cursor position, returning the
+// delta since the last update.
+func (g *Gizmo) dragTo(cam *camera.Camera, cursor lmath.Vec2) (Delta, bool) {
+	// The camera may have moved since dragging started.
+	g.drag.frame.cam = cam
+	total, ok := g.total(cursor)
+	if !ok {
+		return NoDelta, false
+	}
+	prev := g.drag.emitted
+	if total == prev {
+		return NoDelta, false
+	}
+	g.drag.emitted = total
+	return Delta{
+		Translation: total.Translation.Sub(prev.Translation),
+		Rotation:    prev.Rotation.Conjugate().Mul(total.Rotation),
+		Scale:       total.Scale.Div(prev.Scale),
+	}, true
+}
@@ -0,0 +1,121 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gl2
+
+import (
+	"image"
+	"sync"
+
+	"azul3d.org/engine/gfx"
+)
+
+// batcher is the canvas that command lists are executed on by Submit. Instead
+// of sending each command to the render loop individually (a channel send and
+// context switch each) it collects them, such that they may all be sent at
+// once.
+type batcher struct {
+	gfx.Canvas
+	r         *device
+	pre, post func()
+	fns       []func() bool
+}
+
+// add adds the given function to the batch, unless it is nil.
+func (b *batcher) add(f func() bool) {
+	if f != nil {
+		b.fns = append(b.fns, f)
+	}
+}
+
+// Implements gfx.Canvas interface.
+func (b *batcher) Clear(rect image.Rectangle, bg gfx.Color) {
+	b.add(b.r.clearExec(rect, bg, b.pre, b.post))
+}
+
+// Implements gfx.Canvas interface.
+func (b *batcher) ClearDepth(rect image.Rectangle, depth float64) {
+	b.add(b.r.clearDepthExec(rect, depth, b.pre, b.post))
+}
+
+// Implements gfx.Canvas interface.
+func (b *batcher) ClearStencil(rect image.Rectangle, stencil int) {
+	b.add(b.r.clearStencilExec(rect, stencil, b.pre, b.post))
+}
+
+// Implements gfx.Canvas interface.
+func (b *batcher) Draw(rect image.Rectangle, o *gfx.Object, c gfx.Camera) {
+	b.add(b.r.drawExec(rect, o, c, b.pre, b.post))
+}
+
+// Implements gfx.DebugGrouper interface.
+func (b *batcher) PushDebugGroup(name string) {
+	b.add(func() bool {
+		b.r.pushDebugGroup(name)
+		return false
+	})
+}
+
+// Implements gfx.DebugGrouper interface.
+func (b *batcher) PopDebugGroup() {
+	b.add(func() bool {
+		b.r.popDebugGroup()
+		return false
+	})
+}
+
+// exec executes the batch and then releases the batcher. It must be called
+// inside renderExec.
+func (b *batcher) exec() bool {
+	for i, f := range b.fns {
+		f()
+		b.fns[i] = nil
+	}
+	b.release()
+	return false
+}
+
+// release resets the batcher and puts it back into the pool for reuse.
+func (b *batcher) release() {
+	b.fns = b.fns[:0]
+	b.Canvas = nil
+	b.r = nil
+	b.pre, b.post = nil, nil
+	batcherPool.Put(b)
+}
+
+var batcherPool = sync.Pool{
+	New: func() interface{} {
+		return &batcher{}
+	},
+}
+
+// submit executes the command list on the given canvas of this device. The
+// resources of the objects drawn are loaded first, and then every command is
+// sent to the render loop at once.
+func (r *device) submit(l *gfx.CommandList, c gfx.Canvas, pre, post func()) {
+	b := batcherPool.Get().(*batcher)
+	b.Canvas = c
+	b.r = r
+	b.pre, b.post = pre, post
+	l.Execute(b)
+	if len(b.fns) == 0 {
+		b.release()
+		return
+	}
+	r.renderExec <- b.exec
+}
+
+// Submit implements the gfx.CommandSubmitter interface.
+func (r *device) Submit(l *gfx.CommandList) {
+	r.submit(l, r, nil, nil)
+}
+
+// Submit implements the gfx.CommandSubmitter interface.
+func (r *rttCanvas) Submit(l *gfx.CommandList) {
+	if r.noop() {
+		return
+	}
+	r.r.submit(l, r, r.rttBegin, r.rttEnd)
+}
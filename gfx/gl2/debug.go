@@ -28,7 +28,7 @@ func debugType(t uint32) string {
 	case gl.DEBUG_TYPE_OTHER:
 		return "OTHER"
 	default:
-		return fmt.Sprintf("Type(0x%x)")
+		return fmt.Sprintf("Type(0x%x)", t)
 	}
 }
 
@@ -41,7 +41,7 @@ func debugSeverity(t uint32) string {
 	case gl.DEBUG_SEVERITY_HIGH:
 		return "HIGH"
 	default:
-		return fmt.Sprintf("Severity(0x%x)")
+		return fmt.Sprintf("Severity(0x%x)", t)
 	}
 }
 
@@ -0,0 +1,115 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gl2
+
+import (
+	"fmt"
+
+	"azul3d.org/engine/gfx/internal/gl/2.0/gl"
+	"azul3d.org/engine/gfx/internal/glutil"
+)
+
+// labelKind is the kind of OpenGL object being labeled.
+type labelKind int
+
+const (
+	labelBuffer labelKind = iota
+	labelTexture
+	labelProgram
+)
+
+// debugLabelInit queries for the extensions used for debug labels and groups.
+func (r *device) debugLabelInit(exts glutil.Extensions) {
+	r.glKhrDebug = exts.Present("GL_KHR_debug")
+	r.glExtDebugMarker = exts.Present("GL_EXT_debug_marker")
+	r.glExtDebugLabel = exts.Present("GL_EXT_debug_label")
+	r.devInfo.DebugLabels = r.glKhrDebug || (r.glExtDebugMarker && r.glExtDebugLabel)
+}
+
+// PushDebugGroup implements the gfx.DebugGrouper interface.
+func (r *device) PushDebugGroup(name string) {
+	r.renderExec <- func() bool {
+		r.pushDebugGroup(name)
+		return false
+	}
+}
+
+// PopDebugGroup implements the gfx.DebugGrouper interface.
+func (r *device) PopDebugGroup() {
+	r.renderExec <- func() bool {
+		r.popDebugGroup()
+		return false
+	}
+}
+
+// pushDebugGroup pushes a debug group of the given name, if supported. It must
+// be called inside renderExec.
+func (r *device) pushDebugGroup(name string) {
+	switch {
+	case r.glKhrDebug:
+		gl.PushDebugGroup(gl.DEBUG_SOURCE_APPLICATION, 0, int32(len(name)), gl.Str(name+"\x00"))
+	case r.glExtDebugMarker:
+		gl.PushGroupMarkerEXT(int32(len(name)), gl.Str(name+"\x00"))
+	}
+}
+
+// popDebugGroup pops the current debug group, if supported. It must be called
+// inside renderExec.
+func (r *device) popDebugGroup() {
+	switch {
+	case r.glKhrDebug:
+		gl.PopDebugGroup()
+	case r.glExtDebugMarker:
+		gl.PopGroupMarkerEXT()
+	}
+}
+
+// labelObject labels the given OpenGL object, if supported. If the label is
+// an empty string or the object ID is zero, it is no-op. It must be called
+// inside renderExec.
+func (r *device) labelObject(kind labelKind, id uint32, label string) {
+	if id == 0 || len(label) == 0 {
+		return
+	}
+	switch {
+	case r.glKhrDebug:
+		identifier := map[labelKind]uint32{
+			labelBuffer:  gl.BUFFER,
+			labelTexture: gl.TEXTURE,
+			labelProgram: gl.PROGRAM,
+		}[kind]
+		gl.ObjectLabel(identifier, id, int32(len(label)), gl.Str(label+"\x00"))
+	case r.glExtDebugLabel:
+		xtype := map[labelKind]uint32{
+			labelBuffer:  gl.BUFFER_OBJECT_EXT,
+			labelTexture: gl.TEXTURE,
+			labelProgram: gl.PROGRAM_OBJECT_EXT,
+		}[kind]
+		gl.LabelObjectEXT(xtype, id, int32(len(label)), gl.Str(label+"\x00"))
+	}
+}
+
+// labelMesh labels each buffer of the given native mesh with the given label,
+// suffixed by what the buffer holds (e.g. "label.Vertices"). It must be called
+// inside renderExec.
+func (r *device) labelMesh(native *nativeMesh, label string) {
+	if len(label) == 0 || !r.devInfo.DebugLabels {
+		return
+	}
+	r.labelObject(labelBuffer, native.indices, label+".Indices")
+	r.labelObject(labelBuffer, native.vertices, label+".Vertices")
+	for i, vbo := range native.texCoords {
+		r.labelObject(labelBuffer, vbo, fmt.Sprintf("%s.TexCoord%d", label, i))
+	}
+	for name, attrib := range native.attribs {
+		for i, vbo := range attrib.vbos {
+			indexName := name
+			if len(attrib.vbos) > 1 {
+				indexName = fmt.Sprintf("%s%d", name, i)
+			}
+			r.labelObject(labelBuffer, vbo, label+"."+indexName)
+		}
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"fmt"
 	"image"
 	"io"
-	"log"
 	"runtime"
 	"sync"
 	"time"
@@ -20,8 +19,13 @@ import (
 	"azul3d.org/engine/gfx/internal/glutil"
 	"azul3d.org/engine/gfx/internal/tag"
 	"azul3d.org/engine/gfx/internal/util"
+	"azul3d.org/engine/log"
 )
 
+// logger is the logger of the device, with which debug output is logged
+// unless a debug output writer is set.
+var logger = log.New("gfx")
+
 type pendingQuery struct {
 	// The ID of the pending occlusion query.
 	id uint32
@@ -36,9 +40,19 @@ type rsrcManager struct {
 	sync.RWMutex
 	meshes        []*nativeMesh
 	shaders       []*nativeShader
+	indirects     []*nativeIndirect
 	textures      []uint32
 	fbos          []uint32
 	renderbuffers []uint32
+
+	// The memory tracker of the device, such that freed resources are
+	// accounted for.
+	mem *memory
+
+	// The graphics state and persistent mapping state of the device, such
+	// that deleted objects are unbound from and forgotten by them.
+	state   *graphicsState
+	persist *persister
 }
 
 // freePending free's all of the pending resources.
@@ -46,23 +60,29 @@ func (r *rsrcManager) freePending() {
 	r.Lock()
 
 	// Free the meshes.
-	if tag.Gfxdebug && len(r.meshes) > 0 {
-		log.Printf("gfx: free %d meshes\n", len(r.meshes))
+	if len(r.meshes) > 0 {
+		logger.Debugf("free %d meshes", len(r.meshes))
 	}
 	for _, native := range r.meshes {
-		native.free()
+		native.free(r.mem)
 	}
 	r.meshes = r.meshes[:0]
 
 	// Free the shaders.
-	if tag.Gfxdebug && len(r.shaders) > 0 {
-		log.Printf("gfx: free %d shaders\n", len(r.shaders))
+	if len(r.shaders) > 0 {
+		logger.Debugf("free %d shaders", len(r.shaders))
 	}
 	for _, native := range r.shaders {
 		native.free()
 	}
 	r.shaders = r.shaders[:0]
 
+	// Free the indirect buffers.
+	for _, native := range r.indirects {
+		native.free(r.mem)
+	}
+	r.indirects = r.indirects[:0]
+
 	r.Unlock()
 
 	r.freeTextures()
@@ -78,8 +98,13 @@ type device struct {
 	clock         *clock.Clock
 	devInfo       gfx.DeviceInfo
 	rsrcManager   *rsrcManager
+	mem           *memory
 	graphicsState *graphicsState
 
+	// Scratch space for uploading uniform values, see the scratch method.
+	// Only touched inside renderExec.
+	uniformScratch [16]float32
+
 	// Render execution channel.
 	renderExec chan func() bool
 
@@ -91,7 +116,15 @@ type device struct {
 
 	// Whether or not certain extensions we use are present or not.
 	glArbDebugOutput, glArbMultisample, glArbFramebufferObject,
-	glArbOcclusionQuery bool
+	glArbOcclusionQuery, glArbTimerQuery, glKhrDebug, glExtDebugMarker,
+	glExtDebugLabel, glArbPixelBufferObject, glFramebufferSRGB,
+	glExtTextureFilterAnisotropic, glArbHalfFloatVertex bool
+
+	// Whether or not sRGB framebuffer conversion is enabled, see SetSRGB.
+	srgb struct {
+		sync.RWMutex
+		enabled bool
+	}
 
 	// Number of multisampling samples, buffers.
 	samples, sampleBuffers int32
@@ -114,6 +147,33 @@ type device struct {
 	rttTexFormats map[gfx.TexFormat]int32
 	rttDSFormats  map[gfx.DSFormat]int32
 
+	// Per-pass profiling state.
+	profile profiler
+
+	// Asynchronous download state.
+	download downloader
+
+	// Streaming texture state.
+	stream streamer
+
+	// Mipmap streaming state.
+	mips mipStreamer
+
+	// Frame capture state.
+	capture capturer
+
+	// Persistent mapping state.
+	persist persister
+
+	// Indirect drawing state.
+	indirect indirector
+
+	// Program binary cache state.
+	shaderCache shaderCache
+
+	// Sampler object state.
+	samplers samplerObjects
+
 	// If non-nil, then we are currently rendering to a texture. It is only
 	// touched inside renderExec.
 	rttCanvas *rttCanvas
@@ -173,8 +233,26 @@ func (r *device) Info() gfx.DeviceInfo {
 	return r.devInfo
 }
 
+// SetSRGB implements the gfx.SRGBCanvas interface.
+func (r *device) SetSRGB(enabled bool) {
+	r.srgb.Lock()
+	r.srgb.enabled = enabled
+	r.srgb.Unlock()
+}
+
+// SRGB implements the gfx.SRGBCanvas interface.
+func (r *device) SRGB() bool {
+	r.srgb.RLock()
+	enabled := r.srgb.enabled
+	r.srgb.RUnlock()
+	return enabled
+}
+
 // SetDebugOutput implements the Device interface.
 func (r *device) SetDebugOutput(w io.Writer) {
+	if w == nil {
+		w = logger.Writer(log.Warn)
+	}
 	r.warner.RLock()
 	r.warner.W = w
 	r.warner.RUnlock()
@@ -193,11 +271,19 @@ func (r *device) Destroy() {
 
 // Implements gfx.Canvas interface.
 func (r *device) hookedClear(rect image.Rectangle, bg gfx.Color, pre, post func()) {
+	if f := r.clearExec(rect, bg, pre, post); f != nil {
+		r.renderExec <- f
+	}
+}
+
+// clearExec returns the function which performs the clear inside renderExec,
+// or nil if there is nothing to clear.
+func (r *device) clearExec(rect image.Rectangle, bg gfx.Color, pre, post func()) func() bool {
 	// Clearing an empty rectangle is effectively no-op.
 	if rect.Empty() {
-		return
+		return nil
 	}
-	r.renderExec <- func() bool {
+	return func() bool {
 		if pre != nil {
 			pre()
 		}
@@ -205,6 +291,7 @@ func (r *device) hookedClear(rect image.Rectangle, bg gfx.Color, pre, post func(
 
 		// Color write mask effects the glClear call below.
 		r.graphicsState.ColorWrite(true, true, true, true)
+		r.graphicsState.pipeline = nil
 
 		// Perform clearing.
 		r.performScissor(rect)
@@ -221,11 +308,18 @@ func (r *device) hookedClear(rect image.Rectangle, bg gfx.Color, pre, post func(
 
 // Implements gfx.Canvas interface.
 func (r *device) hookedClearDepth(rect image.Rectangle, depth float64, pre, post func()) {
+	if f := r.clearDepthExec(rect, depth, pre, post); f != nil {
+		r.renderExec <- f
+	}
+}
+
+// clearDepthExec is like clearExec, except it performs a depth clear.
+func (r *device) clearDepthExec(rect image.Rectangle, depth float64, pre, post func()) func() bool {
 	// Clearing an empty rectangle is effectively no-op.
 	if rect.Empty() {
-		return
+		return nil
 	}
-	r.renderExec <- func() bool {
+	return func() bool {
 		if pre != nil {
 			pre()
 		}
@@ -233,6 +327,7 @@ func (r *device) hookedClearDepth(rect image.Rectangle, depth float64, pre, post
 
 		// Depth write mask effects the glClear call below.
 		r.graphicsState.DepthWrite(true)
+		r.graphicsState.pipeline = nil
 
 		// Perform clearing.
 		r.performScissor(rect)
@@ -249,11 +344,18 @@ func (r *device) hookedClearDepth(rect image.Rectangle, depth float64, pre, post
 
 // Implements gfx.Canvas interface.
 func (r *device) hookedClearStencil(rect image.Rectangle, stencil int, pre, post func()) {
+	if f := r.clearStencilExec(rect, stencil, pre, post); f != nil {
+		r.renderExec <- f
+	}
+}
+
+// clearStencilExec is like clearExec, except it performs a stencil clear.
+func (r *device) clearStencilExec(rect image.Rectangle, stencil int, pre, post func()) func() bool {
 	// Clearing an empty rectangle is effectively no-op.
 	if rect.Empty() {
-		return
+		return nil
 	}
-	r.renderExec <- func() bool {
+	return func() bool {
 		if pre != nil {
 			pre()
 		}
@@ -261,6 +363,7 @@ func (r *device) hookedClearStencil(rect image.Rectangle, stencil int, pre, post
 
 		// Stencil mask effects the glClear call below.
 		r.graphicsState.stencilMaskSeparate(0xFFFF, 0xFFFF)
+		r.graphicsState.pipeline = nil
 
 		// Perform clearing.
 		r.performScissor(rect)
@@ -356,6 +459,24 @@ func (r *device) hookedRender(pre, post func()) {
 			return false
 		}
 
+		// Finish profiling the frame.
+		r.profileEndFrame()
+
+		// Complete any asynchronous downloads from previous frames.
+		r.downloadEndFrame()
+
+		// Mark any streamed textures as loaded.
+		r.streamEndFrame()
+
+		// Stream mipmap levels in and out.
+		r.mipsEndFrame()
+
+		// Finish and begin frame captures.
+		r.captureEndFrame()
+
+		// Fence the frame for persistently mapped buffers.
+		r.persistEndFrame()
+
 		// Tick the clock.
 		r.clock.Tick()
 
@@ -364,6 +485,9 @@ func (r *device) hookedRender(pre, post func()) {
 		return true
 	}
 	<-r.renderComplete
+
+	// Notify of streamed textures which were marked as loaded.
+	r.streamNotify()
 }
 
 // Tries to receive pending occlusion query results, returns immediately if
@@ -373,6 +497,12 @@ func (r *device) queryYield() int {
 		return 0
 	}
 	r.pending.Lock()
+	if len(r.pending.queries) == 0 {
+		// Return early, such that the variables below are not allocated at
+		// each draw.
+		r.pending.Unlock()
+		return 0
+	}
 	var (
 		available, result int32
 		toRemove          []pendingQuery
@@ -450,10 +580,10 @@ func newDevice(opts ...Option) (Device, error) {
 		BaseCanvas: &util.BaseCanvas{
 			VMSAA: true,
 		},
-		warner:         util.NewWarner(nil),
+		warner:         util.NewWarner(logger.Writer(log.Warn)),
 		common:         glc.NewContext(),
 		clock:          clock.New(),
-		rsrcManager:    &rsrcManager{},
+		mem:            &memory{},
 		renderExec:     make(chan func() bool, 1024),
 		renderComplete: make(chan struct{}, 8),
 		wantFree:       make(chan struct{}, 1),
@@ -462,6 +592,11 @@ func newDevice(opts ...Option) (Device, error) {
 	r.graphicsState = &graphicsState{
 		GraphicsState: glc.NewGraphicsState(r.common),
 	}
+	r.rsrcManager = &rsrcManager{
+		mem:     r.mem,
+		state:   r.graphicsState,
+		persist: &r.persist,
+	}
 	go r.yield()
 
 	for _, opt := range opts {
@@ -509,6 +644,21 @@ func newDevice(opts ...Option) (Device, error) {
 	// Query whether we have the GL_ARB_occlusion_query extension.
 	r.glArbOcclusionQuery = exts.Present("GL_ARB_occlusion_query")
 
+	// Query for the debug label and group extensions.
+	r.debugLabelInit(exts)
+
+	// Query whether we have the GL_ARB_timer_query extension.
+	r.glArbTimerQuery = exts.Present("GL_ARB_timer_query")
+
+	// Query whether we have the GL_ARB_pixel_buffer_object extension.
+	r.glArbPixelBufferObject = exts.Present("GL_ARB_pixel_buffer_object")
+
+	// Query for the persistent mapping extensions.
+	r.persistInit(exts)
+
+	// Query whether we have the GL_ARB_half_float_vertex extension.
+	r.glArbHalfFloatVertex = exts.Present("GL_ARB_half_float_vertex")
+
 	// Query whether we have the GL_ARB_multisample extension.
 	r.glArbMultisample = exts.Present("GL_ARB_multisample")
 	if r.glArbMultisample {
@@ -520,7 +670,10 @@ func newDevice(opts ...Option) (Device, error) {
 
 	// Store GPU info.
 	var maxTextureSize, maxVaryingFloats, maxVertexInputs, maxFragmentInputs, occlusionQueryBits int32
+	var maxTextureUnits, maxVertexAttribs int32
 	gl.GetIntegerv(gl.MAX_TEXTURE_SIZE, &maxTextureSize)
+	gl.GetIntegerv(gl.MAX_TEXTURE_IMAGE_UNITS, &maxTextureUnits)
+	gl.GetIntegerv(gl.MAX_VERTEX_ATTRIBS, &maxVertexAttribs)
 	gl.GetIntegerv(gl.MAX_VARYING_FLOATS, &maxVaryingFloats)
 	gl.GetIntegerv(gl.MAX_VERTEX_UNIFORM_COMPONENTS, &maxVertexInputs)
 	gl.GetIntegerv(gl.MAX_FRAGMENT_UNIFORM_COMPONENTS, &maxFragmentInputs)
@@ -531,13 +684,31 @@ func newDevice(opts ...Option) (Device, error) {
 	// Collect GPU information.
 	r.devInfo.DepthClamp = exts.Present("GL_ARB_depth_clamp")
 	r.devInfo.MaxTextureSize = int(maxTextureSize)
+	r.devInfo.MaxTextures = int(maxTextureUnits)
+	r.devInfo.MaxVertexAttribs = int(maxVertexAttribs)
+	r.devInfo.MaxSamples = int(r.samples)
 	r.devInfo.AlphaToCoverage = r.glArbMultisample && r.samples > 0 && r.sampleBuffers > 0
 	r.devInfo.Name = gl.GoStr(gl.GetString(gl.RENDERER))
 	r.devInfo.Vendor = gl.GoStr(gl.GetString(gl.VENDOR))
 	r.devInfo.OcclusionQuery = r.glArbOcclusionQuery && occlusionQueryBits > 0
 	r.devInfo.OcclusionQueryBits = int(occlusionQueryBits)
+	r.devInfo.TimerQuery = r.glArbTimerQuery
 	r.devInfo.NPOT = exts.Present("GL_ARB_texture_non_power_of_two")
 	r.devInfo.TexWrapBorderColor = true
+	r.devInfo.SRGBTextures = exts.Present("GL_EXT_texture_sRGB")
+
+	// Query whether we have sRGB framebuffer support.
+	r.glFramebufferSRGB = exts.Present("GL_ARB_framebuffer_sRGB") || exts.Present("GL_EXT_framebuffer_sRGB")
+	r.devInfo.SRGBFramebuffer = r.glFramebufferSRGB
+
+	// Query anisotropic filtering and level of detail bias limits, the latter
+	// is core in OpenGL 1.4.
+	r.glExtTextureFilterAnisotropic = exts.Present("GL_EXT_texture_filter_anisotropic")
+	r.devInfo.MaxAnisotropy = 1
+	if r.glExtTextureFilterAnisotropic {
+		gl.GetFloatv(gl.MAX_TEXTURE_MAX_ANISOTROPY_EXT, &r.devInfo.MaxAnisotropy)
+	}
+	gl.GetFloatv(gl.MAX_TEXTURE_LOD_BIAS, &r.devInfo.MaxLODBias)
 
 	// OpenGL Information.
 	glInfo := &gfx.GLInfo{
@@ -555,6 +726,15 @@ func newDevice(opts ...Option) (Device, error) {
 	glslInfo.MajorVersion, glslInfo.MinorVersion, glslInfo.ReleaseVersion, _ = r.common.ShadingLanguageVersion()
 	r.devInfo.GLSL = glslInfo
 
+	// Query for program binary support, if a shader cache was requested.
+	r.shaderCacheInit(exts)
+
+	// Query for the indirect drawing and compute shader extensions.
+	r.indirectInit(exts)
+
+	// Query for the sampler objects extension.
+	r.samplerInit(exts)
+
 	if r.glArbFramebufferObject {
 		// See http://www.opengl.org/wiki/Image_Format for more information.
 		//
@@ -564,7 +744,6 @@ func newDevice(opts ...Option) (Device, error) {
 		//  GL_STENCIL_INDEX8 (looks like 4.3+ GL hardware)
 		//  GL_RGBA16F, GL_RGBA32F via Texture.Format
 		//  Compressed formats (DXT ?)
-		//  sRGB formats
 		//
 		//  GL_RGB16, GL_RGBA16
 
@@ -579,6 +758,9 @@ func newDevice(opts ...Option) (Device, error) {
 			gfx.RGB,
 			gfx.RGBA,
 		}...)
+		if r.devInfo.SRGBTextures {
+			fmts.ColorFormats = append(fmts.ColorFormats, gfx.SRGBA)
+		}
 		for _, cf := range fmts.ColorFormats {
 			r.rttTexFormats[cf] = convertTexFormat(cf)
 		}
@@ -608,6 +790,9 @@ func newDevice(opts ...Option) (Device, error) {
 		for i := 0; i < int(maxSamples); i++ {
 			fmts.Samples = append(fmts.Samples, i)
 		}
+		if int(maxSamples) > r.devInfo.MaxSamples {
+			r.devInfo.MaxSamples = int(maxSamples)
+		}
 
 		r.devInfo.RTTFormats = fmts
 	}
@@ -632,5 +817,14 @@ func newDevice(opts ...Option) (Device, error) {
 		r.compressedTextureFormats = make([]int32, numFormats)
 		gl.GetIntegerv(gl.COMPRESSED_TEXTURE_FORMATS, &r.compressedTextureFormats[0])
 	}
+	for _, f := range []gfx.TexFormat{gfx.DXT1, gfx.DXT1RGBA, gfx.DXT3, gfx.DXT5} {
+		glFormat := convertTexFormat(f)
+		for _, format := range r.compressedTextureFormats {
+			if format == glFormat {
+				r.devInfo.CompressedFormats = append(r.devInfo.CompressedFormats, f)
+				break
+			}
+		}
+	}
 	return r, nil
 }
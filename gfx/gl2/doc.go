@@ -92,6 +92,24 @@
 //  gfx.Color    -> vec4 (GLSL does not have a dedicated color type)
 //  gfx.TexCoord -> vec2 (GLSL does not have a dedicated texture coordinate type)
 //
+// As are the 32-bit lmath types:
+//
+//  lmath.Mat4f  -> mat4
+//  lmath.Vec4f  -> vec4
+//  lmath.Quatf  -> vec4 (components are W, X, Y, Z)
+//  lmath.Vec3f  -> vec3
+//  lmath.Vec2f  -> vec2
+//
+// Vertex attributes may additionally use the 16-bit lmath types, which halve
+// the memory used by e.g. normals or texture coordinates (they are converted
+// to 32-bit data before uploading if the GL_ARB_half_float_vertex extension is
+// not present):
+//
+//  lmath.Vec4h  -> vec4
+//  lmath.Vec3h  -> vec3
+//  lmath.Vec2h  -> vec2
+//  lmath.Half   -> float
+//
 // Slices are mapped directly to GLSL arrays, which can be fixed or dynamically
 // sized, standard GLSL restrictions apply (such as a lack of dynamic indexing
 // on dynamically sized arrays, etc).
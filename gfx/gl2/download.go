@@ -0,0 +1,179 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gl2
+
+import (
+	"encoding/binary"
+	"image"
+	"unsafe"
+
+	"azul3d.org/engine/gfx/internal/gl/2.0/gl"
+	"azul3d.org/engine/gfx/internal/glutil"
+	"azul3d.org/engine/gfx/internal/util"
+)
+
+// pendingDownload is a single asynchronous download whose pixels are being
+// read into a pixel buffer object.
+type pendingDownload struct {
+	pbo      uint32
+	img      image.Image
+	size     int
+	complete chan image.Image
+
+	// The frame during which the download was issued.
+	frame uint64
+}
+
+// downloader holds the asynchronous download state of a device. It is only
+// touched inside renderExec.
+type downloader struct {
+	// The number of frames rendered so far.
+	frame uint64
+
+	// Downloads which have not yet completed, in the order they were issued.
+	pending []pendingDownload
+
+	// Pixel buffer objects which are not in use, ready for reuse.
+	free []uint32
+}
+
+// DownloadAsync implements the gfx.AsyncDownloader interface.
+func (r *device) DownloadAsync(rect image.Rectangle, complete chan image.Image) {
+	r.hookedDownloadAsync(rect, complete, nil, nil)
+}
+
+// Implements gfx.AsyncDownloader interface.
+func (r *device) hookedDownloadAsync(rect image.Rectangle, complete chan image.Image, pre, post func()) {
+	if !r.glArbPixelBufferObject {
+		// Without pixel buffer objects we can only download synchronously.
+		r.hookedDownload(rect, complete, pre, post)
+		return
+	}
+
+	r.renderExec <- func() bool {
+		if pre != nil {
+			pre()
+		}
+
+		// Intersect the rectangle with the renderer's bounds.
+		bounds := r.Bounds()
+		rect = bounds.Intersect(rect)
+
+		img, pix, xtype := r.newDownloadImage(rect.Dx(), rect.Dy())
+		if len(pix) == 0 {
+			if post != nil {
+				post()
+			}
+			complete <- img
+			return false
+		}
+
+		// Grab a free PBO, or create a new one.
+		var pbo uint32
+		if n := len(r.download.free); n > 0 {
+			pbo = r.download.free[n-1]
+			r.download.free = r.download.free[:n-1]
+		} else {
+			gl.GenBuffers(1, &pbo)
+		}
+
+		// Read the pixels into the PBO, which returns immediately instead of
+		// waiting for rendering to finish.
+		gl.BindBuffer(gl.PIXEL_PACK_BUFFER, pbo)
+		gl.BufferData(gl.PIXEL_PACK_BUFFER, len(pix), nil, gl.STREAM_READ)
+		x, y, w, h := glutil.ConvertRect(rect, bounds)
+		gl.ReadPixels(
+			int32(x), int32(y), int32(w), int32(h),
+			gl.RGBA,
+			xtype,
+			nil, // Offset into the PBO.
+		)
+		gl.BindBuffer(gl.PIXEL_PACK_BUFFER, 0)
+
+		if post != nil {
+			post()
+		}
+
+		r.download.pending = append(r.download.pending, pendingDownload{
+			pbo:      pbo,
+			img:      img,
+			size:     len(pix),
+			complete: complete,
+			frame:    r.download.frame,
+		})
+		return false
+	}
+}
+
+// downloadEndFrame completes each pending download that was issued at least
+// one full frame ago, such that the GPU has (most likely) finished with it and
+// mapping it's PBO will not stall. It must be called inside renderExec.
+func (r *device) downloadEndFrame() {
+	r.download.frame++
+	for len(r.download.pending) > 0 {
+		d := r.download.pending[0]
+		if d.frame+2 > r.download.frame {
+			break
+		}
+		r.download.pending = r.download.pending[1:]
+
+		gl.BindBuffer(gl.PIXEL_PACK_BUFFER, d.pbo)
+		ptr := gl.MapBuffer(gl.PIXEL_PACK_BUFFER, gl.READ_ONLY)
+		if ptr == nil {
+			gl.BindBuffer(gl.PIXEL_PACK_BUFFER, 0)
+			r.warner.Warnf("DownloadAsync(): glMapBuffer() failed; returning nil\n")
+			r.download.free = append(r.download.free, d.pbo)
+			d.complete <- nil
+			continue
+		}
+		finishDownload(d.img, (*[1 << 30]byte)(ptr)[:d.size:d.size])
+		gl.UnmapBuffer(gl.PIXEL_PACK_BUFFER)
+		gl.BindBuffer(gl.PIXEL_PACK_BUFFER, 0)
+		r.download.free = append(r.download.free, d.pbo)
+		d.complete <- d.img
+	}
+}
+
+// newDownloadImage returns a new image of the given size to download pixels
+// of the current render target into, it's pixel data, and the type that the
+// pixels must be read as (in the gl.RGBA format).
+//
+// Render targets with more than 8 bits per color component (e.g. a window
+// with a 10-bit precision) are downloaded with 16 bits per component into a
+// *image.RGBA64, all others into a *image.RGBA. It must be called inside
+// renderExec, after the render target is bound.
+func (r *device) newDownloadImage(width, height int) (img image.Image, pix []byte, xtype uint32) {
+	prec := r.Precision()
+	if r.rttCanvas != nil {
+		prec = r.rttCanvas.Precision()
+	}
+	rect := image.Rect(0, 0, width, height)
+	if prec.RedBits > 8 || prec.GreenBits > 8 || prec.BlueBits > 8 || prec.AlphaBits > 8 {
+		m := image.NewRGBA64(rect)
+		return m, m.Pix, gl.UNSIGNED_SHORT
+	}
+	m := image.NewRGBA(rect)
+	return m, m.Pix, gl.UNSIGNED_BYTE
+}
+
+// finishDownload copies the pixels read from the render target into the image
+// returned by newDownloadImage (src may be it's own pixel data), and flips the
+// image vertically as OpenGL's origin is the bottom-left corner.
+func finishDownload(img image.Image, src []byte) {
+	switch m := img.(type) {
+	case *image.RGBA:
+		copy(m.Pix, src)
+		util.VerticalFlip(m)
+	case *image.RGBA64:
+		// OpenGL gives us 16-bit values in the native byte order, but the
+		// image stores them in big-endian order.
+		n := len(src) / 2
+		values := (*[1 << 29]uint16)(unsafe.Pointer(&src[0]))[:n:n]
+		for i, v := range values {
+			binary.BigEndian.PutUint16(m.Pix[i*2:], v)
+		}
+		util.VerticalFlip64(m)
+	}
+}
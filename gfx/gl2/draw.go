@@ -8,11 +8,13 @@ import (
 	"fmt"
 	"image"
 	"reflect"
+	"unsafe"
 
 	"azul3d.org/engine/gfx"
 	"azul3d.org/engine/gfx/internal/gl/2.0/gl"
 	"azul3d.org/engine/gfx/internal/glutil"
 	"azul3d.org/engine/gfx/internal/util"
+	"azul3d.org/engine/lmath"
 )
 
 var (
@@ -40,17 +42,25 @@ func (n *nativeObject) SampleCount() int {
 func (n *nativeObject) Destroy() {}
 
 func (r *device) hookedDraw(rect image.Rectangle, o *gfx.Object, c gfx.Camera, pre, post func()) {
+	// Ask the render loop to perform drawing.
+	if f := r.drawExec(rect, o, c, pre, post); f != nil {
+		r.renderExec <- f
+	}
+}
+
+// drawExec loads the resources of the object and returns the function which
+// draws it inside renderExec, or nil if the object should not be drawn.
+func (r *device) drawExec(rect image.Rectangle, o *gfx.Object, c gfx.Camera, pre, post func()) func() bool {
 	doDraw, err := util.PreDraw(r, rect, o, c)
 	if err != nil {
 		r.warner.Warnf("%v\n", err)
-		return
+		return nil
 	}
 	if !doDraw {
-		return
+		return nil
 	}
 
-	// Ask the render loop to perform drawing.
-	r.renderExec <- func() bool {
+	return func() bool {
 		// Give the object a native object.
 		if o.NativeObject == nil {
 			o.NativeObject = &nativeObject{
@@ -62,6 +72,11 @@ func (r *device) hookedDraw(rect image.Rectangle, o *gfx.Object, c gfx.Camera, p
 			pre()
 		}
 
+		// Wrap the draw in a debug group named after the object.
+		if len(o.Label) > 0 {
+			r.pushDebugGroup(o.Label)
+		}
+
 		// Set global GL state.
 		r.graphicsState.Begin(r)
 
@@ -69,16 +84,20 @@ func (r *device) hookedDraw(rect image.Rectangle, o *gfx.Object, c gfx.Camera, p
 		r.performScissor(rect)
 
 		var ns *nativeShader
-		if o.NativeShader != nil {
-			ns = o.NativeShader.(*nativeShader)
+		if shader, _ := o.DrawState(); shader.NativeShader != nil {
+			ns = shader.NativeShader.(*nativeShader)
 		}
 
 		// Use the object's state.
 		r.useState(ns, o, c)
 
-		// Draw each mesh.
-		for _, m := range o.Meshes {
-			r.drawMesh(ns, m)
+		// Draw each mesh, or the draw commands of the first one.
+		if o.Indirect != nil && len(o.Meshes) > 0 {
+			r.drawIndirect(ns, o)
+		} else {
+			for _, m := range o.Meshes {
+				r.drawMesh(ns, m)
+			}
 		}
 
 		// Clear the object's state.
@@ -87,6 +106,10 @@ func (r *device) hookedDraw(rect image.Rectangle, o *gfx.Object, c gfx.Camera, p
 		// Yield for occlusion query results, if any are available.
 		r.queryYield()
 
+		if len(o.Label) > 0 {
+			r.popDebugGroup()
+		}
+
 		if post != nil {
 			post()
 		}
@@ -104,6 +127,19 @@ func (r *device) updateUniform(native *nativeShader, name string, value interfac
 		return
 	}
 
+	// Uniform values are kept by the program, so skip uploading a value it
+	// already has.
+	if uniformCacheable(value) {
+		if !r.graphicsState.Guard(native.uniforms[location] != value) {
+			return
+		}
+		if native.uniforms == nil {
+			native.uniforms = make(map[int32]interface{})
+		}
+		native.uniforms[location] = value
+	}
+	delete(native.matrices, location)
+
 	switch v := value.(type) {
 	case texSlot:
 		// Special case: Texture input uniform.
@@ -114,10 +150,10 @@ func (r *device) updateUniform(native *nativeShader, name string, value interfac
 		if v {
 			intBool = 1
 		}
-		gl.Uniform1iv(location, 1, &intBool)
+		gl.Uniform1i(location, intBool)
 
 	case float32:
-		gl.Uniform1fv(location, 1, &v)
+		gl.Uniform1fv(location, 1, r.scratch(unsafe.Pointer(&v), 1))
 
 	case []float32:
 		if len(v) > 0 {
@@ -125,7 +161,7 @@ func (r *device) updateUniform(native *nativeShader, name string, value interfac
 		}
 
 	case gfx.TexCoord:
-		gl.Uniform2fv(location, 1, &v.U)
+		gl.Uniform2fv(location, 1, r.scratch(unsafe.Pointer(&v), 2))
 
 	case []gfx.TexCoord:
 		if len(v) > 0 {
@@ -133,7 +169,7 @@ func (r *device) updateUniform(native *nativeShader, name string, value interfac
 		}
 
 	case gfx.Vec3:
-		gl.Uniform3fv(location, 1, &v.X)
+		gl.Uniform3fv(location, 1, r.scratch(unsafe.Pointer(&v), 3))
 
 	case []gfx.Vec3:
 		if len(v) > 0 {
@@ -141,7 +177,7 @@ func (r *device) updateUniform(native *nativeShader, name string, value interfac
 		}
 
 	case gfx.Vec4:
-		gl.Uniform4fv(location, 1, &v.X)
+		gl.Uniform4fv(location, 1, r.scratch(unsafe.Pointer(&v), 4))
 
 	case []gfx.Vec4:
 		if len(v) > 0 {
@@ -149,7 +185,7 @@ func (r *device) updateUniform(native *nativeShader, name string, value interfac
 		}
 
 	case gfx.Color:
-		gl.Uniform4fv(location, 1, &v.R)
+		gl.Uniform4fv(location, 1, r.scratch(unsafe.Pointer(&v), 4))
 
 	case []gfx.Color:
 		if len(v) > 0 {
@@ -157,19 +193,100 @@ func (r *device) updateUniform(native *nativeShader, name string, value interfac
 		}
 
 	case gfx.Mat4:
-		gl.UniformMatrix4fv(location, 1, false, &v[0][0])
+		gl.UniformMatrix4fv(location, 1, false, r.scratch(unsafe.Pointer(&v), 16))
 
 	case []gfx.Mat4:
 		if len(v) > 0 {
 			gl.UniformMatrix4fv(location, int32(len(v)), false, &v[0][0][0])
 		}
 
+	case lmath.Vec2f:
+		gl.Uniform2fv(location, 1, r.scratch(unsafe.Pointer(&v), 2))
+
+	case []lmath.Vec2f:
+		if len(v) > 0 {
+			gl.Uniform2fv(location, int32(len(v)), &v[0].X)
+		}
+
+	case lmath.Vec3f:
+		gl.Uniform3fv(location, 1, r.scratch(unsafe.Pointer(&v), 3))
+
+	case []lmath.Vec3f:
+		if len(v) > 0 {
+			gl.Uniform3fv(location, int32(len(v)), &v[0].X)
+		}
+
+	case lmath.Vec4f:
+		gl.Uniform4fv(location, 1, r.scratch(unsafe.Pointer(&v), 4))
+
+	case []lmath.Vec4f:
+		if len(v) > 0 {
+			gl.Uniform4fv(location, int32(len(v)), &v[0].X)
+		}
+
+	case lmath.Quatf:
+		gl.Uniform4fv(location, 1, r.scratch(unsafe.Pointer(&v), 4))
+
+	case []lmath.Quatf:
+		if len(v) > 0 {
+			gl.Uniform4fv(location, int32(len(v)), &v[0].W)
+		}
+
+	case lmath.Mat4f:
+		gl.UniformMatrix4fv(location, 1, false, r.scratch(unsafe.Pointer(&v), 16))
+
+	case []lmath.Mat4f:
+		if len(v) > 0 {
+			gl.UniformMatrix4fv(location, int32(len(v)), false, &v[0][0][0])
+		}
+
 	default:
 		r.warner.Warnf("Shader input %q uses an invalid shader input data type %q, ignoring.\n", name, reflect.TypeOf(value))
 		// We don't know of the type at all, ignore it.
 	}
 }
 
+// uniformCacheable reports whether or not the given uniform value may be
+// cached by updateUniform. Slices are not, as comparing them costs about as
+// much as uploading them.
+func uniformCacheable(value interface{}) bool {
+	switch value.(type) {
+	case texSlot, bool, float32, gfx.TexCoord, gfx.Vec3, gfx.Vec4, gfx.Color,
+		gfx.Mat4, lmath.Vec2f, lmath.Vec3f, lmath.Vec4f, lmath.Quatf, lmath.Mat4f:
+		return true
+	}
+	return false
+}
+
+// updateMatrixUniform is like updateUniform, except that the matrix is not
+// boxed in an interface (which is a heap allocation). It is used for the
+// object matrices, which are updated at each draw.
+func (r *device) updateMatrixUniform(native *nativeShader, name string, m *gfx.Mat4) {
+	location := int32(native.LocationCache.FindUniform(name))
+	if location == -1 {
+		return
+	}
+	last, ok := native.matrices[location]
+	if !r.graphicsState.Guard(!ok || last != *m) {
+		return
+	}
+	if native.matrices == nil {
+		native.matrices = make(map[int32]gfx.Mat4)
+	}
+	native.matrices[location] = *m
+	delete(native.uniforms, location)
+	gl.UniformMatrix4fv(location, 1, false, &m[0][0])
+}
+
+// scratch copies n float32 values from p into the uniform scratch space, and
+// returns a pointer to it. Uploading values from there instead of from the
+// value itself keeps the value from escaping to the heap. It must be called
+// inside renderExec.
+func (r *device) scratch(p unsafe.Pointer, n int) *float32 {
+	copy(r.uniformScratch[:n], (*[16]float32)(p)[:n])
+	return &r.uniformScratch[0]
+}
+
 func (r *device) beginQuery(o *gfx.Object, n *nativeObject) {
 	if r.glArbOcclusionQuery && o.OcclusionTest {
 		gl.GenQueries(1, &n.pendingQuery)
@@ -189,28 +306,26 @@ func (r *device) endQuery(o *gfx.Object, n *nativeObject) {
 }
 
 func (r *device) useState(ns *nativeShader, obj *gfx.Object, c gfx.Camera) {
-	// Use object state.
-	r.graphicsState.ColorWrite(obj.WriteRed, obj.WriteGreen, obj.WriteBlue, obj.WriteAlpha)
-	r.graphicsState.Dithering(obj.Dithering)
-	r.graphicsState.StencilTest(obj.StencilTest)
-	r.graphicsState.StencilOpSeparate(obj.StencilFront, obj.StencilBack)
-	r.graphicsState.stencilFuncSeparate(obj.StencilFront, obj.StencilBack)
-	r.graphicsState.stencilMaskSeparate(obj.StencilFront.WriteMask, obj.StencilBack.WriteMask)
-	if r.devInfo.DepthClamp {
-		r.graphicsState.depthClamp(obj.DepthClamp)
+	shader, state := obj.DrawState()
+
+	// Use the fixed-function state. That of a loaded pipeline was validated
+	// when it was loaded, and need not be set again at all if it was the last
+	// one used.
+	var np *nativePipeline
+	if obj.Pipeline != nil && obj.Pipeline.NativePipeline != nil {
+		np = obj.Pipeline.NativePipeline.(*nativePipeline)
+		state = &np.state
+	}
+	if np == nil || r.graphicsState.Guard(r.graphicsState.pipeline != np) {
+		r.useFixedState(state)
+		r.graphicsState.pipeline = np
 	}
-	r.graphicsState.DepthCmp(obj.DepthCmp)
-	r.graphicsState.DepthTest(obj.DepthTest)
-	r.graphicsState.DepthWrite(obj.DepthWrite)
-	r.graphicsState.FaceCulling(obj.FaceCulling)
 
 	// Begin using the shader.
-	shader := obj.Shader
 	r.graphicsState.useProgram(ns.program)
 
 	// Update shader inputs.
-	for name := range shader.Inputs {
-		value := shader.Inputs[name]
+	for name, value := range shader.Inputs {
 		r.updateUniform(ns, name, value)
 	}
 
@@ -219,23 +334,13 @@ func (r *device) useState(ns *nativeShader, obj *gfx.Object, c gfx.Camera) {
 	nativeObj.MVPCache.Update(obj, c)
 
 	// Add the matrix inputs for the object.
-	r.updateUniform(ns, "Model", nativeObj.MVPCache.Model)
-	r.updateUniform(ns, "View", nativeObj.MVPCache.View)
-	r.updateUniform(ns, "Projection", nativeObj.MVPCache.Projection)
-	r.updateUniform(ns, "MVP", nativeObj.MVPCache.MVP)
+	r.updateMatrixUniform(ns, "Model", &nativeObj.MVPCache.Model)
+	r.updateMatrixUniform(ns, "View", &nativeObj.MVPCache.View)
+	r.updateMatrixUniform(ns, "Projection", &nativeObj.MVPCache.Projection)
+	r.updateMatrixUniform(ns, "MVP", &nativeObj.MVPCache.MVP)
 
 	// Set alpha mode.
-	if r.devInfo.AlphaToCoverage {
-		r.graphicsState.SampleAlphaToCoverage(obj.AlphaMode == gfx.AlphaToCoverage)
-	}
-	r.graphicsState.Blend(obj.AlphaMode == gfx.AlphaBlend)
-	if obj.AlphaMode == gfx.AlphaBlend {
-		r.graphicsState.BlendColor(obj.Blend.Color)
-		r.graphicsState.BlendFuncSeparate(obj.Blend)
-		r.graphicsState.BlendEquationSeparate(obj.Blend)
-	}
-
-	switch obj.AlphaMode {
+	switch state.AlphaMode {
 	case gfx.NoAlpha, gfx.AlphaBlend:
 		r.updateUniform(ns, "BinaryAlpha", false)
 
@@ -257,33 +362,31 @@ func (r *device) useState(ns *nativeShader, obj *gfx.Object, c gfx.Camera) {
 			}
 		}
 
-		nt := t.NativeTexture.(*nativeTexture)
+		nt, ok := t.NativeTexture.(*nativeTexture)
+		if !ok {
+			// The texture is still streaming, use the placeholder instead.
+			nt = r.streamPlaceholder()
+		}
 
-		gl.ActiveTexture(gl.TEXTURE0 + uint32(i))
-		gl.BindTexture(gl.TEXTURE_2D, nt.id)
+		r.graphicsState.activeTexture(uint32(i))
+		r.graphicsState.bindTexture(nt.id)
 
-		// Load wrap mode.
-		uWrap := int32(r.common.ConvertTexWrap(t.WrapU))
-		vWrap := int32(r.common.ConvertTexWrap(t.WrapV))
-		if t.WrapU == gfx.BorderColor || t.WrapV == gfx.BorderColor {
-			// We must specify the actual border color then.
-			gl.TexParameterfv(gl.TEXTURE_2D, gl.TEXTURE_BORDER_COLOR, &t.BorderColor.R)
+		// Determine the sampling state, the object's sampler overrides the
+		// texture's own. Without sampler objects (OpenGL 2 lacks them) we
+		// emulate them by setting the texture's state, unless it already has
+		// that state.
+		s := t.Sampler()
+		if i < len(obj.Samplers) && obj.Samplers[i] != nil {
+			s = *obj.Samplers[i]
 		}
-		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, uWrap)
-		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, vWrap)
-
-		// Load filter.
-		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, int32(r.common.ConvertTexFilter(t.MinFilter)))
-		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, int32(r.common.ConvertTexFilter(t.MagFilter)))
-
-		// If we do not want mipmapping, turn it off. Note that only the
-		// minification filter can be mipmapped (mag filter can never be).
-		if t.MinFilter.Mipmapped() {
-			gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_BASE_LEVEL, 0)
-			gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAX_LEVEL, 1000)
-		} else {
-			gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_BASE_LEVEL, 0)
-			gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAX_LEVEL, 0)
+		if r.samplers.supported {
+			r.graphicsState.bindSampler(uint32(i), r.samplerObject(s))
+		} else if r.graphicsState.Guard(nt.sampler == nil || *nt.sampler != s) {
+			r.useSampler(s)
+
+			// Copy it, such that s does not escape to the heap at each draw.
+			cpy := s
+			nt.sampler = &cpy
 		}
 
 		// Add uniform input.
@@ -294,36 +397,121 @@ func (r *device) useState(ns *nativeShader, obj *gfx.Object, c gfx.Camera) {
 	r.beginQuery(obj, nativeObj)
 }
 
+// useFixedState sets the fixed-function state used for drawing to the given
+// one.
+func (r *device) useFixedState(s *gfx.State) {
+	r.graphicsState.ColorWrite(s.WriteRed, s.WriteGreen, s.WriteBlue, s.WriteAlpha)
+	r.graphicsState.Dithering(s.Dithering)
+	r.graphicsState.StencilTest(s.StencilTest)
+	r.graphicsState.StencilOpSeparate(s.StencilFront, s.StencilBack)
+	r.graphicsState.stencilFuncSeparate(s.StencilFront, s.StencilBack)
+	r.graphicsState.stencilMaskSeparate(s.StencilFront.WriteMask, s.StencilBack.WriteMask)
+	if r.devInfo.DepthClamp {
+		r.graphicsState.depthClamp(s.DepthClamp)
+	}
+	r.graphicsState.DepthCmp(s.DepthCmp)
+	r.graphicsState.DepthTest(s.DepthTest)
+	r.graphicsState.DepthWrite(s.DepthWrite)
+	r.graphicsState.FaceCulling(s.FaceCulling)
+
+	// Set alpha mode.
+	if r.devInfo.AlphaToCoverage {
+		r.graphicsState.SampleAlphaToCoverage(s.AlphaMode == gfx.AlphaToCoverage)
+	}
+	r.graphicsState.Blend(s.AlphaMode == gfx.AlphaBlend)
+	if s.AlphaMode == gfx.AlphaBlend {
+		r.graphicsState.BlendColor(s.Blend.Color)
+		r.graphicsState.BlendFuncSeparate(s.Blend)
+		r.graphicsState.BlendEquationSeparate(s.Blend)
+	}
+}
+
+// useSampler sets the sampling state of the texture bound to the active
+// texture unit.
+func (r *device) useSampler(s gfx.Sampler) {
+	// Load wrap mode.
+	uWrap := int32(r.common.ConvertTexWrap(s.WrapU))
+	vWrap := int32(r.common.ConvertTexWrap(s.WrapV))
+	if s.WrapU == gfx.BorderColor || s.WrapV == gfx.BorderColor {
+		// We must specify the actual border color then.
+		gl.TexParameterfv(gl.TEXTURE_2D, gl.TEXTURE_BORDER_COLOR, r.scratch(unsafe.Pointer(&s.BorderColor), 4))
+	}
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, uWrap)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, vWrap)
+
+	// Load filter.
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, int32(r.common.ConvertTexFilter(s.MinFilter)))
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, int32(r.common.ConvertTexFilter(s.MagFilter)))
+
+	// If we do not want mipmapping, turn it off. Note that only the
+	// minification filter can be mipmapped (mag filter can never be).
+	if s.MinFilter.Mipmapped() {
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_BASE_LEVEL, 0)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAX_LEVEL, 1000)
+	} else {
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_BASE_LEVEL, 0)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAX_LEVEL, 0)
+	}
+
+	// Load anisotropy and level of detail bias.
+	if r.glExtTextureFilterAnisotropic {
+		gl.TexParameterf(gl.TEXTURE_2D, gl.TEXTURE_MAX_ANISOTROPY_EXT, clampf(s.MaxAnisotropy, 1, r.devInfo.MaxAnisotropy))
+	}
+	if r.devInfo.MaxLODBias > 0 {
+		gl.TexParameterf(gl.TEXTURE_2D, gl.TEXTURE_LOD_BIAS, clampf(s.LODBias, -r.devInfo.MaxLODBias, r.devInfo.MaxLODBias))
+	}
+}
+
+// clampf returns v clamped to the range [min, max].
+func clampf(v, min, max float32) float32 {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
 func (r *device) clearState(ns *nativeShader, obj *gfx.Object) {
 	// End occlusion query.
 	r.endQuery(obj, obj.NativeObject.(*nativeObject))
 
-	// Use no texture.
-	gl.BindTexture(gl.TEXTURE_2D, 0)
-	gl.ActiveTexture(gl.TEXTURE0)
+	// Textures, buffers and vertex attribute arrays are left bound, such that
+	// consecutive draws using the same ones need not bind them again.
 }
 
 func (r *device) drawMesh(ns *nativeShader, m *gfx.Mesh) {
+	native := r.useMesh(ns, m)
+	if native.indicesCount > 0 {
+		// Draw indexed mesh.
+		r.graphicsState.bindBuffer(gl.ELEMENT_ARRAY_BUFFER, native.indices)
+		gl.DrawElements(uint32(r.common.ConvertPrimitive(m.Primitive)), native.indicesCount, gl.UNSIGNED_INT, gl.PtrOffset(r.persistOffset(native, native.indices)))
+	} else {
+		// Draw regular mesh.
+		gl.DrawArrays(uint32(r.common.ConvertPrimitive(m.Primitive)), 0, native.verticesCount)
+	}
+	r.profile.drawCalls++
+}
+
+// useMesh sources the vertex attributes of the given shader from the given
+// mesh, and returns it's native mesh.
+func (r *device) useMesh(ns *nativeShader, m *gfx.Mesh) *nativeMesh {
 	// Grab the native mesh.
 	native := m.NativeMesh.(*nativeMesh)
 
 	// Use vertices data.
+	g := r.graphicsState
 	location := ns.LocationCache.FindAttrib("Vertex")
 	if location != -1 {
-		gl.BindBuffer(gl.ARRAY_BUFFER, native.vertices)
-		gl.EnableVertexAttribArray(uint32(location))
-		defer gl.DisableVertexAttribArray(uint32(location))
-		gl.VertexAttribPointer(uint32(location), 3, gl.FLOAT, false, 0, nil)
+		g.attribPointer(uint32(location), native.vertices, 3, gl.FLOAT, r.persistOffset(native, native.vertices))
 	}
 
 	// Use each texture coordinate set data.
 	for index, texCoords := range native.texCoords {
 		location = ns.LocationCache.FindAttrib(texCoordIndex.Name(index))
 		if location != -1 {
-			gl.BindBuffer(gl.ARRAY_BUFFER, texCoords)
-			gl.EnableVertexAttribArray(uint32(location))
-			defer gl.DisableVertexAttribArray(uint32(location))
-			gl.VertexAttribPointer(uint32(location), 2, gl.FLOAT, false, 0, nil)
+			g.attribPointer(uint32(location), texCoords, 2, gl.FLOAT, r.persistOffset(native, texCoords))
 		}
 	}
 
@@ -342,26 +530,14 @@ func (r *device) drawMesh(ns *nativeShader, m *gfx.Mesh) {
 				continue
 			}
 
-			// Bind the buffer, send each row.
-			gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
+			// Source each row from the buffer.
 			for row := uint32(0); row < attrib.rows; row++ {
-				l := uint32(location) + row
-				gl.EnableVertexAttribArray(l)
-				defer gl.DisableVertexAttribArray(l)
-				gl.VertexAttribPointer(l, attrib.size, gl.FLOAT, false, 0, nil)
+				g.attribPointer(uint32(location)+row, vbo, attrib.size, attrib.typ, r.persistOffset(native, vbo))
 			}
 		}
 	}
 
-	if native.indicesCount > 0 {
-		// Draw indexed mesh.
-		gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, native.indices)
-		gl.DrawElements(uint32(r.common.ConvertPrimitive(m.Primitive)), native.indicesCount, gl.UNSIGNED_INT, nil)
-	} else {
-		// Draw regular mesh.
-		gl.DrawArrays(uint32(r.common.ConvertPrimitive(m.Primitive)), 0, native.verticesCount)
-	}
-
-	// Unbind buffer to avoid carrying OpenGL state.
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+	// Disable vertex attribute arrays used by previous draws but not this one.
+	g.useAttribs()
+	return native
 }
@@ -0,0 +1,101 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gl2
+
+import (
+	"fmt"
+	"sync"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/internal/gl/2.0/gl"
+)
+
+// recorder implements the gl.Tracer interface, recording each call into a
+// frame capture.
+type recorder struct {
+	sync.Mutex
+	capture *gfx.FrameCapture
+	depth   int
+}
+
+// Call implements the gl.Tracer interface.
+func (r *recorder) Call(name string, args []interface{}, result interface{}) {
+	r.Lock()
+	switch name {
+	case "glPopDebugGroup", "glPopGroupMarkerEXT":
+		if r.depth > 0 {
+			r.depth--
+		}
+	}
+	r.capture.Calls = append(r.capture.Calls, gfx.CapturedCall{
+		Name:   name,
+		Args:   args,
+		Result: result,
+		Depth:  r.depth,
+	})
+	switch name {
+	case "glPushDebugGroup", "glPushGroupMarkerEXT":
+		r.depth++
+	}
+	r.Unlock()
+}
+
+// capturer holds the frame capture state of a device.
+type capturer struct {
+	sync.Mutex
+
+	// Channels of pending capture requests, waiting for the current frame to
+	// end.
+	requests []chan *gfx.FrameCapture
+
+	// The recorder of the frame currently being captured (or nil), and the
+	// requests waiting for it. Only touched inside renderExec.
+	rec     *recorder
+	waiting []chan *gfx.FrameCapture
+}
+
+// CaptureFrame implements the gfx.FrameCapturer interface.
+func (r *device) CaptureFrame() <-chan *gfx.FrameCapture {
+	ch := make(chan *gfx.FrameCapture, 1)
+	r.capture.Lock()
+	r.capture.requests = append(r.capture.requests, ch)
+	r.capture.Unlock()
+	return ch
+}
+
+// captureEndFrame finishes the frame currently being captured, if any, and
+// begins capturing the next one if it has been requested. It must be called
+// inside renderExec at the end of each frame.
+//
+// The tracer is global to the OpenGL bindings, so calls made by a shared
+// device (i.e. asset loading) during the frame are captured as well.
+func (r *device) captureEndFrame() {
+	if rec := r.capture.rec; rec != nil {
+		gl.SetTracer(nil)
+		rec.Lock()
+		c := rec.capture
+		rec.Unlock()
+		for _, ch := range r.capture.waiting {
+			ch <- c
+		}
+		r.capture.rec = nil
+		r.capture.waiting = nil
+	}
+
+	r.capture.Lock()
+	requests := r.capture.requests
+	r.capture.requests = nil
+	r.capture.Unlock()
+	if len(requests) == 0 {
+		return
+	}
+	r.capture.waiting = requests
+	r.capture.rec = &recorder{
+		capture: &gfx.FrameCapture{
+			Device: fmt.Sprintf("%s (%s)", r.devInfo.Name, r.devInfo.GL),
+		},
+	}
+	gl.SetTracer(r.capture.rec)
+}
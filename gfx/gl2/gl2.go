@@ -8,6 +8,7 @@ import (
 	"errors"
 	"image"
 	"io"
+	"sync/atomic"
 
 	"azul3d.org/engine/gfx"
 )
@@ -45,6 +46,9 @@ type Device interface {
 	// SetDebugOutput sets the writer, w, to write debug output to. It will
 	// mostly contain just shader debug information, but other information may
 	// be written in future versions as well.
+	//
+	// By default (or if w is nil) debug output is logged as warnings with the
+	// "gfx" tag, see the azul3d.org/engine/log package.
 	SetDebugOutput(w io.Writer)
 
 	// RestoreState immediately restores the OpenGL state to what it was before
@@ -84,9 +88,13 @@ type Option func(d *device)
 //
 // The given other device must be from this package specifically, or else a
 // panic will occur.
+//
+// Meshes loaded by the other device from then on do not use persistently
+// mapped buffers (see gfx.DeviceInfo.PersistentMapping).
 func Share(other Device) Option {
 	return func(d *device) {
 		d.shared.device = other.(*device)
+		atomic.StoreInt32(&d.shared.device.persist.shared, 1)
 	}
 }
 
@@ -0,0 +1,351 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gl2
+
+import (
+	"runtime"
+	"unsafe"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/internal/gl/2.0/gl"
+	"azul3d.org/engine/gfx/internal/glutil"
+	"azul3d.org/engine/lmath"
+)
+
+// cullGroupSize is the number of draw commands culled by each work group of
+// the culling shader.
+const cullGroupSize = 64
+
+// cullSource is the GLSL source of the compute shader which culls the
+// bounding spheres of draw commands against the view frustum (given as six
+// planes in the object's space), copying the source commands into the buffer
+// drawn from with the instance count of each culled command zeroed.
+const cullSource = `#version 430
+layout(local_size_x = 64) in;
+
+struct Command {
+	uint count;
+	uint instanceCount;
+	uint firstIndex;
+	int baseVertex;
+	uint baseInstance;
+};
+
+layout(std430, binding = 0) readonly buffer Source {
+	Command source[];
+};
+
+layout(std430, binding = 1) readonly buffer Bounds {
+	vec4 bounds[];
+};
+
+layout(std430, binding = 2) writeonly buffer Draw {
+	Command draw[];
+};
+
+uniform vec4 Planes[6];
+uniform int Count;
+
+void main(void) {
+	uint i = gl_GlobalInvocationID.x;
+	if (i >= uint(Count)) {
+		return;
+	}
+	Command c = source[i];
+	vec4 s = bounds[i];
+	for (int p = 0; p < 6; p++) {
+		if (dot(Planes[p].xyz, s.xyz) + Planes[p].w < -s.w) {
+			c.instanceCount = 0u;
+		}
+	}
+	draw[i] = c;
+}
+`
+
+// indirector holds the indirect drawing state of a device. Everything except
+// for the supported fields is only touched inside renderExec.
+type indirector struct {
+	// Whether or not multi-draw-indirect and compute shaders (with shader
+	// storage buffers) are supported.
+	draw, compute bool
+
+	// The glDrawElementsInstanced function (or it's ARB equivalent) for
+	// drawing the commands one at a time, or nil if instancing is not
+	// supported.
+	drawInstanced func(mode uint32, count int32, xtype uint32, indices unsafe.Pointer, instances int32)
+
+	// The culling program and the locations of it's uniforms, created at the
+	// first use.
+	program       uint32
+	planes, count int32
+
+	// Scratch space for the commands culled on the CPU.
+	scratch []gfx.DrawCommand
+}
+
+// indirectInit queries for the indirect drawing, instanced drawing and
+// compute shader extensions.
+func (r *device) indirectInit(exts glutil.Extensions) {
+	r.indirect.draw = exts.Present("GL_ARB_draw_indirect") &&
+		exts.Present("GL_ARB_multi_draw_indirect")
+
+	// The culling shader is written in GLSL 4.30.
+	glsl := r.devInfo.GLSL
+	r.indirect.compute = r.indirect.draw &&
+		exts.Present("GL_ARB_compute_shader") &&
+		exts.Present("GL_ARB_shader_storage_buffer_object") &&
+		(glsl.MajorVersion > 4 || glsl.MajorVersion == 4 && glsl.MinorVersion >= 30)
+
+	// Instanced drawing is core since OpenGL 3.1, older versions may provide
+	// it through the GL_ARB_draw_instanced extension.
+	v := r.devInfo.GL
+	switch {
+	case v.MajorVersion > 3 || v.MajorVersion == 3 && v.MinorVersion >= 1:
+		r.indirect.drawInstanced = gl.DrawElementsInstanced
+	case exts.Present("GL_ARB_draw_instanced"):
+		r.indirect.drawInstanced = gl.DrawElementsInstancedARB
+	}
+
+	r.devInfo.DrawIndirect = r.indirect.draw
+	r.devInfo.ComputeCulling = r.indirect.compute
+	r.devInfo.Instancing = r.indirect.drawInstanced != nil
+}
+
+// nativeIndirect is the native object of a gfx.Indirect, used only by devices
+// which support multi-draw-indirect.
+type nativeIndirect struct {
+	// The buffer of the commands drawn from. If the commands are culled on the
+	// GPU, the buffers of the source commands and their bounds which the
+	// culling shader reads (zero otherwise).
+	draw, source, bounds uint32
+
+	// The number of commands.
+	count int32
+
+	r *rsrcManager
+}
+
+// Destroy implements the gfx.Destroyable interface.
+func (n *nativeIndirect) Destroy() {
+	finalizeIndirect(n)
+}
+
+// finalizeIndirect is the finalizer called to free the native indirect
+// buffer. It must be free'd in the presence of the OpenGL context, and thus
+// we queue it to be free'd at the next available time (next frame).
+func finalizeIndirect(n *nativeIndirect) {
+	n.r.Lock()
+	if n.draw == 0 {
+		// Already free'd.
+		n.r.Unlock()
+		return
+	}
+	n.r.indirects = append(n.r.indirects, n)
+	n.r.Unlock()
+}
+
+// free literally frees the native indirect buffer right now, accounting for
+// the freed memory. It may only be called under the presence of the OpenGL
+// context.
+func (n *nativeIndirect) free(mem *memory) {
+	for _, id := range []uint32{n.draw, n.source, n.bounds} {
+		if id != 0 {
+			mem.setBuffer(id, 0)
+		}
+	}
+	n.r.state.deleteBuffers(n.draw, n.source, n.bounds)
+	*n = nativeIndirect{
+		r: n.r,
+	}
+}
+
+// bufferData replaces the data store of the given buffer (creating it, if
+// *id is zero) with size bytes of the given data, which may be nil.
+func (r *device) bufferData(target uint32, id *uint32, size int, data unsafe.Pointer, usage uint32) {
+	if *id == 0 {
+		gl.GenBuffers(1, id)
+	}
+	gl.BindBuffer(target, *id)
+	gl.BufferData(target, size, data, usage)
+	gl.BindBuffer(target, 0)
+	r.mem.setBuffer(*id, int64(size))
+}
+
+// loadIndirect loads the given indirect buffer, if it is not already loaded,
+// and returns it's native object.
+func (r *device) loadIndirect(ind *gfx.Indirect) *nativeIndirect {
+	n, _ := ind.NativeIndirect.(*nativeIndirect)
+	if ind.Loaded && n != nil {
+		return n
+	}
+	if n == nil {
+		n = &nativeIndirect{r: r.rsrcManager}
+		runtime.SetFinalizer(n, finalizeIndirect)
+		ind.NativeIndirect = n
+	}
+	n.count = int32(len(ind.Commands))
+	size := len(ind.Commands) * gfx.DrawCommandSize
+	var commands unsafe.Pointer
+	if len(ind.Commands) > 0 {
+		commands = unsafe.Pointer(&ind.Commands[0])
+	}
+
+	switch {
+	case len(ind.Bounds) == 0:
+		// The commands are drawn as-is.
+		r.bufferData(gl.DRAW_INDIRECT_BUFFER, &n.draw, size, commands, gl.STATIC_DRAW)
+
+	case r.indirect.compute:
+		// The culling shader reads the commands and their bounds, and writes
+		// the buffer drawn from.
+		bounds := make([]gfx.Vec4, len(ind.Commands))
+		for i := range bounds {
+			s := ind.Bounds[i]
+			bounds[i] = gfx.Vec4{
+				X: float32(s.Center.X),
+				Y: float32(s.Center.Y),
+				Z: float32(s.Center.Z),
+				W: float32(s.Radius),
+			}
+		}
+		r.bufferData(gl.SHADER_STORAGE_BUFFER, &n.source, size, commands, gl.STATIC_DRAW)
+		r.bufferData(gl.SHADER_STORAGE_BUFFER, &n.bounds, len(bounds)*16, gl.Ptr(bounds), gl.STATIC_DRAW)
+		r.bufferData(gl.DRAW_INDIRECT_BUFFER, &n.draw, size, nil, gl.DYNAMIC_DRAW)
+
+	default:
+		// The commands are culled on the CPU, and uploaded at each draw.
+		if n.draw == 0 {
+			gl.GenBuffers(1, &n.draw)
+		}
+	}
+	ind.Loaded = true
+	return n
+}
+
+// cullProgram returns the culling program, compiling and linking it if it
+// has not been yet. If it fails to compile or link, culling on the GPU is
+// disabled and zero is returned.
+func (r *device) cullProgram() uint32 {
+	if r.indirect.program != 0 {
+		return r.indirect.program
+	}
+	shader := gl.CreateShader(gl.COMPUTE_SHADER)
+	sources, free := gl.Strs(cullSource + "\x00")
+	gl.ShaderSource(shader, 1, sources, nil)
+	gl.CompileShader(shader)
+	free()
+	log, compiled := shaderCompilerLog(shader)
+	if !compiled {
+		gl.DeleteShader(shader)
+		r.warner.Warnf("Culling shader errors, culling on the CPU instead:\n%s\n", log)
+		r.indirect.compute = false
+		return 0
+	}
+
+	program := gl.CreateProgram()
+	gl.AttachShader(program, shader)
+	gl.LinkProgram(program)
+	gl.DeleteShader(shader)
+	var ok int32
+	gl.GetProgramiv(program, gl.LINK_STATUS, &ok)
+	if ok == 0 {
+		gl.DeleteProgram(program)
+		r.warner.Warnf("Culling shader failed to link, culling on the CPU instead.\n")
+		r.indirect.compute = false
+		return 0
+	}
+	r.indirect.program = program
+	r.indirect.planes = gl.GetUniformLocation(program, gl.Str("Planes\x00"))
+	r.indirect.count = gl.GetUniformLocation(program, gl.Str("Count\x00"))
+	return program
+}
+
+// cullIndirect culls the commands of the given native indirect buffer on the
+// GPU against the given frustum (in the object's space), writing the buffer
+// drawn from. It returns false if culling on the GPU is not possible.
+func (r *device) cullIndirect(n *nativeIndirect, f lmath.Frustum) bool {
+	program := r.cullProgram()
+	if program == 0 {
+		return false
+	}
+	var planes [6]gfx.Vec4
+	for i, p := range f {
+		planes[i] = gfx.Vec4{
+			X: float32(p.Normal.X),
+			Y: float32(p.Normal.Y),
+			Z: float32(p.Normal.Z),
+			W: float32(p.D),
+		}
+	}
+
+	// Use the culling program, the caller's is used again afterwards.
+	last := r.graphicsState.S.ShaderProgram
+	r.graphicsState.useProgram(program)
+	gl.Uniform4fv(r.indirect.planes, 6, &planes[0].X)
+	gl.Uniform1i(r.indirect.count, n.count)
+	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 0, n.source)
+	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 1, n.bounds)
+	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 2, n.draw)
+	gl.DispatchCompute(uint32(n.count+cullGroupSize-1)/cullGroupSize, 1, 1)
+
+	// The draw commands are read after the shader has written them.
+	gl.MemoryBarrier(gl.COMMAND_BARRIER_BIT)
+	r.graphicsState.useProgram(last)
+	return true
+}
+
+// drawIndirect draws the commands of the given object's indirect buffer,
+// each drawing a range of the indices of it's first mesh. It must be called
+// inside renderExec, after the object's state is used.
+func (r *device) drawIndirect(ns *nativeShader, o *gfx.Object) {
+	m := o.Meshes[0]
+	native := r.useMesh(ns, m)
+	if native.indicesCount == 0 {
+		r.warner.Warnf("Indirect drawing requires a mesh with indices, ignoring.\n")
+		return
+	}
+	r.graphicsState.bindBuffer(gl.ELEMENT_ARRAY_BUFFER, native.indices)
+	mode := uint32(r.common.ConvertPrimitive(m.Primitive))
+	offset := r.persistOffset(native, native.indices)
+
+	ind := o.Indirect
+	mvp := o.NativeObject.(*nativeObject).MVPCache.MVP.Mat4()
+	frustum := lmath.FrustumFromMat4(mvp)
+
+	// Indices in a persistently mapped buffer are not at it's start, which
+	// the commands of the indirect buffer would need to account for; they
+	// are drawn one command at a time instead.
+	if r.indirect.draw && offset == 0 {
+		n := r.loadIndirect(ind)
+		count := n.count
+		if len(ind.Bounds) > 0 && !(r.indirect.compute && r.cullIndirect(n, frustum)) {
+			// Upload the commands culled on the CPU.
+			r.indirect.scratch = ind.Cull(r.indirect.scratch[:0], frustum)
+			count = int32(len(r.indirect.scratch))
+			if count > 0 {
+				r.bufferData(gl.DRAW_INDIRECT_BUFFER, &n.draw, int(count)*gfx.DrawCommandSize, gl.Ptr(r.indirect.scratch), gl.STREAM_DRAW)
+			}
+		}
+		if count > 0 {
+			gl.BindBuffer(gl.DRAW_INDIRECT_BUFFER, n.draw)
+			gl.MultiDrawElementsIndirect(mode, gl.UNSIGNED_INT, nil, count, 0)
+			gl.BindBuffer(gl.DRAW_INDIRECT_BUFFER, 0)
+			r.profile.drawCalls++
+		}
+		return
+	}
+
+	// Draw each command which is not culled on it's own.
+	r.indirect.scratch = ind.Cull(r.indirect.scratch[:0], frustum)
+	for _, c := range r.indirect.scratch {
+		indices := gl.PtrOffset(offset + int(c.FirstIndex)*4)
+		if c.InstanceCount > 1 && r.indirect.drawInstanced != nil {
+			r.indirect.drawInstanced(mode, int32(c.Count), gl.UNSIGNED_INT, indices, int32(c.InstanceCount))
+		} else {
+			gl.DrawElements(mode, int32(c.Count), gl.UNSIGNED_INT, indices)
+		}
+		r.profile.drawCalls++
+	}
+}
@@ -11,15 +11,20 @@ import (
 
 	"azul3d.org/engine/gfx"
 	"azul3d.org/engine/gfx/internal/gl/2.0/gl"
+	"azul3d.org/engine/lmath"
 )
 
 // TODO(slimsag): move to internal/glc ?
 type nativeAttrib struct {
 	size int32    // 1, 2, 3, 4 - parameter to VertexAttribPointer
+	typ  uint32   // gl.FLOAT or glHALF_FLOAT - parameter to VertexAttribPointer
 	rows uint32   // e.g. 1 for vec[2,3,4], 3 for mat3, 4 for mat4.
 	vbos []uint32 // length 1 for []gfx.Vec3, literal len() for [][]gfx.Vec3
 }
 
+// See: https://www.opengl.org/registry/specs/ARB/half_float_vertex.txt
+const glHALF_FLOAT = 0x140B
+
 // nativeMesh is stored inside the *Mesh.Native interface and stores vertex
 // buffer object ID's.
 type nativeMesh struct {
@@ -51,24 +56,28 @@ func finalizeMesh(n *nativeMesh) {
 	n.r.Unlock()
 }
 
-// free literally frees the native mesh object right now. It may only be
-// called under the presence of the OpenGL context.
-func (n *nativeMesh) free() {
-	// Delete indices VBO.
-	gl.DeleteBuffers(1, &n.indices)
-
-	// Delete vertices VBO.
-	gl.DeleteBuffers(1, &n.vertices)
-
-	// Delete texture coords VBOs.
-	if len(n.texCoords) > 0 {
-		gl.DeleteBuffers(int32(len(n.texCoords)), &n.texCoords[0])
+// free literally frees the native mesh object right now, accounting for the
+// freed memory. It may only be called under the presence of the OpenGL
+// context.
+func (n *nativeMesh) free(mem *memory) {
+	mem.setBuffer(n.indices, 0)
+	mem.setBuffer(n.vertices, 0)
+	for _, vbo := range n.texCoords {
+		mem.setBuffer(vbo, 0)
+	}
+	for _, attrib := range n.attribs {
+		for _, vbo := range attrib.vbos {
+			mem.setBuffer(vbo, 0)
+		}
 	}
 
-	// Delete custom attribute VBOs.
+	// Delete the indices, vertices, texture coords and custom attribute VBOs.
+	vbos := append([]uint32{n.indices, n.vertices}, n.texCoords...)
 	for _, attrib := range n.attribs {
-		gl.DeleteBuffers(int32(len(attrib.vbos)), &attrib.vbos[0])
+		vbos = append(vbos, attrib.vbos...)
 	}
+	n.r.persist.forget(vbos...)
+	n.r.state.deleteBuffers(vbos...)
 
 	// Zero-out the nativeMesh structure, only keeping the rsrcManager around.
 	*n = nativeMesh{
@@ -82,9 +91,15 @@ func (r *device) createVBO() (vboID uint32) {
 	return
 }
 
-func (r *device) updateVBO(usageHint int32, dataSize uintptr, dataLength int, data unsafe.Pointer, vboID uint32) {
+func (r *device) updateVBO(usageHint int32, dataSize uintptr, dataLength int, data unsafe.Pointer, vboID *uint32) {
+	// Dynamic data is written into a persistently mapped buffer instead, if
+	// possible (note that this may replace the VBO).
+	if usageHint == gl.DYNAMIC_DRAW && r.persistUpdate(vboID, data, int(dataSize)*dataLength) {
+		return
+	}
+
 	// Bind the VBO now.
-	gl.BindBuffer(gl.ARRAY_BUFFER, vboID)
+	r.graphicsState.bindBuffer(gl.ARRAY_BUFFER, *vboID)
 
 	// Fill the VBO with the data.
 	gl.BufferData(
@@ -93,6 +108,7 @@ func (r *device) updateVBO(usageHint int32, dataSize uintptr, dataLength int, da
 		data,
 		uint32(usageHint),
 	)
+	r.mem.setBuffer(*vboID, int64(dataSize)*int64(dataLength))
 }
 
 func (r *device) deleteVBO(vboID *uint32) {
@@ -100,7 +116,9 @@ func (r *device) deleteVBO(vboID *uint32) {
 	if *vboID == 0 {
 		return
 	}
-	gl.DeleteBuffers(1, vboID)
+	r.persist.forget(*vboID)
+	r.graphicsState.deleteBuffers(*vboID)
+	r.mem.setBuffer(*vboID, 0)
 	*vboID = 0 // Just for safety.
 }
 
@@ -113,23 +131,48 @@ func (r *device) deleteVBO(vboID *uint32) {
 //  size == 2 == gfx.TexCoord
 //  size == 3 == gfx.Vec3
 //  size == 4 == gfx.Vec4, gfx.Color, gfx.Mat4
+//
+// The 32-bit lmath types (lmath.Vec2f, etc) are mapped like their gfx
+// equivalents. The 16-bit lmath types (lmath.Half, lmath.Vec2h, etc) are
+// mapped likewise, except half == true is returned (and their elements are
+// 16-bit).
 // ok == false is returned if x is not one of the above types.
 //
 // TODO(slimsag): move to internal/glc ?
-func attribSize(x interface{}) (rows uint32, size int32, ok bool) {
+func attribSize(x interface{}) (rows uint32, size int32, half, ok bool) {
 	switch x.(type) {
 	case float32:
-		return 1, 1, true
-	case gfx.TexCoord:
-		return 1, 2, true
-	case gfx.Vec3:
-		return 1, 3, true
-	case gfx.Vec4, gfx.Color:
-		return 1, 4, true
-	case gfx.Mat4:
-		return 4, 4, true
+		return 1, 1, false, true
+	case gfx.TexCoord, lmath.Vec2f:
+		return 1, 2, false, true
+	case gfx.Vec3, lmath.Vec3f:
+		return 1, 3, false, true
+	case gfx.Vec4, gfx.Color, lmath.Vec4f, lmath.Quatf:
+		return 1, 4, false, true
+	case gfx.Mat4, lmath.Mat4f:
+		return 4, 4, false, true
+	case lmath.Half:
+		return 1, 1, true, true
+	case lmath.Vec2h:
+		return 1, 2, true, true
+	case lmath.Vec3h:
+		return 1, 3, true, true
+	case lmath.Vec4h:
+		return 1, 4, true, true
 	}
-	return 0, 0, false
+	return 0, 0, false, false
+}
+
+// halfToFloat32 converts the given number of 16-bit floating point numbers at
+// data to 32-bit ones, for devices without half-float vertex attributes.
+func halfToFloat32(data unsafe.Pointer, n int) unsafe.Pointer {
+	var halfs []lmath.Half
+	sh := (*reflect.SliceHeader)(unsafe.Pointer(&halfs))
+	sh.Data = uintptr(data)
+	sh.Len = n
+	sh.Cap = n
+	f := lmath.Float32Slice(nil, halfs)
+	return unsafe.Pointer(&f[0])
 }
 
 func (r *device) updateCustomAttribVBO(usageHint int32, name string, attrib gfx.VertexAttrib, n *nativeAttrib) {
@@ -146,11 +189,11 @@ func (r *device) updateCustomAttribVBO(usageHint int32, name string, attrib gfx.
 	isArray := vIndexZero.Kind() == reflect.Slice
 
 	// Do we even have a valid data type? attribSize() will tell us if we do.
-	var ok bool
+	var half, ok bool
 	if isArray {
-		n.rows, n.size, ok = attribSize(vIndexZero.Index(0).Interface())
+		n.rows, n.size, half, ok = attribSize(vIndexZero.Index(0).Interface())
 	} else {
-		n.rows, n.size, ok = attribSize(vIndexZero.Interface())
+		n.rows, n.size, half, ok = attribSize(vIndexZero.Interface())
 	}
 	if !ok {
 		// Invalid data type.
@@ -158,6 +201,22 @@ func (r *device) updateCustomAttribVBO(usageHint int32, name string, attrib gfx.
 		return
 	}
 
+	// Without the GL_ARB_half_float_vertex extension, half-float data is
+	// converted to float32 data before uploading.
+	n.typ = gl.FLOAT
+	elemSize := uintptr(4)
+	convert := func(data unsafe.Pointer, length int) unsafe.Pointer { return data }
+	if half {
+		if r.glArbHalfFloatVertex {
+			n.typ = glHALF_FLOAT
+			elemSize = 2
+		} else {
+			convert = func(data unsafe.Pointer, length int) unsafe.Pointer {
+				return halfToFloat32(data, length*int(n.size))
+			}
+		}
+	}
+
 	// Generate vertex buffer objects, if we need to.
 	if len(n.vbos) == 0 {
 		// Determine the number of VBO's we need to create. For example if we
@@ -183,20 +242,20 @@ func (r *device) updateCustomAttribVBO(usageHint int32, name string, attrib gfx.
 			data := unsafe.Pointer(v.Index(i).Index(0).UnsafeAddr())
 			r.updateVBO(
 				usageHint,
-				uintptr(n.size*4),
+				uintptr(n.size)*elemSize,
 				vIndexZero.Len(),
-				data,
-				n.vbos[i],
+				convert(data, vIndexZero.Len()),
+				&n.vbos[i],
 			)
 		}
 	} else {
 		data := unsafe.Pointer(vIndexZero.UnsafeAddr())
 		r.updateVBO(
 			usageHint,
-			uintptr(n.size*4),
+			uintptr(n.size)*elemSize,
 			v.Len(),
-			data,
-			n.vbos[0],
+			convert(data, v.Len()),
+			&n.vbos[0],
 		)
 	}
 }
@@ -258,7 +317,7 @@ func (r *device) LoadMesh(m *gfx.Mesh, done chan *gfx.Mesh) {
 					unsafe.Sizeof(m.Indices[0]),
 					len(m.Indices),
 					unsafe.Pointer(&m.Indices[0]),
-					native.indices,
+					&native.indices,
 				)
 				native.indicesCount = int32(len(m.Indices))
 			}
@@ -282,7 +341,7 @@ func (r *device) LoadMesh(m *gfx.Mesh, done chan *gfx.Mesh) {
 					unsafe.Sizeof(m.Vertices[0]),
 					len(m.Vertices),
 					unsafe.Pointer(&m.Vertices[0]),
-					native.vertices,
+					&native.vertices,
 				)
 				native.verticesCount = int32(len(m.Vertices))
 			}
@@ -324,7 +383,6 @@ func (r *device) LoadMesh(m *gfx.Mesh, done chan *gfx.Mesh) {
 		toUpdate := m.TexCoords
 		for _, set := range added {
 			vbo := r.createVBO()
-			native.texCoords = append(native.texCoords, vbo)
 
 			// Update the VBO.
 			r.updateVBO(
@@ -332,8 +390,9 @@ func (r *device) LoadMesh(m *gfx.Mesh, done chan *gfx.Mesh) {
 				unsafe.Sizeof(set.Slice[0]),
 				len(set.Slice),
 				unsafe.Pointer(&set.Slice[0]),
-				vbo,
+				&vbo,
 			)
+			native.texCoords = append(native.texCoords, vbo)
 		}
 
 		// And finally, any texture coordinate sets that were changed need to
@@ -346,7 +405,7 @@ func (r *device) LoadMesh(m *gfx.Mesh, done chan *gfx.Mesh) {
 					unsafe.Sizeof(set.Slice[0]),
 					len(set.Slice),
 					unsafe.Pointer(&set.Slice[0]),
-					native.texCoords[index],
+					&native.texCoords[index],
 				)
 				set.Changed = false
 			}
@@ -401,8 +460,8 @@ func (r *device) LoadMesh(m *gfx.Mesh, done chan *gfx.Mesh) {
 			}
 		}
 
-		// Ensure no buffer is active when we leave (so that OpenGL state is untouched).
-		gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+		// Label the buffers for graphics debuggers.
+		r.labelMesh(native, m.Label)
 
 		// If the mesh was not loaded, then we need to assign the native mesh
 		// and create a finalizer to free the native mesh later.
@@ -18,6 +18,14 @@ type nativeShader struct {
 	*glutil.LocationCache
 	program, vertex, fragment uint32
 	r                         *rsrcManager
+
+	// The value last uploaded to each uniform location of the program, see
+	// updateUniform. Only touched inside renderExec.
+	uniforms map[int32]interface{}
+
+	// The matrix last uploaded to each of the object matrix uniforms, see
+	// updateMatrixUniform. Only touched inside renderExec.
+	matrices map[int32]gfx.Mat4
 }
 
 // Implements gfx.Destroyable interface.
@@ -107,91 +115,100 @@ func (r *device) LoadShader(s *gfx.Shader, done chan *gfx.Shader) {
 			r: r.rsrcManager,
 		}
 
-		// Compile vertex shader.
-		native.vertex = gl.CreateShader(gl.VERTEX_SHADER)
-		sources, free := gl.Strs(string(s.GLSL.Vertex) + "\x00")
-		gl.ShaderSource(native.vertex, 1, sources, nil) // TODO(slimsag): use length parameter instead of null terminator
-		gl.CompileShader(native.vertex)
-		free()
-
-		// Check if the shader compiled or not.
-		log, compiled := shaderCompilerLog(native.vertex)
-		if !compiled {
-			// Just for sanity.
-			native.vertex = 0
-
-			// Append the errors.
-			s.Error = append(s.Error, []byte(s.Name+" | Vertex shader errors:\n")...)
-			s.Error = append(s.Error, log...)
-		}
-		if len(log) > 0 {
-			// Send the compiler log to the debug writer.
-			r.warner.Warnf("%s | Vertex shader errors:\n", s.Name)
-			r.warner.Warnf(string(log))
-		}
-
-		// Compile fragment shader.
-		native.fragment = gl.CreateShader(gl.FRAGMENT_SHADER)
-		sources, free = gl.Strs(string(s.GLSL.Fragment) + "\x00")
-		gl.ShaderSource(native.fragment, 1, sources, nil) // TODO(slimsag): use length parameter instead of null terminator
-		gl.CompileShader(native.fragment)
-		free()
-
-		// Check if the shader compiled or not.
-		log, compiled = shaderCompilerLog(native.fragment)
-		if !compiled {
-			// Just for sanity.
-			native.fragment = 0
-
-			// Append the errors.
-			s.Error = append(s.Error, []byte(s.Name+" | Fragment shader errors:\n")...)
-			s.Error = append(s.Error, log...)
-		}
-		if len(log) > 0 {
-			// Send the compiler log to the debug writer.
-			r.warner.Warnf("%s | Fragment shader errors:\n", s.Name)
-			r.warner.Warnf(string(log))
-		}
+		// Use the cached program, if there is one. Otherwise compile and link
+		// the shader.
+		native.program = r.shaderCacheLoad(s)
+		if native.program == 0 {
+			// Compile vertex shader.
+			native.vertex = gl.CreateShader(gl.VERTEX_SHADER)
+			sources, free := gl.Strs(string(s.GLSL.Vertex) + "\x00")
+			gl.ShaderSource(native.vertex, 1, sources, nil) // TODO(slimsag): use length parameter instead of null terminator
+			gl.CompileShader(native.vertex)
+			free()
+
+			// Check if the shader compiled or not.
+			log, compiled := shaderCompilerLog(native.vertex)
+			if !compiled {
+				// Just for sanity.
+				native.vertex = 0
 
-		// Create the shader program if all went well with the vertex and
-		// fragment shaders.
-		if native.vertex != 0 && native.fragment != 0 {
-			native.program = gl.CreateProgram()
-			gl.AttachShader(native.program, native.vertex)
-			gl.AttachShader(native.program, native.fragment)
-			gl.LinkProgram(native.program)
-
-			// Grab the linker's log.
-			var (
-				logSize int32
-				log     []byte
-			)
-			gl.GetProgramiv(native.program, gl.INFO_LOG_LENGTH, &logSize)
-
-			if logSize > 0 {
-				log = make([]byte, logSize)
-				gl.GetProgramInfoLog(native.program, logSize, nil, &log[0])
-
-				// Strip the null-termination byte.
-				log = log[:len(log)-1]
+				// Append the errors.
+				s.Error = append(s.Error, []byte(s.Name+" | Vertex shader errors:\n")...)
+				s.Error = append(s.Error, log...)
 			}
+			if len(log) > 0 {
+				// Send the compiler log to the debug writer.
+				r.warner.Warnf("%s | Vertex shader errors:\n", s.Name)
+				r.warner.Warnf(string(log))
+			}
+
+			// Compile fragment shader.
+			native.fragment = gl.CreateShader(gl.FRAGMENT_SHADER)
+			sources, free = gl.Strs(string(s.GLSL.Fragment) + "\x00")
+			gl.ShaderSource(native.fragment, 1, sources, nil) // TODO(slimsag): use length parameter instead of null terminator
+			gl.CompileShader(native.fragment)
+			free()
 
-			// Check for linker errors.
-			var ok int32
-			gl.GetProgramiv(native.program, gl.LINK_STATUS, &ok)
-			if ok == 0 {
+			// Check if the shader compiled or not.
+			log, compiled = shaderCompilerLog(native.fragment)
+			if !compiled {
 				// Just for sanity.
-				native.program = 0
+				native.fragment = 0
 
 				// Append the errors.
-				s.Error = append(s.Error, []byte(s.Name+" | Linker errors:\n")...)
+				s.Error = append(s.Error, []byte(s.Name+" | Fragment shader errors:\n")...)
 				s.Error = append(s.Error, log...)
 			}
 			if len(log) > 0 {
-				// Send the linker log to the debug writer.
-				r.warner.Warnf("%s | Linker errors:\n", s.Name)
+				// Send the compiler log to the debug writer.
+				r.warner.Warnf("%s | Fragment shader errors:\n", s.Name)
 				r.warner.Warnf(string(log))
 			}
+
+			// Create the shader program if all went well with the vertex and
+			// fragment shaders.
+			if native.vertex != 0 && native.fragment != 0 {
+				native.program = gl.CreateProgram()
+				gl.AttachShader(native.program, native.vertex)
+				gl.AttachShader(native.program, native.fragment)
+				r.shaderCachePrepare(native.program)
+				gl.LinkProgram(native.program)
+
+				// Grab the linker's log.
+				var (
+					logSize int32
+					log     []byte
+				)
+				gl.GetProgramiv(native.program, gl.INFO_LOG_LENGTH, &logSize)
+
+				if logSize > 0 {
+					log = make([]byte, logSize)
+					gl.GetProgramInfoLog(native.program, logSize, nil, &log[0])
+
+					// Strip the null-termination byte.
+					log = log[:len(log)-1]
+				}
+
+				// Check for linker errors.
+				var ok int32
+				gl.GetProgramiv(native.program, gl.LINK_STATUS, &ok)
+				if ok == 0 {
+					// Just for sanity.
+					native.program = 0
+
+					// Append the errors.
+					s.Error = append(s.Error, []byte(s.Name+" | Linker errors:\n")...)
+					s.Error = append(s.Error, log...)
+				} else {
+					// Cache the linked program for next time.
+					r.shaderCacheStore(s, native.program)
+				}
+				if len(log) > 0 {
+					// Send the linker log to the debug writer.
+					r.warner.Warnf("%s | Linker errors:\n", s.Name)
+					r.warner.Warnf(string(log))
+				}
+			}
 		}
 
 		// Mark the shader as loaded if there were no errors.
@@ -205,6 +222,13 @@ func (r *device) LoadShader(s *gfx.Shader, done chan *gfx.Shader) {
 				},
 			}
 
+			// Label the program for graphics debuggers.
+			r.labelObject(labelProgram, native.program, s.Name)
+
+			// Reflect the active uniforms and attributes.
+			s.Uniforms = activeVars(native.program, true)
+			s.Attributes = activeVars(native.program, false)
+
 			s.Loaded = true
 			s.NativeShader = native
 			s.ClearData()
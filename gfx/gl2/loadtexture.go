@@ -6,15 +6,13 @@ package gl2
 
 import (
 	"image"
-	"image/draw"
-	"log"
 	"runtime"
 	"unsafe"
 
 	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/imgutil"
 	"azul3d.org/engine/gfx/internal/gl/2.0/gl"
 	"azul3d.org/engine/gfx/internal/glutil"
-	"azul3d.org/engine/gfx/internal/tag"
 	"azul3d.org/engine/gfx/internal/util"
 )
 
@@ -23,8 +21,20 @@ type nativeTexture struct {
 	id             uint32
 	internalFormat int32
 	width, height  int
+	size           int64 // Estimated memory usage in bytes.
 	rttCanvas      *rttCanvas
 	destroyHandler func(n *nativeTexture)
+
+	// The sampling state last set on the texture, or nil if it is unknown.
+	// Only touched inside renderExec.
+	sampler *gfx.Sampler
+
+	// The pre-built mipmap levels the texture was loaded from (see
+	// mipstream.go), or nil. The first level of the mip tail, and the most
+	// detailed resident level (which is protected by the device's mips lock).
+	mips       []gfx.MipLevel
+	compressed bool
+	tail, base int
 }
 
 // Generates texture ID, binds, and sets BASE/MAX mipmap levels to zero.
@@ -40,7 +50,7 @@ func newNativeTexture(r *device, internalFormat int32, width, height int) *nativ
 	}
 	gl.GenTextures(1, &tex.id)
 
-	gl.BindTexture(gl.TEXTURE_2D, tex.id)
+	r.graphicsState.bindTexture(tex.id)
 	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_BASE_LEVEL, 0)
 	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAX_LEVEL, 1000)
 	return tex
@@ -57,6 +67,13 @@ func (n *nativeTexture) ChosenFormat() gfx.TexFormat {
 }
 
 func finalizeTexture(n *nativeTexture) {
+	if n.mips != nil {
+		n.r.mipsForget(n)
+	}
+	if n.rttCanvas == nil {
+		// Render-to-texture textures are accounted for by their canvas.
+		n.r.mem.addTextures(-n.size)
+	}
 	n.r.rsrcManager.Lock()
 	n.r.rsrcManager.textures = append(n.r.rsrcManager.textures, n.id)
 	n.r.rsrcManager.Unlock()
@@ -84,10 +101,11 @@ func (n *nativeTexture) Download(rect image.Rectangle, complete chan image.Image
 		gl.GenFramebuffers(1, &fbo)
 		gl.BindFramebuffer(gl.FRAMEBUFFER, fbo)
 
-		gl.BindTexture(gl.TEXTURE_2D, n.id)
+		n.r.graphicsState.bindTexture(n.id)
 		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
 		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
-		gl.BindTexture(gl.TEXTURE_2D, 0)
+		n.r.graphicsState.bindTexture(0)
+		n.sampler = nil
 
 		// Attach the texture to the FBO.
 		gl.FramebufferTexture2D(
@@ -133,24 +151,13 @@ func (n *nativeTexture) Download(rect image.Rectangle, complete chan image.Image
 }
 
 func prepareImage(npot bool, img image.Image) *image.RGBA {
-	bounds := img.Bounds()
-
 	if !npot {
 		// Convert the image to a power-of-two size if it's not already.
 		img = util.POT(img)
-
-		// Update known bounds.
-		bounds = img.Bounds()
 	}
 
 	// Currently, images must be RGBA format. Convert now if needed.
-	rgba, ok := img.(*image.RGBA)
-	if !ok {
-		// Convert the image to RGBA.
-		rgba = image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
-		draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
-	}
-	return rgba
+	return imgutil.RGBA(img)
 }
 
 // Download implements the gfx.Downloadable interface.
@@ -169,13 +176,13 @@ func (r *device) hookedDownload(rect image.Rectangle, complete chan image.Image,
 		bounds := r.Bounds()
 		rect = bounds.Intersect(rect)
 
-		img := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+		img, pix, xtype := r.newDownloadImage(rect.Dx(), rect.Dy())
 		x, y, w, h := glutil.ConvertRect(rect, bounds)
 		gl.ReadPixels(
 			int32(x), int32(y), int32(w), int32(h),
 			gl.RGBA,
-			gl.UNSIGNED_BYTE,
-			unsafe.Pointer(&img.Pix[0]),
+			xtype,
+			unsafe.Pointer(&pix[0]),
 		)
 
 		if post != nil {
@@ -185,8 +192,9 @@ func (r *device) hookedDownload(rect image.Rectangle, complete chan image.Image,
 		// Flush OpenGL commands.
 		gl.Flush()
 
-		// We must vertically flip the image.
-		util.VerticalFlip(img)
+		// The pixels were read into the image itself, which only needs to be
+		// converted and vertically flipped.
+		finishDownload(img, pix)
 
 		// Yield for occlusion query results, if any are available.
 		r.queryYield()
@@ -200,12 +208,12 @@ func (r *rsrcManager) freeTextures() {
 	// Lock the list.
 	r.Lock()
 
-	if tag.Gfxdebug && len(r.textures) > 0 {
-		log.Printf("gfx: free %d textures\n", len(r.textures))
+	if len(r.textures) > 0 {
+		logger.Debugf("free %d textures", len(r.textures))
 	}
 	if len(r.textures) > 0 {
 		// Free the textures.
-		gl.DeleteTextures(int32(len(r.textures)), &r.textures[0])
+		r.state.deleteTextures(r.textures)
 
 		// Flush OpenGL commands.
 		gl.Flush()
@@ -239,11 +247,33 @@ func convertTexFormat(f gfx.TexFormat) int32 {
 		return glCOMPRESSED_RGBA_S3TC_DXT3_EXT
 	case gfx.DXT5:
 		return glCOMPRESSED_RGBA_S3TC_DXT5_EXT
+	case gfx.SRGB:
+		return gl.SRGB8
+	case gfx.SRGBA:
+		return gl.SRGB8_ALPHA8
 	default:
 		panic("unknown format")
 	}
 }
 
+// textureInternalFormat returns the OpenGL internal format to use for a
+// texture with the given format, whose source image is uploaded as RGBA.
+func (r *device) textureInternalFormat(f gfx.TexFormat) int32 {
+	if f.IsSRGB() {
+		if r.devInfo.SRGBTextures {
+			return gl.SRGB8_ALPHA8
+		}
+		return gl.RGBA
+	}
+	targetFormat := convertTexFormat(f)
+	for _, format := range r.compressedTextureFormats {
+		if format == targetFormat {
+			return format
+		}
+	}
+	return gl.RGBA
+}
+
 func unconvertTexFormat(f int32) gfx.TexFormat {
 	switch f {
 	case gl.RGBA8:
@@ -258,6 +288,10 @@ func unconvertTexFormat(f int32) gfx.TexFormat {
 		return gfx.DXT3
 	case glCOMPRESSED_RGBA_S3TC_DXT5_EXT:
 		return gfx.DXT5
+	case gl.SRGB8:
+		return gfx.SRGB
+	case gl.SRGB8_ALPHA8:
+		return gfx.SRGBA
 	default:
 		panic("unknown format")
 	}
@@ -275,7 +309,7 @@ func (r *device) LoadTexture(t *gfx.Texture, done chan *gfx.Texture) {
 	}
 	r.shared.RUnlock()
 
-	if !t.Loaded && t.Source == nil {
+	if !t.Loaded && t.Source == nil && t.Mipmaps == nil {
 		panic("LoadTexture(): Texture has a nil source!")
 	}
 	if t.Loaded {
@@ -286,20 +320,24 @@ func (r *device) LoadTexture(t *gfx.Texture, done chan *gfx.Texture) {
 		}
 		return
 	}
+	if t.Mipmaps != nil {
+		// Load the texture from it's pre-built mipmaps, which are streamed
+		// in later instead (see SetResidentMip).
+		r.loadTextureMipmaps(t, done)
+		return
+	}
+	if t.Streaming {
+		// Stream the texture in the background.
+		r.streamTexture(t, done)
+		return
+	}
 
 	// Prepare the image for uploading.
 	src := prepareImage(r.devInfo.NPOT, t.Source)
 
 	r.renderExec <- func() bool {
 		// Determine appropriate internal image format.
-		targetFormat := convertTexFormat(t.Format)
-		internalFormat := int32(gl.RGBA)
-		for _, format := range r.compressedTextureFormats {
-			if format == targetFormat {
-				internalFormat = format
-				break
-			}
-		}
+		internalFormat := r.textureInternalFormat(t.Format)
 
 		// Initialize native texture.
 		bounds := src.Bounds()
@@ -328,7 +366,14 @@ func (r *device) LoadTexture(t *gfx.Texture, done chan *gfx.Texture) {
 		)
 
 		// Unbind texture to avoid carrying OpenGL state.
-		gl.BindTexture(gl.TEXTURE_2D, 0)
+		r.graphicsState.bindTexture(0)
+
+		// Label the texture for graphics debuggers.
+		r.labelObject(labelTexture, native.id, t.Label)
+
+		// Account for the texture's memory.
+		native.size = textureSize(internalFormat, bounds.Dx(), bounds.Dy(), t.MinFilter.Mipmapped())
+		r.mem.addTextures(native.size)
 
 		// Mark the texture as loaded.
 		t.Loaded = true
@@ -349,3 +394,31 @@ func (r *device) LoadTexture(t *gfx.Texture, done chan *gfx.Texture) {
 		return false // no frame rendered.
 	}
 }
+
+// loadTextureMipmaps loads the texture from it's pre-built mipmaps.
+func (r *device) loadTextureMipmaps(t *gfx.Texture, done chan *gfx.Texture) {
+	r.renderExec <- func() bool {
+		native := r.loadMipmaps(t)
+
+		// Label the texture for graphics debuggers.
+		r.labelObject(labelTexture, native.id, t.Label)
+
+		// Account for the texture's memory.
+		r.mem.addTextures(native.size)
+
+		// Mark the texture as loaded.
+		t.Loaded = true
+		t.NativeTexture = native
+		t.ClearData()
+
+		// Attach a finalizer to the texture that will later free it.
+		runtime.SetFinalizer(native, finalizeTexture)
+
+		// Signal completion and return.
+		select {
+		case done <- t:
+		default:
+		}
+		return false // no frame rendered.
+	}
+}
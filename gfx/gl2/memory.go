@@ -0,0 +1,139 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gl2
+
+import (
+	"sync"
+
+	"azul3d.org/engine/gfx"
+)
+
+// memory tracks the estimated memory usage of a device's resources.
+type memory struct {
+	sync.Mutex
+	stats gfx.MemoryStats
+
+	// The size of each vertex buffer object, by ID.
+	buffers map[uint32]int64
+
+	budget   int64
+	exceeded chan gfx.MemoryStats
+}
+
+// check sends the memory statistics over the exceeded channel if the budget is
+// exceeded. It must be called under lock.
+func (m *memory) check() {
+	if m.budget <= 0 || m.exceeded == nil || m.stats.Total() <= m.budget {
+		return
+	}
+	select {
+	case m.exceeded <- m.stats:
+	default:
+	}
+}
+
+// setBuffer sets the size of the given vertex buffer object (zero if it was
+// deleted).
+func (m *memory) setBuffer(id uint32, size int64) {
+	m.Lock()
+	if m.buffers == nil {
+		m.buffers = make(map[uint32]int64)
+	}
+	m.stats.Meshes += size - m.buffers[id]
+	if size == 0 {
+		delete(m.buffers, id)
+	} else {
+		m.buffers[id] = size
+	}
+	if size > 0 {
+		m.check()
+	}
+	m.Unlock()
+}
+
+// addTextures adjusts the texture memory usage by delta bytes.
+func (m *memory) addTextures(delta int64) {
+	m.Lock()
+	m.stats.Textures += delta
+	if delta > 0 {
+		m.check()
+	}
+	m.Unlock()
+}
+
+// addCanvases adjusts the canvas memory usage by delta bytes.
+func (m *memory) addCanvases(delta int64) {
+	m.Lock()
+	m.stats.Canvases += delta
+	if delta > 0 {
+		m.check()
+	}
+	m.Unlock()
+}
+
+// textureSize returns the estimated size in bytes of a texture with the given
+// OpenGL internal format and dimensions.
+func textureSize(internalFormat int32, width, height int, mipmapped bool) int64 {
+	pixels := int64(width) * int64(height)
+	var size int64
+	switch internalFormat {
+	case glCOMPRESSED_RGB_S3TC_DXT1_EXT, glCOMPRESSED_RGBA_S3TC_DXT1_EXT:
+		size = pixels / 2
+	case glCOMPRESSED_RGBA_S3TC_DXT3_EXT, glCOMPRESSED_RGBA_S3TC_DXT5_EXT:
+		size = pixels
+	default:
+		size = pixels * 4
+	}
+	if mipmapped {
+		// A full mipmap chain adds one third.
+		size += size / 3
+	}
+	return size
+}
+
+// MemoryStats implements the gfx.MemoryReporter interface.
+func (r *device) MemoryStats() gfx.MemoryStats {
+	r.mem.Lock()
+	stats := r.mem.stats
+	r.mem.Unlock()
+	return stats
+}
+
+// TextureSize implements the gfx.MemoryReporter interface.
+func (r *device) TextureSize(t *gfx.Texture) int64 {
+	native, ok := t.NativeTexture.(*nativeTexture)
+	if !ok {
+		return 0
+	}
+	return native.size
+}
+
+// MeshSize implements the gfx.MemoryReporter interface.
+func (r *device) MeshSize(m *gfx.Mesh) int64 {
+	native, ok := m.NativeMesh.(*nativeMesh)
+	if !ok {
+		return 0
+	}
+	r.mem.Lock()
+	defer r.mem.Unlock()
+	size := r.mem.buffers[native.indices] + r.mem.buffers[native.vertices]
+	for _, vbo := range native.texCoords {
+		size += r.mem.buffers[vbo]
+	}
+	for _, attrib := range native.attribs {
+		for _, vbo := range attrib.vbos {
+			size += r.mem.buffers[vbo]
+		}
+	}
+	return size
+}
+
+// SetMemoryBudget implements the gfx.MemoryReporter interface.
+func (r *device) SetMemoryBudget(budget int64, exceeded chan gfx.MemoryStats) {
+	r.mem.Lock()
+	r.mem.budget = budget
+	r.mem.exceeded = exceeded
+	r.mem.Unlock()
+}
@@ -0,0 +1,202 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gl2
+
+import (
+	"sync"
+	"unsafe"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/internal/gl/2.0/gl"
+)
+
+// mipTailSize is the largest width or height of the mipmap levels uploaded
+// when a texture with pre-built mipmaps is loaded (the 'mip tail').
+const mipTailSize = 64
+
+// mipStreamer holds the mipmap streaming state of a device.
+type mipStreamer struct {
+	sync.Mutex
+
+	// The level requested for each texture whose resident level differs
+	// from it.
+	pending map[*nativeTexture]int
+}
+
+// mipTail returns the first (most detailed) level of the mip tail of the
+// given levels.
+func mipTail(levels []gfx.MipLevel) int {
+	for i, l := range levels {
+		if l.Width <= mipTailSize && l.Height <= mipTailSize {
+			return i
+		}
+	}
+	return len(levels) - 1
+}
+
+// mipFormat returns the OpenGL internal format to use for a texture loaded
+// from pre-built mipmaps of the given format, and whether or not it is
+// compressed. If the device cannot store the format, ok is false.
+func (r *device) mipFormat(f gfx.TexFormat) (internalFormat int32, compressed, ok bool) {
+	switch f {
+	case gfx.RGBA:
+		return gl.RGBA8, false, true
+	case gfx.SRGBA:
+		if r.devInfo.SRGBTextures {
+			return gl.SRGB8_ALPHA8, false, true
+		}
+		return gl.RGBA8, false, true
+	case gfx.DXT1, gfx.DXT1RGBA, gfx.DXT3, gfx.DXT5:
+		internalFormat = convertTexFormat(f)
+		for _, format := range r.compressedTextureFormats {
+			if format == internalFormat {
+				return internalFormat, true, true
+			}
+		}
+		return internalFormat, true, false
+	}
+	return 0, false, false
+}
+
+// loadMipmaps creates a native texture from the pre-built mipmaps of the
+// given texture, uploading only it's mip tail. It must be called inside
+// renderExec.
+func (r *device) loadMipmaps(t *gfx.Texture) *nativeTexture {
+	levels := t.Mipmaps
+	internalFormat, compressed, ok := r.mipFormat(t.Format)
+	native := newNativeTexture(r, internalFormat, levels[0].Width, levels[0].Height)
+	if !ok {
+		// Without the pixel data in a format we can upload, the texture
+		// stays incomplete (and samples as black).
+		r.warner.Warnf("LoadTexture(): %q has mipmaps of unsupported format %v\n", t.Label, t.Format)
+		r.graphicsState.bindTexture(0)
+		return native
+	}
+	native.mips = levels
+	native.compressed = compressed
+	native.tail = mipTail(levels)
+
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAX_LEVEL, int32(len(levels)-1))
+	for i := len(levels) - 1; i >= native.tail; i-- {
+		native.uploadMip(i)
+	}
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_BASE_LEVEL, int32(native.tail))
+	r.graphicsState.bindTexture(0)
+
+	native.base = native.tail
+	native.size = gfx.MipSize(levels, native.base)
+	return native
+}
+
+// uploadMip uploads the given mipmap level of the bound texture. It must be
+// called inside renderExec.
+func (n *nativeTexture) uploadMip(level int) {
+	l := n.mips[level]
+	var data unsafe.Pointer
+	if len(l.Data) > 0 {
+		data = unsafe.Pointer(&l.Data[0])
+	}
+	if n.compressed {
+		gl.CompressedTexImage2D(gl.TEXTURE_2D, int32(level), uint32(n.internalFormat), int32(l.Width), int32(l.Height), 0, int32(len(l.Data)), data)
+		return
+	}
+	gl.TexImage2D(gl.TEXTURE_2D, int32(level), n.internalFormat, int32(l.Width), int32(l.Height), 0, gl.RGBA, gl.UNSIGNED_BYTE, data)
+}
+
+// freeMip frees the storage of the given mipmap level of the bound texture
+// by redefining it with a size of zero. It must be called inside renderExec.
+func (n *nativeTexture) freeMip(level int) {
+	if n.compressed {
+		gl.CompressedTexImage2D(gl.TEXTURE_2D, int32(level), uint32(n.internalFormat), 0, 0, 0, 0, nil)
+		return
+	}
+	gl.TexImage2D(gl.TEXTURE_2D, int32(level), n.internalFormat, 0, 0, 0, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+}
+
+// SetResidentMip implements the gfx.MipStreamer interface.
+func (r *device) SetResidentMip(t *gfx.Texture, level int) {
+	native, ok := t.NativeTexture.(*nativeTexture)
+	if !ok || native.mips == nil {
+		return
+	}
+	if level < 0 {
+		level = 0
+	} else if level > native.tail {
+		level = native.tail
+	}
+
+	// The texture may have been loaded by a device we share assets with.
+	m := &native.r.mips
+	m.Lock()
+	if level == native.base {
+		delete(m.pending, native)
+	} else {
+		if m.pending == nil {
+			m.pending = make(map[*nativeTexture]int)
+		}
+		m.pending[native] = level
+	}
+	m.Unlock()
+}
+
+// ResidentMip implements the gfx.MipStreamer interface.
+func (r *device) ResidentMip(t *gfx.Texture) int {
+	native, ok := t.NativeTexture.(*nativeTexture)
+	if !ok || native.mips == nil {
+		return 0
+	}
+	m := &native.r.mips
+	m.Lock()
+	defer m.Unlock()
+	return native.base
+}
+
+// mipsForget removes any pending request for the given texture, which is
+// being destroyed.
+func (r *device) mipsForget(n *nativeTexture) {
+	r.mips.Lock()
+	delete(r.mips.pending, n)
+	r.mips.Unlock()
+}
+
+// mipsEndFrame moves the resident level of each texture with a pending
+// request toward the requested level. Detailed levels which are no longer
+// requested are freed at once, while requested ones are uploaded one level
+// per texture per frame (smallest first), such that streaming in a large
+// texture does not cause a hitch. It must be called inside renderExec.
+func (r *device) mipsEndFrame() {
+	r.mips.Lock()
+	defer r.mips.Unlock()
+	if len(r.mips.pending) == 0 {
+		return
+	}
+	for native, level := range r.mips.pending {
+		r.graphicsState.bindTexture(native.id)
+		base := native.base
+		if level < base {
+			// Upload the next most detailed level, then begin sampling it.
+			base--
+			native.uploadMip(base)
+			gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_BASE_LEVEL, int32(base))
+		} else {
+			// Stop sampling the detailed levels, then free them.
+			gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_BASE_LEVEL, int32(level))
+			for i := base; i < level; i++ {
+				native.freeMip(i)
+			}
+			base = level
+		}
+		native.base = base
+		if base == level {
+			delete(r.mips.pending, native)
+		}
+
+		// Account for the change in the texture's memory.
+		size := gfx.MipSize(native.mips, base)
+		r.mem.addTextures(size - native.size)
+		native.size = size
+	}
+	r.graphicsState.bindTexture(0)
+}
@@ -0,0 +1,48 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gl2
+
+import (
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/internal/gl/2.0/gl"
+)
+
+// RunNative implements the gfx.NativeInterop interface.
+func (r *device) RunNative(f func()) {
+	done := make(chan struct{}, 1)
+	r.renderExec <- func() bool {
+		f()
+
+		// The function may have modified the OpenGL state, so restore it.
+		r.RestoreState()
+		done <- struct{}{}
+		return false
+	}
+	<-done
+}
+
+// CopyToNative implements the gfx.NativeInterop interface.
+func (r *device) CopyToNative(src *gfx.Texture, dst uint32) bool {
+	if src == nil || src.NativeTexture == nil {
+		return false
+	}
+	n, ok := src.NativeTexture.(*nativeTexture)
+	if !ok || !r.glArbFramebufferObject {
+		return false
+	}
+
+	// Attach the source texture to a temporary framebuffer object, and copy
+	// from it into the destination texture.
+	var fbo uint32
+	gl.GenFramebuffers(1, &fbo)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, fbo)
+	gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, n.id, 0)
+	r.graphicsState.bindTexture(dst)
+	gl.CopyTexSubImage2D(gl.TEXTURE_2D, 0, 0, 0, 0, 0, int32(n.width), int32(n.height))
+	r.graphicsState.bindTexture(0)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	gl.DeleteFramebuffers(1, &fbo)
+	return true
+}
@@ -0,0 +1,249 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gl2
+
+import (
+	"sync/atomic"
+	"unsafe"
+
+	"azul3d.org/engine/gfx/internal/gl/2.0/gl"
+	"azul3d.org/engine/gfx/internal/glutil"
+)
+
+// persistFrames is the number of frames which may be in flight (i.e. queued
+// for or being drawn by the GPU) at once, and thus the number of regions each
+// persistently mapped buffer is divided into.
+const persistFrames = 3
+
+// persistFlags are the flags persistently mapped buffers are created and
+// mapped with. Because they are coherent, writes are visible to the GPU
+// without explicit flushing.
+const persistFlags = gl.MAP_WRITE_BIT | gl.MAP_PERSISTENT_BIT | gl.MAP_COHERENT_BIT
+
+// persistTimeout is the time, in nanoseconds, that glClientWaitSync waits for
+// a fence before checking again.
+const persistTimeout = 1000000000
+
+// persistentBuffer is a vertex buffer object with immutable storage that is
+// persistently mapped, divided into persistFrames regions. Each update of
+// it's data is written to the next region (a ring), such that the GPU may
+// still be drawing with the data of previous regions.
+type persistentBuffer struct {
+	// The mapped pointer to the entire buffer.
+	ptr unsafe.Pointer
+
+	// The size of each region in bytes.
+	capacity int
+
+	// The index of the region drawn with, and whether or not any data has
+	// been written yet.
+	cur     int
+	written bool
+
+	// The frame in which each region was last drawn with, or zero if it never
+	// was.
+	used [persistFrames]uint64
+}
+
+// offset returns the byte offset of the current region.
+func (p *persistentBuffer) offset() int {
+	return p.cur * p.capacity
+}
+
+// persister holds the persistent mapping state of a device. Everything except
+// for the shared field is only touched inside renderExec.
+type persister struct {
+	// Whether or not persistent mapping is supported.
+	supported bool
+
+	// Non-zero if another device shares assets with this one (see Share), in
+	// which case meshes loaded from then on do not use persistent mapping, as
+	// we cannot know when the other device has finished drawing with them.
+	shared int32
+
+	// The current frame number, starting at one.
+	frame uint64
+
+	// The fence inserted at the end of each of the last persistFrames
+	// frames (or zero), and the frame number of each.
+	fences      [persistFrames]uintptr
+	fenceFrames [persistFrames]uint64
+
+	// The most recent frame known to have been completed by the GPU.
+	completed uint64
+
+	// The persistent buffers, by buffer object ID.
+	buffers map[uint32]*persistentBuffer
+}
+
+// persistInit queries for the extensions used for persistent mapping.
+func (r *device) persistInit(exts glutil.Extensions) {
+	r.persist.supported = exts.Present("GL_ARB_buffer_storage") &&
+		exts.Present("GL_ARB_map_buffer_range") &&
+		exts.Present("GL_ARB_sync")
+	r.persist.frame = 1
+	r.devInfo.PersistentMapping = r.persist.supported
+}
+
+// persistOffset returns the byte offset, into the given buffer object of a
+// mesh, of the data to draw with. It is zero unless the buffer is a persistent
+// one. It must be called inside renderExec.
+func (r *device) persistOffset(native *nativeMesh, id uint32) int {
+	if len(r.persist.buffers) == 0 || native.r != r.rsrcManager {
+		return 0
+	}
+	p, ok := r.persist.buffers[id]
+	if !ok {
+		return 0
+	}
+	p.used[p.cur] = r.persist.frame
+	return p.offset()
+}
+
+// persistUpdate writes size bytes of data to the next region of the given
+// persistent buffer object, creating it (and possibly replacing *id) if it
+// does not exist or is too small. It returns false if persistent mapping is
+// not supported, in which case the caller should upload the data normally.
+// It must be called inside renderExec.
+func (r *device) persistUpdate(id *uint32, data unsafe.Pointer, size int) bool {
+	if !r.persist.supported || atomic.LoadInt32(&r.persist.shared) != 0 {
+		return false
+	}
+	p := r.persist.buffers[*id]
+	if p == nil || size > p.capacity {
+		if p != nil {
+			// Immutable storage cannot be resized, so replace the buffer (the
+			// GPU may continue drawing with the old one until it is done).
+			r.persist.forget(*id)
+			r.graphicsState.deleteBuffers(*id)
+			r.mem.setBuffer(*id, 0)
+			gl.GenBuffers(1, id)
+		}
+		if p = r.persistCreate(*id, size); p == nil {
+			return false
+		}
+	}
+
+	next := p.cur
+	if p.written {
+		next = (p.cur + 1) % persistFrames
+	}
+	r.persistWait(p.used[next])
+	copy(
+		(*[1 << 30]byte)(unsafe.Pointer(uintptr(p.ptr) + uintptr(next*p.capacity)))[:size:size],
+		(*[1 << 30]byte)(data)[:size:size],
+	)
+	p.cur = next
+	p.written = true
+	return true
+}
+
+// persistCreate creates persistent storage, with room for at least size bytes
+// per region, for the given buffer object which must not yet have immutable
+// storage. It returns nil if the storage could not be mapped, in which case
+// persistent mapping is disabled.
+func (r *device) persistCreate(id uint32, size int) *persistentBuffer {
+	// Round the capacity up to a power of two, such that a buffer which grows
+	// slowly is not replaced often.
+	capacity := 256
+	for capacity < size {
+		capacity *= 2
+	}
+
+	r.graphicsState.bindBuffer(gl.ARRAY_BUFFER, id)
+	gl.BufferStorage(gl.ARRAY_BUFFER, capacity*persistFrames, nil, persistFlags)
+	ptr := gl.MapBufferRange(gl.ARRAY_BUFFER, 0, capacity*persistFrames, persistFlags)
+	if ptr == nil {
+		r.warner.Warnf("glMapBufferRange() failed, disabling persistent mapping.\n")
+		r.persist.supported = false
+		return nil
+	}
+	r.mem.setBuffer(id, int64(capacity*persistFrames))
+
+	p := &persistentBuffer{
+		ptr:      ptr,
+		capacity: capacity,
+	}
+	if r.persist.buffers == nil {
+		r.persist.buffers = make(map[uint32]*persistentBuffer)
+	}
+	r.persist.buffers[id] = p
+	return p
+}
+
+// forget forgets the given buffer objects, which are about to be deleted
+// (deleting a buffer object also unmaps it). It must be called inside
+// renderExec.
+func (p *persister) forget(ids ...uint32) {
+	for _, id := range ids {
+		delete(p.buffers, id)
+	}
+}
+
+// persistWait waits for the GPU to finish drawing the given frame, if it has
+// not already.
+func (r *device) persistWait(frame uint64) {
+	if frame <= r.persist.completed {
+		return
+	}
+	if frame >= r.persist.frame {
+		// The frame has not ended yet, so there is no fence to wait on. This
+		// only happens if a mesh is updated more than persistFrames times in
+		// a single frame.
+		logger.Debugf("dynamic mesh updated too often in a single frame, waiting for the GPU")
+		gl.Finish()
+		r.persist.completed = r.persist.frame - 1
+		return
+	}
+
+	// Wait for the oldest fence inserted at or after the frame.
+	slot := -1
+	for i, f := range r.persist.fenceFrames {
+		if r.persist.fences[i] != 0 && f >= frame && (slot == -1 || f < r.persist.fenceFrames[slot]) {
+			slot = i
+		}
+	}
+	if slot == -1 {
+		return
+	}
+	r.persistWaitFence(slot)
+}
+
+// persistWaitFence waits for the fence in the given slot to be signaled, and
+// then deletes it.
+func (r *device) persistWaitFence(slot int) {
+	fence := r.persist.fences[slot]
+	for {
+		status := gl.ClientWaitSync(fence, gl.SYNC_FLUSH_COMMANDS_BIT, persistTimeout)
+		if status != gl.TIMEOUT_EXPIRED {
+			break
+		}
+	}
+	gl.DeleteSync(fence)
+	if f := r.persist.fenceFrames[slot]; f > r.persist.completed {
+		r.persist.completed = f
+	}
+	r.persist.fences[slot] = 0
+}
+
+// persistEndFrame inserts a fence marking the end of the current frame,
+// waiting for the frame persistFrames ago to complete if it has not yet. It
+// must be called inside renderExec at the end of each frame.
+func (r *device) persistEndFrame() {
+	if !r.persist.supported || len(r.persist.buffers) == 0 {
+		// No fences are needed, everything before now is complete as far as
+		// persistent buffers are concerned.
+		r.persist.completed = r.persist.frame
+		r.persist.frame++
+		return
+	}
+	slot := int(r.persist.frame % persistFrames)
+	if r.persist.fences[slot] != 0 {
+		r.persistWaitFence(slot)
+	}
+	r.persist.fences[slot] = gl.FenceSync(gl.SYNC_GPU_COMMANDS_COMPLETE, 0)
+	r.persist.fenceFrames[slot] = r.persist.frame
+	r.persist.frame++
+}
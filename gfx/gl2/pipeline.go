@@ -0,0 +1,74 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gl2
+
+import "azul3d.org/engine/gfx"
+
+// Used as the *gfx.Pipeline.NativePipeline interface value.
+type nativePipeline struct {
+	// The state of the pipeline as it was when it was loaded, validated
+	// against the capabilities of the device.
+	state gfx.State
+}
+
+// Implements the gfx.Destroyable interface.
+func (n *nativePipeline) Destroy() {}
+
+// LoadPipeline implements the gfx.PipelineLoader interface.
+func (r *device) LoadPipeline(p *gfx.Pipeline, done chan *gfx.Pipeline) {
+	// If we are sharing assets with another renderer, allow it to load the
+	// pipeline instead.
+	r.shared.RLock()
+	if r.shared.device != nil {
+		r.shared.device.LoadPipeline(p, done)
+		r.shared.RUnlock()
+		return
+	}
+	r.shared.RUnlock()
+
+	signal := func() {
+		select {
+		case done <- p:
+		default:
+		}
+	}
+	if p.Loaded || p.Shader == nil || p.State == nil {
+		signal()
+		return
+	}
+
+	// Load the shader, waiting for it to complete.
+	if !p.Shader.Loaded {
+		shaderLoad := make(chan *gfx.Shader, 1)
+		r.LoadShader(p.Shader, shaderLoad)
+		<-shaderLoad
+	}
+	if len(p.Shader.Error) > 0 {
+		signal()
+		return
+	}
+
+	// Pipelines hold no OpenGL objects, so there is nothing to do inside the
+	// render loop.
+	p.NativePipeline = &nativePipeline{
+		state: r.pipelineState(*p.State),
+	}
+	p.Loaded = true
+	signal()
+}
+
+// pipelineState returns the given pipeline state adjusted to what the device
+// supports, warning about anything that it does not.
+func (r *device) pipelineState(s gfx.State) gfx.State {
+	if s.AlphaMode == gfx.AlphaToCoverage && !r.devInfo.AlphaToCoverage {
+		r.warner.Warnf("LoadPipeline: AlphaToCoverage is not supported, using BinaryAlpha instead.\n")
+		s.AlphaMode = gfx.BinaryAlpha
+	}
+	if s.DepthClamp && !r.devInfo.DepthClamp {
+		r.warner.Warnf("LoadPipeline: DepthClamp is not supported, ignoring it.\n")
+		s.DepthClamp = false
+	}
+	return s
+}
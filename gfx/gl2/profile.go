@@ -0,0 +1,189 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gl2
+
+import (
+	"sync"
+	"time"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/internal/gl/2.0/gl"
+)
+
+// maxPendingFrames is the maximum number of frames whose timer query results
+// may be pending at once. If more frames than this are pending, the oldest is
+// dropped.
+const maxPendingFrames = 8
+
+// profilePass is a single pass whose statistics are being collected.
+type profilePass struct {
+	gfx.PassStats
+
+	// The timer query ID, or zero if timer queries are not supported.
+	query uint32
+
+	// The draw call, state change and skipped state change counters at the
+	// start of the pass.
+	startDraws, startChanges, startSkipped int
+}
+
+// profileFrame is a single frame whose statistics are being collected.
+type profileFrame struct {
+	passes                                  []*profilePass
+	drawCalls, stateChanges, skippedChanges int
+}
+
+// profiler holds the profiling state of a device. Everything except for the
+// last field is only touched inside renderExec.
+type profiler struct {
+	// The total number of draw calls made by the device.
+	drawCalls int
+
+	// The draw call, state change and skipped state change counters at the
+	// start of the frame.
+	startDraws, startChanges, startSkipped int
+
+	// The current frame and the currently open pass (or nil).
+	cur  profileFrame
+	open *profilePass
+
+	// Frames whose timer query results are not yet available.
+	pending []profileFrame
+
+	// The statistics of the last frame whose results were available.
+	last struct {
+		sync.RWMutex
+		stats gfx.FrameStats
+	}
+}
+
+// BeginPass implements the gfx.Profiler interface.
+func (r *device) BeginPass(name string) {
+	r.renderExec <- func() bool {
+		r.profileEndPass()
+
+		p := &profilePass{
+			startDraws:   r.profile.drawCalls,
+			startChanges: r.graphicsState.Changes,
+			startSkipped: r.graphicsState.Skipped,
+		}
+		p.Name = name
+		if r.glArbTimerQuery {
+			gl.GenQueries(1, &p.query)
+			gl.BeginQuery(gl.TIME_ELAPSED, p.query)
+		}
+		r.profile.open = p
+		r.profile.cur.passes = append(r.profile.cur.passes, p)
+		return false
+	}
+}
+
+// EndPass implements the gfx.Profiler interface.
+func (r *device) EndPass() {
+	r.renderExec <- func() bool {
+		r.profileEndPass()
+		return false
+	}
+}
+
+// FrameStats implements the gfx.Profiler interface.
+func (r *device) FrameStats() gfx.FrameStats {
+	r.profile.last.RLock()
+	stats := r.profile.last.stats
+	r.profile.last.RUnlock()
+	return stats
+}
+
+// profileEndPass ends the currently open pass, if any. It must be called
+// inside renderExec.
+func (r *device) profileEndPass() {
+	p := r.profile.open
+	if p == nil {
+		return
+	}
+	if p.query != 0 {
+		gl.EndQuery(gl.TIME_ELAPSED)
+	}
+	p.DrawCalls = r.profile.drawCalls - p.startDraws
+	p.StateChanges = r.graphicsState.Changes - p.startChanges
+	p.SkippedChanges = r.graphicsState.Skipped - p.startSkipped
+	r.profile.open = nil
+}
+
+// profileEndFrame ends the current frame and collects the results of any
+// previous frames whose timer queries have completed. It must be called inside
+// renderExec.
+func (r *device) profileEndFrame() {
+	r.profileEndPass()
+
+	f := r.profile.cur
+	f.drawCalls = r.profile.drawCalls - r.profile.startDraws
+	f.stateChanges = r.graphicsState.Changes - r.profile.startChanges
+	f.skippedChanges = r.graphicsState.Skipped - r.profile.startSkipped
+	r.profile.cur = profileFrame{}
+	r.profile.startDraws = r.profile.drawCalls
+	r.profile.startChanges = r.graphicsState.Changes
+	r.profile.startSkipped = r.graphicsState.Skipped
+
+	if len(r.profile.pending) == maxPendingFrames {
+		r.profileDeleteQueries(r.profile.pending[0])
+		r.profile.pending = r.profile.pending[1:]
+	}
+	r.profile.pending = append(r.profile.pending, f)
+
+	// Collect the results of each pending frame, in order, stopping at the
+	// first one whose results are not yet available.
+	var available int32
+	for len(r.profile.pending) > 0 {
+		f := r.profile.pending[0]
+		ready := true
+		for _, p := range f.passes {
+			if p.query == 0 {
+				continue
+			}
+			gl.GetQueryObjectiv(p.query, gl.QUERY_RESULT_AVAILABLE, &available)
+			if available != gl.TRUE {
+				ready = false
+				break
+			}
+		}
+		if !ready {
+			break
+		}
+		r.profile.pending = r.profile.pending[1:]
+
+		stats := gfx.FrameStats{
+			Passes:         make([]gfx.PassStats, len(f.passes)),
+			DrawCalls:      f.drawCalls,
+			StateChanges:   f.stateChanges,
+			SkippedChanges: f.skippedChanges,
+		}
+		for i, p := range f.passes {
+			if p.query != 0 {
+				var ns uint64
+				gl.GetQueryObjectui64v(p.query, gl.QUERY_RESULT, &ns)
+				p.GPU = time.Duration(ns)
+			}
+			stats.Passes[i] = p.PassStats
+			stats.GPU += p.GPU
+		}
+		r.profileDeleteQueries(f)
+
+		r.profile.last.Lock()
+		r.profile.last.stats = stats
+		r.profile.last.Unlock()
+	}
+}
+
+// profileDeleteQueries deletes the timer queries of each pass in the given
+// frame. It must be called inside renderExec.
+func (r *device) profileDeleteQueries(f profileFrame) {
+	for _, p := range f.passes {
+		if p.query != 0 {
+			gl.DeleteQueries(1, &p.query)
+			p.query = 0
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gl2
+
+import (
+	"strings"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/internal/gl/2.0/gl"
+)
+
+// glslTypeName returns the GLSL type name of the given OpenGL type enum, e.g.
+// "vec3" for gl.FLOAT_VEC3.
+func glslTypeName(t uint32) string {
+	switch t {
+	case gl.FLOAT:
+		return "float"
+	case gl.FLOAT_VEC2:
+		return "vec2"
+	case gl.FLOAT_VEC3:
+		return "vec3"
+	case gl.FLOAT_VEC4:
+		return "vec4"
+	case gl.INT:
+		return "int"
+	case gl.INT_VEC2:
+		return "ivec2"
+	case gl.INT_VEC3:
+		return "ivec3"
+	case gl.INT_VEC4:
+		return "ivec4"
+	case gl.BOOL:
+		return "bool"
+	case gl.BOOL_VEC2:
+		return "bvec2"
+	case gl.BOOL_VEC3:
+		return "bvec3"
+	case gl.BOOL_VEC4:
+		return "bvec4"
+	case gl.FLOAT_MAT2:
+		return "mat2"
+	case gl.FLOAT_MAT3:
+		return "mat3"
+	case gl.FLOAT_MAT4:
+		return "mat4"
+	case gl.SAMPLER_2D:
+		return "sampler2D"
+	case gl.SAMPLER_CUBE:
+		return "samplerCube"
+	}
+	return "unknown"
+}
+
+// activeVars queries the active uniforms (if uniforms is true) or attributes
+// of the given linked program. It must be called inside renderExec.
+func activeVars(program uint32, uniforms bool) []gfx.ShaderVar {
+	countEnum, maxLenEnum := uint32(gl.ACTIVE_ATTRIBUTES), uint32(gl.ACTIVE_ATTRIBUTE_MAX_LENGTH)
+	get := gl.GetActiveAttrib
+	if uniforms {
+		countEnum, maxLenEnum = gl.ACTIVE_UNIFORMS, gl.ACTIVE_UNIFORM_MAX_LENGTH
+		get = gl.GetActiveUniform
+	}
+
+	var count, maxLen int32
+	gl.GetProgramiv(program, countEnum, &count)
+	gl.GetProgramiv(program, maxLenEnum, &maxLen)
+	if count <= 0 || maxLen <= 0 {
+		return []gfx.ShaderVar{}
+	}
+
+	vars := make([]gfx.ShaderVar, 0, count)
+	buf := make([]uint8, maxLen)
+	for i := uint32(0); i < uint32(count); i++ {
+		var (
+			length, size int32
+			xtype        uint32
+		)
+		get(program, i, maxLen, &length, &size, &xtype, &buf[0])
+		vars = append(vars, gfx.ShaderVar{
+			// Array names are reported with a "[0]" suffix by most drivers.
+			Name: strings.TrimSuffix(string(buf[:length]), "[0]"),
+			Type: glslTypeName(xtype),
+			Size: int(size),
+		})
+	}
+	return vars
+}
@@ -6,14 +6,12 @@ package gl2
 
 import (
 	"image"
-	"log"
 	"runtime"
 	"sync"
 
 	"azul3d.org/engine/gfx"
 	"azul3d.org/engine/gfx/internal/gl/2.0/gl"
 	"azul3d.org/engine/gfx/internal/glc"
-	"azul3d.org/engine/gfx/internal/tag"
 	"azul3d.org/engine/gfx/internal/util"
 )
 
@@ -22,9 +20,7 @@ func (r *rsrcManager) freeFBOs() {
 	r.Lock()
 
 	if len(r.fbos) > 0 {
-		if tag.Gfxdebug {
-			log.Printf("gfx: free %d FBOs\n", len(r.fbos))
-		}
+		logger.Debugf("free %d FBOs", len(r.fbos))
 		// Free the FBOs.
 		gl.DeleteFramebuffers(int32(len(r.fbos)), &r.fbos[0])
 
@@ -43,9 +39,7 @@ func (r *rsrcManager) freeRenderbuffers() {
 
 	if len(r.renderbuffers) > 0 {
 		// Free the FBOs.
-		if tag.Gfxdebug {
-			log.Printf("gfx: free %d renderbuffers\n", len(r.renderbuffers))
-		}
+		logger.Debugf("free %d renderbuffers", len(r.renderbuffers))
 		gl.DeleteRenderbuffers(int32(len(r.renderbuffers)), &r.renderbuffers[0])
 
 		// Flush OpenGL commands.
@@ -66,6 +60,9 @@ type rttCanvas struct {
 	// Frame buffer ID.
 	fbo uint32
 
+	// Estimated memory usage of the textures and render buffers, in bytes.
+	size int64
+
 	// Render buffer ID's (rbColor is only a valid render buffer if e.g. the
 	// cfg.Color field is nil).
 	//
@@ -119,6 +116,8 @@ func (r *rttCanvas) freeTexture(n *nativeTexture) {
 		freeRb(r.rbDepth)
 		freeRb(r.rbStencil)
 		freeRb(r.rbDepthAndStencil)
+
+		r.r.mem.addCanvases(-r.size)
 	}
 	r.textureCount.Unlock()
 }
@@ -180,13 +179,13 @@ func (r *rttCanvas) Render() {
 				return
 			}
 			n := t.NativeTexture.(*nativeTexture)
-			gl.BindTexture(gl.TEXTURE_2D, n.id)
+			r.r.graphicsState.bindTexture(n.id)
 			gl.GenerateMipmap(gl.TEXTURE_2D)
 		}
 		do(r.cfg.Color)
 		do(r.cfg.Depth)
 		do(r.cfg.Stencil)
-		gl.BindTexture(gl.TEXTURE_2D, 0)
+		r.r.graphicsState.bindTexture(0)
 	})
 }
 
@@ -195,6 +194,11 @@ func (r *rttCanvas) Download(rect image.Rectangle, complete chan image.Image) {
 	r.r.hookedDownload(rect, complete, r.rttBegin, r.rttEnd)
 }
 
+// Implements gfx.AsyncDownloader interface.
+func (r *rttCanvas) DownloadAsync(rect image.Rectangle, complete chan image.Image) {
+	r.r.hookedDownloadAsync(rect, complete, r.rttBegin, r.rttEnd)
+}
+
 func (r *rttCanvas) rttBegin() {
 	r.r.rttCanvas = r
 
@@ -324,7 +328,7 @@ func (r *device) RenderToTexture(cfg gfx.RTTConfig) gfx.Canvas {
 		fbError = r.common.FramebufferStatus(status)
 
 		// Unbind textures, render buffers, and the FBO.
-		gl.BindTexture(gl.TEXTURE_2D, 0)
+		r.graphicsState.bindTexture(0)
 		gl.BindRenderbuffer(gl.RENDERBUFFER, 0)
 		gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
 
@@ -343,6 +347,28 @@ func (r *device) RenderToTexture(cfg gfx.RTTConfig) gfx.Canvas {
 		panic(fbError)
 	}
 
+	// Account for the memory of the textures and render buffers.
+	attachSize := func(bits uint8, native *nativeTexture) int64 {
+		size := int64(cfg.Bounds.Dx()) * int64(cfg.Bounds.Dy()) * int64(bits/8)
+		if native != nil {
+			// Textures have mipmaps, but no multisampling.
+			size += size / 3
+			native.size = size
+		} else if cfg.Samples > 1 {
+			size *= int64(cfg.Samples)
+		}
+		return size
+	}
+	cr, cg, cb, ca = cfg.ColorFormat.Bits()
+	canvas.size = attachSize(cr+cg+cb+ca, nTexColor)
+	if dsCombined := cfg.DepthFormat == cfg.StencilFormat && cfg.DepthFormat.IsCombined(); dsCombined {
+		canvas.size += attachSize(cfg.DepthFormat.DepthBits()+cfg.DepthFormat.StencilBits(), nil)
+	} else {
+		canvas.size += attachSize(cfg.DepthFormat.DepthBits()+cfg.DepthFormat.StencilBits(), nTexDepth)
+		canvas.size += attachSize(cfg.StencilFormat.DepthBits()+cfg.StencilFormat.StencilBits(), nTexStencil)
+	}
+	r.mem.addCanvases(canvas.size)
+
 	// Finish textures (mark as loaded, clear data slices, unlock).
 	finishTexture := func(t *gfx.Texture, dsFmt *gfx.DSFormat, native *nativeTexture) {
 		if t == nil {
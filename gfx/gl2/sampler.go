@@ -0,0 +1,61 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gl2
+
+import (
+	"unsafe"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/internal/gl/2.0/gl"
+	"azul3d.org/engine/gfx/internal/glutil"
+)
+
+// samplerObjects is the sampler object state of a device.
+type samplerObjects struct {
+	// Whether or not sampler objects are supported. If not, they are
+	// emulated by setting the state of each texture (see useSampler).
+	supported bool
+
+	// The sampler object of each sampling state used so far. There are few
+	// distinct sampling states, so they are kept until the device is
+	// destroyed. Only touched inside renderExec.
+	objects map[gfx.Sampler]uint32
+}
+
+// samplerInit queries for the sampler objects extension, which is core since
+// OpenGL 3.3.
+func (r *device) samplerInit(exts glutil.Extensions) {
+	v := r.devInfo.GL
+	r.samplers.supported = exts.Present("GL_ARB_sampler_objects") ||
+		v.MajorVersion > 3 || v.MajorVersion == 3 && v.MinorVersion >= 3
+}
+
+// samplerObject returns the sampler object of the given sampling state,
+// creating it if needed. It must be called inside renderExec.
+func (r *device) samplerObject(s gfx.Sampler) uint32 {
+	if id, ok := r.samplers.objects[s]; ok {
+		return id
+	}
+	var id uint32
+	gl.GenSamplers(1, &id)
+	if s.WrapU == gfx.BorderColor || s.WrapV == gfx.BorderColor {
+		gl.SamplerParameterfv(id, gl.TEXTURE_BORDER_COLOR, r.scratch(unsafe.Pointer(&s.BorderColor), 4))
+	}
+	gl.SamplerParameteri(id, gl.TEXTURE_WRAP_S, int32(r.common.ConvertTexWrap(s.WrapU)))
+	gl.SamplerParameteri(id, gl.TEXTURE_WRAP_T, int32(r.common.ConvertTexWrap(s.WrapV)))
+	gl.SamplerParameteri(id, gl.TEXTURE_MIN_FILTER, int32(r.common.ConvertTexFilter(s.MinFilter)))
+	gl.SamplerParameteri(id, gl.TEXTURE_MAG_FILTER, int32(r.common.ConvertTexFilter(s.MagFilter)))
+	if r.glExtTextureFilterAnisotropic {
+		gl.SamplerParameterf(id, gl.TEXTURE_MAX_ANISOTROPY_EXT, clampf(s.MaxAnisotropy, 1, r.devInfo.MaxAnisotropy))
+	}
+	if r.devInfo.MaxLODBias > 0 {
+		gl.SamplerParameterf(id, gl.TEXTURE_LOD_BIAS, clampf(s.LODBias, -r.devInfo.MaxLODBias, r.devInfo.MaxLODBias))
+	}
+	if r.samplers.objects == nil {
+		r.samplers.objects = make(map[gfx.Sampler]uint32)
+	}
+	r.samplers.objects[s] = id
+	return id
+}
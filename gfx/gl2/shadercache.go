@@ -0,0 +1,182 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gl2
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/internal/gl/2.0/gl"
+	"azul3d.org/engine/gfx/internal/glutil"
+)
+
+// shaderCacheMagic prefixes each cached program binary file, such that files
+// written by a future (incompatible) version are rejected.
+const shaderCacheMagic = "azul3d-glprog-1\n"
+
+// shaderCache is an on-disk cache of linked program binaries.
+type shaderCache struct {
+	// The directory the cache is stored in, or an empty string if the cache
+	// is disabled (see the ShaderCache option).
+	dir string
+
+	// Whether or not program binaries are supported.
+	supported bool
+
+	// The vendor, renderer and version strings of the driver, which are a
+	// part of each key as program binaries are only valid for the driver
+	// that produced them.
+	driver string
+}
+
+// ShaderCache is an option that specifies a directory in which linked shader
+// programs are cached, such that subsequent loads of a shader with the same
+// sources (e.g. on the next run of the program) skip compiling and linking
+// it entirely.
+//
+// Programs are cached only if the driver supports retrieving them (via the
+// GL_ARB_get_program_binary extension). Cached programs are keyed by both
+// the shader's sources and the driver version, such that a driver update
+// simply causes them to be compiled again. The directory is created if it
+// does not exist.
+func ShaderCache(dir string) Option {
+	return func(d *device) {
+		d.shaderCache.dir = dir
+	}
+}
+
+// shaderCacheInit queries for program binary support.
+func (r *device) shaderCacheInit(exts glutil.Extensions) {
+	if len(r.shaderCache.dir) == 0 || !exts.Present("GL_ARB_get_program_binary") {
+		return
+	}
+
+	// Drivers may support the extension but no binary formats at all.
+	var formats int32
+	gl.GetIntegerv(gl.NUM_PROGRAM_BINARY_FORMATS, &formats)
+	if formats == 0 {
+		return
+	}
+	if err := os.MkdirAll(r.shaderCache.dir, 0755); err != nil {
+		r.warner.Warnf("ShaderCache: %v\n", err)
+		return
+	}
+	r.shaderCache.supported = true
+	r.shaderCache.driver = r.devInfo.Vendor + "\x00" + r.devInfo.Name + "\x00" + gl.GoStr(gl.GetString(gl.VERSION))
+}
+
+// shaderCacheKey returns the cache file path of the given shader's program.
+func (r *device) shaderCacheKey(s *gfx.Shader) string {
+	h := sha256.New()
+	h.Write([]byte(r.shaderCache.driver))
+	h.Write([]byte{0})
+	h.Write(s.GLSL.Vertex)
+	h.Write([]byte{0})
+	h.Write(s.GLSL.Fragment)
+	return filepath.Join(r.shaderCache.dir, hex.EncodeToString(h.Sum(nil))+".bin")
+}
+
+// shaderCacheLoad creates a program from the cached binary of the given
+// shader. It returns zero if the binary is not cached, or if the driver
+// rejects it (in which case it is removed from the cache). It must be called
+// inside renderExec.
+func (r *device) shaderCacheLoad(s *gfx.Shader) (program uint32) {
+	if !r.shaderCache.supported {
+		return 0
+	}
+	path := r.shaderCacheKey(s)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	header := len(shaderCacheMagic) + 4
+	if len(data) <= header || string(data[:len(shaderCacheMagic)]) != shaderCacheMagic {
+		os.Remove(path)
+		return 0
+	}
+	format := binary.LittleEndian.Uint32(data[len(shaderCacheMagic):])
+	bin := data[header:]
+
+	program = gl.CreateProgram()
+	gl.ProgramBinary(program, format, unsafe.Pointer(&bin[0]), int32(len(bin)))
+	var ok int32
+	gl.GetProgramiv(program, gl.LINK_STATUS, &ok)
+	if ok == 0 {
+		// The binary is stale (e.g. the driver changed without it's version
+		// string changing), so compile the shader normally.
+		logger.Debugf("%s | cached program binary rejected, recompiling", s.Name)
+		gl.DeleteProgram(program)
+		os.Remove(path)
+		return 0
+	}
+	return program
+}
+
+// shaderCachePrepare hints that the binary of the given program (which is
+// about to be linked) will be retrieved. It must be called inside renderExec.
+func (r *device) shaderCachePrepare(program uint32) {
+	if r.shaderCache.supported {
+		gl.ProgramParameteri(program, gl.PROGRAM_BINARY_RETRIEVABLE_HINT, gl.TRUE)
+	}
+}
+
+// shaderCacheStore stores the binary of the given linked program in the
+// cache. The file is written in the background. It must be called inside
+// renderExec.
+func (r *device) shaderCacheStore(s *gfx.Shader, program uint32) {
+	if !r.shaderCache.supported {
+		return
+	}
+	var length int32
+	gl.GetProgramiv(program, gl.PROGRAM_BINARY_LENGTH, &length)
+	if length == 0 {
+		return
+	}
+	header := len(shaderCacheMagic) + 4
+	data := make([]byte, header+int(length))
+	copy(data, shaderCacheMagic)
+	var format uint32
+	gl.GetProgramBinary(program, length, &length, &format, unsafe.Pointer(&data[header]))
+	binary.LittleEndian.PutUint32(data[len(shaderCacheMagic):], format)
+	data = data[:header+int(length)]
+
+	path := r.shaderCacheKey(s)
+	go func() {
+		if err := writeFileAtomic(path, data); err != nil {
+			r.warner.Warnf("ShaderCache: %v\n", err)
+		}
+	}()
+}
+
+// writeFileAtomic writes the data to the named file through a uniquely named
+// temporary file in the same directory, such that a partially written file
+// is never loaded and concurrent writers (e.g. other devices or processes
+// storing the same program) do not interleave their data.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
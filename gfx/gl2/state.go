@@ -5,19 +5,74 @@
 package gl2
 
 import (
+	"sync/atomic"
+
 	"azul3d.org/engine/gfx"
 	"azul3d.org/engine/gfx/internal/gl/2.0/gl"
 	"azul3d.org/engine/gfx/internal/glc"
-	"azul3d.org/engine/gfx/internal/tag"
 )
 
-// Set this to true to disable state guarding (i.e. avoiding useless OpenGL
-// state calls). This is useful for debugging the state guard code.
-const noStateGuard = tag.Gsgdebug
-
 type graphicsState struct {
 	*glc.GraphicsState
 	lastProgramPointSizeExt bool
+
+	// The pipeline whose fixed-function state was last used (see useState),
+	// or nil if the state may have changed since.
+	pipeline *nativePipeline
+
+	// Object bindings. Unlike the state above they are not saved by Begin,
+	// instead Begin assumes they are at their defaults (nothing bound, with
+	// the first texture unit active) and Restore resets them to that.
+	bindings bindings
+
+	// The value of the deletions counter that the bindings are known to be
+	// up to date with.
+	deletions uint64
+}
+
+// deletions counts the calls to deleteTextures and deleteBuffers of every
+// device. Devices that share objects delete them on the device that loaded
+// them, leaving the bindings of the other devices naming objects that no
+// longer exist, and which OpenGL may reuse the names of (see syncDeletions).
+var deletions uint64
+
+// invalidBinding is a binding shadow value that never matches an object name,
+// such that the next bind is never skipped.
+const invalidBinding = ^uint32(0)
+
+// bindings are the shadowed object bindings of a graphics state.
+type bindings struct {
+	// The index of the active texture unit, and the texture bound to each
+	// unit (units past the end of the slice have no texture bound).
+	activeTexture uint32
+	textures      []uint32
+
+	// The sampler object bound to each texture unit (units past the end of
+	// the slice have none bound). Sampler objects are never deleted.
+	samplers []uint32
+
+	// The buffers bound to ARRAY_BUFFER and ELEMENT_ARRAY_BUFFER.
+	arrayBuffer, elementArrayBuffer uint32
+
+	// The state of each vertex attribute array (arrays past the end of the
+	// slice are disabled).
+	attribs []attribState
+}
+
+// attribState is the shadowed state of a single vertex attribute array.
+type attribState struct {
+	// Whether or not the array is enabled, and whether or not it is used by
+	// the draw currently being set up (see useAttribs).
+	enabled, used bool
+
+	// The buffer, size, type and byte offset last given to
+	// glVertexAttribPointer. If the buffer was deleted then the pointer is
+	// invalid and ptrValid is false.
+	buffer   uint32
+	size     int32
+	typ      uint32
+	offset   int
+	ptrValid bool
 }
 
 func (g *graphicsState) Begin(d *device) {
@@ -42,13 +97,24 @@ func (g *graphicsState) Begin(d *device) {
 			g.Multisample(true)
 		}
 	}
+
+	// Enable sRGB framebuffer conversion, if available and wanted.
+	if d.glFramebufferSRGB {
+		if d.SRGB() {
+			gl.Enable(gl.FRAMEBUFFER_SRGB)
+		} else {
+			gl.Disable(gl.FRAMEBUFFER_SRGB)
+		}
+	}
 }
 
 func (g *graphicsState) Restore(d *device) {
+	g.pipeline = nil
 	bounds := d.BaseCanvas.Bounds()
 	if !g.GraphicsState.Restore(bounds, g.restoreCustom) {
 		return
 	}
+	g.resetBindings()
 }
 
 func (g *graphicsState) beginCustom() {
@@ -80,7 +146,7 @@ func (g *graphicsState) restoreCustom() {
 
 // Uncommon because WebGL needs a js.Object data type.
 func (g *graphicsState) useProgram(p uint32) {
-	if noStateGuard || g.S.ShaderProgram != p {
+	if g.Guard(g.S.ShaderProgram != p) {
 		g.S.ShaderProgram = p
 		gl.UseProgram(p)
 	}
@@ -90,7 +156,7 @@ func (g *graphicsState) useProgram(p uint32) {
 //
 // TODO(slimsag): See if WebGL or OpenGL ES 2 expose this through an extension.
 func (g *graphicsState) depthClamp(v bool) {
-	if noStateGuard || g.S.DepthClamp != v {
+	if g.Guard(g.S.DepthClamp != v) {
 		g.C.Feature(gl.DEPTH_CLAMP, v)
 	}
 }
@@ -98,7 +164,7 @@ func (g *graphicsState) depthClamp(v bool) {
 // Specific to OpenGL 2 (OpenGL ES 2 and WebGL 1.0 both have shader program
 // point size enabled by default).
 func (g *graphicsState) programPointSizeExt(v bool) {
-	if noStateGuard || g.lastProgramPointSizeExt != v {
+	if g.Guard(g.lastProgramPointSizeExt != v) {
 		g.lastProgramPointSizeExt = v
 		g.C.Feature(gl.PROGRAM_POINT_SIZE_EXT, v)
 	}
@@ -110,7 +176,7 @@ func (g *graphicsState) programPointSizeExt(v bool) {
 //
 // TODO(slimsag): See if WebGL exposes this through an extension.
 func (g *graphicsState) stencilMaskSeparate(front, back uint) {
-	if noStateGuard || g.S.StencilFront.WriteMask != front || g.S.StencilBack.WriteMask != back {
+	if g.Guard(g.S.StencilFront.WriteMask != front || g.S.StencilBack.WriteMask != back) {
 		g.S.StencilFront.WriteMask = front
 		g.S.StencilBack.WriteMask = back
 
@@ -143,7 +209,7 @@ func (g *graphicsState) stencilFuncSeparate(front, back gfx.StencilState) {
 		return a.Cmp != b.Cmp || a.Reference != b.Reference || a.ReadMask != b.ReadMask
 	}
 
-	if noStateGuard || diff(g.S.StencilFront, front) || diff(g.S.StencilBack, back) {
+	if g.Guard(diff(g.S.StencilFront, front) || diff(g.S.StencilBack, back)) {
 		g.S.StencilFront.Cmp = front.Cmp
 		g.S.StencilFront.Reference = front.Reference
 		g.S.StencilFront.ReadMask = front.ReadMask
@@ -197,3 +263,196 @@ func (g *graphicsState) getStencilFuncSeparate(front, back *gfx.StencilState) {
 	back.Reference = uint(backRef)
 	back.ReadMask = uint(backValueMask)
 }
+
+// resetBindings unconditionally resets the object bindings to their defaults,
+// as they may have been changed behind our back (e.g. by a RunNative
+// function).
+func (g *graphicsState) resetBindings() {
+	b := &g.bindings
+	for i := len(b.textures) - 1; i >= 0; i-- {
+		gl.ActiveTexture(gl.TEXTURE0 + uint32(i))
+		gl.BindTexture(gl.TEXTURE_2D, 0)
+	}
+	gl.ActiveTexture(gl.TEXTURE0)
+	for i := range b.samplers {
+		gl.BindSampler(uint32(i), 0)
+	}
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, 0)
+	for i := range b.attribs {
+		gl.DisableVertexAttribArray(uint32(i))
+	}
+	*b = bindings{
+		textures: b.textures[:0],
+		samplers: b.samplers[:0],
+		attribs:  b.attribs[:0],
+	}
+}
+
+// syncDeletions invalidates the binding shadows if objects were deleted by
+// another device since they were last known to be up to date, as the names
+// they hold may have been reused for other objects. The bindings themselves
+// are left untouched, the next bind of each is just not skipped.
+func (g *graphicsState) syncDeletions() {
+	n := atomic.LoadUint64(&deletions)
+	if n == g.deletions {
+		return
+	}
+	g.deletions = n
+	b := &g.bindings
+	for unit := range b.textures {
+		b.textures[unit] = invalidBinding
+	}
+	b.arrayBuffer = invalidBinding
+	b.elementArrayBuffer = invalidBinding
+	for i := range b.attribs {
+		b.attribs[i].ptrValid = false
+	}
+}
+
+// deleted records that this device deleted objects, and updated it's own
+// binding shadows accordingly.
+func (g *graphicsState) deleted() {
+	n := atomic.AddUint64(&deletions, 1)
+	if n == g.deletions+1 {
+		// No other device deleted objects since the shadows were up to date.
+		g.deletions = n
+	}
+}
+
+// activeTexture makes the given texture unit index active.
+func (g *graphicsState) activeTexture(unit uint32) {
+	if g.Guard(g.bindings.activeTexture != unit) {
+		g.bindings.activeTexture = unit
+		gl.ActiveTexture(gl.TEXTURE0 + unit)
+	}
+}
+
+// bindTexture binds the given 2D texture to the active texture unit.
+func (g *graphicsState) bindTexture(id uint32) {
+	g.syncDeletions()
+	b := &g.bindings
+	unit := int(b.activeTexture)
+	var bound uint32
+	if unit < len(b.textures) {
+		bound = b.textures[unit]
+	}
+	if g.Guard(bound != id) {
+		for unit >= len(b.textures) {
+			b.textures = append(b.textures, 0)
+		}
+		b.textures[unit] = id
+		gl.BindTexture(gl.TEXTURE_2D, id)
+	}
+}
+
+// bindSampler binds the given sampler object to the given texture unit.
+func (g *graphicsState) bindSampler(unit, id uint32) {
+	b := &g.bindings
+	var bound uint32
+	if int(unit) < len(b.samplers) {
+		bound = b.samplers[unit]
+	}
+	if g.Guard(bound != id) {
+		for int(unit) >= len(b.samplers) {
+			b.samplers = append(b.samplers, 0)
+		}
+		b.samplers[unit] = id
+		gl.BindSampler(unit, id)
+	}
+}
+
+// deleteTextures deletes the given textures, which OpenGL implicitly unbinds
+// from each texture unit.
+func (g *graphicsState) deleteTextures(ids []uint32) {
+	if len(ids) == 0 {
+		return
+	}
+	gl.DeleteTextures(int32(len(ids)), &ids[0])
+	for unit, bound := range g.bindings.textures {
+		for _, id := range ids {
+			if bound == id {
+				g.bindings.textures[unit] = 0
+			}
+		}
+	}
+	g.deleted()
+}
+
+// bindBuffer binds the given buffer to the ARRAY_BUFFER or
+// ELEMENT_ARRAY_BUFFER target.
+func (g *graphicsState) bindBuffer(target, id uint32) {
+	g.syncDeletions()
+	bound := &g.bindings.arrayBuffer
+	if target == gl.ELEMENT_ARRAY_BUFFER {
+		bound = &g.bindings.elementArrayBuffer
+	}
+	if g.Guard(*bound != id) {
+		*bound = id
+		gl.BindBuffer(target, id)
+	}
+}
+
+// deleteBuffers deletes the given buffers, which OpenGL implicitly unbinds.
+// Vertex attribute arrays sourced from them must have their pointers
+// specified again.
+func (g *graphicsState) deleteBuffers(ids ...uint32) {
+	b := &g.bindings
+	for _, id := range ids {
+		if id == 0 {
+			continue
+		}
+		if b.arrayBuffer == id {
+			b.arrayBuffer = 0
+		}
+		if b.elementArrayBuffer == id {
+			b.elementArrayBuffer = 0
+		}
+		for i := range b.attribs {
+			if b.attribs[i].buffer == id {
+				b.attribs[i].ptrValid = false
+			}
+		}
+	}
+	if len(ids) > 0 {
+		gl.DeleteBuffers(int32(len(ids)), &ids[0])
+		g.deleted()
+	}
+}
+
+// attribPointer enables the given vertex attribute array and sources it from
+// the given buffer, with tightly packed elements of the given size and type.
+// The array is marked as used by the current draw (see useAttribs).
+func (g *graphicsState) attribPointer(location uint32, buffer uint32, size int32, typ uint32, offset int) {
+	g.syncDeletions()
+	b := &g.bindings
+	for int(location) >= len(b.attribs) {
+		b.attribs = append(b.attribs, attribState{})
+	}
+	a := &b.attribs[location]
+	a.used = true
+	if g.Guard(!a.enabled) {
+		a.enabled = true
+		gl.EnableVertexAttribArray(location)
+	}
+	if g.Guard(!a.ptrValid || a.buffer != buffer || a.size != size || a.typ != typ || a.offset != offset) {
+		g.bindBuffer(gl.ARRAY_BUFFER, buffer)
+		a.buffer, a.size, a.typ, a.offset, a.ptrValid = buffer, size, typ, offset, true
+		gl.VertexAttribPointer(location, size, typ, false, 0, gl.PtrOffset(offset))
+	}
+}
+
+// useAttribs disables each enabled vertex attribute array which was not used
+// (see attribPointer) since the last call, such that arrays stay enabled
+// across consecutive draws which use them.
+func (g *graphicsState) useAttribs() {
+	for i := range g.bindings.attribs {
+		a := &g.bindings.attribs[i]
+		if a.enabled && !a.used {
+			g.Changes++
+			a.enabled = false
+			gl.DisableVertexAttribArray(uint32(i))
+		}
+		a.used = false
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gl2
+
+import (
+	"image"
+	"runtime"
+	"sync"
+	"unsafe"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/internal/gl/2.0/gl"
+)
+
+// streamedTexture is a single streaming texture whose upload has been issued,
+// but which has not yet been marked as loaded.
+type streamedTexture struct {
+	t      *gfx.Texture
+	native *nativeTexture
+	done   []chan *gfx.Texture
+}
+
+// streamer holds the streaming texture state of a device.
+type streamer struct {
+	sync.Mutex
+
+	// Textures currently being streamed, and the done channels to signal
+	// when they are loaded.
+	loading map[*gfx.Texture][]chan *gfx.Texture
+
+	// Textures whose uploads have been issued, and textures which have been
+	// marked as loaded but whose OnLoad functions have not yet been called.
+	uploaded, loaded []streamedTexture
+
+	// The placeholder texture used in place of textures that are still
+	// loading, or nil if not yet created. Only touched inside renderExec.
+	placeholder *nativeTexture
+}
+
+// streamTexture begins streaming the given texture. It returns immediately.
+func (r *device) streamTexture(t *gfx.Texture, done chan *gfx.Texture) {
+	r.stream.Lock()
+	if r.stream.loading == nil {
+		r.stream.loading = make(map[*gfx.Texture][]chan *gfx.Texture)
+	}
+	chans, inProgress := r.stream.loading[t]
+	if done != nil {
+		chans = append(chans, done)
+	}
+	r.stream.loading[t] = chans
+	r.stream.Unlock()
+	if inProgress {
+		return
+	}
+
+	go func() {
+		// Prepare the image for uploading (which, for large non-RGBA images,
+		// can be quite slow itself).
+		src := prepareImage(r.devInfo.NPOT, t.Source)
+
+		r.renderExec <- func() bool {
+			if !r.glArbPixelBufferObject {
+				r.streamUpload(t, src, 0)
+				return false
+			}
+
+			// Create a PBO and map it, such that we can copy the pixels into
+			// it outside of the render loop.
+			var pbo uint32
+			gl.GenBuffers(1, &pbo)
+			gl.BindBuffer(gl.PIXEL_UNPACK_BUFFER, pbo)
+			gl.BufferData(gl.PIXEL_UNPACK_BUFFER, len(src.Pix), nil, gl.STREAM_DRAW)
+			ptr := gl.MapBuffer(gl.PIXEL_UNPACK_BUFFER, gl.WRITE_ONLY)
+			gl.BindBuffer(gl.PIXEL_UNPACK_BUFFER, 0)
+			if ptr == nil {
+				gl.DeleteBuffers(1, &pbo)
+				r.streamUpload(t, src, 0)
+				return false
+			}
+
+			go func() {
+				copy((*[1 << 30]byte)(ptr)[:len(src.Pix):len(src.Pix)], src.Pix)
+				r.renderExec <- func() bool {
+					gl.BindBuffer(gl.PIXEL_UNPACK_BUFFER, pbo)
+					gl.UnmapBuffer(gl.PIXEL_UNPACK_BUFFER)
+					r.streamUpload(t, src, pbo)
+					gl.BindBuffer(gl.PIXEL_UNPACK_BUFFER, 0)
+
+					// OpenGL keeps the PBO alive until the upload completes.
+					gl.DeleteBuffers(1, &pbo)
+					return false
+				}
+			}()
+			return false
+		}
+	}()
+}
+
+// streamUpload uploads the texture image from the bound PBO (if pbo != 0) or
+// else directly from the source image. It must be called inside renderExec.
+func (r *device) streamUpload(t *gfx.Texture, src *image.RGBA, pbo uint32) {
+	// Determine appropriate internal image format.
+	internalFormat := r.textureInternalFormat(t.Format)
+
+	// Initialize native texture.
+	bounds := src.Bounds()
+	native := newNativeTexture(r, internalFormat, bounds.Dx(), bounds.Dy())
+	if t.MinFilter.Mipmapped() {
+		gl.TexParameteri(gl.TEXTURE_2D, gl.GENERATE_MIPMAP, int32(gl.TRUE))
+	}
+
+	// Upload the image, from the PBO at offset zero if we have one.
+	pixels := unsafe.Pointer(nil)
+	if pbo == 0 {
+		pixels = unsafe.Pointer(&src.Pix[0])
+	}
+	gl.TexImage2D(
+		gl.TEXTURE_2D,
+		0,
+		internalFormat,
+		int32(bounds.Dx()),
+		int32(bounds.Dy()),
+		0,
+		gl.RGBA,
+		gl.UNSIGNED_BYTE,
+		pixels,
+	)
+	r.graphicsState.bindTexture(0)
+
+	// Label the texture for graphics debuggers.
+	r.labelObject(labelTexture, native.id, t.Label)
+
+	// Account for the texture's memory.
+	native.size = textureSize(internalFormat, bounds.Dx(), bounds.Dy(), t.MinFilter.Mipmapped())
+	r.mem.addTextures(native.size)
+
+	r.stream.Lock()
+	r.stream.uploaded = append(r.stream.uploaded, streamedTexture{
+		t:      t,
+		native: native,
+	})
+	r.stream.Unlock()
+}
+
+// streamEndFrame marks each texture whose upload was issued as loaded, such
+// that it is used in place of the placeholder from the next frame onward. It
+// must be called inside renderExec.
+func (r *device) streamEndFrame() {
+	r.stream.Lock()
+	for _, s := range r.stream.uploaded {
+		s.t.Loaded = true
+		s.t.NativeTexture = s.native
+		s.t.ClearData()
+
+		// Attach a finalizer to the texture that will later free it.
+		runtime.SetFinalizer(s.native, finalizeTexture)
+
+		s.done = r.stream.loading[s.t]
+		delete(r.stream.loading, s.t)
+		r.stream.loaded = append(r.stream.loaded, s)
+	}
+	r.stream.uploaded = r.stream.uploaded[:0]
+	r.stream.Unlock()
+}
+
+// streamNotify calls the OnLoad functions of, and signals the done channels
+// of, each texture that streamEndFrame marked as loaded. It is called by
+// Render (outside of renderExec).
+func (r *device) streamNotify() {
+	r.stream.Lock()
+	loaded := r.stream.loaded
+	r.stream.loaded = nil
+	r.stream.Unlock()
+
+	for _, s := range loaded {
+		for _, f := range s.t.OnLoad {
+			f(s.t)
+		}
+		for _, done := range s.done {
+			select {
+			case done <- s.t:
+			default:
+			}
+		}
+	}
+}
+
+// streamPlaceholder returns the placeholder texture (a single opaque white
+// pixel) to be used in place of textures that are still being streamed,
+// creating it if needed. It must be called inside renderExec.
+func (r *device) streamPlaceholder() *nativeTexture {
+	if r.stream.placeholder != nil {
+		return r.stream.placeholder
+	}
+	native := newNativeTexture(r, gl.RGBA, 1, 1)
+	pixel := [4]uint8{255, 255, 255, 255}
+	gl.TexImage2D(
+		gl.TEXTURE_2D,
+		0,
+		gl.RGBA,
+		1,
+		1,
+		0,
+		gl.RGBA,
+		gl.UNSIGNED_BYTE,
+		unsafe.Pointer(&pixel[0]),
+	)
+	r.graphicsState.bindTexture(0)
+	r.stream.placeholder = native
+	return native
+}
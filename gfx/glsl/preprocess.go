@@ -0,0 +1,234 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package glsl implements a GLSL shader preprocessor.
+//
+// The preprocessor allows GLSL shader code to be shared across backends (e.g.
+// desktop OpenGL 2 and mobile OpenGL ES 2) and between shaders, by supporting
+// #include of shared files, injection of #define macros (e.g. per material
+// variant), and insertion of the appropriate #version header for the target
+// backend:
+//
+//  pp := &glsl.Preprocessor{
+//      Target: glsl.GL2,
+//      Include: glsl.Dir("shaders"),
+//      Defines: map[string]string{"NORMAL_MAP": "1"},
+//  }
+//  shader, err := pp.Shader("lit", vertSrc, fragSrc)
+//
+// Sources should be written in GLSL 1.20 / GLSL ES 1.00 style (i.e. using
+// attribute, varying, texture2D, and gl_FragColor) without a #version line;
+// when targeting GL3 compatibility macros are inserted for these.
+package glsl // import "azul3d.org/engine/gfx/glsl"
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"azul3d.org/engine/gfx"
+)
+
+// Target is a backend that shaders are preprocessed for.
+type Target int
+
+const (
+	// GL2 targets desktop OpenGL 2 (GLSL 1.20).
+	GL2 Target = iota
+
+	// GLES2 targets OpenGL ES 2 and WebGL (GLSL ES 1.00).
+	GLES2
+
+	// GL3 targets desktop OpenGL 3.2 core profile (GLSL 1.50).
+	GL3
+)
+
+// String returns a string representation of the target, which is also the
+// name of the macro defined for it, e.g. "GL2".
+func (t Target) String() string {
+	switch t {
+	case GL2:
+		return "GL2"
+	case GLES2:
+		return "GLES2"
+	case GL3:
+		return "GL3"
+	}
+	return fmt.Sprintf("Target(%d)", int(t))
+}
+
+// Stage is a shader stage.
+type Stage int
+
+const (
+	// Vertex is the vertex shader stage.
+	Vertex Stage = iota
+
+	// Fragment is the fragment shader stage.
+	Fragment
+)
+
+// header returns the version header (and compatibility macros) of the target
+// for the given stage.
+func (t Target) header(stage Stage) string {
+	switch t {
+	case GLES2:
+		h := "#version 100\n"
+		if stage == Fragment {
+			h += "precision mediump float;\n"
+		}
+		return h
+	case GL3:
+		h := "#version 150\n#define texture2D texture\n"
+		if stage == Vertex {
+			return h + "#define attribute in\n#define varying out\n"
+		}
+		return h + "#define varying in\nout vec4 azFragColor;\n#define gl_FragColor azFragColor\n"
+	}
+	return "#version 120\n"
+}
+
+// Dir returns an include function which reads included files from the given
+// directory on the filesystem.
+func Dir(dir string) func(name string) ([]byte, error) {
+	return func(name string) ([]byte, error) {
+		return ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	}
+}
+
+// maxIncludeDepth is the maximum depth of nested includes, after which an
+// error is returned (e.g. for an include cycle).
+const maxIncludeDepth = 32
+
+// Preprocessor preprocesses GLSL shader sources.
+type Preprocessor struct {
+	// Target is the backend to preprocess shaders for.
+	Target Target
+
+	// Include is called to read the file named by an #include directive,
+	// e.g. "lighting.glsl" for:
+	//
+	//  #include "lighting.glsl"
+	//
+	// If nil, #include directives result in an error.
+	Include func(name string) ([]byte, error)
+
+	// Defines is a map of macro names to their values, which are defined at
+	// the start of each preprocessed source (after the version header).
+	Defines map[string]string
+}
+
+// Process preprocesses the given GLSL source for the given shader stage. It
+// returns the resulting source, which begins with the version header of the
+// target backend followed by the macro definitions, or an error if an include
+// failed.
+//
+// Any #version directive in the source is removed, and the macro named by the
+// target (e.g. GL2) is defined as 1.
+//
+// Files containing #pragma once are only included once.
+func (p *Preprocessor) Process(src []byte, stage Stage) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(p.Target.header(stage))
+	fmt.Fprintf(&buf, "#define %s 1\n", p.Target)
+
+	// Sorted for deterministic output.
+	names := make([]string, 0, len(p.Defines))
+	for name := range p.Defines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&buf, "#define %s %s\n", name, p.Defines[name])
+	}
+
+	once := make(map[string]bool)
+	if err := p.process(&buf, "", src, once, 0); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// process writes the given source (named by file, or "" for the root source)
+// to buf, expanding #include directives recursively.
+func (p *Preprocessor) process(buf *bytes.Buffer, file string, src []byte, once map[string]bool, depth int) error {
+	if depth > maxIncludeDepth {
+		return fmt.Errorf("glsl: %s: includes nested too deeply (include cycle?)", file)
+	}
+	for i, line := range strings.Split(string(src), "\n") {
+		directive, arg := parseDirective(line)
+		switch directive {
+		case "version":
+			continue
+		case "pragma":
+			if arg == "once" {
+				once[file] = true
+				continue
+			}
+		case "include":
+			if len(arg) < 2 || !(arg[0] == '"' && arg[len(arg)-1] == '"' || arg[0] == '<' && arg[len(arg)-1] == '>') {
+				return fmt.Errorf("glsl: %s:%d: malformed #include directive", file, i+1)
+			}
+			name := arg[1 : len(arg)-1]
+			if once[name] {
+				continue
+			}
+			if p.Include == nil {
+				return fmt.Errorf("glsl: %s:%d: #include %q: no include function", file, i+1, name)
+			}
+			data, err := p.Include(name)
+			if err != nil {
+				return fmt.Errorf("glsl: %s:%d: #include %q: %v", file, i+1, name, err)
+			}
+			if err := p.process(buf, name, data, once, depth+1); err != nil {
+				return err
+			}
+			continue
+		}
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	return nil
+}
+
+// parseDirective parses a preprocessor directive line, e.g.:
+//
+//  #include "foo.glsl"
+//
+// returns "include" and `"foo.glsl"`. If the line is not a directive, empty
+// strings are returned.
+func parseDirective(line string) (directive, arg string) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "#") {
+		return "", ""
+	}
+	line = strings.TrimSpace(line[1:])
+	i := strings.IndexAny(line, " \t")
+	if i == -1 {
+		return line, ""
+	}
+	return line[:i], strings.TrimSpace(line[i:])
+}
+
+// Shader preprocesses the given vertex and fragment sources and returns a new
+// shader with the given name using them.
+func (p *Preprocessor) Shader(name string, vert, frag []byte) (*gfx.Shader, error) {
+	v, err := p.Process(vert, Vertex)
+	if err != nil {
+		return nil, err
+	}
+	f, err := p.Process(frag, Fragment)
+	if err != nil {
+		return nil, err
+	}
+	s := gfx.NewShader(name)
+	s.GLSL = &gfx.GLSLSources{
+		Vertex:   v,
+		Fragment: f,
+	}
+	return s, nil
+}
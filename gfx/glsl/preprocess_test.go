@@ -0,0 +1,87 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package glsl
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// mapInclude returns an include function reading from the given map.
+func mapInclude(files map[string]string) func(name string) ([]byte, error) {
+	return func(name string) ([]byte, error) {
+		data, ok := files[name]
+		if !ok {
+			return nil, fmt.Errorf("no such file")
+		}
+		return []byte(data), nil
+	}
+}
+
+func TestProcess(t *testing.T) {
+	p := &Preprocessor{
+		Target: GLES2,
+		Include: mapInclude(map[string]string{
+			"common.glsl": "#pragma once\nfloat common;",
+			"light.glsl":  "#include \"common.glsl\"\nfloat light;",
+		}),
+		Defines: map[string]string{"B": "2", "A": "1"},
+	}
+	src := "#version 120\n#include \"common.glsl\"\n  #include <light.glsl>\nvoid main() {}"
+	got, err := p.Process([]byte(src), Fragment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"#version 100",
+		"precision mediump float;",
+		"#define GLES2 1",
+		"#define A 1",
+		"#define B 2",
+		"float common;",
+		"float light;",
+		"void main() {}",
+		"",
+	}, "\n")
+	if string(got) != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestProcessGL3(t *testing.T) {
+	p := &Preprocessor{Target: GL3}
+	vert, err := p.Process([]byte("attribute vec3 Vertex;"), Vertex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(vert), "#version 150\n") || !strings.Contains(string(vert), "#define attribute in\n") {
+		t.Fatalf("unexpected vertex source:\n%s", vert)
+	}
+	frag, err := p.Process([]byte("void main() {}"), Fragment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(frag), "#define gl_FragColor azFragColor\n") {
+		t.Fatalf("unexpected fragment source:\n%s", frag)
+	}
+}
+
+func TestProcessErrors(t *testing.T) {
+	p := &Preprocessor{
+		Include: mapInclude(map[string]string{
+			"cycle.glsl": "#include \"cycle.glsl\"",
+		}),
+	}
+	for _, src := range []string{
+		"#include \"missing.glsl\"",
+		"#include missing.glsl",
+		"#include \"cycle.glsl\"",
+	} {
+		if _, err := p.Process([]byte(src), Vertex); err == nil {
+			t.Errorf("%q: expected error", src)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package imgutil loads and converts images into the RGBA layout that
+// textures are uploaded from.
+//
+// Images of any format registered with the image package are decoded, and
+// then optionally downscaled to fit the device's maximum texture size, flipped
+// vertically (for texture coordinates with a bottom-left origin, as in plain
+// OpenGL) and premultiplied by alpha:
+//
+//  import _ "image/png"
+//
+//  img, err := imgutil.Open("tree.png", imgutil.Options{
+//      Premultiply: true,
+//      MaxSize:     imgutil.MaxSize(d),
+//  })
+//  if err != nil {
+//      log.Fatal(err)
+//  }
+//  tex := gfx.NewTexture()
+//  tex.Source = img
+//  tex.Bounds = img.Bounds()
+package imgutil // import "azul3d.org/engine/gfx/imgutil"
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"io"
+	"os"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/internal/resize"
+	"azul3d.org/engine/gfx/internal/util"
+)
+
+// Options describes how an image is converted.
+type Options struct {
+	// Flip flips the image vertically, such that it's bottom row comes first,
+	// as expected by texture coordinates with a bottom-left origin.
+	Flip bool
+
+	// Premultiply premultiplies the color of each pixel by it's alpha, as
+	// expected by premultiplied alpha blending (see gfx.DefaultBlendState).
+	// Otherwise the pixels are left with straight alpha, as stored in e.g. PNG
+	// files. It has no effect on opaque images.
+	Premultiply bool
+
+	// MaxSize, if greater than zero, is the maximum width and height of the
+	// image. Larger images are downscaled to fit, keeping their aspect ratio.
+	MaxSize int
+}
+
+// MaxSize returns the maximum size of textures for the given device, for use
+// as Options.MaxSize, or zero if it is not available.
+func MaxSize(d gfx.Device) int {
+	if s := d.Info().MaxTextureSize; s > 0 {
+		return s
+	}
+	return 0
+}
+
+// RGBA returns the given image in the RGBA layout, with it's origin at (0, 0).
+// If it already is such an *image.RGBA it is returned as-is, otherwise it is
+// converted into a new one.
+func RGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) {
+		return rgba
+	}
+	b := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Src)
+	return rgba
+}
+
+// Convert converts the given image into a new image in the RGBA layout, with
+// it's origin at (0, 0), using the given options. The given image is never
+// modified.
+//
+// Unless o.Premultiply is set, the pixels of the returned image hold straight
+// alpha (as those of an *image.NRGBA would), which textures upload verbatim.
+func Convert(img image.Image, o Options) *image.RGBA {
+	b := img.Bounds()
+	if w, h, ok := fit(b.Dx(), b.Dy(), o.MaxSize); ok {
+		// Filtering averages premultiplied colors, such that transparent
+		// pixels do not bleed their color into their neighbours. Resize
+		// expects an origin of (0, 0).
+		img = resize.Resize(RGBA(img), image.Rect(0, 0, b.Dx(), b.Dy()), w, h)
+		b = img.Bounds()
+	}
+
+	var rgba *image.RGBA
+	r := image.Rect(0, 0, b.Dx(), b.Dy())
+	if o.Premultiply {
+		rgba = image.NewRGBA(r)
+		draw.Draw(rgba, r, img, b.Min, draw.Src)
+	} else {
+		nrgba := image.NewNRGBA(r)
+		draw.Draw(nrgba, r, img, b.Min, draw.Src)
+		rgba = &image.RGBA{Pix: nrgba.Pix, Stride: nrgba.Stride, Rect: r}
+	}
+	if o.Flip {
+		util.VerticalFlip(rgba)
+	}
+	return rgba
+}
+
+// fit returns the size that a w by h image must be downscaled to, keeping it's
+// aspect ratio, for it's width and height to be at most max. If it already
+// fits (or max <= 0), ok is false.
+func fit(w, h, max int) (fw, fh int, ok bool) {
+	if max <= 0 || (w <= max && h <= max) {
+		return w, h, false
+	}
+	if w >= h {
+		fw, fh = max, h*max/w
+	} else {
+		fw, fh = w*max/h, max
+	}
+	if fw < 1 {
+		fw = 1
+	}
+	if fh < 1 {
+		fh = 1
+	}
+	return fw, fh, true
+}
+
+// Decode decodes an image from r, in any format registered with the image
+// package, and converts it using the given options (see Convert).
+func Decode(r io.Reader, o Options) (*image.RGBA, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	return Convert(img, o), nil
+}
+
+// Open opens and decodes the named image file, see Decode.
+func Open(path string, o Options) (*image.RGBA, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, err := Decode(f, o)
+	if err != nil {
+		return nil, fmt.Errorf("imgutil: %s: %v", path, err)
+	}
+	return img, nil
+}
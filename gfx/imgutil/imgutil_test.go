@@ -0,0 +1,99 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imgutil
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// testImage returns a 4x2 straight alpha image whose top row is half
+// transparent red and whose bottom row is opaque blue.
+func testImage() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(10, 10, 14, 12))
+	for x := 10; x < 14; x++ {
+		img.SetNRGBA(x, 10, color.NRGBA{R: 255, A: 128})
+		img.SetNRGBA(x, 11, color.NRGBA{B: 255, A: 255})
+	}
+	return img
+}
+
+func TestRGBA(t *testing.T) {
+	rgba := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	if RGBA(rgba) != rgba {
+		t.Fatal("RGBA image was copied")
+	}
+	got := RGBA(testImage())
+	if got.Rect != image.Rect(0, 0, 4, 2) {
+		t.Fatalf("bounds = %v", got.Rect)
+	}
+	if c := got.RGBAAt(0, 0); c != (color.RGBA{R: 128, A: 128}) {
+		t.Fatalf("top-left = %v, want premultiplied red", c)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	src := testImage()
+	straight := Convert(src, Options{})
+	if c := straight.RGBAAt(0, 0); c != (color.RGBA{R: 255, A: 128}) {
+		t.Fatalf("top-left = %v, want straight red", c)
+	}
+	pre := Convert(src, Options{Premultiply: true, Flip: true})
+	if c := pre.RGBAAt(0, 0); c != (color.RGBA{B: 255, A: 255}) {
+		t.Fatalf("top-left = %v, want blue (flipped)", c)
+	}
+	if c := pre.RGBAAt(3, 1); c != (color.RGBA{R: 128, A: 128}) {
+		t.Fatalf("bottom-right = %v, want premultiplied red", c)
+	}
+	if c := src.NRGBAAt(10, 10); c != (color.NRGBA{R: 255, A: 128}) {
+		t.Fatal("source image was modified")
+	}
+
+	small := Convert(src, Options{MaxSize: 2, Premultiply: true})
+	if small.Rect != image.Rect(0, 0, 2, 1) {
+		t.Fatalf("downscaled bounds = %v, want 2x1", small.Rect)
+	}
+	if same := Convert(src, Options{MaxSize: 4}); same.Rect != image.Rect(0, 0, 4, 2) {
+		t.Fatalf("bounds = %v, want 4x2", same.Rect)
+	}
+}
+
+func TestFit(t *testing.T) {
+	for _, tc := range []struct {
+		w, h, max, fw, fh int
+		ok                bool
+	}{
+		{4096, 1024, 2048, 2048, 512, true},
+		{1024, 4096, 2048, 512, 2048, true},
+		{4096, 1, 1024, 1024, 1, true},
+		{512, 512, 2048, 512, 512, false},
+		{4096, 4096, 0, 4096, 4096, false},
+	} {
+		fw, fh, ok := fit(tc.w, tc.h, tc.max)
+		if fw != tc.fw || fh != tc.fh || ok != tc.ok {
+			t.Errorf("fit(%d, %d, %d) = %d, %d, %v", tc.w, tc.h, tc.max, fw, fh, ok)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage()); err != nil {
+		t.Fatal(err)
+	}
+	img, err := Decode(&buf, Options{Flip: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := img.RGBAAt(0, 1); c != (color.RGBA{R: 255, A: 128}) {
+		t.Fatalf("bottom-left = %v, want straight red", c)
+	}
+	if _, err := Decode(bytes.NewReader([]byte("not an image")), Options{}); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"unsafe"
+
+	"azul3d.org/engine/lmath"
+)
+
+// DrawCommand describes a single draw of a range of a mesh's indices. It is
+// laid out as GPUs read draw parameters from indirect buffers, i.e. as
+// OpenGL's DrawElementsIndirectCommand.
+type DrawCommand struct {
+	// Count is the number of indices drawn, starting at FirstIndex.
+	Count uint32
+
+	// InstanceCount is the number of instances drawn, which is one unless the
+	// object's shader uses instancing (see DeviceInfo.Instancing). If zero,
+	// the command draws nothing.
+	InstanceCount uint32
+
+	// FirstIndex is the index, into the mesh's indices, of the first index
+	// drawn.
+	FirstIndex uint32
+
+	// BaseVertex is added to each index drawn. It must be zero on devices
+	// which do not support indirect drawing (see DeviceInfo.DrawIndirect).
+	BaseVertex int32
+
+	// BaseInstance is the instance number of the first instance drawn. It is
+	// ignored by devices which do not support indirect drawing.
+	BaseInstance uint32
+}
+
+// DrawCommandSize is the size of a DrawCommand in bytes, i.e. the stride of
+// draw commands in an indirect buffer.
+const DrawCommandSize = int(unsafe.Sizeof(DrawCommand{}))
+
+// Indirect is a buffer of draw commands, each drawing a range of an object's
+// mesh (e.g. one of many instances of a prop merged into a single mesh), see
+// Object.Indirect.
+//
+// Each command may have a bounding sphere, against which the view frustum is
+// culled at each draw. On capable devices (see DeviceInfo.ComputeCulling)
+// this is done by a compute shader on the GPU, which writes the visible
+// commands to the indirect buffer drawn from, such that the CPU cost of
+// drawing does not grow with the number of commands. Otherwise the commands
+// are culled on the CPU, see the Cull method.
+//
+// Once loaded, changes to the commands and bounds have no effect until the
+// buffer is reloaded, which is done by setting Loaded to false.
+//
+// An indirect buffer and it's methods are not safe for access from multiple
+// goroutines concurrently.
+type Indirect struct {
+	// The native object of this indirect buffer. Once it is loaded this field
+	// will be initialized by the device. Only device implementations should
+	// assign values to this field.
+	NativeIndirect Destroyable
+
+	// Weather or not this indirect buffer is currently loaded or not.
+	Loaded bool
+
+	// The draw commands.
+	Commands []DrawCommand
+
+	// The bounding spheres of each command, in the object's space. If empty,
+	// the commands are not culled. Otherwise there must be one for each
+	// command.
+	Bounds []lmath.Sphere
+}
+
+// Cull appends the commands whose bounds overlap the given frustum (in the
+// object's space) to dst and returns it. If the buffer has no bounds, all of
+// the commands are appended. Commands which draw no instances are omitted.
+func (i *Indirect) Cull(dst []DrawCommand, f lmath.Frustum) []DrawCommand {
+	for n, c := range i.Commands {
+		if c.InstanceCount == 0 {
+			continue
+		}
+		if len(i.Bounds) > 0 && !f.OverlapsSphere(i.Bounds[n]) {
+			continue
+		}
+		dst = append(dst, c)
+	}
+	return dst
+}
+
+// Copy returns a new copy of this indirect buffer. Explicitily not copied is
+// the native object and the loaded status.
+func (i *Indirect) Copy() *Indirect {
+	return &Indirect{
+		Commands: append([]DrawCommand(nil), i.Commands...),
+		Bounds:   append([]lmath.Sphere(nil), i.Bounds...),
+	}
+}
+
+// Destroy destroys the native indirect buffer, after which it must be loaded
+// again to be used.
+func (i *Indirect) Destroy() {
+	if i.NativeIndirect != nil {
+		i.NativeIndirect.Destroy()
+		i.NativeIndirect = nil
+	}
+	i.Loaded = false
+}
+
+// NewIndirect returns a new indirect buffer with the given commands and their
+// bounds (which may be nil).
+func NewIndirect(commands []DrawCommand, bounds []lmath.Sphere) *Indirect {
+	return &Indirect{
+		Commands: commands,
+		Bounds:   bounds,
+	}
+}
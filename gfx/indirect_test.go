@@ -0,0 +1,50 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"testing"
+
+	"azul3d.org/engine/lmath"
+)
+
+func TestDrawCommandSize(t *testing.T) {
+	// GPUs read five 32-bit integers per command.
+	if DrawCommandSize != 20 {
+		t.Fatalf("DrawCommandSize = %d, want 20", DrawCommandSize)
+	}
+}
+
+func TestIndirectCull(t *testing.T) {
+	// A frustum looking down -Z from the origin, as OpenGL does.
+	f := lmath.FrustumFromMat4(lmath.Mat4Perspective(90, 1, 1, 100))
+	ind := NewIndirect([]DrawCommand{
+		{Count: 3, InstanceCount: 1, FirstIndex: 0},
+		{Count: 3, InstanceCount: 1, FirstIndex: 3},
+		{Count: 3, InstanceCount: 0, FirstIndex: 6},
+		{Count: 3, InstanceCount: 1, FirstIndex: 9},
+	}, []lmath.Sphere{
+		{Center: lmath.Vec3{Z: -10}, Radius: 1},
+		{Center: lmath.Vec3{Z: 10}, Radius: 1},
+		{Center: lmath.Vec3{Z: -10}, Radius: 1},
+		{Center: lmath.Vec3{X: 10.5, Z: -10}, Radius: 1},
+	})
+	got := ind.Cull(nil, f)
+	if len(got) != 2 || got[0].FirstIndex != 0 || got[1].FirstIndex != 9 {
+		t.Fatalf("got %v", got)
+	}
+
+	// Without bounds, only the commands drawing nothing are culled.
+	ind.Bounds = nil
+	if got = ind.Cull(got[:0], f); len(got) != 3 {
+		t.Fatalf("got %v", got)
+	}
+
+	cpy := ind.Copy()
+	cpy.Commands[0].Count = 6
+	if ind.Commands[0].Count != 3 {
+		t.Fatal("Copy did not copy the commands")
+	}
+}
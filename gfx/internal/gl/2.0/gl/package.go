@@ -121,21 +121,27 @@ package gl
 // typedef void  (APIENTRYP GPATTACHSHADER)(GLuint  program, GLuint  shader);
 // typedef void  (APIENTRYP GPBEGINQUERY)(GLenum  target, GLuint  id);
 // typedef void  (APIENTRYP GPBINDBUFFER)(GLenum  target, GLuint  buffer);
+// typedef void  (APIENTRYP GPBINDBUFFERBASE)(GLenum  target, GLuint  index, GLuint  buffer);
 // typedef void  (APIENTRYP GPBINDFRAMEBUFFER)(GLenum  target, GLuint  framebuffer);
 // typedef void  (APIENTRYP GPBINDRENDERBUFFER)(GLenum  target, GLuint  renderbuffer);
+// typedef void  (APIENTRYP GPBINDSAMPLER)(GLuint  unit, GLuint  sampler);
 // typedef void  (APIENTRYP GPBINDTEXTURE)(GLenum  target, GLuint  texture);
 // typedef void  (APIENTRYP GPBLENDCOLOR)(GLfloat  red, GLfloat  green, GLfloat  blue, GLfloat  alpha);
 // typedef void  (APIENTRYP GPBLENDEQUATIONSEPARATE)(GLenum  modeRGB, GLenum  modeAlpha);
 // typedef void  (APIENTRYP GPBLENDFUNCSEPARATE)(GLenum  sfactorRGB, GLenum  dfactorRGB, GLenum  sfactorAlpha, GLenum  dfactorAlpha);
 // typedef void  (APIENTRYP GPBUFFERDATA)(GLenum  target, GLsizeiptr  size, const void * data, GLenum  usage);
+// typedef void  (APIENTRYP GPBUFFERSTORAGE)(GLenum  target, GLsizeiptr  size, const void * data, GLbitfield  flags);
 // typedef GLenum  (APIENTRYP GPCHECKFRAMEBUFFERSTATUS)(GLenum  target);
 // typedef void  (APIENTRYP GPCLEAR)(GLbitfield  mask);
 // typedef void  (APIENTRYP GPCLEARCOLOR)(GLfloat  red, GLfloat  green, GLfloat  blue, GLfloat  alpha);
 // typedef void  (APIENTRYP GPCLEARDEPTH)(GLdouble  depth);
 // typedef void  (APIENTRYP GPCLEARDEPTHF)(GLfloat  d);
 // typedef void  (APIENTRYP GPCLEARSTENCIL)(GLint  s);
+// typedef GLenum  (APIENTRYP GPCLIENTWAITSYNC)(GLsync  sync, GLbitfield  flags, GLuint64  timeout);
 // typedef void  (APIENTRYP GPCOLORMASK)(GLboolean  red, GLboolean  green, GLboolean  blue, GLboolean  alpha);
 // typedef void  (APIENTRYP GPCOMPILESHADER)(GLuint  shader);
+// typedef void  (APIENTRYP GPCOMPRESSEDTEXIMAGE2D)(GLenum  target, GLint  level, GLenum  internalformat, GLsizei  width, GLsizei  height, GLint  border, GLsizei  imageSize, const void * data);
+// typedef void  (APIENTRYP GPCOPYTEXSUBIMAGE2D)(GLenum  target, GLint  level, GLint  xoffset, GLint  yoffset, GLint  x, GLint  y, GLsizei  width, GLsizei  height);
 // typedef GLuint  (APIENTRYP GPCREATEPROGRAM)();
 // typedef GLuint  (APIENTRYP GPCREATESHADER)(GLenum  type);
 // typedef void  (APIENTRYP GPCULLFACE)(GLenum  mode);
@@ -146,16 +152,21 @@ package gl
 // typedef void  (APIENTRYP GPDELETEQUERIES)(GLsizei  n, const GLuint * ids);
 // typedef void  (APIENTRYP GPDELETERENDERBUFFERS)(GLsizei  n, const GLuint * renderbuffers);
 // typedef void  (APIENTRYP GPDELETESHADER)(GLuint  shader);
+// typedef void  (APIENTRYP GPDELETESYNC)(GLsync  sync);
 // typedef void  (APIENTRYP GPDELETETEXTURES)(GLsizei  n, const GLuint * textures);
 // typedef void  (APIENTRYP GPDEPTHFUNC)(GLenum  func);
 // typedef void  (APIENTRYP GPDEPTHMASK)(GLboolean  flag);
 // typedef void  (APIENTRYP GPDISABLE)(GLenum  cap);
 // typedef void  (APIENTRYP GPDISABLEVERTEXATTRIBARRAY)(GLuint  index);
+// typedef void  (APIENTRYP GPDISPATCHCOMPUTE)(GLuint  num_groups_x, GLuint  num_groups_y, GLuint  num_groups_z);
 // typedef void  (APIENTRYP GPDRAWARRAYS)(GLenum  mode, GLint  first, GLsizei  count);
 // typedef void  (APIENTRYP GPDRAWELEMENTS)(GLenum  mode, GLsizei  count, GLenum  type, const void * indices);
+// typedef void  (APIENTRYP GPDRAWELEMENTSINSTANCED)(GLenum  mode, GLsizei  count, GLenum  type, const void * indices, GLsizei  instancecount);
+// typedef void  (APIENTRYP GPDRAWELEMENTSINSTANCEDARB)(GLenum  mode, GLsizei  count, GLenum  type, const void * indices, GLsizei  primcount);
 // typedef void  (APIENTRYP GPENABLE)(GLenum  cap);
 // typedef void  (APIENTRYP GPENABLEVERTEXATTRIBARRAY)(GLuint  index);
 // typedef void  (APIENTRYP GPENDQUERY)(GLenum  target);
+// typedef GLsync  (APIENTRYP GPFENCESYNC)(GLenum  condition, GLbitfield  flags);
 // typedef void  (APIENTRYP GPFINISH)();
 // typedef void  (APIENTRYP GPFLUSH)();
 // typedef void  (APIENTRYP GPFRAMEBUFFERRENDERBUFFER)(GLenum  target, GLenum  attachment, GLenum  renderbuffertarget, GLuint  renderbuffer);
@@ -164,31 +175,52 @@ package gl
 // typedef void  (APIENTRYP GPGENFRAMEBUFFERS)(GLsizei  n, GLuint * framebuffers);
 // typedef void  (APIENTRYP GPGENQUERIES)(GLsizei  n, GLuint * ids);
 // typedef void  (APIENTRYP GPGENRENDERBUFFERS)(GLsizei  n, GLuint * renderbuffers);
+// typedef void  (APIENTRYP GPGENSAMPLERS)(GLsizei  count, GLuint * samplers);
 // typedef void  (APIENTRYP GPGENTEXTURES)(GLsizei  n, GLuint * textures);
 // typedef void  (APIENTRYP GPGENERATEMIPMAP)(GLenum  target);
+// typedef void  (APIENTRYP GPGETACTIVEATTRIB)(GLuint  program, GLuint  index, GLsizei  bufSize, GLsizei * length, GLint * size, GLenum * type, GLchar * name);
+// typedef void  (APIENTRYP GPGETACTIVEUNIFORM)(GLuint  program, GLuint  index, GLsizei  bufSize, GLsizei * length, GLint * size, GLenum * type, GLchar * name);
 // typedef GLint  (APIENTRYP GPGETATTRIBLOCATION)(GLuint  program, const GLchar * name);
 // typedef void  (APIENTRYP GPGETBOOLEANV)(GLenum  pname, GLboolean * data);
 // typedef void  (APIENTRYP GPGETDOUBLEV)(GLenum  pname, GLdouble * data);
 // typedef GLenum  (APIENTRYP GPGETERROR)();
 // typedef void  (APIENTRYP GPGETFLOATV)(GLenum  pname, GLfloat * data);
 // typedef void  (APIENTRYP GPGETINTEGERV)(GLenum  pname, GLint * data);
+// typedef void  (APIENTRYP GPGETPROGRAMBINARY)(GLuint  program, GLsizei  bufSize, GLsizei * length, GLenum * binaryFormat, void * binary);
 // typedef void  (APIENTRYP GPGETPROGRAMINFOLOG)(GLuint  program, GLsizei  bufSize, GLsizei * length, GLchar * infoLog);
 // typedef void  (APIENTRYP GPGETPROGRAMIV)(GLuint  program, GLenum  pname, GLint * params);
 // typedef void  (APIENTRYP GPGETQUERYOBJECTIV)(GLuint  id, GLenum  pname, GLint * params);
 // typedef void  (APIENTRYP GPGETQUERYIV)(GLenum  target, GLenum  pname, GLint * params);
+// typedef void  (APIENTRYP GPGETQUERYOBJECTUI64V)(GLuint  id, GLenum  pname, GLuint64 * params);
 // typedef void  (APIENTRYP GPGETSHADERINFOLOG)(GLuint  shader, GLsizei  bufSize, GLsizei * length, GLchar * infoLog);
 // typedef void  (APIENTRYP GPGETSHADERIV)(GLuint  shader, GLenum  pname, GLint * params);
 // typedef const GLubyte * (APIENTRYP GPGETSTRING)(GLenum  name);
 // typedef GLint  (APIENTRYP GPGETUNIFORMLOCATION)(GLuint  program, const GLchar * name);
+// typedef void  (APIENTRYP GPLABELOBJECTEXT)(GLenum  xtype, GLuint  object, GLsizei  length, const GLchar * label);
 // typedef void  (APIENTRYP GPLINKPROGRAM)(GLuint  program);
+// typedef void * (APIENTRYP GPMAPBUFFER)(GLenum  target, GLenum  access);
+// typedef void * (APIENTRYP GPMAPBUFFERRANGE)(GLenum  target, GLintptr  offset, GLsizeiptr  length, GLbitfield  access);
+// typedef void  (APIENTRYP GPMEMORYBARRIER)(GLbitfield  barriers);
+// typedef void  (APIENTRYP GPMULTIDRAWELEMENTSINDIRECT)(GLenum  mode, GLenum  type, const void * indirect, GLsizei  drawcount, GLsizei  stride);
+// typedef void  (APIENTRYP GPOBJECTLABEL)(GLenum  identifier, GLuint  name, GLsizei  length, const GLchar * label);
+// typedef void  (APIENTRYP GPPOPDEBUGGROUP)();
+// typedef void  (APIENTRYP GPPOPGROUPMARKEREXT)();
+// typedef void  (APIENTRYP GPPROGRAMBINARY)(GLuint  program, GLenum  binaryFormat, const void * binary, GLsizei  length);
+// typedef void  (APIENTRYP GPPROGRAMPARAMETERI)(GLuint  program, GLenum  pname, GLint  value);
+// typedef void  (APIENTRYP GPPUSHDEBUGGROUP)(GLenum  source, GLuint  id, GLsizei  length, const GLchar * message);
+// typedef void  (APIENTRYP GPPUSHGROUPMARKEREXT)(GLsizei  length, const GLchar * marker);
 // typedef void  (APIENTRYP GPREADPIXELS)(GLint  x, GLint  y, GLsizei  width, GLsizei  height, GLenum  format, GLenum  type, void * pixels);
 // typedef void  (APIENTRYP GPRENDERBUFFERSTORAGEMULTISAMPLE)(GLenum  target, GLsizei  samples, GLenum  internalformat, GLsizei  width, GLsizei  height);
+// typedef void  (APIENTRYP GPSAMPLERPARAMETERF)(GLuint  sampler, GLenum  pname, GLfloat  param);
+// typedef void  (APIENTRYP GPSAMPLERPARAMETERFV)(GLuint  sampler, GLenum  pname, const GLfloat * param);
+// typedef void  (APIENTRYP GPSAMPLERPARAMETERI)(GLuint  sampler, GLenum  pname, GLint  param);
 // typedef void  (APIENTRYP GPSCISSOR)(GLint  x, GLint  y, GLsizei  width, GLsizei  height);
 // typedef void  (APIENTRYP GPSHADERSOURCE)(GLuint  shader, GLsizei  count, const GLchar *const* string, const GLint * length);
 // typedef void  (APIENTRYP GPSTENCILFUNCSEPARATE)(GLenum  face, GLenum  func, GLint  ref, GLuint  mask);
 // typedef void  (APIENTRYP GPSTENCILMASKSEPARATE)(GLenum  face, GLuint  mask);
 // typedef void  (APIENTRYP GPSTENCILOPSEPARATE)(GLenum  face, GLenum  sfail, GLenum  dpfail, GLenum  dppass);
 // typedef void  (APIENTRYP GPTEXIMAGE2D)(GLenum  target, GLint  level, GLint  internalformat, GLsizei  width, GLsizei  height, GLint  border, GLenum  format, GLenum  type, const void * pixels);
+// typedef void  (APIENTRYP GPTEXPARAMETERF)(GLenum  target, GLenum  pname, GLfloat  param);
 // typedef void  (APIENTRYP GPTEXPARAMETERFV)(GLenum  target, GLenum  pname, const GLfloat * params);
 // typedef void  (APIENTRYP GPTEXPARAMETERI)(GLenum  target, GLenum  pname, GLint  param);
 // typedef void  (APIENTRYP GPUNIFORM1FV)(GLint  location, GLsizei  count, const GLfloat * value);
@@ -198,6 +230,7 @@ package gl
 // typedef void  (APIENTRYP GPUNIFORM3FV)(GLint  location, GLsizei  count, const GLfloat * value);
 // typedef void  (APIENTRYP GPUNIFORM4FV)(GLint  location, GLsizei  count, const GLfloat * value);
 // typedef void  (APIENTRYP GPUNIFORMMATRIX4FV)(GLint  location, GLsizei  count, GLboolean  transpose, const GLfloat * value);
+// typedef GLboolean  (APIENTRYP GPUNMAPBUFFER)(GLenum  target);
 // typedef void  (APIENTRYP GPUSEPROGRAM)(GLuint  program);
 // typedef void  (APIENTRYP GPVERTEXATTRIBPOINTER)(GLuint  index, GLint  size, GLenum  type, GLboolean  normalized, GLsizei  stride, const void * pointer);
 // typedef void  (APIENTRYP GPVIEWPORT)(GLint  x, GLint  y, GLsizei  width, GLsizei  height);
@@ -213,12 +246,18 @@ package gl
 // static void  glowBindBuffer(GPBINDBUFFER fnptr, GLenum  target, GLuint  buffer) {
 //   (*fnptr)(target, buffer);
 // }
+// static void  glowBindBufferBase(GPBINDBUFFERBASE fnptr, GLenum  target, GLuint  index, GLuint  buffer) {
+//   (*fnptr)(target, index, buffer);
+// }
 // static void  glowBindFramebuffer(GPBINDFRAMEBUFFER fnptr, GLenum  target, GLuint  framebuffer) {
 //   (*fnptr)(target, framebuffer);
 // }
 // static void  glowBindRenderbuffer(GPBINDRENDERBUFFER fnptr, GLenum  target, GLuint  renderbuffer) {
 //   (*fnptr)(target, renderbuffer);
 // }
+// static void  glowBindSampler(GPBINDSAMPLER fnptr, GLuint  unit, GLuint  sampler) {
+//   (*fnptr)(unit, sampler);
+// }
 // static void  glowBindTexture(GPBINDTEXTURE fnptr, GLenum  target, GLuint  texture) {
 //   (*fnptr)(target, texture);
 // }
@@ -234,6 +273,9 @@ package gl
 // static void  glowBufferData(GPBUFFERDATA fnptr, GLenum  target, GLsizeiptr  size, const void * data, GLenum  usage) {
 //   (*fnptr)(target, size, data, usage);
 // }
+// static void  glowBufferStorage(GPBUFFERSTORAGE fnptr, GLenum  target, GLsizeiptr  size, const void * data, GLbitfield  flags) {
+//   (*fnptr)(target, size, data, flags);
+// }
 // static GLenum  glowCheckFramebufferStatus(GPCHECKFRAMEBUFFERSTATUS fnptr, GLenum  target) {
 //   return (*fnptr)(target);
 // }
@@ -252,12 +294,21 @@ package gl
 // static void  glowClearStencil(GPCLEARSTENCIL fnptr, GLint  s) {
 //   (*fnptr)(s);
 // }
+// static GLenum  glowClientWaitSync(GPCLIENTWAITSYNC fnptr, GLsync  sync, GLbitfield  flags, GLuint64  timeout) {
+//   return (*fnptr)(sync, flags, timeout);
+// }
 // static void  glowColorMask(GPCOLORMASK fnptr, GLboolean  red, GLboolean  green, GLboolean  blue, GLboolean  alpha) {
 //   (*fnptr)(red, green, blue, alpha);
 // }
 // static void  glowCompileShader(GPCOMPILESHADER fnptr, GLuint  shader) {
 //   (*fnptr)(shader);
 // }
+// static void  glowCompressedTexImage2D(GPCOMPRESSEDTEXIMAGE2D fnptr, GLenum  target, GLint  level, GLenum  internalformat, GLsizei  width, GLsizei  height, GLint  border, GLsizei  imageSize, const void * data) {
+//   (*fnptr)(target, level, internalformat, width, height, border, imageSize, data);
+// }
+// static void  glowCopyTexSubImage2D(GPCOPYTEXSUBIMAGE2D fnptr, GLenum  target, GLint  level, GLint  xoffset, GLint  yoffset, GLint  x, GLint  y, GLsizei  width, GLsizei  height) {
+//   (*fnptr)(target, level, xoffset, yoffset, x, y, width, height);
+// }
 // static GLuint  glowCreateProgram(GPCREATEPROGRAM fnptr) {
 //   return (*fnptr)();
 // }
@@ -288,6 +339,9 @@ package gl
 // static void  glowDeleteShader(GPDELETESHADER fnptr, GLuint  shader) {
 //   (*fnptr)(shader);
 // }
+// static void  glowDeleteSync(GPDELETESYNC fnptr, GLsync  sync) {
+//   (*fnptr)(sync);
+// }
 // static void  glowDeleteTextures(GPDELETETEXTURES fnptr, GLsizei  n, const GLuint * textures) {
 //   (*fnptr)(n, textures);
 // }
@@ -303,12 +357,21 @@ package gl
 // static void  glowDisableVertexAttribArray(GPDISABLEVERTEXATTRIBARRAY fnptr, GLuint  index) {
 //   (*fnptr)(index);
 // }
+// static void  glowDispatchCompute(GPDISPATCHCOMPUTE fnptr, GLuint  num_groups_x, GLuint  num_groups_y, GLuint  num_groups_z) {
+//   (*fnptr)(num_groups_x, num_groups_y, num_groups_z);
+// }
 // static void  glowDrawArrays(GPDRAWARRAYS fnptr, GLenum  mode, GLint  first, GLsizei  count) {
 //   (*fnptr)(mode, first, count);
 // }
 // static void  glowDrawElements(GPDRAWELEMENTS fnptr, GLenum  mode, GLsizei  count, GLenum  type, const void * indices) {
 //   (*fnptr)(mode, count, type, indices);
 // }
+// static void  glowDrawElementsInstanced(GPDRAWELEMENTSINSTANCED fnptr, GLenum  mode, GLsizei  count, GLenum  type, const void * indices, GLsizei  instancecount) {
+//   (*fnptr)(mode, count, type, indices, instancecount);
+// }
+// static void  glowDrawElementsInstancedARB(GPDRAWELEMENTSINSTANCEDARB fnptr, GLenum  mode, GLsizei  count, GLenum  type, const void * indices, GLsizei  primcount) {
+//   (*fnptr)(mode, count, type, indices, primcount);
+// }
 // static void  glowEnable(GPENABLE fnptr, GLenum  cap) {
 //   (*fnptr)(cap);
 // }
@@ -318,6 +381,9 @@ package gl
 // static void  glowEndQuery(GPENDQUERY fnptr, GLenum  target) {
 //   (*fnptr)(target);
 // }
+// static GLsync  glowFenceSync(GPFENCESYNC fnptr, GLenum  condition, GLbitfield  flags) {
+//   return (*fnptr)(condition, flags);
+// }
 // static void  glowFinish(GPFINISH fnptr) {
 //   (*fnptr)();
 // }
@@ -342,12 +408,21 @@ package gl
 // static void  glowGenRenderbuffers(GPGENRENDERBUFFERS fnptr, GLsizei  n, GLuint * renderbuffers) {
 //   (*fnptr)(n, renderbuffers);
 // }
+// static void  glowGenSamplers(GPGENSAMPLERS fnptr, GLsizei  count, GLuint * samplers) {
+//   (*fnptr)(count, samplers);
+// }
 // static void  glowGenTextures(GPGENTEXTURES fnptr, GLsizei  n, GLuint * textures) {
 //   (*fnptr)(n, textures);
 // }
 // static void  glowGenerateMipmap(GPGENERATEMIPMAP fnptr, GLenum  target) {
 //   (*fnptr)(target);
 // }
+// static void  glowGetActiveAttrib(GPGETACTIVEATTRIB fnptr, GLuint  program, GLuint  index, GLsizei  bufSize, GLsizei * length, GLint * size, GLenum * type, GLchar * name) {
+//   (*fnptr)(program, index, bufSize, length, size, type, name);
+// }
+// static void  glowGetActiveUniform(GPGETACTIVEUNIFORM fnptr, GLuint  program, GLuint  index, GLsizei  bufSize, GLsizei * length, GLint * size, GLenum * type, GLchar * name) {
+//   (*fnptr)(program, index, bufSize, length, size, type, name);
+// }
 // static GLint  glowGetAttribLocation(GPGETATTRIBLOCATION fnptr, GLuint  program, const GLchar * name) {
 //   return (*fnptr)(program, name);
 // }
@@ -366,6 +441,9 @@ package gl
 // static void  glowGetIntegerv(GPGETINTEGERV fnptr, GLenum  pname, GLint * data) {
 //   (*fnptr)(pname, data);
 // }
+// static void  glowGetProgramBinary(GPGETPROGRAMBINARY fnptr, GLuint  program, GLsizei  bufSize, GLsizei * length, GLenum * binaryFormat, void * binary) {
+//   (*fnptr)(program, bufSize, length, binaryFormat, binary);
+// }
 // static void  glowGetProgramInfoLog(GPGETPROGRAMINFOLOG fnptr, GLuint  program, GLsizei  bufSize, GLsizei * length, GLchar * infoLog) {
 //   (*fnptr)(program, bufSize, length, infoLog);
 // }
@@ -375,6 +453,9 @@ package gl
 // static void  glowGetQueryObjectiv(GPGETQUERYOBJECTIV fnptr, GLuint  id, GLenum  pname, GLint * params) {
 //   (*fnptr)(id, pname, params);
 // }
+// static void  glowGetQueryObjectui64v(GPGETQUERYOBJECTUI64V fnptr, GLuint  id, GLenum  pname, GLuint64 * params) {
+//   (*fnptr)(id, pname, params);
+// }
 // static void  glowGetQueryiv(GPGETQUERYIV fnptr, GLenum  target, GLenum  pname, GLint * params) {
 //   (*fnptr)(target, pname, params);
 // }
@@ -390,15 +471,60 @@ package gl
 // static GLint  glowGetUniformLocation(GPGETUNIFORMLOCATION fnptr, GLuint  program, const GLchar * name) {
 //   return (*fnptr)(program, name);
 // }
+// static void  glowLabelObjectEXT(GPLABELOBJECTEXT fnptr, GLenum  xtype, GLuint  object, GLsizei  length, const GLchar * label) {
+//   (*fnptr)(xtype, object, length, label);
+// }
 // static void  glowLinkProgram(GPLINKPROGRAM fnptr, GLuint  program) {
 //   (*fnptr)(program);
 // }
+// static void * glowMapBuffer(GPMAPBUFFER fnptr, GLenum  target, GLenum  access) {
+//   return (*fnptr)(target, access);
+// }
+// static void * glowMapBufferRange(GPMAPBUFFERRANGE fnptr, GLenum  target, GLintptr  offset, GLsizeiptr  length, GLbitfield  access) {
+//   return (*fnptr)(target, offset, length, access);
+// }
+// static void  glowMemoryBarrier(GPMEMORYBARRIER fnptr, GLbitfield  barriers) {
+//   (*fnptr)(barriers);
+// }
+// static void  glowMultiDrawElementsIndirect(GPMULTIDRAWELEMENTSINDIRECT fnptr, GLenum  mode, GLenum  type, const void * indirect, GLsizei  drawcount, GLsizei  stride) {
+//   (*fnptr)(mode, type, indirect, drawcount, stride);
+// }
+// static void  glowObjectLabel(GPOBJECTLABEL fnptr, GLenum  identifier, GLuint  name, GLsizei  length, const GLchar * label) {
+//   (*fnptr)(identifier, name, length, label);
+// }
+// static void  glowPopDebugGroup(GPPOPDEBUGGROUP fnptr) {
+//   (*fnptr)();
+// }
+// static void  glowPopGroupMarkerEXT(GPPOPGROUPMARKEREXT fnptr) {
+//   (*fnptr)();
+// }
+// static void  glowProgramBinary(GPPROGRAMBINARY fnptr, GLuint  program, GLenum  binaryFormat, const void * binary, GLsizei  length) {
+//   (*fnptr)(program, binaryFormat, binary, length);
+// }
+// static void  glowProgramParameteri(GPPROGRAMPARAMETERI fnptr, GLuint  program, GLenum  pname, GLint  value) {
+//   (*fnptr)(program, pname, value);
+// }
+// static void  glowPushDebugGroup(GPPUSHDEBUGGROUP fnptr, GLenum  source, GLuint  id, GLsizei  length, const GLchar * message) {
+//   (*fnptr)(source, id, length, message);
+// }
+// static void  glowPushGroupMarkerEXT(GPPUSHGROUPMARKEREXT fnptr, GLsizei  length, const GLchar * marker) {
+//   (*fnptr)(length, marker);
+// }
 // static void  glowReadPixels(GPREADPIXELS fnptr, GLint  x, GLint  y, GLsizei  width, GLsizei  height, GLenum  format, GLenum  type, void * pixels) {
 //   (*fnptr)(x, y, width, height, format, type, pixels);
 // }
 // static void  glowRenderbufferStorageMultisample(GPRENDERBUFFERSTORAGEMULTISAMPLE fnptr, GLenum  target, GLsizei  samples, GLenum  internalformat, GLsizei  width, GLsizei  height) {
 //   (*fnptr)(target, samples, internalformat, width, height);
 // }
+// static void  glowSamplerParameterf(GPSAMPLERPARAMETERF fnptr, GLuint  sampler, GLenum  pname, GLfloat  param) {
+//   (*fnptr)(sampler, pname, param);
+// }
+// static void  glowSamplerParameterfv(GPSAMPLERPARAMETERFV fnptr, GLuint  sampler, GLenum  pname, const GLfloat * param) {
+//   (*fnptr)(sampler, pname, param);
+// }
+// static void  glowSamplerParameteri(GPSAMPLERPARAMETERI fnptr, GLuint  sampler, GLenum  pname, GLint  param) {
+//   (*fnptr)(sampler, pname, param);
+// }
 // static void  glowScissor(GPSCISSOR fnptr, GLint  x, GLint  y, GLsizei  width, GLsizei  height) {
 //   (*fnptr)(x, y, width, height);
 // }
@@ -417,6 +543,9 @@ package gl
 // static void  glowTexImage2D(GPTEXIMAGE2D fnptr, GLenum  target, GLint  level, GLint  internalformat, GLsizei  width, GLsizei  height, GLint  border, GLenum  format, GLenum  type, const void * pixels) {
 //   (*fnptr)(target, level, internalformat, width, height, border, format, type, pixels);
 // }
+// static void  glowTexParameterf(GPTEXPARAMETERF fnptr, GLenum  target, GLenum  pname, GLfloat  param) {
+//   (*fnptr)(target, pname, param);
+// }
 // static void  glowTexParameterfv(GPTEXPARAMETERFV fnptr, GLenum  target, GLenum  pname, const GLfloat * params) {
 //   (*fnptr)(target, pname, params);
 // }
@@ -444,6 +573,9 @@ package gl
 // static void  glowUniformMatrix4fv(GPUNIFORMMATRIX4FV fnptr, GLint  location, GLsizei  count, GLboolean  transpose, const GLfloat * value) {
 //   (*fnptr)(location, count, transpose, value);
 // }
+// static GLboolean  glowUnmapBuffer(GPUNMAPBUFFER fnptr, GLenum  target) {
+//   return (*fnptr)(target);
+// }
 // static void  glowUseProgram(GPUSEPROGRAM fnptr, GLuint  program) {
 //   (*fnptr)(program);
 // }
@@ -460,7 +592,12 @@ import (
 )
 
 const (
+	ACTIVE_ATTRIBUTES                         = 0x8B89
+	ACTIVE_ATTRIBUTE_MAX_LENGTH               = 0x8B8A
+	ACTIVE_UNIFORMS                           = 0x8B86
+	ACTIVE_UNIFORM_MAX_LENGTH                 = 0x8B87
 	ALPHA_BITS                                = 0x0D55
+	ALREADY_SIGNALED                          = 0x911A
 	ALWAYS                                    = 0x0207
 	ARRAY_BUFFER                              = 0x8892
 	BACK                                      = 0x0405
@@ -474,14 +611,23 @@ const (
 	BLEND_SRC_ALPHA                           = 0x80CB
 	BLEND_SRC_RGB                             = 0x80C9
 	BLUE_BITS                                 = 0x0D54
+	BOOL                                      = 0x8B56
+	BOOL_VEC2                                 = 0x8B57
+	BOOL_VEC3                                 = 0x8B58
+	BOOL_VEC4                                 = 0x8B59
+	BUFFER                                    = 0x82E0
+	BUFFER_OBJECT_EXT                         = 0x9151
 	CLAMP_TO_BORDER                           = 0x812D
 	CLAMP_TO_EDGE                             = 0x812F
 	COLOR_ATTACHMENT0                         = 0x8CE0
 	COLOR_BUFFER_BIT                          = 0x00004000
 	COLOR_CLEAR_VALUE                         = 0x0C22
 	COLOR_WRITEMASK                           = 0x0C23
+	COMMAND_BARRIER_BIT                       = 0x00000040
 	COMPILE_STATUS                            = 0x8B81
 	COMPRESSED_TEXTURE_FORMATS                = 0x86A3
+	COMPUTE_SHADER                            = 0x91B9
+	CONDITION_SATISFIED                       = 0x911C
 	CONSTANT_ALPHA                            = 0x8003
 	CONSTANT_COLOR                            = 0x8001
 	CULL_FACE                                 = 0x0B44
@@ -491,6 +637,7 @@ const (
 	DEBUG_SEVERITY_HIGH                       = 0x9146
 	DEBUG_SEVERITY_LOW                        = 0x9148
 	DEBUG_SEVERITY_MEDIUM                     = 0x9147
+	DEBUG_SOURCE_APPLICATION                  = 0x824A
 	DEBUG_TYPE_DEPRECATED_BEHAVIOR            = 0x824D
 	DEBUG_TYPE_ERROR                          = 0x824C
 	DEBUG_TYPE_OTHER                          = 0x8251
@@ -513,6 +660,7 @@ const (
 	DEPTH_TEST                                = 0x0B71
 	DEPTH_WRITEMASK                           = 0x0B72
 	DITHER                                    = 0x0BD0
+	DRAW_INDIRECT_BUFFER                      = 0x8F3F
 	DST_ALPHA                                 = 0x0304
 	DST_COLOR                                 = 0x0306
 	DYNAMIC_DRAW                              = 0x88E8
@@ -520,6 +668,12 @@ const (
 	EQUAL                                     = 0x0202
 	EXTENSIONS                                = 0x1F03
 	FLOAT                                     = 0x1406
+	FLOAT_MAT2                                = 0x8B5A
+	FLOAT_MAT3                                = 0x8B5B
+	FLOAT_MAT4                                = 0x8B5C
+	FLOAT_VEC2                                = 0x8B50
+	FLOAT_VEC3                                = 0x8B51
+	FLOAT_VEC4                                = 0x8B52
 	FRAGMENT_SHADER                           = 0x8B30
 	FRAMEBUFFER                               = 0x8D40
 	FRAMEBUFFER_COMPLETE                      = 0x8CD5
@@ -528,6 +682,7 @@ const (
 	FRAMEBUFFER_INCOMPLETE_MISSING_ATTACHMENT = 0x8CD7
 	FRAMEBUFFER_INCOMPLETE_MULTISAMPLE        = 0x8D56
 	FRAMEBUFFER_INCOMPLETE_READ_BUFFER        = 0x8CDC
+	FRAMEBUFFER_SRGB                          = 0x8DB9
 	FRAMEBUFFER_UNDEFINED                     = 0x8219
 	FRAMEBUFFER_UNSUPPORTED                   = 0x8CDD
 	FRONT                                     = 0x0404
@@ -542,6 +697,10 @@ const (
 	INCR                                      = 0x1E02
 	INCR_WRAP                                 = 0x8507
 	INFO_LOG_LENGTH                           = 0x8B84
+	INT                                       = 0x1404
+	INT_VEC2                                  = 0x8B53
+	INT_VEC3                                  = 0x8B54
+	INT_VEC4                                  = 0x8B55
 	INVALID_ENUM                              = 0x0500
 	INVALID_FRAMEBUFFER_OPERATION             = 0x0506
 	INVALID_OPERATION                         = 0x0502
@@ -555,12 +714,19 @@ const (
 	LINEAR_MIPMAP_NEAREST                     = 0x2701
 	LINES                                     = 0x0001
 	LINK_STATUS                               = 0x8B82
+	MAP_COHERENT_BIT                          = 0x0080
+	MAP_PERSISTENT_BIT                        = 0x0040
+	MAP_WRITE_BIT                             = 0x0002
 	MAX_FRAGMENT_UNIFORM_COMPONENTS           = 0x8B49
 	MAX_FRAGMENT_UNIFORM_VECTORS              = 0x8DFD
 	MAX_SAMPLES                               = 0x8D57
+	MAX_TEXTURE_IMAGE_UNITS                   = 0x8872
+	MAX_TEXTURE_LOD_BIAS                      = 0x84FD
+	MAX_TEXTURE_MAX_ANISOTROPY_EXT            = 0x84FF
 	MAX_TEXTURE_SIZE                          = 0x0D33
 	MAX_VARYING_FLOATS                        = 0x8B4B
 	MAX_VARYING_VECTORS                       = 0x8DFC
+	MAX_VERTEX_ATTRIBS                        = 0x8869
 	MAX_VERTEX_UNIFORM_COMPONENTS             = 0x8B4A
 	MAX_VERTEX_UNIFORM_VECTORS                = 0x8DFB
 	MIRRORED_REPEAT                           = 0x8370
@@ -572,6 +738,7 @@ const (
 	NOTEQUAL                                  = 0x0205
 	NO_ERROR                                  = 0
 	NUM_COMPRESSED_TEXTURE_FORMATS            = 0x86A2
+	NUM_PROGRAM_BINARY_FORMATS                = 0x87FE
 	ONE                                       = 1
 	ONE_MINUS_CONSTANT_ALPHA                  = 0x8004
 	ONE_MINUS_CONSTANT_COLOR                  = 0x8002
@@ -580,11 +747,18 @@ const (
 	ONE_MINUS_SRC_ALPHA                       = 0x0303
 	ONE_MINUS_SRC_COLOR                       = 0x0301
 	OUT_OF_MEMORY                             = 0x0505
+	PIXEL_PACK_BUFFER                         = 0x88EB
+	PIXEL_UNPACK_BUFFER                       = 0x88EC
 	POINTS                                    = 0x0000
+	PROGRAM                                   = 0x82E2
+	PROGRAM_BINARY_LENGTH                     = 0x8741
+	PROGRAM_BINARY_RETRIEVABLE_HINT           = 0x8257
+	PROGRAM_OBJECT_EXT                        = 0x8B40
 	PROGRAM_POINT_SIZE_EXT                    = 0x8642
 	QUERY_COUNTER_BITS                        = 0x8864
 	QUERY_RESULT                              = 0x8866
 	QUERY_RESULT_AVAILABLE                    = 0x8867
+	READ_ONLY                                 = 0x88B8
 	RED_BITS                                  = 0x0D52
 	RENDERBUFFER                              = 0x8D41
 	RENDERER                                  = 0x1F01
@@ -594,16 +768,22 @@ const (
 	RGB8                                      = 0x8051
 	RGBA                                      = 0x1908
 	RGBA8                                     = 0x8058
+	SAMPLER_2D                                = 0x8B5E
+	SAMPLER_CUBE                              = 0x8B60
 	SAMPLES                                   = 0x80A9
 	SAMPLES_PASSED                            = 0x8914
 	SAMPLE_ALPHA_TO_COVERAGE                  = 0x809E
 	SAMPLE_BUFFERS                            = 0x80A8
 	SCISSOR_BOX                               = 0x0C10
 	SCISSOR_TEST                              = 0x0C11
+	SHADER_STORAGE_BARRIER_BIT                = 0x00002000
+	SHADER_STORAGE_BUFFER                     = 0x90D2
 	SHADING_LANGUAGE_VERSION                  = 0x8B8C
 	SRC_ALPHA                                 = 0x0302
 	SRC_ALPHA_SATURATE                        = 0x0308
 	SRC_COLOR                                 = 0x0300
+	SRGB8                                     = 0x8C41
+	SRGB8_ALPHA8                              = 0x8C43
 	STACK_OVERFLOW                            = 0x0503
 	STACK_UNDERFLOW                           = 0x0504
 	STATIC_DRAW                               = 0x88E4
@@ -626,23 +806,35 @@ const (
 	STENCIL_TEST                              = 0x0B90
 	STENCIL_VALUE_MASK                        = 0x0B93
 	STENCIL_WRITEMASK                         = 0x0B98
+	STREAM_DRAW                               = 0x88E0
+	STREAM_READ                               = 0x88E1
+	SYNC_FLUSH_COMMANDS_BIT                   = 0x00000001
+	SYNC_GPU_COMMANDS_COMPLETE                = 0x9117
+	TEXTURE                                   = 0x1702
 	TEXTURE0                                  = 0x84C0
 	TEXTURE_2D                                = 0x0DE1
 	TEXTURE_BASE_LEVEL                        = 0x813C
 	TEXTURE_BORDER_COLOR                      = 0x1004
+	TEXTURE_LOD_BIAS                          = 0x8501
 	TEXTURE_MAG_FILTER                        = 0x2800
+	TEXTURE_MAX_ANISOTROPY_EXT                = 0x84FE
 	TEXTURE_MAX_LEVEL                         = 0x813D
 	TEXTURE_MIN_FILTER                        = 0x2801
 	TEXTURE_WRAP_S                            = 0x2802
 	TEXTURE_WRAP_T                            = 0x2803
+	TIMEOUT_EXPIRED                           = 0x911B
+	TIME_ELAPSED                              = 0x88BF
 	TRIANGLES                                 = 0x0004
 	TRUE                                      = 1
 	UNSIGNED_BYTE                             = 0x1401
 	UNSIGNED_INT                              = 0x1405
+	UNSIGNED_SHORT                            = 0x1403
 	VENDOR                                    = 0x1F00
 	VERSION                                   = 0x1F02
 	VERTEX_SHADER                             = 0x8B31
 	VIEWPORT                                  = 0x0BA2
+	WAIT_FAILED                               = 0x911D
+	WRITE_ONLY                                = 0x88B9
 	ZERO                                      = 0
 )
 
@@ -651,21 +843,27 @@ var (
 	gpAttachShader                   C.GPATTACHSHADER
 	gpBeginQuery                     C.GPBEGINQUERY
 	gpBindBuffer                     C.GPBINDBUFFER
+	gpBindBufferBase                 C.GPBINDBUFFERBASE
 	gpBindFramebuffer                C.GPBINDFRAMEBUFFER
 	gpBindRenderbuffer               C.GPBINDRENDERBUFFER
+	gpBindSampler                    C.GPBINDSAMPLER
 	gpBindTexture                    C.GPBINDTEXTURE
 	gpBlendColor                     C.GPBLENDCOLOR
 	gpBlendEquationSeparate          C.GPBLENDEQUATIONSEPARATE
 	gpBlendFuncSeparate              C.GPBLENDFUNCSEPARATE
 	gpBufferData                     C.GPBUFFERDATA
+	gpBufferStorage                  C.GPBUFFERSTORAGE
 	gpCheckFramebufferStatus         C.GPCHECKFRAMEBUFFERSTATUS
 	gpClear                          C.GPCLEAR
 	gpClearColor                     C.GPCLEARCOLOR
 	gpClearDepth                     C.GPCLEARDEPTH
 	gpClearDepthf                    C.GPCLEARDEPTHF
 	gpClearStencil                   C.GPCLEARSTENCIL
+	gpClientWaitSync                 C.GPCLIENTWAITSYNC
 	gpColorMask                      C.GPCOLORMASK
 	gpCompileShader                  C.GPCOMPILESHADER
+	gpCompressedTexImage2D           C.GPCOMPRESSEDTEXIMAGE2D
+	gpCopyTexSubImage2D              C.GPCOPYTEXSUBIMAGE2D
 	gpCreateProgram                  C.GPCREATEPROGRAM
 	gpCreateShader                   C.GPCREATESHADER
 	gpCullFace                       C.GPCULLFACE
@@ -676,16 +874,21 @@ var (
 	gpDeleteQueries                  C.GPDELETEQUERIES
 	gpDeleteRenderbuffers            C.GPDELETERENDERBUFFERS
 	gpDeleteShader                   C.GPDELETESHADER
+	gpDeleteSync                     C.GPDELETESYNC
 	gpDeleteTextures                 C.GPDELETETEXTURES
 	gpDepthFunc                      C.GPDEPTHFUNC
 	gpDepthMask                      C.GPDEPTHMASK
 	gpDisable                        C.GPDISABLE
 	gpDisableVertexAttribArray       C.GPDISABLEVERTEXATTRIBARRAY
+	gpDispatchCompute                C.GPDISPATCHCOMPUTE
 	gpDrawArrays                     C.GPDRAWARRAYS
 	gpDrawElements                   C.GPDRAWELEMENTS
+	gpDrawElementsInstanced          C.GPDRAWELEMENTSINSTANCED
+	gpDrawElementsInstancedARB       C.GPDRAWELEMENTSINSTANCEDARB
 	gpEnable                         C.GPENABLE
 	gpEnableVertexAttribArray        C.GPENABLEVERTEXATTRIBARRAY
 	gpEndQuery                       C.GPENDQUERY
+	gpFenceSync                      C.GPFENCESYNC
 	gpFinish                         C.GPFINISH
 	gpFlush                          C.GPFLUSH
 	gpFramebufferRenderbuffer        C.GPFRAMEBUFFERRENDERBUFFER
@@ -694,31 +897,52 @@ var (
 	gpGenFramebuffers                C.GPGENFRAMEBUFFERS
 	gpGenQueries                     C.GPGENQUERIES
 	gpGenRenderbuffers               C.GPGENRENDERBUFFERS
+	gpGenSamplers                    C.GPGENSAMPLERS
 	gpGenTextures                    C.GPGENTEXTURES
 	gpGenerateMipmap                 C.GPGENERATEMIPMAP
+	gpGetActiveAttrib                C.GPGETACTIVEATTRIB
+	gpGetActiveUniform               C.GPGETACTIVEUNIFORM
 	gpGetAttribLocation              C.GPGETATTRIBLOCATION
 	gpGetBooleanv                    C.GPGETBOOLEANV
 	gpGetDoublev                     C.GPGETDOUBLEV
 	gpGetError                       C.GPGETERROR
 	gpGetFloatv                      C.GPGETFLOATV
 	gpGetIntegerv                    C.GPGETINTEGERV
+	gpGetProgramBinary               C.GPGETPROGRAMBINARY
 	gpGetProgramInfoLog              C.GPGETPROGRAMINFOLOG
 	gpGetProgramiv                   C.GPGETPROGRAMIV
 	gpGetQueryObjectiv               C.GPGETQUERYOBJECTIV
+	gpGetQueryObjectui64v            C.GPGETQUERYOBJECTUI64V
 	gpGetQueryiv                     C.GPGETQUERYIV
 	gpGetShaderInfoLog               C.GPGETSHADERINFOLOG
 	gpGetShaderiv                    C.GPGETSHADERIV
 	gpGetString                      C.GPGETSTRING
 	gpGetUniformLocation             C.GPGETUNIFORMLOCATION
+	gpLabelObjectEXT                 C.GPLABELOBJECTEXT
 	gpLinkProgram                    C.GPLINKPROGRAM
+	gpMapBuffer                      C.GPMAPBUFFER
+	gpMapBufferRange                 C.GPMAPBUFFERRANGE
+	gpMemoryBarrier                  C.GPMEMORYBARRIER
+	gpMultiDrawElementsIndirect      C.GPMULTIDRAWELEMENTSINDIRECT
+	gpObjectLabel                    C.GPOBJECTLABEL
+	gpPopDebugGroup                  C.GPPOPDEBUGGROUP
+	gpPopGroupMarkerEXT              C.GPPOPGROUPMARKEREXT
+	gpProgramBinary                  C.GPPROGRAMBINARY
+	gpProgramParameteri              C.GPPROGRAMPARAMETERI
+	gpPushDebugGroup                 C.GPPUSHDEBUGGROUP
+	gpPushGroupMarkerEXT             C.GPPUSHGROUPMARKEREXT
 	gpReadPixels                     C.GPREADPIXELS
 	gpRenderbufferStorageMultisample C.GPRENDERBUFFERSTORAGEMULTISAMPLE
+	gpSamplerParameterf              C.GPSAMPLERPARAMETERF
+	gpSamplerParameterfv             C.GPSAMPLERPARAMETERFV
+	gpSamplerParameteri              C.GPSAMPLERPARAMETERI
 	gpScissor                        C.GPSCISSOR
 	gpShaderSource                   C.GPSHADERSOURCE
 	gpStencilFuncSeparate            C.GPSTENCILFUNCSEPARATE
 	gpStencilMaskSeparate            C.GPSTENCILMASKSEPARATE
 	gpStencilOpSeparate              C.GPSTENCILOPSEPARATE
 	gpTexImage2D                     C.GPTEXIMAGE2D
+	gpTexParameterf                  C.GPTEXPARAMETERF
 	gpTexParameterfv                 C.GPTEXPARAMETERFV
 	gpTexParameteri                  C.GPTEXPARAMETERI
 	gpUniform1fv                     C.GPUNIFORM1FV
@@ -728,6 +952,7 @@ var (
 	gpUniform3fv                     C.GPUNIFORM3FV
 	gpUniform4fv                     C.GPUNIFORM4FV
 	gpUniformMatrix4fv               C.GPUNIFORMMATRIX4FV
+	gpUnmapBuffer                    C.GPUNMAPBUFFER
 	gpUseProgram                     C.GPUSEPROGRAM
 	gpVertexAttribPointer            C.GPVERTEXATTRIBPOINTER
 	gpViewport                       C.GPVIEWPORT
@@ -744,403 +969,912 @@ func boolToInt(b bool) int {
 // select active texture unit
 func ActiveTexture(texture uint32) {
 	C.glowActiveTexture(gpActiveTexture, (C.GLenum)(texture))
+	if tracing() {
+		trace("glActiveTexture", []interface{}{Enum(texture)}, nil)
+	}
 }
 
 // Attaches a shader object to a program object
 func AttachShader(program uint32, shader uint32) {
 	C.glowAttachShader(gpAttachShader, (C.GLuint)(program), (C.GLuint)(shader))
+	if tracing() {
+		trace("glAttachShader", []interface{}{program, shader}, nil)
+	}
 }
 
 // delimit the boundaries of a query object
 func BeginQuery(target uint32, id uint32) {
 	C.glowBeginQuery(gpBeginQuery, (C.GLenum)(target), (C.GLuint)(id))
+	if tracing() {
+		trace("glBeginQuery", []interface{}{Enum(target), id}, nil)
+	}
 }
 
 // bind a named buffer object
 func BindBuffer(target uint32, buffer uint32) {
 	C.glowBindBuffer(gpBindBuffer, (C.GLenum)(target), (C.GLuint)(buffer))
+	if tracing() {
+		trace("glBindBuffer", []interface{}{Enum(target), buffer}, nil)
+	}
+}
+
+// bind a buffer object to an indexed buffer target
+func BindBufferBase(target uint32, index uint32, buffer uint32) {
+	C.glowBindBufferBase(gpBindBufferBase, (C.GLenum)(target), (C.GLuint)(index), (C.GLuint)(buffer))
+	if tracing() {
+		trace("glBindBufferBase", []interface{}{Enum(target), index, buffer}, nil)
+	}
 }
 
 // bind a framebuffer to a framebuffer target
 func BindFramebuffer(target uint32, framebuffer uint32) {
 	C.glowBindFramebuffer(gpBindFramebuffer, (C.GLenum)(target), (C.GLuint)(framebuffer))
+	if tracing() {
+		trace("glBindFramebuffer", []interface{}{Enum(target), framebuffer}, nil)
+	}
 }
 
 // bind a renderbuffer to a renderbuffer target
 func BindRenderbuffer(target uint32, renderbuffer uint32) {
 	C.glowBindRenderbuffer(gpBindRenderbuffer, (C.GLenum)(target), (C.GLuint)(renderbuffer))
+	if tracing() {
+		trace("glBindRenderbuffer", []interface{}{Enum(target), renderbuffer}, nil)
+	}
+}
+
+// bind a named sampler to a texturing target
+func BindSampler(unit uint32, sampler uint32) {
+	C.glowBindSampler(gpBindSampler, (C.GLuint)(unit), (C.GLuint)(sampler))
+	if tracing() {
+		trace("glBindSampler", []interface{}{unit, sampler}, nil)
+	}
 }
 
 // bind a named texture to a texturing target
 func BindTexture(target uint32, texture uint32) {
 	C.glowBindTexture(gpBindTexture, (C.GLenum)(target), (C.GLuint)(texture))
+	if tracing() {
+		trace("glBindTexture", []interface{}{Enum(target), texture}, nil)
+	}
 }
 
 // set the blend color
 func BlendColor(red float32, green float32, blue float32, alpha float32) {
 	C.glowBlendColor(gpBlendColor, (C.GLfloat)(red), (C.GLfloat)(green), (C.GLfloat)(blue), (C.GLfloat)(alpha))
+	if tracing() {
+		trace("glBlendColor", []interface{}{red, green, blue, alpha}, nil)
+	}
 }
 
 // set the RGB blend equation and the alpha blend equation separately
 func BlendEquationSeparate(modeRGB uint32, modeAlpha uint32) {
 	C.glowBlendEquationSeparate(gpBlendEquationSeparate, (C.GLenum)(modeRGB), (C.GLenum)(modeAlpha))
+	if tracing() {
+		trace("glBlendEquationSeparate", []interface{}{Enum(modeRGB), Enum(modeAlpha)}, nil)
+	}
 }
 
 // specify pixel arithmetic for RGB and alpha components separately
 func BlendFuncSeparate(sfactorRGB uint32, dfactorRGB uint32, sfactorAlpha uint32, dfactorAlpha uint32) {
 	C.glowBlendFuncSeparate(gpBlendFuncSeparate, (C.GLenum)(sfactorRGB), (C.GLenum)(dfactorRGB), (C.GLenum)(sfactorAlpha), (C.GLenum)(dfactorAlpha))
+	if tracing() {
+		trace("glBlendFuncSeparate", []interface{}{Enum(sfactorRGB), Enum(dfactorRGB), Enum(sfactorAlpha), Enum(dfactorAlpha)}, nil)
+	}
 }
 
 // creates and initializes a buffer object's data     store
 func BufferData(target uint32, size int, data unsafe.Pointer, usage uint32) {
 	C.glowBufferData(gpBufferData, (C.GLenum)(target), (C.GLsizeiptr)(size), data, (C.GLenum)(usage))
+	if tracing() {
+		trace("glBufferData", []interface{}{Enum(target), size, data, Enum(usage)}, nil)
+	}
+}
+
+// creates and initializes a buffer object's immutable data store
+func BufferStorage(target uint32, size int, data unsafe.Pointer, flags uint32) {
+	C.glowBufferStorage(gpBufferStorage, (C.GLenum)(target), (C.GLsizeiptr)(size), data, (C.GLbitfield)(flags))
+	if tracing() {
+		trace("glBufferStorage", []interface{}{Enum(target), size, data, flags}, nil)
+	}
 }
 
 // check the completeness status of a framebuffer
 func CheckFramebufferStatus(target uint32) uint32 {
 	ret := C.glowCheckFramebufferStatus(gpCheckFramebufferStatus, (C.GLenum)(target))
+	if tracing() {
+		trace("glCheckFramebufferStatus", []interface{}{Enum(target)}, (uint32)(ret))
+	}
 	return (uint32)(ret)
 }
 
 // clear buffers to preset values
 func Clear(mask uint32) {
 	C.glowClear(gpClear, (C.GLbitfield)(mask))
+	if tracing() {
+		trace("glClear", []interface{}{mask}, nil)
+	}
 }
 
 // specify clear values for the color buffers
 func ClearColor(red float32, green float32, blue float32, alpha float32) {
 	C.glowClearColor(gpClearColor, (C.GLfloat)(red), (C.GLfloat)(green), (C.GLfloat)(blue), (C.GLfloat)(alpha))
+	if tracing() {
+		trace("glClearColor", []interface{}{red, green, blue, alpha}, nil)
+	}
 }
 
 // specify the clear value for the depth buffer
 func ClearDepth(depth float64) {
 	C.glowClearDepth(gpClearDepth, (C.GLdouble)(depth))
+	if tracing() {
+		trace("glClearDepth", []interface{}{depth}, nil)
+	}
 }
 func ClearDepthf(d float32) {
 	C.glowClearDepthf(gpClearDepthf, (C.GLfloat)(d))
+	if tracing() {
+		trace("glClearDepthf", []interface{}{d}, nil)
+	}
 }
 
 // specify the clear value for the stencil buffer
 func ClearStencil(s int32) {
 	C.glowClearStencil(gpClearStencil, (C.GLint)(s))
+	if tracing() {
+		trace("glClearStencil", []interface{}{s}, nil)
+	}
+}
+
+// block and wait for a sync object to become signaled
+func ClientWaitSync(sync uintptr, flags uint32, timeout uint64) uint32 {
+	ret := C.glowClientWaitSync(gpClientWaitSync, (C.GLsync)(unsafe.Pointer(sync)), (C.GLbitfield)(flags), (C.GLuint64)(timeout))
+	if tracing() {
+		trace("glClientWaitSync", []interface{}{sync, flags, timeout}, (uint32)(ret))
+	}
+	return (uint32)(ret)
 }
 func ColorMask(red bool, green bool, blue bool, alpha bool) {
 	C.glowColorMask(gpColorMask, (C.GLboolean)(boolToInt(red)), (C.GLboolean)(boolToInt(green)), (C.GLboolean)(boolToInt(blue)), (C.GLboolean)(boolToInt(alpha)))
+	if tracing() {
+		trace("glColorMask", []interface{}{red, green, blue, alpha}, nil)
+	}
 }
 
 // Compiles a shader object
 func CompileShader(shader uint32) {
 	C.glowCompileShader(gpCompileShader, (C.GLuint)(shader))
+	if tracing() {
+		trace("glCompileShader", []interface{}{shader}, nil)
+	}
+}
+
+// specify a two-dimensional texture image in a compressed format
+func CompressedTexImage2D(target uint32, level int32, internalformat uint32, width int32, height int32, border int32, imageSize int32, data unsafe.Pointer) {
+	C.glowCompressedTexImage2D(gpCompressedTexImage2D, (C.GLenum)(target), (C.GLint)(level), (C.GLenum)(internalformat), (C.GLsizei)(width), (C.GLsizei)(height), (C.GLint)(border), (C.GLsizei)(imageSize), data)
+	if tracing() {
+		trace("glCompressedTexImage2D", []interface{}{Enum(target), level, Enum(internalformat), width, height, border, imageSize, data}, nil)
+	}
+}
+
+// Copy a two-dimensional texture subimage
+func CopyTexSubImage2D(target uint32, level int32, xoffset int32, yoffset int32, x int32, y int32, width int32, height int32) {
+	C.glowCopyTexSubImage2D(gpCopyTexSubImage2D, (C.GLenum)(target), (C.GLint)(level), (C.GLint)(xoffset), (C.GLint)(yoffset), (C.GLint)(x), (C.GLint)(y), (C.GLsizei)(width), (C.GLsizei)(height))
+	if tracing() {
+		trace("glCopyTexSubImage2D", []interface{}{Enum(target), level, xoffset, yoffset, x, y, width, height}, nil)
+	}
 }
 
 // Creates a program object
 func CreateProgram() uint32 {
 	ret := C.glowCreateProgram(gpCreateProgram)
+	if tracing() {
+		trace("glCreateProgram", []interface{}{}, (uint32)(ret))
+	}
 	return (uint32)(ret)
 }
 
 // Creates a shader object
 func CreateShader(xtype uint32) uint32 {
 	ret := C.glowCreateShader(gpCreateShader, (C.GLenum)(xtype))
+	if tracing() {
+		trace("glCreateShader", []interface{}{Enum(xtype)}, (uint32)(ret))
+	}
 	return (uint32)(ret)
 }
 
 // specify whether front- or back-facing facets can be culled
 func CullFace(mode uint32) {
 	C.glowCullFace(gpCullFace, (C.GLenum)(mode))
+	if tracing() {
+		trace("glCullFace", []interface{}{Enum(mode)}, nil)
+	}
 }
 func DebugMessageCallbackARB(callback DebugProc, userParam unsafe.Pointer) {
 	userDebugCallback = callback
 	C.glowDebugMessageCallbackARB(gpDebugMessageCallbackARB, (C.GLDEBUGPROCARB)(unsafe.Pointer(&callback)), userParam)
+	if tracing() {
+		trace("glDebugMessageCallbackARB", []interface{}{"<func>", userParam}, nil)
+	}
 }
 
 // delete named buffer objects
 func DeleteBuffers(n int32, buffers *uint32) {
 	C.glowDeleteBuffers(gpDeleteBuffers, (C.GLsizei)(n), (*C.GLuint)(unsafe.Pointer(buffers)))
+	if tracing() {
+		trace("glDeleteBuffers", []interface{}{n, traceUint32s(buffers, n)}, nil)
+	}
 }
 
 // delete framebuffer objects
 func DeleteFramebuffers(n int32, framebuffers *uint32) {
 	C.glowDeleteFramebuffers(gpDeleteFramebuffers, (C.GLsizei)(n), (*C.GLuint)(unsafe.Pointer(framebuffers)))
+	if tracing() {
+		trace("glDeleteFramebuffers", []interface{}{n, traceUint32s(framebuffers, n)}, nil)
+	}
 }
 
 // Deletes a program object
 func DeleteProgram(program uint32) {
 	C.glowDeleteProgram(gpDeleteProgram, (C.GLuint)(program))
+	if tracing() {
+		trace("glDeleteProgram", []interface{}{program}, nil)
+	}
 }
 
 // delete named query objects
 func DeleteQueries(n int32, ids *uint32) {
 	C.glowDeleteQueries(gpDeleteQueries, (C.GLsizei)(n), (*C.GLuint)(unsafe.Pointer(ids)))
+	if tracing() {
+		trace("glDeleteQueries", []interface{}{n, traceUint32s(ids, n)}, nil)
+	}
 }
 
 // delete renderbuffer objects
 func DeleteRenderbuffers(n int32, renderbuffers *uint32) {
 	C.glowDeleteRenderbuffers(gpDeleteRenderbuffers, (C.GLsizei)(n), (*C.GLuint)(unsafe.Pointer(renderbuffers)))
+	if tracing() {
+		trace("glDeleteRenderbuffers", []interface{}{n, traceUint32s(renderbuffers, n)}, nil)
+	}
 }
 
 // Deletes a shader object
 func DeleteShader(shader uint32) {
 	C.glowDeleteShader(gpDeleteShader, (C.GLuint)(shader))
+	if tracing() {
+		trace("glDeleteShader", []interface{}{shader}, nil)
+	}
+}
+
+// delete a sync object
+func DeleteSync(sync uintptr) {
+	C.glowDeleteSync(gpDeleteSync, (C.GLsync)(unsafe.Pointer(sync)))
+	if tracing() {
+		trace("glDeleteSync", []interface{}{sync}, nil)
+	}
 }
 
 // delete named textures
 func DeleteTextures(n int32, textures *uint32) {
 	C.glowDeleteTextures(gpDeleteTextures, (C.GLsizei)(n), (*C.GLuint)(unsafe.Pointer(textures)))
+	if tracing() {
+		trace("glDeleteTextures", []interface{}{n, traceUint32s(textures, n)}, nil)
+	}
 }
 
 // specify the value used for depth buffer comparisons
 func DepthFunc(xfunc uint32) {
 	C.glowDepthFunc(gpDepthFunc, (C.GLenum)(xfunc))
+	if tracing() {
+		trace("glDepthFunc", []interface{}{Enum(xfunc)}, nil)
+	}
 }
 
 // enable or disable writing into the depth buffer
 func DepthMask(flag bool) {
 	C.glowDepthMask(gpDepthMask, (C.GLboolean)(boolToInt(flag)))
+	if tracing() {
+		trace("glDepthMask", []interface{}{flag}, nil)
+	}
 }
 func Disable(cap uint32) {
 	C.glowDisable(gpDisable, (C.GLenum)(cap))
+	if tracing() {
+		trace("glDisable", []interface{}{Enum(cap)}, nil)
+	}
 }
 
 // Enable or disable a generic vertex attribute     array
 func DisableVertexAttribArray(index uint32) {
 	C.glowDisableVertexAttribArray(gpDisableVertexAttribArray, (C.GLuint)(index))
+	if tracing() {
+		trace("glDisableVertexAttribArray", []interface{}{index}, nil)
+	}
+}
+
+// launch one or more compute work groups
+func DispatchCompute(num_groups_x uint32, num_groups_y uint32, num_groups_z uint32) {
+	C.glowDispatchCompute(gpDispatchCompute, (C.GLuint)(num_groups_x), (C.GLuint)(num_groups_y), (C.GLuint)(num_groups_z))
+	if tracing() {
+		trace("glDispatchCompute", []interface{}{num_groups_x, num_groups_y, num_groups_z}, nil)
+	}
 }
 
 // render primitives from array data
 func DrawArrays(mode uint32, first int32, count int32) {
 	C.glowDrawArrays(gpDrawArrays, (C.GLenum)(mode), (C.GLint)(first), (C.GLsizei)(count))
+	if tracing() {
+		trace("glDrawArrays", []interface{}{Enum(mode), first, count}, nil)
+	}
 }
 
 // render primitives from array data
 func DrawElements(mode uint32, count int32, xtype uint32, indices unsafe.Pointer) {
 	C.glowDrawElements(gpDrawElements, (C.GLenum)(mode), (C.GLsizei)(count), (C.GLenum)(xtype), indices)
+	if tracing() {
+		trace("glDrawElements", []interface{}{Enum(mode), count, Enum(xtype), indices}, nil)
+	}
+}
+
+// draw multiple instances of a set of elements
+func DrawElementsInstanced(mode uint32, count int32, xtype uint32, indices unsafe.Pointer, instancecount int32) {
+	C.glowDrawElementsInstanced(gpDrawElementsInstanced, (C.GLenum)(mode), (C.GLsizei)(count), (C.GLenum)(xtype), indices, (C.GLsizei)(instancecount))
+	if tracing() {
+		trace("glDrawElementsInstanced", []interface{}{Enum(mode), count, Enum(xtype), indices, instancecount}, nil)
+	}
+}
+func DrawElementsInstancedARB(mode uint32, count int32, xtype uint32, indices unsafe.Pointer, primcount int32) {
+	C.glowDrawElementsInstancedARB(gpDrawElementsInstancedARB, (C.GLenum)(mode), (C.GLsizei)(count), (C.GLenum)(xtype), indices, (C.GLsizei)(primcount))
+	if tracing() {
+		trace("glDrawElementsInstancedARB", []interface{}{Enum(mode), count, Enum(xtype), indices, primcount}, nil)
+	}
 }
 
 // enable or disable server-side GL capabilities
 func Enable(cap uint32) {
 	C.glowEnable(gpEnable, (C.GLenum)(cap))
+	if tracing() {
+		trace("glEnable", []interface{}{Enum(cap)}, nil)
+	}
 }
 
 // Enable or disable a generic vertex attribute     array
 func EnableVertexAttribArray(index uint32) {
 	C.glowEnableVertexAttribArray(gpEnableVertexAttribArray, (C.GLuint)(index))
+	if tracing() {
+		trace("glEnableVertexAttribArray", []interface{}{index}, nil)
+	}
 }
 func EndQuery(target uint32) {
 	C.glowEndQuery(gpEndQuery, (C.GLenum)(target))
+	if tracing() {
+		trace("glEndQuery", []interface{}{Enum(target)}, nil)
+	}
+}
+
+// create a new sync object and insert it into the GL command stream
+func FenceSync(condition uint32, flags uint32) uintptr {
+	ret := C.glowFenceSync(gpFenceSync, (C.GLenum)(condition), (C.GLbitfield)(flags))
+	if tracing() {
+		trace("glFenceSync", []interface{}{Enum(condition), flags}, (uintptr)(unsafe.Pointer(ret)))
+	}
+	return (uintptr)(unsafe.Pointer(ret))
 }
 
 // block until all GL execution is complete
 func Finish() {
 	C.glowFinish(gpFinish)
+	if tracing() {
+		trace("glFinish", []interface{}{}, nil)
+	}
 }
 
 // force execution of GL commands in finite time
 func Flush() {
 	C.glowFlush(gpFlush)
+	if tracing() {
+		trace("glFlush", []interface{}{}, nil)
+	}
 }
 
 // attach a renderbuffer as a logical buffer of a framebuffer object
 func FramebufferRenderbuffer(target uint32, attachment uint32, renderbuffertarget uint32, renderbuffer uint32) {
 	C.glowFramebufferRenderbuffer(gpFramebufferRenderbuffer, (C.GLenum)(target), (C.GLenum)(attachment), (C.GLenum)(renderbuffertarget), (C.GLuint)(renderbuffer))
+	if tracing() {
+		trace("glFramebufferRenderbuffer", []interface{}{Enum(target), Enum(attachment), Enum(renderbuffertarget), renderbuffer}, nil)
+	}
 }
 func FramebufferTexture2D(target uint32, attachment uint32, textarget uint32, texture uint32, level int32) {
 	C.glowFramebufferTexture2D(gpFramebufferTexture2D, (C.GLenum)(target), (C.GLenum)(attachment), (C.GLenum)(textarget), (C.GLuint)(texture), (C.GLint)(level))
+	if tracing() {
+		trace("glFramebufferTexture2D", []interface{}{Enum(target), Enum(attachment), Enum(textarget), texture, level}, nil)
+	}
 }
 
 // generate buffer object names
 func GenBuffers(n int32, buffers *uint32) {
 	C.glowGenBuffers(gpGenBuffers, (C.GLsizei)(n), (*C.GLuint)(unsafe.Pointer(buffers)))
+	if tracing() {
+		trace("glGenBuffers", []interface{}{n, traceUint32s(buffers, n)}, nil)
+	}
 }
 
 // generate framebuffer object names
 func GenFramebuffers(n int32, framebuffers *uint32) {
 	C.glowGenFramebuffers(gpGenFramebuffers, (C.GLsizei)(n), (*C.GLuint)(unsafe.Pointer(framebuffers)))
+	if tracing() {
+		trace("glGenFramebuffers", []interface{}{n, traceUint32s(framebuffers, n)}, nil)
+	}
 }
 
 // generate query object names
 func GenQueries(n int32, ids *uint32) {
 	C.glowGenQueries(gpGenQueries, (C.GLsizei)(n), (*C.GLuint)(unsafe.Pointer(ids)))
+	if tracing() {
+		trace("glGenQueries", []interface{}{n, traceUint32s(ids, n)}, nil)
+	}
 }
 
 // generate renderbuffer object names
 func GenRenderbuffers(n int32, renderbuffers *uint32) {
 	C.glowGenRenderbuffers(gpGenRenderbuffers, (C.GLsizei)(n), (*C.GLuint)(unsafe.Pointer(renderbuffers)))
+	if tracing() {
+		trace("glGenRenderbuffers", []interface{}{n, traceUint32s(renderbuffers, n)}, nil)
+	}
+}
+
+// generate sampler object names
+func GenSamplers(count int32, samplers *uint32) {
+	C.glowGenSamplers(gpGenSamplers, (C.GLsizei)(count), (*C.GLuint)(unsafe.Pointer(samplers)))
+	if tracing() {
+		trace("glGenSamplers", []interface{}{count, traceUint32s(samplers, count)}, nil)
+	}
 }
 
 // generate texture names
 func GenTextures(n int32, textures *uint32) {
 	C.glowGenTextures(gpGenTextures, (C.GLsizei)(n), (*C.GLuint)(unsafe.Pointer(textures)))
+	if tracing() {
+		trace("glGenTextures", []interface{}{n, traceUint32s(textures, n)}, nil)
+	}
 }
 
 // generate mipmaps for a specified texture object
 func GenerateMipmap(target uint32) {
 	C.glowGenerateMipmap(gpGenerateMipmap, (C.GLenum)(target))
+	if tracing() {
+		trace("glGenerateMipmap", []interface{}{Enum(target)}, nil)
+	}
+}
+
+// Returns information about an active attribute variable for the specified program object
+func GetActiveAttrib(program uint32, index uint32, bufSize int32, length *int32, size *int32, xtype *uint32, name *uint8) {
+	C.glowGetActiveAttrib(gpGetActiveAttrib, (C.GLuint)(program), (C.GLuint)(index), (C.GLsizei)(bufSize), (*C.GLsizei)(unsafe.Pointer(length)), (*C.GLint)(unsafe.Pointer(size)), (*C.GLenum)(unsafe.Pointer(xtype)), (*C.GLchar)(unsafe.Pointer(name)))
+	if tracing() {
+		trace("glGetActiveAttrib", []interface{}{program, index, bufSize, length, size, xtype, name}, nil)
+	}
+}
+
+// Returns information about an active uniform variable for the specified program object
+func GetActiveUniform(program uint32, index uint32, bufSize int32, length *int32, size *int32, xtype *uint32, name *uint8) {
+	C.glowGetActiveUniform(gpGetActiveUniform, (C.GLuint)(program), (C.GLuint)(index), (C.GLsizei)(bufSize), (*C.GLsizei)(unsafe.Pointer(length)), (*C.GLint)(unsafe.Pointer(size)), (*C.GLenum)(unsafe.Pointer(xtype)), (*C.GLchar)(unsafe.Pointer(name)))
+	if tracing() {
+		trace("glGetActiveUniform", []interface{}{program, index, bufSize, length, size, xtype, name}, nil)
+	}
 }
 
 // Returns the location of an attribute variable
 func GetAttribLocation(program uint32, name *uint8) int32 {
 	ret := C.glowGetAttribLocation(gpGetAttribLocation, (C.GLuint)(program), (*C.GLchar)(unsafe.Pointer(name)))
+	if tracing() {
+		trace("glGetAttribLocation", []interface{}{program, traceStr(name)}, (int32)(ret))
+	}
 	return (int32)(ret)
 }
 func GetBooleanv(pname uint32, data *bool) {
 	C.glowGetBooleanv(gpGetBooleanv, (C.GLenum)(pname), (*C.GLboolean)(unsafe.Pointer(data)))
+	if tracing() {
+		trace("glGetBooleanv", []interface{}{Enum(pname), data}, nil)
+	}
 }
 func GetDoublev(pname uint32, data *float64) {
 	C.glowGetDoublev(gpGetDoublev, (C.GLenum)(pname), (*C.GLdouble)(unsafe.Pointer(data)))
+	if tracing() {
+		trace("glGetDoublev", []interface{}{Enum(pname), data}, nil)
+	}
 }
 
 // return error information
 func GetError() uint32 {
 	ret := C.glowGetError(gpGetError)
+	if tracing() {
+		trace("glGetError", []interface{}{}, (uint32)(ret))
+	}
 	return (uint32)(ret)
 }
 func GetFloatv(pname uint32, data *float32) {
 	C.glowGetFloatv(gpGetFloatv, (C.GLenum)(pname), (*C.GLfloat)(unsafe.Pointer(data)))
+	if tracing() {
+		trace("glGetFloatv", []interface{}{Enum(pname), data}, nil)
+	}
 }
 func GetIntegerv(pname uint32, data *int32) {
 	C.glowGetIntegerv(gpGetIntegerv, (C.GLenum)(pname), (*C.GLint)(unsafe.Pointer(data)))
+	if tracing() {
+		trace("glGetIntegerv", []interface{}{Enum(pname), data}, nil)
+	}
+}
+
+// return a binary representation of a program object's compiled and linked executable source
+func GetProgramBinary(program uint32, bufSize int32, length *int32, binaryFormat *uint32, binary unsafe.Pointer) {
+	C.glowGetProgramBinary(gpGetProgramBinary, (C.GLuint)(program), (C.GLsizei)(bufSize), (*C.GLsizei)(unsafe.Pointer(length)), (*C.GLenum)(unsafe.Pointer(binaryFormat)), binary)
+	if tracing() {
+		trace("glGetProgramBinary", []interface{}{program, bufSize, length, binaryFormat, binary}, nil)
+	}
 }
 
 // Returns the information log for a program object
 func GetProgramInfoLog(program uint32, bufSize int32, length *int32, infoLog *uint8) {
 	C.glowGetProgramInfoLog(gpGetProgramInfoLog, (C.GLuint)(program), (C.GLsizei)(bufSize), (*C.GLsizei)(unsafe.Pointer(length)), (*C.GLchar)(unsafe.Pointer(infoLog)))
+	if tracing() {
+		trace("glGetProgramInfoLog", []interface{}{program, bufSize, length, infoLog}, nil)
+	}
 }
 
 // Returns a parameter from a program object
 func GetProgramiv(program uint32, pname uint32, params *int32) {
 	C.glowGetProgramiv(gpGetProgramiv, (C.GLuint)(program), (C.GLenum)(pname), (*C.GLint)(unsafe.Pointer(params)))
+	if tracing() {
+		trace("glGetProgramiv", []interface{}{program, Enum(pname), params}, nil)
+	}
 }
 func GetQueryObjectiv(id uint32, pname uint32, params *int32) {
 	C.glowGetQueryObjectiv(gpGetQueryObjectiv, (C.GLuint)(id), (C.GLenum)(pname), (*C.GLint)(unsafe.Pointer(params)))
+	if tracing() {
+		trace("glGetQueryObjectiv", []interface{}{id, Enum(pname), params}, nil)
+	}
+}
+
+// return parameters of a query object
+func GetQueryObjectui64v(id uint32, pname uint32, params *uint64) {
+	C.glowGetQueryObjectui64v(gpGetQueryObjectui64v, (C.GLuint)(id), (C.GLenum)(pname), (*C.GLuint64)(unsafe.Pointer(params)))
+	if tracing() {
+		trace("glGetQueryObjectui64v", []interface{}{id, Enum(pname), params}, nil)
+	}
 }
 
 // return parameters of a query object target
 func GetQueryiv(target uint32, pname uint32, params *int32) {
 	C.glowGetQueryiv(gpGetQueryiv, (C.GLenum)(target), (C.GLenum)(pname), (*C.GLint)(unsafe.Pointer(params)))
+	if tracing() {
+		trace("glGetQueryiv", []interface{}{Enum(target), Enum(pname), params}, nil)
+	}
 }
 
 // Returns the information log for a shader object
 func GetShaderInfoLog(shader uint32, bufSize int32, length *int32, infoLog *uint8) {
 	C.glowGetShaderInfoLog(gpGetShaderInfoLog, (C.GLuint)(shader), (C.GLsizei)(bufSize), (*C.GLsizei)(unsafe.Pointer(length)), (*C.GLchar)(unsafe.Pointer(infoLog)))
+	if tracing() {
+		trace("glGetShaderInfoLog", []interface{}{shader, bufSize, length, infoLog}, nil)
+	}
 }
 
 // Returns a parameter from a shader object
 func GetShaderiv(shader uint32, pname uint32, params *int32) {
 	C.glowGetShaderiv(gpGetShaderiv, (C.GLuint)(shader), (C.GLenum)(pname), (*C.GLint)(unsafe.Pointer(params)))
+	if tracing() {
+		trace("glGetShaderiv", []interface{}{shader, Enum(pname), params}, nil)
+	}
 }
 
 // return a string describing the current GL connection
 func GetString(name uint32) *uint8 {
 	ret := C.glowGetString(gpGetString, (C.GLenum)(name))
+	if tracing() {
+		trace("glGetString", []interface{}{Enum(name)}, (*uint8)(ret))
+	}
 	return (*uint8)(ret)
 }
 
 // Returns the location of a uniform variable
 func GetUniformLocation(program uint32, name *uint8) int32 {
 	ret := C.glowGetUniformLocation(gpGetUniformLocation, (C.GLuint)(program), (*C.GLchar)(unsafe.Pointer(name)))
+	if tracing() {
+		trace("glGetUniformLocation", []interface{}{program, traceStr(name)}, (int32)(ret))
+	}
 	return (int32)(ret)
 }
+func LabelObjectEXT(xtype uint32, object uint32, length int32, label *uint8) {
+	C.glowLabelObjectEXT(gpLabelObjectEXT, (C.GLenum)(xtype), (C.GLuint)(object), (C.GLsizei)(length), (*C.GLchar)(unsafe.Pointer(label)))
+	if tracing() {
+		trace("glLabelObjectEXT", []interface{}{Enum(xtype), object, length, traceStr(label)}, nil)
+	}
+}
 
 // Links a program object
 func LinkProgram(program uint32) {
 	C.glowLinkProgram(gpLinkProgram, (C.GLuint)(program))
+	if tracing() {
+		trace("glLinkProgram", []interface{}{program}, nil)
+	}
+}
+
+// map a buffer object's data store
+func MapBuffer(target uint32, access uint32) unsafe.Pointer {
+	ret := C.glowMapBuffer(gpMapBuffer, (C.GLenum)(target), (C.GLenum)(access))
+	if tracing() {
+		trace("glMapBuffer", []interface{}{Enum(target), Enum(access)}, (unsafe.Pointer)(ret))
+	}
+	return (unsafe.Pointer)(ret)
+}
+
+// map all or part of a buffer object's data store into the client's address space
+func MapBufferRange(target uint32, offset int, length int, access uint32) unsafe.Pointer {
+	ret := C.glowMapBufferRange(gpMapBufferRange, (C.GLenum)(target), (C.GLintptr)(offset), (C.GLsizeiptr)(length), (C.GLbitfield)(access))
+	if tracing() {
+		trace("glMapBufferRange", []interface{}{Enum(target), offset, length, access}, (unsafe.Pointer)(ret))
+	}
+	return (unsafe.Pointer)(ret)
+}
+
+// defines a barrier ordering memory transactions
+func MemoryBarrier(barriers uint32) {
+	C.glowMemoryBarrier(gpMemoryBarrier, (C.GLbitfield)(barriers))
+	if tracing() {
+		trace("glMemoryBarrier", []interface{}{barriers}, nil)
+	}
+}
+
+// render indexed primitives from array data, taking parameters from memory
+func MultiDrawElementsIndirect(mode uint32, xtype uint32, indirect unsafe.Pointer, drawcount int32, stride int32) {
+	C.glowMultiDrawElementsIndirect(gpMultiDrawElementsIndirect, (C.GLenum)(mode), (C.GLenum)(xtype), indirect, (C.GLsizei)(drawcount), (C.GLsizei)(stride))
+	if tracing() {
+		trace("glMultiDrawElementsIndirect", []interface{}{Enum(mode), Enum(xtype), indirect, drawcount, stride}, nil)
+	}
+}
+
+// label a named object identified within a namespace
+func ObjectLabel(identifier uint32, name uint32, length int32, label *uint8) {
+	C.glowObjectLabel(gpObjectLabel, (C.GLenum)(identifier), (C.GLuint)(name), (C.GLsizei)(length), (*C.GLchar)(unsafe.Pointer(label)))
+	if tracing() {
+		trace("glObjectLabel", []interface{}{Enum(identifier), name, length, traceStr(label)}, nil)
+	}
+}
+
+// pop the active debug group
+func PopDebugGroup() {
+	C.glowPopDebugGroup(gpPopDebugGroup)
+	if tracing() {
+		trace("glPopDebugGroup", []interface{}{}, nil)
+	}
+}
+func PopGroupMarkerEXT() {
+	C.glowPopGroupMarkerEXT(gpPopGroupMarkerEXT)
+	if tracing() {
+		trace("glPopGroupMarkerEXT", []interface{}{}, nil)
+	}
+}
+
+// load a program object with a program binary
+func ProgramBinary(program uint32, binaryFormat uint32, binary unsafe.Pointer, length int32) {
+	C.glowProgramBinary(gpProgramBinary, (C.GLuint)(program), (C.GLenum)(binaryFormat), binary, (C.GLsizei)(length))
+	if tracing() {
+		trace("glProgramBinary", []interface{}{program, Enum(binaryFormat), binary, length}, nil)
+	}
+}
+
+// specify a parameter for a program object
+func ProgramParameteri(program uint32, pname uint32, value int32) {
+	C.glowProgramParameteri(gpProgramParameteri, (C.GLuint)(program), (C.GLenum)(pname), (C.GLint)(value))
+	if tracing() {
+		trace("glProgramParameteri", []interface{}{program, Enum(pname), value}, nil)
+	}
+}
+
+// push a named debug group into the command stream
+func PushDebugGroup(source uint32, id uint32, length int32, message *uint8) {
+	C.glowPushDebugGroup(gpPushDebugGroup, (C.GLenum)(source), (C.GLuint)(id), (C.GLsizei)(length), (*C.GLchar)(unsafe.Pointer(message)))
+	if tracing() {
+		trace("glPushDebugGroup", []interface{}{Enum(source), id, length, traceStr(message)}, nil)
+	}
+}
+func PushGroupMarkerEXT(length int32, marker *uint8) {
+	C.glowPushGroupMarkerEXT(gpPushGroupMarkerEXT, (C.GLsizei)(length), (*C.GLchar)(unsafe.Pointer(marker)))
+	if tracing() {
+		trace("glPushGroupMarkerEXT", []interface{}{length, traceStr(marker)}, nil)
+	}
 }
 
 // read a block of pixels from the frame buffer
 func ReadPixels(x int32, y int32, width int32, height int32, format uint32, xtype uint32, pixels unsafe.Pointer) {
 	C.glowReadPixels(gpReadPixels, (C.GLint)(x), (C.GLint)(y), (C.GLsizei)(width), (C.GLsizei)(height), (C.GLenum)(format), (C.GLenum)(xtype), pixels)
+	if tracing() {
+		trace("glReadPixels", []interface{}{x, y, width, height, Enum(format), Enum(xtype), pixels}, nil)
+	}
 }
 
 // establish data storage, format, dimensions and sample count of     a renderbuffer object's image
 func RenderbufferStorageMultisample(target uint32, samples int32, internalformat uint32, width int32, height int32) {
 	C.glowRenderbufferStorageMultisample(gpRenderbufferStorageMultisample, (C.GLenum)(target), (C.GLsizei)(samples), (C.GLenum)(internalformat), (C.GLsizei)(width), (C.GLsizei)(height))
+	if tracing() {
+		trace("glRenderbufferStorageMultisample", []interface{}{Enum(target), samples, Enum(internalformat), width, height}, nil)
+	}
+}
+
+// set sampler parameters
+func SamplerParameterf(sampler uint32, pname uint32, param float32) {
+	C.glowSamplerParameterf(gpSamplerParameterf, (C.GLuint)(sampler), (C.GLenum)(pname), (C.GLfloat)(param))
+	if tracing() {
+		trace("glSamplerParameterf", []interface{}{sampler, Enum(pname), param}, nil)
+	}
+}
+func SamplerParameterfv(sampler uint32, pname uint32, param *float32) {
+	C.glowSamplerParameterfv(gpSamplerParameterfv, (C.GLuint)(sampler), (C.GLenum)(pname), (*C.GLfloat)(unsafe.Pointer(param)))
+	if tracing() {
+		trace("glSamplerParameterfv", []interface{}{sampler, Enum(pname), param}, nil)
+	}
+}
+func SamplerParameteri(sampler uint32, pname uint32, param int32) {
+	C.glowSamplerParameteri(gpSamplerParameteri, (C.GLuint)(sampler), (C.GLenum)(pname), (C.GLint)(param))
+	if tracing() {
+		trace("glSamplerParameteri", []interface{}{sampler, Enum(pname), param}, nil)
+	}
 }
 
 // define the scissor box
 func Scissor(x int32, y int32, width int32, height int32) {
 	C.glowScissor(gpScissor, (C.GLint)(x), (C.GLint)(y), (C.GLsizei)(width), (C.GLsizei)(height))
+	if tracing() {
+		trace("glScissor", []interface{}{x, y, width, height}, nil)
+	}
 }
 
 // Replaces the source code in a shader object
 func ShaderSource(shader uint32, count int32, xstring **uint8, length *int32) {
 	C.glowShaderSource(gpShaderSource, (C.GLuint)(shader), (C.GLsizei)(count), (**C.GLchar)(unsafe.Pointer(xstring)), (*C.GLint)(unsafe.Pointer(length)))
+	if tracing() {
+		trace("glShaderSource", []interface{}{shader, count, xstring, length}, nil)
+	}
 }
 
 // set front and/or back function and reference value for stencil testing
 func StencilFuncSeparate(face uint32, xfunc uint32, ref int32, mask uint32) {
 	C.glowStencilFuncSeparate(gpStencilFuncSeparate, (C.GLenum)(face), (C.GLenum)(xfunc), (C.GLint)(ref), (C.GLuint)(mask))
+	if tracing() {
+		trace("glStencilFuncSeparate", []interface{}{Enum(face), Enum(xfunc), ref, mask}, nil)
+	}
 }
 
 // control the front and/or back writing of individual bits in the stencil planes
 func StencilMaskSeparate(face uint32, mask uint32) {
 	C.glowStencilMaskSeparate(gpStencilMaskSeparate, (C.GLenum)(face), (C.GLuint)(mask))
+	if tracing() {
+		trace("glStencilMaskSeparate", []interface{}{Enum(face), mask}, nil)
+	}
 }
 
 // set front and/or back stencil test actions
 func StencilOpSeparate(face uint32, sfail uint32, dpfail uint32, dppass uint32) {
 	C.glowStencilOpSeparate(gpStencilOpSeparate, (C.GLenum)(face), (C.GLenum)(sfail), (C.GLenum)(dpfail), (C.GLenum)(dppass))
+	if tracing() {
+		trace("glStencilOpSeparate", []interface{}{Enum(face), Enum(sfail), Enum(dpfail), Enum(dppass)}, nil)
+	}
 }
 
 // specify a two-dimensional texture image
 func TexImage2D(target uint32, level int32, internalformat int32, width int32, height int32, border int32, format uint32, xtype uint32, pixels unsafe.Pointer) {
 	C.glowTexImage2D(gpTexImage2D, (C.GLenum)(target), (C.GLint)(level), (C.GLint)(internalformat), (C.GLsizei)(width), (C.GLsizei)(height), (C.GLint)(border), (C.GLenum)(format), (C.GLenum)(xtype), pixels)
+	if tracing() {
+		trace("glTexImage2D", []interface{}{Enum(target), level, internalformat, width, height, border, Enum(format), Enum(xtype), pixels}, nil)
+	}
+}
+
+// set texture parameters
+func TexParameterf(target uint32, pname uint32, param float32) {
+	C.glowTexParameterf(gpTexParameterf, (C.GLenum)(target), (C.GLenum)(pname), (C.GLfloat)(param))
+	if tracing() {
+		trace("glTexParameterf", []interface{}{Enum(target), Enum(pname), param}, nil)
+	}
 }
 func TexParameterfv(target uint32, pname uint32, params *float32) {
 	C.glowTexParameterfv(gpTexParameterfv, (C.GLenum)(target), (C.GLenum)(pname), (*C.GLfloat)(unsafe.Pointer(params)))
+	if tracing() {
+		trace("glTexParameterfv", []interface{}{Enum(target), Enum(pname), params}, nil)
+	}
 }
 func TexParameteri(target uint32, pname uint32, param int32) {
 	C.glowTexParameteri(gpTexParameteri, (C.GLenum)(target), (C.GLenum)(pname), (C.GLint)(param))
+	if tracing() {
+		trace("glTexParameteri", []interface{}{Enum(target), Enum(pname), param}, nil)
+	}
 }
 
 // Specify the value of a uniform variable for the current program object
 func Uniform1fv(location int32, count int32, value *float32) {
 	C.glowUniform1fv(gpUniform1fv, (C.GLint)(location), (C.GLsizei)(count), (*C.GLfloat)(unsafe.Pointer(value)))
+	if tracing() {
+		trace("glUniform1fv", []interface{}{location, count, value}, nil)
+	}
 }
 
 // Specify the value of a uniform variable for the current program object
 func Uniform1i(location int32, v0 int32) {
 	C.glowUniform1i(gpUniform1i, (C.GLint)(location), (C.GLint)(v0))
+	if tracing() {
+		trace("glUniform1i", []interface{}{location, v0}, nil)
+	}
 }
 
 // Specify the value of a uniform variable for the current program object
 func Uniform1iv(location int32, count int32, value *int32) {
 	C.glowUniform1iv(gpUniform1iv, (C.GLint)(location), (C.GLsizei)(count), (*C.GLint)(unsafe.Pointer(value)))
+	if tracing() {
+		trace("glUniform1iv", []interface{}{location, count, value}, nil)
+	}
 }
 
 // Specify the value of a uniform variable for the current program object
 func Uniform2fv(location int32, count int32, value *float32) {
 	C.glowUniform2fv(gpUniform2fv, (C.GLint)(location), (C.GLsizei)(count), (*C.GLfloat)(unsafe.Pointer(value)))
+	if tracing() {
+		trace("glUniform2fv", []interface{}{location, count, value}, nil)
+	}
 }
 
 // Specify the value of a uniform variable for the current program object
 func Uniform3fv(location int32, count int32, value *float32) {
 	C.glowUniform3fv(gpUniform3fv, (C.GLint)(location), (C.GLsizei)(count), (*C.GLfloat)(unsafe.Pointer(value)))
+	if tracing() {
+		trace("glUniform3fv", []interface{}{location, count, value}, nil)
+	}
 }
 
 // Specify the value of a uniform variable for the current program object
 func Uniform4fv(location int32, count int32, value *float32) {
 	C.glowUniform4fv(gpUniform4fv, (C.GLint)(location), (C.GLsizei)(count), (*C.GLfloat)(unsafe.Pointer(value)))
+	if tracing() {
+		trace("glUniform4fv", []interface{}{location, count, value}, nil)
+	}
 }
 
 // Specify the value of a uniform variable for the current program object
 func UniformMatrix4fv(location int32, count int32, transpose bool, value *float32) {
 	C.glowUniformMatrix4fv(gpUniformMatrix4fv, (C.GLint)(location), (C.GLsizei)(count), (C.GLboolean)(boolToInt(transpose)), (*C.GLfloat)(unsafe.Pointer(value)))
+	if tracing() {
+		trace("glUniformMatrix4fv", []interface{}{location, count, transpose, value}, nil)
+	}
+}
+
+// release the mapping of a buffer object's data store
+func UnmapBuffer(target uint32) bool {
+	ret := C.glowUnmapBuffer(gpUnmapBuffer, (C.GLenum)(target))
+	if tracing() {
+		trace("glUnmapBuffer", []interface{}{Enum(target)}, ret == TRUE)
+	}
+	return ret == TRUE
 }
 
 // Installs a program object as part of current rendering state
 func UseProgram(program uint32) {
 	C.glowUseProgram(gpUseProgram, (C.GLuint)(program))
+	if tracing() {
+		trace("glUseProgram", []interface{}{program}, nil)
+	}
 }
 
 // define an array of generic vertex attribute data
 func VertexAttribPointer(index uint32, size int32, xtype uint32, normalized bool, stride int32, pointer unsafe.Pointer) {
 	C.glowVertexAttribPointer(gpVertexAttribPointer, (C.GLuint)(index), (C.GLint)(size), (C.GLenum)(xtype), (C.GLboolean)(boolToInt(normalized)), (C.GLsizei)(stride), pointer)
+	if tracing() {
+		trace("glVertexAttribPointer", []interface{}{index, size, Enum(xtype), normalized, stride, pointer}, nil)
+	}
 }
 
 // set the viewport
 func Viewport(x int32, y int32, width int32, height int32) {
 	C.glowViewport(gpViewport, (C.GLint)(x), (C.GLint)(y), (C.GLsizei)(width), (C.GLsizei)(height))
+	if tracing() {
+		trace("glViewport", []interface{}{x, y, width, height}, nil)
+	}
 }
 func Init() error {
 	return InitWithProcAddrFunc(getProcAddress)
@@ -1166,8 +1900,10 @@ func InitWithProcAddrFunc(getProcAddr func(name string) unsafe.Pointer) error {
 	if gpBindBuffer == nil {
 		return errors.New("glBindBuffer")
 	}
+	gpBindBufferBase = (C.GPBINDBUFFERBASE)(getProcAddr("glBindBufferBase"))
 	gpBindFramebuffer = (C.GPBINDFRAMEBUFFER)(getProcAddr("glBindFramebuffer"))
 	gpBindRenderbuffer = (C.GPBINDRENDERBUFFER)(getProcAddr("glBindRenderbuffer"))
+	gpBindSampler = (C.GPBINDSAMPLER)(getProcAddr("glBindSampler"))
 	gpBindTexture = (C.GPBINDTEXTURE)(getProcAddr("glBindTexture"))
 	if gpBindTexture == nil {
 		return errors.New("glBindTexture")
@@ -1188,6 +1924,7 @@ func InitWithProcAddrFunc(getProcAddr func(name string) unsafe.Pointer) error {
 	if gpBufferData == nil {
 		return errors.New("glBufferData")
 	}
+	gpBufferStorage = (C.GPBUFFERSTORAGE)(getProcAddr("glBufferStorage"))
 	gpCheckFramebufferStatus = (C.GPCHECKFRAMEBUFFERSTATUS)(getProcAddr("glCheckFramebufferStatus"))
 	gpClear = (C.GPCLEAR)(getProcAddr("glClear"))
 	if gpClear == nil {
@@ -1206,6 +1943,7 @@ func InitWithProcAddrFunc(getProcAddr func(name string) unsafe.Pointer) error {
 	if gpClearStencil == nil {
 		return errors.New("glClearStencil")
 	}
+	gpClientWaitSync = (C.GPCLIENTWAITSYNC)(getProcAddr("glClientWaitSync"))
 	gpColorMask = (C.GPCOLORMASK)(getProcAddr("glColorMask"))
 	if gpColorMask == nil {
 		return errors.New("glColorMask")
@@ -1214,6 +1952,14 @@ func InitWithProcAddrFunc(getProcAddr func(name string) unsafe.Pointer) error {
 	if gpCompileShader == nil {
 		return errors.New("glCompileShader")
 	}
+	gpCompressedTexImage2D = (C.GPCOMPRESSEDTEXIMAGE2D)(getProcAddr("glCompressedTexImage2D"))
+	if gpCompressedTexImage2D == nil {
+		return errors.New("glCompressedTexImage2D")
+	}
+	gpCopyTexSubImage2D = (C.GPCOPYTEXSUBIMAGE2D)(getProcAddr("glCopyTexSubImage2D"))
+	if gpCopyTexSubImage2D == nil {
+		return errors.New("glCopyTexSubImage2D")
+	}
 	gpCreateProgram = (C.GPCREATEPROGRAM)(getProcAddr("glCreateProgram"))
 	if gpCreateProgram == nil {
 		return errors.New("glCreateProgram")
@@ -1245,6 +1991,7 @@ func InitWithProcAddrFunc(getProcAddr func(name string) unsafe.Pointer) error {
 	if gpDeleteShader == nil {
 		return errors.New("glDeleteShader")
 	}
+	gpDeleteSync = (C.GPDELETESYNC)(getProcAddr("glDeleteSync"))
 	gpDeleteTextures = (C.GPDELETETEXTURES)(getProcAddr("glDeleteTextures"))
 	if gpDeleteTextures == nil {
 		return errors.New("glDeleteTextures")
@@ -1265,6 +2012,7 @@ func InitWithProcAddrFunc(getProcAddr func(name string) unsafe.Pointer) error {
 	if gpDisableVertexAttribArray == nil {
 		return errors.New("glDisableVertexAttribArray")
 	}
+	gpDispatchCompute = (C.GPDISPATCHCOMPUTE)(getProcAddr("glDispatchCompute"))
 	gpDrawArrays = (C.GPDRAWARRAYS)(getProcAddr("glDrawArrays"))
 	if gpDrawArrays == nil {
 		return errors.New("glDrawArrays")
@@ -1273,6 +2021,8 @@ func InitWithProcAddrFunc(getProcAddr func(name string) unsafe.Pointer) error {
 	if gpDrawElements == nil {
 		return errors.New("glDrawElements")
 	}
+	gpDrawElementsInstanced = (C.GPDRAWELEMENTSINSTANCED)(getProcAddr("glDrawElementsInstanced"))
+	gpDrawElementsInstancedARB = (C.GPDRAWELEMENTSINSTANCEDARB)(getProcAddr("glDrawElementsInstancedARB"))
 	gpEnable = (C.GPENABLE)(getProcAddr("glEnable"))
 	if gpEnable == nil {
 		return errors.New("glEnable")
@@ -1285,6 +2035,7 @@ func InitWithProcAddrFunc(getProcAddr func(name string) unsafe.Pointer) error {
 	if gpEndQuery == nil {
 		return errors.New("glEndQuery")
 	}
+	gpFenceSync = (C.GPFENCESYNC)(getProcAddr("glFenceSync"))
 	gpFinish = (C.GPFINISH)(getProcAddr("glFinish"))
 	if gpFinish == nil {
 		return errors.New("glFinish")
@@ -1305,11 +2056,20 @@ func InitWithProcAddrFunc(getProcAddr func(name string) unsafe.Pointer) error {
 		return errors.New("glGenQueries")
 	}
 	gpGenRenderbuffers = (C.GPGENRENDERBUFFERS)(getProcAddr("glGenRenderbuffers"))
+	gpGenSamplers = (C.GPGENSAMPLERS)(getProcAddr("glGenSamplers"))
 	gpGenTextures = (C.GPGENTEXTURES)(getProcAddr("glGenTextures"))
 	if gpGenTextures == nil {
 		return errors.New("glGenTextures")
 	}
 	gpGenerateMipmap = (C.GPGENERATEMIPMAP)(getProcAddr("glGenerateMipmap"))
+	gpGetActiveAttrib = (C.GPGETACTIVEATTRIB)(getProcAddr("glGetActiveAttrib"))
+	if gpGetActiveAttrib == nil {
+		return errors.New("glGetActiveAttrib")
+	}
+	gpGetActiveUniform = (C.GPGETACTIVEUNIFORM)(getProcAddr("glGetActiveUniform"))
+	if gpGetActiveUniform == nil {
+		return errors.New("glGetActiveUniform")
+	}
 	gpGetAttribLocation = (C.GPGETATTRIBLOCATION)(getProcAddr("glGetAttribLocation"))
 	if gpGetAttribLocation == nil {
 		return errors.New("glGetAttribLocation")
@@ -1334,6 +2094,7 @@ func InitWithProcAddrFunc(getProcAddr func(name string) unsafe.Pointer) error {
 	if gpGetIntegerv == nil {
 		return errors.New("glGetIntegerv")
 	}
+	gpGetProgramBinary = (C.GPGETPROGRAMBINARY)(getProcAddr("glGetProgramBinary"))
 	gpGetProgramInfoLog = (C.GPGETPROGRAMINFOLOG)(getProcAddr("glGetProgramInfoLog"))
 	if gpGetProgramInfoLog == nil {
 		return errors.New("glGetProgramInfoLog")
@@ -1346,6 +2107,7 @@ func InitWithProcAddrFunc(getProcAddr func(name string) unsafe.Pointer) error {
 	if gpGetQueryObjectiv == nil {
 		return errors.New("glGetQueryObjectiv")
 	}
+	gpGetQueryObjectui64v = (C.GPGETQUERYOBJECTUI64V)(getProcAddr("glGetQueryObjectui64v"))
 	gpGetQueryiv = (C.GPGETQUERYIV)(getProcAddr("glGetQueryiv"))
 	if gpGetQueryiv == nil {
 		return errors.New("glGetQueryiv")
@@ -1366,15 +2128,33 @@ func InitWithProcAddrFunc(getProcAddr func(name string) unsafe.Pointer) error {
 	if gpGetUniformLocation == nil {
 		return errors.New("glGetUniformLocation")
 	}
+	gpLabelObjectEXT = (C.GPLABELOBJECTEXT)(getProcAddr("glLabelObjectEXT"))
 	gpLinkProgram = (C.GPLINKPROGRAM)(getProcAddr("glLinkProgram"))
 	if gpLinkProgram == nil {
 		return errors.New("glLinkProgram")
 	}
+	gpMapBuffer = (C.GPMAPBUFFER)(getProcAddr("glMapBuffer"))
+	if gpMapBuffer == nil {
+		return errors.New("glMapBuffer")
+	}
+	gpMapBufferRange = (C.GPMAPBUFFERRANGE)(getProcAddr("glMapBufferRange"))
+	gpMemoryBarrier = (C.GPMEMORYBARRIER)(getProcAddr("glMemoryBarrier"))
+	gpMultiDrawElementsIndirect = (C.GPMULTIDRAWELEMENTSINDIRECT)(getProcAddr("glMultiDrawElementsIndirect"))
+	gpObjectLabel = (C.GPOBJECTLABEL)(getProcAddr("glObjectLabel"))
+	gpPopDebugGroup = (C.GPPOPDEBUGGROUP)(getProcAddr("glPopDebugGroup"))
+	gpPopGroupMarkerEXT = (C.GPPOPGROUPMARKEREXT)(getProcAddr("glPopGroupMarkerEXT"))
+	gpProgramBinary = (C.GPPROGRAMBINARY)(getProcAddr("glProgramBinary"))
+	gpProgramParameteri = (C.GPPROGRAMPARAMETERI)(getProcAddr("glProgramParameteri"))
+	gpPushDebugGroup = (C.GPPUSHDEBUGGROUP)(getProcAddr("glPushDebugGroup"))
+	gpPushGroupMarkerEXT = (C.GPPUSHGROUPMARKEREXT)(getProcAddr("glPushGroupMarkerEXT"))
 	gpReadPixels = (C.GPREADPIXELS)(getProcAddr("glReadPixels"))
 	if gpReadPixels == nil {
 		return errors.New("glReadPixels")
 	}
 	gpRenderbufferStorageMultisample = (C.GPRENDERBUFFERSTORAGEMULTISAMPLE)(getProcAddr("glRenderbufferStorageMultisample"))
+	gpSamplerParameterf = (C.GPSAMPLERPARAMETERF)(getProcAddr("glSamplerParameterf"))
+	gpSamplerParameterfv = (C.GPSAMPLERPARAMETERFV)(getProcAddr("glSamplerParameterfv"))
+	gpSamplerParameteri = (C.GPSAMPLERPARAMETERI)(getProcAddr("glSamplerParameteri"))
 	gpScissor = (C.GPSCISSOR)(getProcAddr("glScissor"))
 	if gpScissor == nil {
 		return errors.New("glScissor")
@@ -1399,6 +2179,10 @@ func InitWithProcAddrFunc(getProcAddr func(name string) unsafe.Pointer) error {
 	if gpTexImage2D == nil {
 		return errors.New("glTexImage2D")
 	}
+	gpTexParameterf = (C.GPTEXPARAMETERF)(getProcAddr("glTexParameterf"))
+	if gpTexParameterf == nil {
+		return errors.New("glTexParameterf")
+	}
 	gpTexParameterfv = (C.GPTEXPARAMETERFV)(getProcAddr("glTexParameterfv"))
 	if gpTexParameterfv == nil {
 		return errors.New("glTexParameterfv")
@@ -1435,6 +2219,10 @@ func InitWithProcAddrFunc(getProcAddr func(name string) unsafe.Pointer) error {
 	if gpUniformMatrix4fv == nil {
 		return errors.New("glUniformMatrix4fv")
 	}
+	gpUnmapBuffer = (C.GPUNMAPBUFFER)(getProcAddr("glUnmapBuffer"))
+	if gpUnmapBuffer == nil {
+		return errors.New("glUnmapBuffer")
+	}
 	gpUseProgram = (C.GPUSEPROGRAM)(getProcAddr("glUseProgram"))
 	if gpUseProgram == nil {
 		return errors.New("glUseProgram")
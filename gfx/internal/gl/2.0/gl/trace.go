@@ -0,0 +1,316 @@
+// Code generated by tracegen; DO NOT EDIT.
+
+package gl
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// Tracer is informed of each OpenGL call made through this package, after the
+// call returns. Calls may be made from multiple goroutines concurrently.
+type Tracer interface {
+	// Call is called with the name, arguments and result (or nil) of a call.
+	Call(name string, args []interface{}, result interface{})
+}
+
+var (
+	traceOn     int32
+	traceAccess sync.RWMutex
+	tracer      Tracer
+)
+
+// SetTracer sets the tracer informed of each call, or disables tracing if t
+// is nil. Tracing costs little more than an atomic load per call while
+// disabled.
+func SetTracer(t Tracer) {
+	traceAccess.Lock()
+	tracer = t
+	on := int32(0)
+	if t != nil {
+		on = 1
+	}
+	atomic.StoreInt32(&traceOn, on)
+	traceAccess.Unlock()
+}
+
+func tracing() bool {
+	return atomic.LoadInt32(&traceOn) != 0
+}
+
+func trace(name string, args []interface{}, result interface{}) {
+	traceAccess.RLock()
+	t := tracer
+	traceAccess.RUnlock()
+	if t != nil {
+		t.Call(name, args, result)
+	}
+}
+
+func traceStr(s *uint8) interface{} {
+	if s == nil {
+		return s
+	}
+	return GoStr(s)
+}
+
+func traceUint32s(p *uint32, n int32) interface{} {
+	if p == nil || n <= 0 {
+		return p
+	}
+	return append([]uint32(nil), (*[1 << 28]uint32)(unsafe.Pointer(p))[:n:n]...)
+}
+
+// Enum is an OpenGL enum argument of a traced call.
+type Enum uint32
+
+// String returns the name of the enum, e.g. "GL_TEXTURE_2D", or it's value
+// in hexadecimal if it has no name.
+func (e Enum) String() string {
+	if name, ok := enumNames[uint64(e)]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%X", uint32(e))
+}
+
+var enumNames = map[uint64]string{
+	0x0:    "GL_NO_ERROR/GL_POINTS/GL_ZERO",
+	0x1:    "GL_LINES/GL_ONE/GL_SYNC_FLUSH_COMMANDS_BIT/GL_TRUE",
+	0x2:    "GL_MAP_WRITE_BIT",
+	0x4:    "GL_TRIANGLES",
+	0x40:   "GL_COMMAND_BARRIER_BIT/GL_MAP_PERSISTENT_BIT",
+	0x80:   "GL_MAP_COHERENT_BIT",
+	0x100:  "GL_DEPTH_BUFFER_BIT",
+	0x200:  "GL_NEVER",
+	0x201:  "GL_LESS",
+	0x202:  "GL_EQUAL",
+	0x203:  "GL_LEQUAL",
+	0x204:  "GL_GREATER",
+	0x205:  "GL_NOTEQUAL",
+	0x206:  "GL_GEQUAL",
+	0x207:  "GL_ALWAYS",
+	0x300:  "GL_SRC_COLOR",
+	0x301:  "GL_ONE_MINUS_SRC_COLOR",
+	0x302:  "GL_SRC_ALPHA",
+	0x303:  "GL_ONE_MINUS_SRC_ALPHA",
+	0x304:  "GL_DST_ALPHA",
+	0x305:  "GL_ONE_MINUS_DST_ALPHA",
+	0x306:  "GL_DST_COLOR",
+	0x307:  "GL_ONE_MINUS_DST_COLOR",
+	0x308:  "GL_SRC_ALPHA_SATURATE",
+	0x400:  "GL_STENCIL_BUFFER_BIT",
+	0x404:  "GL_FRONT",
+	0x405:  "GL_BACK",
+	0x408:  "GL_FRONT_AND_BACK",
+	0x500:  "GL_INVALID_ENUM",
+	0x501:  "GL_INVALID_VALUE",
+	0x502:  "GL_INVALID_OPERATION",
+	0x503:  "GL_STACK_OVERFLOW",
+	0x504:  "GL_STACK_UNDERFLOW",
+	0x505:  "GL_OUT_OF_MEMORY",
+	0x506:  "GL_INVALID_FRAMEBUFFER_OPERATION",
+	0xB44:  "GL_CULL_FACE",
+	0xB45:  "GL_CULL_FACE_MODE",
+	0xB71:  "GL_DEPTH_TEST",
+	0xB72:  "GL_DEPTH_WRITEMASK",
+	0xB73:  "GL_DEPTH_CLEAR_VALUE",
+	0xB74:  "GL_DEPTH_FUNC",
+	0xB90:  "GL_STENCIL_TEST",
+	0xB91:  "GL_STENCIL_CLEAR_VALUE",
+	0xB92:  "GL_STENCIL_FUNC",
+	0xB93:  "GL_STENCIL_VALUE_MASK",
+	0xB94:  "GL_STENCIL_FAIL",
+	0xB95:  "GL_STENCIL_PASS_DEPTH_FAIL",
+	0xB96:  "GL_STENCIL_PASS_DEPTH_PASS",
+	0xB97:  "GL_STENCIL_REF",
+	0xB98:  "GL_STENCIL_WRITEMASK",
+	0xBA2:  "GL_VIEWPORT",
+	0xBD0:  "GL_DITHER",
+	0xBE2:  "GL_BLEND",
+	0xC10:  "GL_SCISSOR_BOX",
+	0xC11:  "GL_SCISSOR_TEST",
+	0xC22:  "GL_COLOR_CLEAR_VALUE",
+	0xC23:  "GL_COLOR_WRITEMASK",
+	0xD33:  "GL_MAX_TEXTURE_SIZE",
+	0xD52:  "GL_RED_BITS",
+	0xD53:  "GL_GREEN_BITS",
+	0xD54:  "GL_BLUE_BITS",
+	0xD55:  "GL_ALPHA_BITS",
+	0xD56:  "GL_DEPTH_BITS",
+	0xD57:  "GL_STENCIL_BITS",
+	0xDE1:  "GL_TEXTURE_2D",
+	0x1004: "GL_TEXTURE_BORDER_COLOR",
+	0x1401: "GL_UNSIGNED_BYTE",
+	0x1404: "GL_INT",
+	0x1405: "GL_UNSIGNED_INT",
+	0x1406: "GL_FLOAT",
+	0x150A: "GL_INVERT",
+	0x1702: "GL_TEXTURE",
+	0x1902: "GL_DEPTH_COMPONENT",
+	0x1907: "GL_RGB",
+	0x1908: "GL_RGBA",
+	0x1E00: "GL_KEEP",
+	0x1E01: "GL_REPLACE",
+	0x1E02: "GL_INCR",
+	0x1E03: "GL_DECR",
+	0x1F00: "GL_VENDOR",
+	0x1F01: "GL_RENDERER",
+	0x1F02: "GL_VERSION",
+	0x1F03: "GL_EXTENSIONS",
+	0x2000: "GL_SHADER_STORAGE_BARRIER_BIT",
+	0x2600: "GL_NEAREST",
+	0x2601: "GL_LINEAR",
+	0x2700: "GL_NEAREST_MIPMAP_NEAREST",
+	0x2701: "GL_LINEAR_MIPMAP_NEAREST",
+	0x2702: "GL_NEAREST_MIPMAP_LINEAR",
+	0x2703: "GL_LINEAR_MIPMAP_LINEAR",
+	0x2800: "GL_TEXTURE_MAG_FILTER",
+	0x2801: "GL_TEXTURE_MIN_FILTER",
+	0x2802: "GL_TEXTURE_WRAP_S",
+	0x2803: "GL_TEXTURE_WRAP_T",
+	0x2901: "GL_REPEAT",
+	0x4000: "GL_COLOR_BUFFER_BIT",
+	0x8001: "GL_CONSTANT_COLOR",
+	0x8002: "GL_ONE_MINUS_CONSTANT_COLOR",
+	0x8003: "GL_CONSTANT_ALPHA",
+	0x8004: "GL_ONE_MINUS_CONSTANT_ALPHA",
+	0x8005: "GL_BLEND_COLOR",
+	0x8006: "GL_FUNC_ADD",
+	0x8009: "GL_BLEND_EQUATION_RGB",
+	0x800A: "GL_FUNC_SUBTRACT",
+	0x800B: "GL_FUNC_REVERSE_SUBTRACT",
+	0x8051: "GL_RGB8",
+	0x8058: "GL_RGBA8",
+	0x809D: "GL_MULTISAMPLE",
+	0x809E: "GL_SAMPLE_ALPHA_TO_COVERAGE",
+	0x80A8: "GL_SAMPLE_BUFFERS",
+	0x80A9: "GL_SAMPLES",
+	0x80C8: "GL_BLEND_DST_RGB",
+	0x80C9: "GL_BLEND_SRC_RGB",
+	0x80CA: "GL_BLEND_DST_ALPHA",
+	0x80CB: "GL_BLEND_SRC_ALPHA",
+	0x80E1: "GL_BGRA",
+	0x812D: "GL_CLAMP_TO_BORDER",
+	0x812F: "GL_CLAMP_TO_EDGE",
+	0x813C: "GL_TEXTURE_BASE_LEVEL",
+	0x813D: "GL_TEXTURE_MAX_LEVEL",
+	0x8191: "GL_GENERATE_MIPMAP",
+	0x81A5: "GL_DEPTH_COMPONENT16",
+	0x81A6: "GL_DEPTH_COMPONENT24",
+	0x81A7: "GL_DEPTH_COMPONENT32",
+	0x8219: "GL_FRAMEBUFFER_UNDEFINED",
+	0x8242: "GL_DEBUG_OUTPUT_SYNCHRONOUS_ARB",
+	0x824A: "GL_DEBUG_SOURCE_APPLICATION",
+	0x824C: "GL_DEBUG_TYPE_ERROR",
+	0x824D: "GL_DEBUG_TYPE_DEPRECATED_BEHAVIOR",
+	0x824E: "GL_DEBUG_TYPE_UNDEFINED_BEHAVIOR",
+	0x824F: "GL_DEBUG_TYPE_PORTABILITY",
+	0x8250: "GL_DEBUG_TYPE_PERFORMANCE",
+	0x8251: "GL_DEBUG_TYPE_OTHER",
+	0x8257: "GL_PROGRAM_BINARY_RETRIEVABLE_HINT",
+	0x82E0: "GL_BUFFER",
+	0x82E2: "GL_PROGRAM",
+	0x8370: "GL_MIRRORED_REPEAT",
+	0x84C0: "GL_TEXTURE0",
+	0x84FD: "GL_MAX_TEXTURE_LOD_BIAS",
+	0x84FE: "GL_TEXTURE_MAX_ANISOTROPY_EXT",
+	0x84FF: "GL_MAX_TEXTURE_MAX_ANISOTROPY_EXT",
+	0x8501: "GL_TEXTURE_LOD_BIAS",
+	0x8507: "GL_INCR_WRAP",
+	0x8508: "GL_DECR_WRAP",
+	0x8642: "GL_PROGRAM_POINT_SIZE_EXT",
+	0x864F: "GL_DEPTH_CLAMP",
+	0x86A2: "GL_NUM_COMPRESSED_TEXTURE_FORMATS",
+	0x86A3: "GL_COMPRESSED_TEXTURE_FORMATS",
+	0x8741: "GL_PROGRAM_BINARY_LENGTH",
+	0x87FE: "GL_NUM_PROGRAM_BINARY_FORMATS",
+	0x8800: "GL_STENCIL_BACK_FUNC",
+	0x8801: "GL_STENCIL_BACK_FAIL",
+	0x8802: "GL_STENCIL_BACK_PASS_DEPTH_FAIL",
+	0x8803: "GL_STENCIL_BACK_PASS_DEPTH_PASS",
+	0x883D: "GL_BLEND_EQUATION_ALPHA",
+	0x8864: "GL_QUERY_COUNTER_BITS",
+	0x8866: "GL_QUERY_RESULT",
+	0x8867: "GL_QUERY_RESULT_AVAILABLE",
+	0x8869: "GL_MAX_VERTEX_ATTRIBS",
+	0x8872: "GL_MAX_TEXTURE_IMAGE_UNITS",
+	0x8892: "GL_ARRAY_BUFFER",
+	0x8893: "GL_ELEMENT_ARRAY_BUFFER",
+	0x88B8: "GL_READ_ONLY",
+	0x88B9: "GL_WRITE_ONLY",
+	0x88BF: "GL_TIME_ELAPSED",
+	0x88E0: "GL_STREAM_DRAW",
+	0x88E1: "GL_STREAM_READ",
+	0x88E4: "GL_STATIC_DRAW",
+	0x88E8: "GL_DYNAMIC_DRAW",
+	0x88EB: "GL_PIXEL_PACK_BUFFER",
+	0x88EC: "GL_PIXEL_UNPACK_BUFFER",
+	0x88F0: "GL_DEPTH24_STENCIL8",
+	0x8914: "GL_SAMPLES_PASSED",
+	0x8B30: "GL_FRAGMENT_SHADER",
+	0x8B31: "GL_VERTEX_SHADER",
+	0x8B40: "GL_PROGRAM_OBJECT_EXT",
+	0x8B49: "GL_MAX_FRAGMENT_UNIFORM_COMPONENTS",
+	0x8B4A: "GL_MAX_VERTEX_UNIFORM_COMPONENTS",
+	0x8B4B: "GL_MAX_VARYING_FLOATS",
+	0x8B50: "GL_FLOAT_VEC2",
+	0x8B51: "GL_FLOAT_VEC3",
+	0x8B52: "GL_FLOAT_VEC4",
+	0x8B53: "GL_INT_VEC2",
+	0x8B54: "GL_INT_VEC3",
+	0x8B55: "GL_INT_VEC4",
+	0x8B56: "GL_BOOL",
+	0x8B57: "GL_BOOL_VEC2",
+	0x8B58: "GL_BOOL_VEC3",
+	0x8B59: "GL_BOOL_VEC4",
+	0x8B5A: "GL_FLOAT_MAT2",
+	0x8B5B: "GL_FLOAT_MAT3",
+	0x8B5C: "GL_FLOAT_MAT4",
+	0x8B5E: "GL_SAMPLER_2D",
+	0x8B60: "GL_SAMPLER_CUBE",
+	0x8B81: "GL_COMPILE_STATUS",
+	0x8B82: "GL_LINK_STATUS",
+	0x8B84: "GL_INFO_LOG_LENGTH",
+	0x8B86: "GL_ACTIVE_UNIFORMS",
+	0x8B87: "GL_ACTIVE_UNIFORM_MAX_LENGTH",
+	0x8B89: "GL_ACTIVE_ATTRIBUTES",
+	0x8B8A: "GL_ACTIVE_ATTRIBUTE_MAX_LENGTH",
+	0x8B8C: "GL_SHADING_LANGUAGE_VERSION",
+	0x8B8D: "GL_CURRENT_PROGRAM",
+	0x8C41: "GL_SRGB8",
+	0x8C43: "GL_SRGB8_ALPHA8",
+	0x8CA3: "GL_STENCIL_BACK_REF",
+	0x8CA4: "GL_STENCIL_BACK_VALUE_MASK",
+	0x8CA5: "GL_STENCIL_BACK_WRITEMASK",
+	0x8CD5: "GL_FRAMEBUFFER_COMPLETE",
+	0x8CD6: "GL_FRAMEBUFFER_INCOMPLETE_ATTACHMENT",
+	0x8CD7: "GL_FRAMEBUFFER_INCOMPLETE_MISSING_ATTACHMENT",
+	0x8CDB: "GL_FRAMEBUFFER_INCOMPLETE_DRAW_BUFFER",
+	0x8CDC: "GL_FRAMEBUFFER_INCOMPLETE_READ_BUFFER",
+	0x8CDD: "GL_FRAMEBUFFER_UNSUPPORTED",
+	0x8CE0: "GL_COLOR_ATTACHMENT0",
+	0x8D00: "GL_DEPTH_ATTACHMENT",
+	0x8D20: "GL_STENCIL_ATTACHMENT",
+	0x8D40: "GL_FRAMEBUFFER",
+	0x8D41: "GL_RENDERBUFFER",
+	0x8D56: "GL_FRAMEBUFFER_INCOMPLETE_MULTISAMPLE",
+	0x8D57: "GL_MAX_SAMPLES",
+	0x8DB9: "GL_FRAMEBUFFER_SRGB",
+	0x8DFB: "GL_MAX_VERTEX_UNIFORM_VECTORS",
+	0x8DFC: "GL_MAX_VARYING_VECTORS",
+	0x8DFD: "GL_MAX_FRAGMENT_UNIFORM_VECTORS",
+	0x8F3F: "GL_DRAW_INDIRECT_BUFFER",
+	0x90D2: "GL_SHADER_STORAGE_BUFFER",
+	0x9117: "GL_SYNC_GPU_COMMANDS_COMPLETE",
+	0x911A: "GL_ALREADY_SIGNALED",
+	0x911B: "GL_TIMEOUT_EXPIRED",
+	0x911C: "GL_CONDITION_SATISFIED",
+	0x911D: "GL_WAIT_FAILED",
+	0x9146: "GL_DEBUG_SEVERITY_HIGH",
+	0x9147: "GL_DEBUG_SEVERITY_MEDIUM",
+	0x9148: "GL_DEBUG_SEVERITY_LOW",
+	0x9151: "GL_BUFFER_OBJECT_EXT",
+	0x91B9: "GL_COMPUTE_SHADER",
+}
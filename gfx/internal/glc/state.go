@@ -87,6 +87,30 @@ type GraphicsState struct {
 	C     *Context
 	S     *glutil.CommonState
 	Saved *glutil.CommonState
+
+	// Changes is the number of OpenGL state changes that have actually been
+	// made (i.e. those that were not avoided by the state guard). It is only
+	// ever incremented, so callers may take the difference between two
+	// readings.
+	Changes int
+
+	// Skipped is the number of redundant OpenGL state changes that have been
+	// avoided by the state guard (i.e. those which would have set the state
+	// to it's existing value). Like Changes, it is only ever incremented.
+	Skipped int
+}
+
+// Guard reports whether or not an OpenGL state change must be made, given
+// whether or not the new state differs from the existing one, and counts it as
+// either a change or a skipped change. If the state guard is disabled it
+// always returns true.
+func (g *GraphicsState) Guard(differs bool) bool {
+	if noStateGuard || differs {
+		g.Changes++
+		return true
+	}
+	g.Skipped++
+	return false
 }
 
 // Begin begins use of this graphics state by saving the existing OpenGL state
@@ -171,7 +195,7 @@ func (g *GraphicsState) Scissor(bounds, rect image.Rectangle) {
 	// If the intersected scissor rectangle is different from the last one then
 	// we need to make the OpenGL call.
 	rect = bounds.Intersect(rect)
-	if noStateGuard || g.S.Scissor != rect {
+	if g.Guard(g.S.Scissor != rect) {
 		g.S.Scissor = rect
 		x, y, width, height := glutil.ConvertRect(rect, bounds)
 		g.C.gl.Scissor(x, y, width, height)
@@ -184,7 +208,7 @@ func (g *GraphicsState) getScissor(bounds image.Rectangle) image.Rectangle {
 }
 
 func (g *GraphicsState) ColorWrite(red, green, blue, alpha bool) {
-	if noStateGuard || g.S.WriteRed != red || g.S.WriteGreen != green || g.S.WriteBlue != blue || g.S.WriteAlpha != alpha {
+	if g.Guard(g.S.WriteRed != red || g.S.WriteGreen != green || g.S.WriteBlue != blue || g.S.WriteAlpha != alpha) {
 		g.S.WriteRed = red
 		g.S.WriteGreen = green
 		g.S.WriteBlue = blue
@@ -194,49 +218,49 @@ func (g *GraphicsState) ColorWrite(red, green, blue, alpha bool) {
 }
 
 func (g *GraphicsState) ClearColor(color gfx.Color) {
-	if noStateGuard || g.S.ClearColor != color {
+	if g.Guard(g.S.ClearColor != color) {
 		g.S.ClearColor = color
 		g.C.gl.ClearColor(color.R, color.G, color.B, color.A)
 	}
 }
 
 func (g *GraphicsState) DepthWrite(write bool) {
-	if noStateGuard || g.S.DepthWrite != write {
+	if g.Guard(g.S.DepthWrite != write) {
 		g.S.DepthWrite = write
 		g.C.gl.DepthMask(write)
 	}
 }
 
 func (g *GraphicsState) ClearDepth(depth float64) {
-	if noStateGuard || g.S.ClearDepth != depth {
+	if g.Guard(g.S.ClearDepth != depth) {
 		g.S.ClearDepth = depth
 		g.C.gl.ClearDepth(depth)
 	}
 }
 
 func (g *GraphicsState) BlendColor(c gfx.Color) {
-	if noStateGuard || g.S.State.Blend.Color != c {
+	if g.Guard(g.S.State.Blend.Color != c) {
 		g.S.State.Blend.Color = c
 		g.C.gl.BlendColor(c.R, c.G, c.B, c.A)
 	}
 }
 
 func (g *GraphicsState) ClearStencil(stencil int) {
-	if noStateGuard || g.S.ClearStencil != stencil {
+	if g.Guard(g.S.ClearStencil != stencil) {
 		g.S.ClearStencil = stencil
 		g.C.gl.ClearStencil(stencil)
 	}
 }
 
 func (g *GraphicsState) DepthCmp(cmp gfx.Cmp) {
-	if noStateGuard || g.S.DepthCmp != cmp {
+	if g.Guard(g.S.DepthCmp != cmp) {
 		g.S.DepthCmp = cmp
 		g.C.gl.DepthFunc(g.C.ConvertCmp(cmp))
 	}
 }
 
 func (g *GraphicsState) FaceCulling(m gfx.FaceCullMode) {
-	if noStateGuard || g.S.FaceCulling != m {
+	if g.Guard(g.S.FaceCulling != m) {
 		g.S.FaceCulling = m
 		switch m {
 		case gfx.BackFaceCulling:
@@ -274,7 +298,7 @@ func (g *GraphicsState) BlendFuncSeparate(bs gfx.BlendState) {
 		return a.SrcRGB != b.SrcRGB || a.DstRGB != b.DstRGB || a.SrcAlpha != b.SrcAlpha || a.DstAlpha != b.DstAlpha
 	}
 
-	if noStateGuard || diff(g.S.State.Blend, bs) {
+	if g.Guard(diff(g.S.State.Blend, bs)) {
 		g.S.State.Blend.SrcRGB = bs.SrcRGB
 		g.S.State.Blend.DstRGB = bs.DstRGB
 		g.S.State.Blend.SrcAlpha = bs.SrcAlpha
@@ -298,7 +322,7 @@ func (g *GraphicsState) getBlendFuncSeparate(bs *gfx.BlendState) {
 }
 
 func (g *GraphicsState) BlendEquationSeparate(bs gfx.BlendState) {
-	if noStateGuard || (g.S.State.Blend.RGBEq != bs.RGBEq || g.S.State.Blend.AlphaEq != bs.AlphaEq) {
+	if g.Guard((g.S.State.Blend.RGBEq != bs.RGBEq || g.S.State.Blend.AlphaEq != bs.AlphaEq)) {
 		g.S.State.Blend.RGBEq = bs.RGBEq
 		g.S.State.Blend.AlphaEq = bs.AlphaEq
 
@@ -320,7 +344,7 @@ func (g *GraphicsState) StencilOpSeparate(front, back gfx.StencilState) {
 		return a.Fail != b.Fail || a.DepthFail != b.DepthFail || a.DepthPass != b.DepthPass
 	}
 
-	if noStateGuard || diff(g.S.StencilFront, front) || diff(g.S.StencilBack, back) {
+	if g.Guard(diff(g.S.StencilFront, front) || diff(g.S.StencilBack, back)) {
 		g.S.StencilFront.Fail = front.Fail
 		g.S.StencilFront.DepthFail = front.DepthFail
 		g.S.StencilFront.DepthPass = front.DepthPass
@@ -366,49 +390,49 @@ func (g *GraphicsState) getStencilOpSeparate(front, back *gfx.StencilState) {
 }
 
 func (g *GraphicsState) Dithering(v bool) {
-	if noStateGuard || g.S.Dithering != v {
+	if g.Guard(g.S.Dithering != v) {
 		g.S.Dithering = v
 		g.C.Feature(g.C.DITHER, v)
 	}
 }
 
 func (g *GraphicsState) ScissorTest(v bool) {
-	if noStateGuard || g.S.ScissorTest != v {
+	if g.Guard(g.S.ScissorTest != v) {
 		g.S.ScissorTest = v
 		g.C.Feature(g.C.SCISSOR_TEST, v)
 	}
 }
 
 func (g *GraphicsState) StencilTest(v bool) {
-	if noStateGuard || g.S.StencilTest != v {
+	if g.Guard(g.S.StencilTest != v) {
 		g.S.StencilTest = v
 		g.C.Feature(g.C.STENCIL_TEST, v)
 	}
 }
 
 func (g *GraphicsState) DepthTest(v bool) {
-	if noStateGuard || g.S.DepthTest != v {
+	if g.Guard(g.S.DepthTest != v) {
 		g.S.DepthTest = v
 		g.C.Feature(g.C.DEPTH_TEST, v)
 	}
 }
 
 func (g *GraphicsState) Blend(v bool) {
-	if noStateGuard || g.S.Blend != v {
+	if g.Guard(g.S.Blend != v) {
 		g.S.Blend = v
 		g.C.Feature(g.C.BLEND, v)
 	}
 }
 
 func (g *GraphicsState) SampleAlphaToCoverage(v bool) {
-	if noStateGuard || g.S.SampleAlphaToCoverage != v {
+	if g.Guard(g.S.SampleAlphaToCoverage != v) {
 		g.S.SampleAlphaToCoverage = v
 		g.C.Feature(g.C.SAMPLE_ALPHA_TO_COVERAGE, v)
 	}
 }
 
 func (g *GraphicsState) Multisample(v bool) {
-	if noStateGuard || g.S.Multisample != v {
+	if g.Guard(g.S.Multisample != v) {
 		g.S.Multisample = v
 		g.C.Feature(g.C.MULTISAMPLE, v)
 	}
@@ -71,6 +71,7 @@ package gles2
 // typedef void  (APIENTRYP GPCLEARSTENCIL)(GLint  s);
 // typedef void  (APIENTRYP GPCOLORMASK)(GLboolean  red, GLboolean  green, GLboolean  blue, GLboolean  alpha);
 // typedef void  (APIENTRYP GPCOMPILESHADER)(GLuint  shader);
+// typedef void  (APIENTRYP GPCOPYTEXSUBIMAGE2D)(GLenum  target, GLint  level, GLint  xoffset, GLint  yoffset, GLint  x, GLint  y, GLsizei  width, GLsizei  height);
 // typedef GLuint  (APIENTRYP GPCREATEPROGRAM)();
 // typedef GLuint  (APIENTRYP GPCREATESHADER)(GLenum  type);
 // typedef void  (APIENTRYP GPCULLFACE)(GLenum  mode);
@@ -179,6 +180,9 @@ package gles2
 // static void  glowCompileShader(GPCOMPILESHADER fnptr, GLuint  shader) {
 //   (*fnptr)(shader);
 // }
+// static void  glowCopyTexSubImage2D(GPCOPYTEXSUBIMAGE2D fnptr, GLenum  target, GLint  level, GLint  xoffset, GLint  yoffset, GLint  x, GLint  y, GLsizei  width, GLsizei  height) {
+//   (*fnptr)(target, level, xoffset, yoffset, x, y, width, height);
+// }
 // static GLuint  glowCreateProgram(GPCREATEPROGRAM fnptr) {
 //   return (*fnptr)();
 // }
@@ -510,6 +514,7 @@ const (
 	TRUE                                      = 1
 	UNSIGNED_BYTE                             = 0x1401
 	UNSIGNED_INT                              = 0x1405
+	UNSIGNED_SHORT                            = 0x1403
 	VENDOR                                    = 0x1F00
 	VERSION                                   = 0x1F02
 	VERTEX_SHADER                             = 0x8B31
@@ -535,6 +540,7 @@ var (
 	gpClearStencil             C.GPCLEARSTENCIL
 	gpColorMask                C.GPCOLORMASK
 	gpCompileShader            C.GPCOMPILESHADER
+	gpCopyTexSubImage2D        C.GPCOPYTEXSUBIMAGE2D
 	gpCreateProgram            C.GPCREATEPROGRAM
 	gpCreateShader             C.GPCREATESHADER
 	gpCullFace                 C.GPCULLFACE
@@ -605,360 +611,590 @@ func boolToInt(b bool) int {
 // select active texture unit
 func ActiveTexture(texture uint32) {
 	C.glowActiveTexture(gpActiveTexture, (C.GLenum)(texture))
+	if tracing() {
+		trace("glActiveTexture", []interface{}{Enum(texture)}, nil)
+	}
 }
 
 // Attaches a shader object to a program object
 func AttachShader(program uint32, shader uint32) {
 	C.glowAttachShader(gpAttachShader, (C.GLuint)(program), (C.GLuint)(shader))
+	if tracing() {
+		trace("glAttachShader", []interface{}{program, shader}, nil)
+	}
 }
 
 // bind a named buffer object
 func BindBuffer(target uint32, buffer uint32) {
 	C.glowBindBuffer(gpBindBuffer, (C.GLenum)(target), (C.GLuint)(buffer))
+	if tracing() {
+		trace("glBindBuffer", []interface{}{Enum(target), buffer}, nil)
+	}
 }
 
 // bind a framebuffer to a framebuffer target
 func BindFramebuffer(target uint32, framebuffer uint32) {
 	C.glowBindFramebuffer(gpBindFramebuffer, (C.GLenum)(target), (C.GLuint)(framebuffer))
+	if tracing() {
+		trace("glBindFramebuffer", []interface{}{Enum(target), framebuffer}, nil)
+	}
 }
 
 // bind a renderbuffer to a renderbuffer target
 func BindRenderbuffer(target uint32, renderbuffer uint32) {
 	C.glowBindRenderbuffer(gpBindRenderbuffer, (C.GLenum)(target), (C.GLuint)(renderbuffer))
+	if tracing() {
+		trace("glBindRenderbuffer", []interface{}{Enum(target), renderbuffer}, nil)
+	}
 }
 
 // bind a named texture to a texturing target
 func BindTexture(target uint32, texture uint32) {
 	C.glowBindTexture(gpBindTexture, (C.GLenum)(target), (C.GLuint)(texture))
+	if tracing() {
+		trace("glBindTexture", []interface{}{Enum(target), texture}, nil)
+	}
 }
 
 // set the blend color
 func BlendColor(red float32, green float32, blue float32, alpha float32) {
 	C.glowBlendColor(gpBlendColor, (C.GLfloat)(red), (C.GLfloat)(green), (C.GLfloat)(blue), (C.GLfloat)(alpha))
+	if tracing() {
+		trace("glBlendColor", []interface{}{red, green, blue, alpha}, nil)
+	}
 }
 
 // set the RGB blend equation and the alpha blend equation separately
 func BlendEquationSeparate(modeRGB uint32, modeAlpha uint32) {
 	C.glowBlendEquationSeparate(gpBlendEquationSeparate, (C.GLenum)(modeRGB), (C.GLenum)(modeAlpha))
+	if tracing() {
+		trace("glBlendEquationSeparate", []interface{}{Enum(modeRGB), Enum(modeAlpha)}, nil)
+	}
 }
 
 // specify pixel arithmetic for RGB and alpha components separately
 func BlendFuncSeparate(sfactorRGB uint32, dfactorRGB uint32, sfactorAlpha uint32, dfactorAlpha uint32) {
 	C.glowBlendFuncSeparate(gpBlendFuncSeparate, (C.GLenum)(sfactorRGB), (C.GLenum)(dfactorRGB), (C.GLenum)(sfactorAlpha), (C.GLenum)(dfactorAlpha))
+	if tracing() {
+		trace("glBlendFuncSeparate", []interface{}{Enum(sfactorRGB), Enum(dfactorRGB), Enum(sfactorAlpha), Enum(dfactorAlpha)}, nil)
+	}
 }
 
 // creates and initializes a buffer object's data     store
 func BufferData(target uint32, size int, data unsafe.Pointer, usage uint32) {
 	C.glowBufferData(gpBufferData, (C.GLenum)(target), (C.GLsizeiptr)(size), data, (C.GLenum)(usage))
+	if tracing() {
+		trace("glBufferData", []interface{}{Enum(target), size, data, Enum(usage)}, nil)
+	}
 }
 
 // check the completeness status of a framebuffer
 func CheckFramebufferStatus(target uint32) uint32 {
 	ret := C.glowCheckFramebufferStatus(gpCheckFramebufferStatus, (C.GLenum)(target))
+	if tracing() {
+		trace("glCheckFramebufferStatus", []interface{}{Enum(target)}, (uint32)(ret))
+	}
 	return (uint32)(ret)
 }
 
 // clear buffers to preset values
 func Clear(mask uint32) {
 	C.glowClear(gpClear, (C.GLbitfield)(mask))
+	if tracing() {
+		trace("glClear", []interface{}{mask}, nil)
+	}
 }
 
 // specify clear values for the color buffers
 func ClearColor(red float32, green float32, blue float32, alpha float32) {
 	C.glowClearColor(gpClearColor, (C.GLfloat)(red), (C.GLfloat)(green), (C.GLfloat)(blue), (C.GLfloat)(alpha))
+	if tracing() {
+		trace("glClearColor", []interface{}{red, green, blue, alpha}, nil)
+	}
 }
 func ClearDepthf(d float32) {
 	C.glowClearDepthf(gpClearDepthf, (C.GLfloat)(d))
+	if tracing() {
+		trace("glClearDepthf", []interface{}{d}, nil)
+	}
 }
 
 // specify the clear value for the stencil buffer
 func ClearStencil(s int32) {
 	C.glowClearStencil(gpClearStencil, (C.GLint)(s))
+	if tracing() {
+		trace("glClearStencil", []interface{}{s}, nil)
+	}
 }
 func ColorMask(red bool, green bool, blue bool, alpha bool) {
 	C.glowColorMask(gpColorMask, (C.GLboolean)(boolToInt(red)), (C.GLboolean)(boolToInt(green)), (C.GLboolean)(boolToInt(blue)), (C.GLboolean)(boolToInt(alpha)))
+	if tracing() {
+		trace("glColorMask", []interface{}{red, green, blue, alpha}, nil)
+	}
 }
 
 // Compiles a shader object
 func CompileShader(shader uint32) {
 	C.glowCompileShader(gpCompileShader, (C.GLuint)(shader))
+	if tracing() {
+		trace("glCompileShader", []interface{}{shader}, nil)
+	}
+}
+
+// Copy a two-dimensional texture subimage
+func CopyTexSubImage2D(target uint32, level int32, xoffset int32, yoffset int32, x int32, y int32, width int32, height int32) {
+	C.glowCopyTexSubImage2D(gpCopyTexSubImage2D, (C.GLenum)(target), (C.GLint)(level), (C.GLint)(xoffset), (C.GLint)(yoffset), (C.GLint)(x), (C.GLint)(y), (C.GLsizei)(width), (C.GLsizei)(height))
+	if tracing() {
+		trace("glCopyTexSubImage2D", []interface{}{Enum(target), level, xoffset, yoffset, x, y, width, height}, nil)
+	}
 }
 
 // Creates a program object
 func CreateProgram() uint32 {
 	ret := C.glowCreateProgram(gpCreateProgram)
+	if tracing() {
+		trace("glCreateProgram", []interface{}{}, (uint32)(ret))
+	}
 	return (uint32)(ret)
 }
 
 // Creates a shader object
 func CreateShader(xtype uint32) uint32 {
 	ret := C.glowCreateShader(gpCreateShader, (C.GLenum)(xtype))
+	if tracing() {
+		trace("glCreateShader", []interface{}{Enum(xtype)}, (uint32)(ret))
+	}
 	return (uint32)(ret)
 }
 
 // specify whether front- or back-facing facets can be culled
 func CullFace(mode uint32) {
 	C.glowCullFace(gpCullFace, (C.GLenum)(mode))
+	if tracing() {
+		trace("glCullFace", []interface{}{Enum(mode)}, nil)
+	}
 }
 
 // delete named buffer objects
 func DeleteBuffers(n int32, buffers *uint32) {
 	C.glowDeleteBuffers(gpDeleteBuffers, (C.GLsizei)(n), (*C.GLuint)(unsafe.Pointer(buffers)))
+	if tracing() {
+		trace("glDeleteBuffers", []interface{}{n, traceUint32s(buffers, n)}, nil)
+	}
 }
 
 // delete framebuffer objects
 func DeleteFramebuffers(n int32, framebuffers *uint32) {
 	C.glowDeleteFramebuffers(gpDeleteFramebuffers, (C.GLsizei)(n), (*C.GLuint)(unsafe.Pointer(framebuffers)))
+	if tracing() {
+		trace("glDeleteFramebuffers", []interface{}{n, traceUint32s(framebuffers, n)}, nil)
+	}
 }
 
 // Deletes a program object
 func DeleteProgram(program uint32) {
 	C.glowDeleteProgram(gpDeleteProgram, (C.GLuint)(program))
+	if tracing() {
+		trace("glDeleteProgram", []interface{}{program}, nil)
+	}
 }
 
 // delete renderbuffer objects
 func DeleteRenderbuffers(n int32, renderbuffers *uint32) {
 	C.glowDeleteRenderbuffers(gpDeleteRenderbuffers, (C.GLsizei)(n), (*C.GLuint)(unsafe.Pointer(renderbuffers)))
+	if tracing() {
+		trace("glDeleteRenderbuffers", []interface{}{n, traceUint32s(renderbuffers, n)}, nil)
+	}
 }
 
 // Deletes a shader object
 func DeleteShader(shader uint32) {
 	C.glowDeleteShader(gpDeleteShader, (C.GLuint)(shader))
+	if tracing() {
+		trace("glDeleteShader", []interface{}{shader}, nil)
+	}
 }
 
 // delete named textures
 func DeleteTextures(n int32, textures *uint32) {
 	C.glowDeleteTextures(gpDeleteTextures, (C.GLsizei)(n), (*C.GLuint)(unsafe.Pointer(textures)))
+	if tracing() {
+		trace("glDeleteTextures", []interface{}{n, traceUint32s(textures, n)}, nil)
+	}
 }
 
 // specify the value used for depth buffer comparisons
 func DepthFunc(xfunc uint32) {
 	C.glowDepthFunc(gpDepthFunc, (C.GLenum)(xfunc))
+	if tracing() {
+		trace("glDepthFunc", []interface{}{Enum(xfunc)}, nil)
+	}
 }
 
 // enable or disable writing into the depth buffer
 func DepthMask(flag bool) {
 	C.glowDepthMask(gpDepthMask, (C.GLboolean)(boolToInt(flag)))
+	if tracing() {
+		trace("glDepthMask", []interface{}{flag}, nil)
+	}
 }
 func Disable(cap uint32) {
 	C.glowDisable(gpDisable, (C.GLenum)(cap))
+	if tracing() {
+		trace("glDisable", []interface{}{Enum(cap)}, nil)
+	}
 }
 
 // Enable or disable a generic vertex attribute     array
 func DisableVertexAttribArray(index uint32) {
 	C.glowDisableVertexAttribArray(gpDisableVertexAttribArray, (C.GLuint)(index))
+	if tracing() {
+		trace("glDisableVertexAttribArray", []interface{}{index}, nil)
+	}
 }
 
 // render primitives from array data
 func DrawArrays(mode uint32, first int32, count int32) {
 	C.glowDrawArrays(gpDrawArrays, (C.GLenum)(mode), (C.GLint)(first), (C.GLsizei)(count))
+	if tracing() {
+		trace("glDrawArrays", []interface{}{Enum(mode), first, count}, nil)
+	}
 }
 
 // render primitives from array data
 func DrawElements(mode uint32, count int32, xtype uint32, indices unsafe.Pointer) {
 	C.glowDrawElements(gpDrawElements, (C.GLenum)(mode), (C.GLsizei)(count), (C.GLenum)(xtype), indices)
+	if tracing() {
+		trace("glDrawElements", []interface{}{Enum(mode), count, Enum(xtype), indices}, nil)
+	}
 }
 
 // enable or disable server-side GL capabilities
 func Enable(cap uint32) {
 	C.glowEnable(gpEnable, (C.GLenum)(cap))
+	if tracing() {
+		trace("glEnable", []interface{}{Enum(cap)}, nil)
+	}
 }
 
 // Enable or disable a generic vertex attribute     array
 func EnableVertexAttribArray(index uint32) {
 	C.glowEnableVertexAttribArray(gpEnableVertexAttribArray, (C.GLuint)(index))
+	if tracing() {
+		trace("glEnableVertexAttribArray", []interface{}{index}, nil)
+	}
 }
 
 // block until all GL execution is complete
 func Finish() {
 	C.glowFinish(gpFinish)
+	if tracing() {
+		trace("glFinish", []interface{}{}, nil)
+	}
 }
 
 // force execution of GL commands in finite time
 func Flush() {
 	C.glowFlush(gpFlush)
+	if tracing() {
+		trace("glFlush", []interface{}{}, nil)
+	}
 }
 
 // attach a renderbuffer as a logical buffer of a framebuffer object
 func FramebufferRenderbuffer(target uint32, attachment uint32, renderbuffertarget uint32, renderbuffer uint32) {
 	C.glowFramebufferRenderbuffer(gpFramebufferRenderbuffer, (C.GLenum)(target), (C.GLenum)(attachment), (C.GLenum)(renderbuffertarget), (C.GLuint)(renderbuffer))
+	if tracing() {
+		trace("glFramebufferRenderbuffer", []interface{}{Enum(target), Enum(attachment), Enum(renderbuffertarget), renderbuffer}, nil)
+	}
 }
 func FramebufferTexture2D(target uint32, attachment uint32, textarget uint32, texture uint32, level int32) {
 	C.glowFramebufferTexture2D(gpFramebufferTexture2D, (C.GLenum)(target), (C.GLenum)(attachment), (C.GLenum)(textarget), (C.GLuint)(texture), (C.GLint)(level))
+	if tracing() {
+		trace("glFramebufferTexture2D", []interface{}{Enum(target), Enum(attachment), Enum(textarget), texture, level}, nil)
+	}
 }
 
 // generate buffer object names
 func GenBuffers(n int32, buffers *uint32) {
 	C.glowGenBuffers(gpGenBuffers, (C.GLsizei)(n), (*C.GLuint)(unsafe.Pointer(buffers)))
+	if tracing() {
+		trace("glGenBuffers", []interface{}{n, traceUint32s(buffers, n)}, nil)
+	}
 }
 
 // generate framebuffer object names
 func GenFramebuffers(n int32, framebuffers *uint32) {
 	C.glowGenFramebuffers(gpGenFramebuffers, (C.GLsizei)(n), (*C.GLuint)(unsafe.Pointer(framebuffers)))
+	if tracing() {
+		trace("glGenFramebuffers", []interface{}{n, traceUint32s(framebuffers, n)}, nil)
+	}
 }
 
 // generate renderbuffer object names
 func GenRenderbuffers(n int32, renderbuffers *uint32) {
 	C.glowGenRenderbuffers(gpGenRenderbuffers, (C.GLsizei)(n), (*C.GLuint)(unsafe.Pointer(renderbuffers)))
+	if tracing() {
+		trace("glGenRenderbuffers", []interface{}{n, traceUint32s(renderbuffers, n)}, nil)
+	}
 }
 
 // generate texture names
 func GenTextures(n int32, textures *uint32) {
 	C.glowGenTextures(gpGenTextures, (C.GLsizei)(n), (*C.GLuint)(unsafe.Pointer(textures)))
+	if tracing() {
+		trace("glGenTextures", []interface{}{n, traceUint32s(textures, n)}, nil)
+	}
 }
 
 // generate mipmaps for a specified texture object
 func GenerateMipmap(target uint32) {
 	C.glowGenerateMipmap(gpGenerateMipmap, (C.GLenum)(target))
+	if tracing() {
+		trace("glGenerateMipmap", []interface{}{Enum(target)}, nil)
+	}
 }
 
 // Returns the location of an attribute variable
 func GetAttribLocation(program uint32, name *uint8) int32 {
 	ret := C.glowGetAttribLocation(gpGetAttribLocation, (C.GLuint)(program), (*C.GLchar)(unsafe.Pointer(name)))
+	if tracing() {
+		trace("glGetAttribLocation", []interface{}{program, traceStr(name)}, (int32)(ret))
+	}
 	return (int32)(ret)
 }
 func GetBooleanv(pname uint32, data *bool) {
 	C.glowGetBooleanv(gpGetBooleanv, (C.GLenum)(pname), (*C.GLboolean)(unsafe.Pointer(data)))
+	if tracing() {
+		trace("glGetBooleanv", []interface{}{Enum(pname), data}, nil)
+	}
 }
 
 // return error information
 func GetError() uint32 {
 	ret := C.glowGetError(gpGetError)
+	if tracing() {
+		trace("glGetError", []interface{}{}, (uint32)(ret))
+	}
 	return (uint32)(ret)
 }
 func GetFloatv(pname uint32, data *float32) {
 	C.glowGetFloatv(gpGetFloatv, (C.GLenum)(pname), (*C.GLfloat)(unsafe.Pointer(data)))
+	if tracing() {
+		trace("glGetFloatv", []interface{}{Enum(pname), data}, nil)
+	}
 }
 func GetIntegerv(pname uint32, data *int32) {
 	C.glowGetIntegerv(gpGetIntegerv, (C.GLenum)(pname), (*C.GLint)(unsafe.Pointer(data)))
+	if tracing() {
+		trace("glGetIntegerv", []interface{}{Enum(pname), data}, nil)
+	}
 }
 
 // Returns the information log for a program object
 func GetProgramInfoLog(program uint32, bufSize int32, length *int32, infoLog *uint8) {
 	C.glowGetProgramInfoLog(gpGetProgramInfoLog, (C.GLuint)(program), (C.GLsizei)(bufSize), (*C.GLsizei)(unsafe.Pointer(length)), (*C.GLchar)(unsafe.Pointer(infoLog)))
+	if tracing() {
+		trace("glGetProgramInfoLog", []interface{}{program, bufSize, length, infoLog}, nil)
+	}
 }
 
 // Returns a parameter from a program object
 func GetProgramiv(program uint32, pname uint32, params *int32) {
 	C.glowGetProgramiv(gpGetProgramiv, (C.GLuint)(program), (C.GLenum)(pname), (*C.GLint)(unsafe.Pointer(params)))
+	if tracing() {
+		trace("glGetProgramiv", []interface{}{program, Enum(pname), params}, nil)
+	}
 }
 
 // Returns the information log for a shader object
 func GetShaderInfoLog(shader uint32, bufSize int32, length *int32, infoLog *uint8) {
 	C.glowGetShaderInfoLog(gpGetShaderInfoLog, (C.GLuint)(shader), (C.GLsizei)(bufSize), (*C.GLsizei)(unsafe.Pointer(length)), (*C.GLchar)(unsafe.Pointer(infoLog)))
+	if tracing() {
+		trace("glGetShaderInfoLog", []interface{}{shader, bufSize, length, infoLog}, nil)
+	}
 }
 
 // Returns a parameter from a shader object
 func GetShaderiv(shader uint32, pname uint32, params *int32) {
 	C.glowGetShaderiv(gpGetShaderiv, (C.GLuint)(shader), (C.GLenum)(pname), (*C.GLint)(unsafe.Pointer(params)))
+	if tracing() {
+		trace("glGetShaderiv", []interface{}{shader, Enum(pname), params}, nil)
+	}
 }
 
 // return a string describing the current GL connection
 func GetString(name uint32) *uint8 {
 	ret := C.glowGetString(gpGetString, (C.GLenum)(name))
+	if tracing() {
+		trace("glGetString", []interface{}{Enum(name)}, (*uint8)(ret))
+	}
 	return (*uint8)(ret)
 }
 
 // Returns the location of a uniform variable
 func GetUniformLocation(program uint32, name *uint8) int32 {
 	ret := C.glowGetUniformLocation(gpGetUniformLocation, (C.GLuint)(program), (*C.GLchar)(unsafe.Pointer(name)))
+	if tracing() {
+		trace("glGetUniformLocation", []interface{}{program, traceStr(name)}, (int32)(ret))
+	}
 	return (int32)(ret)
 }
 
 // Links a program object
 func LinkProgram(program uint32) {
 	C.glowLinkProgram(gpLinkProgram, (C.GLuint)(program))
+	if tracing() {
+		trace("glLinkProgram", []interface{}{program}, nil)
+	}
 }
 
 // read a block of pixels from the frame buffer
 func ReadPixels(x int32, y int32, width int32, height int32, format uint32, xtype uint32, pixels unsafe.Pointer) {
 	C.glowReadPixels(gpReadPixels, (C.GLint)(x), (C.GLint)(y), (C.GLsizei)(width), (C.GLsizei)(height), (C.GLenum)(format), (C.GLenum)(xtype), pixels)
+	if tracing() {
+		trace("glReadPixels", []interface{}{x, y, width, height, Enum(format), Enum(xtype), pixels}, nil)
+	}
 }
 
 // define the scissor box
 func Scissor(x int32, y int32, width int32, height int32) {
 	C.glowScissor(gpScissor, (C.GLint)(x), (C.GLint)(y), (C.GLsizei)(width), (C.GLsizei)(height))
+	if tracing() {
+		trace("glScissor", []interface{}{x, y, width, height}, nil)
+	}
 }
 
 // Replaces the source code in a shader object
 func ShaderSource(shader uint32, count int32, xstring **uint8, length *int32) {
 	C.glowShaderSource(gpShaderSource, (C.GLuint)(shader), (C.GLsizei)(count), (**C.GLchar)(unsafe.Pointer(xstring)), (*C.GLint)(unsafe.Pointer(length)))
+	if tracing() {
+		trace("glShaderSource", []interface{}{shader, count, xstring, length}, nil)
+	}
 }
 
 // set front and/or back function and reference value for stencil testing
 func StencilFuncSeparate(face uint32, xfunc uint32, ref int32, mask uint32) {
 	C.glowStencilFuncSeparate(gpStencilFuncSeparate, (C.GLenum)(face), (C.GLenum)(xfunc), (C.GLint)(ref), (C.GLuint)(mask))
+	if tracing() {
+		trace("glStencilFuncSeparate", []interface{}{Enum(face), Enum(xfunc), ref, mask}, nil)
+	}
 }
 
 // control the front and/or back writing of individual bits in the stencil planes
 func StencilMaskSeparate(face uint32, mask uint32) {
 	C.glowStencilMaskSeparate(gpStencilMaskSeparate, (C.GLenum)(face), (C.GLuint)(mask))
+	if tracing() {
+		trace("glStencilMaskSeparate", []interface{}{Enum(face), mask}, nil)
+	}
 }
 
 // set front and/or back stencil test actions
 func StencilOpSeparate(face uint32, sfail uint32, dpfail uint32, dppass uint32) {
 	C.glowStencilOpSeparate(gpStencilOpSeparate, (C.GLenum)(face), (C.GLenum)(sfail), (C.GLenum)(dpfail), (C.GLenum)(dppass))
+	if tracing() {
+		trace("glStencilOpSeparate", []interface{}{Enum(face), Enum(sfail), Enum(dpfail), Enum(dppass)}, nil)
+	}
 }
 
 // specify a two-dimensional texture image
 func TexImage2D(target uint32, level int32, internalformat int32, width int32, height int32, border int32, format uint32, xtype uint32, pixels unsafe.Pointer) {
 	C.glowTexImage2D(gpTexImage2D, (C.GLenum)(target), (C.GLint)(level), (C.GLint)(internalformat), (C.GLsizei)(width), (C.GLsizei)(height), (C.GLint)(border), (C.GLenum)(format), (C.GLenum)(xtype), pixels)
+	if tracing() {
+		trace("glTexImage2D", []interface{}{Enum(target), level, internalformat, width, height, border, Enum(format), Enum(xtype), pixels}, nil)
+	}
 }
 func TexParameterfv(target uint32, pname uint32, params *float32) {
 	C.glowTexParameterfv(gpTexParameterfv, (C.GLenum)(target), (C.GLenum)(pname), (*C.GLfloat)(unsafe.Pointer(params)))
+	if tracing() {
+		trace("glTexParameterfv", []interface{}{Enum(target), Enum(pname), params}, nil)
+	}
 }
 func TexParameteri(target uint32, pname uint32, param int32) {
 	C.glowTexParameteri(gpTexParameteri, (C.GLenum)(target), (C.GLenum)(pname), (C.GLint)(param))
+	if tracing() {
+		trace("glTexParameteri", []interface{}{Enum(target), Enum(pname), param}, nil)
+	}
 }
 
 // Specify the value of a uniform variable for the current program object
 func Uniform1fv(location int32, count int32, value *float32) {
 	C.glowUniform1fv(gpUniform1fv, (C.GLint)(location), (C.GLsizei)(count), (*C.GLfloat)(unsafe.Pointer(value)))
+	if tracing() {
+		trace("glUniform1fv", []interface{}{location, count, value}, nil)
+	}
 }
 
 // Specify the value of a uniform variable for the current program object
 func Uniform1i(location int32, v0 int32) {
 	C.glowUniform1i(gpUniform1i, (C.GLint)(location), (C.GLint)(v0))
+	if tracing() {
+		trace("glUniform1i", []interface{}{location, v0}, nil)
+	}
 }
 
 // Specify the value of a uniform variable for the current program object
 func Uniform1iv(location int32, count int32, value *int32) {
 	C.glowUniform1iv(gpUniform1iv, (C.GLint)(location), (C.GLsizei)(count), (*C.GLint)(unsafe.Pointer(value)))
+	if tracing() {
+		trace("glUniform1iv", []interface{}{location, count, value}, nil)
+	}
 }
 
 // Specify the value of a uniform variable for the current program object
 func Uniform2fv(location int32, count int32, value *float32) {
 	C.glowUniform2fv(gpUniform2fv, (C.GLint)(location), (C.GLsizei)(count), (*C.GLfloat)(unsafe.Pointer(value)))
+	if tracing() {
+		trace("glUniform2fv", []interface{}{location, count, value}, nil)
+	}
 }
 
 // Specify the value of a uniform variable for the current program object
 func Uniform3fv(location int32, count int32, value *float32) {
 	C.glowUniform3fv(gpUniform3fv, (C.GLint)(location), (C.GLsizei)(count), (*C.GLfloat)(unsafe.Pointer(value)))
+	if tracing() {
+		trace("glUniform3fv", []interface{}{location, count, value}, nil)
+	}
 }
 
 // Specify the value of a uniform variable for the current program object
 func Uniform4fv(location int32, count int32, value *float32) {
 	C.glowUniform4fv(gpUniform4fv, (C.GLint)(location), (C.GLsizei)(count), (*C.GLfloat)(unsafe.Pointer(value)))
+	if tracing() {
+		trace("glUniform4fv", []interface{}{location, count, value}, nil)
+	}
 }
 
 // Specify the value of a uniform variable for the current program object
 func UniformMatrix4fv(location int32, count int32, transpose bool, value *float32) {
 	C.glowUniformMatrix4fv(gpUniformMatrix4fv, (C.GLint)(location), (C.GLsizei)(count), (C.GLboolean)(boolToInt(transpose)), (*C.GLfloat)(unsafe.Pointer(value)))
+	if tracing() {
+		trace("glUniformMatrix4fv", []interface{}{location, count, transpose, value}, nil)
+	}
 }
 
 // Installs a program object as part of current rendering state
 func UseProgram(program uint32) {
 	C.glowUseProgram(gpUseProgram, (C.GLuint)(program))
+	if tracing() {
+		trace("glUseProgram", []interface{}{program}, nil)
+	}
 }
 
 // define an array of generic vertex attribute data
 func VertexAttribPointer(index uint32, size int32, xtype uint32, normalized bool, stride int32, pointer unsafe.Pointer) {
 	C.glowVertexAttribPointer(gpVertexAttribPointer, (C.GLuint)(index), (C.GLint)(size), (C.GLenum)(xtype), (C.GLboolean)(boolToInt(normalized)), (C.GLsizei)(stride), pointer)
+	if tracing() {
+		trace("glVertexAttribPointer", []interface{}{index, size, Enum(xtype), normalized, stride, pointer}, nil)
+	}
 }
 
 // set the viewport
 func Viewport(x int32, y int32, width int32, height int32) {
 	C.glowViewport(gpViewport, (C.GLint)(x), (C.GLint)(y), (C.GLsizei)(width), (C.GLsizei)(height))
+	if tracing() {
+		trace("glViewport", []interface{}{x, y, width, height}, nil)
+	}
 }
 func Init() error {
 	return InitWithProcAddrFunc(getProcAddress)
@@ -1036,6 +1272,10 @@ func InitWithProcAddrFunc(getProcAddr func(name string) unsafe.Pointer) error {
 	if gpCompileShader == nil {
 		return errors.New("glCompileShader")
 	}
+	gpCopyTexSubImage2D = (C.GPCOPYTEXSUBIMAGE2D)(getProcAddr("glCopyTexSubImage2D"))
+	if gpCopyTexSubImage2D == nil {
+		return errors.New("glCopyTexSubImage2D")
+	}
 	gpCreateProgram = (C.GPCREATEPROGRAM)(getProcAddr("glCreateProgram"))
 	if gpCreateProgram == nil {
 		return errors.New("glCreateProgram")
@@ -0,0 +1,51 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pass
+
+import "azul3d.org/engine/gfx"
+
+// RTTDevice is a device which pretends to support render-to-texture, for
+// testing passes without a real device. The canvases it returns are the
+// embedded device itself.
+type RTTDevice struct {
+	gfx.Device
+
+	// DepthFormats are the depth formats reported as supported for
+	// render-to-texture, in addition to the RGBA color format.
+	DepthFormats []gfx.DSFormat
+
+	// Config is the configuration of the last render-to-texture canvas.
+	Config gfx.RTTConfig
+}
+
+// Info implements the gfx.Device interface.
+func (d *RTTDevice) Info() gfx.DeviceInfo {
+	i := d.Device.Info()
+	i.RTTFormats.ColorFormats = []gfx.TexFormat{gfx.RGBA}
+	i.RTTFormats.DepthFormats = d.DepthFormats
+	return i
+}
+
+// RenderToTexture implements the gfx.Device interface. Like a real device, it
+// returns nil if a depth texture is requested of an unsupported format, or of
+// a combined depth and stencil format (which cannot be used as a texture).
+func (d *RTTDevice) RenderToTexture(cfg gfx.RTTConfig) gfx.Canvas {
+	d.Config = cfg
+	if cfg.Depth != nil {
+		if cfg.DepthFormat.IsCombined() || !d.supports(cfg.DepthFormat) {
+			return nil
+		}
+	}
+	return d.Device
+}
+
+func (d *RTTDevice) supports(f gfx.DSFormat) bool {
+	for _, s := range d.DepthFormats {
+		if s == f {
+			return true
+		}
+	}
+	return false
+}
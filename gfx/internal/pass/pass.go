@@ -0,0 +1,55 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package pass implements the parts shared by the screen-space passes (e.g.
+// SSAO, TAA and OIT), which draw quads covering the whole canvas.
+package pass // import "azul3d.org/engine/gfx/internal/pass"
+
+import (
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/glsl"
+)
+
+// Vert is the GLSL source of a vertex shader drawing a quad covering the
+// whole canvas, whose vertices are given in normalized device coordinates. It
+// is written in GLSL 1.20 style, and passes the texture coordinates of the
+// canvas to the fragment shader as texCoord.
+const Vert = `
+attribute vec3 Vertex;
+varying vec2 texCoord;
+
+void main(void) {
+	texCoord = Vertex.xy * 0.5 + 0.5;
+	gl_Position = vec4(Vertex.xy, 0.0, 1.0);
+}
+`
+
+// Quad returns the mesh of a quad covering the canvas.
+func Quad() *gfx.Mesh {
+	m := gfx.NewMesh()
+	m.Vertices = []gfx.Vec3{
+		{X: -1, Y: -1},
+		{X: 1, Y: -1},
+		{X: 1, Y: 1},
+		{X: -1, Y: 1},
+	}
+	m.Indices = []uint32{0, 1, 2, 0, 2, 3}
+	return m
+}
+
+// Shader returns a new shader of the given fragment source (written in GLSL
+// 1.20 style), drawing a quad covering the canvas. The sources are
+// preprocessed using package glsl for the given target, with the given
+// defines (which may be nil).
+//
+// It panics if the sources cannot be preprocessed, which is only possible
+// with a bug in the pass's fragment source.
+func Shader(name string, t glsl.Target, frag string, defines map[string]string) *gfx.Shader {
+	pp := &glsl.Preprocessor{Target: t, Defines: defines}
+	s, err := pp.Shader(name, []byte(Vert), []byte(frag))
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
@@ -0,0 +1,299 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Command tracegen adds call tracing to the OpenGL bindings generated by
+// Glow, for frame capture (see gfx.FrameCapturer).
+//
+// It is run on the directory of a generated package after Glow:
+//
+//  go run ./tracegen ./gl/2.0/gl
+//
+// Each function of the package.go file is made to pass it's name, arguments
+// and result to the package's Tracer (if one is set), and a trace.go file is
+// written which declares the Tracer interface and names the package's enums.
+// Running it more than once on the same package is no-op.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	funcRe  = regexp.MustCompile(`^func ([A-Z]\w*)\((.*)\)\s*(.*?)\s*\{$`)
+	callRe  = regexp.MustCompile(`^\t(ret := )?C\.glow\w+\(gp\w+(, (.*))?\)$`)
+	constRe = regexp.MustCompile(`^\t([A-Z][A-Z0-9_]*)\s+= (0x[0-9A-Fa-f]+|[0-9]+)$`)
+	convRe  = regexp.MustCompile(`^\((\*?)C\.(\w+)\)`)
+)
+
+// param is a single parameter of a generated function.
+type param struct {
+	name, typ string
+
+	// The C type the parameter is converted to, and whether it is a pointer
+	// to it.
+	ctype string
+	ptr   bool
+}
+
+// splitArgs splits a comma-separated list at the top level of parentheses.
+func splitArgs(s string) []string {
+	var (
+		args  []string
+		depth int
+		start int
+	)
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if rest := strings.TrimSpace(s[start:]); rest != "" {
+		args = append(args, rest)
+	}
+	return args
+}
+
+// traceArg returns the expression which records parameter p of the named
+// function, given all of it's parameters.
+func traceArg(fn string, p param, params []param) string {
+	hasN := false
+	for _, x := range params {
+		if x.name == "n" {
+			hasN = true
+		}
+	}
+	switch {
+	case !p.ptr && p.ctype == "GLenum":
+		return fmt.Sprintf("Enum(%s)", p.name)
+	case p.ptr && p.typ == "*uint8" && p.ctype == "GLchar" && (!strings.HasPrefix(fn, "Get") || strings.HasSuffix(fn, "Location")):
+		return fmt.Sprintf("traceStr(%s)", p.name)
+	case p.ptr && p.typ == "*uint32" && hasN:
+		return fmt.Sprintf("traceUint32s(%s, n)", p.name)
+	case strings.HasPrefix(p.typ, "func") || p.typ == "DebugProc":
+		return `"<func>"`
+	}
+	return p.name
+}
+
+// patch adds tracing to the functions of the given package.go source.
+func patch(src []byte) ([]byte, error) {
+	lines := strings.Split(string(src), "\n")
+	var out []string
+	for i := 0; i < len(lines); i++ {
+		out = append(out, lines[i])
+		m := funcRe.FindStringSubmatch(lines[i])
+		if m == nil || m[1] == "Init" || m[1] == "InitWithProcAddrFunc" {
+			continue
+		}
+		name := m[1]
+		var params []param
+		for _, a := range splitArgs(m[2]) {
+			f := strings.Fields(a)
+			params = append(params, param{name: f[0], typ: f[1]})
+		}
+
+		// Copy the body up to and including the call.
+		var call []string
+		for i+1 < len(lines) && lines[i+1] != "}" {
+			i++
+			out = append(out, lines[i])
+			if call = callRe.FindStringSubmatch(lines[i]); call != nil {
+				break
+			}
+		}
+		if call == nil {
+			return nil, fmt.Errorf("%s: no call found", name)
+		}
+		if i+1 < len(lines) && strings.Contains(lines[i+1], "tracing()") {
+			continue // Already traced.
+		}
+		for j, a := range splitArgs(call[3]) {
+			if j >= len(params) {
+				break
+			}
+			if c := convRe.FindStringSubmatch(a); c != nil {
+				params[j].ptr = c[1] == "*"
+				params[j].ctype = c[2]
+			}
+		}
+		args := make([]string, len(params))
+		for j, p := range params {
+			args[j] = traceArg(name, p, params)
+		}
+
+		result := "nil"
+		if call[1] != "" {
+			// The result is the expression returned on the following line.
+			if i+1 >= len(lines) || !strings.HasPrefix(lines[i+1], "\treturn ") {
+				return nil, fmt.Errorf("%s: no return found", name)
+			}
+			result = strings.TrimPrefix(lines[i+1], "\treturn ")
+		}
+		out = append(out,
+			"\tif tracing() {",
+			fmt.Sprintf("\t\ttrace(%q, []interface{}{%s}, %s)", "gl"+name, strings.Join(args, ", "), result),
+			"\t}",
+		)
+	}
+	return []byte(strings.Join(out, "\n")), nil
+}
+
+// enums returns the names of the constants of the given package.go source,
+// by value. Names which share a value are joined with a slash.
+func enums(src []byte) map[uint64]string {
+	names := map[uint64][]string{}
+	for _, line := range strings.Split(string(src), "\n") {
+		m := constRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		var v uint64
+		fmt.Sscan(m[2], &v)
+		names[v] = append(names[v], "GL_"+m[1])
+	}
+	joined := make(map[uint64]string, len(names))
+	for v, n := range names {
+		sort.Strings(n)
+		joined[v] = strings.Join(n, "/")
+	}
+	return joined
+}
+
+const traceTmpl = `// Code generated by tracegen; DO NOT EDIT.
+
+package %s
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// Tracer is informed of each OpenGL call made through this package, after the
+// call returns. Calls may be made from multiple goroutines concurrently.
+type Tracer interface {
+	// Call is called with the name, arguments and result (or nil) of a call.
+	Call(name string, args []interface{}, result interface{})
+}
+
+var (
+	traceOn     int32
+	traceAccess sync.RWMutex
+	tracer      Tracer
+)
+
+// SetTracer sets the tracer informed of each call, or disables tracing if t
+// is nil. Tracing costs little more than an atomic load per call while
+// disabled.
+func SetTracer(t Tracer) {
+	traceAccess.Lock()
+	tracer = t
+	on := int32(0)
+	if t != nil {
+		on = 1
+	}
+	atomic.StoreInt32(&traceOn, on)
+	traceAccess.Unlock()
+}
+
+func tracing() bool {
+	return atomic.LoadInt32(&traceOn) != 0
+}
+
+func trace(name string, args []interface{}, result interface{}) {
+	traceAccess.RLock()
+	t := tracer
+	traceAccess.RUnlock()
+	if t != nil {
+		t.Call(name, args, result)
+	}
+}
+
+func traceStr(s *uint8) interface{} {
+	if s == nil {
+		return s
+	}
+	return GoStr(s)
+}
+
+func traceUint32s(p *uint32, n int32) interface{} {
+	if p == nil || n <= 0 {
+		return p
+	}
+	return append([]uint32(nil), (*[1 << 28]uint32)(unsafe.Pointer(p))[:n:n]...)
+}
+
+// Enum is an OpenGL enum argument of a traced call.
+type Enum uint32
+
+// String returns the name of the enum, e.g. "GL_TEXTURE_2D", or it's value
+// in hexadecimal if it has no name.
+func (e Enum) String() string {
+	if name, ok := enumNames[uint64(e)]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%%X", uint32(e))
+}
+
+var enumNames = map[uint64]string{
+%s}
+`
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("tracegen: ")
+	if len(os.Args) != 2 {
+		log.Fatal("usage: tracegen <package dir>")
+	}
+	dir := os.Args[1]
+	path := filepath.Join(dir, "package.go")
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	patched, err := patch(src)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, patched, 0644); err != nil {
+		log.Fatal(err)
+	}
+
+	names := enums(src)
+	values := make([]uint64, 0, len(names))
+	for v := range names {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	var table bytes.Buffer
+	for _, v := range values {
+		fmt.Fprintf(&table, "\t0x%X: %q,\n", v, names[v])
+	}
+	trace := fmt.Sprintf(traceTmpl, filepath.Base(dir), table.String())
+	formatted, err := format.Source([]byte(trace))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "trace.go"), formatted, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
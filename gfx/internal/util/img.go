@@ -54,3 +54,20 @@ func VerticalFlip(img *image.RGBA) {
 		copy(topRow, rowCpy)
 	}
 }
+
+// VerticalFlip64 is like VerticalFlip, but for 16-bit images.
+func VerticalFlip64(img *image.RGBA64) {
+	b := img.Bounds()
+	rowCpy := make([]uint8, b.Dx()*8)
+	for r := 0; r < (b.Dy() / 2); r++ {
+		topRow := img.Pix[img.PixOffset(0, r):img.PixOffset(b.Dx(), r)]
+
+		bottomR := b.Dy() - r - 1
+		bottomRow := img.Pix[img.PixOffset(0, bottomR):img.PixOffset(b.Dx(), bottomR)]
+
+		// Swap the rows.
+		copy(rowCpy, bottomRow)
+		copy(bottomRow, topRow)
+		copy(topRow, rowCpy)
+	}
+}
@@ -28,7 +28,9 @@ var (
 // It will return draw=false, err == nil in the following cases:
 //
 //  rect.Empty() == true
-//  o.Shader != nil && len(o.Shader.Error) > 0
+//  shader != nil && len(shader.Error) > 0
+//
+// Where shader is that of o.DrawState().
 //
 // It may return the following errors:
 //
@@ -45,8 +47,9 @@ var (
 // object has a chance to calculate a bounding box before it's data slices are
 // set to nil.
 //
-// Ask the given device to load each shader, mesh, and texture that the object
-// has associated with it and waits for loading to complete before returning.
+// Ask the given device to load each pipeline, shader, mesh, and texture that
+// the object has associated with it and waits for loading to complete before
+// returning (except for streaming textures, whose loading is only started).
 func PreDraw(dev gfx.Device, rect image.Rectangle, o *gfx.Object, c gfx.Camera) (draw bool, err error) {
 	// Draw calls with empty rectangles are effectively no-op.
 	if rect.Empty() {
@@ -57,13 +60,14 @@ func PreDraw(dev gfx.Device, rect image.Rectangle, o *gfx.Object, c gfx.Camera)
 	o.Bounds()
 
 	// Test for basic cases of object invalidity.
-	if o.State == nil {
+	shader, state := o.DrawState()
+	if state == nil {
 		return false, ErrNilState
 	}
-	if o.Shader == nil {
+	if shader == nil {
 		return false, ErrNilShader
 	}
-	if len(o.Shader.Error) > 0 {
+	if len(shader.Error) > 0 {
 		return false, ErrShaderError
 	}
 	if len(o.Meshes) == 0 {
@@ -75,9 +79,17 @@ func PreDraw(dev gfx.Device, rect image.Rectangle, o *gfx.Object, c gfx.Camera)
 		meshLoad    chan *gfx.Mesh
 		textureLoad chan *gfx.Texture
 	)
-	if !o.Shader.Loaded {
+	if p := o.Pipeline; p != nil && !p.Loaded {
+		// Loading the pipeline loads it's shader, too.
+		if pl, ok := dev.(gfx.PipelineLoader); ok {
+			pipelineLoad := make(chan *gfx.Pipeline, 1)
+			pl.LoadPipeline(p, pipelineLoad)
+			<-pipelineLoad
+		}
+	}
+	if !shader.Loaded {
 		shaderLoad := make(chan *gfx.Shader, 1)
-		dev.LoadShader(o.Shader, shaderLoad)
+		dev.LoadShader(shader, shaderLoad)
 		<-shaderLoad
 	}
 	for _, m := range o.Meshes {
@@ -97,9 +109,14 @@ func PreDraw(dev gfx.Device, rect image.Rectangle, o *gfx.Object, c gfx.Camera)
 		if t.Loaded {
 			continue
 		}
-		if t.Source == nil {
+		if t.Source == nil && t.Mipmaps == nil {
 			return false, ErrNilSource
 		}
+		if t.Streaming {
+			// Streaming textures load in the background, don't wait for them.
+			dev.LoadTexture(t, nil)
+			continue
+		}
 		if textureLoad == nil {
 			textureLoad = make(chan *gfx.Texture, 1)
 		}
@@ -108,7 +125,7 @@ func PreDraw(dev gfx.Device, rect image.Rectangle, o *gfx.Object, c gfx.Camera)
 	}
 
 	// Check the now-loaded shader for errors.
-	if len(o.Shader.Error) > 0 {
+	if len(shader.Error) > 0 {
 		return false, ErrShaderError
 	}
 	return true, nil
@@ -114,6 +114,20 @@ func (s *Swapper) LoadShader(sh *gfx.Shader, done chan *gfx.Shader) {
 	s.d.LoadShader(sh, done)
 }
 
+// LoadPipeline loads a pipeline using the current graphics device, if it
+// implements the gfx.PipelineLoader interface. Otherwise the pipeline is sent
+// over the done channel immediately.
+func (s *Swapper) LoadPipeline(p *gfx.Pipeline, done chan *gfx.Pipeline) {
+	if l, ok := s.d.(gfx.PipelineLoader); ok {
+		l.LoadPipeline(p, done)
+		return
+	}
+	select {
+	case done <- p:
+	default:
+	}
+}
+
 // RenderToTexture returns a new RTT canvas using the current graphics device.
 //
 // TODO(slimsag): Do we require a swappable canvas, here, too?
@@ -121,6 +135,163 @@ func (s *Swapper) RenderToTexture(cfg gfx.RTTConfig) gfx.Canvas {
 	return s.d.RenderToTexture(cfg)
 }
 
+// BeginPass begins a profiling pass on the current graphics device, if it
+// implements the gfx.Profiler interface.
+func (s *Swapper) BeginPass(name string) {
+	if p, ok := s.d.(gfx.Profiler); ok {
+		p.BeginPass(name)
+	}
+}
+
+// EndPass ends a profiling pass on the current graphics device, if it
+// implements the gfx.Profiler interface.
+func (s *Swapper) EndPass() {
+	if p, ok := s.d.(gfx.Profiler); ok {
+		p.EndPass()
+	}
+}
+
+// FrameStats returns the frame statistics of the current graphics device, if
+// it implements the gfx.Profiler interface.
+func (s *Swapper) FrameStats() gfx.FrameStats {
+	if p, ok := s.d.(gfx.Profiler); ok {
+		return p.FrameStats()
+	}
+	return gfx.FrameStats{}
+}
+
+// DownloadAsync downloads from the current graphics device asynchronously, if
+// it implements the gfx.AsyncDownloader interface. Otherwise it falls back to
+// a regular (synchronous) download.
+func (s *Swapper) DownloadAsync(r image.Rectangle, complete chan image.Image) {
+	if a, ok := s.d.(gfx.AsyncDownloader); ok {
+		a.DownloadAsync(r, complete)
+		return
+	}
+	s.d.Download(r, complete)
+}
+
+// SetSRGB sets the sRGB framebuffer status of the current graphics device, if
+// it implements the gfx.SRGBCanvas interface.
+func (s *Swapper) SetSRGB(enabled bool) {
+	if c, ok := s.d.(gfx.SRGBCanvas); ok {
+		c.SetSRGB(enabled)
+	}
+}
+
+// SRGB gets the sRGB framebuffer status of the current graphics device, if it
+// implements the gfx.SRGBCanvas interface.
+func (s *Swapper) SRGB() bool {
+	if c, ok := s.d.(gfx.SRGBCanvas); ok {
+		return c.SRGB()
+	}
+	return false
+}
+
+// MemoryStats returns the memory statistics of the current graphics device, if
+// it implements the gfx.MemoryReporter interface.
+func (s *Swapper) MemoryStats() gfx.MemoryStats {
+	if m, ok := s.d.(gfx.MemoryReporter); ok {
+		return m.MemoryStats()
+	}
+	return gfx.MemoryStats{}
+}
+
+// TextureSize returns the size of the texture on the current graphics device,
+// if it implements the gfx.MemoryReporter interface.
+func (s *Swapper) TextureSize(t *gfx.Texture) int64 {
+	if m, ok := s.d.(gfx.MemoryReporter); ok {
+		return m.TextureSize(t)
+	}
+	return 0
+}
+
+// MeshSize returns the size of the mesh on the current graphics device, if it
+// implements the gfx.MemoryReporter interface.
+func (s *Swapper) MeshSize(mesh *gfx.Mesh) int64 {
+	if m, ok := s.d.(gfx.MemoryReporter); ok {
+		return m.MeshSize(mesh)
+	}
+	return 0
+}
+
+// SetMemoryBudget sets the memory budget of the current graphics device, if it
+// implements the gfx.MemoryReporter interface.
+func (s *Swapper) SetMemoryBudget(budget int64, exceeded chan gfx.MemoryStats) {
+	if m, ok := s.d.(gfx.MemoryReporter); ok {
+		m.SetMemoryBudget(budget, exceeded)
+	}
+}
+
+// SetResidentMip sets the resident mipmap level of the texture on the current
+// graphics device, if it implements the gfx.MipStreamer interface.
+func (s *Swapper) SetResidentMip(t *gfx.Texture, level int) {
+	if m, ok := s.d.(gfx.MipStreamer); ok {
+		m.SetResidentMip(t, level)
+	}
+}
+
+// ResidentMip returns the resident mipmap level of the texture on the current
+// graphics device, if it implements the gfx.MipStreamer interface.
+func (s *Swapper) ResidentMip(t *gfx.Texture) int {
+	if m, ok := s.d.(gfx.MipStreamer); ok {
+		return m.ResidentMip(t)
+	}
+	return 0
+}
+
+// PushDebugGroup pushes a debug group on the current graphics device, if it
+// implements the gfx.DebugGrouper interface.
+func (s *Swapper) PushDebugGroup(name string) {
+	if g, ok := s.d.(gfx.DebugGrouper); ok {
+		g.PushDebugGroup(name)
+	}
+}
+
+// PopDebugGroup pops a debug group on the current graphics device, if it
+// implements the gfx.DebugGrouper interface.
+func (s *Swapper) PopDebugGroup() {
+	if g, ok := s.d.(gfx.DebugGrouper); ok {
+		g.PopDebugGroup()
+	}
+}
+
+// CaptureFrame requests a capture of the next frame from the current graphics
+// device, if it implements the gfx.FrameCapturer interface. Otherwise the
+// returned channel is closed.
+func (s *Swapper) CaptureFrame() <-chan *gfx.FrameCapture {
+	if c, ok := s.d.(gfx.FrameCapturer); ok {
+		return c.CaptureFrame()
+	}
+	ch := make(chan *gfx.FrameCapture)
+	close(ch)
+	return ch
+}
+
+// Submit submits the command list to the current graphics device, using it's
+// Submit method if it implements the gfx.CommandSubmitter interface.
+func (s *Swapper) Submit(l *gfx.CommandList) {
+	gfx.Submit(s.d, l)
+}
+
+// RunNative runs the function under the presence of the native context of the
+// current graphics device, if it implements the gfx.NativeInterop interface.
+// Otherwise the function is not run.
+func (s *Swapper) RunNative(f func()) {
+	if n, ok := s.d.(gfx.NativeInterop); ok {
+		n.RunNative(f)
+	}
+}
+
+// CopyToNative copies the texture into the foreign native texture using the
+// current graphics device, if it implements the gfx.NativeInterop interface.
+func (s *Swapper) CopyToNative(src *gfx.Texture, dst uint32) bool {
+	if n, ok := s.d.(gfx.NativeInterop); ok {
+		return n.CopyToNative(src, dst)
+	}
+	return false
+}
+
 // NewSwapper returns a new graphics device swapper, wrapping the given device.
 func NewSwapper(d gfx.Device) *Swapper {
 	s := &Swapper{
@@ -0,0 +1,279 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package light
+
+import (
+	"image"
+	"math"
+	"sort"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/shaders"
+	"azul3d.org/engine/lmath"
+)
+
+var (
+	// Get an matrix which will translate our matrix from ZUpRight to YUpRight
+	zUpRightToYUpRight = lmath.CoordSysZUpRight.ConvertMat4(lmath.CoordSysYUpRight)
+)
+
+// The number of clusters, and the number of texels of the cluster texture
+// holding the lights of each cluster.
+const (
+	numClusters    = shaders.ClustersX * shaders.ClustersY * shaders.ClustersZ
+	clusterTexels  = shaders.MaxClusterLights / 4
+	clustersWidth  = shaders.ClustersX * clusterTexels
+	clustersHeight = shaders.ClustersY * shaders.ClustersZ
+)
+
+// Clusters assigns lights to the clusters of a camera's view frustum.
+//
+// The view frustum is sliced along it's depth exponentially, from the near
+// plane to the far plane, such that clusters are roughly cube-shaped. Only
+// perspective projections are supported.
+//
+// Clusters are not safe for access from multiple goroutines concurrently.
+type Clusters struct {
+	// Lights are the lights of the scene. If there are more than
+	// shaders.MaxLights within the view frustum, the ones nearest to the
+	// camera are used.
+	Lights []*Light
+
+	// CookieTiles is the number of square cookies in the cookie texture (the
+	// third texture of shaders.Clustered), see Light.Cookie.
+	CookieTiles int
+
+	// Texture holds the indices of the lights in each cluster. It must be
+	// the second texture of objects drawn with shaders.Clustered.
+	Texture *gfx.Texture
+
+	// Dropped is the number of lights which were left out of a cluster
+	// during the last update, because it already held
+	// shaders.MaxClusterLights of them.
+	Dropped int
+
+	// The lights of the last update, and their view space bounds.
+	visible []visibleLight
+
+	// The number of lights in each cluster, and the view space bounds of
+	// each cluster.
+	counts []int
+	bounds []lmath.Rect3
+
+	// The shader inputs.
+	lights []gfx.Vec4
+	params gfx.Vec4
+}
+
+// visibleLight is a light within the view frustum.
+type visibleLight struct {
+	*Light
+	center lmath.Vec3
+	radius float64
+}
+
+// Len returns the number of lights assigned to clusters by the last update.
+func (c *Clusters) Len() int {
+	return len(c.visible)
+}
+
+// Cluster returns the lights assigned to the cluster at the given coordinates
+// by the last update, where (0, 0, 0) is the bottom-left cluster nearest to
+// the camera.
+func (c *Clusters) Cluster(x, y, z int) []*Light {
+	var lights []*Light
+	img := c.Texture.Source.(*image.RGBA)
+	row := z*shaders.ClustersY + y
+	for i := 0; i < c.counts[row*shaders.ClustersX+x]; i++ {
+		id := img.Pix[img.PixOffset(x*clusterTexels+i/4, row)+i%4]
+		lights = append(lights, c.visible[id-1].Light)
+	}
+	return lights
+}
+
+// frustum describes a perspective projection.
+type frustum struct {
+	near, far float64
+
+	// The view space X and Y coordinates of a point at a distance of one
+	// along the view axis are (ndc + offset) * scale.
+	scaleX, scaleY, offsetX, offsetY float64
+}
+
+// newFrustum returns the frustum of the given projection matrix.
+func newFrustum(p lmath.Mat4) frustum {
+	a, b := p[2][2], p[3][2]
+	return frustum{
+		near:    b / (a - 1),
+		far:     b / (a + 1),
+		scaleX:  1 / p[0][0],
+		scaleY:  1 / p[1][1],
+		offsetX: p[2][0],
+		offsetY: p[2][1],
+	}
+}
+
+// slice returns the depth (distance along the view axis) at which the slice
+// of the given index begins.
+func (f frustum) slice(i int) float64 {
+	return f.near * math.Pow(f.far/f.near, float64(i)/shaders.ClustersZ)
+}
+
+// sliceOf returns the index of the slice at the given depth, clamped to the
+// frustum.
+func (f frustum) sliceOf(depth float64) int {
+	if depth <= f.near {
+		return 0
+	}
+	i := int(math.Log(depth/f.near) / math.Log(f.far/f.near) * shaders.ClustersZ)
+	if i >= shaders.ClustersZ {
+		i = shaders.ClustersZ - 1
+	}
+	return i
+}
+
+// bounds returns the view space bounds of the cluster at the given
+// coordinates.
+func (f frustum) bounds(x, y, z int) lmath.Rect3 {
+	near, far := f.slice(z), f.slice(z+1)
+	x0 := -1 + 2*float64(x)/shaders.ClustersX
+	x1 := -1 + 2*float64(x+1)/shaders.ClustersX
+	y0 := -1 + 2*float64(y)/shaders.ClustersY
+	y1 := -1 + 2*float64(y+1)/shaders.ClustersY
+	var b lmath.Rect3
+	for i, d := range []float64{near, far} {
+		r := lmath.Rect3{
+			Min: lmath.Vec3{X: d * (x0 + f.offsetX) * f.scaleX, Y: d * (y0 + f.offsetY) * f.scaleY, Z: -far},
+			Max: lmath.Vec3{X: d * (x1 + f.offsetX) * f.scaleX, Y: d * (y1 + f.offsetY) * f.scaleY, Z: -near},
+		}
+		if i == 0 {
+			b = r
+		} else {
+			b = b.Union(r)
+		}
+	}
+	return b
+}
+
+// Update assigns the lights to the clusters of the given camera's view
+// frustum, drawing to a canvas rectangle of the given bounds (which must
+// start at the origin of the canvas), and updates the cluster texture.
+func (c *Clusters) Update(cam gfx.Camera, b image.Rectangle) {
+	camInverse, _ := cam.Transform().Mat4().Inverse()
+	view := camInverse.Mul(zUpRightToYUpRight)
+	f := newFrustum(cam.Projection().Mat4())
+
+	// Find the lights within the depth range of the frustum, keeping the
+	// nearest ones if there are too many.
+	c.visible = c.visible[:0]
+	for _, l := range c.Lights {
+		center, radius := l.bounds(view)
+		if depth := -center.Z; depth+radius < f.near || depth-radius > f.far {
+			continue
+		}
+		c.visible = append(c.visible, visibleLight{l, center, radius})
+	}
+	if len(c.visible) > shaders.MaxLights {
+		sort.Sort(byDistance(c.visible))
+		c.visible = c.visible[:shaders.MaxLights]
+	}
+
+	// Assign each light to the clusters it's bounding sphere touches.
+	for z := 0; z < shaders.ClustersZ; z++ {
+		for y := 0; y < shaders.ClustersY; y++ {
+			for x := 0; x < shaders.ClustersX; x++ {
+				i := (z*shaders.ClustersY+y)*shaders.ClustersX + x
+				c.bounds[i] = f.bounds(x, y, z)
+				c.counts[i] = 0
+			}
+		}
+	}
+	img := c.Texture.Source.(*image.RGBA)
+	for i := range img.Pix {
+		img.Pix[i] = 0
+	}
+	c.Dropped = 0
+	for i, l := range c.visible {
+		z0, z1 := 0, shaders.ClustersZ-1
+		if !math.IsInf(l.radius, 1) {
+			z0, z1 = f.sliceOf(-l.center.Z-l.radius), f.sliceOf(-l.center.Z+l.radius)
+		}
+		for z := z0; z <= z1; z++ {
+			for y := 0; y < shaders.ClustersY; y++ {
+				for x := 0; x < shaders.ClustersX; x++ {
+					cluster := (z*shaders.ClustersY+y)*shaders.ClustersX + x
+					if !math.IsInf(l.radius, 1) && c.bounds[cluster].SqDistToPoint(l.center) > l.radius*l.radius {
+						continue
+					}
+					n := c.counts[cluster]
+					if n == shaders.MaxClusterLights {
+						c.Dropped++
+						continue
+					}
+					c.counts[cluster]++
+					o := img.PixOffset(x*clusterTexels+n/4, z*shaders.ClustersY+y)
+					img.Pix[o+n%4] = uint8(i + 1)
+				}
+			}
+		}
+	}
+
+	// The device reloads the texture the next time it is drawn.
+	if c.Texture.NativeTexture != nil {
+		c.Texture.NativeTexture.Destroy()
+		c.Texture.NativeTexture = nil
+	}
+	c.Texture.Loaded = false
+
+	for i := range c.lights {
+		c.lights[i] = gfx.Vec4{}
+	}
+	for i, l := range c.visible {
+		l.encode(view, c.lights[i*4:])
+	}
+	c.params = gfx.Vec4{
+		X: float32(shaders.ClustersX / float64(b.Dx())),
+		Y: float32(shaders.ClustersY / float64(b.Dy())),
+		Z: float32(shaders.ClustersZ / math.Log(f.far/f.near)),
+		W: float32(f.near),
+	}
+}
+
+// Apply sets the inputs of the given shaders.Clustered shader to the lights
+// of the last update.
+func (c *Clusters) Apply(s *gfx.Shader) {
+	s.Inputs["Lights"] = c.lights
+	s.Inputs["ClusterParams"] = c.params
+	s.Inputs["CookieTiles"] = float32(c.CookieTiles)
+}
+
+// byDistance sorts lights by the distance from the camera to their bounds.
+type byDistance []visibleLight
+
+func (b byDistance) Len() int      { return len(b) }
+func (b byDistance) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b byDistance) Less(i, j int) bool {
+	return b[i].center.Length()-b[i].radius < b[j].center.Length()-b[j].radius
+}
+
+// New returns a new set of clusters, without any lights.
+func New() *Clusters {
+	t := gfx.NewTexture()
+	t.Source = image.NewRGBA(image.Rect(0, 0, clustersWidth, clustersHeight))
+	t.Bounds = t.Source.Bounds()
+	t.KeepDataOnLoad = true
+	t.Dynamic = true
+	t.MinFilter = gfx.Nearest
+	t.MagFilter = gfx.Nearest
+	t.WrapU = gfx.Clamp
+	t.WrapV = gfx.Clamp
+	return &Clusters{
+		CookieTiles: 1,
+		Texture:     t,
+		counts:      make([]int, numClusters),
+		bounds:      make([]lmath.Rect3, numClusters),
+		lights:      make([]gfx.Vec4, shaders.MaxLights*4),
+	}
+}
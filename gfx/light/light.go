@@ -0,0 +1,186 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package light implements point, spot and directional lights, culled per
+// cluster of the view frustum such that forward rendering can handle hundreds
+// of them.
+//
+// The view frustum is split into a grid of clusters: tiles across the screen,
+// each sliced exponentially along the depth. Each frame the lights are
+// assigned to the clusters they touch (see Clusters.Update), and the
+// shaders.Clustered shader looks up the cluster of each fragment to shade it
+// by only the lights that may reach it:
+//
+//  c := light.New()
+//  obj.Shader = shaders.New(shaders.Clustered, glsl.GL2)
+//  obj.Textures = []*gfx.Texture{albedo, c.Texture, cookies}
+//
+//  c.Lights = append(c.Lights, &light.Light{
+//      Kind:     light.Point,
+//      Position: lmath.Vec3{0, 10, 2},
+//      Color:    gfx.Color{4, 3, 2, 1},
+//      Range:    15,
+//  })
+//  for {
+//      c.Update(cam, d.Bounds())
+//      c.Apply(obj.Shader)
+//      d.Draw(d.Bounds(), obj, cam)
+//      ...
+//  }
+//
+// The cluster grid and the number of lights are limited by the constants of
+// the shader library (shaders.MaxLights, etc). Lights are stored in the
+// uniforms of the shader, and the indices of the lights in each cluster in an
+// RGBA texture, such that no floating-point texture support is required.
+package light // import "azul3d.org/engine/gfx/light"
+
+import (
+	"fmt"
+	"math"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/lmath"
+)
+
+// Kind is a kind of light.
+type Kind int
+
+const (
+	// Point is a light emitting in all directions from a point.
+	Point Kind = iota
+
+	// Spot is a light emitting in a cone from a point.
+	Spot
+
+	// Directional is an infinitely distant light, such as the sun, which
+	// emits in a single direction everywhere.
+	Directional
+)
+
+// String returns the name of the kind, e.g. "Spot".
+func (k Kind) String() string {
+	switch k {
+	case Point:
+		return "Point"
+	case Spot:
+		return "Spot"
+	case Directional:
+		return "Directional"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Light is a single light.
+type Light struct {
+	// The kind of light.
+	Kind Kind
+
+	// The world space position of point and spot lights.
+	Position lmath.Vec3
+
+	// The world space direction that spot and directional lights emit in, it
+	// need not be normalized.
+	Direction lmath.Vec3
+
+	// The color of the light in linear space, whose components may exceed
+	// one for bright lights.
+	Color gfx.Color
+
+	// Range is the distance from point and spot lights at which their light
+	// reaches zero. Within it, the light falls off with the inverse square of
+	// the distance, windowed smoothly to zero at the range.
+	Range float64
+
+	// The angles (in radians, from the center of the cone to it's edge) of
+	// the cone of spot lights: the light is at full strength within the inner
+	// angle, falling off to zero at the outer angle.
+	InnerAngle, OuterAngle float64
+
+	// Cookie, if non-zero, is the number of the texture projected by a spot
+	// light (e.g. the shape of a window, or a flashlight's lens) in the
+	// cookie texture, counting from one. The cookie texture holds a row of
+	// square cookies from left to right, see Clusters.CookieTiles.
+	Cookie int
+}
+
+// bounds returns the view space bounding sphere of the light, given the view
+// matrix. The radius of directional lights is infinite.
+func (l *Light) bounds(view lmath.Mat4) (center lmath.Vec3, radius float64) {
+	if l.Kind == Directional {
+		return lmath.Vec3{}, math.Inf(1)
+	}
+	return l.Position.TransformMat4(view), l.Range
+}
+
+// cone returns the scale and offset of the cone of the light: spot lights are
+// attenuated by clamp(cos*scale + offset), which is one within the inner angle
+// and zero beyond the outer one. Other lights are not attenuated.
+func (l *Light) cone() (scale, offset float64) {
+	if l.Kind != Spot {
+		return 0, 1
+	}
+	cosInner, cosOuter := math.Cos(l.InnerAngle), math.Cos(l.OuterAngle)
+	scale = 1 / math.Max(cosInner-cosOuter, 1e-4)
+	return scale, -cosOuter * scale
+}
+
+// Incident returns the normalized world space direction from the given point
+// towards the light, the distance to the light (infinite for directional
+// lights) and the attenuation of the light at the point, as computed by the
+// shaders.Clustered shader (without cookies).
+func (l *Light) Incident(p lmath.Vec3) (dir lmath.Vec3, dist, att float64) {
+	if l.Kind == Directional {
+		dir, _ = l.Direction.MulScalar(-1).Normalized()
+		return dir, math.Inf(1), 1
+	}
+	toLight := l.Position.Sub(p)
+	dist = toLight.Length()
+	if dist == 0 || dist >= l.Range {
+		return lmath.Vec3{}, dist, 0
+	}
+	dir = toLight.DivScalar(dist)
+
+	// Inverse-square falloff, windowed smoothly to zero at the range.
+	w := 1 - math.Pow(dist/l.Range, 4)
+	att = w * w / (dist*dist + 1)
+
+	// The cone of spot lights.
+	spotDir, _ := l.Direction.Normalized()
+	scale, offset := l.cone()
+	att *= lmath.Clamp(-dir.Dot(spotDir)*scale+offset, 0, 1)
+	return dir, dist, att
+}
+
+// encode encodes the light into the four vectors of the shader's Lights
+// array, in view space.
+func (l *Light) encode(view lmath.Mat4, dst []gfx.Vec4) {
+	dir, _ := l.Direction.TransformVecMat4(view).Normalized()
+	color := l.Color
+
+	scale, offset := l.cone()
+	var right lmath.Vec3
+	cookie := 0
+	if l.Kind == Spot {
+		if l.Cookie > 0 {
+			// The horizontal axis of the cookie, level with the ground.
+			up := lmath.Vec3{Z: 1}.TransformVecMat4(view)
+			var ok bool
+			right, ok = dir.Cross(up).Normalized()
+			if !ok {
+				right = lmath.Vec3{X: 1}
+			}
+			cookie = l.Cookie
+		}
+	}
+
+	pos := l.Position.TransformMat4(view)
+	rng := l.Range
+	if l.Kind == Directional {
+		pos, rng = lmath.Vec3{}, 0
+	}
+	dst[0] = gfx.Vec4{X: float32(pos.X), Y: float32(pos.Y), Z: float32(pos.Z), W: float32(rng)}
+	dst[1] = gfx.Vec4{X: color.R, Y: color.G, Z: color.B, W: float32(scale)}
+	dst[2] = gfx.Vec4{X: float32(dir.X), Y: float32(dir.Y), Z: float32(dir.Z), W: float32(offset)}
+	dst[3] = gfx.Vec4{X: float32(right.X), Y: float32(right.Y), Z: float32(right.Z), W: float32(cookie)}
+}
@@ -0,0 +1,145 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package light
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/portal"
+	"azul3d.org/engine/gfx/shaders"
+	"azul3d.org/engine/lmath"
+)
+
+// camera returns a camera at the origin looking along +Y, with near and far
+// planes at 1 and 100.
+func camera() gfx.Camera {
+	return &portal.Camera{
+		T: gfx.NewTransform(),
+		P: gfx.ConvertMat4(lmath.Mat4Perspective(75, 16.0/9.0, 1, 100)),
+	}
+}
+
+// contains tells if l is in the slice.
+func contains(lights []*Light, l *Light) bool {
+	for _, v := range lights {
+		if v == l {
+			return true
+		}
+	}
+	return false
+}
+
+func TestClusters(t *testing.T) {
+	point := &Light{Kind: Point, Position: lmath.Vec3{Y: 10}, Color: gfx.Color{R: 1, G: 1, B: 1, A: 1}, Range: 2}
+	behind := &Light{Kind: Point, Position: lmath.Vec3{Y: -10}, Range: 2}
+	sun := &Light{Kind: Directional, Direction: lmath.Vec3{Z: -1}}
+	c := New()
+	c.Lights = []*Light{point, behind, sun}
+	c.Update(camera(), image.Rect(0, 0, 1600, 900))
+	if c.Len() != 2 {
+		t.Fatalf("got %d visible lights, want 2", c.Len())
+	}
+
+	// The point light lies in the middle of the screen, at a depth of 10.
+	slice := int(math.Log(10) / math.Log(100) * shaders.ClustersZ)
+	for _, tc := range []struct {
+		x, y, z int
+		want    bool
+	}{
+		{shaders.ClustersX / 2, shaders.ClustersY / 2, slice, true},
+		{shaders.ClustersX/2 - 1, shaders.ClustersY / 2, slice, true},
+		{0, 0, slice, false},
+		{shaders.ClustersX / 2, shaders.ClustersY / 2, 0, false},
+		{shaders.ClustersX / 2, shaders.ClustersY / 2, shaders.ClustersZ - 1, false},
+	} {
+		lights := c.Cluster(tc.x, tc.y, tc.z)
+		if contains(lights, point) != tc.want {
+			t.Errorf("cluster (%d, %d, %d) holds the point light: %v", tc.x, tc.y, tc.z, !tc.want)
+		}
+		if !contains(lights, sun) {
+			t.Errorf("cluster (%d, %d, %d) is missing the directional light", tc.x, tc.y, tc.z)
+		}
+	}
+
+	// The light's view space position and range.
+	if v := c.lights[0]; v != (gfx.Vec4{Z: -10, W: 2}) {
+		t.Fatalf("got encoded position %v", v)
+	}
+	if want := float32(shaders.ClustersX / 1600.0); c.params.X != want || math.Abs(float64(c.params.W)-1) > 1e-6 {
+		t.Fatalf("got cluster parameters %v", c.params)
+	}
+}
+
+func TestClustersDropped(t *testing.T) {
+	c := New()
+	for i := 0; i < shaders.MaxClusterLights+8; i++ {
+		c.Lights = append(c.Lights, &Light{Kind: Point, Position: lmath.Vec3{Y: 10}, Range: 0.1})
+	}
+	c.Update(camera(), image.Rect(0, 0, 1600, 900))
+	if c.Dropped == 0 {
+		t.Fatal("no lights dropped from a full cluster")
+	}
+	slice := int(math.Log(10) / math.Log(100) * shaders.ClustersZ)
+	if n := len(c.Cluster(shaders.ClustersX/2, shaders.ClustersY/2, slice)); n != shaders.MaxClusterLights {
+		t.Fatalf("got %d lights in the cluster", n)
+	}
+}
+
+func TestEncodeSpot(t *testing.T) {
+	l := &Light{
+		Kind:       Spot,
+		Direction:  lmath.Vec3{Y: 1},
+		InnerAngle: math.Pi / 8,
+		OuterAngle: math.Pi / 4,
+		Cookie:     2,
+	}
+	v := make([]gfx.Vec4, 4)
+	l.encode(lmath.Mat4Identity, v)
+
+	// The cone attenuation is one at the inner angle, zero at the outer.
+	scale, offset := float64(v[1].W), float64(v[2].W)
+	if a := math.Cos(math.Pi/8)*scale + offset; math.Abs(a-1) > 1e-5 {
+		t.Errorf("attenuation %v at the inner angle", a)
+	}
+	if a := math.Cos(math.Pi/4)*scale + offset; math.Abs(a) > 1e-5 {
+		t.Errorf("attenuation %v at the outer angle", a)
+	}
+	if v[3] != (gfx.Vec4{X: 1, W: 2}) {
+		t.Errorf("got cookie %v", v[3])
+	}
+	if Spot.String() != "Spot" || Kind(7).String() != "Kind(7)" {
+		t.Error("wrong kind names")
+	}
+}
+
+func TestIncident(t *testing.T) {
+	spot := &Light{
+		Kind:       Spot,
+		Position:   lmath.Vec3{Z: 10},
+		Direction:  lmath.Vec3{Z: -1},
+		Range:      20,
+		InnerAngle: math.Pi / 8,
+		OuterAngle: math.Pi / 4,
+	}
+	dir, dist, att := spot.Incident(lmath.Vec3{})
+	if !dir.AlmostEquals(lmath.Vec3{Z: 1}, 1e-9) || dist != 10 {
+		t.Fatalf("got direction %v, distance %v", dir, dist)
+	}
+	w := 1 - math.Pow(0.5, 4)
+	if want := w * w / 101; math.Abs(att-want) > 1e-9 {
+		t.Fatalf("got attenuation %v, want %v", att, want)
+	}
+	if _, _, att := spot.Incident(lmath.Vec3{X: 20}); att != 0 {
+		t.Fatalf("got attenuation %v outside of the cone", att)
+	}
+
+	sun := &Light{Kind: Directional, Direction: lmath.Vec3{Z: -2}}
+	if dir, dist, att := sun.Incident(lmath.Vec3{}); dir != (lmath.Vec3{Z: 1}) || !math.IsInf(dist, 1) || att != 1 {
+		t.Fatalf("got direction %v, distance %v, attenuation %v", dir, dist, att)
+	}
+}
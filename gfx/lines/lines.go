@@ -0,0 +1,313 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package lines draws wide, antialiased lines and points.
+//
+// Core OpenGL profiles and OpenGL ES do not honor line widths other than one,
+// and do not antialias lines or points consistently. Instead, each line
+// segment and point is drawn as a quad which is expanded to it's width in
+// pixels by a shader, with caps at the ends of lines, joins between the
+// segments of polylines, and antialiased edges:
+//
+//  b := lines.New(glsl.GL2)
+//  style := lines.Style{Width: 3, Cap: lines.RoundCap, Join: lines.RoundJoin}
+//  b.AddPolyline(points, false, gfx.Color{1, 1, 1, 1}, style)
+//  b.AddPoint(p, gfx.Color{1, 0, 0, 1}, lines.Style{Width: 8, Cap: lines.RoundCap})
+//  b.Draw(d, d.Bounds(), cam)
+//
+// This is used for e.g. debug drawing (see package debugdraw), editor gizmos
+// and 2D graphs (using an orthographic camera).
+package lines // import "azul3d.org/engine/gfx/lines"
+
+import (
+	"fmt"
+	"image"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/glsl"
+	"azul3d.org/engine/lmath"
+)
+
+// MiterLimit is the maximum length of a miter join, relative to half the
+// width of the line, beyond which it is cut short.
+const MiterLimit = 4
+
+// Cap is the shape of the ends of a line (and of points).
+type Cap int
+
+const (
+	// ButtCap ends lines squarely at their end points.
+	ButtCap Cap = iota
+
+	// SquareCap ends lines squarely, half of their width past their end
+	// points. Points are drawn as squares.
+	SquareCap
+
+	// RoundCap ends lines with a half circle. Points are drawn as circles.
+	RoundCap
+)
+
+// String returns the name of the cap, e.g. "RoundCap".
+func (c Cap) String() string {
+	switch c {
+	case ButtCap:
+		return "ButtCap"
+	case SquareCap:
+		return "SquareCap"
+	case RoundCap:
+		return "RoundCap"
+	}
+	return fmt.Sprintf("Cap(%d)", int(c))
+}
+
+// Join is the shape of the joins between the segments of a polyline.
+type Join int
+
+const (
+	// MiterJoin extends the edges of both segments until they meet, up to
+	// MiterLimit.
+	MiterJoin Join = iota
+
+	// RoundJoin rounds the joins with a circle. Where the segments overlap,
+	// semi-transparent colors are blended twice.
+	RoundJoin
+)
+
+// String returns the name of the join, e.g. "MiterJoin".
+func (j Join) String() string {
+	switch j {
+	case MiterJoin:
+		return "MiterJoin"
+	case RoundJoin:
+		return "RoundJoin"
+	}
+	return fmt.Sprintf("Join(%d)", int(j))
+}
+
+// Style is the style that lines and points are drawn with. Zero fields take
+// their default values.
+type Style struct {
+	// Width is the width of lines, and the size of points, in pixels. The
+	// default is one.
+	Width float64
+
+	// The shape of the ends of lines and of points, and of the joins between
+	// the segments of polylines.
+	Cap  Cap
+	Join Join
+}
+
+// withDefaults returns the style with zero fields set to their default
+// values.
+func (s Style) withDefaults() Style {
+	if s.Width <= 0 {
+		s.Width = 1
+	}
+	return s
+}
+
+// The kinds of segment ends known to the shader.
+const (
+	kindButt = iota
+	kindSquare
+	kindRound
+	kindMiter
+)
+
+// kind returns the kind of segment end for the cap.
+func (c Cap) kind() int {
+	switch c {
+	case SquareCap:
+		return kindSquare
+	case RoundCap:
+		return kindRound
+	}
+	return kindButt
+}
+
+// kind returns the kind of segment end for the join.
+func (j Join) kind() int {
+	if j == RoundJoin {
+		return kindRound
+	}
+	return kindMiter
+}
+
+// Batch accumulates lines and points, and draws them all at once.
+//
+// A batch and it's methods are not safe for access from multiple goroutines
+// concurrently.
+type Batch struct {
+	// The object which is drawn. Unlike most objects, the batch owns it and
+	// you should not modify it's meshes directly.
+	*gfx.Object
+
+	mesh              *gfx.Mesh
+	others, adjacents []gfx.Vec3
+	strokes           []gfx.Vec4
+}
+
+// addSegment adds a segment from a to z, whose ends are adjacent to the
+// points before a and after z (which are the ends themselves if there are
+// none), and are of the given kinds.
+func (b *Batch) addSegment(a, z, before, after lmath.Vec3, c gfx.Color, width float64, startKind, endKind int) {
+	m := b.mesh
+	base := uint32(len(m.Vertices))
+	hw := float32(width / 2)
+	kinds := float32(startKind + 4*endKind)
+	va, vz := gfx.ConvertVec3(a), gfx.ConvertVec3(z)
+	vBefore, vAfter := gfx.ConvertVec3(before), gfx.ConvertVec3(after)
+
+	m.Vertices = append(m.Vertices, va, va, vz, vz)
+	m.Colors = append(m.Colors, c, c, c, c)
+	b.others = append(b.others, vz, vz, va, va)
+	b.adjacents = append(b.adjacents, vBefore, vBefore, vAfter, vAfter)
+	b.strokes = append(b.strokes,
+		gfx.Vec4{X: -1, Y: -1, Z: hw, W: kinds},
+		gfx.Vec4{X: 1, Y: -1, Z: hw, W: kinds},
+		gfx.Vec4{X: -1, Y: 1, Z: hw, W: kinds},
+		gfx.Vec4{X: 1, Y: 1, Z: hw, W: kinds},
+	)
+	m.Indices = append(m.Indices, base, base+1, base+2, base+2, base+1, base+3)
+}
+
+// AddLine adds a single line from a to z with the given color and style.
+func (b *Batch) AddLine(a, z lmath.Vec3, c gfx.Color, s Style) {
+	s = s.withDefaults()
+	b.addSegment(a, z, a, z, c, s.Width, s.Cap.kind(), s.Cap.kind())
+}
+
+// AddPolyline adds a line through each of the given points with the given
+// color and style. If closed is true, the last point is joined back to the
+// first one. Fewer than two points add nothing.
+func (b *Batch) AddPolyline(points []lmath.Vec3, closed bool, c gfx.Color, s Style) {
+	s = s.withDefaults()
+	n := len(points)
+	if n < 2 {
+		return
+	}
+	segments := n - 1
+	if closed {
+		segments = n
+	}
+	for i := 0; i < segments; i++ {
+		a, z := points[i], points[(i+1)%n]
+
+		// Ends with a neighbouring segment are joined, the others capped.
+		before, startKind := a, s.Cap.kind()
+		if i > 0 || closed {
+			before, startKind = points[(i+n-1)%n], s.Join.kind()
+		}
+		after, endKind := z, s.Cap.kind()
+		if i < n-2 || closed {
+			after, endKind = points[(i+2)%n], s.Join.kind()
+		}
+		b.addSegment(a, z, before, after, c, s.Width, startKind, endKind)
+	}
+}
+
+// AddPoint adds a point with the given color and style, drawn as a square or
+// circle (see the Cap type) of the style's width.
+func (b *Batch) AddPoint(p lmath.Vec3, c gfx.Color, s Style) {
+	s = s.withDefaults()
+	kind := kindSquare
+	if s.Cap == RoundCap {
+		kind = kindRound
+	}
+	b.addSegment(p, p, p, p, c, s.Width, kind, kind)
+}
+
+// Len returns the number of line segments and points that have been added
+// since the last call to Draw or Reset.
+func (b *Batch) Len() int {
+	return len(b.mesh.Vertices) / 4
+}
+
+// Reset discards all of the lines and points that have been added since the
+// last call to Draw or Reset.
+func (b *Batch) Reset() {
+	m := b.mesh
+	m.Vertices = m.Vertices[:0]
+	m.Colors = m.Colors[:0]
+	m.Indices = m.Indices[:0]
+	b.others = b.others[:0]
+	b.adjacents = b.adjacents[:0]
+	b.strokes = b.strokes[:0]
+}
+
+// Draw flushes all of the lines and points that have been added since the
+// last call to Draw or Reset to the given rectangle of the given canvas,
+// using the given camera. Afterwards, the batch is reset so that lines for
+// the next frame may be added.
+//
+// Widths are in pixels of the given rectangle, or of the canvas's bounds if
+// it is empty. If nothing has been added, this method is no-op.
+func (b *Batch) Draw(canvas gfx.Canvas, r image.Rectangle, cam gfx.Camera) {
+	m := b.mesh
+	if len(m.Vertices) == 0 {
+		return
+	}
+	size := r.Size()
+	if r.Empty() {
+		size = canvas.Bounds().Size()
+	}
+	b.Shader.Inputs["Viewport"] = gfx.TexCoord{U: float32(size.X), V: float32(size.Y)}
+
+	// Mark the mesh data as changed, and invalidate the cached bounds as the
+	// vertices have (most likely) changed since the last frame.
+	m.VerticesChanged = true
+	m.ColorsChanged = true
+	m.IndicesChanged = true
+	m.Attribs["Other"] = gfx.VertexAttrib{Data: b.others, Changed: true}
+	m.Attribs["Adjacent"] = gfx.VertexAttrib{Data: b.adjacents, Changed: true}
+	m.Attribs["Stroke"] = gfx.VertexAttrib{Data: b.strokes, Changed: true}
+	m.AABB = lmath.Rect3{}
+	b.Object.CachedBounds = nil
+
+	canvas.Draw(r, b.Object, cam)
+	b.Reset()
+}
+
+// Destroy destroys this batch and the object it owns. You must not use it
+// after calling this method.
+func (b *Batch) Destroy() {
+	b.Object.Destroy()
+}
+
+// New returns a new batch, with nothing added, whose shader is preprocessed
+// for the given target.
+func New(t glsl.Target) *Batch {
+	pp := &glsl.Preprocessor{
+		Target: t,
+		Defines: map[string]string{
+			"KIND_BUTT":   fmt.Sprintf("%d.0", kindButt),
+			"KIND_SQUARE": fmt.Sprintf("%d.0", kindSquare),
+			"KIND_ROUND":  fmt.Sprintf("%d.0", kindRound),
+			"KIND_MITER":  fmt.Sprintf("%d.0", kindMiter),
+			"MITER_LIMIT": fmt.Sprintf("%d.0", MiterLimit),
+		},
+	}
+	shader, err := pp.Shader("lines", []byte(vertSource), []byte(fragSource))
+	if err != nil {
+		// Only possible with a bug in the shader's sources.
+		panic(err)
+	}
+
+	m := gfx.NewMesh()
+	m.Dynamic = true
+	m.KeepDataOnLoad = true
+
+	o := gfx.NewObject()
+	o.State = gfx.NewState()
+	o.State.AlphaMode = gfx.AlphaBlend
+	o.State.DepthWrite = false
+	o.State.FaceCulling = gfx.NoFaceCulling
+	o.Shader = shader
+	o.Meshes = []*gfx.Mesh{m}
+
+	return &Batch{
+		Object: o,
+		mesh:   m,
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lines
+
+import (
+	"image"
+	"testing"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/camera"
+	"azul3d.org/engine/gfx/glsl"
+	"azul3d.org/engine/lmath"
+)
+
+func TestPolyline(t *testing.T) {
+	white := gfx.Color{R: 1, G: 1, B: 1, A: 1}
+	points := []lmath.Vec3{{X: 0}, {X: 1}, {X: 1, Y: 1}, {Y: 1}}
+	style := Style{Width: 4, Cap: RoundCap, Join: MiterJoin}
+
+	b := New(glsl.GL2)
+	b.AddPolyline(points[:1], false, white, style)
+	if b.Len() != 0 {
+		t.Fatal("a single point added a line")
+	}
+	b.AddPolyline(points, false, white, style)
+	if b.Len() != 3 {
+		t.Fatalf("got %d segments, want 3", b.Len())
+	}
+
+	// The outer ends are capped, the inner ones joined to their neighbours.
+	for i, want := range []struct {
+		kinds         float32
+		before, after lmath.Vec3
+	}{
+		{kindRound + 4*kindMiter, points[0], points[2]},
+		{kindMiter + 4*kindMiter, points[0], points[3]},
+		{kindMiter + 4*kindRound, points[1], points[3]},
+	} {
+		v := i * 4
+		if got := b.strokes[v].W; got != want.kinds {
+			t.Errorf("segment %d: kinds %v, want %v", i, got, want.kinds)
+		}
+		if b.adjacents[v].Vec3() != want.before || b.adjacents[v+3].Vec3() != want.after {
+			t.Errorf("segment %d: adjacent to %v and %v", i, b.adjacents[v], b.adjacents[v+3])
+		}
+		if b.strokes[v].Z != 2 {
+			t.Errorf("segment %d: half width %v, want 2", i, b.strokes[v].Z)
+		}
+	}
+
+	// Closed polylines join the last point to the first one.
+	b.Reset()
+	b.AddPolyline(points, true, white, style)
+	if b.Len() != 4 || b.strokes[0].W != kindMiter+4*kindMiter || b.adjacents[0].Vec3() != points[3] {
+		t.Fatal("polyline not closed")
+	}
+}
+
+func TestDraw(t *testing.T) {
+	b := New(glsl.GL3)
+	b.AddLine(lmath.Vec3Zero, lmath.Vec3One, gfx.Color{A: 1}, Style{})
+	b.AddPoint(lmath.Vec3One, gfx.Color{A: 1}, Style{Width: 8, Cap: RoundCap})
+	if b.Len() != 2 || b.strokes[0].Z != 0.5 || b.strokes[4].W != kindRound+4*kindRound {
+		t.Fatal("wrong line or point", b.strokes)
+	}
+	m := b.mesh
+	if len(m.Indices) != 12 || len(b.others) != len(m.Vertices) {
+		t.Fatal("wrong mesh")
+	}
+
+	d := gfx.Nil()
+	b.Draw(d, image.Rect(0, 0, 0, 0), camera.New(d.Bounds()))
+	if b.Len() != 0 || len(m.Indices) != 0 {
+		t.Fatal("not reset after Draw")
+	}
+	want := gfx.TexCoord{U: float32(d.Bounds().Dx()), V: float32(d.Bounds().Dy())}
+	if got := b.Shader.Inputs["Viewport"]; got != want {
+		t.Fatalf("viewport %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lines
+
+// The GLSL sources of the line shader, written in GLSL 1.20 style and
+// preprocessed using package glsl.
+//
+// Each segment is a quad whose four vertices lie on it's two ends. The vertex
+// shader projects both ends onto the screen and offsets each vertex by half
+// the width of the segment (plus a pixel of antialiasing fringe) in pixels,
+// away from the center line and, depending on the kind of the end, past it.
+// The fragment shader then computes the distance of each fragment from the
+// shape of the segment in pixels, from which it's coverage follows.
+//
+// The distances are interpolated linearly in screen space by multiplying them
+// by the W of the vertex, and dividing them by the interpolated W.
+const (
+	vertSource = `
+attribute vec3 Vertex;
+attribute vec3 Other;
+attribute vec3 Adjacent;
+attribute vec4 Color;
+attribute vec4 Stroke;
+
+uniform mat4 MVP;
+uniform vec2 Viewport;
+
+varying vec4 color;
+varying vec3 stroke;
+varying vec4 segment;
+
+vec2 toScreen(vec4 clip) {
+	return clip.xy / clip.w * 0.5 * Viewport;
+}
+
+void main(void) {
+	vec4 clip = MVP * vec4(Vertex, 1.0);
+	vec2 p = toScreen(clip);
+	vec2 o = toScreen(MVP * vec4(Other, 1.0));
+	vec2 adj = toScreen(MVP * vec4(Adjacent, 1.0));
+
+	float side = Stroke.x;
+	float end = Stroke.y;
+	float hw = Stroke.z;
+	float startKind = mod(Stroke.w, 4.0);
+	float endKind = floor(Stroke.w / 4.0);
+	float kind = end < 0.0 ? startKind : endKind;
+
+	// The direction of the segment out of this end, and the normal of the
+	// segment (which is the same at both ends). Points face the X axis.
+	float len = length(p - o);
+	vec2 dir = vec2(end, 0.0);
+	if (len > 0.0001) {
+		dir = (p - o) / len;
+	}
+	vec2 n = vec2(-dir.y, dir.x) * end;
+
+	// The half width, plus the antialiasing fringe.
+	float r = hw + 1.0;
+	vec2 offset = n * side * r;
+	if (kind == KIND_MITER) {
+		// The corners of both segments meet on the line bisecting them.
+		vec2 next = adj - p;
+		vec2 t = dir;
+		if (length(next) > 0.0001) {
+			t = dir + normalize(next);
+		}
+		if (length(t) > 0.0001) {
+			vec2 m = normalize(vec2(-t.y, t.x));
+			float c = dot(m, n);
+			if (abs(c) < 1.0 / MITER_LIMIT) {
+				c = sign(c) / MITER_LIMIT;
+			}
+			if (c != 0.0) {
+				offset = m * side * r / c;
+			}
+		}
+	} else if (kind == KIND_BUTT) {
+		offset += dir;
+	} else {
+		offset += dir * r;
+	}
+
+	gl_Position = clip + vec4(offset / (0.5 * Viewport) * clip.w, 0.0, 0.0);
+	stroke = vec3(dot(offset, n), end * (len * 0.5 + dot(offset, dir)), 1.0) * clip.w;
+	segment = vec4(len * 0.5, hw, startKind, endKind);
+	color = Color;
+}
+`
+
+	fragSource = `
+varying vec4 color;
+varying vec3 stroke;
+varying vec4 segment;
+
+void main(void) {
+	float across = abs(stroke.x / stroke.z);
+	float along = stroke.y / stroke.z;
+	float beyond = abs(along) - segment.x;
+	float hw = segment.y;
+	float kind = along < 0.0 ? segment.z : segment.w;
+
+	// The distance from the shape of the segment, which is cut off at the
+	// ends by their kind.
+	float d = across;
+	if (kind == KIND_BUTT) {
+		d = max(across, beyond + hw);
+	} else if (kind == KIND_SQUARE) {
+		d = max(across, beyond);
+	} else if (kind == KIND_ROUND) {
+		d = length(vec2(across, max(beyond, 0.0)));
+	}
+	float coverage = clamp(hw - d + 0.5, 0.0, 1.0);
+	if (coverage <= 0.0) {
+		discard;
+	}
+	gl_FragColor = color * coverage;
+}
+`
+)
@@ -0,0 +1,66 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+// MemoryStats describes the estimated amount of graphics memory, in bytes,
+// used by the resources of a device.
+//
+// The amounts are estimates only: graphics drivers are free to pad, compress,
+// or duplicate resources as they see fit.
+type MemoryStats struct {
+	// Memory used by loaded textures (not including render-to-texture ones,
+	// which are counted as part of Canvases).
+	Textures int64
+
+	// Memory used by the vertex data of loaded meshes.
+	Meshes int64
+
+	// Memory used by render-to-texture canvases (i.e. their textures and
+	// render buffers).
+	Canvases int64
+}
+
+// Total returns the total estimated memory usage, in bytes.
+func (m MemoryStats) Total() int64 {
+	return m.Textures + m.Meshes + m.Canvases
+}
+
+// MemoryReporter is an optional interface that a Device may implement in
+// order to report the (estimated) graphics memory used by it's resources,
+// such that applications may e.g. evict textures when memory runs low:
+//
+//  if m, ok := d.(gfx.MemoryReporter); ok {
+//      exceeded := make(chan gfx.MemoryStats, 1)
+//      m.SetMemoryBudget(256<<20, exceeded)
+//      go func() {
+//          for stats := range exceeded {
+//              ... free some textures ...
+//          }
+//      }()
+//  }
+//
+// Like the Device interface, these methods are safe to call from multiple
+// goroutines concurrently.
+type MemoryReporter interface {
+	// MemoryStats returns the current estimated memory usage of the device's
+	// resources.
+	MemoryStats() MemoryStats
+
+	// TextureSize returns the estimated memory used by the given loaded
+	// texture, in bytes, or zero if it is not loaded by the device.
+	TextureSize(t *Texture) int64
+
+	// MeshSize returns the estimated memory used by the given loaded mesh, in
+	// bytes, or zero if it is not loaded by the device.
+	MeshSize(m *Mesh) int64
+
+	// SetMemoryBudget sets the memory budget of the device, in bytes. Each
+	// time a resource is loaded and the total memory usage exceeds the budget,
+	// the memory statistics are sent over the exceeded channel (as long as
+	// sending would not block).
+	//
+	// A budget of zero (the default) or a nil channel disables the budget.
+	SetMemoryBudget(budget int64, exceeded chan MemoryStats)
+}
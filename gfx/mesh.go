@@ -55,6 +55,13 @@ type VertexAttrib struct {
 	//  [][]gfx.Color
 	//  []gfx.TexCoord
 	//  [][]gfx.TexCoord
+	//
+	// Or slices of the 32-bit lmath types (e.g. []lmath.Vec3f), likewise, or
+	// of the 16-bit (half-float) lmath types:
+	//  []lmath.Half
+	//  []lmath.Vec2h
+	//  []lmath.Vec3h
+	//  []lmath.Vec4h
 	Data interface{}
 
 	// Weather or not the per-vertex data (see the Data field) has changed
@@ -142,11 +149,29 @@ func (a VertexAttrib) Copy() VertexAttrib {
 		}
 
 	default:
-		return VertexAttrib{}
+		// Other slices (e.g. of the lmath types) are copied generically.
+		v := reflect.ValueOf(a.Data)
+		if v.Kind() != reflect.Slice {
+			return VertexAttrib{}
+		}
+		cpy = copySlice(v).Interface()
 	}
 	return VertexAttrib{Data: cpy}
 }
 
+// copySlice returns a deep copy of the given slice (or slice of slices).
+func copySlice(v reflect.Value) reflect.Value {
+	c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+	if v.Type().Elem().Kind() == reflect.Slice {
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(copySlice(v.Index(i)))
+		}
+		return c
+	}
+	reflect.Copy(c, v)
+	return c
+}
+
 // Mesh represents a single mesh made up of several components. A mesh may or
 // may not be made up of indexed vertices, etc, depending on whether or not
 // len(m.Indices) == 0 holds true.
@@ -264,6 +289,11 @@ type Mesh struct {
 	// See the documentation on the VertexAttrib type for more information
 	// regarding what data types may be used.
 	Attribs map[string]VertexAttrib
+
+	// Label is an optional human-readable label for debugging purposes. If
+	// the device supports it, the mesh's buffers are labeled with it such that
+	// they are identifiable in graphics debuggers (e.g. RenderDoc, apitrace).
+	Label string
 }
 
 // Copy returns a new copy of this Mesh. Depending on how large the mesh is
@@ -290,6 +320,7 @@ func (m *Mesh) Copy() *Mesh {
 		false, // BaryChanged -- not copied.
 		make([]TexCoordSet, len(m.TexCoords)),
 		make(map[string]VertexAttrib, len(m.Attribs)),
+		m.Label,
 	}
 
 	copy(cpy.Indices, m.Indices)
@@ -580,6 +611,7 @@ func (m *Mesh) Reset() {
 	}
 	m.TexCoords = m.TexCoords[:0]
 	m.Attribs = make(map[string]VertexAttrib)
+	m.Label = ""
 }
 
 // Destroy destroys this mesh for use by other callees to NewMesh. You must not
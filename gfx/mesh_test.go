@@ -4,7 +4,11 @@
 
 package gfx
 
-import "testing"
+import (
+	"testing"
+
+	"azul3d.org/engine/lmath"
+)
 
 var meshAppendTests = []struct {
 	name                                           string
@@ -223,3 +227,18 @@ func BenchmarkMeshAppend4kPrealloc(b *testing.B) {
 func BenchmarkMeshAppend4kDumb(b *testing.B) {
 	benchmarkMeshAppend(b, 16000, false)
 }
+
+func TestVertexAttribCopyHalf(t *testing.T) {
+	data := []lmath.Vec3h{
+		lmath.Vec3{X: 1, Y: 2, Z: 3}.Vec3h(),
+		lmath.Vec3{X: 4, Y: 5, Z: 6}.Vec3h(),
+	}
+	cpy, ok := VertexAttrib{Data: data}.Copy().Data.([]lmath.Vec3h)
+	if !ok || len(cpy) != len(data) || cpy[1] != data[1] {
+		t.Fatalf("Copy() = %v, want %v", cpy, data)
+	}
+	cpy[0] = lmath.Vec3h{}
+	if data[0] == cpy[0] {
+		t.Fatal("Copy() did not copy the data slice")
+	}
+}
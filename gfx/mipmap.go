@@ -0,0 +1,67 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+// MipLevel is a single pre-built mipmap level of a texture (see the Mipmaps
+// field of Texture).
+type MipLevel struct {
+	// The size of the level, in pixels.
+	Width, Height int
+
+	// The data of the level in the texture's format: tightly packed rows of
+	// four bytes per pixel for RGBA and SRGBA, or rows of 4x4 pixel blocks
+	// for the DXT formats.
+	Data []byte
+}
+
+// MipSize returns the size in bytes of the given mipmap levels, starting at
+// the given level (i.e. the memory needed to keep levels [level, len(levels))
+// resident). If the level is out of range, zero is returned.
+func MipSize(levels []MipLevel, level int) int64 {
+	if level < 0 {
+		level = 0
+	}
+	var size int64
+	for i := level; i < len(levels); i++ {
+		size += int64(len(levels[i].Data))
+	}
+	return size
+}
+
+// MipStreamer is an optional interface that a Device may implement in order
+// to stream the mipmap levels of textures with pre-built Mipmaps in and out
+// of graphics memory.
+//
+// When such a texture is loaded, only it's smallest levels (the 'mip tail')
+// are uploaded, such that it can be drawn immediately. More detailed levels
+// are uploaded afterwards as requested, one level at a time, from the
+// smallest to the largest:
+//
+//  if s, ok := d.(gfx.MipStreamer); ok {
+//      // Make the full resolution of the texture resident.
+//      s.SetResidentMip(tex, 0)
+//  }
+//
+// Most applications will want to use the texstream package instead, which
+// selects the levels based on their distance from the camera and a memory
+// budget.
+//
+// Like the Device interface, these methods are safe to call from multiple
+// goroutines concurrently.
+type MipStreamer interface {
+	// SetResidentMip requests that the given mipmap level of the loaded
+	// texture, and all smaller levels, be resident in graphics memory. More
+	// detailed levels are freed at the end of the next frame, while missing
+	// ones are uploaded over the following frames.
+	//
+	// The level is clamped such that the mip tail is always resident. If the
+	// texture is not loaded or has no pre-built Mipmaps, it is no-op.
+	SetResidentMip(t *Texture, level int)
+
+	// ResidentMip returns the most detailed mipmap level of the texture which
+	// is currently resident in graphics memory, or zero if the texture is not
+	// loaded or has no pre-built Mipmaps.
+	ResidentMip(t *Texture) int
+}
@@ -0,0 +1,32 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+// NativeInterop is an optional interface that a Device may implement in order
+// to interoperate with other native graphics APIs that share it's context,
+// for instance to submit rendered textures to a VR runtime or a video encoder.
+//
+// With OpenGL based devices, native objects are OpenGL object names (e.g. as
+// returned by glGenTextures).
+//
+// Like the Device interface, these methods are safe to call from multiple
+// goroutines concurrently.
+type NativeInterop interface {
+	// RunNative runs the given function under the presence of the device's
+	// native context (e.g. with it's OpenGL context current), ordered with
+	// respect to the device's other operations, and waits for it to return.
+	//
+	// The function may modify the native graphics state freely, it is
+	// restored afterwards.
+	RunNative(f func())
+
+	// CopyToNative copies the contents of the given loaded texture into the
+	// foreign native texture object, dst, which must be of the same size. It
+	// may only be called from within a function passed to RunNative.
+	//
+	// If the texture is not loaded by this device, false is returned and no
+	// copy occurs.
+	CopyToNative(src *Texture, dst uint32) bool
+}
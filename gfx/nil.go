@@ -65,9 +65,12 @@ func (n *nilDevice) Precision() Precision {
 
 func (n *nilDevice) Info() DeviceInfo {
 	return DeviceInfo{
-		MaxTextureSize:  8096,
-		AlphaToCoverage: true,
-		OcclusionQuery:  false,
+		MaxTextureSize:   8096,
+		AlphaToCoverage:  true,
+		OcclusionQuery:   false,
+		MaxAnisotropy:    1,
+		MaxTextures:      -1,
+		MaxVertexAttribs: -1,
 	}
 }
 func (n *nilDevice) Download(r image.Rectangle, complete chan image.Image) {
@@ -111,6 +114,9 @@ func (n *nilDevice) LoadTexture(t *Texture, done chan *Texture) {
 	t.NativeTexture = nilNativeTexture{
 		t.Format,
 	}
+	for _, f := range t.OnLoad {
+		f(t)
+	}
 	select {
 	case done <- t:
 	default:
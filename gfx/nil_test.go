@@ -5,6 +5,7 @@
 package gfx
 
 import (
+	"image"
 	"image/color"
 	"testing"
 )
@@ -38,3 +39,25 @@ func TestNilDevice(t *testing.T) {
 		d.Render()
 	}
 }
+
+func TestNilDeviceStreamingTexture(t *testing.T) {
+	d := Nil()
+
+	tex := NewTexture()
+	tex.Source = image.NewRGBA(image.Rect(0, 0, 8, 8))
+	tex.Streaming = true
+	var loaded bool
+	tex.OnLoad = append(tex.OnLoad, func(t *Texture) {
+		loaded = true
+	})
+
+	done := make(chan *Texture, 1)
+	d.LoadTexture(tex, done)
+	<-done
+	if !tex.Loaded || !loaded {
+		t.Fatalf("got Loaded=%v OnLoad called=%v, want both true", tex.Loaded, loaded)
+	}
+	if cpy := tex.Copy(); !cpy.Streaming || cpy.OnLoad != nil {
+		t.Fatal("Copy did not copy Streaming, or copied OnLoad")
+	}
+}
@@ -64,6 +64,11 @@ type Object struct {
 	// The shader program to be used during drawing the object.
 	*Shader
 
+	// The pipeline to be used during drawing the object. If non-nil, the
+	// shader and state of the pipeline are used instead of the State and
+	// Shader fields above (which may then be nil), see the DrawState method.
+	Pipeline *Pipeline
+
 	// A slice of meshes which make up the object. The order in which the
 	// meshes appear in this slice also affects the order in which they are
 	// sent to the graphics card.
@@ -73,11 +78,22 @@ type Object struct {
 	// meshes.
 	Meshes []*Mesh
 
+	// Indirect, if non-nil, is a buffer of draw commands each drawing a range
+	// of the object's first mesh, which are drawn instead of the meshes
+	// themselves.
+	Indirect *Indirect
+
 	// A slice of textures which are used to texture the meshes of this object.
 	// The order in which the textures appear in this slice is also the order
 	// in which they are sent to the graphics card.
 	Textures []*Texture
 
+	// A slice of samplers which override the sampling state of the textures
+	// of the same index, e.g. Samplers[1] is used when sampling Textures[1].
+	// If the slice is shorter than the textures slice, or if a sampler is
+	// nil, the texture's own sampling state is used instead.
+	Samplers []*Sampler
+
 	// CachedBounds represents the pre-calculated cached bounding box of this
 	// object. Note that the bounds are only calculated once Object.Bounds() is
 	// invoked.
@@ -91,6 +107,12 @@ type Object struct {
 	//
 	// And then simply invoke o.Bounds() again to calculate the bounds again.
 	CachedBounds *lmath.Rect3
+
+	// Label is an optional human-readable label for debugging purposes. If
+	// the device supports it, drawing the object is wrapped in a debug group
+	// of this name, such that it is identifiable in graphics debuggers (e.g.
+	// RenderDoc, apitrace).
+	Label string
 }
 
 // Bounds implements the Boundable interface. The returned bounding box takes
@@ -135,6 +157,15 @@ func (o *Object) Bounds() lmath.Rect3 {
 	return b
 }
 
+// DrawState returns the shader and state used to draw this object: those of
+// it's pipeline if it has one, otherwise it's own.
+func (o *Object) DrawState() (*Shader, *State) {
+	if o.Pipeline != nil {
+		return o.Pipeline.Shader, o.Pipeline.State
+	}
+	return o.Shader, o.State
+}
+
 // Compare compares this object's state (including shader and textures) against
 // the other one and determines if it should sort before the other one for
 // state sorting purposes.
@@ -144,7 +175,9 @@ func (o *Object) Compare(other *Object) bool {
 	}
 
 	// Compare shaders.
-	if o.Shader != other.Shader {
+	shader, state := o.DrawState()
+	otherShader, otherState := other.DrawState()
+	if shader != otherShader {
 		return false
 	}
 
@@ -155,15 +188,28 @@ func (o *Object) Compare(other *Object) bool {
 		}
 	}
 
-	// Compare states.
-	return o.State.Compare(other.State)
+	// Compare samplers.
+	if len(o.Samplers) != len(other.Samplers) {
+		return false
+	}
+	for i, s := range o.Samplers {
+		if other.Samplers[i] != s {
+			return false
+		}
+	}
+
+	// Compare states, objects with the same pipeline have the same state.
+	if o.Pipeline != nil && o.Pipeline == other.Pipeline {
+		return true
+	}
+	return state.Compare(otherState)
 }
 
 // Copy returns a new copy of this Object. Explicitily not copied is the native
 // object. The transform is copied via it's Copy() method.
 //
-// The state, shader, meshes, and textures are all shallow copies only (i.e.
-// only the pointer values are copied).
+// The state, shader, pipeline, meshes, indirect buffer, textures, and samplers
+// are all shallow copies only (i.e. only the pointer values are copied).
 func (o *Object) Copy() *Object {
 	cpyCachedBounds := *o.CachedBounds
 	cpy := &Object{
@@ -171,12 +217,17 @@ func (o *Object) Copy() *Object {
 		State:         o.State,
 		Transform:     o.Transform.Copy(),
 		Shader:        o.Shader,
+		Pipeline:      o.Pipeline,
 		Meshes:        make([]*Mesh, len(o.Meshes)),
+		Indirect:      o.Indirect,
 		Textures:      make([]*Texture, len(o.Textures)),
+		Samplers:      make([]*Sampler, len(o.Samplers)),
 		CachedBounds:  &cpyCachedBounds,
+		Label:         o.Label,
 	}
 	copy(cpy.Meshes, o.Meshes)
 	copy(cpy.Textures, o.Textures)
+	copy(cpy.Samplers, o.Samplers)
 	return cpy
 }
 
@@ -187,7 +238,10 @@ func (o *Object) Reset() {
 	o.State = nil
 	o.Transform = NewTransform()
 	o.Shader = nil
+	o.Pipeline = nil
+	o.Indirect = nil
 	o.CachedBounds = nil
+	o.Label = ""
 
 	// Nil out each mesh pointer.
 	for i := 0; i < len(o.Meshes); i++ {
@@ -200,6 +254,12 @@ func (o *Object) Reset() {
 		o.Textures[i] = nil
 	}
 	o.Textures = o.Textures[:0]
+
+	// Nil out each sampler pointer.
+	for i := 0; i < len(o.Samplers); i++ {
+		o.Samplers[i] = nil
+	}
+	o.Samplers = o.Samplers[:0]
 }
 
 // Destroy destroys this object for use by other callees to NewObject. You must
@@ -0,0 +1,394 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package oit implements order-independent transparency: drawing overlapping
+// transparent objects (e.g. particles and glass) correctly regardless of the
+// order that they are drawn in, without sorting them.
+//
+// The transparent objects are drawn through a pass into render-to-texture
+// canvases of it's own, after the opaque scene has been drawn, and the pass
+// then composites them onto the canvas:
+//
+//  p, err := oit.New(d, d.Bounds(), oit.Config{
+//      Mode:   oit.WeightedBlended,
+//      Target: glsl.GL2,
+//      Depth:  depthTexture,
+//  })
+//  if err != nil {
+//      log.Fatal(err)
+//  }
+//  for {
+//      // Draw the opaque scene to the screen, and it's depth to the depth
+//      // canvas.
+//      ...
+//      p.Draw(d, transparent, cam)
+//      d.Render()
+//  }
+//
+// The objects are drawn using their own shaders, whose fragment shaders are
+// wrapped by the pass such that their output is written as each mode
+// requires. As such their GLSL sources must still be available, i.e. the
+// shaders must have KeepDataOnLoad set or not yet be loaded. The colors that
+// they output are treated as premultiplied by alpha, as with
+// gfx.DefaultBlendState.
+package oit // import "azul3d.org/engine/gfx/oit"
+
+import (
+	"errors"
+	"fmt"
+	"image"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/glsl"
+	"azul3d.org/engine/gfx/internal/pass"
+)
+
+// Mode is a method of order-independent transparency.
+type Mode int
+
+const (
+	// WeightedBlended draws the objects once, accumulating the colors of
+	// overlapping fragments as an average weighted by their alpha and
+	// distance from the camera. It is approximate (the order of fragments
+	// of similar distance is lost, and the accumulation has only 8 bits of
+	// precision) but it's cost does not grow with the depth complexity.
+	WeightedBlended Mode = iota
+
+	// DepthPeeling draws the objects once per layer, each time peeling off
+	// the nearest fragments behind the previous layer and compositing them
+	// front to back. It is exact up to Config.Layers overlapping fragments,
+	// and requires a device supporting depth textures.
+	DepthPeeling
+)
+
+// String returns the name of the mode, e.g. "WeightedBlended".
+func (m Mode) String() string {
+	switch m {
+	case WeightedBlended:
+		return "WeightedBlended"
+	case DepthPeeling:
+		return "DepthPeeling"
+	}
+	return fmt.Sprintf("Mode(%d)", int(m))
+}
+
+// Config configures an OIT pass. Zero fields take their default values.
+type Config struct {
+	// The mode of the pass. The default is WeightedBlended.
+	Mode Mode
+
+	// Layers is the number of overlapping fragments expected. Depth peeling
+	// peels this many layers (dropping any fragments behind them), weighted
+	// blending scales the accumulated colors down by it such that they fit
+	// in the range of the canvas. The default is 4.
+	Layers int
+
+	// Distance is the distance from the camera at which the weight of
+	// fragments halves, for weighted blending. The default is 10.
+	Distance float64
+
+	// Target is the backend that the compositing shaders are preprocessed
+	// for.
+	Target glsl.Target
+
+	// Depth is the optional depth texture of a render-to-texture canvas that
+	// the opaque scene is drawn into using the camera, at the same size as
+	// the canvas that the pass draws to. Transparent fragments behind it are
+	// discarded; without it, the objects are drawn over the opaque scene.
+	Depth *gfx.Texture
+}
+
+// withDefaults returns the configuration with zero fields set to their
+// default values.
+func (c Config) withDefaults() Config {
+	if c.Layers <= 0 {
+		c.Layers = 4
+	}
+	if c.Distance <= 0 {
+		c.Distance = 10
+	}
+	return c
+}
+
+// Pass is an order-independent transparency pass.
+type Pass struct {
+	// The configuration of the pass, which must not be changed.
+	Config
+
+	// The canvases that the objects are drawn into, and their color and
+	// depth textures. For weighted blending the first holds the accumulated
+	// colors and the second the revealage, for depth peeling they hold
+	// alternating layers.
+	canvases [2]gfx.Canvas
+	colors   [2]*gfx.Texture
+	depths   [2]*gfx.Texture
+
+	// The canvas that the layers of depth peeling are composited into, front
+	// to back, and it's color texture.
+	layers      gfx.Canvas
+	layersColor *gfx.Texture
+
+	// under composites a layer behind the previous ones, and composite draws
+	// the result onto the canvas.
+	under, composite *gfx.Object
+
+	// The wrapped shaders, and the wrapped objects of each stage drawn by the
+	// last call to Draw.
+	shaders map[shaderKey]*gfx.Shader
+	objects [numStages]map[*gfx.Object]*gfx.Object
+	size    gfx.TexCoord
+}
+
+// object returns the wrapped object of the given one for the given stage,
+// updated to match it, or nil if it's shader cannot be wrapped. The wrapped
+// objects used are moved into the given maps, indexed by stage.
+func (p *Pass) object(o *gfx.Object, st stage, used *[numStages]map[*gfx.Object]*gfx.Object) *gfx.Object {
+	shader, state := o.DrawState()
+	if shader == nil {
+		return nil
+	}
+	s, err := p.wrap(shader, st, len(o.Textures))
+	if err != nil {
+		return nil
+	}
+	w, ok := used[st][o]
+	if !ok {
+		w, ok = p.objects[st][o]
+		if !ok {
+			w = gfx.NewObject()
+			w.State = gfx.NewState()
+		}
+		used[st][o] = w
+	}
+
+	// Inputs of the object's shader are those of the wrapped one.
+	for k, v := range shader.Inputs {
+		s.Inputs[k] = v
+	}
+	s.Inputs["OITSize"] = p.size
+	if st == accumStage {
+		s.Inputs["OITScale"] = float32(1 / float64(p.Layers))
+		s.Inputs["OITDistance"] = float32(p.Distance)
+	}
+
+	w.Shader = s
+	w.Transform = o.Transform
+	w.Meshes = o.Meshes
+	w.Indirect = o.Indirect
+	w.Samplers = o.Samplers
+	w.Label = o.Label
+	w.Textures = append(w.Textures[:0], o.Textures...)
+	if p.Depth != nil {
+		w.Textures = append(w.Textures, p.Depth)
+	}
+	if state != nil {
+		*w.State = *state
+	} else {
+		*w.State = *gfx.NewState()
+	}
+	switch st {
+	case accumStage:
+		w.State.AlphaMode = gfx.AlphaBlend
+		w.State.Blend = gfx.BlendState{
+			SrcRGB:   gfx.BOne,
+			DstRGB:   gfx.BOne,
+			SrcAlpha: gfx.BOne,
+			DstAlpha: gfx.BOne,
+		}
+		w.State.DepthTest = false
+		w.State.DepthWrite = false
+	case revealStage:
+		w.State.AlphaMode = gfx.AlphaBlend
+		w.State.Blend = gfx.BlendState{
+			SrcRGB:   gfx.BZero,
+			DstRGB:   gfx.BOneMinusSrcAlpha,
+			SrcAlpha: gfx.BZero,
+			DstAlpha: gfx.BOneMinusSrcAlpha,
+		}
+		w.State.DepthTest = false
+		w.State.DepthWrite = false
+	case peelStage:
+		// The nearest fragment of the layer is kept, the depth texture of
+		// the previous layer is appended by Draw.
+		w.State.AlphaMode = gfx.NoAlpha
+		w.State.DepthTest = true
+		w.State.DepthWrite = true
+		w.State.DepthCmp = gfx.Less
+	}
+	return w
+}
+
+// Draw draws the given transparent objects using the given camera, and
+// composites them onto the given canvas (the one that the pass was created
+// for). Objects whose shaders cannot be wrapped (see the package
+// documentation) are not drawn.
+func (p *Pass) Draw(c gfx.Canvas, objs []*gfx.Object, cam gfx.Camera) {
+	var used [numStages]map[*gfx.Object]*gfx.Object
+	for i := range used {
+		used[i] = make(map[*gfx.Object]*gfx.Object, len(p.objects[i]))
+	}
+	if p.Mode == DepthPeeling {
+		p.peel(objs, cam, &used)
+	} else {
+		p.blend(objs, cam, &used)
+	}
+
+	// Objects which were not drawn are forgotten.
+	p.objects = used
+	c.Draw(c.Bounds(), p.composite, cam)
+}
+
+// blend draws the objects with weighted blending.
+func (p *Pass) blend(objs []*gfx.Object, cam gfx.Camera, used *[numStages]map[*gfx.Object]*gfx.Object) {
+	accum, reveal := p.canvases[0], p.canvases[1]
+	accum.Clear(accum.Bounds(), gfx.Color{})
+	reveal.Clear(reveal.Bounds(), gfx.Color{R: 1, G: 1, B: 1, A: 1})
+	for _, o := range objs {
+		if w := p.object(o, accumStage, used); w != nil {
+			accum.Draw(accum.Bounds(), w, cam)
+		}
+		if w := p.object(o, revealStage, used); w != nil {
+			reveal.Draw(reveal.Bounds(), w, cam)
+		}
+	}
+	accum.Render()
+	reveal.Render()
+}
+
+// peel draws the objects with depth peeling.
+func (p *Pass) peel(objs []*gfx.Object, cam gfx.Camera, used *[numStages]map[*gfx.Object]*gfx.Object) {
+	b := p.layers.Bounds()
+	p.layers.Clear(b, gfx.Color{})
+	p.layers.Render()
+
+	// Nothing is peeled before the first layer.
+	p.canvases[1].ClearDepth(b, 0)
+	p.canvases[1].Render()
+
+	for i := 0; i < p.Layers; i++ {
+		cur, prev := i%2, 1-i%2
+		canvas := p.canvases[cur]
+		canvas.Clear(b, gfx.Color{})
+		canvas.ClearDepth(b, 1)
+		for _, o := range objs {
+			if w := p.object(o, peelStage, used); w != nil {
+				w.Textures = append(w.Textures, p.depths[prev])
+				canvas.Draw(b, w, cam)
+			}
+		}
+		canvas.Render()
+
+		p.under.Textures[0] = p.colors[cur]
+		p.layers.Draw(b, p.under, cam)
+		p.layers.Render()
+	}
+}
+
+// newQuad returns a new object drawing a quad covering the canvas with the
+// given shader and textures, blended with the given state.
+func newQuad(s *gfx.Shader, blend gfx.BlendState, textures ...*gfx.Texture) *gfx.Object {
+	o := gfx.NewObject()
+	o.Meshes = []*gfx.Mesh{pass.Quad()}
+	o.Textures = textures
+	o.Shader = s
+	o.State = gfx.NewState()
+	o.State.AlphaMode = gfx.AlphaBlend
+	o.State.Blend = blend
+	o.State.DepthTest = false
+	o.State.DepthWrite = false
+	o.State.FaceCulling = gfx.NoFaceCulling
+	return o
+}
+
+// newCanvas returns a new render-to-texture canvas of the given device with
+// the given bounds and it's color texture, and if depth is true it's depth
+// texture.
+func newCanvas(d gfx.Device, b image.Rectangle, depth bool) (c gfx.Canvas, color, depthTex *gfx.Texture, err error) {
+	formats := d.Info().RTTFormats
+
+	// The colors need an alpha channel, regardless of the device's.
+	prec := d.Precision()
+	prec.AlphaBits = 8
+	cfg := formats.ChooseConfig(prec, false)
+	if cfg.ColorFormat == gfx.ZeroTexFormat {
+		return nil, nil, nil, errors.New("oit: render-to-texture is not supported")
+	}
+	cfg.Bounds = image.Rectangle{Max: b.Size()}
+	cfg.Color = gfx.NewTexture()
+	cfg.Color.MinFilter = gfx.Nearest
+	cfg.Color.MagFilter = gfx.Nearest
+	cfg.Color.WrapU = gfx.Clamp
+	cfg.Color.WrapV = gfx.Clamp
+	if depth {
+		// Combined depth and stencil formats cannot be used as textures, so
+		// choose the most precise depth-only format.
+		var best gfx.DSFormat
+		for _, f := range formats.DepthFormats {
+			if f.IsDepth() && !f.IsCombined() && f.DepthBits() > best.DepthBits() {
+				best = f
+			}
+		}
+		if best == gfx.ZeroDSFormat {
+			return nil, nil, nil, errors.New("oit: depth textures are not supported")
+		}
+		cfg.Depth = gfx.NewTexture()
+		cfg.Depth.MinFilter = gfx.Nearest
+		cfg.Depth.MagFilter = gfx.Nearest
+		cfg.DepthFormat = best
+		cfg.StencilFormat = gfx.ZeroDSFormat
+	}
+	if c = d.RenderToTexture(cfg); c == nil {
+		return nil, nil, nil, errors.New("oit: render-to-texture is not supported")
+	}
+	return c, cfg.Color, cfg.Depth, nil
+}
+
+// New returns a new OIT pass drawing to a canvas of the given bounds, using
+// render-to-texture canvases of the given device. An error is returned if the
+// device does not support render-to-texture, or depth textures for depth
+// peeling.
+func New(d gfx.Device, b image.Rectangle, c Config) (*Pass, error) {
+	c = c.withDefaults()
+	p := &Pass{
+		Config:  c,
+		shaders: make(map[shaderKey]*gfx.Shader),
+		size: gfx.TexCoord{
+			U: float32(b.Dx()),
+			V: float32(b.Dy()),
+		},
+	}
+	for i := range p.objects {
+		p.objects[i] = make(map[*gfx.Object]*gfx.Object)
+	}
+	peeling := c.Mode == DepthPeeling
+	for i := range p.canvases {
+		var err error
+		p.canvases[i], p.colors[i], p.depths[i], err = newCanvas(d, b, peeling)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Premultiplied colors are composited over the canvas.
+	over := gfx.DefaultBlendState
+	if !peeling {
+		p.composite = newQuad(pass.Shader("OIT Resolve", c.Target, resolveFrag, nil), over, p.colors[0], p.colors[1])
+		return p, nil
+	}
+
+	var err error
+	p.layers, p.layersColor, _, err = newCanvas(d, b, false)
+	if err != nil {
+		return nil, err
+	}
+	blit := pass.Shader("OIT Blit", c.Target, blitFrag, nil)
+	p.under = newQuad(blit, gfx.BlendState{
+		SrcRGB:   gfx.BOneMinusDstAlpha,
+		DstRGB:   gfx.BOne,
+		SrcAlpha: gfx.BOneMinusDstAlpha,
+		DstAlpha: gfx.BOne,
+	}, p.colors[0])
+	p.composite = newQuad(blit, over, p.layersColor)
+	return p, nil
+}
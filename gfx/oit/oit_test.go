@@ -0,0 +1,117 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oit
+
+import (
+	"bytes"
+	"image"
+	"testing"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/glsl"
+	"azul3d.org/engine/gfx/internal/pass"
+	"azul3d.org/engine/gfx/shaders"
+)
+
+// depthFormats are the depth formats of a device supporting depth textures.
+var depthFormats = []gfx.DSFormat{gfx.Depth16, gfx.Depth24AndStencil8, gfx.Depth24}
+
+func TestWrapFrag(t *testing.T) {
+	src := []byte("#version 100\nprecision mediump float;\nvoid main(void) {\n\tgl_FragColor = vec4(1.0);\n}\n")
+	got := wrapFrag(src, peelStage, 2, true)
+	if !bytes.HasPrefix(got, []byte("#version 100\n#define main oitMain\nprecision")) {
+		t.Fatalf("wrong header:\n%s", got)
+	}
+	for _, want := range []string{
+		"#undef main",
+		"uniform sampler2D Texture2;",
+		"uniform sampler2D Texture3;",
+		"texture2D(Texture3, oitCoord).r) {\n\t\tdiscard;",
+		"\toitMain();",
+	} {
+		if !bytes.Contains(got, []byte(want)) {
+			t.Errorf("missing %q:\n%s", want, got)
+		}
+	}
+	if bytes.Contains(got, []byte("OITScale")) {
+		t.Errorf("peeling weighs fragments:\n%s", got)
+	}
+}
+
+func TestNew(t *testing.T) {
+	b := image.Rect(0, 0, 640, 480)
+	if _, err := New(gfx.Nil(), b, Config{}); err == nil {
+		t.Fatal("expected an error without render-to-texture support")
+	}
+	if _, err := New(&pass.RTTDevice{Device: gfx.Nil()}, b, Config{Mode: DepthPeeling}); err == nil {
+		t.Fatal("expected an error without depth texture support")
+	}
+	p, err := New(&pass.RTTDevice{Device: gfx.Nil()}, b, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Layers != 4 || p.Distance != 10 || p.layers != nil {
+		t.Fatal("wrong pass", p)
+	}
+	d := &pass.RTTDevice{Device: gfx.Nil(), DepthFormats: depthFormats}
+	p, err = New(d, b, Config{Mode: DepthPeeling})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.depths[0] == nil || p.depths[0] == p.depths[1] || p.layers == nil {
+		t.Fatal("wrong pass", p)
+	}
+	if d.Config.DepthFormat != gfx.Depth24 {
+		t.Fatal("wrong depth format", d.Config.DepthFormat)
+	}
+}
+
+func TestDraw(t *testing.T) {
+	d := &pass.RTTDevice{Device: gfx.Nil(), DepthFormats: depthFormats}
+	glass := gfx.NewObject()
+	glass.Shader = shaders.New(shaders.Unlit, glsl.GL2)
+	glass.Shader.Inputs["Tint"] = float32(0.5)
+	glass.Textures = []*gfx.Texture{gfx.NewTexture()}
+
+	// The sources of loaded shaders are gone.
+	loaded := gfx.NewObject()
+	loaded.Shader = gfx.NewShader("Loaded")
+	loaded.Shader.GLSL = &gfx.GLSLSources{}
+
+	p, err := New(d, image.Rect(0, 0, 200, 100), Config{Depth: gfx.NewTexture()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Draw(d, []*gfx.Object{glass, loaded}, nil)
+	if len(p.objects[accumStage]) != 1 || len(p.objects[revealStage]) != 1 {
+		t.Fatal("wrong objects drawn", p.objects)
+	}
+	w := p.objects[accumStage][glass]
+	if w.Shader.Inputs["Tint"] != float32(0.5) || w.Shader.Inputs["OITScale"] != float32(0.25) {
+		t.Fatal("wrong inputs", w.Shader.Inputs)
+	}
+	if len(w.Textures) != 2 || w.Textures[1] != p.Depth || w.State.Blend.DstRGB != gfx.BOne {
+		t.Fatal("wrong accumulation object", w)
+	}
+
+	// Objects no longer drawn are forgotten.
+	p.Draw(d, nil, nil)
+	if len(p.objects[accumStage]) != 0 {
+		t.Fatal("objects not forgotten")
+	}
+
+	p, err = New(d, image.Rect(0, 0, 200, 100), Config{Mode: DepthPeeling})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Draw(d, []*gfx.Object{glass}, nil)
+	w = p.objects[peelStage][glass]
+	if len(w.Textures) != 2 || w.Textures[1] != p.depths[0] || !w.State.DepthWrite {
+		t.Fatal("wrong peeling object", w)
+	}
+	if p.under.Textures[0] != p.colors[1] {
+		t.Fatal("last layer not composited")
+	}
+}
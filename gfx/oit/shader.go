@@ -0,0 +1,155 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"azul3d.org/engine/gfx"
+)
+
+// The GLSL sources of the compositing shaders, written in GLSL 1.20 style and
+// preprocessed using package glsl.
+const (
+	// resolveFrag resolves the accumulated colors (the first texture) and
+	// revealage (the second texture) of weighted blending into a single
+	// premultiplied color.
+	resolveFrag = `
+uniform sampler2D Texture0;
+uniform sampler2D Texture1;
+
+varying vec2 texCoord;
+
+void main(void) {
+	float revealage = texture2D(Texture1, texCoord).r;
+	if (revealage >= 1.0) {
+		// Nothing transparent covers the pixel.
+		discard;
+	}
+	vec4 accum = texture2D(Texture0, texCoord);
+	vec3 average = accum.rgb / max(accum.a, 0.00001);
+	gl_FragColor = vec4(average * (1.0 - revealage), 1.0 - revealage);
+}
+`
+
+	// blitFrag draws the first texture as-is.
+	blitFrag = `
+uniform sampler2D Texture0;
+
+varying vec2 texCoord;
+
+void main(void) {
+	gl_FragColor = texture2D(Texture0, texCoord);
+}
+`
+)
+
+// stage is a way that a pass draws the transparent objects.
+type stage int
+
+const (
+	// accumStage accumulates the weighted colors of the fragments.
+	accumStage stage = iota
+
+	// revealStage multiplies the revealage by one minus the alpha of the
+	// fragments.
+	revealStage
+
+	// peelStage draws the nearest fragments behind the previous layer.
+	peelStage
+
+	numStages
+)
+
+// wrapFrag returns the given fragment shader source, wrapped such that it's
+// output is written as required by the given stage. The object using it has
+// the given number of textures, after which the depth texture of the opaque
+// scene (if opaque is true) and the one of the previous layer (for
+// peelStage) are appended.
+//
+// The main function of the source is renamed, and called by a new main
+// function which discards fragments which are occluded (or peeled) before
+// calling it, and then rewrites the color that it output.
+func wrapFrag(src []byte, st stage, textures int, opaque bool) []byte {
+	var buf bytes.Buffer
+
+	// The version header (and with it, precision statements) must come first.
+	head, body := []byte(nil), src
+	if bytes.HasPrefix(bytes.TrimSpace(src), []byte("#version")) {
+		if i := bytes.IndexByte(src, '\n'); i >= 0 {
+			head, body = src[:i+1], src[i+1:]
+		}
+	}
+	buf.Write(head)
+	buf.WriteString("#define main oitMain\n")
+	buf.Write(body)
+	buf.WriteString("\n#undef main\n\n")
+
+	buf.WriteString("uniform vec2 OITSize;\n")
+	opaqueTex, prevTex := textures, textures
+	if opaque {
+		fmt.Fprintf(&buf, "uniform sampler2D Texture%d;\n", opaqueTex)
+		prevTex++
+	}
+	if st == peelStage {
+		fmt.Fprintf(&buf, "uniform sampler2D Texture%d;\n", prevTex)
+	}
+	if st == accumStage {
+		buf.WriteString("uniform float OITScale;\nuniform float OITDistance;\n")
+	}
+
+	buf.WriteString("\nvoid main(void) {\n")
+	buf.WriteString("\tvec2 oitCoord = gl_FragCoord.xy / OITSize;\n")
+	if opaque {
+		fmt.Fprintf(&buf, "\tif (gl_FragCoord.z >= texture2D(Texture%d, oitCoord).r) {\n\t\tdiscard;\n\t}\n", opaqueTex)
+	}
+	if st == peelStage {
+		fmt.Fprintf(&buf, "\tif (gl_FragCoord.z <= texture2D(Texture%d, oitCoord).r) {\n\t\tdiscard;\n\t}\n", prevTex)
+	}
+	buf.WriteString("\toitMain();\n\tvec4 oitColor = gl_FragColor;\n")
+	switch st {
+	case accumStage:
+		// The weight falls off with the distance from the camera, such that
+		// nearer fragments dominate the average.
+		buf.WriteString("\tfloat z = 1.0 / gl_FragCoord.w;\n")
+		buf.WriteString("\tfloat w = OITScale * clamp(OITDistance / (OITDistance + z), 0.1, 1.0);\n")
+		buf.WriteString("\tgl_FragColor = oitColor * w;\n")
+	case revealStage:
+		buf.WriteString("\tgl_FragColor = vec4(oitColor.a);\n")
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+// shaderKey identifies a wrapped shader.
+type shaderKey struct {
+	s        *gfx.Shader
+	st       stage
+	textures int
+}
+
+// errNoSources is returned by wrap for shaders whose sources are gone.
+var errNoSources = errors.New("oit: shader has no GLSL sources (set KeepDataOnLoad)")
+
+// wrap returns the wrapped shader of the given one for the given stage, used
+// by objects with the given number of textures. Wrapped shaders are cached.
+func (p *Pass) wrap(s *gfx.Shader, st stage, textures int) (*gfx.Shader, error) {
+	key := shaderKey{s, st, textures}
+	if w, ok := p.shaders[key]; ok {
+		return w, nil
+	}
+	if s.GLSL == nil || len(s.GLSL.Fragment) == 0 {
+		return nil, errNoSources
+	}
+	w := gfx.NewShader(s.Name + " (OIT)")
+	w.GLSL = &gfx.GLSLSources{
+		Vertex:   s.GLSL.Vertex,
+		Fragment: wrapFrag(s.GLSL.Fragment, st, textures, p.Depth != nil),
+	}
+	p.shaders[key] = w
+	return w, nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import "azul3d.org/engine/lmath"
+
+// IntersectRay tests whether the given ray (in the mesh's local space)
+// intersects any triangle of the mesh, returning the distance along the ray
+// to the nearest intersection and the index of the first vertex index (or
+// vertex, if the mesh is not indexed) of the intersected triangle.
+//
+// Meshes whose primitive is not Triangles, or whose data was not kept after
+// loading (see KeepDataOnLoad), are never intersected.
+func (m *Mesh) IntersectRay(r lmath.Ray) (t float64, triangle int, ok bool) {
+	if m.Primitive != Triangles || len(m.Vertices) == 0 {
+		return 0, 0, false
+	}
+
+	// Reject rays missing the mesh entirely.
+	if _, hit := r.IntersectRect3(m.Bounds()); !hit {
+		return 0, 0, false
+	}
+
+	n := len(m.Vertices)
+	if m.Indices != nil {
+		n = len(m.Indices)
+	}
+	vertex := func(i int) lmath.Vec3 {
+		if m.Indices != nil {
+			return m.Vertices[m.Indices[i]].Vec3()
+		}
+		return m.Vertices[i].Vec3()
+	}
+	for i := 0; i+2 < n; i += 3 {
+		tri, _, _, hit := r.IntersectTriangle(vertex(i), vertex(i+1), vertex(i+2))
+		if hit && (!ok || tri < t) {
+			t, triangle, ok = tri, i, true
+		}
+	}
+	return
+}
+
+// IntersectRay tests whether the given world space ray intersects any mesh of
+// the object, returning the distance along the ray to the nearest
+// intersection.
+//
+// The ray is transformed into the object's local space, so the returned
+// distance is in units of the length of the ray's direction, as given.
+func (o *Object) IntersectRay(r lmath.Ray) (t float64, ok bool) {
+	if o.Transform != nil {
+		r = r.Transform(o.Transform.Convert(WorldToLocal))
+	}
+	for _, m := range o.Meshes {
+		mt, _, hit := m.IntersectRay(r)
+		if hit && (!ok || mt < t) {
+			t, ok = mt, true
+		}
+	}
+	return
+}
+
+// Pick returns the nearest of the given objects intersected by the given world
+// space ray (e.g. as returned by a camera's PickRay method), and the distance
+// along the ray to it. If no object is intersected, nil is returned.
+func Pick(r lmath.Ray, objects ...*Object) (nearest *Object, t float64) {
+	for _, o := range objects {
+		ot, hit := o.IntersectRay(r)
+		if hit && (nearest == nil || ot < t) {
+			nearest, t = o, ot
+		}
+	}
+	return
+}
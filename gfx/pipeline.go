@@ -0,0 +1,98 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+// Pipeline bundles a shader program with the fixed-function render state used
+// when drawing with it. Many objects may share a single pipeline (see
+// Object.Pipeline).
+//
+// Unlike the shader and state of an object, which are examined each time the
+// object is drawn, a pipeline is validated once by the device when it is
+// loaded (see PipelineLoader). Devices may then cache what they derived from
+// it (or map it to a native pipeline object, on graphics APIs which have
+// them), such that drawing many objects with the same pipeline is cheaper.
+//
+// Once loaded, changes to the pipeline's State have no effect until it is
+// reloaded, which is done by setting Loaded to false. The inputs of the shader
+// may be changed freely.
+//
+// A pipeline and it's methods are not safe for access from multiple
+// goroutines concurrently.
+type Pipeline struct {
+	// The native object of this pipeline. Once the pipeline is loaded this
+	// field will be initialized by the device. Only device implementations
+	// should assign values to this field.
+	NativePipeline Destroyable
+
+	// Weather or not this pipeline is currently loaded or not.
+	Loaded bool
+
+	// The shader program to be used when drawing with the pipeline.
+	Shader *Shader
+
+	// The render state to be used when drawing with the pipeline.
+	State *State
+}
+
+// Compare compares this pipeline against the other one and determines if it
+// should sort before the other one for state sorting purposes.
+func (p *Pipeline) Compare(other *Pipeline) bool {
+	if p == other {
+		return true
+	}
+	if p.Shader != other.Shader {
+		return false
+	}
+	return p.State.Compare(other.State)
+}
+
+// Copy returns a new copy of this pipeline. Explicitily not copied is the
+// native pipeline and the loaded status. The shader and state are shallow
+// copies only (i.e. only the pointer values are copied).
+func (p *Pipeline) Copy() *Pipeline {
+	return &Pipeline{
+		Shader: p.Shader,
+		State:  p.State,
+	}
+}
+
+// Destroy destroys the native pipeline, after which the pipeline must be
+// loaded again to be used. The shader and state of the pipeline are not
+// destroyed, as they may be used elsewhere.
+func (p *Pipeline) Destroy() {
+	if p.NativePipeline != nil {
+		p.NativePipeline.Destroy()
+		p.NativePipeline = nil
+	}
+	p.Loaded = false
+}
+
+// NewPipeline returns a new pipeline with the given shader and state.
+func NewPipeline(shader *Shader, state *State) *Pipeline {
+	return &Pipeline{
+		Shader: shader,
+		State:  state,
+	}
+}
+
+// PipelineLoader is the interface implemented by devices which validate and
+// cache pipelines ahead of time.
+//
+// Devices which do not implement it still draw objects with pipelines, but do
+// so by examining the pipeline's shader and state at each draw, as they would
+// the object's own.
+type PipelineLoader interface {
+	// LoadPipeline should begin loading the specified pipeline (and it's
+	// shader, if it is not yet loaded), and send it over the done channel
+	// when complete.
+	//
+	// If the pipeline's shader or state is nil, or it's shader has an error,
+	// the pipeline is not loaded (i.e. Loaded remains false), but it is still
+	// sent over the done channel.
+	//
+	// The done channel may be nil, in which case no signal is sent. Sending
+	// over the channel is non-blocking, as such it should be buffered.
+	LoadPipeline(p *Pipeline, done chan *Pipeline)
+}
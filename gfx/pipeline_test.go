@@ -0,0 +1,57 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import "testing"
+
+func TestObjectDrawState(t *testing.T) {
+	o := NewObject()
+	o.Shader = NewShader("object")
+	o.State = NewState()
+	if shader, state := o.DrawState(); shader != o.Shader || state != o.State {
+		t.Fatal("expected the object's own shader and state")
+	}
+
+	p := NewPipeline(NewShader("pipeline"), NewState())
+	o.Pipeline = p
+	if shader, state := o.DrawState(); shader != p.Shader || state != p.State {
+		t.Fatal("expected the pipeline's shader and state")
+	}
+}
+
+func TestObjectComparePipeline(t *testing.T) {
+	shader := NewShader("shared")
+	a := NewObject()
+	a.Pipeline = NewPipeline(shader, NewState())
+	b := NewObject()
+	b.Pipeline = a.Pipeline
+	if !a.Compare(b) || !b.Compare(a) {
+		t.Fatal("objects with the same pipeline should compare equal")
+	}
+
+	// An object without a pipeline, but with the same shader and state,
+	// compares the same as one with it.
+	c := NewObject()
+	c.Shader = shader
+	c.State = a.Pipeline.State
+	if !c.Compare(a) {
+		t.Fatal("expected equal shader and state to compare equal")
+	}
+
+	// Different shaders never sort before one another.
+	b.Pipeline = NewPipeline(NewShader("other"), a.Pipeline.State)
+	if a.Compare(b) {
+		t.Fatal("expected different shaders to compare unequal")
+	}
+}
+
+func TestPipelineCopy(t *testing.T) {
+	p := NewPipeline(NewShader("p"), NewState())
+	p.Loaded = true
+	cpy := p.Copy()
+	if cpy.Loaded || cpy.Shader != p.Shader || cpy.State != p.State {
+		t.Fatalf("bad copy %+v", cpy)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"image"
+	"runtime"
+	"testing"
+)
+
+// benchFrameObjects is the number of objects drawn per frame by the frame
+// benchmarks below.
+const benchFrameObjects = 1000
+
+// countCanvas is a canvas which counts the draws issued to it.
+type countCanvas struct {
+	Canvas
+	draws int
+}
+
+func (c *countCanvas) Draw(r image.Rectangle, o *Object, cam Camera) {
+	c.draws++
+}
+
+// reportGCPause reports the garbage collector pause time per operation of the
+// benchmark, from the time it is called until the returned function is.
+func reportGCPause(b *testing.B) func() {
+	var start runtime.MemStats
+	runtime.ReadMemStats(&start)
+	return func() {
+		var end runtime.MemStats
+		runtime.ReadMemStats(&end)
+		b.ReportMetric(float64(end.PauseTotalNs-start.PauseTotalNs)/float64(b.N), "gc-ns/op")
+	}
+}
+
+// BenchmarkFrameAlloc records a frame of draws, allocating new objects and a
+// new command list each frame.
+func BenchmarkFrameAlloc(b *testing.B) {
+	rect := image.Rect(0, 0, 640, 480)
+	c := &countCanvas{}
+	b.ReportAllocs()
+	defer reportGCPause(b)()
+	for i := 0; i < b.N; i++ {
+		l := NewCommandList()
+		for n := 0; n < benchFrameObjects; n++ {
+			o := &Object{Transform: &Transform{}}
+			l.Draw(rect, o, nil)
+		}
+		l.Execute(c)
+	}
+}
+
+// BenchmarkFramePooled is like BenchmarkFrameAlloc, except objects come from
+// (and are returned to) the object pool, and the command list is reused.
+func BenchmarkFramePooled(b *testing.B) {
+	rect := image.Rect(0, 0, 640, 480)
+	c := &countCanvas{}
+	objects := make([]*Object, benchFrameObjects)
+	l := NewCommandList()
+	b.ReportAllocs()
+	defer reportGCPause(b)()
+	for i := 0; i < b.N; i++ {
+		for n := range objects {
+			objects[n] = NewObject()
+			l.Draw(rect, objects[n], nil)
+		}
+		l.Execute(c)
+		for _, o := range objects {
+			o.Destroy()
+		}
+		l.Reset()
+	}
+}
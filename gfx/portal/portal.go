@@ -0,0 +1,283 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package portal provides helpers for stencil-based planar mirror and portal
+// rendering.
+//
+// A mirror (or portal) is rendered in a few steps. First the surface of the
+// mirror is drawn into the stencil buffer using MaskState. Next the scene is
+// drawn from the point of view of the reflected camera (see Mirror) using
+// ContentState, such that it only appears where the mirror surface is visible.
+// Finally the depth buffer is cleared, the mirror surface is drawn again with
+// color writes disabled to restore it's depth, and the scene is drawn as usual
+// from the point of view of the real camera:
+//
+//  mirrorCam := portal.Mirror(cam, plane)
+//
+//  surface.State = portal.MaskState(1)
+//  canvas.Draw(r, surface, cam)
+//
+//  for _, o := range scene {
+//      reflected := o.Copy()
+//      reflected.State = portal.ContentState(o.State, 1, true)
+//      canvas.Draw(r, reflected, mirrorCam)
+//  }
+//
+//  canvas.ClearDepth(r, 1.0)
+//  surface.State = depthOnlyState
+//  canvas.Draw(r, surface, cam)
+//  ...
+//
+// The reflected camera's near plane is made oblique (i.e. it is aligned with
+// the mirror's plane), such that objects behind the mirror are clipped away
+// without requiring user clip planes, which are not supported on all
+// backends.
+package portal // import "azul3d.org/engine/gfx/portal"
+
+import (
+	"image"
+	"math"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/lmath"
+)
+
+var (
+	// Get an matrix which will translate our matrix from YUpRight to ZUpRight
+	yUpRightToZUpRight = lmath.CoordSysYUpRight.ConvertMat4(lmath.CoordSysZUpRight)
+
+	// A half turn about the Z (up) axis.
+	halfTurn = lmath.Mat4FromAxisAngle(lmath.Vec3{0, 0, 1}, math.Pi, lmath.CoordSysZUpRight)
+)
+
+// Plane is a plane in world space, consisting of all points p where:
+//
+//  p.Dot(plane.Normal) == plane.Dist
+//
+// The side of the plane that the normal points toward is the front side.
+type Plane struct {
+	Normal lmath.Vec3
+	Dist   float64
+}
+
+// PlaneFromPoint returns the plane passing through the given point with the
+// given normal, which is normalized.
+func PlaneFromPoint(point, normal lmath.Vec3) Plane {
+	n, _ := normal.Normalized()
+	return Plane{
+		Normal: n,
+		Dist:   point.Dot(n),
+	}
+}
+
+// vec4 returns the plane in homogeneous form, such that a point p on the plane
+// satisfies Vec4{p.X, p.Y, p.Z, 1}.Dot(v) == 0.
+func (p Plane) vec4() lmath.Vec4 {
+	return lmath.Vec4{p.Normal.X, p.Normal.Y, p.Normal.Z, -p.Dist}
+}
+
+// Reflect returns a matrix which reflects points about the plane.
+func (p Plane) Reflect() lmath.Mat4 {
+	n := p.Normal
+	return lmath.Matrix4(
+		1-2*n.X*n.X, -2*n.X*n.Y, -2*n.X*n.Z, 0,
+		-2*n.Y*n.X, 1-2*n.Y*n.Y, -2*n.Y*n.Z, 0,
+		-2*n.Z*n.X, -2*n.Z*n.Y, 1-2*n.Z*n.Z, 0,
+		2*p.Dist*n.X, 2*p.Dist*n.Y, 2*p.Dist*n.Z, 1,
+	)
+}
+
+// fromMat4 returns a new transform (without a parent) whose matrix is the
+// given affine matrix, which may contain a reflection.
+func fromMat4(m lmath.Mat4) *gfx.Transform {
+	upper := m.UpperMat3()
+
+	// Decompose only handles proper rotations, so move any reflection into the
+	// X axis scale.
+	flip := upper.Determinant() < 0
+	if flip {
+		upper[0] = [3]float64{-upper[0][0], -upper[0][1], -upper[0][2]}
+	}
+	scale, shear, hpr := upper.Decompose(lmath.CoordSysZUpRight)
+	if flip {
+		scale.X = -scale.X
+	}
+
+	t := gfx.NewTransform()
+	t.SetPos(m.Translation())
+	t.SetQuat(lmath.QuatFromHpr(hpr, lmath.CoordSysZUpRight))
+	t.SetScale(scale)
+	t.SetShear(shear)
+	return t
+}
+
+// MirrorTransform returns a new transform which is the given one (e.g. of a
+// camera) reflected about the given plane.
+//
+// The reflection reverses the winding order of triangles seen through the
+// transform, see ContentState.
+func MirrorTransform(t gfx.Transformable, p Plane) *gfx.Transform {
+	return fromMat4(t.Transform().Mat4().Mul(p.Reflect()))
+}
+
+// PortalTransform returns a new transform which is the given one (e.g. of a
+// camera) looking into the src portal, as it would appear looking out of the
+// dst portal.
+//
+// Portal surfaces lie on their X/Z plane and face along their forward (+Y)
+// axis, i.e. the camera looks into the front side of src and out of the front
+// side of dst.
+func PortalTransform(t, src, dst gfx.Transformable) *gfx.Transform {
+	srcInv, _ := src.Transform().Mat4().Inverse()
+	m := t.Transform().Mat4().Mul(srcInv).Mul(halfTurn).Mul(dst.Transform().Mat4())
+	return fromMat4(m)
+}
+
+// PortalPlane returns the plane of the given portal (see PortalTransform),
+// whose front side is the side the portal faces.
+func PortalPlane(t gfx.Transformable) Plane {
+	m := t.Transform().Mat4()
+	forward := lmath.Vec3{0, 1, 0}.TransformVecMat4(m)
+	return PlaneFromPoint(m.Translation(), forward)
+}
+
+// sign returns -1, 0, or +1 depending on the sign of v.
+func sign(v float64) float64 {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+	return 0
+}
+
+// ObliqueProjection returns the given projection matrix of a camera with the
+// given transform, modified such that it's near plane coincides with the given
+// plane. Everything behind the plane (i.e. on the back side) is clipped away.
+//
+// The camera must be behind the plane, or else the projection matrix is
+// returned unmodified.
+//
+// See "Oblique View Frustum Depth Projection and Clipping", Eric Lengyel,
+// Journal of Game Development, 2005.
+func ObliqueProjection(camera gfx.Transformable, proj gfx.Mat4, p Plane) gfx.Mat4 {
+	// Transform the plane into view space. Points are transformed by the view
+	// matrix, so planes are transformed by the transpose of it's inverse.
+	viewInv := yUpRightToZUpRight.Mul(camera.Transform().Mat4())
+	c := p.vec4().Transform(viewInv.Transposed())
+	if c.W >= 0 {
+		return proj
+	}
+
+	// Find the clip-space corner point opposite the plane, and scale the
+	// plane such that it becomes the near plane.
+	m := proj.Mat4()
+	inv, ok := m.Inverse()
+	if !ok {
+		return proj
+	}
+	q := lmath.Vec4{sign(c.X), sign(c.Y), 1, 1}.Transform(inv)
+	c = c.MulScalar(2 / c.Dot(q))
+	m = m.SetCol(2, c.Sub(m.Col(3)))
+	return gfx.ConvertMat4(m)
+}
+
+// Camera is a camera with a fixed transform and projection matrix, as returned
+// by Mirror and Portal.
+type Camera struct {
+	T *gfx.Transform
+	P gfx.Mat4
+}
+
+// Transform implements the gfx.Camera interface.
+func (c *Camera) Transform() *gfx.Transform {
+	return c.T
+}
+
+// Projection implements the gfx.Camera interface.
+func (c *Camera) Projection() gfx.Mat4 {
+	return c.P
+}
+
+// Update implements the gfx.Camera interface. It does nothing, as the
+// projection matrix is derived from another camera.
+func (c *Camera) Update(b image.Rectangle) {}
+
+// Mirror returns a camera viewing the reflection of the given camera's view in
+// a planar mirror with the given plane, whose front side is the reflective
+// side. Objects behind the mirror are clipped.
+//
+// The camera must be recreated whenever the given camera (or it's projection)
+// changes.
+func Mirror(cam gfx.Camera, p Plane) *Camera {
+	t := MirrorTransform(cam, p)
+	return &Camera{
+		T: t,
+		P: ObliqueProjection(t, cam.Projection(), p),
+	}
+}
+
+// Portal returns a camera viewing what the given camera sees through the src
+// portal, i.e. out of the dst portal (see PortalTransform). Objects behind the
+// dst portal are clipped.
+//
+// The camera must be recreated whenever the given camera (or it's projection)
+// changes.
+func Portal(cam gfx.Camera, src, dst gfx.Transformable) *Camera {
+	t := PortalTransform(cam, src, dst)
+	return &Camera{
+		T: t,
+		P: ObliqueProjection(t, cam.Projection(), PortalPlane(dst)),
+	}
+}
+
+// MaskState returns a new state for drawing the surface of a mirror or portal
+// into the stencil buffer: wherever the surface is visible the stencil buffer
+// is set to the given reference value, and neither color nor depth are
+// written.
+func MaskState(ref uint) *gfx.State {
+	s := gfx.NewState()
+	s.WriteRed = false
+	s.WriteGreen = false
+	s.WriteBlue = false
+	s.WriteAlpha = false
+	s.DepthWrite = false
+	s.StencilTest = true
+	st := gfx.DefaultStencilState
+	st.Reference = ref
+	st.DepthPass = gfx.SReplace
+	s.StencilFront = st
+	s.StencilBack = st
+	return s
+}
+
+// ContentState returns a new copy of the given state (or of the default state
+// if nil), for drawing an object seen through a mirror or portal: the object
+// is only drawn where the stencil buffer equals the given reference value (see
+// MaskState).
+//
+// If mirrored is true, the face culling mode is swapped because reflection
+// reverses the winding order of triangles.
+func ContentState(base *gfx.State, ref uint, mirrored bool) *gfx.State {
+	s := gfx.NewState()
+	if base != nil {
+		*s = *base
+	}
+	s.StencilTest = true
+	st := gfx.DefaultStencilState
+	st.Reference = ref
+	st.Cmp = gfx.Equal
+	s.StencilFront = st
+	s.StencilBack = st
+	if mirrored {
+		switch s.FaceCulling {
+		case gfx.BackFaceCulling:
+			s.FaceCulling = gfx.FrontFaceCulling
+		case gfx.FrontFaceCulling:
+			s.FaceCulling = gfx.BackFaceCulling
+		}
+	}
+	return s
+}
@@ -0,0 +1,69 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package portal
+
+import (
+	"image"
+	"testing"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/camera"
+	"azul3d.org/engine/lmath"
+)
+
+const epsilon = 1e-6
+
+func TestReflect(t *testing.T) {
+	p := PlaneFromPoint(lmath.Vec3{0, 0, 1}, lmath.Vec3{0, 0, 2})
+	got := lmath.Vec3{1, 2, 3}.TransformMat4(p.Reflect())
+	want := lmath.Vec3{1, 2, -1}
+	if !got.AlmostEquals(want, epsilon) {
+		t.Fatalf("got %v want %v", got, want)
+	}
+}
+
+func TestMirrorTransform(t *testing.T) {
+	cam := gfx.NewTransform()
+	cam.SetPos(lmath.Vec3{1, -5, 2})
+	cam.SetRot(lmath.Vec3{-20, 0, 30})
+
+	p := PlaneFromPoint(lmath.Vec3{0, 0, 0}, lmath.Vec3{0.2, 0, 1})
+	got := MirrorTransform(cam, p).Mat4()
+	want := cam.Mat4().Mul(p.Reflect())
+	if !got.AlmostEquals(want, epsilon) {
+		t.Fatalf("got\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestPortalTransform(t *testing.T) {
+	cam := gfx.NewTransform()
+	cam.SetPos(lmath.Vec3{0, 5, 0})
+	dst := gfx.NewTransform()
+	dst.SetPos(lmath.Vec3{10, 0, 0})
+
+	got := PortalTransform(cam, gfx.NewTransform(), dst).Mat4().Translation()
+	want := lmath.Vec3{10, -5, 0}
+	if !got.AlmostEquals(want, epsilon) {
+		t.Fatalf("got %v want %v", got, want)
+	}
+}
+
+func TestMirrorNearPlane(t *testing.T) {
+	cam := camera.New(image.Rect(0, 0, 640, 480))
+	cam.SetPos(lmath.Vec3{0, -10, 2})
+
+	// A floor mirror, the point on it straight ahead of the camera must lie
+	// on the near plane of the reflected camera.
+	p := PlaneFromPoint(lmath.Vec3{0, 0, 0}, lmath.Vec3{0, 0, 1})
+	m := Mirror(cam, p)
+
+	viewInv := yUpRightToZUpRight.Mul(m.Transform().Mat4())
+	view, _ := viewInv.Inverse()
+	vp := view.Mul(m.Projection().Mat4())
+	clip := lmath.Vec4{0, 0, 0, 1}.Transform(vp)
+	if z := clip.Z / clip.W; !lmath.AlmostEqual(z, -1, 1e-4) {
+		t.Fatalf("got NDC z=%v, want -1", z)
+	}
+}
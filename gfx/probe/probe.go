@@ -0,0 +1,196 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package probe implements reflection probes: cubemaps of the scene captured
+// at points within it, which are reflected by nearby shiny surfaces.
+//
+// Each probe has a box (typically fitted to the walls of a room) which bounds
+// the area it influences, and onto which it's cubemap is projected such that
+// reflections line up with the surroundings of the reflecting surface rather
+// than those of the probe. Objects drawn with the shaders.Reflective shader
+// blend the two probes nearest to them (see Set.Apply):
+//
+//  canvas, tex := probe.NewCanvas(d, 256)
+//  p := probe.New(lmath.Vec3{0, 0, 2}, lmath.Rect3{
+//      Min: lmath.Vec3{-5, -5, 0},
+//      Max: lmath.Vec3{5, 5, 4},
+//  })
+//  p.Texture = tex
+//  p.Capture(canvas, 0.1, 100, func(r image.Rectangle, cam gfx.Camera) {
+//      canvas.Clear(r, skyColor)
+//      canvas.ClearDepth(r, 1.0)
+//      for _, o := range staticScene {
+//          canvas.Draw(r, o, cam)
+//      }
+//  })
+//
+//  set := &probe.Set{Probes: []*probe.Probe{p, ...}}
+//  obj.Shader = shaders.New(shaders.Reflective, glsl.GL2)
+//  obj.Shader.Inputs["Reflectivity"] = float32(0.04)
+//  set.Apply(obj)
+//
+// The device has no cubemap textures, so the six faces of each cubemap are
+// laid out from left to right in a single 2D texture, in the order +X, -X,
+// +Y, -Y, +Z, -Z (see Face). Probes may be captured once when the scene is
+// loaded, or baked ahead of time by downloading the captured texture and
+// saving it as an image (see Probe.Flipped).
+package probe // import "azul3d.org/engine/gfx/probe"
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/portal"
+	"azul3d.org/engine/lmath"
+)
+
+// Face is a face of a cubemap.
+type Face int
+
+// The faces of a cubemap, in the order they are laid out in the texture.
+const (
+	PosX Face = iota
+	NegX
+	PosY
+	NegY
+	PosZ
+	NegZ
+)
+
+// String returns the name of the face, e.g. "+X".
+func (f Face) String() string {
+	switch f {
+	case PosX:
+		return "+X"
+	case NegX:
+		return "-X"
+	case PosY:
+		return "+Y"
+	case NegY:
+		return "-Y"
+	case PosZ:
+		return "+Z"
+	case NegZ:
+		return "-Z"
+	}
+	return fmt.Sprintf("Face(%d)", int(f))
+}
+
+// Axes returns the world space direction that the face looks along, and the
+// direction of the top of the face. The bottom-left of each face is at it's
+// texture coordinate (0, 0), as with render-to-texture canvases.
+func (f Face) Axes() (forward, up lmath.Vec3) {
+	switch f {
+	case PosX:
+		return lmath.Vec3{X: 1}, lmath.Vec3{Z: 1}
+	case NegX:
+		return lmath.Vec3{X: -1}, lmath.Vec3{Z: 1}
+	case PosY:
+		return lmath.Vec3{Y: 1}, lmath.Vec3{Z: 1}
+	case NegY:
+		return lmath.Vec3{Y: -1}, lmath.Vec3{Z: 1}
+	case PosZ:
+		return lmath.Vec3{Z: 1}, lmath.Vec3{Y: 1}
+	case NegZ:
+		return lmath.Vec3{Z: -1}, lmath.Vec3{Y: 1}
+	}
+	panic(fmt.Sprintf("probe: invalid face %v", f))
+}
+
+// Probe is a reflection probe.
+type Probe struct {
+	// The world space position that the cubemap is captured from.
+	Position lmath.Vec3
+
+	// The world space box that the probe influences, and that it's cubemap
+	// is projected onto.
+	Box lmath.Rect3
+
+	// Fade is the distance from the sides of the box over which the
+	// influence of the probe fades in, such that reflections blend smoothly
+	// between neighbouring probes.
+	Fade float64
+
+	// The texture holding the six faces of the cubemap, see NewCanvas.
+	Texture *gfx.Texture
+
+	// Flipped tells if the faces of the texture are upside down, as they are
+	// in textures loaded from images (e.g. a baked cubemap, downloaded from a
+	// captured texture and saved).
+	Flipped bool
+}
+
+// New returns a new probe at the given position influencing the given box,
+// with a fade distance of one.
+func New(pos lmath.Vec3, box lmath.Rect3) *Probe {
+	return &Probe{
+		Position: pos,
+		Box:      box,
+		Fade:     1,
+	}
+}
+
+// Weight returns the influence of the probe at the given world space point:
+// zero outside of it's box, rising to one at the fade distance within it.
+func (p *Probe) Weight(point lmath.Vec3) float64 {
+	if !p.Box.Contains(point) {
+		return 0
+	}
+	if p.Fade <= 0 {
+		return 1
+	}
+	// The distance to the nearest side of the box.
+	d := point.Sub(p.Box.Min).Min(p.Box.Max.Sub(point))
+	return math.Min(math.Min(d.X, d.Y), math.Min(d.Z, p.Fade)) / p.Fade
+}
+
+// Camera returns the camera that the given face of the cubemap is drawn with,
+// given the near and far planes.
+func (p *Probe) Camera(f Face, near, far float64) *portal.Camera {
+	forward, up := f.Axes()
+	t := gfx.NewTransform()
+	t.SetPos(p.Position)
+	t.LookAt(p.Position.Add(forward), up)
+	return &portal.Camera{
+		T: t,
+		P: gfx.ConvertMat4(lmath.Mat4Perspective(90, 1, near, far)),
+	}
+}
+
+// Capture captures the cubemap of the probe using the given canvas (see
+// NewCanvas), with the given near and far planes. For each face, draw is
+// called to draw the scene to the given rectangle of the canvas using the
+// given camera, then the canvas is rendered.
+func (p *Probe) Capture(c gfx.Canvas, near, far float64, draw func(r image.Rectangle, cam gfx.Camera)) {
+	size := c.Bounds().Dy()
+	for f := PosX; f <= NegZ; f++ {
+		r := image.Rect(int(f)*size, 0, int(f+1)*size, size)
+		draw(r, p.Camera(f, near, far))
+	}
+	c.Render()
+}
+
+// NewCanvas creates a render-to-texture canvas that cubemaps with faces of
+// the given size are captured with, and returns it along with it's color
+// texture. If the device does not support render-to-texture, the canvas and
+// texture are nil.
+func NewCanvas(d gfx.Device, size int) (gfx.Canvas, *gfx.Texture) {
+	cfg := d.Info().RTTFormats.ChooseConfig(d.Precision(), false)
+	if cfg.ColorFormat == gfx.ZeroTexFormat {
+		return nil, nil
+	}
+	cfg.Bounds = image.Rect(0, 0, size*6, size)
+	cfg.Color = gfx.NewTexture()
+	cfg.Color.MinFilter = gfx.Linear
+	cfg.Color.MagFilter = gfx.Linear
+	cfg.Color.WrapU = gfx.Clamp
+	cfg.Color.WrapV = gfx.Clamp
+	c := d.RenderToTexture(cfg)
+	if c == nil {
+		return nil, nil
+	}
+	return c, cfg.Color
+}
@@ -0,0 +1,100 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package probe
+
+import (
+	"math"
+	"testing"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/shaders"
+	"azul3d.org/engine/lmath"
+)
+
+// room returns a probe in the middle of a 10x10x4 room whose corner is at the
+// given X coordinate.
+func room(x float64) *Probe {
+	p := New(lmath.Vec3{X: x + 5, Y: 5, Z: 2}, lmath.Rect3{
+		Max: lmath.Vec3{X: 10, Y: 10, Z: 4},
+	}.Add(lmath.Vec3{X: x}))
+	p.Texture = gfx.NewTexture()
+	return p
+}
+
+func TestFaces(t *testing.T) {
+	p := room(0)
+	for f := PosX; f <= NegZ; f++ {
+		forward, up := f.Axes()
+		cam := p.Camera(f, 0.1, 100)
+		m := cam.T.Mat4()
+		if got := (lmath.Vec3{Y: 1}).TransformVecMat4(m); !got.AlmostEquals(forward, 1e-9) {
+			t.Errorf("%v: camera looks along %v, want %v", f, got, forward)
+		}
+		if got := (lmath.Vec3{Z: 1}).TransformVecMat4(m); !got.AlmostEquals(up, 1e-9) {
+			t.Errorf("%v: camera up is %v, want %v", f, got, up)
+		}
+	}
+	if Face(6).String() != "Face(6)" {
+		t.Error("wrong invalid face name")
+	}
+}
+
+func TestWeight(t *testing.T) {
+	p := room(0)
+	for _, tc := range []struct {
+		point lmath.Vec3
+		want  float64
+	}{
+		{lmath.Vec3{X: 5, Y: 5, Z: 2}, 1},
+		{lmath.Vec3{X: 5, Y: 5, Z: 0.5}, 0.5},
+		{lmath.Vec3{X: 0.25, Y: 5, Z: 2}, 0.25},
+		{lmath.Vec3{X: -1, Y: 5, Z: 2}, 0},
+	} {
+		if w := p.Weight(tc.point); math.Abs(w-tc.want) > 1e-9 {
+			t.Errorf("weight %v at %v, want %v", w, tc.point, tc.want)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	a, b, far := room(0), room(9.5), room(100)
+	b.Flipped = true
+	s := &Set{Probes: []*Probe{far, a, b}}
+
+	m := gfx.NewMesh()
+	m.Vertices = []gfx.Vec3{{X: -0.1, Z: -0.1}, {X: 0.1, Z: 0.1}}
+	o := gfx.NewObject()
+	o.Meshes = []*gfx.Mesh{m}
+	o.Shader = shaders.New(shaders.Reflective, 0)
+	o.Textures = []*gfx.Texture{gfx.NewTexture()}
+
+	// Where the rooms overlap, both probes are blended evenly.
+	o.SetPos(lmath.Vec3{X: 9.75, Y: 5, Z: 2})
+	s.Apply(o)
+	probes := o.Shader.Inputs["Probes"].([]gfx.Vec4)
+	if len(o.Textures) != 3 || o.Textures[1] != a.Texture || o.Textures[2] != b.Texture {
+		t.Fatal("wrong probe textures")
+	}
+	if probes[2].W != 0.5 || probes[5].W != 0.5 || probes[0].W != 0 || probes[3].W != 1 {
+		t.Fatalf("got probes %v", probes)
+	}
+
+	// Within the first room, only it's probe is reflected.
+	o.SetPos(lmath.Vec3{X: 2, Y: 5, Z: 2})
+	o.CachedBounds = nil
+	s.Apply(o)
+	probes = o.Shader.Inputs["Probes"].([]gfx.Vec4)
+	if o.Textures[1] != a.Texture || o.Textures[2] != a.Texture || probes[2].W != 1 || probes[5].W != 0 {
+		t.Fatalf("got probes %v", probes)
+	}
+
+	// Outside of every room, nothing is reflected.
+	o.SetPos(lmath.Vec3{X: 50})
+	s.Apply(o)
+	probes = o.Shader.Inputs["Probes"].([]gfx.Vec4)
+	if o.Textures[1] != o.Textures[0] || probes[2].W != 0 || probes[5].W != 0 {
+		t.Fatalf("got probes %v", probes)
+	}
+}
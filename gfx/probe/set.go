@@ -0,0 +1,88 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package probe
+
+import (
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/lmath"
+)
+
+// Set is the set of probes of a scene.
+type Set struct {
+	Probes []*Probe
+}
+
+// nearest returns the two probes with the greatest influence at the given
+// point and their weights. Either probe may be nil (with a zero weight) if
+// there are fewer probes influencing the point. The weights of two probes
+// are normalized, such that only a single probe fades out.
+func (s *Set) nearest(point lmath.Vec3) (a, b *Probe, wa, wb float64) {
+	for _, p := range s.Probes {
+		w := p.Weight(point)
+		switch {
+		case w <= 0 || p.Texture == nil:
+		case w > wa:
+			b, wb = a, wa
+			a, wa = p, w
+		case w > wb:
+			b, wb = p, w
+		}
+	}
+	if b != nil {
+		sum := wa + wb
+		wa, wb = wa/sum, wb/sum
+	}
+	return
+}
+
+// Apply sets the second and third textures and the inputs of the given
+// object, drawn with the shaders.Reflective shader, to the two probes with
+// the greatest influence at the center of it's bounds. Where no probe has any
+// influence, the object reflects nothing.
+//
+// The inputs differ for each object, as such each object must have a shader
+// of it's own (see gfx.Shader.Copy). Apply must be called again after the
+// object or the probes are moved.
+func (s *Set) Apply(o *gfx.Object) {
+	a, b, wa, wb := s.nearest(o.Bounds().Center())
+
+	// Unused texture slots are filled with another texture, which is not
+	// sampled.
+	for len(o.Textures) < 3 {
+		o.Textures = append(o.Textures, nil)
+	}
+	o.Textures[1], o.Textures[2] = o.Textures[0], o.Textures[0]
+	if a != nil {
+		o.Textures[1], o.Textures[2] = a.Texture, a.Texture
+	}
+	if b != nil {
+		o.Textures[2] = b.Texture
+	}
+
+	probes := make([]gfx.Vec4, 6)
+	encode(probes[:3], a, wa)
+	encode(probes[3:], b, wb)
+	o.Shader.Inputs["Probes"] = probes
+}
+
+// encode encodes the given probe (which may be nil) with the given weight
+// into three vectors of the shader's Probes array.
+func encode(dst []gfx.Vec4, p *Probe, weight float64) {
+	if p == nil {
+		return
+	}
+	flip := 0.0
+	if p.Flipped {
+		flip = 1
+	}
+	dst[0] = vec4(p.Box.Min, flip)
+	dst[1] = vec4(p.Box.Max, 0)
+	dst[2] = vec4(p.Position, weight)
+}
+
+// vec4 returns a gfx.Vec4 with the given XYZ components and W component.
+func vec4(v lmath.Vec3, w float64) gfx.Vec4 {
+	return gfx.Vec4{X: float32(v.X), Y: float32(v.Y), Z: float32(v.Z), W: float32(w)}
+}
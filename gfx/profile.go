@@ -0,0 +1,71 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import "time"
+
+// PassStats represents statistics about a single named render pass.
+type PassStats struct {
+	// The name of the pass, as given to Profiler.BeginPass.
+	Name string
+
+	// GPU is the amount of time the GPU spent executing the pass. If timer
+	// queries are not supported (see DeviceInfo.TimerQuery) it is zero.
+	GPU time.Duration
+
+	// The number of draw calls issued during the pass.
+	DrawCalls int
+
+	// The number of graphics state changes made during the pass (i.e. those
+	// which the device could not avoid). State changes include object
+	// bindings and shader input uploads.
+	StateChanges int
+
+	// The number of redundant graphics state changes which the device avoided
+	// during the pass, because the state was already set.
+	SkippedChanges int
+}
+
+// FrameStats represents statistics about a single rendered frame.
+type FrameStats struct {
+	// Each named pass that was rendered during the frame, in the order that
+	// they began.
+	Passes []PassStats
+
+	// GPU is the sum of the GPU time of each pass.
+	GPU time.Duration
+
+	// The total number of draw calls, state changes and skipped state changes
+	// made during the frame, including ones made outside of any named pass.
+	DrawCalls, StateChanges, SkippedChanges int
+}
+
+// Profiler is an optional interface that a Device may implement in order to
+// expose per-pass GPU timing information. Passes are not nested, beginning a
+// new pass implicitly ends the current one:
+//
+//  if p, ok := d.(gfx.Profiler); ok {
+//      p.BeginPass("shadows")
+//      ... draw shadow casters ...
+//      p.BeginPass("scene")
+//      ... draw the scene ...
+//      p.EndPass()
+//  }
+//
+// Like the Device interface, a profiler's methods are safe to call from
+// multiple goroutines concurrently.
+type Profiler interface {
+	// BeginPass begins a new named pass, ending the current one (if any).
+	BeginPass(name string)
+
+	// EndPass ends the current pass. If there is no current pass, it is
+	// no-op.
+	EndPass()
+
+	// FrameStats returns statistics about the most recent frame whose results
+	// are available. As GPU timing results are only available some time after
+	// the frame is rendered, they typically lag behind by a frame or two.
+	FrameStats() FrameStats
+}
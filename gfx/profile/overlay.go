@@ -0,0 +1,245 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package profile
+
+import (
+	"image"
+	"strings"
+	"time"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/lmath"
+	"azul3d.org/engine/text"
+)
+
+var vertShader = []byte(`
+#version 120
+
+attribute vec3 Vertex;
+attribute vec4 Color;
+
+varying vec4 color;
+
+void main(void) {
+	gl_Position = vec4(Vertex.xy, 0.0, 1.0);
+	color = Color;
+}
+`)
+
+var fragShader = []byte(`
+#version 120
+
+varying vec4 color;
+
+void main(void) {
+	gl_FragColor = color;
+}
+`)
+
+var shader *gfx.Shader
+
+func init() {
+	shader = gfx.NewShader("profile-overlay")
+	shader.GLSL = &gfx.GLSLSources{
+		Vertex:   vertShader,
+		Fragment: fragShader,
+	}
+}
+
+var (
+	// The colors used for bars, in order.
+	barColors = []gfx.Color{
+		{R: 0.2, G: 0.6, B: 1, A: 0.9},
+		{R: 0.3, G: 0.9, B: 0.4, A: 0.9},
+		{R: 1, G: 0.8, B: 0.2, A: 0.9},
+		{R: 0.8, G: 0.4, B: 1, A: 0.9},
+		{R: 0.2, G: 0.9, B: 0.9, A: 0.9},
+	}
+
+	// The color of bars which exceed the budget.
+	overBudgetColor = gfx.Color{R: 1, G: 0.2, B: 0.2, A: 0.9}
+
+	// The background color of the overlay.
+	backgroundColor = gfx.Color{R: 0, G: 0, B: 0, A: 0.6}
+
+	// The color of the labels of the overlay.
+	labelColor = gfx.Color{R: 1, G: 1, B: 1, A: 1}
+)
+
+// bar is a single bar of the overlay.
+type bar struct {
+	start, duration time.Duration
+}
+
+// Overlay draws frame statistics as on-screen horizontal bars, with one row
+// for each line of Report: the CPU frame, each CPU section (positioned
+// according to when it began during the frame), the GPU frame and each GPU
+// pass. Bars which exceed the budget are drawn in red.
+//
+// If the overlay has a font, each bar is labeled with it's line of Report,
+// i.e. the milliseconds spent, and for the GPU the number of draw calls and
+// state changes.
+//
+// An overlay and it's methods are not safe for access from multiple goroutines
+// concurrently.
+type Overlay struct {
+	// The object which is drawn. You should not modify it's meshes directly.
+	*gfx.Object
+
+	// Budget is the frame time which spans the full width of the overlay,
+	// e.g. 16ms for 60 FPS.
+	Budget time.Duration
+
+	// The width and height in pixels of the overlay's bars. If the overlay
+	// has a font, the line height of the font is used as the height instead.
+	Width, BarHeight int
+
+	mesh   *gfx.Mesh
+	bars   []bar
+	report string
+	labels *text.Text
+}
+
+// Update updates the overlay to display the given CPU frame and GPU frame
+// statistics.
+func (o *Overlay) Update(cpu Frame, gpu gfx.FrameStats) {
+	o.bars = append(o.bars[:0], bar{0, cpu.Duration})
+	for _, s := range cpu.Sections {
+		o.bars = append(o.bars, bar{s.Start, s.Duration})
+	}
+	o.bars = append(o.bars, bar{0, gpu.GPU})
+	var start time.Duration
+	for _, p := range gpu.Passes {
+		o.bars = append(o.bars, bar{start, p.GPU})
+		start += p.GPU
+	}
+	if o.labels != nil {
+		o.report = strings.TrimSuffix(Report(cpu, gpu), "\n")
+	}
+}
+
+// addRect adds a rectangle, given in pixels relative to the bounds b, to the
+// mesh.
+func (o *Overlay) addRect(b, r image.Rectangle, c gfx.Color) {
+	ndc := func(x, y int) gfx.Vec3 {
+		return gfx.Vec3{
+			X: 2*float32(x-b.Min.X)/float32(b.Dx()) - 1,
+			Y: 1 - 2*float32(y-b.Min.Y)/float32(b.Dy()),
+		}
+	}
+	bl, br := ndc(r.Min.X, r.Max.Y), ndc(r.Max.X, r.Max.Y)
+	tl, tr := ndc(r.Min.X, r.Min.Y), ndc(r.Max.X, r.Min.Y)
+	o.mesh.Vertices = append(o.mesh.Vertices, bl, br, tl, tl, br, tr)
+	for i := 0; i < 6; i++ {
+		o.mesh.Colors = append(o.mesh.Colors, c)
+	}
+}
+
+// Draw draws the overlay onto the given canvas, at the top-left corner of the
+// given rectangle. The labels of an overlay with a font are drawn using the
+// given orthographic camera, whose view should be the bounds of the canvas
+// (see camera.NewOrtho); it may be nil if the overlay has no font.
+func (o *Overlay) Draw(c gfx.Canvas, r image.Rectangle, cam gfx.Camera) {
+	if len(o.bars) == 0 || o.Budget <= 0 {
+		return
+	}
+	b := c.Bounds()
+	o.mesh.Vertices = o.mesh.Vertices[:0]
+	o.mesh.Colors = o.mesh.Colors[:0]
+
+	// The labels, to the right of the bars, with one line for each row.
+	rowHeight, width := o.BarHeight, o.Width
+	if o.labels != nil {
+		f := o.labels.Font
+		rowHeight = f.LineHeight
+		pad := f.LineHeight / 2
+		if o.labels.String() != o.report {
+			o.labels.Set(o.report)
+		}
+		width += pad + f.Measure(o.report).X + pad
+
+		// The text is laid out in the X/Z plane with +Z up, and it's origin
+		// at the baseline of the first line.
+		o.labels.Transform.SetPos(lmath.Vec3{
+			X: float64(r.Min.X - b.Min.X + o.Width + pad),
+			Z: float64(b.Max.Y - r.Min.Y - f.Base),
+		})
+	}
+
+	// Background.
+	bg := image.Rect(0, 0, width, rowHeight*len(o.bars)).Add(r.Min)
+	o.addRect(b, bg, backgroundColor)
+
+	// Bars.
+	scale := float64(o.Width) / float64(o.Budget)
+	for i, br := range o.bars {
+		x0 := int(float64(br.start) * scale)
+		x1 := int(float64(br.start+br.duration) * scale)
+		if x1 == x0 {
+			x1++
+		}
+		col := barColors[i%len(barColors)]
+		if br.start+br.duration > o.Budget {
+			col = overBudgetColor
+			if x1 > o.Width {
+				x1 = o.Width
+			}
+		}
+		y := i * rowHeight
+		rect := image.Rect(x0, y+1, x1, y+rowHeight-1).Add(r.Min)
+		o.addRect(b, rect, col)
+	}
+
+	o.mesh.VerticesChanged = true
+	o.mesh.ColorsChanged = true
+	o.mesh.AABB = lmath.Rect3{}
+	o.Object.CachedBounds = nil
+	c.Draw(r, o.Object, nil)
+	if o.labels != nil {
+		for _, l := range o.labels.Objects {
+			c.Draw(r, l, cam)
+		}
+	}
+}
+
+// Destroy destroys the object of the overlay, and those of it's labels.
+func (o *Overlay) Destroy() {
+	o.Object.Destroy()
+	if o.labels != nil {
+		for _, l := range o.labels.Objects {
+			l.Destroy()
+		}
+	}
+}
+
+// NewOverlay returns a new overlay with a budget of 16ms, 256 pixels wide with
+// 8 pixel tall bars. If the given font is non-nil, the bars are labeled using
+// it.
+func NewOverlay(f *text.Font) *Overlay {
+	m := gfx.NewMesh()
+	m.Dynamic = true
+	m.KeepDataOnLoad = true
+
+	obj := gfx.NewObject()
+	obj.State = gfx.NewState()
+	obj.State.DepthTest = false
+	obj.State.DepthWrite = false
+	obj.State.AlphaMode = gfx.AlphaBlend
+	obj.Shader = shader
+	obj.Meshes = []*gfx.Mesh{m}
+
+	o := &Overlay{
+		Object:    obj,
+		Budget:    16 * time.Millisecond,
+		Width:     256,
+		BarHeight: 8,
+		mesh:      m,
+	}
+	if f != nil {
+		o.labels = text.New(f, nil)
+		o.labels.Color = labelColor
+	}
+	return o
+}
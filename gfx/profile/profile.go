@@ -0,0 +1,158 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package profile implements a CPU frame profiler and an on-screen overlay for
+// frame statistics.
+//
+// The CPU profiler measures the time spent in named (and possibly nested)
+// sections of each frame:
+//
+//  prof := profile.New()
+//  for {
+//      prof.Begin("physics")
+//      ...
+//      prof.End()
+//
+//      prof.Begin("render")
+//      ...
+//      prof.End()
+//      prof.EndFrame()
+//  }
+//
+// The GPU side of profiling is exposed by devices which implement the
+// gfx.Profiler interface. Both may be visualized using an Overlay, or printed
+// using Report.
+package profile // import "azul3d.org/engine/gfx/profile"
+
+import (
+	"sync"
+	"time"
+)
+
+// Section represents the time spent in a single named section of a frame.
+type Section struct {
+	// The name of the section, as given to Profiler.Begin.
+	Name string
+
+	// The depth of the section, zero for top-level sections, one for sections
+	// begun inside a top-level section, etc.
+	Depth int
+
+	// The time at which the section began, relative to the start of the
+	// frame.
+	Start time.Duration
+
+	// The amount of time spent in the section.
+	Duration time.Duration
+}
+
+// Frame represents the sections of a single frame.
+type Frame struct {
+	// Each section of the frame, in the order that they began.
+	Sections []Section
+
+	// The total duration of the frame.
+	Duration time.Duration
+}
+
+// Profiler is a CPU frame profiler. It is safe to call it's methods from
+// multiple goroutines concurrently, but because sections are nested the Begin
+// and End methods should generally only be called from a single goroutine.
+type Profiler struct {
+	access sync.Mutex
+
+	// The time that the current frame started.
+	frameStart time.Time
+
+	// The current frame's sections, and the indices of the currently open
+	// ones.
+	cur  []Section
+	open []int
+
+	// The last complete frame.
+	last Frame
+}
+
+// Begin begins a new named section. Sections may be nested by calling Begin
+// again before a call to End.
+func (p *Profiler) Begin(name string) {
+	p.access.Lock()
+	p.open = append(p.open, len(p.cur))
+	p.cur = append(p.cur, Section{
+		Name:  name,
+		Depth: len(p.open) - 1,
+		Start: time.Since(p.frameStart),
+	})
+	p.access.Unlock()
+}
+
+// End ends the most recently begun section. If there is no open section, it
+// is no-op.
+func (p *Profiler) End() {
+	p.access.Lock()
+	p.end()
+	p.access.Unlock()
+}
+
+// end is the lock-free implementation of End.
+func (p *Profiler) end() {
+	if len(p.open) == 0 {
+		return
+	}
+	i := p.open[len(p.open)-1]
+	p.open = p.open[:len(p.open)-1]
+	p.cur[i].Duration = time.Since(p.frameStart) - p.cur[i].Start
+}
+
+// Record records a section which was measured elsewhere, such as a job run on
+// another goroutine, given the times at which it began and ended. It is added
+// as a child of the innermost open section, if any. Unlike Begin and End it
+// may be called from any goroutine.
+func (p *Profiler) Record(name string, start, end time.Time) {
+	p.access.Lock()
+	begin := start.Sub(p.frameStart)
+	if begin < 0 {
+		begin = 0
+	}
+	p.cur = append(p.cur, Section{
+		Name:     name,
+		Depth:    len(p.open),
+		Start:    begin,
+		Duration: end.Sub(p.frameStart) - begin,
+	})
+	p.access.Unlock()
+}
+
+// EndFrame ends the current frame, ending any open sections, such that it is
+// returned by Last. A new frame is begun immediately.
+func (p *Profiler) EndFrame() {
+	p.access.Lock()
+	for len(p.open) > 0 {
+		p.end()
+	}
+	now := time.Now()
+	p.last = Frame{
+		Sections: p.cur,
+		Duration: now.Sub(p.frameStart),
+	}
+	p.cur = nil
+	p.frameStart = now
+	p.access.Unlock()
+}
+
+// Last returns the last complete frame, i.e. the one ended by the most recent
+// call to EndFrame.
+func (p *Profiler) Last() Frame {
+	p.access.Lock()
+	f := p.last
+	p.access.Unlock()
+	return f
+}
+
+// New returns a new CPU frame profiler. The first frame begins immediately.
+func New() *Profiler {
+	return &Profiler{
+		frameStart: time.Now(),
+	}
+}
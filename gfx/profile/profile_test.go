@@ -0,0 +1,136 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package profile
+
+import (
+	"image"
+	"strings"
+	"testing"
+	"time"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/text"
+)
+
+func TestProfiler(t *testing.T) {
+	p := New()
+	p.Begin("a")
+	p.Begin("b")
+	time.Sleep(time.Millisecond)
+	p.End()
+	p.End()
+	p.Begin("c")
+	p.EndFrame()
+
+	f := p.Last()
+	if len(f.Sections) != 3 {
+		t.Fatalf("got %d sections, want 3", len(f.Sections))
+	}
+	for i, want := range []Section{{Name: "a", Depth: 0}, {Name: "b", Depth: 1}, {Name: "c", Depth: 0}} {
+		s := f.Sections[i]
+		if s.Name != want.Name || s.Depth != want.Depth {
+			t.Fatalf("section %d: got %q depth %d, want %q depth %d", i, s.Name, s.Depth, want.Name, want.Depth)
+		}
+	}
+	a, b := f.Sections[0], f.Sections[1]
+	if b.Duration < time.Millisecond || a.Duration < b.Duration {
+		t.Fatalf("unexpected durations a=%v b=%v", a.Duration, b.Duration)
+	}
+	if f.Duration < a.Duration {
+		t.Fatalf("frame duration %v less than section duration %v", f.Duration, a.Duration)
+	}
+
+	// A new frame must have begun.
+	p.EndFrame()
+	if n := len(p.Last().Sections); n != 0 {
+		t.Fatalf("got %d sections in empty frame, want 0", n)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	p := New()
+	p.Begin("cull")
+	start := time.Now()
+	time.Sleep(time.Millisecond)
+	p.Record("job", start, time.Now())
+	p.End()
+	p.EndFrame()
+
+	f := p.Last()
+	if len(f.Sections) != 2 {
+		t.Fatalf("got %d sections, want 2", len(f.Sections))
+	}
+	if s := f.Sections[1]; s.Name != "job" || s.Depth != 1 || s.Duration < time.Millisecond {
+		t.Fatalf("got section %+v", s)
+	}
+}
+
+func TestReport(t *testing.T) {
+	cpu := Frame{
+		Sections: []Section{{Name: "render", Duration: 2 * time.Millisecond}},
+		Duration: 3 * time.Millisecond,
+	}
+	gpu := gfx.FrameStats{
+		Passes:    []gfx.PassStats{{Name: "scene", GPU: time.Millisecond, DrawCalls: 5}},
+		GPU:       time.Millisecond,
+		DrawCalls: 5,
+	}
+	r := Report(cpu, gpu)
+	for _, want := range []string{"cpu 3.00ms", "  render 2.00ms", "  scene 1.00ms, 5 draws"} {
+		if !strings.Contains(r, want) {
+			t.Fatalf("report missing %q:\n%s", want, r)
+		}
+	}
+}
+
+func TestOverlay(t *testing.T) {
+	p := New()
+	p.Begin("render")
+	p.End()
+	p.EndFrame()
+
+	gpu := gfx.FrameStats{
+		Passes: []gfx.PassStats{{Name: "scene", GPU: 20 * time.Millisecond}},
+	}
+	o := NewOverlay(nil)
+	o.Update(p.Last(), gpu)
+
+	d := gfx.Nil()
+	o.Draw(d, d.Bounds(), nil)
+
+	// Background plus four bars (CPU frame, section, GPU frame and pass), two
+	// triangles each.
+	if got, want := len(o.mesh.Vertices), 5*6; got != want {
+		t.Fatalf("got %d vertices, want %d", got, want)
+	}
+
+	// With a font, each bar is labeled with it's line of the report.
+	page := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	f := &text.Font{
+		LineHeight: 10,
+		Base:       8,
+		PageSize:   page.Bounds().Size(),
+		Images:     []image.Image{page},
+		Glyphs: map[rune]*text.Glyph{
+			'?': {Rune: '?', Rect: page.Bounds(), Advance: 6},
+		},
+	}
+	o = NewOverlay(f)
+	o.Update(p.Last(), gpu)
+	o.Draw(d, d.Bounds(), nil)
+	report := Report(p.Last(), gpu)
+	if got := o.labels.String(); got+"\n" != report {
+		t.Fatalf("got labels %q, want %q", got, report)
+	}
+	if n := strings.Count(report, "\n"); n != len(o.bars) {
+		t.Fatalf("%d report lines for %d bars", n, len(o.bars))
+	}
+
+	// The rows are as tall as the lines of the font.
+	height := float32(f.LineHeight*len(o.bars)) / float32(d.Bounds().Dy())
+	if bottom := o.mesh.Vertices[0].Y; bottom != 1-2*height {
+		t.Fatalf("background bottom at %v, want %v", bottom, 1-2*height)
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package profile
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"azul3d.org/engine/gfx"
+)
+
+// ms returns the given duration in milliseconds.
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// Report returns a human-readable, multi-line report of the given CPU frame
+// and GPU frame statistics. For example:
+//
+//  cpu 16.21ms
+//    physics 2.10ms
+//    render 13.80ms
+//      cull 1.20ms
+//  gpu 9.61ms, 120 draws, 310 state changes (540 skipped)
+//    shadows 3.02ms, 40 draws, 85 state changes (130 skipped)
+//    scene 6.59ms, 80 draws, 220 state changes (410 skipped)
+//
+func Report(cpu Frame, gpu gfx.FrameStats) string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "cpu %.2fms\n", ms(cpu.Duration))
+	for _, s := range cpu.Sections {
+		indent := strings.Repeat("  ", s.Depth+1)
+		fmt.Fprintf(&buf, "%s%s %.2fms\n", indent, s.Name, ms(s.Duration))
+	}
+	fmt.Fprintf(&buf, "gpu %.2fms, %d draws, %d state changes (%d skipped)\n", ms(gpu.GPU), gpu.DrawCalls, gpu.StateChanges, gpu.SkippedChanges)
+	for _, p := range gpu.Passes {
+		fmt.Fprintf(&buf, "  %s %.2fms, %d draws, %d state changes (%d skipped)\n", p.Name, ms(p.GPU), p.DrawCalls, p.StateChanges, p.SkippedChanges)
+	}
+	return buf.String()
+}
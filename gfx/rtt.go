@@ -5,7 +5,6 @@
 package gfx
 
 import (
-	"fmt"
 	"image"
 	"sort"
 )
@@ -208,6 +207,11 @@ func (s chooseTexFormats) Less(ii, jj int) bool {
 	// Choose the closest.
 	iDist := absInt(pb - i)
 	jDist := absInt(pb - j)
+	if iDist == jDist {
+		// Prefer linear formats over sRGB ones, which must be explicitly
+		// requested.
+		return !s.s[ii].IsSRGB() && s.s[jj].IsSRGB()
+	}
 	return iDist < jDist
 }
 
@@ -268,9 +272,6 @@ func (f RTTFormats) Choose(p Precision, compression bool) (color TexFormat, dept
 		}
 		sort.Sort(depths)
 		depth = depths.s[0]
-		for _, d := range depths.s {
-			fmt.Println(d)
-		}
 	}
 	if (p.StencilBits > 0) && len(f.StencilFormats) > 0 {
 		stencils := chooseDSFormats{
@@ -0,0 +1,17 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import "testing"
+
+func TestRTTFormatsChoosePrefersLinear(t *testing.T) {
+	f := RTTFormats{
+		ColorFormats: []TexFormat{SRGBA, RGB, RGBA},
+	}
+	color, _, _ := f.Choose(Precision{RedBits: 8, GreenBits: 8, BlueBits: 8, AlphaBits: 8}, false)
+	if color != RGBA {
+		t.Fatalf("got %v, want RGBA", color)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+// Sampler represents the state used when sampling a texture: it's wrap modes
+// and filtering.
+//
+// Every texture has it's own sampling state (see the fields of Texture of the
+// same names), which is used by default. A sampler allows drawing the same
+// texture with different sampling state in different draws, without copying
+// the texture, see Object.Samplers.
+//
+// Devices map samplers to native sampler objects when the graphics hardware
+// supports them, otherwise they are emulated by changing the texture's state
+// before each draw.
+type Sampler struct {
+	// The U and V wrap modes.
+	WrapU, WrapV TexWrap
+
+	// The color of the border when a wrap mode is set to BorderColor.
+	BorderColor Color
+
+	// The texture filtering used for minification and magnification. Note
+	// that mipmaps are only available if the texture itself was loaded with a
+	// mipmapped MinFilter.
+	MinFilter, MagFilter TexFilter
+
+	// The maximum degree of anisotropic filtering, see Texture.MaxAnisotropy.
+	MaxAnisotropy float32
+
+	// The level of detail bias, see Texture.LODBias.
+	LODBias float32
+}
+
+// Sampler returns the sampling state of this texture.
+func (t *Texture) Sampler() Sampler {
+	return Sampler{
+		WrapU:         t.WrapU,
+		WrapV:         t.WrapV,
+		BorderColor:   t.BorderColor,
+		MinFilter:     t.MinFilter,
+		MagFilter:     t.MagFilter,
+		MaxAnisotropy: t.MaxAnisotropy,
+		LODBias:       t.LODBias,
+	}
+}
+
+// SetSampler sets the sampling state of this texture to the given one.
+func (t *Texture) SetSampler(s Sampler) {
+	t.WrapU = s.WrapU
+	t.WrapV = s.WrapV
+	t.BorderColor = s.BorderColor
+	t.MinFilter = s.MinFilter
+	t.MagFilter = s.MagFilter
+	t.MaxAnisotropy = s.MaxAnisotropy
+	t.LODBias = s.LODBias
+}
@@ -0,0 +1,36 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import "testing"
+
+func TestTextureSampler(t *testing.T) {
+	s := Sampler{
+		WrapU:         Repeat,
+		WrapV:         Mirror,
+		MinFilter:     LinearMipmapLinear,
+		MagFilter:     Nearest,
+		MaxAnisotropy: 16,
+		LODBias:       -0.5,
+	}
+	tex := NewTexture()
+	tex.SetSampler(s)
+	if got := tex.Sampler(); got != s {
+		t.Fatalf("got %+v want %+v", got, s)
+	}
+}
+
+func TestObjectCopySamplers(t *testing.T) {
+	o := NewObject()
+	o.Bounds() // Copy requires cached bounds.
+	o.Samplers = []*Sampler{nil, {MinFilter: Linear}}
+	cpy := o.Copy()
+	if len(cpy.Samplers) != 2 || cpy.Samplers[1] != o.Samplers[1] {
+		t.Fatal("samplers not copied")
+	}
+	if !o.Compare(cpy) {
+		t.Fatal("copy should compare equal")
+	}
+}
@@ -4,7 +4,14 @@
 
 package gfx
 
-import "sync"
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"azul3d.org/engine/lmath"
+)
 
 // Shader represents a single shader program.
 //
@@ -55,15 +62,163 @@ type Shader struct {
 	//  gfx.TexCoord
 	//  []gfx.TexCoord
 	//
+	// The 32-bit lmath types (lmath.Vec2f, Vec3f, Vec4f, Quatf, and Mat4f) and
+	// slices of them are accepted as well, which avoids converting from the
+	// 64-bit lmath types each frame.
+	//
+	// See the SetInput method, which validates inputs against the active
+	// uniforms of the loaded shader.
 	Inputs map[string]interface{}
 
 	// The error log from compiling the shader program, if any. Only set once
 	// the shader is loaded.
 	Error []byte
+
+	// The active uniform and attribute variables of the shader program, as
+	// reported by the device after linking. Only set once the shader is
+	// loaded, and only by devices which support reflection (otherwise nil).
+	Uniforms, Attributes []ShaderVar
+}
+
+// ShaderVar describes a single active variable (e.g. a uniform) of a shader
+// program.
+type ShaderVar struct {
+	// The name of the variable, e.g. "Color". For arrays the name excludes
+	// any index suffix (i.e. "Lights" not "Lights[0]").
+	Name string
+
+	// The GLSL type of the variable, e.g. "vec3" or "sampler2D".
+	Type string
+
+	// The number of elements, one for non-array variables.
+	Size int
+}
+
+// String returns a string representation of the variable, e.g. "vec3 Color"
+// or "float Weights[4]".
+func (v ShaderVar) String() string {
+	if v.Size > 1 {
+		return fmt.Sprintf("%s %s[%d]", v.Type, v.Name, v.Size)
+	}
+	return v.Type + " " + v.Name
+}
+
+// Uniform returns the active uniform variable with the given name, and
+// whether or not it was found.
+func (s *Shader) Uniform(name string) (ShaderVar, bool) {
+	for _, v := range s.Uniforms {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return ShaderVar{}, false
+}
+
+// inputTypes maps GLSL types to the types of values that may be used as
+// inputs for them, and whether or not they are slice types.
+var inputTypes = map[string][]struct {
+	value interface{}
+	slice bool
+}{
+	"bool":  {{false, false}},
+	"float": {{float32(0), false}, {[]float32(nil), true}},
+	"vec2":  {{TexCoord{}, false}, {[]TexCoord(nil), true}, {lmath.Vec2f{}, false}, {[]lmath.Vec2f(nil), true}},
+	"vec3":  {{Vec3{}, false}, {[]Vec3(nil), true}, {lmath.Vec3f{}, false}, {[]lmath.Vec3f(nil), true}},
+	"vec4": {
+		{Vec4{}, false}, {[]Vec4(nil), true}, {Color{}, false}, {[]Color(nil), true},
+		{lmath.Vec4f{}, false}, {[]lmath.Vec4f(nil), true}, {lmath.Quatf{}, false}, {[]lmath.Quatf(nil), true},
+	},
+	"mat4": {{Mat4{}, false}, {[]Mat4(nil), true}, {lmath.Mat4f{}, false}, {[]lmath.Mat4f(nil), true}},
+}
+
+// inputLen returns the number of elements of the given slice input value.
+func inputLen(value interface{}) int {
+	switch v := value.(type) {
+	case []float32:
+		return len(v)
+	case []TexCoord:
+		return len(v)
+	case []Vec3:
+		return len(v)
+	case []Vec4:
+		return len(v)
+	case []Color:
+		return len(v)
+	case []Mat4:
+		return len(v)
+	case []lmath.Vec2f:
+		return len(v)
+	case []lmath.Vec3f:
+		return len(v)
+	case []lmath.Vec4f:
+		return len(v)
+	case []lmath.Quatf:
+		return len(v)
+	case []lmath.Mat4f:
+		return len(v)
+	}
+	return 1
+}
+
+// SetInput sets the named shader input to the given value (see the Inputs
+// field).
+//
+// If the shader is loaded and the device reported its active uniforms (see
+// the Uniforms field), the input is first validated against them: an error is
+// returned if no active uniform has the given name (e.g. it was misspelled, or
+// optimized away by the compiler), if the value's type does not match the
+// uniform's GLSL type, or if a slice value has more elements than the uniform
+// array. In this case the input is left unchanged.
+func (s *Shader) SetInput(name string, value interface{}) error {
+	if s.Uniforms != nil {
+		if err := s.checkInput(name, value); err != nil {
+			return err
+		}
+	}
+	if s.Inputs == nil {
+		s.Inputs = make(map[string]interface{})
+	}
+	s.Inputs[name] = value
+	return nil
+}
+
+// checkInput validates the named input value against the active uniforms.
+func (s *Shader) checkInput(name string, value interface{}) error {
+	u, ok := s.Uniform(name)
+	if !ok {
+		names := make([]string, 0, len(s.Uniforms))
+		for _, v := range s.Uniforms {
+			if !strings.HasPrefix(v.Type, "sampler") && !strings.HasPrefix(v.Name, "gl_") {
+				names = append(names, v.Name)
+			}
+		}
+		sort.Strings(names)
+		return fmt.Errorf("shader %q: no active uniform %q (active uniforms: %s)", s.Name, name, strings.Join(names, ", "))
+	}
+	if strings.HasPrefix(u.Type, "sampler") {
+		return fmt.Errorf("shader %q: uniform %q is a %s; bind textures via Object.Textures instead", s.Name, name, u.Type)
+	}
+	var want []string
+	for _, t := range inputTypes[u.Type] {
+		if fmt.Sprintf("%T", t.value) == fmt.Sprintf("%T", value) {
+			if t.slice {
+				if n := inputLen(value); n > u.Size {
+					return fmt.Errorf("shader %q: uniform %q has %d elements, got %d", s.Name, name, u.Size, n)
+				}
+			}
+			return nil
+		}
+		want = append(want, fmt.Sprintf("%T", t.value))
+	}
+	if len(want) == 0 {
+		return fmt.Errorf("shader %q: uniform %s has a type unsupported as an input", s.Name, u)
+	}
+	return fmt.Errorf("shader %q: uniform %s cannot be set to %T (want %s)", s.Name, u, value, strings.Join(want, " or "))
 }
 
 // Copy returns a new copy of this Shader. Explicitly not copied over is the
-// native shader, the OnLoad slice, the Loaded status, and error log slice.
+// native shader, the OnLoad slice, the Loaded status, error log slice, and the
+// active uniforms and attributes.
 func (s *Shader) Copy() *Shader {
 	cpy := &Shader{
 		nil,   // Native shader -- not copied.
@@ -73,6 +228,8 @@ func (s *Shader) Copy() *Shader {
 		nil, // GLSL shader.
 		make(map[string]interface{}, len(s.Inputs)),
 		nil, // Error slice -- not copied.
+		nil, // Uniforms -- not copied.
+		nil, // Attributes -- not copied.
 	}
 	if s.GLSL != nil {
 		cpy.GLSL = s.GLSL.Copy()
@@ -107,6 +264,8 @@ func (s *Shader) Reset() {
 		delete(s.Inputs, k)
 	}
 	s.Error = s.Error[:0]
+	s.Uniforms = nil
+	s.Attributes = nil
 }
 
 // Destroy destroys this shader for use by other callees to NewShader. You must
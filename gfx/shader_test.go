@@ -0,0 +1,52 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"testing"
+
+	"azul3d.org/engine/lmath"
+)
+
+func TestShaderSetInput(t *testing.T) {
+	s := NewShader("test")
+
+	// Without reflection, any input is accepted.
+	if err := s.SetInput("Anything", "string"); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Uniforms = []ShaderVar{
+		{Name: "Color", Type: "vec4", Size: 1},
+		{Name: "Weights", Type: "float", Size: 4},
+		{Name: "Texture0", Type: "sampler2D", Size: 1},
+		{Name: "Bones", Type: "mat4", Size: 2},
+	}
+	for _, tst := range []struct {
+		name  string
+		value interface{}
+		ok    bool
+	}{
+		{"Color", Color{R: 1}, true},
+		{"Color", Vec4{X: 1}, true},
+		{"Colour", Color{R: 1}, false},
+		{"Color", Vec3{X: 1}, false},
+		{"Weights", []float32{1, 2, 3, 4}, true},
+		{"Weights", []float32{1, 2, 3, 4, 5}, false},
+		{"Texture0", float32(0), false},
+		{"Color", lmath.Vec4f{X: 1}, true},
+		{"Color", lmath.Vec3f{X: 1}, false},
+		{"Bones", []lmath.Mat4f{{}, {}}, true},
+		{"Bones", []lmath.Mat4f{{}, {}, {}}, false},
+	} {
+		err := s.SetInput(tst.name, tst.value)
+		if (err == nil) != tst.ok {
+			t.Errorf("SetInput(%q, %T) returned %v", tst.name, tst.value, err)
+		}
+	}
+	if _, ok := s.Inputs["Colour"]; ok {
+		t.Fatal("invalid input was set")
+	}
+}
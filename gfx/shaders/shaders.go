@@ -0,0 +1,296 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package shaders provides a library of ready-made shaders for common effects.
+//
+// Each shader is preprocessed (see package glsl) for the target backend, such
+// that it can be used without writing any GLSL:
+//
+//  obj.Shader = shaders.New(shaders.Unlit, glsl.GL2)
+//  obj.Textures = []*gfx.Texture{tex}
+//
+// All shaders use the standard uniforms and vertex attributes provided by the
+// device (MVP, BinaryAlpha, Vertex, TexCoord0, etc), as well as additional
+// inputs documented on each Kind, which must be set through the Inputs map of
+// the shader (or through the Attribs map of the mesh, for vertex attributes).
+//
+// Lighting is computed in linear space. For correct results, textures holding
+// colors should use the gfx.SRGB or gfx.SRGBA formats and the canvas should
+// have sRGB conversion enabled (see gfx.SRGBCanvas); input colors such as
+// LightColor should be given in linear space as well.
+package shaders // import "azul3d.org/engine/gfx/shaders"
+
+import (
+	"fmt"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/glsl"
+)
+
+// MaxBones is the maximum number of bones supported by the Skinned shader.
+const MaxBones = 32
+
+// The limits of the Clustered shader, see package light.
+const (
+	// MaxLights is the maximum number of lights.
+	MaxLights = 128
+
+	// The number of clusters along the X and Y axes of the screen, and along
+	// the depth of the view frustum.
+	ClustersX, ClustersY, ClustersZ = 16, 9, 24
+
+	// MaxClusterLights is the maximum number of lights in each cluster, a
+	// multiple of four.
+	MaxClusterLights = 32
+)
+
+// Kind is a kind of shader in the library.
+type Kind int
+
+const (
+	// Unlit draws the object's first texture without any lighting.
+	Unlit Kind = iota
+
+	// VertexColor draws the object's vertex colors without any lighting.
+	VertexColor
+
+	// BlinnPhong draws the object's first texture lit by a single directional
+	// light using the Blinn-Phong model. It requires the Normal vertex
+	// attribute (vec3), and the inputs:
+	//
+	//  LightDirection gfx.Vec3  -> world-space direction the light travels in
+	//  LightColor     gfx.Color -> color of the light
+	//  AmbientColor   gfx.Color -> color of the ambient light
+	//  Shininess      float32   -> specular exponent, e.g. 32
+	//
+	BlinnPhong
+
+	// NormalMapped is like BlinnPhong, but additionally perturbs the normals
+	// using a tangent-space normal map as the object's second texture. It
+	// requires the Tangent vertex attribute (vec3).
+	NormalMapped
+
+	// Skinned is like BlinnPhong, but additionally skins the vertices on the
+	// GPU. It requires the BoneIndices and BoneWeights vertex attributes
+	// (vec4, with up to four bones per vertex) and the input:
+	//
+	//  Bones []gfx.Mat4 -> bone matrices, at most MaxBones
+	//
+	Skinned
+
+	// Billboard draws the object's first texture onto a quad which always
+	// faces the camera. The quad's vertices should lie on the X/Z plane
+	// centered at the origin (i.e. facing the default camera), the object's
+	// position and scale are respected but it's rotation is not. See also
+	// package billboard.
+	Billboard
+
+	// Wireframe draws the edges of triangles. It requires the mesh's Bary
+	// slice to be set, and the inputs:
+	//
+	//  WireColor gfx.Color -> color of the edges
+	//  WireWidth float32   -> width of the edges in barycentric units, e.g. 0.02
+	//
+	Wireframe
+
+	// Terrain is like BlinnPhong, but blends four tiled layer textures (the
+	// second through fifth textures, e.g. grass, rock, dirt and snow) by the
+	// weights held in the red, green, blue and alpha channels of a splat map
+	// (the first texture) which spans the whole mesh, see package terrain.
+	// It additionally requires the input:
+	//
+	//  Tiling float32 -> number of times the layers repeat across the mesh
+	//
+	Terrain
+
+	// Decal draws the object's first texture modulated by it's vertex colors
+	// without any lighting, e.g. for decals faded out through the alpha of
+	// their vertex colors (see package decal).
+	Decal
+
+	// Clustered is like BlinnPhong, but lit by up to MaxLights point, spot
+	// and directional lights, culled per cluster of the view frustum by
+	// package light. The second texture holds the lights of each cluster and
+	// the optional third texture holds the cookies of spot lights, and it
+	// requires the inputs (all set by light.Clusters):
+	//
+	//  Lights        []gfx.Vec4 -> four vectors describing each light
+	//  ClusterParams gfx.Vec4   -> scale from the screen to clusters, and near plane
+	//  CookieTiles   float32    -> number of cookies in the third texture
+	//  AmbientColor  gfx.Color  -> color of the ambient light
+	//  Shininess     float32    -> specular exponent, e.g. 32
+	//
+	// Lights are looked up from uniform arrays with dynamic indices, which is
+	// not supported by GLSL ES fragment shaders; the glsl.GLES2 target is not
+	// supported.
+	Clustered
+
+	// Lightmapped draws the object's first texture modulated by the light
+	// baked into it's second texture, a lightmap sampled with the mesh's
+	// second texture coordinate set (see package lightmap).
+	Lightmapped
+
+	// Reflective is like BlinnPhong, but additionally reflects the cubemaps
+	// of up to two reflection probes (the second and third textures), blended
+	// by their weights and projected onto their boxes, see package probe. It
+	// additionally requires the inputs:
+	//
+	//  Probes       []gfx.Vec4 -> three vectors describing each probe (set by probe.Set)
+	//  Reflectivity float32    -> reflectance at normal incidence, e.g. 0.04
+	//
+	Reflective
+
+	// CylindricalBillboard is like Billboard, but the quad only turns about
+	// the object's Z axis to face the camera (e.g. for trees, which should
+	// stay upright). The quad's vertices may lie anywhere on the X/Z plane.
+	CylindricalBillboard
+
+	// Impostor is like CylindricalBillboard, but draws the frame of an
+	// impostor atlas (the object's first texture) captured from the
+	// direction nearest to the one that the object is viewed from, see
+	// billboard.Bake. It requires the input:
+	//
+	//  Frames float32 -> number of frames in the atlas, laid out horizontally
+	//
+	Impostor
+
+	// Velocity draws the motion of the object on the screen since the
+	// previous frame instead of it's color, into a velocity buffer (see
+	// package taa). Fragments behind the depth texture of the scene (the
+	// object's first texture, drawn using the same camera) are discarded.
+	// It requires the inputs:
+	//
+	//  PrevMVP gfx.Mat4 -> unjittered model-view-projection matrix of the previous frame
+	//  CurMVP  gfx.Mat4 -> unjittered model-view-projection matrix of this frame
+	//
+	// The motion is given in texture coordinates, and packed into 16 bits
+	// per axis: the red and green channels hold the high bytes, and the blue
+	// and alpha channels the low ones, of the X and Y motion mapped from the
+	// range of [-1, 1] to [1, 255]. Pixels without motion are left zero.
+	Velocity
+)
+
+// String returns the name of the kind, e.g. "BlinnPhong".
+func (k Kind) String() string {
+	switch k {
+	case Unlit:
+		return "Unlit"
+	case VertexColor:
+		return "VertexColor"
+	case BlinnPhong:
+		return "BlinnPhong"
+	case NormalMapped:
+		return "NormalMapped"
+	case Skinned:
+		return "Skinned"
+	case Billboard:
+		return "Billboard"
+	case Wireframe:
+		return "Wireframe"
+	case Terrain:
+		return "Terrain"
+	case Decal:
+		return "Decal"
+	case Clustered:
+		return "Clustered"
+	case Lightmapped:
+		return "Lightmapped"
+	case Reflective:
+		return "Reflective"
+	case CylindricalBillboard:
+		return "CylindricalBillboard"
+	case Impostor:
+		return "Impostor"
+	case Velocity:
+		return "Velocity"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// source returns the names of the vertex and fragment source files and the
+// macros to define for the kind.
+func (k Kind) source() (vert, frag string, defines map[string]string) {
+	switch k {
+	case Unlit:
+		return "basic.vert", "basic.frag", map[string]string{"TEXTURED": "1"}
+	case VertexColor:
+		return "basic.vert", "basic.frag", map[string]string{"VERTEX_COLOR": "1"}
+	case BlinnPhong:
+		return "lit.vert", "lit.frag", nil
+	case NormalMapped:
+		return "lit.vert", "lit.frag", map[string]string{"NORMAL_MAP": "1"}
+	case Skinned:
+		return "lit.vert", "lit.frag", map[string]string{
+			"SKINNED":   "1",
+			"MAX_BONES": fmt.Sprint(MaxBones),
+		}
+	case Billboard:
+		return "billboard.vert", "basic.frag", map[string]string{"TEXTURED": "1"}
+	case Wireframe:
+		return "basic.vert", "basic.frag", map[string]string{"WIREFRAME": "1"}
+	case Terrain:
+		return "lit.vert", "lit.frag", map[string]string{"SPLAT": "1"}
+	case Decal:
+		return "basic.vert", "basic.frag", map[string]string{
+			"TEXTURED":     "1",
+			"VERTEX_COLOR": "1",
+		}
+	case Clustered:
+		return "lit.vert", "lit.frag", map[string]string{
+			"CLUSTERED":      "1",
+			"MAX_LIGHTS":     fmt.Sprint(MaxLights),
+			"CLUSTERS_X":     fmt.Sprint(ClustersX),
+			"CLUSTERS_Y":     fmt.Sprint(ClustersY),
+			"CLUSTERS_Z":     fmt.Sprint(ClustersZ),
+			"CLUSTER_TEXELS": fmt.Sprint(MaxClusterLights / 4),
+		}
+	case Lightmapped:
+		return "basic.vert", "basic.frag", map[string]string{
+			"TEXTURED": "1",
+			"LIGHTMAP": "1",
+		}
+	case Reflective:
+		return "lit.vert", "lit.frag", map[string]string{"PROBES": "1"}
+	case CylindricalBillboard:
+		return "billboard.vert", "basic.frag", map[string]string{
+			"TEXTURED":    "1",
+			"CYLINDRICAL": "1",
+		}
+	case Impostor:
+		return "billboard.vert", "basic.frag", map[string]string{
+			"TEXTURED":    "1",
+			"CYLINDRICAL": "1",
+			"IMPOSTOR":    "1",
+		}
+	case Velocity:
+		return "velocity.vert", "velocity.frag", nil
+	}
+	panic(fmt.Sprintf("shaders: invalid kind %v", k))
+}
+
+// include reads the named source file of the library.
+func include(name string) ([]byte, error) {
+	src, ok := sources[name]
+	if !ok {
+		return nil, fmt.Errorf("no such file")
+	}
+	return []byte(src), nil
+}
+
+// New returns a new shader of the given kind, preprocessed for the given
+// target backend. It panics if the kind is invalid.
+func New(k Kind, t glsl.Target) *gfx.Shader {
+	vert, frag, defines := k.source()
+	pp := &glsl.Preprocessor{
+		Target:  t,
+		Include: include,
+		Defines: defines,
+	}
+	s, err := pp.Shader(k.String(), []byte(sources[vert]), []byte(sources[frag]))
+	if err != nil {
+		// Only possible with a bug in the library's sources.
+		panic(err)
+	}
+	return s
+}
@@ -0,0 +1,38 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shaders
+
+import (
+	"strings"
+	"testing"
+
+	"azul3d.org/engine/gfx/glsl"
+)
+
+func TestNew(t *testing.T) {
+	for k := Unlit; k <= Velocity; k++ {
+		for _, target := range []glsl.Target{glsl.GL2, glsl.GLES2, glsl.GL3} {
+			s := New(k, target)
+			if s.Name != k.String() {
+				t.Errorf("%v: got name %q", k, s.Name)
+			}
+			for _, src := range [][]byte{s.GLSL.Vertex, s.GLSL.Fragment} {
+				if strings.Contains(string(src), "#include") {
+					t.Errorf("%v %v: unexpanded #include", k, target)
+				}
+				if !strings.Contains(string(src), "void main(void)") {
+					t.Errorf("%v %v: missing main function", k, target)
+				}
+			}
+		}
+	}
+}
+
+func TestNewSkinned(t *testing.T) {
+	s := New(Skinned, glsl.GL2)
+	if !strings.Contains(string(s.GLSL.Vertex), "#define MAX_BONES 32\n") {
+		t.Fatalf("missing MAX_BONES define:\n%s", s.GLSL.Vertex)
+	}
+}
@@ -0,0 +1,451 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shaders
+
+// sources holds the GLSL source files of the shader library by name, they are
+// written in GLSL 1.20 style and preprocessed using package glsl.
+var sources = map[string]string{
+	"common.glsl": `
+#pragma once
+
+uniform mat4 Model;
+uniform mat4 View;
+uniform mat4 Projection;
+uniform mat4 MVP;
+uniform bool BinaryAlpha;
+
+// toMat3 returns the upper-left 3x3 portion of m (matrix-from-matrix
+// constructors are not available in GLSL ES 1.00).
+mat3 toMat3(mat4 m) {
+	return mat3(m[0].xyz, m[1].xyz, m[2].xyz);
+}
+`,
+
+	"basic.vert": `
+#include "common.glsl"
+
+attribute vec3 Vertex;
+#ifdef TEXTURED
+attribute vec2 TexCoord0;
+varying vec2 texCoord;
+#endif
+#ifdef LIGHTMAP
+attribute vec2 TexCoord1;
+varying vec2 lightmapCoord;
+#endif
+#ifdef VERTEX_COLOR
+attribute vec4 Color;
+varying vec4 color;
+#endif
+#ifdef WIREFRAME
+attribute vec3 Bary;
+varying vec3 bary;
+#endif
+
+void main(void) {
+#ifdef TEXTURED
+	texCoord = TexCoord0;
+#endif
+#ifdef LIGHTMAP
+	lightmapCoord = TexCoord1;
+#endif
+#ifdef VERTEX_COLOR
+	color = Color;
+#endif
+#ifdef WIREFRAME
+	bary = Bary;
+#endif
+	gl_Position = MVP * vec4(Vertex, 1.0);
+}
+`,
+
+	"billboard.vert": `
+#include "common.glsl"
+
+attribute vec3 Vertex;
+attribute vec2 TexCoord0;
+varying vec2 texCoord;
+#ifdef IMPOSTOR
+uniform float Frames;
+#endif
+
+void main(void) {
+	// Discard the rotation of the model-view matrix, keeping the object's
+	// position and scale, such that the quad always faces the camera.
+	vec4 center = View * Model * vec4(0.0, 0.0, 0.0, 1.0);
+	vec2 scale = vec2(length(Model[0].xyz), length(Model[2].xyz));
+	texCoord = TexCoord0;
+#ifdef CYLINDRICAL
+	// Only rotate about the object's Z axis, which stays upright on the
+	// screen.
+	vec3 up = normalize((View * vec4(Model[2].xyz, 0.0)).xyz);
+	vec3 right = normalize(cross(center.xyz, up));
+	vec3 offset = right * Vertex.x * scale.x + up * Vertex.z * scale.y;
+#else
+	vec3 offset = vec3(Vertex.x * scale.x, Vertex.z * scale.y, 0.0);
+#endif
+#ifdef IMPOSTOR
+	// Select the frame of the atlas captured from the direction nearest to
+	// the one that the object is viewed from, about it's Z axis.
+	mat3 v = toMat3(View);
+	vec3 eye = -(View[3].xyz * v);
+	vec3 d = eye - Model[3].xyz;
+	float angle = atan(dot(d, Model[1].xyz), dot(d, Model[0].xyz));
+	float frame = mod(floor(angle / 6.2831853 * Frames + 0.5), Frames);
+	texCoord.x = (texCoord.x + frame) / Frames;
+#endif
+	gl_Position = Projection * (center + vec4(offset, 0.0));
+}
+`,
+
+	"basic.frag": `
+#include "common.glsl"
+
+#ifdef TEXTURED
+uniform sampler2D Texture0;
+varying vec2 texCoord;
+#endif
+#ifdef LIGHTMAP
+uniform sampler2D Texture1;
+varying vec2 lightmapCoord;
+#endif
+#ifdef VERTEX_COLOR
+varying vec4 color;
+#endif
+#ifdef WIREFRAME
+uniform vec4 WireColor;
+uniform float WireWidth;
+varying vec3 bary;
+#endif
+
+void main(void) {
+	vec4 c = vec4(1.0);
+#ifdef TEXTURED
+	c *= texture2D(Texture0, texCoord);
+#endif
+#ifdef LIGHTMAP
+	c.rgb *= texture2D(Texture1, lightmapCoord).rgb;
+#endif
+#ifdef VERTEX_COLOR
+	c *= color;
+#endif
+#ifdef WIREFRAME
+	if (min(min(bary.x, bary.y), bary.z) > WireWidth) {
+		discard;
+	}
+	c *= WireColor;
+#endif
+	if (BinaryAlpha && c.a < 0.5) {
+		discard;
+	}
+	gl_FragColor = c;
+}
+`,
+
+	"lit.vert": `
+#include "common.glsl"
+
+attribute vec3 Vertex;
+attribute vec3 Normal;
+attribute vec2 TexCoord0;
+#ifdef NORMAL_MAP
+attribute vec3 Tangent;
+varying vec3 viewTangent;
+#endif
+#ifdef SKINNED
+attribute vec4 BoneIndices;
+attribute vec4 BoneWeights;
+uniform mat4 Bones[MAX_BONES];
+#endif
+
+varying vec3 viewPos;
+varying vec3 viewNormal;
+varying vec2 texCoord;
+#ifdef PROBES
+varying vec3 worldPos;
+#endif
+
+void main(void) {
+	vec4 pos = vec4(Vertex, 1.0);
+	vec3 normal = Normal;
+#ifdef NORMAL_MAP
+	vec3 tangent = Tangent;
+#endif
+
+#ifdef SKINNED
+	mat4 skin = BoneWeights.x * Bones[int(BoneIndices.x)];
+	skin += BoneWeights.y * Bones[int(BoneIndices.y)];
+	skin += BoneWeights.z * Bones[int(BoneIndices.z)];
+	skin += BoneWeights.w * Bones[int(BoneIndices.w)];
+	pos = skin * pos;
+	normal = toMat3(skin) * normal;
+#ifdef NORMAL_MAP
+	tangent = toMat3(skin) * tangent;
+#endif
+#endif
+
+	// Note: the normal matrix assumes the model is uniformly scaled.
+	mat4 modelView = View * Model;
+	mat3 normalMatrix = toMat3(modelView);
+	vec4 p = modelView * pos;
+	viewPos = p.xyz;
+	viewNormal = normalMatrix * normal;
+#ifdef NORMAL_MAP
+	viewTangent = normalMatrix * tangent;
+#endif
+	texCoord = TexCoord0;
+#ifdef PROBES
+	worldPos = (Model * pos).xyz;
+#endif
+	gl_Position = Projection * p;
+}
+`,
+
+	"lit.frag": `
+#include "common.glsl"
+
+uniform sampler2D Texture0;
+#ifdef NORMAL_MAP
+uniform sampler2D Texture1;
+varying vec3 viewTangent;
+#endif
+#ifdef SPLAT
+uniform sampler2D Texture1;
+uniform sampler2D Texture2;
+uniform sampler2D Texture3;
+uniform sampler2D Texture4;
+uniform float Tiling;
+#endif
+
+#ifdef CLUSTERED
+uniform sampler2D Texture1;
+uniform sampler2D Texture2;
+uniform vec4 Lights[MAX_LIGHTS * 4];
+uniform vec4 ClusterParams;
+uniform float CookieTiles;
+#else
+uniform vec3 LightDirection;
+uniform vec4 LightColor;
+#endif
+uniform vec4 AmbientColor;
+uniform float Shininess;
+
+varying vec3 viewPos;
+varying vec3 viewNormal;
+varying vec2 texCoord;
+
+#ifdef PROBES
+uniform sampler2D Texture1;
+uniform sampler2D Texture2;
+uniform vec4 Probes[6];
+uniform float Reflectivity;
+varying vec3 worldPos;
+
+// probeCoord returns the texture coordinate of the given world space
+// direction in the cubemap of a probe, whose six square faces (+X, -X, +Y,
+// -Y, +Z, -Z) are laid out from left to right, see package probe. If flip is
+// one, the faces are upside down.
+vec2 probeCoord(vec3 d, float flip) {
+	vec3 a = abs(d);
+	float face;
+	vec2 uv;
+	if (a.x >= a.y && a.x >= a.z) {
+		face = d.x > 0.0 ? 0.0 : 1.0;
+		uv = vec2(d.x > 0.0 ? -d.y : d.y, d.z) / a.x;
+	} else if (a.y >= a.z) {
+		face = d.y > 0.0 ? 2.0 : 3.0;
+		uv = vec2(d.y > 0.0 ? d.x : -d.x, d.z) / a.y;
+	} else {
+		face = d.z > 0.0 ? 4.0 : 5.0;
+		uv = vec2(d.z > 0.0 ? -d.x : d.x, d.y) / a.z;
+	}
+
+	// Keep away from the edges of the face, such that filtering does not
+	// bleed in the neighbouring face.
+	uv = clamp(uv * 0.5 + 0.5, 0.002, 0.998);
+	return vec2((face + uv.x) / 6.0, mix(uv.y, 1.0 - uv.y, flip));
+}
+
+// probeDir returns the direction from the center of a probe (whose box spans
+// boxMin to boxMax) to the point at which the ray from p along r leaves the
+// box, such that reflections line up with the walls of the box.
+vec3 probeDir(vec3 p, vec3 r, vec4 boxMin, vec4 boxMax, vec4 center) {
+	vec3 t = max((boxMax.xyz - p) / r, (boxMin.xyz - p) / r);
+	return p + r * min(min(t.x, t.y), t.z) - center.xyz;
+}
+#endif
+
+#ifdef CLUSTERED
+// shade returns the light reflected by the surface from the light of the
+// given index, see light.Clusters for the layout of the Lights array.
+vec3 shade(int i, vec3 albedo, vec3 n, vec3 v) {
+	vec4 pos = Lights[i * 4];
+	vec4 color = Lights[i * 4 + 1];
+	vec4 dir = Lights[i * 4 + 2];
+	vec4 cookie = Lights[i * 4 + 3];
+
+	vec3 l = -dir.xyz;
+	float att = 1.0;
+	if (pos.w > 0.0) {
+		// Inverse-square falloff, windowed smoothly to zero at the range.
+		vec3 toLight = pos.xyz - viewPos;
+		float dist = length(toLight);
+		l = toLight / dist;
+		float w = clamp(1.0 - pow(dist / pos.w, 4.0), 0.0, 1.0);
+		att = w * w / (dist * dist + 1.0);
+
+		// The cone of spot lights (always one for point lights).
+		att *= clamp(dot(-l, dir.xyz) * color.w + dir.w, 0.0, 1.0);
+
+		if (cookie.w > 0.0) {
+			// Project the cookie along the cone.
+			vec3 down = cross(dir.xyz, cookie.xyz);
+			float cosOuter = -dir.w / color.w;
+			float tanOuter = sqrt(1.0 - cosOuter * cosOuter) / cosOuter;
+			vec3 d = viewPos - pos.xyz;
+			vec2 uv = vec2(dot(d, cookie.xyz), dot(d, down)) / (dot(d, dir.xyz) * tanOuter);
+			uv = clamp(uv * 0.5 + 0.5, 0.0, 1.0);
+			uv.x = (uv.x + cookie.w - 1.0) / CookieTiles;
+			att *= texture2D(Texture2, uv).r;
+		}
+	}
+
+	vec3 h = normalize(l + v);
+	float diffuse = max(dot(n, l), 0.0);
+	float specular = 0.0;
+	if (diffuse > 0.0) {
+		specular = pow(max(dot(n, h), 0.0), Shininess);
+	}
+	return color.rgb * att * (albedo * diffuse + specular);
+}
+#endif
+
+void main(void) {
+#ifdef SPLAT
+	// Blend the tiled layers by the normalized weights of the splat map.
+	vec4 w = texture2D(Texture0, texCoord);
+	w /= max(dot(w, vec4(1.0)), 0.0001);
+	vec2 tc = texCoord * Tiling;
+	vec4 albedo = w.r * texture2D(Texture1, tc);
+	albedo += w.g * texture2D(Texture2, tc);
+	albedo += w.b * texture2D(Texture3, tc);
+	albedo += w.a * texture2D(Texture4, tc);
+	albedo.a = 1.0;
+#else
+	vec4 albedo = texture2D(Texture0, texCoord);
+#endif
+	if (BinaryAlpha && albedo.a < 0.5) {
+		discard;
+	}
+
+	vec3 n = normalize(viewNormal);
+#ifdef NORMAL_MAP
+	// Gram-Schmidt orthogonalize the tangent frame.
+	vec3 t = normalize(viewTangent - n * dot(n, viewTangent));
+	vec3 b = cross(n, t);
+	vec3 m = texture2D(Texture1, texCoord).xyz * 2.0 - 1.0;
+	n = normalize(mat3(t, b, n) * m);
+#endif
+
+#ifdef CLUSTERED
+	// Find the cluster of the fragment, and sum the light of each light in
+	// it (whose indices plus one are stored four per texel, ending in zero).
+	vec3 rgb = albedo.rgb * AmbientColor.rgb;
+	vec3 v = -normalize(viewPos);
+	vec2 cell = min(floor(gl_FragCoord.xy * ClusterParams.xy), vec2(CLUSTERS_X - 1, CLUSTERS_Y - 1));
+	float depth = max(-viewPos.z, ClusterParams.w);
+	float slice = min(floor(log(depth / ClusterParams.w) * ClusterParams.z), float(CLUSTERS_Z - 1));
+	vec2 size = vec2(CLUSTERS_X * CLUSTER_TEXELS, CLUSTERS_Y * CLUSTERS_Z);
+	for (int s = 0; s < CLUSTER_TEXELS; s++) {
+		vec2 texel = vec2(cell.x * float(CLUSTER_TEXELS) + float(s), slice * float(CLUSTERS_Y) + cell.y);
+		vec4 ids = floor(texture2D(Texture1, (texel + 0.5) / size) * 255.0 + 0.5);
+		if (ids.x == 0.0) {
+			break;
+		}
+		rgb += shade(int(ids.x) - 1, albedo.rgb, n, v);
+		if (ids.y == 0.0) {
+			break;
+		}
+		rgb += shade(int(ids.y) - 1, albedo.rgb, n, v);
+		if (ids.z == 0.0) {
+			break;
+		}
+		rgb += shade(int(ids.z) - 1, albedo.rgb, n, v);
+		if (ids.w == 0.0) {
+			break;
+		}
+		rgb += shade(int(ids.w) - 1, albedo.rgb, n, v);
+	}
+	gl_FragColor = vec4(rgb, albedo.a);
+#else
+	// Blinn-Phong lighting in view space.
+	vec3 l = normalize(-(toMat3(View) * LightDirection));
+	vec3 h = normalize(l - normalize(viewPos));
+	float diffuse = max(dot(n, l), 0.0);
+	float specular = 0.0;
+	if (diffuse > 0.0) {
+		specular = pow(max(dot(n, h), 0.0), Shininess);
+	}
+	vec3 rgb = albedo.rgb * (AmbientColor.rgb + LightColor.rgb * diffuse);
+	rgb += LightColor.rgb * specular;
+#ifdef PROBES
+	// Blend the reflections of both probes along the world space reflection
+	// of the view vector, by Schlick's approximation of the Fresnel term.
+	vec3 v = normalize(viewPos);
+	vec3 r = reflect(v, n) * toMat3(View);
+	vec3 d0 = probeDir(worldPos, r, Probes[0], Probes[1], Probes[2]);
+	vec3 d1 = probeDir(worldPos, r, Probes[3], Probes[4], Probes[5]);
+	vec3 reflected = Probes[2].w * texture2D(Texture1, probeCoord(d0, Probes[0].w)).rgb;
+	reflected += Probes[5].w * texture2D(Texture2, probeCoord(d1, Probes[3].w)).rgb;
+	float fresnel = Reflectivity + (1.0 - Reflectivity) * pow(1.0 - max(dot(n, -v), 0.0), 5.0);
+	rgb = mix(rgb, reflected, fresnel * (Probes[2].w + Probes[5].w));
+#endif
+	gl_FragColor = vec4(rgb, albedo.a);
+#endif
+}
+`,
+
+	"velocity.vert": `
+#include "common.glsl"
+
+uniform mat4 PrevMVP;
+uniform mat4 CurMVP;
+
+attribute vec3 Vertex;
+varying vec4 pos;
+varying vec4 prevPos;
+varying vec4 curPos;
+
+void main(void) {
+	prevPos = PrevMVP * vec4(Vertex, 1.0);
+	curPos = CurMVP * vec4(Vertex, 1.0);
+	pos = MVP * vec4(Vertex, 1.0);
+	gl_Position = pos;
+}
+`,
+
+	"velocity.frag": `
+uniform sampler2D Texture0;
+
+varying vec4 pos;
+varying vec4 prevPos;
+varying vec4 curPos;
+
+void main(void) {
+	// Only the visible surface of the scene moves.
+	vec2 tc = pos.xy / pos.w * 0.5 + 0.5;
+	if (gl_FragCoord.z > texture2D(Texture0, tc).r + 0.0001) {
+		discard;
+	}
+
+	// The motion in texture coordinates, packed into the range of [1, 255]
+	// with a fractional part such that zero means no motion vector.
+	vec2 motion = (curPos.xy / curPos.w - prevPos.xy / prevPos.w) * 0.5;
+	vec2 v = (clamp(motion, -1.0, 1.0) * 0.5 + 0.5) * 254.0 + 1.0;
+	vec2 hi = floor(v);
+	gl_FragColor = vec4(hi / 255.0, v - hi);
+}
+`,
+}
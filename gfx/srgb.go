@@ -0,0 +1,30 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+// SRGBCanvas is an optional interface that a Canvas may implement in order to
+// support sRGB framebuffers (see DeviceInfo.SRGBFramebuffer).
+//
+// When enabled, the colors output by shaders are assumed to be in linear
+// space and are converted to sRGB space by the hardware when written to the
+// canvas (blending also occurs in linear space). Combined with sRGB texture
+// formats (see SRGB and SRGBA) this allows for a linear lighting workflow,
+// where lighting math is done in linear space instead of gamma space:
+//
+//  if c, ok := d.(gfx.SRGBCanvas); ok && d.Info().SRGBFramebuffer {
+//      c.SetSRGB(true)
+//  }
+//
+// Without an sRGB framebuffer, shaders wanting linear lighting must convert
+// their output to sRGB manually (e.g. using pow(color, vec3(1.0/2.2))).
+type SRGBCanvas interface {
+	// SetSRGB turns on or off conversion from linear to sRGB space of colors
+	// written to the canvas. If sRGB framebuffers are not supported, it has no
+	// effect.
+	SetSRGB(enabled bool)
+
+	// SRGB returns the last value passed into SetSRGB on this canvas.
+	SRGB() bool
+}
@@ -0,0 +1,96 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssao
+
+// The GLSL sources of the occlusion and composite shaders, written in GLSL
+// 1.20 style and preprocessed using package glsl.
+const (
+	occlusionFrag = `
+uniform mat4 Projection;
+uniform sampler2D Texture0;
+uniform vec4 Kernel[SAMPLES];
+uniform vec2 DepthTexel;
+uniform float Radius;
+uniform float Bias;
+uniform float Intensity;
+
+varying vec2 texCoord;
+
+// viewPos returns the view space position of the scene at the given texture
+// coordinate of the depth texture, which was written using the perspective
+// projection matrix.
+vec3 viewPos(vec2 tc) {
+	float d = texture2D(Texture0, tc).r * 2.0 - 1.0;
+	float z = -Projection[3][2] / (d + Projection[2][2]);
+	vec2 ndc = tc * 2.0 - 1.0;
+	return vec3(
+		-z * (ndc.x + Projection[2][0]) / Projection[0][0],
+		-z * (ndc.y + Projection[2][1]) / Projection[1][1],
+		z
+	);
+}
+
+// hash returns a pseudo-random number in [0, 1) for the given coordinate.
+float hash(vec2 p) {
+	return fract(sin(dot(p, vec2(12.9898, 78.233))) * 43758.5453);
+}
+
+void main(void) {
+	vec3 p = viewPos(texCoord);
+
+	// The normal of the surface, from the nearest of the neighbouring depth
+	// texels along each axis (such that edges do not smear the normal).
+	vec3 px = viewPos(texCoord + vec2(DepthTexel.x, 0.0)) - p;
+	vec3 nx = p - viewPos(texCoord - vec2(DepthTexel.x, 0.0));
+	vec3 py = viewPos(texCoord + vec2(0.0, DepthTexel.y)) - p;
+	vec3 ny = p - viewPos(texCoord - vec2(0.0, DepthTexel.y));
+	vec3 dx = abs(px.z) < abs(nx.z) ? px : nx;
+	vec3 dy = abs(py.z) < abs(ny.z) ? py : ny;
+	vec3 n = normalize(cross(dx, dy));
+
+	// Rotate the kernel about the normal randomly per pixel, which the blur
+	// of the composite pass smooths out.
+	float angle = hash(gl_FragCoord.xy) * 6.2831853;
+	vec3 r = vec3(cos(angle), sin(angle), 0.0);
+	vec3 t = normalize(r - n * dot(r, n));
+	mat3 tbn = mat3(t, cross(n, t), n);
+
+	float occlusion = 0.0;
+	for (int i = 0; i < SAMPLES; i++) {
+		vec3 s = p + tbn * Kernel[i].xyz * Radius;
+		vec4 clip = Projection * vec4(s, 1.0);
+		vec2 tc = clip.xy / clip.w * 0.5 + 0.5;
+		float sceneZ = viewPos(tc).z;
+
+		// Occluders far outside of the radius (e.g. a wall behind a thin
+		// pole) do not occlude.
+		float rangeCheck = smoothstep(0.0, 1.0, Radius / abs(p.z - sceneZ));
+		occlusion += (sceneZ >= s.z + Bias ? 1.0 : 0.0) * rangeCheck;
+	}
+	float ao = pow(1.0 - occlusion / float(SAMPLES), Intensity);
+	gl_FragColor = vec4(ao, ao, ao, 1.0);
+}
+`
+
+	compositeFrag = `
+uniform sampler2D Texture0;
+uniform vec2 OcclusionTexel;
+
+varying vec2 texCoord;
+
+void main(void) {
+	// Box blur the occlusion, undoing the noise of the random rotations.
+	float ao = 0.0;
+	for (int y = 0; y < BLUR; y++) {
+		for (int x = 0; x < BLUR; x++) {
+			vec2 offset = vec2(float(x), float(y)) - float(BLUR - 1) * 0.5;
+			ao += texture2D(Texture0, texCoord + offset * OcclusionTexel).r;
+		}
+	}
+	ao /= float(BLUR * BLUR);
+	gl_FragColor = vec4(ao, ao, ao, 1.0);
+}
+`
+)
@@ -0,0 +1,270 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ssao implements screen-space ambient occlusion: darkening creases,
+// corners and contact points of the scene, estimated from it's depth buffer.
+//
+// The scene's depth is drawn into a render-to-texture canvas with a depth
+// texture (as with package water), from which the pass estimates the
+// occlusion of each pixel by sampling the depth in a hemisphere about the
+// surface. The occlusion is then blurred and multiplied onto the scene that
+// was drawn to the screen:
+//
+//  p, err := ssao.New(d, d.Bounds(), ssao.Config{
+//      Target:  glsl.GL2,
+//      Depth:   depthTexture,
+//      Quality: ssao.Medium,
+//  })
+//  if err != nil {
+//      log.Fatal(err)
+//  }
+//  for {
+//      // Draw the scene to the screen, and it's depth to the depth canvas.
+//      ...
+//      p.Draw(d, cam)
+//      d.Render()
+//  }
+//
+// On ES-class GPUs the occlusion should be estimated at half resolution (see
+// Config.HalfResolution), which costs a quarter as much and is mostly hidden
+// by the blur.
+package ssao // import "azul3d.org/engine/gfx/ssao"
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"math/rand"
+	"strconv"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/glsl"
+	"azul3d.org/engine/gfx/internal/pass"
+	"azul3d.org/engine/lmath"
+)
+
+// Quality is a quality preset, trading the number of samples (and the size
+// of the blur) against speed.
+type Quality int
+
+const (
+	// Low takes 8 samples per pixel, blurred over 2x2 pixels.
+	Low Quality = iota + 1
+
+	// Medium takes 16 samples per pixel, blurred over 4x4 pixels.
+	Medium
+
+	// High takes 32 samples per pixel, blurred over 4x4 pixels.
+	High
+)
+
+// String returns the name of the quality preset, e.g. "Medium".
+func (q Quality) String() string {
+	switch q {
+	case Low:
+		return "Low"
+	case Medium:
+		return "Medium"
+	case High:
+		return "High"
+	}
+	return fmt.Sprintf("Quality(%d)", int(q))
+}
+
+// params returns the number of samples and the size of the blur of the
+// preset.
+func (q Quality) params() (samples, blur int) {
+	switch q {
+	case Low:
+		return 8, 2
+	case High:
+		return 32, 4
+	}
+	return 16, 4
+}
+
+// Config configures an SSAO pass. Zero fields take their default values.
+type Config struct {
+	// The quality preset. The default is Medium.
+	Quality Quality
+
+	// HalfResolution tells whether the occlusion is estimated at half of the
+	// resolution of the screen, as is suitable for ES-class GPUs.
+	HalfResolution bool
+
+	// Radius is the world space radius of the hemisphere in which occluders
+	// are searched for. The default is 0.5.
+	Radius float64
+
+	// Bias is the depth difference below which occluders are ignored,
+	// avoiding self-occlusion of flat surfaces. The default is 0.025.
+	Bias float64
+
+	// Intensity is the exponent applied to the visibility (one minus the
+	// occlusion) of each pixel; larger values darken occluded areas more.
+	// The default is 1.
+	Intensity float64
+
+	// Target is the backend that the shaders are preprocessed for.
+	Target glsl.Target
+
+	// Depth is the depth texture of a render-to-texture canvas that the
+	// scene is drawn into using the camera, at the same size as the canvas
+	// that the occlusion is applied to. It is required.
+	Depth *gfx.Texture
+}
+
+// withDefaults returns the configuration with zero fields set to their
+// default values.
+func (c Config) withDefaults() Config {
+	if c.Quality == 0 {
+		c.Quality = Medium
+	}
+	if c.Radius <= 0 {
+		c.Radius = 0.5
+	}
+	if c.Bias <= 0 {
+		c.Bias = 0.025
+	}
+	if c.Intensity <= 0 {
+		c.Intensity = 1
+	}
+	return c
+}
+
+// Kernel returns the given number of sample offsets within the unit
+// hemisphere about +Z, for use by the occlusion shader. They are distributed
+// more densely near the center, such that nearby occluders matter more, and
+// are the same for each call.
+func Kernel(n int) []gfx.Vec4 {
+	rng := rand.New(rand.NewSource(1))
+	k := make([]gfx.Vec4, n)
+	for i := range k {
+		v := lmath.Vec3{
+			X: rng.Float64()*2 - 1,
+			Y: rng.Float64()*2 - 1,
+			Z: rng.Float64(),
+		}
+		v, ok := v.Normalized()
+		if !ok {
+			v = lmath.Vec3{Z: 1}
+		}
+		scale := float64(i) / float64(n)
+		v = v.MulScalar(rng.Float64() * lmath.Lerp(0.1, 1, scale*scale))
+		k[i] = gfx.Vec4{X: float32(v.X), Y: float32(v.Y), Z: float32(v.Z)}
+	}
+	return k
+}
+
+// Pass is a screen-space ambient occlusion pass.
+type Pass struct {
+	// The configuration of the pass, which must not be changed.
+	Config
+
+	// Texture is the occlusion texture, written by each draw, whose red
+	// channel is the visibility of each pixel (one where it is unoccluded).
+	// It is not blurred.
+	Texture *gfx.Texture
+
+	canvas               gfx.Canvas
+	occlusion, composite *gfx.Object
+}
+
+// Draw estimates the occlusion of the scene drawn using the given camera,
+// and multiplies it onto the given canvas (the one that the pass was created
+// for).
+func (p *Pass) Draw(c gfx.Canvas, cam gfx.Camera) {
+	p.canvas.Draw(p.canvas.Bounds(), p.occlusion, cam)
+	p.canvas.Render()
+	c.Draw(c.Bounds(), p.composite, cam)
+}
+
+// New returns a new SSAO pass applied to a canvas of the given bounds, using
+// a render-to-texture canvas of the given device to hold the occlusion. An
+// error is returned if the configuration has no depth texture, or if the
+// device does not support render-to-texture.
+func New(d gfx.Device, b image.Rectangle, c Config) (*Pass, error) {
+	c = c.withDefaults()
+	if c.Depth == nil {
+		return nil, errors.New("ssao: no depth texture")
+	}
+	size := b.Size()
+	if c.HalfResolution {
+		size = size.Div(2)
+	}
+	cfg := d.Info().RTTFormats.ChooseConfig(d.Precision(), false)
+	if cfg.ColorFormat == gfx.ZeroTexFormat {
+		return nil, errors.New("ssao: render-to-texture is not supported")
+	}
+	cfg.Bounds = image.Rectangle{Max: size}
+	cfg.Color = gfx.NewTexture()
+	cfg.Color.MinFilter = gfx.Linear
+	cfg.Color.MagFilter = gfx.Linear
+	cfg.Color.WrapU = gfx.Clamp
+	cfg.Color.WrapV = gfx.Clamp
+	canvas := d.RenderToTexture(cfg)
+	if canvas == nil {
+		return nil, errors.New("ssao: render-to-texture is not supported")
+	}
+
+	samples, blur := c.Quality.params()
+	p := &Pass{
+		Config:    c,
+		Texture:   cfg.Color,
+		canvas:    canvas,
+		occlusion: gfx.NewObject(),
+		composite: gfx.NewObject(),
+	}
+
+	// The quad is drawn over everything, without writing depth.
+	state := gfx.NewState()
+	state.DepthTest = false
+	state.DepthWrite = false
+	state.FaceCulling = gfx.NoFaceCulling
+
+	o := p.occlusion
+	o.Meshes = []*gfx.Mesh{pass.Quad()}
+	o.Textures = []*gfx.Texture{c.Depth}
+	o.State = state
+	o.Shader = pass.Shader("SSAO", c.Target, occlusionFrag, map[string]string{
+		"SAMPLES": strconv.Itoa(samples),
+	})
+	o.Shader.Inputs["Kernel"] = Kernel(samples)
+	o.Shader.Inputs["DepthTexel"] = texel(b.Size())
+	o.Shader.Inputs["Radius"] = float32(c.Radius)
+	o.Shader.Inputs["Bias"] = float32(c.Bias)
+	o.Shader.Inputs["Intensity"] = float32(c.Intensity)
+
+	// The blurred occlusion is multiplied onto the canvas.
+	composite := gfx.NewState()
+	*composite = *state
+	composite.AlphaMode = gfx.AlphaBlend
+	composite.Blend = gfx.BlendState{
+		SrcRGB:   gfx.BDstColor,
+		DstRGB:   gfx.BZero,
+		SrcAlpha: gfx.BZero,
+		DstAlpha: gfx.BOne,
+		RGBEq:    gfx.BAdd,
+		AlphaEq:  gfx.BAdd,
+	}
+	o = p.composite
+	o.Meshes = []*gfx.Mesh{pass.Quad()}
+	o.Textures = []*gfx.Texture{p.Texture}
+	o.State = composite
+	o.Shader = pass.Shader("SSAO Composite", c.Target, compositeFrag, map[string]string{
+		"BLUR": strconv.Itoa(blur),
+	})
+	o.Shader.Inputs["OcclusionTexel"] = texel(size)
+	return p, nil
+}
+
+// texel returns the size of a texel of a texture of the given size, in
+// texture coordinates.
+func texel(size image.Point) gfx.TexCoord {
+	return gfx.TexCoord{
+		U: 1 / float32(math.Max(float64(size.X), 1)),
+		V: 1 / float32(math.Max(float64(size.Y), 1)),
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssao
+
+import (
+	"image"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/glsl"
+	"azul3d.org/engine/gfx/internal/pass"
+)
+
+func TestKernel(t *testing.T) {
+	k := Kernel(16)
+	if len(k) != 16 || !reflect.DeepEqual(k, Kernel(16)) {
+		t.Fatal("kernel is not deterministic")
+	}
+	for _, v := range k {
+		if v.Z < 0 || v.X*v.X+v.Y*v.Y+v.Z*v.Z > 1 {
+			t.Fatalf("sample %v outside of the unit hemisphere", v)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	b := image.Rect(0, 0, 640, 480)
+	if _, err := New(&pass.RTTDevice{Device: gfx.Nil()}, b, Config{}); err == nil {
+		t.Fatal("expected an error without a depth texture")
+	}
+	if _, err := New(gfx.Nil(), b, Config{Depth: gfx.NewTexture()}); err == nil {
+		t.Fatal("expected an error without render-to-texture support")
+	}
+
+	for _, q := range []Quality{Low, Medium, High} {
+		d := &pass.RTTDevice{Device: gfx.Nil()}
+		p, err := New(d, b, Config{
+			Quality:        q,
+			HalfResolution: q == Low,
+			Target:         glsl.GLES2,
+			Depth:          gfx.NewTexture(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		samples, blur := q.params()
+		if got := p.occlusion.Shader.Inputs["Kernel"].([]gfx.Vec4); len(got) != samples {
+			t.Errorf("%v: got %d samples", q, len(got))
+		}
+		if !strings.Contains(string(p.composite.Shader.GLSL.Fragment), "#define BLUR "+strconv.Itoa(blur)) {
+			t.Errorf("%v: missing BLUR define", q)
+		}
+		want := b
+		if q == Low {
+			want = image.Rect(0, 0, 320, 240)
+		}
+		if d.Config.Bounds != want || p.Texture != d.Config.Color {
+			t.Errorf("%v: occlusion canvas of %v", q, d.Config.Bounds)
+		}
+		if p.Radius != 0.5 || p.Intensity != 1 {
+			t.Errorf("%v: defaults not applied", q)
+		}
+	}
+	if Medium.String() != "Medium" || Quality(9).String() != "Quality(9)" {
+		t.Error("wrong quality names")
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package taa
+
+// The GLSL sources of the resolve and blit shaders, written in GLSL 1.20
+// style and preprocessed using package glsl.
+const (
+	resolveFrag = `
+uniform sampler2D Texture0;
+uniform sampler2D Texture1;
+uniform sampler2D Texture2;
+#ifdef MOTION
+uniform sampler2D Texture3;
+#endif
+uniform mat4 Reprojection;
+uniform vec2 Texel;
+uniform float Feedback;
+
+varying vec2 texCoord;
+
+void main(void) {
+	vec3 current = texture2D(Texture0, texCoord).rgb;
+
+	// The range of colors in the neighbourhood of the pixel, to which the
+	// history is clamped: history which is no longer visible (e.g. after
+	// being disoccluded, or behind a moving object) would otherwise ghost.
+	vec3 lo = current;
+	vec3 hi = current;
+	for (int y = -1; y <= 1; y++) {
+		for (int x = -1; x <= 1; x++) {
+			vec3 c = texture2D(Texture0, texCoord + vec2(float(x), float(y)) * Texel).rgb;
+			lo = min(lo, c);
+			hi = max(hi, c);
+		}
+	}
+
+	// Where the pixel was on the screen in the previous frame, from it's
+	// depth and the movement of the camera.
+	float d = texture2D(Texture1, texCoord).r;
+	vec4 prev = Reprojection * vec4(texCoord * 2.0 - 1.0, d * 2.0 - 1.0, 1.0);
+	vec2 tc = prev.xy / prev.w * 0.5 + 0.5;
+#ifdef MOTION
+	// Or from it's own motion, if it has any (see shaders.Velocity).
+	vec4 m = texture2D(Texture3, texCoord);
+	if (m.r > 0.0) {
+		vec2 v = floor(m.rg * 255.0 + 0.5) + m.ba;
+		tc = texCoord - ((v - 1.0) / 254.0 * 2.0 - 1.0);
+	}
+#endif
+
+	// Pixels which were off-screen have no history.
+	float feedback = Feedback;
+	if (any(lessThan(tc, vec2(0.0))) || any(greaterThan(tc, vec2(1.0)))) {
+		feedback = 1.0;
+	}
+	vec3 history = clamp(texture2D(Texture2, tc).rgb, lo, hi);
+	gl_FragColor = vec4(mix(history, current, feedback), 1.0);
+}
+`
+
+	blitFrag = `
+uniform sampler2D Texture0;
+
+varying vec2 texCoord;
+
+void main(void) {
+	gl_FragColor = vec4(texture2D(Texture0, texCoord).rgb, 1.0);
+}
+`
+)
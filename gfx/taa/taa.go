@@ -0,0 +1,371 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package taa implements temporal anti-aliasing: smoothing the edges of the
+// scene by blending each frame with the frames before it, each drawn with
+// the camera's projection offset by a different subpixel amount.
+//
+// It is an alternative to MSAA for scenes which are drawn into a
+// render-to-texture canvas (where MSAA is unavailable). The scene is drawn
+// into a canvas with a color and depth texture, after jittering the camera,
+// and the pass then resolves it onto the screen:
+//
+//  p, err := taa.New(d, d.Bounds(), taa.Config{
+//      Target: glsl.GL2,
+//      Color:  colorTexture,
+//      Depth:  depthTexture,
+//  })
+//  if err != nil {
+//      log.Fatal(err)
+//  }
+//  for {
+//      p.Jitter(cam)
+//      // Draw the scene to the scene canvas using the camera.
+//      ...
+//      p.Draw(d, cam)
+//      d.Render()
+//  }
+//
+// The history of each pixel is found by reprojecting it's depth using the
+// previous and current positions of the camera, and is clamped to the
+// colors of the neighbouring pixels, such that moving objects do not leave
+// trails behind. Reset should be called whenever the camera cuts to another
+// view.
+//
+// Objects which move on their own are reprojected more accurately using
+// their motion vectors, drawn into a velocity buffer when the Motion option
+// is set:
+//
+//  p.Jitter(cam)
+//  // Draw the scene to the scene canvas using the camera.
+//  ...
+//  p.DrawMotion(movingObjects, cam)
+//  p.Draw(d, cam)
+package taa // import "azul3d.org/engine/gfx/taa"
+
+import (
+	"errors"
+	"image"
+	"math"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/camera"
+	"azul3d.org/engine/gfx/glsl"
+	"azul3d.org/engine/gfx/internal/pass"
+	"azul3d.org/engine/gfx/shaders"
+	"azul3d.org/engine/lmath"
+)
+
+// zUpRightToYUpRight converts the coordinate system of the world into the
+// one of the projection matrix, as the camera package does.
+var zUpRightToYUpRight = lmath.CoordSysZUpRight.ConvertMat4(lmath.CoordSysYUpRight)
+
+// view returns the view matrix of the given camera.
+func view(cam *camera.Camera) lmath.Mat4 {
+	v, _ := cam.Transform().Mat4().Inverse()
+	return v.Mul(zUpRightToYUpRight)
+}
+
+// Halton returns the i'th (starting at one) number of the Halton sequence of
+// the given base, in the range of [0, 1). Successive numbers of the sequence
+// are spread evenly, as such the bases 2 and 3 make good subpixel offsets.
+func Halton(i, base int) float64 {
+	var (
+		r float64
+		f = 1.0
+	)
+	for ; i > 0; i /= base {
+		f /= float64(base)
+		r += f * float64(i%base)
+	}
+	return r
+}
+
+// Samples is the number of distinct subpixel offsets that the camera is
+// jittered by, before they repeat.
+const Samples = 8
+
+// Config configures a TAA pass. Zero fields take their default values.
+type Config struct {
+	// Feedback is how much of each new frame is blended into the history,
+	// in the range of (0, 1]. Lower values are smoother but take longer to
+	// converge, e.g. after disocclusion. The default is 0.1.
+	Feedback float64
+
+	// Target is the backend that the shaders are preprocessed for.
+	Target glsl.Target
+
+	// Color and Depth are the color and depth textures of a render-to-texture
+	// canvas that the scene is drawn into using the jittered camera, at the
+	// same size as the canvas that the pass draws to. Both are required.
+	Color, Depth *gfx.Texture
+
+	// Motion enables the velocity buffer, into which the motion of objects
+	// is drawn by DrawMotion. Pixels covered by it are reprojected using
+	// their own motion, the others using their depth and the movement of the
+	// camera.
+	Motion bool
+}
+
+// withDefaults returns the configuration with zero fields set to their
+// default values.
+func (c Config) withDefaults() Config {
+	if c.Feedback <= 0 || c.Feedback > 1 {
+		c.Feedback = 0.1
+	}
+	return c
+}
+
+// Pass is a temporal anti-aliasing pass.
+type Pass struct {
+	// The configuration of the pass, which must not be changed.
+	Config
+
+	// Texture is the anti-aliased image of the last drawn frame, which is
+	// the history of the next one.
+	Texture *gfx.Texture
+
+	// Velocity is the velocity buffer, packed as described by
+	// shaders.Velocity, or nil if the Motion option is not set.
+	Velocity *gfx.Texture
+
+	frame    int
+	history  [2]gfx.Canvas
+	textures [2]*gfx.Texture
+	resolve  *gfx.Object
+	blit     *gfx.Object
+	velocity gfx.Canvas
+	motions  map[*gfx.Object]*motion
+
+	// The unjittered view-projection matrix of the previous frame, valid
+	// only if hasPrev is true.
+	prev    lmath.Mat4
+	hasPrev bool
+}
+
+// motion is an object drawing the motion of another one into the velocity
+// buffer.
+type motion struct {
+	*gfx.Object
+
+	// The unjittered model-view-projection matrix of the object in the
+	// previous frame, valid only if hasPrev is true.
+	prev    lmath.Mat4
+	hasPrev bool
+}
+
+// Jitter offsets the projection of the given camera by the subpixel amount
+// of the next frame, and updates it. It should be called before the scene
+// is drawn, each frame.
+func (p *Pass) Jitter(cam *camera.Camera) {
+	p.frame++
+	i := p.frame%Samples + 1
+	cam.Jitter = lmath.Vec2{
+		X: Halton(i, 2) - 0.5,
+		Y: Halton(i, 3) - 0.5,
+	}
+	cam.Update(cam.View)
+}
+
+// Reset discards the history of the pass, such that the next frame is not
+// blended with the previous ones. It should be called when the camera cuts
+// to another view.
+func (p *Pass) Reset() {
+	p.hasPrev = false
+	for _, m := range p.motions {
+		m.hasPrev = false
+	}
+}
+
+// DrawMotion draws the motion of the given objects since the previous frame
+// into the velocity buffer, using the given (jittered) camera. It should be
+// called each frame after the scene is drawn and before Draw, with the
+// objects which move on their own, as the motion of the others is found from
+// their depth. Objects drawn for the first time have no motion, and those no
+// longer drawn are forgotten.
+//
+// Only the transforms of the objects are accounted for, not the movement of
+// their vertices by their shaders (e.g. skinning). It panics if the Motion
+// option is not set.
+func (p *Pass) DrawMotion(objs []*gfx.Object, cam *camera.Camera) {
+	if p.velocity == nil {
+		panic("taa: DrawMotion without the Motion option")
+	}
+	b := p.velocity.Bounds()
+	p.velocity.Clear(b, gfx.Color{})
+
+	viewProj := view(cam).Mul(cam.Unjittered().Mat4())
+	used := make(map[*gfx.Object]*motion, len(p.motions))
+	for _, o := range objs {
+		m, ok := p.motions[o]
+		if !ok {
+			m = p.newMotion()
+		}
+		used[o] = m
+
+		mvp := o.Transform.Mat4().Mul(viewProj)
+		if !m.hasPrev {
+			m.prev = mvp
+		}
+		m.Shader.Inputs["PrevMVP"] = gfx.ConvertMat4(m.prev)
+		m.Shader.Inputs["CurMVP"] = gfx.ConvertMat4(mvp)
+		m.prev, m.hasPrev = mvp, true
+
+		m.Transform = o.Transform
+		m.Meshes = o.Meshes
+		m.Indirect = o.Indirect
+		if _, state := o.DrawState(); state != nil {
+			m.State.FaceCulling = state.FaceCulling
+		}
+		p.velocity.Draw(b, m.Object, cam)
+	}
+
+	// Objects which were not drawn are forgotten.
+	p.motions = used
+	p.velocity.Render()
+}
+
+// newMotion returns a new object drawing motion into the velocity buffer.
+func (p *Pass) newMotion() *motion {
+	o := gfx.NewObject()
+	o.Shader = shaders.New(shaders.Velocity, p.Target)
+	o.Textures = []*gfx.Texture{p.Depth}
+	o.State = gfx.NewState()
+	o.State.DepthTest = false
+	o.State.DepthWrite = false
+	return &motion{Object: o}
+}
+
+// Draw blends the scene drawn using the given camera with the history, and
+// draws the result to the given canvas (the one that the pass was created
+// for).
+func (p *Pass) Draw(c gfx.Canvas, cam *camera.Camera) {
+	v := view(cam)
+	cur := v.Mul(cam.P.Mat4())
+	unjittered := v.Mul(cam.Unjittered().Mat4())
+
+	// The reprojection transforms the normalized device coordinates of the
+	// current frame into the clip space of the previous one.
+	curInv, ok := cur.Inverse()
+	feedback := p.Feedback
+	if !p.hasPrev || !ok {
+		feedback = 1
+	}
+	in := p.resolve.Shader.Inputs
+	in["Reprojection"] = gfx.ConvertMat4(curInv.Mul(p.prev))
+	in["Feedback"] = float32(feedback)
+	p.prev, p.hasPrev = unjittered, true
+
+	// Resolve into the older of the history textures, which then becomes
+	// the newest one.
+	next := 0
+	if p.Texture == p.textures[0] {
+		next = 1
+	}
+	p.resolve.Textures[2] = p.Texture
+	dst := p.history[next]
+	dst.Draw(dst.Bounds(), p.resolve, cam)
+	dst.Render()
+	p.Texture = p.textures[next]
+
+	p.blit.Textures[0] = p.Texture
+	c.Draw(c.Bounds(), p.blit, cam)
+}
+
+// New returns a new TAA pass drawing to a canvas of the given bounds, using
+// two render-to-texture canvases of the given device to hold the history. An
+// error is returned if the configuration has no color or depth texture, or
+// if the device does not support render-to-texture (of the RGBA format, for
+// the velocity buffer).
+func New(d gfx.Device, b image.Rectangle, c Config) (*Pass, error) {
+	c = c.withDefaults()
+	if c.Color == nil || c.Depth == nil {
+		return nil, errors.New("taa: no color or depth texture")
+	}
+	p := &Pass{
+		Config:  c,
+		resolve: gfx.NewObject(),
+		blit:    gfx.NewObject(),
+	}
+	for i := range p.history {
+		cfg := d.Info().RTTFormats.ChooseConfig(d.Precision(), false)
+		if cfg.ColorFormat == gfx.ZeroTexFormat {
+			return nil, errors.New("taa: render-to-texture is not supported")
+		}
+		cfg.Bounds = image.Rectangle{Max: b.Size()}
+		cfg.Color = gfx.NewTexture()
+		cfg.Color.MinFilter = gfx.Linear
+		cfg.Color.MagFilter = gfx.Linear
+		cfg.Color.WrapU = gfx.Clamp
+		cfg.Color.WrapV = gfx.Clamp
+		p.history[i] = d.RenderToTexture(cfg)
+		if p.history[i] == nil {
+			return nil, errors.New("taa: render-to-texture is not supported")
+		}
+		p.textures[i] = cfg.Color
+	}
+	p.Texture = p.textures[0]
+	if c.Motion {
+		if err := p.newVelocity(d, b); err != nil {
+			return nil, err
+		}
+	}
+
+	// The quads are drawn over everything, without writing depth.
+	state := gfx.NewState()
+	state.DepthTest = false
+	state.DepthWrite = false
+	state.FaceCulling = gfx.NoFaceCulling
+
+	o := p.resolve
+	o.Meshes = []*gfx.Mesh{pass.Quad()}
+	o.Textures = []*gfx.Texture{c.Color, c.Depth, p.Texture}
+	o.State = state
+	if c.Motion {
+		o.Textures = append(o.Textures, p.Velocity)
+		o.Shader = pass.Shader("TAA", c.Target, resolveFrag, map[string]string{"MOTION": "1"})
+	} else {
+		o.Shader = pass.Shader("TAA", c.Target, resolveFrag, nil)
+	}
+	o.Shader.Inputs["Texel"] = gfx.TexCoord{
+		U: 1 / float32(math.Max(float64(b.Dx()), 1)),
+		V: 1 / float32(math.Max(float64(b.Dy()), 1)),
+	}
+
+	o = p.blit
+	o.Meshes = []*gfx.Mesh{pass.Quad()}
+	o.Textures = []*gfx.Texture{p.Texture}
+	o.State = state
+	o.Shader = pass.Shader("TAA Blit", c.Target, blitFrag, nil)
+	return p, nil
+}
+
+// newVelocity creates the velocity buffer, a render-to-texture canvas of the
+// given device and bounds.
+func (p *Pass) newVelocity(d gfx.Device, b image.Rectangle) error {
+	supported := false
+	for _, f := range d.Info().RTTFormats.ColorFormats {
+		if f == gfx.RGBA {
+			supported = true
+		}
+	}
+	if !supported {
+		return errors.New("taa: render-to-texture of RGBA is not supported")
+	}
+	cfg := gfx.RTTConfig{
+		ColorFormat: gfx.RGBA,
+		Bounds:      image.Rectangle{Max: b.Size()},
+		Color:       gfx.NewTexture(),
+	}
+	// The packed motion must not be filtered.
+	cfg.Color.MinFilter = gfx.Nearest
+	cfg.Color.MagFilter = gfx.Nearest
+	cfg.Color.WrapU = gfx.Clamp
+	cfg.Color.WrapV = gfx.Clamp
+	if p.velocity = d.RenderToTexture(cfg); p.velocity == nil {
+		return errors.New("taa: render-to-texture is not supported")
+	}
+	p.Velocity = cfg.Color
+	return nil
+}
@@ -0,0 +1,152 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package taa
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/camera"
+	"azul3d.org/engine/gfx/internal/pass"
+	"azul3d.org/engine/lmath"
+)
+
+func TestHalton(t *testing.T) {
+	for _, tc := range []struct {
+		i, base int
+		want    float64
+	}{
+		{1, 2, 0.5},
+		{2, 2, 0.25},
+		{3, 2, 0.75},
+		{1, 3, 1.0 / 3},
+		{2, 3, 2.0 / 3},
+		{3, 3, 1.0 / 9},
+	} {
+		if got := Halton(tc.i, tc.base); math.Abs(got-tc.want) > 1e-12 {
+			t.Errorf("Halton(%d, %d) = %v, want %v", tc.i, tc.base, got, tc.want)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	b := image.Rect(0, 0, 640, 480)
+	if _, err := New(&pass.RTTDevice{Device: gfx.Nil()}, b, Config{Color: gfx.NewTexture()}); err == nil {
+		t.Fatal("expected an error without a depth texture")
+	}
+	c := Config{Color: gfx.NewTexture(), Depth: gfx.NewTexture()}
+	if _, err := New(gfx.Nil(), b, c); err == nil {
+		t.Fatal("expected an error without render-to-texture support")
+	}
+	p, err := New(&pass.RTTDevice{Device: gfx.Nil()}, b, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Feedback != 0.1 || p.textures[0] == p.textures[1] {
+		t.Fatal("wrong pass", p)
+	}
+}
+
+func TestDraw(t *testing.T) {
+	d := &pass.RTTDevice{Device: gfx.Nil()}
+	p, err := New(d, image.Rect(0, 0, 200, 100), Config{
+		Color: gfx.NewTexture(),
+		Depth: gfx.NewTexture(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cam := camera.New(image.Rect(0, 0, 200, 100))
+	cam.Transform().SetPos(lmath.Vec3{Y: -10})
+
+	// The first frame has no history.
+	p.Jitter(cam)
+	p.Draw(d, cam)
+	if p.resolve.Shader.Inputs["Feedback"] != float32(1) {
+		t.Fatal("first frame blended with the history")
+	}
+	first := p.Texture
+
+	// A point seen by the still camera is reprojected to where it would be
+	// without the jitter of the frame.
+	p.Jitter(cam)
+	p.Draw(d, cam)
+	if p.resolve.Shader.Inputs["Feedback"] != float32(0.1) || p.Texture == first {
+		t.Fatal("history not used")
+	}
+	world := lmath.Vec3{X: 1, Y: 5, Z: 2}
+	ndc, _ := cam.Project(world)
+	cur := lmath.Vec4{X: ndc.X, Y: ndc.Y, W: 1}
+	// The depth of the point does not matter for a still camera.
+	r := p.resolve.Shader.Inputs["Reprojection"].(gfx.Mat4).Mat4()
+	prev := cur.Transform(r)
+	got := lmath.Vec2{X: prev.X / prev.W, Y: prev.Y / prev.W}
+	want := ndc.Sub(lmath.Vec2{
+		X: 2 * cam.Jitter.X / 200,
+		Y: 2 * cam.Jitter.Y / 100,
+	})
+	if !got.AlmostEquals(want, 1e-4) {
+		t.Fatalf("reprojected to %v, want %v", got, want)
+	}
+}
+
+func TestDrawMotion(t *testing.T) {
+	d := &pass.RTTDevice{Device: gfx.Nil()}
+	p, err := New(d, image.Rect(0, 0, 200, 100), Config{
+		Color: gfx.NewTexture(),
+		Depth: gfx.NewTexture(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Velocity != nil {
+		t.Fatal("velocity buffer without the Motion option")
+	}
+	p, err = New(d, image.Rect(0, 0, 200, 100), Config{
+		Color:  gfx.NewTexture(),
+		Depth:  gfx.NewTexture(),
+		Motion: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Config.Color != p.Velocity || d.Config.ColorFormat != gfx.RGBA || p.resolve.Textures[3] != p.Velocity {
+		t.Fatal("wrong velocity buffer", d.Config)
+	}
+	cam := camera.New(image.Rect(0, 0, 200, 100))
+	cam.Transform().SetPos(lmath.Vec3{Y: -10})
+	o := gfx.NewObject()
+
+	// The first frame has no motion.
+	p.Jitter(cam)
+	p.DrawMotion([]*gfx.Object{o}, cam)
+	m := p.motions[o]
+	if m == nil || m.Shader.Inputs["PrevMVP"] != m.Shader.Inputs["CurMVP"] {
+		t.Fatal("motion in the first frame")
+	}
+
+	// A moving object is reprojected to where it was, without jitter.
+	o.Transform.SetPos(lmath.Vec3{X: 1})
+	p.Jitter(cam)
+	p.DrawMotion([]*gfx.Object{o}, cam)
+	if p.motions[o] != m {
+		t.Fatal("motion object not reused")
+	}
+	prev := m.Shader.Inputs["PrevMVP"].(gfx.Mat4).Mat4()
+	cur := m.Shader.Inputs["CurMVP"].(gfx.Mat4).Mat4()
+	origin := lmath.Vec4{W: 1}
+	a, b := origin.Transform(prev), origin.Transform(cur)
+	if b.X/b.W-a.X/a.W <= 0 || math.Abs(b.Y/b.W-a.Y/a.W) > 1e-9 {
+		t.Fatalf("moved from %v to %v", a, b)
+	}
+
+	// Objects no longer drawn are forgotten.
+	p.DrawMotion(nil, cam)
+	if len(p.motions) != 0 {
+		t.Fatal("objects not forgotten")
+	}
+}
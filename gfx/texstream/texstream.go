@@ -0,0 +1,200 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package texstream streams the mipmap levels of textures in and out of
+// graphics memory based on their distance from the camera and a memory
+// budget.
+//
+// Only textures with pre-built mipmaps (e.g. those loaded from DDS or KTX
+// files by the asset package) are streamed, and only on devices which
+// implement gfx.MipStreamer. Objects are added to a streamer, which is
+// updated with the position of the camera once per frame:
+//
+//  s := texstream.New(device)
+//  s.Budget = 256 << 20
+//  s.Add(terrain)
+//  s.Add(house)
+//  for {
+//      s.Update(cam.Transform.Pos())
+//      ...
+//  }
+//
+// A texture is streamed at full resolution (level zero) while it's nearest
+// object is within Distance of the camera, and each doubling of the distance
+// beyond that drops one level. If the selected levels exceed the budget,
+// levels are dropped from the textures with the most texels per pixel on
+// screen (i.e. large levels of distant textures) until they fit.
+package texstream // import "azul3d.org/engine/gfx/texstream"
+
+import (
+	"math"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/lmath"
+)
+
+// entry is a single texture tracked by a streamer.
+type entry struct {
+	t       *gfx.Texture
+	objects []*gfx.Object
+
+	// The distance to the nearest object, and the selected level.
+	dist  float64
+	level int
+}
+
+// Streamer selects the resident mipmap levels of the textures of objects.
+//
+// A streamer is not safe for access from multiple goroutines concurrently.
+type Streamer struct {
+	// Budget is the maximum amount of graphics memory, in bytes, that the
+	// selected levels of all textures may use. Zero means no limit. The
+	// budget is approximate: devices always keep the smallest levels of each
+	// texture resident.
+	Budget int64
+
+	// Distance is the distance from the camera within which textures are
+	// streamed at full resolution (level zero). The default is 16.
+	Distance float64
+
+	dev     gfx.MipStreamer
+	entries []*entry
+	byTex   map[*gfx.Texture]*entry
+}
+
+// Add adds the textures of the given object with pre-built mipmaps to the
+// streamer. An object added more than once is tracked only once.
+func (s *Streamer) Add(o *gfx.Object) {
+	for _, t := range o.Textures {
+		if t.Mipmaps == nil {
+			continue
+		}
+		e, ok := s.byTex[t]
+		if !ok {
+			e = &entry{t: t}
+			s.byTex[t] = e
+			s.entries = append(s.entries, e)
+		}
+		found := false
+		for _, other := range e.objects {
+			if other == o {
+				found = true
+				break
+			}
+		}
+		if !found {
+			e.objects = append(e.objects, o)
+		}
+	}
+}
+
+// Remove removes the given object from the streamer. Textures not used by any
+// remaining object are no longer streamed (their resident levels are left
+// as-is).
+func (s *Streamer) Remove(o *gfx.Object) {
+	for _, t := range o.Textures {
+		e, ok := s.byTex[t]
+		if !ok {
+			continue
+		}
+		for i, other := range e.objects {
+			if other == o {
+				e.objects = append(e.objects[:i], e.objects[i+1:]...)
+				break
+			}
+		}
+		if len(e.objects) > 0 {
+			continue
+		}
+		delete(s.byTex, t)
+		for i, other := range s.entries {
+			if other == e {
+				s.entries = append(s.entries[:i], s.entries[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// Len returns the number of textures being streamed.
+func (s *Streamer) Len() int {
+	return len(s.entries)
+}
+
+// Level returns the mipmap level selected for the given texture by the last
+// call to Update, or zero if it is not being streamed.
+func (s *Streamer) Level(t *gfx.Texture) int {
+	if e, ok := s.byTex[t]; ok {
+		return e.level
+	}
+	return 0
+}
+
+// Update selects the level of each texture given the world space position of
+// the camera, and requests them from the device. It returns the amount of
+// memory, in bytes, used by the selected levels.
+func (s *Streamer) Update(eye lmath.Vec3) (size int64) {
+	dist := s.Distance
+	if dist <= 0 {
+		dist = 16
+	}
+
+	// Select levels by distance alone.
+	for _, e := range s.entries {
+		e.dist = math.Inf(1)
+		for _, o := range e.objects {
+			d := math.Sqrt(o.Bounds().SqDistToPoint(eye))
+			e.dist = math.Min(e.dist, d)
+		}
+		e.level = 0
+		if e.dist > dist {
+			e.level = int(math.Log2(e.dist / dist))
+		}
+		if max := len(e.t.Mipmaps) - 1; e.level > max {
+			e.level = max
+		}
+		size += gfx.MipSize(e.t.Mipmaps, e.level)
+	}
+
+	// Drop the least visible levels until they fit in the budget.
+	for s.Budget > 0 && size > s.Budget {
+		var drop *entry
+		var dropDetail float64
+		for _, e := range s.entries {
+			if e.level >= len(e.t.Mipmaps)-1 {
+				continue
+			}
+			// The texels per pixel of the level grow with both it's size
+			// and the distance it is viewed from.
+			detail := float64(e.t.Mipmaps[e.level].Width) * e.dist
+			if drop == nil || detail > dropDetail {
+				drop, dropDetail = e, detail
+			}
+		}
+		if drop == nil {
+			break
+		}
+		size -= int64(len(drop.t.Mipmaps[drop.level].Data))
+		drop.level++
+	}
+
+	if s.dev != nil {
+		for _, e := range s.entries {
+			s.dev.SetResidentMip(e.t, e.level)
+		}
+	}
+	return size
+}
+
+// New returns a new streamer which streams textures on the given device. If
+// the device does not implement gfx.MipStreamer, levels are still selected
+// (see Level) but not requested from it.
+func New(d gfx.Device) *Streamer {
+	m, _ := d.(gfx.MipStreamer)
+	return &Streamer{
+		Distance: 16,
+		dev:      m,
+		byTex:    make(map[*gfx.Texture]*entry),
+	}
+}
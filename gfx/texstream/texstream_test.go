@@ -0,0 +1,87 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package texstream
+
+import (
+	"testing"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/lmath"
+)
+
+// mipDevice is a device which records the requested resident levels.
+type mipDevice struct {
+	gfx.Device
+	levels map[*gfx.Texture]int
+}
+
+func (d *mipDevice) SetResidentMip(t *gfx.Texture, level int) {
+	d.levels[t] = level
+}
+
+func (d *mipDevice) ResidentMip(t *gfx.Texture) int {
+	return d.levels[t]
+}
+
+// mipObject returns an object at the given position with a 64x64 RGBA
+// texture (of seven levels).
+func mipObject(pos lmath.Vec3) *gfx.Object {
+	t := gfx.NewTexture()
+	for size := 64; size > 0; size /= 2 {
+		t.Mipmaps = append(t.Mipmaps, gfx.MipLevel{
+			Width:  size,
+			Height: size,
+			Data:   make([]byte, size*size*4),
+		})
+	}
+	o := gfx.NewObject()
+	o.Meshes = []*gfx.Mesh{gfx.NewMesh()}
+	o.Meshes[0].Vertices = []gfx.Vec3{{}, {X: 1, Y: 1, Z: 1}}
+	o.Textures = []*gfx.Texture{t}
+	o.Transform.SetPos(pos)
+	return o
+}
+
+func TestStreamer(t *testing.T) {
+	d := &mipDevice{levels: make(map[*gfx.Texture]int)}
+	s := New(d)
+	near := mipObject(lmath.Vec3{})
+	far := mipObject(lmath.Vec3{Y: 1 + 64})
+	s.Add(near)
+	s.Add(far)
+	s.Add(far)
+	if s.Len() != 2 {
+		t.Fatalf("streaming %d textures, want 2", s.Len())
+	}
+
+	// 64 units away is four times Distance, so two levels are dropped.
+	nearTex, farTex := near.Textures[0], far.Textures[0]
+	size := s.Update(lmath.Vec3{})
+	if s.Level(nearTex) != 0 || s.Level(farTex) != 2 {
+		t.Fatalf("levels %d and %d, want 0 and 2", s.Level(nearTex), s.Level(farTex))
+	}
+	if d.levels[farTex] != 2 {
+		t.Fatalf("device level %d, want 2", d.levels[farTex])
+	}
+	if want := gfx.MipSize(nearTex.Mipmaps, 0) + gfx.MipSize(farTex.Mipmaps, 2); size != want {
+		t.Fatalf("size %d, want %d", size, want)
+	}
+
+	// With a budget of just the full near texture, the far one drops to it's
+	// last level first, and the near one is dropped only as far as needed.
+	s.Budget = gfx.MipSize(nearTex.Mipmaps, 0)
+	size = s.Update(lmath.Vec3{})
+	if size > s.Budget {
+		t.Fatalf("size %d exceeds budget %d", size, s.Budget)
+	}
+	if s.Level(farTex) != len(farTex.Mipmaps)-1 || s.Level(nearTex) != 1 {
+		t.Fatalf("levels %d and %d", s.Level(nearTex), s.Level(farTex))
+	}
+
+	s.Remove(far)
+	if s.Len() != 1 || s.Level(farTex) != 0 {
+		t.Fatal("far texture still streamed after Remove")
+	}
+}
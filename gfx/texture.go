@@ -26,6 +26,10 @@ func (t TexFormat) Bits() (r, g, b, a uint8) {
 		return 8, 8, 8, 0
 	case RGBA:
 		return 8, 8, 8, 8
+	case SRGB:
+		return 8, 8, 8, 0
+	case SRGBA:
+		return 8, 8, 8, 8
 
 	case ZeroTexFormat:
 		return 0, 0, 0, 0
@@ -74,8 +78,23 @@ const (
 	// chunk in a similar manner to DXT1's color storage. It provides the same
 	// 4:1 compression ratio as DXT3.
 	DXT5
+
+	// SRGB is like RGB, except the color components are sRGB encoded (i.e.
+	// gamma corrected, as most images are). The device converts them to
+	// linear space when the texture is sampled by a shader.
+	SRGB
+
+	// SRGBA is like RGBA, except the color components (but not alpha) are
+	// sRGB encoded, see SRGB.
+	SRGBA
 )
 
+// IsSRGB tells if the texture format is sRGB encoded, i.e. either SRGB or
+// SRGBA.
+func (t TexFormat) IsSRGB() bool {
+	return t == SRGB || t == SRGBA
+}
+
 // Downloadable represents a image that can be downloaded from the graphics
 // hardware into system memory (e.g. for taking a screen-shot).
 type Downloadable interface {
@@ -98,6 +117,23 @@ type Downloadable interface {
 	Download(r image.Rectangle, complete chan image.Image)
 }
 
+// AsyncDownloader is an optional interface that a Canvas may implement in
+// order to download it's contents without stalling the graphics pipeline.
+//
+// It is primarily useful when downloading every frame (e.g. for recording
+// video), where a regular Download would force the CPU to wait for the GPU to
+// finish rendering each frame.
+type AsyncDownloader interface {
+	// DownloadAsync is like Download, except the download completes at some
+	// point in the future (typically after the next frame has been rendered),
+	// instead of immediately. Multiple downloads may be pending at once, and
+	// they complete in the same order that they were issued.
+	//
+	// As with Download, if downloading is impossible then nil will be sent
+	// over the channel.
+	DownloadAsync(r image.Rectangle, complete chan image.Image)
+}
+
 // NativeTexture represents the native object of a *Texture, the device is
 // responsible for creating these and fulfilling the interface.
 type NativeTexture interface {
@@ -136,6 +172,21 @@ type Texture struct {
 	// update texture data often (i.e. it's not static) then set this to true.
 	Dynamic bool
 
+	// Streaming, if true, causes the texture to be loaded without blocking
+	// drawing: objects using the texture are drawn (with a placeholder in
+	// it's place) while the device uploads it in the background, and once the
+	// upload completes the device sets Loaded to true during a call to Render.
+	//
+	// Streaming is useful for large textures, whose upload would otherwise
+	// cause a noticeable hitch in the frame rate.
+	Streaming bool
+
+	// OnLoad is a list of functions that are called, in order, once a
+	// streaming texture has finished loading. They are called from within the
+	// Render method of the device that loaded the texture, and as such should
+	// not block for long.
+	OnLoad []func(t *Texture)
+
 	// The bounds of the texture, in the case of a texture loaded from a image
 	// this should be set to the image's bounds. In the case of rendering to a
 	// texture this should be set to the desired canvas resolution.
@@ -145,6 +196,17 @@ type Texture struct {
 	// to texture, unless downloaded).
 	Source image.Image
 
+	// Mipmaps, if non-nil, are the pre-built mipmap levels of the texture
+	// (e.g. loaded from a DDS or KTX file), largest first, in which case the
+	// texture is loaded from them instead of from Source. The data of each
+	// level is in the texture's Format, which must be RGBA, SRGBA, or one of
+	// the DXT formats.
+	//
+	// Unlike Source, Mipmaps are not cleared when the texture is loaded, as
+	// devices implementing MipStreamer stream levels in and out of graphics
+	// memory from them.
+	Mipmaps []MipLevel
+
 	// The texture format to use for storing this texture on the GPU, which may
 	// result in lossy conversions (e.g. RGB would lose the alpha channel, etc).
 	//
@@ -162,6 +224,24 @@ type Texture struct {
 	// The texture filtering used for minification and magnification of the
 	// texture.
 	MinFilter, MagFilter TexFilter
+
+	// MaxAnisotropy is the maximum degree of anisotropic filtering used when
+	// minifying the texture, e.g. 16. Anisotropic filtering keeps textures
+	// viewed at steep angles (e.g. distant ground) sharp, where trilinear
+	// filtering alone would blur them. Values less than or equal to one
+	// disable it, and values above DeviceInfo.MaxAnisotropy are clamped.
+	MaxAnisotropy float32
+
+	// LODBias is added to the mipmap level of detail selected when sampling
+	// the texture, where negative values select sharper (larger) mipmaps and
+	// positive ones select blurrier (smaller) mipmaps. It is clamped to the
+	// range of DeviceInfo.MaxLODBias.
+	LODBias float32
+
+	// Label is an optional human-readable label for debugging purposes. If
+	// the device supports it, the texture is labeled with it such that it is
+	// identifiable in graphics debuggers (e.g. RenderDoc, apitrace).
+	Label string
 }
 
 // Copy returns a new copy of this Texture. Explicitly not copied over is the
@@ -174,14 +254,20 @@ func (t *Texture) Copy() *Texture {
 		false, // Loaded status -- not copied.
 		t.KeepDataOnLoad,
 		t.Dynamic,
+		t.Streaming,
+		nil, // OnLoad slice -- not copied.
 		t.Bounds,
 		nil, // Source image -- not copied.
+		t.Mipmaps,
 		t.Format,
 		t.WrapU,
 		t.WrapV,
 		t.BorderColor,
 		t.MinFilter,
 		t.MagFilter,
+		t.MaxAnisotropy,
+		t.LODBias,
+		t.Label,
 	}
 }
 
@@ -199,14 +285,20 @@ func (t *Texture) Reset() {
 	t.Loaded = false
 	t.KeepDataOnLoad = false
 	t.Dynamic = false
+	t.Streaming = false
+	t.OnLoad = nil
 	t.Bounds = image.Rectangle{}
 	t.Source = nil
+	t.Mipmaps = nil
 	t.Format = RGBA
 	t.WrapU = 0
 	t.WrapV = 0
 	t.BorderColor = Color{}
 	t.MinFilter = 0
 	t.MagFilter = 0
+	t.MaxAnisotropy = 0
+	t.LODBias = 0
+	t.Label = ""
 }
 
 // Destroy destroys this texture for use by other callees to NewTexture. You
@@ -368,6 +368,36 @@ func (t *Transform) Shear() lmath.Vec3 {
 	return s
 }
 
+// SetTRS sets the local position, quaternion rotation, and scale of this
+// transform to the ones of the given TRS transform. The shear is left
+// unchanged.
+func (t *Transform) SetTRS(trs lmath.Transform) {
+	t.SetPos(trs.Pos)
+	t.SetQuat(trs.Rot)
+	t.SetScale(trs.Scale)
+}
+
+// TRS returns the local position, rotation (see Quat), and scale of this
+// transform as a TRS transform. The shear is not included.
+func (t *Transform) TRS() lmath.Transform {
+	return lmath.Transform{
+		Pos:   t.Pos(),
+		Rot:   t.Quat(),
+		Scale: t.Scale(),
+	}
+}
+
+// LookAt sets the quaternion rotation of this transform such that it's
+// forward axis (+Y) points towards the target position, and it's up axis
+// (+Z) is as close to the given up vector as possible. The target and up
+// vectors are in the same (i.e. parent) space as the position of this
+// transform.
+//
+// If the target is the position of this transform, the rotation is reset.
+func (t *Transform) LookAt(target, up lmath.Vec3) {
+	t.SetQuat(lmath.QuatLookAt(target.Sub(t.Pos()), up, lmath.CoordSysZUpRight))
+}
+
 // Reset sets all of the values of this transform to the default ones.
 func (t *Transform) Reset() {
 	t.access.Lock()
@@ -136,6 +136,42 @@ func TestTransformQuat(t *testing.T) {
 	}
 }
 
+func TestTransformTRS(t *testing.T) {
+	trs := lmath.Transform{
+		Pos:   lmath.Vec3{1, 2, 3},
+		Rot:   lmath.QuatFromAxisAngle(lmath.Vec3{0, 0, 1}, 0.5),
+		Scale: lmath.Vec3{2, 2, 2},
+	}
+
+	a := NewTransform()
+	a.SetTRS(trs)
+	if a.TRS() != trs {
+		t.Log("got", a.TRS())
+		t.Log("want", trs)
+		t.Fail()
+	}
+	if !a.Mat4().AlmostEquals(trs.Mat4(), 1e-9) {
+		t.Log("got", a.Mat4())
+		t.Log("want", trs.Mat4())
+		t.Fail()
+	}
+}
+
+func TestTransformLookAt(t *testing.T) {
+	a := NewTransform()
+	a.SetPos(lmath.Vec3{X: 1, Y: 1, Z: 0})
+	a.LookAt(lmath.Vec3{X: 1, Y: 1, Z: 5}, lmath.Vec3{Z: 1})
+
+	// The forward axis must point towards the target, even though it is
+	// straight above (i.e. parallel to the up vector).
+	p := a.ConvertPos(lmath.Vec3{Y: 5}, LocalToWorld)
+	if want := (lmath.Vec3{X: 1, Y: 1, Z: 5}); !p.AlmostEquals(want, 1e-9) {
+		t.Log("got", p)
+		t.Log("want", want)
+		t.Fail()
+	}
+}
+
 func BenchmarkTransformPos(b *testing.B) {
 	a := NewTransform()
 	positions := [2]lmath.Vec3{
@@ -0,0 +1,54 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package water
+
+import (
+	"image"
+
+	"azul3d.org/engine/gfx"
+)
+
+// NewCanvases creates the render-to-texture canvases of the given size that
+// the reflection and the depth of the scene are drawn into, and sets the
+// Reflection and Depth textures of the configuration to them.
+//
+// If the device does not support render-to-texture, or it does not support a
+// depth format that can be used as a texture, the respective canvas is nil
+// and the texture is left unset.
+func NewCanvases(d gfx.Device, b image.Rectangle, c *Config) (reflection, depth gfx.Canvas) {
+	formats := d.Info().RTTFormats
+
+	// The reflection has a depth buffer of it's own, which is not needed as a
+	// texture.
+	cfg := formats.ChooseConfig(d.Precision(), false)
+	cfg.Bounds = b
+	cfg.Color = gfx.NewTexture()
+	if cfg.ColorFormat != gfx.ZeroTexFormat {
+		if reflection = d.RenderToTexture(cfg); reflection != nil {
+			c.Reflection = cfg.Color
+		}
+	}
+
+	// Combined depth and stencil formats cannot be used as textures, so
+	// choose the most precise depth-only format.
+	var best gfx.DSFormat
+	for _, f := range formats.DepthFormats {
+		if f.IsDepth() && !f.IsCombined() && f.DepthBits() > best.DepthBits() {
+			best = f
+		}
+	}
+	if best == gfx.ZeroDSFormat {
+		return
+	}
+	cfg = gfx.RTTConfig{
+		Bounds:      b,
+		Depth:       gfx.NewTexture(),
+		DepthFormat: best,
+	}
+	if depth = d.RenderToTexture(cfg); depth != nil {
+		c.Depth = cfg.Depth
+	}
+	return
+}
@@ -0,0 +1,53 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package water renders water surfaces animated by Gerstner waves, with
+// planar reflections and depth-based blending at the shore.
+//
+// A Water is a grid displaced on the GPU by the sum of a few Gerstner waves,
+// which move the surface in circles to form sharp crests and wide troughs.
+// The same waves are evaluated on the CPU by HeightAt, e.g. to keep floating
+// objects on the surface.
+//
+// The reflection and the depth of the scene are drawn into render-to-texture
+// canvases (see NewCanvases) before the water is drawn, using the reflected
+// camera (see Reflect) and the real one, respectively:
+//
+//  cfg := water.Config{Target: glsl.GL2}
+//  reflection, depth := water.NewCanvases(d, d.Bounds(), &cfg)
+//  w := water.New(cfg)
+//  w.Waves = []water.Wave{
+//      {Direction: lmath.Vec2{1, 0}, Wavelength: 30, Amplitude: 0.4, Steepness: 0.6},
+//      {Direction: lmath.Vec2{1, 0.6}, Wavelength: 12, Amplitude: 0.15, Steepness: 0.8},
+//  }
+//  for {
+//      w.Update(t)
+//      mirrorCam := w.Reflect(cam)
+//      reflection.Clear(reflection.Bounds(), skyColor)
+//      reflection.ClearDepth(reflection.Bounds(), 1.0)
+//      for _, o := range mirroredScene {
+//          reflection.Draw(reflection.Bounds(), o, mirrorCam)
+//      }
+//      depth.ClearDepth(depth.Bounds(), 1.0)
+//      for _, o := range scene {
+//          depth.Draw(depth.Bounds(), o, cam)
+//      }
+//      reflection.Render()
+//      depth.Render()
+//
+//      // Draw the scene to the screen, then the water on top of it.
+//      ...
+//      d.Draw(d.Bounds(), w.Object, cam)
+//  }
+//
+// Objects drawn with the reflected camera must use a state with their face
+// culling swapped, see MirroredState.
+//
+// Either canvas may be omitted: without a reflection the water is drawn in
+// it's color alone, and without the depth of the scene it does not fade out
+// at the shore.
+//
+// As with the rest of the engine, the world is Z-up: the surface lies on the
+// X/Y plane of it's object.
+package water // import "azul3d.org/engine/gfx/water"
@@ -0,0 +1,111 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package water
+
+// The GLSL sources of the water shader, written in GLSL 1.20 style and
+// preprocessed using package glsl.
+const (
+	waterVert = `
+uniform mat4 Model;
+uniform mat4 View;
+uniform mat4 Projection;
+
+// The direction, wave number and phase of each wave, and it's amplitude and
+// steepness factor.
+uniform vec4 Waves[MAX_WAVES];
+uniform vec4 WaveShapes[MAX_WAVES];
+
+attribute vec3 Vertex;
+
+varying vec3 viewPos;
+varying vec3 viewNormal;
+varying vec4 clipPos;
+
+void main(void) {
+	// The waves are evaluated in world space, such that they line up across
+	// neighbouring surfaces.
+	vec4 world = Model * vec4(Vertex, 1.0);
+	vec3 p = world.xyz;
+	vec3 n = vec3(0.0, 0.0, 1.0);
+	for (int i = 0; i < MAX_WAVES; i++) {
+		vec2 dir = Waves[i].xy;
+		float k = Waves[i].z;
+		float a = WaveShapes[i].x;
+		float q = WaveShapes[i].y;
+		float theta = k * dot(dir, world.xy) - Waves[i].w;
+		float s = sin(theta);
+		float c = cos(theta);
+		p.xy += q * a * dir * c;
+		p.z += a * s;
+		n.xy -= dir * k * a * c;
+		n.z -= q * k * a * s;
+	}
+
+	vec4 v = View * vec4(p, 1.0);
+	viewPos = v.xyz;
+	viewNormal = mat3(View[0].xyz, View[1].xyz, View[2].xyz) * n;
+	clipPos = Projection * v;
+	gl_Position = clipPos;
+}
+`
+
+	waterFrag = `
+uniform mat4 View;
+uniform mat4 Projection;
+
+#ifdef REFLECTION
+uniform sampler2D Texture0;
+uniform float Distortion;
+#endif
+#ifdef SHORELINE
+uniform sampler2D DEPTH_TEXTURE;
+uniform float ShoreDepth;
+#endif
+
+uniform vec4 WaterColor;
+uniform vec3 LightDirection;
+uniform vec4 LightColor;
+uniform float Shininess;
+
+varying vec3 viewPos;
+varying vec3 viewNormal;
+varying vec4 clipPos;
+
+#ifdef SHORELINE
+// linearDepth returns the view space distance of a value in the depth buffer,
+// which was written using the perspective projection matrix.
+float linearDepth(float d) {
+	return Projection[3][2] / (d * 2.0 - 1.0 + Projection[2][2]);
+}
+#endif
+
+void main(void) {
+	vec3 n = normalize(viewNormal);
+	vec3 v = normalize(-viewPos);
+	vec2 screen = clipPos.xy / clipPos.w * 0.5 + 0.5;
+	vec4 c = WaterColor;
+
+#ifdef REFLECTION
+	// Schlick's approximation of the Fresnel term of water.
+	float fresnel = 0.02 + 0.98 * pow(1.0 - max(dot(n, v), 0.0), 5.0);
+	vec3 reflected = texture2D(Texture0, screen + n.xy * Distortion).rgb;
+	c.rgb = mix(c.rgb, reflected, fresnel);
+	c.a = mix(c.a, 1.0, fresnel);
+#endif
+
+	// Specular highlights of the light, in view space.
+	vec3 l = normalize(-(mat3(View[0].xyz, View[1].xyz, View[2].xyz) * LightDirection));
+	vec3 h = normalize(l + v);
+	c.rgb += LightColor.rgb * pow(max(dot(n, h), 0.0), Shininess);
+
+#ifdef SHORELINE
+	// Fade the water out where the scene behind it is close to the surface.
+	float scene = linearDepth(texture2D(DEPTH_TEXTURE, screen).r);
+	c.a *= clamp((scene + viewPos.z) / ShoreDepth, 0.0, 1.0);
+#endif
+	gl_FragColor = c;
+}
+`
+)
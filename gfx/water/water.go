@@ -0,0 +1,260 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package water
+
+import (
+	"math"
+	"strconv"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/glsl"
+	"azul3d.org/engine/gfx/portal"
+	"azul3d.org/engine/lmath"
+)
+
+// MaxWaves is the maximum number of waves of a water surface.
+const MaxWaves = 8
+
+// Config configures a water surface. Zero fields take their default values.
+type Config struct {
+	// Size is the length of each side of the square surface, in world units.
+	// The default is 256.
+	Size float64
+
+	// Resolution is the number of quads along each side of the surface. The
+	// default is 128.
+	Resolution int
+
+	// Target is the backend that the shader is preprocessed for.
+	Target glsl.Target
+
+	// Reflection is the color texture of a render-to-texture canvas that the
+	// scene is drawn into using the reflected camera (see Reflect), at the
+	// same size as the canvas the water is drawn to. If nil, the water does
+	// not reflect the scene.
+	Reflection *gfx.Texture
+
+	// Depth is the depth texture of a render-to-texture canvas that the
+	// opaque scene (without the water) is drawn into using the camera, at
+	// the same size as the canvas the water is drawn to. If nil, the water
+	// does not blend into the shore.
+	Depth *gfx.Texture
+}
+
+// withDefaults returns the configuration with zero fields set to their
+// default values.
+func (c Config) withDefaults() Config {
+	if c.Size <= 0 {
+		c.Size = 256
+	}
+	if c.Resolution <= 0 {
+		c.Resolution = 128
+	}
+	return c
+}
+
+// Water is a water surface, drawn as a grid displaced by waves.
+//
+// The surface lies on the X/Y plane of it's object, whose transform should
+// only be translated (the waves are evaluated in world space).
+//
+// A water surface is not safe for access from multiple goroutines
+// concurrently.
+type Water struct {
+	// The object drawn for the surface. It's shader, state and textures are
+	// set up by New.
+	*gfx.Object
+
+	// The configuration of the surface, with defaults applied. It must not
+	// be modified.
+	Config
+
+	// Waves are the waves of the surface, at most MaxWaves of them.
+	Waves []Wave
+
+	// Color is the color of the water in linear space, where the alpha is
+	// the opacity of deep water. The default is a dark blue-green.
+	Color gfx.Color
+
+	// ShoreDepth is the depth of the water (i.e. the distance between the
+	// surface and the scene behind it) over which it fades in at the shore,
+	// in world units. The default is 1.
+	ShoreDepth float64
+
+	// Distortion is how far the waves distort the reflection, in screen
+	// space units. The default is 0.02.
+	Distortion float64
+
+	// The direction the light travels in, it's color and the specular
+	// exponent of the highlights it casts on the surface.
+	LightDirection lmath.Vec3
+	LightColor     gfx.Color
+	Shininess      float64
+
+	// The wave inputs, reused each frame.
+	waves, shapes []gfx.Vec4
+}
+
+// Plane returns the world space plane of the surface at rest, facing up.
+func (w *Water) Plane() portal.Plane {
+	return portal.PlaneFromPoint(w.Transform.Pos(), lmath.Vec3{Z: 1})
+}
+
+// Reflect returns the camera that the reflection is drawn with: the given
+// camera reflected about the surface's plane, with objects below the surface
+// clipped away. Objects drawn with it must use a mirrored state (see
+// MirroredState).
+//
+// The camera must be recreated whenever the given camera (or it's projection)
+// changes.
+func (w *Water) Reflect(cam gfx.Camera) *portal.Camera {
+	return portal.Mirror(cam, w.Plane())
+}
+
+// MirroredState returns a copy of the given state (which may be nil) with
+// front and back face culling swapped, as is needed to draw an object using
+// the reflected camera.
+func MirroredState(s *gfx.State) *gfx.State {
+	m := gfx.NewState()
+	if s != nil {
+		*m = *s
+	}
+	switch m.FaceCulling {
+	case gfx.BackFaceCulling:
+		m.FaceCulling = gfx.FrontFaceCulling
+	case gfx.FrontFaceCulling:
+		m.FaceCulling = gfx.BackFaceCulling
+	}
+	return m
+}
+
+// HeightAt returns the world space height of the surface at the given world
+// space X and Y coordinates at time t (in seconds), see HeightAt.
+func (w *Water) HeightAt(x, y, t float64) float64 {
+	return w.Transform.Pos().Z + HeightAt(w.Waves, x, y, t)
+}
+
+// Update updates the shader inputs of the surface for the given time, in
+// seconds. It must be called before the surface is drawn, after any changes
+// to it's fields.
+func (w *Water) Update(t float64) {
+	waves := w.Waves
+	if len(waves) > MaxWaves {
+		waves = waves[:MaxWaves]
+	}
+
+	// Unused waves are left with a zero amplitude.
+	var horizontal, vertical float64
+	for i := range w.waves {
+		w.waves[i], w.shapes[i] = gfx.Vec4{}, gfx.Vec4{}
+		if i >= len(waves) {
+			continue
+		}
+		wave := waves[i]
+		dir, k, omega, q := wave.params(len(waves))
+		w.waves[i] = gfx.Vec4{
+			X: float32(dir.X),
+			Y: float32(dir.Y),
+			Z: float32(k),
+			W: float32(phase(omega, t)),
+		}
+		w.shapes[i] = gfx.Vec4{X: float32(wave.Amplitude), Y: float32(q)}
+		horizontal += math.Abs(q * wave.Amplitude)
+		vertical += math.Abs(wave.Amplitude)
+	}
+
+	in := w.Shader.Inputs
+	in["Waves"] = w.waves
+	in["WaveShapes"] = w.shapes
+	in["WaterColor"] = w.Color
+	in["LightDirection"] = gfx.ConvertVec3(w.LightDirection)
+	in["LightColor"] = w.LightColor
+	in["Shininess"] = float32(w.Shininess)
+	if w.Reflection != nil {
+		in["Distortion"] = float32(w.Distortion)
+	}
+	if w.Depth != nil {
+		in["ShoreDepth"] = float32(w.ShoreDepth)
+	}
+
+	// Grow the bounds of the grid by the furthest the waves may displace it.
+	m := w.Meshes[0]
+	half := w.Size / 2
+	m.AABB = lmath.Rect3{
+		Min: lmath.Vec3{X: -half - horizontal, Y: -half - horizontal, Z: -vertical},
+		Max: lmath.Vec3{X: half + horizontal, Y: half + horizontal, Z: vertical},
+	}
+	w.CachedBounds = nil
+}
+
+// grid returns a flat square grid mesh of the given size and resolution,
+// centered at the origin.
+func grid(size float64, res int) *gfx.Mesh {
+	m := gfx.NewMesh()
+	m.Vertices = make([]gfx.Vec3, 0, (res+1)*(res+1))
+	for y := 0; y <= res; y++ {
+		for x := 0; x <= res; x++ {
+			m.Vertices = append(m.Vertices, gfx.Vec3{
+				X: float32((float64(x)/float64(res) - 0.5) * size),
+				Y: float32((float64(y)/float64(res) - 0.5) * size),
+			})
+		}
+	}
+	m.Indices = make([]uint32, 0, res*res*6)
+	for y := 0; y < res; y++ {
+		for x := 0; x < res; x++ {
+			a := uint32(y*(res+1) + x)
+			b, c, d := a+1, a+1+uint32(res+1), a+uint32(res+1)
+			m.Indices = append(m.Indices, a, b, c, a, c, d)
+		}
+	}
+	return m
+}
+
+// New returns a new water surface with the given configuration.
+func New(c Config) *Water {
+	c = c.withDefaults()
+	w := &Water{
+		Object:         gfx.NewObject(),
+		Config:         c,
+		Color:          gfx.Color{R: 0.01, G: 0.06, B: 0.08, A: 0.9},
+		ShoreDepth:     1,
+		Distortion:     0.02,
+		LightDirection: lmath.Vec3{X: 0.5, Y: 0.5, Z: -1},
+		LightColor:     gfx.Color{R: 1, G: 1, B: 1, A: 1},
+		Shininess:      128,
+		waves:          make([]gfx.Vec4, MaxWaves),
+		shapes:         make([]gfx.Vec4, MaxWaves),
+	}
+	w.Meshes = []*gfx.Mesh{grid(c.Size, c.Resolution)}
+
+	// The textures are bound in order, the depth texture following the
+	// reflection (if any).
+	defines := map[string]string{"MAX_WAVES": strconv.Itoa(MaxWaves)}
+	if c.Reflection != nil {
+		defines["REFLECTION"] = "1"
+		w.Textures = append(w.Textures, c.Reflection)
+	}
+	if c.Depth != nil {
+		defines["SHORELINE"] = "1"
+		defines["DEPTH_TEXTURE"] = "Texture" + strconv.Itoa(len(w.Textures))
+		w.Textures = append(w.Textures, c.Depth)
+	}
+	pp := &glsl.Preprocessor{Target: c.Target, Defines: defines}
+	s, err := pp.Shader("Water", []byte(waterVert), []byte(waterFrag))
+	if err != nil {
+		// Only possible with a bug in the shader's sources.
+		panic(err)
+	}
+	w.Shader = s
+
+	// The surface is visible from below as well, and blends into the scene
+	// behind it.
+	w.State = gfx.NewState()
+	w.State.FaceCulling = gfx.NoFaceCulling
+	w.State.AlphaMode = gfx.AlphaBlend
+	w.Update(0)
+	return w
+}
@@ -0,0 +1,101 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package water
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/lmath"
+)
+
+func TestDisplace(t *testing.T) {
+	// Without steepness a wave is a plain sine wave.
+	waves := []Wave{{Direction: lmath.Vec2{X: 2}, Wavelength: 4, Amplitude: 0.5, Speed: 1}}
+	for _, x := range []float64{0, 0.5, 1, 3.2} {
+		d := Displace(waves, x, 7, 0.25)
+		want := 0.5 * math.Sin(2*math.Pi/4*(x-0.25))
+		if d.X != 0 || d.Y != 0 || math.Abs(d.Z-want) > 1e-9 {
+			t.Fatalf("x=%v: got %v, want Z=%v", x, d, want)
+		}
+	}
+
+	// With steepness, points move toward the crests.
+	waves[0].Steepness = 1
+	if d := Displace(waves, 0, 0, 0); math.Abs(d.X-1/(2*math.Pi/4)) > 1e-9 {
+		t.Fatalf("got %v", d)
+	}
+
+	// The normal leans back against the rising front of the wave.
+	k := 2 * math.Pi / 4 * 0.5
+	if n := Normal(waves, 0, 0, 0); math.Abs(n.X+k/math.Sqrt(1+k*k)) > 1e-9 || n.Y != 0 {
+		t.Fatalf("got normal %v", n)
+	}
+}
+
+func TestHeightAt(t *testing.T) {
+	waves := []Wave{
+		{Direction: lmath.Vec2{X: 1}, Wavelength: 20, Amplitude: 0.5, Steepness: 0.7},
+		{Direction: lmath.Vec2{X: 1, Y: 1}, Wavelength: 7, Amplitude: 0.2, Steepness: 0.5},
+	}
+	for _, p := range []lmath.Vec2{{X: 0, Y: 0}, {X: 3, Y: -2}, {X: 11.5, Y: 4}} {
+		// The point on the rest plane displaced to p is found, such that
+		// displacing it again gives the same height.
+		z := HeightAt(waves, p.X, p.Y, 1.5)
+		d := Displace(waves, p.X, p.Y, 1.5)
+		x, y := p.X-d.X, p.Y-d.Y
+		for i := 0; i < 32; i++ {
+			d = Displace(waves, x, y, 1.5)
+			x, y = p.X-d.X, p.Y-d.Y
+		}
+		if math.Abs(z-d.Z) > 1e-3 {
+			t.Errorf("%v: got %v, want %v", p, z, d.Z)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	w := New(Config{Size: 10, Resolution: 4})
+	if len(w.Meshes[0].Vertices) != 25 || len(w.Meshes[0].Indices) != 4*4*6 {
+		t.Fatalf("got grid of %d vertices", len(w.Meshes[0].Vertices))
+	}
+	if len(w.Textures) != 0 || strings.Contains(string(w.Shader.GLSL.Fragment), "#define REFLECTION") {
+		t.Fatal("reflection without a texture")
+	}
+
+	w.Waves = []Wave{{Direction: lmath.Vec2{Y: 1}, Wavelength: 8, Amplitude: 2}}
+	w.Update(1)
+	waves := w.Shader.Inputs["Waves"].([]gfx.Vec4)
+	if len(waves) != MaxWaves || waves[0].Y != 1 || waves[1] != (gfx.Vec4{}) {
+		t.Fatalf("got wave inputs %v", waves)
+	}
+	if b := w.Bounds(); b.Max.Z != 2 || b.Min.Z != -2 {
+		t.Fatalf("bounds %v", b)
+	}
+
+	// The depth texture follows the reflection.
+	refl, depth := gfx.NewTexture(), gfx.NewTexture()
+	w = New(Config{Reflection: refl, Depth: depth})
+	if len(w.Textures) != 2 || w.Textures[1] != depth {
+		t.Fatal("textures not bound in order")
+	}
+	if !strings.Contains(string(w.Shader.GLSL.Fragment), "#define DEPTH_TEXTURE Texture1\n") {
+		t.Fatalf("missing DEPTH_TEXTURE define:\n%s", w.Shader.GLSL.Fragment)
+	}
+}
+
+func TestReflect(t *testing.T) {
+	w := New(Config{Resolution: 1})
+	w.Transform.SetPos(lmath.Vec3{Z: 3})
+	p := w.Plane()
+	if p.Normal != (lmath.Vec3{Z: 1}) || p.Dist != 3 {
+		t.Fatalf("plane %v", p)
+	}
+	if s := MirroredState(nil); s.FaceCulling != gfx.FrontFaceCulling {
+		t.Fatalf("got %v", s.FaceCulling)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package water
+
+import (
+	"math"
+
+	"azul3d.org/engine/lmath"
+)
+
+// Gravity is the gravitational acceleration used to derive the speed of waves
+// from their wavelength, in world units per second squared.
+const Gravity = 9.81
+
+// Wave is a single Gerstner (trochoidal) wave. The surface of the water is
+// the sum of each of it's waves.
+type Wave struct {
+	// Direction is the direction the wave travels in across the X/Y plane,
+	// it need not be normalized.
+	Direction lmath.Vec2
+
+	// Wavelength is the distance between two crests, in world units.
+	Wavelength float64
+
+	// Amplitude is the height of a crest above the rest level of the water,
+	// in world units.
+	Amplitude float64
+
+	// Steepness controls the sharpness of the crests, from zero (a plain sine
+	// wave) to one (the sharpest crests possible, when combined with the
+	// other waves, without the surface looping over itself).
+	Steepness float64
+
+	// Speed is the speed of the wave's crests in world units per second. If
+	// zero, it is that of a wave of the given wavelength in deep water.
+	Speed float64
+}
+
+// params returns the parameters of the wave as one of n waves: the normalized
+// direction, the wave number, the angular frequency and the steepness factor
+// of the horizontal displacement.
+func (w Wave) params(n int) (dir lmath.Vec2, k, omega, q float64) {
+	dir, _ = w.Direction.Normalized()
+	if w.Wavelength <= 0 {
+		return dir, 0, 0, 0
+	}
+	k = 2 * math.Pi / w.Wavelength
+	speed := w.Speed
+	if speed == 0 {
+		speed = math.Sqrt(Gravity / k)
+	}
+	omega = speed * k
+	if w.Amplitude != 0 {
+		q = w.Steepness / (k * w.Amplitude * float64(n))
+	}
+	return
+}
+
+// phase returns the phase of the wave at time t (in seconds), wrapped to the
+// range [0, 2*Pi) such that it may be given to the GPU with little loss of
+// precision.
+func phase(omega, t float64) float64 {
+	return math.Mod(omega*t, 2*math.Pi)
+}
+
+// Displace returns the displacement by the waves of the point at (x, y) on
+// the rest plane of the water at time t, in seconds. The point moves in
+// circles, such that it's displacement has horizontal components as well as
+// a vertical one.
+func Displace(waves []Wave, x, y, t float64) lmath.Vec3 {
+	var d lmath.Vec3
+	for _, w := range waves {
+		dir, k, omega, q := w.params(len(waves))
+		theta := k*(dir.X*x+dir.Y*y) - phase(omega, t)
+		s, c := math.Sincos(theta)
+		d.X += q * w.Amplitude * dir.X * c
+		d.Y += q * w.Amplitude * dir.Y * c
+		d.Z += w.Amplitude * s
+	}
+	return d
+}
+
+// Normal returns the normal of the surface at the displaced position of the
+// point at (x, y) on the rest plane of the water, at time t.
+func Normal(waves []Wave, x, y, t float64) lmath.Vec3 {
+	n := lmath.Vec3{Z: 1}
+	for _, w := range waves {
+		dir, k, omega, q := w.params(len(waves))
+		theta := k*(dir.X*x+dir.Y*y) - phase(omega, t)
+		s, c := math.Sincos(theta)
+		ka := k * w.Amplitude
+		n.X -= dir.X * ka * c
+		n.Y -= dir.Y * ka * c
+		n.Z -= q * ka * s
+	}
+	n, _ = n.Normalized()
+	return n
+}
+
+// HeightAt returns the height of the surface above the rest plane of the
+// water at (x, y) at time t, e.g. for floating objects on it.
+//
+// Because the waves displace the surface horizontally, the point on the rest
+// plane which ends up at (x, y) is found iteratively; the result is accurate
+// to within a small fraction of the amplitude of the waves.
+func HeightAt(waves []Wave, x, y, t float64) float64 {
+	px, py := x, y
+	for i := 0; i < 4; i++ {
+		d := Displace(waves, px, py, t)
+		px, py = x-d.X, y-d.Y
+	}
+	return Displace(waves, px, py, t).Z
+}
@@ -0,0 +1,113 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package window
+
+import "sync"
+
+// coalesce merges event b into event a (which occured first), returning the
+// merged event and true, or false if the two events cannot be merged.
+func coalesce(a, b Event) (Event, bool) {
+	switch av := a.(type) {
+	case CursorMoved:
+		bv, ok := b.(CursorMoved)
+		if !ok || av.Delta != bv.Delta || av.Raw != bv.Raw {
+			return nil, false
+		}
+		if bv.Delta {
+			// Relative motion accumulates.
+			bv.X += av.X
+			bv.Y += av.Y
+		}
+		return bv, true
+	case Moved:
+		_, ok := b.(Moved)
+		return b, ok
+	case Resized:
+		_, ok := b.(Resized)
+		return b, ok
+	case FramebufferResized:
+		_, ok := b.(FramebufferResized)
+		return b, ok
+	case ContentScaleChanged:
+		_, ok := b.(ContentScaleChanged)
+		return b, ok
+	}
+	return nil, false
+}
+
+// Coalesce coalesces high-frequency events in the given slice, returning the
+// coalesced events. Consecutive events of the following types are merged into
+// a single one:
+//
+//  CursorMoved (absolute positions keep the last one, deltas are summed)
+//  Moved
+//  Resized
+//  FramebufferResized
+//  ContentScaleChanged
+//
+// Only consecutive events are merged, so the order of all events relative to
+// each other is preserved (e.g. a click always happens at the position of the
+// cursor movement preceding it). The time of a merged event is that of the
+// last event merged into it.
+//
+// The slice is modified in-place.
+func Coalesce(events []Event) []Event {
+	if len(events) == 0 {
+		return events
+	}
+	out := events[:1]
+	for _, ev := range events[1:] {
+		if merged, ok := coalesce(out[len(out)-1], ev); ok {
+			out[len(out)-1] = merged
+			continue
+		}
+		out = append(out, ev)
+	}
+	return out
+}
+
+// PollCoalesced is like Poll, except that the pending events are coalesced
+// (see Coalesce) before f is called for each of them. This is useful for e.g.
+// handling mouse movement once per frame, rather than for each of possibly
+// hundreds of events:
+//
+//  for {
+//      window.PollCoalesced(events, func(e window.Event) {
+//          fmt.Println("event", e)
+//      })
+//
+//      fmt.Println("render!")
+//  }
+//
+func PollCoalesced(events <-chan Event, f func(e Event)) {
+	l := len(events)
+	if l == 0 {
+		return
+	}
+	buf := pollBuffers.Get().(*[]Event)
+	pending := (*buf)[:0]
+	for i := 0; i < l; i++ {
+		pending = append(pending, <-events)
+	}
+	for _, e := range Coalesce(pending) {
+		f(e)
+	}
+
+	// Clear the events, such that the buffer does not keep them alive.
+	for i := range pending {
+		pending[i] = nil
+	}
+	*buf = pending[:0]
+	pollBuffers.Put(buf)
+}
+
+// pollBuffers holds the buffers of PollCoalesced, which are reused between
+// calls (f may call it again, e.g. for another channel, so there may be more
+// than one in use at a time).
+var pollBuffers = sync.Pool{
+	New: func() interface{} {
+		return new([]Event)
+	},
+}
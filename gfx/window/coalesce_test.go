@@ -0,0 +1,52 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package window
+
+import (
+	"reflect"
+	"testing"
+
+	"azul3d.org/engine/keyboard"
+)
+
+func TestCoalesce(t *testing.T) {
+	typed := keyboard.Typed{S: "a"}
+	events := []Event{
+		CursorMoved{X: 1, Y: 1},
+		CursorMoved{X: 2, Y: 3},
+		typed,
+		CursorMoved{X: 1, Y: 1, Delta: true},
+		CursorMoved{X: 2, Y: -3, Delta: true},
+		CursorMoved{X: 5, Y: 5},
+		Resized{Width: 1, Height: 1},
+		Resized{Width: 2, Height: 2},
+	}
+	want := []Event{
+		CursorMoved{X: 2, Y: 3},
+		typed,
+		CursorMoved{X: 3, Y: -2, Delta: true},
+		CursorMoved{X: 5, Y: 5},
+		Resized{Width: 2, Height: 2},
+	}
+	got := Coalesce(events)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v\nwant %v", got, want)
+	}
+}
+
+func TestPollCoalesced(t *testing.T) {
+	ch := make(chan Event, 4)
+	ch <- CursorMoved{X: 1}
+	ch <- CursorMoved{X: 2}
+	ch <- Close{}
+	var got []Event
+	PollCoalesced(ch, func(e Event) {
+		got = append(got, e)
+	})
+	want := []Event{CursorMoved{X: 2}, Close{}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package window
+
+import "image"
+
+// CursorShape is a standard mouse cursor shape provided by the system.
+type CursorShape uint8
+
+// Standard cursor shapes.
+const (
+	// ArrowCursor is the regular arrow cursor.
+	ArrowCursor CursorShape = iota
+
+	// IBeamCursor is the text input I-beam cursor.
+	IBeamCursor
+
+	// CrosshairCursor is the crosshair cursor.
+	CrosshairCursor
+
+	// HandCursor is the hand (e.g. hovering a link) cursor.
+	HandCursor
+
+	// HResizeCursor is the horizontal resize arrow cursor.
+	HResizeCursor
+
+	// VResizeCursor is the vertical resize arrow cursor.
+	VResizeCursor
+)
+
+// Cursor describes the appearance of the mouse cursor while it is over a
+// window. Because it is displayed by the system (i.e. a hardware cursor) it
+// moves without the latency of rendering it yourself.
+//
+// A cursor must not be modified once it has been given to a window via
+// Props.SetCursor, instead create a new one:
+//
+//  props.SetCursor(&window.Cursor{
+//      Image: swordImage,
+//      HotX:  2,
+//      HotY:  2,
+//  })
+//
+type Cursor struct {
+	// The standard shape of the cursor, used if Image is nil.
+	Shape CursorShape
+
+	// A custom image for the cursor, or nil to use the standard shape.
+	Image image.Image
+
+	// The hotspot of the cursor (i.e. the point which clicks) in pixels,
+	// relative to the upper-left corner of the image.
+	HotX, HotY int
+}
@@ -48,6 +48,40 @@
 //  // Less efficient:
 //  // w.Notify(events, window.AllEvents)
 //
+// High-frequency events, such as cursor movement, can be coalesced such that
+// they are handled once per frame instead of once per event:
+//
+//  window.PollCoalesced(events, func(e window.Event) {
+//      ... handle e ...
+//  })
+//
+// Input State
+//
+// The current state of input devices can be queried at any time without
+// consuming any events, which is often simpler than tracking it yourself:
+//
+//  w.Keyboard().Down(keyboard.W)       // Is the W key held down?
+//  w.Mouse().Down(mouse.Left)          // Is the left mouse button held down?
+//  x, y := w.Props().CursorPos()       // Where is the cursor?
+//  w.Gamepads().Axis(0, gamepad.LeftX) // Where is the left stick?
+//
+// Drag and Drop
+//
+// Files dragged and dropped onto the window (e.g. from a file manager) are
+// delivered as an ItemsDropped event, which editors and viewers can use to
+// open the dropped assets:
+//
+//  w.Notify(events, window.ItemsDroppedEvents)
+//  for e := range events {
+//      for _, path := range e.(window.ItemsDropped).Items {
+//          ... open the file at path ...
+//      }
+//  }
+//
+// Touch events (see the touch package) are delivered with the TouchEvents
+// mask on platforms that have touch screens. Note that the GLFW backend used on
+// desktop platforms only supports touch input on Windows 7 and later.
+//
 // Properties
 //
 // Creating window properties (such as a window's title, position, size,
@@ -69,7 +103,7 @@
 //  window.Run(gfxLoop, props)
 //
 //  // Or when creating multiple windows:
-//  window.New(gfxLoop, props)
+//  window.New(props)
 //
 // Changing Properties
 //
@@ -85,6 +119,20 @@
 //  // Request the new window properties.
 //  myWindow.Request(props)
 //
+// HiDPI Displays
+//
+// On HiDPI ("retina") displays the framebuffer of a window has more pixels
+// than the window has screen coordinates. The ratio between the two is the
+// window's content scale, which user interfaces should scale by:
+//
+//  scaleX, scaleY := w.Props().ContentScale()
+//
+// A ContentScaleChanged event is sent when it changes, e.g. when the window is
+// dragged onto a monitor with a different pixel density. To specify the window
+// size in framebuffer pixels instead, use:
+//
+//  props.SetPixelExact(true)
+//
 // FPS in Title
 //
 // If the window title contains a "{FPS}" string inside of it, it will be
@@ -126,6 +174,48 @@
 //      window.Run(gfxLoop, nil)
 //  }
 //
+// Spawn is a shorthand for the above: it creates the window and runs the
+// graphics loop in a new goroutine for you, which suits tools that have e.g.
+// an editor and a preview window:
+//
+//  err := window.Spawn(previewLoop, previewProps)
+//
+// Closing one window does not affect the others; the main loop exits once the
+// last window has been closed.
+//
+// Shared Resources
+//
+// All windows share GPU resources with each other. Meshes, textures and
+// shaders are loaded by a hidden asset context that every window's context
+// shares objects with, so an asset loaded through one window's device can be
+// drawn by any other window's device:
+//
+//  // Load the texture through the editor window's device.
+//  d.LoadTexture(tex, nil)
+//
+//  // ... and draw it in the preview window as well.
+//  d2.Draw(d2.Bounds(), previewObj, cam)
+//
+// Assets outlive the window whose device loaded them, they are only released
+// when they are destroyed or when the last window is closed. Render-to-texture
+// canvases and other device state (clear colors, scissor, etc) are not shared;
+// they belong to a single window.
+//
+// Threading Model
+//
+// Each window is driven by its own goroutine which is locked to an OS thread
+// and owns the window's OpenGL context. The device returned for a window only
+// submits work to that goroutine, so any goroutine may issue draw calls to it
+// (but as usual, the frame is only finished once Render is called).
+//
+// Each window likewise delivers its events independently: a channel passed to
+// Notify only receives the events of the window it was registered with, so
+// each window may have its own event loop in its own goroutine.
+//
+// Window creation, destruction and event polling happen on the main thread via
+// the main loop (see the Main Thread section below). Window and device
+// methods are safe for concurrent use.
+//
 // If you prefer not to use the simple Run function, you can use the New and
 // MainLoop functions yourself. The only restriction is that New cannot
 // complete unless MainLoop is already running.
@@ -185,6 +275,18 @@
 // useful for testing how your application might run under more constrained
 // OpenGL ES 2 devices (i.e. mobile devices).
 //
+// The build tag "wayland" is accepted on Linux and FreeBSD to create native
+// Wayland windows instead of X11 ones (which run under XWayland on Wayland
+// desktops, and are blurry when scaled on HiDPI displays). Under Wayland the
+// content scale is the buffer scale of the output the window is on, and the
+// window position cannot be set (the compositor places windows).
+//
+// Mobile Platforms
+//
+// Android and iOS are not supported yet. A window backend for them needs an
+// OpenGL ES 2 device and application lifecycle events (e.g. for pausing when
+// sent to the background), neither of which exist yet.
+//
 // Examples
 //
 // The examples repository contains several examples which utilize the gfx core
@@ -73,6 +73,27 @@ const (
 	// KeyboardButtonEvents is a event mask matching keyboard.ButtonEvent's.
 	KeyboardButtonEvents
 
+	// ContentScaleChangedEvents is a event mask matching
+	// window.ContentScaleChanged events.
+	ContentScaleChangedEvents
+
+	// GamepadConnectionEvents is a event mask matching gamepad.Connected and
+	// gamepad.Disconnected events.
+	GamepadConnectionEvents
+
+	// GamepadButtonEvents is a event mask matching gamepad.ButtonEvent's.
+	GamepadButtonEvents
+
+	// GamepadAxisEvents is a event mask matching gamepad.AxisMoved events.
+	GamepadAxisEvents
+
+	// TouchEvents is a event mask matching touch.Event's.
+	TouchEvents
+
+	// KeyboardCompositionEvents is a event mask matching keyboard.Composition
+	// events.
+	KeyboardCompositionEvents
+
 	// NoEvents is a event mask matching no events at all.
 	NoEvents EventMask = 0
 
@@ -100,6 +121,24 @@ const (
 	//
 	//  keyboard.ButtonEvent
 	//  keyboard.Typed
+	//  keyboard.Composition
+	//
+	KeyboardEvents EventMask = KeyboardButtonEvents | KeyboardTypedEvents | KeyboardCompositionEvents
+
+	// TextInputEvents is an event mask that selects the events needed by text
+	// input fields:
+	//
+	//  keyboard.Typed
+	//  keyboard.Composition
+	//
+	TextInputEvents EventMask = KeyboardTypedEvents | KeyboardCompositionEvents
+
+	// GamepadEvents is an event mask that selects all gamepad events:
+	//
+	//  gamepad.Connected
+	//  gamepad.Disconnected
+	//  gamepad.ButtonEvent
+	//  gamepad.AxisMoved
 	//
-	KeyboardEvents EventMask = KeyboardButtonEvents | KeyboardTypedEvents
+	GamepadEvents EventMask = GamepadConnectionEvents | GamepadButtonEvents | GamepadAxisEvents
 )
@@ -103,12 +103,17 @@ type CursorMoved struct {
 	// values (e.g. for a FPS style camera).
 	Delta bool
 
+	// Whether or not the delta values are raw (unaccelerated) mouse motion,
+	// see Props.SetRawMouseMotion. It is always false unless
+	// RawMouseMotionSupported returns true.
+	Raw bool
+
 	T time.Time
 }
 
 // String returns a string representation of this event.
 func (ev CursorMoved) String() string {
-	return fmt.Sprintf("CursorMoved(X=%f, Y=%f, Time=%v)", ev.X, ev.Y, ev.T)
+	return fmt.Sprintf("CursorMoved(X=%f, Y=%f, Delta=%v, Raw=%v, Time=%v)", ev.X, ev.Y, ev.Delta, ev.Raw, ev.T)
 }
 
 // Time implements the Event interface.
@@ -264,20 +269,46 @@ func (ev FramebufferResized) Time() time.Time {
 	return ev.T
 }
 
-// ItemsDropped is an event where the user dropped an item (or multiple items)
-// onto the window.
+// ItemsDropped is an event where the user dragged and dropped an item (or
+// multiple items) onto the window, e.g. files from a file manager.
 type ItemsDropped struct {
+	// The paths of the dropped files or directories.
 	Items []string
 
+	// Position of the cursor, relative to the upper-left corner of the window,
+	// at which the items were dropped.
+	X, Y float64
+
 	T time.Time
 }
 
 // String returns a string representation of this event.
 func (ev ItemsDropped) String() string {
-	return fmt.Sprintf("ItemsDropped(Items=%v, Time=%v)", ev.Items, ev.T)
+	return fmt.Sprintf("ItemsDropped(Items=%v, X=%f, Y=%f, Time=%v)", ev.Items, ev.X, ev.Y, ev.T)
 }
 
 // Time implements the Event interface.
 func (ev ItemsDropped) Time() time.Time {
 	return ev.T
 }
+
+// ContentScaleChanged is an event where the content scale of the window (the
+// ratio of framebuffer pixels to screen coordinates) changed, for instance
+// because it was moved onto a monitor with a different pixel density.
+type ContentScaleChanged struct {
+	// The new content scale along each axis, e.g. 2.0 on most HiDPI
+	// ("retina") displays.
+	X, Y float64
+
+	T time.Time
+}
+
+// String returns a string representation of this event.
+func (ev ContentScaleChanged) String() string {
+	return fmt.Sprintf("ContentScaleChanged(X=%v, Y=%v, Time=%v)", ev.X, ev.Y, ev.T)
+}
+
+// Time implements the Event interface.
+func (ev ContentScaleChanged) Time() time.Time {
+	return ev.T
+}
@@ -0,0 +1,15 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+// +build 386,!windows,!linux,!freebsd amd64,!windows,!linux,!freebsd 386,linux,wayland amd64,linux,wayland 386,freebsd,wayland amd64,freebsd,wayland
+
+package window
+
+import "github.com/go-gl/glfw/v3.3/glfw"
+
+// glfwConfineCursor would confine the cursor to the given window, but neither
+// OS X nor Wayland (via GLFW 3.3) offer a way to do so, so the cursor is never
+// confined on these platforms.
+func glfwConfineCursor(win *glfw.Window, confine bool) bool {
+	return false
+}
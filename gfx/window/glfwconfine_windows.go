@@ -0,0 +1,48 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+// +build 386 amd64
+
+package window
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+var (
+	user32              = syscall.MustLoadDLL("user32.dll")
+	procClipCursor      = user32.MustFindProc("ClipCursor")
+	procGetClientRect   = user32.MustFindProc("GetClientRect")
+	procMapWindowPoints = user32.MustFindProc("MapWindowPoints")
+)
+
+// rect is a Win32 RECT structure.
+type rect struct {
+	left, top, right, bottom int32
+}
+
+// glfwConfineCursor confines the cursor to the client area of the given
+// window, or releases it, and returns whether or not the cursor is now
+// confined. It may only be called on the main thread.
+//
+// Windows confines the cursor to a rectangle in screen coordinates, so it must
+// be confined again whenever the window moves or is resized.
+func glfwConfineCursor(win *glfw.Window, confine bool) bool {
+	if !confine {
+		procClipCursor.Call(0)
+		return false
+	}
+	hwnd := uintptr(unsafe.Pointer(win.GetWin32Window()))
+	var r rect
+	if ret, _, _ := procGetClientRect.Call(hwnd, uintptr(unsafe.Pointer(&r))); ret == 0 {
+		return false
+	}
+
+	// Convert the client rectangle (two points) into screen coordinates.
+	procMapWindowPoints.Call(hwnd, 0, uintptr(unsafe.Pointer(&r)), 2)
+	ret, _, _ := procClipCursor.Call(uintptr(unsafe.Pointer(&r)))
+	return ret != 0
+}
@@ -0,0 +1,39 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+// +build 386,linux,!wayland amd64,linux,!wayland 386,freebsd,!wayland amd64,freebsd,!wayland
+
+package window
+
+/*
+#cgo LDFLAGS: -lX11
+#include <X11/Xlib.h>
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// glfwConfineCursor confines the cursor to the given window, or releases it,
+// and returns whether or not the cursor is now confined. It may only be called
+// on the main thread.
+//
+// X11 confines the cursor through an active pointer grab, so while it is
+// confined the window manager's decorations (e.g. the title bar) cannot be
+// clicked either. The grab follows the window as it moves.
+func glfwConfineCursor(win *glfw.Window, confine bool) bool {
+	display := (*C.Display)(unsafe.Pointer(glfw.GetX11Display()))
+	if !confine {
+		C.XUngrabPointer(display, C.CurrentTime)
+		return false
+	}
+
+	// With owner_events set and an empty event mask, pointer events are still
+	// delivered to GLFW as usual.
+	xwin := C.Window(win.GetX11Window())
+	status := C.XGrabPointer(display, xwin, C.True, 0, C.GrabModeAsync, C.GrabModeAsync, xwin, C.None, C.CurrentTime)
+	return status == C.GrabSuccess
+}
@@ -6,10 +6,14 @@
 package window
 
 import (
+	"image"
+	"image/draw"
+	"reflect"
+
 	"azul3d.org/engine/keyboard"
 	"azul3d.org/engine/mouse"
 
-	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/go-gl/glfw/v3.3/glfw"
 )
 
 func convertMouseAction(a glfw.Action) mouse.State {
@@ -323,3 +327,61 @@ func convertKey(k glfw.Key) keyboard.Key {
 		panic("unhandled key")
 	}
 }
+
+// convertCursor creates a GLFW cursor for the given cursor, it returns nil
+// (i.e. the default cursor) if c is nil.
+//
+// It may only be called on the main thread.
+func convertCursor(c *Cursor) *glfw.Cursor {
+	if c == nil {
+		return nil
+	}
+	if c.Image != nil {
+		return glfw.CreateCursor(c.Image, c.HotX, c.HotY)
+	}
+	switch c.Shape {
+	case IBeamCursor:
+		return glfw.CreateStandardCursor(glfw.IBeamCursor)
+	case CrosshairCursor:
+		return glfw.CreateStandardCursor(glfw.CrosshairCursor)
+	case HandCursor:
+		return glfw.CreateStandardCursor(glfw.HandCursor)
+	case HResizeCursor:
+		return glfw.CreateStandardCursor(glfw.HResizeCursor)
+	case VResizeCursor:
+		return glfw.CreateStandardCursor(glfw.VResizeCursor)
+	default:
+		return glfw.CreateStandardCursor(glfw.ArrowCursor)
+	}
+}
+
+// convertIcon converts the given window icon into the images passed to GLFW's
+// SetIcon. A nil icon (the system's default icon) is converted into no images.
+func convertIcon(icon image.Image) []image.Image {
+	if icon == nil {
+		return nil
+	}
+
+	// GLFW uses the pixels of an *image.NRGBA as-is, so they must be tightly
+	// packed with an origin of (0, 0).
+	b := icon.Bounds()
+	if n, ok := icon.(*image.NRGBA); ok && b.Min == (image.Point{}) && n.Stride == 4*b.Dx() {
+		return []image.Image{n}
+	}
+	n := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(n, n.Bounds(), icon, b.Min, draw.Src)
+	return []image.Image{n}
+}
+
+// sameImage tells whether a and b are the same image. Images of types that are
+// not comparable (comparing them would panic) are never considered the same.
+func sameImage(a, b image.Image) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	t := reflect.TypeOf(a)
+	if t != reflect.TypeOf(b) || !t.Comparable() {
+		return false
+	}
+	return a == b
+}
@@ -0,0 +1,114 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+// +build 386 amd64
+
+package window
+
+import (
+	"sync"
+	"time"
+
+	"azul3d.org/engine/gamepad"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+var (
+	// The gamepad watcher shared by all windows.
+	gamepads = gamepad.NewWatcher()
+
+	// The mappings of each connected joystick (only accessed on the main
+	// thread).
+	joystickMappings [glfw.JoystickLast + 1]*gamepad.Mapping
+
+	// The raw state of each joystick, reused between polls (only accessed on
+	// the main thread).
+	joystickStates [glfw.JoystickLast + 1]struct {
+		axes    []float64
+		buttons []bool
+		hats    []uint8
+	}
+
+	// The set of open windows, which receive gamepad events.
+	openWindows = struct {
+		sync.RWMutex
+		m map[*glfwWindow]struct{}
+	}{m: make(map[*glfwWindow]struct{})}
+)
+
+// pollGamepads polls the state of each joystick, sending events to every open
+// window.
+//
+// Mappings are looked up by the joystick's SDL compatible GUID, or by it's
+// name, and joysticks without a known mapping fall back to gamepad.XInput (the
+// layout GLFW reports for Xbox 360 compatible controllers on Windows). GLFW
+// offers no rumble. As the state of each joystick must be polled anyway, so
+// are connections.
+//
+// It may only be called on the main thread.
+func pollGamepads() {
+	now := time.Now()
+	var events []gamepad.Event
+	for joy := glfw.Joystick1; joy <= glfw.JoystickLast; joy++ {
+		id := int(joy)
+		present := joy.Present()
+		m := joystickMappings[joy]
+		switch {
+		case present && m == nil:
+			name := joy.GetName()
+			m = gamepad.DefaultDB.Lookup(joy.GetGUID(), name)
+			if m == nil {
+				m = gamepad.XInput
+			}
+			joystickMappings[joy] = m
+			events = append(events, gamepads.Connect(id, name, now))
+		case !present && m != nil:
+			joystickMappings[joy] = nil
+			events = append(events, gamepads.Disconnect(id, now))
+			continue
+		case !present:
+			continue
+		}
+
+		// Convert the raw state.
+		st := &joystickStates[joy]
+		st.axes = st.axes[:0]
+		for _, v := range joy.GetAxes() {
+			st.axes = append(st.axes, float64(v))
+		}
+		st.buttons = st.buttons[:0]
+		for _, v := range joy.GetButtons() {
+			st.buttons = append(st.buttons, v == glfw.Press)
+		}
+		st.hats = st.hats[:0]
+		for _, v := range joy.GetHats() {
+			st.hats = append(st.hats, uint8(v))
+		}
+		snapshot := m.Map(st.axes, st.buttons, st.hats)
+		events = append(events, gamepads.Update(id, snapshot, now)...)
+	}
+	if len(events) == 0 {
+		return
+	}
+
+	openWindows.RLock()
+	for w := range openWindows.m {
+		for _, ev := range events {
+			switch ev.(type) {
+			case gamepad.ButtonEvent:
+				w.sendEvent(ev, GamepadButtonEvents)
+			case gamepad.AxisMoved:
+				w.sendEvent(ev, GamepadAxisEvents)
+			default:
+				w.sendEvent(ev, GamepadConnectionEvents)
+			}
+		}
+	}
+	openWindows.RUnlock()
+}
+
+// pollAll polls for both GLFW window events and gamepad state.
+func pollAll() {
+	glfw.PollEvents()
+	pollGamepads()
+}
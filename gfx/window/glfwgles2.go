@@ -7,7 +7,7 @@ package window
 
 import (
 	"azul3d.org/engine/gfx/gles2"
-	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/go-gl/glfw/v3.3/glfw"
 )
 
 const (
@@ -0,0 +1,11 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+// +build 386,!windows amd64,!windows
+
+package window
+
+// glfwHookWindow would hook into the given window to deliver the events which
+// GLFW does not report (keyboard.Composition and touch.Event events), but this
+// is only done on Windows.
+func glfwHookWindow(w *glfwWindow) {}
@@ -0,0 +1,226 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+// +build 386 amd64
+
+package window
+
+import (
+	"syscall"
+	"time"
+	"unicode/utf16"
+	"unsafe"
+
+	"azul3d.org/engine/keyboard"
+	"azul3d.org/engine/touch"
+)
+
+// Win32 window messages and flags.
+const (
+	wmNCDestroy         = 0x0082
+	wmIMEEndComposition = 0x010E
+	wmIMEComposition    = 0x010F
+	wmIMESetContext     = 0x0281
+	wmTouch             = 0x0240
+
+	touchEventFMove = 0x0001
+	touchEventFDown = 0x0002
+	touchEventFUp   = 0x0004
+
+	gcsCompStr   = 0x0008
+	gcsCursorPos = 0x0080
+
+	iscShowUICompositionWindow = 0x80000000
+
+	// GWLP_WNDPROC (-4) as an unsigned value.
+	gwlpWndProc = ^uintptr(3)
+)
+
+var (
+	imm32                        = syscall.MustLoadDLL("imm32.dll")
+	procImmGetContext            = imm32.MustFindProc("ImmGetContext")
+	procImmReleaseContext        = imm32.MustFindProc("ImmReleaseContext")
+	procImmGetCompositionStringW = imm32.MustFindProc("ImmGetCompositionStringW")
+
+	procCallWindowProcW = user32.MustFindProc("CallWindowProcW")
+	procClientToScreen  = user32.MustFindProc("ClientToScreen")
+
+	// 32-bit Windows only has SetWindowLongPtrW as a macro of SetWindowLongW.
+	procSetWindowLongPtrW = func() *syscall.Proc {
+		if p, err := user32.FindProc("SetWindowLongPtrW"); err == nil {
+			return p
+		}
+		return user32.MustFindProc("SetWindowLongW")
+	}()
+
+	// Touch input is only available on Windows 7 and later, so these may be
+	// nil.
+	procRegisterTouchWindow   = findProc(user32, "RegisterTouchWindow")
+	procGetTouchInputInfo     = findProc(user32, "GetTouchInputInfo")
+	procCloseTouchInputHandle = findProc(user32, "CloseTouchInputHandle")
+
+	// The window procedure of hooked windows, and the hooked windows by their
+	// handle (only accessed on the main thread).
+	hookWndProc   = syscall.NewCallback(hookedWndProc)
+	hookedWindows = make(map[uintptr]*hookedWindow)
+)
+
+// findProc returns the named procedure of the DLL, or nil if it does not
+// exist.
+func findProc(dll *syscall.DLL, name string) *syscall.Proc {
+	p, err := dll.FindProc(name)
+	if err != nil {
+		return nil
+	}
+	return p
+}
+
+// touchInput is the Win32 TOUCHINPUT structure.
+type touchInput struct {
+	x, y               int32 // In hundredths of a pixel, in screen coordinates.
+	source             uintptr
+	id, flags, mask    uint32
+	time               uint32
+	extraInfo          uintptr
+	contactX, contactY uint32
+}
+
+// hookedWindow is a window whose window procedure was replaced by
+// glfwHookWindow.
+type hookedWindow struct {
+	w *glfwWindow
+
+	// The window procedure of GLFW, which hookedWndProc calls through to.
+	prev uintptr
+}
+
+// glfwHookWindow replaces the window procedure of the given window, in order
+// to deliver the events which GLFW does not report: keyboard.Composition and
+// touch.Event events. It may only be called on the main thread.
+func glfwHookWindow(w *glfwWindow) {
+	hwnd := uintptr(unsafe.Pointer(w.window.GetWin32Window()))
+	h := &hookedWindow{w: w}
+	hookedWindows[hwnd] = h
+	h.prev, _, _ = procSetWindowLongPtrW.Call(hwnd, gwlpWndProc, hookWndProc)
+	if h.prev == 0 {
+		delete(hookedWindows, hwnd)
+		return
+	}
+	if procRegisterTouchWindow != nil {
+		procRegisterTouchWindow.Call(hwnd, 0)
+	}
+}
+
+// hookedWndProc is the window procedure of hooked windows. Windows calls it on
+// the main thread (which created the windows), while GLFW polls for events.
+func hookedWndProc(hwnd, msg, wParam, lParam uintptr) uintptr {
+	h := hookedWindows[hwnd]
+	switch msg {
+	case wmNCDestroy:
+		// The last message the window receives.
+		delete(hookedWindows, hwnd)
+
+	case wmIMESetContext:
+		// Text fields display the composition text themselves, so the IME
+		// should not display it in it's own window.
+		if h.w.wants(KeyboardCompositionEvents) {
+			lParam &^= iscShowUICompositionWindow
+		}
+
+	case wmIMEComposition:
+		if lParam&gcsCompStr != 0 {
+			h.composition(hwnd)
+		}
+
+	case wmIMEEndComposition:
+		h.w.sendEvent(keyboard.Composition{T: time.Now()}, KeyboardCompositionEvents)
+
+	case wmTouch:
+		if h.w.wants(TouchEvents) && h.touch(hwnd, int(wParam&0xffff), lParam) {
+			procCloseTouchInputHandle.Call(lParam)
+			return 0
+		}
+	}
+	ret, _, _ := procCallWindowProcW.Call(h.prev, hwnd, msg, wParam, lParam)
+	return ret
+}
+
+// composition sends a keyboard.Composition event with the current composition
+// string of the window's IME.
+func (h *hookedWindow) composition(hwnd uintptr) {
+	if !h.w.wants(KeyboardCompositionEvents) {
+		return
+	}
+	himc, _, _ := procImmGetContext.Call(hwnd)
+	if himc == 0 {
+		return
+	}
+	defer procImmReleaseContext.Call(hwnd, himc)
+
+	// The size of the composition string in bytes, or a negative error code.
+	size, _, _ := procImmGetCompositionStringW.Call(himc, gcsCompStr, 0, 0)
+	if int32(size) <= 0 {
+		h.w.sendEvent(keyboard.Composition{T: time.Now()}, KeyboardCompositionEvents)
+		return
+	}
+	text := make([]uint16, int32(size)/2)
+	procImmGetCompositionStringW.Call(himc, gcsCompStr, uintptr(unsafe.Pointer(&text[0])), size)
+
+	// The cursor position is in UTF-16 code units, not runes.
+	cursor, _, _ := procImmGetCompositionStringW.Call(himc, gcsCursorPos, 0, 0)
+	c := int(int32(cursor))
+	if c < 0 || c > len(text) {
+		c = len(text)
+	}
+	h.w.sendEvent(keyboard.Composition{
+		T:      time.Now(),
+		Text:   string(utf16.Decode(text)),
+		Cursor: len(utf16.Decode(text[:c])),
+	}, KeyboardCompositionEvents)
+}
+
+// touch sends a touch.Event for each of the n touch points of the given
+// WM_TOUCH input handle. It returns false if the touch points could not be
+// read.
+func (h *hookedWindow) touch(hwnd uintptr, n int, handle uintptr) bool {
+	if n == 0 {
+		return false
+	}
+	inputs := make([]touchInput, n)
+	ok, _, _ := procGetTouchInputInfo.Call(
+		handle,
+		uintptr(n),
+		uintptr(unsafe.Pointer(&inputs[0])),
+		unsafe.Sizeof(inputs[0]),
+	)
+	if ok == 0 {
+		return false
+	}
+
+	// The origin of the client area, in screen coordinates.
+	var origin struct{ x, y int32 }
+	procClientToScreen.Call(hwnd, uintptr(unsafe.Pointer(&origin)))
+
+	now := time.Now()
+	for _, in := range inputs {
+		var state touch.State
+		switch {
+		case in.flags&touchEventFDown != 0:
+			state = touch.Down
+		case in.flags&touchEventFUp != 0:
+			state = touch.Up
+		case in.flags&touchEventFMove != 0:
+			state = touch.Move
+		default:
+			continue
+		}
+		h.w.sendEvent(touch.Event{
+			T:     now,
+			ID:    int(in.id),
+			X:     float64(in.x)/100 - float64(origin.x),
+			Y:     float64(in.y)/100 - float64(origin.y),
+			State: state,
+		}, TouchEvents)
+	}
+	return true
+}
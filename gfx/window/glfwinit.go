@@ -10,7 +10,7 @@ import (
 	"runtime"
 	"time"
 
-	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/go-gl/glfw/v3.3/glfw"
 )
 
 var (
@@ -79,8 +79,8 @@ func assetLoader() {
 	}
 }
 
-// pollEvents submits a function to the main loop to poll for GLFW events at
-// 120hz.
+// pollEvents submits a function to the main loop to poll for GLFW events (and
+// gamepad state) at 120hz.
 func pollEvents() {
 	// Poll for events at 120hz.
 	ticker := time.NewTicker(time.Second / 120)
@@ -100,7 +100,7 @@ func pollEvents() {
 		select {
 		case <-pollerExit:
 			return
-		case MainLoopChan <- glfw.PollEvents:
+		case MainLoopChan <- pollAll:
 		}
 	}
 }
@@ -0,0 +1,84 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+// +build 386 amd64
+
+package window
+
+import (
+	"sort"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// convertVidMode converts a GLFW video mode into a VideoMode.
+func convertVidMode(vm *glfw.VidMode) VideoMode {
+	if vm == nil {
+		return VideoMode{}
+	}
+	return VideoMode{
+		Width:       vm.Width,
+		Height:      vm.Height,
+		RefreshRate: vm.RefreshRate,
+		RedBits:     vm.RedBits,
+		GreenBits:   vm.GreenBits,
+		BlueBits:    vm.BlueBits,
+	}
+}
+
+// convertMonitor converts a GLFW monitor into a Monitor.
+func convertMonitor(m *glfw.Monitor, primary bool) *Monitor {
+	mon := &Monitor{
+		Name:    m.GetName(),
+		Primary: primary,
+		Mode:    convertVidMode(m.GetVideoMode()),
+	}
+	mon.X, mon.Y = m.GetPos()
+	mon.PhysicalWidth, mon.PhysicalHeight = m.GetPhysicalSize()
+	for _, vm := range m.GetVideoModes() {
+		mon.Modes = append(mon.Modes, convertVidMode(vm))
+	}
+	sort.SliceStable(mon.Modes, func(i, j int) bool {
+		a, b := mon.Modes[i], mon.Modes[j]
+		if a.Width*a.Height != b.Width*b.Height {
+			return a.Width*a.Height < b.Width*b.Height
+		}
+		return a.RefreshRate < b.RefreshRate
+	})
+	return mon
+}
+
+// doMonitors returns the list of connected monitors, primary first.
+//
+// It may only be called on the main thread.
+func doMonitors() ([]*Monitor, error) {
+	if err := doInit(); err != nil {
+		return nil, err
+	}
+	primary := glfw.GetPrimaryMonitor()
+	var monitors []*Monitor
+	if primary != nil {
+		monitors = append(monitors, convertMonitor(primary, true))
+	}
+	for _, m := range glfw.GetMonitors() {
+		if m != primary {
+			monitors = append(monitors, convertMonitor(m, false))
+		}
+	}
+	return monitors, nil
+}
+
+// findMonitor returns the GLFW monitor with the given name, or the primary
+// monitor if the name is empty or no such monitor is connected.
+//
+// It may only be called on the main thread.
+func findMonitor(name string) *glfw.Monitor {
+	if name != "" {
+		for _, m := range glfw.GetMonitors() {
+			if m.GetName() == name {
+				return m
+			}
+		}
+	}
+	return glfw.GetPrimaryMonitor()
+}
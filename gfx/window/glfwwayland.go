@@ -0,0 +1,10 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+// +build 386,linux,wayland amd64,linux,wayland 386,freebsd,wayland amd64,freebsd,wayland
+
+package window
+
+// glfwWayland tells whether GLFW uses its native Wayland backend (the
+// "wayland" build tag) rather than X11.
+const glfwWayland = true
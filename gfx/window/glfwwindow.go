@@ -16,12 +16,14 @@ import (
 	"sync"
 	"time"
 
+	"azul3d.org/engine/gamepad"
 	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/gfxutil"
 	"azul3d.org/engine/gfx/internal/tag"
 	"azul3d.org/engine/gfx/internal/util"
 	"azul3d.org/engine/keyboard"
 	"azul3d.org/engine/mouse"
-	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/go-gl/glfw/v3.3/glfw"
 )
 
 // intBool returns 0 or 1 depending on b.
@@ -68,7 +70,10 @@ type glfwWindow struct {
 	monitor                  *glfw.Monitor
 	beforeFullscreen         [2]int // Window size before fullscreen.
 	lastCursorX, lastCursorY float64
+	rawMouseMotion           bool
 	closed, runInvoked       bool
+	confined                 bool // Whether the cursor is confined.
+	cursor                   *glfw.Cursor
 }
 
 // Props implements the Window interface.
@@ -98,6 +103,11 @@ func (w *glfwWindow) Mouse() *mouse.Watcher {
 	return w.mouse
 }
 
+// Gamepads implements the Window interface.
+func (w *glfwWindow) Gamepads() *gamepad.Watcher {
+	return gamepads
+}
+
 // SetClipboard implements the Clipboard interface.
 func (w *glfwWindow) SetClipboard(clipboard string) {
 	MainLoopChan <- func() {
@@ -110,15 +120,11 @@ func (w *glfwWindow) SetClipboard(clipboard string) {
 // Clipboard implements the Clipboard interface.
 func (w *glfwWindow) Clipboard() string {
 	w.RLock()
-	var (
-		str string
-		err error
-	)
+	var str string
 	w.waitFor(func() {
-		str, err = w.window.GetClipboardString()
+		str = w.window.GetClipboardString()
 	})
 	w.RUnlock()
-	logError(err)
 	return str
 }
 
@@ -182,9 +188,15 @@ func (w *glfwWindow) useProps(p *Props, force bool) {
 	//
 	// We can do this without losing time, as assets are stored in the shared
 	// asset context -- not in this window's context.
+	//
+	// The same applies to switching the monitor or video mode of a fullscreen
+	// window.
 	fullscreen := w.props.Fullscreen()
 	lastFullscreen := w.last.Fullscreen()
-	if fullscreen != lastFullscreen {
+	modeChanged := fullscreen && (w.props.Monitor() != w.last.Monitor() ||
+		w.props.VideoMode() != w.last.VideoMode() ||
+		w.props.Borderless() != w.last.Borderless())
+	if fullscreen != lastFullscreen || modeChanged {
 		w.last.SetFullscreen(fullscreen)
 
 		// If we're not switching to fullscreen, restore the window size from
@@ -207,7 +219,8 @@ func (w *glfwWindow) useProps(p *Props, force bool) {
 	// Window Size.
 	width, height := w.props.Size()
 	lastWidth, lastHeight := w.last.Size()
-	if force || width != lastWidth || height != lastHeight {
+	pixelExact := w.props.PixelExact()
+	if force || width != lastWidth || height != lastHeight || w.last.PixelExact() != pixelExact {
 		// If we're not switching to fullscreen, save the window size as it was
 		// for restoration after we've exited fullscreen later.
 		if !fullscreen {
@@ -215,20 +228,25 @@ func (w *glfwWindow) useProps(p *Props, force bool) {
 		}
 
 		w.last.SetSize(width, height)
+		w.last.SetPixelExact(pixelExact)
+		screenWidth, screenHeight := w.toScreen(width, height)
 		withoutLock(func() {
-			win.SetSize(width, height)
+			win.SetSize(screenWidth, screenHeight)
 		})
 	}
 
-	// Window Position.
+	// Window Position. Wayland has no global coordinates, windows are placed
+	// by the compositor instead.
 	x, y := w.props.Pos()
 	lastX, lastY := w.last.Pos()
-	if (force || x != lastX || y != lastY) && !fullscreen {
+	if (force || x != lastX || y != lastY) && !fullscreen && !glfwWayland {
 		w.last.SetPos(x, y)
 		if x == -1 && y == -1 {
+			mx, my := w.monitor.GetPos()
 			vm := w.monitor.GetVideoMode()
-			x = (vm.Width / 2) - (width / 2)
-			y = (vm.Height / 2) - (height / 2)
+			screenWidth, screenHeight := w.toScreen(width, height)
+			x = mx + (vm.Width / 2) - (screenWidth / 2)
+			y = my + (vm.Height / 2) - (screenHeight / 2)
 		}
 		withoutLock(func() {
 			win.SetPos(x, y)
@@ -266,9 +284,9 @@ func (w *glfwWindow) useProps(p *Props, force bool) {
 		w.last.SetMinimized(minimized)
 		withoutLock(func() {
 			if minimized {
-				logError(win.Iconify())
+				win.Iconify()
 			} else {
-				logError(win.Restore())
+				win.Restore()
 			}
 		})
 	}
@@ -294,19 +312,73 @@ func (w *glfwWindow) useProps(p *Props, force bool) {
 		glfw.SwapInterval(swapInterval)
 	}
 
+	// sRGB framebuffer.
+	srgb := w.props.SRGB()
+	if force || w.last.SRGB() != srgb {
+		w.last.SetSRGB(srgb)
+		if c, ok := w.device.(gfx.SRGBCanvas); ok {
+			c.SetSRGB(srgb)
+		}
+	}
+
+	// Window icon.
+	icon := w.props.Icon()
+	if force || !sameImage(w.last.Icon(), icon) {
+		w.last.SetIcon(icon)
+		withoutLock(func() {
+			win.SetIcon(convertIcon(icon))
+		})
+	}
+
+	// Window opacity.
+	opacity := w.props.Opacity()
+	if force || w.last.Opacity() != opacity {
+		w.last.SetOpacity(opacity)
+		withoutLock(func() {
+			win.SetOpacity(float32(opacity))
+		})
+	}
+
+	// Window Resizable.
+	resizable := w.props.Resizable()
+	if force || w.last.Resizable() != resizable {
+		w.last.SetResizable(resizable)
+		withoutLock(func() {
+			win.SetAttrib(glfw.Resizable, intBool(resizable))
+		})
+	}
+
+	// Window Decorated, borderless fullscreen windows are never decorated.
+	decorated := w.props.Decorated()
+	if force || w.last.Decorated() != decorated {
+		w.last.SetDecorated(decorated)
+		borderless := fullscreen && w.props.Borderless()
+		withoutLock(func() {
+			win.SetAttrib(glfw.Decorated, intBool(decorated && !borderless))
+		})
+	}
+
+	// Window AlwaysOnTop.
+	alwaysOnTop := w.props.AlwaysOnTop()
+	if force || w.last.AlwaysOnTop() != alwaysOnTop {
+		w.last.SetAlwaysOnTop(alwaysOnTop)
+		withoutLock(func() {
+			win.SetAttrib(glfw.Floating, intBool(alwaysOnTop))
+		})
+	}
+
 	// The following cannot be changed via GLFW post window creation -- and
-	// they are not deemed significant enough to warrant rebuilding the window.
+	// it is not deemed significant enough to warrant rebuilding the window.
 	//
 	//  Focused
-	//  Resizable
-	//  Decorated
-	//  AlwaysOnTop (via GLFW_FLOATING)
 	//
 
 	// Cursor Mode.
 	grabbed := w.props.CursorGrabbed()
-	if force || w.last.CursorGrabbed() != grabbed {
+	cursorVisible := w.props.CursorVisible()
+	if force || w.last.CursorGrabbed() != grabbed || w.last.CursorVisible() != cursorVisible {
 		w.last.SetCursorGrabbed(grabbed)
+		w.last.SetCursorVisible(cursorVisible)
 
 		// Reset both last cursor values to the callback can identify the
 		// large/fake delta.
@@ -315,13 +387,151 @@ func (w *glfwWindow) useProps(p *Props, force bool) {
 
 		// Set input mode.
 		withoutLock(func() {
-			if grabbed {
+			switch {
+			case grabbed:
 				w.window.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
-			} else {
+			case !cursorVisible:
+				w.window.SetInputMode(glfw.CursorMode, glfw.CursorHidden)
+			default:
 				w.window.SetInputMode(glfw.CursorMode, glfw.CursorNormal)
 			}
 		})
 	}
+
+	// Raw mouse motion, which GLFW only applies while the cursor is disabled.
+	raw := w.props.RawMouseMotion()
+	if force || w.last.RawMouseMotion() != raw {
+		w.last.SetRawMouseMotion(raw)
+		if glfw.RawMouseMotionSupported() {
+			w.rawMouseMotion = raw
+			withoutLock(func() {
+				w.window.SetInputMode(glfw.RawMouseMotion, intBool(raw))
+			})
+		}
+	}
+
+	// Cursor appearance.
+	cursor := w.props.Cursor()
+	if force || w.last.Cursor() != cursor {
+		w.last.SetCursor(cursor)
+		old := w.cursor
+		w.cursor = convertCursor(cursor)
+		withoutLock(func() {
+			win.SetCursor(w.cursor)
+		})
+		if old != nil {
+			old.Destroy()
+		}
+	}
+
+	// Cursor confinement.
+	w.last.SetCursorConfined(w.props.CursorConfined())
+	withoutLock(func() {
+		w.updateConfinement(false)
+	})
+}
+
+// doRawMouseMotionSupported tells whether or not raw mouse motion is
+// supported, which GLFW reports for Windows and for X11 servers with the
+// XInput extension.
+//
+// It may only be called on the main thread.
+func doRawMouseMotionSupported() bool {
+	if err := doInit(); err != nil {
+		logError(err)
+		return false
+	}
+	return glfw.RawMouseMotionSupported()
+}
+
+// updateConfinement confines the cursor to the window, or releases it, as
+// requested by the CursorConfined property. The cursor is only confined while
+// the window has focus and the cursor is not grabbed. If reapply is true, an
+// existing confinement is applied again because the window has moved or was
+// resized.
+//
+// It may only be called on the main thread, and without the window's lock.
+func (w *glfwWindow) updateConfinement(reapply bool) {
+	w.Lock()
+	confine := w.props.CursorConfined() && w.last.Focused() && !w.last.CursorGrabbed()
+	if confine == w.confined && !(confine && reapply) {
+		w.Unlock()
+		return
+	}
+	win := w.window
+	w.Unlock()
+
+	confined := glfwConfineCursor(win, confine)
+	w.Lock()
+	w.confined = confined
+	w.Unlock()
+}
+
+// toScreen converts a window size from the units of the Size property into
+// screen coordinates, using the last known content scale.
+//
+// It may only be called under the presence of the window's lock.
+func (w *glfwWindow) toScreen(width, height int) (int, int) {
+	if !w.props.PixelExact() {
+		return width, height
+	}
+	x, y := w.last.ContentScale()
+	return int(math.Ceil(float64(width) / x)), int(math.Ceil(float64(height) / y))
+}
+
+// fromScreen converts a window size in screen coordinates into the units of the
+// Size property, using the last known content scale.
+//
+// It may only be called under the presence of the window's lock.
+func (w *glfwWindow) fromScreen(width, height int) (int, int) {
+	if !w.props.PixelExact() {
+		return width, height
+	}
+	x, y := w.last.ContentScale()
+	return int(math.Floor(float64(width)*x + 0.5)), int(math.Floor(float64(height)*y + 0.5))
+}
+
+// updateScale recomputes the content scale of the window from the sizes of its
+// framebuffer and client area. If it changed, a ContentScaleChanged event is
+// sent and pixel exact windows are resized to keep their framebuffer size.
+//
+// It may only be called on the main thread, without the window's lock held.
+func (w *glfwWindow) updateScale(gw *glfw.Window) {
+	width, height := gw.GetSize()
+	fbWidth, fbHeight := gw.GetFramebufferSize()
+	if width <= 0 || height <= 0 || fbWidth <= 0 || fbHeight <= 0 {
+		// Minimized, the scale is unknown.
+		return
+	}
+	x := float64(fbWidth) / float64(width)
+	y := float64(fbHeight) / float64(height)
+
+	w.Lock()
+	lastX, lastY := w.last.ContentScale()
+	if x == lastX && y == lastY {
+		w.Unlock()
+		return
+	}
+	w.last.SetContentScale(x, y)
+	w.props.SetContentScale(x, y)
+	pixelExact := w.props.PixelExact()
+	screenWidth, screenHeight := w.toScreen(w.props.Size())
+	w.Unlock()
+
+	if pixelExact && (screenWidth != width || screenHeight != height) {
+		gw.SetSize(screenWidth, screenHeight)
+	}
+	w.sendEvent(ContentScaleChanged{X: x, Y: y, T: time.Now()}, ContentScaleChangedEvents)
+}
+
+// saveScreenshot saves a screenshot of the window to a PNG file in the current
+// working directory, asynchronously. Errors are logged.
+func (w *glfwWindow) saveScreenshot() {
+	name := "screenshot-" + time.Now().Format("20060102-150405.000") + ".png"
+	errs := gfxutil.SaveScreenshot(w.swapper, name)
+	go func() {
+		logError(<-errs)
+	}()
 }
 
 // initCallbacks sets a callback handler for each GLFW window event.
@@ -386,6 +596,7 @@ func (w *glfwWindow) initCallbacks() {
 		w.last.SetFocused(focused)
 		w.props.SetFocused(focused)
 		w.RUnlock()
+		w.updateConfinement(false)
 
 		// Send the proper event.
 		if focused {
@@ -397,6 +608,8 @@ func (w *glfwWindow) initCallbacks() {
 
 	// Moved event.
 	w.window.SetPosCallback(func(gw *glfw.Window, x, y int) {
+		w.updateConfinement(true)
+
 		// Store the position state.
 		w.RLock()
 		w.last.SetPos(x, y)
@@ -411,9 +624,13 @@ func (w *glfwWindow) initCallbacks() {
 	})
 
 	// Resized event.
-	w.window.SetSizeCallback(func(gw *glfw.Window, width, height int) {
+	w.window.SetSizeCallback(func(gw *glfw.Window, screenWidth, screenHeight int) {
+		w.updateScale(gw)
+		w.updateConfinement(true)
+
 		// Store the size state.
 		w.Lock()
+		width, height := w.fromScreen(screenWidth, screenHeight)
 		if !w.last.Fullscreen() {
 			// If we're not currently in fullscreen, save the window size as it
 			// was for restoration after we've exited fullscreen later.
@@ -423,8 +640,8 @@ func (w *glfwWindow) initCallbacks() {
 		w.props.SetSize(width, height)
 		w.Unlock()
 		w.sendEvent(Resized{
-			Width:  width,
-			Height: height,
+			Width:  screenWidth,
+			Height: screenHeight,
 			T:      time.Now(),
 		}, ResizedEvents)
 	})
@@ -439,6 +656,7 @@ func (w *glfwWindow) initCallbacks() {
 
 		// Update device's bounds.
 		w.device.UpdateBounds(image.Rect(0, 0, width, height))
+		w.updateScale(gw)
 
 		// Send the event.
 		w.sendEvent(FramebufferResized{
@@ -450,7 +668,13 @@ func (w *glfwWindow) initCallbacks() {
 
 	// Dropped event.
 	w.window.SetDropCallback(func(gw *glfw.Window, items []string) {
-		w.sendEvent(ItemsDropped{Items: items, T: time.Now()}, ItemsDroppedEvents)
+		x, y := gw.GetCursorPos()
+		w.sendEvent(ItemsDropped{
+			Items: items,
+			X:     x,
+			Y:     y,
+			T:     time.Now(),
+		}, ItemsDroppedEvents)
 	})
 
 	// CursorMoved event.
@@ -458,6 +682,7 @@ func (w *glfwWindow) initCallbacks() {
 		// Store the cursor position state.
 		w.RLock()
 		grabbed := w.props.CursorGrabbed()
+		raw := grabbed && w.rawMouseMotion
 		if grabbed {
 			// Store/swap last cursor values. Note: It's safe to modify
 			// lastCursorX/Y with just w.RLock because they are only modified
@@ -485,12 +710,15 @@ func (w *glfwWindow) initCallbacks() {
 		w.RUnlock()
 
 		// Send proper event.
-		w.sendEvent(CursorMoved{
-			X:     x,
-			Y:     y,
-			Delta: grabbed,
-			T:     time.Now(),
-		}, CursorMovedEvents)
+		if w.wants(CursorMovedEvents) {
+			w.sendEvent(CursorMoved{
+				X:     x,
+				Y:     y,
+				Delta: grabbed,
+				Raw:   raw,
+				T:     time.Now(),
+			}, CursorMovedEvents)
+		}
 	})
 
 	// CursorEnter and CursorExit events.
@@ -503,7 +731,9 @@ func (w *glfwWindow) initCallbacks() {
 		w.sendEvent(CursorExit{T: time.Now()}, CursorExitEvents)
 	})
 
-	// keyboard.Typed
+	// keyboard.Typed, this includes text committed by an IME. GLFW does not
+	// expose the IME composition (preedit) text, keyboard.Composition events
+	// are sent by glfwHookWindow instead.
 	w.window.SetCharCallback(func(gw *glfw.Window, r rune) {
 		w.sendEvent(keyboard.Typed{S: string(r), T: time.Now()}, KeyboardTypedEvents)
 	})
@@ -523,6 +753,11 @@ func (w *glfwWindow) initCallbacks() {
 		w.keyboard.SetState(k, s)
 		w.keyboard.SetRawState(r, s)
 
+		// Save a screenshot, if the screenshot hotkey was pressed.
+		if sk := w.Props().ScreenshotKey(); sk != keyboard.Invalid && k == sk && s == keyboard.Down {
+			w.saveScreenshot()
+		}
+
 		// Send the event.
 		w.sendEvent(keyboard.ButtonEvent{
 			T:     time.Now(),
@@ -551,6 +786,9 @@ func (w *glfwWindow) initCallbacks() {
 
 	// mouse.Scrolled event.
 	w.window.SetScrollCallback(func(gw *glfw.Window, x, y float64) {
+		if !w.wants(MouseScrolledEvents) {
+			return
+		}
 		w.sendEvent(mouse.Scrolled{
 			T: time.Now(),
 			X: x,
@@ -588,9 +826,18 @@ func (w *glfwWindow) run() {
 		// Release the context.
 		glfw.DetachCurrentContext()
 
-		// Destroy the window on the main thread.
+		// Release a confined cursor, then destroy the window and cursor on the
+		// main thread.
 		MainLoopChan <- func() {
+			if w.confined {
+				glfwConfineCursor(w.window, false)
+				w.confined = false
+			}
 			w.window.Destroy()
+			if w.cursor != nil {
+				w.cursor.Destroy()
+				w.cursor = nil
+			}
 		}
 	}
 
@@ -620,6 +867,11 @@ func (w *glfwWindow) run() {
 		case <-w.exit:
 			cleanup()
 
+			// No longer send gamepad events to this window.
+			openWindows.Lock()
+			delete(openWindows.m, w)
+			openWindows.Unlock()
+
 			// Decrement the number of open windows by one.
 			windowCount := Num(-1)
 
@@ -709,21 +961,40 @@ func (w *glfwWindow) build() error {
 		dstWidth, dstHeight = p.Size()
 	)
 
-	// Specify the primary monitor if we want fullscreen, store the monitor
+	// Specify the requested monitor if we want fullscreen, store the monitor
 	// regardless for centering the window.
-	w.monitor = glfw.GetPrimaryMonitor()
+	w.monitor = findMonitor(p.Monitor())
+	w.last.SetMonitor(p.Monitor())
+	w.last.SetVideoMode(p.VideoMode())
+	w.last.SetBorderless(p.Borderless())
+	w.last.SetResizable(p.Resizable())
+	w.last.SetDecorated(p.Decorated())
+	w.last.SetAlwaysOnTop(p.AlwaysOnTop())
+	refreshRate := glfw.DontCare
+	decorated := p.Decorated()
+	borderless := p.Fullscreen() && p.Borderless()
 	if p.Fullscreen() {
-		dstMonitor = w.monitor
 		w.beforeFullscreen = [2]int{dstWidth, dstHeight}
 
-		// TODO(slimsag): publish a way to get valid video modes instead of
-		// assuming the monitor's one.
-		vm := w.monitor.GetVideoMode()
+		var vm VideoMode
+		if borderless {
+			// Borderless fullscreen is a regular undecorated window covering
+			// the monitor at its current video mode.
+			vm = convertVidMode(w.monitor.GetVideoMode())
+			decorated = false
+		} else {
+			// Pick the supported video mode closest to the requested one.
+			dstMonitor = w.monitor
+			vm = convertMonitor(w.monitor, false).Closest(p.VideoMode())
+			refreshRate = vm.RefreshRate
+		}
 		dstWidth, dstHeight = vm.Width, vm.Height
-		w.props.SetSize(dstWidth, dstHeight)
-		w.last.SetSize(dstWidth, dstHeight)
+		width, height := w.fromScreen(dstWidth, dstHeight)
+		w.props.SetSize(width, height)
+		w.last.SetSize(width, height)
 	} else {
 		w.beforeFullscreen = [2]int{dstWidth, dstHeight}
+		dstWidth, dstHeight = w.toScreen(dstWidth, dstHeight)
 	}
 
 	// Hint standard properties (note visibility is always false, we show the
@@ -735,7 +1006,7 @@ func (w *glfwWindow) build() error {
 		//glfw.Focused: intBool(p.Focused()),
 		//glfw.Iconified: intBool(p.Minimized()),
 		glfw.Resizable:           intBool(p.Resizable()),
-		glfw.Decorated:           intBool(p.Decorated()),
+		glfw.Decorated:           intBool(decorated),
 		glfw.AutoIconify:         1,
 		glfw.Floating:            intBool(p.AlwaysOnTop()),
 		glfw.RedBits:             int(prec.RedBits),
@@ -745,6 +1016,7 @@ func (w *glfwWindow) build() error {
 		glfw.DepthBits:           int(prec.DepthBits),
 		glfw.StencilBits:         int(prec.StencilBits),
 		glfw.Samples:             prec.Samples,
+		glfw.RefreshRate:         refreshRate,
 		glfw.SRGBCapable:         1,
 		glfw.OpenGLDebugContext:  intBool(tag.Gfxdebug),
 		glfw.ContextVersionMajor: glfwContextVersionMajor,
@@ -763,6 +1035,10 @@ func (w *glfwWindow) build() error {
 	if err != nil {
 		return err
 	}
+	if borderless && !glfwWayland {
+		// Cover the monitor entirely.
+		w.window.SetPos(w.monitor.GetPos())
+	}
 
 	// OpenGL context must be active.
 	w.window.MakeContextCurrent()
@@ -783,6 +1059,7 @@ func (w *glfwWindow) build() error {
 
 	// Setup callbacks and the window.
 	w.initCallbacks()
+	glfwHookWindow(w)
 	w.useProps(p, true)
 
 	// Done with OpenGL things on this window, for now.
@@ -817,6 +1094,11 @@ func doNew(p *Props) (Window, gfx.Device, error) {
 
 	w.swapper = util.NewSwapper(w.device)
 
+	// Deliver gamepad events to the window.
+	openWindows.Lock()
+	openWindows.m[w] = struct{}{}
+	openWindows.Unlock()
+
 	// Spawn the goroutine responsible for running the window.
 	go w.run()
 
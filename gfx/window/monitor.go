@@ -0,0 +1,119 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package window
+
+import "fmt"
+
+// VideoMode describes a single video mode of a monitor.
+type VideoMode struct {
+	// The resolution of the video mode, in screen coordinates.
+	Width, Height int
+
+	// The refresh rate of the video mode, in hertz.
+	RefreshRate int
+
+	// The bit depth of each color channel.
+	RedBits, GreenBits, BlueBits int
+}
+
+// String returns a string like:
+//
+//  "1920x1080@60hz"
+//
+func (v VideoMode) String() string {
+	return fmt.Sprintf("%dx%d@%dhz", v.Width, v.Height, v.RefreshRate)
+}
+
+// Monitor describes a single monitor connected to the system.
+type Monitor struct {
+	// The human-readable name of the monitor, e.g. "DELL U2412M". The name is
+	// not guaranteed to be unique.
+	Name string
+
+	// Whether or not this is the primary monitor of the system.
+	Primary bool
+
+	// The position of the upper-left corner of the monitor in the virtual
+	// screen, in screen coordinates.
+	X, Y int
+
+	// The physical size of the monitor in millimeters, or zero if unknown.
+	PhysicalWidth, PhysicalHeight int
+
+	// The current video mode of the monitor.
+	Mode VideoMode
+
+	// The video modes supported by the monitor, sorted in ascending order by
+	// resolution and then refresh rate.
+	Modes []VideoMode
+}
+
+// DPI returns the horizontal and vertical dots per inch of the monitor at its
+// current video mode. If the physical size of the monitor is unknown, zero is
+// returned.
+func (m *Monitor) DPI() (x, y float64) {
+	const mmPerInch = 25.4
+	if m.PhysicalWidth > 0 {
+		x = float64(m.Mode.Width) / (float64(m.PhysicalWidth) / mmPerInch)
+	}
+	if m.PhysicalHeight > 0 {
+		y = float64(m.Mode.Height) / (float64(m.PhysicalHeight) / mmPerInch)
+	}
+	return
+}
+
+// Closest returns the video mode of the monitor closest to the requested one.
+// Zero fields in the request are not considered, e.g. a request with only a
+// refresh rate picks the current resolution at the nearest refresh rate.
+//
+// If the monitor has no known video modes, its current one is returned.
+func (m *Monitor) Closest(want VideoMode) VideoMode {
+	if want.Width == 0 || want.Height == 0 {
+		want.Width, want.Height = m.Mode.Width, m.Mode.Height
+	}
+	if want.RefreshRate == 0 {
+		want.RefreshRate = m.Mode.RefreshRate
+	}
+	abs := func(x int) int {
+		if x < 0 {
+			return -x
+		}
+		return x
+	}
+
+	best := m.Mode
+	bestDist := -1
+	for _, mode := range m.Modes {
+		// Resolution differences weigh more than refresh rate ones.
+		dist := (abs(mode.Width-want.Width)+abs(mode.Height-want.Height))*1000 +
+			abs(mode.RefreshRate-want.RefreshRate)
+		if want.RedBits != 0 {
+			dist += abs(mode.RedBits-want.RedBits) + abs(mode.GreenBits-want.GreenBits) + abs(mode.BlueBits-want.BlueBits)
+		}
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = mode, dist
+		}
+	}
+	return best
+}
+
+// Monitors returns a list of the monitors connected to the system, the
+// primary monitor is always first.
+//
+// Like New, Monitors requests operations be run on the main loop internally
+// and cannot complete unless MainLoop is running.
+func Monitors() ([]*Monitor, error) {
+	var (
+		monitors []*Monitor
+		err      error
+	)
+	done := make(chan struct{}, 1)
+	MainLoopChan <- func() {
+		monitors, err = doMonitors()
+		done <- struct{}{}
+	}
+	<-done
+	return monitors, err
+}
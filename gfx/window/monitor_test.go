@@ -0,0 +1,41 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package window
+
+import "testing"
+
+func TestMonitorClosest(t *testing.T) {
+	m := &Monitor{
+		Mode: VideoMode{Width: 1920, Height: 1080, RefreshRate: 60},
+		Modes: []VideoMode{
+			{Width: 1280, Height: 720, RefreshRate: 60},
+			{Width: 1920, Height: 1080, RefreshRate: 60},
+			{Width: 1920, Height: 1080, RefreshRate: 144},
+		},
+	}
+	tests := []struct {
+		want, got VideoMode
+	}{
+		{VideoMode{}, VideoMode{Width: 1920, Height: 1080, RefreshRate: 60}},
+		{VideoMode{RefreshRate: 120}, VideoMode{Width: 1920, Height: 1080, RefreshRate: 144}},
+		{VideoMode{Width: 1280, Height: 700}, VideoMode{Width: 1280, Height: 720, RefreshRate: 60}},
+	}
+	for _, tst := range tests {
+		if got := m.Closest(tst.want); got != tst.got {
+			t.Errorf("Closest(%v) = %v, want %v", tst.want, got, tst.got)
+		}
+	}
+}
+
+func TestMonitorDPI(t *testing.T) {
+	m := &Monitor{
+		PhysicalWidth: 508,
+		Mode:          VideoMode{Width: 1920, Height: 1080},
+	}
+	x, y := m.DPI()
+	if x < 95.9 || x > 96.1 || y != 0 {
+		t.Fatalf("DPI() = %v, %v, want 96, 0", x, y)
+	}
+}
@@ -11,6 +11,84 @@ import "sync"
 type notifierEntry struct {
 	ch chan<- Event
 	EventMask
+	*overflow
+}
+
+// maxOverflow is the maximum number of events queued for a single channel,
+// further events are dropped until the queue drains.
+const maxOverflow = 1024
+
+// overflow queues the events that did not fit into the buffer of a channel,
+// and delivers them in order as space becomes available.
+type overflow struct {
+	sync.Mutex
+
+	// The queued events, which are sent by the drain goroutine while
+	// draining is true. Events are only sent to the channel directly while
+	// it is not.
+	events   []Event
+	draining bool
+
+	// stop is closed when the channel is unsubscribed, stopping the drain
+	// goroutine. done is signaled once the drain goroutine has exited, after
+	// which nothing sends to the channel anymore.
+	stop chan struct{}
+	done sync.WaitGroup
+}
+
+// send sends the event to ch, or queues it if ch is full (or events are
+// already queued). Consecutive high-frequency events are coalesced (see
+// Coalesce) while queued, such that e.g. a burst of cursor movement takes up
+// a single event. Events are dropped once maxOverflow events are queued.
+func (o *overflow) send(ch chan<- Event, ev Event) {
+	o.Lock()
+	defer o.Unlock()
+	if !o.draining {
+		select {
+		case ch <- ev:
+			return
+		default:
+		}
+	}
+	if n := len(o.events); n > 0 {
+		if merged, ok := coalesce(o.events[n-1], ev); ok {
+			o.events[n-1] = merged
+			return
+		}
+	}
+	if len(o.events) >= maxOverflow {
+		return
+	}
+	o.events = append(o.events, ev)
+	if !o.draining {
+		o.draining = true
+		o.done.Add(1)
+		go o.drain(ch)
+	}
+}
+
+// drain sends the queued events to ch, blocking until each one is received,
+// until there are no more events queued or the channel is unsubscribed.
+func (o *overflow) drain(ch chan<- Event) {
+	defer o.done.Done()
+	for {
+		o.Lock()
+		if len(o.events) == 0 {
+			o.draining = false
+			o.Unlock()
+			return
+		}
+		ev := o.events[0]
+		o.events[0] = nil
+		o.events = o.events[1:]
+		o.Unlock()
+
+		select {
+		case ch <- ev:
+		case <-o.stop:
+			return
+		}
+	}
 }
 
 // notifier implements the Window interface's Notify method.
@@ -25,7 +103,7 @@ func (n *notifier) Notify(ch chan<- Event, m EventMask) {
 	if m == NoEvents {
 		n.deleteEntries(ch)
 	} else {
-		n.entries = append(n.entries, notifierEntry{ch, m})
+		n.entries = append(n.entries, notifierEntry{ch, m, &overflow{stop: make(chan struct{})}})
 	}
 	n.Unlock()
 }
@@ -43,27 +121,41 @@ func (n *notifier) findEntry(ch chan<- Event) int {
 	return -1
 }
 
-// deleteEntries deletes all entries associated with ch.
+// deleteEntries deletes all entries associated with ch, and waits for their
+// drain goroutines to exit such that ch may be closed afterwards.
 func (n *notifier) deleteEntries(ch chan<- Event) {
-	s := n.entries
 	idx := n.findEntry(ch)
 	for idx != -1 {
-		s = append(s[:idx], s[idx+1:]...)
+		e := n.entries[idx]
+		close(e.stop)
+		e.done.Wait()
+		n.entries = append(n.entries[:idx], n.entries[idx+1:]...)
 		idx = n.findEntry(ch)
 	}
-	n.entries = s
+}
+
+// wants reports whether or not any notifier entry's bitmask matches with m.
+// Senders of high-frequency events use it to avoid creating events (boxing
+// them in the Event interface is a heap allocation) that nobody would
+// receive.
+func (n *notifier) wants(m EventMask) bool {
+	n.RLock()
+	defer n.RUnlock()
+	for _, nf := range n.entries {
+		if (nf.EventMask & m) != 0 {
+			return true
+		}
+	}
+	return false
 }
 
 // sendEvent sends the given event to all of the notifier entries whose bitmask
-// matches with m.
+// matches with m, without blocking (see overflow).
 func (n *notifier) sendEvent(ev Event, m EventMask) {
 	n.RLock()
 	for _, nf := range n.entries {
 		if (nf.EventMask & m) != 0 {
-			select {
-			case nf.ch <- ev:
-			default:
-			}
+			nf.send(nf.ch, ev)
 		}
 	}
 	n.RUnlock()
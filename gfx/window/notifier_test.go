@@ -0,0 +1,114 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package window
+
+import "testing"
+
+func TestNotifierWants(t *testing.T) {
+	n := &notifier{}
+	if n.wants(CursorMovedEvents) {
+		t.Fatal("expected no wanted events")
+	}
+	ch := make(chan Event, 1)
+	n.Notify(ch, CursorMovedEvents|MouseEvents)
+	if !n.wants(CursorMovedEvents) || n.wants(KeyboardTypedEvents) {
+		t.Fatal("wrong wanted events")
+	}
+}
+
+func TestNotifierDelete(t *testing.T) {
+	n := &notifier{}
+	a, b := make(chan Event, 1), make(chan Event, 1)
+	n.Notify(a, CursorMovedEvents)
+	n.Notify(b, MouseEvents)
+	n.Notify(a, KeyboardTypedEvents)
+	n.Notify(a, NoEvents)
+	if len(n.entries) != 1 || n.entries[0].ch != b {
+		t.Fatalf("got %d entries, want only the other channel's", len(n.entries))
+	}
+	n.Notify(b, NoEvents)
+	if n.wants(MouseEvents) {
+		t.Fatal("expected no wanted events after unsubscribing")
+	}
+}
+
+func TestNotifierOverflow(t *testing.T) {
+	n := &notifier{}
+	ch := make(chan Event, 1)
+	n.Notify(ch, CursorMovedEvents|CloseEvents)
+
+	// The first event fits into the channel, the others are queued (with the
+	// cursor movement coalesced, unless already being delivered) and
+	// delivered in order.
+	for i := 1; i <= 3; i++ {
+		n.sendEvent(CursorMoved{X: float64(i), Y: float64(i)}, CursorMovedEvents)
+	}
+	n.sendEvent(Close{}, CloseEvents)
+	var got []Event
+	for {
+		ev := <-ch
+		if _, ok := ev.(Close); ok {
+			break
+		}
+		got = append(got, ev)
+	}
+	if len(got) < 2 || got[0] != (CursorMoved{X: 1, Y: 1}) || got[len(got)-1] != (CursorMoved{X: 3, Y: 3}) {
+		t.Fatalf("got events %v", got)
+	}
+}
+
+func TestNotifierUnsubscribeClose(t *testing.T) {
+	n := &notifier{}
+	ch := make(chan Event, 1)
+	n.Notify(ch, CloseEvents)
+	for i := 0; i < 3; i++ {
+		n.sendEvent(Close{}, CloseEvents)
+	}
+
+	// Once unsubscribed, the queued events must not be sent to the (now
+	// closed) channel.
+	n.Notify(ch, NoEvents)
+	close(ch)
+}
+
+func TestNotifierOverflowLimit(t *testing.T) {
+	n := &notifier{}
+	ch := make(chan Event)
+	n.Notify(ch, CloseEvents)
+	for i := 0; i < maxOverflow*2; i++ {
+		n.sendEvent(Close{}, CloseEvents)
+	}
+	o := n.entries[0].overflow
+	o.Lock()
+	queued := len(o.events)
+	o.Unlock()
+	if queued > maxOverflow {
+		t.Fatalf("got %d queued events, want at most %d", queued, maxOverflow)
+	}
+	n.Notify(ch, NoEvents)
+}
+
+// The benchmarks below send cursor movement events which no one listens for,
+// as is the case for most applications.
+
+func BenchmarkCursorMovedUnguarded(b *testing.B) {
+	n := &notifier{}
+	n.Notify(make(chan Event, 1), KeyboardTypedEvents)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		n.sendEvent(CursorMoved{X: float64(i), Y: float64(i)}, CursorMovedEvents)
+	}
+}
+
+func BenchmarkCursorMovedGuarded(b *testing.B) {
+	n := &notifier{}
+	n.Notify(make(chan Event, 1), KeyboardTypedEvents)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if n.wants(CursorMovedEvents) {
+			n.sendEvent(CursorMoved{X: float64(i), Y: float64(i)}, CursorMovedEvents)
+		}
+	}
+}
@@ -6,9 +6,11 @@ package window
 
 import (
 	"fmt"
+	"image"
 	"sync"
 
 	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/keyboard"
 )
 
 // Props represents window properties. Properties are safe for use concurrently
@@ -17,11 +19,19 @@ type Props struct {
 	l                                                 sync.RWMutex
 	title                                             string
 	width, height, fbWidth, fbHeight, x, y            int
-	cursorX, cursorY                                  float64
+	cursorX, cursorY, scaleX, scaleY                  float64
 	fullscreen, shouldClose, visible, decorated       bool
 	minimized, focused, vsync, resizable, alwaysOnTop bool
-	cursorGrabbed, resizeRenderSync                   bool
+	cursorGrabbed, resizeRenderSync, srgb, borderless bool
+	pixelExact, rawMouseMotion                        bool
+	cursorVisible, cursorConfined                     bool
+	cursor                                            *Cursor
+	icon                                              image.Image
+	opacity                                           float64
+	monitor                                           string
+	videoMode                                         VideoMode
 	precision                                         gfx.Precision
+	screenshotKey                                     keyboard.Key
 }
 
 // String returns a string like:
@@ -30,7 +40,7 @@ type Props struct {
 //
 func (p *Props) String() string {
 	p.l.RLock()
-	str := fmt.Sprintf("Window(Title=%q, Fullscreen=%q)", p.title, p.fullscreen)
+	str := fmt.Sprintf("Window(Title=%q, Fullscreen=%v)", p.title, p.fullscreen)
 	p.l.RUnlock()
 	return str
 }
@@ -71,6 +81,71 @@ func (p *Props) Fullscreen() bool {
 	return fullscreen
 }
 
+// SetBorderless sets whether or not fullscreen windows use borderless (also
+// called "windowed") fullscreen mode: an undecorated window covering the
+// entire monitor at its current desktop video mode.
+//
+// Unlike exclusive fullscreen the monitor's video mode is never changed (the
+// VideoMode property is ignored), which makes switching to other applications
+// instant at the cost of the application not owning the display.
+//
+// It only has an effect while the window is fullscreen.
+func (p *Props) SetBorderless(borderless bool) {
+	p.l.Lock()
+	p.borderless = borderless
+	p.l.Unlock()
+}
+
+// Borderless tells whether or not fullscreen windows use borderless fullscreen
+// mode, as previously set via SetBorderless.
+func (p *Props) Borderless() bool {
+	p.l.RLock()
+	borderless := p.borderless
+	p.l.RUnlock()
+	return borderless
+}
+
+// SetMonitor sets the name of the monitor (see Monitors) that the window is
+// made fullscreen on. An empty string, or the name of a monitor that is not
+// connected, means the primary monitor.
+//
+// The monitor is also used to center the window when its position is -1, -1.
+func (p *Props) SetMonitor(name string) {
+	p.l.Lock()
+	p.monitor = name
+	p.l.Unlock()
+}
+
+// Monitor returns the name of the monitor the window is made fullscreen on, as
+// previously set via SetMonitor.
+func (p *Props) Monitor() string {
+	p.l.RLock()
+	name := p.monitor
+	p.l.RUnlock()
+	return name
+}
+
+// SetVideoMode sets the video mode that the monitor is switched to while the
+// window is fullscreen. The closest mode supported by the monitor is used (see
+// Monitor.Closest), so a zero resolution or refresh rate keeps the monitor's
+// current one.
+//
+// The zero VideoMode, the default, keeps the monitor's current video mode.
+func (p *Props) SetVideoMode(m VideoMode) {
+	p.l.Lock()
+	p.videoMode = m
+	p.l.Unlock()
+}
+
+// VideoMode returns the video mode used while the window is fullscreen, as
+// previously set via SetVideoMode.
+func (p *Props) VideoMode() VideoMode {
+	p.l.RLock()
+	m := p.videoMode
+	p.l.RUnlock()
+	return m
+}
+
 // SetFramebufferSize sets the size of the framebuffer in pixels. Each value is
 // clamped to at least a value of 1.
 //
@@ -99,8 +174,66 @@ func (p *Props) FramebufferSize() (width, height int) {
 	return
 }
 
-// SetSize sets the size of the window in screen coordinates. Each value is
-// clamped to at least a value of 1.
+// SetContentScale sets the content scale of the window: the ratio of
+// framebuffer pixels to screen coordinates along each axis. Each value is
+// clamped to a minimum of a small positive number.
+//
+// Only the Window implementation should set the content scale: clients who are
+// just utilizing the existing implementations defined in this package should
+// not invoke this method.
+func (p *Props) SetContentScale(x, y float64) {
+	const min = 1.0 / 64
+	if x < min {
+		x = min
+	}
+	if y < min {
+		y = min
+	}
+	p.l.Lock()
+	p.scaleX = x
+	p.scaleY = y
+	p.l.Unlock()
+}
+
+// ContentScale returns the content scale of the window: the ratio of
+// framebuffer pixels to screen coordinates along each axis. It is 1.0 on
+// regular displays and e.g. 2.0 on most HiDPI ("retina") displays.
+//
+// User interfaces should multiply their sizes by the content scale to avoid
+// appearing tiny on HiDPI displays.
+func (p *Props) ContentScale() (x, y float64) {
+	p.l.RLock()
+	x = p.scaleX
+	y = p.scaleY
+	p.l.RUnlock()
+	return
+}
+
+// SetPixelExact sets whether or not the window size (see SetSize) is specified
+// in framebuffer pixels instead of screen coordinates.
+//
+// By default (false) a window requested to be 800x450 is 800x450 screen
+// coordinates large, and on a HiDPI display with a content scale of 2.0 will
+// have a scaled 1600x900 pixel framebuffer. When pixel exact, the same window
+// is instead made 400x225 screen coordinates large such that its framebuffer
+// is exactly 800x450 pixels.
+func (p *Props) SetPixelExact(pixelExact bool) {
+	p.l.Lock()
+	p.pixelExact = pixelExact
+	p.l.Unlock()
+}
+
+// PixelExact tells whether or not the window size is specified in framebuffer
+// pixels instead of screen coordinates, as previously set via SetPixelExact.
+func (p *Props) PixelExact() bool {
+	p.l.RLock()
+	pixelExact := p.pixelExact
+	p.l.RUnlock()
+	return pixelExact
+}
+
+// SetSize sets the size of the window in screen coordinates (or framebuffer
+// pixels, see SetPixelExact). Each value is clamped to at least a value of 1.
 func (p *Props) SetSize(width, height int) {
 	if width < 1 {
 		width = 1
@@ -114,7 +247,8 @@ func (p *Props) SetSize(width, height int) {
 	p.l.Unlock()
 }
 
-// Size returns the size of the window in screen coordinates.
+// Size returns the size of the window in screen coordinates (or framebuffer
+// pixels, see SetPixelExact).
 func (p *Props) Size() (width, height int) {
 	p.l.RLock()
 	width = p.width
@@ -270,6 +404,52 @@ func (p *Props) Decorated() bool {
 	return decorated
 }
 
+// SetIcon sets the icon of the window, as displayed e.g. in the title bar and
+// task bar. A nil icon means the system's default icon. Square images with
+// sizes like 16x16, 32x32 or 48x48 work best on most platforms. OS X windows
+// have no icon, and Wayland offers no way to set one.
+//
+// The icon must not be modified after it has been set.
+func (p *Props) SetIcon(icon image.Image) {
+	p.l.Lock()
+	p.icon = icon
+	p.l.Unlock()
+}
+
+// Icon returns the icon of the window, as previously set via SetIcon.
+func (p *Props) Icon() image.Image {
+	p.l.RLock()
+	icon := p.icon
+	p.l.RUnlock()
+	return icon
+}
+
+// SetOpacity sets the opacity of the entire window, including its decorations,
+// in the range of 0 (fully transparent) to 1 (fully opaque). The value is
+// clamped to that range.
+//
+// Not all platforms support window opacity (e.g. Wayland), in which case the
+// window stays fully opaque.
+func (p *Props) SetOpacity(opacity float64) {
+	if opacity < 0 {
+		opacity = 0
+	}
+	if opacity > 1 {
+		opacity = 1
+	}
+	p.l.Lock()
+	p.opacity = opacity
+	p.l.Unlock()
+}
+
+// Opacity returns the opacity of the window, as previously set via SetOpacity.
+func (p *Props) Opacity() float64 {
+	p.l.RLock()
+	opacity := p.opacity
+	p.l.RUnlock()
+	return opacity
+}
+
 // SetAlwaysOnTop sets whether or not the window is always on top of other
 // windows.
 func (p *Props) SetAlwaysOnTop(alwaysOnTop bool) {
@@ -287,6 +467,84 @@ func (p *Props) AlwaysOnTop() bool {
 	return alwaysOnTop
 }
 
+// SetSRGB sets whether or not the window's framebuffer converts the (linear)
+// colors written to it into sRGB space, see gfx.SRGBCanvas. It has no effect
+// if the graphics device does not support sRGB framebuffers.
+func (p *Props) SetSRGB(srgb bool) {
+	p.l.Lock()
+	p.srgb = srgb
+	p.l.Unlock()
+}
+
+// SRGB tells whether or not the window's framebuffer converts the colors
+// written to it into sRGB space.
+func (p *Props) SRGB() bool {
+	p.l.RLock()
+	srgb := p.srgb
+	p.l.RUnlock()
+	return srgb
+}
+
+// SetCursor sets the appearance of the mouse cursor while it is over the
+// window. A nil cursor means the standard arrow cursor.
+//
+// The cursor must not be modified after it has been set, see the Cursor type
+// for details.
+func (p *Props) SetCursor(c *Cursor) {
+	p.l.Lock()
+	p.cursor = c
+	p.l.Unlock()
+}
+
+// Cursor returns the appearance of the mouse cursor, as previously set via
+// SetCursor.
+func (p *Props) Cursor() *Cursor {
+	p.l.RLock()
+	c := p.cursor
+	p.l.RUnlock()
+	return c
+}
+
+// SetCursorVisible sets whether or not the mouse cursor is visible while it is
+// over the window. Unlike grabbing the cursor, a hidden cursor still moves
+// freely and generates absolute CursorMoved events.
+func (p *Props) SetCursorVisible(visible bool) {
+	p.l.Lock()
+	p.cursorVisible = visible
+	p.l.Unlock()
+}
+
+// CursorVisible tells whether or not the mouse cursor is visible while it is
+// over the window, as previously set via SetCursorVisible.
+func (p *Props) CursorVisible() bool {
+	p.l.RLock()
+	visible := p.cursorVisible
+	p.l.RUnlock()
+	return visible
+}
+
+// SetCursorConfined sets whether or not the mouse cursor is confined to the
+// window while it has focus. Unlike grabbing the cursor, a confined cursor
+// stays visible and generates absolute CursorMoved events, e.g. for scrolling
+// the map of a strategy game when the cursor touches the window's edge.
+//
+// The cursor is confined by the operating system, on Windows and X11 only. OS X
+// and Wayland offer no way to confine the cursor, there it moves freely.
+func (p *Props) SetCursorConfined(confined bool) {
+	p.l.Lock()
+	p.cursorConfined = confined
+	p.l.Unlock()
+}
+
+// CursorConfined tells whether or not the mouse cursor is confined to the
+// window, as previously set via SetCursorConfined.
+func (p *Props) CursorConfined() bool {
+	p.l.RLock()
+	confined := p.cursorConfined
+	p.l.RUnlock()
+	return confined
+}
+
 // SetCursorGrabbed sets whether or not the cursor should be grabbed. If the
 // cursor is grabbed, it is hidden from sight and cannot leave the window.
 //
@@ -306,6 +564,43 @@ func (p *Props) CursorGrabbed() bool {
 	return grabbed
 }
 
+// SetRawMouseMotion sets whether or not raw (unaccelerated) mouse motion is
+// used while the cursor is grabbed. Raw motion is what FPS-style camera
+// controls want: it reflects the physical movement of the mouse without the
+// operating system's pointer acceleration (or scaling) applied.
+//
+// The property is ignored unless RawMouseMotionSupported returns true, which
+// is not the case e.g. on macOS or on X11 servers without the XInput
+// extension. Whether or not a
+// CursorMoved event carries raw motion is indicated by its Raw field.
+func (p *Props) SetRawMouseMotion(raw bool) {
+	p.l.Lock()
+	p.rawMouseMotion = raw
+	p.l.Unlock()
+}
+
+// RawMouseMotion tells whether or not raw mouse motion is used while the
+// cursor is grabbed, as previously set via SetRawMouseMotion.
+func (p *Props) RawMouseMotion() bool {
+	p.l.RLock()
+	raw := p.rawMouseMotion
+	p.l.RUnlock()
+	return raw
+}
+
+// RawMouseMotionSupported tells whether or not raw mouse motion (see
+// Props.SetRawMouseMotion) is supported on this system.
+//
+// Like New, RawMouseMotionSupported requests operations be run on the main
+// loop internally and cannot complete unless MainLoop is running.
+func RawMouseMotionSupported() bool {
+	supported := make(chan bool, 1)
+	MainLoopChan <- func() {
+		supported <- doRawMouseMotionSupported()
+	}
+	return <-supported
+}
+
 // SetResizeRenderSync sets whether or not window resize operations should be
 // synchronized with rendering. In general, this controls whether or not
 // resizing the window will be appear "fluid" by halting the user from resizing
@@ -363,6 +658,25 @@ func (p *Props) Precision() gfx.Precision {
 	return precision
 }
 
+// SetScreenshotKey sets the key which, when pressed, saves a screenshot of the
+// window to a PNG file in the current working directory (named e.g.
+// "screenshot-20060102-150405.000.png"). If key == keyboard.Invalid, then the
+// screenshot hotkey is disabled.
+func (p *Props) SetScreenshotKey(key keyboard.Key) {
+	p.l.Lock()
+	p.screenshotKey = key
+	p.l.Unlock()
+}
+
+// ScreenshotKey returns the key which saves a screenshot of the window when
+// pressed, or keyboard.Invalid if the screenshot hotkey is disabled.
+func (p *Props) ScreenshotKey() keyboard.Key {
+	p.l.RLock()
+	key := p.screenshotKey
+	p.l.RUnlock()
+	return key
+}
+
 // NewProps returns a new initialized set of window properties. The default
 // values for each property are as follows:
 //
@@ -374,14 +688,27 @@ func (p *Props) Precision() gfx.Precision {
 //  Visible: true
 //  Minimized: false
 //  Fullscreen: false
+//  Borderless: false
 //  Focused: true
 //  VSync: true
 //  Resizable: true
 //  Decorated: true
 //  AlwaysOnTop: false
+//  Icon: nil (system default)
+//  Opacity: 1.0
+//  Cursor: nil (standard arrow cursor)
+//  CursorVisible: true
+//  CursorConfined: false
 //  CursorGrabbed: false
+//  RawMouseMotion: true
 //  ResizeRenderSync: true
+//  SRGB: false
+//  Monitor: "" (primary monitor)
+//  VideoMode: VideoMode{} (monitor's current mode)
+//  ScreenshotKey: keyboard.Invalid (disabled)
+//  PixelExact: false
 //  FramebufferSize: 1x1 (set via window owner)
+//  ContentScale: 1.0, 1.0 (set via window owner)
 //  Precision: gfx.Precision{
 //      RedBits: 8, GreenBits: 8, BlueBits: 8, AlphaBits: 0,
 //      DepthBits: 24,
@@ -400,17 +727,29 @@ func NewProps() *Props {
 		y:                -1,
 		cursorX:          -1.0,
 		cursorY:          -1.0,
+		scaleX:           1.0,
+		scaleY:           1.0,
 		shouldClose:      true,
 		visible:          true,
 		minimized:        false,
 		fullscreen:       false,
+		borderless:       false,
 		focused:          true,
 		vsync:            true,
 		resizable:        true,
 		decorated:        true,
 		alwaysOnTop:      false,
+		icon:             nil,
+		opacity:          1.0,
+		cursor:           nil,
+		cursorVisible:    true,
+		cursorConfined:   false,
 		cursorGrabbed:    false,
+		rawMouseMotion:   true,
 		resizeRenderSync: true,
+		srgb:             false,
+		pixelExact:       false,
+		screenshotKey:    keyboard.Invalid,
 		precision: gfx.Precision{
 			RedBits: 8, GreenBits: 8, BlueBits: 8, AlphaBits: 0,
 			DepthBits: 24,
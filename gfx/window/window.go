@@ -6,34 +6,39 @@ package window
 
 import (
 	"errors"
-	"log"
+	"os"
 	"sync"
 
+	"azul3d.org/engine/gamepad"
 	"azul3d.org/engine/gfx"
 	"azul3d.org/engine/keyboard"
+	"azul3d.org/engine/log"
 	"azul3d.org/engine/mouse"
 )
 
-// Clipboard is the interface describing a system's clipboard. Grab a clipboard
-// from a window (some platforms don't support clipboard access):
+// Clipboard is the interface describing a system's clipboard. Every window
+// implements it, for instance to copy and paste text:
 //
-//  clip, ok := win.(window.Clipboard)
-//  if ok {
-//      // We have clipboard access.
-//      clip.SetClipboard("Hello World!")
-//  }
+//  win.SetClipboard("Hello World!")
+//  fmt.Println(win.Clipboard()) // "Hello World!"
 //
+// On platforms without clipboard access, SetClipboard does nothing and
+// Clipboard returns an empty string.
 type Clipboard interface {
 	// SetClipboard sets the clipboard string.
 	SetClipboard(clipboard string)
 
-	// Clipboard returns the clipboard string.
+	// Clipboard returns the clipboard string, or an empty string if the
+	// clipboard is empty or does not contain text.
 	Clipboard() string
 }
 
 // Window represents a single window that graphics can be drawn to. The window
 // is safe for use concurrently from multiple goroutines.
 type Window interface {
+	// Clipboard provides access to the system's clipboard.
+	Clipboard
+
 	// Props returns the window's properties.
 	Props() *Props
 
@@ -68,16 +73,34 @@ type Window interface {
 	//
 	Mouse() *mouse.Watcher
 
+	// Gamepads returns a gamepad watcher, which is shared by all windows. It
+	// can be used to tell if certain gamepad buttons are currently held down,
+	// for instance:
+	//
+	//  for _, id := range w.Gamepads().Connected() {
+	//      if w.Gamepads().Down(id, gamepad.A) {
+	//          fmt.Println("The A button of gamepad", id, "is held down")
+	//      }
+	//  }
+	//
+	// Gamepad events are sent to every window, regardless of focus.
+	Gamepads() *gamepad.Watcher
+
 	// Notify causes the window to relay window events to ch based on the event
 	// mask.
 	//
 	// The special event mask NoEvents causes the window to stop relaying any
 	// events to the given channel. You should always perform this action when
-	// you are done using the event channel.
+	// you are done using the event channel, once it returns no more events
+	// are sent to the channel (and it may be closed).
 	//
-	// The window will not block sending events to ch: the caller must ensure
-	// that ch has a sufficient amount of buffer space to keep up with the
-	// event rate.
+	// The window will not block sending events to ch: events which do not fit
+	// into it's buffer are queued, and sent in order as space becomes
+	// available. Consecutive high-frequency events (e.g. cursor movement, see
+	// Coalesce) are merged while queued, but others are not, and events are
+	// dropped once a limited number of them are queued. The caller should
+	// still ensure that ch has a sufficient amount of buffer space to keep up
+	// with the event rate.
 	//
 	// If you only expect to receive a single event like Close then a buffer
 	// size of one is acceptable.
@@ -195,6 +218,13 @@ func New(p *Props) (w Window, d gfx.Device, err error) {
 	MainLoopChan <- func() {
 		// Create a new window via the platform-specific backend.
 		w, d, err = doNew(p)
+
+		// Increment the number of open windows while still on the main loop,
+		// otherwise another window closing in the meantime could observe a
+		// count of zero and cause the main loop to exit early.
+		if err == nil {
+			Num(1)
+		}
 		done <- struct{}{}
 	}
 	<-done
@@ -203,12 +233,51 @@ func New(p *Props) (w Window, d gfx.Device, err error) {
 	if err != nil {
 		return nil, nil, err
 	}
-
-	// No error occured, increment the number of open windows and return.
-	Num(1)
 	return w, d, err
 }
 
+// Spawn creates a new window with the given properties (see New) and runs the
+// given graphics loop for it in a new goroutine. It is the multiple-window
+// analogue of Run, for instance an editor could open a preview window:
+//
+//  func gfxLoop(w window.Window, d gfx.Device) {
+//      err := window.Spawn(previewLoop, previewProps)
+//      if err != nil {
+//          log.Fatal(err)
+//      }
+//      ... render the editor ...
+//  }
+//
+// Like New, Spawn cannot complete unless MainLoop is running. Any error that
+// occurs while creating the window is returned and gfxLoop is not invoked.
+func Spawn(gfxLoop func(w Window, d gfx.Device), p *Props) error {
+	if gfxLoop == nil {
+		panic("window: nil graphics loop function!")
+	}
+	w, d, err := New(p)
+	if err != nil {
+		return err
+	}
+	go runLoop(gfxLoop, w, d)
+	return nil
+}
+
+// runLoop runs the graphics loop for the given window and device, closing the
+// window if the loop panics.
+func runLoop(gfxLoop func(w Window, d gfx.Device), w Window, d gfx.Device) {
+	// If the gfxLoop panics, we should still close the window (or else the
+	// user's screen resolution won't be restored, for example).
+	defer func() {
+		if r := recover(); r != nil {
+			w.Close()
+			panic(r)
+		}
+	}()
+
+	// Enter the graphics loop.
+	gfxLoop(w, d)
+}
+
 // Run opens a window with the given properties and runs the given graphics
 // loop in a separate goroutine.
 //
@@ -219,6 +288,9 @@ func New(p *Props) (w Window, d gfx.Device, err error) {
 //      window.Run(gfxLoop, nil)
 //  }
 //
+// If the window cannot be created, the error is logged (with the "window"
+// tag) and the program exits.
+//
 // For more documentation about the behavior of Run, see the New function.
 func Run(gfxLoop func(w Window, d gfx.Device), p *Props) {
 	if gfxLoop == nil {
@@ -231,20 +303,10 @@ func Run(gfxLoop func(w Window, d gfx.Device), p *Props) {
 		// Create the window with the given properties.
 		w, d, err := New(p)
 		if err != nil {
-			log.Fatal(err)
+			log.New("window").Errorf("%v", err)
+			os.Exit(1)
 		}
-
-		// If the gfxLoop panics, we should still close the window (or else the
-		// user's screen resolution won't be restored, for example).
-		defer func() {
-			if r := recover(); r != nil {
-				w.Close()
-				panic(r)
-			}
-		}()
-
-		// Enter the graphics loop.
-		gfxLoop(w, d)
+		runLoop(gfxLoop, w, d)
 	}()
 
 	// Enter the main loop now.
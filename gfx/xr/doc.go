@@ -0,0 +1,27 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package xr provides virtual reality sessions for rendering to headsets.
+//
+// A Session drives the lifecycle of a VR session, provides render targets for
+// each eye sized as the runtime recommends, predicts the head and controller
+// poses for the time each frame will be displayed, and submits the rendered
+// eyes to the runtime.
+//
+// Sessions use a Backend which talks to the VR runtime. The Simulator backend
+// simulates a headset, which is useful for developing without VR hardware. The
+// OpenXR backend uses the system's OpenXR runtime, it is only available when
+// building with the "openxr" build tag (currently on Linux only):
+//
+//  go build -tags openxr
+//
+// Submission requires the graphics device to implement gfx.NativeInterop,
+// which the OpenGL devices of the window package do.
+//
+// Coordinate System
+//
+// Poses are converted from the runtime's coordinate system into the engine's
+// (right-handed, Z up) coordinate system, and can be applied directly to a
+// gfx.Transform (see Pose.Apply) or a camera (see View.Apply).
+package xr // import "azul3d.org/engine/gfx/xr"
@@ -0,0 +1,483 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+// +build openxr,linux
+
+package xr
+
+/*
+#cgo LDFLAGS: -lopenxr_loader -lGL -lX11
+#cgo CFLAGS: -DXR_USE_PLATFORM_XLIB -DXR_USE_GRAPHICS_API_OPENGL
+
+#include <stdlib.h>
+#include <string.h>
+#include <X11/Xlib.h>
+#include <GL/glx.h>
+#include <openxr/openxr.h>
+#include <openxr/openxr_platform.h>
+
+#define XRGO_MAX_IMAGES 8
+
+typedef struct {
+	XrInstance instance;
+	XrSystemId system;
+	XrSession session;
+	XrSpace space;
+	XrActionSet actionSet;
+	XrAction gripAction;
+	XrPath handPaths[2];
+	XrSpace handSpaces[2];
+	XrSwapchain swapchains[2];
+	uint32_t images[2][XRGO_MAX_IMAGES];
+	uint32_t imageCount[2];
+	uint32_t width, height;
+	XrSessionState state;
+} xrgo;
+
+// xrgo_create creates the instance and finds the headset.
+static XrResult xrgo_create(xrgo* x, const char* appName) {
+	const char* extensions[] = {XR_KHR_OPENGL_ENABLE_EXTENSION_NAME};
+	XrInstanceCreateInfo ici = {XR_TYPE_INSTANCE_CREATE_INFO};
+	strncpy(ici.applicationInfo.applicationName, appName, XR_MAX_APPLICATION_NAME_SIZE-1);
+	strncpy(ici.applicationInfo.engineName, "Azul3D", XR_MAX_ENGINE_NAME_SIZE-1);
+	ici.applicationInfo.apiVersion = XR_CURRENT_API_VERSION;
+	ici.enabledExtensionCount = 1;
+	ici.enabledExtensionNames = extensions;
+	XrResult r = xrCreateInstance(&ici, &x->instance);
+	if (XR_FAILED(r)) return r;
+
+	XrSystemGetInfo sgi = {XR_TYPE_SYSTEM_GET_INFO};
+	sgi.formFactor = XR_FORM_FACTOR_HEAD_MOUNTED_DISPLAY;
+	r = xrGetSystem(x->instance, &sgi, &x->system);
+	if (XR_FAILED(r)) return r;
+
+	XrViewConfigurationView views[2] = {{XR_TYPE_VIEW_CONFIGURATION_VIEW}, {XR_TYPE_VIEW_CONFIGURATION_VIEW}};
+	uint32_t n;
+	r = xrEnumerateViewConfigurationViews(x->instance, x->system, XR_VIEW_CONFIGURATION_TYPE_PRIMARY_STEREO, 2, &n, views);
+	if (XR_FAILED(r)) return r;
+	x->width = views[0].recommendedImageRectWidth;
+	x->height = views[0].recommendedImageRectHeight;
+	return XR_SUCCESS;
+}
+
+// xrgo_create_session creates the session using the current GLX context, so
+// it must be called with the device's context current.
+static XrResult xrgo_create_session(xrgo* x) {
+	// The runtime requires that the graphics requirements are queried.
+	PFN_xrGetOpenGLGraphicsRequirementsKHR getReqs;
+	XrResult r = xrGetInstanceProcAddr(x->instance, "xrGetOpenGLGraphicsRequirementsKHR", (PFN_xrVoidFunction*)&getReqs);
+	if (XR_FAILED(r)) return r;
+	XrGraphicsRequirementsOpenGLKHR reqs = {XR_TYPE_GRAPHICS_REQUIREMENTS_OPENGL_KHR};
+	r = getReqs(x->instance, x->system, &reqs);
+	if (XR_FAILED(r)) return r;
+
+	XrGraphicsBindingOpenGLXlibKHR binding = {XR_TYPE_GRAPHICS_BINDING_OPENGL_XLIB_KHR};
+	binding.xDisplay = glXGetCurrentDisplay();
+	binding.glxDrawable = glXGetCurrentDrawable();
+	binding.glxContext = glXGetCurrentContext();
+	XrSessionCreateInfo sci = {XR_TYPE_SESSION_CREATE_INFO};
+	sci.next = &binding;
+	sci.systemId = x->system;
+	r = xrCreateSession(x->instance, &sci, &x->session);
+	if (XR_FAILED(r)) return r;
+
+	XrReferenceSpaceCreateInfo rsci = {XR_TYPE_REFERENCE_SPACE_CREATE_INFO};
+	rsci.referenceSpaceType = XR_REFERENCE_SPACE_TYPE_STAGE;
+	rsci.poseInReferenceSpace.orientation.w = 1;
+	r = xrCreateReferenceSpace(x->session, &rsci, &x->space);
+	if (XR_FAILED(r)) return r;
+
+	// Create the swapchain of each eye.
+	for (int eye = 0; eye < 2; eye++) {
+		XrSwapchainCreateInfo swci = {XR_TYPE_SWAPCHAIN_CREATE_INFO};
+		swci.usageFlags = XR_SWAPCHAIN_USAGE_COLOR_ATTACHMENT_BIT | XR_SWAPCHAIN_USAGE_TRANSFER_DST_BIT;
+		swci.format = GL_RGBA8;
+		swci.sampleCount = 1;
+		swci.width = x->width;
+		swci.height = x->height;
+		swci.faceCount = 1;
+		swci.arraySize = 1;
+		swci.mipCount = 1;
+		r = xrCreateSwapchain(x->session, &swci, &x->swapchains[eye]);
+		if (XR_FAILED(r)) return r;
+
+		XrSwapchainImageOpenGLKHR images[XRGO_MAX_IMAGES];
+		for (int i = 0; i < XRGO_MAX_IMAGES; i++) {
+			images[i].type = XR_TYPE_SWAPCHAIN_IMAGE_OPENGL_KHR;
+			images[i].next = NULL;
+		}
+		r = xrEnumerateSwapchainImages(x->swapchains[eye], XRGO_MAX_IMAGES, &x->imageCount[eye], (XrSwapchainImageBaseHeader*)images);
+		if (XR_FAILED(r)) return r;
+		for (uint32_t i = 0; i < x->imageCount[eye]; i++) {
+			x->images[eye][i] = images[i].image;
+		}
+	}
+	return XR_SUCCESS;
+}
+
+// xrgo_create_actions creates the controller grip pose actions, using the
+// simple controller profile which every runtime supports.
+static XrResult xrgo_create_actions(xrgo* x) {
+	XrActionSetCreateInfo asci = {XR_TYPE_ACTION_SET_CREATE_INFO};
+	strcpy(asci.actionSetName, "azul3d");
+	strcpy(asci.localizedActionSetName, "Azul3D");
+	XrResult r = xrCreateActionSet(x->instance, &asci, &x->actionSet);
+	if (XR_FAILED(r)) return r;
+
+	xrStringToPath(x->instance, "/user/hand/left", &x->handPaths[0]);
+	xrStringToPath(x->instance, "/user/hand/right", &x->handPaths[1]);
+	XrActionCreateInfo aci = {XR_TYPE_ACTION_CREATE_INFO};
+	aci.actionType = XR_ACTION_TYPE_POSE_INPUT;
+	strcpy(aci.actionName, "grip");
+	strcpy(aci.localizedActionName, "Grip");
+	aci.countSubactionPaths = 2;
+	aci.subactionPaths = x->handPaths;
+	r = xrCreateAction(x->actionSet, &aci, &x->gripAction);
+	if (XR_FAILED(r)) return r;
+
+	XrPath profile, grips[2];
+	xrStringToPath(x->instance, "/interaction_profiles/khr/simple_controller", &profile);
+	xrStringToPath(x->instance, "/user/hand/left/input/grip/pose", &grips[0]);
+	xrStringToPath(x->instance, "/user/hand/right/input/grip/pose", &grips[1]);
+	XrActionSuggestedBinding bindings[2] = {{x->gripAction, grips[0]}, {x->gripAction, grips[1]}};
+	XrInteractionProfileSuggestedBinding ipsb = {XR_TYPE_INTERACTION_PROFILE_SUGGESTED_BINDING};
+	ipsb.interactionProfile = profile;
+	ipsb.countSuggestedBindings = 2;
+	ipsb.suggestedBindings = bindings;
+	r = xrSuggestInteractionProfileBindings(x->instance, &ipsb);
+	if (XR_FAILED(r)) return r;
+
+	for (int h = 0; h < 2; h++) {
+		XrActionSpaceCreateInfo ascr = {XR_TYPE_ACTION_SPACE_CREATE_INFO};
+		ascr.action = x->gripAction;
+		ascr.subactionPath = x->handPaths[h];
+		ascr.poseInActionSpace.orientation.w = 1;
+		r = xrCreateActionSpace(x->session, &ascr, &x->handSpaces[h]);
+		if (XR_FAILED(r)) return r;
+	}
+
+	XrSessionActionSetsAttachInfo sasai = {XR_TYPE_SESSION_ACTION_SETS_ATTACH_INFO};
+	sasai.countActionSets = 1;
+	sasai.actionSets = &x->actionSet;
+	return xrAttachSessionActionSets(x->session, &sasai);
+}
+
+// xrgo_poll handles pending events, beginning and ending the session as the
+// runtime requests.
+static XrResult xrgo_poll(xrgo* x) {
+	for (;;) {
+		XrEventDataBuffer ev = {XR_TYPE_EVENT_DATA_BUFFER};
+		XrResult r = xrPollEvent(x->instance, &ev);
+		if (r == XR_EVENT_UNAVAILABLE) return XR_SUCCESS;
+		if (XR_FAILED(r)) return r;
+		if (ev.type != XR_TYPE_EVENT_DATA_SESSION_STATE_CHANGED) continue;
+
+		x->state = ((XrEventDataSessionStateChanged*)&ev)->state;
+		if (x->state == XR_SESSION_STATE_READY) {
+			XrSessionBeginInfo sbi = {XR_TYPE_SESSION_BEGIN_INFO};
+			sbi.primaryViewConfigurationType = XR_VIEW_CONFIGURATION_TYPE_PRIMARY_STEREO;
+			r = xrBeginSession(x->session, &sbi);
+		} else if (x->state == XR_SESSION_STATE_STOPPING) {
+			r = xrEndSession(x->session);
+		}
+		if (XR_FAILED(r)) return r;
+	}
+}
+
+// xrgo_wait_frame waits for and begins the next frame, and syncs the actions.
+static XrResult xrgo_wait_frame(xrgo* x, XrFrameState* fs) {
+	fs->type = XR_TYPE_FRAME_STATE;
+	fs->next = NULL;
+	XrFrameWaitInfo fwi = {XR_TYPE_FRAME_WAIT_INFO};
+	XrResult r = xrWaitFrame(x->session, &fwi, fs);
+	if (XR_FAILED(r)) return r;
+	XrFrameBeginInfo fbi = {XR_TYPE_FRAME_BEGIN_INFO};
+	r = xrBeginFrame(x->session, &fbi);
+	if (XR_FAILED(r)) return r;
+
+	XrActiveActionSet active = {x->actionSet, XR_NULL_PATH};
+	XrActionsSyncInfo asi = {XR_TYPE_ACTIONS_SYNC_INFO};
+	asi.countActiveActionSets = 1;
+	asi.activeActionSets = &active;
+	r = xrSyncActions(x->session, &asi);
+	if (r == XR_SESSION_NOT_FOCUSED) return XR_SUCCESS;
+	return r;
+}
+
+// xrgo_locate_views locates both eyes at the given time.
+static XrResult xrgo_locate_views(xrgo* x, XrTime t, XrView views[2]) {
+	XrViewLocateInfo vli = {XR_TYPE_VIEW_LOCATE_INFO};
+	vli.viewConfigurationType = XR_VIEW_CONFIGURATION_TYPE_PRIMARY_STEREO;
+	vli.displayTime = t;
+	vli.space = x->space;
+	XrViewState vs = {XR_TYPE_VIEW_STATE};
+	views[0].type = views[1].type = XR_TYPE_VIEW;
+	views[0].next = views[1].next = NULL;
+	uint32_t n;
+	return xrLocateViews(x->session, &vli, &vs, 2, &n, views);
+}
+
+// xrgo_locate_hand locates the given hand's controller at the given time.
+static XrResult xrgo_locate_hand(xrgo* x, int h, XrTime t, XrPosef* pose, int* tracked) {
+	XrSpaceLocation loc = {XR_TYPE_SPACE_LOCATION};
+	XrResult r = xrLocateSpace(x->handSpaces[h], x->space, t, &loc);
+	if (XR_FAILED(r)) return r;
+	*pose = loc.pose;
+	*tracked = (loc.locationFlags & XR_SPACE_LOCATION_POSITION_TRACKED_BIT) &&
+		(loc.locationFlags & XR_SPACE_LOCATION_ORIENTATION_TRACKED_BIT);
+	return XR_SUCCESS;
+}
+
+// xrgo_acquire acquires and waits for the next image of the eye's swapchain.
+static XrResult xrgo_acquire(xrgo* x, int eye, uint32_t* image) {
+	XrSwapchainImageAcquireInfo ai = {XR_TYPE_SWAPCHAIN_IMAGE_ACQUIRE_INFO};
+	uint32_t index;
+	XrResult r = xrAcquireSwapchainImage(x->swapchains[eye], &ai, &index);
+	if (XR_FAILED(r)) return r;
+	XrSwapchainImageWaitInfo wi = {XR_TYPE_SWAPCHAIN_IMAGE_WAIT_INFO};
+	wi.timeout = XR_INFINITE_DURATION;
+	r = xrWaitSwapchainImage(x->swapchains[eye], &wi);
+	*image = x->images[eye][index];
+	return r;
+}
+
+// xrgo_release releases the acquired image of the eye's swapchain.
+static XrResult xrgo_release(xrgo* x, int eye) {
+	XrSwapchainImageReleaseInfo ri = {XR_TYPE_SWAPCHAIN_IMAGE_RELEASE_INFO};
+	return xrReleaseSwapchainImage(x->swapchains[eye], &ri);
+}
+
+// xrgo_end_frame ends the frame, with a projection layer of the given views if
+// render is non-zero.
+static XrResult xrgo_end_frame(xrgo* x, XrTime t, XrView views[2], int render) {
+	XrCompositionLayerProjectionView pv[2];
+	XrCompositionLayerProjection layer = {XR_TYPE_COMPOSITION_LAYER_PROJECTION};
+	const XrCompositionLayerBaseHeader* layers[1] = {(XrCompositionLayerBaseHeader*)&layer};
+	for (int eye = 0; eye < 2; eye++) {
+		memset(&pv[eye], 0, sizeof(pv[eye]));
+		pv[eye].type = XR_TYPE_COMPOSITION_LAYER_PROJECTION_VIEW;
+		pv[eye].pose = views[eye].pose;
+		pv[eye].fov = views[eye].fov;
+		pv[eye].subImage.swapchain = x->swapchains[eye];
+		pv[eye].subImage.imageRect.extent.width = x->width;
+		pv[eye].subImage.imageRect.extent.height = x->height;
+	}
+	layer.space = x->space;
+	layer.viewCount = 2;
+	layer.views = pv;
+
+	XrFrameEndInfo fei = {XR_TYPE_FRAME_END_INFO};
+	fei.displayTime = t;
+	fei.environmentBlendMode = XR_ENVIRONMENT_BLEND_MODE_OPAQUE;
+	if (render) {
+		fei.layerCount = 1;
+		fei.layers = layers;
+	}
+	return xrEndFrame(x->session, &fei);
+}
+
+// xrgo_destroy destroys the session and instance.
+static void xrgo_destroy(xrgo* x) {
+	if (x->session != XR_NULL_HANDLE) xrDestroySession(x->session);
+	if (x->instance != XR_NULL_HANDLE) xrDestroyInstance(x->instance);
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"time"
+	"unsafe"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/lmath"
+)
+
+// OpenXRError is an error returned by the OpenXR runtime.
+type OpenXRError struct {
+	Func   string // The failed function, e.g. "xrCreateInstance".
+	Result int32  // The XrResult code.
+}
+
+// Error implements the error interface.
+func (e *OpenXRError) Error() string {
+	return fmt.Sprintf("xr: %s failed: XrResult(%d)", e.Func, e.Result)
+}
+
+// check returns an error for the given result, or nil if it is a success.
+func check(fn string, r C.XrResult) error {
+	if r < 0 {
+		return &OpenXRError{Func: fn, Result: int32(r)}
+	}
+	return nil
+}
+
+// OpenXR is a Backend using the system's OpenXR runtime, via the
+// XR_KHR_opengl_enable extension. It is only available when building with the
+// "openxr" build tag on Linux (using GLX), and requires the OpenXR loader
+// library.
+type OpenXR struct {
+	x     *C.xrgo // C-allocated, as it contains handles passed back to C.
+	views [2]C.XrView
+}
+
+// convertVec3 converts a OpenXR (Y up) vector into the engine's coordinate
+// system (Z up).
+func convertVec3(v C.XrVector3f) lmath.Vec3 {
+	return lmath.Vec3{X: float64(v.x), Y: -float64(v.z), Z: float64(v.y)}
+}
+
+// convertPose converts a OpenXR pose into the engine's coordinate system.
+func convertPose(p C.XrPosef) Pose {
+	o := p.orientation
+	return Pose{
+		Position: convertVec3(p.position),
+		Orientation: lmath.Quat{
+			W: float64(o.w),
+			X: float64(o.x),
+			Y: -float64(o.z),
+			Z: float64(o.y),
+		},
+	}
+}
+
+// RecommendedSize implements the Backend interface.
+func (o *OpenXR) RecommendedSize() (width, height int) {
+	return int(o.x.width), int(o.x.height)
+}
+
+// PollState implements the Backend interface.
+func (o *OpenXR) PollState() (SessionState, error) {
+	if err := check("xrPollEvent", C.xrgo_poll(o.x)); err != nil {
+		return InvalidState, err
+	}
+	switch o.x.state {
+	case C.XR_SESSION_STATE_IDLE:
+		return Idle, nil
+	case C.XR_SESSION_STATE_READY:
+		return Ready, nil
+	case C.XR_SESSION_STATE_SYNCHRONIZED:
+		return Synchronized, nil
+	case C.XR_SESSION_STATE_VISIBLE:
+		return Visible, nil
+	case C.XR_SESSION_STATE_FOCUSED:
+		return Focused, nil
+	case C.XR_SESSION_STATE_STOPPING:
+		return Stopping, nil
+	case C.XR_SESSION_STATE_LOSS_PENDING:
+		return LossPending, nil
+	case C.XR_SESSION_STATE_EXITING:
+		return Exiting, nil
+	}
+	return InvalidState, nil
+}
+
+// WaitFrame implements the Backend interface.
+func (o *OpenXR) WaitFrame() (FrameState, error) {
+	var fs C.XrFrameState
+	if err := check("xrWaitFrame", C.xrgo_wait_frame(o.x, &fs)); err != nil {
+		return FrameState{}, err
+	}
+	return FrameState{
+		DisplayTime:  time.Duration(fs.predictedDisplayTime),
+		Period:       time.Duration(fs.predictedDisplayPeriod),
+		ShouldRender: fs.shouldRender != 0,
+	}, nil
+}
+
+// LocateViews implements the Backend interface.
+func (o *OpenXR) LocateViews(t time.Duration) ([2]View, error) {
+	var views [2]View
+	r := C.xrgo_locate_views(o.x, C.XrTime(t), &o.views[0])
+	if err := check("xrLocateViews", r); err != nil {
+		return views, err
+	}
+	for eye, v := range o.views {
+		views[eye].Pose = convertPose(v.pose)
+		views[eye].FOV = FOV{
+			Left:  float64(v.fov.angleLeft),
+			Right: float64(v.fov.angleRight),
+			Up:    float64(v.fov.angleUp),
+			Down:  float64(v.fov.angleDown),
+		}
+	}
+	return views, nil
+}
+
+// LocateHand implements the Backend interface.
+func (o *OpenXR) LocateHand(h Hand, t time.Duration) (Pose, bool, error) {
+	var (
+		pose    C.XrPosef
+		tracked C.int
+	)
+	r := C.xrgo_locate_hand(o.x, C.int(h), C.XrTime(t), &pose, &tracked)
+	if err := check("xrLocateSpace", r); err != nil {
+		return Pose{}, false, err
+	}
+	return convertPose(pose), tracked != 0, nil
+}
+
+// EndFrame implements the Backend interface. The eye textures are copied into
+// the runtime's swapchain images using the native interop.
+func (o *OpenXR) EndFrame(f *Frame, eyes [2]*gfx.Texture, n gfx.NativeInterop) error {
+	render := eyes[LeftEye] != nil && eyes[RightEye] != nil
+	var err error
+	if render {
+		n.RunNative(func() {
+			for eye, tex := range eyes {
+				var image C.uint32_t
+				if err = check("xrAcquireSwapchainImage", C.xrgo_acquire(o.x, C.int(eye), &image)); err != nil {
+					return
+				}
+				n.CopyToNative(tex, uint32(image))
+				if err = check("xrReleaseSwapchainImage", C.xrgo_release(o.x, C.int(eye))); err != nil {
+					return
+				}
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+	var r C.int
+	if render {
+		r = 1
+	}
+	return check("xrEndFrame", C.xrgo_end_frame(o.x, C.XrTime(f.DisplayTime), &o.views[0], r))
+}
+
+// Destroy implements the Backend interface.
+func (o *OpenXR) Destroy() {
+	C.xrgo_destroy(o.x)
+	C.free(unsafe.Pointer(o.x))
+}
+
+// NewOpenXR creates a OpenXR instance and session for the first headset found,
+// with the given application name.
+//
+// The session is created in the OpenGL context of the given device (which must
+// be the device later passed to NewSession).
+func NewOpenXR(appName string, n gfx.NativeInterop) (*OpenXR, error) {
+	o := &OpenXR{
+		x: (*C.xrgo)(C.calloc(1, C.size_t(unsafe.Sizeof(C.xrgo{})))),
+	}
+	cName := C.CString(appName)
+	defer C.free(unsafe.Pointer(cName))
+	if err := check("xrCreateInstance", C.xrgo_create(o.x, cName)); err != nil {
+		o.Destroy()
+		return nil, err
+	}
+
+	var err error
+	n.RunNative(func() {
+		err = check("xrCreateSession", C.xrgo_create_session(o.x))
+	})
+	if err == nil {
+		err = check("xrCreateActionSet", C.xrgo_create_actions(o.x))
+	}
+	if err != nil {
+		o.Destroy()
+		return nil, err
+	}
+	return o, nil
+}
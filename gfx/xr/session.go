@@ -0,0 +1,193 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xr
+
+import (
+	"errors"
+	"image"
+	"time"
+
+	"azul3d.org/engine/gfx"
+)
+
+// ErrNoInterop is returned by NewSession when the graphics device does not
+// implement the gfx.NativeInterop interface, which is required to submit
+// frames to the runtime.
+var ErrNoInterop = errors.New("xr: graphics device does not support native interop")
+
+// ErrNoRTT is returned by NewSession when the graphics device does not
+// support render-to-texture.
+var ErrNoRTT = errors.New("xr: graphics device does not support render-to-texture")
+
+// ErrNotRunning is returned by Session.BeginFrame when the session is not in a
+// running state (see SessionState.Running).
+var ErrNotRunning = errors.New("xr: session is not running")
+
+// Backend is a VR runtime, for instance OpenXR (see NewOpenXR) or a Simulator.
+//
+// Applications use a Session instead of calling these methods directly, which
+// are only ever called from the goroutine using the session.
+type Backend interface {
+	// RecommendedSize returns the recommended size of the render target of
+	// each eye, in pixels.
+	RecommendedSize() (width, height int)
+
+	// PollState handles any pending runtime events and returns the current
+	// state of the session.
+	PollState() (SessionState, error)
+
+	// WaitFrame waits until the runtime wants the next frame, and begins it.
+	WaitFrame() (FrameState, error)
+
+	// LocateViews returns the views of each eye at the given display time.
+	LocateViews(t time.Duration) ([2]View, error)
+
+	// LocateHand returns the pose of the given hand's controller at the given
+	// display time, and whether or not it is tracked.
+	LocateHand(h Hand, t time.Duration) (Pose, bool, error)
+
+	// EndFrame ends the frame, submitting the given eye textures (which are
+	// nil if the frame should not be rendered) via the native interop.
+	EndFrame(f *Frame, eyes [2]*gfx.Texture, n gfx.NativeInterop) error
+
+	// Destroy destroys the backend, ending the session.
+	Destroy()
+}
+
+// Session is a VR session. Each frame the application renders each eye into
+// the session's canvases and submits them:
+//
+//  for {
+//      state, err := s.Update()
+//      ... handle err, exit if state == xr.Exiting ...
+//      if !state.Running() {
+//          ... render to the window instead ...
+//          continue
+//      }
+//      f, err := s.BeginFrame()
+//      ... handle err ...
+//      if f.ShouldRender {
+//          for eye := xr.LeftEye; eye <= xr.RightEye; eye++ {
+//              f.Views[eye].Apply(cam)
+//              canvas := s.Canvas(eye)
+//              canvas.Clear(canvas.Bounds(), gfx.Color{0, 0, 0, 1})
+//              canvas.Draw(canvas.Bounds(), scene, cam)
+//              canvas.Render()
+//          }
+//      }
+//      err = s.EndFrame(f)
+//      ... handle err ...
+//  }
+//
+// A session is not safe for use concurrently from multiple goroutines.
+type Session struct {
+	backend  Backend
+	native   gfx.NativeInterop
+	state    SessionState
+	eyes     [2]*gfx.Texture
+	canvases [2]gfx.Canvas
+}
+
+// State returns the state of the session, as of the last call to Update.
+func (s *Session) State() SessionState {
+	return s.state
+}
+
+// Update handles runtime events, returning the current state of the session.
+// It should be called once per frame.
+func (s *Session) Update() (SessionState, error) {
+	state, err := s.backend.PollState()
+	if err != nil {
+		return s.state, err
+	}
+	s.state = state
+	return state, nil
+}
+
+// Canvas returns the render-to-texture canvas of the given eye, which is
+// sized as recommended by the runtime.
+func (s *Session) Canvas(eye Eye) gfx.Canvas {
+	return s.canvases[eye]
+}
+
+// Texture returns the color texture of the given eye's canvas, e.g. to mirror
+// it to the window.
+func (s *Session) Texture(eye Eye) *gfx.Texture {
+	return s.eyes[eye]
+}
+
+// BeginFrame waits until the runtime wants the next frame and returns it,
+// with the views and controller poses predicted for the time it will be
+// displayed. ErrNotRunning is returned if the session is not running.
+func (s *Session) BeginFrame() (*Frame, error) {
+	if !s.state.Running() {
+		return nil, ErrNotRunning
+	}
+	fs, err := s.backend.WaitFrame()
+	if err != nil {
+		return nil, err
+	}
+	f := &Frame{FrameState: fs}
+	f.Views, err = s.backend.LocateViews(fs.DisplayTime)
+	if err != nil {
+		return nil, err
+	}
+	for h := LeftHand; h <= RightHand; h++ {
+		f.Hands[h], f.Tracked[h], err = s.backend.LocateHand(h, fs.DisplayTime)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// EndFrame submits the frame returned by BeginFrame, with the contents of the
+// eye canvases if the frame should be rendered. Every frame returned by
+// BeginFrame must be ended.
+func (s *Session) EndFrame(f *Frame) error {
+	var eyes [2]*gfx.Texture
+	if f.ShouldRender {
+		eyes = s.eyes
+	}
+	return s.backend.EndFrame(f, eyes, s.native)
+}
+
+// Destroy destroys the session and it's backend.
+func (s *Session) Destroy() {
+	s.backend.Destroy()
+	for _, t := range s.eyes {
+		t.Destroy()
+	}
+}
+
+// NewSession returns a new session using the given backend, rendering with
+// the given graphics device. The device must implement the gfx.NativeInterop
+// interface (devices returned by the window package do), or ErrNoInterop is
+// returned. If render-to-texture is not supported, ErrNoRTT is returned.
+func NewSession(b Backend, d gfx.Device) (*Session, error) {
+	native, ok := d.(gfx.NativeInterop)
+	if !ok {
+		return nil, ErrNoInterop
+	}
+	s := &Session{
+		backend: b,
+		native:  native,
+		state:   Idle,
+	}
+
+	// Create each eye's canvas.
+	width, height := b.RecommendedSize()
+	cfg := d.Info().RTTFormats.ChooseConfig(d.Precision(), false)
+	cfg.Bounds = image.Rect(0, 0, width, height)
+	for eye := range s.eyes {
+		s.eyes[eye] = gfx.NewTexture()
+		cfg.Color = s.eyes[eye]
+		s.canvases[eye] = d.RenderToTexture(cfg)
+		if s.canvases[eye] == nil {
+			return nil, ErrNoRTT
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,180 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xr
+
+import (
+	"sync"
+	"time"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/lmath"
+)
+
+// Simulator is a Backend which simulates a headset, for developing and testing
+// VR applications without VR hardware. The head and controller poses can be
+// changed at any time, for instance driven by the mouse and keyboard.
+//
+// The exported fields must not be modified once a session is created, the
+// methods are safe for use concurrently from multiple goroutines.
+type Simulator struct {
+	// The size of the render target of each eye, in pixels.
+	Width, Height int
+
+	// The distance between the eyes, in meters.
+	IPD float64
+
+	// The field of view of each eye.
+	FOV FOV
+
+	// The display refresh rate, in frames per second.
+	RefreshRate float64
+
+	access    sync.RWMutex
+	state     SessionState
+	exit      bool
+	head      Pose
+	hands     [2]Pose
+	tracked   [2]bool
+	start     time.Time
+	frames    uint64
+	submitted uint64
+}
+
+// SetHead sets the pose of the simulated head.
+func (s *Simulator) SetHead(p Pose) {
+	s.access.Lock()
+	s.head = p
+	s.access.Unlock()
+}
+
+// SetHand sets the pose of the simulated controller of the given hand, and
+// whether or not it is tracked.
+func (s *Simulator) SetHand(h Hand, p Pose, tracked bool) {
+	s.access.Lock()
+	s.hands[h] = p
+	s.tracked[h] = tracked
+	s.access.Unlock()
+}
+
+// Exit simulates the runtime requesting that the application exit (e.g. the
+// user quit from a system menu).
+func (s *Simulator) Exit() {
+	s.access.Lock()
+	s.exit = true
+	s.access.Unlock()
+}
+
+// Submitted returns the number of frames that have been submitted with
+// content.
+func (s *Simulator) Submitted() uint64 {
+	s.access.RLock()
+	defer s.access.RUnlock()
+	return s.submitted
+}
+
+// RecommendedSize implements the Backend interface.
+func (s *Simulator) RecommendedSize() (width, height int) {
+	return s.Width, s.Height
+}
+
+// PollState implements the Backend interface. Like a real runtime, the
+// simulated session moves through one state at a time, from Idle to Focused
+// (or from Stopping to Exiting).
+func (s *Simulator) PollState() (SessionState, error) {
+	s.access.Lock()
+	defer s.access.Unlock()
+	switch {
+	case s.exit && s.state < Stopping:
+		s.state = Stopping
+	case s.state == Stopping:
+		s.state = Exiting
+	case s.state < Focused:
+		s.state++
+		if s.state == Synchronized {
+			s.start = time.Now()
+		}
+	}
+	return s.state, nil
+}
+
+// period returns the duration between frames.
+func (s *Simulator) period() time.Duration {
+	return time.Duration(float64(time.Second) / s.RefreshRate)
+}
+
+// WaitFrame implements the Backend interface.
+func (s *Simulator) WaitFrame() (FrameState, error) {
+	s.access.Lock()
+	period := s.period()
+	next := time.Duration(s.frames) * period
+	s.frames++
+	visible := s.state == Visible || s.state == Focused
+	s.access.Unlock()
+
+	if wait := next - time.Since(s.start); wait > 0 {
+		time.Sleep(wait)
+	}
+	return FrameState{
+		DisplayTime:  next + period,
+		Period:       period,
+		ShouldRender: visible,
+	}, nil
+}
+
+// LocateViews implements the Backend interface.
+func (s *Simulator) LocateViews(t time.Duration) ([2]View, error) {
+	s.access.RLock()
+	head := s.head
+	s.access.RUnlock()
+
+	// Offset each eye along the head's right vector.
+	right := head.Orientation.TransformVec3(lmath.Vec3{X: 1})
+	offset := right.MulScalar(s.IPD / 2)
+	var views [2]View
+	for eye := range views {
+		views[eye].Pose = head
+		views[eye].FOV = s.FOV
+	}
+	views[LeftEye].Position = head.Position.Sub(offset)
+	views[RightEye].Position = head.Position.Add(offset)
+	return views, nil
+}
+
+// LocateHand implements the Backend interface.
+func (s *Simulator) LocateHand(h Hand, t time.Duration) (Pose, bool, error) {
+	s.access.RLock()
+	defer s.access.RUnlock()
+	return s.hands[h], s.tracked[h], nil
+}
+
+// EndFrame implements the Backend interface. The submitted eye textures are
+// not used, they can be mirrored to a window using Session.Texture instead.
+func (s *Simulator) EndFrame(f *Frame, eyes [2]*gfx.Texture, n gfx.NativeInterop) error {
+	if eyes[LeftEye] != nil {
+		s.access.Lock()
+		s.submitted++
+		s.access.Unlock()
+	}
+	return nil
+}
+
+// Destroy implements the Backend interface.
+func (s *Simulator) Destroy() {}
+
+// NewSimulator returns a new simulated headset with 1080x1200 pixels per eye
+// refreshing at 90hz, an IPD of 64mm, and a field of view of 100 degrees. The
+// head is at the origin, 1.7 meters high, and the controllers are untracked.
+func NewSimulator() *Simulator {
+	half := lmath.Radians(50)
+	return &Simulator{
+		Width:       1080,
+		Height:      1200,
+		IPD:         0.064,
+		FOV:         FOV{Left: -half, Right: half, Up: half, Down: -half},
+		RefreshRate: 90,
+		state:       Idle,
+		head:        Pose{Position: lmath.Vec3{Z: 1.7}, Orientation: lmath.QuatIdentity},
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xr
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/camera"
+	"azul3d.org/engine/lmath"
+)
+
+// Eye is a single eye of the headset.
+type Eye uint8
+
+// Eye constants.
+const (
+	LeftEye Eye = iota
+	RightEye
+)
+
+// Hand is a single hand (i.e. controller) of the user.
+type Hand uint8
+
+// Hand constants.
+const (
+	LeftHand Hand = iota
+	RightHand
+)
+
+// SessionState is the state of a session, as driven by the runtime.
+type SessionState uint8
+
+// Session state constants. The InvalidState is declared to help users detect
+// uninitialized variables.
+const (
+	InvalidState SessionState = iota
+
+	// Idle is the initial state, the session is waiting for the runtime.
+	Idle
+
+	// Ready means the runtime wants the application to begin rendering.
+	Ready
+
+	// Synchronized means frames are synchronized with the runtime, but are not
+	// visible to the user.
+	Synchronized
+
+	// Visible means frames are visible to the user, but input is not
+	// available (e.g. a system menu is in front).
+	Visible
+
+	// Focused means frames are visible and input is available.
+	Focused
+
+	// Stopping means the runtime wants the application to stop rendering.
+	Stopping
+
+	// LossPending means the session is about to be lost (e.g. the headset was
+	// disconnected), and should be destroyed.
+	LossPending
+
+	// Exiting means the runtime wants the application to exit.
+	Exiting
+)
+
+// Running tells whether frames should be submitted in this state.
+func (s SessionState) Running() bool {
+	return s == Synchronized || s == Visible || s == Focused
+}
+
+// Pose is a position and orientation in the (Z up, right-handed) coordinate
+// system of the engine, relative to the user's tracking space.
+type Pose struct {
+	Position    lmath.Vec3
+	Orientation lmath.Quat
+}
+
+// Mat4 returns the pose as a transformation matrix.
+func (p Pose) Mat4() lmath.Mat4 {
+	hpr := p.Orientation.Hpr(lmath.CoordSysZUpRight)
+	rot := lmath.Mat3Compose(lmath.Vec3One, lmath.Vec3Zero, hpr, lmath.CoordSysZUpRight)
+	return lmath.Mat4Identity.SetUpperMat3(rot).SetTranslation(p.Position)
+}
+
+// Apply sets the position and orientation of the given transform to the pose.
+func (p Pose) Apply(t *gfx.Transform) {
+	t.SetPos(p.Position)
+	t.SetQuat(p.Orientation)
+}
+
+// String returns a string representation of this pose.
+func (p Pose) String() string {
+	return fmt.Sprintf("Pose(Position=%v, Orientation=%v)", p.Position, p.Orientation)
+}
+
+// FOV is an asymmetric field of view, as angles in radians of each side of
+// the view from the forward direction (Left and Down are typically negative).
+type FOV struct {
+	Left, Right, Up, Down float64
+}
+
+// Projection returns the projection matrix for the field of view.
+func (f FOV) Projection(near, far float64) gfx.Mat4 {
+	m := lmath.Mat4FromFrustum(
+		math.Tan(f.Left)*near,
+		math.Tan(f.Right)*near,
+		math.Tan(f.Down)*near,
+		math.Tan(f.Up)*near,
+		near, far,
+	)
+	return gfx.ConvertMat4(m)
+}
+
+// View is the pose and field of view of a single eye.
+type View struct {
+	Pose
+	FOV FOV
+}
+
+// Apply updates the given camera to render the view: it's transform is set to
+// the pose of the eye and it's projection to the field of view (using the
+// camera's Near and Far values).
+//
+// The camera is expected to have no parent (or a parent which places the
+// tracking space in the world).
+func (v View) Apply(c *camera.Camera) {
+	v.Pose.Apply(c.Transform())
+	c.P = v.FOV.Projection(c.Near, c.Far)
+}
+
+// FrameState describes the timing of a frame, as predicted by the runtime.
+type FrameState struct {
+	// The predicted time at which the frame will be displayed, in the
+	// runtime's clock, and the predicted duration between frames.
+	DisplayTime, Period time.Duration
+
+	// Whether or not the frame should be rendered (it is submitted
+	// regardless, but without any content if false).
+	ShouldRender bool
+}
+
+// Frame is a single frame of a session, returned by Session.BeginFrame.
+type Frame struct {
+	FrameState
+
+	// The views of each eye at the predicted display time.
+	Views [2]View
+
+	// The poses of each hand's controller at the predicted display time, and
+	// whether or not each controller is currently tracked.
+	Hands   [2]Pose
+	Tracked [2]bool
+}
@@ -0,0 +1,137 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xr
+
+import (
+	"math"
+	"testing"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/lmath"
+)
+
+// interopDevice is a nil device which supports render-to-texture and native
+// interop.
+type interopDevice struct {
+	gfx.Device
+	rtt []gfx.RTTConfig
+}
+
+func (d *interopDevice) RenderToTexture(cfg gfx.RTTConfig) gfx.Canvas {
+	d.rtt = append(d.rtt, cfg)
+	return gfx.Nil()
+}
+
+func (d *interopDevice) RunNative(f func())                             { f() }
+func (d *interopDevice) CopyToNative(src *gfx.Texture, dst uint32) bool { return true }
+
+func newTestSession(t *testing.T) (*Session, *Simulator, *interopDevice) {
+	sim := NewSimulator()
+	sim.RefreshRate = 1000
+	d := &interopDevice{Device: gfx.Nil()}
+	s, err := NewSession(sim, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, sim, d
+}
+
+func TestNewSession(t *testing.T) {
+	if _, err := NewSession(NewSimulator(), gfx.Nil()); err != ErrNoInterop {
+		t.Fatal("expected ErrNoInterop, got", err)
+	}
+
+	s, sim, d := newTestSession(t)
+	if len(d.rtt) != 2 {
+		t.Fatalf("expected 2 render targets, got %d", len(d.rtt))
+	}
+	for _, cfg := range d.rtt {
+		if cfg.Bounds.Dx() != sim.Width || cfg.Bounds.Dy() != sim.Height {
+			t.Fatal("render target not of the recommended size:", cfg.Bounds)
+		}
+	}
+	if s.Texture(LeftEye) == s.Texture(RightEye) {
+		t.Fatal("eyes share a texture")
+	}
+}
+
+func TestSessionLifecycle(t *testing.T) {
+	s, sim, _ := newTestSession(t)
+	if _, err := s.BeginFrame(); err != ErrNotRunning {
+		t.Fatal("expected ErrNotRunning, got", err)
+	}
+
+	want := []SessionState{Ready, Synchronized, Visible, Focused, Focused}
+	for _, w := range want {
+		state, err := s.Update()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if state != w {
+			t.Fatalf("got state %v, want %v", state, w)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		f, err := s.BeginFrame()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !f.ShouldRender {
+			t.Fatal("focused frame should render")
+		}
+		if err := s.EndFrame(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := sim.Submitted(); n != 3 {
+		t.Fatalf("got %d submitted frames, want 3", n)
+	}
+
+	sim.Exit()
+	for _, w := range []SessionState{Stopping, Exiting} {
+		if state, _ := s.Update(); state != w {
+			t.Fatalf("got state %v, want %v", state, w)
+		}
+	}
+	s.Destroy()
+}
+
+func TestSimulatorViews(t *testing.T) {
+	sim := NewSimulator()
+
+	// Face right (+X), the eyes are then offset along the Y axis.
+	q := lmath.QuatFromAxisAngle(lmath.Vec3{Z: 1}, -math.Pi/2)
+	sim.SetHead(Pose{Position: lmath.Vec3{Z: 1}, Orientation: q})
+	views, err := sim.LocateViews(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := views[RightEye].Position.Sub(views[LeftEye].Position)
+	if !lmath.AlmostEqual(d.Length(), sim.IPD, 1e-9) {
+		t.Fatal("eye distance is not the IPD:", d.Length())
+	}
+	if !lmath.AlmostEqual(d.Z, 0, 1e-9) {
+		t.Fatal("eyes are not level:", views)
+	}
+
+	sim.SetHand(RightHand, Pose{Position: lmath.Vec3{X: 1}}, true)
+	p, tracked, _ := sim.LocateHand(RightHand, 0)
+	if !tracked || p.Position.X != 1 {
+		t.Fatal("right hand not tracked")
+	}
+	if _, tracked, _ = sim.LocateHand(LeftHand, 0); tracked {
+		t.Fatal("left hand tracked")
+	}
+}
+
+func TestSessionStateString(t *testing.T) {
+	if s := LossPending.String(); s != "LossPending" {
+		t.Fatal(s)
+	}
+	if s := RightEye.String(); s != "RightEye" {
+		t.Fatal(s)
+	}
+}
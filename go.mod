@@ -3,7 +3,7 @@ module azul3d.org/engine
 go 1.14
 
 require (
-	github.com/go-gl/glfw v0.0.0-20200707082815-5321531c36a2
+	github.com/go-gl/glfw/v3.3/glfw v0.0.0-20200707082815-5321531c36a2
 	github.com/ianremmler/ode v0.0.0-20150825143819-b062ec7b88b9
 	github.com/mewkiz/flac v1.0.6
 	gopkg.in/qml.v1 v1.0.0-20150209141031-2ee7e5ff7370
@@ -0,0 +1,33 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package job implements a work stealing job scheduler.
+//
+// A Scheduler runs jobs (functions) on a pool of worker goroutines. Jobs may
+// depend on other jobs, forming a task graph, and are only run once all of
+// their dependencies are complete. Subsystems which have independent work to
+// do each frame (culling, particle updates, audio mixing, etc) submit it to a
+// Frame, which acts as a fence for all the jobs of the frame:
+//
+//  sched := job.New(0)
+//  defer sched.Close()
+//  for {
+//      f := sched.Frame()
+//      cull := f.Go("cull", cullObjects)
+//      f.For("particles", len(particles), 256, func(start, end int) {
+//          updateParticles(particles[start:end])
+//      })
+//      f.Go("sort", sortObjects, cull)
+//      f.Wait()
+//      ...
+//  }
+//
+// Goroutines which wait for jobs help to run queued jobs meanwhile, such that
+// jobs may themselves submit and wait for other jobs without deadlocking the
+// pool.
+//
+// The time spent running each job can be recorded by a Recorder, such as the
+// CPU profiler of the gfx/profile package, such that jobs appear in it's
+// reports and overlay.
+package job // import "azul3d.org/engine/job"
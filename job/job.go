@@ -0,0 +1,105 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package job
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Job is a single job submitted to a scheduler.
+type Job struct {
+	s     *Scheduler
+	name  string
+	fn    func()
+	frame *Frame
+
+	// The number of dependencies which are not yet complete, plus one while
+	// the job is being submitted.
+	pending int32
+
+	access     sync.Mutex
+	complete   bool
+	dependents []*Job
+	done       chan struct{}
+}
+
+// Name returns the name of the job, as given when it was submitted.
+func (j *Job) Name() string {
+	return j.name
+}
+
+// Done tells if the job has completed.
+func (j *Job) Done() bool {
+	select {
+	case <-j.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Wait waits for the job to complete, running queued jobs of the scheduler
+// meanwhile.
+func (j *Job) Wait() {
+	j.s.help(j.Done)
+}
+
+// then adds dependent as a dependent of the job, unless it has already
+// completed.
+func (j *Job) then(dependent *Job) {
+	j.access.Lock()
+	if !j.complete {
+		j.dependents = append(j.dependents, dependent)
+		atomic.AddInt32(&dependent.pending, 1)
+	}
+	j.access.Unlock()
+}
+
+// finish marks the job as complete, returning the dependents it was holding
+// back.
+func (j *Job) finish() []*Job {
+	j.access.Lock()
+	j.complete = true
+	deps := j.dependents
+	j.dependents = nil
+	j.access.Unlock()
+	close(j.done)
+	return deps
+}
+
+// Frame is a frame-scoped group of jobs, which acts as a fence: Wait waits
+// for all of the jobs submitted to the frame.
+//
+// The methods of a frame may be called from multiple goroutines concurrently,
+// but jobs must not be submitted to it concurrently with (or after) Wait.
+type Frame struct {
+	s       *Scheduler
+	pending int32
+	jobs    int32
+}
+
+// Go submits a job to the frame, see Scheduler.Go.
+func (f *Frame) Go(name string, fn func(), deps ...*Job) *Job {
+	return f.s.submit(name, fn, f, deps)
+}
+
+// For submits a parallel-for to the frame, see Scheduler.For.
+func (f *Frame) For(name string, n, grain int, fn func(start, end int), deps ...*Job) *Job {
+	return f.s.parallelFor(name, n, grain, fn, f, deps)
+}
+
+// Len returns the number of jobs submitted to the frame.
+func (f *Frame) Len() int {
+	return int(atomic.LoadInt32(&f.jobs))
+}
+
+// Wait waits for all of the jobs submitted to the frame to complete, running
+// queued jobs of the scheduler meanwhile.
+func (f *Frame) Wait() {
+	f.s.help(func() bool {
+		return atomic.LoadInt32(&f.pending) == 0
+	})
+}
@@ -0,0 +1,103 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package job
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type recorder struct {
+	sync.Mutex
+	names map[string]int
+}
+
+func (r *recorder) Record(name string, start, end time.Time) {
+	r.Lock()
+	r.names[name]++
+	r.Unlock()
+}
+
+func TestScheduler(t *testing.T) {
+	s := New(4)
+	defer s.Close()
+	rec := &recorder{names: make(map[string]int)}
+	s.Recorder = rec
+
+	// Dependencies run before their dependents.
+	var order []string
+	var mu sync.Mutex
+	add := func(name string) func() {
+		return func() {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+		}
+	}
+	a := s.Go("a", add("a"))
+	b := s.Go("b", add("b"), a)
+	c := s.Go("c", add("c"), a, b)
+	c.Wait()
+	if !a.Done() || !b.Done() || len(order) != 3 || order[2] != "c" || order[0] != "a" {
+		t.Fatalf("order %v", order)
+	}
+
+	// Jobs may wait for jobs they submit.
+	var n int32
+	outer := s.Go("outer", func() {
+		var inner []*Job
+		for i := 0; i < 16; i++ {
+			inner = append(inner, s.Go("inner", func() { atomic.AddInt32(&n, 1) }))
+		}
+		for _, j := range inner {
+			j.Wait()
+		}
+	})
+	outer.Wait()
+	if n != 16 {
+		t.Fatalf("ran %d inner jobs, want 16", n)
+	}
+	if rec.names["inner"] != 16 || rec.names["a"] != 1 {
+		t.Fatalf("recorded %v", rec.names)
+	}
+	if st := s.Stats(); st.Workers != 4 || st.Jobs != 20 {
+		t.Fatalf("stats %+v", st)
+	}
+}
+
+func TestFrame(t *testing.T) {
+	s := New(3)
+	defer s.Close()
+
+	for frame := 0; frame < 10; frame++ {
+		f := s.Frame()
+		sums := make([]int64, 1000)
+		first := f.For("fill", len(sums), 0, func(start, end int) {
+			for i := start; i < end; i++ {
+				sums[i] = int64(i)
+			}
+		})
+		var total int64
+		f.For("sum", len(sums), 64, func(start, end int) {
+			var sum int64
+			for i := start; i < end; i++ {
+				sum += sums[i]
+			}
+			atomic.AddInt64(&total, sum)
+		}, first)
+		f.Wait()
+		if total != 999*1000/2 {
+			t.Fatalf("frame %d: total %d", frame, total)
+		}
+		if f.Len() < 16 {
+			t.Fatalf("frame %d: %d jobs", frame, f.Len())
+		}
+	}
+
+	// An empty parallel-for completes.
+	s.For("empty", 0, 1, func(start, end int) { t.Fatal("called") }).Wait()
+}
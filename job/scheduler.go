@@ -0,0 +1,300 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package job
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Recorder records the time spent running jobs. It's methods are called from
+// multiple goroutines concurrently. The CPU profiler of the gfx/profile
+// package is a Recorder.
+type Recorder interface {
+	Record(name string, start, end time.Time)
+}
+
+// Stats are statistics about the jobs run by a scheduler.
+type Stats struct {
+	// The number of worker goroutines.
+	Workers int
+
+	// The number of jobs which have completed.
+	Jobs uint64
+
+	// The number of jobs which were run by a goroutine other than the worker
+	// whose queue they were in: stolen by another worker, or run by a
+	// goroutine waiting for a job or frame.
+	Steals uint64
+}
+
+// queue is the job queue of a single worker. The worker takes jobs from the
+// back of it's queue, while others steal from the front.
+type queue struct {
+	sync.Mutex
+	jobs []*Job
+}
+
+func (q *queue) push(j *Job) {
+	q.Lock()
+	q.jobs = append(q.jobs, j)
+	q.Unlock()
+}
+
+func (q *queue) pop() *Job {
+	q.Lock()
+	defer q.Unlock()
+	n := len(q.jobs)
+	if n == 0 {
+		return nil
+	}
+	j := q.jobs[n-1]
+	q.jobs[n-1] = nil
+	q.jobs = q.jobs[:n-1]
+	return j
+}
+
+func (q *queue) steal() *Job {
+	q.Lock()
+	defer q.Unlock()
+	if len(q.jobs) == 0 {
+		return nil
+	}
+	j := q.jobs[0]
+	q.jobs[0] = nil
+	q.jobs = q.jobs[1:]
+	return j
+}
+
+// Scheduler is a work stealing job scheduler, running jobs on a fixed pool of
+// worker goroutines. It's methods are safe to call from multiple goroutines
+// concurrently.
+type Scheduler struct {
+	// The recorder of the time spent running jobs, or nil. It may only be
+	// set before jobs are submitted.
+	Recorder Recorder
+
+	queues []*queue
+	next   uint32
+	jobs   uint64
+	steals uint64
+
+	// Guards queued and closed, and is the lock of wake.
+	access sync.Mutex
+	wake   *sync.Cond
+	queued int
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// Workers returns the number of worker goroutines of the scheduler.
+func (s *Scheduler) Workers() int {
+	return len(s.queues)
+}
+
+// Stats returns statistics about the jobs run by the scheduler.
+func (s *Scheduler) Stats() Stats {
+	return Stats{
+		Workers: len(s.queues),
+		Jobs:    atomic.LoadUint64(&s.jobs),
+		Steals:  atomic.LoadUint64(&s.steals),
+	}
+}
+
+// Go submits a job which runs fn once all of the given jobs (which may be
+// from any frame) have completed.
+func (s *Scheduler) Go(name string, fn func(), deps ...*Job) *Job {
+	return s.submit(name, fn, nil, deps)
+}
+
+// For submits a parallel-for: jobs which call fn for consecutive ranges
+// [start, end) of at most grain indices, covering [0, n). If grain <= 0 a
+// grain giving each worker about four ranges is chosen. The returned job
+// completes once all of the ranges have.
+func (s *Scheduler) For(name string, n, grain int, fn func(start, end int), deps ...*Job) *Job {
+	return s.parallelFor(name, n, grain, fn, nil, deps)
+}
+
+// Frame returns a new frame, whose Wait method acts as a fence for the jobs
+// submitted to it.
+func (s *Scheduler) Frame() *Frame {
+	return &Frame{s: s}
+}
+
+// Close waits for all submitted jobs to complete, and then stops the worker
+// goroutines. Jobs may not be submitted after Close is called.
+func (s *Scheduler) Close() {
+	s.access.Lock()
+	s.closed = true
+	s.wake.Broadcast()
+	s.access.Unlock()
+	s.wg.Wait()
+}
+
+func (s *Scheduler) parallelFor(name string, n, grain int, fn func(start, end int), f *Frame, deps []*Job) *Job {
+	if grain <= 0 {
+		grain = n / (4 * len(s.queues))
+		if grain < 1 {
+			grain = 1
+		}
+	}
+	var parts []*Job
+	for start := 0; start < n; start += grain {
+		start, end := start, start+grain
+		if end > n {
+			end = n
+		}
+		parts = append(parts, s.submit(name, func() { fn(start, end) }, f, deps))
+	}
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	if len(parts) == 0 {
+		parts = deps
+	}
+	return s.submit(name, func() {}, f, parts)
+}
+
+// newJob returns a new job, pending submission.
+func (s *Scheduler) newJob(name string, fn func(), f *Frame) *Job {
+	return &Job{
+		s:       s,
+		name:    name,
+		fn:      fn,
+		frame:   f,
+		pending: 1,
+		done:    make(chan struct{}),
+	}
+}
+
+// submit submits a new job of the frame (if not nil), depending on the given
+// jobs.
+func (s *Scheduler) submit(name string, fn func(), f *Frame, deps []*Job) *Job {
+	if f != nil {
+		atomic.AddInt32(&f.pending, 1)
+		atomic.AddInt32(&f.jobs, 1)
+	}
+	j := s.newJob(name, fn, f)
+	for _, d := range deps {
+		d.then(j)
+	}
+	s.release(j, -1)
+	return j
+}
+
+// release releases one pending dependency of the job, queueing it on the
+// given worker's queue (or the next one in turn, if w < 0) if it is ready.
+func (s *Scheduler) release(j *Job, w int) {
+	if atomic.AddInt32(&j.pending, -1) != 0 {
+		return
+	}
+	if w < 0 {
+		w = int(atomic.AddUint32(&s.next, 1) % uint32(len(s.queues)))
+	}
+	s.queues[w].push(j)
+	s.access.Lock()
+	s.queued++
+	s.wake.Broadcast()
+	s.access.Unlock()
+}
+
+// take takes a job to run for the given worker (or any goroutine, if w < 0):
+// from the back of it's queue, or else stolen from the front of another's.
+func (s *Scheduler) take(w int) *Job {
+	var j *Job
+	if w >= 0 {
+		j = s.queues[w].pop()
+	}
+	for i := 1; j == nil && i <= len(s.queues); i++ {
+		if j = s.queues[(w+i+len(s.queues))%len(s.queues)].steal(); j != nil {
+			atomic.AddUint64(&s.steals, 1)
+		}
+	}
+	if j != nil {
+		s.access.Lock()
+		s.queued--
+		s.access.Unlock()
+	}
+	return j
+}
+
+// run runs the job on behalf of the given worker (or any goroutine, if w <
+// 0), and then releases it's dependents.
+func (s *Scheduler) run(j *Job, w int) {
+	start := time.Now()
+	j.fn()
+	if s.Recorder != nil {
+		s.Recorder.Record(j.name, start, time.Now())
+	}
+	atomic.AddUint64(&s.jobs, 1)
+	for _, d := range j.finish() {
+		s.release(d, w)
+	}
+	if j.frame != nil {
+		atomic.AddInt32(&j.frame.pending, -1)
+	}
+
+	// Wake goroutines waiting for the job or it's frame.
+	s.access.Lock()
+	s.wake.Broadcast()
+	s.access.Unlock()
+}
+
+// help runs queued jobs until done returns true.
+func (s *Scheduler) help(done func() bool) {
+	for !done() {
+		if j := s.take(-1); j != nil {
+			s.run(j, -1)
+			continue
+		}
+		s.access.Lock()
+		for s.queued == 0 && !done() {
+			s.wake.Wait()
+		}
+		s.access.Unlock()
+	}
+}
+
+// work is the loop of the given worker goroutine.
+func (s *Scheduler) work(w int) {
+	defer s.wg.Done()
+	for {
+		if j := s.take(w); j != nil {
+			s.run(j, w)
+			continue
+		}
+		s.access.Lock()
+		for s.queued == 0 && !s.closed {
+			s.wake.Wait()
+		}
+		exit := s.queued == 0 && s.closed
+		s.access.Unlock()
+		if exit {
+			return
+		}
+	}
+}
+
+// New returns a new scheduler with the given number of worker goroutines, or
+// runtime.NumCPU() workers if workers <= 0.
+func New(workers int) *Scheduler {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	s := &Scheduler{
+		queues: make([]*queue, workers),
+	}
+	s.wake = sync.NewCond(&s.access)
+	for i := range s.queues {
+		s.queues[i] = new(queue)
+	}
+	s.wg.Add(workers)
+	for i := range s.queues {
+		go s.work(i)
+	}
+	return s
+}
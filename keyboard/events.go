@@ -39,6 +39,10 @@ func (b ButtonEvent) String() string {
 
 // Typed represents an event where some sort of user input has generated a
 // string of text which should be considered as user input.
+//
+// Text committed by an input method editor (IME), e.g. when composing Chinese,
+// Japanese or Korean text, is also delivered as a Typed event once the user
+// confirms it. See the Composition event for the text being composed.
 type Typed struct {
 	T time.Time
 	S string
@@ -53,3 +57,34 @@ func (t Typed) Time() time.Time {
 func (t Typed) String() string {
 	return t.S
 }
+
+// Composition represents an event where the text being composed by an input
+// method editor (IME) has changed. The composed (also called "preedit") text
+// is not yet user input: it should be displayed inline by the text field at
+// the cursor position, usually underlined, but not inserted.
+//
+// When the composition ends, a Composition event with an empty Text is sent.
+// If the user confirmed the composition, a Typed event carrying the final text
+// follows it.
+//
+// Composition events are currently only sent on Windows, on other platforms
+// the IME displays the composed text itself.
+type Composition struct {
+	T time.Time
+
+	// The text currently being composed.
+	Text string
+
+	// The position of the composition cursor within Text, in runes.
+	Cursor int
+}
+
+// Time returns the time at which this event occured.
+func (c Composition) Time() time.Time {
+	return c.T
+}
+
+// String returns an string representation of this event.
+func (c Composition) String() string {
+	return fmt.Sprintf("Composition(Text=%q, Cursor=%v, Time=%v)", c.Text, c.Cursor, c.T)
+}
@@ -0,0 +1,379 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lightmap
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/light"
+	"azul3d.org/engine/lmath"
+)
+
+// Offset along the normal of the origin of rays cast from surfaces, avoiding
+// self-intersection.
+const rayOffset = 1e-4
+
+// Target is an object whose lightmap is baked.
+type Target struct {
+	// The object, whose meshes hold lightmap texture coordinates as their
+	// second texture coordinate set (see Unwrap).
+	Object *gfx.Object
+
+	// The albedo (diffuse reflectance) of the object's surface.
+	Albedo gfx.Color
+
+	// The lightmap, written by Baker.Bake. The alpha channel is zero where
+	// texels are neither covered by the object's triangles nor within the
+	// padding around them.
+	Lightmap *image.RGBA
+
+	// Padding is the number of texels around each triangle which are filled
+	// in with the color of the nearest covered texel, such that bilinear
+	// filtering does not bleed unlit texels into the triangle's edges. It
+	// should match the padding given to Unwrap.
+	Padding int
+
+	tris []*tri
+}
+
+// Texture returns a new texture of the target's lightmap, for use as the
+// second texture of objects drawn with shaders.Lightmapped.
+func (t *Target) Texture() *gfx.Texture {
+	tex := gfx.NewTexture()
+	tex.Source = t.Lightmap
+	tex.Bounds = t.Lightmap.Bounds()
+	tex.MinFilter = gfx.Linear
+	tex.MagFilter = gfx.Linear
+	tex.WrapU = gfx.Clamp
+	tex.WrapV = gfx.Clamp
+	return tex
+}
+
+// Baker is an offline path tracer, which bakes the lighting of a static scene
+// into lightmaps.
+//
+// Lighting is baked as the color by which a surface's albedo is multiplied,
+// in linear space: direct light from Lights is computed the same way as the
+// shaders.Clustered shader does (without cookies or specular highlights),
+// and indirect light is gathered from surfaces and the sky by cosine-weighted
+// rays from each texel. Values beyond one are clamped.
+type Baker struct {
+	// The lights of the scene.
+	Lights []*light.Light
+
+	// The color of the sky, which is seen by rays escaping the scene.
+	Sky gfx.Color
+
+	// Samples is the number of rays gathering indirect light for each texel,
+	// or zero for direct light only.
+	Samples int
+
+	// Bounces is the number of times that indirect light is reflected
+	// between surfaces, zero meaning that only the sky is gathered.
+	Bounces int
+
+	targets []*Target
+	tris    []*tri
+}
+
+// NewBaker returns a new baker, gathering 64 samples with two bounces.
+func NewBaker() *Baker {
+	return &Baker{
+		Samples: 64,
+		Bounces: 2,
+	}
+}
+
+// Add adds the given object to the scene, with the given albedo. If width and
+// height are non-zero a lightmap of that size (see Unwrap) is baked for it,
+// otherwise it only occludes and reflects light.
+//
+// The object's meshes must hold triangles; baking uses their current world
+// space positions, as such they should not be moved afterwards.
+func (b *Baker) Add(o *gfx.Object, albedo gfx.Color, width, height int) *Target {
+	t := &Target{
+		Object:  o,
+		Albedo:  albedo,
+		Padding: 2,
+	}
+	if width > 0 && height > 0 {
+		t.Lightmap = image.NewRGBA(image.Rect(0, 0, width, height))
+		b.targets = append(b.targets, t)
+	}
+
+	model := o.Transform.Mat4()
+	for _, m := range o.Meshes {
+		if m.Primitive != gfx.Triangles {
+			continue
+		}
+		lightmapped := t.Lightmap != nil && len(m.TexCoords) > 1
+		for i := 0; i < numTriangles(m); i++ {
+			ia, ib, ic := triangle(m, i)
+			tr := &tri{
+				a:      m.Vertices[ia].Vec3().TransformMat4(model),
+				b:      m.Vertices[ib].Vec3().TransformMat4(model),
+				c:      m.Vertices[ic].Vec3().TransformMat4(model),
+				target: t,
+			}
+			tr.normal, _ = tr.b.Sub(tr.a).Cross(tr.c.Sub(tr.a)).Normalized()
+			b.tris = append(b.tris, tr)
+			if !lightmapped {
+				continue
+			}
+			uv := m.TexCoords[1].Slice
+			for j, idx := range []int{ia, ib, ic} {
+				tr.uv[j] = lmath.Vec2{
+					X: float64(uv[idx].U) * float64(width),
+					Y: float64(uv[idx].V) * float64(height),
+				}
+				tr.normals[j] = tr.normal
+				if len(m.Normals) > 0 {
+					n, ok := m.Normals[idx].Vec3().TransformVecMat4(model).Normalized()
+					if ok {
+						tr.normals[j] = n
+					}
+				}
+			}
+			t.tris = append(t.tris, tr)
+		}
+	}
+	return t
+}
+
+// texel is a baked texel of a lightmap.
+type texel struct {
+	x, y  int
+	color lmath.Vec3
+}
+
+// Bake bakes the lightmaps of all targets added to the scene, using all
+// available CPUs. Baking is deterministic: the same scene always bakes the
+// same lightmaps.
+func (b *Baker) Bake() {
+	if len(b.tris) == 0 {
+		return
+	}
+	bvh := newBVH(append([]*tri(nil), b.tris...))
+
+	for _, t := range b.targets {
+		// Each triangle is baked by a worker, with it's own random source.
+		results := make([][]texel, len(t.tris))
+		work := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < runtime.NumCPU(); w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range work {
+					rng := rand.New(rand.NewSource(int64(i)))
+					results[i] = b.bakeTriangle(bvh, t.tris[i], rng)
+				}
+			}()
+		}
+		for i := range t.tris {
+			work <- i
+		}
+		close(work)
+		wg.Wait()
+
+		img := t.Lightmap
+		for i := range img.Pix {
+			img.Pix[i] = 0
+		}
+		for _, texels := range results {
+			for _, tx := range texels {
+				img.SetRGBA(tx.x, tx.y, color.RGBA{
+					R: toByte(tx.color.X),
+					G: toByte(tx.color.Y),
+					B: toByte(tx.color.Z),
+					A: 255,
+				})
+			}
+		}
+		dilate(img, t.Padding)
+	}
+}
+
+// toByte converts a linear [0, 1] value to a byte, clamping it.
+func toByte(v float64) uint8 {
+	return uint8(lmath.Clamp(v, 0, 1)*255 + 0.5)
+}
+
+// bakeTriangle returns the texels whose centers are covered by the given
+// triangle.
+func (b *Baker) bakeTriangle(bvh *node, t *tri, rng *rand.Rand) []texel {
+	min := t.uv[0].Min(t.uv[1]).Min(t.uv[2])
+	max := t.uv[0].Max(t.uv[1]).Max(t.uv[2])
+	bounds := t.target.Lightmap.Bounds()
+	x0, y0 := maxInt(int(math.Floor(min.X)), 0), maxInt(int(math.Floor(min.Y)), 0)
+	x1, y1 := minInt(int(math.Ceil(max.X)), bounds.Dx()), minInt(int(math.Ceil(max.Y)), bounds.Dy())
+
+	e1, e2 := t.uv[1].Sub(t.uv[0]), t.uv[2].Sub(t.uv[0])
+	det := e1.X*e2.Y - e1.Y*e2.X
+	if math.Abs(det) < lmath.EPSILON {
+		return nil
+	}
+	var texels []texel
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			// The barycentric coordinates of the texel's center.
+			p := lmath.Vec2{X: float64(x) + 0.5, Y: float64(y) + 0.5}.Sub(t.uv[0])
+			u := (p.X*e2.Y - p.Y*e2.X) / det
+			v := (e1.X*p.Y - e1.Y*p.X) / det
+			if u < 0 || v < 0 || u+v > 1 {
+				continue
+			}
+			w := 1 - u - v
+			pos := t.a.MulScalar(w).Add(t.b.MulScalar(u)).Add(t.c.MulScalar(v))
+			n := t.normals[0].MulScalar(w).Add(t.normals[1].MulScalar(u)).Add(t.normals[2].MulScalar(v))
+			n, ok := n.Normalized()
+			if !ok {
+				n = t.normal
+			}
+			texels = append(texels, texel{x, y, b.gather(bvh, pos, n, rng)})
+		}
+	}
+	return texels
+}
+
+// gather returns the light arriving at the given point with the given normal.
+func (b *Baker) gather(bvh *node, p, n lmath.Vec3, rng *rand.Rand) lmath.Vec3 {
+	l := b.direct(bvh, p, n)
+	if b.Samples <= 0 {
+		return l
+	}
+	var indirect lmath.Vec3
+	for i := 0; i < b.Samples; i++ {
+		indirect = indirect.Add(b.trace(bvh, p, n, b.Bounces, rng))
+	}
+	return l.Add(indirect.DivScalar(float64(b.Samples)))
+}
+
+// direct returns the direct light arriving at the given point with the given
+// normal, casting shadow rays towards each light.
+func (b *Baker) direct(bvh *node, p, n lmath.Vec3) lmath.Vec3 {
+	var sum lmath.Vec3
+	origin := p.Add(n.MulScalar(rayOffset))
+	for _, l := range b.Lights {
+		dir, dist, att := l.Incident(p)
+		cos := n.Dot(dir)
+		if att <= 0 || cos <= 0 {
+			continue
+		}
+		if !bvh.visible(lmath.Ray{Origin: origin, Dir: dir}, dist) {
+			continue
+		}
+		c := l.Color
+		sum = sum.Add(lmath.Vec3{X: float64(c.R), Y: float64(c.G), Z: float64(c.B)}.MulScalar(att * cos))
+	}
+	return sum
+}
+
+// trace returns the light arriving at the given point from a random
+// cosine-weighted direction about the given normal, reflected between surfaces
+// up to the given number of bounces.
+func (b *Baker) trace(bvh *node, p, n lmath.Vec3, bounces int, rng *rand.Rand) lmath.Vec3 {
+	r := lmath.Ray{
+		Origin: p.Add(n.MulScalar(rayOffset)),
+		Dir:    cosineSample(n, rng),
+	}
+	hit, dist := bvh.closest(r)
+	if hit == nil {
+		return lmath.Vec3{X: float64(b.Sky.R), Y: float64(b.Sky.G), Z: float64(b.Sky.B)}
+	}
+	if bounces == 0 {
+		return lmath.Vec3{}
+	}
+	hp := r.At(dist)
+	hn := hit.normal
+	if hn.Dot(r.Dir) > 0 {
+		hn = hn.MulScalar(-1)
+	}
+	l := b.direct(bvh, hp, hn).Add(b.trace(bvh, hp, hn, bounces-1, rng))
+	a := hit.target.Albedo
+	return l.Mul(lmath.Vec3{X: float64(a.R), Y: float64(a.G), Z: float64(a.B)})
+}
+
+// cosineSample returns a random direction in the hemisphere about the given
+// normal, with a probability proportional to the cosine of it's angle to the
+// normal.
+func cosineSample(n lmath.Vec3, rng *rand.Rand) lmath.Vec3 {
+	// A tangent frame about the normal.
+	up := lmath.Vec3{Z: 1}
+	if math.Abs(n.Z) > 0.9 {
+		up = lmath.Vec3{X: 1}
+	}
+	t, _ := up.Cross(n).Normalized()
+	bt := n.Cross(t)
+
+	// Malley's method: project a uniform sample of the unit disc onto the
+	// hemisphere.
+	r := math.Sqrt(rng.Float64())
+	phi := 2 * math.Pi * rng.Float64()
+	x, y := r*math.Cos(phi), r*math.Sin(phi)
+	z := math.Sqrt(math.Max(0, 1-x*x-y*y))
+	return t.MulScalar(x).Add(bt.MulScalar(y)).Add(n.MulScalar(z))
+}
+
+// dilate fills the given number of rings of uncovered texels (those with zero
+// alpha) around covered ones with the average of their covered neighbours.
+func dilate(img *image.RGBA, rings int) {
+	b := img.Bounds()
+	for ; rings > 0; rings-- {
+		src := append([]uint8(nil), img.Pix...)
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				o := img.PixOffset(x, y)
+				if src[o+3] != 0 {
+					continue
+				}
+				var sum [3]int
+				n := 0
+				for dy := -1; dy <= 1; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						if !image.Pt(x+dx, y+dy).In(b) {
+							continue
+						}
+						no := img.PixOffset(x+dx, y+dy)
+						if src[no+3] == 0 {
+							continue
+						}
+						for i := range sum {
+							sum[i] += int(src[no+i])
+						}
+						n++
+					}
+				}
+				if n == 0 {
+					continue
+				}
+				for i := range sum {
+					img.Pix[o+i] = uint8(sum[i] / n)
+				}
+				img.Pix[o+3] = 255
+			}
+		}
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,136 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lightmap
+
+import (
+	"math"
+	"sort"
+
+	"azul3d.org/engine/lmath"
+)
+
+// tri is a world space triangle of the scene.
+type tri struct {
+	a, b, c lmath.Vec3
+	normal  lmath.Vec3
+	target  *Target
+
+	// The lightmap texel coordinates and world space normals of the
+	// vertices, for triangles whose lightmap is baked.
+	uv      [3]lmath.Vec2
+	normals [3]lmath.Vec3
+}
+
+// bounds returns the bounds of the triangle.
+func (t *tri) bounds() lmath.Rect3 {
+	return lmath.Rect3{
+		Min: t.a.Min(t.b).Min(t.c),
+		Max: t.a.Max(t.b).Max(t.c),
+	}
+}
+
+// center returns the centroid of the triangle.
+func (t *tri) center() lmath.Vec3 {
+	return t.a.Add(t.b).Add(t.c).DivScalar(3)
+}
+
+// The maximum number of triangles in a leaf node of the BVH.
+const leafSize = 4
+
+// node is a node of a bounding volume hierarchy over triangles. Leaf nodes
+// hold triangles, other nodes hold two children.
+type node struct {
+	bounds      lmath.Rect3
+	left, right *node
+	tris        []*tri
+}
+
+// newBVH builds a bounding volume hierarchy over the given triangles, by
+// splitting them at the median along the longest axis of their bounds. The
+// slice is reordered.
+func newBVH(tris []*tri) *node {
+	n := &node{bounds: tris[0].bounds()}
+	for _, t := range tris[1:] {
+		n.bounds = n.bounds.Union(t.bounds())
+	}
+	if len(tris) <= leafSize {
+		n.tris = tris
+		return n
+	}
+
+	size := n.bounds.Size()
+	axis := func(v lmath.Vec3) float64 { return v.X }
+	if size.Y > size.X && size.Y > size.Z {
+		axis = func(v lmath.Vec3) float64 { return v.Y }
+	} else if size.Z > size.X {
+		axis = func(v lmath.Vec3) float64 { return v.Z }
+	}
+	sort.Sort(byAxis{tris, axis})
+	mid := len(tris) / 2
+	n.left, n.right = newBVH(tris[:mid]), newBVH(tris[mid:])
+	return n
+}
+
+// byAxis sorts triangles by their centroid along an axis.
+type byAxis struct {
+	tris []*tri
+	axis func(v lmath.Vec3) float64
+}
+
+func (b byAxis) Len() int      { return len(b.tris) }
+func (b byAxis) Swap(i, j int) { b.tris[i], b.tris[j] = b.tris[j], b.tris[i] }
+func (b byAxis) Less(i, j int) bool {
+	return b.axis(b.tris[i].center()) < b.axis(b.tris[j].center())
+}
+
+// intersect returns the closest triangle hit by the ray within the given
+// distance, and the distance to it. If any is true, it returns the first
+// triangle hit instead (e.g. for shadow rays).
+func (n *node) intersect(r lmath.Ray, maxDist float64, any bool) (hit *tri, dist float64) {
+	dist = maxDist
+	if t, ok := r.IntersectRect3(n.bounds); !ok || t > dist {
+		return nil, dist
+	}
+	if n.tris == nil {
+		hit, dist = n.left.intersect(r, dist, any)
+		if hit != nil && any {
+			return hit, dist
+		}
+		if h, d := n.right.intersect(r, dist, any); h != nil {
+			hit, dist = h, d
+		}
+		return hit, dist
+	}
+	for _, t := range n.tris {
+		d, _, _, ok := r.IntersectTriangle(t.a, t.b, t.c)
+		if !ok || d >= dist {
+			continue
+		}
+		hit, dist = t, d
+		if any {
+			break
+		}
+	}
+	return hit, dist
+}
+
+// visible tells if the ray reaches the given distance without hitting any
+// triangle.
+func (n *node) visible(r lmath.Ray, dist float64) bool {
+	if n == nil {
+		return true
+	}
+	hit, _ := n.intersect(r, dist, true)
+	return hit == nil
+}
+
+// closest returns the closest triangle hit by the ray, and the distance to
+// it.
+func (n *node) closest(r lmath.Ray) (*tri, float64) {
+	if n == nil {
+		return nil, 0
+	}
+	return n.intersect(r, math.Inf(1), false)
+}
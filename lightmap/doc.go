@@ -0,0 +1,32 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package lightmap bakes the lighting of static scene geometry into lightmaps
+// offline, using a CPU path tracer.
+//
+// Lightmaps are addressed by a second texture coordinate set of each mesh,
+// generated by Unwrap. The Baker traces direct light from the lights of the
+// scene (see package light) and indirect light bounced between surfaces and
+// from the sky, then the lightmaps are drawn using the shaders.Lightmapped
+// shader:
+//
+//  w, h, err := lightmap.Unwrap(ground.Meshes[0], 16, 2)
+//  if err != nil {
+//      log.Fatal(err)
+//  }
+//  b := lightmap.NewBaker()
+//  b.Sky = gfx.Color{0.3, 0.35, 0.4, 1}
+//  b.Lights = []*light.Light{sun}
+//  target := b.Add(ground, gfx.Color{0.6, 0.6, 0.6, 1}, w, h)
+//  b.Add(house, gfx.Color{0.8, 0.7, 0.6, 1}, 0, 0) // Only casts shadows.
+//  b.Bake()
+//
+//  ground.Shader = shaders.New(shaders.Lightmapped, glsl.GL2)
+//  ground.Textures = []*gfx.Texture{albedo, target.Texture()}
+//
+// Baking is slow, it is meant to be done ahead of time with the lightmaps
+// saved as images (e.g. with image/png). As Unwrap is deterministic, the
+// texture coordinates need not be saved: calling it again with the same
+// parameters on the same mesh when it is loaded recreates them.
+package lightmap // import "azul3d.org/engine/lightmap"
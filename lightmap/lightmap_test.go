@@ -0,0 +1,153 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lightmap
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/light"
+	"azul3d.org/engine/lmath"
+)
+
+// quad returns an object with a square of the given size on the X/Y plane at
+// the given height, facing +Z.
+func quad(size, z float32) *gfx.Object {
+	m := gfx.NewMesh()
+	h := size / 2
+	m.Vertices = []gfx.Vec3{
+		{X: -h, Y: -h, Z: z},
+		{X: h, Y: -h, Z: z},
+		{X: h, Y: h, Z: z},
+		{X: -h, Y: h, Z: z},
+	}
+	m.Indices = []uint32{0, 1, 2, 0, 2, 3}
+	o := gfx.NewObject()
+	o.Meshes = []*gfx.Mesh{m}
+	return o
+}
+
+func TestUnwrap(t *testing.T) {
+	m := quad(4, 0).Meshes[0]
+	m.Colors = []gfx.Color{{R: 1}, {G: 1}, {B: 1}, {A: 1}}
+	w, h, err := Unwrap(m, 8, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Indices) != 0 || len(m.Vertices) != 6 || len(m.Colors) != 6 {
+		t.Fatalf("got %d indices, %d vertices, %d colors", len(m.Indices), len(m.Vertices), len(m.Colors))
+	}
+	if m.Colors[4] != (gfx.Color{B: 1}) {
+		t.Fatalf("got color %v for the second triangle's second vertex", m.Colors[4])
+	}
+
+	// Each triangle's texels lie within the lightmap, without overlapping.
+	uv := m.TexCoords[1].Slice
+	var rects []image.Rectangle
+	for i := 0; i < len(uv); i += 3 {
+		var r image.Rectangle
+		for j, tc := range uv[i : i+3] {
+			if tc.U < 0 || tc.U > 1 || tc.V < 0 || tc.V > 1 {
+				t.Fatalf("texture coordinate %v out of range", tc)
+			}
+			p := image.Pt(int(tc.U*float32(w)), int(tc.V*float32(h)))
+			if j == 0 {
+				r = image.Rectangle{Min: p, Max: p}
+			}
+			r = r.Union(image.Rectangle{Min: p, Max: p.Add(image.Pt(1, 1))})
+		}
+		for _, other := range rects {
+			if r.Overlaps(other) {
+				t.Fatalf("triangle texels %v overlap %v", r, other)
+			}
+		}
+		rects = append(rects, r)
+	}
+
+	// The texel density is preserved: the legs of the first triangle are
+	// four units long.
+	if r := rects[0]; r.Dx() < 32 || r.Dx() > 34 || r.Dy() < 32 || r.Dy() > 34 {
+		t.Fatalf("triangle spans %v texels", r)
+	}
+
+	if _, _, err := Unwrap(gfx.NewMesh(), 8, 2); err == nil {
+		t.Fatal("expected an error unwrapping an empty mesh")
+	}
+}
+
+// bake bakes a 4x4 ground plane, whose lightmap is returned.
+func bake(t *testing.T, b *Baker, occluders ...*gfx.Object) *image.RGBA {
+	ground := quad(4, 0)
+	w, h, err := Unwrap(ground.Meshes[0], 8, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := b.Add(ground, gfx.Color{R: 1, G: 1, B: 1, A: 1}, w, h)
+	target.Padding = 0
+	for _, o := range occluders {
+		b.Add(o, gfx.Color{A: 1}, 0, 0)
+	}
+	b.Bake()
+	return target.Lightmap
+}
+
+// histogram returns the fraction of covered texels of the lightmap with red
+// values below 16, and the largest red value.
+func histogram(img *image.RGBA) (dark float64, max uint8) {
+	covered := 0
+	n := 0
+	for i := 0; i < len(img.Pix); i += 4 {
+		if img.Pix[i+3] == 0 {
+			continue
+		}
+		covered++
+		if img.Pix[i] < 16 {
+			n++
+		}
+		if img.Pix[i] > max {
+			max = img.Pix[i]
+		}
+	}
+	return float64(n) / float64(covered), max
+}
+
+func TestBakeShadow(t *testing.T) {
+	b := NewBaker()
+	b.Samples = 0
+	b.Lights = []*light.Light{{
+		Kind:      light.Directional,
+		Direction: lmath.Vec3{Z: -1},
+		Color:     gfx.Color{R: 0.5, G: 0.5, B: 0.5, A: 1},
+	}}
+	img := bake(t, b, quad(1, 1))
+
+	// The occluder shadows a sixteenth of the ground.
+	dark, max := histogram(img)
+	if math.Abs(dark-1.0/16) > 0.02 {
+		t.Fatalf("got %v of the ground in shadow, want 1/16", dark)
+	}
+	if max != 128 {
+		t.Fatalf("got lit value %d, want 128", max)
+	}
+}
+
+func TestBakeSky(t *testing.T) {
+	b := NewBaker()
+	b.Samples = 16
+	b.Sky = gfx.Color{R: 1, G: 1, B: 1, A: 1}
+	img := bake(t, b)
+
+	// The ground sees nothing but the sky.
+	if dark, max := histogram(img); dark != 0 || max != 255 {
+		t.Fatalf("got dark fraction %v, max %d", dark, max)
+	}
+	for i := 0; i < len(img.Pix); i += 4 {
+		if img.Pix[i+3] != 0 && img.Pix[i] != 255 {
+			t.Fatalf("got sky light %d, want 255", img.Pix[i])
+		}
+	}
+}
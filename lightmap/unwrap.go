@@ -0,0 +1,172 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lightmap
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"sort"
+
+	"azul3d.org/engine/binpack"
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/lmath"
+)
+
+// chart is the rectangle of a single triangle in the lightmap, in texels.
+type chart struct {
+	uv            [3]lmath.Vec2
+	width, height int
+	x, y          int
+}
+
+// size returns the larger of the chart's width and height.
+func (c *chart) size() int {
+	if c.width > c.height {
+		return c.width
+	}
+	return c.height
+}
+
+// charts implements binpack.Packable, and sorts charts largest first as it
+// requires.
+type charts []*chart
+
+func (c charts) Len() int              { return len(c) }
+func (c charts) Size(n int) (w, h int) { return c[n].width, c[n].height }
+func (c charts) Place(n, x, y int)     { c[n].x, c[n].y = x, y }
+func (c charts) Less(i, j int) bool    { return c[i].size() > c[j].size() }
+func (c charts) Swap(i, j int)         { c[i], c[j] = c[j], c[i] }
+
+// triangle returns the vertex indices of the n'th triangle of the mesh.
+func triangle(m *gfx.Mesh, n int) (a, b, c int) {
+	if len(m.Indices) > 0 {
+		return int(m.Indices[n*3]), int(m.Indices[n*3+1]), int(m.Indices[n*3+2])
+	}
+	return n * 3, n*3 + 1, n*3 + 2
+}
+
+// numTriangles returns the number of triangles of the mesh.
+func numTriangles(m *gfx.Mesh) int {
+	if len(m.Indices) > 0 {
+		return len(m.Indices) / 3
+	}
+	return len(m.Vertices) / 3
+}
+
+// expand returns a copy of the given slice (a slice of any type) holding the
+// elements at the given indices, or the slice itself if it is empty.
+func expand(slice interface{}, indices []int) interface{} {
+	v := reflect.ValueOf(slice)
+	if v.Kind() != reflect.Slice || v.Len() == 0 {
+		return slice
+	}
+	out := reflect.MakeSlice(v.Type(), len(indices), len(indices))
+	for i, idx := range indices {
+		out.Index(i).Set(v.Index(idx))
+	}
+	return out.Interface()
+}
+
+// Unwrap generates lightmap texture coordinates for the given mesh as it's
+// second texture coordinate set (TexCoords[1]), with the given number of
+// lightmap texels per world unit, and returns the size of the lightmap that
+// they are laid out for.
+//
+// Each triangle is laid flat in it's own chart, surrounded by the given
+// number of texels of padding, and the charts are packed into the lightmap.
+// As no two triangles share a chart, the mesh is first converted to
+// non-indexed triangles (such that no vertices are shared between them).
+// The mesh's vertices are in it's object's local space, as such the object
+// should not be scaled.
+//
+// Unwrapping is deterministic, such that the texture coordinates need not be
+// stored alongside a baked lightmap: they can be generated again when the
+// mesh is loaded.
+func Unwrap(m *gfx.Mesh, texelsPerUnit float64, padding int) (width, height int, err error) {
+	if m.Primitive != gfx.Triangles {
+		return 0, 0, errors.New("lightmap: mesh primitive is not Triangles")
+	}
+	n := numTriangles(m)
+	if n == 0 {
+		return 0, 0, errors.New("lightmap: mesh has no triangles")
+	}
+
+	// Lay each triangle flat, with it's first edge along the X axis.
+	cs := make(charts, n)
+	indices := make([]int, 0, n*3)
+	for i := range cs {
+		ia, ib, ic := triangle(m, i)
+		indices = append(indices, ia, ib, ic)
+		a, b, c := m.Vertices[ia].Vec3(), m.Vertices[ib].Vec3(), m.Vertices[ic].Vec3()
+		ab, ac := b.Sub(a), c.Sub(a)
+		e1, ok := ab.Normalized()
+		if !ok {
+			e1 = lmath.Vec3{X: 1}
+		}
+		normal, _ := ab.Cross(ac).Normalized()
+		e2 := normal.Cross(e1)
+		uv := [3]lmath.Vec2{
+			{},
+			{X: ab.Length() * texelsPerUnit},
+			{X: ac.Dot(e1) * texelsPerUnit, Y: ac.Dot(e2) * texelsPerUnit},
+		}
+		minX := math.Min(0, uv[2].X)
+		for j := range uv {
+			uv[j].X += float64(padding) - minX
+			uv[j].Y += float64(padding)
+		}
+		cs[i] = &chart{
+			uv:     uv,
+			width:  int(math.Ceil(math.Max(uv[1].X, uv[2].X))) + padding,
+			height: int(math.Ceil(uv[2].Y)) + padding,
+		}
+	}
+
+	sorted := make(charts, n)
+	copy(sorted, cs)
+	sort.Stable(sorted)
+	width, height = binpack.Pack(sorted)
+	if width < 0 {
+		return 0, 0, errors.New("lightmap: failed to pack charts")
+	}
+
+	// Convert the mesh to non-indexed triangles, and assign the texture
+	// coordinates of the packed charts.
+	m.Vertices = expand(m.Vertices, indices).([]gfx.Vec3)
+	m.Colors = expand(m.Colors, indices).([]gfx.Color)
+	m.Normals = expand(m.Normals, indices).([]gfx.Vec3)
+	m.Bary = expand(m.Bary, indices).([]gfx.Vec3)
+	for i := range m.TexCoords {
+		m.TexCoords[i].Slice = expand(m.TexCoords[i].Slice, indices).([]gfx.TexCoord)
+		m.TexCoords[i].Changed = true
+	}
+	for name, a := range m.Attribs {
+		a.Data = expand(a.Data, indices)
+		a.Changed = true
+		m.Attribs[name] = a
+	}
+	m.Indices = nil
+	m.IndicesChanged = true
+	m.VerticesChanged = true
+	m.ColorsChanged = len(m.Colors) > 0
+	m.NormalsChanged = len(m.Normals) > 0
+	m.BaryChanged = len(m.Bary) > 0
+
+	set := make([]gfx.TexCoord, 0, n*3)
+	for _, c := range cs {
+		for _, uv := range c.uv {
+			set = append(set, gfx.TexCoord{
+				U: float32((float64(c.x) + uv.X) / float64(width)),
+				V: float32((float64(c.y) + uv.Y) / float64(height)),
+			})
+		}
+	}
+	for len(m.TexCoords) < 2 {
+		m.TexCoords = append(m.TexCoords, gfx.TexCoordSet{})
+	}
+	m.TexCoords[1] = gfx.TexCoordSet{Slice: set, Changed: true}
+	return width, height, nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lmath
+
+// TransformPoints transforms each point in src by the affine transformation
+// matrix (like Vec3.TransformMat4), storing the results in dst. dst and src
+// may be the same slice.
+//
+// On amd64 and arm64 it uses SIMD instructions, and is significantly faster
+// than transforming each point individually (e.g. for particles or skinning).
+// On arm64 results may differ from Vec3.TransformMat4 by a few ULP, as it
+// uses fused multiply-add instructions (amd64 uses separate SSE2 multiply and
+// add instructions).
+//
+// It panics if dst is shorter than src.
+func TransformPoints(dst, src []Vec3, m Mat4) {
+	if len(dst) < len(src) {
+		panic("lmath: TransformPoints destination too short")
+	}
+	if len(src) == 0 {
+		return
+	}
+	transformPoints(dst, src, &m)
+}
+
+// MulMat4s multiplies each pair of matrices from a and b (like Mat4.Mul),
+// storing the results in dst. dst may be the same slice as a or b.
+//
+// On amd64 and arm64 it uses SIMD instructions, and is significantly faster
+// than multiplying each pair individually (e.g. for skinning matrices). On
+// arm64 results may differ from Mat4.Mul by a few ULP, as it uses fused
+// multiply-add instructions.
+//
+// It panics if b or dst is shorter than a.
+func MulMat4s(dst, a, b []Mat4) {
+	if len(b) < len(a) || len(dst) < len(a) {
+		panic("lmath: MulMat4s slices too short")
+	}
+	if len(a) == 0 {
+		return
+	}
+	mulMat4s(dst, a, b)
+}
+
+// transformPointsGeneric is the pure-Go implementation of TransformPoints.
+func transformPointsGeneric(dst, src []Vec3, m *Mat4) {
+	for i, p := range src {
+		dst[i] = p.TransformMat4(*m)
+	}
+}
+
+// mulMat4sGeneric is the pure-Go implementation of MulMat4s.
+func mulMat4sGeneric(dst, a, b []Mat4) {
+	for i := range a {
+		dst[i] = a[i].Mul(b[i])
+	}
+}
@@ -0,0 +1,13 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lmath
+
+// Implemented in assembly.
+
+//go:noescape
+func transformPoints(dst, src []Vec3, m *Mat4)
+
+//go:noescape
+func mulMat4s(dst, a, b []Mat4)
@@ -0,0 +1,136 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+#include "textflag.h"
+
+// func transformPoints(dst, src []Vec3, m *Mat4)
+//
+// The X and Y components of each point are computed two at a time using SSE2,
+// the Z component is computed using scalar SSE2 instructions.
+TEXT ·transformPoints(SB), NOSPLIT, $0-56
+	MOVQ dst_base+0(FP), DI
+	MOVQ src_base+24(FP), SI
+	MOVQ src_len+32(FP), CX
+	MOVQ m+48(FP), AX
+
+	// X0-X3 = columns 0 and 1 of each matrix row.
+	MOVUPD 0(AX), X0
+	MOVUPD 32(AX), X1
+	MOVUPD 64(AX), X2
+	MOVUPD 96(AX), X3
+
+	// X4-X7 = column 2 of each matrix row.
+	MOVSD 16(AX), X4
+	MOVSD 48(AX), X5
+	MOVSD 80(AX), X6
+	MOVSD 112(AX), X7
+
+loop:
+	MOVSD 0(SI), X8
+	MOVSD 8(SI), X9
+	MOVSD 16(SI), X10
+
+	// Z = x*m[0][2] + y*m[1][2] + z*m[2][2] + m[3][2]
+	MOVAPD X8, X12
+	MULSD  X4, X12
+	MOVAPD X9, X13
+	MULSD  X5, X13
+	ADDSD  X13, X12
+	MOVAPD X10, X13
+	MULSD  X6, X13
+	ADDSD  X13, X12
+	ADDSD  X7, X12
+
+	// XY = x*m[0][0:2] + y*m[1][0:2] + z*m[2][0:2] + m[3][0:2]
+	UNPCKLPD X8, X8
+	MULPD    X0, X8
+	UNPCKLPD X9, X9
+	MULPD    X1, X9
+	ADDPD    X9, X8
+	UNPCKLPD X10, X10
+	MULPD    X2, X10
+	ADDPD    X10, X8
+	ADDPD    X3, X8
+
+	MOVUPD X8, 0(DI)
+	MOVSD  X12, 16(DI)
+
+	ADDQ $24, SI
+	ADDQ $24, DI
+	DECQ CX
+	JNZ  loop
+	RET
+
+// func mulMat4s(dst, a, b []Mat4)
+//
+// Each row of the result is computed two columns at a time using SSE2:
+//  out[r] = a[r][0]*b[0] + a[r][1]*b[1] + a[r][2]*b[2] + a[r][3]*b[3]
+TEXT ·mulMat4s(SB), NOSPLIT, $0-72
+	MOVQ dst_base+0(FP), DI
+	MOVQ a_base+24(FP), SI
+	MOVQ a_len+32(FP), CX
+	MOVQ b_base+48(FP), DX
+
+matrix:
+	// X0-X7 = the rows of b, two columns per register. They are loaded before
+	// storing any result row, in case dst and b alias.
+	MOVUPD 0(DX), X0
+	MOVUPD 16(DX), X1
+	MOVUPD 32(DX), X2
+	MOVUPD 48(DX), X3
+	MOVUPD 64(DX), X4
+	MOVUPD 80(DX), X5
+	MOVUPD 96(DX), X6
+	MOVUPD 112(DX), X7
+
+	MOVQ $4, BX
+
+row:
+	// X8, X9 = a[r][0] * b[0]
+	MOVSD    0(SI), X10
+	UNPCKLPD X10, X10
+	MOVAPD   X10, X8
+	MULPD    X0, X8
+	MOVAPD   X10, X9
+	MULPD    X1, X9
+
+	// += a[r][1] * b[1]
+	MOVSD    8(SI), X10
+	UNPCKLPD X10, X10
+	MOVAPD   X10, X11
+	MULPD    X2, X11
+	ADDPD    X11, X8
+	MULPD    X3, X10
+	ADDPD    X10, X9
+
+	// += a[r][2] * b[2]
+	MOVSD    16(SI), X10
+	UNPCKLPD X10, X10
+	MOVAPD   X10, X11
+	MULPD    X4, X11
+	ADDPD    X11, X8
+	MULPD    X5, X10
+	ADDPD    X10, X9
+
+	// += a[r][3] * b[3]
+	MOVSD    24(SI), X10
+	UNPCKLPD X10, X10
+	MOVAPD   X10, X11
+	MULPD    X6, X11
+	ADDPD    X11, X8
+	MULPD    X7, X10
+	ADDPD    X10, X9
+
+	MOVUPD X8, 0(DI)
+	MOVUPD X9, 16(DI)
+
+	ADDQ $32, SI
+	ADDQ $32, DI
+	DECQ BX
+	JNZ  row
+
+	ADDQ $128, DX
+	DECQ CX
+	JNZ  matrix
+	RET
@@ -0,0 +1,106 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+#include "textflag.h"
+
+// func transformPoints(dst, src []Vec3, m *Mat4)
+//
+// The X and Y components of each point are computed two at a time using NEON
+// fused multiply-add, the Z component is computed using scalar instructions.
+TEXT ·transformPoints(SB), NOSPLIT, $0-56
+	MOVD dst_base+0(FP), R0
+	MOVD src_base+24(FP), R1
+	MOVD src_len+32(FP), R2
+	MOVD m+48(FP), R3
+
+	// V0-V3 = columns 0 and 1 of each matrix row.
+	VLD1 (R3), [V0.D2]
+	ADD  $32, R3, R4
+	VLD1 (R4), [V1.D2]
+	ADD  $64, R3, R4
+	VLD1 (R4), [V2.D2]
+	ADD  $96, R3, R4
+	VLD1 (R4), [V3.D2]
+
+	// F4-F7 = column 2 of each matrix row.
+	FMOVD 16(R3), F4
+	FMOVD 48(R3), F5
+	FMOVD 80(R3), F6
+	FMOVD 112(R3), F7
+
+loop:
+	FMOVD 0(R1), F16
+	FMOVD 8(R1), F17
+	FMOVD 16(R1), F18
+
+	// Z = x*m[0][2] + y*m[1][2] + z*m[2][2] + m[3][2]
+	FMULD F4, F16, F22
+	FMULD F5, F17, F23
+	FADDD F23, F22, F22
+	FMULD F6, F18, F23
+	FADDD F23, F22, F22
+	FADDD F7, F22, F22
+
+	// XY = m[3][0:2] + x*m[0][0:2] + y*m[1][0:2] + z*m[2][0:2]
+	VORR  V3.B16, V3.B16, V20.B16
+	VDUP  V16.D[0], V21.D2
+	VFMLA V0.D2, V21.D2, V20.D2
+	VDUP  V17.D[0], V21.D2
+	VFMLA V1.D2, V21.D2, V20.D2
+	VDUP  V18.D[0], V21.D2
+	VFMLA V2.D2, V21.D2, V20.D2
+
+	VST1  [V20.D2], (R0)
+	FMOVD F22, 16(R0)
+
+	ADD  $24, R1
+	ADD  $24, R0
+	SUBS $1, R2
+	BNE  loop
+	RET
+
+// func mulMat4s(dst, a, b []Mat4)
+//
+// Each row of the result is computed two columns at a time using NEON fused
+// multiply-add:
+//  out[r] = a[r][0]*b[0] + a[r][1]*b[1] + a[r][2]*b[2] + a[r][3]*b[3]
+TEXT ·mulMat4s(SB), NOSPLIT, $0-72
+	MOVD dst_base+0(FP), R0
+	MOVD a_base+24(FP), R1
+	MOVD a_len+32(FP), R2
+	MOVD b_base+48(FP), R3
+
+matrix:
+	// V0-V7 = the rows of b, two columns per register. They are loaded before
+	// storing any result row, in case dst and b alias.
+	VLD1.P 64(R3), [V0.D2, V1.D2, V2.D2, V3.D2]
+	VLD1.P 64(R3), [V4.D2, V5.D2, V6.D2, V7.D2]
+	MOVD   $4, R4
+
+row:
+	VLD1.P 32(R1), [V16.D2, V17.D2]
+	VEOR   V18.B16, V18.B16, V18.B16
+	VEOR   V19.B16, V19.B16, V19.B16
+
+	VDUP  V16.D[0], V20.D2
+	VFMLA V0.D2, V20.D2, V18.D2
+	VFMLA V1.D2, V20.D2, V19.D2
+	VDUP  V16.D[1], V20.D2
+	VFMLA V2.D2, V20.D2, V18.D2
+	VFMLA V3.D2, V20.D2, V19.D2
+	VDUP  V17.D[0], V20.D2
+	VFMLA V4.D2, V20.D2, V18.D2
+	VFMLA V5.D2, V20.D2, V19.D2
+	VDUP  V17.D[1], V20.D2
+	VFMLA V6.D2, V20.D2, V18.D2
+	VFMLA V7.D2, V20.D2, V19.D2
+
+	VST1.P [V18.D2, V19.D2], 32(R0)
+
+	SUBS $1, R4
+	BNE  row
+
+	SUBS $1, R2
+	BNE  matrix
+	RET
@@ -0,0 +1,14 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+// +build !amd64,!arm64
+
+package lmath
+
+func transformPoints(dst, src []Vec3, m *Mat4) {
+	transformPointsGeneric(dst, src, m)
+}
+
+func mulMat4s(dst, a, b []Mat4) {
+	mulMat4sGeneric(dst, a, b)
+}
@@ -0,0 +1,134 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lmath
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func randMat4(r *rand.Rand) Mat4 {
+	var m Mat4
+	for i := range m {
+		for j := range m[i] {
+			m[i][j] = r.Float64()*20 - 10
+		}
+	}
+	return m
+}
+
+func randPoints(r *rand.Rand, n int) []Vec3 {
+	p := make([]Vec3, n)
+	for i := range p {
+		p[i] = Vec3{r.Float64()*200 - 100, r.Float64()*200 - 100, r.Float64()*200 - 100}
+	}
+	return p
+}
+
+func TestTransformPoints(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, n := range []int{0, 1, 2, 3, 17} {
+		m := randMat4(r)
+		src := randPoints(r, n)
+		want := make([]Vec3, n)
+		transformPointsGeneric(want, src, &m)
+		got := make([]Vec3, n)
+		TransformPoints(got, src, m)
+		for i := range got {
+			if !got[i].AlmostEquals(want[i], 1e-9) {
+				t.Fatalf("n=%d: point %d = %v, want %v", n, i, got[i], want[i])
+			}
+		}
+
+		// In-place.
+		TransformPoints(src, src, m)
+		for i := range src {
+			if !src[i].AlmostEquals(want[i], 1e-9) {
+				t.Fatalf("n=%d: in-place point %d = %v, want %v", n, i, src[i], want[i])
+			}
+		}
+	}
+}
+
+func TestMulMat4s(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for _, n := range []int{0, 1, 2, 5} {
+		a := make([]Mat4, n)
+		b := make([]Mat4, n)
+		for i := range a {
+			a[i], b[i] = randMat4(r), randMat4(r)
+		}
+		want := make([]Mat4, n)
+		mulMat4sGeneric(want, a, b)
+		got := make([]Mat4, n)
+		MulMat4s(got, a, b)
+		for i := range got {
+			if !got[i].AlmostEquals(want[i], 1e-9) {
+				t.Fatalf("n=%d: matrix %d = %v, want %v", n, i, got[i], want[i])
+			}
+		}
+
+		// In-place, aliasing both operands.
+		MulMat4s(a, a, b)
+		MulMat4s(b, want, b)
+		for i := range a {
+			if !a[i].AlmostEquals(want[i], 1e-9) {
+				t.Fatalf("n=%d: in-place (a) matrix %d = %v, want %v", n, i, a[i], want[i])
+			}
+		}
+	}
+}
+
+func TestBatchPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	TransformPoints(make([]Vec3, 1), make([]Vec3, 2), Mat4Identity)
+}
+
+func benchmarkTransformPoints(b *testing.B, f func(dst, src []Vec3, m *Mat4)) {
+	r := rand.New(rand.NewSource(1))
+	m := randMat4(r)
+	src := randPoints(r, 1024)
+	dst := make([]Vec3, len(src))
+	b.SetBytes(int64(len(src) * 24))
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		f(dst, src, &m)
+	}
+}
+
+func BenchmarkTransformPoints(b *testing.B) {
+	benchmarkTransformPoints(b, transformPoints)
+}
+
+func BenchmarkTransformPointsGeneric(b *testing.B) {
+	benchmarkTransformPoints(b, transformPointsGeneric)
+}
+
+func benchmarkMulMat4s(b *testing.B, f func(dst, a, b []Mat4)) {
+	r := rand.New(rand.NewSource(1))
+	x := make([]Mat4, 128)
+	y := make([]Mat4, len(x))
+	for i := range x {
+		x[i], y[i] = randMat4(r), randMat4(r)
+	}
+	dst := make([]Mat4, len(x))
+	b.SetBytes(int64(len(x) * 128))
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		f(dst, x, y)
+	}
+}
+
+func BenchmarkMulMat4s(b *testing.B) {
+	benchmarkMulMat4s(b, mulMat4s)
+}
+
+func BenchmarkMulMat4sGeneric(b *testing.B) {
+	benchmarkMulMat4s(b, mulMat4sGeneric)
+}
@@ -0,0 +1,115 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lmath
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSphereContains(t *testing.T) {
+	s := Sphere{Center: Vec3{1, 1, 1}, Radius: 2}
+	if !s.Contains(Vec3{2.5, 1, 1}) {
+		t.Fatal("point inside sphere not contained")
+	}
+	if s.Contains(Vec3{3.5, 1, 1}) {
+		t.Fatal("point outside sphere contained")
+	}
+	if !(Sphere{Center: Vec3{1.5, 1, 1}, Radius: 1}).In(s) {
+		t.Fatal("inner sphere not in sphere")
+	}
+	if (Rect3{Min: Vec3{0, 0, 0}, Max: Vec3{2.5, 2.5, 2.5}}).InSphere(s) {
+		t.Fatal("rectangle with corner outside of sphere in sphere")
+	}
+}
+
+func TestRect3Furthest(t *testing.T) {
+	r := Rect3{Min: Vec3{-1, -1, -1}, Max: Vec3{1, 1, 1}}
+	if got := r.Furthest(Vec3{5, -5, 0.5}); got != (Vec3{-1, 1, -1}) {
+		t.Fatal("got", got)
+	}
+}
+
+func TestPlane(t *testing.T) {
+	p, ok := PlaneFromPoints(Vec3{0, 0, 1}, Vec3{1, 0, 1}, Vec3{0, 1, 1})
+	if !ok || !p.Normal.AlmostEquals(Vec3{0, 0, 1}, 1e-9) {
+		t.Fatal("got", p)
+	}
+	if d := p.Distance(Vec3{5, 5, 3}); !AlmostEqual(d, 2, 1e-9) {
+		t.Fatal("distance", d)
+	}
+	tt, ok := Ray{Vec3{0, 0, 5}, Vec3{0, 0, -2}}.IntersectPlane(p)
+	if !ok || !AlmostEqual(tt, 2, 1e-9) {
+		t.Fatal("ray intersection", tt, ok)
+	}
+}
+
+func TestOBB(t *testing.T) {
+	// A unit cube rotated 45 degrees around Z and scaled by 2.
+	m := Mat4FromScale(Vec3{2, 2, 2}).Mul(Mat4FromAxisAngle(Vec3{0, 0, 1}, math.Pi/4, CoordSysZUpRight))
+	b := OBBFromRect3(Rect3{Min: Vec3{-1, -1, -1}, Max: Vec3{1, 1, 1}}, m)
+	if !b.HalfSize.AlmostEquals(Vec3{2, 2, 2}, 1e-9) {
+		t.Fatal("half size", b.HalfSize)
+	}
+	corner := 2 * math.Sqrt2
+	if !b.Contains(Vec3{corner - 0.01, 0, 0}) || b.Contains(Vec3{2.1, 2.1, 0}) {
+		t.Fatal("contains")
+	}
+	if r := b.Rect3(); !AlmostEqual(r.Max.X, corner, 1e-9) || !AlmostEqual(r.Max.Z, 2, 1e-9) {
+		t.Fatal("enclosing rect", r)
+	}
+	if !b.OverlapsSphere(Sphere{Center: Vec3{3, 0, 0}, Radius: 0.5}) {
+		t.Fatal("sphere near corner should overlap")
+	}
+	if b.OverlapsSphere(Sphere{Center: Vec3{2.5, 2.5, 0}, Radius: 0.5}) {
+		t.Fatal("sphere near edge should not overlap")
+	}
+
+	// Boxes whose axis-aligned bounds overlap but which do not.
+	other := OBB{Center: Vec3{3, 3, 0}, Axes: [3]Vec3{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}, HalfSize: Vec3{0.9, 0.9, 1}}
+	if !b.Rect3().Overlaps(other.Rect3()) || b.Overlaps(other) {
+		t.Fatal("separated boxes overlap")
+	}
+	other.Center = Vec3{2.5, 0, 0}
+	if !b.Overlaps(other) || !other.Overlaps(b) {
+		t.Fatal("boxes do not overlap")
+	}
+
+	tt, ok := Ray{Vec3{-10, 0, 0}, Vec3{1, 0, 0}}.IntersectOBB(b)
+	if !ok || !AlmostEqual(tt, 10-corner, 1e-9) {
+		t.Fatal("ray intersection", tt, ok)
+	}
+}
+
+func TestFrustum(t *testing.T) {
+	// A camera at the origin looking down -Z, as in OpenGL.
+	f := FrustumFromMat4(Mat4Perspective(90, 1, 1, 100))
+	if !f.Contains(Vec3{0, 0, -10}) || f.Contains(Vec3{0, 0, 10}) {
+		t.Fatal("contains (forward/behind)")
+	}
+	if f.Contains(Vec3{20, 0, -10}) || f.Contains(Vec3{0, 0, -200}) {
+		t.Fatal("contains (outside/too far)")
+	}
+	if !f.OverlapsSphere(Sphere{Center: Vec3{11, 0, -10}, Radius: 2}) {
+		t.Fatal("sphere crossing the right plane")
+	}
+	if f.OverlapsSphere(Sphere{Center: Vec3{0, 0, 5}, Radius: 2}) {
+		t.Fatal("sphere behind the camera")
+	}
+	if !f.OverlapsRect3(Rect3{Min: Vec3{9, -1, -11}, Max: Vec3{12, 1, -9}}) {
+		t.Fatal("rectangle crossing the right plane")
+	}
+	if f.OverlapsRect3(Rect3{Min: Vec3{-1, -1, 1}, Max: Vec3{1, 1, 3}}) {
+		t.Fatal("rectangle behind the camera")
+	}
+	b := OBBFromRect3(Rect3{Min: Vec3{-1, -1, -1}, Max: Vec3{1, 1, 1}}, Mat4FromTranslation(Vec3{0, 0, -50}))
+	if !f.OverlapsOBB(b) {
+		t.Fatal("box in front of the camera")
+	}
+	b.Center = Vec3{0, 60, -50}
+	if f.OverlapsOBB(b) {
+		t.Fatal("box above the frustum")
+	}
+}
@@ -268,14 +268,19 @@ func SphereToCart(r float64, p Vec2) Vec3 {
 }
 
 // CartToSphere converts the point in cartesian coordinate space, p, into
-// spherical coordinates in the form of Vec3{radiys, inclination, azimuth} and
-// returns it.
+// spherical coordinates in the form of Vec3{radius, inclination, azimuth} and
+// returns it. The azimuth is in the range of [-Pi, Pi].
+//
+// If p is the origin, then a zero vector is returned.
 //
 // It is implemented according to:
 //  http://en.wikipedia.org/wiki/Spherical_coordinate_system#Cartesian_coordinates
 func CartToSphere(p Vec3) Vec3 {
 	r := p.Length()
-	i := math.Acos(p.Z / r)
-	a := math.Atan(p.Y / p.X)
-	return Vec3{r, i, a}
+	if Equal(r, 0) {
+		return Vec3Zero
+	}
+	i := math.Acos(Clamp(p.Z/r, -1, 1))
+	a := math.Atan2(p.Y, p.X)
+	return Vec3{X: r, Y: i, Z: a}
 }
@@ -0,0 +1,234 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package curve
+
+import (
+	"math"
+	"testing"
+
+	"azul3d.org/engine/lmath"
+)
+
+var easeTests = map[string]Func{
+	"Linear":      Linear,
+	"Smoothstep":  Smoothstep,
+	"InQuad":      InQuad,
+	"OutQuad":     OutQuad,
+	"InOutQuad":   InOutQuad,
+	"InCubic":     InCubic,
+	"OutCubic":    OutCubic,
+	"InOutCubic":  InOutCubic,
+	"InQuart":     InQuart,
+	"OutQuart":    OutQuart,
+	"InOutQuart":  InOutQuart,
+	"InSine":      InSine,
+	"OutSine":     OutSine,
+	"InOutSine":   InOutSine,
+	"InExpo":      InExpo,
+	"OutExpo":     OutExpo,
+	"InOutExpo":   InOutExpo,
+	"InBack":      InBack,
+	"OutBack":     OutBack,
+	"InOutBack":   InOutBack,
+	"InElastic":   InElastic,
+	"OutElastic":  OutElastic,
+	"InBounce":    InBounce,
+	"OutBounce":   OutBounce,
+	"CubicBezier": CubicBezier(0.25, 0.1, 0.25, 1),
+}
+
+func TestEaseEndpoints(t *testing.T) {
+	for name, f := range easeTests {
+		if got := f(0); !lmath.AlmostEqual(got, 0, 1e-9) {
+			t.Errorf("%s(0) = %v, want 0", name, got)
+		}
+		if got := f(1); !lmath.AlmostEqual(got, 1, 1e-9) {
+			t.Errorf("%s(1) = %v, want 1", name, got)
+		}
+	}
+}
+
+func TestEaseSymmetric(t *testing.T) {
+	for _, name := range []string{"Smoothstep", "InOutQuad", "InOutCubic", "InOutSine", "InOutExpo", "InOutBack"} {
+		f := easeTests[name]
+		if got := f(0.5); !lmath.AlmostEqual(got, 0.5, 1e-9) {
+			t.Errorf("%s(0.5) = %v, want 0.5", name, got)
+		}
+		for _, x := range []float64{0.1, 0.3} {
+			if a, b := f(x), 1-f(1-x); !lmath.AlmostEqual(a, b, 1e-9) {
+				t.Errorf("%s not symmetric at %v: %v != %v", name, x, a, b)
+			}
+		}
+	}
+}
+
+func TestCubicBezier(t *testing.T) {
+	// A linear curve.
+	f := CubicBezier(1.0/3, 1.0/3, 2.0/3, 2.0/3)
+	for _, x := range []float64{0.1, 0.25, 0.5, 0.9} {
+		if got := f(x); !lmath.AlmostEqual(got, x, 1e-6) {
+			t.Errorf("linear CubicBezier(%v) = %v", x, got)
+		}
+	}
+
+	// The CSS ease-in curve must be monotonic.
+	f = CubicBezier(0.42, 0, 1, 1)
+	last := 0.0
+	for i := 1; i <= 100; i++ {
+		v := f(float64(i) / 100)
+		if v < last {
+			t.Fatalf("CubicBezier not monotonic at %v: %v < %v", float64(i)/100, v, last)
+		}
+		last = v
+	}
+}
+
+func TestBezier(t *testing.T) {
+	b := Bezier{
+		P0: lmath.Vec3{X: 0, Y: 0, Z: 0},
+		P1: lmath.Vec3{X: 1, Y: 2, Z: 0},
+		P2: lmath.Vec3{X: 3, Y: 2, Z: 0},
+		P3: lmath.Vec3{X: 4, Y: 0, Z: 0},
+	}
+	if !b.At(0).Equals(b.P0) || !b.At(1).Equals(b.P3) {
+		t.Fatal("curve does not pass through end points")
+	}
+	if got, want := b.At(0.5), (lmath.Vec3{X: 2, Y: 1.5, Z: 0}); !got.AlmostEquals(want, 1e-9) {
+		t.Errorf("At(0.5) = %v, want %v", got, want)
+	}
+	if got, want := b.Tangent(0), b.P1.Sub(b.P0).MulScalar(3); !got.AlmostEquals(want, 1e-9) {
+		t.Errorf("Tangent(0) = %v, want %v", got, want)
+	}
+
+	left, right := b.Split(0.3)
+	for _, x := range []float64{0, 0.25, 0.5, 1} {
+		if got, want := left.At(x), b.At(x*0.3); !got.AlmostEquals(want, 1e-9) {
+			t.Errorf("left.At(%v) = %v, want %v", x, got, want)
+		}
+		if got, want := right.At(x), b.At(0.3+x*0.7); !got.AlmostEquals(want, 1e-9) {
+			t.Errorf("right.At(%v) = %v, want %v", x, got, want)
+		}
+	}
+
+	// A straight line.
+	line := Bezier{lmath.Vec3{}, lmath.Vec3{X: 1, Y: 0, Z: 0}, lmath.Vec3{X: 2, Y: 0, Z: 0}, lmath.Vec3{X: 3, Y: 0, Z: 0}}
+	if l := line.Length(16); !lmath.AlmostEqual(l, 3, 1e-9) {
+		t.Errorf("Length() = %v, want 3", l)
+	}
+}
+
+func TestCatmullRom(t *testing.T) {
+	c := &CatmullRom{
+		Points: []lmath.Vec3{
+			{X: 0, Y: 0, Z: 0},
+			{X: 1, Y: 1, Z: 0},
+			{X: 2, Y: 0, Z: 0},
+			{X: 3, Y: 1, Z: 0},
+		},
+	}
+	if c.Len() != 3 {
+		t.Fatalf("Len() = %v, want 3", c.Len())
+	}
+	for i, p := range c.Points {
+		if got := c.At(float64(i)); !got.AlmostEquals(p, 1e-9) {
+			t.Errorf("At(%d) = %v, want %v", i, got, p)
+		}
+	}
+
+	// The tangent at an inner point is half the difference of it's
+	// neighbors.
+	if got, want := c.Tangent(1), c.Points[2].Sub(c.Points[0]).MulScalar(0.5); !got.AlmostEquals(want, 1e-9) {
+		t.Errorf("Tangent(1) = %v, want %v", got, want)
+	}
+
+	c.Closed = true
+	if c.Len() != 4 {
+		t.Fatalf("closed Len() = %v, want 4", c.Len())
+	}
+	if got := c.At(4); !got.AlmostEquals(c.Points[0], 1e-9) {
+		t.Errorf("closed At(4) = %v, want %v", got, c.Points[0])
+	}
+}
+
+func TestFloatTrack(t *testing.T) {
+	tr := &FloatTrack{}
+	tr.Insert(FloatKey{Time: 2, Value: 10, Interp: Step})
+	tr.Insert(FloatKey{Time: 0, Value: 0})
+	tr.Insert(FloatKey{Time: 3, Value: 20})
+	if tr.Duration() != 3 {
+		t.Fatalf("Duration() = %v, want 3", tr.Duration())
+	}
+
+	tests := []struct {
+		t, want float64
+	}{
+		{-1, 0},
+		{0, 0},
+		{1, 5},
+		{2, 10},
+		{2.5, 10}, // Step
+		{3, 20},
+		{4, 20},
+	}
+	for _, tst := range tests {
+		if got := tr.At(tst.t); !lmath.AlmostEqual(got, tst.want, 1e-9) {
+			t.Errorf("At(%v) = %v, want %v", tst.t, got, tst.want)
+		}
+	}
+
+	tr.Keys[0].Ease = InQuad
+	if got := tr.At(1); !lmath.AlmostEqual(got, 2.5, 1e-9) {
+		t.Errorf("eased At(1) = %v, want 2.5", got)
+	}
+}
+
+func TestFloatTrackSmooth(t *testing.T) {
+	// Smooth keys on a straight line must remain on the line.
+	tr := &FloatTrack{
+		Keys: []FloatKey{
+			{Time: 0, Value: 0, Interp: Smooth},
+			{Time: 1, Value: 1, Interp: Smooth},
+			{Time: 3, Value: 3, Interp: Smooth},
+			{Time: 4, Value: 4, Interp: Smooth},
+		},
+	}
+	for _, x := range []float64{0.5, 1.5, 2, 3.7} {
+		if got := tr.At(x); !lmath.AlmostEqual(got, x, 1e-9) {
+			t.Errorf("At(%v) = %v, want %v", x, got, x)
+		}
+	}
+}
+
+func TestVec3Track(t *testing.T) {
+	tr := &Vec3Track{
+		Keys: []Vec3Key{
+			{Time: 0, Value: lmath.Vec3{X: 0, Y: 0, Z: 0}},
+			{Time: 2, Value: lmath.Vec3{X: 2, Y: 4, Z: 6}, Interp: Smooth},
+			{Time: 4, Value: lmath.Vec3{X: 4, Y: 8, Z: 12}},
+		},
+	}
+	if got, want := tr.At(1), (lmath.Vec3{X: 1, Y: 2, Z: 3}); !got.AlmostEquals(want, 1e-9) {
+		t.Errorf("At(1) = %v, want %v", got, want)
+	}
+	if got, want := tr.At(3), (lmath.Vec3{X: 3, Y: 6, Z: 9}); !got.AlmostEquals(want, 1e-9) {
+		t.Errorf("At(3) = %v, want %v", got, want)
+	}
+}
+
+func TestQuatTrack(t *testing.T) {
+	axis := lmath.Vec3{X: 0, Y: 0, Z: 1}
+	tr := &QuatTrack{
+		Keys: []QuatKey{
+			{Time: 0, Value: lmath.QuatIdentity},
+			{Time: 1, Value: lmath.QuatFromAxisAngle(axis, math.Pi/2)},
+		},
+	}
+	if got, want := tr.At(0.5), lmath.QuatFromAxisAngle(axis, math.Pi/4); !got.AlmostEquals(want, 1e-9) {
+		t.Errorf("At(0.5) = %v, want %v", got, want)
+	}
+	if got := (&QuatTrack{}).At(1); got != lmath.QuatIdentity {
+		t.Errorf("empty At(1) = %v, want identity", got)
+	}
+}
@@ -0,0 +1,28 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package curve implements easing functions, splines, and keyframed tracks.
+//
+// Easing functions map the linear progress of e.g. an animation in the range
+// of [0, 1] to an eased one:
+//
+//  t = curve.InOutCubic(elapsed / duration)
+//
+// Splines (see Bezier and CatmullRom) describe smooth paths through 3D space,
+// for instance for camera paths.
+//
+// Tracks (see FloatTrack, Vec3Track, and QuatTrack) are sequences of
+// keyframes, which are evaluated at a given time by interpolating between the
+// surrounding keys:
+//
+//  track := &curve.Vec3Track{
+//      Keys: []curve.Vec3Key{
+//          {Time: 0, Value: lmath.Vec3{0, 0, 0}},
+//          {Time: 2, Value: lmath.Vec3{0, 10, 0}, Interp: curve.Smooth},
+//          {Time: 3, Value: lmath.Vec3{5, 10, 0}},
+//      },
+//  }
+//  pos := track.At(1.5)
+//
+package curve
@@ -0,0 +1,185 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package curve
+
+import "math"
+
+// Func is an easing function, it maps the linear progress t in the range of
+// [0, 1] to an eased progress. All of the easing functions in this package
+// return exactly 0 and 1 for t == 0 and t == 1, respectively, but some (e.g.
+// InBack, OutElastic) overshoot the range in between.
+type Func func(t float64) float64
+
+// Linear is the identity easing function, it returns t.
+func Linear(t float64) float64 { return t }
+
+// Smoothstep eases in and out using the smoothstep polynomial 3t² - 2t³.
+func Smoothstep(t float64) float64 { return t * t * (3 - 2*t) }
+
+// InQuad eases in using a quadratic curve.
+func InQuad(t float64) float64 { return t * t }
+
+// OutQuad eases out using a quadratic curve.
+func OutQuad(t float64) float64 { return t * (2 - t) }
+
+// InOutQuad eases in and out using a quadratic curve.
+func InOutQuad(t float64) float64 { return InOut(InQuad)(t) }
+
+// InCubic eases in using a cubic curve.
+func InCubic(t float64) float64 { return t * t * t }
+
+// OutCubic eases out using a cubic curve.
+func OutCubic(t float64) float64 { return Out(InCubic)(t) }
+
+// InOutCubic eases in and out using a cubic curve.
+func InOutCubic(t float64) float64 { return InOut(InCubic)(t) }
+
+// InQuart eases in using a quartic curve.
+func InQuart(t float64) float64 { return t * t * t * t }
+
+// OutQuart eases out using a quartic curve.
+func OutQuart(t float64) float64 { return Out(InQuart)(t) }
+
+// InOutQuart eases in and out using a quartic curve.
+func InOutQuart(t float64) float64 { return InOut(InQuart)(t) }
+
+// InSine eases in using a sine curve.
+func InSine(t float64) float64 { return 1 - math.Cos(t*math.Pi/2) }
+
+// OutSine eases out using a sine curve.
+func OutSine(t float64) float64 { return math.Sin(t * math.Pi / 2) }
+
+// InOutSine eases in and out using a sine curve.
+func InOutSine(t float64) float64 { return (1 - math.Cos(t*math.Pi)) / 2 }
+
+// InExpo eases in using an exponential curve.
+func InExpo(t float64) float64 {
+	if t <= 0 {
+		return 0
+	}
+	return math.Pow(2, 10*(t-1))
+}
+
+// OutExpo eases out using an exponential curve.
+func OutExpo(t float64) float64 { return Out(InExpo)(t) }
+
+// InOutExpo eases in and out using an exponential curve.
+func InOutExpo(t float64) float64 { return InOut(InExpo)(t) }
+
+// backOvershoot is the amount that the back easing functions overshoot by,
+// roughly 10 percent.
+const backOvershoot = 1.70158
+
+// InBack eases in by first moving slightly backwards.
+func InBack(t float64) float64 { return t * t * ((backOvershoot+1)*t - backOvershoot) }
+
+// OutBack eases out by overshooting slightly and then settling.
+func OutBack(t float64) float64 { return Out(InBack)(t) }
+
+// InOutBack eases in and out by moving slightly backwards, and overshooting.
+func InOutBack(t float64) float64 { return InOut(InBack)(t) }
+
+// OutElastic eases out by oscillating around the end, like a spring.
+func OutElastic(t float64) float64 {
+	if t <= 0 || t >= 1 {
+		return t
+	}
+	return math.Pow(2, -10*t)*math.Sin((t-0.075)*(2*math.Pi)/0.3) + 1
+}
+
+// InElastic eases in by oscillating around the start, like a spring.
+func InElastic(t float64) float64 { return Out(OutElastic)(t) }
+
+// OutBounce eases out by bouncing against the end, like a dropped ball.
+func OutBounce(t float64) float64 {
+	const n, d = 7.5625, 2.75
+	switch {
+	case t < 1/d:
+		return n * t * t
+	case t < 2/d:
+		t -= 1.5 / d
+		return n*t*t + 0.75
+	case t < 2.5/d:
+		t -= 2.25 / d
+		return n*t*t + 0.9375
+	default:
+		t -= 2.625 / d
+		return n*t*t + 0.984375
+	}
+}
+
+// InBounce eases in by bouncing against the start.
+func InBounce(t float64) float64 { return Out(OutBounce)(t) }
+
+// Out returns the reverse of the given easing function, i.e. an ease in
+// function becomes an ease out one and vice versa:
+//  Out(f)(t) == 1 - f(1 - t)
+func Out(f Func) Func {
+	return func(t float64) float64 {
+		return 1 - f(1-t)
+	}
+}
+
+// InOut returns an easing function which applies the given ease in function
+// for the first half, and the reverse of it for the second half.
+func InOut(in Func) Func {
+	return func(t float64) float64 {
+		if t < 0.5 {
+			return in(2*t) / 2
+		}
+		return 1 - in(2-2*t)/2
+	}
+}
+
+// CubicBezier returns an easing function described by a cubic Bezier curve
+// from (0, 0) to (1, 1) with the control points (x1, y1) and (x2, y2), like
+// the CSS cubic-bezier() timing function. The X coordinates must be in the
+// range of [0, 1].
+//
+// For example the CSS "ease" timing function is:
+//  CubicBezier(0.25, 0.1, 0.25, 1)
+func CubicBezier(x1, y1, x2, y2 float64) Func {
+	bezier := func(a, b, t float64) float64 {
+		// The curve with the end points 0 and 1.
+		s := 1 - t
+		return 3*s*s*t*a + 3*s*t*t*b + t*t*t
+	}
+	return func(t float64) float64 {
+		if t <= 0 || t >= 1 {
+			return t
+		}
+
+		// Find the curve parameter u whose X coordinate is t using Newton's
+		// method, falling back to bisection where the slope is too small.
+		u := t
+		for i := 0; i < 8; i++ {
+			x := bezier(x1, x2, u) - t
+			if math.Abs(x) < 1e-7 {
+				return bezier(y1, y2, u)
+			}
+			s := 1 - u
+			dx := 3*s*s*x1 + 6*s*u*(x2-x1) + 3*u*u*(1-x2)
+			if math.Abs(dx) < 1e-6 {
+				break
+			}
+			u -= x / dx
+		}
+		lo, hi := 0.0, 1.0
+		u = t
+		for i := 0; i < 64; i++ {
+			x := bezier(x1, x2, u)
+			if math.Abs(x-t) < 1e-7 {
+				break
+			}
+			if x < t {
+				lo = u
+			} else {
+				hi = u
+			}
+			u = (lo + hi) / 2
+		}
+		return bezier(y1, y2, u)
+	}
+}
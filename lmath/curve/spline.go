@@ -0,0 +1,169 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package curve
+
+import (
+	"fmt"
+	"math"
+
+	"azul3d.org/engine/lmath"
+)
+
+// Bezier is a cubic Bezier curve which starts at P0, ends at P3, and is
+// pulled towards the control points P1 and P2.
+type Bezier struct {
+	P0, P1, P2, P3 lmath.Vec3
+}
+
+// String returns a string representation of the curve b.
+func (b Bezier) String() string {
+	return fmt.Sprintf("Bezier(%v, %v, %v, %v)", b.P0, b.P1, b.P2, b.P3)
+}
+
+// At returns the point on the curve at the parameter t, in the range of
+// [0, 1].
+func (b Bezier) At(t float64) lmath.Vec3 {
+	s := 1 - t
+	return b.P0.MulScalar(s * s * s).
+		Add(b.P1.MulScalar(3 * s * s * t)).
+		Add(b.P2.MulScalar(3 * s * t * t)).
+		Add(b.P3.MulScalar(t * t * t))
+}
+
+// Tangent returns the derivative of the curve at the parameter t, in the
+// range of [0, 1]. It is not normalized.
+func (b Bezier) Tangent(t float64) lmath.Vec3 {
+	s := 1 - t
+	return b.P1.Sub(b.P0).MulScalar(3 * s * s).
+		Add(b.P2.Sub(b.P1).MulScalar(6 * s * t)).
+		Add(b.P3.Sub(b.P2).MulScalar(3 * t * t))
+}
+
+// Split splits the curve at the parameter t, in the range of [0, 1], into two
+// curves which together are identical to b (using de Casteljau's algorithm).
+func (b Bezier) Split(t float64) (left, right Bezier) {
+	lerp := func(a, b lmath.Vec3) lmath.Vec3 {
+		return a.Add(b.Sub(a).MulScalar(t))
+	}
+	p01, p12, p23 := lerp(b.P0, b.P1), lerp(b.P1, b.P2), lerp(b.P2, b.P3)
+	p012, p123 := lerp(p01, p12), lerp(p12, p23)
+	p := lerp(p012, p123)
+	return Bezier{b.P0, p01, p012, p}, Bezier{p, p123, p23, b.P3}
+}
+
+// Length returns the approximate arc length of the curve, by summing the
+// lengths of the given number of straight line segments along it.
+func (b Bezier) Length(segments int) float64 {
+	return length(b.At, segments)
+}
+
+// Bounds returns a bounding box that contains the entire curve. It contains
+// the control points and thus is not necessarily tight.
+func (b Bezier) Bounds() lmath.Rect3 {
+	return lmath.Rect3{
+		Min: b.P0.Min(b.P1).Min(b.P2).Min(b.P3),
+		Max: b.P0.Max(b.P1).Max(b.P2).Max(b.P3),
+	}
+}
+
+// CatmullRom is a uniform Catmull-Rom spline, which passes through each of
+// it's points (unlike a Bezier curve, which only passes through it's end
+// points).
+//
+// The spline is parameterized such that each pair of consecutive points is
+// one unit apart, that is the parameter t is in the range of [0, N-1] (or
+// [0, N] if closed) where N is the number of points.
+type CatmullRom struct {
+	// The points of the spline, there must be at least two.
+	Points []lmath.Vec3
+
+	// Whether or not the spline is closed, i.e. the last point connects back
+	// to the first one.
+	Closed bool
+}
+
+// Len returns the length of the parameter range of the spline, i.e. the
+// number of segments it consists of.
+func (c *CatmullRom) Len() float64 {
+	if c.Closed {
+		return float64(len(c.Points))
+	}
+	return float64(len(c.Points) - 1)
+}
+
+// segment returns the four points surrounding the parameter t, and the
+// parameter within their segment.
+func (c *CatmullRom) segment(t float64) (p0, p1, p2, p3 lmath.Vec3, u float64) {
+	n := len(c.Points)
+	t = lmath.Clamp(t, 0, c.Len())
+	i := int(math.Floor(t))
+	if i >= int(c.Len()) {
+		i = int(c.Len()) - 1
+	}
+	u = t - float64(i)
+
+	point := func(i int) lmath.Vec3 {
+		if c.Closed {
+			return c.Points[((i%n)+n)%n]
+		}
+		if i < 0 {
+			// Extrapolate a point before the first one.
+			return c.Points[0].MulScalar(2).Sub(c.Points[1])
+		}
+		if i >= n {
+			// Extrapolate a point after the last one.
+			return c.Points[n-1].MulScalar(2).Sub(c.Points[n-2])
+		}
+		return c.Points[i]
+	}
+	return point(i - 1), point(i), point(i + 1), point(i + 2), u
+}
+
+// At returns the point on the spline at the parameter t, in the range of
+// [0, c.Len()].
+func (c *CatmullRom) At(t float64) lmath.Vec3 {
+	p0, p1, p2, p3, u := c.segment(t)
+	u2, u3 := u*u, u*u*u
+	return p1.MulScalar(2).
+		Add(p2.Sub(p0).MulScalar(u)).
+		Add(p0.MulScalar(2).Sub(p1.MulScalar(5)).Add(p2.MulScalar(4)).Sub(p3).MulScalar(u2)).
+		Add(p1.MulScalar(3).Sub(p0).Sub(p2.MulScalar(3)).Add(p3).MulScalar(u3)).
+		MulScalar(0.5)
+}
+
+// Tangent returns the derivative of the spline at the parameter t, in the
+// range of [0, c.Len()]. It is not normalized.
+func (c *CatmullRom) Tangent(t float64) lmath.Vec3 {
+	p0, p1, p2, p3, u := c.segment(t)
+	return p2.Sub(p0).
+		Add(p0.MulScalar(2).Sub(p1.MulScalar(5)).Add(p2.MulScalar(4)).Sub(p3).MulScalar(2 * u)).
+		Add(p1.MulScalar(3).Sub(p0).Sub(p2.MulScalar(3)).Add(p3).MulScalar(3 * u * u)).
+		MulScalar(0.5)
+}
+
+// Length returns the approximate arc length of the spline, by summing the
+// lengths of the given number of straight line segments per point along it.
+func (c *CatmullRom) Length(segments int) float64 {
+	n := int(c.Len())
+	return length(func(t float64) lmath.Vec3 {
+		return c.At(t * float64(n))
+	}, segments*n)
+}
+
+// length approximates the arc length of the given curve in the parameter
+// range of [0, 1] using the given number of straight line segments.
+func length(at func(t float64) lmath.Vec3, segments int) float64 {
+	if segments < 1 {
+		segments = 1
+	}
+	var l float64
+	last := at(0)
+	for i := 1; i <= segments; i++ {
+		p := at(float64(i) / float64(segments))
+		l += p.Sub(last).Length()
+		last = p
+	}
+	return l
+}
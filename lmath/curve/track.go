@@ -0,0 +1,312 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package curve
+
+import (
+	"sort"
+
+	"azul3d.org/engine/lmath"
+)
+
+// Interp is the interpolation mode used between a key and the next one.
+type Interp uint8
+
+const (
+	// Lerp interpolates linearly (spherically, for quaternions).
+	Lerp Interp = iota
+
+	// Step holds the value of the key until the next key.
+	Step
+
+	// Smooth interpolation, using a cubic Hermite (Catmull-Rom) curve through
+	// the surrounding keys. Quaternion tracks interpolate smooth keys
+	// spherically, as with Lerp.
+	Smooth
+)
+
+// Track is the interface implemented by each type of track.
+type Track interface {
+	// Len returns the number of keys in the track.
+	Len() int
+
+	// Time returns the time of the i'th key.
+	Time(i int) float64
+
+	// Duration returns the time of the last key, or zero if the track has no
+	// keys.
+	Duration() float64
+}
+
+// find locates the keys surrounding the time t in the given track. It
+// returns the index of the key before t, and the progress from it to the next
+// key (i+1) in the range of [0, 1], eased by the given function. If t is
+// outside of the track then the first or last key is returned with a progress
+// of zero.
+//
+// The track must have at least one key.
+func find(tr Track, t float64, ease func(i int) Func) (i int, u float64) {
+	n := tr.Len()
+	if t <= tr.Time(0) {
+		return 0, 0
+	}
+	if t >= tr.Time(n-1) {
+		return n - 1, 0
+	}
+	i = sort.Search(n, func(i int) bool {
+		return tr.Time(i) > t
+	}) - 1
+	t0, t1 := tr.Time(i), tr.Time(i+1)
+	u = (t - t0) / (t1 - t0)
+	if f := ease(i); f != nil {
+		u = f(u)
+	}
+	return i, u
+}
+
+// hermite evaluates the cubic Hermite curve between p1 and p2 at u, using the
+// Catmull-Rom tangents of the surrounding (non-uniformly spaced in time)
+// values p0 and p3.
+func hermite(p0, p1, p2, p3, t0, t1, t2, t3, u float64) float64 {
+	dt := t2 - t1
+	m1 := (p2 - p0) / (t2 - t0) * dt
+	m2 := (p3 - p1) / (t3 - t1) * dt
+	u2, u3 := u*u, u*u*u
+	return (2*u3-3*u2+1)*p1 + (u3-2*u2+u)*m1 + (-2*u3+3*u2)*p2 + (u3-u2)*m2
+}
+
+// neighbors returns the indices of the keys surrounding the segment from i to
+// i+1, clamped to the track.
+func neighbors(i, n int) (prev, next int) {
+	prev, next = i-1, i+2
+	if prev < 0 {
+		prev = i
+	}
+	if next >= n {
+		next = i + 1
+	}
+	return
+}
+
+// hermiteTimes returns the key times used by hermite for the segment from i
+// to i+1, avoiding division by zero at the ends of the track.
+func hermiteTimes(tr Track, i, prev, next int) (t0, t1, t2, t3 float64) {
+	t0, t1, t2, t3 = tr.Time(prev), tr.Time(i), tr.Time(i+1), tr.Time(next)
+	if prev == i {
+		t0 = t1 - (t2 - t1)
+	}
+	if next == i+1 {
+		t3 = t2 + (t2 - t1)
+	}
+	return
+}
+
+// FloatKey is a single keyframe of a FloatTrack.
+type FloatKey struct {
+	// The time of the key, and it's value.
+	Time, Value float64
+
+	// The interpolation mode to the next key.
+	Interp Interp
+
+	// An optional easing function applied to the progress towards the next
+	// key (nil for none).
+	Ease Func
+}
+
+// FloatTrack is a keyframed track of float64 values. The keys must be sorted
+// by time (see Insert).
+type FloatTrack struct {
+	Keys []FloatKey
+}
+
+// Len implements the Track interface.
+func (tr *FloatTrack) Len() int { return len(tr.Keys) }
+
+// Time implements the Track interface.
+func (tr *FloatTrack) Time(i int) float64 { return tr.Keys[i].Time }
+
+// Duration implements the Track interface.
+func (tr *FloatTrack) Duration() float64 {
+	if len(tr.Keys) == 0 {
+		return 0
+	}
+	return tr.Keys[len(tr.Keys)-1].Time
+}
+
+// Insert inserts the given key into the track, maintaining the order of the
+// keys by time.
+func (tr *FloatTrack) Insert(k FloatKey) {
+	i := sort.Search(len(tr.Keys), func(i int) bool {
+		return tr.Keys[i].Time > k.Time
+	})
+	tr.Keys = append(tr.Keys, FloatKey{})
+	copy(tr.Keys[i+1:], tr.Keys[i:])
+	tr.Keys[i] = k
+}
+
+// At returns the value of the track at the time t. Before the first key and
+// after the last key, the value of the respective key is returned. If the
+// track has no keys, zero is returned.
+func (tr *FloatTrack) At(t float64) float64 {
+	if len(tr.Keys) == 0 {
+		return 0
+	}
+	i, u := find(tr, t, func(i int) Func { return tr.Keys[i].Ease })
+	k := tr.Keys[i]
+	if u == 0 || k.Interp == Step {
+		return k.Value
+	}
+	next := tr.Keys[i+1]
+	if k.Interp == Smooth {
+		prev, n := neighbors(i, len(tr.Keys))
+		t0, t1, t2, t3 := hermiteTimes(tr, i, prev, n)
+		p0, p3 := tr.Keys[prev].Value, tr.Keys[n].Value
+		if prev == i {
+			p0 = 2*k.Value - next.Value
+		}
+		if n == i+1 {
+			p3 = 2*next.Value - k.Value
+		}
+		return hermite(p0, k.Value, next.Value, p3, t0, t1, t2, t3, u)
+	}
+	return lmath.Lerp(k.Value, next.Value, u)
+}
+
+// Vec3Key is a single keyframe of a Vec3Track.
+type Vec3Key struct {
+	// The time of the key, and it's value.
+	Time  float64
+	Value lmath.Vec3
+
+	// The interpolation mode to the next key.
+	Interp Interp
+
+	// An optional easing function applied to the progress towards the next
+	// key (nil for none).
+	Ease Func
+}
+
+// Vec3Track is a keyframed track of 3-component vectors (e.g. positions,
+// scales, or RGB colors). The keys must be sorted by time (see Insert).
+type Vec3Track struct {
+	Keys []Vec3Key
+}
+
+// Len implements the Track interface.
+func (tr *Vec3Track) Len() int { return len(tr.Keys) }
+
+// Time implements the Track interface.
+func (tr *Vec3Track) Time(i int) float64 { return tr.Keys[i].Time }
+
+// Duration implements the Track interface.
+func (tr *Vec3Track) Duration() float64 {
+	if len(tr.Keys) == 0 {
+		return 0
+	}
+	return tr.Keys[len(tr.Keys)-1].Time
+}
+
+// Insert inserts the given key into the track, maintaining the order of the
+// keys by time.
+func (tr *Vec3Track) Insert(k Vec3Key) {
+	i := sort.Search(len(tr.Keys), func(i int) bool {
+		return tr.Keys[i].Time > k.Time
+	})
+	tr.Keys = append(tr.Keys, Vec3Key{})
+	copy(tr.Keys[i+1:], tr.Keys[i:])
+	tr.Keys[i] = k
+}
+
+// At returns the value of the track at the time t. Before the first key and
+// after the last key, the value of the respective key is returned. If the
+// track has no keys, a zero vector is returned.
+func (tr *Vec3Track) At(t float64) lmath.Vec3 {
+	if len(tr.Keys) == 0 {
+		return lmath.Vec3Zero
+	}
+	i, u := find(tr, t, func(i int) Func { return tr.Keys[i].Ease })
+	k := tr.Keys[i]
+	if u == 0 || k.Interp == Step {
+		return k.Value
+	}
+	next := tr.Keys[i+1]
+	if k.Interp == Smooth {
+		prev, n := neighbors(i, len(tr.Keys))
+		t0, t1, t2, t3 := hermiteTimes(tr, i, prev, n)
+		p0, p3 := tr.Keys[prev].Value, tr.Keys[n].Value
+		if prev == i {
+			p0 = k.Value.MulScalar(2).Sub(next.Value)
+		}
+		if n == i+1 {
+			p3 = next.Value.MulScalar(2).Sub(k.Value)
+		}
+		return lmath.Vec3{
+			X: hermite(p0.X, k.Value.X, next.Value.X, p3.X, t0, t1, t2, t3, u),
+			Y: hermite(p0.Y, k.Value.Y, next.Value.Y, p3.Y, t0, t1, t2, t3, u),
+			Z: hermite(p0.Z, k.Value.Z, next.Value.Z, p3.Z, t0, t1, t2, t3, u),
+		}
+	}
+	return k.Value.Add(next.Value.Sub(k.Value).MulScalar(u))
+}
+
+// QuatKey is a single keyframe of a QuatTrack.
+type QuatKey struct {
+	// The time of the key, and it's value (a unit quaternion).
+	Time  float64
+	Value lmath.Quat
+
+	// The interpolation mode to the next key.
+	Interp Interp
+
+	// An optional easing function applied to the progress towards the next
+	// key (nil for none).
+	Ease Func
+}
+
+// QuatTrack is a keyframed track of rotations. The keys must be sorted by
+// time (see Insert).
+type QuatTrack struct {
+	Keys []QuatKey
+}
+
+// Len implements the Track interface.
+func (tr *QuatTrack) Len() int { return len(tr.Keys) }
+
+// Time implements the Track interface.
+func (tr *QuatTrack) Time(i int) float64 { return tr.Keys[i].Time }
+
+// Duration implements the Track interface.
+func (tr *QuatTrack) Duration() float64 {
+	if len(tr.Keys) == 0 {
+		return 0
+	}
+	return tr.Keys[len(tr.Keys)-1].Time
+}
+
+// Insert inserts the given key into the track, maintaining the order of the
+// keys by time.
+func (tr *QuatTrack) Insert(k QuatKey) {
+	i := sort.Search(len(tr.Keys), func(i int) bool {
+		return tr.Keys[i].Time > k.Time
+	})
+	tr.Keys = append(tr.Keys, QuatKey{})
+	copy(tr.Keys[i+1:], tr.Keys[i:])
+	tr.Keys[i] = k
+}
+
+// At returns the rotation of the track at the time t. Before the first key
+// and after the last key, the rotation of the respective key is returned. If
+// the track has no keys, the identity quaternion is returned.
+func (tr *QuatTrack) At(t float64) lmath.Quat {
+	if len(tr.Keys) == 0 {
+		return lmath.QuatIdentity
+	}
+	i, u := find(tr, t, func(i int) Func { return tr.Keys[i].Ease })
+	k := tr.Keys[i]
+	if u == 0 || k.Interp == Step {
+		return k.Value
+	}
+	return k.Value.Slerp(tr.Keys[i+1].Value, u)
+}
@@ -0,0 +1,158 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lmath
+
+import "fmt"
+
+// Vec2f is a 32-bit floating point mirror of Vec2, for compact storage and
+// uploading to graphics hardware. Use Vec2 for any math.
+type Vec2f struct {
+	X, Y float32
+}
+
+// String returns an string representation of this vector.
+func (a Vec2f) String() string {
+	return fmt.Sprintf("Vec2f(X=%f, Y=%f)", a.X, a.Y)
+}
+
+// Vec2 converts this 32-bit vector to a 64-bit Vec2.
+func (a Vec2f) Vec2() Vec2 {
+	return Vec2{X: float64(a.X), Y: float64(a.Y)}
+}
+
+// Vec2f converts this vector to a 32-bit Vec2f.
+func (a Vec2) Vec2f() Vec2f {
+	return Vec2f{X: float32(a.X), Y: float32(a.Y)}
+}
+
+// Vec3f is a 32-bit floating point mirror of Vec3, for compact storage and
+// uploading to graphics hardware. Use Vec3 for any math.
+type Vec3f struct {
+	X, Y, Z float32
+}
+
+// String returns an string representation of this vector.
+func (a Vec3f) String() string {
+	return fmt.Sprintf("Vec3f(X=%f, Y=%f, Z=%f)", a.X, a.Y, a.Z)
+}
+
+// Vec3 converts this 32-bit vector to a 64-bit Vec3.
+func (a Vec3f) Vec3() Vec3 {
+	return Vec3{X: float64(a.X), Y: float64(a.Y), Z: float64(a.Z)}
+}
+
+// Vec3f converts this vector to a 32-bit Vec3f.
+func (a Vec3) Vec3f() Vec3f {
+	return Vec3f{X: float32(a.X), Y: float32(a.Y), Z: float32(a.Z)}
+}
+
+// Vec4f is a 32-bit floating point mirror of Vec4, for compact storage and
+// uploading to graphics hardware. Use Vec4 for any math.
+type Vec4f struct {
+	X, Y, Z, W float32
+}
+
+// String returns an string representation of this vector.
+func (a Vec4f) String() string {
+	return fmt.Sprintf("Vec4f(X=%f, Y=%f, Z=%f, W=%f)", a.X, a.Y, a.Z, a.W)
+}
+
+// Vec4 converts this 32-bit vector to a 64-bit Vec4.
+func (a Vec4f) Vec4() Vec4 {
+	return Vec4{X: float64(a.X), Y: float64(a.Y), Z: float64(a.Z), W: float64(a.W)}
+}
+
+// Vec4f converts this vector to a 32-bit Vec4f.
+func (a Vec4) Vec4f() Vec4f {
+	return Vec4f{X: float32(a.X), Y: float32(a.Y), Z: float32(a.Z), W: float32(a.W)}
+}
+
+// Quatf is a 32-bit floating point mirror of Quat, for compact storage and
+// uploading to graphics hardware. Use Quat for any math.
+//
+// Note that like Quat the W (real) component comes first in memory, so as a
+// GLSL vec4 the components are (W, X, Y, Z).
+type Quatf struct {
+	W, X, Y, Z float32
+}
+
+// String returns an string representation of this quaternion.
+func (a Quatf) String() string {
+	return fmt.Sprintf("Quatf(W=%f, X=%f, Y=%f, Z=%f)", a.W, a.X, a.Y, a.Z)
+}
+
+// Quat converts this 32-bit quaternion to a 64-bit Quat.
+func (a Quatf) Quat() Quat {
+	return Quat{W: float64(a.W), X: float64(a.X), Y: float64(a.Y), Z: float64(a.Z)}
+}
+
+// Quatf converts this quaternion to a 32-bit Quatf.
+func (a Quat) Quatf() Quatf {
+	return Quatf{W: float32(a.W), X: float32(a.X), Y: float32(a.Y), Z: float32(a.Z)}
+}
+
+// Mat4f is a 32-bit floating point mirror of Mat4, for compact storage and
+// uploading to graphics hardware. Use Mat4 for any math.
+type Mat4f [4][4]float32
+
+// String returns an string representation of this matrix.
+func (a Mat4f) String() string {
+	return a.Mat4().String()
+}
+
+// Mat4 converts this 32-bit matrix to a 64-bit Mat4.
+func (a Mat4f) Mat4() Mat4 {
+	var m Mat4
+	for r := range a {
+		for c := range a[r] {
+			m[r][c] = float64(a[r][c])
+		}
+	}
+	return m
+}
+
+// Mat4f converts this matrix to a 32-bit Mat4f.
+func (a Mat4) Mat4f() Mat4f {
+	var m Mat4f
+	for r := range a {
+		for c := range a[r] {
+			m[r][c] = float32(a[r][c])
+		}
+	}
+	return m
+}
+
+// Vec3fSlice converts each vector in src to a 32-bit Vec3f, storing them in
+// dst (which is grown as needed) and returning it. Reusing dst between calls
+// avoids allocating each time the data is uploaded.
+func Vec3fSlice(dst []Vec3f, src []Vec3) []Vec3f {
+	dst = dst[:0]
+	for _, v := range src {
+		dst = append(dst, v.Vec3f())
+	}
+	return dst
+}
+
+// Vec4fSlice converts each vector in src to a 32-bit Vec4f, storing them in
+// dst (which is grown as needed) and returning it. Reusing dst between calls
+// avoids allocating each time the data is uploaded.
+func Vec4fSlice(dst []Vec4f, src []Vec4) []Vec4f {
+	dst = dst[:0]
+	for _, v := range src {
+		dst = append(dst, v.Vec4f())
+	}
+	return dst
+}
+
+// Mat4fSlice converts each matrix in src to a 32-bit Mat4f, storing them in
+// dst (which is grown as needed) and returning it. Reusing dst between calls
+// avoids allocating each time the data is uploaded (e.g. skinning matrices).
+func Mat4fSlice(dst []Mat4f, src []Mat4) []Mat4f {
+	dst = dst[:0]
+	for _, m := range src {
+		dst = append(dst, m.Mat4f())
+	}
+	return dst
+}
@@ -0,0 +1,47 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lmath
+
+import "testing"
+
+func TestFloat32RoundTrip(t *testing.T) {
+	v3 := Vec3{1.5, -2, 3.25}
+	if got := v3.Vec3f().Vec3(); got != v3 {
+		t.Fatal("Vec3:", got)
+	}
+	v4 := Vec4{1, 2, 3, 4}
+	if got := v4.Vec4f().Vec4(); got != v4 {
+		t.Fatal("Vec4:", got)
+	}
+	q := Quat{1, 0.5, -0.5, 0.25}
+	if got := q.Quatf().Quat(); got != q {
+		t.Fatal("Quat:", got)
+	}
+	m := Mat4FromTranslation(Vec3{1, 2, 3})
+	if got := m.Mat4f().Mat4(); got != m {
+		t.Fatal("Mat4:", got)
+	}
+}
+
+func TestMat4fSlice(t *testing.T) {
+	src := []Mat4{Mat4Identity, Mat4FromTranslation(Vec3{1, 2, 3})}
+	buf := make([]Mat4f, 0, 4)
+	dst := Mat4fSlice(buf, src)
+	if len(dst) != 2 || &dst[0] != &buf[:1][0] {
+		t.Fatal("did not reuse dst")
+	}
+	if dst[1][3][0] != 1 {
+		t.Fatal("bad conversion:", dst[1])
+	}
+}
+
+func BenchmarkMat4fSlice(b *testing.B) {
+	src := make([]Mat4, 64)
+	var dst []Mat4f
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		dst = Mat4fSlice(dst, src)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lmath
+
+import "math"
+
+// Frustum describes a 3D viewing frustum as six planes whose normals face
+// inwards, in the order: left, right, bottom, top, near, far.
+type Frustum [6]Plane
+
+// FrustumFromMat4 returns the frustum of the given view-projection matrix
+// (which transforms world space points into OpenGL style clip space, using
+// vector * matrix multiplication). The planes are normalized.
+func FrustumFromMat4(m Mat4) Frustum {
+	// Fast Extraction of Viewing Frustum Planes from the World-View-Projection
+	// Matrix, Gribb & Hartmann. With row vectors each clip space component is
+	// the dot product of the point with a column of the matrix.
+	col := func(j int) Plane {
+		return Plane{Normal: Vec3{m[0][j], m[1][j], m[2][j]}, D: m[3][j]}
+	}
+	add := func(a, b Plane) Plane {
+		return Plane{Normal: a.Normal.Add(b.Normal), D: a.D + b.D}
+	}
+	sub := func(a, b Plane) Plane {
+		return Plane{Normal: a.Normal.Sub(b.Normal), D: a.D - b.D}
+	}
+	x, y, z, w := col(0), col(1), col(2), col(3)
+	f := Frustum{
+		add(w, x), // left
+		sub(w, x), // right
+		add(w, y), // bottom
+		sub(w, y), // top
+		add(w, z), // near
+		sub(w, z), // far
+	}
+	for i, p := range f {
+		f[i], _ = p.Normalized()
+	}
+	return f
+}
+
+// Contains tells if the point p is within the frustum.
+func (f Frustum) Contains(p Vec3) bool {
+	for _, plane := range f {
+		if plane.Distance(p) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// OverlapsSphere reports whether the sphere s may intersect the frustum. Like
+// all frustum overlap tests it is conservative: it may report a few spheres
+// near the frustum's corners as overlapping when they are not, which is
+// acceptable for culling.
+func (f Frustum) OverlapsSphere(s Sphere) bool {
+	for _, plane := range f {
+		if plane.Distance(s.Center) < -s.Radius {
+			return false
+		}
+	}
+	return true
+}
+
+// OverlapsRect3 reports whether the rectangle r may intersect the frustum (see
+// OverlapsSphere).
+func (f Frustum) OverlapsRect3(r Rect3) bool {
+	for _, plane := range f {
+		// Test the corner furthest along the plane's normal (the "positive
+		// vertex"), if it is behind the plane so is the whole rectangle.
+		p := r.Min
+		if plane.Normal.X >= 0 {
+			p.X = r.Max.X
+		}
+		if plane.Normal.Y >= 0 {
+			p.Y = r.Max.Y
+		}
+		if plane.Normal.Z >= 0 {
+			p.Z = r.Max.Z
+		}
+		if plane.Distance(p) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// OverlapsOBB reports whether the box b may intersect the frustum (see
+// OverlapsSphere).
+func (f Frustum) OverlapsOBB(b OBB) bool {
+	for _, plane := range f {
+		// The projected radius of the box onto the plane's normal.
+		r := 0.0
+		for i, axis := range b.Axes {
+			r += b.halfSize(i) * math.Abs(plane.Normal.Dot(axis))
+		}
+		if plane.Distance(b.Center) < -r {
+			return false
+		}
+	}
+	return true
+}
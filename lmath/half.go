@@ -0,0 +1,223 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lmath
+
+import (
+	"fmt"
+	"math"
+)
+
+// Half is a 16-bit (IEEE 754 binary16) floating point number, for compact
+// storage and uploading to graphics hardware (e.g. as vertex attributes or HDR
+// texture data). It has roughly three decimal digits of precision and a
+// maximum value of 65504. Use float64 for any math.
+type Half uint16
+
+// HalfFromFloat32 converts the given 32-bit floating point number to the
+// nearest 16-bit one (rounding ties to even). Values too large to represent
+// become infinity, and values too small become zero (or subnormal numbers).
+func HalfFromFloat32(f float32) Half {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int(bits>>23) & 0xff
+	mant := bits & 0x7fffff
+
+	if exp == 0xff {
+		if mant != 0 {
+			// NaN, keep it quiet.
+			return Half(sign | 0x7e00)
+		}
+		return Half(sign | 0x7c00) // Infinity.
+	}
+
+	e := exp - 127 + 15
+	switch {
+	case e >= 0x1f:
+		// Overflow, becomes infinity.
+		return Half(sign | 0x7c00)
+
+	case e <= 0:
+		// Subnormal, or too small to represent (rounds to zero).
+		if e < -10 {
+			return Half(sign)
+		}
+		mant |= 0x800000 // Implicit leading bit.
+		shift := uint(14 - e)
+		h := mant >> shift
+		rem := mant & (1<<shift - 1)
+		halfway := uint32(1) << (shift - 1)
+		if rem > halfway || (rem == halfway && h&1 != 0) {
+			h++
+		}
+		return Half(sign | uint16(h))
+	}
+
+	// Normal number. If rounding carries into the exponent the result is
+	// still correct (or becomes infinity).
+	h := uint32(e)<<10 | mant>>13
+	rem := mant & 0x1fff
+	if rem > 0x1000 || (rem == 0x1000 && h&1 != 0) {
+		h++
+	}
+	return Half(sign | uint16(h))
+}
+
+// HalfFromFloat64 is short-hand for:
+//  HalfFromFloat32(float32(f))
+func HalfFromFloat64(f float64) Half {
+	return HalfFromFloat32(float32(f))
+}
+
+// Float32 converts the 16-bit floating point number to a 32-bit one, the
+// conversion is exact.
+func (h Half) Float32() float32 {
+	sign := uint32(h&0x8000) << 16
+	exp := uint32(h>>10) & 0x1f
+	mant := uint32(h & 0x3ff)
+	switch exp {
+	case 0:
+		if mant == 0 {
+			return math.Float32frombits(sign) // Zero.
+		}
+
+		// Subnormal, normalize it.
+		e := uint32(127 - 15 + 1)
+		for mant&0x400 == 0 {
+			mant <<= 1
+			e--
+		}
+		mant &= 0x3ff
+		return math.Float32frombits(sign | e<<23 | mant<<13)
+
+	case 0x1f:
+		// Infinity or NaN.
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	}
+	return math.Float32frombits(sign | (exp+127-15)<<23 | mant<<13)
+}
+
+// Float64 converts the 16-bit floating point number to a 64-bit one.
+func (h Half) Float64() float64 {
+	return float64(h.Float32())
+}
+
+// String returns an string representation of this number.
+func (h Half) String() string {
+	return fmt.Sprintf("%f", h.Float32())
+}
+
+// Vec2h is a 16-bit floating point mirror of Vec2, for compact storage and
+// uploading to graphics hardware. Use Vec2 for any math.
+type Vec2h struct {
+	X, Y Half
+}
+
+// String returns an string representation of this vector.
+func (a Vec2h) String() string {
+	return fmt.Sprintf("Vec2h(X=%v, Y=%v)", a.X, a.Y)
+}
+
+// Vec2 converts this 16-bit vector to a 64-bit Vec2.
+func (a Vec2h) Vec2() Vec2 {
+	return Vec2{X: a.X.Float64(), Y: a.Y.Float64()}
+}
+
+// Vec2h converts this vector to a 16-bit Vec2h.
+func (a Vec2) Vec2h() Vec2h {
+	return Vec2h{X: HalfFromFloat64(a.X), Y: HalfFromFloat64(a.Y)}
+}
+
+// Vec3h is a 16-bit floating point mirror of Vec3, for compact storage and
+// uploading to graphics hardware. Use Vec3 for any math.
+type Vec3h struct {
+	X, Y, Z Half
+}
+
+// String returns an string representation of this vector.
+func (a Vec3h) String() string {
+	return fmt.Sprintf("Vec3h(X=%v, Y=%v, Z=%v)", a.X, a.Y, a.Z)
+}
+
+// Vec3 converts this 16-bit vector to a 64-bit Vec3.
+func (a Vec3h) Vec3() Vec3 {
+	return Vec3{X: a.X.Float64(), Y: a.Y.Float64(), Z: a.Z.Float64()}
+}
+
+// Vec3h converts this vector to a 16-bit Vec3h.
+func (a Vec3) Vec3h() Vec3h {
+	return Vec3h{X: HalfFromFloat64(a.X), Y: HalfFromFloat64(a.Y), Z: HalfFromFloat64(a.Z)}
+}
+
+// Vec4h is a 16-bit floating point mirror of Vec4, for compact storage and
+// uploading to graphics hardware. Use Vec4 for any math.
+type Vec4h struct {
+	X, Y, Z, W Half
+}
+
+// String returns an string representation of this vector.
+func (a Vec4h) String() string {
+	return fmt.Sprintf("Vec4h(X=%v, Y=%v, Z=%v, W=%v)", a.X, a.Y, a.Z, a.W)
+}
+
+// Vec4 converts this 16-bit vector to a 64-bit Vec4.
+func (a Vec4h) Vec4() Vec4 {
+	return Vec4{X: a.X.Float64(), Y: a.Y.Float64(), Z: a.Z.Float64(), W: a.W.Float64()}
+}
+
+// Vec4h converts this vector to a 16-bit Vec4h.
+func (a Vec4) Vec4h() Vec4h {
+	return Vec4h{X: HalfFromFloat64(a.X), Y: HalfFromFloat64(a.Y), Z: HalfFromFloat64(a.Z), W: HalfFromFloat64(a.W)}
+}
+
+// HalfSlice converts each number in src to a 16-bit Half, storing them in dst
+// (which is grown as needed) and returning it. Reusing dst between calls
+// avoids allocating each time the data is uploaded.
+func HalfSlice(dst []Half, src []float32) []Half {
+	dst = dst[:0]
+	for _, f := range src {
+		dst = append(dst, HalfFromFloat32(f))
+	}
+	return dst
+}
+
+// Float32Slice converts each 16-bit number in src to a float32, storing them
+// in dst (which is grown as needed) and returning it.
+func Float32Slice(dst []float32, src []Half) []float32 {
+	dst = dst[:0]
+	for _, h := range src {
+		dst = append(dst, h.Float32())
+	}
+	return dst
+}
+
+// Vec2hSlice converts each vector in src to a 16-bit Vec2h, storing them in
+// dst (which is grown as needed) and returning it.
+func Vec2hSlice(dst []Vec2h, src []Vec2) []Vec2h {
+	dst = dst[:0]
+	for _, v := range src {
+		dst = append(dst, v.Vec2h())
+	}
+	return dst
+}
+
+// Vec3hSlice converts each vector in src to a 16-bit Vec3h, storing them in
+// dst (which is grown as needed) and returning it.
+func Vec3hSlice(dst []Vec3h, src []Vec3) []Vec3h {
+	dst = dst[:0]
+	for _, v := range src {
+		dst = append(dst, v.Vec3h())
+	}
+	return dst
+}
+
+// Vec4hSlice converts each vector in src to a 16-bit Vec4h, storing them in
+// dst (which is grown as needed) and returning it.
+func Vec4hSlice(dst []Vec4h, src []Vec4) []Vec4h {
+	dst = dst[:0]
+	for _, v := range src {
+		dst = append(dst, v.Vec4h())
+	}
+	return dst
+}
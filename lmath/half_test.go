@@ -0,0 +1,86 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lmath
+
+import (
+	"math"
+	"testing"
+)
+
+var halfTests = []struct {
+	f float32
+	h Half
+}{
+	{0, 0x0000},
+	{float32(math.Copysign(0, -1)), 0x8000},
+	{1, 0x3c00},
+	{-2, 0xc000},
+	{0.5, 0x3800},
+	{65504, 0x7bff},                              // Largest normal.
+	{6.103515625e-05, 0x0400},                    // Smallest normal.
+	{5.960464477539063e-08, 0x0001},              // Smallest subnormal.
+	{float32(math.Inf(1)), 0x7c00},               // Infinity.
+	{float32(math.Inf(-1)), 0xfc00},              // -Infinity.
+	{1e6, 0x7c00},                                // Overflow.
+	{1e-9, 0x0000},                               // Underflow.
+	{1 + 1.0/2048, 0x3c00},                       // Tie, rounds to even (down).
+	{1 + 3.0/2048, 0x3c02},                       // Tie, rounds to even (up).
+	{65520, 0x7c00},                              // Rounds up to infinity.
+	{2.98023223876953125e-08 * 3, 0x0002},        // Subnormal tie, rounds to even.
+	{0.333251953125, 0x3555},                     // Exact.
+	{0.1, 0x2e66},                                // Rounded.
+	{-0.0001, 0x868e},                            // Rounded, negative.
+	{6.097555160522461e-05, 0x03ff},              // Largest subnormal.
+	{5.960464477539063e-08 / 2 * 1.0001, 0x0001}, // Just above half the smallest subnormal.
+}
+
+func TestHalfFromFloat32(t *testing.T) {
+	for _, tst := range halfTests {
+		if got := HalfFromFloat32(tst.f); got != tst.h {
+			t.Errorf("HalfFromFloat32(%v) = %#04x, want %#04x", tst.f, uint16(got), uint16(tst.h))
+		}
+	}
+	if h := HalfFromFloat32(float32(math.NaN())); h&0x7c00 != 0x7c00 || h&0x3ff == 0 {
+		t.Errorf("HalfFromFloat32(NaN) = %#04x, not a NaN", uint16(h))
+	}
+}
+
+func TestHalfFloat32(t *testing.T) {
+	// Every finite half must convert to a float32 and back exactly.
+	for i := 0; i < 1<<16; i++ {
+		h := Half(i)
+		f := h.Float32()
+		if math.IsNaN(float64(f)) {
+			if h&0x7c00 != 0x7c00 || h&0x3ff == 0 {
+				t.Fatalf("%#04x.Float32() = NaN", i)
+			}
+			continue
+		}
+		if back := HalfFromFloat32(f); back != h {
+			t.Fatalf("HalfFromFloat32(%#04x.Float32() = %v) = %#04x", i, f, uint16(back))
+		}
+	}
+}
+
+func TestHalfVec(t *testing.T) {
+	v := Vec4{X: 1, Y: -0.5, Z: 1024, W: 0.25}
+	if got := v.Vec4h().Vec4(); got != v {
+		t.Errorf("Vec4h round trip = %v, want %v", got, v)
+	}
+	s := Vec3hSlice(nil, []Vec3{{X: 1, Y: 2, Z: 3}, {X: 4, Y: 5, Z: 6}})
+	if len(s) != 2 || s[1].Vec3() != (Vec3{X: 4, Y: 5, Z: 6}) {
+		t.Errorf("Vec3hSlice = %v", s)
+	}
+	f := Float32Slice(nil, HalfSlice(nil, []float32{0.5, 2, -8}))
+	if len(f) != 3 || f[0] != 0.5 || f[1] != 2 || f[2] != -8 {
+		t.Errorf("Float32Slice(HalfSlice()) = %v", f)
+	}
+}
+
+func BenchmarkHalfFromFloat32(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		HalfFromFloat32(float32(i) * 0.001)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lmath
+
+import "math"
+
+// Orthonormalize returns an orthonormal basis (forward, right, and up unit
+// vectors) where forward points in the direction of the given forward vector
+// and up is as close to the given up vector as possible, in the given
+// coordinate system (whose handedness determines the direction of right).
+//
+// If up is parallel to forward (e.g. when looking straight up or down), then
+// an arbitrary up vector perpendicular to forward is chosen instead. Only if
+// forward is a zero vector is ok == false returned.
+func Orthonormalize(forward, up Vec3, cs CoordSys) (f, r, u Vec3, ok bool) {
+	f, ok = forward.Normalized()
+	if !ok {
+		return
+	}
+
+	// Gram-Schmidt: remove the component of up along forward.
+	u, ok = up.Sub(f.MulScalar(up.Dot(f))).Normalized()
+	if !ok || math.Abs(up.Dot(f)) > (1-1e-6)*up.Length() {
+		// Up is parallel to forward (or zero), pick the axis that is least
+		// parallel to forward instead.
+		axis := Vec3{X: 1}
+		x, y, z := math.Abs(f.X), math.Abs(f.Y), math.Abs(f.Z)
+		switch {
+		case y <= x && y <= z:
+			axis = Vec3{Y: 1}
+		case z <= x && z <= y:
+			axis = Vec3{Z: 1}
+		}
+		u, _ = axis.Sub(f.MulScalar(axis.Dot(f))).Normalized()
+	}
+	if cs.RightHanded() {
+		r = f.Cross(u)
+	} else {
+		r = u.Cross(f)
+	}
+	return f, r, u, true
+}
+
+// Mat3LookAt returns a rotation matrix which rotates the forward axis of the
+// given coordinate system to point in the direction of the forward vector,
+// and the up axis to be as close to the up vector as possible (see
+// Orthonormalize).
+//
+// If forward is a zero vector, then the identity matrix is returned.
+func Mat3LookAt(forward, up Vec3, cs CoordSys) Mat3 {
+	f, r, u, ok := Orthonormalize(forward, up, cs)
+	if !ok {
+		return Mat3Identity
+	}
+
+	// Map each axis of the coordinate system (the rows of the basis matrix)
+	// onto the new ones, since the basis matrix is orthonormal it's inverse
+	// is it's transpose.
+	cf, cr, cu := cs.Forward(), cs.Right(), cs.Up()
+	basis := Mat3{
+		{cr.X, cr.Y, cr.Z},
+		{cf.X, cf.Y, cf.Z},
+		{cu.X, cu.Y, cu.Z},
+	}
+	target := Mat3{
+		{r.X, r.Y, r.Z},
+		{f.X, f.Y, f.Z},
+		{u.X, u.Y, u.Z},
+	}
+	return basis.Transposed().Mul(target)
+}
+
+// QuatLookAt is like Mat3LookAt, except it returns a quaternion rotation.
+//
+// If forward is a zero vector, then the identity quaternion is returned.
+func QuatLookAt(forward, up Vec3, cs CoordSys) Quat {
+	return QuatFromMat3(Mat3LookAt(forward, up, cs)).Normalized()
+}
+
+// Mat4LookAt returns a transformation matrix which places an object at the
+// eye position, oriented such that it's forward axis points towards the
+// target position (see Mat3LookAt).
+//
+// Note that this is the object's local-to-world transformation; invert it to
+// use it as a view matrix.
+func Mat4LookAt(eye, target, up Vec3, cs CoordSys) Mat4 {
+	rot := Mat3LookAt(target.Sub(eye), up, cs)
+	return Mat4Identity.SetUpperMat3(rot).SetTranslation(eye)
+}
@@ -0,0 +1,64 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lmath
+
+import "testing"
+
+var coordSystems = []CoordSys{CoordSysZUpRight, CoordSysZUpLeft, CoordSysYUpRight, CoordSysYUpLeft}
+
+func TestOrthonormalize(t *testing.T) {
+	for _, cs := range coordSystems {
+		for _, tst := range []struct{ forward, up Vec3 }{
+			{Vec3{X: 1, Y: 2, Z: 0.5}, Vec3{Z: 1}},
+			{Vec3{Z: 3}, Vec3{Z: 1}},  // Looking straight up.
+			{Vec3{Z: -1}, Vec3{Z: 1}}, // Looking straight down.
+			{Vec3{X: 1}, Vec3Zero},    // No up vector.
+		} {
+			f, r, u, ok := Orthonormalize(tst.forward, tst.up, cs)
+			if !ok {
+				t.Fatalf("Orthonormalize(%v, %v) failed", tst.forward, tst.up)
+			}
+			for _, v := range []Vec3{f, r, u} {
+				if !AlmostEqual(v.Length(), 1, 1e-9) {
+					t.Errorf("Orthonormalize(%v, %v): %v is not normalized", tst.forward, tst.up, v)
+				}
+			}
+			if !AlmostEqual(f.Dot(r), 0, 1e-9) || !AlmostEqual(f.Dot(u), 0, 1e-9) || !AlmostEqual(r.Dot(u), 0, 1e-9) {
+				t.Errorf("Orthonormalize(%v, %v): not orthogonal: %v %v %v", tst.forward, tst.up, f, r, u)
+			}
+		}
+	}
+	if _, _, _, ok := Orthonormalize(Vec3Zero, Vec3{Z: 1}, CoordSysZUpRight); ok {
+		t.Error("Orthonormalize of zero forward vector succeeded")
+	}
+}
+
+func TestQuatLookAt(t *testing.T) {
+	forward, _ := Vec3{X: 1, Y: 1, Z: 1}.Normalized()
+	for _, cs := range coordSystems {
+		q := QuatLookAt(forward.MulScalar(4), cs.Up(), cs)
+		if got := q.TransformVec3(cs.Forward()); !got.AlmostEquals(forward, 1e-9) {
+			t.Errorf("%v: forward = %v, want %v", cs, got, forward)
+		}
+		up := q.TransformVec3(cs.Up())
+		if !AlmostEqual(up.Dot(forward), 0, 1e-9) || up.Dot(cs.Up()) <= 0 {
+			t.Errorf("%v: bad up vector %v", cs, up)
+		}
+		if got := Mat3LookAt(forward, cs.Up(), cs).Determinant(); !AlmostEqual(got, 1, 1e-9) {
+			t.Errorf("%v: determinant = %v, want 1 (a rotation)", cs, got)
+		}
+	}
+}
+
+func TestMat4LookAt(t *testing.T) {
+	eye, target := Vec3{X: 1, Y: 2, Z: 3}, Vec3{X: 4, Y: 2, Z: 3}
+	m := Mat4LookAt(eye, target, Vec3{Z: 1}, CoordSysZUpRight)
+	if got := Vec3Zero.TransformMat4(m); !got.AlmostEquals(eye, 1e-9) {
+		t.Errorf("origin = %v, want %v", got, eye)
+	}
+	if got, want := (Vec3{Y: 3}).TransformMat4(m), target; !got.AlmostEquals(want, 1e-9) {
+		t.Errorf("forward point = %v, want %v", got, want)
+	}
+}
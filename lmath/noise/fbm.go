@@ -0,0 +1,113 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package noise
+
+// FBM is a source of fractal Brownian motion noise: the sum of several
+// octaves of another noise source, each with a higher frequency and a lower
+// amplitude than the last. The result is normalized to the range of [-1, 1].
+type FBM struct {
+	// The noise source, sampled once per octave.
+	Source Source
+
+	// The number of octaves to sum.
+	Octaves int
+
+	// The factor by which the frequency is multiplied each octave.
+	Lacunarity float64
+
+	// The factor by which the amplitude is multiplied each octave (also known
+	// as the persistence).
+	Gain float64
+}
+
+// NewFBM returns a new fractal Brownian motion noise source of the given
+// source and number of octaves, with the default properties:
+//
+//  Lacunarity: 2
+//  Gain: 0.5
+//
+func NewFBM(src Source, octaves int) *FBM {
+	return &FBM{
+		Source:     src,
+		Octaves:    octaves,
+		Lacunarity: 2,
+		Gain:       0.5,
+	}
+}
+
+// offset is added to the coordinates of each octave, such that the lattice
+// points (where e.g. Perlin noise is zero) of each octave do not line up.
+const offset = 17.31
+
+// Noise2 implements the Source interface.
+func (f *FBM) Noise2(x, y float64) float64 {
+	var sum, norm float64
+	freq, amp := 1.0, 1.0
+	for i := 0; i < f.Octaves; i++ {
+		o := float64(i) * offset
+		sum += amp * f.Source.Noise2(x*freq+o, y*freq+o)
+		norm += amp
+		freq *= f.Lacunarity
+		amp *= f.Gain
+	}
+	if norm == 0 {
+		return 0
+	}
+	return sum / norm
+}
+
+// Noise3 implements the Source interface.
+func (f *FBM) Noise3(x, y, z float64) float64 {
+	var sum, norm float64
+	freq, amp := 1.0, 1.0
+	for i := 0; i < f.Octaves; i++ {
+		o := float64(i) * offset
+		sum += amp * f.Source.Noise3(x*freq+o, y*freq+o, z*freq+o)
+		norm += amp
+		freq *= f.Lacunarity
+		amp *= f.Gain
+	}
+	if norm == 0 {
+		return 0
+	}
+	return sum / norm
+}
+
+// Warp is a noise source whose coordinates are distorted by another noise
+// source (i.e. domain warping), producing swirling, organic looking noise:
+//
+//  src(p + Amount * warp(p))
+//
+// Where each component of the warp vector is sampled from the warp source at
+// a different offset.
+type Warp struct {
+	// The noise source to sample, and the source that distorts it.
+	Source, Warp Source
+
+	// The distance by which coordinates are distorted.
+	Amount float64
+}
+
+// Offsets at which each warp component is sampled, such that the components
+// are uncorrelated.
+const (
+	warpOffsetY = 5.2
+	warpOffsetZ = 9.7
+)
+
+// Noise2 implements the Source interface.
+func (w *Warp) Noise2(x, y float64) float64 {
+	dx := w.Warp.Noise2(x, y)
+	dy := w.Warp.Noise2(x+warpOffsetY, y+warpOffsetY)
+	return w.Source.Noise2(x+w.Amount*dx, y+w.Amount*dy)
+}
+
+// Noise3 implements the Source interface.
+func (w *Warp) Noise3(x, y, z float64) float64 {
+	dx := w.Warp.Noise3(x, y, z)
+	dy := w.Warp.Noise3(x+warpOffsetY, y+warpOffsetY, z+warpOffsetY)
+	dz := w.Warp.Noise3(x+warpOffsetZ, y+warpOffsetZ, z+warpOffsetZ)
+	return w.Source.Noise3(x+w.Amount*dx, y+w.Amount*dy, z+w.Amount*dz)
+}
@@ -0,0 +1,62 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package noise implements deterministic procedural noise functions.
+//
+// Gradient noise (see Perlin and Simplex) is smooth pseudo-random noise, which
+// is typically combined into fractal noise (see FBM) and distorted (see Warp)
+// to generate e.g. terrain heightmaps, clouds, or textures:
+//
+//  n := noise.NewFBM(noise.NewSimplex(seed), 6)
+//  height := n.Noise2(x*0.01, y*0.01)
+//
+// All noise sources are deterministic: the same seed always produces the
+// same noise (on every platform), and sources are safe for concurrent use
+// once created.
+package noise
+
+import "math/rand"
+
+// Source is a source of smooth noise, in the range of (roughly) [-1, 1].
+type Source interface {
+	// Noise2 returns the noise value at the given 2D coordinates.
+	Noise2(x, y float64) float64
+
+	// Noise3 returns the noise value at the given 3D coordinates.
+	Noise3(x, y, z float64) float64
+}
+
+// permutation returns a table of the numbers in the range of [0, 256) in a
+// pseudo-random order determined by the seed, repeated twice to avoid
+// wrapping indices.
+func permutation(seed int64) (p [512]uint8) {
+	// Perm relies only on the source, which produces the same sequence for a
+	// given seed in every Go release.
+	perm := rand.New(rand.NewSource(seed)).Perm(256)
+	for i, v := range perm {
+		p[i] = uint8(v)
+		p[i+256] = uint8(v)
+	}
+	return
+}
+
+// floor returns the integer floor of x, it is faster than math.Floor.
+func floor(x float64) int {
+	i := int(x)
+	if x < float64(i) {
+		return i - 1
+	}
+	return i
+}
+
+// fade is the quintic fade curve 6t⁵ - 15t⁴ + 10t³ of improved Perlin noise,
+// which has zero first and second derivatives at t == 0 and t == 1.
+func fade(t float64) float64 {
+	return t * t * t * (t*(t*6-15) + 10)
+}
+
+// lerp linearly interpolates between a and b.
+func lerp(a, b, t float64) float64 {
+	return a + t*(b-a)
+}
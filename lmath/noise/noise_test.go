@@ -0,0 +1,118 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package noise
+
+import (
+	"math"
+	"testing"
+)
+
+var sources = map[string]func(seed int64) Source{
+	"Perlin":  func(seed int64) Source { return NewPerlin(seed) },
+	"Simplex": func(seed int64) Source { return NewSimplex(seed) },
+	"FBM":     func(seed int64) Source { return NewFBM(NewPerlin(seed), 5) },
+	"Warp": func(seed int64) Source {
+		return &Warp{Source: NewSimplex(seed), Warp: NewPerlin(seed + 1), Amount: 2}
+	},
+}
+
+// sample calls f for a grid of coordinates, including negative ones.
+func sample(f func(x, y, z float64)) {
+	for i := 0; i < 4000; i++ {
+		x := float64(i%50)*0.173 - 4
+		y := float64(i/50)*0.191 - 7
+		z := float64(i%13)*0.237 - 1
+		f(x, y, z)
+	}
+}
+
+func TestRange(t *testing.T) {
+	for name, newSource := range sources {
+		src := newSource(1)
+		var nonZero bool
+		sample(func(x, y, z float64) {
+			for _, v := range []float64{src.Noise2(x, y), src.Noise3(x, y, z)} {
+				if math.IsNaN(v) || v < -1 || v > 1 {
+					t.Fatalf("%s: noise at (%v, %v, %v) = %v, out of range", name, x, y, z, v)
+				}
+				if v != 0 {
+					nonZero = true
+				}
+			}
+		})
+		if !nonZero {
+			t.Errorf("%s: noise is zero everywhere", name)
+		}
+	}
+}
+
+func TestDeterministic(t *testing.T) {
+	for name, newSource := range sources {
+		a, b, c := newSource(42), newSource(42), newSource(43)
+		var differs bool
+		sample(func(x, y, z float64) {
+			if a.Noise2(x, y) != b.Noise2(x, y) || a.Noise3(x, y, z) != b.Noise3(x, y, z) {
+				t.Fatalf("%s: same seed gives different noise at (%v, %v, %v)", name, x, y, z)
+			}
+			if a.Noise3(x, y, z) != c.Noise3(x, y, z) {
+				differs = true
+			}
+		})
+		if !differs {
+			t.Errorf("%s: different seeds give the same noise", name)
+		}
+	}
+}
+
+func TestContinuous(t *testing.T) {
+	const eps = 1e-6
+	for name, newSource := range sources {
+		src := newSource(7)
+		sample(func(x, y, z float64) {
+			if d := math.Abs(src.Noise2(x, y) - src.Noise2(x+eps, y)); d > 1e-3 {
+				t.Fatalf("%s: Noise2 discontinuous at (%v, %v): %v", name, x, y, d)
+			}
+			if d := math.Abs(src.Noise3(x, y, z) - src.Noise3(x, y, z+eps)); d > 1e-3 {
+				t.Fatalf("%s: Noise3 discontinuous at (%v, %v, %v): %v", name, x, y, z, d)
+			}
+		})
+	}
+}
+
+func TestPerlinLattice(t *testing.T) {
+	p := NewPerlin(3)
+	for i := -3; i < 3; i++ {
+		x, y, z := float64(i), float64(i*2), float64(-i)
+		if v := p.Noise1(x); v != 0 {
+			t.Errorf("Noise1(%v) = %v, want 0", x, v)
+		}
+		if v := p.Noise2(x, y); v != 0 {
+			t.Errorf("Noise2(%v, %v) = %v, want 0", x, y, v)
+		}
+		if v := p.Noise3(x, y, z); v != 0 {
+			t.Errorf("Noise3(%v, %v, %v) = %v, want 0", x, y, z, v)
+		}
+	}
+	for i := 0; i < 1000; i++ {
+		x := float64(i)*0.137 - 50
+		if v := p.Noise1(x); v < -1 || v > 1 {
+			t.Fatalf("Noise1(%v) = %v, out of range", x, v)
+		}
+	}
+}
+
+func BenchmarkPerlin3(b *testing.B) {
+	p := NewPerlin(1)
+	for i := 0; i < b.N; i++ {
+		p.Noise3(float64(i)*0.01, 0.5, 0.25)
+	}
+}
+
+func BenchmarkSimplex3(b *testing.B) {
+	s := NewSimplex(1)
+	for i := 0; i < b.N; i++ {
+		s.Noise3(float64(i)*0.01, 0.5, 0.25)
+	}
+}
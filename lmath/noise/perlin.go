@@ -0,0 +1,139 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package noise
+
+// Perlin is a source of Perlin (improved) gradient noise. The noise is zero
+// at each integer coordinate.
+type Perlin struct {
+	perm [512]uint8
+}
+
+// NewPerlin returns a new Perlin noise source using the given seed.
+func NewPerlin(seed int64) *Perlin {
+	return &Perlin{perm: permutation(seed)}
+}
+
+// grad1 returns the dot product of a pseudo-random 1D gradient (selected by
+// the hash) and the distance x.
+func grad1(hash uint8, x float64) float64 {
+	g := float64(1 + hash&7) // Gradient magnitude in [1, 8].
+	if hash&8 != 0 {
+		g = -g
+	}
+	return g * x
+}
+
+// grad2 returns the dot product of a pseudo-random 2D gradient (one of eight
+// directions, selected by the hash) and the distance vector (x, y).
+func grad2(hash uint8, x, y float64) float64 {
+	switch hash & 7 {
+	case 0:
+		return x + y
+	case 1:
+		return -x + y
+	case 2:
+		return x - y
+	case 3:
+		return -x - y
+	case 4:
+		return x
+	case 5:
+		return -x
+	case 6:
+		return y
+	default:
+		return -y
+	}
+}
+
+// grad3 returns the dot product of a pseudo-random 3D gradient (one of the
+// twelve edges of a cube, selected by the hash) and the distance vector (x,
+// y, z).
+func grad3(hash uint8, x, y, z float64) float64 {
+	switch hash & 15 {
+	case 0, 12:
+		return x + y
+	case 1, 14:
+		return -x + y
+	case 2:
+		return x - y
+	case 3:
+		return -x - y
+	case 4:
+		return x + z
+	case 5:
+		return -x + z
+	case 6:
+		return x - z
+	case 7:
+		return -x - z
+	case 8:
+		return y + z
+	case 9, 13:
+		return -y + z
+	case 10:
+		return y - z
+	default:
+		return -y - z
+	}
+}
+
+// Noise1 returns the noise value at the given 1D coordinate.
+func (n *Perlin) Noise1(x float64) float64 {
+	xi := floor(x)
+	x -= float64(xi)
+	i := xi & 255
+	u := fade(x)
+	v := lerp(grad1(n.perm[i], x), grad1(n.perm[i+1], x-1), u)
+
+	// The largest gradient is 8, and the distance at most 0.5.
+	return v / 4
+}
+
+// Noise2 implements the Source interface.
+func (n *Perlin) Noise2(x, y float64) float64 {
+	xi, yi := floor(x), floor(y)
+	x -= float64(xi)
+	y -= float64(yi)
+	i, j := xi&255, yi&255
+	u, v := fade(x), fade(y)
+
+	p := &n.perm
+	a, b := int(p[i])+j, int(p[i+1])+j
+	return lerp(
+		lerp(grad2(p[a], x, y), grad2(p[b], x-1, y), u),
+		lerp(grad2(p[a+1], x, y-1), grad2(p[b+1], x-1, y-1), u),
+		v,
+	)
+}
+
+// Noise3 implements the Source interface.
+func (n *Perlin) Noise3(x, y, z float64) float64 {
+	xi, yi, zi := floor(x), floor(y), floor(z)
+	x -= float64(xi)
+	y -= float64(yi)
+	z -= float64(zi)
+	i, j, k := xi&255, yi&255, zi&255
+	u, v, w := fade(x), fade(y), fade(z)
+
+	p := &n.perm
+	a := int(p[i]) + j
+	aa, ab := int(p[a])+k, int(p[a+1])+k
+	b := int(p[i+1]) + j
+	ba, bb := int(p[b])+k, int(p[b+1])+k
+	return lerp(
+		lerp(
+			lerp(grad3(p[aa], x, y, z), grad3(p[ba], x-1, y, z), u),
+			lerp(grad3(p[ab], x, y-1, z), grad3(p[bb], x-1, y-1, z), u),
+			v,
+		),
+		lerp(
+			lerp(grad3(p[aa+1], x, y, z-1), grad3(p[ba+1], x-1, y, z-1), u),
+			lerp(grad3(p[ab+1], x, y-1, z-1), grad3(p[bb+1], x-1, y-1, z-1), u),
+			v,
+		),
+		w,
+	)
+}
@@ -0,0 +1,150 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package noise
+
+import "math"
+
+// Simplex is a source of simplex gradient noise. Compared to Perlin noise it
+// has fewer directional (grid aligned) artifacts.
+//
+// The implementation follows Stefan Gustavson's "Simplex noise demystified".
+type Simplex struct {
+	perm [512]uint8
+}
+
+// NewSimplex returns a new simplex noise source using the given seed.
+func NewSimplex(seed int64) *Simplex {
+	return &Simplex{perm: permutation(seed)}
+}
+
+// Skewing and unskewing factors for two and three dimensions.
+var (
+	f2 = 0.5 * (math.Sqrt(3) - 1)
+	g2 = (3 - math.Sqrt(3)) / 6
+)
+
+const (
+	f3 = 1.0 / 3
+	g3 = 1.0 / 6
+)
+
+// corner2 returns the contribution of a single corner of a 2D simplex, given
+// the distance (x, y) to it.
+func corner2(hash uint8, x, y float64) float64 {
+	t := 0.5 - x*x - y*y
+	if t < 0 {
+		return 0
+	}
+	t *= t
+	return t * t * grad2(hash, x, y)
+}
+
+// corner3 returns the contribution of a single corner of a 3D simplex, given
+// the distance (x, y, z) to it.
+//
+// The radius of influence is 0.5 rather than the 0.6 of the reference
+// implementation, which would overlap the neighboring simplices and cause
+// discontinuities.
+func corner3(hash uint8, x, y, z float64) float64 {
+	t := 0.5 - x*x - y*y - z*z
+	if t < 0 {
+		return 0
+	}
+	t *= t
+	return t * t * grad3(hash, x, y, z)
+}
+
+// Noise2 implements the Source interface.
+func (n *Simplex) Noise2(x, y float64) float64 {
+	// Skew the input space to determine which simplex cell we're in.
+	s := (x + y) * f2
+	i, j := floor(x+s), floor(y+s)
+	t := float64(i+j) * g2
+
+	// The distances from the cell origin.
+	x0 := x - (float64(i) - t)
+	y0 := y - (float64(j) - t)
+
+	// Determine which of the two triangles of the cell we're in.
+	i1, j1 := 0, 1
+	if x0 > y0 {
+		i1, j1 = 1, 0
+	}
+
+	// The distances to the middle and last corners.
+	x1 := x0 - float64(i1) + g2
+	y1 := y0 - float64(j1) + g2
+	x2 := x0 - 1 + 2*g2
+	y2 := y0 - 1 + 2*g2
+
+	p := &n.perm
+	ii, jj := i&255, j&255
+	r := corner2(p[ii+int(p[jj])], x0, y0) +
+		corner2(p[ii+i1+int(p[jj+j1])], x1, y1) +
+		corner2(p[ii+1+int(p[jj+1])], x2, y2)
+
+	// Scale the result to the range of [-1, 1].
+	return 70 * r
+}
+
+// Noise3 implements the Source interface.
+func (n *Simplex) Noise3(x, y, z float64) float64 {
+	// Skew the input space to determine which simplex cell we're in.
+	s := (x + y + z) * f3
+	i, j, k := floor(x+s), floor(y+s), floor(z+s)
+	t := float64(i+j+k) * g3
+
+	// The distances from the cell origin.
+	x0 := x - (float64(i) - t)
+	y0 := y - (float64(j) - t)
+	z0 := z - (float64(k) - t)
+
+	// Determine which of the six tetrahedra of the cell we're in, i.e. the
+	// offsets of the second and third corners.
+	var i1, j1, k1, i2, j2, k2 int
+	if x0 >= y0 {
+		switch {
+		case y0 >= z0:
+			i1, j1, k1, i2, j2, k2 = 1, 0, 0, 1, 1, 0
+		case x0 >= z0:
+			i1, j1, k1, i2, j2, k2 = 1, 0, 0, 1, 0, 1
+		default:
+			i1, j1, k1, i2, j2, k2 = 0, 0, 1, 1, 0, 1
+		}
+	} else {
+		switch {
+		case y0 < z0:
+			i1, j1, k1, i2, j2, k2 = 0, 0, 1, 0, 1, 1
+		case x0 < z0:
+			i1, j1, k1, i2, j2, k2 = 0, 1, 0, 0, 1, 1
+		default:
+			i1, j1, k1, i2, j2, k2 = 0, 1, 0, 1, 1, 0
+		}
+	}
+
+	// The distances to the remaining corners.
+	x1 := x0 - float64(i1) + g3
+	y1 := y0 - float64(j1) + g3
+	z1 := z0 - float64(k1) + g3
+	x2 := x0 - float64(i2) + 2*g3
+	y2 := y0 - float64(j2) + 2*g3
+	z2 := z0 - float64(k2) + 2*g3
+	x3 := x0 - 1 + 3*g3
+	y3 := y0 - 1 + 3*g3
+	z3 := z0 - 1 + 3*g3
+
+	p := &n.perm
+	ii, jj, kk := i&255, j&255, k&255
+	hash := func(i, j, k int) uint8 {
+		return p[ii+i+int(p[jj+j+int(p[kk+k])])]
+	}
+	r := corner3(hash(0, 0, 0), x0, y0, z0) +
+		corner3(hash(i1, j1, k1), x1, y1, z1) +
+		corner3(hash(i2, j2, k2), x2, y2, z2) +
+		corner3(hash(1, 1, 1), x3, y3, z3)
+
+	// Scale the result to the range of [-1, 1].
+	return 76 * r
+}
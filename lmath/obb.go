@@ -0,0 +1,197 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lmath
+
+import (
+	"fmt"
+	"math"
+)
+
+// OBB describes a 3D oriented bounding box composed of a center point, three
+// orthonormal axes (the box's local X, Y, and Z axes in world space), and the
+// half size of the box along each axis.
+type OBB struct {
+	Center   Vec3
+	Axes     [3]Vec3
+	HalfSize Vec3
+}
+
+// String returns a string representation of the box b.
+func (b OBB) String() string {
+	return fmt.Sprintf("OBB(Center=%v, Axes=%v, HalfSize=%v)", b.Center, b.Axes, b.HalfSize)
+}
+
+// halfSize returns the half size along the given axis index.
+func (b OBB) halfSize(i int) float64 {
+	switch i {
+	case 0:
+		return b.HalfSize.X
+	case 1:
+		return b.HalfSize.Y
+	}
+	return b.HalfSize.Z
+}
+
+// OBBFromRect3 returns the oriented box which results from transforming the
+// axis-aligned box r by the affine transformation matrix m (e.g. an object's
+// local-to-world matrix). The matrix must not contain shear.
+func OBBFromRect3(r Rect3, m Mat4) OBB {
+	half := r.Size().MulScalar(0.5)
+	b := OBB{Center: r.Center().TransformMat4(m)}
+	sizes := [3]float64{half.X, half.Y, half.Z}
+	for i := range b.Axes {
+		row := Vec3{m[i][0], m[i][1], m[i][2]}
+		scale := row.Length()
+		b.Axes[i], _ = row.Normalized()
+		sizes[i] *= scale
+	}
+	b.HalfSize = Vec3{sizes[0], sizes[1], sizes[2]}
+	return b
+}
+
+// Corners returns the eight corner points of the box, in the same order as
+// Rect3.Corners (in terms of the box's local axes).
+func (b OBB) Corners() [8]Vec3 {
+	x := b.Axes[0].MulScalar(b.HalfSize.X)
+	y := b.Axes[1].MulScalar(b.HalfSize.Y)
+	z := b.Axes[2].MulScalar(b.HalfSize.Z)
+	var c [8]Vec3
+	for i := range c {
+		p := b.Center
+		if i&1 != 0 {
+			p = p.Add(x)
+		} else {
+			p = p.Sub(x)
+		}
+		if i&2 != 0 {
+			p = p.Add(y)
+		} else {
+			p = p.Sub(y)
+		}
+		if i&4 != 0 {
+			p = p.Add(z)
+		} else {
+			p = p.Sub(z)
+		}
+		c[i] = p
+	}
+	return c
+}
+
+// Rect3 returns the axis-aligned 3D rectangle encapsulating this box.
+func (b OBB) Rect3() Rect3 {
+	var extent Vec3
+	for i, axis := range b.Axes {
+		h := b.halfSize(i)
+		extent.X += math.Abs(axis.X) * h
+		extent.Y += math.Abs(axis.Y) * h
+		extent.Z += math.Abs(axis.Z) * h
+	}
+	return Rect3{Min: b.Center.Sub(extent), Max: b.Center.Add(extent)}
+}
+
+// Closest returns the closest point towards p contained by this box.
+func (b OBB) Closest(p Vec3) Vec3 {
+	// Real-Time Collision Detection, 5.1.4:
+	//  Closest Point on OBB to Point
+	d := p.Sub(b.Center)
+	q := b.Center
+	for i, axis := range b.Axes {
+		h := b.halfSize(i)
+		dist := Clamp(d.Dot(axis), -h, h)
+		q = q.Add(axis.MulScalar(dist))
+	}
+	return q
+}
+
+// Contains tells if the point p is within this box.
+func (b OBB) Contains(p Vec3) bool {
+	d := p.Sub(b.Center)
+	for i, axis := range b.Axes {
+		if math.Abs(d.Dot(axis)) > b.halfSize(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// OverlapsSphere reports whether the box b has a non-empty intersection with
+// the sphere s.
+func (b OBB) OverlapsSphere(s Sphere) bool {
+	return b.Closest(s.Center).Sub(s.Center).LengthSq() <= s.Radius*s.Radius
+}
+
+// OverlapsRect3 reports whether the box b has a non-empty intersection with
+// the rectangle r.
+func (b OBB) OverlapsRect3(r Rect3) bool {
+	return b.Overlaps(OBBFromRect3(r, Mat4Identity))
+}
+
+// Overlaps reports whether the boxes a and b have a non-empty intersection.
+func (a OBB) Overlaps(b OBB) bool {
+	// Real-Time Collision Detection, 4.4.1:
+	//  OBB-OBB Intersection
+	var rot, absRot [3][3]float64
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			rot[i][j] = a.Axes[i].Dot(b.Axes[j])
+
+			// Add an epsilon to counteract arithmetic errors when two edges
+			// are parallel and their cross product is (near) null.
+			absRot[i][j] = math.Abs(rot[i][j]) + EPSILON
+		}
+	}
+
+	// Translation vector, in a's coordinate frame.
+	d := b.Center.Sub(a.Center)
+	t := [3]float64{d.Dot(a.Axes[0]), d.Dot(a.Axes[1]), d.Dot(a.Axes[2])}
+	ae := [3]float64{a.HalfSize.X, a.HalfSize.Y, a.HalfSize.Z}
+	be := [3]float64{b.HalfSize.X, b.HalfSize.Y, b.HalfSize.Z}
+
+	// Test axes L = A0, A1, A2.
+	for i := 0; i < 3; i++ {
+		ra := ae[i]
+		rb := be[0]*absRot[i][0] + be[1]*absRot[i][1] + be[2]*absRot[i][2]
+		if math.Abs(t[i]) > ra+rb {
+			return false
+		}
+	}
+
+	// Test axes L = B0, B1, B2.
+	for i := 0; i < 3; i++ {
+		ra := ae[0]*absRot[0][i] + ae[1]*absRot[1][i] + ae[2]*absRot[2][i]
+		rb := be[i]
+		if math.Abs(t[0]*rot[0][i]+t[1]*rot[1][i]+t[2]*rot[2][i]) > ra+rb {
+			return false
+		}
+	}
+
+	// Test the nine axes L = Ai x Bj.
+	for i := 0; i < 3; i++ {
+		i1, i2 := (i+1)%3, (i+2)%3
+		for j := 0; j < 3; j++ {
+			j1, j2 := (j+1)%3, (j+2)%3
+			ra := ae[i1]*absRot[i2][j] + ae[i2]*absRot[i1][j]
+			rb := be[j1]*absRot[i][j2] + be[j2]*absRot[i][j1]
+			if math.Abs(t[i2]*rot[i1][j]-t[i1]*rot[i2][j]) > ra+rb {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// IntersectOBB tests whether the ray intersects the given oriented box,
+// returning the distance along the ray at which it enters the box (or zero if
+// the origin is inside the box).
+func (r Ray) IntersectOBB(b OBB) (t float64, ok bool) {
+	// Intersect the ray, in the box's local space, with the local box.
+	d := r.Origin.Sub(b.Center)
+	local := Ray{
+		Origin: Vec3{d.Dot(b.Axes[0]), d.Dot(b.Axes[1]), d.Dot(b.Axes[2])},
+		Dir:    Vec3{r.Dir.Dot(b.Axes[0]), r.Dir.Dot(b.Axes[1]), r.Dir.Dot(b.Axes[2])},
+	}
+	return local.IntersectRect3(Rect3{Min: b.HalfSize.MulScalar(-1), Max: b.HalfSize})
+}
@@ -0,0 +1,69 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lmath
+
+import (
+	"fmt"
+	"math"
+)
+
+// Plane describes a 3D plane as a normal and a distance, such that the points
+// on the plane are those where:
+//  Normal.Dot(p) + D == 0
+//
+// Points where Normal.Dot(p) + D > 0 are said to be in front of the plane.
+type Plane struct {
+	Normal Vec3
+	D      float64
+}
+
+// String returns a string representation of the plane p.
+func (p Plane) String() string {
+	return fmt.Sprintf("Plane(Normal=%v, D=%f)", p.Normal, p.D)
+}
+
+// Normalized returns the plane with a unit length normal, such that Distance
+// returns true distances. If the normal has zero length, ok=false is
+// returned.
+func (p Plane) Normalized() (n Plane, ok bool) {
+	length := p.Normal.Length()
+	if Equal(length, 0) {
+		return p, false
+	}
+	return Plane{Normal: p.Normal.DivScalar(length), D: p.D / length}, true
+}
+
+// Distance returns the signed distance from the plane to the point q, which is
+// positive if the point is in front of the plane. The distance is in units of
+// the normal's length.
+func (p Plane) Distance(q Vec3) float64 {
+	return p.Normal.Dot(q) + p.D
+}
+
+// PlaneFromPoints returns the plane passing through the three points, with a
+// unit normal facing the side from which the points appear counter-clockwise.
+// If the points are colinear, ok=false is returned.
+func PlaneFromPoints(a, b, c Vec3) (p Plane, ok bool) {
+	n, ok := b.Sub(a).Cross(c.Sub(a)).Normalized()
+	if !ok {
+		return Plane{}, false
+	}
+	return Plane{Normal: n, D: -n.Dot(a)}, true
+}
+
+// IntersectPlane tests whether the ray intersects the given plane, returning
+// the distance along the ray to the intersection.
+func (r Ray) IntersectPlane(p Plane) (t float64, ok bool) {
+	denom := p.Normal.Dot(r.Dir)
+	if math.Abs(denom) < EPSILON {
+		// The ray is parallel to the plane.
+		return 0, false
+	}
+	t = -p.Distance(r.Origin) / denom
+	if t < 0 {
+		return 0, false
+	}
+	return t, true
+}
@@ -194,6 +194,28 @@ func (a Quat) Lerp(b Quat, t float64) Quat {
 	return a.Mul(b.MulScalar(t))
 }
 
+// Slerp returns a quaternion representing the spherical linear interpolation
+// between the unit quaternions a and b, taking the shortest path. The t
+// parameter is the amount to interpolate (0.0 - 1.0) between the quaternions.
+func (a Quat) Slerp(b Quat, t float64) Quat {
+	cos := a.Dot(b)
+	if cos < 0 {
+		// Take the shortest path.
+		b = b.MulScalar(-1)
+		cos = -cos
+	}
+	if cos > 1-EPSILON {
+		// The quaternions are nearly equal, linearly interpolate instead to
+		// avoid division by zero.
+		return a.Add(b.Sub(a).MulScalar(t)).Normalized()
+	}
+	angle := math.Acos(cos)
+	sin := math.Sin(angle)
+	wa := math.Sin((1-t)*angle) / sin
+	wb := math.Sin(t*angle) / sin
+	return a.MulScalar(wa).Add(b.MulScalar(wb))
+}
+
 // Conjugate calculates and returns the conjugate of this quaternion.
 func (a Quat) Conjugate() Quat {
 	return Quat{
@@ -0,0 +1,110 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package random implements random number utilities for gameplay.
+//
+// PCG is a small and fast random number generator which implements the
+// rand.Source64 interface, such that it can be used with the standard
+// library's math/rand package:
+//
+//  r := rand.New(random.NewPCG(seed, 0))
+//  dir := random.OnSphere(r)
+//
+// Unlike the default math/rand source, a PCG generator has many independent
+// streams (sequences) for a single seed. Giving each entity in a game it's
+// own stream (see NewStream) keeps the random numbers of each deterministic
+// regardless of the order in which entities are updated, which is important
+// for e.g. replays and networking.
+package random
+
+import "math/rand"
+
+const pcgMultiplier = 6364136223846793005
+
+// PCG is a PCG32 (XSH-RR variant) random number generator, as described at:
+//
+//  http://www.pcg-random.org/
+//
+// It implements the rand.Source64 interface. It is not safe for concurrent
+// use by multiple goroutines, and is not suitable for cryptographic use.
+type PCG struct {
+	state, inc uint64
+}
+
+// Ensure PCG implements the rand.Source64 interface.
+var _ rand.Source64 = &PCG{}
+
+// NewPCG returns a new PCG generator with the given seed and stream. Each
+// stream is an independent sequence of random numbers.
+func NewPCG(seed, stream uint64) *PCG {
+	p := &PCG{}
+	p.SeedStream(seed, stream)
+	return p
+}
+
+// NewStream is short-hand for:
+//
+//  rand.New(NewPCG(uint64(seed), stream))
+//
+// It is intended for creating deterministic per-entity random number
+// generators, where the seed is shared (e.g. per game or level) and the
+// stream is unique to each entity (e.g. it's ID).
+func NewStream(seed int64, stream uint64) *rand.Rand {
+	return rand.New(NewPCG(uint64(seed), stream))
+}
+
+// SeedStream seeds the generator with the given seed and stream.
+func (p *PCG) SeedStream(seed, stream uint64) {
+	p.state = 0
+	p.inc = stream<<1 | 1
+	p.Uint32()
+	p.state += seed
+	p.Uint32()
+}
+
+// Seed implements the rand.Source interface. It seeds the generator, keeping
+// it's current stream.
+func (p *PCG) Seed(seed int64) {
+	p.SeedStream(uint64(seed), p.inc>>1)
+}
+
+// Uint32 returns a pseudo-random 32-bit value.
+func (p *PCG) Uint32() uint32 {
+	old := p.state
+	p.state = old*pcgMultiplier + p.inc
+	xorShifted := uint32(((old >> 18) ^ old) >> 27)
+	rot := uint32(old >> 59)
+	return xorShifted>>rot | xorShifted<<((-rot)&31)
+}
+
+// Uint64 implements the rand.Source64 interface. It returns a pseudo-random
+// 64-bit value, composed of two 32-bit ones.
+func (p *PCG) Uint64() uint64 {
+	return uint64(p.Uint32())<<32 | uint64(p.Uint32())
+}
+
+// Int63 implements the rand.Source interface. It returns a non-negative
+// pseudo-random 63-bit integer.
+func (p *PCG) Int63() int64 {
+	return int64(p.Uint64() >> 1)
+}
+
+// Advance advances the generator by delta steps (i.e. as if Uint32 were
+// called delta times) in O(log(delta)) time. This allows e.g. skipping ahead
+// to a known point in a replay.
+func (p *PCG) Advance(delta uint64) {
+	// Brown's "Random Number Generation with Arbitrary Stride".
+	accMul, accPlus := uint64(1), uint64(0)
+	curMul, curPlus := uint64(pcgMultiplier), p.inc
+	for delta > 0 {
+		if delta&1 != 0 {
+			accMul *= curMul
+			accPlus = accPlus*curMul + curPlus
+		}
+		curPlus = (curMul + 1) * curPlus
+		curMul *= curMul
+		delta >>= 1
+	}
+	p.state = accMul*p.state + accPlus
+}
@@ -0,0 +1,185 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package random
+
+import (
+	"math"
+	"math/rand"
+
+	"azul3d.org/engine/lmath"
+)
+
+// Range returns a pseudo-random number in the range of [min, max).
+func Range(r *rand.Rand, min, max float64) float64 {
+	return min + r.Float64()*(max-min)
+}
+
+// Chance returns true with the given probability, in the range of [0, 1].
+func Chance(r *rand.Rand, probability float64) bool {
+	return r.Float64() < probability
+}
+
+// OnCircle returns a pseudo-random point uniformly distributed on the unit
+// circle (i.e. a random 2D direction).
+func OnCircle(r *rand.Rand) lmath.Vec2 {
+	s, c := math.Sincos(r.Float64() * 2 * math.Pi)
+	return lmath.Vec2{X: c, Y: s}
+}
+
+// InDisc returns a pseudo-random point uniformly distributed within the unit
+// disc.
+func InDisc(r *rand.Rand) lmath.Vec2 {
+	// The square root accounts for the area of the disc growing with the
+	// radius, otherwise points would cluster at the center.
+	return OnCircle(r).MulScalar(math.Sqrt(r.Float64()))
+}
+
+// OnSphere returns a pseudo-random point uniformly distributed on the unit
+// sphere (i.e. a random 3D direction).
+func OnSphere(r *rand.Rand) lmath.Vec3 {
+	// Archimedes' hat-box theorem: a uniform height and angle give a uniform
+	// distribution on the sphere.
+	z := 2*r.Float64() - 1
+	s, c := math.Sincos(r.Float64() * 2 * math.Pi)
+	rad := math.Sqrt(1 - z*z)
+	return lmath.Vec3{X: rad * c, Y: rad * s, Z: z}
+}
+
+// InSphere returns a pseudo-random point uniformly distributed within the
+// unit sphere.
+func InSphere(r *rand.Rand) lmath.Vec3 {
+	return OnSphere(r).MulScalar(math.Cbrt(r.Float64()))
+}
+
+// InCone returns a pseudo-random unit vector uniformly distributed within the
+// cone around the given direction, with the given half angle in radians (e.g.
+// for the spread of bullets or particles). The direction must be a unit
+// vector.
+func InCone(r *rand.Rand, dir lmath.Vec3, angle float64) lmath.Vec3 {
+	// A uniform direction within the cone around the forward (+Y) axis, like
+	// OnSphere but with a limited height.
+	y := Range(r, math.Cos(angle), 1)
+	s, c := math.Sincos(r.Float64() * 2 * math.Pi)
+	rad := math.Sqrt(1 - y*y)
+	v := lmath.Vec3{X: rad * c, Y: y, Z: rad * s}
+
+	// Rotate the forward axis onto the direction.
+	return lmath.QuatLookAt(dir, lmath.Vec3{Z: 1}, lmath.CoordSysZUpRight).TransformVec3(v)
+}
+
+// Quat returns a pseudo-random rotation uniformly distributed over all
+// rotations.
+func Quat(r *rand.Rand) lmath.Quat {
+	// Shoemake's "Uniform Random Rotations".
+	u1, u2, u3 := r.Float64(), r.Float64()*2*math.Pi, r.Float64()*2*math.Pi
+	a, b := math.Sqrt(1-u1), math.Sqrt(u1)
+	s2, c2 := math.Sincos(u2)
+	s3, c3 := math.Sincos(u3)
+	return lmath.Quat{W: b * c3, X: a * s2, Y: a * c2, Z: b * s3}
+}
+
+// Weighted returns a pseudo-random index into the given slice of weights,
+// where the probability of each index is proportional to it's weight. The
+// weights must not be negative. If the weights sum to zero (or there are
+// none), -1 is returned.
+//
+// It takes O(n) time, see AliasTable for choosing from the same weights
+// repeatedly.
+func Weighted(r *rand.Rand, weights []float64) int {
+	var total float64
+	for _, w := range weights {
+		total += w
+	}
+	if total <= 0 {
+		return -1
+	}
+	x := r.Float64() * total
+	for i, w := range weights {
+		if x < w {
+			return i
+		}
+		x -= w
+	}
+
+	// Floating point rounding, choose the last non-zero weight.
+	for i := len(weights) - 1; i >= 0; i-- {
+		if weights[i] > 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// AliasTable chooses pseudo-random indices with probabilities proportional to
+// a fixed set of weights, like Weighted, but in O(1) time per choice (using
+// Vose's alias method).
+type AliasTable struct {
+	prob  []float64
+	alias []int
+}
+
+// NewAliasTable returns a new alias table for the given weights, which must
+// not be negative and must not sum to zero.
+func NewAliasTable(weights []float64) *AliasTable {
+	n := len(weights)
+	t := &AliasTable{
+		prob:  make([]float64, n),
+		alias: make([]int, n),
+	}
+	var total float64
+	for _, w := range weights {
+		total += w
+	}
+
+	// Scale the weights such that their average is one, and split them into
+	// those below and above it.
+	scaled := make([]float64, n)
+	var small, large []int
+	for i, w := range weights {
+		scaled[i] = w * float64(n) / total
+		if scaled[i] < 1 {
+			small = append(small, i)
+		} else {
+			large = append(large, i)
+		}
+	}
+
+	// Pair each small weight with a large one that fills the rest of it's
+	// column.
+	for len(small) > 0 && len(large) > 0 {
+		s, l := small[len(small)-1], large[len(large)-1]
+		small = small[:len(small)-1]
+		t.prob[s] = scaled[s]
+		t.alias[s] = l
+		scaled[l] -= 1 - scaled[s]
+		if scaled[l] < 1 {
+			large = large[:len(large)-1]
+			small = append(small, l)
+		}
+	}
+
+	// The remaining columns are full (or off by rounding errors).
+	for _, i := range large {
+		t.prob[i] = 1
+	}
+	for _, i := range small {
+		t.prob[i] = 1
+	}
+	return t
+}
+
+// Len returns the number of weights in the table.
+func (t *AliasTable) Len() int {
+	return len(t.prob)
+}
+
+// Choose returns a pseudo-random index into the weights of the table.
+func (t *AliasTable) Choose(r *rand.Rand) int {
+	i := r.Intn(len(t.prob))
+	if r.Float64() < t.prob[i] {
+		return i
+	}
+	return t.alias[i]
+}
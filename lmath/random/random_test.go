@@ -0,0 +1,127 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package random
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"azul3d.org/engine/lmath"
+)
+
+func TestPCG(t *testing.T) {
+	// The reference output of pcg32-demo from the PCG C library.
+	p := NewPCG(42, 54)
+	want := []uint32{0xa15c02b7, 0x7b47f409, 0xba1d3330, 0x83d2f293, 0xbfa4784b, 0xcbed606e}
+	for i, w := range want {
+		if got := p.Uint32(); got != w {
+			t.Fatalf("output %d = %#08x, want %#08x", i, got, w)
+		}
+	}
+}
+
+func TestPCGAdvance(t *testing.T) {
+	a, b := NewPCG(1, 2), NewPCG(1, 2)
+	for i := 0; i < 1000; i++ {
+		a.Uint32()
+	}
+	b.Advance(1000)
+	if a.Uint32() != b.Uint32() {
+		t.Fatal("Advance(1000) differs from 1000 calls to Uint32")
+	}
+}
+
+func TestPCGStreams(t *testing.T) {
+	a, b := NewStream(7, 1), NewStream(7, 2)
+	if a.Int63() == b.Int63() && a.Int63() == b.Int63() {
+		t.Fatal("streams produce the same sequence")
+	}
+
+	// Reseeding keeps the stream.
+	p := NewPCG(7, 1)
+	first := p.Uint64()
+	p.Seed(7)
+	if p.Uint64() != first {
+		t.Fatal("Seed did not restart the sequence")
+	}
+	if v := p.Int63(); v < 0 {
+		t.Fatalf("Int63() = %v, negative", v)
+	}
+}
+
+func TestDistributions(t *testing.T) {
+	r := NewStream(1, 0)
+	var sum lmath.Vec3
+	for i := 0; i < 10000; i++ {
+		if l := OnSphere(r).Length(); !lmath.AlmostEqual(l, 1, 1e-9) {
+			t.Fatalf("OnSphere length = %v", l)
+		}
+		if l := InSphere(r).Length(); l > 1 {
+			t.Fatalf("InSphere length = %v", l)
+		}
+		if l := OnCircle(r).Length(); !lmath.AlmostEqual(l, 1, 1e-9) {
+			t.Fatalf("OnCircle length = %v", l)
+		}
+		if l := InDisc(r).Length(); l > 1 {
+			t.Fatalf("InDisc length = %v", l)
+		}
+		if l := Quat(r).Length(); !lmath.AlmostEqual(l, 1, 1e-9) {
+			t.Fatalf("Quat length = %v", l)
+		}
+		sum = sum.Add(OnSphere(r))
+	}
+
+	// Uniform points on a sphere average to it's center.
+	if avg := sum.DivScalar(10000); avg.Length() > 0.05 {
+		t.Errorf("OnSphere average = %v, want near zero", avg)
+	}
+}
+
+func TestInCone(t *testing.T) {
+	r := NewStream(2, 0)
+	const angle = 0.3
+	for _, dir := range []lmath.Vec3{{X: 1}, {Z: 1}, {Z: -1}, {X: 0.6, Y: 0.8}} {
+		for i := 0; i < 1000; i++ {
+			v := InCone(r, dir, angle)
+			if !lmath.AlmostEqual(v.Length(), 1, 1e-9) {
+				t.Fatalf("InCone length = %v", v.Length())
+			}
+			if a := math.Acos(lmath.Clamp(v.Dot(dir), -1, 1)); a > angle+1e-9 {
+				t.Fatalf("InCone(%v) = %v, %v radians from the direction", dir, v, a)
+			}
+		}
+	}
+}
+
+func TestWeighted(t *testing.T) {
+	r := NewStream(3, 0)
+	weights := []float64{1, 0, 3, 6}
+	table := NewAliasTable(weights)
+	const n = 100000
+	var counts, tableCounts [4]int
+	for i := 0; i < n; i++ {
+		counts[Weighted(r, weights)]++
+		tableCounts[table.Choose(r)]++
+	}
+	for i, w := range weights {
+		want := w / 10
+		for _, c := range []int{counts[i], tableCounts[i]} {
+			if got := float64(c) / n; math.Abs(got-want) > 0.01 {
+				t.Errorf("index %d chosen %v of the time, want %v", i, got, want)
+			}
+		}
+	}
+	if i := Weighted(r, []float64{0, 0}); i != -1 {
+		t.Errorf("Weighted of zero weights = %d, want -1", i)
+	}
+}
+
+func BenchmarkPCG(b *testing.B) {
+	r := rand.New(NewPCG(1, 0))
+	for i := 0; i < b.N; i++ {
+		r.Float64()
+	}
+}
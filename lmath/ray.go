@@ -0,0 +1,131 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lmath
+
+import (
+	"fmt"
+	"math"
+)
+
+// Ray describes a 3D ray composed of an origin point and a direction. The
+// points along the ray are:
+//  Origin + Dir*t, for t >= 0
+//
+// The direction need not be normalized, but distances along the ray (the t
+// values returned by the intersection methods) are in units of it's length.
+type Ray struct {
+	Origin, Dir Vec3
+}
+
+// String returns a string representation of the ray r.
+func (r Ray) String() string {
+	return fmt.Sprintf("Ray(Origin=%v, Dir=%v)", r.Origin, r.Dir)
+}
+
+// At returns the point along the ray at the given distance, t.
+func (r Ray) At(t float64) Vec3 {
+	return r.Origin.Add(r.Dir.MulScalar(t))
+}
+
+// Transform returns the ray transformed by the given matrix. Because the
+// direction is not normalized, distances along the transformed ray correspond
+// to the same points as those along the original ray.
+func (r Ray) Transform(m Mat4) Ray {
+	return Ray{
+		Origin: r.Origin.TransformMat4(m),
+		Dir:    r.Dir.TransformVecMat4(m),
+	}
+}
+
+// IntersectRect3 tests whether the ray intersects the given axis-aligned box,
+// returning the distance along the ray at which it enters the box (or zero if
+// the origin is inside the box).
+func (r Ray) IntersectRect3(b Rect3) (t float64, ok bool) {
+	// Real-Time Collision Detection, 5.3.3:
+	//  Intersecting Ray or Segment Against Box
+	tMin, tMax := 0.0, math.Inf(1)
+	origin := [3]float64{r.Origin.X, r.Origin.Y, r.Origin.Z}
+	dir := [3]float64{r.Dir.X, r.Dir.Y, r.Dir.Z}
+	min := [3]float64{b.Min.X, b.Min.Y, b.Min.Z}
+	max := [3]float64{b.Max.X, b.Max.Y, b.Max.Z}
+	for i := 0; i < 3; i++ {
+		if math.Abs(dir[i]) < EPSILON {
+			// Parallel to the slab, no hit if the origin is not within it.
+			if origin[i] < min[i] || origin[i] > max[i] {
+				return 0, false
+			}
+			continue
+		}
+		ood := 1.0 / dir[i]
+		t1 := (min[i] - origin[i]) * ood
+		t2 := (max[i] - origin[i]) * ood
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		tMin = math.Max(tMin, t1)
+		tMax = math.Min(tMax, t2)
+		if tMin > tMax {
+			return 0, false
+		}
+	}
+	return tMin, true
+}
+
+// IntersectSphere tests whether the ray intersects the given sphere, returning
+// the distance along the ray at which it enters the sphere (or zero if the
+// origin is inside the sphere).
+func (r Ray) IntersectSphere(s Sphere) (t float64, ok bool) {
+	// Real-Time Collision Detection, 5.3.2:
+	//  Intersecting Ray or Segment Against Sphere
+	m := r.Origin.Sub(s.Center)
+	a := r.Dir.Dot(r.Dir)
+	b := m.Dot(r.Dir)
+	c := m.Dot(m) - s.Radius*s.Radius
+	if c > 0 && b > 0 {
+		// The origin is outside the sphere, and the ray points away from it.
+		return 0, false
+	}
+	discr := b*b - a*c
+	if discr < 0 || a == 0 {
+		return 0, false
+	}
+	t = (-b - math.Sqrt(discr)) / a
+	if t < 0 {
+		t = 0
+	}
+	return t, true
+}
+
+// IntersectTriangle tests whether the ray intersects the triangle (a, b, c),
+// from either side, returning the distance along the ray to the intersection
+// and it's barycentric coordinates (u, v) such that the point is:
+//  a*(1-u-v) + b*u + c*v
+func (r Ray) IntersectTriangle(a, b, c Vec3) (t, u, v float64, ok bool) {
+	// Möller–Trumbore ray-triangle intersection.
+	e1 := b.Sub(a)
+	e2 := c.Sub(a)
+	p := r.Dir.Cross(e2)
+	det := e1.Dot(p)
+	if math.Abs(det) < EPSILON {
+		// The ray is parallel to the triangle.
+		return 0, 0, 0, false
+	}
+	invDet := 1.0 / det
+	s := r.Origin.Sub(a)
+	u = s.Dot(p) * invDet
+	if u < 0 || u > 1 {
+		return 0, 0, 0, false
+	}
+	q := s.Cross(e1)
+	v = r.Dir.Dot(q) * invDet
+	if v < 0 || u+v > 1 {
+		return 0, 0, 0, false
+	}
+	t = e2.Dot(q) * invDet
+	if t < 0 {
+		return 0, 0, 0, false
+	}
+	return t, u, v, true
+}
@@ -0,0 +1,56 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lmath
+
+import "testing"
+
+func TestRayIntersectRect3(t *testing.T) {
+	box := Rect3{Min: Vec3{-1, -1, -1}, Max: Vec3{1, 1, 1}}
+	tests := []struct {
+		r    Ray
+		t    float64
+		want bool
+	}{
+		{Ray{Vec3{0, -5, 0}, Vec3{0, 1, 0}}, 4, true},
+		{Ray{Vec3{0, -5, 0}, Vec3{0, 2, 0}}, 2, true},
+		{Ray{Vec3{0, -5, 0}, Vec3{0, -1, 0}}, 0, false},
+		{Ray{Vec3{0, 0, 0}, Vec3{1, 0, 0}}, 0, true},
+		{Ray{Vec3{5, -5, 0}, Vec3{0, 1, 0}}, 0, false},
+		{Ray{Vec3{-5, -5, 0}, Vec3{1, 1, 0}}, 4, true},
+	}
+	for _, tst := range tests {
+		got, ok := tst.r.IntersectRect3(box)
+		if ok != tst.want || !AlmostEqual(got, tst.t, 1e-9) {
+			t.Errorf("%v: got (%v, %v), want (%v, %v)", tst.r, got, ok, tst.t, tst.want)
+		}
+	}
+}
+
+func TestRayIntersectSphere(t *testing.T) {
+	s := Sphere{Center: Vec3{0, 10, 0}, Radius: 2}
+	if got, ok := (Ray{Vec3Zero, Vec3{0, 1, 0}}).IntersectSphere(s); !ok || !AlmostEqual(got, 8, 1e-9) {
+		t.Fatal("expected hit at 8, got", got, ok)
+	}
+	if _, ok := (Ray{Vec3Zero, Vec3{0, -1, 0}}).IntersectSphere(s); ok {
+		t.Fatal("expected miss behind the ray")
+	}
+	if _, ok := (Ray{Vec3{3, 0, 0}, Vec3{0, 1, 0}}).IntersectSphere(s); ok {
+		t.Fatal("expected miss beside the sphere")
+	}
+}
+
+func TestRayIntersectTriangle(t *testing.T) {
+	a, b, c := Vec3{0, 5, 0}, Vec3{2, 5, 0}, Vec3{0, 5, 2}
+	got, u, v, ok := Ray{Vec3{0.5, 0, 0.5}, Vec3{0, 1, 0}}.IntersectTriangle(a, b, c)
+	if !ok || !AlmostEqual(got, 5, 1e-9) || !AlmostEqual(u, 0.25, 1e-9) || !AlmostEqual(v, 0.25, 1e-9) {
+		t.Fatal("got", got, u, v, ok)
+	}
+	if _, _, _, ok := (Ray{Vec3{1.5, 0, 1.5}, Vec3{0, 1, 0}}).IntersectTriangle(a, b, c); ok {
+		t.Fatal("expected miss outside the triangle")
+	}
+	if _, _, _, ok := (Ray{Vec3{0.5, 10, 0.5}, Vec3{0, 1, 0}}).IntersectTriangle(a, b, c); ok {
+		t.Fatal("expected miss behind the ray")
+	}
+}
@@ -174,9 +174,18 @@ func (r Rect3) Closest(p Vec3) Vec3 {
 
 // Furthest returns the furthest point away from p contained by this rectangle.
 func (r Rect3) Furthest(p Vec3) Vec3 {
-	p = p.Min(r.Max)
-	p = p.Max(r.Min)
-	return p
+	c := r.Center()
+	f := r.Min
+	if p.X < c.X {
+		f.X = r.Max.X
+	}
+	if p.Y < c.Y {
+		f.Y = r.Max.Y
+	}
+	if p.Z < c.Z {
+		f.Z = r.Max.Z
+	}
+	return f
 }
 
 // SqDistToPoint returns the squared distance between the point p and the
@@ -229,7 +238,7 @@ func (r Rect3) Contains(p Vec3) bool {
 	}
 	return r.Min.X <= p.X && p.X < r.Max.X &&
 		r.Min.Y <= p.Y && p.Y < r.Max.Y &&
-		r.Max.Z <= p.Z && p.Z < r.Max.Z
+		r.Min.Z <= p.Z && p.Z < r.Max.Z
 }
 
 // Corners returns an array of the eight corner points of this 3D rectangle.
@@ -267,7 +276,7 @@ func (r Rect3) Area() float64 {
 
 // InSphere reports whether the rectangle r is completely inside the sphere s.
 func (r Rect3) InSphere(s Sphere) bool {
-	return s.Contains(r.Min) && s.Contains(r.Max)
+	return s.Contains(r.Furthest(s.Center))
 }
 
 // Rect3Zero is the zero rectangle.
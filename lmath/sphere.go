@@ -12,12 +12,12 @@ type Sphere struct {
 
 // Contains tells if the point p is within this sphere.
 func (s Sphere) Contains(p Vec3) bool {
-	return s.Center.Sub(p).LengthSq() < s.Radius
+	return s.Center.Sub(p).LengthSq() < s.Radius*s.Radius
 }
 
 // In tells if the sphere s inside the sphere b.
 func (s Sphere) In(b Sphere) bool {
-	return false
+	return s.Center.Sub(b.Center).Length()+s.Radius <= b.Radius
 }
 
 // Overlaps reports whether s and b have a non-empty intersection.
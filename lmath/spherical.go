@@ -0,0 +1,83 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lmath
+
+import "math"
+
+// LatLongToCart converts the given latitude and longitude (in radians) on a
+// sphere of the given radius, centered at the origin, to cartesian
+// coordinates in the Z up coordinate system and returns it.
+//
+// A latitude of zero lies on the equator (the XY plane) and a latitude of
+// Pi/2 on the north pole (+Z). A longitude of zero lies on the +X axis, and
+// increases counter-clockwise towards +Y.
+func LatLongToCart(r, lat, long float64) Vec3 {
+	sLat, cLat := math.Sincos(lat)
+	sLong, cLong := math.Sincos(long)
+	return Vec3{
+		X: r * cLat * cLong,
+		Y: r * cLat * sLong,
+		Z: r * sLat,
+	}
+}
+
+// CartToLatLong converts the point in cartesian coordinate space, p, into the
+// radius, latitude, and longitude (in radians) of it on a sphere centered at
+// the origin. It is the inverse of LatLongToCart. The latitude is in the
+// range of [-Pi/2, Pi/2] and the longitude in the range of [-Pi, Pi].
+//
+// If p is the origin, then all zeros are returned.
+func CartToLatLong(p Vec3) (r, lat, long float64) {
+	r = p.Length()
+	if Equal(r, 0) {
+		return 0, 0, 0
+	}
+	lat = math.Asin(Clamp(p.Z/r, -1, 1))
+	long = math.Atan2(p.Y, p.X)
+	return
+}
+
+// LatLongFrame returns the local east, north, and up directions of the
+// surface of a sphere at the given latitude and longitude (in radians), in
+// the Z up coordinate system.
+//
+// At the poles, where east and north are ambiguous, the frame remains
+// orthonormal and is oriented by the given longitude.
+func LatLongFrame(lat, long float64) (east, north, up Vec3) {
+	up = LatLongToCart(1, lat, long)
+	sLong, cLong := math.Sincos(long)
+	east = Vec3{X: -sLong, Y: cLong}
+	north = up.Cross(east)
+	return
+}
+
+// LatLongQuat returns the rotation which places an object on the surface of
+// a sphere at the given latitude and longitude (in radians), in the Z up
+// right-handed coordinate system: the object's up axis (+Z) points away from
+// the sphere's center, and it's forward axis (+Y) points north.
+//
+// Combine it with LatLongToCart to place objects on a planet, for example:
+//  t.SetQuat(lmath.LatLongQuat(lat, long))
+//  t.SetPos(lmath.LatLongToCart(radius, lat, long))
+func LatLongQuat(lat, long float64) Quat {
+	east, north, up := LatLongFrame(lat, long)
+	return QuatFromMat3(Mat3{
+		{east.X, east.Y, east.Z},
+		{north.X, north.Y, north.Z},
+		{up.X, up.Y, up.Z},
+	}).Normalized()
+}
+
+// GreatCircleDistance returns the distance along the surface of a sphere of
+// the given radius between the two points at the given latitudes and
+// longitudes (in radians).
+//
+// It uses the haversine formula, which is accurate for small distances.
+func GreatCircleDistance(r, lat1, long1, lat2, long2 float64) float64 {
+	sLat := math.Sin((lat2 - lat1) / 2)
+	sLong := math.Sin((long2 - long1) / 2)
+	h := sLat*sLat + math.Cos(lat1)*math.Cos(lat2)*sLong*sLong
+	return 2 * r * math.Asin(math.Sqrt(Clamp(h, 0, 1)))
+}
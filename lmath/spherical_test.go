@@ -0,0 +1,77 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lmath
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCartToSphere(t *testing.T) {
+	for _, p := range []Vec2{
+		{X: 0.5, Y: 0.25},
+		{X: 1, Y: 2.5},  // Second quadrant.
+		{X: 2, Y: -2.5}, // Third quadrant.
+	} {
+		c := SphereToCart(3, p)
+		got := CartToSphere(c)
+		want := Vec3{X: 3, Y: p.X, Z: p.Y}
+		if !got.AlmostEquals(want, 1e-9) {
+			t.Errorf("CartToSphere(SphereToCart(3, %v)) = %v, want %v", p, got, want)
+		}
+	}
+	if got := CartToSphere(Vec3Zero); got != Vec3Zero {
+		t.Errorf("CartToSphere(0) = %v, want zero", got)
+	}
+}
+
+func TestLatLong(t *testing.T) {
+	if got, want := LatLongToCart(2, 0, 0), (Vec3{X: 2}); !got.AlmostEquals(want, 1e-9) {
+		t.Errorf("LatLongToCart(2, 0, 0) = %v, want %v", got, want)
+	}
+	if got, want := LatLongToCart(2, math.Pi/2, 1), (Vec3{Z: 2}); !got.AlmostEquals(want, 1e-9) {
+		t.Errorf("LatLongToCart(2, Pi/2, 1) = %v, want %v", got, want)
+	}
+	for _, ll := range [][2]float64{{0.3, 0.4}, {-1.2, 3}, {0.7, -2.2}} {
+		r, lat, long := CartToLatLong(LatLongToCart(5, ll[0], ll[1]))
+		if !AlmostEqual(r, 5, 1e-9) || !AlmostEqual(lat, ll[0], 1e-9) || !AlmostEqual(long, ll[1], 1e-9) {
+			t.Errorf("CartToLatLong(LatLongToCart(5, %v, %v)) = %v, %v, %v", ll[0], ll[1], r, lat, long)
+		}
+	}
+}
+
+func TestLatLongQuat(t *testing.T) {
+	for _, ll := range [][2]float64{{0, 0}, {0.3, 0.4}, {-1.2, 3}, {math.Pi / 2, 0}} {
+		east, north, up := LatLongFrame(ll[0], ll[1])
+		if !AlmostEqual(east.Dot(north), 0, 1e-9) || !AlmostEqual(north.Dot(up), 0, 1e-9) || !AlmostEqual(north.Length(), 1, 1e-9) {
+			t.Errorf("LatLongFrame(%v, %v) not orthonormal: %v %v %v", ll[0], ll[1], east, north, up)
+		}
+		q := LatLongQuat(ll[0], ll[1])
+		if got := q.TransformVec3(Vec3{Z: 1}); !got.AlmostEquals(up, 1e-9) {
+			t.Errorf("LatLongQuat(%v, %v) up = %v, want %v", ll[0], ll[1], got, up)
+		}
+		if got := q.TransformVec3(Vec3{Y: 1}); !got.AlmostEquals(north, 1e-9) {
+			t.Errorf("LatLongQuat(%v, %v) forward = %v, want %v", ll[0], ll[1], got, north)
+		}
+	}
+
+	// Away from the poles, north points towards increasing latitude.
+	_, north, _ := LatLongFrame(0.2, 1)
+	d := LatLongToCart(1, 0.2+1e-6, 1).Sub(LatLongToCart(1, 0.2, 1))
+	if d.Dot(north) <= 0 {
+		t.Errorf("north %v does not point towards increasing latitude", north)
+	}
+}
+
+func TestGreatCircleDistance(t *testing.T) {
+	// A quarter of the equator.
+	if got, want := GreatCircleDistance(2, 0, 0, 0, math.Pi/2), math.Pi; !AlmostEqual(got, want, 1e-9) {
+		t.Errorf("GreatCircleDistance = %v, want %v", got, want)
+	}
+	// Pole to pole.
+	if got, want := GreatCircleDistance(1, math.Pi/2, 0, -math.Pi/2, 1), math.Pi; !AlmostEqual(got, want, 1e-9) {
+		t.Errorf("GreatCircleDistance = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lmath
+
+import "fmt"
+
+// Transform describes a transformation composed of a scale, followed by a
+// rotation, followed by a translation (i.e. TRS).
+//
+// Unlike a Mat4, a transform can be inverted and interpolated cheaply and
+// without the loss of precision caused by decomposing a matrix.
+type Transform struct {
+	// The translation of the transform.
+	Pos Vec3
+
+	// The rotation of the transform, it must be a unit quaternion.
+	Rot Quat
+
+	// The scale of the transform.
+	Scale Vec3
+}
+
+// TransformIdentity is the identity transform (i.e. no translation, no
+// rotation, and a scale of one).
+var TransformIdentity = Transform{
+	Rot:   QuatIdentity,
+	Scale: Vec3One,
+}
+
+// String returns a string representation of the transform t.
+func (t Transform) String() string {
+	return fmt.Sprintf("Transform(Pos=%v, Rot=%v, Scale=%v)", t.Pos, t.Rot, t.Scale)
+}
+
+// AlmostEquals tells if the components of t and b are equal within the
+// absolute tolerance epsilon.
+//
+// Note that q and -q represent the same rotation, but are not considered
+// equal by this method.
+func (t Transform) AlmostEquals(b Transform, epsilon float64) bool {
+	return t.Pos.AlmostEquals(b.Pos, epsilon) && t.Rot.AlmostEquals(b.Rot, epsilon) && t.Scale.AlmostEquals(b.Scale, epsilon)
+}
+
+// Mat4 returns the transformation matrix of the transform t, it is
+// equivalent to (but faster than):
+//  Mat4FromScale(t.Scale).MulQuat(t.Rot).Mul(Mat4FromTranslation(t.Pos))
+func (t Transform) Mat4() Mat4 {
+	r := t.Rot.ExtractToMat3()
+	return Mat4{
+		{r[0][0] * t.Scale.X, r[0][1] * t.Scale.X, r[0][2] * t.Scale.X, 0},
+		{r[1][0] * t.Scale.Y, r[1][1] * t.Scale.Y, r[1][2] * t.Scale.Y, 0},
+		{r[2][0] * t.Scale.Z, r[2][1] * t.Scale.Z, r[2][2] * t.Scale.Z, 0},
+		{t.Pos.X, t.Pos.Y, t.Pos.Z, 1},
+	}
+}
+
+// TransformPoint returns the point p transformed by t (i.e. scaled, rotated,
+// and then translated).
+func (t Transform) TransformPoint(p Vec3) Vec3 {
+	return t.Rot.TransformVec3(p.Mul(t.Scale)).Add(t.Pos)
+}
+
+// TransformVec returns the direction vector v transformed by t (i.e. scaled
+// and rotated, but not translated).
+func (t Transform) TransformVec(v Vec3) Vec3 {
+	return t.Rot.TransformVec3(v.Mul(t.Scale))
+}
+
+// Mul composes the transform t with it's parent transform, returning a
+// transform that first applies t and then the parent transform. That is, if t
+// is relative to parent then the result is t in the parent's space:
+//  t.Mul(parent).Mat4() == t.Mat4().Mul(parent.Mat4())
+//
+// A TRS transform cannot represent the shear that results from a rotated
+// child under a non-uniformly scaled parent, so in that case the result is
+// only an approximation (the parent's scale is applied along the child's
+// axes).
+func (t Transform) Mul(parent Transform) Transform {
+	return Transform{
+		Pos:   parent.TransformPoint(t.Pos),
+		Rot:   t.Rot.Mul(parent.Rot).Normalized(),
+		Scale: t.Scale.Mul(parent.Scale),
+	}
+}
+
+// Inverse returns the inverse of the transform t, such that:
+//  t.Mul(t.Inverse()) == TransformIdentity
+//
+// The scale components must be non-zero. Like Mul, the result is exact only
+// for uniform scaling.
+func (t Transform) Inverse() Transform {
+	inv := Transform{
+		Rot:   t.Rot.Conjugate(),
+		Scale: Vec3One.Div(t.Scale),
+	}
+	inv.Pos = inv.Rot.TransformVec3(t.Pos).Mul(inv.Scale).MulScalar(-1)
+	return inv
+}
+
+// RelativeTo returns the transform t, given in the same space as the parent
+// transform, relative to the parent. It is the inverse of Mul, such that:
+//  t.RelativeTo(parent).Mul(parent) == t
+func (t Transform) RelativeTo(parent Transform) Transform {
+	return t.Mul(parent.Inverse())
+}
+
+// Lerp returns a transform representing the interpolation between the
+// transforms t and b. The position and scale are interpolated linearly, and
+// the rotation spherically (see Quat.Slerp). The amount parameter is the
+// amount to interpolate (0.0 - 1.0) between the transforms.
+func (t Transform) Lerp(b Transform, amount float64) Transform {
+	return Transform{
+		Pos:   t.Pos.Lerp(b.Pos, amount),
+		Rot:   t.Rot.Slerp(b.Rot, amount),
+		Scale: t.Scale.Lerp(b.Scale, amount),
+	}
+}
+
+// TransformFromMat4 decomposes the given transformation matrix into a
+// transform. If the matrix contains shear or a projection, or has a zero
+// scale component, then ok == false is returned and the transform is not
+// valid.
+func TransformFromMat4(m Mat4) (t Transform, ok bool) {
+	if !Equal(m[0][3], 0) || !Equal(m[1][3], 0) || !Equal(m[2][3], 0) || !Equal(m[3][3], 1) {
+		return t, false
+	}
+	rows := [3]Vec3{
+		{m[0][0], m[0][1], m[0][2]},
+		{m[1][0], m[1][1], m[1][2]},
+		{m[2][0], m[2][1], m[2][2]},
+	}
+	t.Scale = Vec3{rows[0].Length(), rows[1].Length(), rows[2].Length()}
+	if Equal(t.Scale.X, 0) || Equal(t.Scale.Y, 0) || Equal(t.Scale.Z, 0) {
+		return t, false
+	}
+	rows[0] = rows[0].DivScalar(t.Scale.X)
+	rows[1] = rows[1].DivScalar(t.Scale.Y)
+	rows[2] = rows[2].DivScalar(t.Scale.Z)
+
+	// The rows must be orthogonal, or else the matrix contains shear.
+	const tolerance = 1e-6
+	if !AlmostEqual(rows[0].Dot(rows[1]), 0, tolerance) || !AlmostEqual(rows[0].Dot(rows[2]), 0, tolerance) || !AlmostEqual(rows[1].Dot(rows[2]), 0, tolerance) {
+		return t, false
+	}
+
+	// A reflection is represented by a negative scale.
+	if rows[0].Cross(rows[1]).Dot(rows[2]) < 0 {
+		t.Scale = t.Scale.MulScalar(-1)
+		rows[0] = rows[0].MulScalar(-1)
+		rows[1] = rows[1].MulScalar(-1)
+		rows[2] = rows[2].MulScalar(-1)
+	}
+
+	t.Rot = QuatFromMat3(Mat3{
+		{rows[0].X, rows[0].Y, rows[0].Z},
+		{rows[1].X, rows[1].Y, rows[1].Z},
+		{rows[2].X, rows[2].Y, rows[2].Z},
+	}).Normalized()
+	t.Pos = Vec3{m[3][0], m[3][1], m[3][2]}
+	return t, true
+}
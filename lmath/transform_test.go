@@ -0,0 +1,89 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lmath
+
+import (
+	"math"
+	"testing"
+)
+
+var transformTests = []Transform{
+	TransformIdentity,
+	{Pos: Vec3{1, 2, 3}, Rot: QuatIdentity, Scale: Vec3One},
+	{Pos: Vec3{-4, 0, 1}, Rot: QuatFromAxisAngle(Vec3{0, 0, 1}, math.Pi/3), Scale: Vec3{2, 2, 2}},
+	{Pos: Vec3{0, 5, -2}, Rot: QuatFromAxisAngle(Vec3{math.Sqrt2 / 2, math.Sqrt2 / 2, 0}, 1.2), Scale: Vec3{1, 3, 0.5}},
+}
+
+func TestTransformMat4(t *testing.T) {
+	p := Vec3{0.5, -1, 2}
+	for _, tr := range transformTests {
+		m := tr.Mat4()
+		want := Mat4FromScale(tr.Scale).MulQuat(tr.Rot).Mul(Mat4FromTranslation(tr.Pos))
+		if !m.AlmostEquals(want, 1e-9) {
+			t.Errorf("%v.Mat4()\ngot  %v\nwant %v", tr, m, want)
+		}
+		if got, want := tr.TransformPoint(p), p.TransformMat4(m); !got.AlmostEquals(want, 1e-9) {
+			t.Errorf("%v.TransformPoint(%v) = %v, want %v", tr, p, got, want)
+		}
+	}
+}
+
+func TestTransformMul(t *testing.T) {
+	child := Transform{Pos: Vec3{1, 0, 0}, Rot: QuatFromAxisAngle(Vec3{0, 1, 0}, 0.7), Scale: Vec3{1, 2, 3}}
+	for _, parent := range transformTests[:3] {
+		got := child.Mul(parent).Mat4()
+		want := child.Mat4().Mul(parent.Mat4())
+		if !got.AlmostEquals(want, 1e-9) {
+			t.Errorf("%v.Mul(%v)\ngot  %v\nwant %v", child, parent, got, want)
+		}
+		if rel := child.Mul(parent).RelativeTo(parent); !rel.AlmostEquals(child, 1e-9) {
+			t.Errorf("RelativeTo(%v) = %v, want %v", parent, rel, child)
+		}
+	}
+}
+
+func TestTransformInverse(t *testing.T) {
+	for _, tr := range transformTests[:3] {
+		got := tr.Inverse().Mat4()
+		want, _ := tr.Mat4().Inverse()
+		if !got.AlmostEquals(want, 1e-9) {
+			t.Errorf("%v.Inverse()\ngot  %v\nwant %v", tr, got, want)
+		}
+	}
+}
+
+func TestTransformLerp(t *testing.T) {
+	a := Transform{Pos: Vec3{0, 0, 0}, Rot: QuatIdentity, Scale: Vec3One}
+	b := Transform{Pos: Vec3{2, 4, 6}, Rot: QuatFromAxisAngle(Vec3{0, 0, 1}, math.Pi/2), Scale: Vec3{3, 3, 3}}
+	got := a.Lerp(b, 0.5)
+	want := Transform{Pos: Vec3{1, 2, 3}, Rot: QuatFromAxisAngle(Vec3{0, 0, 1}, math.Pi/4), Scale: Vec3{2, 2, 2}}
+	if !got.AlmostEquals(want, 1e-9) {
+		t.Errorf("Lerp\ngot  %v\nwant %v", got, want)
+	}
+	if got := a.Lerp(b, 1); !got.AlmostEquals(b, 1e-9) {
+		t.Errorf("Lerp(1) = %v, want %v", got, b)
+	}
+}
+
+func TestTransformFromMat4(t *testing.T) {
+	for _, tr := range transformTests {
+		got, ok := TransformFromMat4(tr.Mat4())
+		if !ok {
+			t.Errorf("TransformFromMat4(%v.Mat4()) failed", tr)
+			continue
+		}
+		if got.Rot.Dot(tr.Rot) < 0 {
+			got.Rot = got.Rot.MulScalar(-1)
+		}
+		if !got.AlmostEquals(tr, 1e-9) {
+			t.Errorf("TransformFromMat4\ngot  %v\nwant %v", got, tr)
+		}
+	}
+
+	// Shear cannot be represented.
+	if _, ok := TransformFromMat4(Mat4FromScaleShear(Vec3One, Vec3{1, 0, 0}, CoordSysZUpRight)); ok {
+		t.Error("TransformFromMat4 accepted a sheared matrix")
+	}
+}
@@ -215,7 +215,7 @@ func (a Vec2) Max(b Vec2) Vec2 {
 // vectors a and b. The t parameter is the amount to interpolate (0.0 - 1.0)
 // between the vectors.
 func (a Vec2) Lerp(b Vec2, t float64) Vec2 {
-	return a.Mul(b.MulScalar(t))
+	return a.Add(b.Sub(a).MulScalar(t))
 }
 
 // Angle returns the angle in radians between the two vectors.
@@ -55,3 +55,19 @@ func TestVec2Div(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestVec2Lerp(t *testing.T) {
+	a := Vec2{1, 3}
+	b := Vec2{3, -1}
+	if !a.Lerp(b, 0).Equals(a) {
+		t.Fail()
+	}
+
+	if !a.Lerp(b, 0.5).Equals(Vec2{2, 1}) {
+		t.Fail()
+	}
+
+	if !a.Lerp(b, 1).Equals(b) {
+		t.Fail()
+	}
+}
@@ -230,7 +230,7 @@ func (a Vec3) Max(b Vec3) Vec3 {
 // vectors a and b. The t parameter is the amount to interpolate (0.0 - 1.0)
 // between the vectors.
 func (a Vec3) Lerp(b Vec3, t float64) Vec3 {
-	return a.Mul(b.MulScalar(t))
+	return a.Add(b.Sub(a).MulScalar(t))
 }
 
 // Cross returns the cross product of the two vectors.
@@ -56,6 +56,22 @@ func TestVec3Div(t *testing.T) {
 	}
 }
 
+func TestVec3Lerp(t *testing.T) {
+	a := Vec3{1, 3, 3}
+	b := Vec3{3, -1, 5}
+	if !a.Lerp(b, 0).Equals(a) {
+		t.Fail()
+	}
+
+	if !a.Lerp(b, 0.5).Equals(Vec3{2, 1, 4}) {
+		t.Fail()
+	}
+
+	if !a.Lerp(b, 1).Equals(b) {
+		t.Fail()
+	}
+}
+
 func BenchmarkVec3Equals(b *testing.B) {
 	x := Vec3{1, 3, 3}
 	y := Vec3{1.33, 3.33, 3.33}
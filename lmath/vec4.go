@@ -31,7 +31,7 @@ func (a Vec4) Equals(b Vec4) bool {
 
 // Add performs a componentwise addition of the two vectors, returning a + b.
 func (a Vec4) Add(b Vec4) Vec4 {
-	return Vec4{a.X + b.X, a.Y + b.Y, a.Z + b.Z, a.W + a.W}
+	return Vec4{a.X + b.X, a.Y + b.Y, a.Z + b.Z, a.W + b.W}
 }
 
 // AddScalar performs a componentwise scalar addition of a + b.
@@ -240,7 +240,7 @@ func (a Vec4) Max(b Vec4) Vec4 {
 // vectors a and b. The t parameter is the amount to interpolate (0.0 - 1.0)
 // between the vectors.
 func (a Vec4) Lerp(b Vec4, t float64) Vec4 {
-	return a.Mul(b.MulScalar(t))
+	return a.Add(b.Sub(a).MulScalar(t))
 }
 
 // Transform transforms this vector by the matrix (vector * matrix) and returns
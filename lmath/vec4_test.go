@@ -55,3 +55,19 @@ func TestVec4Div(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestVec4Lerp(t *testing.T) {
+	a := Vec4{1, 3, 3, 3}
+	b := Vec4{3, -1, 5, 3}
+	if !a.Lerp(b, 0).Equals(a) {
+		t.Fail()
+	}
+
+	if !a.Lerp(b, 0.5).Equals(Vec4{2, 1, 4, 3}) {
+		t.Fail()
+	}
+
+	if !a.Lerp(b, 1).Equals(b) {
+		t.Fail()
+	}
+}
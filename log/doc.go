@@ -0,0 +1,32 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package log implements leveled, structured logging for the engine.
+//
+// Each subsystem logs through a Logger tagged with it's name, and may attach
+// key/value fields to the entries it logs:
+//
+//  var logger = log.New("gfx")
+//
+//  logger.Warnf("texture %q is not power-of-two", t.Label)
+//  logger.With("meshes", n, "bytes", size).Debugf("freed meshes")
+//
+// Entries below the level of their tag (see SetLevel and SetTagLevel) are
+// discarded, the rest are delivered to every sink. By default there is a
+// single sink which writes Info (and higher) entries to os.Stderr, much like
+// the standard log package does:
+//
+//  2014/10/16 15:04:05 [WARN] gfx: texture "grass.png" is not power-of-two
+//
+// Sinks are pluggable (see AddSink). A Ring keeps the most recent entries in
+// memory, e.g. for display in the in-game console or inclusion in crash
+// reports, and a WriterSink writes formatted entries to any io.Writer (such
+// as a file, or a console.Console):
+//
+//  recent := log.NewRing(256)
+//  log.AddSink(recent)
+//  log.AddSink(log.NewWriterSink(con, log.Info))
+//
+// The package is safe for use from multiple goroutines concurrently.
+package log // import "azul3d.org/engine/log"
@@ -0,0 +1,282 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is the severity level of a log entry.
+type Level int8
+
+const (
+	// Debug entries are verbose information useful only when debugging.
+	Debug Level = iota
+
+	// Info entries are informational messages about normal operation.
+	Info
+
+	// Warn entries are potential problems which do not prevent operation.
+	Warn
+
+	// Error entries are failures, which the engine recovered from.
+	Error
+)
+
+// String returns a short, upper-case, string name for this level. For
+// example:
+//
+//  Warn.String() == "WARN"
+//
+func (l Level) String() string {
+	switch l {
+	case Debug:
+		return "DEBUG"
+	case Info:
+		return "INFO"
+	case Warn:
+		return "WARN"
+	case Error:
+		return "ERROR"
+	}
+	return fmt.Sprintf("Level(%d)", l)
+}
+
+// Field is a single key/value pair attached to a log entry.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Entry is a single log entry.
+type Entry struct {
+	// The time at which the entry was logged.
+	Time time.Time
+
+	// The level and tag (i.e. subsystem name) of the entry.
+	Level Level
+	Tag   string
+
+	// The message and fields of the entry.
+	Message string
+	Fields  []Field
+}
+
+// String returns the entry formatted as a single line, without the time:
+//
+//  [WARN] gfx: texture is not power-of-two size=[100 100]
+//
+func (e *Entry) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] ", e.Level)
+	if e.Tag != "" {
+		b.WriteString(e.Tag)
+		b.WriteString(": ")
+	}
+	b.WriteString(strings.TrimSuffix(e.Message, "\n"))
+	for _, f := range e.Fields {
+		fmt.Fprintf(&b, " %s=%v", f.Key, f.Value)
+	}
+	return b.String()
+}
+
+var (
+	access    sync.RWMutex
+	level     = Info
+	tagLevels = map[string]Level{}
+	sinks     = []Sink{NewWriterSink(os.Stderr, Debug)}
+)
+
+// SetLevel sets the minimum level of entries which are logged, for tags which
+// have no level of their own (see SetTagLevel). The default level is Info.
+func SetLevel(l Level) {
+	access.Lock()
+	level = l
+	access.Unlock()
+}
+
+// SetTagLevel sets the minimum level of entries with the given tag which are
+// logged, e.g. to enable debug output of a single subsystem:
+//
+//  log.SetTagLevel("gfx", log.Debug)
+//
+func SetTagLevel(tag string, l Level) {
+	access.Lock()
+	tagLevels[tag] = l
+	access.Unlock()
+}
+
+// ClearTagLevel removes the level of the given tag, such that the level set by
+// SetLevel applies to it.
+func ClearTagLevel(tag string) {
+	access.Lock()
+	delete(tagLevels, tag)
+	access.Unlock()
+}
+
+// Enabled tells whether entries of the given level and tag would be logged. It
+// may be used to avoid expensive work producing an entry which is discarded.
+func Enabled(l Level, tag string) bool {
+	access.RLock()
+	defer access.RUnlock()
+	min, ok := tagLevels[tag]
+	if !ok {
+		min = level
+	}
+	return l >= min
+}
+
+// AddSink adds the given sink, to which all entries logged afterwards are
+// delivered.
+func AddSink(s Sink) {
+	access.Lock()
+	sinks = append(sinks, s)
+	access.Unlock()
+}
+
+// RemoveSink removes the given sink, if it was added.
+func RemoveSink(s Sink) {
+	access.Lock()
+	for i, x := range sinks {
+		if x == s {
+			sinks = append(sinks[:i:i], sinks[i+1:]...)
+			break
+		}
+	}
+	access.Unlock()
+}
+
+// SetSinks replaces all sinks (including the default os.Stderr one) with the
+// given ones.
+func SetSinks(s ...Sink) {
+	access.Lock()
+	sinks = append([]Sink(nil), s...)
+	access.Unlock()
+}
+
+// Log delivers the given entry to all sinks, if it's level is enabled for it's
+// tag. If the entry's time is zero, it is set to the current time.
+func Log(e *Entry) {
+	if !Enabled(e.Level, e.Tag) {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	access.RLock()
+	s := sinks
+	access.RUnlock()
+	for _, sink := range s {
+		sink.Log(e)
+	}
+}
+
+// Logger logs entries with a tag, and optionally a set of fields. Loggers are
+// immutable values, and safe for use from multiple goroutines concurrently.
+type Logger struct {
+	tag    string
+	fields []Field
+}
+
+// Tag returns the tag of the logger.
+func (l *Logger) Tag() string {
+	return l.tag
+}
+
+// With returns a copy of the logger which attaches the given key/value pairs
+// to each entry it logs, in addition to the fields of this logger:
+//
+//  l.With("path", path, "size", n).Infof("loaded")
+//
+// Keys must be strings, With panics if not (or if there is an odd number of
+// arguments).
+func (l *Logger) With(kv ...interface{}) *Logger {
+	if len(kv)%2 != 0 {
+		panic("log: With: odd number of arguments")
+	}
+	fields := make([]Field, len(l.fields), len(l.fields)+len(kv)/2)
+	copy(fields, l.fields)
+	for i := 0; i < len(kv); i += 2 {
+		key, ok := kv[i].(string)
+		if !ok {
+			panic(fmt.Sprintf("log: With: key %v is not a string", kv[i]))
+		}
+		fields = append(fields, Field{Key: key, Value: kv[i+1]})
+	}
+	return &Logger{tag: l.tag, fields: fields}
+}
+
+// Logf formats according to a format specifier and logs the result at the
+// given level.
+func (l *Logger) Logf(lvl Level, format string, args ...interface{}) {
+	if !Enabled(lvl, l.tag) {
+		return
+	}
+	Log(&Entry{
+		Level:   lvl,
+		Tag:     l.tag,
+		Message: fmt.Sprintf(format, args...),
+		Fields:  l.fields,
+	})
+}
+
+// Debugf logs a Debug level entry, see Logf.
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.Logf(Debug, format, args...)
+}
+
+// Infof logs an Info level entry, see Logf.
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.Logf(Info, format, args...)
+}
+
+// Warnf logs a Warn level entry, see Logf.
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.Logf(Warn, format, args...)
+}
+
+// Errorf logs an Error level entry, see Logf.
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.Logf(Error, format, args...)
+}
+
+// Writer returns an io.Writer which logs each line written to it as an entry
+// of the given level, for use with APIs which write their output to a writer.
+func (l *Logger) Writer(lvl Level) *Writer {
+	return &Writer{Logger: l, Level: lvl}
+}
+
+// New returns a new logger with the given tag, which is usually the name of a
+// package or subsystem.
+func New(tag string) *Logger {
+	return &Logger{tag: tag}
+}
+
+// std is the logger used by the package-level functions, it has no tag.
+var std = New("")
+
+// Debugf logs an untagged Debug level entry, see Logger.Logf.
+func Debugf(format string, args ...interface{}) {
+	std.Logf(Debug, format, args...)
+}
+
+// Infof logs an untagged Info level entry, see Logger.Logf.
+func Infof(format string, args ...interface{}) {
+	std.Logf(Info, format, args...)
+}
+
+// Warnf logs an untagged Warn level entry, see Logger.Logf.
+func Warnf(format string, args ...interface{}) {
+	std.Logf(Warn, format, args...)
+}
+
+// Errorf logs an untagged Error level entry, see Logger.Logf.
+func Errorf(format string, args ...interface{}) {
+	std.Logf(Error, format, args...)
+}
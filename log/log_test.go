@@ -0,0 +1,74 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestLevels(t *testing.T) {
+	ring := NewRing(16)
+	defer SetSinks(sinks...)
+	SetSinks(ring)
+	defer SetLevel(Info)
+
+	gfx := New("gfx")
+	audio := New("audio")
+	gfx.Debugf("hidden")
+	gfx.Infof("shown %d", 1)
+	SetTagLevel("gfx", Debug)
+	gfx.Debugf("shown %d", 2)
+	audio.Debugf("hidden")
+	ClearTagLevel("gfx")
+	gfx.Debugf("hidden")
+	SetLevel(Error)
+	audio.Warnf("hidden")
+	audio.With("code", 7).Errorf("shown %d", 3)
+
+	want := []string{
+		"[INFO] gfx: shown 1",
+		"[DEBUG] gfx: shown 2",
+		"[ERROR] audio: shown 3 code=7",
+	}
+	if got := ring.Lines(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q\nwant %q", got, want)
+	}
+}
+
+func TestRing(t *testing.T) {
+	r := NewRing(3)
+	for i := 0; i < 5; i++ {
+		r.Log(&Entry{Level: Info, Message: fmt.Sprint(i)})
+	}
+	want := []string{"[INFO] 2", "[INFO] 3", "[INFO] 4"}
+	if got := r.Lines(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	r.Reset()
+	if len(r.Entries()) != 0 {
+		t.Fatal("expected no entries after Reset")
+	}
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewWriterSink(&buf, Warn)
+	sink.TimeFormat = ""
+	defer SetSinks(sinks...)
+	SetSinks(sink)
+
+	w := New("gl").Writer(Warn)
+	fmt.Fprintf(w, "first\nsec")
+	fmt.Fprintf(w, "ond\r\n\n")
+	New("gl").Infof("filtered by the sink")
+
+	want := "[WARN] gl: first\n[WARN] gl: second\n"
+	if buf.String() != want {
+		t.Fatalf("got %q, want %q", buf.String(), want)
+	}
+}
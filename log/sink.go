@@ -0,0 +1,161 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Sink receives the entries which are logged. Sinks are called from whichever
+// goroutine logged the entry, so they must be safe for use from multiple
+// goroutines concurrently. They must not retain or modify the entry after
+// returning, and must not log entries themselves.
+type Sink interface {
+	Log(e *Entry)
+}
+
+// SinkFunc is a function which implements the Sink interface.
+type SinkFunc func(e *Entry)
+
+// Log implements the Sink interface.
+func (f SinkFunc) Log(e *Entry) {
+	f(e)
+}
+
+// WriterSink writes formatted entries to a writer, one per line, prefixed with
+// their time.
+type WriterSink struct {
+	// The minimum level of entries which are written.
+	Level Level
+
+	// The layout of the time prefix, as for time.Time.Format. If empty, the
+	// time is not written.
+	TimeFormat string
+
+	access sync.Mutex
+	w      io.Writer
+}
+
+// Log implements the Sink interface.
+func (s *WriterSink) Log(e *Entry) {
+	if e.Level < s.Level {
+		return
+	}
+	line := e.String() + "\n"
+	if s.TimeFormat != "" {
+		line = e.Time.Format(s.TimeFormat) + " " + line
+	}
+	s.access.Lock()
+	io.WriteString(s.w, line)
+	s.access.Unlock()
+}
+
+// NewWriterSink returns a new sink which writes entries of at least the given
+// level to w, with the time format of the standard log package.
+func NewWriterSink(w io.Writer, min Level) *WriterSink {
+	return &WriterSink{
+		Level:      min,
+		TimeFormat: "2006/01/02 15:04:05",
+		w:          w,
+	}
+}
+
+// Ring is a sink which keeps the most recent entries in memory.
+type Ring struct {
+	access  sync.Mutex
+	entries []Entry
+	next    int
+	full    bool
+}
+
+// Log implements the Sink interface.
+func (r *Ring) Log(e *Entry) {
+	r.access.Lock()
+	cpy := *e
+	cpy.Fields = append([]Field(nil), e.Fields...)
+	r.entries[r.next] = cpy
+	r.next++
+	if r.next == len(r.entries) {
+		r.next = 0
+		r.full = true
+	}
+	r.access.Unlock()
+}
+
+// Entries returns a copy of the entries in the ring, oldest first.
+func (r *Ring) Entries() []Entry {
+	r.access.Lock()
+	defer r.access.Unlock()
+	if !r.full {
+		return append([]Entry(nil), r.entries[:r.next]...)
+	}
+	return append(append([]Entry(nil), r.entries[r.next:]...), r.entries[:r.next]...)
+}
+
+// Lines returns the entries in the ring formatted as lines (see
+// Entry.String), oldest first.
+func (r *Ring) Lines() []string {
+	entries := r.Entries()
+	lines := make([]string, len(entries))
+	for i := range entries {
+		lines[i] = entries[i].String()
+	}
+	return lines
+}
+
+// Reset removes all of the entries from the ring.
+func (r *Ring) Reset() {
+	r.access.Lock()
+	for i := range r.entries {
+		r.entries[i] = Entry{}
+	}
+	r.next, r.full = 0, false
+	r.access.Unlock()
+}
+
+// NewRing returns a new, empty, ring which keeps at most n entries. It panics
+// if n <= 0.
+func NewRing(n int) *Ring {
+	if n <= 0 {
+		panic(fmt.Sprintf("log: NewRing: invalid size %d", n))
+	}
+	return &Ring{entries: make([]Entry, n)}
+}
+
+// Writer is an io.Writer which logs each line written to it as an entry (see
+// Logger.Writer). Partial lines are buffered until they are completed.
+type Writer struct {
+	// The logger and level which lines are logged with.
+	Logger *Logger
+	Level  Level
+
+	access sync.Mutex
+	buf    []byte
+}
+
+// Write implements the io.Writer interface.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.access.Lock()
+	w.buf = append(w.buf, p...)
+	var lines []string
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		if line := string(bytes.TrimRight(w.buf[:i], "\r")); line != "" {
+			lines = append(lines, line)
+		}
+		w.buf = w.buf[i+1:]
+	}
+	w.access.Unlock()
+	for _, line := range lines {
+		w.Logger.Logf(w.Level, "%s", line)
+	}
+	return len(p), nil
+}
@@ -108,7 +108,7 @@ func (w *Watcher) State(button Button) State {
 	// If the lookup table isn't large enough to contain the button's state, we
 	// are not aware of it so it's in the Up state.
 	b := int(button)
-	if b > len(w.states) {
+	if b >= len(w.states) {
 		return Up
 	}
 
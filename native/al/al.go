@@ -112,9 +112,10 @@ import "C"
 
 import (
 	"errors"
-	"log"
 	"sync"
 	"unsafe"
+
+	"azul3d.org/engine/log"
 )
 
 var (
@@ -132,8 +133,10 @@ var (
 var (
 	errorHandlerAccess sync.RWMutex
 	errorHandler       = func(e error) {
-		log.Println(e)
+		logger.Errorf("%v", e)
 	}
+
+	logger = log.New("openal")
 )
 
 func SetErrorHandler(f func(error)) {
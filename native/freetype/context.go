@@ -20,18 +20,28 @@ package freetype
 #include FT_FREETYPE_H
 #include FT_SIZES_H
 #include FT_GLYPH_H
+#include FT_OUTLINE_H
+
+// Accessors of outline arrays, whose element types differ between FreeType
+// versions.
+static int outlineContour(FT_Outline* o, int i) { return o->contours[i]; }
+static FT_Vector outlinePoint(FT_Outline* o, int i) { return o->points[i]; }
+static unsigned char outlineTag(FT_Outline* o, int i) { return o->tags[i]; }
 */
 import "C"
 
 import (
-	"fmt"
 	"image"
 	"reflect"
 	"runtime"
 	"sync"
 	"unsafe"
+
+	"azul3d.org/engine/log"
 )
 
+var logger = log.New("freetype")
+
 // GlyphMetrics contains metrics of a single glyph.
 type GlyphMetrics struct {
 	// Left side bearing and top side bearing
@@ -52,6 +62,12 @@ type GlyphMetrics struct {
 type GlyphImage struct {
 	*image.Alpha
 
+	// The offset of the image from the pen position: Left is the distance to
+	// it's left edge, and Top the distance from the baseline to it's top edge
+	// (positive values upward).
+	// Expressed in pixels.
+	Left, Top int
+
 	// Holds *Glyph to avoid GC.
 	glyph *Glyph
 }
@@ -61,6 +77,10 @@ type Glyph struct {
 	// Holds *Font to avoid GC.
 	font        *Font
 	renderImage func(g *Glyph) (*GlyphImage, error)
+	outline     *Outline
+
+	// The index of the glyph within the font.
+	Index uint
 
 	// Width and height of glyph.
 	// Expressed in font units.
@@ -81,6 +101,35 @@ func (g *Glyph) Image() (*GlyphImage, error) {
 	return g.renderImage(g)
 }
 
+// Outline returns the vector outline of the glyph, which may be used to
+// triangulate it or to generate a signed distance field. ErrInvalidGlyphFormat
+// is returned for glyphs which have no outline (e.g. those of bitmap fonts).
+func (g *Glyph) Outline() (*Outline, error) {
+	if g.outline == nil {
+		return nil, ErrInvalidGlyphFormat
+	}
+	return g.outline, nil
+}
+
+// copyOutline copies the given FreeType outline.
+func copyOutline(o *C.FT_Outline) *Outline {
+	n := int(o.n_points)
+	out := &Outline{
+		Points:   make([]image.Point, n),
+		Tags:     make([]uint8, n),
+		Contours: make([]int, int(o.n_contours)),
+	}
+	for i := 0; i < n; i++ {
+		p := C.outlinePoint(o, C.int(i))
+		out.Points[i] = image.Pt(int(p.x), int(p.y))
+		out.Tags[i] = uint8(C.outlineTag(o, C.int(i)))
+	}
+	for i := range out.Contours {
+		out.Contours[i] = int(C.outlineContour(o, C.int(i)))
+	}
+	return out
+}
+
 // Font represents a single Freetype font.
 type Font struct {
 	// Holds *Context to avoid GC.
@@ -89,6 +138,19 @@ type Font struct {
 	data []uint8
 	c    C.FT_Face
 
+	// The family and style names of the font face, e.g. "DejaVu Sans" and
+	// "Bold".
+	FamilyName, StyleName string
+
+	// Whether or not the font face is bold or italic.
+	Bold, Italic bool
+
+	// The number of glyphs in the font face.
+	NumGlyphs int
+
+	// The number of faces in the font file (e.g. in a TrueType collection).
+	NumFaces int
+
 	// Bounding box that is large enough to contain any glyph in the font face.
 	// Expressed in font units.
 	BBox image.Rectangle
@@ -137,7 +199,7 @@ func (f *Font) init() {
 
 	err := C.FT_Select_Charmap(f.c, C.FT_ENCODING_UNICODE)
 	if err != 0 {
-		fmt.Println("Font.init(): FT_Select_Charmap() failed!")
+		logger.Warnf("Font.init(): FT_Select_Charmap() failed")
 	}
 
 	b := f.c.bbox
@@ -156,6 +218,13 @@ func (f *Font) init() {
 	f.MaxAdvanceHeight = int(f.c.max_advance_height)
 	f.UnderlinePosition = int(f.c.underline_position)
 	f.UnderlineThickness = int(f.c.underline_thickness)
+
+	f.FamilyName = C.GoString(f.c.family_name)
+	f.StyleName = C.GoString(f.c.style_name)
+	f.Bold = f.c.style_flags&C.FT_STYLE_FLAG_BOLD != 0
+	f.Italic = f.c.style_flags&C.FT_STYLE_FLAG_ITALIC != 0
+	f.NumGlyphs = int(f.c.num_glyphs)
+	f.NumFaces = int(f.c.num_faces)
 }
 
 // SetSize sets the current size of the font given 26.6 width and height units
@@ -214,24 +283,39 @@ func (f *Font) Index(r rune) (glyphIndex uint) {
 	return uint(C.FT_Get_Char_Index(f.c, C.FT_ULong(r)))
 }
 
-// Kerning returns the X/Y kerning pair for the left and right horizontally
-// aligned glyphs, or x=0, y=0, and a error.
-func (f *Font) Kerning(leftGlyph, rightGlyph rune) (x, y int, e error) {
+// KerningMode is the mode in which kerning is returned.
+type KerningMode int
+
+const (
+	// KerningDefault returns kerning scaled and grid-fitted, in 26.6 pixel
+	// units.
+	KerningDefault KerningMode = iota
+
+	// KerningUnfitted returns kerning scaled but not grid-fitted, in 26.6
+	// pixel units.
+	KerningUnfitted
+
+	// KerningUnscaled returns kerning in font units.
+	KerningUnscaled
+)
+
+// HasKerning tells if the font face has kerning information.
+func (f *Font) HasKerning() bool {
+	return f.c.face_flags&C.FT_FACE_FLAG_KERNING != 0
+}
+
+// KerningIndex returns the X/Y kerning between the left and right glyph
+// indices, in the given mode, or x=0, y=0, and a error.
+func (f *Font) KerningIndex(left, right uint, mode KerningMode) (x, y int, e error) {
 	f.ctx.access.Lock()
 	defer f.ctx.access.Unlock()
 
-	left := C.FT_Get_Char_Index(f.c, C.FT_ULong(leftGlyph))
-	right := C.FT_Get_Char_Index(f.c, C.FT_ULong(rightGlyph))
-	if left == 0 || right == 0 {
-		return 0, 0, nil
-	}
-
 	var vec C.FT_Vector
 	err := C.FT_Get_Kerning(
 		f.c,
-		left,
-		right,
-		C.FT_KERNING_DEFAULT,
+		C.FT_UInt(left),
+		C.FT_UInt(right),
+		C.FT_UInt(mode),
 		&vec,
 	)
 	if err != 0 {
@@ -240,6 +324,55 @@ func (f *Font) Kerning(leftGlyph, rightGlyph rune) (x, y int, e error) {
 	return int(vec.x), int(vec.y), nil
 }
 
+// Kerning returns the X/Y kerning pair for the left and right horizontally
+// aligned glyphs, or x=0, y=0, and a error.
+func (f *Font) Kerning(leftGlyph, rightGlyph rune) (x, y int, e error) {
+	left, right := f.Index(leftGlyph), f.Index(rightGlyph)
+	if left == 0 || right == 0 {
+		return 0, 0, nil
+	}
+	return f.KerningIndex(left, right, KerningDefault)
+}
+
+// KerningPair is the kerning between two characters.
+type KerningPair struct {
+	Left, Right rune
+
+	// The kerning, in the mode it was requested in.
+	X, Y int
+}
+
+// KerningPairs returns the kerning between each ordered pair of the given
+// characters, in the given mode, omitting pairs which are not kerned.
+func (f *Font) KerningPairs(runes []rune, mode KerningMode) ([]KerningPair, error) {
+	if !f.HasKerning() {
+		return nil, nil
+	}
+	indices := make([]uint, len(runes))
+	for i, r := range runes {
+		indices[i] = f.Index(r)
+	}
+	var pairs []KerningPair
+	for i, left := range indices {
+		if left == 0 {
+			continue
+		}
+		for j, right := range indices {
+			if right == 0 {
+				continue
+			}
+			x, y, err := f.KerningIndex(left, right, mode)
+			if err != nil {
+				return nil, err
+			}
+			if x != 0 || y != 0 {
+				pairs = append(pairs, KerningPair{Left: runes[i], Right: runes[j], X: x, Y: y})
+			}
+		}
+	}
+	return pairs, nil
+}
+
 // Load loads the given glyph index into the font's glyph slot and returns the
 // glyph.
 func (f *Font) Load(glyphIndex uint) (*Glyph, error) {
@@ -280,13 +413,23 @@ func (f *Font) Load(glyphIndex uint) (*Glyph, error) {
 		return &GlyphImage{
 			glyph: glyph,
 			Alpha: img,
+			Left:  int(g.bitmap_left),
+			Top:   int(g.bitmap_top),
 		}, nil
 	}
 
+	// The face's glyph slot will change, so we need to copy the outline.
+	var outline *Outline
+	if g.format == C.FT_GLYPH_FORMAT_OUTLINE {
+		outline = copyOutline(&g.outline)
+	}
+
 	m := g.metrics
 	return &Glyph{
 		font:        f,
 		renderImage: renderImage,
+		outline:     outline,
+		Index:       glyphIndex,
 		Width:       int(m.width),
 		Height:      int(m.height),
 		HMetrics: GlyphMetrics{
@@ -314,6 +457,15 @@ type Context struct {
 // Load loads and returns the given font file data and returns the loaded font
 // or an error.
 func (c *Context) Load(fontFileData []byte) (*Font, error) {
+	return c.LoadFace(fontFileData, 0)
+}
+
+// LoadFace is like Load, except it loads the face with the given index of the
+// font file (e.g. of a TrueType collection, see Font.NumFaces).
+func (c *Context) LoadFace(fontFileData []byte, index int) (*Font, error) {
+	if len(fontFileData) == 0 {
+		return nil, ErrInvalidFileFormat
+	}
 	c.access.Lock()
 
 	f := new(Font)
@@ -323,10 +475,11 @@ func (c *Context) Load(fontFileData []byte) (*Font, error) {
 		c.c,
 		(*C.FT_Byte)(unsafe.Pointer(&f.data[0])),
 		C.FT_Long(len(f.data)),
-		0,
+		C.FT_Long(index),
 		&f.c,
 	)
 	if err != 0 {
+		c.access.Unlock()
 		return nil, lookupErr[int(err)]
 	}
 
@@ -3,4 +3,9 @@
 // license that can be found in the LICENSE file.
 
 // Package freetype is a wrapper around the FreeType font rendering library.
+//
+// Besides rendering glyph images it exposes glyph metrics, kerning and the
+// vector outlines of glyphs (e.g. for triangulated or signed distance field
+// text), and can enumerate the font faces installed on the system (see
+// SystemFontDirs and Context.Faces).
 package freetype
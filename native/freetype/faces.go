@@ -0,0 +1,152 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package freetype
+
+/*
+#include <stdlib.h>
+#include <ft2build.h>
+#include FT_FREETYPE_H
+*/
+import "C"
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"unsafe"
+)
+
+// FaceInfo describes a font face found in a font file.
+type FaceInfo struct {
+	// The path of the font file.
+	Path string
+
+	// The index of the face within the font file.
+	Index int
+
+	// The family and style names of the face, e.g. "DejaVu Sans" and "Bold".
+	Family, Style string
+
+	// Whether or not the face is bold or italic.
+	Bold, Italic bool
+}
+
+// fontExts are the extensions of font files that are enumerated.
+var fontExts = map[string]bool{
+	".ttf": true,
+	".ttc": true,
+	".otf": true,
+	".otc": true,
+	".pfb": true,
+}
+
+// SystemFontDirs returns the directories in which the operating system and
+// the user install fonts. Not all of them necessarily exist.
+func SystemFontDirs() []string {
+	home, _ := os.UserHomeDir()
+	switch runtime.GOOS {
+	case "windows":
+		dirs := []string{filepath.Join(os.Getenv("WINDIR"), "Fonts")}
+		if local := os.Getenv("LOCALAPPDATA"); local != "" {
+			dirs = append(dirs, filepath.Join(local, "Microsoft", "Windows", "Fonts"))
+		}
+		return dirs
+	case "darwin":
+		return []string{
+			"/System/Library/Fonts",
+			"/Library/Fonts",
+			filepath.Join(home, "Library", "Fonts"),
+		}
+	}
+	dirs := []string{"/usr/share/fonts", "/usr/local/share/fonts"}
+	if home != "" {
+		dirs = append(dirs, filepath.Join(home, ".fonts"), filepath.Join(home, ".local", "share", "fonts"))
+	}
+	return dirs
+}
+
+// faces returns the faces of the given font file.
+func (c *Context) faces(path string) []FaceInfo {
+	c.access.Lock()
+	defer c.access.Unlock()
+
+	cpath := C.CString(path)
+	defer C.free(unsafe.Pointer(cpath))
+
+	var faces []FaceInfo
+	for i, n := 0, 1; i < n; i++ {
+		var face C.FT_Face
+		if C.FT_New_Face(c.c, cpath, C.FT_Long(i), &face) != 0 {
+			break
+		}
+		n = int(face.num_faces)
+		faces = append(faces, FaceInfo{
+			Path:   path,
+			Index:  i,
+			Family: C.GoString(face.family_name),
+			Style:  C.GoString(face.style_name),
+			Bold:   face.style_flags&C.FT_STYLE_FLAG_BOLD != 0,
+			Italic: face.style_flags&C.FT_STYLE_FLAG_ITALIC != 0,
+		})
+		C.FT_Done_Face(face)
+	}
+	return faces
+}
+
+// Faces enumerates the font faces of the font files (TrueType, OpenType and
+// Type 1 fonts) found recursively in the given directories, or in the system
+// font directories (see SystemFontDirs) if none are given. Directories which
+// do not exist and files which FreeType cannot load are skipped. The faces are
+// sorted by family, style and then path.
+func (c *Context) Faces(dirs ...string) ([]FaceInfo, error) {
+	if len(dirs) == 0 {
+		dirs = SystemFontDirs()
+	}
+	var faces []FaceInfo
+	for _, dir := range dirs {
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) || os.IsPermission(err) {
+					return nil
+				}
+				return err
+			}
+			if info.IsDir() || !fontExts[strings.ToLower(filepath.Ext(path))] {
+				return nil
+			}
+			faces = append(faces, c.faces(path)...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(faces, func(i, j int) bool {
+		a, b := faces[i], faces[j]
+		if a.Family != b.Family {
+			return a.Family < b.Family
+		}
+		if a.Style != b.Style {
+			return a.Style < b.Style
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Index < b.Index
+	})
+	return faces, nil
+}
+
+// Open loads the font face described by the given info, see LoadFace.
+func (c *Context) Open(info FaceInfo) (*Font, error) {
+	data, err := ioutil.ReadFile(info.Path)
+	if err != nil {
+		return nil, err
+	}
+	return c.LoadFace(data, info.Index)
+}
@@ -5,9 +5,11 @@
 package freetype
 
 import (
+	"image"
 	"image/png"
 	"io/ioutil"
 	"os"
+	"reflect"
 	"testing"
 )
 
@@ -102,3 +104,151 @@ func TestFreetype(t *testing.T) {
 	}
 	t.Log("Wrote test_freetype_out.png file.")
 }
+
+func loadVera(t *testing.T) (*Context, *Font) {
+	ctx, err := Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile("vera/Vera.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	font, err := ctx.Load(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctx, font
+}
+
+func TestGlyphOutline(t *testing.T) {
+	_, font := loadVera(t)
+	if font.FamilyName != "Bitstream Vera Sans" || font.NumFaces != 1 || font.Bold || !font.HasKerning() {
+		t.Fatalf("family %q, %d faces", font.FamilyName, font.NumFaces)
+	}
+	if err := font.SetSizePixels(0, 32); err != nil {
+		t.Fatal(err)
+	}
+
+	// An 'O' has an outer and an inner contour.
+	glyph, err := font.Load(font.Index('O'))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if glyph.Index != font.Index('O') {
+		t.Fatalf("glyph index %d", glyph.Index)
+	}
+	outline, err := glyph.Outline()
+	if err != nil {
+		t.Fatal(err)
+	}
+	polys, err := outline.Flatten(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outline.Contours) != 2 || len(polys) != 2 {
+		t.Fatalf("%d contours, %d polygons", len(outline.Contours), len(polys))
+	}
+
+	img, err := glyph.Image()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Top <= 0 || img.Top > 32 {
+		t.Fatalf("image top %d", img.Top)
+	}
+
+	pairs, err := font.KerningPairs([]rune("AVT."), KerningUnscaled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var av bool
+	for _, p := range pairs {
+		if p.Left == 'A' && p.Right == 'V' && p.X < 0 {
+			av = true
+		}
+	}
+	if !av {
+		t.Fatalf("kerning pairs %v", pairs)
+	}
+}
+
+func TestFaces(t *testing.T) {
+	ctx, _ := loadVera(t)
+	faces, err := ctx.Faces("vera", "does-not-exist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(faces) != 10 {
+		t.Fatalf("%d faces", len(faces))
+	}
+	var boldItalic *FaceInfo
+	for i, f := range faces {
+		if f.Path == "vera/VeraBI.ttf" {
+			boldItalic = &faces[i]
+		}
+	}
+	if boldItalic == nil || !boldItalic.Bold || !boldItalic.Italic || boldItalic.Family != "Bitstream Vera Sans" {
+		t.Fatalf("bold italic face %+v", boldItalic)
+	}
+	font, err := ctx.Open(*boldItalic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if font.StyleName != boldItalic.Style {
+		t.Fatalf("style %q", font.StyleName)
+	}
+}
+
+func TestOutlineDecompose(t *testing.T) {
+	on, conic, cubic := uint8(tagOn), uint8(tagConic), uint8(tagCubic)
+	o := &Outline{
+		Points: []image.Point{
+			// A square with a rounded corner.
+			{0, 0}, {64, 0}, {64, 64}, {0, 64},
+
+			// Only conic points.
+			{0, 0}, {128, 0},
+
+			// A cubic curve.
+			{0, 0}, {0, 64}, {64, 64}, {64, 0},
+		},
+		Tags:     []uint8{on, on, conic, on, conic, conic, on, cubic, cubic, on},
+		Contours: []int{3, 5, 9},
+	}
+	segs, err := o.Decompose()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Segment{
+		{Op: MoveTo, Points: [3]image.Point{{0, 0}}},
+		{Op: LineTo, Points: [3]image.Point{{64, 0}}},
+		{Op: QuadTo, Points: [3]image.Point{{64, 64}, {0, 64}}},
+		{Op: LineTo, Points: [3]image.Point{{0, 0}}},
+
+		{Op: MoveTo, Points: [3]image.Point{{64, 0}}},
+		{Op: QuadTo, Points: [3]image.Point{{0, 0}, {64, 0}}},
+		{Op: QuadTo, Points: [3]image.Point{{128, 0}, {64, 0}}},
+
+		{Op: MoveTo, Points: [3]image.Point{{0, 0}}},
+		{Op: CubicTo, Points: [3]image.Point{{0, 64}, {64, 64}, {64, 0}}},
+		{Op: LineTo, Points: [3]image.Point{{0, 0}}},
+	}
+	if !reflect.DeepEqual(segs, want) {
+		t.Fatalf("got segments\n%v\nwant\n%v", segs, want)
+	}
+
+	// The rounded corner is approximated by two lines.
+	polys, err := o.Flatten(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(polys) != 3 || len(polys[0]) != 4 || polys[0][2].X != 0.75 || polys[0][2].Y != 0.75 {
+		t.Fatalf("polygons %v", polys)
+	}
+
+	o.Tags[0] = cubic
+	if _, err := o.Decompose(); err != ErrInvalidOutline {
+		t.Fatalf("decomposed an invalid outline, err=%v", err)
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package freetype
+
+import (
+	"image"
+
+	"azul3d.org/engine/lmath"
+)
+
+// Point tags of outlines, see FT_CURVE_TAG.
+const (
+	tagConic = 0
+	tagOn    = 1
+	tagCubic = 2
+)
+
+// Outline is the vector outline of a glyph, as stored by FreeType: points
+// grouped into closed contours, tagged as on or off the curve.
+type Outline struct {
+	// The points of the outline, with positive Y values upward.
+	// Expressed in 26.6 pixel units (or font units, for unscaled fonts).
+	Points []image.Point
+
+	// The tags of each point, see the FT_CURVE_TAG documentation of FreeType.
+	Tags []uint8
+
+	// The index of the last point of each contour.
+	Contours []int
+}
+
+// SegmentOp is the operation of an outline segment.
+type SegmentOp uint8
+
+const (
+	// MoveTo starts a new contour at Points[0].
+	MoveTo SegmentOp = iota
+
+	// LineTo draws a line to Points[0].
+	LineTo
+
+	// QuadTo draws a quadratic Bézier curve with control point Points[0] to
+	// Points[1].
+	QuadTo
+
+	// CubicTo draws a cubic Bézier curve with control points Points[0] and
+	// Points[1] to Points[2].
+	CubicTo
+)
+
+// Segment is a single segment of a decomposed outline.
+type Segment struct {
+	Op     SegmentOp
+	Points [3]image.Point
+}
+
+// tag returns the curve tag of the point at index i.
+func (o *Outline) tag(i int) uint8 {
+	return o.Tags[i] & 3
+}
+
+// Decompose decomposes the outline into segments: each contour begins with a
+// MoveTo and is closed by it's last segment ending at it's first point (as
+// FT_Outline_Decompose does). ErrInvalidOutline is returned if the outline is
+// malformed.
+func (o *Outline) Decompose() ([]Segment, error) {
+	if len(o.Tags) != len(o.Points) {
+		return nil, ErrInvalidOutline
+	}
+	var segs []Segment
+	add := func(op SegmentOp, pts ...image.Point) {
+		s := Segment{Op: op}
+		copy(s.Points[:], pts)
+		segs = append(segs, s)
+	}
+	mid := func(a, b image.Point) image.Point {
+		return a.Add(b).Div(2)
+	}
+
+	first := 0
+	for _, last := range o.Contours {
+		if last < first || last >= len(o.Points) {
+			return nil, ErrInvalidOutline
+		}
+		limit := last
+		start := o.Points[first]
+		point := first
+
+		switch o.tag(first) {
+		case tagCubic:
+			// A contour cannot start with a cubic control point.
+			return nil, ErrInvalidOutline
+		case tagConic:
+			// Start at the last point if it is on the curve, or else in the
+			// middle of the first and last points.
+			if o.tag(last) == tagOn {
+				start = o.Points[last]
+				limit--
+			} else {
+				start = mid(start, o.Points[last])
+			}
+			point--
+		}
+		add(MoveTo, start)
+
+		closed := false
+	contour:
+		for point < limit {
+			point++
+			switch o.tag(point) {
+			case tagOn:
+				add(LineTo, o.Points[point])
+
+			case tagConic:
+				control := o.Points[point]
+				for point < limit {
+					point++
+					p := o.Points[point]
+					switch o.tag(point) {
+					case tagOn:
+						add(QuadTo, control, p)
+						continue contour
+					case tagConic:
+						// Consecutive conic points imply an on point
+						// between them.
+						m := mid(control, p)
+						add(QuadTo, control, m)
+						control = p
+					default:
+						return nil, ErrInvalidOutline
+					}
+				}
+				add(QuadTo, control, start)
+				closed = true
+				break contour
+
+			default:
+				if point+1 > limit || o.tag(point+1) != tagCubic {
+					return nil, ErrInvalidOutline
+				}
+				c1, c2 := o.Points[point], o.Points[point+1]
+				point += 2
+				if point <= limit {
+					add(CubicTo, c1, c2, o.Points[point])
+					continue
+				}
+				add(CubicTo, c1, c2, start)
+				closed = true
+				break contour
+			}
+		}
+		if !closed {
+			add(LineTo, start)
+		}
+		first = last + 1
+	}
+	return segs, nil
+}
+
+// Flatten decomposes the outline and approximates it's curves with the given
+// number of line segments each, returning a closed polygon for each contour
+// (the last point of which is not repeated). Points are converted from 26.6
+// units, such that they are in pixels.
+func (o *Outline) Flatten(steps int) ([][]lmath.Vec2, error) {
+	segs, err := o.Decompose()
+	if err != nil {
+		return nil, err
+	}
+	if steps < 1 {
+		steps = 1
+	}
+	vec := func(p image.Point) lmath.Vec2 {
+		return lmath.Vec2{X: float64(p.X) / 64, Y: float64(p.Y) / 64}
+	}
+
+	var (
+		polys [][]lmath.Vec2
+		poly  []lmath.Vec2
+		pen   lmath.Vec2
+	)
+	for _, s := range segs {
+		switch s.Op {
+		case MoveTo:
+			if len(poly) > 0 {
+				polys = append(polys, poly)
+			}
+			pen = vec(s.Points[0])
+			poly = []lmath.Vec2{pen}
+			continue
+		case LineTo:
+			pen = vec(s.Points[0])
+			poly = append(poly, pen)
+		case QuadTo:
+			p0, c, p1 := pen, vec(s.Points[0]), vec(s.Points[1])
+			for i := 1; i <= steps; i++ {
+				t := float64(i) / float64(steps)
+				u := 1 - t
+				poly = append(poly, p0.MulScalar(u*u).Add(c.MulScalar(2*u*t)).Add(p1.MulScalar(t*t)))
+			}
+			pen = p1
+		case CubicTo:
+			p0, c1, c2, p1 := pen, vec(s.Points[0]), vec(s.Points[1]), vec(s.Points[2])
+			for i := 1; i <= steps; i++ {
+				t := float64(i) / float64(steps)
+				u := 1 - t
+				p := p0.MulScalar(u * u * u).Add(c1.MulScalar(3 * u * u * t))
+				poly = append(poly, p.Add(c2.MulScalar(3*u*t*t)).Add(p1.MulScalar(t*t*t)))
+			}
+			pen = p1
+		}
+	}
+	if len(poly) > 0 {
+		polys = append(polys, poly)
+	}
+
+	// Contours are closed by their last segment, drop the repeated point.
+	for i, p := range polys {
+		if n := len(p); n > 1 && p[n-1].Equals(p[0]) {
+			polys[i] = p[:n-1]
+		}
+	}
+	return polys, nil
+}
@@ -0,0 +1,42 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lua
+
+/*
+#include <stdint.h>
+#include <lua.h>
+*/
+import "C"
+
+import "fmt"
+
+//export goLuaCall
+func goLuaCall(L *C.lua_State, h C.uintptr_t) (n C.int) {
+	s := &State{c: L}
+	f, ok := handle(uintptr(h)).(Func)
+	if !ok {
+		s.PushString("attempt to call a released Go function")
+		return -1
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			s.PushString(fmt.Sprint("Go panic: ", r))
+			n = -1
+		}
+	}()
+	results, err := f(s)
+	if err != nil {
+		s.PushString(err.Error())
+		return -1
+	}
+	return C.int(results)
+}
+
+//export goLuaRelease
+func goLuaRelease(h C.uintptr_t) {
+	handles.Lock()
+	delete(handles.m, uintptr(h))
+	handles.Unlock()
+}
@@ -0,0 +1,32 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package lua is a wrapper around the Lua 5.3 scripting language.
+//
+// It exposes the Lua stack based C API closely, plus Go functions callable
+// from Lua (see PushFunc) and Go values held by Lua userdata (see PushGo):
+//
+//  s := lua.New()
+//  defer s.Close()
+//  s.Register("greet", func(s *lua.State) (int, error) {
+//      name, _ := s.ToString(1)
+//      s.PushString("Hello, " + name)
+//      return 1, nil
+//  })
+//  if err := s.DoString(`print(greet("world"))`, "hello"); err != nil {
+//      log.Fatal(err)
+//  }
+//
+// Errors raised by Lua code called through Call (or DoString) are returned
+// as *Error values. Go functions called from Lua report errors by returning
+// them, which raises a Lua error in the calling script.
+//
+// Lua errors raised directly inside API calls made from Go (e.g. an erroring
+// __index metamethod invoked by GetField) cannot be caught and abort the
+// program, so such calls should only be made on values whose metamethods are
+// known, or through Call.
+//
+// The Lua 5.3 library is linked using pkg-config on Linux (lua5.3), and as
+// -llua on other platforms.
+package lua // import "azul3d.org/engine/native/lua"
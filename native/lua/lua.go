@@ -0,0 +1,538 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lua
+
+/*
+#cgo linux pkg-config: lua5.3
+#cgo !linux LDFLAGS: -llua
+
+#include <stdint.h>
+#include <stdlib.h>
+#include <lua.h>
+#include <lauxlib.h>
+#include <lualib.h>
+
+extern int goLuaCall(lua_State *L, uintptr_t h);
+extern void goLuaRelease(uintptr_t h);
+
+// azul_trampoline calls the Go function whose handle is held by the first
+// upvalue. A negative result from Go indicates an error message was pushed.
+static int azul_trampoline(lua_State *L) {
+	uintptr_t *h = (uintptr_t*)lua_touserdata(L, lua_upvalueindex(1));
+	int n = goLuaCall(L, *h);
+	if(n < 0) {
+		return lua_error(L);
+	}
+	return n;
+}
+
+// azul_gc releases the handle held by the userdata being collected.
+static int azul_gc(lua_State *L) {
+	uintptr_t *h = (uintptr_t*)lua_touserdata(L, 1);
+	if(h != NULL) {
+		goLuaRelease(*h);
+	}
+	return 0;
+}
+
+// azul_traceback is the message handler of azul_pcall.
+static int azul_traceback(lua_State *L) {
+	const char *msg = lua_tostring(L, 1);
+	if(msg == NULL) {
+		msg = "(error object is not a string)";
+	}
+	luaL_traceback(L, L, msg, 1);
+	return 1;
+}
+
+static int azul_pcall(lua_State *L, int nargs, int nresults) {
+	int base = lua_gettop(L) - nargs;
+	lua_pushcfunction(L, azul_traceback);
+	lua_insert(L, base);
+	int r = lua_pcall(L, nargs, nresults, base);
+	lua_remove(L, base);
+	return r;
+}
+
+static void *azul_pushhandle(lua_State *L, uintptr_t h) {
+	uintptr_t *p = (uintptr_t*)lua_newuserdata(L, sizeof(uintptr_t));
+	*p = h;
+	return p;
+}
+
+// azul_gohandle returns the handle held by the userdata at index i, or NULL
+// if it is not userdata created by pushHandle.
+static uintptr_t *azul_gohandle(lua_State *L, int i) {
+	if(lua_type(L, i) != LUA_TUSERDATA || !lua_getmetatable(L, i)) {
+		return NULL;
+	}
+	lua_getfield(L, -1, "__gc");
+	int ok = lua_tocfunction(L, -1) == azul_gc;
+	lua_pop(L, 2);
+	return ok ? (uintptr_t*)lua_touserdata(L, i) : NULL;
+}
+
+static void azul_pushfunc(lua_State *L) {
+	lua_pushcclosure(L, azul_trampoline, 1);
+}
+
+static void azul_pushgc(lua_State *L) {
+	lua_pushcfunction(L, azul_gc);
+}
+
+static int azul_registryindex() {
+	return LUA_REGISTRYINDEX;
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"unsafe"
+)
+
+// Type is the type of a Lua value.
+type Type int
+
+// Lua value types.
+const (
+	TypeNone          Type = C.LUA_TNONE
+	TypeNil           Type = C.LUA_TNIL
+	TypeBoolean       Type = C.LUA_TBOOLEAN
+	TypeLightUserdata Type = C.LUA_TLIGHTUSERDATA
+	TypeNumber        Type = C.LUA_TNUMBER
+	TypeString        Type = C.LUA_TSTRING
+	TypeTable         Type = C.LUA_TTABLE
+	TypeFunction      Type = C.LUA_TFUNCTION
+	TypeUserdata      Type = C.LUA_TUSERDATA
+	TypeThread        Type = C.LUA_TTHREAD
+)
+
+// String returns the Lua name of the type, e.g. "table".
+func (t Type) String() string {
+	switch t {
+	case TypeNone:
+		return "no value"
+	case TypeNil:
+		return "nil"
+	case TypeBoolean:
+		return "boolean"
+	case TypeLightUserdata, TypeUserdata:
+		return "userdata"
+	case TypeNumber:
+		return "number"
+	case TypeString:
+		return "string"
+	case TypeTable:
+		return "table"
+	case TypeFunction:
+		return "function"
+	case TypeThread:
+		return "thread"
+	}
+	return fmt.Sprintf("Type(%d)", int(t))
+}
+
+// Error is an error raised by Lua code.
+type Error struct {
+	// The error message, including a stack traceback.
+	Msg string
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return "lua: " + e.Msg
+}
+
+// ErrClosed is returned when a closed state is used.
+var ErrClosed = errors.New("lua: state is closed")
+
+// Func is a Go function callable from Lua. Arguments are on the stack at
+// indices 1 to Top(), and the function pushes it's results and returns the
+// number of them. Returning an error raises it as a Lua error instead.
+type Func func(s *State) (int, error)
+
+// The metatable names used for Go functions and values.
+const (
+	funcMeta  = "azul3d.func"
+	valueMeta = "azul3d.value"
+)
+
+// handles holds the Go functions and values referenced by Lua userdata.
+var handles = struct {
+	sync.Mutex
+	m    map[uintptr]interface{}
+	next uintptr
+}{m: make(map[uintptr]interface{})}
+
+func newHandle(v interface{}) uintptr {
+	handles.Lock()
+	handles.next++
+	h := handles.next
+	handles.m[h] = v
+	handles.Unlock()
+	return h
+}
+
+func handle(h uintptr) interface{} {
+	handles.Lock()
+	v := handles.m[h]
+	handles.Unlock()
+	return v
+}
+
+// RegistryIndex is the pseudo-index of the Lua registry.
+var RegistryIndex = int(C.azul_registryindex())
+
+// State is a Lua state (i.e. an interpreter). It's methods are not safe for
+// access from multiple goroutines concurrently.
+type State struct {
+	c *C.lua_State
+}
+
+// Close closes the state, running the finalizers of it's values.
+func (s *State) Close() {
+	if s.c != nil {
+		C.lua_close(s.c)
+		s.c = nil
+	}
+}
+
+// Load loads the given chunk of Lua source code, pushing it as a function if
+// it compiles, or returning the compilation error otherwise.
+func (s *State) Load(code []byte, chunkName string) error {
+	if s.c == nil {
+		return ErrClosed
+	}
+	cname := C.CString(chunkName)
+	defer C.free(unsafe.Pointer(cname))
+	cmode := C.CString("t")
+	defer C.free(unsafe.Pointer(cmode))
+	var buf *C.char
+	if len(code) > 0 {
+		buf = (*C.char)(unsafe.Pointer(&code[0]))
+	}
+	if C.luaL_loadbufferx(s.c, buf, C.size_t(len(code)), cname, cmode) != C.LUA_OK {
+		return s.popError()
+	}
+	return nil
+}
+
+// Call calls the function on the stack below it's nargs arguments in
+// protected mode, replacing them with nresults results (or all of them, if
+// nresults is MultRet). On error the function and it's arguments are popped
+// and the error (with a traceback) is returned.
+func (s *State) Call(nargs, nresults int) error {
+	if s.c == nil {
+		return ErrClosed
+	}
+	if C.azul_pcall(s.c, C.int(nargs), C.int(nresults)) != C.LUA_OK {
+		return s.popError()
+	}
+	return nil
+}
+
+// MultRet is passed to Call to keep all of the results.
+const MultRet = C.LUA_MULTRET
+
+// popError pops the error message on the top of the stack.
+func (s *State) popError() error {
+	msg, ok := s.ToString(-1)
+	if !ok {
+		msg = s.Type(-1).String()
+	}
+	s.Pop(1)
+	return &Error{Msg: msg}
+}
+
+// DoString loads and runs the given Lua source code, discarding any results.
+func (s *State) DoString(code, chunkName string) error {
+	if err := s.Load([]byte(code), chunkName); err != nil {
+		return err
+	}
+	return s.Call(0, 0)
+}
+
+// Top returns the index of the value on the top of the stack, i.e. the
+// number of values on the stack.
+func (s *State) Top() int {
+	return int(C.lua_gettop(s.c))
+}
+
+// SetTop sets the top of the stack, popping values or pushing nils.
+func (s *State) SetTop(i int) {
+	C.lua_settop(s.c, C.int(i))
+}
+
+// Pop pops n values from the stack.
+func (s *State) Pop(n int) {
+	C.lua_settop(s.c, C.int(-n-1))
+}
+
+// AbsIndex converts the (possibly negative) stack index i to an absolute
+// one.
+func (s *State) AbsIndex(i int) int {
+	return int(C.lua_absindex(s.c, C.int(i)))
+}
+
+// PushValue pushes a copy of the value at index i.
+func (s *State) PushValue(i int) {
+	C.lua_pushvalue(s.c, C.int(i))
+}
+
+// Remove removes the value at index i, shifting the values above it down.
+func (s *State) Remove(i int) {
+	C.lua_rotate(s.c, C.int(i), -1)
+	s.Pop(1)
+}
+
+// Type returns the type of the value at index i, or TypeNone if the index is
+// not valid.
+func (s *State) Type(i int) Type {
+	return Type(C.lua_type(s.c, C.int(i)))
+}
+
+// PushNil pushes nil.
+func (s *State) PushNil() {
+	C.lua_pushnil(s.c)
+}
+
+// PushBool pushes a boolean.
+func (s *State) PushBool(b bool) {
+	v := C.int(0)
+	if b {
+		v = 1
+	}
+	C.lua_pushboolean(s.c, v)
+}
+
+// PushNumber pushes a floating point number.
+func (s *State) PushNumber(n float64) {
+	C.lua_pushnumber(s.c, C.lua_Number(n))
+}
+
+// PushInteger pushes an integer.
+func (s *State) PushInteger(n int64) {
+	C.lua_pushinteger(s.c, C.lua_Integer(n))
+}
+
+// PushString pushes a string.
+func (s *State) PushString(str string) {
+	cs := C.CString(str)
+	C.lua_pushlstring(s.c, cs, C.size_t(len(str)))
+	C.free(unsafe.Pointer(cs))
+}
+
+// pushHandle pushes userdata holding a handle of v, with the given
+// metatable which releases the handle when it is collected.
+func (s *State) pushHandle(v interface{}, meta string) {
+	C.azul_pushhandle(s.c, C.uintptr_t(newHandle(v)))
+	s.NewGoMetatable(meta)
+	C.lua_setmetatable(s.c, -2)
+}
+
+// PushFunc pushes a Go function.
+func (s *State) PushFunc(f Func) {
+	s.pushHandle(f, funcMeta)
+	C.azul_pushfunc(s.c)
+}
+
+// PushGo pushes userdata holding the Go value v, with the given metatable
+// (which is created as with NewGoMetatable if it does not exist). ToGo
+// returns the value.
+func (s *State) PushGo(v interface{}, metatable string) {
+	if metatable == "" {
+		metatable = valueMeta
+	}
+	s.pushHandle(v, metatable)
+}
+
+// NewGoMetatable pushes the metatable with the given name from the registry,
+// creating it if it does not exist. Created metatables have a __gc
+// metamethod which releases the Go values of userdata (see PushGo), which
+// must not be replaced. It returns true if the metatable was created.
+func (s *State) NewGoMetatable(name string) bool {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	if C.luaL_newmetatable(s.c, cname) == 0 {
+		return false
+	}
+	C.azul_pushgc(s.c)
+	s.SetField(-2, "__gc")
+	return true
+}
+
+// ToBool returns the truthiness of the value at index i: false for nil and
+// false, true otherwise.
+func (s *State) ToBool(i int) bool {
+	return C.lua_toboolean(s.c, C.int(i)) != 0
+}
+
+// ToNumber converts the value at index i to a number, returning false if it
+// is not a number or a string convertible to one.
+func (s *State) ToNumber(i int) (float64, bool) {
+	var ok C.int
+	n := C.lua_tonumberx(s.c, C.int(i), &ok)
+	return float64(n), ok != 0
+}
+
+// ToInteger converts the value at index i to an integer, returning false if
+// it is not an integral number or a string convertible to one.
+func (s *State) ToInteger(i int) (int64, bool) {
+	var ok C.int
+	n := C.lua_tointegerx(s.c, C.int(i), &ok)
+	return int64(n), ok != 0
+}
+
+// IsInteger tells if the value at index i is a number with an integer
+// representation.
+func (s *State) IsInteger(i int) bool {
+	return C.lua_isinteger(s.c, C.int(i)) != 0
+}
+
+// ToString returns the value at index i as a string, returning false if it
+// is not a string or a number. Note that numbers are converted to strings in
+// place, as with lua_tolstring.
+func (s *State) ToString(i int) (string, bool) {
+	var n C.size_t
+	cs := C.lua_tolstring(s.c, C.int(i), &n)
+	if cs == nil {
+		return "", false
+	}
+	return C.GoStringN(cs, C.int(n)), true
+}
+
+// ToGo returns the Go value held by the userdata at index i (see PushGo),
+// returning false if it is not such userdata.
+func (s *State) ToGo(i int) (interface{}, bool) {
+	p := C.azul_gohandle(s.c, C.int(i))
+	if p == nil {
+		return nil, false
+	}
+	v := handle(uintptr(*p))
+	if _, ok := v.(Func); ok {
+		return nil, false
+	}
+	return v, v != nil
+}
+
+// NewTable pushes a new, empty, table.
+func (s *State) NewTable() {
+	C.lua_createtable(s.c, 0, 0)
+}
+
+// GetField pushes t[k], where t is the value at index i, and returns it's
+// type. Metamethods may be invoked.
+func (s *State) GetField(i int, k string) Type {
+	ck := C.CString(k)
+	defer C.free(unsafe.Pointer(ck))
+	return Type(C.lua_getfield(s.c, C.int(i), ck))
+}
+
+// SetField sets t[k] to the value on the top of the stack, which is popped,
+// where t is the value at index i. Metamethods may be invoked.
+func (s *State) SetField(i int, k string) {
+	ck := C.CString(k)
+	defer C.free(unsafe.Pointer(ck))
+	C.lua_setfield(s.c, C.int(i), ck)
+}
+
+// GetIndex pushes t[n], where t is the value at index i, and returns it's
+// type. Metamethods may be invoked.
+func (s *State) GetIndex(i int, n int64) Type {
+	return Type(C.lua_geti(s.c, C.int(i), C.lua_Integer(n)))
+}
+
+// SetIndex sets t[n] to the value on the top of the stack, which is popped,
+// where t is the value at index i. Metamethods may be invoked.
+func (s *State) SetIndex(i int, n int64) {
+	C.lua_seti(s.c, C.int(i), C.lua_Integer(n))
+}
+
+// GetTable pushes t[k], where t is the value at index i and k is the value
+// on the top of the stack (which is popped), and returns it's type.
+// Metamethods may be invoked.
+func (s *State) GetTable(i int) Type {
+	return Type(C.lua_gettable(s.c, C.int(i)))
+}
+
+// SetTable sets t[k] to v, where t is the value at index i and v and k are
+// the values on the top of the stack and just below it (both are popped).
+// Metamethods may be invoked.
+func (s *State) SetTable(i int) {
+	C.lua_settable(s.c, C.int(i))
+}
+
+// Next pops a key and pushes the next key-value pair of the table at index
+// i, returning false (and pushing nothing) when there are no more. Iteration
+// begins by pushing a nil key.
+func (s *State) Next(i int) bool {
+	return C.lua_next(s.c, C.int(i)) != 0
+}
+
+// RawLen returns the length of the string, table or userdata at index i,
+// without invoking metamethods.
+func (s *State) RawLen(i int) int {
+	return int(C.lua_rawlen(s.c, C.int(i)))
+}
+
+// GetGlobal pushes the value of the named global variable, and returns it's
+// type.
+func (s *State) GetGlobal(name string) Type {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	return Type(C.lua_getglobal(s.c, cname))
+}
+
+// SetGlobal pops a value from the stack and sets it as the named global
+// variable.
+func (s *State) SetGlobal(name string) {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	C.lua_setglobal(s.c, cname)
+}
+
+// Register sets the Go function as the named global variable.
+func (s *State) Register(name string, f Func) {
+	s.PushFunc(f)
+	s.SetGlobal(name)
+}
+
+// SetMetatable pops a table from the stack and sets it as the metatable of
+// the value at index i.
+func (s *State) SetMetatable(i int) {
+	C.lua_setmetatable(s.c, C.int(i))
+}
+
+// GetMetatable pushes the metatable of the value at index i and returns
+// true, or pushes nothing and returns false if it has none.
+func (s *State) GetMetatable(i int) bool {
+	return C.lua_getmetatable(s.c, C.int(i)) != 0
+}
+
+// Ref pops a value from the stack and stores it in the registry, returning a
+// reference to it for use with PushRef and Unref.
+func (s *State) Ref() int {
+	return int(C.luaL_ref(s.c, C.int(RegistryIndex)))
+}
+
+// PushRef pushes the value stored in the registry with the given reference.
+func (s *State) PushRef(ref int) {
+	C.lua_rawgeti(s.c, C.int(RegistryIndex), C.lua_Integer(ref))
+}
+
+// Unref releases the given reference, see Ref.
+func (s *State) Unref(ref int) {
+	C.luaL_unref(s.c, C.int(RegistryIndex), C.int(ref))
+}
+
+// New returns a new Lua state with the standard libraries opened.
+func New() *State {
+	s := &State{c: C.luaL_newstate()}
+	C.luaL_openlibs(s.c)
+	return s
+}
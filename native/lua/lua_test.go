@@ -0,0 +1,95 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lua
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestState(t *testing.T) {
+	s := New()
+	defer s.Close()
+
+	s.Register("add", func(s *State) (int, error) {
+		a, _ := s.ToNumber(1)
+		b, _ := s.ToNumber(2)
+		s.PushNumber(a + b)
+		return 1, nil
+	})
+	s.Register("fail", func(s *State) (int, error) {
+		return 0, errors.New("failed in Go")
+	})
+	if err := s.DoString(`x = add(2, 3.5); t = {name = "lua", 10, 20}`, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if s.GetGlobal("x") != TypeNumber {
+		t.Fatal("x is not a number")
+	}
+	if x, _ := s.ToNumber(-1); x != 5.5 {
+		t.Fatalf("x = %v", x)
+	}
+	s.Pop(1)
+
+	s.GetGlobal("t")
+	if s.GetField(-1, "name"); s.Type(-1) != TypeString {
+		t.Fatal("t.name is not a string")
+	}
+	if name, _ := s.ToString(-1); name != "lua" || s.RawLen(-2) != 2 {
+		t.Fatalf("t.name = %q, #t = %d", name, s.RawLen(-2))
+	}
+	s.Pop(2)
+	if s.Top() != 0 {
+		t.Fatalf("%d values left on the stack", s.Top())
+	}
+
+	// Errors raised by Go functions and Lua code.
+	err := s.DoString(`fail()`, "test")
+	if e, ok := err.(*Error); !ok || !strings.Contains(e.Msg, "failed in Go") {
+		t.Fatalf("got error %v", err)
+	}
+	if err := s.DoString(`error("boom")`, "test"); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("got error %v", err)
+	}
+	if err := s.DoString(`this is not lua`, "test"); err == nil {
+		t.Fatal("expected a syntax error")
+	}
+}
+
+func TestGoValues(t *testing.T) {
+	s := New()
+	defer s.Close()
+
+	type thing struct{ n int }
+	v := &thing{n: 7}
+	s.PushGo(v, "")
+	s.SetGlobal("thing")
+	s.GetGlobal("thing")
+	got, ok := s.ToGo(-1)
+	if !ok || got != v {
+		t.Fatalf("got %v", got)
+	}
+	s.Pop(1)
+
+	// Functions and plain userdata are not Go values.
+	s.PushFunc(func(s *State) (int, error) { return 0, nil })
+	if _, ok := s.ToGo(-1); ok {
+		t.Fatal("function is a Go value")
+	}
+	s.Pop(1)
+
+	// Values are released when collected.
+	if err := s.DoString(`thing = nil; collectgarbage()`, "test"); err != nil {
+		t.Fatal(err)
+	}
+	handles.Lock()
+	defer handles.Unlock()
+	for _, h := range handles.m {
+		if h == v {
+			t.Fatal("value not released")
+		}
+	}
+}
@@ -0,0 +1,394 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nav
+
+import (
+	"errors"
+	"math"
+
+	"azul3d.org/engine/lmath"
+)
+
+// ErrNoWalkableArea is returned by Build when the level geometry has no area
+// that an agent can walk on.
+var ErrNoWalkableArea = errors.New("nav: no walkable area")
+
+// Config describes how a navigation mesh is built.
+type Config struct {
+	// The horizontal size and the height of the voxels that the level
+	// geometry is voxelized into. Smaller voxels capture more detail, but
+	// take longer to build.
+	CellSize, CellHeight float64
+
+	// The height of the agent, i.e. the clearance it needs above the ground.
+	AgentHeight float64
+
+	// The radius of the agent, i.e. the distance it keeps from walls and
+	// ledges.
+	AgentRadius float64
+
+	// The height of the tallest step (e.g. stairs) the agent can climb.
+	AgentMaxClimb float64
+
+	// The steepest angle of the ground (in radians) the agent can walk on.
+	AgentMaxSlope float64
+}
+
+// DefaultConfig is the default configuration, for human sized agents in a
+// level whose units are meters.
+var DefaultConfig = Config{
+	CellSize:      0.3,
+	CellHeight:    0.2,
+	AgentHeight:   2,
+	AgentRadius:   0.6,
+	AgentMaxClimb: 0.9,
+	AgentMaxSlope: lmath.Radians(45),
+}
+
+// cell is an open (walkable) cell of the heightfield, the space above the
+// top of a walkable span.
+type cell struct {
+	x, y int
+
+	// The floor and ceiling, in units of the cell height, and the height of
+	// the floor relative to the heightfield origin.
+	floor, ceil int
+	top         float64
+
+	// The index of the neighboring cell in each direction, or -1.
+	conn [4]int
+
+	// The polygon the cell belongs to, or -1.
+	poly int
+
+	removed bool
+}
+
+// The directions of cell connections, in counter-clockwise order.
+const (
+	dirPosX = iota
+	dirPosY
+	dirNegX
+	dirNegY
+)
+
+var (
+	dirX = [4]int{1, 0, -1, 0}
+	dirY = [4]int{0, 1, 0, -1}
+)
+
+// builder holds the state of building a navigation mesh.
+type builder struct {
+	cfg                Config
+	hf                 *heightfield
+	height, climb, rad int
+
+	cells []cell
+
+	// The range of cells in each column of the heightfield.
+	columns [][2]int
+}
+
+// Build builds a navigation mesh from the level geometry: the given vertices,
+// and indices of each triangle's three vertices. If indices is nil, each
+// three consecutive vertices form a triangle. Triangles may face either way.
+func Build(vertices []lmath.Vec3, indices []uint32, cfg Config) (*Mesh, error) {
+	var tris [][3]lmath.Vec3
+	if indices == nil {
+		for i := 0; i+2 < len(vertices); i += 3 {
+			tris = append(tris, [3]lmath.Vec3{vertices[i], vertices[i+1], vertices[i+2]})
+		}
+	} else {
+		for i := 0; i+2 < len(indices); i += 3 {
+			tris = append(tris, [3]lmath.Vec3{
+				vertices[indices[i]],
+				vertices[indices[i+1]],
+				vertices[indices[i+2]],
+			})
+		}
+	}
+	if len(tris) == 0 {
+		return nil, ErrNoWalkableArea
+	}
+
+	bounds := lmath.Rect3{Min: tris[0][0], Max: tris[0][0]}
+	for _, t := range tris {
+		for _, v := range t {
+			bounds.Min = bounds.Min.Min(v)
+			bounds.Max = bounds.Max.Max(v)
+		}
+	}
+
+	b := &builder{
+		cfg:    cfg,
+		hf:     newHeightfield(bounds, cfg.CellSize, cfg.CellHeight),
+		height: int(math.Ceil(cfg.AgentHeight / cfg.CellHeight)),
+		climb:  int(math.Floor(cfg.AgentMaxClimb / cfg.CellHeight)),
+		rad:    int(math.Ceil(cfg.AgentRadius / cfg.CellSize)),
+	}
+
+	// Voxelize the triangles, marking the surfaces flat enough to walk on.
+	minNormalZ := math.Cos(cfg.AgentMaxSlope)
+	for _, t := range tris {
+		n, ok := t[1].Sub(t[0]).Cross(t[2].Sub(t[0])).Normalized()
+		walkable := ok && math.Abs(n.Z) >= minNormalZ-lmath.EPSILON
+		b.hf.rasterize(t[0], t[1], t[2], walkable)
+	}
+	b.hf.filterLowHanging(b.climb)
+
+	b.buildCells()
+	b.erode()
+	m := b.buildPolygons()
+	if len(m.Polygons) == 0 {
+		return nil, ErrNoWalkableArea
+	}
+	return m, nil
+}
+
+// buildCells builds the open cells above the walkable spans with enough
+// clearance for an agent, and connects neighboring cells an agent can step
+// between.
+func (b *builder) buildCells() {
+	hf := b.hf
+	b.columns = make([][2]int, len(hf.columns))
+	for y := 0; y < hf.height; y++ {
+		for x := 0; x < hf.width; x++ {
+			i := x + y*hf.width
+			col := hf.columns[i]
+			b.columns[i][0] = len(b.cells)
+			for j, s := range col {
+				if !s.walkable {
+					continue
+				}
+				ceil := math.MaxInt32
+				if j+1 < len(col) {
+					ceil = col[j+1].min
+				}
+				if ceil-s.max < b.height {
+					continue
+				}
+				b.cells = append(b.cells, cell{
+					x: x, y: y,
+					floor: s.max, ceil: ceil, top: s.top,
+					conn: [4]int{-1, -1, -1, -1},
+					poly: -1,
+				})
+			}
+			b.columns[i][1] = len(b.cells)
+		}
+	}
+
+	for ci := range b.cells {
+		c := &b.cells[ci]
+		for dir := 0; dir < 4; dir++ {
+			nx, ny := c.x+dirX[dir], c.y+dirY[dir]
+			if nx < 0 || ny < 0 || nx >= hf.width || ny >= hf.height {
+				continue
+			}
+			col := b.columns[nx+ny*hf.width]
+			for ni := col[0]; ni < col[1]; ni++ {
+				n := &b.cells[ni]
+				bottom, top := c.floor, c.ceil
+				if n.floor > bottom {
+					bottom = n.floor
+				}
+				if n.ceil < top {
+					top = n.ceil
+				}
+				if top-bottom >= b.height && abs(n.floor-c.floor) <= b.climb {
+					c.conn[dir] = ni
+					break
+				}
+			}
+		}
+	}
+}
+
+// erode removes the cells closer to a border (a wall, a ledge or the edge of
+// the level) than the agent radius.
+func (b *builder) erode() {
+	if b.rad <= 0 {
+		return
+	}
+
+	// Find the distance of each cell from the nearest border cell, one which
+	// is not connected in all directions.
+	dist := make([]int, len(b.cells))
+	var queue []int
+	for ci, c := range b.cells {
+		dist[ci] = -1
+		for _, n := range c.conn {
+			if n < 0 {
+				dist[ci] = 0
+				queue = append(queue, ci)
+				break
+			}
+		}
+	}
+	for len(queue) > 0 {
+		ci := queue[0]
+		queue = queue[1:]
+		if dist[ci]+1 >= b.rad {
+			continue
+		}
+		for _, n := range b.cells[ci].conn {
+			if n >= 0 && dist[n] < 0 {
+				dist[n] = dist[ci] + 1
+				queue = append(queue, n)
+			}
+		}
+	}
+
+	for ci := range b.cells {
+		if dist[ci] >= 0 && dist[ci] < b.rad {
+			b.cells[ci].removed = true
+		}
+	}
+	for ci := range b.cells {
+		c := &b.cells[ci]
+		for dir, n := range c.conn {
+			if n >= 0 && (c.removed || b.cells[n].removed) {
+				c.conn[dir] = -1
+			}
+		}
+	}
+}
+
+// free tells if the cell exists, is not yet part of a polygon, and has about
+// the same floor height as the reference cell.
+func (b *builder) free(ci, ref int) bool {
+	if ci < 0 {
+		return false
+	}
+	c := &b.cells[ci]
+	return !c.removed && c.poly < 0 && abs(c.floor-b.cells[ref].floor) <= b.climb
+}
+
+// buildPolygons covers the remaining cells with rectangular polygons, each
+// grown greedily from it's first cell, and connects them with portals.
+func (b *builder) buildPolygons() *Mesh {
+	var rects [][][]int
+	for ci := range b.cells {
+		if !b.free(ci, ci) {
+			continue
+		}
+
+		// Grow along +X, then add rows along +Y for as long as the whole row
+		// is free and connected.
+		row := []int{ci}
+		for {
+			n := b.cells[row[len(row)-1]].conn[dirPosX]
+			if !b.free(n, ci) {
+				break
+			}
+			row = append(row, n)
+		}
+		rows := [][]int{row}
+	grow:
+		for {
+			prev := rows[len(rows)-1]
+			next := make([]int, len(prev))
+			for i, p := range prev {
+				n := b.cells[p].conn[dirPosY]
+				if !b.free(n, ci) || (i > 0 && b.cells[next[i-1]].conn[dirPosX] != n) {
+					break grow
+				}
+				next[i] = n
+			}
+			rows = append(rows, next)
+		}
+
+		id := len(rects)
+		for _, r := range rows {
+			for _, c := range r {
+				b.cells[c].poly = id
+			}
+		}
+		rects = append(rects, rows)
+	}
+
+	m := &Mesh{Config: b.cfg}
+	for id, rows := range rects {
+		m.Polygons = append(m.Polygons, b.polygon(id, rows))
+	}
+	m.init()
+	return m
+}
+
+// polygon returns the polygon of the rectangle of cells (in rows along +Y of
+// cells along +X), including it's portals to other polygons.
+func (b *builder) polygon(id int, rows [][]int) Polygon {
+	first, last := rows[0], rows[len(rows)-1]
+	corners := [4]int{first[0], first[len(first)-1], last[len(last)-1], last[0]}
+	p := Polygon{Vertices: make([]lmath.Vec3, 4)}
+	for i, ci := range corners {
+		c := &b.cells[ci]
+		x, y := c.x, c.y
+		if i == 1 || i == 2 {
+			x++
+		}
+		if i >= 2 {
+			y++
+		}
+		p.Vertices[i] = b.point(x, y, c.top)
+	}
+
+	// Walk the sides in counter-clockwise order, merging adjacent cell edges
+	// leading to the same polygon into a single portal.
+	var sides [4][]int
+	for _, r := range rows {
+		sides[dirPosX] = append(sides[dirPosX], r[len(r)-1])
+		sides[dirNegX] = append([]int{r[0]}, sides[dirNegX]...)
+	}
+	sides[dirNegY] = first
+	for i := len(last) - 1; i >= 0; i-- {
+		sides[dirPosY] = append(sides[dirPosY], last[i])
+	}
+	for _, dir := range []int{dirNegY, dirPosX, dirPosY, dirNegX} {
+		for _, ci := range sides[dir] {
+			c := &b.cells[ci]
+			ni := c.conn[dir]
+			if ni < 0 || b.cells[ni].poly == id {
+				continue
+			}
+			n := &b.cells[ni]
+			a, e := b.edge(c, dir)
+			z := b.hf.origin.Z + (c.top+n.top)/2
+			a.Z, e.Z = z, z
+			if k := len(p.Portals) - 1; k >= 0 && p.Portals[k].Polygon == n.poly && p.Portals[k].B.AlmostEquals(a, lmath.EPSILON) {
+				p.Portals[k].B = e
+				continue
+			}
+			p.Portals = append(p.Portals, Portal{Polygon: n.poly, A: a, B: e})
+		}
+	}
+	return p
+}
+
+// edge returns the endpoints of the cell's edge in the given direction, in
+// counter-clockwise order around the cell.
+func (b *builder) edge(c *cell, dir int) (a, e lmath.Vec3) {
+	x, y := c.x, c.y
+	switch dir {
+	case dirNegY:
+		return b.point(x, y, 0), b.point(x+1, y, 0)
+	case dirPosX:
+		return b.point(x+1, y, 0), b.point(x+1, y+1, 0)
+	case dirPosY:
+		return b.point(x+1, y+1, 0), b.point(x, y+1, 0)
+	}
+	return b.point(x, y+1, 0), b.point(x, y, 0)
+}
+
+// point returns the world space point at the given cell corner, and height
+// relative to the heightfield origin.
+func (b *builder) point(x, y int, z float64) lmath.Vec3 {
+	hf := b.hf
+	return lmath.Vec3{
+		X: hf.origin.X + float64(x)*hf.cs,
+		Y: hf.origin.Y + float64(y)*hf.cs,
+		Z: hf.origin.Z + z,
+	}
+}
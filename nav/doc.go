@@ -0,0 +1,39 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package nav implements navigation meshes and pathfinding for AI agents.
+//
+// Build generates a navigation mesh from level geometry in a way similar to
+// Mikko Mononen's Recast: the triangles are voxelized into a heightfield,
+// the walkable surfaces (with enough clearance above them for an agent to
+// stand) are connected where an agent can step between them, and the area
+// closer to any wall or ledge than the agent's radius is eroded away. The
+// remaining area is covered with convex polygons, connected by portals
+// where they share an edge.
+//
+//  mesh, err := nav.Build(vertices, indices, nav.DefaultConfig)
+//  if err != nil {
+//      // Handle error.
+//  }
+//  path, err := mesh.FindPath(agent.Position, target)
+//  if err != nil {
+//      // Handle error (e.g. nav.ErrNoPath).
+//  }
+//  for _, wp := range path {
+//      // Walk to wp.Point, or traverse wp.Link (e.g. jump) if it is not nil.
+//  }
+//
+// FindPath searches the polygons with A*, and smooths the resulting corridor
+// of polygons into a short path with the funnel algorithm.
+//
+// Off-mesh links (see Link) connect two points of the mesh that an agent
+// cannot walk between, such as the top and bottom of a ledge, or the ends of
+// a ladder or teleporter. They can be added and removed at any time (e.g. as
+// a door opens or a bridge collapses).
+//
+// Building a navigation mesh is slow, so meshes are typically built ahead of
+// time and stored alongside the level (see Mesh.Save and Load).
+//
+// As with the rest of the engine, the world is Z-up.
+package nav // import "azul3d.org/engine/nav"
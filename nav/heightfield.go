@@ -0,0 +1,196 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nav
+
+import (
+	"math"
+
+	"azul3d.org/engine/lmath"
+)
+
+// span is a solid vertical range of voxels in a heightfield column, in units
+// of the cell height.
+type span struct {
+	min, max int
+
+	// The height of the top surface, relative to the heightfield's origin.
+	top float64
+
+	// Whether the top of the span is a walkable surface.
+	walkable bool
+}
+
+// heightfield is a voxelized representation of the level geometry: a grid of
+// columns, each of which holds the solid spans within it, in ascending
+// order.
+type heightfield struct {
+	origin        lmath.Vec3
+	width, height int
+	cs, ch        float64
+	columns       [][]span
+}
+
+// newHeightfield returns a new empty heightfield covering the given bounds.
+func newHeightfield(bounds lmath.Rect3, cs, ch float64) *heightfield {
+	size := bounds.Size()
+	h := &heightfield{
+		origin: bounds.Min,
+		width:  int(math.Ceil(size.X/cs)) + 1,
+		height: int(math.Ceil(size.Y/cs)) + 1,
+		cs:     cs,
+		ch:     ch,
+	}
+	h.columns = make([][]span, h.width*h.height)
+	return h
+}
+
+// addSpan adds a span to the column at (x, y), merging it with the spans it
+// overlaps.
+func (h *heightfield) addSpan(x, y int, s span) {
+	col := h.columns[x+y*h.width]
+	i := 0
+	for i < len(col) {
+		cur := col[i]
+		if cur.min > s.max {
+			break
+		}
+		if cur.max < s.min {
+			i++
+			continue
+		}
+
+		// Merge the overlapping span, the walkable flag is that of the top
+		// surface (or of either if their tops are about equal).
+		if cur.min < s.min {
+			s.min = cur.min
+		}
+		either := s.walkable || cur.walkable
+		near := abs(cur.max-s.max) <= 1
+		if cur.max > s.max || (cur.max == s.max && cur.top > s.top) {
+			s.max, s.top, s.walkable = cur.max, cur.top, cur.walkable
+		}
+		if near {
+			s.walkable = either
+		}
+		col = append(col[:i], col[i+1:]...)
+	}
+	col = append(col, span{})
+	copy(col[i+1:], col[i:])
+	col[i] = s
+	h.columns[x+y*h.width] = col
+}
+
+// rasterize adds the spans covered by the triangle (a, b, c) to the
+// heightfield.
+func (h *heightfield) rasterize(a, b, c lmath.Vec3, walkable bool) {
+	min := a.Min(b).Min(c).Sub(h.origin)
+	max := a.Max(b).Max(c).Sub(h.origin)
+	x0 := clampInt(int(math.Floor(min.X/h.cs)), 0, h.width-1)
+	x1 := clampInt(int(math.Floor(max.X/h.cs)), 0, h.width-1)
+	y0 := clampInt(int(math.Floor(min.Y/h.cs)), 0, h.height-1)
+	y1 := clampInt(int(math.Floor(max.Y/h.cs)), 0, h.height-1)
+
+	tri := []lmath.Vec3{a.Sub(h.origin), b.Sub(h.origin), c.Sub(h.origin)}
+	for y := y0; y <= y1; y++ {
+		row := clip(tri, axisY, float64(y)*h.cs, float64(y+1)*h.cs)
+		if len(row) < 3 {
+			continue
+		}
+		for x := x0; x <= x1; x++ {
+			cell := clip(row, axisX, float64(x)*h.cs, float64(x+1)*h.cs)
+			if len(cell) < 3 {
+				continue
+			}
+			zmin, zmax := cell[0].Z, cell[0].Z
+			for _, p := range cell[1:] {
+				zmin = math.Min(zmin, p.Z)
+				zmax = math.Max(zmax, p.Z)
+			}
+			s := span{
+				min:      int(math.Floor(zmin / h.ch)),
+				max:      int(math.Ceil(zmax / h.ch)),
+				top:      zmax,
+				walkable: walkable,
+			}
+			if s.max <= s.min {
+				s.max = s.min + 1
+			}
+			h.addSpan(x, y, s)
+		}
+	}
+}
+
+// filterLowHanging marks non-walkable spans as walkable when they are low
+// enough above a walkable span to be stepped onto (e.g. curbs and stair
+// steps, whose sides are not walkable).
+func (h *heightfield) filterLowHanging(climb int) {
+	for _, col := range h.columns {
+		prevWalkable := false
+		prevMax := 0
+		for i := range col {
+			s := &col[i]
+			walkable := s.walkable
+			if !s.walkable && prevWalkable && s.max-prevMax <= climb {
+				s.walkable = true
+			}
+			prevWalkable, prevMax = walkable, s.max
+		}
+	}
+}
+
+const (
+	axisX = iota
+	axisY
+)
+
+// component returns the X or Y component of the vector.
+func component(v lmath.Vec3, axis int) float64 {
+	if axis == axisX {
+		return v.X
+	}
+	return v.Y
+}
+
+// clip clips the convex polygon to the slab lo <= v[axis] <= hi.
+func clip(poly []lmath.Vec3, axis int, lo, hi float64) []lmath.Vec3 {
+	poly = clipPlane(poly, axis, lo, 1)
+	return clipPlane(poly, axis, hi, -1)
+}
+
+// clipPlane clips the convex polygon to the half space where
+// sign*(v[axis]-d) >= 0.
+func clipPlane(poly []lmath.Vec3, axis int, d, sign float64) []lmath.Vec3 {
+	out := make([]lmath.Vec3, 0, len(poly)+1)
+	for i, a := range poly {
+		b := poly[(i+1)%len(poly)]
+		da := sign * (component(a, axis) - d)
+		db := sign * (component(b, axis) - d)
+		if da >= 0 {
+			out = append(out, a)
+		}
+		if (da > 0 && db < 0) || (da < 0 && db > 0) {
+			t := da / (da - db)
+			out = append(out, a.Add(b.Sub(a).MulScalar(t)))
+		}
+	}
+	return out
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func clampInt(x, min, max int) int {
+	if x < min {
+		return min
+	}
+	if x > max {
+		return max
+	}
+	return x
+}
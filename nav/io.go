@@ -0,0 +1,159 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nav
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"azul3d.org/engine/lmath"
+)
+
+// ErrInvalidData is returned by Load when the data is not a valid navigation
+// mesh.
+var ErrInvalidData = errors.New("nav: input data is invalid or corrupt")
+
+const (
+	// The magic bytes at the start of a saved navigation mesh, and the version
+	// of the format.
+	magic   = "AZNAVMSH"
+	version = 1
+)
+
+// Save writes the navigation mesh, including it's off-mesh links, to the
+// given writer in a compact little-endian binary format (see Load).
+func (m *Mesh) Save(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	e := &encoder{w: bw}
+	e.write([]byte(magic))
+	e.write(uint32(version))
+	e.write(m.Config)
+	e.write(uint32(len(m.Polygons)))
+	for _, p := range m.Polygons {
+		e.write(uint32(len(p.Vertices)))
+		e.write(p.Vertices)
+		e.write(uint32(len(p.Portals)))
+		for _, portal := range p.Portals {
+			e.write(uint32(portal.Polygon))
+			e.write(portal.A)
+			e.write(portal.B)
+		}
+	}
+	e.write(uint32(len(m.links)))
+	for _, l := range m.links {
+		e.write(l.Start)
+		e.write(l.End)
+		e.write(l.Bidirectional)
+		e.write(l.Cost)
+	}
+	if e.err != nil {
+		return e.err
+	}
+	return bw.Flush()
+}
+
+// Load reads a navigation mesh saved by Mesh.Save from the given reader. If
+// the data is not a valid navigation mesh, ErrInvalidData is returned.
+func Load(r io.Reader) (*Mesh, error) {
+	d := &decoder{r: bufio.NewReader(r)}
+	var head [len(magic)]byte
+	var ver uint32
+	d.read(&head)
+	d.read(&ver)
+	if d.err == nil && (string(head[:]) != magic || ver != version) {
+		return nil, ErrInvalidData
+	}
+
+	m := &Mesh{}
+	d.read(&m.Config)
+	m.Polygons = make([]Polygon, d.count())
+	for i := range m.Polygons {
+		p := &m.Polygons[i]
+		p.Vertices = make([]lmath.Vec3, d.count())
+		d.read(p.Vertices)
+		if d.err == nil && len(p.Vertices) < 3 {
+			return nil, ErrInvalidData
+		}
+		p.Portals = make([]Portal, d.count())
+		for j := range p.Portals {
+			var poly uint32
+			d.read(&poly)
+			d.read(&p.Portals[j].A)
+			d.read(&p.Portals[j].B)
+			if d.err == nil && int(poly) >= len(m.Polygons) {
+				return nil, ErrInvalidData
+			}
+			p.Portals[j].Polygon = int(poly)
+		}
+	}
+	links := make([]*Link, d.count())
+	for i := range links {
+		l := &Link{}
+		d.read(&l.Start)
+		d.read(&l.End)
+		d.read(&l.Bidirectional)
+		d.read(&l.Cost)
+		links[i] = l
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	m.init()
+	for _, l := range links {
+		if err := m.AddLink(l); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// encoder writes binary data, remembering the first error.
+type encoder struct {
+	w   io.Writer
+	err error
+}
+
+func (e *encoder) write(v interface{}) {
+	if e.err == nil {
+		e.err = binary.Write(e.w, binary.LittleEndian, v)
+	}
+}
+
+// decoder reads binary data, remembering the first error.
+type decoder struct {
+	r   io.Reader
+	err error
+}
+
+func (d *decoder) read(v interface{}) {
+	if d.err != nil {
+		return
+	}
+	d.err = binary.Read(d.r, binary.LittleEndian, v)
+	if d.err == io.EOF || d.err == io.ErrUnexpectedEOF {
+		d.err = ErrInvalidData
+	}
+}
+
+// maxCount is the largest element count accepted by the decoder, which
+// avoids huge allocations for corrupt data.
+const maxCount = 1 << 24
+
+// count reads an element count.
+func (d *decoder) count() int {
+	var n uint32
+	d.read(&n)
+	if d.err != nil {
+		return 0
+	}
+	if n > maxCount {
+		d.err = ErrInvalidData
+		return 0
+	}
+	return int(n)
+}
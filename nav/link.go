@@ -0,0 +1,77 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nav
+
+import "azul3d.org/engine/lmath"
+
+// Link is an off-mesh link, a connection between two points of a navigation
+// mesh that an agent cannot simply walk between: e.g. jumping down a ledge,
+// climbing a ladder or using a teleporter.
+type Link struct {
+	// The start and end points of the link.
+	Start, End lmath.Vec3
+
+	// Whether the link can also be traversed from it's end to it's start.
+	Bidirectional bool
+
+	// The cost of traversing the link, in the same units as the distances
+	// walked on the mesh. If zero, the distance between the ends is used.
+	Cost float64
+
+	// The polygons the ends of the link are on.
+	startPoly, endPoly int
+}
+
+// cost returns the cost of traversing the link.
+func (l *Link) cost() float64 {
+	if l.Cost != 0 {
+		return l.Cost
+	}
+	return l.End.Sub(l.Start).Length()
+}
+
+// AddLink adds the off-mesh link to the mesh, and snaps it's ends to the mesh.
+// If either end is not on the mesh, ErrNotOnMesh is returned.
+//
+// The link must not be modified until it is removed.
+func (m *Mesh) AddLink(l *Link) error {
+	start, sp, err := m.snap(l.Start)
+	if err != nil {
+		return err
+	}
+	end, ep, err := m.snap(l.End)
+	if err != nil {
+		return err
+	}
+	l.Start, l.End = sp, ep
+	l.startPoly, l.endPoly = start, end
+	m.links = append(m.links, l)
+	m.polyLinks[start] = append(m.polyLinks[start], l)
+	if l.Bidirectional && end != start {
+		m.polyLinks[end] = append(m.polyLinks[end], l)
+	}
+	return nil
+}
+
+// RemoveLink removes the off-mesh link from the mesh.
+func (m *Mesh) RemoveLink(l *Link) {
+	m.links = removeLink(m.links, l)
+	m.polyLinks[l.startPoly] = removeLink(m.polyLinks[l.startPoly], l)
+	m.polyLinks[l.endPoly] = removeLink(m.polyLinks[l.endPoly], l)
+}
+
+// Links returns the off-mesh links of the mesh.
+func (m *Mesh) Links() []*Link {
+	return append([]*Link(nil), m.links...)
+}
+
+func removeLink(links []*Link, l *Link) []*Link {
+	for i, other := range links {
+		if other == l {
+			return append(links[:i:i], links[i+1:]...)
+		}
+	}
+	return links
+}
@@ -0,0 +1,171 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nav
+
+import (
+	"errors"
+	"math"
+
+	"azul3d.org/engine/collision"
+	"azul3d.org/engine/lmath"
+)
+
+// ErrNotOnMesh is returned when a point is not on (or near) the navigation
+// mesh.
+var ErrNotOnMesh = errors.New("nav: point is not on the navigation mesh")
+
+// Portal is an edge shared by two polygons, through which an agent can walk
+// from one to the other.
+type Portal struct {
+	// The index of the polygon on the other side of the portal.
+	Polygon int
+
+	// The endpoints of the portal, in counter-clockwise order around the
+	// polygon it belongs to (as seen from above).
+	A, B lmath.Vec3
+}
+
+// Polygon is a convex polygon of a navigation mesh.
+type Polygon struct {
+	// The vertices of the polygon, in counter-clockwise order (as seen from
+	// above).
+	Vertices []lmath.Vec3
+
+	// The portals to the neighboring polygons.
+	Portals []Portal
+}
+
+// Center returns the center of the polygon.
+func (p *Polygon) Center() lmath.Vec3 {
+	var c lmath.Vec3
+	for _, v := range p.Vertices {
+		c = c.Add(v)
+	}
+	return c.DivScalar(float64(len(p.Vertices)))
+}
+
+// Bounds returns the bounding box of the polygon.
+func (p *Polygon) Bounds() lmath.Rect3 {
+	b := lmath.Rect3{Min: p.Vertices[0], Max: p.Vertices[0]}
+	for _, v := range p.Vertices[1:] {
+		b.Min = b.Min.Min(v)
+		b.Max = b.Max.Max(v)
+	}
+	return b
+}
+
+// height returns the height of the polygon's surface at the given point, and
+// whether the point is inside of the polygon (as seen from above).
+func (p *Polygon) height(pt lmath.Vec3) (z float64, inside bool) {
+	v := p.Vertices
+	for i := range v {
+		if cross2(v[(i+1)%len(v)].Sub(v[i]), pt.Sub(v[i])) < -lmath.EPSILON {
+			return 0, false
+		}
+	}
+
+	// Interpolate the height across the triangle fan containing the point.
+	for i := 1; i+1 < len(v); i++ {
+		a, b, c := v[0], v[i], v[i+1]
+		area := cross2(b.Sub(a), c.Sub(a))
+		if area <= 0 {
+			continue
+		}
+		u := cross2(c.Sub(b), pt.Sub(b)) / area
+		w := cross2(a.Sub(c), pt.Sub(c)) / area
+		if u >= -lmath.EPSILON && w >= -lmath.EPSILON && u+w <= 1+lmath.EPSILON {
+			return a.Z*u + b.Z*w + c.Z*(1-u-w), true
+		}
+	}
+	return v[0].Z, true
+}
+
+// closest returns the point of the polygon closest to the given one.
+func (p *Polygon) closest(pt lmath.Vec3) lmath.Vec3 {
+	if z, inside := p.height(pt); inside {
+		return lmath.Vec3{X: pt.X, Y: pt.Y, Z: z}
+	}
+	best, bestDist := p.Vertices[0], math.Inf(1)
+	for i, a := range p.Vertices {
+		q := closestOnSegment(pt, a, p.Vertices[(i+1)%len(p.Vertices)])
+		if d := q.Sub(pt).LengthSq(); d < bestDist {
+			best, bestDist = q, d
+		}
+	}
+	return best
+}
+
+// Mesh is a navigation mesh.
+//
+// Queries may be made concurrently by multiple goroutines, but not while
+// links are being added or removed.
+type Mesh struct {
+	// The configuration the mesh was built with.
+	Config Config
+
+	// The polygons of the mesh.
+	Polygons []Polygon
+
+	bvh   *collision.BVH
+	links []*Link
+
+	// The links leaving each polygon.
+	polyLinks map[int][]*Link
+}
+
+// init builds the search structures of the mesh, once it's polygons are
+// complete.
+func (m *Mesh) init() {
+	bounds := make([]lmath.Rect3, len(m.Polygons))
+	for i := range m.Polygons {
+		bounds[i] = m.Polygons[i].Bounds()
+	}
+	m.bvh = collision.NewBVH(bounds)
+	m.polyLinks = make(map[int][]*Link)
+}
+
+// Nearest returns the index of the polygon nearest to the given point, and
+// the nearest point on it, searching up to maxDist away.
+func (m *Mesh) Nearest(p lmath.Vec3, maxDist float64) (poly int, q lmath.Vec3, ok bool) {
+	box := lmath.Rect3{Min: p, Max: p}.Inset(-maxDist)
+	bestDist := maxDist * maxDist
+	poly = -1
+	m.bvh.Query(box, func(i int) bool {
+		c := m.Polygons[i].closest(p)
+		if d := c.Sub(p).LengthSq(); d <= bestDist {
+			poly, q, bestDist = i, c, d
+		}
+		return true
+	})
+	return poly, q, poly >= 0
+}
+
+// snap returns the nearest polygon and point to p, within the distance at
+// which points are considered to be on the mesh: up to the agent height
+// away.
+func (m *Mesh) snap(p lmath.Vec3) (int, lmath.Vec3, error) {
+	poly, q, ok := m.Nearest(p, m.Config.AgentHeight)
+	if !ok {
+		return -1, p, ErrNotOnMesh
+	}
+	return poly, q, nil
+}
+
+// cross2 returns the Z component of the cross product of a and b, i.e. the
+// cross product of their projections onto the XY plane.
+func cross2(a, b lmath.Vec3) float64 {
+	return a.X*b.Y - a.Y*b.X
+}
+
+// closestOnSegment returns the point of the line segment (a, b) closest to p.
+func closestOnSegment(p, a, b lmath.Vec3) lmath.Vec3 {
+	ab := b.Sub(a)
+	l := ab.LengthSq()
+	if l == 0 {
+		return a
+	}
+	t := lmath.Clamp(p.Sub(a).Dot(ab)/l, 0, 1)
+	return a.Add(ab.MulScalar(t))
+}
@@ -0,0 +1,259 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nav
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"azul3d.org/engine/lmath"
+)
+
+// quad returns the vertices of the two triangles of the quad (a, b, c, d).
+func quad(a, b, c, d lmath.Vec3) []lmath.Vec3 {
+	return []lmath.Vec3{a, b, c, a, c, d}
+}
+
+// level returns the vertices of a 20x20 meter floor at Z=0 and the given
+// boxes (with their minimum and maximum corners).
+func level(boxes ...[2]lmath.Vec3) []lmath.Vec3 {
+	vertices := quad(
+		lmath.Vec3{X: -10, Y: -10},
+		lmath.Vec3{X: 10, Y: -10},
+		lmath.Vec3{X: 10, Y: 10},
+		lmath.Vec3{X: -10, Y: 10},
+	)
+	for _, b := range boxes {
+		v := func(x, y, z int) lmath.Vec3 {
+			return lmath.Vec3{X: b[x].X, Y: b[y].Y, Z: b[z].Z}
+		}
+		vertices = append(vertices, quad(v(0, 0, 1), v(1, 0, 1), v(1, 1, 1), v(0, 1, 1))...)
+		vertices = append(vertices, quad(v(0, 0, 0), v(1, 0, 0), v(1, 0, 1), v(0, 0, 1))...)
+		vertices = append(vertices, quad(v(0, 1, 0), v(1, 1, 0), v(1, 1, 1), v(0, 1, 1))...)
+		vertices = append(vertices, quad(v(0, 0, 0), v(0, 1, 0), v(0, 1, 1), v(0, 0, 1))...)
+		vertices = append(vertices, quad(v(1, 0, 0), v(1, 1, 0), v(1, 1, 1), v(1, 0, 1))...)
+	}
+	return vertices
+}
+
+func build(t *testing.T, vertices []lmath.Vec3) *Mesh {
+	m, err := Build(vertices, nil, DefaultConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// pathLength returns the length of the path, as seen from above.
+func pathLength(path []Waypoint) float64 {
+	var l float64
+	for i := 1; i < len(path); i++ {
+		d := path[i].Point.Sub(path[i-1].Point)
+		l += math.Hypot(d.X, d.Y)
+	}
+	return l
+}
+
+func TestBuild(t *testing.T) {
+	m := build(t, level())
+
+	// The floor is eroded by the agent radius.
+	var area float64
+	for _, p := range m.Polygons {
+		b := p.Bounds()
+		area += (b.Max.X - b.Min.X) * (b.Max.Y - b.Min.Y)
+		for _, portal := range p.Portals {
+			if portal.Polygon < 0 || portal.Polygon >= len(m.Polygons) {
+				t.Fatalf("portal to polygon %d", portal.Polygon)
+			}
+		}
+	}
+	want := (20 - 2*DefaultConfig.AgentRadius) * (20 - 2*DefaultConfig.AgentRadius)
+	if math.Abs(area-want) > 2*20*DefaultConfig.CellSize {
+		t.Fatalf("area %v, want about %v", area, want)
+	}
+
+	poly, q, ok := m.Nearest(lmath.Vec3{X: 1, Y: 2, Z: 0.5}, 1)
+	if !ok || !q.AlmostEquals(lmath.Vec3{X: 1, Y: 2}, 1e-6) {
+		t.Fatalf("Nearest = %v, %v, %v", poly, q, ok)
+	}
+	if _, _, ok := m.Nearest(lmath.Vec3{X: 15}, 1); ok {
+		t.Fatal("Nearest found a polygon off the mesh")
+	}
+
+	wall := []lmath.Vec3{{}, {X: 1}, {X: 1, Z: 1}}
+	if _, err := Build(wall, nil, DefaultConfig); err != ErrNoWalkableArea {
+		t.Fatalf("Build of a wall: %v", err)
+	}
+}
+
+func TestFindPath(t *testing.T) {
+	// A wall across the middle of the floor, with a gap at Y > 5.
+	m := build(t, level([2]lmath.Vec3{{X: -1, Y: -10}, {X: 1, Y: 5, Z: 3}}))
+
+	start, end := lmath.Vec3{X: -5}, lmath.Vec3{X: 5}
+	path, err := m.FindPath(start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !path[0].Point.AlmostEquals(start, 1e-6) || !path[len(path)-1].Point.AlmostEquals(end, 1e-6) {
+		t.Fatalf("path %v does not go from %v to %v", path, start, end)
+	}
+
+	// The shortest path goes around the end of the wall, which it keeps at
+	// least the agent radius away from.
+	r, cs := DefaultConfig.AgentRadius, DefaultConfig.CellSize
+	want := 2*math.Hypot(4, 5+r) + 2
+	if len(path) < 4 || math.Abs(pathLength(path)-want) > 1 {
+		t.Fatalf("path %v has length %v, want about %v", path, pathLength(path), want)
+	}
+	for _, wp := range path[1 : len(path)-1] {
+		if wp.Point.Y < 5+r-2*cs || math.Abs(wp.Point.X) > 1+r+2*cs {
+			t.Fatalf("corner %v of path %v is not near the end of the wall", wp.Point, path)
+		}
+	}
+
+	// A straight path in the open.
+	path, err = m.FindPath(lmath.Vec3{X: -5, Y: 8}, lmath.Vec3{X: 5, Y: 8})
+	if err != nil || len(path) != 2 {
+		t.Fatalf("FindPath = %v, %v; want a straight path", path, err)
+	}
+
+	if _, err := m.FindPath(lmath.Vec3{X: -5, Z: 10}, end); err != ErrNotOnMesh {
+		t.Fatalf("FindPath from off the mesh: %v", err)
+	}
+}
+
+func TestLinks(t *testing.T) {
+	// A wall dividing the floor in two.
+	m := build(t, level([2]lmath.Vec3{{X: -1, Y: -10}, {X: 1, Y: 10, Z: 3}}))
+	start, end := lmath.Vec3{X: -5}, lmath.Vec3{X: 5}
+	if _, err := m.FindPath(start, end); err != ErrNoPath {
+		t.Fatalf("FindPath through the wall: %v", err)
+	}
+
+	// A teleporter through the wall.
+	l := &Link{Start: lmath.Vec3{X: -3, Y: 3}, End: lmath.Vec3{X: 3, Y: 3}, Cost: 1}
+	if err := m.AddLink(l); err != nil {
+		t.Fatal(err)
+	}
+	path, err := m.FindPath(start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(path) != 4 || path[1].Link != l || !path[1].Point.AlmostEquals(l.Start, 1e-6) || !path[2].Point.AlmostEquals(l.End, 1e-6) {
+		t.Fatalf("path %v does not use the link", path)
+	}
+	if _, err := m.FindPath(end, start); err != ErrNoPath {
+		t.Fatalf("FindPath back through the one-way link: %v", err)
+	}
+	l2 := &Link{Start: lmath.Vec3{X: -3, Y: -3}, End: lmath.Vec3{X: 3, Y: -3}, Bidirectional: true}
+	if err := m.AddLink(l2); err != nil {
+		t.Fatal(err)
+	}
+	path, err = m.FindPath(end, start)
+	if err != nil || path[1].Link != l2 || !path[1].Point.AlmostEquals(l2.End, 1e-6) {
+		t.Fatalf("FindPath back through the bidirectional link = %v, %v", path, err)
+	}
+
+	m.RemoveLink(l)
+	m.RemoveLink(l2)
+	if _, err := m.FindPath(start, end); err != ErrNoPath {
+		t.Fatalf("FindPath through removed links: %v", err)
+	}
+	if err := m.AddLink(&Link{Start: lmath.Vec3{X: 50}, End: end}); err != ErrNotOnMesh {
+		t.Fatalf("AddLink off the mesh: %v", err)
+	}
+}
+
+func TestFindPathLevels(t *testing.T) {
+	// A platform at Z=2, reached by a 20 degree ramp along +X.
+	rise := 2.0
+	run := rise / math.Tan(lmath.Radians(20))
+	vertices := level([2]lmath.Vec3{{X: 2, Y: -10}, {X: 10, Y: 10, Z: rise}})
+	vertices = append(vertices, quad(
+		lmath.Vec3{X: 2 - run, Y: -4},
+		lmath.Vec3{X: 2, Y: -4, Z: rise},
+		lmath.Vec3{X: 2, Y: -2, Z: rise},
+		lmath.Vec3{X: 2 - run, Y: -2},
+	)...)
+	m := build(t, vertices)
+
+	end := lmath.Vec3{X: 6, Y: 5, Z: rise}
+	path, err := m.FindPath(lmath.Vec3{X: -8, Y: 5}, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := path[len(path)-1].Point
+	if !lmath.AlmostEqual(last.Z, rise, 0.1) {
+		t.Fatalf("path %v ends at %v, want the platform", path, last)
+	}
+	// The path must climb the ramp.
+	onRamp := false
+	for _, wp := range path {
+		if wp.Point.Y > -4 && wp.Point.Y < -2 && wp.Point.X < 2 {
+			onRamp = true
+		}
+	}
+	if !onRamp {
+		t.Fatalf("path %v does not climb the ramp", path)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	m := build(t, level([2]lmath.Vec3{{X: -1, Y: -10}, {X: 1, Y: 10, Z: 3}}))
+	if err := m.AddLink(&Link{Start: lmath.Vec3{X: -3}, End: lmath.Vec3{X: 3}}); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := m.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	loaded, err := Load(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Polygons) != len(m.Polygons) || len(loaded.Links()) != 1 || loaded.Config != m.Config {
+		t.Fatalf("loaded %d polygons and %d links", len(loaded.Polygons), len(loaded.Links()))
+	}
+
+	start, end := lmath.Vec3{X: -5, Y: 5}, lmath.Vec3{X: 5, Y: -5}
+	want, err := m.FindPath(start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := loaded.FindPath(start, end)
+	if err != nil || len(got) != len(want) {
+		t.Fatalf("loaded FindPath = %v, %v; want %v", got, err, want)
+	}
+	for i := range got {
+		if !got[i].Point.AlmostEquals(want[i].Point, 1e-9) || (got[i].Link == nil) != (want[i].Link == nil) {
+			t.Fatalf("loaded FindPath = %v, want %v", got, want)
+		}
+	}
+
+	if _, err := Load(bytes.NewReader(data[:len(data)/2])); err != ErrInvalidData {
+		t.Fatalf("Load of truncated data: %v", err)
+	}
+	if _, err := Load(bytes.NewReader([]byte("not a mesh at all"))); err != ErrInvalidData {
+		t.Fatalf("Load of garbage: %v", err)
+	}
+}
+
+func BenchmarkBuild(b *testing.B) {
+	vertices := level([2]lmath.Vec3{{X: -1, Y: -10}, {X: 1, Y: 5, Z: 3}})
+	for i := 0; i < b.N; i++ {
+		Build(vertices, nil, DefaultConfig)
+	}
+}
+
+func BenchmarkFindPath(b *testing.B) {
+	m, _ := Build(level([2]lmath.Vec3{{X: -1, Y: -10}, {X: 1, Y: 5, Z: 3}}), nil, DefaultConfig)
+	for i := 0; i < b.N; i++ {
+		m.FindPath(lmath.Vec3{X: -5}, lmath.Vec3{X: 5})
+	}
+}
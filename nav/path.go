@@ -0,0 +1,232 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nav
+
+import (
+	"container/heap"
+	"errors"
+	"math"
+
+	"azul3d.org/engine/lmath"
+)
+
+// ErrNoPath is returned by FindPath when there is no path between the two
+// points.
+var ErrNoPath = errors.New("nav: no path")
+
+// Waypoint is a point of a path.
+type Waypoint struct {
+	// The point on the navigation mesh.
+	Point lmath.Vec3
+
+	// The off-mesh link which leads from this waypoint to the next one, or
+	// nil if the agent walks there in a straight line.
+	Link *Link
+}
+
+// step is a step of a path through the polygons of a mesh: leaving a polygon
+// through a portal, or traversing a link (possibly in reverse).
+type step struct {
+	portal   *Portal
+	link     *Link
+	reversed bool
+}
+
+// searchNode is the A* search state of a polygon.
+type searchNode struct {
+	g      float64
+	pos    lmath.Vec3
+	parent int
+	via    step
+	closed bool
+}
+
+// searchItem is an entry of the A* open list.
+type searchItem struct {
+	poly int
+	f    float64
+}
+
+type openList []searchItem
+
+func (o openList) Len() int            { return len(o) }
+func (o openList) Less(i, j int) bool  { return o[i].f < o[j].f }
+func (o openList) Swap(i, j int)       { o[i], o[j] = o[j], o[i] }
+func (o *openList) Push(x interface{}) { *o = append(*o, x.(searchItem)) }
+func (o *openList) Pop() interface{} {
+	old := *o
+	x := old[len(old)-1]
+	*o = old[:len(old)-1]
+	return x
+}
+
+// FindPath finds the shortest path from start to end, which are snapped to
+// the nearest points on the mesh (up to the agent height away, otherwise
+// ErrNotOnMesh is returned).
+//
+// The returned path begins at the start point and ends at the end point,
+// with a waypoint at each corner in between and at each end of the off-mesh
+// links it traverses. If there is no path, ErrNoPath is returned.
+func (m *Mesh) FindPath(start, end lmath.Vec3) ([]Waypoint, error) {
+	startPoly, start, err := m.snap(start)
+	if err != nil {
+		return nil, err
+	}
+	endPoly, end, err := m.snap(end)
+	if err != nil {
+		return nil, err
+	}
+	steps, ok := m.search(startPoly, start, endPoly, end)
+	if !ok {
+		return nil, ErrNoPath
+	}
+
+	// Smooth each walked section of the path (between links) with the
+	// funnel algorithm.
+	path := []Waypoint{{Point: start}}
+	from := start
+	var portals [][2]lmath.Vec3
+	for _, s := range steps {
+		if s.portal != nil {
+			// Leaving the polygon, the portal's first point is on the right.
+			portals = append(portals, [2]lmath.Vec3{s.portal.B, s.portal.A})
+			continue
+		}
+		linkStart, linkEnd := s.link.Start, s.link.End
+		if s.reversed {
+			linkStart, linkEnd = linkEnd, linkStart
+		}
+		for _, p := range funnel(from, linkStart, portals) {
+			path = append(path, Waypoint{Point: p})
+		}
+		path[len(path)-1].Link = s.link
+		path = append(path, Waypoint{Point: linkEnd})
+		from, portals = linkEnd, portals[:0]
+	}
+	for _, p := range funnel(from, end, portals) {
+		path = append(path, Waypoint{Point: p})
+	}
+	return path, nil
+}
+
+// search runs A* over the polygons of the mesh, and returns the steps from
+// the start polygon to the end one.
+func (m *Mesh) search(startPoly int, start lmath.Vec3, endPoly int, end lmath.Vec3) ([]step, bool) {
+	nodes := make(map[int]*searchNode)
+	nodes[startPoly] = &searchNode{pos: start, parent: -1}
+	open := &openList{{poly: startPoly, f: start.Sub(end).Length()}}
+
+	visit := func(from, to int, pos lmath.Vec3, cost float64, via step) {
+		cur := nodes[from]
+		g := cur.g + cost
+		if to == endPoly {
+			g += pos.Sub(end).Length()
+		}
+		n, ok := nodes[to]
+		if ok && (n.closed || n.g <= g) {
+			return
+		}
+		nodes[to] = &searchNode{g: g, pos: pos, parent: from, via: via}
+		h := 0.0
+		if to != endPoly {
+			h = pos.Sub(end).Length()
+		}
+		heap.Push(open, searchItem{poly: to, f: g + h})
+	}
+
+	for open.Len() > 0 {
+		item := heap.Pop(open).(searchItem)
+		cur := nodes[item.poly]
+		if cur.closed {
+			continue
+		}
+		cur.closed = true
+		if item.poly == endPoly {
+			break
+		}
+
+		p := &m.Polygons[item.poly]
+		for i := range p.Portals {
+			portal := &p.Portals[i]
+			mid := portal.A.Add(portal.B).MulScalar(0.5)
+			visit(item.poly, portal.Polygon, mid, mid.Sub(cur.pos).Length(), step{portal: portal})
+		}
+		for _, l := range m.polyLinks[item.poly] {
+			if l.startPoly == item.poly {
+				cost := cur.pos.Sub(l.Start).Length() + l.cost()
+				visit(item.poly, l.endPoly, l.End, cost, step{link: l})
+			}
+			if l.Bidirectional && l.endPoly == item.poly {
+				cost := cur.pos.Sub(l.End).Length() + l.cost()
+				visit(item.poly, l.startPoly, l.Start, cost, step{link: l, reversed: true})
+			}
+		}
+	}
+
+	n, ok := nodes[endPoly]
+	if !ok || !n.closed {
+		return nil, false
+	}
+	var steps []step
+	for poly := endPoly; poly != startPoly; poly = nodes[poly].parent {
+		steps = append(steps, nodes[poly].via)
+	}
+	for i, j := 0, len(steps)-1; i < j; i, j = i+1, j-1 {
+		steps[i], steps[j] = steps[j], steps[i]
+	}
+	return steps, true
+}
+
+// funnel returns the corners of the shortest path from start to end through
+// the given portals (as pairs of left and right points, seen from above), in
+// the order they are walked, excluding start but including end. It is
+// Mikko Mononen's "simple stupid funnel algorithm".
+func funnel(start, end lmath.Vec3, portals [][2]lmath.Vec3) []lmath.Vec3 {
+	portals = append(portals, [2]lmath.Vec3{end, end})
+	var corners []lmath.Vec3
+	apex, left, right := start, start, start
+	apexIndex, leftIndex, rightIndex := -1, -1, -1
+	for i := 0; i < len(portals); i++ {
+		l, r := portals[i][0], portals[i][1]
+
+		// Narrow the funnel from the right, unless the new right side crosses
+		// the left one, in which case the left point is a corner.
+		if cross2(right.Sub(apex), r.Sub(apex)) >= 0 {
+			if same2(apex, right) || cross2(left.Sub(apex), r.Sub(apex)) < 0 {
+				right, rightIndex = r, i
+			} else {
+				corners = append(corners, left)
+				apex, apexIndex = left, leftIndex
+				left, right = apex, apex
+				leftIndex, rightIndex = apexIndex, apexIndex
+				i = apexIndex
+				continue
+			}
+		}
+
+		// And likewise from the left.
+		if cross2(left.Sub(apex), l.Sub(apex)) <= 0 {
+			if same2(apex, left) || cross2(right.Sub(apex), l.Sub(apex)) > 0 {
+				left, leftIndex = l, i
+			} else {
+				corners = append(corners, right)
+				apex, apexIndex = right, rightIndex
+				left, right = apex, apex
+				leftIndex, rightIndex = apexIndex, apexIndex
+				i = apexIndex
+				continue
+			}
+		}
+	}
+	if len(corners) == 0 || !same2(corners[len(corners)-1], end) {
+		corners = append(corners, end)
+	}
+	return corners
+}
+
+// same2 tells if the two points are the same, as seen from above.
+func same2(a, b lmath.Vec3) bool {
+	return math.Abs(a.X-b.X) < lmath.EPSILON && math.Abs(a.Y-b.Y) < lmath.EPSILON
+}
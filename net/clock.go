@@ -0,0 +1,101 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package net
+
+import (
+	"sync"
+	"time"
+)
+
+// clockSamples is the number of recent samples a clock estimates from.
+const clockSamples = 16
+
+// clockSample is a single measurement of the remote clock.
+type clockSample struct {
+	rtt, offset time.Duration
+}
+
+// Clock estimates the clock of the peer of a connection from the timestamps
+// exchanged by pings, such that a client may agree with the server on the
+// current time (and hence simulation tick). It's methods are safe to call from
+// multiple goroutines concurrently.
+type Clock struct {
+	access  sync.Mutex
+	samples []clockSample
+	next    int
+	offset  time.Duration
+	rtt     time.Duration
+}
+
+// Sample adds a measurement of the remote clock: remote is the time of the
+// remote clock when it replied to a ping which took rtt to make the round
+// trip and whose reply was received at the local time local.
+//
+// The offset is estimated from the recent sample with the lowest round trip
+// time, as it's error is the smallest.
+func (c *Clock) Sample(rtt time.Duration, remote, local time.Time) {
+	c.access.Lock()
+	defer c.access.Unlock()
+	s := clockSample{
+		rtt:    rtt,
+		offset: remote.Add(rtt / 2).Sub(local),
+	}
+	if len(c.samples) < clockSamples {
+		c.samples = append(c.samples, s)
+	} else {
+		c.samples[c.next] = s
+		c.next = (c.next + 1) % clockSamples
+	}
+	best := c.samples[0]
+	var sum time.Duration
+	for _, s := range c.samples {
+		sum += s.rtt
+		if s.rtt < best.rtt {
+			best = s
+		}
+	}
+	c.offset = best.offset
+	c.rtt = sum / time.Duration(len(c.samples))
+}
+
+// Synced tells if the clock has any samples yet.
+func (c *Clock) Synced() bool {
+	c.access.Lock()
+	defer c.access.Unlock()
+	return len(c.samples) > 0
+}
+
+// Offset returns the estimated difference of the remote clock from the local
+// one.
+func (c *Clock) Offset() time.Duration {
+	c.access.Lock()
+	defer c.access.Unlock()
+	return c.offset
+}
+
+// RTT returns the average round trip time of the recent samples.
+func (c *Clock) RTT() time.Duration {
+	c.access.Lock()
+	defer c.access.Unlock()
+	return c.rtt
+}
+
+// Now returns the estimated current time of the remote clock.
+func (c *Clock) Now() time.Time {
+	return time.Now().Add(c.Offset())
+}
+
+// Tick returns the estimated current tick of the remote clock at the given
+// tick rate, see TickAt.
+func (c *Clock) Tick(rate int) uint32 {
+	return TickAt(c.Now(), rate)
+}
+
+// TickAt returns the simulation tick at the given time, for a simulation
+// running at rate ticks per second. Ticks are counted from the Unix epoch
+// and wrap around.
+func TickAt(t time.Time, rate int) uint32 {
+	return uint32(t.UnixNano() / int64(time.Second/time.Duration(rate)))
+}
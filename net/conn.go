@@ -0,0 +1,499 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package net
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand"
+	stdnet "net"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrClosed is returned by a connection which was closed by either side.
+	ErrClosed = errors.New("net: connection closed")
+
+	// ErrTimeout is returned by a connection whose peer did not respond for
+	// longer than Config.Timeout.
+	ErrTimeout = errors.New("net: connection timed out")
+
+	// ErrTooLarge is returned when sending a message which does not fit in a
+	// single packet.
+	ErrTooLarge = errors.New("net: message too large")
+)
+
+// Mode is the delivery mode of a channel.
+type Mode uint8
+
+const (
+	// Unreliable messages may be lost, duplicated or arrive out of order.
+	Unreliable Mode = iota
+
+	// Reliable messages are resent until received, and are received exactly
+	// once and in the order they were sent.
+	Reliable
+
+	// Sequenced messages may be lost, but messages older than the last one
+	// received are dropped, e.g. for frequent state updates.
+	Sequenced
+)
+
+// String returns the name of the mode, e.g. "Reliable".
+func (m Mode) String() string {
+	switch m {
+	case Unreliable:
+		return "Unreliable"
+	case Reliable:
+		return "Reliable"
+	case Sequenced:
+		return "Sequenced"
+	}
+	return fmt.Sprintf("Mode(%d)", m)
+}
+
+// Config is the configuration of connections. Zero fields use their
+// defaults.
+type Config struct {
+	// The delivery modes of the channels of connections, by channel number.
+	// The default is a Reliable channel 0 and an Unreliable channel 1. At
+	// most 255 channels may be used.
+	Channels []Mode
+
+	// The number of times per second queued messages are sent, default 30.
+	SendRate int
+
+	// How often the clock of the peer is sampled, default 500ms.
+	PingInterval time.Duration
+
+	// How long a connection may receive nothing before it times out, default
+	// 10s.
+	Timeout time.Duration
+
+	// The maximum size of packets in bytes, default 1200.
+	MaxPacket int
+
+	// The fraction of outgoing packets to drop, in the range [0, 1], for
+	// testing under simulated packet loss.
+	Loss float64
+}
+
+// withDefaults returns a copy of the configuration with it's defaults set.
+func (c *Config) withDefaults() Config {
+	var cfg Config
+	if c != nil {
+		cfg = *c
+	}
+	if len(cfg.Channels) == 0 {
+		cfg.Channels = []Mode{Reliable, Unreliable}
+	}
+	if len(cfg.Channels) > control {
+		cfg.Channels = cfg.Channels[:control]
+	}
+	if cfg.SendRate <= 0 {
+		cfg.SendRate = 30
+	}
+	if cfg.PingInterval <= 0 {
+		cfg.PingInterval = 500 * time.Millisecond
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.MaxPacket <= 0 {
+		cfg.MaxPacket = 1200
+	}
+	return cfg
+}
+
+// Message is a message received on a channel.
+type Message struct {
+	// The channel the message was received on.
+	Channel int
+
+	// The data of the message.
+	Data []byte
+}
+
+// pending is a reliable message waiting to be acknowledged.
+type pending struct {
+	msg   *message
+	sent  time.Time
+	acked bool
+}
+
+// channel is the state of a single channel of a connection.
+type channel struct {
+	mode   Mode
+	nextID uint16
+
+	// Reliable messages which are not yet acknowledged.
+	pending []*pending
+
+	// The next reliable message ID expected, and messages received ahead of
+	// it.
+	recvNext uint16
+	recvBuf  map[uint16][]byte
+
+	// The ID of the last sequenced message received.
+	recvLast uint16
+	recvAny  bool
+}
+
+// Conn is a connection to a peer. It's methods are safe to call from multiple
+// goroutines concurrently.
+type Conn struct {
+	access   sync.Mutex
+	cfg      Config
+	addr     stdnet.Addr
+	write    func([]byte) error
+	epoch    time.Time
+	rand     *rand.Rand
+	buf      []byte
+	channels []*channel
+	queue    []*message
+	clock    Clock
+	incoming []Message
+	err      error
+
+	// Sequence numbers and reliable messages of packets sent recently, which
+	// are not yet acknowledged.
+	seq  uint16
+	sent map[uint16][]*pending
+
+	// The most recent sequence number received, and which of the 32 before it
+	// were received.
+	remoteSeq  uint16
+	remoteBits uint32
+	received   bool
+	ackDirty   bool
+
+	lastRecv, lastPing time.Time
+}
+
+// RemoteAddr returns the address of the peer.
+func (c *Conn) RemoteAddr() stdnet.Addr {
+	return c.addr
+}
+
+// Clock returns the estimate of the peer's clock.
+func (c *Conn) Clock() *Clock {
+	return &c.clock
+}
+
+// RTT returns the average round trip time to the peer.
+func (c *Conn) RTT() time.Duration {
+	return c.clock.RTT()
+}
+
+// Err returns the reason the connection was closed, or nil if it is open.
+func (c *Conn) Err() error {
+	c.access.Lock()
+	defer c.access.Unlock()
+	return c.err
+}
+
+// Send queues a message to be sent on the given channel. The data is copied.
+func (c *Conn) Send(ch int, data []byte) error {
+	c.access.Lock()
+	defer c.access.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	if ch < 0 || ch >= len(c.channels) {
+		return fmt.Errorf("net: invalid channel %d", ch)
+	}
+	if len(data) > c.cfg.MaxPacket-headerSize-messageSize {
+		return ErrTooLarge
+	}
+	s := c.channels[ch]
+	m := &message{
+		channel: uint8(ch),
+		id:      s.nextID,
+		data:    append([]byte(nil), data...),
+	}
+	s.nextID++
+	if s.mode == Reliable {
+		s.pending = append(s.pending, &pending{msg: m})
+	} else {
+		c.queue = append(c.queue, m)
+	}
+	return nil
+}
+
+// Receive returns the next message received, or false if there are none. It
+// does not block, such that it may be polled once per frame.
+func (c *Conn) Receive() (Message, bool) {
+	c.access.Lock()
+	defer c.access.Unlock()
+	if len(c.incoming) == 0 {
+		return Message{}, false
+	}
+	m := c.incoming[0]
+	c.incoming = c.incoming[1:]
+	return m, true
+}
+
+// Close closes the connection, notifying the peer (if the notification is
+// lost, the peer times out instead). Queued messages are not sent. Closing a
+// closed connection is no-op.
+func (c *Conn) Close() error {
+	c.access.Lock()
+	defer c.access.Unlock()
+	if c.err != nil {
+		return nil
+	}
+	for i := 0; i < 3; i++ {
+		c.send([]*message{{channel: control, data: []byte{ctrlClose}}}, nil)
+	}
+	c.err = ErrClosed
+	return nil
+}
+
+// ping queues a ping of the peer's clock.
+func (c *Conn) ping(now time.Time) {
+	data := make([]byte, 9)
+	data[0] = ctrlPing
+	binary.BigEndian.PutUint64(data[1:], uint64(now.Sub(c.epoch)))
+	c.queue = append(c.queue, &message{channel: control, data: data})
+	c.lastPing = now
+}
+
+// handleControl handles a control message.
+func (c *Conn) handleControl(data []byte, now time.Time) {
+	if len(data) == 0 {
+		return
+	}
+	switch data[0] {
+	case ctrlPing:
+		if len(data) != 9 {
+			return
+		}
+		pong := make([]byte, 17)
+		pong[0] = ctrlPong
+		copy(pong[1:], data[1:])
+		binary.BigEndian.PutUint64(pong[9:], uint64(now.UnixNano()))
+		c.queue = append(c.queue, &message{channel: control, data: pong})
+	case ctrlPong:
+		if len(data) != 17 {
+			return
+		}
+		sent := time.Duration(binary.BigEndian.Uint64(data[1:]))
+		remote := time.Unix(0, int64(binary.BigEndian.Uint64(data[9:])))
+		c.clock.Sample(now.Sub(c.epoch)-sent, remote, now)
+	case ctrlClose:
+		c.err = ErrClosed
+	}
+}
+
+// deliver handles a message received on a channel.
+func (c *Conn) deliver(m message) {
+	if int(m.channel) >= len(c.channels) {
+		return
+	}
+	s := c.channels[m.channel]
+	data := append([]byte(nil), m.data...)
+	switch s.mode {
+	case Unreliable:
+		c.incoming = append(c.incoming, Message{Channel: int(m.channel), Data: data})
+	case Sequenced:
+		if s.recvAny && !newer(m.id, s.recvLast) {
+			return
+		}
+		s.recvAny = true
+		s.recvLast = m.id
+		c.incoming = append(c.incoming, Message{Channel: int(m.channel), Data: data})
+	case Reliable:
+		if m.id != s.recvNext {
+			if newer(m.id, s.recvNext) {
+				if s.recvBuf == nil {
+					s.recvBuf = make(map[uint16][]byte)
+				}
+				s.recvBuf[m.id] = data
+			}
+			return
+		}
+		for {
+			c.incoming = append(c.incoming, Message{Channel: int(m.channel), Data: data})
+			s.recvNext++
+			next, ok := s.recvBuf[s.recvNext]
+			if !ok {
+				return
+			}
+			delete(s.recvBuf, s.recvNext)
+			data = next
+		}
+	}
+}
+
+// acknowledge marks the reliable messages of the sent packet with the given
+// sequence number as received.
+func (c *Conn) acknowledge(seq uint16) {
+	for _, p := range c.sent[seq] {
+		p.acked = true
+	}
+	delete(c.sent, seq)
+}
+
+// receive handles a packet received from the peer.
+func (c *Conn) receive(buf []byte, now time.Time) {
+	h, msgs, err := decodePacket(buf)
+	if err != nil {
+		return
+	}
+	c.access.Lock()
+	defer c.access.Unlock()
+	if c.err != nil {
+		return
+	}
+
+	// Record the sequence number, dropping duplicate and very old packets.
+	switch {
+	case !c.received:
+		c.remoteSeq, c.remoteBits, c.received = h.seq, 0, true
+	case newer(h.seq, c.remoteSeq):
+		shift := uint(h.seq - c.remoteSeq)
+		c.remoteBits = uint32((uint64(c.remoteBits)<<1 | 1) << (shift - 1))
+		c.remoteSeq = h.seq
+	default:
+		d := uint(c.remoteSeq - h.seq)
+		if d == 0 || d > 32 || c.remoteBits&(1<<(d-1)) != 0 {
+			return
+		}
+		c.remoteBits |= 1 << (d - 1)
+	}
+	c.ackDirty = true
+	c.lastRecv = now
+
+	// Acknowledge the packets the peer received.
+	c.acknowledge(h.ack)
+	for i := uint16(0); i < 32; i++ {
+		if h.ackBits&(1<<i) != 0 {
+			c.acknowledge(h.ack - 1 - i)
+		}
+	}
+
+	for _, m := range msgs {
+		if m.channel == control {
+			c.handleControl(m.data, now)
+			continue
+		}
+		c.deliver(m)
+	}
+}
+
+// send sends a packet holding the given messages, of which reliable are the
+// reliable ones.
+func (c *Conn) send(msgs []*message, reliable []*pending) {
+	h := header{seq: c.seq, ack: c.remoteSeq, ackBits: c.remoteBits}
+	c.sent[c.seq] = reliable
+	// Packets this old can no longer be acknowledged.
+	delete(c.sent, c.seq-64)
+	c.seq++
+	c.ackDirty = false
+	c.buf = encodePacket(c.buf[:0], h, msgs)
+	if c.cfg.Loss > 0 && c.rand.Float64() < c.cfg.Loss {
+		return
+	}
+	c.write(c.buf)
+}
+
+// resendDelay returns how long a reliable message is left unacknowledged
+// before it is resent.
+func (c *Conn) resendDelay() time.Duration {
+	if !c.clock.Synced() {
+		return 100 * time.Millisecond
+	}
+	d := c.clock.RTT()*3/2 + 10*time.Millisecond
+	if d < 30*time.Millisecond {
+		d = 30 * time.Millisecond
+	}
+	return d
+}
+
+// flush sends the queued messages and the reliable messages which are due
+// to be (re)sent, and times out the connection if the peer is unresponsive.
+// It is called SendRate times per second.
+func (c *Conn) flush(now time.Time) {
+	c.access.Lock()
+	defer c.access.Unlock()
+	if c.err != nil {
+		return
+	}
+	if now.Sub(c.lastRecv) > c.cfg.Timeout {
+		c.err = ErrTimeout
+		return
+	}
+	if now.Sub(c.lastPing) >= c.cfg.PingInterval {
+		c.ping(now)
+	}
+
+	// Collect the reliable messages which are due.
+	resend := c.resendDelay()
+	var due []*pending
+	for _, s := range c.channels {
+		keep := s.pending[:0]
+		for _, p := range s.pending {
+			if p.acked {
+				continue
+			}
+			keep = append(keep, p)
+			if p.sent.IsZero() || now.Sub(p.sent) >= resend {
+				due = append(due, p)
+			}
+		}
+		for i := len(keep); i < len(s.pending); i++ {
+			s.pending[i] = nil
+		}
+		s.pending = keep
+	}
+
+	// Pack them, and then the queued messages, into packets.
+	var (
+		msgs     []*message
+		reliable []*pending
+		size     = headerSize
+	)
+	add := func(m *message) {
+		if size+messageSize+len(m.data) > c.cfg.MaxPacket {
+			c.send(msgs, reliable)
+			msgs, reliable, size = nil, nil, headerSize
+		}
+		msgs = append(msgs, m)
+		size += messageSize + len(m.data)
+	}
+	for _, p := range due {
+		add(p.msg)
+		reliable = append(reliable, p)
+		p.sent = now
+	}
+	for _, m := range c.queue {
+		add(m)
+	}
+	c.queue = c.queue[:0]
+	if len(msgs) > 0 || c.ackDirty {
+		c.send(msgs, reliable)
+	}
+}
+
+// newConn returns a new connection to the peer at addr, which writes packets
+// using write.
+func newConn(cfg Config, addr stdnet.Addr, write func([]byte) error) *Conn {
+	now := time.Now()
+	c := &Conn{
+		cfg:      cfg,
+		addr:     addr,
+		write:    write,
+		epoch:    now,
+		rand:     rand.New(rand.NewSource(now.UnixNano())),
+		sent:     make(map[uint16][]*pending),
+		lastRecv: now,
+	}
+	for _, m := range cfg.Channels {
+		c.channels = append(c.channels, &channel{mode: m})
+	}
+	return c
+}
@@ -0,0 +1,36 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package net implements a client/server transport for multiplayer games.
+//
+// Connections are made over UDP with a small reliability layer on top:
+// packets carry acknowledgements of the packets received from the peer, and
+// messages sent on reliable channels are resent until a packet holding them
+// is acknowledged. Messages are multiplexed over a connection's channels,
+// each of which has a delivery mode (see Mode):
+//
+//  srv, err := net.Listen("udp", ":7777", nil)
+//  ...
+//  for {
+//      for c, ok := srv.Accept(); ok; c, ok = srv.Accept() {
+//          players = append(players, c)
+//      }
+//      for _, c := range players {
+//          for m, ok := c.Receive(); ok; m, ok = c.Receive() {
+//              handle(c, m)
+//          }
+//      }
+//      ...
+//  }
+//
+// The state of the game world is replicated with snapshots: the serialized
+// components of each entity at a simulation tick (encoded e.g. with the codec
+// package, see Snapshot.Encode). A Snapshot is sent as a
+// delta against the last snapshot the client acknowledged (see History), and
+// each Conn estimates the clock of it's peer (see Clock) so that clients may
+// agree with the server on the current tick.
+//
+// Messages larger than a single packet (Config.MaxPacket) are not supported,
+// and connections are not authenticated nor encrypted.
+package net // import "azul3d.org/engine/net"
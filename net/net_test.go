@@ -0,0 +1,192 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package net
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"azul3d.org/engine/codec"
+)
+
+func TestPacket(t *testing.T) {
+	h := header{seq: 65535, ack: 3, ackBits: 0x80000001}
+	msgs := []*message{{channel: 0, id: 7, data: []byte("hello")}, {channel: 1, id: 8}}
+	gotH, got, err := decodePacket(encodePacket(nil, h, msgs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotH != h || len(got) != 2 || string(got[0].data) != "hello" || got[1].id != 8 {
+		t.Fatalf("got %+v %+v", gotH, got)
+	}
+	if _, _, err := decodePacket([]byte("garbage packet")); err == nil {
+		t.Fatal("expected error")
+	}
+	if !newer(1, 65535) || newer(65535, 1) || newer(5, 5) {
+		t.Fatal("newer: wrong wrap around")
+	}
+}
+
+func TestReliable(t *testing.T) {
+	cfg := &Config{
+		Channels: []Mode{Reliable, Sequenced},
+		SendRate: 200,
+		Timeout:  2 * time.Second,
+		Loss:     0.3,
+	}
+	srv, err := Listen("udp", "127.0.0.1:0", cfg)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer srv.Close()
+	c, err := Dial("udp", srv.Addr().String(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	const n = 100
+	for i := 0; i < n; i++ {
+		if err := c.Send(0, []byte(fmt.Sprint(i))); err != nil {
+			t.Fatal(err)
+		}
+		c.Send(1, []byte{byte(i)})
+	}
+
+	var (
+		sc      *Conn
+		got     int
+		lastSeq = -1
+	)
+	deadline := time.Now().Add(10 * time.Second)
+	for got < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("received %d of %d reliable messages", got, n)
+		}
+		if sc == nil {
+			sc, _ = srv.Accept()
+		}
+		if sc == nil {
+			time.Sleep(time.Millisecond)
+			continue
+		}
+		m, ok := sc.Receive()
+		if !ok {
+			time.Sleep(time.Millisecond)
+			continue
+		}
+		switch m.Channel {
+		case 0:
+			if want := fmt.Sprint(got); string(m.Data) != want {
+				t.Fatalf("got %q, want %q", m.Data, want)
+			}
+			got++
+		case 1:
+			if int(m.Data[0]) <= lastSeq {
+				t.Fatalf("sequenced message %d after %d", m.Data[0], lastSeq)
+			}
+			lastSeq = int(m.Data[0])
+		}
+	}
+
+	if err := c.Send(2, nil); err == nil {
+		t.Fatal("expected invalid channel error")
+	}
+	if err := c.Send(0, make([]byte, 2000)); err != ErrTooLarge {
+		t.Fatalf("got %v, want ErrTooLarge", err)
+	}
+
+	// The server sees the client close (or time out, if it is lost).
+	c.Close()
+	for sc.Err() == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("server connection not closed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestClock(t *testing.T) {
+	var c Clock
+	local := time.Now()
+	remote := local.Add(time.Hour)
+	c.Sample(100*time.Millisecond, remote.Add(-20*time.Millisecond), local)
+	c.Sample(20*time.Millisecond, remote.Add(-10*time.Millisecond), local)
+	if off := c.Offset(); off != time.Hour {
+		t.Fatalf("got offset %v, want 1h", off)
+	}
+	if rtt := c.RTT(); rtt != 60*time.Millisecond {
+		t.Fatalf("got rtt %v, want 60ms", rtt)
+	}
+	if got := TickAt(time.Unix(2, 0), 60); got != 120 {
+		t.Fatalf("got tick %d, want 120", got)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	base := NewSnapshot(10)
+	base.Set(1, 0, []byte("pos1"))
+	base.Set(1, 1, []byte("vel1"))
+	base.Set(2, 0, []byte("pos2"))
+	base.Set(3, 0, []byte("pos3"))
+
+	s := base.Copy()
+	s.Tick = 11
+	s.Set(1, 0, []byte("pos1b"))
+	s.RemoveComponent(1, 1)
+	s.Remove(2)
+	s.Set(4, 2, []byte("new"))
+
+	full, err := ApplyDelta(nil, s.Delta(nil))
+	if err != nil || !reflect.DeepEqual(full, s) {
+		t.Fatalf("full: got %+v, %v", full, err)
+	}
+	delta := s.Delta(base)
+	if bytes.Contains(delta, []byte("pos3")) {
+		t.Fatal("unchanged component encoded in delta")
+	}
+	got, err := ApplyDelta(base, delta)
+	if err != nil || !reflect.DeepEqual(got, s) {
+		t.Fatalf("delta: got %+v, %v", got, err)
+	}
+	if _, err := ApplyDelta(NewSnapshot(9), delta); err != ErrBaseline {
+		t.Fatalf("got %v, want ErrBaseline", err)
+	}
+	if _, err := ApplyDelta(base, delta[:len(delta)-2]); err == nil {
+		t.Fatal("expected error for truncated delta")
+	}
+
+	h := NewHistory(4)
+	h.Add(base)
+	h.Add(s)
+	if h.Get(10) != base || h.Get(11) != s || h.Get(14) != nil {
+		t.Fatal("history lookup failed")
+	}
+}
+
+type health struct {
+	HP int32
+}
+
+func (h *health) Serialize(s codec.Stream) {
+	s.Int(&h.HP, 0, 100)
+}
+
+func TestSnapshotCodec(t *testing.T) {
+	s := NewSnapshot(1)
+	if err := s.Encode(7, 0, &health{HP: 42}, 1); err != nil {
+		t.Fatal(err)
+	}
+	var h health
+	if err := s.Decode(7, 0, &h); err != nil || h.HP != 42 {
+		t.Fatalf("got %d, %v", h.HP, err)
+	}
+	if err := s.Decode(7, 1, &h); err != ErrNoComponent {
+		t.Fatalf("got %v, want ErrNoComponent", err)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package net
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// protocol identifies packets of this package; others are ignored.
+const protocol = 0x417a4e31 // "AzN1"
+
+// The size of a packet header: protocol, sequence number, ack and ack bits.
+const headerSize = 4 + 2 + 2 + 4
+
+// The size of a message header: channel, message ID and length.
+const messageSize = 1 + 2 + 2
+
+// control is the channel of internal control messages.
+const control = 0xFF
+
+// Control message types.
+const (
+	ctrlPing = iota
+	ctrlPong
+	ctrlClose
+)
+
+var errPacket = errors.New("net: malformed packet")
+
+// header is the header of a packet.
+type header struct {
+	// The sequence number of the packet.
+	seq uint16
+
+	// The most recent sequence number received from the peer, and a bitmask
+	// of which of the 32 sequence numbers before it were received.
+	ack     uint16
+	ackBits uint32
+}
+
+// message is a message within a packet.
+type message struct {
+	channel uint8
+	id      uint16
+	data    []byte
+}
+
+// encodePacket appends the packet with the given header and messages to buf.
+func encodePacket(buf []byte, h header, msgs []*message) []byte {
+	var b [headerSize]byte
+	binary.BigEndian.PutUint32(b[0:], protocol)
+	binary.BigEndian.PutUint16(b[4:], h.seq)
+	binary.BigEndian.PutUint16(b[6:], h.ack)
+	binary.BigEndian.PutUint32(b[8:], h.ackBits)
+	buf = append(buf, b[:]...)
+	for _, m := range msgs {
+		var mb [messageSize]byte
+		mb[0] = m.channel
+		binary.BigEndian.PutUint16(mb[1:], m.id)
+		binary.BigEndian.PutUint16(mb[3:], uint16(len(m.data)))
+		buf = append(buf, mb[:]...)
+		buf = append(buf, m.data...)
+	}
+	return buf
+}
+
+// decodePacket decodes a packet. The data of the returned messages aliases
+// buf.
+func decodePacket(buf []byte) (header, []message, error) {
+	var h header
+	if len(buf) < headerSize || binary.BigEndian.Uint32(buf) != protocol {
+		return h, nil, errPacket
+	}
+	h.seq = binary.BigEndian.Uint16(buf[4:])
+	h.ack = binary.BigEndian.Uint16(buf[6:])
+	h.ackBits = binary.BigEndian.Uint32(buf[8:])
+	buf = buf[headerSize:]
+	var msgs []message
+	for len(buf) > 0 {
+		if len(buf) < messageSize {
+			return h, nil, errPacket
+		}
+		m := message{
+			channel: buf[0],
+			id:      binary.BigEndian.Uint16(buf[1:]),
+		}
+		n := int(binary.BigEndian.Uint16(buf[3:]))
+		buf = buf[messageSize:]
+		if len(buf) < n {
+			return h, nil, errPacket
+		}
+		m.data = buf[:n:n]
+		buf = buf[n:]
+		msgs = append(msgs, m)
+	}
+	return h, msgs, nil
+}
+
+// newer tells if sequence number a is more recent than b, accounting for
+// wrap around.
+func newer(a, b uint16) bool {
+	return a != b && a-b < 0x8000
+}
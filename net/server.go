@@ -0,0 +1,191 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package net
+
+import (
+	stdnet "net"
+	"sync"
+	"time"
+)
+
+// maxDatagram is the size of the buffer packets are read into.
+const maxDatagram = 65536
+
+// Server accepts connections from clients. It's methods are safe to call from
+// multiple goroutines concurrently.
+type Server struct {
+	access sync.Mutex
+	cfg    Config
+	pc     stdnet.PacketConn
+	conns  map[string]*Conn
+	accept []*Conn
+	done   chan struct{}
+	closed bool
+}
+
+// Addr returns the address the server is listening on.
+func (s *Server) Addr() stdnet.Addr {
+	return s.pc.LocalAddr()
+}
+
+// Accept returns the next new connection, or false if there are none. It
+// does not block, such that it may be polled once per frame.
+func (s *Server) Accept() (*Conn, bool) {
+	s.access.Lock()
+	defer s.access.Unlock()
+	if len(s.accept) == 0 {
+		return nil, false
+	}
+	c := s.accept[0]
+	s.accept = s.accept[1:]
+	return c, true
+}
+
+// Conns returns the open connections of the server.
+func (s *Server) Conns() []*Conn {
+	s.access.Lock()
+	defer s.access.Unlock()
+	conns := make([]*Conn, 0, len(s.conns))
+	for _, c := range s.conns {
+		conns = append(conns, c)
+	}
+	return conns
+}
+
+// Close closes all of the connections and stops listening. Closing a closed
+// server is no-op.
+func (s *Server) Close() error {
+	s.access.Lock()
+	defer s.access.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	for _, c := range s.conns {
+		c.Close()
+	}
+	close(s.done)
+	return s.pc.Close()
+}
+
+// read reads packets until the server is closed, accepting connections from
+// new addresses.
+func (s *Server) read() {
+	buf := make([]byte, maxDatagram)
+	for {
+		n, addr, err := s.pc.ReadFrom(buf)
+		if err != nil {
+			select {
+			case <-s.done:
+				return
+			default:
+				continue
+			}
+		}
+		if _, _, err := decodePacket(buf[:n]); err != nil {
+			continue
+		}
+		key := addr.String()
+		s.access.Lock()
+		c, ok := s.conns[key]
+		if !ok && !s.closed {
+			c = newConn(s.cfg, addr, func(b []byte) error {
+				_, err := s.pc.WriteTo(b, addr)
+				return err
+			})
+			s.conns[key] = c
+			s.accept = append(s.accept, c)
+		}
+		s.access.Unlock()
+		if c != nil {
+			c.receive(buf[:n], time.Now())
+		}
+	}
+}
+
+// run flushes the connections SendRate times per second, removing the
+// closed ones, until the server is closed.
+func (s *Server) run() {
+	ticker := time.NewTicker(time.Second / time.Duration(s.cfg.SendRate))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case now := <-ticker.C:
+			for _, c := range s.Conns() {
+				c.flush(now)
+				if c.Err() != nil {
+					s.access.Lock()
+					delete(s.conns, c.addr.String())
+					s.access.Unlock()
+				}
+			}
+		}
+	}
+}
+
+// Listen listens for connections on the given network (e.g. "udp") and
+// address, see net.ListenPacket. If the configuration is nil the defaults
+// are used.
+func Listen(network, address string, cfg *Config) (*Server, error) {
+	pc, err := stdnet.ListenPacket(network, address)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		cfg:   cfg.withDefaults(),
+		pc:    pc,
+		conns: make(map[string]*Conn),
+		done:  make(chan struct{}),
+	}
+	go s.read()
+	go s.run()
+	return s, nil
+}
+
+// Dial connects to the server at the given network (e.g. "udp") and address,
+// see net.Dial. If the configuration is nil the defaults are used; the
+// channels must match those of the server.
+//
+// Dial does not wait for the server to respond: the connection times out if
+// it does not.
+func Dial(network, address string, cfg *Config) (*Conn, error) {
+	nc, err := stdnet.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+	c := newConn(cfg.withDefaults(), nc.RemoteAddr(), func(b []byte) error {
+		_, err := nc.Write(b)
+		return err
+	})
+	go func() {
+		buf := make([]byte, maxDatagram)
+		for {
+			n, err := nc.Read(buf)
+			if err != nil {
+				if c.Err() != nil {
+					return
+				}
+				// E.g. the server is not up yet.
+				time.Sleep(10 * time.Millisecond)
+				continue
+			}
+			c.receive(buf[:n], time.Now())
+		}
+	}()
+	go func() {
+		ticker := time.NewTicker(time.Second / time.Duration(c.cfg.SendRate))
+		defer ticker.Stop()
+		for now := range ticker.C {
+			c.flush(now)
+			if c.Err() != nil {
+				nc.Close()
+				return
+			}
+		}
+	}()
+	return c, nil
+}
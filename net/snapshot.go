@@ -0,0 +1,306 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package net
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"sort"
+
+	"azul3d.org/engine/codec"
+)
+
+var (
+	// ErrBaseline is returned by ApplyDelta when the given baseline is not
+	// the snapshot the delta was encoded against.
+	ErrBaseline = errors.New("net: snapshot baseline mismatch")
+
+	// ErrNoComponent is returned by Snapshot.Decode when the entity has no
+	// such component.
+	ErrNoComponent = errors.New("net: no such component")
+
+	errSnapshot = errors.New("net: malformed snapshot")
+)
+
+// EntityID identifies an entity of the game world.
+type EntityID uint32
+
+// ComponentID identifies a type of component of an entity.
+type ComponentID uint8
+
+// Snapshot is the replicated state of the game world at a simulation tick:
+// the serialized components of each entity (e.g. using
+// encoding.BinaryMarshaler).
+type Snapshot struct {
+	// The tick of the snapshot.
+	Tick uint32
+
+	// The serialized components, by entity.
+	Entities map[EntityID]map[ComponentID][]byte
+}
+
+// Set sets the serialized data of a component of an entity. The data is not
+// copied.
+func (s *Snapshot) Set(e EntityID, c ComponentID, data []byte) {
+	comps, ok := s.Entities[e]
+	if !ok {
+		comps = make(map[ComponentID][]byte)
+		s.Entities[e] = comps
+	}
+	comps[c] = data
+}
+
+// Get returns the serialized data of a component of an entity, or false if
+// the entity has no such component.
+func (s *Snapshot) Get(e EntityID, c ComponentID) ([]byte, bool) {
+	data, ok := s.Entities[e][c]
+	return data, ok
+}
+
+// Encode encodes a component of an entity into the snapshot using the given
+// version of it's encoding, see codec.Marshal.
+func (s *Snapshot) Encode(e EntityID, c ComponentID, v codec.Serializer, version int) error {
+	data, err := codec.Marshal(v, version)
+	if err != nil {
+		return err
+	}
+	s.Set(e, c, data)
+	return nil
+}
+
+// Decode decodes a component of an entity encoded by Encode into v.
+func (s *Snapshot) Decode(e EntityID, c ComponentID, v codec.Serializer) error {
+	data, ok := s.Get(e, c)
+	if !ok {
+		return ErrNoComponent
+	}
+	return codec.Unmarshal(data, v)
+}
+
+// Remove removes an entity from the snapshot.
+func (s *Snapshot) Remove(e EntityID) {
+	delete(s.Entities, e)
+}
+
+// RemoveComponent removes a component of an entity from the snapshot.
+func (s *Snapshot) RemoveComponent(e EntityID, c ComponentID) {
+	delete(s.Entities[e], c)
+}
+
+// Copy returns a copy of the snapshot, sharing the component data.
+func (s *Snapshot) Copy() *Snapshot {
+	cpy := NewSnapshot(s.Tick)
+	for e, comps := range s.Entities {
+		m := make(map[ComponentID][]byte, len(comps))
+		for c, data := range comps {
+			m[c] = data
+		}
+		cpy.Entities[e] = m
+	}
+	return cpy
+}
+
+// sortedEntities returns the IDs of the entities of both snapshots (either of
+// which may be nil), in ascending order.
+func sortedEntities(a, b *Snapshot) []EntityID {
+	seen := make(map[EntityID]bool)
+	var ids []EntityID
+	for _, s := range []*Snapshot{a, b} {
+		if s == nil {
+			continue
+		}
+		for e := range s.Entities {
+			if !seen[e] {
+				seen[e] = true
+				ids = append(ids, e)
+			}
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Entity and component operations of a delta.
+const (
+	opRemove = iota
+	opSet
+)
+
+// Delta encodes the snapshot as a delta against the given baseline: only the
+// entities and components which were added, changed or removed since the
+// baseline are included. If the baseline is nil the whole snapshot is
+// encoded.
+func (s *Snapshot) Delta(base *Snapshot) []byte {
+	var (
+		buf []byte
+		tmp [binary.MaxVarintLen64]byte
+	)
+	uvarint := func(v uint64) {
+		buf = append(buf, tmp[:binary.PutUvarint(tmp[:], v)]...)
+	}
+	uvarint(uint64(s.Tick))
+	if base != nil {
+		buf = append(buf, 1)
+		uvarint(uint64(base.Tick))
+	} else {
+		buf = append(buf, 0)
+	}
+
+	type change struct {
+		c    ComponentID
+		op   byte
+		data []byte
+	}
+	var body []byte
+	n := 0
+	for _, e := range sortedEntities(base, s) {
+		comps, ok := s.Entities[e]
+		var baseComps map[ComponentID][]byte
+		if base != nil {
+			baseComps = base.Entities[e]
+		}
+		if !ok {
+			// Removed since the baseline.
+			body = append(body, tmp[:binary.PutUvarint(tmp[:], uint64(e))]...)
+			body = append(body, opRemove)
+			n++
+			continue
+		}
+		var changes []change
+		for c, data := range comps {
+			if old, ok := baseComps[c]; !ok || !bytes.Equal(old, data) {
+				changes = append(changes, change{c, opSet, data})
+			}
+		}
+		for c := range baseComps {
+			if _, ok := comps[c]; !ok {
+				changes = append(changes, change{c, opRemove, nil})
+			}
+		}
+		if len(changes) == 0 && baseComps != nil {
+			continue
+		}
+		sort.Slice(changes, func(i, j int) bool { return changes[i].c < changes[j].c })
+		body = append(body, tmp[:binary.PutUvarint(tmp[:], uint64(e))]...)
+		body = append(body, opSet)
+		body = append(body, tmp[:binary.PutUvarint(tmp[:], uint64(len(changes)))]...)
+		for _, ch := range changes {
+			body = append(body, byte(ch.c), ch.op)
+			if ch.op == opSet {
+				body = append(body, tmp[:binary.PutUvarint(tmp[:], uint64(len(ch.data)))]...)
+				body = append(body, ch.data...)
+			}
+		}
+		n++
+	}
+	uvarint(uint64(n))
+	return append(buf, body...)
+}
+
+// ApplyDelta decodes a snapshot encoded by Snapshot.Delta against the given
+// baseline, which must be the same snapshot the delta was encoded against (or
+// nil if it was encoded without one). The baseline is not modified.
+func ApplyDelta(base *Snapshot, data []byte) (s *Snapshot, err error) {
+	// The readers panic with errSnapshot if the data is malformed.
+	defer func() {
+		if r := recover(); r != nil {
+			if r != errSnapshot {
+				panic(r)
+			}
+			s, err = nil, errSnapshot
+		}
+	}()
+	r := bytes.NewReader(data)
+	uvarint := func() uint64 {
+		v, err := binary.ReadUvarint(r)
+		if err != nil {
+			panic(errSnapshot)
+		}
+		return v
+	}
+	readByte := func() byte {
+		b, err := r.ReadByte()
+		if err != nil {
+			panic(errSnapshot)
+		}
+		return b
+	}
+
+	tick := uint32(uvarint())
+	if readByte() == 1 {
+		baseTick := uint32(uvarint())
+		if base == nil || base.Tick != baseTick {
+			return nil, ErrBaseline
+		}
+		s = base.Copy()
+		s.Tick = tick
+	} else {
+		s = NewSnapshot(tick)
+	}
+	for n := uvarint(); n > 0; n-- {
+		e := EntityID(uvarint())
+		if readByte() == opRemove {
+			s.Remove(e)
+			continue
+		}
+		if _, ok := s.Entities[e]; !ok {
+			s.Entities[e] = make(map[ComponentID][]byte)
+		}
+		for m := uvarint(); m > 0; m-- {
+			c := ComponentID(readByte())
+			if readByte() == opRemove {
+				s.RemoveComponent(e, c)
+				continue
+			}
+			size := uvarint()
+			if size > uint64(r.Len()) {
+				return nil, errSnapshot
+			}
+			comp := make([]byte, size)
+			r.Read(comp)
+			s.Set(e, c, comp)
+		}
+	}
+	return s, nil
+}
+
+// NewSnapshot returns a new empty snapshot at the given tick.
+func NewSnapshot(tick uint32) *Snapshot {
+	return &Snapshot{
+		Tick:     tick,
+		Entities: make(map[EntityID]map[ComponentID][]byte),
+	}
+}
+
+// History holds the most recent snapshots, by tick, as baselines for deltas:
+// the server keeps the snapshots it sent and encodes each against the last
+// one the client acknowledged, and the client keeps those it received to
+// decode them.
+type History struct {
+	snaps []*Snapshot
+}
+
+// Add adds a snapshot to the history, replacing the one as many ticks old as
+// the size of the history.
+func (h *History) Add(s *Snapshot) {
+	h.snaps[s.Tick%uint32(len(h.snaps))] = s
+}
+
+// Get returns the snapshot at the given tick, or nil if it is not in the
+// history.
+func (h *History) Get(tick uint32) *Snapshot {
+	s := h.snaps[tick%uint32(len(h.snaps))]
+	if s == nil || s.Tick != tick {
+		return nil
+	}
+	return s
+}
+
+// NewHistory returns a new history holding up to n snapshots of consecutive
+// ticks.
+func NewHistory(n int) *History {
+	return &History{snaps: make([]*Snapshot, n)}
+}
@@ -0,0 +1,350 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package physics2d
+
+import (
+	"math"
+
+	"azul3d.org/engine/lmath"
+)
+
+// BodyType is the type of a body, which decides how it moves.
+type BodyType uint8
+
+const (
+	// Static bodies never move and have infinite mass (e.g. the ground).
+	Static BodyType = iota
+
+	// Kinematic bodies move by their velocity only, they are not affected by
+	// forces or collisions and have infinite mass (e.g. moving platforms).
+	Kinematic
+
+	// Dynamic bodies are fully simulated: they are affected by forces and
+	// collide with all other bodies.
+	Dynamic
+)
+
+// Body is a rigid body. Bodies are created with World.AddBody.
+type Body struct {
+	// The linear velocity of the body's center of mass, in meters per second.
+	LinearVelocity lmath.Vec2
+
+	// The angular velocity of the body, in radians per second.
+	AngularVelocity float64
+
+	// Damping reduces the linear and angular velocity of the body over time,
+	// independent of contacts (e.g. air resistance). Usually in the range of
+	// [0, 1].
+	LinearDamping, AngularDamping float64
+
+	// GravityScale scales the world's gravity for the body.
+	GravityScale float64
+
+	// FixedRotation prevents the body from rotating (e.g. for characters).
+	// After changing it, call ResetMassData.
+	FixedRotation bool
+
+	// UserData is an arbitrary value for the application to use, e.g. the
+	// entity that owns the body.
+	UserData interface{}
+
+	typ      BodyType
+	world    *World
+	fixtures []*Fixture
+
+	// The transform of the body's origin, the angle of it, and the world
+	// space center of mass.
+	xf     Transform
+	angle  float64
+	center lmath.Vec2
+
+	// The position and angle before the last step, for interpolation.
+	prevPos   lmath.Vec2
+	prevAngle float64
+
+	force  lmath.Vec2
+	torque float64
+
+	mass, invMass, inertia, invI float64
+	localCenter                  lmath.Vec2
+}
+
+// Type returns the type of the body.
+func (b *Body) Type() BodyType {
+	return b.typ
+}
+
+// World returns the world that the body is in, or nil if it was removed from
+// it.
+func (b *Body) World() *World {
+	return b.world
+}
+
+// Fixtures returns the fixtures attached to the body. The returned slice must
+// not be modified.
+func (b *Body) Fixtures() []*Fixture {
+	return b.fixtures
+}
+
+// AddFixture attaches a new fixture with the given shape and density (see
+// Fixture.Density) to the body, and updates the body's mass. The fixture
+// has the default properties:
+//
+//  Friction: 0.2
+//  Restitution: 0
+//  Sensor: false
+//  Filter: DefaultFilter
+//
+func (b *Body) AddFixture(shape Shape, density float64) *Fixture {
+	f := &Fixture{
+		Shape:    shape,
+		Density:  density,
+		Friction: 0.2,
+		Filter:   DefaultFilter,
+		body:     b,
+	}
+	f.bounds = shape.Bounds(b.xf)
+	b.fixtures = append(b.fixtures, f)
+	if b.world != nil {
+		b.world.addProxy(f)
+	}
+	b.ResetMassData()
+	return f
+}
+
+// RemoveFixture removes the given fixture from the body, destroying any of
+// it's contacts, and updates the body's mass.
+func (b *Body) RemoveFixture(f *Fixture) {
+	for i, other := range b.fixtures {
+		if other == f {
+			b.fixtures = append(b.fixtures[:i], b.fixtures[i+1:]...)
+			break
+		}
+	}
+	if b.world != nil {
+		b.world.removeFixtureContacts(f)
+	}
+	f.body = nil
+	b.ResetMassData()
+}
+
+// ResetMassData recomputes the mass, rotational inertia, and center of mass
+// of the body from it's fixtures. It is called automatically when fixtures are
+// added or removed.
+func (b *Body) ResetMassData() {
+	b.mass, b.invMass, b.inertia, b.invI = 0, 0, 0, 0
+	b.localCenter = lmath.Vec2Zero
+	if b.typ != Dynamic {
+		b.center = b.xf.Pos
+		return
+	}
+
+	// Accumulate the mass and inertia of each fixture, with the inertia about
+	// the body's origin.
+	var center lmath.Vec2
+	for _, f := range b.fixtures {
+		if f.Density == 0 {
+			continue
+		}
+		md := f.Shape.MassData(f.Density)
+		b.mass += md.Mass
+		center = center.Add(md.Center.MulScalar(md.Mass))
+		b.inertia += md.Inertia + md.Mass*md.Center.Dot(md.Center)
+	}
+	if b.mass > 0 {
+		b.invMass = 1 / b.mass
+		center = center.MulScalar(b.invMass)
+	} else {
+		// Dynamic bodies always have mass, such that forces affect them.
+		b.mass, b.invMass = 1, 1
+	}
+
+	// Shift the inertia to the center of mass.
+	if b.inertia > 0 && !b.FixedRotation {
+		b.inertia -= b.mass * center.Dot(center)
+		b.invI = 1 / b.inertia
+	} else {
+		b.inertia, b.invI = 0, 0
+	}
+	b.localCenter = center
+	b.center = b.xf.Apply(center)
+}
+
+// Mass returns the mass of the body in kilograms, or zero for static and
+// kinematic bodies.
+func (b *Body) Mass() float64 {
+	if b.typ != Dynamic {
+		return 0
+	}
+	return b.mass
+}
+
+// Inertia returns the rotational inertia of the body about it's center of
+// mass.
+func (b *Body) Inertia() float64 {
+	return b.inertia
+}
+
+// Position returns the world position of the body's origin.
+func (b *Body) Position() lmath.Vec2 {
+	return b.xf.Pos
+}
+
+// Angle returns the angle of the body in radians.
+func (b *Body) Angle() float64 {
+	return b.angle
+}
+
+// Transform returns the transform of the body's origin.
+func (b *Body) Transform() Transform {
+	return b.xf
+}
+
+// SetTransform teleports the body's origin to the given position and angle.
+// The body is not interpolated from it's old position (see Interpolated).
+func (b *Body) SetTransform(pos lmath.Vec2, angle float64) {
+	b.xf = Transform{Pos: pos, Rot: NewRot(angle)}
+	b.angle = angle
+	b.center = b.xf.Apply(b.localCenter)
+	b.prevPos, b.prevAngle = pos, angle
+	for _, f := range b.fixtures {
+		f.bounds = f.Shape.Bounds(b.xf)
+	}
+}
+
+// synchronize updates the body's origin transform from it's center of mass
+// and angle, after they are integrated.
+func (b *Body) synchronize() {
+	b.xf.Rot = NewRot(b.angle)
+	b.xf.Pos = b.center.Sub(b.xf.Rot.Apply(b.localCenter))
+}
+
+// Interpolated returns the position and angle of the body's origin
+// interpolated between the last two steps of the world by alpha, in the range
+// of [0, 1]. It is intended for rendering with the alpha value given to
+// clock.Loop's Render function, which avoids stuttering when the frame rate
+// is not a multiple of the physics rate.
+func (b *Body) Interpolated(alpha float64) (pos lmath.Vec2, angle float64) {
+	pos = b.prevPos.Add(b.xf.Pos.Sub(b.prevPos).MulScalar(alpha))
+	angle = b.prevAngle + (b.angle-b.prevAngle)*alpha
+	return
+}
+
+// WorldCenter returns the world position of the body's center of mass.
+func (b *Body) WorldCenter() lmath.Vec2 {
+	return b.center
+}
+
+// LocalCenter returns the body's center of mass, relative to it's origin.
+func (b *Body) LocalCenter() lmath.Vec2 {
+	return b.localCenter
+}
+
+// WorldPoint returns the given point, in the body's local space, in world
+// space.
+func (b *Body) WorldPoint(local lmath.Vec2) lmath.Vec2 {
+	return b.xf.Apply(local)
+}
+
+// LocalPoint returns the given point, in world space, in the body's local
+// space.
+func (b *Body) LocalPoint(world lmath.Vec2) lmath.Vec2 {
+	return b.xf.ApplyInv(world)
+}
+
+// VelocityAt returns the velocity of the given world point attached to the
+// body.
+func (b *Body) VelocityAt(p lmath.Vec2) lmath.Vec2 {
+	return b.LinearVelocity.Add(crossSV(b.AngularVelocity, p.Sub(b.center)))
+}
+
+// ApplyForce applies a force (in Newtons) at the given world point over the
+// next step, which also produces a torque if the point is not the center of
+// mass. It has no effect on non-dynamic bodies.
+func (b *Body) ApplyForce(force, point lmath.Vec2) {
+	if b.typ != Dynamic {
+		return
+	}
+	b.force = b.force.Add(force)
+	b.torque += cross(point.Sub(b.center), force)
+}
+
+// ApplyForceToCenter applies a force (in Newtons) to the center of mass over
+// the next step. It has no effect on non-dynamic bodies.
+func (b *Body) ApplyForceToCenter(force lmath.Vec2) {
+	if b.typ != Dynamic {
+		return
+	}
+	b.force = b.force.Add(force)
+}
+
+// ApplyTorque applies a torque (in Newton-meters) over the next step. It has
+// no effect on non-dynamic bodies.
+func (b *Body) ApplyTorque(torque float64) {
+	if b.typ != Dynamic {
+		return
+	}
+	b.torque += torque
+}
+
+// ApplyImpulse applies an impulse (in Newton-seconds) at the given world
+// point, which changes the velocity immediately (e.g. for a jump or an
+// explosion). It has no effect on non-dynamic bodies.
+func (b *Body) ApplyImpulse(impulse, point lmath.Vec2) {
+	if b.typ != Dynamic {
+		return
+	}
+	b.LinearVelocity = b.LinearVelocity.Add(impulse.MulScalar(b.invMass))
+	b.AngularVelocity += b.invI * cross(point.Sub(b.center), impulse)
+}
+
+// ApplyAngularImpulse applies an angular impulse, which changes the angular
+// velocity immediately. It has no effect on non-dynamic bodies.
+func (b *Body) ApplyAngularImpulse(impulse float64) {
+	if b.typ != Dynamic {
+		return
+	}
+	b.AngularVelocity += b.invI * impulse
+}
+
+// integrateVelocity integrates the forces and gravity acting on the body into
+// it's velocity.
+func (b *Body) integrateVelocity(gravity lmath.Vec2, dt float64) {
+	if b.typ != Dynamic {
+		return
+	}
+	accel := gravity.MulScalar(b.GravityScale).Add(b.force.MulScalar(b.invMass))
+	b.LinearVelocity = b.LinearVelocity.Add(accel.MulScalar(dt))
+	b.AngularVelocity += dt * b.invI * b.torque
+
+	// An implicit form of damping, which is stable for large damping values
+	// and time steps.
+	b.LinearVelocity = b.LinearVelocity.MulScalar(1 / (1 + dt*b.LinearDamping))
+	b.AngularVelocity *= 1 / (1 + dt*b.AngularDamping)
+}
+
+// integratePosition integrates the body's velocity into it's position.
+func (b *Body) integratePosition(dt float64) {
+	b.prevPos, b.prevAngle = b.xf.Pos, b.angle
+	if b.typ == Static {
+		return
+	}
+
+	// Limit the velocity such that a body never moves further than
+	// maxTranslation in a single step, which would tunnel it through others.
+	translation := b.LinearVelocity.MulScalar(dt)
+	if l := translation.Length(); l > maxTranslation {
+		b.LinearVelocity = b.LinearVelocity.MulScalar(maxTranslation / l)
+	}
+	if rotation := math.Abs(b.AngularVelocity * dt); rotation > maxRotation {
+		b.AngularVelocity *= maxRotation / rotation
+	}
+
+	b.center = b.center.Add(b.LinearVelocity.MulScalar(dt))
+	b.angle += b.AngularVelocity * dt
+	b.synchronize()
+	b.force = lmath.Vec2Zero
+	b.torque = 0
+}
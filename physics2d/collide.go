@@ -0,0 +1,282 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package physics2d
+
+import (
+	"math"
+
+	"azul3d.org/engine/lmath"
+)
+
+// ContactPoint is a single point of contact between two fixtures.
+type ContactPoint struct {
+	// The contact point in world space, midway between the two surfaces.
+	Point lmath.Vec2
+
+	// The penetration depth of the two fixtures at the point.
+	Depth float64
+
+	// The normal and tangent (friction) impulses applied at the point by the
+	// last step, e.g. for playing impact sounds or dealing damage.
+	NormalImpulse, TangentImpulse float64
+
+	// The geometric feature (vertex or edge) of each fixture that produced the
+	// point, which identifies it from one step to the next.
+	id uint32
+
+	// Solver data.
+	rA, rB                    lmath.Vec2
+	normalMass, tangentMass   float64
+	velocityBias, restitution float64
+}
+
+// Manifold describes the contact between two fixtures.
+type Manifold struct {
+	// The contact normal in world space, pointing from fixture A to fixture
+	// B.
+	Normal lmath.Vec2
+
+	// The contact points, of which the first Count are valid.
+	Points [2]ContactPoint
+	Count  int
+}
+
+// collide computes the contact manifold between two shapes transformed by
+// xfA and xfB, and reports whether they are touching.
+func collide(a Shape, xfA Transform, b Shape, xfB Transform) (m Manifold, ok bool) {
+	switch sa := a.(type) {
+	case *Circle:
+		switch sb := b.(type) {
+		case *Circle:
+			return collideCircles(sa, xfA, sb, xfB)
+		case *Polygon:
+			m, ok = collidePolygonCircle(sb, xfB, sa, xfA)
+			m.Normal = m.Normal.MulScalar(-1)
+			return m, ok
+		}
+	case *Polygon:
+		switch sb := b.(type) {
+		case *Circle:
+			return collidePolygonCircle(sa, xfA, sb, xfB)
+		case *Polygon:
+			return collidePolygons(sa, xfA, sb, xfB)
+		}
+	}
+	panic("physics2d: unsupported shape type")
+}
+
+// collideCircles computes the contact manifold between two circles.
+func collideCircles(a *Circle, xfA Transform, b *Circle, xfB Transform) (m Manifold, ok bool) {
+	pA, pB := xfA.Apply(a.Center), xfB.Apply(b.Center)
+	d := pB.Sub(pA)
+	distSq := d.LengthSq()
+	radius := a.Radius + b.Radius
+	if distSq > radius*radius {
+		return m, false
+	}
+	dist := math.Sqrt(distSq)
+	m.Normal = lmath.Vec2{Y: 1}
+	if dist > lmath.EPSILON {
+		m.Normal = d.DivScalar(dist)
+	}
+	surfA := pA.Add(m.Normal.MulScalar(a.Radius))
+	surfB := pB.Sub(m.Normal.MulScalar(b.Radius))
+	m.Points[0] = ContactPoint{
+		Point: surfA.Add(surfB).MulScalar(0.5),
+		Depth: radius - dist,
+	}
+	m.Count = 1
+	return m, true
+}
+
+// collidePolygonCircle computes the contact manifold between a polygon and a
+// circle, with the normal pointing from the polygon to the circle.
+func collidePolygonCircle(a *Polygon, xfA Transform, b *Circle, xfB Transform) (m Manifold, ok bool) {
+	// Work in the local space of the polygon, and find the edge of least
+	// penetration.
+	c := xfA.ApplyInv(xfB.Apply(b.Center))
+	edge, separation := 0, -math.MaxFloat64
+	for i, n := range a.Normals {
+		s := n.Dot(c.Sub(a.Vertices[i]))
+		if s > b.Radius {
+			return m, false
+		}
+		if s > separation {
+			edge, separation = i, s
+		}
+	}
+
+	v1 := a.Vertices[edge]
+	v2 := a.Vertices[(edge+1)%len(a.Vertices)]
+	normal := a.Normals[edge]
+	surfA := c.Sub(normal.MulScalar(separation))
+	id := uint32(edge)
+	if separation > lmath.EPSILON {
+		// The center is outside the polygon, find whether it is closest to
+		// the edge or one of it's vertices.
+		switch {
+		case c.Sub(v1).Dot(v2.Sub(v1)) <= 0:
+			surfA, id = v1, 0x100|uint32(edge)
+		case c.Sub(v2).Dot(v1.Sub(v2)) <= 0:
+			surfA, id = v2, 0x100|uint32((edge+1)%len(a.Vertices))
+		}
+		if id&0x100 != 0 {
+			d := c.Sub(surfA)
+			dist := d.Length()
+			if dist > b.Radius {
+				return m, false
+			}
+			normal, _ = d.Normalized()
+			separation = dist
+		}
+	}
+
+	surfB := c.Sub(normal.MulScalar(b.Radius))
+	m.Normal = xfA.Rot.Apply(normal)
+	m.Points[0] = ContactPoint{
+		Point: xfA.Apply(surfA.Add(surfB).MulScalar(0.5)),
+		Depth: b.Radius - separation,
+		id:    id,
+	}
+	m.Count = 1
+	return m, true
+}
+
+// worldPolygon is a polygon transformed into world space.
+type worldPolygon struct {
+	vertices, normals []lmath.Vec2
+}
+
+func newWorldPolygon(p *Polygon, xf Transform) worldPolygon {
+	w := worldPolygon{
+		vertices: make([]lmath.Vec2, len(p.Vertices)),
+		normals:  make([]lmath.Vec2, len(p.Normals)),
+	}
+	for i, v := range p.Vertices {
+		w.vertices[i] = xf.Apply(v)
+		w.normals[i] = xf.Rot.Apply(p.Normals[i])
+	}
+	return w
+}
+
+// maxSeparation finds the edge of a with the largest separation from b.
+func maxSeparation(a, b worldPolygon) (edge int, separation float64) {
+	separation = -math.MaxFloat64
+	for i, n := range a.normals {
+		s := math.MaxFloat64
+		for _, v := range b.vertices {
+			if d := n.Dot(v.Sub(a.vertices[i])); d < s {
+				s = d
+			}
+		}
+		if s > separation {
+			edge, separation = i, s
+		}
+	}
+	return
+}
+
+// clipVertex is a vertex of the incident edge during clipping.
+type clipVertex struct {
+	v  lmath.Vec2
+	id uint32
+}
+
+// clipSegment clips the segment in to the half-plane where normal.Dot(v) <=
+// offset. Vertices created by clipping are given the id clipID.
+func clipSegment(in [2]clipVertex, normal lmath.Vec2, offset float64, clipID uint32) (out [2]clipVertex, n int) {
+	d0 := normal.Dot(in[0].v) - offset
+	d1 := normal.Dot(in[1].v) - offset
+	if d0 <= 0 {
+		out[n] = in[0]
+		n++
+	}
+	if d1 <= 0 {
+		out[n] = in[1]
+		n++
+	}
+	if d0*d1 < 0 {
+		t := d0 / (d0 - d1)
+		out[n] = clipVertex{
+			v:  in[0].v.Add(in[1].v.Sub(in[0].v).MulScalar(t)),
+			id: clipID,
+		}
+		n++
+	}
+	return
+}
+
+// collidePolygons computes the contact manifold between two polygons using
+// the separating axis theorem, clipping the incident edge of one polygon
+// against the reference edge (the edge of least penetration) of the other.
+func collidePolygons(pa *Polygon, xfA Transform, pb *Polygon, xfB Transform) (m Manifold, ok bool) {
+	a, b := newWorldPolygon(pa, xfA), newWorldPolygon(pb, xfB)
+	edgeA, sepA := maxSeparation(a, b)
+	if sepA > 0 {
+		return m, false
+	}
+	edgeB, sepB := maxSeparation(b, a)
+	if sepB > 0 {
+		return m, false
+	}
+
+	// Prefer polygon A as the reference, such that the choice does not
+	// flip-flop between steps due to rounding.
+	ref, inc, edge, flip := a, b, edgeA, uint32(0)
+	if sepB > sepA+0.1*linearSlop {
+		ref, inc, edge, flip = b, a, edgeB, 1
+	}
+
+	// The incident edge is the edge of the incident polygon most
+	// anti-parallel to the reference edge.
+	normal := ref.normals[edge]
+	incEdge, minDot := 0, math.MaxFloat64
+	for i, n := range inc.normals {
+		if d := normal.Dot(n); d < minDot {
+			incEdge, minDot = i, d
+		}
+	}
+	i1, i2 := incEdge, (incEdge+1)%len(inc.vertices)
+	incident := [2]clipVertex{
+		{v: inc.vertices[i1], id: uint32(i1)},
+		{v: inc.vertices[i2], id: uint32(i2)},
+	}
+
+	// Clip the incident edge to the side planes of the reference edge.
+	r1, r2 := edge, (edge+1)%len(ref.vertices)
+	v1, v2 := ref.vertices[r1], ref.vertices[r2]
+	tangent, _ := v2.Sub(v1).Normalized()
+	clipped, n := clipSegment(incident, tangent.MulScalar(-1), -tangent.Dot(v1), 0x80|uint32(r1))
+	if n < 2 {
+		return m, false
+	}
+	clipped, n = clipSegment(clipped, tangent, tangent.Dot(v2), 0x80|uint32(r2))
+	if n < 2 {
+		return m, false
+	}
+
+	// Keep the points that are behind the reference edge.
+	front := normal.Dot(v1)
+	for _, cv := range clipped {
+		separation := normal.Dot(cv.v) - front
+		if separation > 0 {
+			continue
+		}
+		m.Points[m.Count] = ContactPoint{
+			Point: cv.v.Sub(normal.MulScalar(separation / 2)),
+			Depth: -separation,
+			id:    flip<<16 | uint32(edge)<<8 | cv.id,
+		}
+		m.Count++
+	}
+	if m.Count == 0 {
+		return m, false
+	}
+	m.Normal = normal
+	if flip != 0 {
+		m.Normal = normal.MulScalar(-1)
+	}
+	return m, true
+}
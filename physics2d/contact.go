@@ -0,0 +1,118 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package physics2d
+
+import (
+	"math"
+
+	"azul3d.org/engine/lmath"
+)
+
+// Contact is a contact between two touching fixtures. Contacts are created
+// and destroyed by the world as fixtures begin and end touching, see
+// World.BeginContact and World.EndContact.
+type Contact struct {
+	// The two fixtures that are touching.
+	FixtureA, FixtureB *Fixture
+
+	// The contact manifold as of the last step.
+	Manifold Manifold
+
+	// The mixed friction and restitution of the two fixtures, which may be
+	// changed by World.BeginContact (e.g. for a bouncy pad).
+	Friction, Restitution float64
+
+	key  pairKey
+	seen bool
+}
+
+// Sensor tells if either fixture of the contact is a sensor, in which case
+// there is no collision response.
+func (c *Contact) Sensor() bool {
+	return c.FixtureA.Sensor || c.FixtureB.Sensor
+}
+
+// update replaces the manifold of the contact, carrying the accumulated
+// impulses of matching points over for warm starting.
+func (c *Contact) update(m Manifold) {
+	old := c.Manifold
+	for i := 0; i < m.Count; i++ {
+		p := &m.Points[i]
+		for j := 0; j < old.Count; j++ {
+			if old.Points[j].id == p.id {
+				p.NormalImpulse = old.Points[j].NormalImpulse
+				p.TangentImpulse = old.Points[j].TangentImpulse
+				break
+			}
+		}
+	}
+	c.Manifold = m
+}
+
+// initVelocity prepares the contact for solving, and applies the impulses of
+// the last step (warm starting).
+func (c *Contact) initVelocity(s step) {
+	a, b := c.FixtureA.body, c.FixtureB.body
+	m := &c.Manifold
+	tangent := crossVS(m.Normal, 1)
+	for i := 0; i < m.Count; i++ {
+		p := &m.Points[i]
+		p.rA = p.Point.Sub(a.center)
+		p.rB = p.Point.Sub(b.center)
+
+		rnA, rnB := cross(p.rA, m.Normal), cross(p.rB, m.Normal)
+		k := a.invMass + b.invMass + a.invI*rnA*rnA + b.invI*rnB*rnB
+		p.normalMass = invOrZero(k)
+
+		rtA, rtB := cross(p.rA, tangent), cross(p.rB, tangent)
+		k = a.invMass + b.invMass + a.invI*rtA*rtA + b.invI*rtB*rtB
+		p.tangentMass = invOrZero(k)
+
+		// Push the bodies apart by a fraction of the penetration per step
+		// (Baumgarte stabilization), allowing some slop to keep resting
+		// contacts persistent.
+		p.velocityBias = baumgarte * s.invDt * math.Max(0, p.Depth-linearSlop)
+
+		// Restitution is only applied above a threshold velocity, otherwise
+		// resting bodies would jitter.
+		p.restitution = 0
+		dv := b.VelocityAt(p.Point).Sub(a.VelocityAt(p.Point))
+		if vn := dv.Dot(m.Normal); vn < -velocityThreshold {
+			p.restitution = -c.Restitution * vn
+		}
+
+		impulse := m.Normal.MulScalar(p.NormalImpulse).Add(tangent.MulScalar(p.TangentImpulse))
+		applyImpulse(a, b, p.rA, p.rB, impulse)
+	}
+}
+
+// solveVelocity applies friction and normal impulses at each contact point.
+func (c *Contact) solveVelocity() {
+	a, b := c.FixtureA.body, c.FixtureB.body
+	m := &c.Manifold
+	tangent := crossVS(m.Normal, 1)
+
+	// Solve friction first, as the non-penetration constraint is more
+	// important.
+	for i := 0; i < m.Count; i++ {
+		p := &m.Points[i]
+		dv := relativeVelocity(a, b, p.rA, p.rB)
+		lambda := -p.tangentMass * dv.Dot(tangent)
+		maxFriction := c.Friction * p.NormalImpulse
+		old := p.TangentImpulse
+		p.TangentImpulse = lmath.Clamp(old+lambda, -maxFriction, maxFriction)
+		applyImpulse(a, b, p.rA, p.rB, tangent.MulScalar(p.TangentImpulse-old))
+	}
+
+	for i := 0; i < m.Count; i++ {
+		p := &m.Points[i]
+		dv := relativeVelocity(a, b, p.rA, p.rB)
+		bias := math.Max(p.velocityBias, p.restitution)
+		lambda := -p.normalMass * (dv.Dot(m.Normal) - bias)
+		old := p.NormalImpulse
+		p.NormalImpulse = math.Max(old+lambda, 0)
+		applyImpulse(a, b, p.rA, p.rB, m.Normal.MulScalar(p.NormalImpulse-old))
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package physics2d
+
+import (
+	"math"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/debugdraw"
+	"azul3d.org/engine/lmath"
+)
+
+// The colors used by DebugDraw.
+var (
+	debugStatic    = gfx.Color{R: 0.5, G: 0.9, B: 0.5, A: 1}
+	debugKinematic = gfx.Color{R: 0.5, G: 0.5, B: 0.9, A: 1}
+	debugDynamic   = gfx.Color{R: 0.9, G: 0.7, B: 0.7, A: 1}
+	debugSensor    = gfx.Color{R: 0.9, G: 0.9, B: 0.3, A: 1}
+	debugJoint     = gfx.Color{R: 0.5, G: 0.8, B: 0.8, A: 1}
+	debugContact   = gfx.Color{R: 1, G: 0.2, B: 0.2, A: 1}
+)
+
+// debugContactLength is the length of the contact normals drawn by DebugDraw.
+const debugContactLength = 0.25
+
+// vec3 maps a point in the simulation to the XZ plane of the world.
+func vec3(v lmath.Vec2) lmath.Vec3 {
+	return lmath.Vec3{X: v.X, Z: v.Y}
+}
+
+// DebugDraw adds the outlines of the fixtures in the world, the joints, and
+// the contact points and normals to the given debug drawer. The simulation's
+// X and Y axes are mapped to the X and Z axes of the world.
+func (w *World) DebugDraw(d *debugdraw.Drawer) {
+	for _, b := range w.bodies {
+		c := debugDynamic
+		switch b.typ {
+		case Static:
+			c = debugStatic
+		case Kinematic:
+			c = debugKinematic
+		}
+		for _, f := range b.fixtures {
+			fc := c
+			if f.Sensor {
+				fc = debugSensor
+			}
+			debugDrawShape(d, f.Shape, b.xf, fc)
+		}
+	}
+
+	for _, j := range w.joints {
+		a, b := j.Bodies()
+		switch j := j.(type) {
+		case *DistanceJoint:
+			d.AddLine(vec3(a.WorldPoint(j.LocalAnchorA)), vec3(b.WorldPoint(j.LocalAnchorB)), debugJoint)
+		case *RevoluteJoint:
+			anchor := vec3(a.WorldPoint(j.LocalAnchorA))
+			d.AddLine(vec3(a.center), anchor, debugJoint)
+			d.AddLine(anchor, vec3(b.center), debugJoint)
+		}
+	}
+
+	for _, c := range w.contacts {
+		m := &c.Manifold
+		for i := 0; i < m.Count; i++ {
+			p := m.Points[i].Point
+			d.AddLine(vec3(p), vec3(p.Add(m.Normal.MulScalar(debugContactLength))), debugContact)
+		}
+	}
+}
+
+// debugDrawShape adds the outline of the shape transformed by xf.
+func debugDrawShape(d *debugdraw.Drawer, s Shape, xf Transform, c gfx.Color) {
+	switch s := s.(type) {
+	case *Circle:
+		center := xf.Apply(s.Center)
+		prev := center.Add(lmath.Vec2{X: s.Radius})
+		for i := 1; i <= debugdraw.SphereSegments; i++ {
+			sin, cos := math.Sincos(float64(i) / debugdraw.SphereSegments * 2 * math.Pi)
+			cur := center.Add(lmath.Vec2{X: cos * s.Radius, Y: sin * s.Radius})
+			d.AddLine(vec3(prev), vec3(cur), c)
+			prev = cur
+		}
+
+		// A radius line shows the rotation of the circle.
+		d.AddLine(vec3(center), vec3(xf.Apply(s.Center.Add(lmath.Vec2{X: s.Radius}))), c)
+
+	case *Polygon:
+		for i, v := range s.Vertices {
+			next := s.Vertices[(i+1)%len(s.Vertices)]
+			d.AddLine(vec3(xf.Apply(v)), vec3(xf.Apply(next)), c)
+		}
+	}
+}
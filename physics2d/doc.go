@@ -0,0 +1,42 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package physics2d implements a 2D rigid body physics simulation.
+//
+// The design closely follows Erin Catto's Box2D: a World contains Bodies,
+// each of which has one or more Fixtures that attach a collision Shape
+// (a Circle or convex Polygon) along with it's material properties. Joints
+// constrain the motion of two bodies relative to each other.
+//
+// Each step, a sort-and-sweep broadphase finds pairs of fixtures whose bounds
+// overlap, the narrow phase generates contact manifolds for them, and a
+// sequential impulse solver (with warm starting) resolves the contacts and
+// joints.
+//
+// The simulation is stable only with a fixed timestep, which is why World
+// implements the Update function signature of clock.Loop directly:
+//
+//  world := physics2d.NewWorld(lmath.Vec2{Y: -9.8})
+//  ground := world.AddBody(physics2d.Static)
+//  ground.AddFixture(physics2d.NewBox(10, 0.5), 0)
+//
+//  box := world.AddBody(physics2d.Dynamic)
+//  box.SetTransform(lmath.Vec2{Y: 4}, 0)
+//  box.AddFixture(physics2d.NewBox(0.5, 0.5), 1)
+//
+//  loop := &clock.Loop{Step: time.Second / 60, ...}
+//  loop.Update = world.Update
+//  loop.Render = func(alpha float64) {
+//      pos, angle := box.Interpolated(alpha)
+//      ...
+//  }
+//
+// Units are meters, kilograms and seconds; the solver's tolerances are tuned
+// for moving objects between roughly 0.1 and 10 meters in size, so scale
+// pixel coordinates down accordingly.
+//
+// The simulation is in the XY plane. Following the tmx package, which lays 2D
+// maps out in the XZ plane of the (Z-up) world, the DebugDraw method maps the
+// X and Y axes of the simulation to the X and Z axes of the world.
+package physics2d // import "azul3d.org/engine/physics2d"
@@ -0,0 +1,79 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package physics2d
+
+import "azul3d.org/engine/lmath"
+
+// Filter controls which fixtures may collide with each other.
+//
+// Two fixtures collide when each one's category is in the other's mask. If
+// both fixtures share the same non-zero group, then the group overrides that:
+// a positive group always collides and a negative group never does (e.g. to
+// stop the limbs of a ragdoll from colliding with each other).
+type Filter struct {
+	Category, Mask uint16
+	Group          int16
+}
+
+// DefaultFilter is the default filter of a fixture, which collides with
+// everything.
+var DefaultFilter = Filter{Category: 1, Mask: 0xffff}
+
+// ShouldCollide tells if fixtures with the filters a and b should collide.
+func (a Filter) ShouldCollide(b Filter) bool {
+	if a.Group == b.Group && a.Group != 0 {
+		return a.Group > 0
+	}
+	return a.Mask&b.Category != 0 && b.Mask&a.Category != 0
+}
+
+// Fixture attaches a shape to a body, along with it's material properties.
+// Fixtures are created with Body.AddFixture.
+type Fixture struct {
+	// The shape of the fixture, in the local space of the body. It must not
+	// be modified once the fixture is created.
+	Shape Shape
+
+	// The density of the fixture in kilograms per square meter. After
+	// changing it, call Body.ResetMassData to update the body's mass.
+	Density float64
+
+	// The coulomb friction coefficient, usually in the range of [0, 1].
+	Friction float64
+
+	// The restitution (bounciness), usually in the range of [0, 1].
+	Restitution float64
+
+	// A sensor fixture detects contacts (see World.BeginContact) but does not
+	// generate a collision response.
+	Sensor bool
+
+	// The collision filter of the fixture.
+	Filter Filter
+
+	// UserData is an arbitrary value for the application to use, e.g. the
+	// entity that owns the fixture.
+	UserData interface{}
+
+	body   *Body
+	bounds AABB
+	id     uint64
+}
+
+// Body returns the body that the fixture is attached to.
+func (f *Fixture) Body() *Body {
+	return f.body
+}
+
+// Bounds returns the world space bounding box of the fixture as of the last
+// step of the world.
+func (f *Fixture) Bounds() AABB {
+	return f.bounds
+}
+
+// TestPoint tells if the given point (in world space) is inside the fixture.
+func (f *Fixture) TestPoint(p lmath.Vec2) bool {
+	return f.Shape.TestPoint(f.body.xf, p)
+}
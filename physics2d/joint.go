@@ -0,0 +1,214 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package physics2d
+
+import (
+	"math"
+
+	"azul3d.org/engine/lmath"
+)
+
+// Joint constrains the motion of two bodies relative to each other. Joints
+// are added to a world with World.AddJoint.
+type Joint interface {
+	// Bodies returns the two bodies connected by the joint.
+	Bodies() (a, b *Body)
+
+	// CollideConnected tells if the two bodies connected by the joint may
+	// collide with each other.
+	CollideConnected() bool
+
+	initVelocity(s step)
+	solveVelocity()
+}
+
+// jointBase implements the parts common to all joints.
+type jointBase struct {
+	a, b             *Body
+	collideConnected bool
+}
+
+// Bodies implements the Joint interface.
+func (j *jointBase) Bodies() (a, b *Body) {
+	return j.a, j.b
+}
+
+// CollideConnected implements the Joint interface.
+func (j *jointBase) CollideConnected() bool {
+	return j.collideConnected
+}
+
+// SetCollideConnected sets whether the two bodies connected by the joint may
+// collide with each other, by default they do not.
+func (j *jointBase) SetCollideConnected(collide bool) {
+	j.collideConnected = collide
+}
+
+// DistanceJoint keeps two anchor points on two bodies at a fixed distance
+// from each other, like a rod, or at a rest length like a spring.
+type DistanceJoint struct {
+	jointBase
+
+	// The anchor points in the local space of each body.
+	LocalAnchorA, LocalAnchorB lmath.Vec2
+
+	// The rest length of the joint.
+	Length float64
+
+	// The frequency in Hertz and damping ratio (usually in the range of [0,
+	// 1]) of the spring. If the frequency is zero, the joint is rigid.
+	Frequency, DampingRatio float64
+
+	impulse, mass, bias, gamma float64
+	u, rA, rB                  lmath.Vec2
+}
+
+// NewDistanceJoint returns a new rigid distance joint between the two
+// bodies, with the given anchor points in world space. The length is the
+// current distance between the anchors.
+func NewDistanceJoint(a, b *Body, anchorA, anchorB lmath.Vec2) *DistanceJoint {
+	return &DistanceJoint{
+		jointBase:    jointBase{a: a, b: b},
+		LocalAnchorA: a.LocalPoint(anchorA),
+		LocalAnchorB: b.LocalPoint(anchorB),
+		Length:       anchorB.Sub(anchorA).Length(),
+	}
+}
+
+func (j *DistanceJoint) initVelocity(s step) {
+	a, b := j.a, j.b
+	j.rA = a.xf.Rot.Apply(j.LocalAnchorA.Sub(a.localCenter))
+	j.rB = b.xf.Rot.Apply(j.LocalAnchorB.Sub(b.localCenter))
+	j.u = b.center.Add(j.rB).Sub(a.center.Add(j.rA))
+	length := j.u.Length()
+	if length > linearSlop {
+		j.u = j.u.DivScalar(length)
+	} else {
+		j.u = lmath.Vec2Zero
+	}
+
+	crA, crB := cross(j.rA, j.u), cross(j.rB, j.u)
+	invMass := a.invMass + a.invI*crA*crA + b.invMass + b.invI*crB*crB
+	c := length - j.Length
+	if j.Frequency > 0 {
+		// A soft constraint, see Erin Catto's "Soft Constraints" talk.
+		mass := invOrZero(invMass)
+		omega := 2 * math.Pi * j.Frequency
+		d := 2 * mass * j.DampingRatio * omega
+		k := mass * omega * omega
+		j.gamma = invOrZero(s.dt * (d + s.dt*k))
+		j.bias = c * s.dt * k * j.gamma
+		invMass += j.gamma
+	} else {
+		j.gamma = 0
+		j.bias = baumgarte * s.invDt * c
+	}
+	j.mass = invOrZero(invMass)
+
+	applyImpulse(a, b, j.rA, j.rB, j.u.MulScalar(j.impulse))
+}
+
+func (j *DistanceJoint) solveVelocity() {
+	cdot := j.u.Dot(relativeVelocity(j.a, j.b, j.rA, j.rB))
+	lambda := -j.mass * (cdot + j.bias + j.gamma*j.impulse)
+	j.impulse += lambda
+	applyImpulse(j.a, j.b, j.rA, j.rB, j.u.MulScalar(lambda))
+}
+
+// RevoluteJoint pins two bodies together at an anchor point, around which
+// they may rotate freely (e.g. a hinge or a wheel). It has an optional motor.
+type RevoluteJoint struct {
+	jointBase
+
+	// The anchor points in the local space of each body.
+	LocalAnchorA, LocalAnchorB lmath.Vec2
+
+	// If the motor is enabled, it drives the relative angular velocity of
+	// the bodies towards MotorSpeed (in radians per second), applying at most
+	// MaxMotorTorque.
+	EnableMotor    bool
+	MotorSpeed     float64
+	MaxMotorTorque float64
+
+	impulse      lmath.Vec2
+	motorImpulse float64
+	rA, rB       lmath.Vec2
+	bias         lmath.Vec2
+	k            [2][2]float64
+	motorMass    float64
+	maxImpulse   float64
+	invDt        float64
+}
+
+// NewRevoluteJoint returns a new revolute joint between the two bodies, at
+// the given anchor point in world space.
+func NewRevoluteJoint(a, b *Body, anchor lmath.Vec2) *RevoluteJoint {
+	return &RevoluteJoint{
+		jointBase:    jointBase{a: a, b: b},
+		LocalAnchorA: a.LocalPoint(anchor),
+		LocalAnchorB: b.LocalPoint(anchor),
+	}
+}
+
+// MotorTorque returns the torque applied by the motor over the last step.
+func (j *RevoluteJoint) MotorTorque() float64 {
+	return j.motorImpulse * j.invDt
+}
+
+func (j *RevoluteJoint) initVelocity(s step) {
+	a, b := j.a, j.b
+	j.rA = a.xf.Rot.Apply(j.LocalAnchorA.Sub(a.localCenter))
+	j.rB = b.xf.Rot.Apply(j.LocalAnchorB.Sub(b.localCenter))
+
+	// The inverse of the effective mass matrix:
+	//
+	//  K = (mA + mB) * I + iA * skew(rA) + iB * skew(rB)
+	//
+	mA, mB, iA, iB := a.invMass, b.invMass, a.invI, b.invI
+	k00 := mA + mB + iA*j.rA.Y*j.rA.Y + iB*j.rB.Y*j.rB.Y
+	k01 := -iA*j.rA.X*j.rA.Y - iB*j.rB.X*j.rB.Y
+	k11 := mA + mB + iA*j.rA.X*j.rA.X + iB*j.rB.X*j.rB.X
+	det := invOrZero(k00*k11 - k01*k01)
+	j.k = [2][2]float64{
+		{det * k11, -det * k01},
+		{-det * k01, det * k00},
+	}
+
+	// Pull the anchors together by a fraction of their separation per step.
+	pA := a.center.Add(j.rA)
+	pB := b.center.Add(j.rB)
+	j.bias = pB.Sub(pA).MulScalar(-baumgarte * s.invDt)
+
+	j.motorMass = invOrZero(iA + iB)
+	j.maxImpulse = j.MaxMotorTorque * s.dt
+	j.invDt = s.invDt
+	if !j.EnableMotor {
+		j.motorImpulse = 0
+	}
+
+	applyImpulse(a, b, j.rA, j.rB, j.impulse)
+	a.AngularVelocity -= iA * j.motorImpulse
+	b.AngularVelocity += iB * j.motorImpulse
+}
+
+func (j *RevoluteJoint) solveVelocity() {
+	a, b := j.a, j.b
+	if j.EnableMotor {
+		cdot := b.AngularVelocity - a.AngularVelocity - j.MotorSpeed
+		old := j.motorImpulse
+		j.motorImpulse = lmath.Clamp(old-j.motorMass*cdot, -j.maxImpulse, j.maxImpulse)
+		lambda := j.motorImpulse - old
+		a.AngularVelocity -= a.invI * lambda
+		b.AngularVelocity += b.invI * lambda
+	}
+
+	rhs := j.bias.Sub(relativeVelocity(a, b, j.rA, j.rB))
+	lambda := lmath.Vec2{
+		X: j.k[0][0]*rhs.X + j.k[0][1]*rhs.Y,
+		Y: j.k[1][0]*rhs.X + j.k[1][1]*rhs.Y,
+	}
+	j.impulse = j.impulse.Add(lambda)
+	applyImpulse(a, b, j.rA, j.rB, lambda)
+}
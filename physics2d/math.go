@@ -0,0 +1,130 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package physics2d
+
+import (
+	"fmt"
+	"math"
+
+	"azul3d.org/engine/lmath"
+)
+
+// Rot is a 2D rotation, stored as the sine and cosine of it's angle.
+type Rot struct {
+	Sin, Cos float64
+}
+
+// RotIdentity is the identity rotation.
+var RotIdentity = Rot{Sin: 0, Cos: 1}
+
+// NewRot returns the rotation by the given angle in radians.
+func NewRot(angle float64) Rot {
+	s, c := math.Sincos(angle)
+	return Rot{Sin: s, Cos: c}
+}
+
+// Angle returns the angle of the rotation in radians, in the range of
+// [-Pi, Pi].
+func (r Rot) Angle() float64 {
+	return math.Atan2(r.Sin, r.Cos)
+}
+
+// Apply returns the vector v rotated by r.
+func (r Rot) Apply(v lmath.Vec2) lmath.Vec2 {
+	return lmath.Vec2{
+		X: r.Cos*v.X - r.Sin*v.Y,
+		Y: r.Sin*v.X + r.Cos*v.Y,
+	}
+}
+
+// ApplyInv returns the vector v rotated by the inverse of r.
+func (r Rot) ApplyInv(v lmath.Vec2) lmath.Vec2 {
+	return lmath.Vec2{
+		X: r.Cos*v.X + r.Sin*v.Y,
+		Y: -r.Sin*v.X + r.Cos*v.Y,
+	}
+}
+
+// Transform is a 2D rigid transformation: a rotation followed by a
+// translation.
+type Transform struct {
+	Pos lmath.Vec2
+	Rot Rot
+}
+
+// TransformIdentity is the identity transformation.
+var TransformIdentity = Transform{Rot: RotIdentity}
+
+// String returns an string representation of this transform.
+func (t Transform) String() string {
+	return fmt.Sprintf("Transform(Pos=%v, Angle=%v)", t.Pos, t.Rot.Angle())
+}
+
+// Apply returns the point p transformed by t, i.e. from the local space of t
+// into the parent (world) space.
+func (t Transform) Apply(p lmath.Vec2) lmath.Vec2 {
+	return t.Rot.Apply(p).Add(t.Pos)
+}
+
+// ApplyInv returns the point p transformed by the inverse of t, i.e. from
+// the parent (world) space into the local space of t.
+func (t Transform) ApplyInv(p lmath.Vec2) lmath.Vec2 {
+	return t.Rot.ApplyInv(p.Sub(t.Pos))
+}
+
+// AABB is a 2D axis-aligned bounding box.
+type AABB struct {
+	Min, Max lmath.Vec2
+}
+
+// String returns an string representation of this box.
+func (a AABB) String() string {
+	return fmt.Sprintf("AABB(Min=%v, Max=%v)", a.Min, a.Max)
+}
+
+// Overlaps tells if the two boxes overlap (or touch).
+func (a AABB) Overlaps(b AABB) bool {
+	return a.Min.X <= b.Max.X && b.Min.X <= a.Max.X &&
+		a.Min.Y <= b.Max.Y && b.Min.Y <= a.Max.Y
+}
+
+// Contains tells if the point p is inside the box.
+func (a AABB) Contains(p lmath.Vec2) bool {
+	return p.X >= a.Min.X && p.X <= a.Max.X && p.Y >= a.Min.Y && p.Y <= a.Max.Y
+}
+
+// Union returns the smallest box containing both boxes.
+func (a AABB) Union(b AABB) AABB {
+	return AABB{Min: a.Min.Min(b.Min), Max: a.Max.Max(b.Max)}
+}
+
+// Expand returns the box grown by the given amount on each side.
+func (a AABB) Expand(amount float64) AABB {
+	return AABB{Min: a.Min.SubScalar(amount), Max: a.Max.AddScalar(amount)}
+}
+
+// Center returns the center point of the box.
+func (a AABB) Center() lmath.Vec2 {
+	return a.Min.Add(a.Max).MulScalar(0.5)
+}
+
+// cross returns the 2D cross product (the Z component of the 3D one) of a and
+// b.
+func cross(a, b lmath.Vec2) float64 {
+	return a.X*b.Y - a.Y*b.X
+}
+
+// crossSV returns the cross product of the scalar (a vector along Z) s and
+// the vector v, i.e. v rotated by 90 degrees counter-clockwise and scaled by
+// s.
+func crossSV(s float64, v lmath.Vec2) lmath.Vec2 {
+	return lmath.Vec2{X: -s * v.Y, Y: s * v.X}
+}
+
+// crossVS returns the cross product of the vector v and the scalar (a vector
+// along Z) s, i.e. v rotated by 90 degrees clockwise and scaled by s.
+func crossVS(v lmath.Vec2, s float64) lmath.Vec2 {
+	return lmath.Vec2{X: s * v.Y, Y: -s * v.X}
+}
@@ -0,0 +1,376 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package physics2d
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"azul3d.org/engine/gfx/debugdraw"
+	"azul3d.org/engine/lmath"
+)
+
+const dt = 1.0 / 60
+
+func TestPolygonHull(t *testing.T) {
+	p := NewPolygon(
+		lmath.Vec2{X: 1, Y: 1},
+		lmath.Vec2{X: -1, Y: -1},
+		lmath.Vec2{X: 0, Y: 0},  // Interior.
+		lmath.Vec2{X: 0, Y: -1}, // Collinear.
+		lmath.Vec2{X: -1, Y: 1},
+		lmath.Vec2{X: 1, Y: -1},
+	)
+	if len(p.Vertices) != 4 {
+		t.Fatalf("got %d vertices, want 4: %v", len(p.Vertices), p.Vertices)
+	}
+	for i, v := range p.Vertices {
+		next := p.Vertices[(i+1)%len(p.Vertices)]
+		if cross(v, next) <= 0 {
+			t.Fatalf("vertices are not counter-clockwise: %v", p.Vertices)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic for a degenerate polygon")
+		}
+	}()
+	NewPolygon(lmath.Vec2{}, lmath.Vec2{X: 1}, lmath.Vec2{X: 2})
+}
+
+func TestMassData(t *testing.T) {
+	box := NewPolygon(
+		lmath.Vec2{X: 2, Y: 3},
+		lmath.Vec2{X: 4, Y: 3},
+		lmath.Vec2{X: 4, Y: 4},
+		lmath.Vec2{X: 2, Y: 4},
+	)
+	md := box.MassData(2)
+	if !lmath.AlmostEqual(md.Mass, 4, 1e-9) {
+		t.Errorf("box mass = %v, want 4", md.Mass)
+	}
+	if !md.Center.AlmostEquals(lmath.Vec2{X: 3, Y: 3.5}, 1e-9) {
+		t.Errorf("box center = %v, want (3, 3.5)", md.Center)
+	}
+	if want := 4 * (4 + 1) / 12.0; !lmath.AlmostEqual(md.Inertia, want, 1e-9) {
+		t.Errorf("box inertia = %v, want %v", md.Inertia, want)
+	}
+
+	md = NewCircle(2).MassData(1)
+	if !lmath.AlmostEqual(md.Mass, 4*math.Pi, 1e-9) || !lmath.AlmostEqual(md.Inertia, 8*math.Pi, 1e-9) {
+		t.Errorf("circle mass data = %+v", md)
+	}
+}
+
+var collideTests = []struct {
+	a, b   Shape
+	xfB    Transform
+	ok     bool
+	normal lmath.Vec2
+	count  int
+	depth  float64
+	name   string
+}{
+	{NewCircle(1), NewCircle(1), Transform{Pos: lmath.Vec2{X: 1.5}, Rot: RotIdentity}, true, lmath.Vec2{X: 1}, 1, 0.5, "circles"},
+	{NewCircle(1), NewCircle(1), Transform{Pos: lmath.Vec2{X: 2.5}, Rot: RotIdentity}, false, lmath.Vec2{}, 0, 0, "separate circles"},
+	{NewBox(1, 1), NewCircle(0.5), Transform{Pos: lmath.Vec2{Y: 1.25}, Rot: RotIdentity}, true, lmath.Vec2{Y: 1}, 1, 0.25, "box and circle"},
+	{NewCircle(0.5), NewBox(1, 1), Transform{Pos: lmath.Vec2{Y: 1.25}, Rot: RotIdentity}, true, lmath.Vec2{Y: 1}, 1, 0.25, "circle and box"},
+	{NewBox(1, 1), NewCircle(0.5), Transform{Pos: lmath.Vec2{X: 1.2, Y: 1.2}, Rot: RotIdentity}, true, lmath.Vec2{X: math.Sqrt2 / 2, Y: math.Sqrt2 / 2}, 1, 0.5 - 0.2*math.Sqrt2, "box corner and circle"},
+	{NewBox(1, 1), NewBox(1, 1), Transform{Pos: lmath.Vec2{X: 0.5, Y: 1.9}, Rot: RotIdentity}, true, lmath.Vec2{Y: 1}, 2, 0.1, "stacked boxes"},
+	{NewBox(1, 1), NewBox(1, 1), Transform{Pos: lmath.Vec2{X: -1.9}, Rot: RotIdentity}, true, lmath.Vec2{X: -1}, 2, 0.1, "boxes side by side"},
+	{NewBox(1, 1), NewBox(1, 1), Transform{Pos: lmath.Vec2{Y: 2.1}, Rot: RotIdentity}, false, lmath.Vec2{}, 0, 0, "separate boxes"},
+	{NewBox(1, 1), NewBox(1, 1), Transform{Pos: lmath.Vec2{Y: 1 + math.Sqrt2 - 0.1}, Rot: NewRot(math.Pi / 4)}, true, lmath.Vec2{Y: 1}, 1, 0.1, "box on it's corner"},
+}
+
+func TestCollide(t *testing.T) {
+	for _, tst := range collideTests {
+		m, ok := collide(tst.a, TransformIdentity, tst.b, tst.xfB)
+		if ok != tst.ok {
+			t.Errorf("%s: touching = %v, want %v", tst.name, ok, tst.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if !m.Normal.AlmostEquals(tst.normal, 1e-9) {
+			t.Errorf("%s: normal = %v, want %v", tst.name, m.Normal, tst.normal)
+		}
+		if m.Count != tst.count {
+			t.Errorf("%s: %d points, want %d", tst.name, m.Count, tst.count)
+		}
+		for i := 0; i < m.Count; i++ {
+			if d := m.Points[i].Depth; !lmath.AlmostEqual(d, tst.depth, 1e-9) {
+				t.Errorf("%s: point %d depth = %v, want %v", tst.name, i, d, tst.depth)
+			}
+		}
+	}
+}
+
+func TestRayCast(t *testing.T) {
+	w := NewWorld(lmath.Vec2{})
+	box := w.AddBody(Static)
+	box.SetTransform(lmath.Vec2{X: 5}, 0)
+	box.AddFixture(NewBox(1, 1), 0)
+	ball := w.AddBody(Static)
+	ball.SetTransform(lmath.Vec2{X: 10}, 0)
+	ball.AddFixture(NewCircle(1), 0)
+
+	hit, ok := w.RayCast(lmath.Vec2{}, lmath.Vec2{X: 20})
+	if !ok || hit.Fixture.Body() != box {
+		t.Fatalf("RayCast hit = %+v, %v; want the box", hit, ok)
+	}
+	if !hit.Point.AlmostEquals(lmath.Vec2{X: 4}, 1e-9) || !hit.Normal.AlmostEquals(lmath.Vec2{X: -1}, 1e-9) {
+		t.Errorf("RayCast hit point %v normal %v", hit.Point, hit.Normal)
+	}
+
+	hit, ok = w.RayCast(lmath.Vec2{X: 20}, lmath.Vec2{})
+	if !ok || hit.Fixture.Body() != ball || !hit.Point.AlmostEquals(lmath.Vec2{X: 11}, 1e-9) {
+		t.Fatalf("RayCast hit = %+v, %v; want the ball at X=11", hit, ok)
+	}
+
+	if _, ok := w.RayCast(lmath.Vec2{Y: 5}, lmath.Vec2{X: 20, Y: 5}); ok {
+		t.Fatal("RayCast hit, want a miss")
+	}
+
+	var found []*Body
+	w.QueryPoint(lmath.Vec2{X: 10.5}, func(f *Fixture) bool {
+		found = append(found, f.Body())
+		return true
+	})
+	if len(found) != 1 || found[0] != ball {
+		t.Fatalf("QueryPoint found %v, want the ball", found)
+	}
+}
+
+// newGround returns a world with a static ground whose surface is at Y=0.
+func newGround() *World {
+	w := NewWorld(lmath.Vec2{Y: -10})
+	ground := w.AddBody(Static)
+	ground.SetTransform(lmath.Vec2{Y: -1}, 0)
+	ground.AddFixture(NewBox(50, 1), 0)
+	return w
+}
+
+func TestStack(t *testing.T) {
+	w := newGround()
+	var boxes []*Body
+	for i := 0; i < 5; i++ {
+		b := w.AddBody(Dynamic)
+		b.SetTransform(lmath.Vec2{Y: 0.5 + float64(i)*1.05}, 0)
+		b.AddFixture(NewBox(0.5, 0.5), 1)
+		boxes = append(boxes, b)
+	}
+	for i := 0; i < 5*60; i++ {
+		w.Step(dt)
+	}
+	for i, b := range boxes {
+		want := lmath.Vec2{Y: 0.5 + float64(i)}
+		if !b.Position().AlmostEquals(want, 0.05) {
+			t.Errorf("box %d at %v, want near %v", i, b.Position(), want)
+		}
+		if v := b.LinearVelocity.Length(); v > 0.05 {
+			t.Errorf("box %d still moving at %v", i, v)
+		}
+		if a := math.Abs(b.Angle()); a > 0.01 {
+			t.Errorf("box %d rotated by %v", i, a)
+		}
+	}
+}
+
+func TestRolling(t *testing.T) {
+	w := newGround()
+	ball := w.AddBody(Dynamic)
+	ball.SetTransform(lmath.Vec2{Y: 0.5}, 0)
+	ball.AddFixture(NewCircle(0.5), 1).Friction = 1
+	ball.LinearVelocity = lmath.Vec2{X: 2}
+	for i := 0; i < 60; i++ {
+		w.Step(dt)
+	}
+
+	// Friction makes the ball roll without slipping, clockwise.
+	if ball.AngularVelocity >= 0 {
+		t.Fatalf("angular velocity = %v, want negative", ball.AngularVelocity)
+	}
+	if v := ball.VelocityAt(ball.WorldCenter().Sub(lmath.Vec2{Y: 0.5})); v.Length() > 0.05 {
+		t.Fatalf("contact point velocity = %v, want zero", v)
+	}
+}
+
+func TestRestitution(t *testing.T) {
+	w := newGround()
+	ball := w.AddBody(Dynamic)
+	ball.SetTransform(lmath.Vec2{Y: 5}, 0)
+	ball.AddFixture(NewCircle(0.5), 1).Restitution = 1
+
+	// The ball falls, bounces, and must rise close to it's initial height.
+	maxHeight, bounced := 0.0, false
+	for i := 0; i < 3*60; i++ {
+		w.Step(dt)
+		if ball.LinearVelocity.Y > 0 {
+			bounced = true
+		}
+		if bounced {
+			maxHeight = math.Max(maxHeight, ball.Position().Y)
+		}
+	}
+	if !bounced || maxHeight < 4.5 {
+		t.Fatalf("bounced to %v, want near 5", maxHeight)
+	}
+}
+
+func TestContactEvents(t *testing.T) {
+	w := NewWorld(lmath.Vec2{})
+	sensor := w.AddBody(Static)
+	sensor.AddFixture(NewBox(1, 1), 0).Sensor = true
+
+	ball := w.AddBody(Dynamic)
+	ball.SetTransform(lmath.Vec2{X: -3}, 0)
+	ball.AddFixture(NewCircle(0.5), 1)
+	ball.LinearVelocity = lmath.Vec2{X: 6}
+
+	var begin, end int
+	w.BeginContact = func(c *Contact) { begin++ }
+	w.EndContact = func(c *Contact) { end++ }
+	for i := 0; i < 60; i++ {
+		w.Step(dt)
+	}
+	if begin != 1 || end != 1 {
+		t.Fatalf("got %d begin and %d end contacts, want one each", begin, end)
+	}
+
+	// The sensor must not have stopped the ball.
+	if !ball.LinearVelocity.AlmostEquals(lmath.Vec2{X: 6}, 1e-9) {
+		t.Fatalf("ball velocity = %v, want unchanged", ball.LinearVelocity)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	w := newGround()
+	ball := w.AddBody(Dynamic)
+	ball.SetTransform(lmath.Vec2{Y: 1}, 0)
+	ball.AddFixture(NewCircle(0.5), 1).Filter.Mask = 0xfffe
+	for i := 0; i < 60; i++ {
+		w.Step(dt)
+	}
+	if ball.Position().Y > -1 {
+		t.Fatalf("filtered ball at %v, want it to fall through the ground", ball.Position())
+	}
+
+	a := Filter{Category: 1, Mask: 0xffff, Group: -1}
+	if a.ShouldCollide(a) {
+		t.Fatal("negative group collides")
+	}
+	a.Group = 2
+	a.Mask = 0
+	if !a.ShouldCollide(a) {
+		t.Fatal("positive group does not collide")
+	}
+}
+
+func TestDistanceJoint(t *testing.T) {
+	w := NewWorld(lmath.Vec2{Y: -10})
+	pivot := w.AddBody(Static)
+	bob := w.AddBody(Dynamic)
+	bob.SetTransform(lmath.Vec2{X: 2}, 0)
+	bob.AddFixture(NewCircle(0.25), 1)
+	w.AddJoint(NewDistanceJoint(pivot, bob, lmath.Vec2{}, lmath.Vec2{X: 2}))
+
+	for i := 0; i < 2*60; i++ {
+		w.Step(dt)
+		if l := bob.Position().Length(); math.Abs(l-2) > 0.05 {
+			t.Fatalf("step %d: pendulum length = %v, want 2", i, l)
+		}
+	}
+}
+
+func TestRevoluteJoint(t *testing.T) {
+	w := NewWorld(lmath.Vec2{})
+	axle := w.AddBody(Static)
+	axle.SetTransform(lmath.Vec2{X: 1, Y: 1}, 0)
+	wheel := w.AddBody(Dynamic)
+	wheel.SetTransform(lmath.Vec2{X: 1, Y: 1}, 0)
+	wheel.AddFixture(NewBox(1, 0.1), 1)
+
+	j := NewRevoluteJoint(axle, wheel, lmath.Vec2{X: 1, Y: 1})
+	j.EnableMotor = true
+	j.MotorSpeed = math.Pi
+	j.MaxMotorTorque = 100
+	w.AddJoint(j)
+
+	w.Update(time.Second / 60)
+	for i := 0; i < 59; i++ {
+		w.Step(dt)
+	}
+	if !wheel.Position().AlmostEquals(lmath.Vec2{X: 1, Y: 1}, 1e-3) {
+		t.Errorf("wheel moved to %v", wheel.Position())
+	}
+	if !lmath.AlmostEqual(wheel.AngularVelocity, math.Pi, 1e-3) {
+		t.Errorf("wheel angular velocity = %v, want Pi", wheel.AngularVelocity)
+	}
+	if !lmath.AlmostEqual(wheel.Angle(), math.Pi, 0.1) {
+		t.Errorf("wheel angle = %v, want near Pi", wheel.Angle())
+	}
+}
+
+func TestInterpolated(t *testing.T) {
+	w := NewWorld(lmath.Vec2{})
+	b := w.AddBody(Kinematic)
+	b.LinearVelocity = lmath.Vec2{X: 60}
+	w.Step(dt)
+	pos, _ := b.Interpolated(0.5)
+	if !pos.AlmostEquals(lmath.Vec2{X: 0.5}, 1e-9) {
+		t.Fatalf("Interpolated(0.5) = %v, want (0.5, 0)", pos)
+	}
+}
+
+func TestRemoveBody(t *testing.T) {
+	w := newGround()
+	var ends int
+	w.EndContact = func(c *Contact) { ends++ }
+	box := w.AddBody(Dynamic)
+	box.SetTransform(lmath.Vec2{Y: 0.5}, 0)
+	box.AddFixture(NewBox(0.5, 0.5), 1)
+	anchor := w.AddBody(Static)
+	anchor.SetTransform(lmath.Vec2{Y: 5}, 0)
+	w.AddJoint(NewDistanceJoint(anchor, box, lmath.Vec2{Y: 5}, lmath.Vec2{Y: 0.5}))
+	w.Step(dt)
+	if len(w.Contacts()) != 1 {
+		t.Fatalf("got %d contacts, want 1", len(w.Contacts()))
+	}
+	w.RemoveBody(box)
+	if len(w.Contacts()) != 0 || len(w.Joints()) != 0 || ends != 1 || len(w.proxies) != 1 {
+		t.Fatalf("contacts, joints or proxies remain after RemoveBody")
+	}
+}
+
+func TestDebugDraw(t *testing.T) {
+	w := newGround()
+	ball := w.AddBody(Dynamic)
+	ball.SetTransform(lmath.Vec2{Y: 0.5}, 0)
+	ball.AddFixture(NewCircle(0.5), 1)
+	w.Step(dt)
+
+	dd := debugdraw.New()
+	w.DebugDraw(dd)
+	want := 4 + debugdraw.SphereSegments + 1 + len(w.Contacts())
+	if got := dd.Len(); got != want {
+		t.Fatalf("got %d lines, want %d", got, want)
+	}
+}
+
+func BenchmarkStep(b *testing.B) {
+	w := newGround()
+	for i := 0; i < 100; i++ {
+		body := w.AddBody(Dynamic)
+		body.SetTransform(lmath.Vec2{X: float64(i%10) - 5, Y: 0.5 + float64(i/10)}, 0)
+		body.AddFixture(NewBox(0.5, 0.5), 1)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w.Step(dt)
+	}
+}
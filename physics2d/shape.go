@@ -0,0 +1,294 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package physics2d
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"azul3d.org/engine/lmath"
+)
+
+// MassData describes the mass properties of a shape or body.
+type MassData struct {
+	// The mass in kilograms.
+	Mass float64
+
+	// The center of mass, in local space.
+	Center lmath.Vec2
+
+	// The rotational inertia about the center of mass.
+	Inertia float64
+}
+
+// Shape is a convex collision shape, in the local space of the body it is
+// attached to. Shapes are immutable once attached to a body via a fixture,
+// as the simulation caches information derived from them.
+type Shape interface {
+	// MassData returns the mass properties of the shape with the given
+	// density in kilograms per square meter.
+	MassData(density float64) MassData
+
+	// Bounds returns the bounding box of the shape transformed by xf.
+	Bounds(xf Transform) AABB
+
+	// TestPoint tells if the point p (in world space) is inside the shape
+	// transformed by xf.
+	TestPoint(xf Transform, p lmath.Vec2) bool
+
+	// RayCast casts a ray from the point a to the point b (in world space)
+	// against the shape transformed by xf. If the ray hits the shape, the
+	// fraction along the ray in the range of [0, 1] and the surface normal at
+	// the hit point are returned.
+	RayCast(xf Transform, a, b lmath.Vec2) (fraction float64, normal lmath.Vec2, ok bool)
+}
+
+// Circle is a solid circle shape.
+type Circle struct {
+	// The center of the circle, in local space.
+	Center lmath.Vec2
+
+	// The radius of the circle.
+	Radius float64
+}
+
+// NewCircle returns a new circle with the given radius centered at the local
+// origin.
+func NewCircle(radius float64) *Circle {
+	return &Circle{Radius: radius}
+}
+
+// String returns an string representation of this circle.
+func (c *Circle) String() string {
+	return fmt.Sprintf("Circle(Center=%v, Radius=%v)", c.Center, c.Radius)
+}
+
+// MassData implements the Shape interface.
+func (c *Circle) MassData(density float64) MassData {
+	mass := density * math.Pi * c.Radius * c.Radius
+	return MassData{
+		Mass:    mass,
+		Center:  c.Center,
+		Inertia: 0.5 * mass * c.Radius * c.Radius,
+	}
+}
+
+// Bounds implements the Shape interface.
+func (c *Circle) Bounds(xf Transform) AABB {
+	p := xf.Apply(c.Center)
+	return AABB{Min: p.SubScalar(c.Radius), Max: p.AddScalar(c.Radius)}
+}
+
+// TestPoint implements the Shape interface.
+func (c *Circle) TestPoint(xf Transform, p lmath.Vec2) bool {
+	return p.Sub(xf.Apply(c.Center)).LengthSq() <= c.Radius*c.Radius
+}
+
+// RayCast implements the Shape interface.
+func (c *Circle) RayCast(xf Transform, a, b lmath.Vec2) (fraction float64, normal lmath.Vec2, ok bool) {
+	// Solve |s + t*d|^2 = r^2 for the smallest t in [0, 1].
+	s := a.Sub(xf.Apply(c.Center))
+	d := b.Sub(a)
+	rr := d.LengthSq()
+	if rr < lmath.EPSILON {
+		return 0, normal, false
+	}
+	cc := s.Dot(d)
+	sigma := cc*cc - rr*(s.LengthSq()-c.Radius*c.Radius)
+	if sigma < 0 {
+		return 0, normal, false
+	}
+	t := -(cc + math.Sqrt(sigma))
+	if t < 0 || t > rr {
+		return 0, normal, false
+	}
+	t /= rr
+	normal, _ = s.Add(d.MulScalar(t)).Normalized()
+	return t, normal, true
+}
+
+// Polygon is a solid convex polygon shape.
+type Polygon struct {
+	// The vertices of the polygon in counter-clockwise order, in local
+	// space.
+	Vertices []lmath.Vec2
+
+	// The outward facing unit normal of each edge, where edge i goes from
+	// vertex i to vertex i+1.
+	Normals []lmath.Vec2
+}
+
+// NewPolygon returns a new polygon that is the convex hull of the given
+// points (in any order). It panics if the hull is degenerate (i.e. it has
+// less than three vertices, or no area).
+func NewPolygon(points ...lmath.Vec2) *Polygon {
+	hull := convexHull(points)
+	if len(hull) < 3 {
+		panic("physics2d: degenerate polygon")
+	}
+	p := &Polygon{
+		Vertices: hull,
+		Normals:  make([]lmath.Vec2, len(hull)),
+	}
+	for i, v := range hull {
+		edge := hull[(i+1)%len(hull)].Sub(v)
+		n, ok := crossVS(edge, 1).Normalized()
+		if !ok {
+			panic("physics2d: degenerate polygon")
+		}
+		p.Normals[i] = n
+	}
+	return p
+}
+
+// NewBox returns a new rectangular polygon with the given half width and
+// half height, centered at the local origin.
+func NewBox(halfWidth, halfHeight float64) *Polygon {
+	return NewPolygon(
+		lmath.Vec2{X: -halfWidth, Y: -halfHeight},
+		lmath.Vec2{X: halfWidth, Y: -halfHeight},
+		lmath.Vec2{X: halfWidth, Y: halfHeight},
+		lmath.Vec2{X: -halfWidth, Y: halfHeight},
+	)
+}
+
+// String returns an string representation of this polygon.
+func (p *Polygon) String() string {
+	return fmt.Sprintf("Polygon(Vertices=%v)", p.Vertices)
+}
+
+// MassData implements the Shape interface.
+func (p *Polygon) MassData(density float64) MassData {
+	// Sum the triangles of a fan about the first vertex, which keeps the
+	// numbers small for polygons far from the origin.
+	var (
+		area, inertia float64
+		center        lmath.Vec2
+		ref           = p.Vertices[0]
+	)
+	for i := 1; i+1 < len(p.Vertices); i++ {
+		e1 := p.Vertices[i].Sub(ref)
+		e2 := p.Vertices[i+1].Sub(ref)
+		d := cross(e1, e2)
+		triArea := 0.5 * d
+		area += triArea
+		center = center.Add(e1.Add(e2).MulScalar(triArea / 3))
+		intx2 := e1.X*e1.X + e2.X*e1.X + e2.X*e2.X
+		inty2 := e1.Y*e1.Y + e2.Y*e1.Y + e2.Y*e2.Y
+		inertia += (d / 12) * (intx2 + inty2)
+	}
+	center = center.DivScalar(area)
+	mass := density * area
+
+	// The inertia is about the reference vertex, shift it to the center of
+	// mass using the parallel axis theorem.
+	inertia = density*inertia - mass*center.Dot(center)
+	return MassData{
+		Mass:    mass,
+		Center:  center.Add(ref),
+		Inertia: inertia,
+	}
+}
+
+// Bounds implements the Shape interface.
+func (p *Polygon) Bounds(xf Transform) AABB {
+	v := xf.Apply(p.Vertices[0])
+	b := AABB{Min: v, Max: v}
+	for _, v := range p.Vertices[1:] {
+		v = xf.Apply(v)
+		b.Min = b.Min.Min(v)
+		b.Max = b.Max.Max(v)
+	}
+	return b
+}
+
+// TestPoint implements the Shape interface.
+func (p *Polygon) TestPoint(xf Transform, pt lmath.Vec2) bool {
+	local := xf.ApplyInv(pt)
+	for i, n := range p.Normals {
+		if n.Dot(local.Sub(p.Vertices[i])) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// RayCast implements the Shape interface.
+func (p *Polygon) RayCast(xf Transform, a, b lmath.Vec2) (fraction float64, normal lmath.Vec2, ok bool) {
+	// Clip the ray (in local space) against each edge's half-plane.
+	p1, p2 := xf.ApplyInv(a), xf.ApplyInv(b)
+	d := p2.Sub(p1)
+	lower, upper := 0.0, 1.0
+	index := -1
+	for i, n := range p.Normals {
+		num := n.Dot(p.Vertices[i].Sub(p1))
+		den := n.Dot(d)
+		if den == 0 {
+			if num < 0 {
+				// Parallel to and outside of this edge.
+				return 0, normal, false
+			}
+			continue
+		}
+		t := num / den
+		if den < 0 && t > lower {
+			// Entering the half-plane.
+			lower = t
+			index = i
+		} else if den > 0 && t < upper {
+			// Leaving the half-plane.
+			upper = t
+		}
+		if upper < lower {
+			return 0, normal, false
+		}
+	}
+	if index < 0 {
+		// The ray starts inside the polygon.
+		return 0, normal, false
+	}
+	return lower, xf.Rot.Apply(p.Normals[index]), true
+}
+
+// convexHull returns the convex hull of the given points in counter-clockwise
+// order, without collinear points, using Andrew's monotone chain algorithm.
+func convexHull(points []lmath.Vec2) []lmath.Vec2 {
+	if len(points) < 3 {
+		return nil
+	}
+	sorted := make([]lmath.Vec2, len(points))
+	copy(sorted, points)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].X != sorted[j].X {
+			return sorted[i].X < sorted[j].X
+		}
+		return sorted[i].Y < sorted[j].Y
+	})
+
+	// Build the lower and upper hulls, dropping points that do not make a
+	// strictly counter-clockwise turn.
+	hull := make([]lmath.Vec2, 0, 2*len(sorted))
+	turn := func(o, a, b lmath.Vec2) float64 {
+		return cross(a.Sub(o), b.Sub(o))
+	}
+	for _, p := range sorted {
+		for len(hull) >= 2 && turn(hull[len(hull)-2], hull[len(hull)-1], p) <= lmath.EPSILON {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	lower := len(hull) + 1
+	for i := len(sorted) - 2; i >= 0; i-- {
+		p := sorted[i]
+		for len(hull) >= lower && turn(hull[len(hull)-2], hull[len(hull)-1], p) <= lmath.EPSILON {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+
+	// The last point is the first one again.
+	return hull[:len(hull)-1]
+}
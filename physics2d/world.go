@@ -0,0 +1,428 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package physics2d
+
+import (
+	"math"
+	"time"
+
+	"azul3d.org/engine/lmath"
+)
+
+const (
+	// The penetration allowed between bodies, which keeps resting contacts
+	// from flickering on and off.
+	linearSlop = 0.005
+
+	// The fraction of the penetration between bodies (or the separation of
+	// joint anchors) that is corrected each step.
+	baumgarte = 0.2
+
+	// The relative velocity below which collisions are inelastic.
+	velocityThreshold = 1.0
+
+	// The maximum distance and angle a body may move in a single step.
+	maxTranslation = 2.0
+	maxRotation    = 0.5 * math.Pi
+)
+
+// step holds the time step, and it's inverse, of a single world step.
+type step struct {
+	dt, invDt float64
+}
+
+// pairKey identifies a pair of fixtures, by their IDs, in a consistent order.
+type pairKey struct {
+	a, b uint64
+}
+
+// World is a 2D physics simulation of bodies and joints. A world is not safe
+// for use concurrently from multiple goroutines.
+type World struct {
+	// The acceleration due to gravity, in meters per second squared.
+	Gravity lmath.Vec2
+
+	// The number of iterations of the velocity solver per step. More
+	// iterations are more accurate (e.g. for tall stacks of bodies) but
+	// slower.
+	VelocityIterations int
+
+	// BeginContact, if not nil, is called during Step when two fixtures begin
+	// touching, and EndContact when they stop touching (or either is
+	// removed). They must not add or remove bodies, fixtures, or joints.
+	BeginContact, EndContact func(c *Contact)
+
+	bodies   []*Body
+	joints   []Joint
+	proxies  []*Fixture
+	contacts []*Contact
+	pairs    map[pairKey]*Contact
+	nextID   uint64
+}
+
+// NewWorld returns a new world with the given gravity, and the default
+// properties:
+//
+//  VelocityIterations: 8
+//
+func NewWorld(gravity lmath.Vec2) *World {
+	return &World{
+		Gravity:            gravity,
+		VelocityIterations: 8,
+		pairs:              make(map[pairKey]*Contact),
+	}
+}
+
+// AddBody adds a new body of the given type at the origin to the world. The
+// body has the default properties:
+//
+//  GravityScale: 1
+//
+func (w *World) AddBody(typ BodyType) *Body {
+	b := &Body{
+		GravityScale: 1,
+		typ:          typ,
+		world:        w,
+		xf:           TransformIdentity,
+	}
+	w.bodies = append(w.bodies, b)
+	return b
+}
+
+// RemoveBody removes the given body from the world, along with it's
+// contacts and any joints connected to it.
+func (w *World) RemoveBody(b *Body) {
+	for i := 0; i < len(w.joints); {
+		ja, jb := w.joints[i].Bodies()
+		if ja == b || jb == b {
+			w.joints = append(w.joints[:i], w.joints[i+1:]...)
+			continue
+		}
+		i++
+	}
+	for _, f := range b.fixtures {
+		w.removeFixtureContacts(f)
+	}
+	for i, other := range w.bodies {
+		if other == b {
+			w.bodies = append(w.bodies[:i], w.bodies[i+1:]...)
+			break
+		}
+	}
+	b.world = nil
+}
+
+// Bodies returns the bodies in the world. The returned slice must not be
+// modified.
+func (w *World) Bodies() []*Body {
+	return w.bodies
+}
+
+// AddJoint adds the given joint to the world. Both of it's bodies must be in
+// the world.
+func (w *World) AddJoint(j Joint) {
+	w.joints = append(w.joints, j)
+}
+
+// RemoveJoint removes the given joint from the world.
+func (w *World) RemoveJoint(j Joint) {
+	for i, other := range w.joints {
+		if other == j {
+			w.joints = append(w.joints[:i], w.joints[i+1:]...)
+			return
+		}
+	}
+}
+
+// Joints returns the joints in the world. The returned slice must not be
+// modified.
+func (w *World) Joints() []Joint {
+	return w.joints
+}
+
+// Contacts returns the contacts between touching fixtures as of the last
+// step. The returned slice must not be modified.
+func (w *World) Contacts() []*Contact {
+	return w.contacts
+}
+
+// addProxy adds the fixture to the broadphase.
+func (w *World) addProxy(f *Fixture) {
+	w.nextID++
+	f.id = w.nextID
+	w.proxies = append(w.proxies, f)
+}
+
+// removeFixtureContacts removes the fixture from the broadphase, and
+// destroys it's contacts.
+func (w *World) removeFixtureContacts(f *Fixture) {
+	for i, p := range w.proxies {
+		if p == f {
+			w.proxies = append(w.proxies[:i], w.proxies[i+1:]...)
+			break
+		}
+	}
+	for i := 0; i < len(w.contacts); {
+		c := w.contacts[i]
+		if c.FixtureA == f || c.FixtureB == f {
+			w.destroyContact(i)
+			continue
+		}
+		i++
+	}
+}
+
+// destroyContact removes the contact at index i, calling EndContact.
+func (w *World) destroyContact(i int) {
+	c := w.contacts[i]
+	w.contacts = append(w.contacts[:i], w.contacts[i+1:]...)
+	delete(w.pairs, c.key)
+	if w.EndContact != nil {
+		w.EndContact(c)
+	}
+}
+
+// shouldCollide tells if the two fixtures should be tested for collision.
+func (w *World) shouldCollide(fa, fb *Fixture) bool {
+	a, b := fa.body, fb.body
+	if a == b || (a.typ != Dynamic && b.typ != Dynamic) {
+		return false
+	}
+	if !fa.Filter.ShouldCollide(fb.Filter) {
+		return false
+	}
+	for _, j := range w.joints {
+		if j.CollideConnected() {
+			continue
+		}
+		ja, jb := j.Bodies()
+		if (ja == a && jb == b) || (ja == b && jb == a) {
+			return false
+		}
+	}
+	return true
+}
+
+// updateContacts finds the pairs of fixtures whose bounds overlap using a
+// sort-and-sweep broadphase, generates their contact manifolds, and creates
+// or destroys contacts as fixtures begin or end touching.
+func (w *World) updateContacts() {
+	// Insertion sort the proxies along the X axis, which is near linear time
+	// as they are mostly sorted from the last step already.
+	for i := 1; i < len(w.proxies); i++ {
+		f := w.proxies[i]
+		j := i - 1
+		for ; j >= 0 && w.proxies[j].bounds.Min.X > f.bounds.Min.X; j-- {
+			w.proxies[j+1] = w.proxies[j]
+		}
+		w.proxies[j+1] = f
+	}
+
+	for _, c := range w.contacts {
+		c.seen = false
+	}
+	for i, fa := range w.proxies {
+		for _, fb := range w.proxies[i+1:] {
+			if fb.bounds.Min.X > fa.bounds.Max.X {
+				break
+			}
+			if !fa.bounds.Overlaps(fb.bounds) || !w.shouldCollide(fa, fb) {
+				continue
+			}
+			w.collide(fa, fb)
+		}
+	}
+
+	// Destroy the contacts whose fixtures are no longer touching.
+	for i := 0; i < len(w.contacts); {
+		if !w.contacts[i].seen {
+			w.destroyContact(i)
+			continue
+		}
+		i++
+	}
+}
+
+// collide generates the contact manifold between the two fixtures, and
+// creates or updates their contact.
+func (w *World) collide(fa, fb *Fixture) {
+	// Order the pair by ID, such that the contact is found again regardless
+	// of the order of the proxies.
+	if fa.id > fb.id {
+		fa, fb = fb, fa
+	}
+	m, ok := collide(fa.Shape, fa.body.xf, fb.Shape, fb.body.xf)
+	if !ok {
+		return
+	}
+	key := pairKey{fa.id, fb.id}
+	c, exists := w.pairs[key]
+	if !exists {
+		c = &Contact{
+			FixtureA:    fa,
+			FixtureB:    fb,
+			Friction:    math.Sqrt(fa.Friction * fb.Friction),
+			Restitution: math.Max(fa.Restitution, fb.Restitution),
+			key:         key,
+		}
+	}
+	c.update(m)
+	c.seen = true
+	if !exists {
+		w.pairs[key] = c
+		w.contacts = append(w.contacts, c)
+		if w.BeginContact != nil {
+			w.BeginContact(c)
+		}
+	}
+}
+
+// Step advances the simulation by dt seconds. For a stable and deterministic
+// simulation, dt should be the same each step (see Update).
+func (w *World) Step(dt float64) {
+	if dt <= 0 {
+		return
+	}
+	s := step{dt: dt, invDt: 1 / dt}
+
+	// TODO: put resting bodies to sleep, such that large worlds where most
+	// bodies are at rest are cheaper to simulate.
+
+	w.updateContacts()
+	for _, b := range w.bodies {
+		b.integrateVelocity(w.Gravity, dt)
+	}
+
+	// Solve the contacts and joints with sequential impulses.
+	solid := w.contacts[:0:0]
+	for _, c := range w.contacts {
+		if !c.Sensor() {
+			solid = append(solid, c)
+		}
+	}
+	for _, c := range solid {
+		c.initVelocity(s)
+	}
+	for _, j := range w.joints {
+		j.initVelocity(s)
+	}
+	for i := 0; i < w.VelocityIterations; i++ {
+		for _, j := range w.joints {
+			j.solveVelocity()
+		}
+		for _, c := range solid {
+			c.solveVelocity()
+		}
+	}
+
+	for _, b := range w.bodies {
+		b.integratePosition(dt)
+		if b.typ == Static {
+			continue
+		}
+		for _, f := range b.fixtures {
+			f.bounds = f.Shape.Bounds(b.xf)
+		}
+	}
+}
+
+// Update is short-hand for:
+//
+//  w.Step(step.Seconds())
+//
+// It matches the signature of clock.Loop's Update function, such that the
+// world can be stepped at a fixed rate:
+//
+//  loop.Update = world.Update
+//
+func (w *World) Update(step time.Duration) {
+	w.Step(step.Seconds())
+}
+
+// QueryAABB calls fn for each fixture whose bounds overlap the given box,
+// until fn returns false.
+func (w *World) QueryAABB(box AABB, fn func(f *Fixture) bool) {
+	for _, f := range w.proxies {
+		if f.bounds.Overlaps(box) && !fn(f) {
+			return
+		}
+	}
+}
+
+// QueryPoint calls fn for each fixture that contains the given point, until
+// fn returns false.
+func (w *World) QueryPoint(p lmath.Vec2, fn func(f *Fixture) bool) {
+	w.QueryAABB(AABB{Min: p, Max: p}, func(f *Fixture) bool {
+		if f.TestPoint(p) {
+			return fn(f)
+		}
+		return true
+	})
+}
+
+// RayHit describes the intersection of a ray with a fixture.
+type RayHit struct {
+	// The fixture that was hit.
+	Fixture *Fixture
+
+	// The hit point and the surface normal there, in world space.
+	Point, Normal lmath.Vec2
+
+	// The fraction along the ray of the hit point, in the range of [0, 1].
+	Fraction float64
+}
+
+// RayCast casts a ray from the point a to the point b, and returns the
+// closest fixture that it hits. Rays starting inside of a fixture do not hit
+// it.
+func (w *World) RayCast(a, b lmath.Vec2) (hit RayHit, ok bool) {
+	box := AABB{Min: a.Min(b), Max: a.Max(b)}
+	hit.Fraction = 1
+	for _, f := range w.proxies {
+		if !f.bounds.Overlaps(box) {
+			continue
+		}
+		fraction, normal, hitOk := f.Shape.RayCast(f.body.xf, a, b)
+		if !hitOk || fraction > hit.Fraction {
+			continue
+		}
+		hit = RayHit{
+			Fixture:  f,
+			Normal:   normal,
+			Fraction: fraction,
+		}
+		ok = true
+	}
+	if ok {
+		hit.Point = a.Add(b.Sub(a).MulScalar(hit.Fraction))
+	}
+	return
+}
+
+// invOrZero returns 1/x, or zero if x is zero (i.e. an infinite mass).
+func invOrZero(x float64) float64 {
+	if x == 0 {
+		return 0
+	}
+	return 1 / x
+}
+
+// relativeVelocity returns the velocity of the point at rB from the center
+// of mass of body b, relative to the point at rA from the center of mass of
+// body a.
+func relativeVelocity(a, b *Body, rA, rB lmath.Vec2) lmath.Vec2 {
+	vA := a.LinearVelocity.Add(crossSV(a.AngularVelocity, rA))
+	vB := b.LinearVelocity.Add(crossSV(b.AngularVelocity, rB))
+	return vB.Sub(vA)
+}
+
+// applyImpulse applies the impulse to body b at rB from it's center of mass,
+// and the opposite impulse to body a at rA.
+func applyImpulse(a, b *Body, rA, rB, impulse lmath.Vec2) {
+	a.LinearVelocity = a.LinearVelocity.Sub(impulse.MulScalar(a.invMass))
+	a.AngularVelocity -= a.invI * cross(rA, impulse)
+	b.LinearVelocity = b.LinearVelocity.Add(impulse.MulScalar(b.invMass))
+	b.AngularVelocity += b.invI * cross(rB, impulse)
+}
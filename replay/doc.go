@@ -0,0 +1,40 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package replay implements deterministic recording and replay of input.
+//
+// A Recorder writes the events of each frame (window, keyboard, mouse,
+// gamepad and touch events, or any registered type, see Register), the frame
+// time step and the seeds of random number generators to a file. A Player
+// reads the file back and posts the events of each frame to an event bus (see
+// the event package), such that a game which derives it's simulation only from
+// those inputs behaves identically, e.g. to reproduce bugs or as an automated
+// gameplay test.
+//
+// Recording:
+//
+//  rec, err := replay.NewRecorder(file, nil)
+//  if err != nil {
+//      // Handle error.
+//  }
+//  rand.Seed(rec.Seed("world"))
+//  rec.Subscribe(bus)
+//  for {
+//      bus.PostFrom(events)
+//      bus.Dispatch()
+//      update(dt)
+//      rec.EndFrame(dt)
+//  }
+//
+// Replaying, with the live input no longer posted to the bus:
+//
+//  p, err := replay.NewPlayer(file)
+//  if err != nil {
+//      // Handle error.
+//  }
+//  seed, _ := p.Seed("world")
+//  rand.Seed(seed)
+//  err = p.Run(bus, update)
+//
+package replay // import "azul3d.org/engine/replay"
@@ -0,0 +1,319 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package replay
+
+import (
+	"bufio"
+	"encoding/gob"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"azul3d.org/engine/event"
+	"azul3d.org/engine/gamepad"
+	"azul3d.org/engine/gfx/window"
+	"azul3d.org/engine/keyboard"
+	"azul3d.org/engine/mouse"
+	"azul3d.org/engine/touch"
+)
+
+// Version is the version of the replay file format written by this package.
+const Version = 1
+
+// magic identifies replay files.
+const magic = "AZ3DREPLAY"
+
+var (
+	// ErrFormat is returned when reading a file which is not a replay.
+	ErrFormat = errors.New("replay: not a replay file")
+
+	// ErrVersion is returned when reading a replay of an unsupported version.
+	ErrVersion = errors.New("replay: unsupported version")
+)
+
+// Register registers the type of the given event value, such that events of
+// it's type may be recorded. The types of the events of the window, keyboard,
+// mouse, gamepad and touch packages are registered already. See
+// encoding/gob.Register.
+//
+// Events are encoded with encoding/gob; an event type may implement
+// gob.GobEncoder and gob.GobDecoder using the compact encoding of the codec
+// package instead.
+func Register(ev interface{}) {
+	gob.Register(ev)
+}
+
+func init() {
+	for _, ev := range []interface{}{
+		window.Close{}, window.Damaged{}, window.CursorMoved{},
+		window.CursorEnter{}, window.CursorExit{}, window.Minimized{},
+		window.Restored{}, window.GainedFocus{}, window.LostFocus{},
+		window.Moved{}, window.Resized{}, window.FramebufferResized{},
+		window.ItemsDropped{}, window.ContentScaleChanged{},
+		keyboard.ButtonEvent{}, keyboard.Typed{}, keyboard.Composition{},
+		mouse.ButtonEvent{}, mouse.Scrolled{},
+		gamepad.Connected{}, gamepad.Disconnected{}, gamepad.ButtonEvent{},
+		gamepad.AxisMoved{},
+		touch.Event{},
+	} {
+		Register(ev)
+	}
+}
+
+// Header is the header of a replay file.
+type Header struct {
+	// The file format version.
+	Version int
+
+	// The time at which recording began.
+	Start time.Time
+
+	// Arbitrary metadata, e.g. the game version or level name.
+	Meta map[string]string
+}
+
+// entry kinds.
+const (
+	kindEvent = iota
+	kindSeed
+	kindFrame
+)
+
+// entry is a single entry of a replay file.
+type entry struct {
+	Kind uint8
+
+	// The event, for kindEvent.
+	Event interface{}
+
+	// The name and value of the seed, for kindSeed.
+	Name string
+	Seed int64
+
+	// The time step of the frame, for kindFrame.
+	DT time.Duration
+}
+
+// Recorder records the input of a game to a replay file. It's methods are
+// safe to call from multiple goroutines concurrently.
+type Recorder struct {
+	access sync.Mutex
+	w      *bufio.Writer
+	enc    *gob.Encoder
+	frames uint64
+	err    error
+}
+
+// write writes an entry, remembering the first error.
+func (r *Recorder) write(e *entry) error {
+	r.access.Lock()
+	defer r.access.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	r.err = r.enc.Encode(e)
+	if r.err == nil && e.Kind == kindFrame {
+		r.frames++
+		r.err = r.w.Flush()
+	}
+	return r.err
+}
+
+// Event records an event of the current frame.
+func (r *Recorder) Event(ev interface{}) error {
+	return r.write(&entry{Kind: kindEvent, Event: ev})
+}
+
+// Subscribe subscribes the recorder to all events published on the given
+// bus, such that they are recorded. Errors are reported by Err.
+func (r *Recorder) Subscribe(b *event.Bus) *event.Subscription {
+	return b.Subscribe(func(ev interface{}) {
+		r.Event(ev)
+	})
+}
+
+// RecordSeed records the given seed of a random number generator, by name.
+func (r *Recorder) RecordSeed(name string, seed int64) error {
+	return r.write(&entry{Kind: kindSeed, Name: name, Seed: seed})
+}
+
+// Seed returns a new seed derived from the current time, recording it by
+// name. Errors are reported by Err.
+func (r *Recorder) Seed(name string) int64 {
+	seed := time.Now().UnixNano()
+	r.RecordSeed(name, seed)
+	return seed
+}
+
+// EndFrame ends the current frame, recording it's time step, and flushes
+// the recorded data to the underlying writer.
+func (r *Recorder) EndFrame(dt time.Duration) error {
+	return r.write(&entry{Kind: kindFrame, DT: dt})
+}
+
+// Frames returns the number of frames recorded.
+func (r *Recorder) Frames() uint64 {
+	r.access.Lock()
+	defer r.access.Unlock()
+	return r.frames
+}
+
+// Err returns the first error encountered while recording, if any.
+func (r *Recorder) Err() error {
+	r.access.Lock()
+	defer r.access.Unlock()
+	return r.err
+}
+
+// NewRecorder writes the header of a replay file with the given metadata to
+// w, and returns a recorder which records to it. Events recorded before the
+// first call to EndFrame belong to the first frame.
+func NewRecorder(w io.Writer, meta map[string]string) (*Recorder, error) {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString(magic); err != nil {
+		return nil, err
+	}
+	r := &Recorder{
+		w:   bw,
+		enc: gob.NewEncoder(bw),
+	}
+	h := &Header{Version: Version, Start: time.Now(), Meta: meta}
+	if err := r.enc.Encode(h); err != nil {
+		return nil, err
+	}
+	if err := bw.Flush(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Player replays a replay file. It's methods are not safe to call from
+// multiple goroutines concurrently.
+type Player struct {
+	// The header of the replay file.
+	Header
+
+	dec    *gob.Decoder
+	next   *entry
+	seeds  map[string][]int64
+	frames uint64
+}
+
+// read reads the next entry into p.next, which is nil at the end of the
+// file.
+func (p *Player) read() error {
+	var e entry
+	if err := p.dec.Decode(&e); err != nil {
+		p.next = nil
+		if err == io.EOF {
+			return nil
+		}
+		return err
+	}
+	p.next = &e
+	return nil
+}
+
+// readSeeds queues the seeds which are next in the file.
+func (p *Player) readSeeds() error {
+	for p.next != nil && p.next.Kind == kindSeed {
+		p.seeds[p.next.Name] = append(p.seeds[p.next.Name], p.next.Seed)
+		if err := p.read(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Seed returns the next recorded seed with the given name, in the order they
+// were recorded, or false if there are none left. Seeds recorded before any
+// event of the first frame are available immediately, others once NextFrame
+// has read the frame they were recorded in.
+func (p *Player) Seed(name string) (int64, bool) {
+	seeds := p.seeds[name]
+	if len(seeds) == 0 {
+		return 0, false
+	}
+	p.seeds[name] = seeds[1:]
+	return seeds[0], true
+}
+
+// Frame returns the number of frames replayed so far.
+func (p *Player) Frame() uint64 {
+	return p.frames
+}
+
+// NextFrame reads the next frame of the replay, posting it's events to the
+// given bus (see event.Bus.Post) in the order they were recorded. It returns
+// the recorded time step of the frame, or io.EOF once all frames have been
+// replayed.
+func (p *Player) NextFrame(b *event.Bus) (time.Duration, error) {
+	if p.next == nil {
+		return 0, io.EOF
+	}
+	for p.next != nil {
+		e := p.next
+		if err := p.read(); err != nil {
+			return 0, err
+		}
+		switch e.Kind {
+		case kindEvent:
+			b.Post(e.Event)
+		case kindSeed:
+			p.seeds[e.Name] = append(p.seeds[e.Name], e.Seed)
+		case kindFrame:
+			p.frames++
+			return e.DT, nil
+		}
+	}
+	// A partial last frame, e.g. if the game crashed while recording.
+	p.frames++
+	return 0, nil
+}
+
+// Run replays all of the remaining frames: for each it posts the frame's
+// events to the bus, dispatches them (see event.Bus.Dispatch) and then calls
+// step with the recorded time step.
+func (p *Player) Run(b *event.Bus, step func(dt time.Duration)) error {
+	for {
+		dt, err := p.NextFrame(b)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		b.Dispatch()
+		step(dt)
+	}
+}
+
+// NewPlayer reads the header of the replay file from r and returns a player
+// which replays it.
+func NewPlayer(r io.Reader) (*Player, error) {
+	br := bufio.NewReader(r)
+	buf := make([]byte, len(magic))
+	if _, err := io.ReadFull(br, buf); err != nil || string(buf) != magic {
+		return nil, ErrFormat
+	}
+	p := &Player{
+		dec:   gob.NewDecoder(br),
+		seeds: make(map[string][]int64),
+	}
+	if err := p.dec.Decode(&p.Header); err != nil {
+		return nil, err
+	}
+	if p.Version != Version {
+		return nil, ErrVersion
+	}
+	if err := p.read(); err != nil {
+		return nil, err
+	}
+	if err := p.readSeeds(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
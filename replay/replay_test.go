@@ -0,0 +1,108 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package replay
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"azul3d.org/engine/event"
+	"azul3d.org/engine/gfx/window"
+	"azul3d.org/engine/keyboard"
+	"azul3d.org/engine/mouse"
+)
+
+// game is a tiny deterministic simulation driven by input events.
+type game struct {
+	x, y  float64
+	rng   *rand.Rand
+	trace []float64
+}
+
+func (g *game) subscribe(b *event.Bus) {
+	b.Subscribe(func(ev keyboard.ButtonEvent) {
+		if ev.Key == keyboard.D && ev.State == keyboard.Down {
+			g.x += 1
+		}
+	})
+	b.Subscribe(func(ev window.CursorMoved) {
+		g.y = ev.Y
+	})
+}
+
+func (g *game) step(dt time.Duration) {
+	g.x += dt.Seconds() * g.rng.Float64()
+	g.trace = append(g.trace, g.x, g.y)
+}
+
+func TestReplay(t *testing.T) {
+	var buf bytes.Buffer
+	rec, err := NewRecorder(&buf, map[string]string{"level": "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Record a game.
+	live := event.NewBus()
+	want := &game{rng: rand.New(rand.NewSource(rec.Seed("world")))}
+	want.subscribe(live)
+	rec.Subscribe(live)
+	now := time.Now()
+	inputs := [][]interface{}{
+		{keyboard.ButtonEvent{Key: keyboard.D, State: keyboard.Down, T: now}},
+		{},
+		{window.CursorMoved{X: 1, Y: 2, T: now}, mouse.ButtonEvent{Button: mouse.Left, State: mouse.Down, T: now}},
+	}
+	for i, evs := range inputs {
+		for _, ev := range evs {
+			live.Post(ev)
+		}
+		live.Dispatch()
+		dt := time.Duration(i+1) * 16 * time.Millisecond
+		want.step(dt)
+		if err := rec.EndFrame(dt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if rec.Frames() != 3 || rec.Err() != nil {
+		t.Fatalf("recorded %d frames, %v", rec.Frames(), rec.Err())
+	}
+
+	// Replay it.
+	p, err := NewPlayer(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Meta["level"] != "1" || p.Version != Version {
+		t.Fatalf("header %+v", p.Header)
+	}
+	seed, ok := p.Seed("world")
+	if !ok {
+		t.Fatal("no seed")
+	}
+	bus := event.NewBus()
+	got := &game{rng: rand.New(rand.NewSource(seed))}
+	got.subscribe(bus)
+	var clicks int
+	bus.Subscribe(func(mouse.ButtonEvent) { clicks++ })
+	if err := p.Run(bus, got.step); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.trace, want.trace) || clicks != 1 || p.Frame() != 3 {
+		t.Fatalf("replayed %v, want %v", got.trace, want.trace)
+	}
+	if _, err := p.NextFrame(bus); err != io.EOF {
+		t.Fatalf("got %v, want EOF", err)
+	}
+
+	if _, err := NewPlayer(strings.NewReader("not a replay")); err != ErrFormat {
+		t.Fatalf("got %v, want ErrFormat", err)
+	}
+}
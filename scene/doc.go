@@ -0,0 +1,34 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package scene implements a scene file format for levels and prefabs.
+//
+// A Scene is a hierarchy of nodes, each with a transform relative to it's
+// parent, and optionally a mesh and material, a light, a camera, a reflection
+// probe, or a prefab (another scene instanced as it's children). Meshes, shaders, textures and
+// prefabs are referenced by their asset path, such that scenes are data which
+// can be written by an editor and loaded by the game:
+//
+//  f, err := os.Open("levels/one.scene")
+//  if err != nil {
+//      // Handle error.
+//  }
+//  level, err := scene.Load(f)
+//  f.Close()
+//  if err != nil {
+//      // Handle error.
+//  }
+//
+//  assets.Register(".scene", scene.Loader{})
+//  inst, err := level.Instantiate(assets)
+//  if err != nil {
+//      // Handle error.
+//  }
+//  defer inst.Release()
+//  for _, o := range inst.Objects {
+//      canvas.Draw(canvas.Bounds(), o, cam)
+//  }
+//
+// Scene files are JSON (see Scene.Save).
+package scene // import "azul3d.org/engine/scene"
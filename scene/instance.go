@@ -0,0 +1,283 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scene
+
+import (
+	"fmt"
+	"image"
+
+	"azul3d.org/engine/asset"
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/camera"
+	"azul3d.org/engine/gfx/probe"
+	"azul3d.org/engine/lmath"
+)
+
+// Loader loads *Scene assets from scene files (see Load), such that prefabs
+// can be instanced. Register it with the asset manager used to instantiate
+// scenes:
+//
+//  assets.Register(".scene", scene.Loader{})
+//
+type Loader struct{}
+
+// Load implements the asset.Loader interface.
+func (Loader) Load(fs asset.FileSystem, p string) (interface{}, error) {
+	f, err := fs.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+// Swap implements the asset.Swapper interface. Existing instances of the
+// scene are not changed, new instances use the reloaded scene.
+func (Loader) Swap(old, new interface{}) {
+	*old.(*Scene) = *new.(*Scene)
+}
+
+// InstanceLight is a light of an instance.
+type InstanceLight struct {
+	// The light of the node.
+	*Light
+
+	// The object of the node, whose transform positions the light.
+	Object *gfx.Object
+}
+
+// Instance is a scene instantiated as graphics objects. Each node becomes an
+// object, parented to the object of it's parent node, such that it's
+// transform is relative to it.
+type Instance struct {
+	// The scene which was instantiated.
+	Scene *Scene
+
+	// Root is the transform which the root nodes are parented to, it can be
+	// used to move the whole instance.
+	Root *gfx.Transform
+
+	// The objects of the nodes with meshes, which are to be drawn.
+	Objects []*gfx.Object
+
+	// The cameras of the nodes with cameras. They must be updated with a
+	// viewing rectangle (see camera.Camera.Update) before use.
+	Cameras []*camera.Camera
+
+	// The lights of the nodes with lights.
+	Lights []InstanceLight
+
+	// The reflection probes of the nodes with probes, positioned in world
+	// space as the instance was when it was instantiated. Probes without a
+	// baked texture have a nil Texture, their cubemaps are to be captured by
+	// the game (see probe.Probe.Capture).
+	Probes []*probe.Probe
+
+	nodes   map[*Node]*gfx.Object
+	all     []*gfx.Object
+	cameras map[*gfx.Object]*camera.Camera
+	assets  []*asset.Asset
+}
+
+// Object returns the object of the given node of the instantiated scene, or
+// nil if the node is not part of it. Nodes of prefab scenes are not part of
+// the instantiated scene.
+func (i *Instance) Object(n *Node) *gfx.Object {
+	return i.nodes[n]
+}
+
+// Store stores the transforms of the objects back into the nodes of the
+// instantiated scene, e.g. after they were moved in an editor, such that the
+// scene can be saved.
+func (i *Instance) Store() {
+	for n, o := range i.nodes {
+		n.Transform = o.TRS()
+	}
+}
+
+// Release destroys the objects and cameras of the instance, and releases
+// it's assets. The instance must not be used afterwards.
+func (i *Instance) Release() {
+	for _, o := range i.all {
+		if c, ok := i.cameras[o]; ok {
+			c.Destroy()
+			continue
+		}
+		o.Destroy()
+	}
+	for _, a := range i.assets {
+		a.Release()
+	}
+	*i = Instance{}
+}
+
+// load loads the asset at the given path, holding a reference to it until
+// the instance is released.
+func (i *Instance) load(m *asset.Manager, p string) (interface{}, error) {
+	a, err := m.Load(p)
+	if err != nil {
+		return nil, err
+	}
+	i.assets = append(i.assets, a)
+	return a.Value(), nil
+}
+
+// probe returns the reflection probe of the given node, whose object is given.
+func (i *Instance) probe(m *asset.Manager, n *Node, o *gfx.Object) (*probe.Probe, error) {
+	// The world space bounds of the box's corners.
+	var box lmath.Rect3
+	for c, corner := range n.Probe.Box.Corners() {
+		p := o.ConvertPos(corner, gfx.LocalToWorld)
+		if c == 0 {
+			box = lmath.Rect3{Min: p, Max: p}
+		} else {
+			box = box.Union(lmath.Rect3{Min: p, Max: p})
+		}
+	}
+	p := probe.New(o.ConvertPos(lmath.Vec3Zero, gfx.LocalToWorld), box)
+	p.Fade = n.Probe.Fade
+	if n.Probe.Texture != "" {
+		v, err := i.load(m, n.Probe.Texture)
+		if err != nil {
+			return nil, err
+		}
+		tex, ok := v.(*gfx.Texture)
+		if !ok {
+			return nil, fmt.Errorf("scene: node %q: %s is not a texture", n.Name, n.Probe.Texture)
+		}
+		p.Texture = tex
+		p.Flipped = true
+	}
+	return p, nil
+}
+
+// instantiate instantiates the nodes as children of the given parent. The
+// prefabs slice holds the paths of the prefabs being instantiated, to detect
+// prefabs which contain themselves.
+func (i *Instance) instantiate(m *asset.Manager, nodes []*Node, parent gfx.Transformable, prefabs []string) error {
+	for _, n := range nodes {
+		var (
+			o *gfx.Object
+			c *camera.Camera
+		)
+		if n.Camera != nil {
+			c = camera.New(image.Rectangle{})
+			c.FOV = n.Camera.FOV
+			c.Near = n.Camera.Near
+			c.Far = n.Camera.Far
+			c.Ortho = n.Camera.Ortho
+			c.OrthoHeight = n.Camera.OrthoHeight
+			o = c.Object
+			i.cameras[o] = c
+			i.Cameras = append(i.Cameras, c)
+		} else {
+			o = gfx.NewObject()
+		}
+		i.all = append(i.all, o)
+		if prefabs == nil {
+			i.nodes[n] = o
+		}
+		o.Label = n.Name
+		o.SetTRS(n.Transform)
+		o.SetParent(parent)
+
+		if n.Mesh != "" {
+			v, err := i.load(m, n.Mesh)
+			if err != nil {
+				return err
+			}
+			mesh, ok := v.(*gfx.Mesh)
+			if !ok {
+				return fmt.Errorf("scene: node %q: %s is not a mesh", n.Name, n.Mesh)
+			}
+			o.Meshes = []*gfx.Mesh{mesh}
+			i.Objects = append(i.Objects, o)
+		}
+		if mat := n.Material; mat != nil {
+			if mat.Shader != "" {
+				v, err := i.load(m, mat.Shader)
+				if err != nil {
+					return err
+				}
+				shader, ok := v.(*gfx.Shader)
+				if !ok {
+					return fmt.Errorf("scene: node %q: %s is not a shader", n.Name, mat.Shader)
+				}
+				o.Shader = shader
+			}
+			for _, p := range mat.Textures {
+				v, err := i.load(m, p)
+				if err != nil {
+					return err
+				}
+				tex, ok := v.(*gfx.Texture)
+				if !ok {
+					return fmt.Errorf("scene: node %q: %s is not a texture", n.Name, p)
+				}
+				o.Textures = append(o.Textures, tex)
+			}
+			if mat.AlphaMode != gfx.NoAlpha || mat.DoubleSided {
+				o.State = gfx.NewState()
+				o.State.AlphaMode = mat.AlphaMode
+				if mat.DoubleSided {
+					o.State.FaceCulling = gfx.NoFaceCulling
+				}
+			}
+		}
+		if n.Light != nil {
+			i.Lights = append(i.Lights, InstanceLight{Light: n.Light, Object: o})
+		}
+		if n.Probe != nil {
+			p, err := i.probe(m, n, o)
+			if err != nil {
+				return err
+			}
+			i.Probes = append(i.Probes, p)
+		}
+
+		if n.Prefab != "" {
+			for _, p := range prefabs {
+				if p == n.Prefab {
+					return fmt.Errorf("scene: prefab %s contains itself", p)
+				}
+			}
+			v, err := i.load(m, n.Prefab)
+			if err != nil {
+				return err
+			}
+			prefab, ok := v.(*Scene)
+			if !ok {
+				return fmt.Errorf("scene: node %q: %s is not a scene", n.Name, n.Prefab)
+			}
+			if err := i.instantiate(m, prefab.Nodes, o.Transform, append(prefabs, n.Prefab)); err != nil {
+				return err
+			}
+		}
+		if err := i.instantiate(m, n.Children, o.Transform, prefabs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Instantiate instantiates the scene as graphics objects, loading the assets
+// (meshes, shaders, textures and prefabs) that it's nodes reference from the
+// given asset manager. Prefabs are loaded as *Scene assets (see Loader).
+//
+// Assets are shared by every instance, and held until the instance is
+// released (see Instance.Release).
+func (s *Scene) Instantiate(m *asset.Manager) (*Instance, error) {
+	i := &Instance{
+		Scene:   s,
+		Root:    gfx.NewTransform(),
+		nodes:   make(map[*Node]*gfx.Object),
+		cameras: make(map[*gfx.Object]*camera.Camera),
+	}
+	if err := i.instantiate(m, s.Nodes, i.Root, nil); err != nil {
+		i.Release()
+		return nil, err
+	}
+	return i, nil
+}
@@ -0,0 +1,299 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scene
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/lmath"
+)
+
+// Version is the version of the scene file format written by Save.
+const Version = 1
+
+// ErrVersion is returned by Load when the scene file is of a newer (or
+// otherwise unknown) version of the format.
+var ErrVersion = errors.New("scene: unsupported file version")
+
+// The JSON representation of the scene file format. Transforms and colors
+// are written as arrays, and omitted where they are the default.
+type (
+	fileJSON struct {
+		Version int         `json:"version"`
+		Nodes   []*nodeJSON `json:"nodes"`
+	}
+
+	nodeJSON struct {
+		Name       string            `json:"name,omitempty"`
+		Pos        *[3]float64       `json:"pos,omitempty"`
+		Rot        *[4]float64       `json:"rot,omitempty"`
+		Scale      *[3]float64       `json:"scale,omitempty"`
+		Mesh       string            `json:"mesh,omitempty"`
+		Material   *materialJSON     `json:"material,omitempty"`
+		Light      *lightJSON        `json:"light,omitempty"`
+		Camera     *cameraJSON       `json:"camera,omitempty"`
+		Probe      *probeJSON        `json:"probe,omitempty"`
+		Prefab     string            `json:"prefab,omitempty"`
+		Properties map[string]string `json:"properties,omitempty"`
+		Children   []*nodeJSON       `json:"children,omitempty"`
+	}
+
+	materialJSON struct {
+		Shader      string   `json:"shader,omitempty"`
+		Textures    []string `json:"textures,omitempty"`
+		AlphaMode   string   `json:"alphaMode,omitempty"`
+		DoubleSided bool     `json:"doubleSided,omitempty"`
+	}
+
+	lightJSON struct {
+		Type      LightType  `json:"type"`
+		Color     [3]float32 `json:"color"`
+		Intensity float64    `json:"intensity"`
+		Range     float64    `json:"range,omitempty"`
+		InnerCone float64    `json:"innerCone,omitempty"`
+		OuterCone float64    `json:"outerCone,omitempty"`
+	}
+
+	cameraJSON struct {
+		FOV         float64 `json:"fov,omitempty"`
+		Near        float64 `json:"near"`
+		Far         float64 `json:"far"`
+		Ortho       bool    `json:"ortho,omitempty"`
+		OrthoHeight float64 `json:"orthoHeight,omitempty"`
+	}
+
+	probeJSON struct {
+		Min     [3]float64 `json:"min"`
+		Max     [3]float64 `json:"max"`
+		Fade    float64    `json:"fade,omitempty"`
+		Texture string     `json:"texture,omitempty"`
+	}
+)
+
+var alphaModes = []gfx.AlphaMode{gfx.NoAlpha, gfx.AlphaBlend, gfx.BinaryAlpha, gfx.AlphaToCoverage}
+
+func encodeNode(n *Node) (*nodeJSON, error) {
+	j := &nodeJSON{
+		Name:       n.Name,
+		Mesh:       n.Mesh,
+		Prefab:     n.Prefab,
+		Properties: n.Properties,
+	}
+	t := n.Transform
+	if t.Pos != lmath.Vec3Zero {
+		j.Pos = &[3]float64{t.Pos.X, t.Pos.Y, t.Pos.Z}
+	}
+	if t.Rot != lmath.QuatIdentity {
+		j.Rot = &[4]float64{t.Rot.W, t.Rot.X, t.Rot.Y, t.Rot.Z}
+	}
+	if t.Scale != lmath.Vec3One {
+		j.Scale = &[3]float64{t.Scale.X, t.Scale.Y, t.Scale.Z}
+	}
+	if m := n.Material; m != nil {
+		j.Material = &materialJSON{
+			Shader:      m.Shader,
+			Textures:    m.Textures,
+			DoubleSided: m.DoubleSided,
+		}
+		if m.AlphaMode != gfx.NoAlpha {
+			if int(m.AlphaMode) >= len(alphaModes) {
+				return nil, fmt.Errorf("scene: node %q: invalid alpha mode %d", n.Name, m.AlphaMode)
+			}
+			j.Material.AlphaMode = m.AlphaMode.String()
+		}
+	}
+	if l := n.Light; l != nil {
+		j.Light = &lightJSON{
+			Type:      l.Type,
+			Color:     [3]float32{l.Color.R, l.Color.G, l.Color.B},
+			Intensity: l.Intensity,
+			Range:     l.Range,
+			InnerCone: l.InnerCone,
+			OuterCone: l.OuterCone,
+		}
+	}
+	if c := n.Camera; c != nil {
+		j.Camera = &cameraJSON{
+			FOV:         c.FOV,
+			Near:        c.Near,
+			Far:         c.Far,
+			Ortho:       c.Ortho,
+			OrthoHeight: c.OrthoHeight,
+		}
+	}
+	if p := n.Probe; p != nil {
+		j.Probe = &probeJSON{
+			Min:     [3]float64{p.Box.Min.X, p.Box.Min.Y, p.Box.Min.Z},
+			Max:     [3]float64{p.Box.Max.X, p.Box.Max.Y, p.Box.Max.Z},
+			Fade:    p.Fade,
+			Texture: p.Texture,
+		}
+	}
+	for _, child := range n.Children {
+		cj, err := encodeNode(child)
+		if err != nil {
+			return nil, err
+		}
+		j.Children = append(j.Children, cj)
+	}
+	return j, nil
+}
+
+func decodeNode(j *nodeJSON) (*Node, error) {
+	if j == nil {
+		return nil, errors.New("scene: null node")
+	}
+	n := NewNode(j.Name)
+	n.Mesh = j.Mesh
+	n.Prefab = j.Prefab
+	n.Properties = j.Properties
+	if p := j.Pos; p != nil {
+		n.Transform.Pos = lmath.Vec3{X: p[0], Y: p[1], Z: p[2]}
+	}
+	if r := j.Rot; r != nil {
+		q := lmath.Quat{W: r[0], X: r[1], Y: r[2], Z: r[3]}
+		if q.LengthSq() == 0 {
+			return nil, fmt.Errorf("scene: node %q: zero rotation quaternion", j.Name)
+		}
+		n.Transform.Rot = q.Normalized()
+	}
+	if s := j.Scale; s != nil {
+		n.Transform.Scale = lmath.Vec3{X: s[0], Y: s[1], Z: s[2]}
+	}
+	if m := j.Material; m != nil {
+		n.Material = &Material{
+			Shader:      m.Shader,
+			Textures:    m.Textures,
+			DoubleSided: m.DoubleSided,
+		}
+		if m.AlphaMode != "" {
+			found := false
+			for _, mode := range alphaModes {
+				if mode.String() == m.AlphaMode {
+					n.Material.AlphaMode, found = mode, true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("scene: node %q: unknown alpha mode %q", j.Name, m.AlphaMode)
+			}
+		}
+	}
+	if l := j.Light; l != nil {
+		n.Light = &Light{
+			Type:      l.Type,
+			Color:     gfx.Color{R: l.Color[0], G: l.Color[1], B: l.Color[2], A: 1},
+			Intensity: l.Intensity,
+			Range:     l.Range,
+			InnerCone: l.InnerCone,
+			OuterCone: l.OuterCone,
+		}
+	}
+	if c := j.Camera; c != nil {
+		n.Camera = &Camera{
+			FOV:         c.FOV,
+			Near:        c.Near,
+			Far:         c.Far,
+			Ortho:       c.Ortho,
+			OrthoHeight: c.OrthoHeight,
+		}
+	}
+	if p := j.Probe; p != nil {
+		n.Probe = &Probe{
+			Box: lmath.Rect3{
+				Min: lmath.Vec3{X: p.Min[0], Y: p.Min[1], Z: p.Min[2]},
+				Max: lmath.Vec3{X: p.Max[0], Y: p.Max[1], Z: p.Max[2]},
+			},
+			Fade:    p.Fade,
+			Texture: p.Texture,
+		}
+		if n.Probe.Box.Empty() {
+			return nil, fmt.Errorf("scene: node %q: empty probe box", j.Name)
+		}
+	}
+	for _, cj := range j.Children {
+		child, err := decodeNode(cj)
+		if err != nil {
+			return nil, err
+		}
+		n.Children = append(n.Children, child)
+	}
+	return n, nil
+}
+
+// Save writes the scene as JSON to the given writer. For example:
+//
+//  {
+//      "version": 1,
+//      "nodes": [
+//          {
+//              "name": "house",
+//              "pos": [10, 0, 0],
+//              "rot": [0.7071, 0, 0, 0.7071],
+//              "mesh": "meshes/house.obj",
+//              "material": {
+//                  "shader": "shaders/lit.glsl",
+//                  "textures": ["textures/house.png"]
+//              },
+//              "children": [
+//                  {"name": "door", "prefab": "prefabs/door.scene"}
+//              ]
+//          },
+//          {
+//              "name": "sun",
+//              "light": {"type": "directional", "color": [1, 1, 0.9], "intensity": 2}
+//          },
+//          {
+//              "name": "hall",
+//              "pos": [0, 0, 2],
+//              "probe": {"min": [-5, -5, -2], "max": [5, 5, 2], "fade": 1}
+//          }
+//      ]
+//  }
+//
+// Rotations are quaternions in W, X, Y, Z order. Positions, rotations and
+// scales equal to those of the identity transform are omitted.
+func (s *Scene) Save(w io.Writer) error {
+	f := &fileJSON{Version: Version, Nodes: []*nodeJSON{}}
+	for _, n := range s.Nodes {
+		j, err := encodeNode(n)
+		if err != nil {
+			return err
+		}
+		f.Nodes = append(f.Nodes, j)
+	}
+	data, err := json.MarshalIndent(f, "", "\t")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Load reads a scene as JSON (see Save) from the given reader. Rotations are
+// normalized, and omitted transform components are those of the identity
+// transform.
+func Load(r io.Reader) (*Scene, error) {
+	var f fileJSON
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, err
+	}
+	if f.Version < 1 || f.Version > Version {
+		return nil, ErrVersion
+	}
+	s := &Scene{}
+	for _, j := range f.Nodes {
+		n, err := decodeNode(j)
+		if err != nil {
+			return nil, err
+		}
+		s.Nodes = append(s.Nodes, n)
+	}
+	return s, nil
+}
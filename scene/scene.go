@@ -0,0 +1,219 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scene
+
+import (
+	"fmt"
+	"strings"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/lmath"
+)
+
+// Scene is a hierarchy of nodes, e.g. a level or a prefab.
+type Scene struct {
+	// The root nodes of the scene.
+	Nodes []*Node
+}
+
+// Find returns the node at the given slash-separated path of node names
+// (e.g. "house/door"), or nil if there is no such node. Where several
+// siblings have the same name, the first one is returned.
+func (s *Scene) Find(path string) *Node {
+	nodes := s.Nodes
+	var found *Node
+	for _, name := range strings.Split(strings.Trim(path, "/"), "/") {
+		found = nil
+		for _, n := range nodes {
+			if n.Name == name {
+				found = n
+				break
+			}
+		}
+		if found == nil {
+			return nil
+		}
+		nodes = found.Children
+	}
+	return found
+}
+
+// Walk calls fn for each node of the scene, parents before their children.
+// If fn returns false, the children of the node are skipped.
+func (s *Scene) Walk(fn func(n *Node) bool) {
+	var walk func(nodes []*Node)
+	walk = func(nodes []*Node) {
+		for _, n := range nodes {
+			if fn(n) {
+				walk(n.Children)
+			}
+		}
+	}
+	walk(s.Nodes)
+}
+
+// Node is a single node of a scene. It has a transform relative to it's
+// parent, and optionally a mesh to draw, a light, a camera, or a reflection
+// probe.
+type Node struct {
+	// The name of the node, used to find it (see Scene.Find).
+	Name string
+
+	// The transform of the node, relative to it's parent.
+	Transform lmath.Transform
+
+	// The asset path of the mesh of the node, or an empty string if the node
+	// has no mesh.
+	Mesh string
+
+	// The material used to draw the mesh of the node, if any.
+	Material *Material
+
+	// The light attached to the node, if any.
+	Light *Light
+
+	// The camera attached to the node, if any.
+	Camera *Camera
+
+	// The reflection probe attached to the node, if any.
+	Probe *Probe
+
+	// The asset path of a scene instanced as the children of this node (i.e.
+	// a prefab), or an empty string if there is none.
+	Prefab string
+
+	// Properties holds arbitrary data of the node, for use by the game (e.g.
+	// a spawn point's team, or the script of a trigger).
+	Properties map[string]string
+
+	// The child nodes, whose transforms are relative to this node.
+	Children []*Node
+}
+
+// NewNode returns a new node with the given name and an identity transform.
+func NewNode(name string) *Node {
+	return &Node{
+		Name:      name,
+		Transform: lmath.TransformIdentity,
+	}
+}
+
+// Material describes how the mesh of a node is drawn.
+type Material struct {
+	// The asset path of the shader (see asset.ShaderLoader), e.g.
+	// "shaders/lit.glsl".
+	Shader string
+
+	// The asset paths of the textures, in the order they are bound.
+	Textures []string
+
+	// The alpha transparency mode used to draw the mesh.
+	AlphaMode gfx.AlphaMode
+
+	// Whether or not both sides of the mesh's faces are drawn (i.e. no face
+	// culling occurs).
+	DoubleSided bool
+}
+
+// LightType is the type of a light.
+type LightType uint8
+
+const (
+	// PointLight emits light in all directions from it's position.
+	PointLight LightType = iota
+
+	// SpotLight emits light in a cone along it's forward (+Y) axis.
+	SpotLight
+
+	// DirectionalLight emits parallel light along it's forward (+Y) axis,
+	// e.g. the sun.
+	DirectionalLight
+)
+
+var lightTypeNames = [...]string{
+	PointLight:       "point",
+	SpotLight:        "spot",
+	DirectionalLight: "directional",
+}
+
+// String returns the name of the light type, e.g. "point".
+func (t LightType) String() string {
+	if int(t) < len(lightTypeNames) {
+		return lightTypeNames[t]
+	}
+	return fmt.Sprintf("LightType(%d)", t)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (t LightType) MarshalText() ([]byte, error) {
+	if int(t) >= len(lightTypeNames) {
+		return nil, fmt.Errorf("scene: invalid light type %d", t)
+	}
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (t *LightType) UnmarshalText(text []byte) error {
+	for i, name := range lightTypeNames {
+		if name == string(text) {
+			*t = LightType(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("scene: unknown light type %q", text)
+}
+
+// Light describes a light source. The engine does not light objects itself,
+// lights are data for the shaders of the game.
+type Light struct {
+	// The type of the light.
+	Type LightType
+
+	// The color of the light.
+	Color gfx.Color
+
+	// The intensity of the light, which scales it's color.
+	Intensity float64
+
+	// The distance at which the light of a point or spot light has faded out,
+	// or zero for no limit.
+	Range float64
+
+	// The angles, in radians, from the axis of a spot light's cone at which
+	// it's light begins to fade out (inner) and has faded out (outer).
+	InnerCone, OuterCone float64
+}
+
+// Camera describes the lens of a camera (see camera.Camera).
+type Camera struct {
+	// The Y axis field-of-view in degrees, unused by orthographic cameras.
+	FOV float64
+
+	// The near and far values of the camera's viewing frustum.
+	Near, Far float64
+
+	// Whether or not the camera is orthographic.
+	Ortho bool
+
+	// The height of the area viewed by an orthographic camera, in world
+	// units.
+	OrthoHeight float64
+}
+
+// Probe describes a reflection probe (see package probe), whose cubemap is
+// captured from the position of it's node.
+type Probe struct {
+	// The box that the probe influences and projects it's cubemap onto,
+	// relative to the node.
+	Box lmath.Rect3
+
+	// The distance from the sides of the box over which the influence of the
+	// probe fades in, or zero for none.
+	Fade float64
+
+	// The asset path of the baked cubemap texture of the probe, or an empty
+	// string if the cubemap is to be captured by the game.
+	Texture string
+}
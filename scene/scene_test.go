@@ -0,0 +1,188 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scene
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"azul3d.org/engine/asset"
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/lmath"
+)
+
+// testScene returns a scene using every feature of the format.
+func testScene() *Scene {
+	house := NewNode("house")
+	house.Transform.Pos = lmath.Vec3{X: 10, Y: 2, Z: 0}
+	house.Transform.Rot = lmath.QuatFromAxisAngle(lmath.Vec3{Z: 1}, lmath.Radians(90))
+	house.Transform.Scale = lmath.Vec3{X: 2, Y: 2, Z: 2}
+	house.Mesh = "quad.obj"
+	house.Material = &Material{
+		Shader:      "basic.glsl",
+		AlphaMode:   gfx.BinaryAlpha,
+		DoubleSided: true,
+	}
+	house.Properties = map[string]string{"team": "red"}
+
+	door := NewNode("door")
+	door.Prefab = "door.scene"
+	house.Children = []*Node{door}
+
+	sun := NewNode("sun")
+	sun.Light = &Light{
+		Type:      DirectionalLight,
+		Color:     gfx.Color{R: 1, G: 1, B: 0.5, A: 1},
+		Intensity: 2,
+	}
+	cam := NewNode("camera")
+	cam.Camera = &Camera{FOV: 75, Near: 0.1, Far: 100}
+	hall := NewNode("hall")
+	hall.Transform.Pos = lmath.Vec3{Z: 2}
+	hall.Probe = &Probe{
+		Box:  lmath.Rect3{Min: lmath.Vec3{X: -5, Y: -5, Z: -2}, Max: lmath.Vec3{X: 5, Y: 5, Z: 2}},
+		Fade: 1,
+	}
+	return &Scene{Nodes: []*Node{house, sun, cam, hall}}
+}
+
+func TestSaveLoad(t *testing.T) {
+	s := testScene()
+	var buf bytes.Buffer
+	if err := s.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"scale": [`) || strings.Count(buf.String(), `"rot"`) != 1 {
+		t.Fatalf("identity transforms were not omitted:\n%s", buf.String())
+	}
+	loaded, err := Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	house := loaded.Find("house")
+	if house == nil || !house.Transform.AlmostEquals(s.Nodes[0].Transform, 1e-12) {
+		t.Fatalf("house %+v", house)
+	}
+	house.Transform = s.Nodes[0].Transform
+	if !reflect.DeepEqual(loaded, s) {
+		t.Fatalf("loaded scene differs")
+	}
+	if loaded.Find("/house/door") != house.Children[0] || loaded.Find("house/window") != nil {
+		t.Fatal("Find failed")
+	}
+
+	var names []string
+	loaded.Walk(func(n *Node) bool {
+		names = append(names, n.Name)
+		return true
+	})
+	if !reflect.DeepEqual(names, []string{"house", "door", "sun", "camera", "hall"}) {
+		t.Fatalf("Walk visited %v", names)
+	}
+
+	for _, bad := range []string{
+		`{"version": 2, "nodes": []}`,
+		`{"nodes": []}`,
+		`{"version": 1, "nodes": [{"rot": [0, 0, 0, 0]}]}`,
+		`{"version": 1, "nodes": [{"material": {"alphaMode": "Foo"}}]}`,
+		`{"version": 1, "nodes": [{"light": {"type": "area"}}]}`,
+		`{"version": 1, "nodes": [null]}`,
+		`{"version": 1, "nodes": [{"probe": {"min": [1, 1, 1], "max": [0, 0, 0]}}]}`,
+	} {
+		if _, err := Load(strings.NewReader(bad)); err == nil {
+			t.Errorf("loaded %s", bad)
+		}
+	}
+}
+
+// writeFile writes the named file of the directory.
+func writeFile(t *testing.T, dir, name, data string) {
+	if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInstantiate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scene")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeFile(t, dir, "quad.obj", "v 0 0 0\nv 1 0 0\nv 1 1 0\nv 0 1 0\nf 1 2 3 4\n")
+	writeFile(t, dir, "basic.vert", "void main() {}")
+	writeFile(t, dir, "basic.frag", "void main() {}")
+	writeFile(t, dir, "door.scene", `{
+		"version": 1,
+		"nodes": [{"name": "panel", "pos": [0, 1, 0], "mesh": "quad.obj"}]
+	}`)
+
+	m := asset.NewManager(asset.Dir(dir))
+	defer m.Close()
+	m.Register(".scene", Loader{})
+
+	s := testScene()
+	inst, err := s.Instantiate(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inst.Objects) != 2 || len(inst.Cameras) != 1 || len(inst.Lights) != 1 {
+		t.Fatalf("%d objects, %d cameras and %d lights", len(inst.Objects), len(inst.Cameras), len(inst.Lights))
+	}
+	house := inst.Object(s.Nodes[0])
+	if house != inst.Objects[0] || house.Shader == nil || house.State.FaceCulling != gfx.NoFaceCulling {
+		t.Fatal("house object")
+	}
+	if inst.Cameras[0].Far != 100 || inst.Object(s.Nodes[2]) != inst.Cameras[0].Object {
+		t.Fatal("camera object")
+	}
+
+	// The probe's box is in world space.
+	if len(inst.Probes) != 1 {
+		t.Fatalf("%d probes", len(inst.Probes))
+	}
+	p := inst.Probes[0]
+	box := lmath.Rect3{Min: lmath.Vec3{X: -5, Y: -5}, Max: lmath.Vec3{X: 5, Y: 5, Z: 4}}
+	if p.Position != (lmath.Vec3{Z: 2}) || !p.Box.AlmostEquals(box, 1e-9) || p.Fade != 1 || p.Texture != nil {
+		t.Fatalf("probe %+v", p)
+	}
+
+	// The prefab's panel is placed relative to the door, within the house.
+	panel := inst.Objects[1]
+	if panel.Meshes[0] != house.Meshes[0] {
+		t.Fatal("meshes are not shared")
+	}
+	want := lmath.Vec3{X: 8, Y: 2, Z: 0}
+	if p := panel.ConvertPos(lmath.Vec3Zero, gfx.LocalToWorld); !p.AlmostEquals(want, 1e-9) {
+		t.Fatalf("panel at %v, want %v", p, want)
+	}
+
+	// Moved objects are stored back into the scene.
+	house.SetPos(lmath.Vec3{X: 1})
+	inst.Store()
+	if s.Nodes[0].Transform.Pos != (lmath.Vec3{X: 1}) {
+		t.Fatalf("stored %v", s.Nodes[0].Transform.Pos)
+	}
+	inst.Release()
+	if m.Len() != 0 {
+		t.Fatalf("%d assets left after Release", m.Len())
+	}
+
+	// Prefabs which contain themselves fail.
+	writeFile(t, dir, "loop.scene", `{"version": 1, "nodes": [{"prefab": "loop.scene"}]}`)
+	loop := NewNode("loop")
+	loop.Prefab = "loop.scene"
+	if _, err := (&Scene{Nodes: []*Node{loop}}).Instantiate(m); err == nil {
+		t.Fatal("instantiated a recursive prefab")
+	}
+	if m.Len() != 0 {
+		t.Fatalf("%d assets left after failure", m.Len())
+	}
+}
@@ -0,0 +1,614 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package script
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"azul3d.org/engine/native/lua"
+)
+
+// valueMeta is the name of the metatable of Go values.
+const valueMeta = "azul3d.script.value"
+
+var (
+	errorType     = reflect.TypeOf((*error)(nil)).Elem()
+	interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+	functionType  = reflect.TypeOf((*Function)(nil))
+)
+
+// Function is a Lua function referenced from Go.
+type Function struct {
+	vm  *VM
+	ref int
+}
+
+// Call calls the function with the given arguments, returning it's results.
+func (f *Function) Call(args ...interface{}) ([]interface{}, error) {
+	s := f.vm.State
+	top := s.Top()
+	s.PushRef(f.ref)
+	for _, a := range args {
+		f.vm.push(s, reflect.ValueOf(a))
+	}
+	if err := s.Call(len(args), lua.MultRet); err != nil {
+		return nil, err
+	}
+	results := make([]interface{}, s.Top()-top)
+	for i := range results {
+		v, err := f.vm.get(s, top+1+i, interfaceType)
+		if err != nil {
+			s.SetTop(top)
+			return nil, err
+		}
+		if v.IsValid() {
+			results[i] = v.Interface()
+		}
+	}
+	s.SetTop(top)
+	return results, nil
+}
+
+// Release releases the reference to the Lua function, it may not be called
+// afterwards.
+func (f *Function) Release() {
+	f.vm.Unref(f.ref)
+}
+
+// goValue returns the Go value held by the userdata at index i, or an invalid
+// value.
+func goValue(s *lua.State, i int) reflect.Value {
+	v, ok := s.ToGo(i)
+	if !ok {
+		return reflect.Value{}
+	}
+	return reflect.ValueOf(v)
+}
+
+// push pushes the Go value v. Numbers, strings and booleans are pushed as Lua
+// values, functions as Lua functions, and other values as userdata. Structs
+// and arrays are copied (and held by pointer), such that their fields and
+// pointer methods are accessible.
+func (vm *VM) push(s *lua.State, v reflect.Value) {
+	if !v.IsValid() {
+		s.PushNil()
+		return
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		s.PushBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s.PushInteger(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		s.PushInteger(int64(v.Uint()))
+	case reflect.Float32, reflect.Float64:
+		s.PushNumber(v.Float())
+	case reflect.String:
+		s.PushString(v.String())
+	case reflect.Interface:
+		if v.IsNil() {
+			s.PushNil()
+			return
+		}
+		vm.push(s, v.Elem())
+	case reflect.Func:
+		if v.IsNil() {
+			s.PushNil()
+			return
+		}
+		if f, ok := v.Interface().(lua.Func); ok {
+			s.PushFunc(f)
+			return
+		}
+		s.PushFunc(vm.wrap(v, 1))
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan:
+		if v.IsNil() {
+			s.PushNil()
+			return
+		}
+		if f, ok := v.Interface().(*Function); ok {
+			s.PushRef(f.ref)
+			return
+		}
+		s.PushGo(v.Interface(), valueMeta)
+	default:
+		// Structs and arrays.
+		p := reflect.New(v.Type())
+		p.Elem().Set(v)
+		s.PushGo(p.Interface(), valueMeta)
+	}
+}
+
+// get converts the Lua value at index i to the Go type t. Nil (and missing)
+// values convert to zero values.
+func (vm *VM) get(s *lua.State, i int, t reflect.Type) (reflect.Value, error) {
+	lt := s.Type(i)
+	if lt == lua.TypeNone || lt == lua.TypeNil {
+		return reflect.Zero(t), nil
+	}
+	if t == interfaceType {
+		return vm.getAny(s, i)
+	}
+
+	// Go values, held by userdata.
+	if gv := goValue(s, i); gv.IsValid() {
+		switch {
+		case gv.Type().AssignableTo(t):
+			return gv, nil
+		case gv.Kind() == reflect.Ptr && gv.Elem().Type().AssignableTo(t):
+			return gv.Elem(), nil
+		case gv.Type().ConvertibleTo(t) && gv.Kind() == t.Kind():
+			return gv.Convert(t), nil
+		}
+		return reflect.Value{}, fmt.Errorf("cannot use %v as %v", gv.Type(), t)
+	}
+
+	bad := func() (reflect.Value, error) {
+		return reflect.Value{}, fmt.Errorf("cannot use %v as %v", lt, t)
+	}
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Bool:
+		v.SetBool(s.ToBool(i))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := s.ToInteger(i)
+		if !ok {
+			f, ok := s.ToNumber(i)
+			if !ok {
+				return bad()
+			}
+			n = int64(f)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, ok := s.ToInteger(i)
+		if !ok || n < 0 {
+			return bad()
+		}
+		v.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		n, ok := s.ToNumber(i)
+		if !ok {
+			return bad()
+		}
+		v.SetFloat(n)
+	case reflect.String:
+		if lt != lua.TypeString && lt != lua.TypeNumber {
+			return bad()
+		}
+		// Convert a copy, as numbers are converted in place (which would
+		// confuse Next, when converting table keys).
+		s.PushValue(i)
+		str, _ := s.ToString(-1)
+		s.Pop(1)
+		v.SetString(str)
+	case reflect.Func:
+		if lt != lua.TypeFunction {
+			return bad()
+		}
+		return vm.makeFunc(s, i, t), nil
+	case reflect.Ptr:
+		if t == functionType {
+			if lt != lua.TypeFunction {
+				return bad()
+			}
+			s.PushValue(i)
+			return reflect.ValueOf(&Function{vm: vm, ref: s.Ref()}), nil
+		}
+		if lt != lua.TypeTable {
+			return bad()
+		}
+		e, err := vm.get(s, i, t.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v = reflect.New(t.Elem())
+		v.Elem().Set(e)
+	case reflect.Struct:
+		if lt != lua.TypeTable {
+			return bad()
+		}
+		i = s.AbsIndex(i)
+		for f := 0; f < t.NumField(); f++ {
+			sf := t.Field(f)
+			if sf.PkgPath != "" {
+				continue
+			}
+			s.GetField(i, sf.Name)
+			fv, err := vm.get(s, -1, sf.Type)
+			s.Pop(1)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("field %s: %v", sf.Name, err)
+			}
+			v.Field(f).Set(fv)
+		}
+	case reflect.Slice, reflect.Array:
+		if lt != lua.TypeTable {
+			return bad()
+		}
+		i = s.AbsIndex(i)
+		n := s.RawLen(i)
+		if t.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(t, n, n))
+		} else if n > t.Len() {
+			n = t.Len()
+		}
+		for e := 0; e < n; e++ {
+			s.GetIndex(i, int64(e+1))
+			ev, err := vm.get(s, -1, t.Elem())
+			s.Pop(1)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("element %d: %v", e+1, err)
+			}
+			v.Index(e).Set(ev)
+		}
+	case reflect.Map:
+		if lt != lua.TypeTable {
+			return bad()
+		}
+		i = s.AbsIndex(i)
+		v.Set(reflect.MakeMap(t))
+		s.PushNil()
+		for s.Next(i) {
+			k, err := vm.get(s, -2, t.Key())
+			if err != nil {
+				s.Pop(2)
+				return reflect.Value{}, err
+			}
+			e, err := vm.get(s, -1, t.Elem())
+			s.Pop(1)
+			if err != nil {
+				s.Pop(1)
+				return reflect.Value{}, err
+			}
+			v.SetMapIndex(k, e)
+		}
+	default:
+		return bad()
+	}
+	return v, nil
+}
+
+// getAny converts the Lua value at index i to a Go value of it's natural
+// type: bool, int64, float64, string, *Function, the Go value of userdata,
+// or a []interface{} or map[string]interface{} for tables (depending on
+// whether they are sequences).
+func (vm *VM) getAny(s *lua.State, i int) (reflect.Value, error) {
+	var v interface{}
+	switch s.Type(i) {
+	case lua.TypeBoolean:
+		v = s.ToBool(i)
+	case lua.TypeNumber:
+		if s.IsInteger(i) {
+			v, _ = s.ToInteger(i)
+		} else {
+			v, _ = s.ToNumber(i)
+		}
+	case lua.TypeString:
+		v, _ = s.ToString(i)
+	case lua.TypeFunction:
+		return vm.get(s, i, functionType)
+	case lua.TypeUserdata:
+		if gv := goValue(s, i); gv.IsValid() {
+			return gv, nil
+		}
+		return reflect.Value{}, errors.New("cannot use userdata as a Go value")
+	case lua.TypeTable:
+		t := reflect.TypeOf(map[string]interface{}(nil))
+		if s.RawLen(i) > 0 {
+			t = reflect.TypeOf([]interface{}(nil))
+		}
+		return vm.get(s, i, t)
+	default:
+		return reflect.Value{}, fmt.Errorf("cannot use %v as a Go value", s.Type(i))
+	}
+	return reflect.ValueOf(v), nil
+}
+
+// makeFunc returns a Go function of type t which calls the Lua function at
+// index i. If the last result of t is an error, Lua errors are returned
+// through it; otherwise they cause a panic.
+func (vm *VM) makeFunc(s *lua.State, i int, t reflect.Type) reflect.Value {
+	s.PushValue(i)
+	f := &Function{vm: vm, ref: s.Ref()}
+	return reflect.MakeFunc(t, func(in []reflect.Value) []reflect.Value {
+		args := make([]interface{}, len(in))
+		for i, a := range in {
+			args[i] = a.Interface()
+		}
+		out := make([]reflect.Value, t.NumOut())
+		for i := range out {
+			out[i] = reflect.Zero(t.Out(i))
+		}
+		withErr := t.NumOut() > 0 && t.Out(t.NumOut()-1) == errorType
+		fail := func(err error) []reflect.Value {
+			if !withErr {
+				panic(err)
+			}
+			out[len(out)-1] = reflect.ValueOf(&err).Elem()
+			return out
+		}
+
+		// Call the function, leaving it's results on the stack for
+		// conversion.
+		s := vm.State
+		top := s.Top()
+		s.PushRef(f.ref)
+		for _, a := range in {
+			vm.push(s, a)
+		}
+		if err := s.Call(len(in), lua.MultRet); err != nil {
+			return fail(err)
+		}
+		defer s.SetTop(top)
+		n := t.NumOut()
+		if withErr {
+			n--
+		}
+		for r := 0; r < n; r++ {
+			v, err := vm.get(s, top+1+r, t.Out(r))
+			if err != nil {
+				return fail(fmt.Errorf("result %d: %v", r+1, err))
+			}
+			out[r] = v
+		}
+		return out
+	})
+}
+
+// wrap returns a Lua function which calls the Go function fn with the Lua
+// arguments starting at index first. A non-nil error returned as the last
+// result of fn is raised as a Lua error.
+func (vm *VM) wrap(fn reflect.Value, first int) lua.Func {
+	t := fn.Type()
+	return func(s *lua.State) (int, error) {
+		n := t.NumIn()
+		if t.IsVariadic() {
+			n--
+		}
+		args := make([]reflect.Value, n)
+		for a := range args {
+			v, err := vm.get(s, first+a, t.In(a))
+			if err != nil {
+				return 0, fmt.Errorf("argument %d: %v", a+1, err)
+			}
+			args[a] = v
+		}
+		if t.IsVariadic() {
+			elem := t.In(n).Elem()
+			for a := first + n; a <= s.Top(); a++ {
+				v, err := vm.get(s, a, elem)
+				if err != nil {
+					return 0, fmt.Errorf("argument %d: %v", a-first+1, err)
+				}
+				args = append(args, v)
+			}
+		}
+
+		out := fn.Call(args)
+		if len(out) > 0 && t.Out(len(out)-1) == errorType {
+			if err := out[len(out)-1]; !err.IsNil() {
+				return 0, err.Interface().(error)
+			}
+			out = out[:len(out)-1]
+		}
+		for _, o := range out {
+			vm.push(s, o)
+		}
+		return len(out), nil
+	}
+}
+
+// member returns the exported method or field (via pointers) of v with the
+// given name.
+func member(v reflect.Value, name string) (method, field reflect.Value) {
+	if m := v.MethodByName(name); m.IsValid() {
+		return m, reflect.Value{}
+	}
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Struct {
+		if sf, ok := v.Type().FieldByName(name); ok && sf.PkgPath == "" {
+			return reflect.Value{}, v.FieldByIndex(sf.Index)
+		}
+	}
+	return
+}
+
+// elem dereferences pointers to the underlying value.
+func elem(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
+
+// pushElem pushes the field or element e: by pointer if it is an addressable
+// struct or array, such that assignments to it's fields modify it in place.
+func (vm *VM) pushElem(s *lua.State, e reflect.Value) {
+	if (e.Kind() == reflect.Struct || e.Kind() == reflect.Array) && e.CanAddr() {
+		s.PushGo(e.Addr().Interface(), valueMeta)
+		return
+	}
+	vm.push(s, e)
+}
+
+// index implements the __index metamethod of Go values.
+func (vm *VM) index(s *lua.State) (int, error) {
+	v := goValue(s, 1)
+	if !v.IsValid() {
+		return 0, errors.New("not a Go value")
+	}
+	if s.Type(2) == lua.TypeString {
+		name, _ := s.ToString(2)
+		method, field := member(v, name)
+		switch {
+		case method.IsValid():
+			// Called with the colon syntax, the receiver is the first
+			// argument.
+			s.PushFunc(func(s *lua.State) (int, error) {
+				recv := goValue(s, 1)
+				if !recv.IsValid() {
+					return 0, fmt.Errorf("method %s called without a receiver (use the colon syntax)", name)
+				}
+				m, _ := member(recv, name)
+				if !m.IsValid() {
+					return 0, fmt.Errorf("%v has no method %s", recv.Type(), name)
+				}
+				return vm.wrap(m, 2)(s)
+			})
+			return 1, nil
+		case field.IsValid():
+			vm.pushElem(s, field)
+			return 1, nil
+		}
+	}
+
+	e := elem(v)
+	switch e.Kind() {
+	case reflect.Map:
+		k, err := vm.get(s, 2, e.Type().Key())
+		if err != nil {
+			return 0, err
+		}
+		vm.push(s, e.MapIndex(k))
+		return 1, nil
+	case reflect.Slice, reflect.Array:
+		n, ok := s.ToInteger(2)
+		if ok && n >= 1 && int(n) <= e.Len() {
+			vm.pushElem(s, e.Index(int(n-1)))
+			return 1, nil
+		}
+	}
+	s.PushNil()
+	return 1, nil
+}
+
+// newIndex implements the __newindex metamethod of Go values.
+func (vm *VM) newIndex(s *lua.State) (int, error) {
+	v := goValue(s, 1)
+	if !v.IsValid() {
+		return 0, errors.New("not a Go value")
+	}
+	set := func(dst reflect.Value) (int, error) {
+		if !dst.CanSet() {
+			return 0, fmt.Errorf("cannot assign to %v", dst.Type())
+		}
+		val, err := vm.get(s, 3, dst.Type())
+		if err != nil {
+			return 0, err
+		}
+		dst.Set(val)
+		return 0, nil
+	}
+	if s.Type(2) == lua.TypeString {
+		name, _ := s.ToString(2)
+		if _, field := member(v, name); field.IsValid() {
+			return set(field)
+		}
+	}
+	e := elem(v)
+	switch e.Kind() {
+	case reflect.Map:
+		k, err := vm.get(s, 2, e.Type().Key())
+		if err != nil {
+			return 0, err
+		}
+		val, err := vm.get(s, 3, e.Type().Elem())
+		if err != nil {
+			return 0, err
+		}
+		e.SetMapIndex(k, val)
+		return 0, nil
+	case reflect.Slice, reflect.Array:
+		n, ok := s.ToInteger(2)
+		if ok && n >= 1 && int(n) <= e.Len() {
+			return set(e.Index(int(n - 1)))
+		}
+	}
+	key, _ := s.ToString(2)
+	return 0, fmt.Errorf("%v has no field %q", v.Type(), key)
+}
+
+// arith returns an implementation of an arithmetic metamethod, calling the
+// named method of the left operand (or the scalar method, if the right one is
+// a number).
+func (vm *VM) arith(name, scalar string) lua.Func {
+	return func(s *lua.State) (int, error) {
+		a, b := 1, 2
+		if !goValue(s, a).IsValid() && scalar == "MulScalar" {
+			// Multiplication commutes, e.g. 2 * v.
+			a, b = b, a
+		}
+		recv := goValue(s, a)
+		if !recv.IsValid() {
+			return 0, fmt.Errorf("cannot %s a %v", name, s.Type(a))
+		}
+		m := name
+		if s.Type(b) == lua.TypeNumber && scalar != "" {
+			m = scalar
+		}
+		method, _ := member(recv, m)
+		if !method.IsValid() {
+			return 0, fmt.Errorf("%v has no method %s", recv.Type(), m)
+		}
+		if method.Type().NumIn() != 1 || method.Type().NumOut() != 1 {
+			return 0, fmt.Errorf("%v.%s is not a binary operator", recv.Type(), m)
+		}
+		arg, err := vm.get(s, b, method.Type().In(0))
+		if err != nil {
+			return 0, err
+		}
+		vm.push(s, method.Call([]reflect.Value{arg})[0])
+		return 1, nil
+	}
+}
+
+// bindValues creates the metatable of Go values.
+func (vm *VM) bindValues() {
+	s := vm.State
+	s.NewGoMetatable(valueMeta)
+	metamethods := map[string]lua.Func{
+		"__index":    vm.index,
+		"__newindex": vm.newIndex,
+		"__add":      vm.arith("Add", ""),
+		"__sub":      vm.arith("Sub", ""),
+		"__mul":      vm.arith("Mul", "MulScalar"),
+		"__div":      vm.arith("Div", "DivScalar"),
+		"__len": func(s *lua.State) (int, error) {
+			e := elem(goValue(s, 1))
+			switch e.Kind() {
+			case reflect.Slice, reflect.Array, reflect.Map, reflect.String, reflect.Chan:
+				s.PushInteger(int64(e.Len()))
+				return 1, nil
+			}
+			return 0, fmt.Errorf("cannot take the length of %v", e.Type())
+		},
+		"__tostring": func(s *lua.State) (int, error) {
+			v := goValue(s, 1)
+			if _, ok := v.Interface().(fmt.Stringer); !ok {
+				v = elem(v)
+			}
+			s.PushString(fmt.Sprint(v.Interface()))
+			return 1, nil
+		},
+		"__eq": func(s *lua.State) (int, error) {
+			a, b := elem(goValue(s, 1)), elem(goValue(s, 2))
+			s.PushBool(a.IsValid() && b.IsValid() && reflect.DeepEqual(a.Interface(), b.Interface()))
+			return 1, nil
+		},
+	}
+	for name, f := range metamethods {
+		s.PushFunc(f)
+		s.SetField(-2, name)
+	}
+	s.Pop(1)
+}
@@ -0,0 +1,48 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package script implements Lua scripting of gameplay, with bindings for
+// engine APIs.
+//
+// A VM embeds a Lua state (see the native/lua package). Go values are exposed
+// to scripts by reflection: their exported fields, methods (called with the
+// colon syntax) and slice, array and map elements are accessible, and Lua
+// values are converted back to the Go types of function arguments, such that
+// most Go APIs may be bound without writing any glue code:
+//
+//  vm := script.New()
+//  defer vm.Close()
+//  vm.Keyboard = keyboardWatcher
+//  vm.Set("player", player)
+//  if err := vm.RunFile("scripts/player.lua"); err != nil {
+//      log.Fatal(err)
+//  }
+//  for {
+//      vm.Reload() // Picks up edited scripts.
+//      vm.Call("update", dt)
+//      ...
+//  }
+//
+// With player.lua being, for example:
+//
+//  function update(dt)
+//      local pos = player.Transform:Pos()
+//      if input.KeyDown("W") then
+//          pos = pos + lmath.Vec3(0, 5 * dt, 0)
+//      end
+//      player.Transform:SetPos(pos)
+//  end
+//
+// The following modules are bound as global tables:
+//
+//  lmath: Vec2, Vec3, Vec4 and Quat constructors, plus the functions of the
+//         lmath package (e.g. Radians, Lerp, QuatFromAxisAngle).
+//  scene: Load (a scene file) and NewNode.
+//  audio: Play and Stop, played by VM.Audio.
+//  input: KeyDown, KeyUp, MouseDown and MouseUp, by key and button names
+//         (e.g. "W", "Space", "Left"), reading VM.Keyboard and VM.Mouse.
+//
+// Arithmetic operators on Go values call their Add, Sub, Mul, Div, MulScalar
+// and DivScalar methods, such that vector math reads naturally.
+package script // import "azul3d.org/engine/script"
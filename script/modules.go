@@ -0,0 +1,144 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package script
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"azul3d.org/engine/keyboard"
+	"azul3d.org/engine/lmath"
+	"azul3d.org/engine/mouse"
+	"azul3d.org/engine/scene"
+)
+
+// ErrNoAudio is raised by the audio module when VM.Audio is nil.
+var ErrNoAudio = errors.New("script: no audio player")
+
+// keys and buttons map the names of keyboard keys and mouse buttons (as
+// returned by their String methods) to them.
+var (
+	keys    = make(map[string]keyboard.Key)
+	buttons = map[string]mouse.Button{
+		"Left":   mouse.Left,
+		"Right":  mouse.Right,
+		"Middle": mouse.Middle,
+		"Wheel":  mouse.Wheel,
+	}
+)
+
+func init() {
+	for k := keyboard.Invalid + 1; !strings.HasPrefix(k.String(), "Key("); k++ {
+		keys[k.String()] = k
+	}
+	for b := mouse.One; b <= mouse.Eight; b++ {
+		buttons[b.String()] = b
+	}
+}
+
+func lookupKey(name string) (keyboard.Key, error) {
+	k, ok := keys[name]
+	if !ok {
+		return keyboard.Invalid, fmt.Errorf("script: unknown key %q", name)
+	}
+	return k, nil
+}
+
+func lookupButton(name string) (mouse.Button, error) {
+	b, ok := buttons[name]
+	if !ok {
+		return mouse.Invalid, fmt.Errorf("script: unknown mouse button %q", name)
+	}
+	return b, nil
+}
+
+// module sets the named global to a table of the given values.
+func (vm *VM) module(name string, members map[string]interface{}) {
+	s := vm.State
+	s.NewTable()
+	for k, v := range members {
+		vm.push(s, reflect.ValueOf(v))
+		s.SetField(-2, k)
+	}
+	s.SetGlobal(name)
+}
+
+// bindModules binds the engine modules, see the package documentation.
+func (vm *VM) bindModules() {
+	vm.module("lmath", map[string]interface{}{
+		"Vec2": func(x, y float64) lmath.Vec2 { return lmath.Vec2{X: x, Y: y} },
+		"Vec3": func(x, y, z float64) lmath.Vec3 { return lmath.Vec3{X: x, Y: y, Z: z} },
+		"Vec4": func(x, y, z, w float64) lmath.Vec4 { return lmath.Vec4{X: x, Y: y, Z: z, W: w} },
+		"Quat": func(w, x, y, z float64) lmath.Quat { return lmath.Quat{W: w, X: x, Y: y, Z: z} },
+
+		"Radians":             lmath.Radians,
+		"Degrees":             lmath.Degrees,
+		"Clamp":               lmath.Clamp,
+		"Lerp":                lmath.Lerp,
+		"QuatFromAxisAngle":   lmath.QuatFromAxisAngle,
+		"QuatFromHpr":         lmath.QuatFromHpr,
+		"Mat4FromTranslation": lmath.Mat4FromTranslation,
+		"Mat4FromScale":       lmath.Mat4FromScale,
+		"Mat4Identity":        lmath.Mat4Identity,
+		"QuatIdentity":        lmath.QuatIdentity,
+		"TransformIdentity":   lmath.TransformIdentity,
+		"CoordSysZUpRight":    lmath.CoordSysZUpRight,
+	})
+
+	vm.module("scene", map[string]interface{}{
+		"Load": func(path string) (*scene.Scene, error) {
+			f, err := os.Open(path)
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			return scene.Load(f)
+		},
+		"NewNode": scene.NewNode,
+	})
+
+	vm.module("audio", map[string]interface{}{
+		"Play": func(name string, volume *float64) error {
+			if vm.Audio == nil {
+				return ErrNoAudio
+			}
+			v := 1.0
+			if volume != nil {
+				v = *volume
+			}
+			vm.Audio.Play(name, v)
+			return nil
+		},
+		"Stop": func(name string) error {
+			if vm.Audio == nil {
+				return ErrNoAudio
+			}
+			vm.Audio.Stop(name)
+			return nil
+		},
+	})
+
+	vm.module("input", map[string]interface{}{
+		"KeyDown": func(name string) (bool, error) {
+			k, err := lookupKey(name)
+			return err == nil && vm.Keyboard != nil && vm.Keyboard.Down(k), err
+		},
+		"KeyUp": func(name string) (bool, error) {
+			k, err := lookupKey(name)
+			return err == nil && (vm.Keyboard == nil || vm.Keyboard.Up(k)), err
+		},
+		"MouseDown": func(name string) (bool, error) {
+			b, err := lookupButton(name)
+			return err == nil && vm.Mouse != nil && vm.Mouse.Down(b), err
+		},
+		"MouseUp": func(name string) (bool, error) {
+			b, err := lookupButton(name)
+			return err == nil && (vm.Mouse == nil || vm.Mouse.Up(b)), err
+		},
+	})
+}
@@ -0,0 +1,123 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package script
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"azul3d.org/engine/keyboard"
+	"azul3d.org/engine/lmath"
+	"azul3d.org/engine/scene"
+)
+
+type player struct {
+	Name  string
+	Pos   lmath.Vec3
+	Items []string
+}
+
+func (p *player) Move(d lmath.Vec3) {
+	p.Pos = p.Pos.Add(d)
+}
+
+type sounds []string
+
+func (s *sounds) Play(name string, volume float64) { *s = append(*s, name) }
+func (s *sounds) Stop(name string)                 {}
+
+func TestBindings(t *testing.T) {
+	vm := New()
+	defer vm.Close()
+	vm.Keyboard = keyboard.NewWatcher()
+	vm.Keyboard.SetState(keyboard.W, keyboard.Down)
+	snd := new(sounds)
+	vm.Audio = snd
+
+	p := &player{Name: "hero", Items: []string{"sword"}}
+	vm.Set("player", p)
+	err := vm.Run(`
+		player:Move(lmath.Vec3(1, 2, 3) * 2)
+		player.Pos.Z = 0
+		player.Items[1] = "shield"
+		if input.KeyDown("W") and not input.KeyDown("S") then
+			audio.Play("step")
+		end
+		function sum(a, b) return a + b, #player.Items end
+		node = scene.NewNode("door")
+		node.Properties = {locked = "yes"}
+	`, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Pos != (lmath.Vec3{2, 4, 0}) || p.Items[0] != "shield" {
+		t.Fatalf("player %+v", p)
+	}
+	if !reflect.DeepEqual(*snd, sounds{"step"}) {
+		t.Fatalf("played %v", *snd)
+	}
+	results, err := vm.Call("sum", 2, 3)
+	if err != nil || !reflect.DeepEqual(results, []interface{}{int64(5), int64(1)}) {
+		t.Fatalf("sum = %v, %v", results, err)
+	}
+	node, err := vm.Get("node")
+	if n, ok := node.(*scene.Node); err != nil || !ok || n.Name != "door" || n.Properties["locked"] != "yes" {
+		t.Fatalf("node = %v, %v", node, err)
+	}
+
+	// Errors of Go functions and bad arguments are raised in scripts.
+	for _, bad := range []string{
+		`input.KeyDown("NoSuchKey")`,
+		`player:Move("up")`,
+		`player.Nope = 1`,
+	} {
+		if err := vm.Run(bad, "bad"); err == nil {
+			t.Errorf("%s: expected an error", bad)
+		}
+	}
+}
+
+func TestReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "script")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "main.lua")
+	write := func(code string, mod time.Time) {
+		if err := ioutil.WriteFile(path, []byte(code), 0644); err != nil {
+			t.Fatal(err)
+		}
+		os.Chtimes(path, mod, mod)
+	}
+
+	vm := New()
+	defer vm.Close()
+	start := time.Now().Add(-time.Hour)
+	write(`count = (count or 0) + 1; function value() return 1 end`, start)
+	if err := vm.RunFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded, err := vm.Reload(); len(reloaded) != 0 || err != nil {
+		t.Fatalf("reloaded %v, %v", reloaded, err)
+	}
+
+	write(`count = (count or 0) + 1
+		function value() return 2 end
+		function on_reload(path) reloads = (reloads or 0) + 1 end`, start.Add(time.Minute))
+	if reloaded, err := vm.Reload(); len(reloaded) != 1 || err != nil {
+		t.Fatalf("reloaded %v, %v", reloaded, err)
+	}
+	results, err := vm.Call("value")
+	count, _ := vm.Get("count")
+	reloads, _ := vm.Get("reloads")
+	if err != nil || results[0] != int64(2) || count != int64(2) || reloads != int64(1) {
+		t.Fatalf("value %v, count %v, reloads %v", results, count, reloads)
+	}
+}
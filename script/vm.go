@@ -0,0 +1,168 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package script
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"time"
+
+	"azul3d.org/engine/keyboard"
+	"azul3d.org/engine/mouse"
+	"azul3d.org/engine/native/lua"
+)
+
+// Audio plays sounds on behalf of scripts, see the audio module.
+type Audio interface {
+	// Play plays the named sound at the given volume (zero to one).
+	Play(name string, volume float64)
+
+	// Stop stops all playback of the named sound.
+	Stop(name string)
+}
+
+// file is a script file run by a VM.
+type file struct {
+	path    string
+	modTime time.Time
+}
+
+// VM is a Lua virtual machine with bindings for engine APIs. It's methods
+// are not safe for access from multiple goroutines concurrently.
+type VM struct {
+	// The Lua state, for direct access to the Lua API.
+	*lua.State
+
+	// The keyboard and mouse watchers read by the input module, or nil.
+	Keyboard *keyboard.Watcher
+	Mouse    *mouse.Watcher
+
+	// The audio player used by the audio module, or nil.
+	Audio Audio
+
+	files []*file
+}
+
+// Set sets the named global variable to the given Go value, see the package
+// documentation for how it is exposed.
+func (vm *VM) Set(name string, v interface{}) {
+	vm.push(vm.State, reflect.ValueOf(v))
+	vm.SetGlobal(name)
+}
+
+// Get returns the value of the named global variable, converted to it's
+// natural Go type (see Function for Lua functions).
+func (vm *VM) Get(name string) (interface{}, error) {
+	vm.GetGlobal(name)
+	defer vm.Pop(1)
+	v, err := vm.get(vm.State, -1, interfaceType)
+	if err != nil || !v.IsValid() {
+		return nil, err
+	}
+	return v.Interface(), nil
+}
+
+// Run runs the given Lua source code, with the given chunk name used in error
+// messages.
+func (vm *VM) Run(code, name string) error {
+	return vm.DoString(code, name)
+}
+
+// RunFile runs the Lua script file at the given path. The file is then
+// watched for changes, see Reload.
+func (vm *VM) RunFile(path string) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	var f *file
+	for _, w := range vm.files {
+		if w.path == path {
+			f = w
+		}
+	}
+	if f == nil {
+		f = &file{path: path}
+		vm.files = append(vm.files, f)
+	}
+	f.modTime = fi.ModTime()
+	return vm.runFile(path)
+}
+
+func (vm *VM) runFile(path string) error {
+	code, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return vm.DoString(string(code), "@"+path)
+}
+
+// Reload re-runs each script file (see RunFile) which was modified since it
+// was last run, in the order they were first run, and then calls the global
+// on_reload function (if the script defines one) with it's path. Global
+// variables defined by a script are kept, such that state may survive
+// reloads. It returns the paths of the reloaded files and the first error
+// encountered (files are reloaded regardless of errors in others).
+func (vm *VM) Reload() (reloaded []string, err error) {
+	for _, f := range vm.files {
+		fi, statErr := os.Stat(f.path)
+		if statErr != nil || !fi.ModTime().After(f.modTime) {
+			continue
+		}
+		f.modTime = fi.ModTime()
+		reloaded = append(reloaded, f.path)
+		runErr := vm.runFile(f.path)
+		if runErr == nil && vm.GetGlobal("on_reload") == lua.TypeFunction {
+			vm.Pop(1)
+			_, runErr = vm.Call("on_reload", f.path)
+		} else if runErr == nil {
+			vm.Pop(1)
+		}
+		if runErr != nil && err == nil {
+			err = runErr
+		}
+	}
+	return reloaded, err
+}
+
+// Call calls the named global Lua function with the given arguments, and
+// returns it's results converted to their natural Go types.
+func (vm *VM) Call(name string, args ...interface{}) ([]interface{}, error) {
+	if t := vm.GetGlobal(name); t != lua.TypeFunction {
+		vm.Pop(1)
+		return nil, fmt.Errorf("script: %s is a %v, not a function", name, t)
+	}
+	s := vm.State
+	top := s.Top() - 1
+	for _, a := range args {
+		vm.push(s, reflect.ValueOf(a))
+	}
+	if err := s.Call(len(args), lua.MultRet); err != nil {
+		return nil, err
+	}
+	defer s.SetTop(top)
+	results := make([]interface{}, s.Top()-top)
+	for i := range results {
+		v, err := vm.get(s, top+1+i, interfaceType)
+		if err != nil {
+			return nil, err
+		}
+		if v.IsValid() {
+			results[i] = v.Interface()
+		}
+	}
+	return results, nil
+}
+
+// New returns a new VM with the Lua standard libraries and the engine
+// modules loaded.
+func New() *VM {
+	vm := &VM{State: lua.New()}
+	vm.bindValues()
+	vm.bindModules()
+	return vm
+}
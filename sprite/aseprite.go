@@ -0,0 +1,83 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sprite
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"sort"
+)
+
+func (j *sheetJSON) aseprite() (*Sheet, error) {
+	frames, err := decodeFrames(j.Frames)
+	if err != nil {
+		return nil, err
+	}
+	s := &Sheet{
+		Image:  j.Meta.Image,
+		Size:   image.Pt(j.Meta.Size.W, j.Meta.Size.H),
+		Frames: frames,
+	}
+
+	// Tags are ranges of frames.
+	for _, jt := range j.Meta.FrameTags {
+		if jt.From < 0 || jt.To >= len(frames) || jt.From > jt.To {
+			return nil, fmt.Errorf("sprite: tag %q has invalid frames %d-%d", jt.Name, jt.From, jt.To)
+		}
+		dir, err := parseDirection(jt.Direction)
+		if err != nil {
+			return nil, err
+		}
+		repeat, err := parseRepeat(jt.Repeat)
+		if err != nil {
+			return nil, err
+		}
+		t := &Tag{
+			Name:      jt.Name,
+			Direction: dir,
+			Repeat:    repeat,
+		}
+		for i := jt.From; i <= jt.To; i++ {
+			t.Frames = append(t.Frames, i)
+		}
+		s.Tags = append(s.Tags, t)
+	}
+
+	for _, js := range j.Meta.Slices {
+		sl := &Slice{Name: js.Name}
+		for _, jk := range js.Keys {
+			k := SliceKey{
+				Frame:  jk.Frame,
+				Bounds: jk.Bounds.rect(),
+			}
+			if jk.Center != nil {
+				k.Center = jk.Center.rect()
+			}
+			if jk.Pivot != nil {
+				k.Pivot = &image.Point{jk.Pivot.X, jk.Pivot.Y}
+			}
+			sl.Keys = append(sl.Keys, k)
+		}
+		sort.SliceStable(sl.Keys, func(a, b int) bool {
+			return sl.Keys[a].Frame < sl.Keys[b].Frame
+		})
+		s.Slices = append(s.Slices, sl)
+	}
+	return s, nil
+}
+
+// ParseAseprite parses a sprite sheet exported by Aseprite in JSON format,
+// with either the "Hash" or "Array" frame layout. Frame tags become the tags
+// of the sheet, and slices it's slices.
+//
+// Aseprite does not export pivots, frames are pivoted at their center.
+func ParseAseprite(data []byte) (*Sheet, error) {
+	var j sheetJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, err
+	}
+	return j.aseprite()
+}
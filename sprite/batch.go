@@ -0,0 +1,148 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sprite
+
+import (
+	"math"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/lmath"
+)
+
+var (
+	glslVert = []byte(`
+#version 120
+
+attribute vec3 Vertex;
+attribute vec2 TexCoord0;
+
+uniform mat4 MVP;
+
+varying vec2 tc0;
+
+void main()
+{
+	tc0 = TexCoord0;
+	gl_Position = MVP * vec4(Vertex, 1.0);
+}
+`)
+
+	glslFrag = []byte(`
+#version 120
+
+varying vec2 tc0;
+
+uniform sampler2D Texture0;
+uniform bool BinaryAlpha;
+
+void main()
+{
+	gl_FragColor = texture2D(Texture0, tc0);
+	if(BinaryAlpha && gl_FragColor.a < 0.5) {
+		discard;
+	}
+}
+`)
+)
+
+// Shader is the shader used by batches, which simply draws the sheet texture.
+var Shader = &gfx.Shader{
+	Name: "sprite.Shader",
+	GLSL: &gfx.GLSLSources{
+		Vertex:   glslVert,
+		Fragment: glslFrag,
+	},
+}
+
+// Affine returns a 2D transformation matrix which scales, then rotates (by
+// the given angle in radians, counter-clockwise) and then translates.
+func Affine(pos lmath.Vec2, rot float64, scale lmath.Vec2) lmath.Mat3 {
+	s, c := math.Sincos(rot)
+	return lmath.Matrix3(
+		c*scale.X, s*scale.X, 0,
+		-s*scale.Y, c*scale.Y, 0,
+		pos.X, pos.Y, 1,
+	)
+}
+
+// Batch draws many frames of a sprite sheet at once, as a single object with
+// a single mesh. A batch is typically reset and refilled each frame:
+//
+//  b.Reset()
+//  for _, e := range entities {
+//      b.Add(e.Frame, sprite.Affine(e.Pos, e.Rot, e.Scale))
+//  }
+//  canvas.Draw(image.Rect(0, 0, 0, 0), b.Object, camera)
+//
+// Sprites are drawn in the order they are added, later sprites in front of
+// earlier ones.
+type Batch struct {
+	*gfx.Object
+
+	// The sheet which frames are drawn from.
+	Sheet *Sheet
+
+	// The distance along the Y axis between consecutive sprites, such that
+	// later sprites are in front of earlier ones. The default is 0.01.
+	DepthOffset float32
+
+	n int
+}
+
+// Len returns the number of sprites in the batch.
+func (b *Batch) Len() int {
+	return b.n
+}
+
+// Reset removes all of the sprites from the batch.
+func (b *Batch) Reset() {
+	m := b.Meshes[0]
+	m.Vertices = m.Vertices[:0]
+	m.TexCoords[0].Slice = m.TexCoords[0].Slice[:0]
+	b.changed(m)
+	b.n = 0
+}
+
+// Add adds the given frame of the sheet to the batch, transformed by m (see
+// Affine). Frame coordinates are in pixels with the frame's pivot at the
+// origin and +Y up.
+func (b *Batch) Add(f *Frame, m lmath.Mat3) {
+	mesh := b.Meshes[0]
+	appendFrame(mesh, b.Sheet, f, m, -float32(b.n)*b.DepthOffset)
+	b.changed(mesh)
+	b.n++
+}
+
+// changed marks the mesh as changed, such that it is updated and it's bounds
+// are recalculated.
+func (b *Batch) changed(m *gfx.Mesh) {
+	m.VerticesChanged = true
+	m.TexCoords[0].Changed = true
+	m.AABB = lmath.Rect3Zero
+	b.CachedBounds = nil
+}
+
+// NewBatch returns a new, empty, batch drawing frames of the given sheet with
+// the given texture (the sheet image).
+func NewBatch(s *Sheet, tex *gfx.Texture) *Batch {
+	m := gfx.NewMesh()
+	m.Dynamic = true
+	m.TexCoords = make([]gfx.TexCoordSet, 1)
+
+	o := gfx.NewObject()
+	o.Shader = Shader
+	o.Meshes = []*gfx.Mesh{m}
+	o.Textures = []*gfx.Texture{tex}
+
+	// Disable face culling, as sprites may be flipped by a negative scale.
+	o.State = gfx.NewState()
+	o.State.FaceCulling = gfx.NoFaceCulling
+	o.State.AlphaMode = gfx.AlphaToCoverage
+	return &Batch{
+		Object:      o,
+		Sheet:       s,
+		DepthOffset: 0.01,
+	}
+}
@@ -0,0 +1,31 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package sprite implements sprite sheets, as exported by Aseprite and
+// TexturePacker.
+//
+// A Sheet describes the frames packed into a single sheet image: the region
+// of each frame within the image, how it was trimmed and rotated, it's pivot
+// and it's duration, along with tags naming the frames of each animation:
+//
+//  data, err := ioutil.ReadFile("sprites/hero.json")
+//  if err != nil {
+//      // Handle error.
+//  }
+//  sheet, err := sprite.Parse(data)
+//  if err != nil {
+//      // Handle error.
+//  }
+//  walk := sheet.Tag("walk")
+//  for _, i := range walk.Frames {
+//      f := sheet.Frames[i]
+//      fmt.Println(f.Name, f.Duration)
+//  }
+//
+// The mesh of a frame (see Sheet.Mesh) is a card textured with the frame's
+// region of the sheet image and positioned relative to it's pivot, such that
+// switching the mesh of an object animates it. Many frames are drawn at once
+// with a Batch, and the animations (tags) of a sheet are played with a Player.
+// For skeletal animation see the skeleton sub-package.
+package sprite // import "azul3d.org/engine/sprite"
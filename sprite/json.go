@@ -0,0 +1,203 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sprite
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"strconv"
+	"strings"
+	"time"
+
+	"azul3d.org/engine/lmath"
+)
+
+// ErrFormat is returned when parsing data which is not a sprite sheet.
+var ErrFormat = errors.New("sprite: unknown sprite sheet format")
+
+// The JSON representation of sprite sheets, which Aseprite and TexturePacker
+// share (both follow the format of TexturePacker's JSON exporter).
+type (
+	rectJSON struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+		W int `json:"w"`
+		H int `json:"h"`
+	}
+
+	sizeJSON struct {
+		W int `json:"w"`
+		H int `json:"h"`
+	}
+
+	frameJSON struct {
+		Filename         string   `json:"filename"`
+		Frame            rectJSON `json:"frame"`
+		Rotated          bool     `json:"rotated"`
+		Trimmed          bool     `json:"trimmed"`
+		SpriteSourceSize rectJSON `json:"spriteSourceSize"`
+		SourceSize       sizeJSON `json:"sourceSize"`
+		Pivot            *struct {
+			X float64 `json:"x"`
+			Y float64 `json:"y"`
+		} `json:"pivot"`
+		Duration int `json:"duration"`
+	}
+
+	tagJSON struct {
+		Name      string          `json:"name"`
+		From      int             `json:"from"`
+		To        int             `json:"to"`
+		Direction string          `json:"direction"`
+		Repeat    json.RawMessage `json:"repeat"`
+	}
+
+	sliceKeyJSON struct {
+		Frame  int       `json:"frame"`
+		Bounds rectJSON  `json:"bounds"`
+		Center *rectJSON `json:"center"`
+		Pivot  *struct {
+			X int `json:"x"`
+			Y int `json:"y"`
+		} `json:"pivot"`
+	}
+
+	sliceJSON struct {
+		Name string         `json:"name"`
+		Keys []sliceKeyJSON `json:"keys"`
+	}
+
+	metaJSON struct {
+		App       string      `json:"app"`
+		Image     string      `json:"image"`
+		Size      sizeJSON    `json:"size"`
+		FrameTags []tagJSON   `json:"frameTags"`
+		Slices    []sliceJSON `json:"slices"`
+	}
+
+	sheetJSON struct {
+		Frames     json.RawMessage     `json:"frames"`
+		Meta       metaJSON            `json:"meta"`
+		Animations map[string][]string `json:"animations"`
+	}
+)
+
+func (r rectJSON) rect() image.Rectangle {
+	return image.Rect(r.X, r.Y, r.X+r.W, r.Y+r.H)
+}
+
+func (f frameJSON) toFrame() *Frame {
+	frame := &Frame{
+		Name:       f.Filename,
+		Rect:       f.Frame.rect(),
+		Rotated:    f.Rotated,
+		SourceSize: image.Pt(f.SourceSize.W, f.SourceSize.H),
+		Trim:       f.SpriteSourceSize.rect(),
+		Pivot:      lmath.Vec2{X: 0.5, Y: 0.5},
+		Duration:   time.Duration(f.Duration) * time.Millisecond,
+	}
+	if f.Rotated {
+		// The size of the frame is that of the unrotated frame.
+		frame.Rect.Max = frame.Rect.Min.Add(image.Pt(f.Frame.H, f.Frame.W))
+	}
+	if frame.SourceSize == (image.Point{}) {
+		frame.SourceSize = image.Pt(f.Frame.W, f.Frame.H)
+	}
+	if frame.Trim.Empty() {
+		frame.Trim = image.Rectangle{Max: frame.SourceSize}
+	}
+	if f.Pivot != nil {
+		frame.Pivot = lmath.Vec2{X: f.Pivot.X, Y: f.Pivot.Y}
+	}
+	return frame
+}
+
+// decodeFrames decodes the frames of a sheet, either an array of frames or an
+// object of frames by their name. The order of the frames of an object is
+// kept, as tags refer to frames by their index.
+func decodeFrames(data json.RawMessage) ([]*Frame, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, ErrFormat
+	}
+	if data[0] == '[' {
+		var frames []frameJSON
+		if err := json.Unmarshal(data, &frames); err != nil {
+			return nil, err
+		}
+		out := make([]*Frame, len(frames))
+		for i, f := range frames {
+			out[i] = f.toFrame()
+		}
+		return out, nil
+	}
+
+	d := json.NewDecoder(bytes.NewReader(data))
+	if t, err := d.Token(); err != nil || t != json.Delim('{') {
+		return nil, ErrFormat
+	}
+	var out []*Frame
+	for d.More() {
+		t, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		var f frameJSON
+		if err := d.Decode(&f); err != nil {
+			return nil, err
+		}
+		f.Filename = t.(string)
+		out = append(out, f.toFrame())
+	}
+	return out, nil
+}
+
+// parseDirection parses the direction of an Aseprite tag.
+func parseDirection(s string) (Direction, error) {
+	switch s {
+	case "", "forward":
+		return Forward, nil
+	case "reverse":
+		return Reverse, nil
+	case "pingpong":
+		return PingPong, nil
+	case "pingpong_reverse":
+		return PingPongReverse, nil
+	}
+	return 0, fmt.Errorf("sprite: unknown tag direction %q", s)
+}
+
+// Parse parses the given sprite sheet data, exported by either Aseprite (see
+// ParseAseprite) or TexturePacker (see ParseTexturePacker) in JSON format.
+func Parse(data []byte) (*Sheet, error) {
+	var j sheetJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, err
+	}
+	if strings.Contains(strings.ToLower(j.Meta.App), "aseprite") {
+		return j.aseprite()
+	}
+	return j.texturePacker()
+}
+
+// parseRepeat parses the repeat count of an Aseprite tag, which newer
+// versions write as a string.
+func parseRepeat(r json.RawMessage) (int, error) {
+	if len(r) == 0 {
+		return 0, nil
+	}
+	var s string
+	if r[0] == '"' {
+		if err := json.Unmarshal(r, &s); err != nil {
+			return 0, err
+		}
+	} else {
+		s = string(r)
+	}
+	return strconv.Atoi(s)
+}
@@ -0,0 +1,198 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sprite
+
+import (
+	"fmt"
+	"time"
+
+	"azul3d.org/engine/lmath"
+)
+
+// Event is an event of an animation, fired when the animation enters a frame.
+type Event struct {
+	// The name of the event.
+	Name string
+
+	// The frame that was entered, as an index into the frames of the sheet.
+	Frame int
+}
+
+// sequence returns the frames of the tag in the order they are played in a
+// single repetition.
+func (t *Tag) sequence() []int {
+	n := len(t.Frames)
+	reversed := make([]int, n)
+	for i, f := range t.Frames {
+		reversed[n-1-i] = f
+	}
+	switch {
+	case t.Direction == Reverse || (t.Direction == PingPongReverse && n < 3):
+		return reversed
+	case t.Direction == PingPong && n >= 3:
+		return append(t.Frames[:n:n], reversed[1:n-1]...)
+	case t.Direction == PingPongReverse:
+		return append(reversed, t.Frames[1:n-1]...)
+	}
+	return t.Frames
+}
+
+// Player plays the animations (tags) of a sprite sheet, frame by frame. A
+// player is typically updated and drawn each frame:
+//
+//  for _, ev := range p.Update(dt) {
+//      if ev.Name == "footstep" {
+//          ...
+//      }
+//  }
+//  p.Draw(batch, sprite.Affine(pos, 0, lmath.Vec2{1, 1}))
+type Player struct {
+	// The sheet whose animations are played.
+	Sheet *Sheet
+
+	// The speed at which animations are played, where 1 is normal speed.
+	Speed float64
+
+	// The duration of frames which have no duration. The default is 100ms.
+	DefaultDuration time.Duration
+
+	// The events of each frame, by it's index into the frames of the sheet.
+	// They are fired each time a frame is entered.
+	Events map[int][]string
+
+	tag     *Tag
+	seq     []int
+	pos     int
+	elapsed time.Duration
+	loops   int
+	done    bool
+	pending []Event
+}
+
+// enter enters the frame at the given position of the sequence, queuing it's
+// events.
+func (p *Player) enter(pos int) {
+	p.pos = pos
+	f := p.seq[pos]
+	for _, name := range p.Events[f] {
+		p.pending = append(p.pending, Event{Name: name, Frame: f})
+	}
+}
+
+// duration returns the duration of the frame with the given index.
+func (p *Player) duration(f int) time.Duration {
+	d := p.Sheet.Frames[f].Duration
+	if d <= 0 {
+		d = p.DefaultDuration
+	}
+	if d <= 0 {
+		// Never loop forever on frames without duration.
+		d = time.Millisecond
+	}
+	return d
+}
+
+// Play starts playing the animation with the given name from it's first
+// frame. An error is returned if the sheet has no such animation, or if it has
+// no frames.
+func (p *Player) Play(name string) error {
+	t := p.Sheet.Tag(name)
+	if t == nil {
+		return fmt.Errorf("sprite: no animation %q", name)
+	}
+	if len(t.Frames) == 0 {
+		return fmt.Errorf("sprite: animation %q has no frames", name)
+	}
+	p.tag = t
+	p.seq = t.sequence()
+	p.elapsed = 0
+	p.loops = 0
+	p.done = false
+	p.pending = p.pending[:0]
+	p.enter(0)
+	return nil
+}
+
+// Tag returns the animation being played, or nil if none is.
+func (p *Player) Tag() *Tag {
+	return p.tag
+}
+
+// Update advances the animation by dt (scaled by the player's speed) and
+// returns the events of the frames that were entered since the last update, in
+// order. An animation which is repeated a number of times stops on the last
+// frame of it's last repetition.
+func (p *Player) Update(dt time.Duration) []Event {
+	if p.tag == nil || p.done {
+		return p.flush()
+	}
+	p.elapsed += time.Duration(float64(dt) * p.Speed)
+	for p.elapsed >= p.duration(p.seq[p.pos]) {
+		p.elapsed -= p.duration(p.seq[p.pos])
+		next := p.pos + 1
+		if next == len(p.seq) {
+			p.loops++
+			if p.tag.Repeat > 0 && p.loops >= p.tag.Repeat {
+				p.done = true
+				p.elapsed = 0
+				break
+			}
+			next = 0
+		}
+		p.enter(next)
+	}
+	return p.flush()
+}
+
+// flush returns and clears the pending events.
+func (p *Player) flush() []Event {
+	if len(p.pending) == 0 {
+		return nil
+	}
+	events := append([]Event(nil), p.pending...)
+	p.pending = p.pending[:0]
+	return events
+}
+
+// Index returns the index into the frames of the sheet of the current frame,
+// or -1 if no animation is being played.
+func (p *Player) Index() int {
+	if p.tag == nil {
+		return -1
+	}
+	return p.seq[p.pos]
+}
+
+// Frame returns the current frame, or nil if no animation is being played.
+func (p *Player) Frame() *Frame {
+	if p.tag == nil {
+		return nil
+	}
+	return p.Sheet.Frames[p.seq[p.pos]]
+}
+
+// Done tells if the animation has finished playing. Animations which loop
+// forever never finish.
+func (p *Player) Done() bool {
+	return p.done
+}
+
+// Draw adds the current frame to the batch, transformed by m. Nothing is drawn
+// if no animation is being played.
+func (p *Player) Draw(b *Batch, m lmath.Mat3) {
+	if f := p.Frame(); f != nil {
+		b.Add(f, m)
+	}
+}
+
+// NewPlayer returns a new player of the animations of the given sheet, which
+// plays no animation until Play is called.
+func NewPlayer(s *Sheet) *Player {
+	return &Player{
+		Sheet:           s,
+		Speed:           1,
+		DefaultDuration: 100 * time.Millisecond,
+	}
+}
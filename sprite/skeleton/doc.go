@@ -0,0 +1,24 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package skeleton implements 2D skeletal (bone-based) sprite animation.
+//
+// A skeleton is a hierarchy of bones with slots attached to them, each slot
+// showing an attachment: a frame of a sprite sheet. Animations move the bones
+// and switch the attachments of slots over time, and a State plays them and
+// draws the skeleton into a sprite.Batch each frame:
+//
+//  state := skeleton.NewState(skel)
+//  state.Play("walk", true)
+//  ...
+//  for _, ev := range state.Update(dt) {
+//      ...
+//  }
+//  batch.Reset()
+//  state.Draw(batch, sprite.Affine(pos, 0, lmath.Vec2{1, 1}))
+//
+// Skeletons may be imported from the JSON formats of Spine (see ParseSpine)
+// and DragonBones (see ParseDragonBones). Only region (image) attachments are
+// supported, and all curves are treated as linear.
+package skeleton // import "azul3d.org/engine/sprite/skeleton"
@@ -0,0 +1,287 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package skeleton
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// The JSON representation of DragonBones skeletons (armatures).
+type (
+	dbTransformJSON struct {
+		X   float64  `json:"x"`
+		Y   float64  `json:"y"`
+		SkY float64  `json:"skY"`
+		ScX *float64 `json:"scX"`
+		ScY *float64 `json:"scY"`
+	}
+
+	dbBoneJSON struct {
+		Name      string          `json:"name"`
+		Parent    string          `json:"parent"`
+		Length    float64         `json:"length"`
+		Transform dbTransformJSON `json:"transform"`
+	}
+
+	dbSlotJSON struct {
+		Name         string `json:"name"`
+		Parent       string `json:"parent"`
+		DisplayIndex int    `json:"displayIndex"`
+	}
+
+	dbDisplayJSON struct {
+		Name      string          `json:"name"`
+		Path      string          `json:"path"`
+		Type      string          `json:"type"`
+		Transform dbTransformJSON `json:"transform"`
+	}
+
+	dbSkinJSON struct {
+		Name string `json:"name"`
+		Slot []struct {
+			Name    string          `json:"name"`
+			Display []dbDisplayJSON `json:"display"`
+		} `json:"slot"`
+	}
+
+	dbFrameJSON struct {
+		Duration    int             `json:"duration"`
+		TweenEasing *float64        `json:"tweenEasing"`
+		Curve       json.RawMessage `json:"curve"`
+		X           *float64        `json:"x"`
+		Y           *float64        `json:"y"`
+		Rotate      float64         `json:"rotate"`
+		Value       int             `json:"value"`
+		Event       string          `json:"event"`
+		Events      []struct {
+			Name string `json:"name"`
+		} `json:"events"`
+	}
+
+	dbAnimationJSON struct {
+		Name     string `json:"name"`
+		Duration int    `json:"duration"`
+		Bone     []struct {
+			Name           string        `json:"name"`
+			TranslateFrame []dbFrameJSON `json:"translateFrame"`
+			RotateFrame    []dbFrameJSON `json:"rotateFrame"`
+			ScaleFrame     []dbFrameJSON `json:"scaleFrame"`
+		} `json:"bone"`
+		Slot []struct {
+			Name         string        `json:"name"`
+			DisplayFrame []dbFrameJSON `json:"displayFrame"`
+		} `json:"slot"`
+		Frame []dbFrameJSON `json:"frame"`
+	}
+
+	dbArmatureJSON struct {
+		Name      string            `json:"name"`
+		FrameRate float64           `json:"frameRate"`
+		Bone      []dbBoneJSON      `json:"bone"`
+		Slot      []dbSlotJSON      `json:"slot"`
+		Skin      []dbSkinJSON      `json:"skin"`
+		Animation []dbAnimationJSON `json:"animation"`
+	}
+
+	dbJSON struct {
+		FrameRate float64          `json:"frameRate"`
+		Armature  []dbArmatureJSON `json:"armature"`
+	}
+)
+
+// curve returns the curve of a DragonBones frame: frames without easing are
+// not tweened.
+func (f dbFrameJSON) curve() Curve {
+	if f.TweenEasing == nil && len(f.Curve) == 0 {
+		return Stepped
+	}
+	return Linear
+}
+
+// dbKeys converts DragonBones frames, whose durations are in frames at the
+// given frame rate, into keys using the value function.
+func dbKeys(frames []dbFrameJSON, frameRate float64, value func(f dbFrameJSON) (x, y float64)) []Key {
+	var (
+		keys []Key
+		t    int
+	)
+	for _, f := range frames {
+		k := Key{Time: seconds(float64(t) / frameRate), Curve: f.curve()}
+		k.X, k.Y = value(f)
+		keys = append(keys, k)
+		t += f.Duration
+	}
+	return keys
+}
+
+func (j *dbArmatureJSON) animation(s *Skeleton, displays [][]string, ja dbAnimationJSON) (*Animation, error) {
+	fr := j.FrameRate
+	a := &Animation{
+		Name:     ja.Name,
+		Duration: seconds(float64(ja.Duration) / fr),
+	}
+
+	for _, jb := range ja.Bone {
+		tl := BoneTimeline{Bone: s.Bone(jb.Name)}
+		if tl.Bone < 0 {
+			return nil, fmt.Errorf("skeleton: animation %q has unknown bone %q", ja.Name, jb.Name)
+		}
+		tl.Translate = dbKeys(jb.TranslateFrame, fr, func(f dbFrameJSON) (x, y float64) {
+			return orZero(f.X), -orZero(f.Y)
+		})
+		tl.Rotate = dbKeys(jb.RotateFrame, fr, func(f dbFrameJSON) (x, y float64) {
+			return -f.Rotate, 0
+		})
+		tl.Scale = dbKeys(jb.ScaleFrame, fr, func(f dbFrameJSON) (x, y float64) {
+			return orOne(f.X), orOne(f.Y)
+		})
+		a.Bones = append(a.Bones, tl)
+	}
+
+	for _, js := range ja.Slot {
+		tl := SlotTimeline{Slot: s.Slot(js.Name)}
+		if tl.Slot < 0 {
+			return nil, fmt.Errorf("skeleton: animation %q has unknown slot %q", ja.Name, js.Name)
+		}
+		t := 0
+		for _, f := range js.DisplayFrame {
+			k := AttachmentKey{Time: seconds(float64(t) / fr)}
+			if f.Value >= 0 && f.Value < len(displays[tl.Slot]) {
+				k.Attachment = displays[tl.Slot][f.Value]
+			}
+			tl.Attachments = append(tl.Attachments, k)
+			t += f.Duration
+		}
+		a.Slots = append(a.Slots, tl)
+	}
+
+	t := 0
+	for _, f := range ja.Frame {
+		at := seconds(float64(t) / fr)
+		if f.Event != "" {
+			a.Events = append(a.Events, EventKey{Time: at, Name: f.Event})
+		}
+		for _, e := range f.Events {
+			a.Events = append(a.Events, EventKey{Time: at, Name: e.Name})
+		}
+		t += f.Duration
+	}
+	return a, nil
+}
+
+func (j *dbArmatureJSON) skeleton() (*Skeleton, error) {
+	if j.FrameRate <= 0 {
+		return nil, fmt.Errorf("skeleton: armature %q has invalid frame rate %v", j.Name, j.FrameRate)
+	}
+	s := &Skeleton{Name: j.Name}
+
+	// DragonBones is Y-down with clockwise rotations, unlike Spine.
+	for _, jb := range j.Bone {
+		b := &Bone{
+			Name:     jb.Name,
+			Parent:   -1,
+			X:        jb.Transform.X,
+			Y:        -jb.Transform.Y,
+			Rotation: -jb.Transform.SkY,
+			ScaleX:   orOne(jb.Transform.ScX),
+			ScaleY:   orOne(jb.Transform.ScY),
+			Length:   jb.Length,
+		}
+		if jb.Parent != "" {
+			if b.Parent = s.Bone(jb.Parent); b.Parent < 0 {
+				return nil, fmt.Errorf("skeleton: bone %q has unknown parent %q", jb.Name, jb.Parent)
+			}
+		}
+		s.Bones = append(s.Bones, b)
+	}
+
+	for _, js := range j.Slot {
+		sl := &Slot{Name: js.Name, Bone: s.Bone(js.Parent)}
+		if sl.Bone < 0 {
+			return nil, fmt.Errorf("skeleton: slot %q has unknown bone %q", js.Name, js.Parent)
+		}
+		s.Slots = append(s.Slots, sl)
+	}
+
+	// The names of the displays of each slot, by display index, as the
+	// animations refer to them by index.
+	displays := make([][]string, len(s.Slots))
+	for _, jsk := range j.Skin {
+		sk := &Skin{Name: jsk.Name}
+		if sk.Name == "" {
+			sk.Name = "default"
+		}
+		for _, js := range jsk.Slot {
+			slot := s.Slot(js.Name)
+			if slot < 0 {
+				return nil, fmt.Errorf("skeleton: skin %q has unknown slot %q", sk.Name, js.Name)
+			}
+			var names []string
+			for _, d := range js.Display {
+				names = append(names, d.Name)
+				if d.Type != "" && d.Type != "image" {
+					continue
+				}
+				a := &Attachment{
+					Name:     d.Name,
+					X:        d.Transform.X,
+					Y:        -d.Transform.Y,
+					Rotation: -d.Transform.SkY,
+					ScaleX:   orOne(d.Transform.ScX),
+					ScaleY:   orOne(d.Transform.ScY),
+				}
+				if d.Path != "" {
+					a.Name = d.Path
+				}
+				sk.add(slot, d.Name, a)
+			}
+			if sk.Name == "default" || displays[slot] == nil {
+				displays[slot] = names
+			}
+		}
+		s.Skins = append(s.Skins, sk)
+	}
+	for i, js := range j.Slot {
+		if js.DisplayIndex >= 0 && js.DisplayIndex < len(displays[i]) {
+			s.Slots[i].Attachment = displays[i][js.DisplayIndex]
+		}
+	}
+
+	for _, ja := range j.Animation {
+		a, err := j.animation(s, displays, ja)
+		if err != nil {
+			return nil, err
+		}
+		s.Animations = append(s.Animations, a)
+	}
+	return s, nil
+}
+
+// ParseDragonBones parses the skeletons (armatures) exported by DragonBones
+// (version 5 and later) in JSON format. Skews and display types other than
+// images (e.g. meshes) are ignored, and easing is linear.
+func ParseDragonBones(data []byte) ([]*Skeleton, error) {
+	var j dbJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, err
+	}
+	if len(j.Armature) == 0 {
+		return nil, errors.New("skeleton: no armatures")
+	}
+	var skeletons []*Skeleton
+	for _, ja := range j.Armature {
+		if ja.FrameRate == 0 {
+			ja.FrameRate = j.FrameRate
+		}
+		s, err := ja.skeleton()
+		if err != nil {
+			return nil, err
+		}
+		skeletons = append(skeletons, s)
+	}
+	return skeletons, nil
+}
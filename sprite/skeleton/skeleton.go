@@ -0,0 +1,491 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package skeleton
+
+import (
+	"fmt"
+	"time"
+
+	"azul3d.org/engine/lmath"
+	"azul3d.org/engine/sprite"
+)
+
+// Bone is a bone of a skeleton, in it's setup pose. Coordinates are in pixels
+// with +Y up, and relative to the parent bone.
+type Bone struct {
+	// The name of the bone.
+	Name string
+
+	// The index of the parent bone, which always comes before it's children,
+	// or -1 for the root bone.
+	Parent int
+
+	// The position of the bone.
+	X, Y float64
+
+	// The rotation of the bone, in degrees counter-clockwise.
+	Rotation float64
+
+	// The scale of the bone.
+	ScaleX, ScaleY float64
+
+	// The length of the bone, which is informational only.
+	Length float64
+}
+
+// local returns the transformation of the bone relative to it's parent.
+func (b *Bone) local() lmath.Mat3 {
+	return sprite.Affine(
+		lmath.Vec2{X: b.X, Y: b.Y},
+		lmath.Radians(b.Rotation),
+		lmath.Vec2{X: b.ScaleX, Y: b.ScaleY},
+	)
+}
+
+// Slot is a slot of a skeleton, which shows an attachment on a bone.
+type Slot struct {
+	// The name of the slot.
+	Name string
+
+	// The index of the bone the slot is attached to.
+	Bone int
+
+	// The name of the attachment shown in the setup pose, or an empty string
+	// if none is.
+	Attachment string
+}
+
+// Attachment is an image attached to a slot, positioned relative to the bone
+// of the slot.
+type Attachment struct {
+	// The name of the sprite sheet frame of the attachment.
+	Name string
+
+	// The position of the attachment.
+	X, Y float64
+
+	// The rotation of the attachment, in degrees counter-clockwise.
+	Rotation float64
+
+	// The scale of the attachment.
+	ScaleX, ScaleY float64
+
+	// The size of the attachment in pixels, or zero to use the size of the
+	// frame.
+	Width, Height float64
+}
+
+// transform returns the transformation of the attachment relative to it's
+// bone, for the given frame.
+func (a *Attachment) transform(f *sprite.Frame) lmath.Mat3 {
+	sx, sy := a.ScaleX, a.ScaleY
+	if a.Width > 0 && f.SourceSize.X > 0 {
+		sx *= a.Width / float64(f.SourceSize.X)
+	}
+	if a.Height > 0 && f.SourceSize.Y > 0 {
+		sy *= a.Height / float64(f.SourceSize.Y)
+	}
+	return sprite.Affine(
+		lmath.Vec2{X: a.X, Y: a.Y},
+		lmath.Radians(a.Rotation),
+		lmath.Vec2{X: sx, Y: sy},
+	)
+}
+
+// Skin is a set of attachments for the slots of a skeleton.
+type Skin struct {
+	// The name of the skin.
+	Name string
+
+	// The attachments of each slot, by slot index and then attachment name.
+	Attachments map[int]map[string]*Attachment
+}
+
+// Attachment returns the named attachment of the given slot, or nil if the
+// skin has no such attachment.
+func (s *Skin) Attachment(slot int, name string) *Attachment {
+	return s.Attachments[slot][name]
+}
+
+// add adds an attachment to the skin.
+func (s *Skin) add(slot int, name string, a *Attachment) {
+	if s.Attachments == nil {
+		s.Attachments = make(map[int]map[string]*Attachment)
+	}
+	m, ok := s.Attachments[slot]
+	if !ok {
+		m = make(map[string]*Attachment)
+		s.Attachments[slot] = m
+	}
+	m[name] = a
+}
+
+// Curve is the interpolation between a keyframe and the next one.
+type Curve uint8
+
+const (
+	// Linear interpolates linearly to the next keyframe.
+	Linear Curve = iota
+
+	// Stepped holds the value of the keyframe until the next keyframe.
+	Stepped
+)
+
+// Key is a keyframe of a bone timeline.
+type Key struct {
+	// The time of the keyframe.
+	Time time.Duration
+
+	// The value of the keyframe: the rotation in degrees (X only), the
+	// translation or the scale.
+	X, Y float64
+
+	// The interpolation to the next keyframe.
+	Curve Curve
+}
+
+// sample returns the value of the keys at the given time, or ok=false if the
+// time is before the first key.
+func sample(keys []Key, t time.Duration, angle bool) (x, y float64, ok bool) {
+	if len(keys) == 0 || t < keys[0].Time {
+		return 0, 0, false
+	}
+	i := 0
+	for i+1 < len(keys) && keys[i+1].Time <= t {
+		i++
+	}
+	k := keys[i]
+	if i+1 == len(keys) || k.Curve == Stepped {
+		return k.X, k.Y, true
+	}
+	next := keys[i+1]
+	f := float64(t-k.Time) / float64(next.Time-k.Time)
+	nx := next.X
+	if angle {
+		// Rotate the shortest way around.
+		for nx-k.X > 180 {
+			nx -= 360
+		}
+		for nx-k.X < -180 {
+			nx += 360
+		}
+	}
+	return lmath.Lerp(k.X, nx, f), lmath.Lerp(k.Y, next.Y, f), true
+}
+
+// BoneTimeline animates a bone. Rotations and translations are added to the
+// setup pose of the bone, and scales multiply it.
+type BoneTimeline struct {
+	// The index of the animated bone.
+	Bone int
+
+	// The keyframes of the bone, sorted by time.
+	Rotate, Translate, Scale []Key
+}
+
+// AttachmentKey is a keyframe of a slot timeline.
+type AttachmentKey struct {
+	// The time of the keyframe.
+	Time time.Duration
+
+	// The name of the attachment shown from the keyframe on, or an empty
+	// string to show none.
+	Attachment string
+}
+
+// SlotTimeline animates the attachment of a slot.
+type SlotTimeline struct {
+	// The index of the animated slot.
+	Slot int
+
+	// The keyframes of the slot, sorted by time.
+	Attachments []AttachmentKey
+}
+
+// EventKey is an event fired at a time of an animation.
+type EventKey struct {
+	// The time of the event.
+	Time time.Duration
+
+	// The name of the event.
+	Name string
+}
+
+// Animation is an animation of a skeleton.
+type Animation struct {
+	// The name of the animation.
+	Name string
+
+	// The duration of the animation.
+	Duration time.Duration
+
+	// The timelines of the animation.
+	Bones []BoneTimeline
+	Slots []SlotTimeline
+
+	// The events of the animation, sorted by time.
+	Events []EventKey
+}
+
+// Skeleton is a skeleton, with it's skins and animations.
+type Skeleton struct {
+	// The name of the skeleton.
+	Name string
+
+	// The bones of the skeleton, parents before their children.
+	Bones []*Bone
+
+	// The slots of the skeleton, in draw order.
+	Slots []*Slot
+
+	// The skins of the skeleton.
+	Skins []*Skin
+
+	// The animations of the skeleton.
+	Animations []*Animation
+}
+
+// Bone returns the index of the bone with the given name, or -1 if there is
+// none.
+func (s *Skeleton) Bone(name string) int {
+	for i, b := range s.Bones {
+		if b.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Slot returns the index of the slot with the given name, or -1 if there is
+// none.
+func (s *Skeleton) Slot(name string) int {
+	for i, sl := range s.Slots {
+		if sl.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Skin returns the skin with the given name, or nil if there is none.
+func (s *Skeleton) Skin(name string) *Skin {
+	for _, sk := range s.Skins {
+		if sk.Name == name {
+			return sk
+		}
+	}
+	return nil
+}
+
+// Animation returns the animation with the given name, or nil if there is
+// none.
+func (s *Skeleton) Animation(name string) *Animation {
+	for _, a := range s.Animations {
+		if a.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+// State is the pose of a skeleton as it plays an animation.
+type State struct {
+	// The skeleton which is posed.
+	Skeleton *Skeleton
+
+	// The skin whose attachments are drawn. Attachments missing from it are
+	// looked up in the "default" skin.
+	Skin *Skin
+
+	// The speed at which animations are played, where 1 is normal speed.
+	Speed float64
+
+	anim        *Animation
+	loop        bool
+	time        time.Duration
+	bones       []Bone
+	world       []lmath.Mat3
+	attachments []string
+	pending     []string
+}
+
+// Animation returns the animation being played, or nil if none is.
+func (s *State) Animation() *Animation {
+	return s.anim
+}
+
+// Time returns the time of the animation being played.
+func (s *State) Time() time.Duration {
+	return s.time
+}
+
+// Play starts playing the animation with the given name from it's start,
+// looping it if loop is true. An error is returned if the skeleton has no such
+// animation.
+func (s *State) Play(name string, loop bool) error {
+	a := s.Skeleton.Animation(name)
+	if a == nil {
+		return fmt.Errorf("skeleton: no animation %q", name)
+	}
+	s.anim = a
+	s.loop = loop
+	s.time = 0
+	s.pending = s.pending[:0]
+	for _, e := range a.Events {
+		if e.Time == 0 {
+			s.pending = append(s.pending, e.Name)
+		}
+	}
+	s.apply()
+	return nil
+}
+
+// Update advances the animation by dt (scaled by the state's speed), poses
+// the skeleton and returns the names of the events passed since the last
+// update (or since Play, for events at the start of the animation), in order.
+// Looping animations fire the events at their start each time they loop.
+func (s *State) Update(dt time.Duration) []string {
+	if s.anim == nil {
+		return nil
+	}
+	prev := s.time
+	s.time += time.Duration(float64(dt) * s.Speed)
+
+	events := s.pending
+	s.pending = nil
+	fire := func(from, to time.Duration) {
+		for _, e := range s.anim.Events {
+			if e.Time > from && e.Time <= to {
+				events = append(events, e.Name)
+			}
+		}
+	}
+	d := s.anim.Duration
+	switch {
+	case d <= 0 || s.time < d:
+		fire(prev, s.time)
+	case !s.loop:
+		fire(prev, d)
+		s.time = d
+	default:
+		// Fire the events of each loop that was passed.
+		for s.time >= d {
+			fire(prev, d)
+			s.time -= d
+			prev = -1
+		}
+		fire(prev, s.time)
+	}
+	s.apply()
+	return events
+}
+
+// Reset poses the skeleton in it's setup pose, playing no animation.
+func (s *State) Reset() {
+	s.anim = nil
+	s.time = 0
+	s.pending = nil
+	s.apply()
+}
+
+// apply poses the skeleton at the current time of the animation.
+func (s *State) apply() {
+	for i, b := range s.Skeleton.Bones {
+		s.bones[i] = *b
+	}
+	for i, sl := range s.Skeleton.Slots {
+		s.attachments[i] = sl.Attachment
+	}
+	if a := s.anim; a != nil {
+		for _, tl := range a.Bones {
+			b := &s.bones[tl.Bone]
+			if r, _, ok := sample(tl.Rotate, s.time, true); ok {
+				b.Rotation += r
+			}
+			if x, y, ok := sample(tl.Translate, s.time, false); ok {
+				b.X += x
+				b.Y += y
+			}
+			if x, y, ok := sample(tl.Scale, s.time, false); ok {
+				b.ScaleX *= x
+				b.ScaleY *= y
+			}
+		}
+		for _, tl := range a.Slots {
+			for _, k := range tl.Attachments {
+				if k.Time > s.time {
+					break
+				}
+				s.attachments[tl.Slot] = k.Attachment
+			}
+		}
+	}
+	for i := range s.bones {
+		b := &s.bones[i]
+		s.world[i] = b.local()
+		if b.Parent >= 0 {
+			s.world[i] = s.world[i].Mul(s.world[b.Parent])
+		}
+	}
+}
+
+// World returns the transformation of the bone with the given index relative
+// to the skeleton, in the current pose.
+func (s *State) World(bone int) lmath.Mat3 {
+	return s.world[bone]
+}
+
+// Attachment returns the attachment shown by the slot with the given index in
+// the current pose, or nil if none is.
+func (s *State) Attachment(slot int) *Attachment {
+	name := s.attachments[slot]
+	if name == "" {
+		return nil
+	}
+	if s.Skin != nil {
+		if a := s.Skin.Attachment(slot, name); a != nil {
+			return a
+		}
+	}
+	if def := s.Skeleton.Skin("default"); def != nil {
+		return def.Attachment(slot, name)
+	}
+	return nil
+}
+
+// Draw adds the attachments of the skeleton in it's current pose to the batch,
+// in the draw order of the slots, transformed by m. Attachments whose frame is
+// missing from the sheet of the batch are not drawn.
+func (s *State) Draw(b *sprite.Batch, m lmath.Mat3) {
+	for i, sl := range s.Skeleton.Slots {
+		a := s.Attachment(i)
+		if a == nil {
+			continue
+		}
+		f := b.Sheet.Frame(a.Name)
+		if f == nil {
+			continue
+		}
+		b.Add(f, a.transform(f).Mul(s.world[sl.Bone]).Mul(m))
+	}
+}
+
+// NewState returns a new state of the given skeleton, in it's setup pose and
+// with it's "default" skin (or it's first skin, if it has no default skin).
+func NewState(s *Skeleton) *State {
+	st := &State{
+		Skeleton:    s,
+		Skin:        s.Skin("default"),
+		Speed:       1,
+		bones:       make([]Bone, len(s.Bones)),
+		world:       make([]lmath.Mat3, len(s.Bones)),
+		attachments: make([]string, len(s.Slots)),
+	}
+	if st.Skin == nil && len(s.Skins) > 0 {
+		st.Skin = s.Skins[0]
+	}
+	st.apply()
+	return st
+}
@@ -0,0 +1,178 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package skeleton
+
+import (
+	"image"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"azul3d.org/engine/lmath"
+	"azul3d.org/engine/sprite"
+)
+
+func readFile(t *testing.T, name string) []byte {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func near(a, b lmath.Vec2) bool {
+	return math.Abs(a.X-b.X) < 1e-6 && math.Abs(a.Y-b.Y) < 1e-6
+}
+
+// testSheet returns a sheet with the torso and sword frames.
+func testSheet() *sprite.Sheet {
+	return &sprite.Sheet{
+		Size: image.Pt(64, 64),
+		Frames: []*sprite.Frame{
+			{
+				Name:       "torso",
+				Rect:       image.Rect(0, 0, 16, 32),
+				SourceSize: image.Pt(16, 32),
+				Trim:       image.Rect(0, 0, 16, 32),
+				Pivot:      lmath.Vec2{X: 0.5, Y: 0.5},
+			},
+			{
+				Name:       "sword",
+				Rect:       image.Rect(16, 0, 24, 32),
+				SourceSize: image.Pt(8, 32),
+				Trim:       image.Rect(0, 0, 8, 32),
+				Pivot:      lmath.Vec2{X: 0.5, Y: 1},
+			},
+		},
+	}
+}
+
+func TestSpine(t *testing.T) {
+	s, err := ParseSpine(readFile(t, "hero_spine3.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	arm, armSlot := s.Bone("arm"), s.Slot("arm")
+	if len(s.Bones) != 3 || s.Bones[arm].Parent != s.Bone("body") || s.Bones[arm].Rotation != 90 || s.Bones[0].ScaleX != 1 {
+		t.Fatalf("bones %+v", s.Bones)
+	}
+	def := s.Skin("default")
+	if a := def.Attachment(armSlot, "hand"); a == nil || a.Name != "sword" || a.Rotation != -90 {
+		t.Fatalf("hand %+v", a)
+	}
+	if a := def.Attachment(armSlot, "cape"); a != nil {
+		t.Fatal("parsed a mesh attachment")
+	}
+
+	// Draw the setup pose.
+	st := NewState(s)
+	b := sprite.NewBatch(testSheet(), nil)
+	st.Draw(b, lmath.Mat3Identity)
+	v := b.Meshes[0].Vertices
+	if b.Len() != 2 || v[0].X != -8 || v[0].Z != 42 {
+		t.Fatalf("%d sprites, torso vertex %v", b.Len(), v[0])
+	}
+	if math.Abs(float64(v[6].X-1)) > 1e-4 || math.Abs(float64(v[6].Z-72)) > 1e-4 {
+		t.Fatalf("sword vertex %v", v[6])
+	}
+
+	// Halfway through the swing the arm is rotated by -45 degrees, and hidden.
+	if err := st.Play("dance", false); err == nil {
+		t.Fatal("played an unknown animation")
+	}
+	if err := st.Play("swing", false); err != nil {
+		t.Fatal(err)
+	}
+	if ev := st.Update(500 * time.Millisecond); !reflect.DeepEqual(ev, []string{"whoosh"}) {
+		t.Fatalf("events %v", ev)
+	}
+	c := math.Sqrt(0.5)
+	w := st.World(arm)
+	if p := (lmath.Vec2{}).TransformPointVec2(w); !near(p, lmath.Vec2{X: 10, Y: 30}) {
+		t.Fatalf("arm at %v", p)
+	}
+	if p := (lmath.Vec2{X: 1}).TransformPointVec2(w); !near(p, lmath.Vec2{X: 10 + 2*c, Y: 30 + c}) {
+		t.Fatalf("arm points to %v", p)
+	}
+	if st.Attachment(armSlot) != nil {
+		t.Fatal("arm is not hidden")
+	}
+
+	// Animations which don't loop stop at their end.
+	st.Update(time.Second)
+	if st.Time() != time.Second {
+		t.Fatalf("time %v", st.Time())
+	}
+
+	// Looping animations fire their events each loop.
+	st.Play("swing", true)
+	if ev := st.Update(1250 * time.Millisecond); len(ev) != 2 || st.Time() != 250*time.Millisecond {
+		t.Fatalf("events %v at %v", ev, st.Time())
+	}
+
+	// Skins fall back to the default skin.
+	st.Reset()
+	st.Skin = s.Skin("gold")
+	if a := st.Attachment(s.Slot("body")); a.Name != "torso_gold" {
+		t.Fatalf("torso %+v", a)
+	}
+	if a := st.Attachment(armSlot); a.Name != "sword" {
+		t.Fatalf("hand %+v", a)
+	}
+
+	// Spine 4.x skins are arrays, and rotations are values.
+	s, err = ParseSpine(readFile(t, "hero_spine4.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	swing := s.Animation("swing")
+	if s.Skin("default").Attachment(0, "hand").Name != "sword" || swing.Duration != 500*time.Millisecond || swing.Bones[0].Rotate[1].X != 45 {
+		t.Fatalf("swing %+v", swing)
+	}
+
+	if _, err := ParseSpine([]byte(`{"bones": [{"name": "a", "parent": "b"}]}`)); err == nil {
+		t.Fatal("parsed a bone of an unknown parent")
+	}
+}
+
+func TestDragonBones(t *testing.T) {
+	skeletons, err := ParseDragonBones(readFile(t, "hero_dragonbones.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := skeletons[0]
+	arm := s.Bones[s.Bone("arm")]
+	if s.Name != "hero" || arm.X != 5 || arm.Y != 20 || arm.Rotation != 90 {
+		t.Fatalf("arm %+v", arm)
+	}
+	hand := s.Skin("default").Attachment(0, "hand")
+	if s.Slots[0].Attachment != "hand" || hand.Name != "sword" || hand.Y != -2 {
+		t.Fatalf("hand %+v", hand)
+	}
+
+	st := NewState(s)
+	st.Play("swing", true)
+	if ev := st.Update(250 * time.Millisecond); ev != nil {
+		t.Fatalf("events %v", ev)
+	}
+	c := math.Sqrt(0.5)
+	if p := (lmath.Vec2{X: 1}).TransformPointVec2(st.World(s.Bone("arm"))); !near(p, lmath.Vec2{X: 5 + c, Y: 20 + c}) {
+		t.Fatalf("arm points to %v", p)
+	}
+	if ev := st.Update(100 * time.Millisecond); !reflect.DeepEqual(ev, []string{"whoosh"}) {
+		t.Fatalf("events %v", ev)
+	}
+	st.Update(200 * time.Millisecond)
+	if a := st.Attachment(0); a == nil || a.Name != "fist" {
+		t.Fatalf("attachment %+v", a)
+	}
+
+	if _, err := ParseDragonBones([]byte(`{"armature": []}`)); err == nil {
+		t.Fatal("parsed no armatures")
+	}
+}
@@ -0,0 +1,315 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package skeleton
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// The JSON representation of Spine skeletons.
+type (
+	spineBoneJSON struct {
+		Name     string   `json:"name"`
+		Parent   string   `json:"parent"`
+		Length   float64  `json:"length"`
+		X        float64  `json:"x"`
+		Y        float64  `json:"y"`
+		Rotation float64  `json:"rotation"`
+		ScaleX   *float64 `json:"scaleX"`
+		ScaleY   *float64 `json:"scaleY"`
+	}
+
+	spineSlotJSON struct {
+		Name       string `json:"name"`
+		Bone       string `json:"bone"`
+		Attachment string `json:"attachment"`
+	}
+
+	spineAttachmentJSON struct {
+		Type     string   `json:"type"`
+		Name     string   `json:"name"`
+		Path     string   `json:"path"`
+		X        float64  `json:"x"`
+		Y        float64  `json:"y"`
+		Rotation float64  `json:"rotation"`
+		ScaleX   *float64 `json:"scaleX"`
+		ScaleY   *float64 `json:"scaleY"`
+		Width    float64  `json:"width"`
+		Height   float64  `json:"height"`
+	}
+
+	// Attachments by slot name and then attachment name.
+	spineSkinJSON map[string]map[string]spineAttachmentJSON
+
+	spineKeyJSON struct {
+		Time  float64         `json:"time"`
+		Angle *float64        `json:"angle"`
+		Value *float64        `json:"value"`
+		X     *float64        `json:"x"`
+		Y     *float64        `json:"y"`
+		Curve json.RawMessage `json:"curve"`
+	}
+
+	spineAttachmentKeyJSON struct {
+		Time float64 `json:"time"`
+		Name *string `json:"name"`
+	}
+
+	spineEventKeyJSON struct {
+		Time float64 `json:"time"`
+		Name string  `json:"name"`
+	}
+
+	spineAnimationJSON struct {
+		Bones  map[string]map[string][]spineKeyJSON           `json:"bones"`
+		Slots  map[string]map[string][]spineAttachmentKeyJSON `json:"slots"`
+		Events []spineEventKeyJSON                            `json:"events"`
+	}
+
+	spineJSON struct {
+		Skeleton struct {
+			Spine string `json:"spine"`
+		} `json:"skeleton"`
+		Bones      []spineBoneJSON               `json:"bones"`
+		Slots      []spineSlotJSON               `json:"slots"`
+		Skins      json.RawMessage               `json:"skins"`
+		Animations map[string]spineAnimationJSON `json:"animations"`
+	}
+)
+
+// orOne returns *f, or one if f is nil.
+func orOne(f *float64) float64 {
+	if f == nil {
+		return 1
+	}
+	return *f
+}
+
+// orZero returns *f, or zero if f is nil.
+func orZero(f *float64) float64 {
+	if f == nil {
+		return 0
+	}
+	return *f
+}
+
+// seconds returns the duration of the given number of seconds.
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Round(s * float64(time.Second)))
+}
+
+// curve returns the curve of a Spine key. Only stepped curves are supported,
+// bezier curves are linear.
+func (k spineKeyJSON) curve() Curve {
+	if string(bytes.TrimSpace(k.Curve)) == `"stepped"` {
+		return Stepped
+	}
+	return Linear
+}
+
+// skins decodes the skins of a skeleton, either an object of skins by their
+// name (Spine 3.x) or an array of skins (Spine 4.x).
+func (j *spineJSON) skins() (names []string, skins []spineSkinJSON, err error) {
+	data := bytes.TrimSpace(j.Skins)
+	if len(data) == 0 {
+		return nil, nil, nil
+	}
+	if data[0] == '[' {
+		var arr []struct {
+			Name        string        `json:"name"`
+			Attachments spineSkinJSON `json:"attachments"`
+		}
+		if err := json.Unmarshal(data, &arr); err != nil {
+			return nil, nil, err
+		}
+		for _, s := range arr {
+			names = append(names, s.Name)
+			skins = append(skins, s.Attachments)
+		}
+		return names, skins, nil
+	}
+	var m map[string]spineSkinJSON
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, nil, err
+	}
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		skins = append(skins, m[name])
+	}
+	return names, skins, nil
+}
+
+func (j *spineJSON) animation(s *Skeleton, name string, ja spineAnimationJSON) (*Animation, error) {
+	a := &Animation{Name: name}
+	extend := func(t time.Duration) {
+		if t > a.Duration {
+			a.Duration = t
+		}
+	}
+
+	for boneName, timelines := range ja.Bones {
+		tl := BoneTimeline{Bone: s.Bone(boneName)}
+		if tl.Bone < 0 {
+			return nil, fmt.Errorf("skeleton: animation %q has unknown bone %q", name, boneName)
+		}
+		for kind, keys := range timelines {
+			var out []Key
+			for _, jk := range keys {
+				k := Key{Time: seconds(jk.Time), Curve: jk.curve()}
+				switch kind {
+				case "rotate":
+					// Spine 3.x names the value "angle", 4.x "value".
+					k.X = orZero(jk.Angle)
+					if jk.Value != nil {
+						k.X = *jk.Value
+					}
+				case "translate":
+					k.X, k.Y = orZero(jk.X), orZero(jk.Y)
+				case "scale":
+					k.X, k.Y = orOne(jk.X), orOne(jk.Y)
+				}
+				extend(k.Time)
+				out = append(out, k)
+			}
+			switch kind {
+			case "rotate":
+				tl.Rotate = out
+			case "translate":
+				tl.Translate = out
+			case "scale":
+				tl.Scale = out
+			}
+		}
+		a.Bones = append(a.Bones, tl)
+	}
+	sort.Slice(a.Bones, func(i, j int) bool {
+		return a.Bones[i].Bone < a.Bones[j].Bone
+	})
+
+	for slotName, timelines := range ja.Slots {
+		tl := SlotTimeline{Slot: s.Slot(slotName)}
+		if tl.Slot < 0 {
+			return nil, fmt.Errorf("skeleton: animation %q has unknown slot %q", name, slotName)
+		}
+		for _, jk := range timelines["attachment"] {
+			k := AttachmentKey{Time: seconds(jk.Time)}
+			if jk.Name != nil {
+				k.Attachment = *jk.Name
+			}
+			extend(k.Time)
+			tl.Attachments = append(tl.Attachments, k)
+		}
+		a.Slots = append(a.Slots, tl)
+	}
+	sort.Slice(a.Slots, func(i, j int) bool {
+		return a.Slots[i].Slot < a.Slots[j].Slot
+	})
+
+	for _, je := range ja.Events {
+		e := EventKey{Time: seconds(je.Time), Name: je.Name}
+		extend(e.Time)
+		a.Events = append(a.Events, e)
+	}
+	sort.SliceStable(a.Events, func(i, j int) bool {
+		return a.Events[i].Time < a.Events[j].Time
+	})
+	return a, nil
+}
+
+// ParseSpine parses a skeleton exported by Spine (versions 3.x and 4.x) in
+// JSON format. Attachments other than regions (e.g. meshes) are ignored.
+func ParseSpine(data []byte) (*Skeleton, error) {
+	var j spineJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, err
+	}
+	s := &Skeleton{}
+
+	for _, jb := range j.Bones {
+		b := &Bone{
+			Name:     jb.Name,
+			Parent:   -1,
+			X:        jb.X,
+			Y:        jb.Y,
+			Rotation: jb.Rotation,
+			ScaleX:   orOne(jb.ScaleX),
+			ScaleY:   orOne(jb.ScaleY),
+			Length:   jb.Length,
+		}
+		if jb.Parent != "" {
+			if b.Parent = s.Bone(jb.Parent); b.Parent < 0 {
+				return nil, fmt.Errorf("skeleton: bone %q has unknown parent %q", jb.Name, jb.Parent)
+			}
+		}
+		s.Bones = append(s.Bones, b)
+	}
+
+	for _, js := range j.Slots {
+		sl := &Slot{Name: js.Name, Bone: s.Bone(js.Bone), Attachment: js.Attachment}
+		if sl.Bone < 0 {
+			return nil, fmt.Errorf("skeleton: slot %q has unknown bone %q", js.Name, js.Bone)
+		}
+		s.Slots = append(s.Slots, sl)
+	}
+
+	names, skins, err := j.skins()
+	if err != nil {
+		return nil, err
+	}
+	for i, js := range skins {
+		sk := &Skin{Name: names[i]}
+		for slotName, attachments := range js {
+			slot := s.Slot(slotName)
+			if slot < 0 {
+				return nil, fmt.Errorf("skeleton: skin %q has unknown slot %q", sk.Name, slotName)
+			}
+			for name, ja := range attachments {
+				if ja.Type != "" && ja.Type != "region" {
+					continue
+				}
+				a := &Attachment{
+					Name:     name,
+					X:        ja.X,
+					Y:        ja.Y,
+					Rotation: ja.Rotation,
+					ScaleX:   orOne(ja.ScaleX),
+					ScaleY:   orOne(ja.ScaleY),
+					Width:    ja.Width,
+					Height:   ja.Height,
+				}
+				if ja.Name != "" {
+					a.Name = ja.Name
+				}
+				if ja.Path != "" {
+					a.Name = ja.Path
+				}
+				sk.add(slot, name, a)
+			}
+		}
+		s.Skins = append(s.Skins, sk)
+	}
+
+	animNames := make([]string, 0, len(j.Animations))
+	for name := range j.Animations {
+		animNames = append(animNames, name)
+	}
+	sort.Strings(animNames)
+	for _, name := range animNames {
+		a, err := j.animation(s, name, j.Animations[name])
+		if err != nil {
+			return nil, err
+		}
+		s.Animations = append(s.Animations, a)
+	}
+	return s, nil
+}
@@ -0,0 +1,247 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sprite
+
+import (
+	"fmt"
+	"image"
+	"time"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/lmath"
+)
+
+// Frame is a single frame of a sprite sheet.
+type Frame struct {
+	// The name of the frame, e.g. it's source file name.
+	Name string
+
+	// The region of the sheet image holding the frame, in pixels. If the
+	// frame is rotated the region holds the frame rotated 90 degrees
+	// clockwise, such that the width of the region is the frame's height.
+	Rect image.Rectangle
+
+	// Whether or not the frame is rotated within the sheet image.
+	Rotated bool
+
+	// The size of the frame in pixels, before it was trimmed.
+	SourceSize image.Point
+
+	// The position and size of the trimmed frame within the untrimmed one,
+	// in pixels. It is the rectangle of the source size for frames which are
+	// not trimmed.
+	Trim image.Rectangle
+
+	// The pivot (or anchor) of the frame, relative to the untrimmed frame
+	// where (0, 0) is it's top-left corner and (1, 1) it's bottom-right
+	// corner. It is the center of the frame, (0.5, 0.5), by default.
+	Pivot lmath.Vec2
+
+	// How long the frame is shown for when animated, or zero if the sheet
+	// does not specify it.
+	Duration time.Duration
+}
+
+// String returns a string representation of this frame.
+func (f *Frame) String() string {
+	return fmt.Sprintf("Frame(Name=%q, Rect=%v, Duration=%v)", f.Name, f.Rect, f.Duration)
+}
+
+// Direction is the direction an animation is played in.
+type Direction uint8
+
+const (
+	// Forward plays the frames in order.
+	Forward Direction = iota
+
+	// Reverse plays the frames in reverse order.
+	Reverse
+
+	// PingPong plays the frames in order and then in reverse order.
+	PingPong
+
+	// PingPongReverse plays the frames in reverse order and then in order.
+	PingPongReverse
+)
+
+// String returns the name of the direction, e.g. "forward".
+func (d Direction) String() string {
+	switch d {
+	case Forward:
+		return "forward"
+	case Reverse:
+		return "reverse"
+	case PingPong:
+		return "pingpong"
+	case PingPongReverse:
+		return "pingpong_reverse"
+	}
+	return fmt.Sprintf("Direction(%d)", d)
+}
+
+// Tag names the frames of an animation of a sprite sheet.
+type Tag struct {
+	// The name of the animation, e.g. "walk".
+	Name string
+
+	// The frames of the animation, as indices into the frames of the sheet.
+	Frames []int
+
+	// The direction in which the frames are played.
+	Direction Direction
+
+	// The number of times the animation is played, or zero if it loops
+	// forever.
+	Repeat int
+}
+
+// String returns a string representation of this tag.
+func (t *Tag) String() string {
+	return fmt.Sprintf("Tag(Name=%q, Frames=%v, Direction=%v)", t.Name, t.Frames, t.Direction)
+}
+
+// SliceKey is the region of a slice, starting at a frame.
+type SliceKey struct {
+	// The index of the first frame this key applies to, until the frame of
+	// the next key.
+	Frame int
+
+	// The region of the slice within the untrimmed frame, in pixels.
+	Bounds image.Rectangle
+
+	// The center region of nine-patch slices, relative to the bounds, or an
+	// empty rectangle if the slice is not a nine-patch.
+	Center image.Rectangle
+
+	// The pivot of the slice relative to the bounds in pixels, or nil if the
+	// slice has no pivot.
+	Pivot *image.Point
+}
+
+// Slice is a named region of the frames of a sprite sheet (e.g. a hitbox),
+// as exported by Aseprite.
+type Slice struct {
+	// The name of the slice.
+	Name string
+
+	// The keys of the slice, sorted by frame.
+	Keys []SliceKey
+}
+
+// Key returns the key of the slice for the given frame index, or nil if the
+// slice does not exist at that frame.
+func (s *Slice) Key(frame int) *SliceKey {
+	var k *SliceKey
+	for i := range s.Keys {
+		if s.Keys[i].Frame > frame {
+			break
+		}
+		k = &s.Keys[i]
+	}
+	return k
+}
+
+// Sheet is a sprite sheet: frames packed into a single image.
+type Sheet struct {
+	// The path of the sheet image, relative to the sheet file.
+	Image string
+
+	// The size of the sheet image in pixels.
+	Size image.Point
+
+	// The frames of the sheet, in order.
+	Frames []*Frame
+
+	// The animations of the sheet.
+	Tags []*Tag
+
+	// The slices of the sheet.
+	Slices []*Slice
+}
+
+// Frame returns the frame with the given name, or nil if there is none.
+func (s *Sheet) Frame(name string) *Frame {
+	for _, f := range s.Frames {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// Tag returns the animation with the given name, or nil if there is none.
+func (s *Sheet) Tag(name string) *Tag {
+	for _, t := range s.Tags {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// Slice returns the slice with the given name, or nil if there is none.
+func (s *Sheet) Slice(name string) *Slice {
+	for _, sl := range s.Slices {
+		if sl.Name == name {
+			return sl
+		}
+	}
+	return nil
+}
+
+// appendFrame appends a card of the frame of the sheet to the mesh, like
+// Sheet.Mesh does, transformed by m and at the given depth.
+func appendFrame(mesh *gfx.Mesh, s *Sheet, f *Frame, m lmath.Mat3, depth float32) {
+	// The corners of the card, relative to the pivot.
+	px := f.Pivot.X * float64(f.SourceSize.X)
+	py := f.Pivot.Y * float64(f.SourceSize.Y)
+	l := float64(f.Trim.Min.X) - px
+	r := float64(f.Trim.Max.X) - px
+	t := py - float64(f.Trim.Min.Y)
+	b := py - float64(f.Trim.Max.Y)
+
+	// The texture coordinates of the region.
+	w, h := float32(s.Size.X), float32(s.Size.Y)
+	u0, u1 := float32(f.Rect.Min.X)/w, float32(f.Rect.Max.X)/w
+	v0, v1 := float32(f.Rect.Min.Y)/h, float32(f.Rect.Max.Y)/h
+
+	// Texture coordinates of the top-left, bottom-left, bottom-right and
+	// top-right corners of the frame.
+	tl, bl, br, tr := gfx.TexCoord{u0, v0}, gfx.TexCoord{u0, v1}, gfx.TexCoord{u1, v1}, gfx.TexCoord{u1, v0}
+	if f.Rotated {
+		// Rotated 90 degrees clockwise in the sheet.
+		tl, bl, br, tr = gfx.TexCoord{u1, v0}, gfx.TexCoord{u0, v0}, gfx.TexCoord{u0, v1}, gfx.TexCoord{u1, v1}
+	}
+
+	addv := func(x, y float64) {
+		p := lmath.Vec2{X: x, Y: y}.TransformPointVec2(m)
+		mesh.Vertices = append(mesh.Vertices, gfx.Vec3{float32(p.X), depth, float32(p.Y)})
+	}
+
+	// Left triangle.
+	addv(l, t)
+	addv(l, b)
+	addv(r, b)
+
+	// Right triangle.
+	addv(l, t)
+	addv(r, b)
+	addv(r, t)
+
+	if len(mesh.TexCoords) == 0 {
+		mesh.TexCoords = make([]gfx.TexCoordSet, 1)
+	}
+	mesh.TexCoords[0].Slice = append(mesh.TexCoords[0].Slice, tl, bl, br, tl, br, tr)
+}
+
+// Mesh returns a new mesh for the given frame of the sheet: a card of the
+// trimmed frame, sized in pixels and textured with the frame's region of the
+// sheet image. The card faces the -Y axis with +Z up (like the cards of the
+// tmx package), and the frame's pivot is at the origin.
+func (s *Sheet) Mesh(f *Frame) *gfx.Mesh {
+	m := gfx.NewMesh()
+	appendFrame(m, s, f, lmath.Mat3Identity, 0)
+	return m
+}
@@ -0,0 +1,201 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sprite
+
+import (
+	"image"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/lmath"
+)
+
+func parseFile(t *testing.T, name string) *Sheet {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestAseprite(t *testing.T) {
+	s := parseFile(t, "hero_aseprite.json")
+	if s.Image != "hero.png" || s.Size != image.Pt(64, 24) || len(s.Frames) != 4 {
+		t.Fatalf("sheet %+v", s)
+	}
+
+	// Frames keep their order.
+	for i, f := range s.Frames {
+		if want := "hero " + string('0'+rune(i)) + ".aseprite"; f.Name != want {
+			t.Fatalf("frame %d is %q, want %q", i, f.Name, want)
+		}
+	}
+	f := s.Frame("hero 2.aseprite")
+	want := &Frame{
+		Name:       "hero 2.aseprite",
+		Rect:       image.Rect(32, 0, 44, 20),
+		SourceSize: image.Pt(16, 24),
+		Trim:       image.Rect(2, 4, 14, 24),
+		Pivot:      lmath.Vec2{X: 0.5, Y: 0.5},
+		Duration:   200 * time.Millisecond,
+	}
+	if !reflect.DeepEqual(f, want) {
+		t.Fatalf("got frame %+v, want %+v", f, want)
+	}
+
+	idle, attack := s.Tag("idle"), s.Tag("attack")
+	if idle == nil || !reflect.DeepEqual(idle.Frames, []int{0, 1}) || idle.Direction != Forward || idle.Repeat != 0 {
+		t.Fatalf("idle %v", idle)
+	}
+	if attack == nil || !reflect.DeepEqual(attack.Frames, []int{2, 3}) || attack.Direction != PingPong || attack.Repeat != 2 {
+		t.Fatalf("attack %v", attack)
+	}
+
+	hitbox := s.Slice("hitbox")
+	if hitbox == nil || hitbox.Keys[0].Frame != 0 || *hitbox.Keys[0].Pivot != image.Pt(6, 22) {
+		t.Fatalf("hitbox %+v", hitbox)
+	}
+	if k := hitbox.Key(3); k == nil || k.Bounds != image.Rect(4, 2, 14, 22) || k.Pivot != nil {
+		t.Fatalf("hitbox key %+v", k)
+	}
+	if k := s.Slice("panel").Key(0); k.Center != image.Rect(4, 4, 12, 12) {
+		t.Fatalf("panel key %+v", k)
+	}
+
+	// The array layout is the same.
+	s = parseFile(t, "hero_array.json")
+	if len(s.Frames) != 2 || s.Frames[1].Duration != 150*time.Millisecond || s.Tags[0].Direction != Reverse {
+		t.Fatalf("array sheet %+v", s)
+	}
+
+	for _, bad := range []string{
+		`{"frames": {}, "meta": {"app": "aseprite", "frameTags": [{"name": "a", "from": 0, "to": 1}]}}`,
+		`{"frames": [{}], "meta": {"app": "aseprite", "frameTags": [{"name": "a", "direction": "sideways"}]}}`,
+		`{"meta": {"app": "aseprite"}}`,
+		`{"frames": 1}`,
+	} {
+		if _, err := Parse([]byte(bad)); err == nil {
+			t.Errorf("parsed %s", bad)
+		}
+	}
+}
+
+func TestTexturePacker(t *testing.T) {
+	s := parseFile(t, "items_texturepacker.json")
+	if s.Image != "items.png" || len(s.Frames) != 3 {
+		t.Fatalf("sheet %+v", s)
+	}
+	if coin := s.Tag("coin"); coin == nil || !reflect.DeepEqual(coin.Frames, []int{0, 1}) {
+		t.Fatalf("coin %v", coin)
+	}
+
+	// The sword is rotated within the sheet.
+	sword := s.Frame("sword.png")
+	if !sword.Rotated || sword.Rect != image.Rect(32, 0, 64, 8) || sword.Pivot != (lmath.Vec2{X: 0.5, Y: 1}) {
+		t.Fatalf("sword %+v", sword)
+	}
+
+	if _, err := Parse([]byte(`{"frames": {}, "animations": {"a": ["b"]}}`)); err == nil {
+		t.Fatal("parsed an animation of unknown frames")
+	}
+}
+
+func TestMesh(t *testing.T) {
+	s := parseFile(t, "items_texturepacker.json")
+
+	// The sword is pivoted at it's bottom center, and trimmed to 8 pixels
+	// wide.
+	m := s.Mesh(s.Frame("sword.png"))
+	wantVerts := []gfx.Vec3{{-4, 0, 32}, {-4, 0, 0}, {4, 0, 0}, {-4, 0, 32}, {4, 0, 0}, {4, 0, 32}}
+	if !reflect.DeepEqual(m.Vertices, wantVerts) {
+		t.Fatalf("vertices %v", m.Vertices)
+	}
+
+	// It's top-left corner is at the top-right of the rotated region.
+	tc := m.TexCoords[0].Slice
+	if tc[0] != (gfx.TexCoord{1, 0}) || tc[1] != (gfx.TexCoord{0.5, 0}) || tc[2] != (gfx.TexCoord{0.5, 0.5}) {
+		t.Fatalf("texture coordinates %v", tc)
+	}
+
+	m = s.Mesh(s.Frames[1])
+	if m.Vertices[0] != (gfx.Vec3{-8, 0, 8}) || m.TexCoords[0].Slice[0] != (gfx.TexCoord{0.25, 0}) {
+		t.Fatalf("coin vertex %v, texture coordinate %v", m.Vertices[0], m.TexCoords[0].Slice[0])
+	}
+}
+
+func TestPlayer(t *testing.T) {
+	s := parseFile(t, "hero_aseprite.json")
+	p := NewPlayer(s)
+	p.Events = map[int][]string{3: {"hit"}}
+	if err := p.Play("jump"); err == nil {
+		t.Fatal("played an unknown animation")
+	}
+
+	// Idle loops forever, at double speed.
+	p.Speed = 2
+	if err := p.Play("idle"); err != nil {
+		t.Fatal(err)
+	}
+	if ev := p.Update(100 * time.Millisecond); ev != nil || p.Index() != 1 {
+		t.Fatalf("events %v, frame %d", ev, p.Index())
+	}
+	p.Update(30 * time.Millisecond)
+	if p.Index() != 0 || p.Done() {
+		t.Fatalf("frame %d, done %v", p.Index(), p.Done())
+	}
+
+	// Attack ping-pongs over two frames, twice.
+	p.Speed = 1
+	if err := p.Play("attack"); err != nil {
+		t.Fatal(err)
+	}
+	if ev := p.Update(250 * time.Millisecond); !reflect.DeepEqual(ev, []Event{{Name: "hit", Frame: 3}}) {
+		t.Fatalf("events %v", ev)
+	}
+	if ev := p.Update(350 * time.Millisecond); len(ev) != 1 || !p.Done() || p.Frame() != s.Frames[3] {
+		t.Fatalf("events %v, done %v, frame %v", ev, p.Done(), p.Frame())
+	}
+	if ev := p.Update(time.Second); ev != nil || p.Index() != 3 {
+		t.Fatalf("finished animation advanced to %d, events %v", p.Index(), ev)
+	}
+
+	// Ping-pong sequences don't repeat their ends.
+	tag := &Tag{Frames: []int{0, 1, 2, 3}, Direction: PingPongReverse}
+	if seq := tag.sequence(); !reflect.DeepEqual(seq, []int{3, 2, 1, 0, 1, 2}) {
+		t.Fatalf("sequence %v", seq)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	s := parseFile(t, "items_texturepacker.json")
+	b := NewBatch(s, nil)
+	sword := s.Frame("sword.png")
+	b.Add(sword, lmath.Mat3Identity)
+	b.Add(sword, Affine(lmath.Vec2{X: 10}, math.Pi/2, lmath.Vec2{X: 1, Y: 1}))
+	m := b.Meshes[0]
+	if b.Len() != 2 || len(m.Vertices) != 12 || len(m.TexCoords[0].Slice) != 12 {
+		t.Fatalf("%d sprites, %d vertices", b.Len(), len(m.Vertices))
+	}
+
+	// The second sword is rotated a quarter turn, in front of the first.
+	v := m.Vertices[6]
+	if math.Abs(float64(v.X+22)) > 1e-5 || v.Y != -0.01 || math.Abs(float64(v.Z+4)) > 1e-5 {
+		t.Fatalf("vertex %v", v)
+	}
+
+	b.Reset()
+	if b.Len() != 0 || len(m.Vertices) != 0 || !m.VerticesChanged {
+		t.Fatalf("reset batch has %d vertices", len(m.Vertices))
+	}
+}
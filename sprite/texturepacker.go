@@ -0,0 +1,62 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sprite
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"sort"
+)
+
+func (j *sheetJSON) texturePacker() (*Sheet, error) {
+	frames, err := decodeFrames(j.Frames)
+	if err != nil {
+		return nil, err
+	}
+	s := &Sheet{
+		Image:  j.Meta.Image,
+		Size:   image.Pt(j.Meta.Size.W, j.Meta.Size.H),
+		Frames: frames,
+	}
+
+	// Animations are lists of frame names, sorted by animation name as they
+	// are stored in an object.
+	names := make([]string, 0, len(j.Animations))
+	for name := range j.Animations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	index := make(map[string]int, len(frames))
+	for i, f := range frames {
+		index[f.Name] = i
+	}
+	for _, name := range names {
+		t := &Tag{Name: name}
+		for _, frame := range j.Animations[name] {
+			i, ok := index[frame]
+			if !ok {
+				return nil, fmt.Errorf("sprite: animation %q has unknown frame %q", name, frame)
+			}
+			t.Frames = append(t.Frames, i)
+		}
+		s.Tags = append(s.Tags, t)
+	}
+	return s, nil
+}
+
+// ParseTexturePacker parses a sprite sheet exported by TexturePacker in JSON
+// format, with either the "JSON (Hash)" or "JSON (Array)" data format (or
+// others based on them, e.g. PixiJS or Phaser). Frames keep their pivot, and
+// animations (as exported for PixiJS) become the tags of the sheet.
+//
+// TexturePacker does not export frame durations, they are zero.
+func ParseTexturePacker(data []byte) (*Sheet, error) {
+	var j sheetJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, err
+	}
+	return j.texturePacker()
+}
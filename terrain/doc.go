@@ -0,0 +1,36 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package terrain implements large heightmap terrains, drawn in chunks with
+// distance based levels of detail.
+//
+// A Heightmap is imported from a grayscale image (e.g. a 16-bit PNG) or a
+// RAW file, and turned into a Terrain of square chunks. Each chunk holds a
+// mesh for each level of detail (geo-mipmapping), where every level halves
+// the resolution of the one before it, and a skirt hangs from it's edges to
+// hide the cracks between neighbouring chunks of different levels:
+//
+//  hm, err := terrain.DecodePNG(f)
+//  if err != nil {
+//      // Handle error.
+//  }
+//  t := terrain.New(hm, terrain.Config{Spacing: 2, Height: 200})
+//  t.Shader = shaders.New(shaders.Terrain, glsl.GL2)
+//  t.Textures = []*gfx.Texture{splat, grass, rock, dirt, snow}
+//  for {
+//      t.Update(cam.Transform.Pos())
+//      t.Draw(canvas, image.Rect(0, 0, 0, 0), cam)
+//      ...
+//  }
+//
+// The splat map (the first texture, see shaders.Terrain) spans the whole
+// terrain, and it's RGBA channels weigh the four tiled layer textures.
+//
+// For gameplay, HeightAt returns the height of the terrain's surface at any
+// point, and Raycast finds where a ray (e.g. a bullet, or the mouse cursor)
+// hits it. Both use the most detailed level, regardless of the level drawn.
+//
+// As with the rest of the engine, the world is Z-up: the terrain lies on the
+// X/Y plane, starting at the origin.
+package terrain // import "azul3d.org/engine/terrain"
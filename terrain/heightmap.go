@@ -0,0 +1,110 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package terrain
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"io/ioutil"
+)
+
+// Heightmap is a grid of height samples, each in the range zero to one.
+//
+// Sample (x, y) lies at X = x and Y = y in units of the terrain's spacing.
+// Images are flipped when they are decoded, such that the top row of the
+// image becomes the far (+Y) edge of the terrain, as seen from above.
+type Heightmap struct {
+	// The number of samples along the X and Y axes.
+	Width, Depth int
+
+	// The samples, row by row, starting at Y = 0.
+	Heights []float32
+}
+
+// At returns the sample at (x, y), clamped to the edges of the heightmap.
+func (h *Heightmap) At(x, y int) float32 {
+	if x < 0 {
+		x = 0
+	} else if x >= h.Width {
+		x = h.Width - 1
+	}
+	if y < 0 {
+		y = 0
+	} else if y >= h.Depth {
+		y = h.Depth - 1
+	}
+	return h.Heights[y*h.Width+x]
+}
+
+// NewHeightmap returns a new flat heightmap with the given number of samples
+// along the X and Y axes.
+func NewHeightmap(width, depth int) *Heightmap {
+	return &Heightmap{
+		Width:   width,
+		Depth:   depth,
+		Heights: make([]float32, width*depth),
+	}
+}
+
+// FromImage returns a heightmap of the luminance of the given image, using
+// all sixteen bits of precision of 16-bit grayscale images.
+func FromImage(img image.Image) *Heightmap {
+	b := img.Bounds()
+	h := NewHeightmap(b.Dx(), b.Dy())
+	for y := 0; y < h.Depth; y++ {
+		row := h.Heights[(h.Depth-1-y)*h.Width:]
+		for x := 0; x < h.Width; x++ {
+			g := color.Gray16Model.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.Gray16)
+			row[x] = float32(g.Y) / 0xFFFF
+		}
+	}
+	return h
+}
+
+// DecodePNG decodes a heightmap from a grayscale (preferably 16-bit) PNG
+// image, see FromImage.
+func DecodePNG(r io.Reader) (*Heightmap, error) {
+	img, err := png.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	return FromImage(img), nil
+}
+
+// DecodeRAW decodes a heightmap from a RAW file of the given size, as
+// exported by most terrain tools: rows of unsigned samples with no header,
+// starting at the top row. The sample size (8 or 16-bit, little-endian) is
+// determined from the length of the file.
+func DecodeRAW(r io.Reader, width, depth int) (*Heightmap, error) {
+	if width <= 0 || depth <= 0 {
+		return nil, errors.New("terrain: invalid RAW heightmap size")
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	n := width * depth
+	if len(data) != n && len(data) != n*2 {
+		return nil, fmt.Errorf("terrain: RAW heightmap of %d bytes is not %dx%d", len(data), width, depth)
+	}
+	h := NewHeightmap(width, depth)
+	for y := 0; y < depth; y++ {
+		row := h.Heights[(depth-1-y)*width:]
+		for x := 0; x < width; x++ {
+			i := y*width + x
+			if len(data) == n {
+				row[x] = float32(data[i]) / 0xFF
+			} else {
+				row[x] = float32(binary.LittleEndian.Uint16(data[i*2:])) / 0xFFFF
+			}
+		}
+	}
+	return h, nil
+}
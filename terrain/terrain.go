@@ -0,0 +1,415 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package terrain
+
+import (
+	"image"
+	"math"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/lmath"
+)
+
+// Config configures a terrain. Zero fields take their default values.
+type Config struct {
+	// Spacing is the distance between adjacent samples along the X and Y
+	// axes, in world units. The default is 1.
+	Spacing float64
+
+	// Height is the height of a sample of one, in world units. The default
+	// is 1.
+	Height float64
+
+	// ChunkSize is the number of quads along each side of a chunk at the most
+	// detailed level, typically a power of two. The default is 32.
+	ChunkSize int
+
+	// LODs is the number of levels of detail of each chunk, at most
+	// log2(ChunkSize)+1. The default is 4.
+	LODs int
+
+	// LODDistance is the distance from the camera within which chunks are
+	// drawn at the most detailed level, each doubling of the distance beyond
+	// it drops one level. The default is 64.
+	LODDistance float64
+}
+
+// withDefaults returns the configuration with zero fields set to their
+// default values.
+func (c Config) withDefaults() Config {
+	if c.Spacing <= 0 {
+		c.Spacing = 1
+	}
+	if c.Height == 0 {
+		c.Height = 1
+	}
+	if c.ChunkSize <= 0 {
+		c.ChunkSize = 32
+	}
+	if c.LODs <= 0 {
+		c.LODs = 4
+	}
+	max := 1
+	for s := c.ChunkSize; s > 1; s /= 2 {
+		max++
+	}
+	if c.LODs > max {
+		c.LODs = max
+	}
+	if c.LODDistance <= 0 {
+		c.LODDistance = 64
+	}
+	return c
+}
+
+// Chunk is a square region of a terrain.
+type Chunk struct {
+	// The object drawn for the chunk, whose mesh is that of the current level
+	// of detail. It is drawn with the shader, state and textures of the
+	// terrain.
+	Object *gfx.Object
+
+	// The world space bounds of the chunk's surface.
+	Bounds lmath.Rect3
+
+	// LOD is the current level of detail of the chunk, where zero is the most
+	// detailed.
+	LOD int
+
+	// The mesh of each level of detail.
+	lods []*gfx.Mesh
+}
+
+// Terrain is a heightmap terrain, split into chunks.
+//
+// A terrain is not safe for access from multiple goroutines concurrently.
+type Terrain struct {
+	// The configuration of the terrain, with defaults applied. It must not
+	// be modified.
+	Config
+
+	// The heightmap of the terrain. It must not be modified.
+	Heightmap *Heightmap
+
+	// The shader, state, and textures that the chunks are drawn with, e.g.
+	// shaders.Terrain along with it's splat map and layers.
+	Shader   *gfx.Shader
+	State    *gfx.State
+	Textures []*gfx.Texture
+
+	// The chunks of the terrain, row by row.
+	Chunks []*Chunk
+}
+
+// Bounds returns the world space bounds of the terrain's surface.
+func (t *Terrain) Bounds() lmath.Rect3 {
+	var b lmath.Rect3
+	for i, c := range t.Chunks {
+		if i == 0 {
+			b = c.Bounds
+		} else {
+			b = b.Union(c.Bounds)
+		}
+	}
+	return b
+}
+
+// Update selects the level of detail of each chunk given the world space
+// position of the camera.
+func (t *Terrain) Update(eye lmath.Vec3) {
+	for _, c := range t.Chunks {
+		d := math.Sqrt(c.Bounds.SqDistToPoint(eye))
+		lod := 0
+		if d > t.LODDistance {
+			lod = int(math.Log2(d / t.LODDistance))
+		}
+		if lod >= len(c.lods) {
+			lod = len(c.lods) - 1
+		}
+		c.LOD = lod
+		c.Object.Meshes[0] = c.lods[lod]
+		c.Object.CachedBounds = nil
+	}
+}
+
+// Draw draws each chunk of the terrain to the canvas, using the given
+// camera.
+func (t *Terrain) Draw(canvas gfx.Canvas, r image.Rectangle, cam gfx.Camera) {
+	for _, c := range t.Chunks {
+		c.Object.Shader = t.Shader
+		c.Object.State = t.State
+		c.Object.Textures = t.Textures
+		canvas.Draw(r, c.Object, cam)
+	}
+}
+
+// Destroy destroys the chunks of the terrain and their meshes. The shader,
+// state and textures are left as-is.
+func (t *Terrain) Destroy() {
+	for _, c := range t.Chunks {
+		c.Object.Textures = nil
+		c.Object.Destroy()
+		for _, m := range c.lods {
+			m.Destroy()
+		}
+	}
+	t.Chunks = nil
+}
+
+// pos returns the world space position of the sample at (x, y).
+func (t *Terrain) pos(x, y int) lmath.Vec3 {
+	return lmath.Vec3{
+		X: float64(x) * t.Spacing,
+		Y: float64(y) * t.Spacing,
+		Z: float64(t.Heightmap.At(x, y)) * t.Height,
+	}
+}
+
+// HeightAt returns the height of the terrain's surface at the given world
+// space X and Y coordinates, as drawn at the most detailed level. If the
+// point is outside of the terrain, ok is false.
+func (t *Terrain) HeightAt(x, y float64) (z float64, ok bool) {
+	h := t.Heightmap
+	fx, fy := x/t.Spacing, y/t.Spacing
+	if fx < 0 || fy < 0 || fx > float64(h.Width-1) || fy > float64(h.Depth-1) {
+		return 0, false
+	}
+	cx := int(math.Min(math.Floor(fx), float64(h.Width-2)))
+	cy := int(math.Min(math.Floor(fy), float64(h.Depth-2)))
+	u, v := fx-float64(cx), fy-float64(cy)
+
+	// Interpolate across the same triangles that the mesh is made of.
+	a := float64(h.At(cx, cy))
+	b := float64(h.At(cx+1, cy))
+	c := float64(h.At(cx+1, cy+1))
+	d := float64(h.At(cx, cy+1))
+	if u >= v {
+		z = a + u*(b-a) + v*(c-b)
+	} else {
+		z = a + v*(d-a) + u*(c-d)
+	}
+	return z * t.Height, true
+}
+
+// Raycast returns the distance along the ray at which it first hits the
+// terrain's surface (at the most detailed level), in units of the ray
+// direction's length.
+func (t *Terrain) Raycast(r lmath.Ray) (dist float64, ok bool) {
+	h := t.Heightmap
+	if len(t.Chunks) == 0 {
+		return 0, false
+	}
+	enter, ok := r.IntersectRect3(t.Bounds())
+	if !ok {
+		return 0, false
+	}
+
+	// Walk the cells that the ray passes over, nearest first, in units of
+	// samples.
+	p := r.At(enter)
+	gx, gy := p.X/t.Spacing, p.Y/t.Spacing
+	cx := int(math.Max(0, math.Min(math.Floor(gx), float64(h.Width-2))))
+	cy := int(math.Max(0, math.Min(math.Floor(gy), float64(h.Depth-2))))
+	step := func(g, dir float64, c int) (s int, next, delta float64) {
+		switch {
+		case dir > 0:
+			return 1, enter + (float64(c+1)-g)/dir, 1 / dir
+		case dir < 0:
+			return -1, enter + (float64(c)-g)/dir, -1 / dir
+		}
+		return 0, math.Inf(1), math.Inf(1)
+	}
+	sx, nextX, deltaX := step(gx, r.Dir.X/t.Spacing, cx)
+	sy, nextY, deltaY := step(gy, r.Dir.Y/t.Spacing, cy)
+
+	for cx >= 0 && cy >= 0 && cx < h.Width-1 && cy < h.Depth-1 {
+		a, b := t.pos(cx, cy), t.pos(cx+1, cy)
+		c, d := t.pos(cx+1, cy+1), t.pos(cx, cy+1)
+		dist = math.Inf(1)
+		if d1, _, _, ok := r.IntersectTriangle(a, b, c); ok {
+			dist = d1
+		}
+		if d2, _, _, ok := r.IntersectTriangle(a, c, d); ok {
+			dist = math.Min(dist, d2)
+		}
+		if !math.IsInf(dist, 1) {
+			return dist, true
+		}
+		if nextX < nextY {
+			cx += sx
+			nextX += deltaX
+		} else {
+			if sy == 0 {
+				break
+			}
+			cy += sy
+			nextY += deltaY
+		}
+	}
+	return 0, false
+}
+
+// Geometry returns the triangles of the terrain's surface at the most
+// detailed level, e.g. for building a navigation mesh (see nav.Build) or a
+// physics collision shape.
+func (t *Terrain) Geometry() (vertices []lmath.Vec3, indices []uint32) {
+	h := t.Heightmap
+	vertices = make([]lmath.Vec3, 0, h.Width*h.Depth)
+	for y := 0; y < h.Depth; y++ {
+		for x := 0; x < h.Width; x++ {
+			vertices = append(vertices, t.pos(x, y))
+		}
+	}
+	for y := 0; y+1 < h.Depth; y++ {
+		for x := 0; x+1 < h.Width; x++ {
+			a := uint32(y*h.Width + x)
+			b, c, d := a+1, a+1+uint32(h.Width), a+uint32(h.Width)
+			indices = append(indices, a, b, c, a, c, d)
+		}
+	}
+	return
+}
+
+// samples returns the sample coordinates from min to max (inclusive) with the
+// given step, always including max.
+func samples(min, max, step int) []int {
+	var s []int
+	for v := min; v < max; v += step {
+		s = append(s, v)
+	}
+	return append(s, max)
+}
+
+// chunkMesh builds the mesh of the chunk covering samples [x0, x1] by
+// [y0, y1] at the given level of detail, with a skirt of the given depth.
+func (t *Terrain) chunkMesh(x0, y0, x1, y1, lod int, skirt float64) *gfx.Mesh {
+	h := t.Heightmap
+	cols := samples(x0, x1, 1<<uint(lod))
+	rows := samples(y0, y1, 1<<uint(lod))
+	nc := len(cols)
+
+	m := gfx.NewMesh()
+	m.TexCoords = make([]gfx.TexCoordSet, 1)
+	add := func(x, y int, dz float64) uint32 {
+		p := t.pos(x, y)
+		m.Vertices = append(m.Vertices, gfx.Vec3{
+			X: float32(p.X),
+			Y: float32(p.Y),
+			Z: float32(p.Z - dz),
+		})
+
+		// The normal, from the central differences of the heightmap.
+		s := t.Height / (2 * t.Spacing)
+		n, _ := lmath.Vec3{
+			X: float64(h.At(x-1, y)-h.At(x+1, y)) * s,
+			Y: float64(h.At(x, y-1)-h.At(x, y+1)) * s,
+			Z: 1,
+		}.Normalized()
+		m.Normals = append(m.Normals, gfx.ConvertVec3(n))
+
+		// The texture coordinates span the whole terrain, with the top of
+		// the image at the far (+Y) edge.
+		m.TexCoords[0].Slice = append(m.TexCoords[0].Slice, gfx.TexCoord{
+			U: float32(x) / float32(h.Width-1),
+			V: 1 - float32(y)/float32(h.Depth-1),
+		})
+		return uint32(len(m.Vertices) - 1)
+	}
+
+	// The surface.
+	for _, y := range rows {
+		for _, x := range cols {
+			add(x, y, 0)
+		}
+	}
+	for j := 0; j+1 < len(rows); j++ {
+		for i := 0; i+1 < nc; i++ {
+			a := uint32(j*nc + i)
+			b, c, d := a+1, a+1+uint32(nc), a+uint32(nc)
+			m.Indices = append(m.Indices, a, b, c, a, c, d)
+		}
+	}
+
+	// The skirt around the edges, with both windings such that it is visible
+	// from either side.
+	type sample struct{ x, y int }
+	var edge []sample
+	for _, x := range cols {
+		edge = append(edge, sample{x, y0})
+	}
+	for _, y := range rows[1:] {
+		edge = append(edge, sample{x1, y})
+	}
+	for i := nc - 2; i >= 0; i-- {
+		edge = append(edge, sample{cols[i], y1})
+	}
+	for i := len(rows) - 2; i >= 0; i-- {
+		edge = append(edge, sample{x0, rows[i]})
+	}
+	var prevTop, prevBottom uint32
+	for i, s := range edge {
+		top := add(s.x, s.y, 0)
+		bottom := add(s.x, s.y, skirt)
+		if i > 0 {
+			m.Indices = append(m.Indices,
+				prevTop, prevBottom, bottom, prevTop, bottom, top,
+				prevTop, bottom, prevBottom, prevTop, top, bottom,
+			)
+		}
+		prevTop, prevBottom = top, bottom
+	}
+	return m
+}
+
+// New returns a new terrain of the given heightmap, which must have at least
+// two samples along each axis.
+func New(h *Heightmap, c Config) *Terrain {
+	t := &Terrain{
+		Config:    c.withDefaults(),
+		Heightmap: h,
+		State:     gfx.NewState(),
+	}
+	if h.Width < 2 || h.Depth < 2 {
+		panic("terrain: heightmap has less than two samples along an axis")
+	}
+
+	size := t.ChunkSize
+	for y0 := 0; y0 < h.Depth-1; y0 += size {
+		for x0 := 0; x0 < h.Width-1; x0 += size {
+			x1 := int(math.Min(float64(x0+size), float64(h.Width-1)))
+			y1 := int(math.Min(float64(y0+size), float64(h.Depth-1)))
+
+			// The bounds of the chunk's surface.
+			minZ, maxZ := math.Inf(1), math.Inf(-1)
+			for y := y0; y <= y1; y++ {
+				for x := x0; x <= x1; x++ {
+					z := float64(h.At(x, y)) * t.Height
+					minZ, maxZ = math.Min(minZ, z), math.Max(maxZ, z)
+				}
+			}
+			bounds := lmath.Rect3{
+				Min: lmath.Vec3{X: float64(x0) * t.Spacing, Y: float64(y0) * t.Spacing, Z: minZ},
+				Max: lmath.Vec3{X: float64(x1) * t.Spacing, Y: float64(y1) * t.Spacing, Z: maxZ},
+			}
+
+			// The skirt hangs deep enough to cover the largest possible
+			// crack, which is bounded by the chunk's height range.
+			skirt := maxZ - minZ + t.Spacing
+			meshBounds := bounds
+			meshBounds.Min.Z -= skirt
+
+			chunk := &Chunk{Bounds: bounds}
+			for lod := 0; lod < t.LODs; lod++ {
+				m := t.chunkMesh(x0, y0, x1, y1, lod, skirt)
+				m.AABB = meshBounds
+				chunk.lods = append(chunk.lods, m)
+			}
+			chunk.Object = gfx.NewObject()
+			chunk.Object.Meshes = []*gfx.Mesh{chunk.lods[0]}
+			t.Chunks = append(t.Chunks, chunk)
+		}
+	}
+	return t
+}
@@ -0,0 +1,145 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package terrain
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"testing"
+
+	"azul3d.org/engine/lmath"
+)
+
+func TestDecodeRAW(t *testing.T) {
+	// 16-bit, 2x2: the first row of the file is the far (+Y) edge.
+	h, err := DecodeRAW(bytes.NewReader([]byte{0xFF, 0xFF, 0, 0, 0, 0, 0, 0}), 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.At(0, 1) != 1 || h.At(0, 0) != 0 {
+		t.Fatalf("got %v", h.Heights)
+	}
+
+	// 8-bit.
+	h, err = DecodeRAW(bytes.NewReader([]byte{0, 0xFF, 0, 0}), 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.At(1, 1) != 1 {
+		t.Fatalf("got %v", h.Heights)
+	}
+
+	if _, err := DecodeRAW(bytes.NewReader([]byte{0, 0, 0}), 2, 2); err == nil {
+		t.Fatal("decoded RAW heightmap of the wrong size")
+	}
+}
+
+func TestDecodePNG(t *testing.T) {
+	img := image.NewGray16(image.Rect(0, 0, 3, 2))
+	img.SetGray16(2, 0, color.Gray16{Y: 0x8000})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	h, err := DecodePNG(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Width != 3 || h.Depth != 2 {
+		t.Fatalf("got %dx%d", h.Width, h.Depth)
+	}
+	if want := float32(0x8000) / 0xFFFF; h.At(2, 1) != want {
+		t.Fatalf("got %v, want %v", h.At(2, 1), want)
+	}
+}
+
+// slope returns a 65x33 heightmap which rises along the X axis.
+func slope() *Heightmap {
+	h := NewHeightmap(65, 33)
+	for y := 0; y < h.Depth; y++ {
+		for x := 0; x < h.Width; x++ {
+			h.Heights[y*h.Width+x] = float32(x) / 64
+		}
+	}
+	return h
+}
+
+func TestTerrain(t *testing.T) {
+	ter := New(slope(), Config{Spacing: 2, Height: 64, ChunkSize: 16})
+	if len(ter.Chunks) != 4*2 {
+		t.Fatalf("got %d chunks, want 8", len(ter.Chunks))
+	}
+	if ter.LODs != 4 {
+		t.Fatalf("got %d LODs, want 4", ter.LODs)
+	}
+	b := ter.Bounds()
+	if b.Max != (lmath.Vec3{X: 128, Y: 64, Z: 64}) {
+		t.Fatalf("bounds %v", b)
+	}
+
+	// Each level halves the resolution: 17x17, 9x9, 5x5 and 3x3 samples,
+	// plus the skirt.
+	c := ter.Chunks[0]
+	for lod, n := range []int{17, 9, 5, 3} {
+		m := c.lods[lod]
+		skirt := 4*(n-1) + 1
+		if len(m.Vertices) != n*n+2*skirt {
+			t.Errorf("LOD %d: %d vertices", lod, len(m.Vertices))
+		}
+	}
+
+	// The near chunk is the most detailed, the far one the least.
+	ter.Update(lmath.Vec3{X: -1, Y: -1})
+	if c.LOD != 0 {
+		t.Fatalf("near chunk LOD %d", c.LOD)
+	}
+	ter.Update(lmath.Vec3{X: -1000, Y: -1000})
+	if c.LOD != 3 || c.Object.Meshes[0] != c.lods[3] {
+		t.Fatalf("far chunk LOD %d", c.LOD)
+	}
+
+	vertices, indices := ter.Geometry()
+	if len(vertices) != 65*33 || len(indices) != 64*32*6 {
+		t.Fatalf("geometry of %d vertices and %d indices", len(vertices), len(indices))
+	}
+}
+
+func TestTerrainQueries(t *testing.T) {
+	ter := New(slope(), Config{Spacing: 2, Height: 64})
+	z, ok := ter.HeightAt(33, 10)
+	if !ok || math.Abs(z-16.5) > 1e-6 {
+		t.Fatalf("HeightAt = %v, %v; want 16.5", z, ok)
+	}
+	if _, ok := ter.HeightAt(-1, 10); ok {
+		t.Fatal("HeightAt outside of the terrain")
+	}
+
+	// Straight down.
+	r := lmath.Ray{Origin: lmath.Vec3{X: 33, Y: 10, Z: 100}, Dir: lmath.Vec3{Z: -1}}
+	dist, ok := ter.Raycast(r)
+	if !ok || math.Abs(r.At(dist).Z-16.5) > 1e-6 {
+		t.Fatalf("Raycast = %v, %v", dist, ok)
+	}
+
+	// At a shallow angle, hitting the rising slope.
+	r = lmath.Ray{Origin: lmath.Vec3{X: -10, Y: 5, Z: 40}, Dir: lmath.Vec3{X: 1, Y: 0.1}}
+	dist, ok = ter.Raycast(r)
+	if !ok {
+		t.Fatal("Raycast missed the slope")
+	}
+	p := r.At(dist)
+	if z, _ := ter.HeightAt(p.X, p.Y); math.Abs(z-p.Z) > 1e-6 || math.Abs(p.X-80) > 1e-6 {
+		t.Fatalf("hit %v, surface at %v", p, z)
+	}
+
+	// Over the terrain, never reaching it.
+	r = lmath.Ray{Origin: lmath.Vec3{X: -10, Y: 5, Z: 100}, Dir: lmath.Vec3{X: 1}}
+	if _, ok := ter.Raycast(r); ok {
+		t.Fatal("Raycast hit the terrain from above it")
+	}
+}
@@ -0,0 +1,319 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"fmt"
+	"unicode"
+)
+
+// Direction is the base direction of a paragraph of text.
+type Direction uint8
+
+const (
+	// Auto uses the direction of the first strongly directional character of
+	// the paragraph, or left-to-right if it has none.
+	Auto Direction = iota
+
+	// LeftToRight is the direction of e.g. Latin text.
+	LeftToRight
+
+	// RightToLeft is the direction of e.g. Arabic and Hebrew text.
+	RightToLeft
+)
+
+// String returns the name of the direction, e.g. "LeftToRight".
+func (d Direction) String() string {
+	switch d {
+	case Auto:
+		return "Auto"
+	case LeftToRight:
+		return "LeftToRight"
+	case RightToLeft:
+		return "RightToLeft"
+	}
+	return fmt.Sprintf("Direction(%d)", d)
+}
+
+// bidiClass is the bidirectional character type of a character, see Unicode
+// Standard Annex #9.
+type bidiClass uint8
+
+const (
+	bidiL   bidiClass = iota // Left-to-right.
+	bidiR                    // Right-to-left.
+	bidiAL                   // Arabic letter.
+	bidiEN                   // European number.
+	bidiES                   // European separator.
+	bidiET                   // European terminator.
+	bidiAN                   // Arabic number.
+	bidiCS                   // Common separator.
+	bidiNSM                  // Nonspacing mark.
+	bidiBN                   // Boundary neutral.
+	bidiS                    // Segment separator.
+	bidiWS                   // Whitespace.
+	bidiON                   // Other neutral.
+)
+
+// isArabic tells if the character is of the Arabic script (including it's
+// presentation forms).
+func isArabic(r rune) bool {
+	return (r >= 0x0600 && r <= 0x06FF) || (r >= 0x0750 && r <= 0x077F) ||
+		(r >= 0x08A0 && r <= 0x08FF) || (r >= 0xFB50 && r <= 0xFDFF) ||
+		(r >= 0xFE70 && r <= 0xFEFF)
+}
+
+// classOf returns the bidirectional type of the character. It approximates
+// the Unicode character database for the scripts this package shapes.
+func classOf(r rune) bidiClass {
+	switch {
+	case unicode.In(r, unicode.Mn, unicode.Me):
+		return bidiNSM
+	case r >= 0x0660 && r <= 0x0669, r == 0x066B, r == 0x066C:
+		return bidiAN
+	case r >= 0x06F0 && r <= 0x06F9:
+		return bidiEN
+	case isArabic(r):
+		return bidiAL
+	case unicode.Is(unicode.Hebrew, r):
+		return bidiR
+	case unicode.IsDigit(r):
+		return bidiEN
+	case r == '+' || r == '-':
+		return bidiES
+	case r == '#' || r == '%' || r == '°' || unicode.Is(unicode.Sc, r):
+		return bidiET
+	case r == ',' || r == '.' || r == ':' || r == '/' || r == 0xA0:
+		return bidiCS
+	case unicode.Is(unicode.Cf, r):
+		return bidiBN
+	case r == '\t':
+		return bidiS
+	case unicode.IsSpace(r):
+		return bidiWS
+	case unicode.IsLetter(r) || unicode.Is(unicode.Mc, r):
+		return bidiL
+	}
+	return bidiON
+}
+
+// paragraphDirection returns the direction of the first strongly directional
+// character, or left-to-right if there is none.
+func paragraphDirection(runes []rune) Direction {
+	for _, r := range runes {
+		switch classOf(r) {
+		case bidiL:
+			return LeftToRight
+		case bidiR, bidiAL:
+			return RightToLeft
+		}
+	}
+	return LeftToRight
+}
+
+// bidiLevels returns the embedding level of each character of a line of
+// text with the given base direction (which may not be Auto), following the
+// implicit rules of the Unicode bidirectional algorithm. Explicit embeddings,
+// overrides and isolates are not supported.
+func bidiLevels(runes []rune, base Direction) []uint8 {
+	n := len(runes)
+	baseLevel := uint8(0)
+	sor := bidiL
+	if base == RightToLeft {
+		baseLevel = 1
+		sor = bidiR
+	}
+	types := make([]bidiClass, n)
+	for i, r := range runes {
+		types[i] = classOf(r)
+	}
+
+	// W1: nonspacing marks take the type of the previous character.
+	prev := sor
+	for i, t := range types {
+		if t == bidiNSM {
+			types[i] = prev
+		} else if t != bidiBN {
+			prev = t
+		}
+	}
+
+	// W2 and W3: European numbers after Arabic letters are Arabic numbers,
+	// and Arabic letters are right-to-left.
+	strong := sor
+	for i, t := range types {
+		switch t {
+		case bidiL, bidiR, bidiAL:
+			strong = t
+		case bidiEN:
+			if strong == bidiAL {
+				types[i] = bidiAN
+			}
+		}
+	}
+	for i, t := range types {
+		if t == bidiAL {
+			types[i] = bidiR
+		}
+	}
+
+	// W4: a single separator between two numbers of the same type joins
+	// them.
+	for i := 1; i+1 < n; i++ {
+		a, b := types[i-1], types[i+1]
+		switch {
+		case types[i] == bidiES && a == bidiEN && b == bidiEN:
+			types[i] = bidiEN
+		case types[i] == bidiCS && a == b && (a == bidiEN || a == bidiAN):
+			types[i] = a
+		}
+	}
+
+	// W5: terminators adjacent to European numbers are European numbers.
+	for i := 0; i < n; i++ {
+		if types[i] != bidiET {
+			continue
+		}
+		j := i
+		for j < n && types[j] == bidiET {
+			j++
+		}
+		if (i > 0 && types[i-1] == bidiEN) || (j < n && types[j] == bidiEN) {
+			for k := i; k < j; k++ {
+				types[k] = bidiEN
+			}
+		}
+		i = j - 1
+	}
+
+	// W6 and W7: remaining separators and terminators are neutral, and
+	// European numbers after left-to-right text are left-to-right.
+	strong = sor
+	for i, t := range types {
+		switch t {
+		case bidiES, bidiET, bidiCS:
+			types[i] = bidiON
+		case bidiL, bidiR:
+			strong = t
+		case bidiEN:
+			if strong == bidiL {
+				types[i] = bidiL
+			}
+		}
+	}
+
+	// N1 and N2: neutrals take the direction of the surrounding text if it
+	// is the same on both sides, or else the base direction.
+	dirOf := func(t bidiClass) (bidiClass, bool) {
+		switch t {
+		case bidiL:
+			return bidiL, true
+		case bidiR, bidiEN, bidiAN:
+			return bidiR, true
+		}
+		return 0, false
+	}
+	for i := 0; i < n; i++ {
+		if _, ok := dirOf(types[i]); ok {
+			continue
+		}
+		j := i
+		for j < n {
+			if _, ok := dirOf(types[j]); ok {
+				break
+			}
+			j++
+		}
+		before, after := sor, sor
+		if i > 0 {
+			before, _ = dirOf(types[i-1])
+		}
+		if j < n {
+			after, _ = dirOf(types[j])
+		}
+		t := sor
+		if before == after {
+			t = before
+		}
+		for k := i; k < j; k++ {
+			types[k] = t
+		}
+		i = j - 1
+	}
+
+	// I1 and I2: resolve the levels.
+	levels := make([]uint8, n)
+	for i, t := range types {
+		l := baseLevel
+		switch {
+		case l%2 == 0 && t == bidiR:
+			l++
+		case l%2 == 0 && (t == bidiAN || t == bidiEN):
+			l += 2
+		case l%2 == 1 && (t == bidiL || t == bidiEN || t == bidiAN):
+			l++
+		}
+		levels[i] = l
+	}
+
+	// L1: trailing whitespace and segment separators are at the base level.
+	for i := n - 1; i >= 0; i-- {
+		c := classOf(runes[i])
+		if c != bidiWS && c != bidiBN && c != bidiS {
+			break
+		}
+		levels[i] = baseLevel
+	}
+	for i, r := range runes {
+		if classOf(r) == bidiS {
+			levels[i] = baseLevel
+		}
+	}
+	return levels
+}
+
+// visualOrder returns the indices of items at the given levels in visual
+// (left-to-right) order, reversing runs of items as in rule L2 of the
+// Unicode bidirectional algorithm.
+func visualOrder(levels []uint8) []int {
+	order := make([]int, len(levels))
+	var highest, lowestOdd uint8 = 0, 255
+	for i, l := range levels {
+		order[i] = i
+		if l > highest {
+			highest = l
+		}
+		if l%2 == 1 && l < lowestOdd {
+			lowestOdd = l
+		}
+	}
+	for level := highest; level >= lowestOdd && level > 0; level-- {
+		for i := 0; i < len(order); i++ {
+			if levels[order[i]] < level {
+				continue
+			}
+			j := i
+			for j < len(order) && levels[order[j]] >= level {
+				j++
+			}
+			for a, b := i, j-1; a < b; a, b = a+1, b-1 {
+				order[a], order[b] = order[b], order[a]
+			}
+			i = j
+		}
+	}
+	return order
+}
+
+// mirrors maps characters to their mirrored forms, used in right-to-left
+// text.
+var mirrors = map[rune]rune{
+	'(': ')', ')': '(',
+	'[': ']', ']': '[',
+	'{': '}', '}': '{',
+	'<': '>', '>': '<',
+	'«': '»', '»': '«',
+	'‹': '›', '›': '‹',
+}
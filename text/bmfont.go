@@ -0,0 +1,274 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/png" // BMFont pages are typically PNG images.
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ErrFormat is returned when parsing data which is not a BMFont font.
+var ErrFormat = errors.New("text: unknown font format")
+
+// bmRecord is a single tag of a BMFont description, e.g. a char, with it's
+// attributes. The text and XML formats share the same tags and attributes.
+type bmRecord struct {
+	tag   string
+	attrs map[string]string
+}
+
+// int returns the integer attribute with the given name, or zero if it is
+// missing.
+func (r bmRecord) int(name string) (int, error) {
+	s, ok := r.attrs[name]
+	if !ok {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("text: %s has invalid %s %q", r.tag, name, s)
+	}
+	return v, nil
+}
+
+// ints returns the integer attributes with the given names.
+func (r bmRecord) ints(names ...string) ([]int, error) {
+	out := make([]int, len(names))
+	for i, name := range names {
+		v, err := r.int(name)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+// parseBMText parses the records of the text format, which has one tag per
+// line followed by key=value attributes, e.g.:
+//
+//  page id=0 file="font_0.png"
+func parseBMText(data []byte) ([]bmRecord, error) {
+	var records []bmRecord
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" {
+			continue
+		}
+		r := bmRecord{attrs: make(map[string]string)}
+		i := strings.IndexAny(line, " \t")
+		if i < 0 {
+			r.tag = line
+			records = append(records, r)
+			continue
+		}
+		r.tag, line = line[:i], line[i:]
+		for {
+			line = strings.TrimLeft(line, " \t")
+			if line == "" {
+				break
+			}
+			eq := strings.IndexByte(line, '=')
+			if eq < 0 {
+				return nil, fmt.Errorf("text: invalid attribute %q of %s", line, r.tag)
+			}
+			key := line[:eq]
+			line = line[eq+1:]
+			var value string
+			if strings.HasPrefix(line, `"`) {
+				end := strings.IndexByte(line[1:], '"')
+				if end < 0 {
+					return nil, fmt.Errorf("text: unterminated %s of %s", key, r.tag)
+				}
+				value, line = line[1:end+1], line[end+2:]
+			} else {
+				end := strings.IndexAny(line, " \t")
+				if end < 0 {
+					end = len(line)
+				}
+				value, line = line[:end], line[end:]
+			}
+			r.attrs[key] = value
+		}
+		records = append(records, r)
+	}
+	return records, s.Err()
+}
+
+// parseBMXML parses the records of the XML format, where each tag is an
+// element.
+func parseBMXML(data []byte) ([]bmRecord, error) {
+	var records []bmRecord
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if se, ok := t.(xml.StartElement); ok {
+			r := bmRecord{tag: se.Name.Local, attrs: make(map[string]string, len(se.Attr))}
+			for _, a := range se.Attr {
+				r.attrs[a.Name.Local] = a.Value
+			}
+			records = append(records, r)
+		}
+	}
+}
+
+// ParseBMFont parses a font description generated by AngelCode's BMFont, in
+// either it's text or XML format. The page images are not loaded (see
+// LoadBMFont).
+func ParseBMFont(data []byte) (*Font, error) {
+	trimmed := bytes.TrimSpace(data)
+	var (
+		records []bmRecord
+		err     error
+	)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		records, err = parseBMXML(trimmed)
+	case bytes.HasPrefix(trimmed, []byte("BMF")):
+		return nil, errors.New("text: binary BMFont files are not supported")
+	default:
+		records, err = parseBMText(trimmed)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	f := &Font{
+		Glyphs:  make(map[rune]*Glyph),
+		Kerning: make(map[KernPair]int),
+	}
+	var hasCommon bool
+	for _, r := range records {
+		switch r.tag {
+		case "info":
+			f.Face = r.attrs["face"]
+			size, err := r.int("size")
+			if err != nil {
+				return nil, err
+			}
+			// Negative sizes match the height of characters, rather than
+			// cells.
+			if size < 0 {
+				size = -size
+			}
+			f.Size = size
+		case "common":
+			v, err := r.ints("lineHeight", "base", "scaleW", "scaleH")
+			if err != nil {
+				return nil, err
+			}
+			f.LineHeight, f.Base = v[0], v[1]
+			f.PageSize = image.Pt(v[2], v[3])
+			hasCommon = true
+		case "page":
+			id, err := r.int("id")
+			if err != nil {
+				return nil, err
+			}
+			if id < 0 || id > len(f.Pages) {
+				return nil, fmt.Errorf("text: page %d is out of order", id)
+			}
+			if id == len(f.Pages) {
+				f.Pages = append(f.Pages, "")
+			}
+			f.Pages[id] = r.attrs["file"]
+		case "char":
+			v, err := r.ints("id", "x", "y", "width", "height", "xoffset", "yoffset", "xadvance", "page")
+			if err != nil {
+				return nil, err
+			}
+			g := &Glyph{
+				Rune:    rune(v[0]),
+				Rect:    image.Rect(v[1], v[2], v[1]+v[3], v[2]+v[4]),
+				Offset:  image.Pt(v[5], v[6]),
+				Advance: v[7],
+				Page:    v[8],
+			}
+			f.Glyphs[g.Rune] = g
+		case "kerning":
+			v, err := r.ints("first", "second", "amount")
+			if err != nil {
+				return nil, err
+			}
+			f.Kerning[KernPair{rune(v[0]), rune(v[1])}] = v[2]
+		}
+	}
+	if !hasCommon {
+		return nil, ErrFormat
+	}
+	for _, g := range f.Glyphs {
+		if g.Page < 0 || g.Page >= len(f.Pages) {
+			return nil, fmt.Errorf("text: glyph %q has invalid page %d", g.Rune, g.Page)
+		}
+	}
+	return f, nil
+}
+
+// LoadBMFont parses the font description from r as ParseBMFont does, and then
+// loads it's page images by opening them relative to dir. Page images may be
+// in any format registered with the image package (PNG is registered by this
+// package).
+func LoadBMFont(r io.Reader, dir string) (*Font, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	f, err := ParseBMFont(data)
+	if err != nil {
+		return nil, err
+	}
+	f.Images = make([]image.Image, len(f.Pages))
+	for i, page := range f.Pages {
+		img, err := loadImage(filepath.Join(dir, filepath.FromSlash(page)))
+		if err != nil {
+			return nil, err
+		}
+		f.Images[i] = img
+	}
+	return f, nil
+}
+
+// LoadBMFontFile loads the font description at the given path and it's page
+// images, see LoadBMFont.
+func LoadBMFontFile(path string) (*Font, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadBMFont(file, filepath.Dir(path))
+}
+
+// loadImage loads the image file at the given path.
+func loadImage(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("text: %s: %v", path, err)
+	}
+	return img, nil
+}
@@ -0,0 +1,32 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package text implements text layout and rendering with bitmap fonts.
+//
+// A Font is a set of glyphs packed into one or more page images, such as the
+// fonts generated by AngelCode's BMFont (and the many tools which export it's
+// format, e.g. Hiero or Glyph Designer). Bitmap fonts are a light alternative
+// to rasterizing glyphs at runtime, well suited to mobile devices and to pixel
+// fonts:
+//
+//  font, err := text.LoadBMFontFile("fonts/arial.fnt")
+//  if err != nil {
+//      // Handle error.
+//  }
+//  t := text.New(font, nil)
+//  t.Set("Hello, world!")
+//  for _, o := range t.Objects {
+//      canvas.Draw(image.Rect(0, 0, 0, 0), o, camera)
+//  }
+//
+// Text is laid out in pixels in the X/Z plane, with +Z up and the origin at
+// the left of the baseline of the first line, like the cards of the sprite
+// and tmx packages.
+//
+// Lines are shaped before they are laid out (see Font.Shape): right-to-left
+// scripts are reordered by the Unicode bidirectional algorithm, Arabic
+// letters take their contextual forms, Devanagari vowel signs are reordered
+// and combining marks, conjuncts and emoji sequences are kept together in
+// clusters drawn with the font's ligature glyphs (see Font.Ligatures).
+package text // import "azul3d.org/engine/text"
@@ -0,0 +1,95 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"fmt"
+	"image"
+)
+
+// Glyph is a single glyph of a font.
+type Glyph struct {
+	// The character of the glyph.
+	Rune rune
+
+	// The index of the page image holding the glyph.
+	Page int
+
+	// The region of the page image holding the glyph, in pixels.
+	Rect image.Rectangle
+
+	// The offset from the pen position to the top-left corner of the glyph
+	// image, in pixels with +Y down. The pen is at the top of the line, such
+	// that the baseline is Font.Base pixels below it.
+	Offset image.Point
+
+	// How far the pen advances after the glyph, in pixels.
+	Advance int
+}
+
+// String returns a string representation of this glyph.
+func (g *Glyph) String() string {
+	return fmt.Sprintf("Glyph(Rune=%q, Page=%d, Rect=%v, Advance=%d)", g.Rune, g.Page, g.Rect, g.Advance)
+}
+
+// KernPair is a pair of consecutive characters.
+type KernPair struct {
+	Left, Right rune
+}
+
+// Font is a bitmap font: glyphs packed into page images.
+type Font struct {
+	// The name of the font face, e.g. "Arial".
+	Face string
+
+	// The size of the font in pixels.
+	Size int
+
+	// The distance between the tops of consecutive lines, in pixels.
+	LineHeight int
+
+	// The distance from the top of a line to it's baseline, in pixels.
+	Base int
+
+	// The size of the page images in pixels.
+	PageSize image.Point
+
+	// The file names of the page images, relative to the font file.
+	Pages []string
+
+	// The page images, or nil if they are not loaded.
+	Images []image.Image
+
+	// The glyphs of the font, by character.
+	Glyphs map[rune]*Glyph
+
+	// The kerning of pairs of characters: the amount the pen moves by between
+	// them, in pixels.
+	Kerning map[KernPair]int
+
+	// Glyphs of character sequences, by the string of the sequence, used by
+	// shaping in place of the glyphs of the individual characters: e.g.
+	// conjuncts of Indic scripts or emoji ZWJ sequences and flags. May be
+	// nil.
+	Ligatures map[string]*Glyph
+}
+
+// Glyph returns the glyph of the given character. If the font has no such
+// glyph the replacement character (U+FFFD) or, failing that, a question mark
+// is returned. If the font has neither nil is returned.
+func (f *Font) Glyph(r rune) *Glyph {
+	if g, ok := f.Glyphs[r]; ok {
+		return g
+	}
+	if g, ok := f.Glyphs['�']; ok {
+		return g
+	}
+	return f.Glyphs['?']
+}
+
+// Kern returns the kerning between the two characters, in pixels.
+func (f *Font) Kern(left, right rune) int {
+	return f.Kerning[KernPair{left, right}]
+}
@@ -0,0 +1,71 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"image"
+	"strings"
+)
+
+// Quad is a glyph placed by a layout.
+type Quad struct {
+	// The glyph.
+	Glyph *Glyph
+
+	// The position of the top-left corner of the glyph image, in pixels with
+	// +Y down and relative to the top-left of the text.
+	Pos image.Point
+}
+
+// Bounds returns the rectangle covered by the glyph image, in pixels with +Y
+// down and relative to the top-left of the text.
+func (q Quad) Bounds() image.Rectangle {
+	return image.Rectangle{Max: q.Glyph.Rect.Size()}.Add(q.Pos)
+}
+
+// Layout lays out the given string with the font, returning a quad for each
+// visible glyph in visual order. Lines are separated by newlines and shaped
+// with their direction determined automatically, see LayoutDir.
+func (f *Font) Layout(s string) []Quad {
+	return f.LayoutDir(s, Auto)
+}
+
+// LayoutDir lays out the given string with the font, returning a quad for
+// each visible glyph in visual order. Lines are separated by newlines and
+// each is shaped using the given base direction (see Shape). Lines are
+// aligned to the left regardless of their direction.
+func (f *Font) LayoutDir(s string, dir Direction) []Quad {
+	var quads []Quad
+	for i, line := range lines(s) {
+		glyphs, _ := f.Shape(line, dir)
+		for _, g := range glyphs {
+			if g.Glyph.Rect.Empty() {
+				continue
+			}
+			pen := image.Pt(g.X, i*f.LineHeight)
+			quads = append(quads, Quad{Glyph: g.Glyph, Pos: pen.Add(g.Glyph.Offset)})
+		}
+	}
+	return quads
+}
+
+// Measure returns the size of the given string laid out with the font, in
+// pixels: the advance of it's widest line and the height of it's lines.
+func (f *Font) Measure(s string) image.Point {
+	var size image.Point
+	lines := lines(s)
+	for _, line := range lines {
+		if _, w := f.Shape(line, Auto); w > size.X {
+			size.X = w
+		}
+	}
+	size.Y = len(lines) * f.LineHeight
+	return size
+}
+
+// lines splits the string into lines, dropping carriage returns.
+func lines(s string) []string {
+	return strings.Split(strings.Replace(s, "\r", "", -1), "\n")
+}
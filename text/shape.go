@@ -0,0 +1,395 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import "unicode"
+
+// ShapedGlyph is a glyph placed on a line by shaping.
+type ShapedGlyph struct {
+	// The glyph.
+	Glyph *Glyph
+
+	// The pen position of the glyph, in pixels from the left of the line.
+	X int
+
+	// The index (in characters) within the line of the first character of
+	// the cluster the glyph belongs to.
+	Cluster int
+}
+
+// Arabic joining types, see the ArabicShaping.txt file of the Unicode
+// character database.
+const (
+	joinNone        = iota // Non-joining.
+	joinRight              // Joins to the preceding character only.
+	joinDual               // Joins on both sides.
+	joinCausing            // Causes joining (tatweel), has no forms.
+	joinTransparent        // Skipped by joining (marks).
+)
+
+// arabicForms maps Arabic letters to their isolated, final, initial and
+// medial presentation forms; zero where the letter has no such form.
+var arabicForms = func() map[rune][4]rune {
+	// The number of presentation forms of each letter from U+0621 to U+064A,
+	// allocated consecutively from U+FE80 in that order.
+	counts := []int{
+		1, 2, 2, 2, 2, 4, 2, 4, 2, 4, 4, 4, 4, 4, 2, 2, // U+0621-0630
+		2, 2, 4, 4, 4, 4, 4, 4, 4, 4, 0, 0, 0, 0, 0, 0, // U+0631-0640
+		4, 4, 4, 4, 4, 4, 4, 2, 2, 4, // U+0641-064A
+	}
+	forms := make(map[rune][4]rune, len(counts))
+	next := rune(0xFE80)
+	for i, n := range counts {
+		if n == 0 {
+			continue
+		}
+		var f [4]rune
+		for j := 0; j < n; j++ {
+			f[j] = next
+			next++
+		}
+		forms[0x0621+rune(i)] = f
+	}
+	return forms
+}()
+
+// lamAlef maps alefs to the isolated form of their ligature with a preceding
+// lam; the final form follows it.
+var lamAlef = map[rune]rune{
+	0x0622: 0xFEF5,
+	0x0623: 0xFEF7,
+	0x0625: 0xFEF9,
+	0x0627: 0xFEFB,
+}
+
+// latinLigatures are the standard Latin ligatures, longest first, used if the
+// font has glyphs for them.
+var latinLigatures = []struct {
+	seq string
+	lig rune
+}{
+	{"ffi", 0xFB03},
+	{"ffl", 0xFB04},
+	{"ff", 0xFB00},
+	{"fi", 0xFB01},
+	{"fl", 0xFB02},
+}
+
+// joiningType returns the Arabic joining type of the character.
+func joiningType(r rune) int {
+	switch {
+	case unicode.In(r, unicode.Mn, unicode.Me), r == 0x200B:
+		return joinTransparent
+	case r == 0x0640 || r == 0x200D:
+		return joinCausing
+	}
+	f, ok := arabicForms[r]
+	switch {
+	case !ok || f[1] == 0:
+		return joinNone
+	case f[2] == 0:
+		return joinRight
+	}
+	return joinDual
+}
+
+// isIgnorable tells if the character is default ignorable: a format
+// character (e.g. a zero width joiner) or variation selector, which is not
+// drawn unless the font has a glyph of a sequence including it.
+func isIgnorable(r rune) bool {
+	return unicode.Is(unicode.Cf, r) || (r >= 0xFE00 && r <= 0xFE0F) ||
+		(r >= 0xE0100 && r <= 0xE01EF)
+}
+
+// isMark tells if the character is a nonspacing mark, drawn over the
+// preceding character without advancing the pen.
+func isMark(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me)
+}
+
+// Characters used by clustering and reordering.
+const (
+	virama   = 0x094D
+	iMatra   = 0x093F
+	zwj      = 0x200D
+	riFirst  = 0x1F1E6
+	riLast   = 0x1F1FF
+	modFirst = 0x1F3FB
+	modLast  = 0x1F3FF
+)
+
+func isConsonant(r rune) bool {
+	return (r >= 0x0915 && r <= 0x0939) || (r >= 0x0958 && r <= 0x095F)
+}
+
+func isRegional(r rune) bool {
+	return r >= riFirst && r <= riLast
+}
+
+// extends tells if the character continues the cluster ending with prev
+// (whose cluster so far contains ri regional indicators).
+func extends(prev, r rune, ri int) bool {
+	switch {
+	case isMark(r), unicode.Is(unicode.Mc, r), isIgnorable(r):
+		return true
+	case r >= modFirst && r <= modLast:
+		return true
+	case prev == zwj:
+		// Emoji ZWJ sequences.
+		return true
+	case prev == virama && isConsonant(r):
+		// Devanagari conjuncts.
+		return true
+	case isRegional(prev) && isRegional(r):
+		// Flags are pairs of regional indicators.
+		return ri%2 == 1
+	}
+	return false
+}
+
+// clusters splits the characters into grapheme clusters, returning the index
+// of the first character of each.
+func clusters(runes []rune) []int {
+	var (
+		starts []int
+		ri     int
+	)
+	for i, r := range runes {
+		if i == 0 || !extends(runes[i-1], r, ri) {
+			starts = append(starts, i)
+			ri = 0
+		}
+		if isRegional(r) {
+			ri++
+		}
+	}
+	return starts
+}
+
+// has tells if the font has a glyph of the character, without falling back.
+func (f *Font) has(r rune) bool {
+	_, ok := f.Glyphs[r]
+	return ok
+}
+
+// joinForms substitutes the Arabic presentation forms (and lam-alef
+// ligatures) the font has glyphs for into the given characters, in logical
+// order. Characters replaced by ligatures become -1.
+func (f *Font) joinForms(runes []rune) {
+	n := len(runes)
+	types := make([]int, n)
+	for i, r := range runes {
+		types[i] = joiningType(r)
+	}
+	// neighbour returns the index of the nearest non-transparent character in
+	// the given direction, or -1.
+	neighbour := func(i, step int) int {
+		for i += step; i >= 0 && i < n; i += step {
+			if types[i] != joinTransparent {
+				return i
+			}
+		}
+		return -1
+	}
+	forms := make([]int, n)
+	for i, t := range types {
+		if t != joinRight && t != joinDual {
+			continue
+		}
+		p, q := neighbour(i, -1), neighbour(i, 1)
+		prev := p >= 0 && (types[p] == joinDual || types[p] == joinCausing)
+		next := t == joinDual && q >= 0 && types[q] != joinNone
+		switch {
+		case prev && next:
+			forms[i] = 3
+		case next:
+			forms[i] = 2
+		case prev:
+			forms[i] = 1
+		}
+	}
+	for i, r := range runes {
+		if types[i] != joinRight && types[i] != joinDual {
+			continue
+		}
+		if r == 0x0644 {
+			// Lam followed by alef forms a ligature, which joins to the
+			// preceding character only.
+			if q := neighbour(i, 1); q >= 0 && q == i+1 {
+				if lig, ok := lamAlef[runes[q]]; ok {
+					if forms[i] == 3 || forms[i] == 1 {
+						lig++
+					}
+					if f.has(lig) {
+						runes[i], runes[q] = lig, -1
+						continue
+					}
+				}
+			}
+		}
+		if form := arabicForms[r][forms[i]]; form != 0 && f.has(form) {
+			runes[i] = form
+		}
+	}
+}
+
+// ligate substitutes the standard Latin ligatures the font has glyphs for
+// into the given characters. Characters replaced by ligatures become -1.
+func (f *Font) ligate(runes []rune) {
+	for i := range runes {
+	next:
+		for _, l := range latinLigatures {
+			if !f.has(l.lig) {
+				continue
+			}
+			j := i
+			for _, r := range l.seq {
+				if j >= len(runes) || runes[j] != r {
+					continue next
+				}
+				j++
+			}
+			runes[i] = l.lig
+			for k := i + 1; k < j; k++ {
+				runes[k] = -1
+			}
+			break
+		}
+	}
+}
+
+// reorder reorders the characters of a Devanagari cluster into visual order:
+// the short i vowel sign, written after the consonants, is drawn before them.
+func reorder(cluster []rune) {
+	for i := len(cluster) - 1; i > 0; i-- {
+		if cluster[i] == iMatra && isConsonant(cluster[0]) {
+			copy(cluster[1:i+1], cluster[:i])
+			cluster[0] = iMatra
+			return
+		}
+	}
+}
+
+// clusterGlyphs returns the glyphs of a cluster: the longest sequences the
+// font has ligature glyphs for, or else the glyphs of it's characters
+// (skipping ignorable characters the font has no glyph for).
+func (f *Font) clusterGlyphs(cluster []rune) []*Glyph {
+	var glyphs []*Glyph
+	for i := 0; i < len(cluster); {
+		n := 0
+		if f.Ligatures != nil {
+			for j := len(cluster); j > i+1; j-- {
+				if g, ok := f.Ligatures[string(cluster[i:j])]; ok {
+					glyphs = append(glyphs, g)
+					n = j - i
+					break
+				}
+			}
+		}
+		if n > 0 {
+			i += n
+			continue
+		}
+		r := cluster[i]
+		i++
+		if isIgnorable(r) && !f.has(r) {
+			continue
+		}
+		if g := f.Glyph(r); g != nil {
+			glyphs = append(glyphs, g)
+		}
+	}
+	return glyphs
+}
+
+// Shape shapes a single line of text with the font, returning it's glyphs in
+// visual (left-to-right) order and the advance width of the line in pixels.
+//
+// Characters are grouped into grapheme clusters (a character with it's
+// combining marks, Devanagari conjuncts, emoji ZWJ, modifier and flag
+// sequences) which are drawn using the font's ligature glyphs if it has
+// them. Arabic letters take the contextual forms the font has presentation
+// form glyphs for, and the lines are reordered by the Unicode bidirectional
+// algorithm using the given base direction (mirroring brackets in
+// right-to-left runs). Nonspacing marks are drawn at the pen position of
+// their cluster without advancing it, and consecutive glyphs are kerned.
+//
+// Shaping is a simplified, table based, approximation of OpenType shaping:
+// fonts are not queried for GSUB and GPOS features, Devanagari reph forms and
+// explicit bidirectional embeddings are not supported.
+func (f *Font) Shape(s string, dir Direction) ([]ShapedGlyph, int) {
+	runes := []rune(s)
+	if len(runes) == 0 {
+		return nil, 0
+	}
+	if dir == Auto {
+		dir = paragraphDirection(runes)
+	}
+	levels := bidiLevels(runes, dir)
+
+	shaped := make([]rune, len(runes))
+	copy(shaped, runes)
+	f.joinForms(shaped)
+	f.ligate(shaped)
+
+	// Build the clusters in logical order.
+	type cluster struct {
+		start  int
+		glyphs []*Glyph
+	}
+	var (
+		cs      []cluster
+		csLevel []uint8
+	)
+	starts := clusters(runes)
+	for i, start := range starts {
+		end := len(runes)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		var c []rune
+		for _, r := range shaped[start:end] {
+			if r < 0 {
+				continue
+			}
+			if levels[start]%2 == 1 {
+				if m, ok := mirrors[r]; ok && f.has(m) {
+					r = m
+				}
+			}
+			c = append(c, r)
+		}
+		if len(c) == 0 {
+			continue
+		}
+		reorder(c)
+		cs = append(cs, cluster{start: start, glyphs: f.clusterGlyphs(c)})
+		csLevel = append(csLevel, levels[start])
+	}
+
+	// Place the clusters in visual order.
+	var (
+		glyphs []ShapedGlyph
+		pen    int
+		prev   rune = -1
+	)
+	for _, i := range visualOrder(csLevel) {
+		base := pen
+		for _, g := range cs[i].glyphs {
+			if isMark(g.Rune) && len(glyphs) > 0 {
+				glyphs = append(glyphs, ShapedGlyph{Glyph: g, X: base, Cluster: cs[i].start})
+				continue
+			}
+			if prev >= 0 {
+				pen += f.Kern(prev, g.Rune)
+			}
+			base = pen
+			glyphs = append(glyphs, ShapedGlyph{Glyph: g, X: pen, Cluster: cs[i].start})
+			pen += g.Advance
+			prev = g.Rune
+		}
+	}
+	return glyphs, pen
+}
@@ -0,0 +1,180 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/lmath"
+)
+
+var (
+	glslVert = []byte(`
+#version 120
+
+attribute vec3 Vertex;
+attribute vec4 Color;
+attribute vec2 TexCoord0;
+
+uniform mat4 MVP;
+
+varying vec4 frontColor;
+varying vec2 tc0;
+
+void main()
+{
+	frontColor = Color;
+	tc0 = TexCoord0;
+	gl_Position = MVP * vec4(Vertex, 1.0);
+}
+`)
+
+	glslFrag = []byte(`
+#version 120
+
+varying vec4 frontColor;
+varying vec2 tc0;
+
+uniform sampler2D Texture0;
+
+void main()
+{
+	gl_FragColor = frontColor * texture2D(Texture0, tc0);
+}
+`)
+)
+
+// Shader is the shader used to draw text, which tints the glyphs of the page
+// images by the text's color.
+var Shader = &gfx.Shader{
+	Name: "text.Shader",
+	GLSL: &gfx.GLSLSources{
+		Vertex:   glslVert,
+		Fragment: glslFrag,
+	},
+}
+
+// Textures returns new textures of the loaded page images of the font, which
+// are filtered linearly. Pixel fonts should set the filters of the textures
+// to gfx.Nearest before they are loaded.
+func (f *Font) Textures() []*gfx.Texture {
+	textures := make([]*gfx.Texture, len(f.Images))
+	for i, img := range f.Images {
+		t := gfx.NewTexture()
+		t.Source = img
+		t.Bounds = img.Bounds()
+		t.WrapU = gfx.Clamp
+		t.WrapV = gfx.Clamp
+		t.MinFilter = gfx.Linear
+		t.MagFilter = gfx.Linear
+		if i < len(f.Pages) {
+			t.Label = f.Pages[i]
+		}
+		textures[i] = t
+	}
+	return textures
+}
+
+// Text is a string drawn with a font: one object for each page of the font,
+// with a mesh of the glyphs of the string on that page.
+type Text struct {
+	// The font the text is drawn with.
+	Font *Font
+
+	// The color of the text, applied the next time the string is set.
+	Color gfx.Color
+
+	// The objects of the text, one for each page of the font. Their
+	// transforms are parented to the transform of the text.
+	Objects []*gfx.Object
+
+	// The transform of the text.
+	Transform *gfx.Transform
+
+	s string
+}
+
+// String returns the string of the text.
+func (t *Text) String() string {
+	return t.s
+}
+
+// Set sets the string of the text, rebuilding it's meshes (see Font.Layout).
+func (t *Text) Set(s string) {
+	t.s = s
+	for _, o := range t.Objects {
+		m := o.Meshes[0]
+		m.Vertices = m.Vertices[:0]
+		m.Colors = m.Colors[:0]
+		m.TexCoords[0].Slice = m.TexCoords[0].Slice[:0]
+	}
+
+	size := t.Font.PageSize
+	for _, q := range t.Font.Layout(s) {
+		if q.Glyph.Page >= len(t.Objects) {
+			continue
+		}
+		m := t.Objects[q.Glyph.Page].Meshes[0]
+		b := q.Bounds()
+
+		// +Y is down in the layout, and the origin is at the baseline.
+		base := t.Font.Base
+		l, r := float32(b.Min.X), float32(b.Max.X)
+		top, bottom := float32(base-b.Min.Y), float32(base-b.Max.Y)
+		m.Vertices = append(m.Vertices,
+			gfx.Vec3{l, 0, top}, gfx.Vec3{l, 0, bottom}, gfx.Vec3{r, 0, bottom},
+			gfx.Vec3{l, 0, top}, gfx.Vec3{r, 0, bottom}, gfx.Vec3{r, 0, top},
+		)
+		for i := 0; i < 6; i++ {
+			m.Colors = append(m.Colors, t.Color)
+		}
+
+		rect := q.Glyph.Rect
+		u0, u1 := float32(rect.Min.X)/float32(size.X), float32(rect.Max.X)/float32(size.X)
+		v0, v1 := float32(rect.Min.Y)/float32(size.Y), float32(rect.Max.Y)/float32(size.Y)
+		m.TexCoords[0].Slice = append(m.TexCoords[0].Slice,
+			gfx.TexCoord{u0, v0}, gfx.TexCoord{u0, v1}, gfx.TexCoord{u1, v1},
+			gfx.TexCoord{u0, v0}, gfx.TexCoord{u1, v1}, gfx.TexCoord{u1, v0},
+		)
+	}
+
+	for _, o := range t.Objects {
+		m := o.Meshes[0]
+		m.VerticesChanged = true
+		m.ColorsChanged = true
+		m.TexCoords[0].Changed = true
+		m.AABB = lmath.Rect3Zero
+		o.CachedBounds = nil
+	}
+}
+
+// New returns a new, empty, text drawn with the given font using the given
+// textures of it's pages. If textures is nil, the textures are created from
+// the loaded page images of the font (see Font.Textures). The text is white.
+func New(f *Font, textures []*gfx.Texture) *Text {
+	if textures == nil {
+		textures = f.Textures()
+	}
+	t := &Text{
+		Font:      f,
+		Color:     gfx.Color{1, 1, 1, 1},
+		Transform: gfx.NewTransform(),
+	}
+	for _, tex := range textures {
+		m := gfx.NewMesh()
+		m.Dynamic = true
+		m.TexCoords = make([]gfx.TexCoordSet, 1)
+
+		o := gfx.NewObject()
+		o.Shader = Shader
+		o.Meshes = []*gfx.Mesh{m}
+		o.Textures = []*gfx.Texture{tex}
+		o.Transform.SetParent(t.Transform)
+		o.State = gfx.NewState()
+		o.State.FaceCulling = gfx.NoFaceCulling
+		o.State.AlphaMode = gfx.AlphaBlend
+		t.Objects = append(t.Objects, o)
+	}
+	return t
+}
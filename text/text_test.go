@@ -0,0 +1,172 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"image"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"azul3d.org/engine/gfx"
+)
+
+func TestBMFont(t *testing.T) {
+	f, err := LoadBMFontFile(filepath.Join("testdata", "pixel.fnt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Face != "Pixel Sans" || f.Size != 10 || f.LineHeight != 10 || f.Base != 8 || f.PageSize != image.Pt(32, 16) {
+		t.Fatalf("font %+v", f)
+	}
+	if len(f.Images) != 1 || f.Images[0].Bounds() != image.Rect(0, 0, 32, 16) {
+		t.Fatalf("pages %v", f.Pages)
+	}
+	g := f.Glyph('g')
+	want := &Glyph{Rune: 'g', Rect: image.Rect(12, 0, 17, 10), Offset: image.Pt(0, 3), Advance: 6}
+	if !reflect.DeepEqual(g, want) {
+		t.Fatalf("got glyph %v, want %v", g, want)
+	}
+	if f.Glyph('Z') != f.Glyphs['?'] || f.Kern('A', 'V') != -1 || f.Kern('V', 'A') != 0 {
+		t.Fatal("bad fallback glyph or kerning")
+	}
+
+	// The XML format is the same.
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "pixel.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := ParseBMFont(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x.Face != f.Face || !reflect.DeepEqual(x.Glyphs['V'], f.Glyphs['V']) || x.Kern('A', 'V') != -1 || x.Pages[0] != "pixel_0.png" {
+		t.Fatalf("xml font %+v", x)
+	}
+
+	for _, bad := range []string{
+		"info face=\"a\"\n",
+		"common lineHeight=10\nchar id=65 page=1\n",
+		"common lineHeight=ten\n",
+		"BMF\x03",
+	} {
+		if _, err := ParseBMFont([]byte(bad)); err == nil {
+			t.Errorf("parsed %q", bad)
+		}
+	}
+}
+
+func TestLayout(t *testing.T) {
+	f, err := LoadBMFontFile(filepath.Join("testdata", "pixel.fnt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The space has no image, and V is kerned towards A.
+	quads := f.Layout("AV g\nA")
+	var got []image.Point
+	for _, q := range quads {
+		got = append(got, q.Pos)
+	}
+	want := []image.Point{{0, 0}, {5, 0}, {15, 3}, {0, 10}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got positions %v, want %v", got, want)
+	}
+	if size := f.Measure("AV g\nA"); size != image.Pt(21, 20) {
+		t.Fatalf("size %v", size)
+	}
+
+	txt := New(f, nil)
+	txt.Color = gfx.Color{1, 0, 0, 1}
+	txt.Set("Ag")
+	m := txt.Objects[0].Meshes[0]
+	if txt.String() != "Ag" || len(m.Vertices) != 12 || m.Colors[0] != txt.Color {
+		t.Fatalf("%d vertices", len(m.Vertices))
+	}
+	if m.Vertices[0] != (gfx.Vec3{0, 0, 8}) || m.Vertices[7] != (gfx.Vec3{6, 0, -5}) {
+		t.Fatalf("vertices %v", m.Vertices)
+	}
+	if tc := m.TexCoords[0].Slice; tc[2] != (gfx.TexCoord{5.0 / 32, 0.5}) {
+		t.Fatalf("texture coordinates %v", tc)
+	}
+}
+
+// shapeFont returns a font with a 10 pixel wide glyph for each character of
+// the given string, and the given ligature glyphs.
+func shapeFont(chars string, ligatures ...string) *Font {
+	f := &Font{Glyphs: make(map[rune]*Glyph), Ligatures: make(map[string]*Glyph)}
+	for _, r := range chars {
+		f.Glyphs[r] = &Glyph{Rune: r, Rect: image.Rect(0, 0, 8, 8), Advance: 10}
+	}
+	for _, l := range ligatures {
+		f.Ligatures[l] = &Glyph{Rune: 0xE000, Rect: image.Rect(0, 0, 8, 8), Advance: 10}
+	}
+	return f
+}
+
+func TestShape(t *testing.T) {
+	shape := func(f *Font, s string, dir Direction) (string, []int) {
+		glyphs, _ := f.Shape(s, dir)
+		var (
+			runes []rune
+			xs    []int
+		)
+		for _, g := range glyphs {
+			runes = append(runes, g.Glyph.Rune)
+			xs = append(xs, g.X)
+		}
+		return string(runes), xs
+	}
+
+	tests := []struct {
+		f    *Font
+		s    string
+		dir  Direction
+		want string
+	}{
+		// Right-to-left runs are reversed and their brackets mirrored, while
+		// numbers keep their order.
+		{shapeFont("ab אבג() "), "ab (אבג)", Auto, "ab (גבא)"},
+		{shapeFont("אב()"), "א(ב)", Auto, "(ב)א"},
+		{shapeFont("ab אבג12 "), "אבג ab 12", Auto, "ab 12 גבא"},
+		{shapeFont("ab אבג12 "), "ab אבג 12", Auto, "ab 12 גבא"},
+		{shapeFont("ab "), "ab", RightToLeft, "ab"},
+
+		// Arabic letters take their contextual forms: BEH (initial), TEH
+		// (medial) and REH (final), then an isolated BEH. Lam-alef is a
+		// ligature.
+		{shapeFont("ﺑﺘﺮﺏﻻ "), "بتر ب لا", Auto, "ﻻ ﺏ ﺮﺘﺑ"},
+
+		// Forms the font lacks are not used.
+		{shapeFont("بتر"), "بتر", Auto, "رتب"},
+
+		// The i vowel sign of Devanagari is drawn before it's consonants.
+		{shapeFont("कषि्"), "क्षि", Auto, "िक्ष"},
+		{shapeFont("कषि्", "क्ष"), "क्षि", Auto, "ि"},
+
+		// Emoji sequences use ligature glyphs, or else are drawn without
+		// their joiners and variation selectors.
+		{shapeFont("a", "🇫🇷", "👩‍💻"), "🇫🇷a👩‍💻", Auto, "a"},
+		{shapeFont("👩💻❤"), "👩‍💻❤️", Auto, "👩💻❤"},
+
+		// Standard ligatures are used if the font has them.
+		{shapeFont("filﬁ"), "fil", Auto, "ﬁl"},
+	}
+	for _, tst := range tests {
+		if got, _ := shape(tst.f, tst.s, tst.dir); got != tst.want {
+			t.Errorf("Shape(%q) = %q, want %q", tst.s, got, tst.want)
+		}
+	}
+
+	// Marks do not advance the pen.
+	f := shapeFont("éx")
+	if got, xs := shape(f, "éx", Auto); got != "éx" || !reflect.DeepEqual(xs, []int{0, 0, 10}) {
+		t.Fatalf("got %q at %v", got, xs)
+	}
+	if _, w := f.Shape("éx", Auto); w != 20 {
+		t.Fatalf("width %d", w)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2014 Lightpoke. All rights reserved.
+// This source code is subject to the terms and
+// conditions defined in the "License.txt" file.
+
+package tmx
+
+import "time"
+
+// Animator plays the animations of the animated tiles of a map (see
+// Tile.Animation), all of which start at the same time.
+//
+// To render animated tiles, set Config.Animator and load the map again (or
+// release and realize it's chunks again, see ChunkCache) whenever Tick
+// reports that a frame changed.
+type Animator struct {
+	m       *Map
+	elapsed time.Duration
+
+	// Map of animated gids (without flip flags) to the gid of their current
+	// frame.
+	frames map[uint32]uint32
+}
+
+// update updates the current frames, and tells if any of them changed.
+func (a *Animator) update() (changed bool) {
+	for _, ts := range a.m.Tilesets {
+		for id, t := range ts.Tiles {
+			if len(t.Animation) == 0 {
+				continue
+			}
+			gid := ts.Firstgid + uint32(id)
+			frame := ts.Firstgid + uint32(t.FrameAt(a.elapsed))
+			if a.frames[gid] != frame {
+				a.frames[gid] = frame
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// Tick advances the animations by dt, and tells if the frame of any animated
+// tile changed.
+func (a *Animator) Tick(dt time.Duration) (changed bool) {
+	a.elapsed += dt
+	return a.update()
+}
+
+// Elapsed returns the time elapsed since the start of the animations.
+func (a *Animator) Elapsed() time.Duration {
+	return a.elapsed
+}
+
+// Gid returns the global tile ID of the current frame of the tile with the
+// given global tile ID, or the given one if the tile is not animated. Flip
+// flags of the given gid are kept.
+func (a *Animator) Gid(gid uint32) uint32 {
+	flags := gid & (FLIPPED_HORIZONTALLY_FLAG | FLIPPED_VERTICALLY_FLAG | FLIPPED_DIAGONALLY_FLAG)
+	if frame, ok := a.frames[gid&^flags]; ok {
+		return frame | flags
+	}
+	return gid
+}
+
+// Animated tells if the tile with the given global tile ID is animated.
+func (a *Animator) Animated(gid uint32) bool {
+	gid &^= FLIPPED_HORIZONTALLY_FLAG | FLIPPED_VERTICALLY_FLAG | FLIPPED_DIAGONALLY_FLAG
+	_, ok := a.frames[gid]
+	return ok
+}
+
+// NewAnimator returns a new animator for the animated tiles of the given map,
+// at the start of their animations.
+func NewAnimator(m *Map) *Animator {
+	a := &Animator{
+		m:      m,
+		frames: make(map[uint32]uint32),
+	}
+	a.update()
+	return a
+}
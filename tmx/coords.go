@@ -0,0 +1,166 @@
+// Copyright 2014 Lightpoke. All rights reserved.
+// This source code is subject to the terms and
+// conditions defined in the "License.txt" file.
+
+package tmx
+
+import "math"
+
+// setStaggerDefaults sets the stagger axis and index of the map to Tiled's
+// defaults, if they are not specified.
+func (m *Map) setStaggerDefaults() {
+	if len(m.StaggerAxis) == 0 {
+		m.StaggerAxis = "y"
+	}
+	if len(m.StaggerIndex) == 0 {
+		m.StaggerIndex = "odd"
+	}
+}
+
+// staggerParams holds the sizes needed to lay out the tiles of staggered and
+// hexagonal maps, like Tiled's hexagonal renderer does.
+type staggerParams struct {
+	tileWidth, tileHeight    float64
+	staggerX, staggerEven    bool
+	sideLengthX, sideLengthY float64
+	sideOffsetX, sideOffsetY float64
+	columnWidth, rowHeight   float64
+}
+
+func (m *Map) staggerParams() staggerParams {
+	p := staggerParams{
+		tileWidth:   float64(m.TileWidth),
+		tileHeight:  float64(m.TileHeight),
+		staggerX:    m.StaggerAxis == "x",
+		staggerEven: m.StaggerIndex == "even",
+	}
+	if m.Orientation == Hexagonal {
+		if p.staggerX {
+			p.sideLengthX = float64(m.HexSideLength)
+		} else {
+			p.sideLengthY = float64(m.HexSideLength)
+		}
+	}
+	p.sideOffsetX = (p.tileWidth - p.sideLengthX) / 2
+	p.sideOffsetY = (p.tileHeight - p.sideLengthY) / 2
+	p.columnWidth = p.sideOffsetX + p.sideLengthX
+	p.rowHeight = p.sideOffsetY + p.sideLengthY
+	return p
+}
+
+// doStagger tells if the row or column i is shifted.
+func (p staggerParams) doStagger(i int) bool {
+	return (i&1 == 1) != p.staggerEven
+}
+
+// PixelSize returns the size of the map in pixels, i.e. the size of the
+// bounding box of all of it's tiles (not accounting for tiles larger than
+// TileWidth and TileHeight).
+//
+// For infinite maps, this is the size of the area of Width by Height tiles
+// at the origin.
+func (m *Map) PixelSize() (width, height int) {
+	tw, th := float64(m.TileWidth), float64(m.TileHeight)
+	switch m.Orientation {
+	case Isometric:
+		n := float64(m.Width + m.Height)
+		return int(n * tw / 2), int(n * th / 2)
+	case Staggered, Hexagonal:
+		p := m.staggerParams()
+		var w, h float64
+		if p.staggerX {
+			w = p.columnWidth*float64(m.Width) + p.sideOffsetX
+			h = (th + p.sideLengthY) * float64(m.Height)
+			if m.Width > 1 {
+				h += p.rowHeight
+			}
+		} else {
+			w = (tw + p.sideLengthX) * float64(m.Width)
+			h = p.rowHeight*float64(m.Height) + p.sideOffsetY
+			if m.Height > 1 {
+				w += p.columnWidth
+			}
+		}
+		return int(w), int(h)
+	}
+	return m.Width * m.TileWidth, m.Height * m.TileHeight
+}
+
+// TileToPixel returns the position in pixels of the tile at the given tile
+// coordinates, that is the top-left corner of the TileWidth by TileHeight
+// bounding box of the tile (e.g. of the diamond, for isometric maps).
+//
+// Pixel coordinates have their origin at the top-left corner of the map, with
+// the Y axis pointing down, just like in Tiled.
+func (m *Map) TileToPixel(x, y int) (px, py float64) {
+	tw, th := float64(m.TileWidth), float64(m.TileHeight)
+	switch m.Orientation {
+	case Isometric:
+		originX := float64(m.Height) * tw / 2
+		return float64(x-y)*tw/2 + originX - tw/2, float64(x+y) * th / 2
+	case Staggered, Hexagonal:
+		p := m.staggerParams()
+		if p.staggerX {
+			px = float64(x) * p.columnWidth
+			py = float64(y) * (th + p.sideLengthY)
+			if p.doStagger(x) {
+				py += p.rowHeight
+			}
+		} else {
+			px = float64(x) * (tw + p.sideLengthX)
+			py = float64(y) * p.rowHeight
+			if p.doStagger(y) {
+				px += p.columnWidth
+			}
+		}
+		return px, py
+	}
+	return float64(x) * tw, float64(y) * th
+}
+
+// PixelToTile returns the coordinates of the tile found at the given position
+// in pixels (see TileToPixel), e.g. the tile under the mouse cursor.
+func (m *Map) PixelToTile(px, py float64) (x, y int) {
+	tw, th := float64(m.TileWidth), float64(m.TileHeight)
+	switch m.Orientation {
+	case Isometric:
+		px -= float64(m.Height) * tw / 2
+		tx, ty := px/tw, py/th
+		return int(math.Floor(ty + tx)), int(math.Floor(ty - tx))
+	case Staggered, Hexagonal:
+		// Find a tile near the position, and then the tile whose center is
+		// closest to it amongst it's neighbors.
+		p := m.staggerParams()
+		var minX, maxX, minY, maxY int
+		if p.staggerX {
+			gx := int(math.Floor(px / p.columnWidth))
+			gy := int(math.Floor(py / (th + p.sideLengthY)))
+			minX, maxX, minY, maxY = gx-2, gx+1, gy-1, gy+1
+		} else {
+			gx := int(math.Floor(px / (tw + p.sideLengthX)))
+			gy := int(math.Floor(py / p.rowHeight))
+			minX, maxX, minY, maxY = gx-1, gx+1, gy-2, gy+1
+		}
+		best := math.Inf(1)
+		for cy := minY; cy <= maxY; cy++ {
+			for cx := minX; cx <= maxX; cx++ {
+				tx, ty := m.TileToPixel(cx, cy)
+				dx := px - (tx + tw/2)
+				dy := py - (ty + th/2)
+				var dist float64
+				if m.Orientation == Staggered {
+					// Distance within the diamond shape of the tile.
+					dist = math.Abs(dx)/tw + math.Abs(dy)/th
+				} else {
+					dist = dx*dx + dy*dy
+				}
+				if dist < best {
+					best = dist
+					x, y = cx, cy
+				}
+			}
+		}
+		return x, y
+	}
+	return int(math.Floor(px / tw)), int(math.Floor(py / th))
+}
@@ -14,6 +14,7 @@ import (
 	"errors"
 	"io"
 	"strconv"
+	"sync"
 )
 
 type xmlDataTile struct {
@@ -30,6 +31,36 @@ type xmlData struct {
 	Compression string `xml:"compression,attr"`
 
 	Tile []xmlDataTile `xml:"tile"`
+
+	// Chunks of the data, for infinite maps.
+	Chunk []xmlChunk `xml:"chunk"`
+}
+
+type xmlChunk struct {
+	X      int           `xml:"x,attr"`
+	Y      int           `xml:"y,attr"`
+	Width  int           `xml:"width,attr"`
+	Height int           `xml:"height,attr"`
+	Data   []byte        `xml:",innerxml"`
+	Tile   []xmlDataTile `xml:"tile"`
+}
+
+// chunks decodes the chunks of the data, for infinite maps.
+func (x xmlData) chunks() ([]*Chunk, error) {
+	chunks := make([]*Chunk, len(x.Chunk))
+	for i, xc := range x.Chunk {
+		local, err := xmlData{
+			Data:        xc.Data,
+			Encoding:    x.Encoding,
+			Compression: x.Compression,
+			Tile:        xc.Tile,
+		}.tiles(xc.Width, xc.Height)
+		if err != nil {
+			return nil, err
+		}
+		chunks[i] = newChunk(xc.X, xc.Y, xc.Width, xc.Height, local)
+	}
+	return chunks, nil
 }
 
 var (
@@ -40,6 +71,33 @@ var (
 	ErrBadCompression = errors.New("tile data compression type is not supported")
 )
 
+var (
+	decompressorsAccess sync.RWMutex
+	decompressors       = make(map[string]func(r io.Reader) (io.ReadCloser, error))
+)
+
+// RegisterDecompressor registers a decompressor for base64 encoded tile data
+// of the given compression type (the compression attribute of the data). The
+// zlib and gzip compression types are supported by default.
+//
+// This package has no zstd decoder of it's own. For instance, zstd compressed
+// data can be read by registering one of a zstd package, e.g.
+// github.com/klauspost/compress/zstd:
+//
+//  tmx.RegisterDecompressor("zstd", func(r io.Reader) (io.ReadCloser, error) {
+//      d, err := zstd.NewReader(r)
+//      if err != nil {
+//          return nil, err
+//      }
+//      return d.IOReadCloser(), nil
+//  })
+//
+func RegisterDecompressor(compression string, fn func(r io.Reader) (io.ReadCloser, error)) {
+	decompressorsAccess.Lock()
+	decompressors[compression] = fn
+	decompressorsAccess.Unlock()
+}
+
 func toCoord(index, width, height int) Coord {
 	if width == 0 {
 		panic("width == 0")
@@ -114,7 +172,18 @@ func (x xmlData) tiles(width, height int) (map[Coord]uint32, error) {
 			decompressed = r
 
 		default:
-			return nil, ErrBadCompression
+			decompressorsAccess.RLock()
+			fn, ok := decompressors[x.Compression]
+			decompressorsAccess.RUnlock()
+			if !ok {
+				return nil, ErrBadCompression
+			}
+			r, err := fn(decoded)
+			if err != nil {
+				return nil, err
+			}
+			defer r.Close()
+			decompressed = r
 		}
 		coordIndex := 0
 		for {
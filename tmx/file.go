@@ -0,0 +1,83 @@
+// Copyright 2014 Lightpoke. All rights reserved.
+// This source code is subject to the terms and
+// conditions defined in the "License.txt" file.
+
+package tmx
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// isJSON tells if the named file is in Tiled's JSON format, by it's
+// extension: ".json", ".tmj" (maps) or ".tsj" (tilesets).
+func isJSON(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json", ".tmj", ".tsj":
+		return true
+	}
+	return false
+}
+
+// ParseFile parses the map file at the given path, and loads it's external
+// tilesets (see LoadTilesets) from the directory of the map file.
+//
+// Maps are parsed as TMX files, or as JSON ones (see ParseJSON) if the file
+// has a ".json" or ".tmj" extension.
+func ParseFile(path string) (*Map, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m *Map
+	if isJSON(path) {
+		m, err = ParseJSON(data)
+	} else {
+		m, err = Parse(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := m.LoadTilesets(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// LoadTilesets loads each external tileset of the map (i.e. those with a
+// Source) whose source path is relative to the given directory, typically
+// the one holding the map file.
+//
+// Tilesets are loaded as TSX files, or as JSON ones (see Tileset.LoadJSON)
+// if the file has a ".json" or ".tsj" extension.
+func (m *Map) LoadTilesets(dir string) error {
+	for _, ts := range m.Tilesets {
+		if len(ts.Source) == 0 {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(ts.Source)))
+		if err != nil {
+			return err
+		}
+		if isJSON(ts.Source) {
+			err = ts.LoadJSON(data)
+		} else {
+			err = ts.Load(data)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImagePath returns the path of the image of this tileset, given the
+// directory of the map file: the image source path is relative to the
+// tileset file for external tilesets, and to the map file otherwise.
+func (t *Tileset) ImagePath(dir string) string {
+	if len(t.Source) > 0 {
+		dir = filepath.Join(dir, filepath.Dir(filepath.FromSlash(t.Source)))
+	}
+	return filepath.Join(dir, filepath.FromSlash(t.Image.Source))
+}
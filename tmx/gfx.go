@@ -7,19 +7,20 @@
 // It loads a 2D tmx map into a few meshes stored in a *gfx.Object and applies
 // textures such that it would render properly.
 //
-// At present the package only supports orthogonal tile map rendering, and has
-// some issues with proper ordering of perspective (I.e. non-uniformly sized)
-// tiles (see for instance tiled-qt/examples/perspective_walls.tmx).
+// Orthogonal, isometric, staggered and hexagonal maps are supported. Tiles are
+// drawn from the top of the map to it's bottom (and then left to right), such
+// that tiles lower on the map are drawn in front of those above them. Tile
+// images are anchored at the bottom-left of their cell, as in Tiled.
 package tmx
 
 import (
 	"image"
-	"image/draw"
-	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 
 	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/gfx/imgutil"
 	"azul3d.org/engine/lmath"
 )
 
@@ -149,6 +150,161 @@ type Config struct {
 
 	// The value to offset each individual tile from one another on the Y axis.
 	TileOffset float64
+
+	// If non-nil, animated tiles are loaded showing their current frame as
+	// given by the animator, otherwise they are loaded as-is.
+	Animator *Animator
+}
+
+// defaultConfig is the configuration used when a nil one is given.
+func defaultConfig() *Config {
+	return &Config{
+		LayerOffset: 0.001,
+		TileOffset:  0.000001,
+	}
+}
+
+// drawTile is a tile to be drawn at the given position in pixels.
+type drawTile struct {
+	gid    uint32
+	px, py float64
+}
+
+// drawOrder returns the tiles of the layer found in the rectangle r (in tile
+// coordinates), in the order they must be drawn: from the top of the map to
+// it's bottom and then from left to right, such that tiles in front of others
+// (e.g. lower on isometric maps) are drawn over them.
+func drawOrder(m *Map, layer *Layer, r image.Rectangle) []drawTile {
+	var tiles []drawTile
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			gid, hasTile := layer.Tiles[Coord{x, y}]
+			if !hasTile {
+				continue
+			}
+			px, py := m.TileToPixel(x, y)
+			tiles = append(tiles, drawTile{gid, px, py})
+		}
+	}
+	sort.SliceStable(tiles, func(i, j int) bool {
+		if tiles[i].py != tiles[j].py {
+			return tiles[i].py < tiles[j].py
+		}
+		return tiles[i].px < tiles[j].px
+	})
+	return tiles
+}
+
+// appendTiles appends the tiles of the layer found in the rectangle r (in tile
+// coordinates) to the objects of the texObjects map, creating objects as
+// needed. The textures of new objects are shared through the textures map, by
+// tileset image filename.
+func appendTiles(texObjects map[string]*gfx.Object, textures map[string]*gfx.Texture, m *Map, c *Config, tsImages map[string]*image.RGBA, layer *Layer, r image.Rectangle, layerOffset float64) {
+	_, mapHeight := m.PixelSize()
+	var tileOffset float64
+	for _, tile := range drawOrder(m, layer, r) {
+		gid := tile.gid
+		if c.Animator != nil {
+			gid = c.Animator.Gid(gid)
+		}
+		tileset := m.FindTileset(gid)
+
+		// Load the tileset texture if needed
+		tsImage := filepath.Base(tileset.Image.Source)
+		rgba, haveTilesetImage := tsImages[tsImage]
+		if !haveTilesetImage {
+			// We weren't given a RGBA image for the tileset, so we
+			// will just omit this tile.
+			continue
+		}
+
+		// Create a textured mesh object, if needed.
+		obj, ok := texObjects[tsImage]
+		if !ok {
+			// Create texture, if needed.
+			t, ok := textures[tsImage]
+			if !ok {
+				t = gfx.NewTexture()
+				t.Source = rgba
+				t.Bounds = rgba.Bounds()
+				t.WrapU = gfx.Clamp
+				t.WrapV = gfx.Clamp
+				t.MinFilter = gfx.LinearMipmapLinear
+				t.MagFilter = gfx.Linear
+				textures[tsImage] = t
+			}
+
+			// And the object.
+			obj = gfx.NewObject()
+			obj.Shader = Shader
+			obj.Meshes = []*gfx.Mesh{gfx.NewMesh()}
+			obj.Textures = []*gfx.Texture{t}
+
+			// Disable face culling because of the flipped cards.
+			obj.State = gfx.NewState()
+			obj.State.FaceCulling = gfx.NoFaceCulling
+			obj.State.AlphaMode = gfx.AlphaToCoverage
+			texObjects[tsImage] = obj
+		}
+		r := m.TilesetRect(tileset, rgba.Bounds().Dx(), rgba.Bounds().Dy(), true, gid)
+
+		halfWidth := float32(tileset.Width) / 2.0
+		halfHeight := float32(tileset.Height) / 2.0
+		cardStart := len(obj.Meshes[0].Vertices)
+		appendCard(
+			obj.Meshes[0],
+			-halfWidth,
+			halfWidth,
+			-halfHeight,
+			halfHeight,
+			0, r, rgba.Bounds(),
+		)
+		cardEnd := len(obj.Meshes[0].Vertices)
+
+		// apply necessary flips
+		flip := lmath.Mat4Identity
+		diagFlipped := (gid & FLIPPED_DIAGONALLY_FLAG) > 0
+		horizFlipped := (gid & FLIPPED_HORIZONTALLY_FLAG) > 0
+		vertFlipped := (gid & FLIPPED_VERTICALLY_FLAG) > 0
+		if diagFlipped {
+			if horizFlipped && vertFlipped {
+				flip = cw90.Mul(flip)
+				flip = horizFlip.Mul(flip)
+			} else if horizFlipped {
+				flip = cw90.Mul(flip)
+			} else if vertFlipped {
+				flip = cwn90.Mul(flip)
+			} else {
+				flip = horizFlip.Mul(flip)
+				flip = cw90.Mul(flip)
+			}
+		} else {
+			if horizFlipped {
+				flip = horizFlip.Mul(flip)
+			}
+			if vertFlipped {
+				flip = vertFlip.Mul(flip)
+			}
+		}
+
+		// Move the card, such that the bottom-left of the tile image is at
+		// the bottom-left of the tile's cell.
+		move := lmath.Mat4FromTranslation(lmath.Vec3{
+			tile.px + float64(halfWidth),
+			layerOffset + tileOffset,
+			float64(mapHeight) - (tile.py + float64(m.TileHeight)) + float64(halfHeight),
+		})
+		tileOffset -= c.TileOffset
+
+		trans := flip.Mul(move)
+
+		// Apply transformation.
+		verts := obj.Meshes[0].Vertices
+		for i, v := range verts[cardStart:cardEnd] {
+			vt := v.Vec3().TransformMat4(trans)
+			verts[cardStart+i] = gfx.Vec3{float32(vt.X), float32(vt.Y), float32(vt.Z)}
+		}
+	}
 }
 
 // Load loads the given tmx map, m, and returns a slice of *gfx.Object with the
@@ -160,124 +316,30 @@ type Config struct {
 // The tsImages map should be a map of tileset image filenames and their
 // associated loaded RGBA images. Tiles who reference tilesets who are not
 // found in the map will be omited (not rendered) in the returned objects.
+//
+// For infinite maps every chunk of every layer is loaded, large maps should
+// instead use a ChunkCache to load only the chunks that are needed. Games
+// drawing large maps, or editing tiles at runtime, should use a Renderer.
 func Load(m *Map, c *Config, tsImages map[string]*image.RGBA) (layers map[string]map[string]*gfx.Object) {
 	if c == nil {
-		c = &Config{
-			LayerOffset: 0.001,
-			TileOffset:  0.000001,
-		}
+		c = defaultConfig()
 	}
 
 	// A map of layer names to a slice of objects each containing one texture
 	// and mesh.
 	layers = make(map[string]map[string]*gfx.Object, len(m.Layers))
+	textures := make(map[string]*gfx.Texture)
 	var layerOffset float64
 
 	for _, layer := range m.Layers {
 		// A slice of objects which contain a single texture and mesh.
 		texObjects := make(map[string]*gfx.Object)
-		var tileOffset float64
-
-		for x := 0; x < m.Width; x++ {
-			for y := 0; y < m.Height; y++ {
-				gid, hasTile := layer.Tiles[Coord{x, y}]
-				if !hasTile {
-					continue
-				}
-
-				tileset := m.FindTileset(gid)
-
-				// Load the tileset texture if needed
-				tsImage := filepath.Base(tileset.Image.Source)
-				rgba, haveTilesetImage := tsImages[tsImage]
-				if !haveTilesetImage {
-					// We weren't given a RGBA image for the tileset, so we
-					// will just omit this tile.
-					continue
-				}
-
-				// Create a textured mesh object, if needed.
-				obj, ok := texObjects[tsImage]
-				if !ok {
-					// Create texture.
-					t := gfx.NewTexture()
-					t.Source = rgba
-					t.Bounds = rgba.Bounds()
-					t.WrapU = gfx.Clamp
-					t.WrapV = gfx.Clamp
-					t.MinFilter = gfx.LinearMipmapLinear
-					t.MagFilter = gfx.Linear
-
-					// And the object.
-					obj = gfx.NewObject()
-					obj.Shader = Shader
-					obj.Meshes = []*gfx.Mesh{gfx.NewMesh()}
-					obj.Textures = []*gfx.Texture{t}
-
-					// Disable face culling because of the flipped cards.
-					obj.State = gfx.NewState()
-					obj.State.FaceCulling = gfx.NoFaceCulling
-					obj.State.AlphaMode = gfx.AlphaToCoverage
-					texObjects[tsImage] = obj
-				}
-				r := m.TilesetRect(tileset, rgba.Bounds().Dx(), rgba.Bounds().Dy(), true, gid)
-
-				halfWidth := float32(tileset.Width) / 2.0
-				halfHeight := float32(tileset.Height) / 2.0
-				cardStart := len(obj.Meshes[0].Vertices)
-				appendCard(
-					obj.Meshes[0],
-					-halfWidth,
-					halfWidth,
-					-halfHeight,
-					halfHeight,
-					0, r, rgba.Bounds(),
-				)
-				cardEnd := len(obj.Meshes[0].Vertices)
-
-				// apply necessary flips
-				flip := lmath.Mat4Identity
-				diagFlipped := (gid & FLIPPED_DIAGONALLY_FLAG) > 0
-				horizFlipped := (gid & FLIPPED_HORIZONTALLY_FLAG) > 0
-				vertFlipped := (gid & FLIPPED_VERTICALLY_FLAG) > 0
-				if diagFlipped {
-					if horizFlipped && vertFlipped {
-						flip = cw90.Mul(flip)
-						flip = horizFlip.Mul(flip)
-					} else if horizFlipped {
-						flip = cw90.Mul(flip)
-					} else if vertFlipped {
-						flip = cwn90.Mul(flip)
-					} else {
-						flip = horizFlip.Mul(flip)
-						flip = cw90.Mul(flip)
-					}
-				} else {
-					if horizFlipped {
-						flip = horizFlip.Mul(flip)
-					}
-					if vertFlipped {
-						flip = vertFlip.Mul(flip)
-					}
-				}
-
-				// Move the card,
-				move := lmath.Mat4FromTranslation(lmath.Vec3{
-					float64(x*m.TileWidth) + float64(halfWidth),
-					layerOffset + tileOffset,
-					float64((m.Height-y)*m.TileHeight) - float64(halfHeight),
-				})
-				tileOffset -= c.TileOffset
-
-				trans := flip.Mul(move)
-
-				// Apply transformation.
-				verts := obj.Meshes[0].Vertices
-				for i, v := range verts[cardStart:cardEnd] {
-					vt := v.Vec3().TransformMat4(trans)
-					verts[cardStart+i] = gfx.Vec3{float32(vt.X), float32(vt.Y), float32(vt.Z)}
-				}
+		if m.Infinite {
+			for _, chunk := range layer.Chunks {
+				appendTiles(texObjects, textures, m, c, tsImages, layer, chunk.Bounds(), layerOffset)
 			}
+		} else {
+			appendTiles(texObjects, textures, m, c, tsImages, layer, image.Rect(0, 0, m.Width, m.Height), layerOffset)
 		}
 
 		// Add the slice to the map of layers.
@@ -319,51 +381,20 @@ func Load(m *Map, c *Config, tsImages map[string]*image.RGBA) (layers map[string
 }
 
 // LoadFile works just like Load except it loads all associated dependencies
-// (external tsx tileset files, tileset texture images) for you.
+// (external tileset files, tileset texture images) for you. The map may be a
+// TMX or JSON file (see ParseFile).
 //
 // Advanced clients who wish to have more control over file IO will use Load()
 // directly instead of using this function.
 func LoadFile(path string, c *Config) (*Map, map[string]map[string]*gfx.Object, error) {
-	f, err := os.Open(path)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	data, err := ioutil.ReadAll(f)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	m, err := Parse(data)
+	// Parse the map, and it's external tilesets.
+	m, err := ParseFile(path)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	relativeDir := filepath.Dir(path)
 
-	// External tilesets in the map must be loaded seperately
-	for _, ts := range m.Tilesets {
-		if len(ts.Source) > 0 {
-			// Open tsx file
-			f, err := os.Open(filepath.Join(relativeDir, filepath.Base(ts.Source)))
-			if err != nil {
-				return nil, nil, err
-			}
-
-			// Read file data
-			data, err := ioutil.ReadAll(f)
-			if err != nil {
-				return nil, nil, err
-			}
-
-			// Load the tileset
-			err = ts.Load(data)
-			if err != nil {
-				return nil, nil, err
-			}
-		}
-	}
-
 	// We must also load the images of the tileset
 	tsImages := make(map[string]*image.RGBA)
 	for _, ts := range m.Tilesets {
@@ -371,28 +402,88 @@ func LoadFile(path string, c *Config) (*Map, map[string]map[string]*gfx.Object,
 		tsImage := filepath.Base(ts.Image.Source)
 
 		// Open tileset image
-		f, err := os.Open(filepath.Join(relativeDir, tsImage))
+		f, err := os.Open(ts.ImagePath(relativeDir))
 		if err != nil {
 			return nil, nil, err
 		}
 
 		// Decode the image
 		src, _, err := image.Decode(f)
+		f.Close()
 		if err != nil {
 			return nil, nil, err
 		}
 
-		// If need be, convert to RGBA
-		rgba, ok := src.(*image.RGBA)
-		if !ok {
-			b := src.Bounds()
-			rgba = image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
-			draw.Draw(rgba, rgba.Bounds(), src, b.Min, draw.Src)
-		}
-
-		// Put into the tileset images map
-		tsImages[tsImage] = rgba
+		// Put into the tileset images map, converted to RGBA if need be.
+		tsImages[tsImage] = imgutil.RGBA(src)
 	}
 
 	return m, Load(m, c, tsImages), nil
 }
+
+// ChunkCache lazily realizes the chunks of an infinite map into renderable
+// objects, such that only the chunks near the camera need to be loaded at
+// once (see Layer.ChunksIn).
+type ChunkCache struct {
+	m        *Map
+	c        *Config
+	tsImages map[string]*image.RGBA
+	textures map[string]*gfx.Texture
+	chunks   map[*Chunk]map[string]*gfx.Object
+}
+
+// Objects returns the objects of the given chunk of the layer at index i of
+// the map (i.e. m.Layers[i]), as a map of tileset image filenames to the
+// object holding the texture and mesh of the chunk's tiles, just like Load.
+//
+// The objects are created on the first request for the chunk, and returned
+// as-is by further requests until the chunk is released.
+func (cc *ChunkCache) Objects(i int, chunk *Chunk) map[string]*gfx.Object {
+	if objs, ok := cc.chunks[chunk]; ok {
+		return objs
+	}
+	objs := make(map[string]*gfx.Object)
+	layerOffset := -float64(i) * cc.c.LayerOffset
+	appendTiles(objs, cc.textures, cc.m, cc.c, cc.tsImages, cc.m.Layers[i], chunk.Bounds(), layerOffset)
+	cc.chunks[chunk] = objs
+	return objs
+}
+
+// Loaded tells if the objects of the given chunk are currently loaded.
+func (cc *ChunkCache) Loaded(chunk *Chunk) bool {
+	_, ok := cc.chunks[chunk]
+	return ok
+}
+
+// Release releases the objects of the given chunk (e.g. once it is far away
+// from the camera), which are loaded again by the next call to Objects.
+func (cc *ChunkCache) Release(chunk *Chunk) {
+	objs, ok := cc.chunks[chunk]
+	if !ok {
+		return
+	}
+	for _, o := range objs {
+		o.Destroy()
+	}
+	delete(cc.chunks, chunk)
+}
+
+// Len returns the number of chunks currently loaded.
+func (cc *ChunkCache) Len() int {
+	return len(cc.chunks)
+}
+
+// NewChunkCache returns a new chunk cache for the given map. The
+// configuration, c, and tileset images are used just like with Load.
+func NewChunkCache(m *Map, c *Config, tsImages map[string]*image.RGBA) *ChunkCache {
+	if c == nil {
+		c = defaultConfig()
+	}
+	return &ChunkCache{
+		m:        m,
+		c:        c,
+		tsImages: tsImages,
+		textures: make(map[string]*gfx.Texture),
+		chunks:   make(map[*Chunk]map[string]*gfx.Object),
+	}
+}
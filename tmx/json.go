@@ -0,0 +1,492 @@
+// Copyright 2014 Lightpoke. All rights reserved.
+// This source code is subject to the terms and
+// conditions defined in the "License.txt" file.
+
+package tmx
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// jsonProperties are the properties of the JSON format, which are either a
+// list of typed properties or (in older versions of Tiled) an object of
+// property names and values.
+type jsonProperties []jsonProperty
+
+type jsonProperty struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (p *jsonProperties) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		var m map[string]interface{}
+		if err := json.Unmarshal(data, &m); err != nil {
+			return err
+		}
+		for name, v := range m {
+			*p = append(*p, jsonProperty{Name: name, Value: v})
+		}
+		return nil
+	}
+	var list []jsonProperty
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*p = list
+	return nil
+}
+
+func (p jsonProperties) toMap() Properties {
+	m := make(Properties, len(p))
+	for _, p := range p {
+		switch v := p.Value.(type) {
+		case string:
+			m[p.Name] = v
+		case bool:
+			m[p.Name] = strconv.FormatBool(v)
+		case float64:
+			m[p.Name] = strconv.FormatFloat(v, 'f', -1, 64)
+		case nil:
+			m[p.Name] = ""
+		default:
+			// Class properties, which have no text form.
+			data, _ := json.Marshal(v)
+			m[p.Name] = string(data)
+		}
+	}
+	return m
+}
+
+type jsonTile struct {
+	ID          int            `json:"id"`
+	Terrain     []int          `json:"terrain"`
+	Probability float64        `json:"probability"`
+	Properties  jsonProperties `json:"properties"`
+	Image       string         `json:"image"`
+	ImageWidth  int            `json:"imagewidth"`
+	ImageHeight int            `json:"imageheight"`
+	Animation   []xmlFrame     `json:"animation"`
+	ObjectGroup *jsonLayer     `json:"objectgroup"`
+}
+
+func (j jsonTile) toTile() *Tile {
+	t := &Tile{
+		ID:          j.ID,
+		Terrain:     [4]int{-1, -1, -1, -1},
+		Probability: j.Probability,
+		Properties:  j.Properties.toMap(),
+		Image: xmlImage{
+			Source: j.Image,
+			Width:  j.ImageWidth,
+			Height: j.ImageHeight,
+		}.toImage(),
+	}
+	copy(t.Terrain[:], j.Terrain)
+	t.Animation = toFrames(j.Animation)
+	if j.ObjectGroup != nil {
+		t.ObjectGroup = j.ObjectGroup.toObjectGroup()
+	}
+	return t
+}
+
+type jsonTileset struct {
+	Firstgid    uint32         `json:"firstgid"`
+	Source      string         `json:"source"`
+	Name        string         `json:"name"`
+	TileWidth   int            `json:"tilewidth"`
+	TileHeight  int            `json:"tileheight"`
+	Spacing     int            `json:"spacing"`
+	Margin      int            `json:"margin"`
+	TileOffset  xmlTileoffset  `json:"tileoffset"`
+	Properties  jsonProperties `json:"properties"`
+	Image       string         `json:"image"`
+	ImageWidth  int            `json:"imagewidth"`
+	ImageHeight int            `json:"imageheight"`
+	Tiles       []jsonTile     `json:"tiles"`
+	Terrains    []struct {
+		Name string `json:"name"`
+		Tile int    `json:"tile"`
+	} `json:"terrains"`
+}
+
+// fromJSON sets the fields of this tileset (except for Firstgid and Source)
+// from the given JSON tileset.
+func (t *Tileset) fromJSON(j *jsonTileset) {
+	t.Name = j.Name
+	t.Width = j.TileWidth
+	t.Height = j.TileHeight
+	t.Spacing = j.Spacing
+	t.Margin = j.Margin
+	t.OffsetX, t.OffsetY = j.TileOffset.X, j.TileOffset.Y
+	t.Properties = j.Properties.toMap()
+	t.Image = &Image{
+		Source: j.Image,
+		Width:  j.ImageWidth,
+		Height: j.ImageHeight,
+	}
+	t.Tiles = make(map[int]*Tile, len(j.Tiles))
+	for _, jt := range j.Tiles {
+		t.Tiles[jt.ID] = jt.toTile()
+	}
+	t.Terrain = make([]TerrainType, len(j.Terrains))
+	for i, jt := range j.Terrains {
+		t.Terrain[i] = TerrainType{
+			Name: jt.Name,
+			Tile: jt.Tile,
+		}
+	}
+}
+
+// LoadJSON works just like Load, except the data is a tileset in Tiled's JSON
+// format.
+func (t *Tileset) LoadJSON(data []byte) error {
+	j := new(jsonTileset)
+	if err := json.Unmarshal(data, j); err != nil {
+		return err
+	}
+	t.fromJSON(j)
+	return nil
+}
+
+type jsonText struct {
+	Text       string `json:"text"`
+	FontFamily string `json:"fontfamily"`
+	PixelSize  *int   `json:"pixelsize"`
+	Wrap       bool   `json:"wrap"`
+	Color      string `json:"color"`
+	Bold       bool   `json:"bold"`
+	Italic     bool   `json:"italic"`
+	Underline  bool   `json:"underline"`
+	Strikeout  bool   `json:"strikeout"`
+	Kerning    *bool  `json:"kerning"`
+	HAlign     string `json:"halign"`
+	VAlign     string `json:"valign"`
+}
+
+// boolToInt returns 1 for true and 0 for false, as written in TMX files.
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (j jsonText) toText() *Text {
+	x := xmlText{
+		FontFamily: j.FontFamily,
+		PixelSize:  j.PixelSize,
+		Wrap:       boolToInt(j.Wrap),
+		Color:      j.Color,
+		Bold:       boolToInt(j.Bold),
+		Italic:     boolToInt(j.Italic),
+		Underline:  boolToInt(j.Underline),
+		Strikeout:  boolToInt(j.Strikeout),
+		HAlign:     j.HAlign,
+		VAlign:     j.VAlign,
+		Data:       j.Text,
+	}
+	if j.Kerning != nil {
+		k := boolToInt(*j.Kerning)
+		x.Kerning = &k
+	}
+	return x.toText()
+}
+
+type jsonObject struct {
+	ID         int            `json:"id"`
+	Name       string         `json:"name"`
+	Type       string         `json:"type"`
+	Class      string         `json:"class"`
+	X          float64        `json:"x"`
+	Y          float64        `json:"y"`
+	Width      float64        `json:"width"`
+	Height     float64        `json:"height"`
+	Rotation   float64        `json:"rotation"`
+	Gid        uint32         `json:"gid"`
+	Visible    *bool          `json:"visible"`
+	Properties jsonProperties `json:"properties"`
+	Ellipse    bool           `json:"ellipse"`
+	Point      bool           `json:"point"`
+	Polygon    []Point        `json:"polygon"`
+	Polyline   []Point        `json:"polyline"`
+	Text       *jsonText      `json:"text"`
+}
+
+func (j jsonObject) toValue() interface{} {
+	switch {
+	case j.Ellipse:
+		return &Ellipse{
+			X:      j.X,
+			Y:      j.Y,
+			Width:  j.Width,
+			Height: j.Height,
+		}
+	case j.Point:
+		return &Point{
+			X: j.X,
+			Y: j.Y,
+		}
+	case j.Polygon != nil:
+		return &Polygon{
+			X:      j.X,
+			Y:      j.Y,
+			Points: j.Polygon,
+		}
+	case j.Polyline != nil:
+		return &Polyline{
+			X:      j.X,
+			Y:      j.Y,
+			Points: j.Polyline,
+		}
+	case j.Text != nil:
+		return j.Text.toText()
+	case j.Gid != 0:
+		return nil
+	}
+	return &Rectangle{
+		X:      j.X,
+		Y:      j.Y,
+		Width:  j.Width,
+		Height: j.Height,
+	}
+}
+
+func (j jsonObject) toObject() *Object {
+	typ := j.Type
+	if len(typ) == 0 {
+		// Tiled 1.9 renamed the type of objects to class.
+		typ = j.Class
+	}
+	return &Object{
+		ID:         j.ID,
+		Name:       j.Name,
+		Type:       typ,
+		X:          j.X,
+		Y:          j.Y,
+		Width:      j.Width,
+		Height:     j.Height,
+		Rotation:   j.Rotation,
+		Gid:        j.Gid,
+		Visible:    j.Visible == nil || *j.Visible,
+		Properties: j.Properties.toMap(),
+		Value:      j.toValue(),
+	}
+}
+
+type jsonLayer struct {
+	Type        string          `json:"type"`
+	Name        string          `json:"name"`
+	Opacity     *float64        `json:"opacity"`
+	Visible     *bool           `json:"visible"`
+	Properties  jsonProperties  `json:"properties"`
+	Data        json.RawMessage `json:"data"`
+	Chunks      []jsonChunk     `json:"chunks"`
+	Encoding    string          `json:"encoding"`
+	Compression string          `json:"compression"`
+	Color       string          `json:"color"`
+	OffsetX     float64         `json:"offsetx"`
+	OffsetY     float64         `json:"offsety"`
+	DrawOrder   string          `json:"draworder"`
+	Objects     []jsonObject    `json:"objects"`
+	Layers      []jsonLayer     `json:"layers"`
+}
+
+type jsonChunk struct {
+	X      int             `json:"x"`
+	Y      int             `json:"y"`
+	Width  int             `json:"width"`
+	Height int             `json:"height"`
+	Data   json.RawMessage `json:"data"`
+}
+
+func (j jsonLayer) opacity() float64 {
+	if j.Opacity == nil {
+		return 1
+	}
+	return *j.Opacity
+}
+
+// decodeTiles decodes JSON tile data, either an array of global tile IDs or a
+// base64 encoded (and possibly compressed) string of them.
+func decodeTiles(data json.RawMessage, compression string, width, height int) (map[Coord]uint32, error) {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		return xmlData{
+			Data:        []byte(s),
+			Encoding:    "base64",
+			Compression: compression,
+		}.tiles(width, height)
+	}
+	var gids []uint32
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &gids); err != nil {
+			return nil, err
+		}
+	}
+	tiles := make(map[Coord]uint32)
+	for i, gid := range gids {
+		if gid != 0 {
+			tiles[toCoord(i, width, height)] = gid
+		}
+	}
+	return tiles, nil
+}
+
+func (j jsonLayer) toLayer(width, height int, infinite bool) (*Layer, error) {
+	l := &Layer{
+		Name:       j.Name,
+		Opacity:    j.opacity(),
+		Visible:    j.Visible == nil || *j.Visible,
+		Properties: j.Properties.toMap(),
+	}
+	if infinite {
+		chunks := make([]*Chunk, len(j.Chunks))
+		for i, jc := range j.Chunks {
+			local, err := decodeTiles(jc.Data, j.Compression, jc.Width, jc.Height)
+			if err != nil {
+				return nil, err
+			}
+			chunks[i] = newChunk(jc.X, jc.Y, jc.Width, jc.Height, local)
+		}
+		l.setChunks(chunks)
+		return l, nil
+	}
+	tiles, err := decodeTiles(j.Data, j.Compression, width, height)
+	if err != nil {
+		return nil, err
+	}
+	l.Tiles = tiles
+	return l, nil
+}
+
+func (j jsonLayer) toObjectGroup() *ObjectGroup {
+	objects := make([]*Object, len(j.Objects))
+	for i, o := range j.Objects {
+		objects[i] = o.toObject()
+	}
+	g := &ObjectGroup{
+		Name:       j.Name,
+		Color:      hexToRGBA(j.Color),
+		Opacity:    j.opacity(),
+		Visible:    j.Visible == nil || *j.Visible,
+		OffsetX:    j.OffsetX,
+		OffsetY:    j.OffsetY,
+		DrawOrder:  j.DrawOrder,
+		Properties: j.Properties.toMap(),
+		Objects:    objects,
+	}
+	if len(g.DrawOrder) == 0 {
+		g.DrawOrder = "topdown"
+	}
+	return g
+}
+
+type jsonMap struct {
+	Version         json.RawMessage `json:"version"`
+	Orientation     string          `json:"orientation"`
+	Width           int             `json:"width"`
+	Height          int             `json:"height"`
+	TileWidth       int             `json:"tilewidth"`
+	TileHeight      int             `json:"tileheight"`
+	Infinite        bool            `json:"infinite"`
+	StaggerAxis     string          `json:"staggeraxis"`
+	StaggerIndex    string          `json:"staggerindex"`
+	HexSideLength   int             `json:"hexsidelength"`
+	BackgroundColor string          `json:"backgroundcolor"`
+	Properties      jsonProperties  `json:"properties"`
+	Tilesets        []jsonTileset   `json:"tilesets"`
+	Layers          []jsonLayer     `json:"layers"`
+}
+
+// addLayers adds the tile layers and object groups of the given JSON layers
+// to the map. The layers of group layers are added in their place, as the
+// TMX format has no groups.
+func (m *Map) addLayers(layers []jsonLayer) error {
+	for _, jl := range layers {
+		switch jl.Type {
+		case "tilelayer":
+			l, err := jl.toLayer(m.Width, m.Height, m.Infinite)
+			if err != nil {
+				return err
+			}
+			m.Layers = append(m.Layers, l)
+		case "objectgroup":
+			m.ObjectGroups = append(m.ObjectGroups, jl.toObjectGroup())
+		case "group":
+			if err := m.addLayers(jl.Layers); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ParseJSON works just like Parse, except the data is a map in Tiled's JSON
+// format.
+func ParseJSON(data []byte) (*Map, error) {
+	x := new(jsonMap)
+	if err := json.Unmarshal(data, x); err != nil {
+		return nil, err
+	}
+
+	// The version is a number in older versions of Tiled, and a string in
+	// newer ones.
+	version := string(x.Version)
+	if len(x.Version) > 0 && x.Version[0] == '"' {
+		if err := json.Unmarshal(x.Version, &version); err != nil {
+			return nil, err
+		}
+	}
+	major, minor, err := parseVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	orient, err := parseOrientation(x.Orientation)
+	if err != nil {
+		return nil, err
+	}
+
+	tilesets := make([]*Tileset, len(x.Tilesets))
+	for i := range x.Tilesets {
+		jt := &x.Tilesets[i]
+		ts := &Tileset{
+			Firstgid: jt.Firstgid,
+			Source:   jt.Source,
+		}
+		ts.fromJSON(jt)
+		tilesets[i] = ts
+	}
+
+	m := &Map{
+		VersionMajor:    major,
+		VersionMinor:    minor,
+		Orientation:     orient,
+		StaggerAxis:     x.StaggerAxis,
+		StaggerIndex:    x.StaggerIndex,
+		HexSideLength:   x.HexSideLength,
+		Width:           x.Width,
+		Height:          x.Height,
+		Infinite:        x.Infinite,
+		TileWidth:       x.TileWidth,
+		TileHeight:      x.TileHeight,
+		BackgroundColor: hexToRGBA(x.BackgroundColor),
+		Properties:      x.Properties.toMap(),
+		Tilesets:        tilesets,
+		Layers:          []*Layer{},
+		ObjectGroups:    []*ObjectGroup{},
+	}
+	m.setStaggerDefaults()
+	if err := m.addLayers(x.Layers); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
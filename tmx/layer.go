@@ -6,26 +6,42 @@ package tmx
 
 import (
 	"fmt"
+	"image"
+	"sort"
 )
 
 type xmlLayer struct {
-	Name    string  `xml:"name,attr"`
-	Opacity float64 `xml:"opacity,attr"`
-	Visible int     `xml:"visible,attr"`
-	Data    xmlData `xml:"data"`
+	Name       string        `xml:"name,attr"`
+	Opacity    *float64      `xml:"opacity,attr"`
+	Visible    *int          `xml:"visible,attr"`
+	Properties xmlProperties `xml:"properties"`
+	Data       xmlData       `xml:"data"`
 }
 
-func (x xmlLayer) toLayer(width, height int) (*Layer, error) {
+func (x xmlLayer) toLayer(width, height int, infinite bool) (*Layer, error) {
+	l := &Layer{
+		Name:       x.Name,
+		Opacity:    1,
+		Visible:    x.Visible == nil || *x.Visible != 0,
+		Properties: x.Properties.toMap(),
+	}
+	if x.Opacity != nil {
+		l.Opacity = *x.Opacity
+	}
+	if infinite {
+		chunks, err := x.Data.chunks()
+		if err != nil {
+			return nil, err
+		}
+		l.setChunks(chunks)
+		return l, nil
+	}
 	tiles, err := x.Data.tiles(width, height)
 	if err != nil {
 		return nil, err
 	}
-	return &Layer{
-		Name:    x.Name,
-		Opacity: x.Opacity,
-		Visible: x.Visible != 0,
-		Tiles:   tiles,
-	}, nil
+	l.Tiles = tiles
+	return l, nil
 }
 
 // Coord represents a single 2D coordinate pair (x, y)
@@ -44,6 +60,9 @@ type Layer struct {
 	// Boolean value representing whether or not the layer is visible.
 	Visible bool
 
+	// Map of properties for this layer.
+	Properties Properties
+
 	// A map of 2D coordinates in this layer to so called "global tile IDs"
 	// (gids).
 	//
@@ -55,7 +74,102 @@ type Layer struct {
 	// tile is you need to find the tileset with the highest Firstgid that is
 	// still lower or equal than the gid. The tilesets are always stored with
 	// increasing firstgids.
+	//
+	// For infinite maps, coordinates may be negative and this map holds the
+	// tiles of every chunk.
 	Tiles map[Coord]uint32
+
+	// The chunks of the layer, sorted by their position (top to bottom, and
+	// then left to right), for infinite maps only.
+	Chunks []*Chunk
+}
+
+// setChunks sets the chunks of the layer, and the tiles to those of every
+// chunk.
+func (l *Layer) setChunks(chunks []*Chunk) {
+	sort.Slice(chunks, func(i, j int) bool {
+		a, b := chunks[i], chunks[j]
+		if a.Y != b.Y {
+			return a.Y < b.Y
+		}
+		return a.X < b.X
+	})
+	l.Chunks = chunks
+	l.Tiles = make(map[Coord]uint32)
+	for _, c := range chunks {
+		for coord, gid := range c.Tiles {
+			l.Tiles[coord] = gid
+		}
+	}
+}
+
+// Tile returns the global tile ID at the given coordinates of this layer, or
+// zero if there is no tile.
+func (l *Layer) Tile(x, y int) uint32 {
+	return l.Tiles[Coord{x, y}]
+}
+
+// ChunkAt returns the chunk holding the given coordinates of this layer, or
+// nil if there is none (or if the map is not infinite).
+func (l *Layer) ChunkAt(x, y int) *Chunk {
+	p := image.Pt(x, y)
+	for _, c := range l.Chunks {
+		if p.In(c.Bounds()) {
+			return c
+		}
+	}
+	return nil
+}
+
+// ChunksIn returns the chunks of this layer which overlap the given rectangle
+// of tile coordinates (e.g. the area visible on screen).
+func (l *Layer) ChunksIn(r image.Rectangle) []*Chunk {
+	var chunks []*Chunk
+	for _, c := range l.Chunks {
+		if c.Bounds().Overlaps(r) {
+			chunks = append(chunks, c)
+		}
+	}
+	return chunks
+}
+
+// Chunk represents a rectangular chunk of the tiles of a layer of an infinite
+// map. Infinite maps store their layers as chunks, such that they can be
+// realized (see ChunkCache) only where needed.
+type Chunk struct {
+	// The position of the chunk, and it's width and height, in tiles.
+	X, Y, Width, Height int
+
+	// The global tile IDs of the chunk, by their coordinates in the layer
+	// (i.e. not relative to the chunk). As with Layer.Tiles, zero gids are not
+	// stored.
+	Tiles map[Coord]uint32
+}
+
+// newChunk returns a new chunk, given it's tiles by their coordinates
+// relative to the chunk.
+func newChunk(x, y, width, height int, local map[Coord]uint32) *Chunk {
+	tiles := make(map[Coord]uint32, len(local))
+	for c, gid := range local {
+		tiles[Coord{x + c.X, y + c.Y}] = gid
+	}
+	return &Chunk{
+		X:      x,
+		Y:      y,
+		Width:  width,
+		Height: height,
+		Tiles:  tiles,
+	}
+}
+
+// Bounds returns the rectangle of tile coordinates covered by this chunk.
+func (c *Chunk) Bounds() image.Rectangle {
+	return image.Rect(c.X, c.Y, c.X+c.Width, c.Y+c.Height)
+}
+
+// String returns a string representation of this chunk.
+func (c *Chunk) String() string {
+	return fmt.Sprintf("Chunk(X=%d, Y=%d, Size=%dx%d)", c.X, c.Y, c.Width, c.Height)
 }
 
 // String returns a string representation of this layer.
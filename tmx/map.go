@@ -23,12 +23,32 @@ type Map struct {
 
 	// Orientation of the map.
 	//
-	// Like "orthogonal", "isometric" or "staggered".
+	// Like "orthogonal", "isometric", "staggered" or "hexagonal".
 	Orientation Orientation
 
+	// For staggered and hexagonal maps, the axis which is staggered: "x"
+	// (every other column is shifted down) or "y" (every other row is shifted
+	// right, the default).
+	StaggerAxis string
+
+	// For staggered and hexagonal maps, which rows or columns are shifted:
+	// "odd" (the default) or "even".
+	StaggerIndex string
+
+	// For hexagonal maps, the length in pixels of the side of a tile which is
+	// parallel to the stagger axis (e.g. the flat top and bottom sides of
+	// tiles with "y" as stagger axis).
+	HexSideLength int
+
 	// Width and height of the map in tiles.
+	//
+	// For infinite maps, these are only the size of the area shown initially
+	// in Tiled, the tiles are stored in chunks (see Layer.Chunks).
 	Width, Height int
 
+	// Whether or not the map is infinite.
+	Infinite bool
+
 	// Width and height of a tile in pixels.
 	TileWidth, TileHeight int
 
@@ -38,7 +58,7 @@ type Map struct {
 	BackgroundColor color.RGBA
 
 	// Map of property names and values for all properties set on the map.
-	Properties map[string]string
+	Properties Properties
 
 	// A list of all loaded tilesets of this map.
 	Tilesets []*Tileset
@@ -104,3 +124,20 @@ func (m *Map) TilesetRect(ts *Tileset, width, height int, spacingAndMargins bool
 	}
 	return image.Rect(cx, cy, cx+ts.Width, cy+ts.Height)
 }
+
+// Object returns the object with the given ID (see Object.ID) from any of the
+// object groups of this map, or nil if there is none. Object properties refer
+// to other objects by their ID (see Properties.Int).
+func (m *Map) Object(id int) *Object {
+	if id == 0 {
+		return nil
+	}
+	for _, g := range m.ObjectGroups {
+		for _, o := range g.Objects {
+			if o.ID == id {
+				return o
+			}
+		}
+	}
+	return nil
+}
@@ -6,22 +6,33 @@ package tmx
 
 import (
 	"fmt"
+	"image/color"
 	"strconv"
 	"strings"
 )
 
+// Rectangle represents a rectangle object, found in the Object.Value field.
+type Rectangle struct {
+	// The position and size of the rectangle.
+	//
+	// These values are identical to the parent object's fields of the same
+	// name, they are only provided for convenience.
+	X, Y, Width, Height float64
+}
+
 // Ellipse represents an ellipse object, found in the Object.Value field.
 type Ellipse struct {
 	// The position and size of the ellipse.
 	//
 	// These values are identical to the parent object's fields of the same
 	// name, they are only provided for convenience.
-	X, Y, Width, Height int
+	X, Y, Width, Height float64
 }
 
-// Point represents a single point.
+// Point represents a single point. A point object is also represented by a
+// *Point of it's position, found in the Object.Value field.
 type Point struct {
-	X, Y int
+	X, Y float64
 }
 
 // Polygon represents a polygon object, found in the Object.Value field.
@@ -30,9 +41,9 @@ type Polygon struct {
 	//
 	// These values are identical to the parent object's fields of the same
 	// name, they are only provided for convenience.
-	X, Y int
+	X, Y float64
 
-	// Points making up the polygon, in pixels.
+	// Points making up the polygon, in pixels relative to the origin.
 	Points []Point
 }
 
@@ -42,25 +53,58 @@ type Polyline struct {
 	//
 	// These values are identical to the parent object's fields of the same
 	// name, they are only provided for convenience.
-	X, Y int
+	X, Y float64
 
-	// Points making up the polyline, in pixels.
+	// Points making up the polyline, in pixels relative to the origin.
 	Points []Point
 }
 
+// Text represents a text object, found in the Object.Value field. The text is
+// laid out within the object's rectangle.
+type Text struct {
+	// The text itself.
+	Text string
+
+	// The font family, e.g. "sans-serif".
+	FontFamily string
+
+	// The size of the font in pixels, 16 by default.
+	PixelSize int
+
+	// Whether or not word wrapping is enabled.
+	Wrap bool
+
+	// The color of the text, black by default.
+	Color color.RGBA
+
+	// The style of the font.
+	Bold, Italic, Underline, Strikeout bool
+
+	// Whether or not kerning is used, true by default.
+	Kerning bool
+
+	// The horizontal alignment of the text: "left" (default), "center",
+	// "right" or "justify".
+	HAlign string
+
+	// The vertical alignment of the text: "top" (default), "center" or
+	// "bottom".
+	VAlign string
+}
+
 // Polygon and Polyset are identical, we share definitions here.
 type xmlPolyset struct {
 	Data string `xml:"points,attr"`
 }
 
 func (x xmlPolyset) toPoints() (points []Point) {
-	pairs := strings.Split(x.Data, " ")
+	pairs := strings.Fields(x.Data)
 	points = make([]Point, 0, len(pairs))
 	for _, pair := range pairs {
-		xy := strings.Split(strings.TrimSpace(pair), ",")
+		xy := strings.Split(pair, ",")
 		if len(xy) == 2 {
-			x, _ := strconv.Atoi(strings.TrimSpace(xy[0]))
-			y, _ := strconv.Atoi(strings.TrimSpace(xy[1]))
+			x, _ := strconv.ParseFloat(strings.TrimSpace(xy[0]), 64)
+			y, _ := strconv.ParseFloat(strings.TrimSpace(xy[1]), 64)
 			points = append(points, Point{
 				X: x,
 				Y: y,
@@ -70,20 +114,73 @@ func (x xmlPolyset) toPoints() (points []Point) {
 	return points
 }
 
+type xmlText struct {
+	FontFamily string `xml:"fontfamily,attr"`
+	PixelSize  *int   `xml:"pixelsize,attr"`
+	Wrap       int    `xml:"wrap,attr"`
+	Color      string `xml:"color,attr"`
+	Bold       int    `xml:"bold,attr"`
+	Italic     int    `xml:"italic,attr"`
+	Underline  int    `xml:"underline,attr"`
+	Strikeout  int    `xml:"strikeout,attr"`
+	Kerning    *int   `xml:"kerning,attr"`
+	HAlign     string `xml:"halign,attr"`
+	VAlign     string `xml:"valign,attr"`
+	Data       string `xml:",chardata"`
+}
+
+func (x xmlText) toText() *Text {
+	t := &Text{
+		Text:       x.Data,
+		FontFamily: x.FontFamily,
+		PixelSize:  16,
+		Wrap:       x.Wrap != 0,
+		Bold:       x.Bold != 0,
+		Italic:     x.Italic != 0,
+		Underline:  x.Underline != 0,
+		Strikeout:  x.Strikeout != 0,
+		Kerning:    x.Kerning == nil || *x.Kerning != 0,
+		HAlign:     x.HAlign,
+		VAlign:     x.VAlign,
+	}
+	if x.PixelSize != nil {
+		t.PixelSize = *x.PixelSize
+	}
+	if len(x.Color) > 0 {
+		t.Color = hexToRGBA(x.Color)
+	} else {
+		t.Color = color.RGBA{0, 0, 0, 255}
+	}
+	if len(t.FontFamily) == 0 {
+		t.FontFamily = "sans-serif"
+	}
+	if len(t.HAlign) == 0 {
+		t.HAlign = "left"
+	}
+	if len(t.VAlign) == 0 {
+		t.VAlign = "top"
+	}
+	return t
+}
+
 type xmlObject struct {
+	ID         int           `xml:"id,attr"`
 	Name       string        `xml:"name,attr"`
 	Type       string        `xml:"type,attr"`
-	X          int           `xml:"x,attr"`
-	Y          int           `xml:"y,attr"`
-	Width      int           `xml:"width,attr"`
-	Height     int           `xml:"height,attr"`
+	Class      string        `xml:"class,attr"`
+	X          float64       `xml:"x,attr"`
+	Y          float64       `xml:"y,attr"`
+	Width      float64       `xml:"width,attr"`
+	Height     float64       `xml:"height,attr"`
 	Rotation   float64       `xml:"rotation,attr"`
-	Gid        int           `xml:"gid,attr"`
-	Visible    int           `xml:"visible,attr"`
+	Gid        uint32        `xml:"gid,attr"`
+	Visible    *int          `xml:"visible,attr"`
 	Properties xmlProperties `xml:"properties"`
-	Ellipse    *string       `xml:"ellipse"`
-	Polygon    xmlPolyset    `xml:"polygon"`
-	Polyline   xmlPolyset    `xml:"polyline"`
+	Ellipse    *struct{}     `xml:"ellipse"`
+	Point      *struct{}     `xml:"point"`
+	Polygon    *xmlPolyset   `xml:"polygon"`
+	Polyline   *xmlPolyset   `xml:"polyline"`
+	Text       *xmlText      `xml:"text"`
 
 	// FIXME: alledgedly, object tags can have images under them, but it's not
 	// clear what that would mean. There also is no way to create one in
@@ -92,42 +189,62 @@ type xmlObject struct {
 }
 
 func (x xmlObject) toValue() interface{} {
-	if x.Ellipse != nil {
+	switch {
+	case x.Ellipse != nil:
 		return &Ellipse{
 			X:      x.X,
 			Y:      x.Y,
 			Width:  x.Width,
 			Height: x.Height,
 		}
-	}
-	if len(x.Polygon.Data) > 0 {
+	case x.Point != nil:
+		return &Point{
+			X: x.X,
+			Y: x.Y,
+		}
+	case x.Polygon != nil:
 		return &Polygon{
 			X:      x.X,
 			Y:      x.Y,
 			Points: x.Polygon.toPoints(),
 		}
-	}
-	if len(x.Polyline.Data) > 0 {
+	case x.Polyline != nil:
 		return &Polyline{
 			X:      x.X,
 			Y:      x.Y,
 			Points: x.Polyline.toPoints(),
 		}
+	case x.Text != nil:
+		return x.Text.toText()
+	case x.Gid != 0:
+		// A tile object, it's tile is the value.
+		return nil
+	}
+	return &Rectangle{
+		X:      x.X,
+		Y:      x.Y,
+		Width:  x.Width,
+		Height: x.Height,
 	}
-	return nil
 }
 
 func (x xmlObject) toObject() *Object {
+	typ := x.Type
+	if len(typ) == 0 {
+		// Tiled 1.9 renamed the type of objects to class.
+		typ = x.Class
+	}
 	return &Object{
+		ID:         x.ID,
 		Name:       x.Name,
-		Type:       x.Type,
+		Type:       typ,
 		X:          x.X,
 		Y:          x.Y,
 		Width:      x.Width,
 		Height:     x.Height,
 		Rotation:   x.Rotation,
-		Gid:        uint32(x.Gid),
-		Visible:    x.Visible != 0,
+		Gid:        x.Gid,
+		Visible:    x.Visible == nil || *x.Visible != 0,
 		Properties: x.Properties.toMap(),
 		Value:      x.toValue(),
 	}
@@ -136,6 +253,10 @@ func (x xmlObject) toObject() *Object {
 // Object represents a single object, which are generally used to add custom
 // information to tile maps, like collision information, spawn points, etc.
 type Object struct {
+	// The unique ID of this object within the map, or zero for maps saved by
+	// older versions of Tiled.
+	ID int
+
 	// The name of this object.
 	Name string
 
@@ -144,7 +265,7 @@ type Object struct {
 
 	// The X and Y coordinates, as well as the width and height of this object
 	// in pixels.
-	X, Y, Width, Height int
+	X, Y, Width, Height float64
 
 	// The rotation of this object in degrees clockwise.
 	Rotation float64
@@ -157,25 +278,28 @@ type Object struct {
 	//  Isometric - Aligned to the bottom-center
 	Gid uint32
 
-	// Boolean value representing whether or not the object group is visible.
+	// Boolean value representing whether or not the object is visible.
 	Visible bool
 
-	// Map of properties for this object group.
-	Properties map[string]string
+	// Map of properties for this object.
+	Properties Properties
 
-	// Value represents the underlying object value (which is sometimes nil).
-	// You can use a type switch to determine it's value:
+	// Value represents the underlying object value, the shape of the object.
+	// It is nil for tile objects (see Gid). You can use a type switch to
+	// determine it's value:
 	//  switch v := obj.Value.(type) {
+	//  case *tmx.Rectangle: handleRectangle(obj, v)
 	//  case *tmx.Ellipse: handleEllipse(obj, v)
+	//  case *tmx.Point: handlePoint(obj, v)
 	//  case *tmx.Polygon: handlePolygon(obj, v)
 	//  case *tmx.Polyline: handlePolyline(obj, v)
-	//  case *tmx.Image: handleImage(obj, v)
+	//  case *tmx.Text: handleText(obj, v)
 	//  }
 	Value interface{}
 }
 
 // String returns a string representation of this object, like:
-//  Object(Name="the name", X=%d, Y=%d, Width=%d, Height=%d)
+//  Object(Name="the name", X=%v, Y=%v, Width=%v, Height=%v)
 func (o *Object) String() string {
-	return fmt.Sprintf("Object(Name=%q, X=%d, Y=%d, Width=%d, Height=%d)", o.Name, o.X, o.Y, o.Width, o.Height)
+	return fmt.Sprintf("Object(Name=%q, X=%v, Y=%v, Width=%v, Height=%v)", o.Name, o.X, o.Y, o.Width, o.Height)
 }
@@ -14,8 +14,11 @@ import (
 type xmlObjectgroup struct {
 	Name       string        `xml:"name,attr"`
 	Color      string        `xml:"color,attr"`
-	Opacity    float64       `xml:"opacity,attr"`
-	Visible    int           `xml:"visible,attr"`
+	Opacity    *float64      `xml:"opacity,attr"`
+	Visible    *int          `xml:"visible,attr"`
+	OffsetX    float64       `xml:"offsetx,attr"`
+	OffsetY    float64       `xml:"offsety,attr"`
+	DrawOrder  string        `xml:"draworder,attr"`
 	Properties xmlProperties `xml:"properties"`
 	Object     []xmlObject   `xml:"object"`
 }
@@ -25,14 +28,24 @@ func (x xmlObjectgroup) toObjectGroup() *ObjectGroup {
 	for i, o := range x.Object {
 		objects[i] = o.toObject()
 	}
-	return &ObjectGroup{
+	g := &ObjectGroup{
 		Name:       x.Name,
 		Color:      hexToRGBA(x.Color),
-		Opacity:    x.Opacity,
-		Visible:    x.Visible != 0,
+		Opacity:    1,
+		Visible:    x.Visible == nil || *x.Visible != 0,
+		OffsetX:    x.OffsetX,
+		OffsetY:    x.OffsetY,
+		DrawOrder:  x.DrawOrder,
 		Properties: x.Properties.toMap(),
 		Objects:    objects,
 	}
+	if x.Opacity != nil {
+		g.Opacity = *x.Opacity
+	}
+	if len(g.DrawOrder) == 0 {
+		g.DrawOrder = "topdown"
+	}
+	return g
 }
 
 // ObjectGroup represents a group of objects.
@@ -49,8 +62,15 @@ type ObjectGroup struct {
 	// Boolean value representing whether or not the object group is visible.
 	Visible bool
 
+	// The offset of the objects in this object group in pixels.
+	OffsetX, OffsetY float64
+
+	// The order in which the objects are drawn: "topdown" (sorted by their Y
+	// coordinate) or "index" (in the order of the Objects slice).
+	DrawOrder string
+
 	// Map of properties for this object group.
-	Properties map[string]string
+	Properties Properties
 
 	// List of objects in this object group.
 	Objects []*Object
@@ -61,3 +81,14 @@ type ObjectGroup struct {
 func (o *ObjectGroup) String() string {
 	return fmt.Sprintf("ObjectGroup(Name=%q, %d objects)", o.Name, len(o.Objects))
 }
+
+// Find returns the first object in this object group with the given name, or
+// nil if there is none.
+func (o *ObjectGroup) Find(name string) *Object {
+	for _, obj := range o.Objects {
+		if obj.Name == name {
+			return obj
+		}
+	}
+	return nil
+}
@@ -18,6 +18,27 @@ const (
 	// Isometric map orientation
 	Isometric
 
-	// Staggered map orientation
+	// Staggered map orientation (isometric tiles, with every other row or
+	// column shifted, see Map.StaggerAxis).
 	Staggered
+
+	// Hexagonal map orientation (hexagonal tiles, with every other row or
+	// column shifted, see Map.StaggerAxis).
+	Hexagonal
 )
+
+// String returns the name of this orientation as found in TMX files, e.g.
+// "orthogonal".
+func (o Orientation) String() string {
+	switch o {
+	case Orthogonal:
+		return "orthogonal"
+	case Isometric:
+		return "isometric"
+	case Staggered:
+		return "staggered"
+	case Hexagonal:
+		return "hexagonal"
+	}
+	return "invalid"
+}
@@ -0,0 +1,240 @@
+// Copyright 2014 Lightpoke. All rights reserved.
+// This source code is subject to the terms and
+// conditions defined in the "License.txt" file.
+
+package tmx
+
+import (
+	"math"
+	"sort"
+
+	"azul3d.org/engine/lmath"
+	"azul3d.org/engine/physics2d"
+)
+
+// The number of vertices of the polygons approximating (non-circular)
+// ellipses.
+const ellipseVertices = 16
+
+// signedArea returns twice the signed area of the polygon, which is positive
+// if it's vertices are in counter-clockwise order.
+func signedArea(pts []lmath.Vec2) float64 {
+	var a float64
+	for i, p := range pts {
+		q := pts[(i+1)%len(pts)]
+		a += p.X*q.Y - q.X*p.Y
+	}
+	return a
+}
+
+// cross returns the cross product of the vectors b-a and c-b.
+func cross(a, b, c lmath.Vec2) float64 {
+	ab, bc := b.Sub(a), c.Sub(b)
+	return ab.X*bc.Y - ab.Y*bc.X
+}
+
+// isConvex tells if the polygon is convex.
+func isConvex(pts []lmath.Vec2) bool {
+	var pos, neg bool
+	for i := range pts {
+		c := cross(pts[i], pts[(i+1)%len(pts)], pts[(i+2)%len(pts)])
+		pos = pos || c > 0
+		neg = neg || c < 0
+	}
+	return !(pos && neg)
+}
+
+// triangulate splits the simple polygon into triangles, by ear clipping.
+func triangulate(pts []lmath.Vec2) [][3]lmath.Vec2 {
+	// Clip ears of the polygon in counter-clockwise order.
+	idx := make([]int, len(pts))
+	for i := range idx {
+		idx[i] = i
+	}
+	if signedArea(pts) < 0 {
+		for i, j := 0, len(idx)-1; i < j; i, j = i+1, j-1 {
+			idx[i], idx[j] = idx[j], idx[i]
+		}
+	}
+	var tris [][3]lmath.Vec2
+	for len(idx) > 3 {
+		clipped := false
+		for i := range idx {
+			ia, ic := idx[(i+len(idx)-1)%len(idx)], idx[(i+1)%len(idx)]
+			a, b, c := pts[ia], pts[idx[i]], pts[ic]
+			if cross(a, b, c) <= 0 {
+				// A reflex vertex.
+				continue
+			}
+			ear := true
+			for _, j := range idx {
+				if j == ia || j == idx[i] || j == ic {
+					continue
+				}
+				p := pts[j]
+				if cross(a, b, p) >= 0 && cross(b, c, p) >= 0 && cross(c, a, p) >= 0 {
+					ear = false
+					break
+				}
+			}
+			if !ear {
+				continue
+			}
+			tris = append(tris, [3]lmath.Vec2{a, b, c})
+			idx = append(idx[:i], idx[i+1:]...)
+			clipped = true
+			break
+		}
+		if !clipped {
+			// Not a simple polygon.
+			return tris
+		}
+	}
+	return append(tris, [3]lmath.Vec2{pts[idx[0]], pts[idx[1]], pts[idx[2]]})
+}
+
+// appendPolygon appends the polygon to the shapes, split into convex pieces
+// if it is concave. Degenerate polygons are omitted.
+func appendPolygon(shapes []physics2d.Shape, pts []lmath.Vec2) []physics2d.Shape {
+	if len(pts) < 3 || math.Abs(signedArea(pts)) < lmath.EPSILON {
+		return shapes
+	}
+	if isConvex(pts) {
+		return append(shapes, physics2d.NewPolygon(pts...))
+	}
+	for _, t := range triangulate(pts) {
+		if math.Abs(signedArea(t[:])) < lmath.EPSILON {
+			continue
+		}
+		shapes = append(shapes, physics2d.NewPolygon(t[:]...))
+	}
+	return shapes
+}
+
+// TileShapes returns the collision shapes of the tile with the given global
+// tile ID (see Tile.ObjectGroup) placed at the given tile coordinates, for use
+// as the fixtures of a static physics2d body at the origin. Tiles without
+// collision objects have no shapes.
+//
+// Shapes are positioned like the tiles of the map loaded for rendering (see
+// Load): in pixels, with +Y up and the bottom of the map at zero, multiplied
+// by the given scale (e.g. 1.0/32 for tiles of 32 pixels that are one meter
+// wide).
+//
+// Rectangles, ellipses and polygons are converted to physics2d shapes, with
+// the tile's flip flags applied. Ellipses that are not circles are
+// approximated by polygons, and concave polygons are split into triangles.
+// Points and polylines have no area and are omitted.
+func (m *Map) TileShapes(gid uint32, x, y int, scale float64) []physics2d.Shape {
+	ts := m.FindTileset(gid)
+	if ts == nil {
+		return nil
+	}
+	t := m.TilesetTile(ts, gid)
+	if t == nil || t.ObjectGroup == nil {
+		return nil
+	}
+
+	// Tile images are anchored at the bottom-left of their cell.
+	px, py := m.TileToPixel(x, y)
+	py += float64(m.TileHeight - ts.Height)
+	_, mapHeight := m.PixelSize()
+
+	tw, th := float64(ts.Width), float64(ts.Height)
+	diagFlipped := (gid & FLIPPED_DIAGONALLY_FLAG) > 0
+	horizFlipped := (gid & FLIPPED_HORIZONTALLY_FLAG) > 0
+	vertFlipped := (gid & FLIPPED_VERTICALLY_FLAG) > 0
+
+	// toWorld converts a point relative to the tile to the world.
+	toWorld := func(p Point) lmath.Vec2 {
+		if diagFlipped {
+			p.X, p.Y = p.Y, p.X
+		}
+		if horizFlipped {
+			p.X = tw - p.X
+		}
+		if vertFlipped {
+			p.Y = th - p.Y
+		}
+		return lmath.Vec2{
+			X: (px + p.X) * scale,
+			Y: (float64(mapHeight) - (py + p.Y)) * scale,
+		}
+	}
+
+	g := t.ObjectGroup
+	var shapes []physics2d.Shape
+	for _, o := range g.Objects {
+		// Objects rotate clockwise around their position.
+		sin, cos := math.Sincos(lmath.Radians(o.Rotation))
+		point := func(dx, dy float64) lmath.Vec2 {
+			return toWorld(Point{
+				X: g.OffsetX + o.X + dx*cos - dy*sin,
+				Y: g.OffsetY + o.Y + dx*sin + dy*cos,
+			})
+		}
+
+		switch v := o.Value.(type) {
+		case *Rectangle:
+			shapes = appendPolygon(shapes, []lmath.Vec2{
+				point(0, 0),
+				point(v.Width, 0),
+				point(v.Width, v.Height),
+				point(0, v.Height),
+			})
+
+		case *Ellipse:
+			rx, ry := v.Width/2, v.Height/2
+			if math.Abs(rx-ry) < lmath.EPSILON {
+				if rx*scale > lmath.EPSILON {
+					shapes = append(shapes, &physics2d.Circle{
+						Center: point(rx, ry),
+						Radius: rx * scale,
+					})
+				}
+				continue
+			}
+			pts := make([]lmath.Vec2, ellipseVertices)
+			for i := range pts {
+				s, c := math.Sincos(2 * math.Pi * float64(i) / ellipseVertices)
+				pts[i] = point(rx+rx*c, ry+ry*s)
+			}
+			shapes = appendPolygon(shapes, pts)
+
+		case *Polygon:
+			pts := make([]lmath.Vec2, len(v.Points))
+			for i, p := range v.Points {
+				pts[i] = point(p.X, p.Y)
+			}
+			shapes = appendPolygon(shapes, pts)
+		}
+	}
+	return shapes
+}
+
+// CollisionShapes returns the collision shapes of every tile of the layer
+// (see TileShapes), for use as the fixtures of a single static physics2d body
+// at the origin, e.g.:
+//
+//  ground := world.AddBody(physics2d.Static)
+//  for _, s := range m.CollisionShapes(layer, 1.0/32) {
+//      ground.AddFixture(s, 0)
+//  }
+//
+func (m *Map) CollisionShapes(l *Layer, scale float64) []physics2d.Shape {
+	coords := make([]Coord, 0, len(l.Tiles))
+	for c := range l.Tiles {
+		coords = append(coords, c)
+	}
+	sort.Slice(coords, func(i, j int) bool {
+		if coords[i].Y != coords[j].Y {
+			return coords[i].Y < coords[j].Y
+		}
+		return coords[i].X < coords[j].X
+	})
+	var shapes []physics2d.Shape
+	for _, c := range coords {
+		shapes = append(shapes, m.TileShapes(l.Tiles[c], c.X, c.Y, scale)...)
+	}
+	return shapes
+}
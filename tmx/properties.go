@@ -0,0 +1,100 @@
+// Copyright 2014 Lightpoke. All rights reserved.
+// This source code is subject to the terms and
+// conditions defined in the "License.txt" file.
+
+package tmx
+
+import (
+	"image/color"
+	"strconv"
+)
+
+type xmlProperty struct {
+	Name  string `xml:"name,attr"`
+	Type  string `xml:"type,attr"`
+	Value string `xml:"value,attr"`
+
+	// Multi-line string values are stored as the element's text instead of
+	// the value attribute.
+	Text string `xml:",chardata"`
+}
+
+type xmlProperties struct {
+	Property []xmlProperty `xml:"property"`
+}
+
+func (p xmlProperties) toMap() Properties {
+	m := make(Properties, len(p.Property))
+	for _, p := range p.Property {
+		v := p.Value
+		if len(v) == 0 {
+			v = p.Text
+		}
+		m[p.Name] = v
+	}
+	return m
+}
+
+// Properties represents the custom properties of a map, tileset, tile, layer,
+// object group or object, as a map of property names to their values.
+//
+// Tiled stores every property as text regardless of it's type (string, int,
+// float, bool, color, file or object), the typed accessors parse the text of
+// a property as the given type.
+type Properties map[string]string
+
+// Int returns the named property as an integer. If the property does not
+// exist or is not an integer, ok is false.
+//
+// Object properties are integers, the ID of the referenced object (see
+// Map.Object).
+func (p Properties) Int(name string) (v int, ok bool) {
+	s, ok := p[name]
+	if !ok {
+		return 0, false
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// Float returns the named property as a floating point number. If the
+// property does not exist or is not a number, ok is false.
+func (p Properties) Float(name string) (v float64, ok bool) {
+	s, ok := p[name]
+	if !ok {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// Bool returns the named property as a boolean. If the property does not
+// exist or is not a boolean (e.g. "true" or "false"), ok is false.
+func (p Properties) Bool(name string) (v bool, ok bool) {
+	s, ok := p[name]
+	if !ok {
+		return false, false
+	}
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, false
+	}
+	return v, true
+}
+
+// Color returns the named property as a color. Tiled writes colors as
+// "#AARRGGBB" (or "#RRGGBB"). If the property does not exist or is not a
+// color, ok is false.
+func (p Properties) Color(name string) (v color.RGBA, ok bool) {
+	s, ok := p[name]
+	if !ok {
+		return color.RGBA{}, false
+	}
+	return parseHexColor(s)
+}
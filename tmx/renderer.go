@@ -0,0 +1,289 @@
+// Copyright 2014 Lightpoke. All rights reserved.
+// This source code is subject to the terms and
+// conditions defined in the "License.txt" file.
+
+package tmx
+
+import (
+	"image"
+	"sort"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/lmath"
+)
+
+// DefaultChunkSize is the default width and height, in tiles, of the chunks
+// of a Renderer.
+const DefaultChunkSize = 16
+
+// chunkKey identifies a chunk of a layer, by it's position in chunks.
+type chunkKey struct {
+	layer, x, y int
+}
+
+// renderChunk is a chunk of a layer, as built by a Renderer.
+type renderChunk struct {
+	key     chunkKey
+	objects []*gfx.Object
+	dirty   bool
+}
+
+// destroy destroys the objects of the chunk.
+func (c *renderChunk) destroy() {
+	for _, o := range c.objects {
+		for _, m := range o.Meshes {
+			m.Destroy()
+		}
+		o.Destroy()
+	}
+	c.objects = nil
+}
+
+// Renderer renders the tile layers of a map at runtime. The tiles of each
+// layer are split into square chunks, each batched into a single static mesh
+// per tileset image, such that:
+//
+//  - Chunks outside of the camera's view are not drawn (see Visible).
+//  - Editing a tile only rebuilds the chunk holding it (see SetTile).
+//
+// Unlike Load, which creates a single object per layer and tileset image, the
+// renderer scales to large (and infinite) maps.
+//
+// Clients should ensure properly synchronized access to the renderer, as it's
+// methods do not attempt any synchronization.
+type Renderer struct {
+	// The transform that the objects of every chunk are parented to, e.g. to
+	// position the map in the world.
+	Transform *gfx.Transform
+
+	m         *Map
+	c         *Config
+	tsImages  map[string]*image.RGBA
+	textures  map[string]*gfx.Texture
+	chunkSize int
+	chunks    map[chunkKey]*renderChunk
+
+	// The chunks sorted in draw order, or nil if chunks were added or removed
+	// since they were last sorted.
+	sorted []*renderChunk
+}
+
+// chunkOf returns the key of the chunk of the layer holding the given tile.
+func (r *Renderer) chunkOf(layer, x, y int) chunkKey {
+	return chunkKey{
+		layer: layer,
+		x:     floorDiv(x, r.chunkSize),
+		y:     floorDiv(y, r.chunkSize),
+	}
+}
+
+// floorDiv returns a divided by b, rounded down.
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+// markDirty marks the chunk with the given key to be rebuilt, adding it if
+// needed.
+func (r *Renderer) markDirty(k chunkKey) {
+	c, ok := r.chunks[k]
+	if !ok {
+		c = &renderChunk{key: k}
+		r.chunks[k] = c
+		r.sorted = nil
+	}
+	c.dirty = true
+}
+
+// build rebuilds the objects of the chunk, removing it if it has no tiles.
+func (r *Renderer) build(c *renderChunk) {
+	c.destroy()
+	c.dirty = false
+
+	bounds := image.Rect(0, 0, r.chunkSize, r.chunkSize).Add(image.Pt(c.key.x*r.chunkSize, c.key.y*r.chunkSize))
+	layerOffset := -float64(c.key.layer) * r.c.LayerOffset
+	texObjects := make(map[string]*gfx.Object)
+	appendTiles(texObjects, r.textures, r.m, r.c, r.tsImages, r.m.Layers[c.key.layer], bounds, layerOffset)
+	if len(texObjects) == 0 {
+		delete(r.chunks, c.key)
+		r.sorted = nil
+		return
+	}
+
+	// Sort the objects by tileset image, for a consistent order.
+	names := make([]string, 0, len(texObjects))
+	for name := range texObjects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		o := texObjects[name]
+		o.Transform.SetParent(r.Transform)
+
+		// Chunks are culled by their bounds, which Mesh.CalculateBounds
+		// would extend to the origin.
+		for _, mesh := range o.Meshes {
+			mesh.AABB = vertexBounds(mesh.Vertices)
+		}
+		c.objects = append(c.objects, o)
+	}
+}
+
+// vertexBounds returns the bounding box of the vertices.
+func vertexBounds(vertices []gfx.Vec3) lmath.Rect3 {
+	var b lmath.Rect3
+	for i, v32 := range vertices {
+		v := v32.Vec3()
+		if i == 0 {
+			b.Min, b.Max = v, v
+			continue
+		}
+		b.Min = b.Min.Min(v)
+		b.Max = b.Max.Max(v)
+	}
+	return b
+}
+
+// update rebuilds the dirty chunks, and sorts the chunks in draw order if
+// needed.
+func (r *Renderer) update() {
+	for _, c := range r.chunks {
+		if c.dirty {
+			r.build(c)
+		}
+	}
+	if r.sorted != nil {
+		return
+	}
+	r.sorted = make([]*renderChunk, 0, len(r.chunks))
+	for _, c := range r.chunks {
+		r.sorted = append(r.sorted, c)
+	}
+	sort.Slice(r.sorted, func(i, j int) bool {
+		a, b := r.sorted[i].key, r.sorted[j].key
+		if a.layer != b.layer {
+			return a.layer < b.layer
+		}
+		if a.y != b.y {
+			return a.y < b.y
+		}
+		return a.x < b.x
+	})
+}
+
+// SetTile sets the global tile ID of the tile at the given coordinates of the
+// layer at index i of the map (i.e. m.Layers[i]). A gid of zero removes the
+// tile. Only the chunk holding the tile is rebuilt, once it is next needed.
+func (r *Renderer) SetTile(i, x, y int, gid uint32) {
+	l := r.m.Layers[i]
+	if gid == 0 {
+		if _, ok := l.Tiles[Coord{x, y}]; !ok {
+			return
+		}
+		delete(l.Tiles, Coord{x, y})
+	} else {
+		if l.Tiles == nil {
+			l.Tiles = make(map[Coord]uint32)
+		}
+		l.Tiles[Coord{x, y}] = gid
+	}
+	r.markDirty(r.chunkOf(i, x, y))
+}
+
+// RefreshAnimated rebuilds (once they are next needed) the chunks holding
+// animated tiles, e.g. after Config.Animator reported that a frame changed.
+func (r *Renderer) RefreshAnimated() {
+	if r.c.Animator == nil {
+		return
+	}
+	for i, l := range r.m.Layers {
+		for c, gid := range l.Tiles {
+			if r.c.Animator.Animated(gid) {
+				r.markDirty(r.chunkOf(i, c.X, c.Y))
+			}
+		}
+	}
+}
+
+// Objects returns the objects of every chunk, in the order they must be
+// drawn (by layer, and then from the top of the map to it's bottom). Chunks
+// whose tiles changed are rebuilt first.
+func (r *Renderer) Objects() []*gfx.Object {
+	r.update()
+	var objs []*gfx.Object
+	for _, c := range r.sorted {
+		objs = append(objs, c.objects...)
+	}
+	return objs
+}
+
+// Visible works just like Objects, except it only returns the objects of the
+// chunks overlapping the given viewing frustum, e.g. that of the camera (see
+// camera.Camera.Frustum).
+func (r *Renderer) Visible(f lmath.Frustum) []*gfx.Object {
+	r.update()
+	var objs []*gfx.Object
+	for _, c := range r.sorted {
+		for _, o := range c.objects {
+			if f.OverlapsRect3(o.Bounds()) {
+				objs = append(objs, o)
+			}
+		}
+	}
+	return objs
+}
+
+// Draw draws the chunks overlapping the viewing frustum, f, to the given
+// rectangle of the canvas using the camera (see Visible).
+func (r *Renderer) Draw(canvas gfx.Canvas, rect image.Rectangle, cam gfx.Camera, f lmath.Frustum) {
+	for _, o := range r.Visible(f) {
+		canvas.Draw(rect, o, cam)
+	}
+}
+
+// Len returns the number of chunks of the renderer.
+func (r *Renderer) Len() int {
+	r.update()
+	return len(r.chunks)
+}
+
+// Destroy destroys the objects of every chunk. The renderer must not be used
+// afterwards.
+func (r *Renderer) Destroy() {
+	for _, c := range r.chunks {
+		c.destroy()
+	}
+	r.chunks = nil
+	r.sorted = nil
+}
+
+// NewRenderer returns a new renderer for the tile layers of the given map,
+// with chunks of chunkSize by chunkSize tiles (or DefaultChunkSize, if it is
+// zero). The configuration, c, and tileset images are used just like with
+// Load.
+func NewRenderer(m *Map, c *Config, tsImages map[string]*image.RGBA, chunkSize int) *Renderer {
+	if c == nil {
+		c = defaultConfig()
+	}
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	r := &Renderer{
+		Transform: gfx.NewTransform(),
+		m:         m,
+		c:         c,
+		tsImages:  tsImages,
+		textures:  make(map[string]*gfx.Texture),
+		chunkSize: chunkSize,
+		chunks:    make(map[chunkKey]*renderChunk),
+	}
+	for i, l := range m.Layers {
+		for c := range l.Tiles {
+			r.markDirty(r.chunkOf(i, c.X, c.Y))
+		}
+	}
+	return r
+}
@@ -9,6 +9,7 @@ import (
 	"encoding/csv"
 	"fmt"
 	"strconv"
+	"time"
 )
 
 const (
@@ -29,6 +30,30 @@ type xmlTile struct {
 	Probability float64       `xml:"probability,attr"`
 	Properties  xmlProperties `xml:"properties"`
 	Image       xmlImage      `xml:"image"`
+	Animation   *struct {
+		Frame []xmlFrame `xml:"frame"`
+	} `xml:"animation"`
+	Objectgroup *xmlObjectgroup `xml:"objectgroup"`
+}
+
+type xmlFrame struct {
+	TileID   int `xml:"tileid,attr" json:"tileid"`
+	Duration int `xml:"duration,attr" json:"duration"`
+}
+
+// toFrames converts the given frames, whose durations are in milliseconds.
+func toFrames(x []xmlFrame) []Frame {
+	if len(x) == 0 {
+		return nil
+	}
+	frames := make([]Frame, len(x))
+	for i, xf := range x {
+		frames[i] = Frame{
+			TileID:   xf.TileID,
+			Duration: time.Duration(xf.Duration) * time.Millisecond,
+		}
+	}
+	return frames
 }
 
 func (x xmlTile) terrainArray() (indices [4]int) {
@@ -55,13 +80,20 @@ func (x xmlTile) terrainArray() (indices [4]int) {
 }
 
 func (x xmlTile) toTile() *Tile {
-	return &Tile{
+	t := &Tile{
 		ID:          x.ID,
 		Terrain:     x.terrainArray(),
 		Probability: x.Probability,
 		Properties:  x.Properties.toMap(),
 		Image:       x.Image.toImage(),
 	}
+	if x.Animation != nil {
+		t.Animation = toFrames(x.Animation.Frame)
+	}
+	if x.Objectgroup != nil {
+		t.ObjectGroup = x.Objectgroup.toObjectGroup()
+	}
+	return t
 }
 
 // Tile represents a single tile definition and it's properties
@@ -81,10 +113,60 @@ type Tile struct {
 	Probability float64
 
 	// Map of properties for the tile
-	Properties map[string]string
+	Properties Properties
 
 	// Image for the tile
 	Image *Image
+
+	// The frames of the animation of this tile, or nil if the tile is not
+	// animated (see Animator).
+	Animation []Frame
+
+	// The collision objects of this tile, or nil if it has none (see
+	// Map.TileShapes). Their positions are in pixels relative to the top-left
+	// corner of the tile.
+	ObjectGroup *ObjectGroup
+}
+
+// Frame is a single frame of the animation of a tile.
+type Frame struct {
+	// The local ID of the tile shown during this frame, within the tileset of
+	// the animated tile.
+	TileID int
+
+	// How long the frame is shown for.
+	Duration time.Duration
+}
+
+// AnimationLength returns the duration of one loop of the animation of this
+// tile, or zero if it is not animated.
+func (t *Tile) AnimationLength() time.Duration {
+	var d time.Duration
+	for _, f := range t.Animation {
+		d += f.Duration
+	}
+	return d
+}
+
+// FrameAt returns the local ID of the tile shown by the (looping) animation
+// of this tile, once the given time has elapsed since it's start. If the tile
+// is not animated, it's own ID is returned.
+func (t *Tile) FrameAt(elapsed time.Duration) int {
+	length := t.AnimationLength()
+	if length <= 0 {
+		return t.ID
+	}
+	elapsed %= length
+	if elapsed < 0 {
+		elapsed += length
+	}
+	for _, f := range t.Animation {
+		if elapsed < f.Duration {
+			return f.TileID
+		}
+		elapsed -= f.Duration
+	}
+	return t.Animation[len(t.Animation)-1].TileID
 }
 
 // String returns a string representation of this tileset.
@@ -10,15 +10,15 @@ import (
 )
 
 type xmlTileset struct {
-	Raw          []byte `xml:",innerxml"`
-	Firstgid     uint32 `xml:"firstgid,attr"`
-	Source       string `xml:"source,attr"`
-	Name         string `xml:"name,attr"`
-	TileWidth    int    `xml:"tilewidth,attr"`
-	TileHeight   int    `xml:"tileheight,attr"`
-	Spacing      int    `xml:"spacing,attr"`
-	Margin       int    `xml:"margin,attr"`
-	Tileoffset   xmlTileoffset
+	Raw          []byte          `xml:",innerxml"`
+	Firstgid     uint32          `xml:"firstgid,attr"`
+	Source       string          `xml:"source,attr"`
+	Name         string          `xml:"name,attr"`
+	TileWidth    int             `xml:"tilewidth,attr"`
+	TileHeight   int             `xml:"tileheight,attr"`
+	Spacing      int             `xml:"spacing,attr"`
+	Margin       int             `xml:"margin,attr"`
+	Tileoffset   xmlTileoffset   `xml:"tileoffset"`
 	Properties   xmlProperties   `xml:"properties"`
 	Image        xmlImage        `xml:"image"`
 	Tile         []xmlTile       `xml:"tile"`
@@ -44,6 +44,35 @@ func (x *xmlTileset) terrainTypes() []TerrainType {
 	return terrainTypes
 }
 
+// fromXML sets the fields of this tileset (except for Firstgid and Source)
+// from the given XML tileset.
+func (t *Tileset) fromXML(x *xmlTileset) {
+	t.Name = x.Name
+	t.Width = x.TileWidth
+	t.Height = x.TileHeight
+	t.Spacing = x.Spacing
+	t.Margin = x.Margin
+
+	// Find tileset offset
+	t.OffsetX, t.OffsetY = x.Tileoffset.X, x.Tileoffset.Y
+
+	// Find tileset properties
+	t.Properties = x.Properties.toMap()
+
+	// Find image properties
+	t.Image = &Image{
+		Source: x.Image.Source,
+		Width:  x.Image.Width,
+		Height: x.Image.Height,
+	}
+
+	// Find tile definitions
+	t.Tiles = x.tilesMap()
+
+	// Find terrain definitions
+	t.Terrain = x.terrainTypes()
+}
+
 // TerrainType defines a single terrain with a name and associated tile ID
 type TerrainType struct {
 	// Name of the terrain type
@@ -81,7 +110,7 @@ type Tileset struct {
 	Margin int
 
 	// Map of property names and values for all properties set on the map.
-	Properties map[string]string
+	Properties Properties
 
 	// The image of the tileset
 	Image *Image
@@ -113,30 +142,6 @@ func (t *Tileset) Load(data []byte) error {
 	if err != nil {
 		return err
 	}
-	t.Name = x.Name
-	t.Width = x.TileWidth
-	t.Height = x.TileHeight
-	t.Spacing = x.Spacing
-	t.Margin = x.Margin
-
-	// Find tileset offset
-	t.OffsetX, t.OffsetY = x.Tileoffset.X, x.Tileoffset.Y
-
-	// Find tileset properties
-	t.Properties = x.Properties.toMap()
-
-	// Find image properties
-	t.Image = &Image{
-		Source: x.Image.Source,
-		Width:  x.Image.Width,
-		Height: x.Image.Height,
-	}
-
-	// Find tile definitions
-	t.Tiles = x.tilesMap()
-
-	// Find terrain definitions
-	t.Terrain = x.terrainTypes()
-
+	t.fromXML(x)
 	return nil
 }
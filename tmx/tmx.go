@@ -11,6 +11,11 @@
 // This package supports all of the current file specification with the
 // exception of embedded image data (I.e. non-external tileset images).
 //
+// Tile data compressed using zlib or gzip is decoded by this package. It
+// does not include a zstd decoder: maps whose tile data is compressed using
+// zstd fail to load with ErrBadCompression, unless a decoder (e.g. from a
+// third-party zstd package) is registered using RegisterDecompressor.
+//
 package tmx
 
 import (
@@ -21,13 +26,25 @@ import (
 	"strings"
 )
 
-// hexColorToRGBA converts hex color strings to color.RGBA
+// hexToRGBA converts hex color strings to color.RGBA
 //
-// Alpha value in returned color will always be 255
+// Invalid colors are returned as opaque black.
 func hexToRGBA(c string) color.RGBA {
+	rgba, ok := parseHexColor(c)
+	if !ok {
+		return color.RGBA{0, 0, 0, 255}
+	}
+	return rgba
+}
+
+// parseHexColor parses a #RRGGBB, #AARRGGBB or #RGB hex color string. The
+// alpha value of the returned color is 255 unless the string specifies one,
+// in which case the color is alpha-premultiplied (as color.RGBA is).
+func parseHexColor(c string) (rgba color.RGBA, ok bool) {
 	// There isin't really a color specification I can find on TMX file format,
-	// but Tiled exports #RRGGBB hex values, but this also supports #RGB ones
-	// just in case some abstract tool uses them by coincidence.
+	// but Tiled exports #RRGGBB (or #AARRGGBB with alpha) hex values, but this
+	// also supports #RGB ones just in case some abstract tool uses them by
+	// coincidence.
 
 	// Strip leading # if there is one
 	if len(c) > 0 && c[0] == '#' {
@@ -35,25 +52,29 @@ func hexToRGBA(c string) color.RGBA {
 	}
 
 	// If an invalid length value then simply return
-	if len(c) != 6 && len(c) != 3 {
-		return color.RGBA{0, 0, 0, 255}
+	if len(c) != 8 && len(c) != 6 && len(c) != 3 {
+		return rgba, false
 	}
 
-	var r, g, b uint8
-	if len(c) == 6 {
-		// Parse RRGGBB color
-		rgb, err := strconv.ParseUint(c, 16, 48)
+	var r, g, b, a uint8 = 0, 0, 0, 255
+	switch len(c) {
+	case 8, 6:
+		// Parse AARRGGBB or RRGGBB color
+		argb, err := strconv.ParseUint(c, 16, 32)
 		if err != nil {
-			return color.RGBA{0, 0, 0, 255}
+			return rgba, false
 		}
-		r = uint8(rgb >> 16)
-		g = uint8(rgb >> 8)
-		b = uint8(rgb)
-	} else {
+		if len(c) == 8 {
+			a = uint8(argb >> 24)
+		}
+		r = uint8(argb >> 16)
+		g = uint8(argb >> 8)
+		b = uint8(argb)
+	default:
 		// Parse #RGB values
 		rgb, err := strconv.ParseUint(c, 16, 24)
 		if err != nil {
-			return color.RGBA{0, 0, 0, 255}
+			return rgba, false
 		}
 		r = uint8(rgb>>8) & 0xf
 		g = uint8(rgb>>4) & 0xf
@@ -62,24 +83,12 @@ func hexToRGBA(c string) color.RGBA {
 		g |= g << 4
 		b |= b << 4
 	}
-	return color.RGBA{r, g, b, 255}
-}
-
-type xmlProperty struct {
-	Name  string `xml:"name,attr"`
-	Value string `xml:"value,attr"`
-}
-
-type xmlProperties struct {
-	Property []xmlProperty `xml:"property"`
-}
-
-func (p xmlProperties) toMap() map[string]string {
-	m := make(map[string]string, len(p.Property))
-	for _, p := range p.Property {
-		m[p.Name] = p.Value
+	if a != 255 {
+		r = uint8(uint32(r) * uint32(a) / 255)
+		g = uint8(uint32(g) * uint32(a) / 255)
+		b = uint8(uint32(b) * uint32(a) / 255)
 	}
-	return m
+	return color.RGBA{r, g, b, a}, true
 }
 
 type xmlTileoffset struct {
@@ -103,6 +112,10 @@ type xmlMap struct {
 	Height          int              `xml:"height,attr"`
 	TileWidth       int              `xml:"tilewidth,attr"`
 	TileHeight      int              `xml:"tileheight,attr"`
+	Infinite        int              `xml:"infinite,attr"`
+	StaggerAxis     string           `xml:"staggeraxis,attr"`
+	StaggerIndex    string           `xml:"staggerindex,attr"`
+	HexSideLength   int              `xml:"hexsidelength,attr"`
 	BackgroundColor string           `xml:"backgroundcolor,attr"`
 	Properties      xmlProperties    `xml:"properties"`
 	Tileset         []xmlTileset     `xml:"tileset"`
@@ -110,6 +123,46 @@ type xmlMap struct {
 	Objectgroup     []xmlObjectgroup `xml:"objectgroup"`
 }
 
+// parseVersion parses a "major.minor" version string, such as "1.0". An
+// empty string is version zero, and one without a minor version is minor
+// version zero.
+func parseVersion(v string) (major, minor int, err error) {
+	split := strings.Split(v, ".")
+	if len(split) == 1 && len(v) > 0 {
+		major, err = strconv.Atoi(v)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	if len(split) == 2 {
+		major, err = strconv.Atoi(split[0])
+		if err != nil {
+			return 0, 0, err
+		}
+
+		minor, err = strconv.Atoi(split[1])
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	return major, minor, nil
+}
+
+// parseOrientation parses a map orientation string, such as "orthogonal".
+func parseOrientation(o string) (Orientation, error) {
+	switch o {
+	case "orthogonal":
+		return Orthogonal, nil
+	case "isometric":
+		return Isometric, nil
+	case "staggered":
+		return Staggered, nil
+	case "hexagonal":
+		return Hexagonal, nil
+	}
+	return Invalid, fmt.Errorf("unknown map orientation.")
+}
+
 // Parse parses the TMX map file data and returns a *Map.
 //
 // nil and a error will be returned if there are any problems parsing the data.
@@ -122,71 +175,28 @@ func Parse(data []byte) (*Map, error) {
 	}
 
 	// Parse version string
-	split := strings.Split(x.Version, ".")
-	var major, minor int
-	if len(split) == 2 {
-		major, err = strconv.Atoi(split[0])
-		if err != nil {
-			return nil, err
-		}
-
-		minor, err = strconv.Atoi(split[1])
-		if err != nil {
-			return nil, err
-		}
+	major, minor, err := parseVersion(x.Version)
+	if err != nil {
+		return nil, err
 	}
 
 	// Find map orientation
-	var orient Orientation
-	switch x.Orientation {
-	case "orthogonal":
-		orient = Orthogonal
-	case "isometric":
-		orient = Isometric
-	case "staggered":
-		orient = Staggered
-	default:
-		return nil, fmt.Errorf("unknown map orientation.")
+	orient, err := parseOrientation(x.Orientation)
+	if err != nil {
+		return nil, err
 	}
 
 	// Find map properties
-	props := make(map[string]string, len(x.Properties.Property))
-	for _, prop := range x.Properties.Property {
-		props[prop.Name] = prop.Value
-	}
+	props := x.Properties.toMap()
 
 	// Convert the tilesets
 	tilesets := make([]*Tileset, len(x.Tileset))
 	for i, tsx := range x.Tileset {
 		ts := &Tileset{
-			Name:     tsx.Name,
 			Firstgid: tsx.Firstgid,
 			Source:   tsx.Source,
-			Width:    tsx.TileWidth,
-			Height:   tsx.TileHeight,
-			Spacing:  tsx.Spacing,
-			Margin:   tsx.Margin,
 		}
-
-		// Find tileset offset
-		ts.OffsetX, ts.OffsetY = tsx.Tileoffset.X, tsx.Tileoffset.Y
-
-		// Find tileset properties
-		ts.Properties = tsx.Properties.toMap()
-
-		// Find image properties
-		ts.Image = &Image{
-			Source: tsx.Image.Source,
-			Width:  tsx.Image.Width,
-			Height: tsx.Image.Height,
-		}
-
-		// Find tile definitions
-		ts.Tiles = tsx.tilesMap()
-
-		// Find terrain definitions
-		ts.Terrain = tsx.terrainTypes()
-
+		ts.fromXML(&tsx)
 		tilesets[i] = ts
 	}
 
@@ -194,7 +204,7 @@ func Parse(data []byte) (*Map, error) {
 	layers := make([]*Layer, len(x.Layer))
 	for i, xl := range x.Layer {
 		var err error
-		layers[i], err = xl.toLayer(x.Width, x.Height)
+		layers[i], err = xl.toLayer(x.Width, x.Height, x.Infinite != 0)
 		if err != nil {
 			return nil, err
 		}
@@ -211,8 +221,12 @@ func Parse(data []byte) (*Map, error) {
 		VersionMajor:    major,
 		VersionMinor:    minor,
 		Orientation:     orient,
+		StaggerAxis:     x.StaggerAxis,
+		StaggerIndex:    x.StaggerIndex,
+		HexSideLength:   x.HexSideLength,
 		Width:           x.Width,
 		Height:          x.Height,
+		Infinite:        x.Infinite != 0,
 		TileWidth:       x.TileWidth,
 		TileHeight:      x.TileHeight,
 		BackgroundColor: hexToRGBA(x.BackgroundColor),
@@ -221,5 +235,6 @@ func Parse(data []byte) (*Map, error) {
 		Layers:          layers,
 		ObjectGroups:    objectGroups,
 	}
+	m.setStaggerDefaults()
 	return m, nil
 }
@@ -5,10 +5,21 @@
 package tmx
 
 import (
+	"compress/flate"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
 	"io/ioutil"
+	"math"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
+
+	"azul3d.org/engine/lmath"
+	"azul3d.org/engine/physics2d"
 )
 
 func verify(t *testing.T, name string) {
@@ -52,7 +63,11 @@ func verify(t *testing.T, name string) {
 			}
 		}
 	}
+	check(t, m)
+}
 
+// check checks the common properties of the test maps.
+func check(t *testing.T, m *Map) {
 	if m.VersionMajor != 1 || m.VersionMinor != 0 {
 		t.Log(m.VersionMajor, m.VersionMinor)
 		t.Fatal("incorrect version number")
@@ -131,3 +146,562 @@ func TestBase64ZLibMap(t *testing.T) {
 func TestObjects(t *testing.T) {
 	verify(t, "test_objects.tmx")
 }
+
+func TestParseFile(t *testing.T) {
+	m, err := ParseFile(filepath.Join("testdata", "test_csv_tsx.tmx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, m)
+	ts := m.Tilesets[0]
+	if ts.Name != "tilesheet" || len(ts.Terrain) != 1 || ts.Tiles[26].Properties["mytileproperty"] != "mytilepropertyvalue" {
+		t.Fatalf("external tileset %v not loaded", ts)
+	}
+	if p := ts.ImagePath("testdata"); p != filepath.Join("testdata", "tilesheet.png") {
+		t.Fatalf("image path %q", p)
+	}
+}
+
+func TestJSONMap(t *testing.T) {
+	m, err := ParseFile(filepath.Join("testdata", "test_json.tmj"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.VersionMajor, m.VersionMinor = 1, 0
+	check(t, m)
+
+	if v, ok := m.Properties.Int("lives"); !ok || v != 3 {
+		t.Fatal("lives", v, ok)
+	}
+	if v, ok := m.Properties.Bool("night"); !ok || !v {
+		t.Fatal("night", v, ok)
+	}
+
+	// Tilesets, from an external TSX and an external JSON file.
+	if len(m.Tilesets) != 2 || m.Tilesets[0].Name != "tilesheet" {
+		t.Fatalf("tilesets %v", m.Tilesets)
+	}
+	blue := m.Tilesets[1]
+	if blue.Name != "tilesheet_blue" || blue.Firstgid != 28 || blue.Image.Width != 288 || blue.Properties["legacy"] != "yes" {
+		t.Fatalf("JSON tileset %v", blue)
+	}
+	tile := blue.Tiles[1]
+	if tile == nil || tile.Probability != 0.5 || tile.Terrain != [4]int{0, 0, -1, -1} || tile.Properties["solid"] != "true" {
+		t.Fatalf("JSON tile %+v", tile)
+	}
+	if len(blue.Terrain) != 1 || blue.Terrain[0].Name != "water" {
+		t.Fatalf("JSON terrain %v", blue.Terrain)
+	}
+
+	// Layers, as arrays and base64 zlib compressed data.
+	if len(m.Layers) != 2 {
+		t.Fatalf("%d layers", len(m.Layers))
+	}
+	bg := m.Layers[0]
+	if len(bg.Tiles) != 30 || bg.Tiles[Coord{39, 3}] != 13 || bg.Properties["parallax"] != "0.5" {
+		t.Fatalf("layer %v with %d tiles", bg, len(bg.Tiles))
+	}
+	l := m.Layers[1]
+	if l.Visible || l.Opacity != 0.75 || len(l.Tiles) != 12 || l.Tiles[Coord{59, 7}] != 29 {
+		t.Fatalf("layer %v with %d tiles", l, len(l.Tiles))
+	}
+
+	// Object groups, from within a group layer.
+	if len(m.ObjectGroups) != 1 {
+		t.Fatalf("%d object groups", len(m.ObjectGroups))
+	}
+	g := m.ObjectGroups[0]
+	if g.DrawOrder != "index" || len(g.Objects) != 4 {
+		t.Fatalf("object group %v", g)
+	}
+	spawn := g.Find("spawn")
+	if p, ok := spawn.Value.(*Point); !ok || *p != (Point{8, 96}) || spawn.Type != "player" {
+		t.Fatalf("spawn %+v", spawn)
+	}
+	if id, ok := spawn.Properties.Int("target"); !ok || m.Object(id) != g.Find("zone") {
+		t.Fatal("object property", id, ok)
+	}
+	if p, ok := g.Find("zone").Value.(*Polygon); !ok || len(p.Points) != 3 || p.Points[2] != (Point{32.5, 16}) {
+		t.Fatalf("zone value %#v", g.Find("zone").Value)
+	}
+	sign := g.Find("sign")
+	if text, ok := sign.Value.(*Text); !ok || sign.Visible || text.Text != "Hello" || !text.Wrap || text.Kerning || text.PixelSize != 16 {
+		t.Fatalf("sign value %#v", sign.Value)
+	}
+	if r, ok := g.Find("box").Value.(*Rectangle); !ok || *r != (Rectangle{10.5, 20.25, 32, 16}) {
+		t.Fatalf("box value %#v", g.Find("box").Value)
+	}
+
+	if _, err := ParseJSON([]byte(`{"version": 1, "orientation": "round"}`)); err == nil {
+		t.Fatal("parsed a map of unknown orientation")
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	// The tileset images of testdata are not available, so write the map and
+	// tilesets with generated images to a temporary directory.
+	dir, err := ioutil.TempDir("", "tmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"test_json.tmj", "tilesheet.tsx", "tilesheet_blue.tsj"} {
+		data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"tilesheet.png", "tilesheet_blue.png"} {
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := png.Encode(f, image.NewRGBA(image.Rect(0, 0, 288, 96))); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+	}
+
+	m, layers, err := LoadFile(filepath.Join(dir, "test_json.tmj"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.VersionMajor != 1 || m.VersionMinor != 10 {
+		t.Fatalf("version %d.%d", m.VersionMajor, m.VersionMinor)
+	}
+	if len(layers) != 2 || len(layers["background"]) != 2 || len(layers["blue"]) != 1 {
+		t.Fatalf("layers %v", layers)
+	}
+}
+
+func TestProperties(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "test_properties.tmx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v, ok := m.Properties.Float("gravity"); !ok || v != 9.8 {
+		t.Fatal("gravity", v, ok)
+	}
+	if v, ok := m.Properties.Int("lives"); !ok || v != 3 {
+		t.Fatal("lives", v, ok)
+	}
+	if v, ok := m.Properties.Bool("night"); !ok || !v {
+		t.Fatal("night", v, ok)
+	}
+	if v, ok := m.Properties.Color("fog"); !ok || v != (color.RGBA{128, 0, 0, 128}) {
+		t.Fatal("fog", v, ok)
+	}
+	if _, ok := m.Properties.Int("gravity"); ok {
+		t.Fatal("float property parsed as int")
+	}
+	if _, ok := m.Properties.Bool("missing"); ok {
+		t.Fatal("missing property found")
+	}
+	if v := m.Properties["intro"]; v != "Once upon\na time" {
+		t.Fatalf("multi-line property %q", v)
+	}
+	if v, ok := m.Tilesets[0].Tiles[0].Properties.Bool("solid"); !ok || !v {
+		t.Fatal("tile property", v, ok)
+	}
+
+	l := m.Layers[0]
+	if l.Opacity != 0.5 || !l.Visible {
+		t.Fatal("layer opacity/visibility", l.Opacity, l.Visible)
+	}
+	if v, ok := l.Properties.Float("parallax"); !ok || v != 0.25 {
+		t.Fatal("layer property", v, ok)
+	}
+
+	g := m.ObjectGroups[0]
+	if g.Visible || g.Opacity != 1 || g.OffsetX != 4 || g.OffsetY != -2.5 || g.DrawOrder != "index" {
+		t.Fatalf("object group %+v", g)
+	}
+	if g.Properties["spawns"] != "yes" || len(g.Objects) != 7 {
+		t.Fatal("object group properties/objects")
+	}
+
+	box := g.Find("box")
+	if box == nil || box.ID != 1 || box.Type != "crate" || box.Rotation != 45 || !box.Visible {
+		t.Fatalf("box %+v", box)
+	}
+	if r, ok := box.Value.(*Rectangle); !ok || *r != (Rectangle{10.5, 20.25, 32, 16}) {
+		t.Fatalf("box value %#v", box.Value)
+	}
+	if e, ok := g.Find("pond").Value.(*Ellipse); !ok || *e != (Ellipse{64, 64, 40, 20}) {
+		t.Fatalf("pond value %#v", g.Find("pond").Value)
+	}
+	spawn := g.Find("spawn")
+	if p, ok := spawn.Value.(*Point); !ok || *p != (Point{8, 96}) {
+		t.Fatalf("spawn value %#v", spawn.Value)
+	}
+	if id, ok := spawn.Properties.Int("target"); !ok || m.Object(id) != g.Find("pond") {
+		t.Fatal("object property", id, ok)
+	}
+	if p, ok := g.Find("zone").Value.(*Polygon); !ok || len(p.Points) != 4 || p.Points[1] != (Point{32.5, 0}) {
+		t.Fatalf("zone value %#v", g.Find("zone").Value)
+	}
+	path := g.Find("path")
+	if p, ok := path.Value.(*Polyline); !ok || path.Visible || len(p.Points) != 2 || p.X != 1 || p.Y != 2 {
+		t.Fatalf("path value %#v", path.Value)
+	}
+	text, ok := g.Find("sign").Value.(*Text)
+	if !ok {
+		t.Fatalf("sign value %#v", g.Find("sign").Value)
+	}
+	want := Text{
+		Text:       "Hello, world!",
+		FontFamily: "Serif",
+		PixelSize:  12,
+		Wrap:       true,
+		Color:      color.RGBA{0, 0, 255, 255},
+		Bold:       true,
+		Kerning:    true,
+		HAlign:     "center",
+		VAlign:     "top",
+	}
+	if *text != want {
+		t.Fatalf("text %+v", *text)
+	}
+	tree := g.Find("tree")
+	if tree.Gid != 1 || tree.Value != nil {
+		t.Fatalf("tile object %+v", tree)
+	}
+	if m.Object(0) != nil || m.Object(42) != nil || g.Find("missing") != nil {
+		t.Fatal("found missing objects")
+	}
+}
+
+func TestInfiniteMap(t *testing.T) {
+	m, err := ParseFile(filepath.Join("testdata", "test_infinite.tmx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Infinite || len(m.Layers) != 1 {
+		t.Fatal("not an infinite map with one layer")
+	}
+	l := m.Layers[0]
+	if len(l.Chunks) != 2 || l.Chunks[0].X != -16 || l.Chunks[1].X != 0 {
+		t.Fatalf("chunks %v", l.Chunks)
+	}
+	for c, gid := range map[Coord]uint32{{-1, 0}: 1, {-16, 15}: 2, {0, 0}: 3, {5, 5}: 4, {1, 1}: 0, {-17, 0}: 0} {
+		if got := l.Tile(c.X, c.Y); got != gid {
+			t.Errorf("Tile(%d, %d) = %d, want %d", c.X, c.Y, got, gid)
+		}
+	}
+	if len(l.Tiles) != 4 {
+		t.Fatalf("%d tiles, want 4", len(l.Tiles))
+	}
+	if c := l.ChunkAt(-1, 15); c != l.Chunks[0] {
+		t.Fatalf("ChunkAt(-1, 15) = %v", c)
+	}
+	if c := l.ChunkAt(0, 16); c != nil {
+		t.Fatalf("ChunkAt(0, 16) = %v", c)
+	}
+	if c := l.ChunksIn(image.Rect(-4, 4, 4, 8)); len(c) != 2 {
+		t.Fatalf("ChunksIn found %v", c)
+	}
+	if c := l.ChunksIn(image.Rect(2, 2, 40, 40)); len(c) != 1 || c[0] != l.Chunks[1] {
+		t.Fatalf("ChunksIn found %v", c)
+	}
+
+	// Realize the chunks lazily.
+	images := map[string]*image.RGBA{
+		"tilesheet.png": image.NewRGBA(image.Rect(0, 0, 288, 96)),
+	}
+	cc := NewChunkCache(m, nil, images)
+	objs := cc.Objects(0, l.Chunks[1])
+	if len(objs) != 1 || len(objs["tilesheet.png"].Meshes[0].Vertices) != 2*6 {
+		t.Fatalf("chunk objects %v", objs)
+	}
+	if cc.Objects(0, l.Chunks[1])["tilesheet.png"] != objs["tilesheet.png"] || cc.Len() != 1 || cc.Loaded(l.Chunks[0]) {
+		t.Fatal("chunk was not cached")
+	}
+	cc.Release(l.Chunks[1])
+	if cc.Len() != 0 {
+		t.Fatal("chunk was not released")
+	}
+
+	// Load loads every chunk.
+	layers := Load(m, nil, images)
+	if v := layers["ground"]["tilesheet.png"].Meshes[0].Vertices; len(v) != 4*6 {
+		t.Fatalf("%d vertices, want %d", len(v), 4*6)
+	}
+}
+
+func TestInfiniteJSONMap(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "test_infinite.tmj"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseJSON(data); err != ErrBadCompression {
+		t.Fatalf("got error %v, want ErrBadCompression", err)
+	}
+
+	// The ground layer uses raw deflate, standing in for e.g. zstd.
+	RegisterDecompressor("deflate", func(r io.Reader) (io.ReadCloser, error) {
+		return flate.NewReader(r), nil
+	})
+	m, err := ParseJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Infinite || len(m.Layers) != 2 {
+		t.Fatal("not an infinite map with two layers")
+	}
+	ground, top := m.Layers[0], m.Layers[1]
+	if len(ground.Chunks) != 2 || ground.Tile(-1, -1) != 5 || ground.Tile(0, -16) != 6 || len(ground.Tiles) != 2 {
+		t.Fatalf("ground tiles %v", ground.Tiles)
+	}
+	if len(top.Chunks) != 1 || top.Tile(17, 2) != 7 || len(top.Tiles) != 1 {
+		t.Fatalf("top tiles %v", top.Tiles)
+	}
+}
+
+func TestOrientations(t *testing.T) {
+	data := []byte(`<map version="1.10" orientation="hexagonal" width="4" height="3" tilewidth="32" tileheight="28" staggeraxis="x" staggerindex="even" hexsidelength="14"></map>`)
+	hex, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.Orientation != Hexagonal || hex.StaggerAxis != "x" || hex.StaggerIndex != "even" || hex.HexSideLength != 14 {
+		t.Fatalf("parsed %v %q %q %d", hex.Orientation, hex.StaggerAxis, hex.StaggerIndex, hex.HexSideLength)
+	}
+
+	tests := []struct {
+		m      *Map
+		w, h   int
+		px, py float64 // Of tile (1, 1).
+	}{
+		{&Map{Orientation: Orthogonal, Width: 4, Height: 3, TileWidth: 32, TileHeight: 32}, 128, 96, 32, 32},
+		{&Map{Orientation: Isometric, Width: 4, Height: 3, TileWidth: 64, TileHeight: 32}, 224, 112, 64, 32},
+		{&Map{Orientation: Staggered, Width: 4, Height: 3, TileWidth: 64, TileHeight: 32, StaggerAxis: "y", StaggerIndex: "odd"}, 288, 64, 96, 16},
+		{&Map{Orientation: Staggered, Width: 4, Height: 3, TileWidth: 64, TileHeight: 32, StaggerAxis: "x", StaggerIndex: "even"}, 160, 112, 32, 32},
+		{&Map{Orientation: Hexagonal, Width: 4, Height: 3, TileWidth: 28, TileHeight: 32, StaggerAxis: "y", StaggerIndex: "odd", HexSideLength: 16}, 126, 80, 42, 24},
+		{hex, 101, 98, 23, 28},
+	}
+	for _, tst := range tests {
+		m := tst.m
+		if w, h := m.PixelSize(); w != tst.w || h != tst.h {
+			t.Errorf("%v: PixelSize() = %dx%d, want %dx%d", m.Orientation, w, h, tst.w, tst.h)
+		}
+		if px, py := m.TileToPixel(1, 1); px != tst.px || py != tst.py {
+			t.Errorf("%v: TileToPixel(1, 1) = %v, %v, want %v, %v", m.Orientation, px, py, tst.px, tst.py)
+		}
+
+		// The center of every tile, and points near it, are within the tile.
+		for y := -2; y < 5; y++ {
+			for x := -2; x < 5; x++ {
+				px, py := m.TileToPixel(x, y)
+				px += float64(m.TileWidth) / 2
+				py += float64(m.TileHeight) / 2
+				for _, d := range [][2]float64{{0, 0}, {-3, 0}, {3, 0}, {0, -3}, {0, 3}} {
+					if gx, gy := m.PixelToTile(px+d[0], py+d[1]); gx != x || gy != y {
+						t.Errorf("%v: PixelToTile(%v, %v) = %d, %d, want %d, %d", m.Orientation, px+d[0], py+d[1], gx, gy, x, y)
+					}
+				}
+			}
+		}
+	}
+
+	// Tiles lower on isometric maps are drawn last.
+	iso := tests[1].m
+	l := &Layer{Tiles: map[Coord]uint32{{1, 1}: 1, {2, 0}: 2, {0, 0}: 3, {0, 2}: 4}}
+	var gids []uint32
+	for _, tile := range drawOrder(iso, l, image.Rect(0, 0, 4, 3)) {
+		gids = append(gids, tile.gid)
+	}
+	if !reflect.DeepEqual(gids, []uint32{3, 4, 1, 2}) {
+		t.Fatalf("draw order %v", gids)
+	}
+}
+
+func TestTileAnimation(t *testing.T) {
+	m, err := ParseFile(filepath.Join("testdata", "test_tiles.tmx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tile := m.Tilesets[0].Tiles[0]
+	if len(tile.Animation) != 3 || tile.Animation[1] != (Frame{TileID: 1, Duration: 200 * time.Millisecond}) {
+		t.Fatalf("animation %v", tile.Animation)
+	}
+	if l := tile.AnimationLength(); l != 400*time.Millisecond {
+		t.Fatalf("AnimationLength() = %v", l)
+	}
+	if f := tile.FrameAt(950 * time.Millisecond); f != 1 {
+		t.Fatalf("FrameAt(950ms) = %d", f)
+	}
+
+	a := NewAnimator(m)
+	if a.Gid(1) != 1 || a.Gid(3) != 3 || !a.Animated(1) || a.Animated(3) {
+		t.Fatal("animator started in the wrong state")
+	}
+	for i, want := range []struct {
+		changed bool
+		gid     uint32
+	}{{true, 2}, {false, 2}, {true, 5}, {true, 1}} {
+		if changed := a.Tick(100 * time.Millisecond); changed != want.changed || a.Gid(1) != want.gid {
+			t.Fatalf("tick %d: changed %v, gid %d", i, changed, a.Gid(1))
+		}
+	}
+	if a.Elapsed() != 400*time.Millisecond {
+		t.Fatalf("Elapsed() = %v", a.Elapsed())
+	}
+	a.Tick(100 * time.Millisecond)
+	if gid := a.Gid(1 | FLIPPED_HORIZONTALLY_FLAG); gid != 2|FLIPPED_HORIZONTALLY_FLAG {
+		t.Fatalf("flipped gid %x", gid)
+	}
+
+	// Tiles are loaded showing their current frame.
+	images := map[string]*image.RGBA{
+		"tilesheet.png": image.NewRGBA(image.Rect(0, 0, 288, 96)),
+	}
+	layers := Load(m, &Config{Animator: a}, images)
+	if n := len(layers["ground"]["tilesheet.png"].Meshes[0].Vertices); n != 3*6 {
+		t.Fatalf("%d vertices", n)
+	}
+
+	// JSON tilesets have animations and collision objects too.
+	ts := new(Tileset)
+	err = ts.LoadJSON([]byte(`{
+		"name": "json", "tilewidth": 32, "tileheight": 32,
+		"image": "tilesheet.png", "imagewidth": 288, "imageheight": 96,
+		"tiles": [{
+			"id": 3,
+			"animation": [{"tileid": 3, "duration": 50}, {"tileid": 7, "duration": 50}],
+			"objectgroup": {"type": "objectgroup", "objects": [{"id": 1, "width": 32, "height": 32}]}
+		}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tile = ts.Tiles[3]
+	if tile.FrameAt(60*time.Millisecond) != 7 || tile.ObjectGroup == nil || len(tile.ObjectGroup.Objects) != 1 {
+		t.Fatalf("json tile %v", tile)
+	}
+}
+
+func TestCollisionShapes(t *testing.T) {
+	m, err := ParseFile(filepath.Join("testdata", "test_tiles.tmx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g := m.Tilesets[0].Tiles[2].ObjectGroup; g == nil || len(g.Objects) != 4 || g.DrawOrder != "index" {
+		t.Fatalf("collision objects %v", g)
+	}
+	if s := m.TileShapes(1, 0, 0, 1); s != nil {
+		t.Fatalf("tile without collision objects has shapes %v", s)
+	}
+
+	// Both tiles have a rectangle, a circle and a concave polygon split into
+	// four triangles. The polyline is omitted.
+	shapes := m.CollisionShapes(m.Layers[0], 1)
+	if len(shapes) != 2*6 {
+		t.Fatalf("%d shapes", len(shapes))
+	}
+	area := func(shapes []physics2d.Shape) (a float64) {
+		for _, s := range shapes {
+			a += s.MassData(1).Mass
+		}
+		return a
+	}
+	for i, tile := range [][]physics2d.Shape{shapes[:6], shapes[6:]} {
+		rect, circle := tile[0], tile[1]
+		x := float64(i * 64)
+		if b := rect.Bounds(physics2d.TransformIdentity); !b.Min.AlmostEquals(lmath.Vec2{X: x, Y: 0}, 1e-9) || !b.Max.AlmostEquals(lmath.Vec2{X: x + 32, Y: 16}, 1e-9) {
+			t.Errorf("tile %d: rectangle bounds %v", i, b)
+		}
+		c, ok := circle.(*physics2d.Circle)
+		if !ok || c.Radius != 8 || !c.Center.AlmostEquals(lmath.Vec2{X: x + 16, Y: 24}, 1e-9) {
+			t.Errorf("tile %d: circle %v", i, circle)
+		}
+		if a := area(tile[2:]); math.Abs(a-192) > 1e-9 {
+			t.Errorf("tile %d: polygon area %v, want 192", i, a)
+		}
+	}
+
+	// The polygon of the horizontally flipped tile is at it's right.
+	inside := func(shapes []physics2d.Shape, p lmath.Vec2) bool {
+		for _, s := range shapes {
+			if s.TestPoint(physics2d.TransformIdentity, p) {
+				return true
+			}
+		}
+		return false
+	}
+	if !inside(shapes[2:6], lmath.Vec2{X: 1, Y: 31}) || inside(shapes[2:6], lmath.Vec2{X: 12, Y: 20}) {
+		t.Error("polygon of the tile")
+	}
+	if !inside(shapes[8:], lmath.Vec2{X: 64 + 31, Y: 31}) || inside(shapes[8:], lmath.Vec2{X: 64 + 1, Y: 31}) {
+		t.Error("polygon of the flipped tile")
+	}
+}
+
+func TestRenderer(t *testing.T) {
+	m, err := ParseFile(filepath.Join("testdata", "test_infinite.tmx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	images := map[string]*image.RGBA{
+		"tilesheet.png": image.NewRGBA(image.Rect(0, 0, 288, 96)),
+	}
+	r := NewRenderer(m, nil, images, 4)
+	defer r.Destroy()
+
+	// The tiles at (-1, 0), (-16, 15), (0, 0) and (5, 5) are each in their
+	// own chunk, sharing a single texture.
+	objs := r.Objects()
+	if r.Len() != 4 || len(objs) != 4 {
+		t.Fatalf("%d chunks, %d objects", r.Len(), len(objs))
+	}
+	for _, o := range objs {
+		if o.Textures[0] != objs[0].Textures[0] {
+			t.Fatal("texture is not shared")
+		}
+	}
+
+	// Editing a tile only rebuilds it's chunk.
+	r.SetTile(0, 6, 6, 2)
+	edited := r.Objects()
+	if edited[0] != objs[0] || len(edited[2].Meshes[0].Vertices) != 2*6 {
+		t.Fatal("edit rebuilt the wrong chunks")
+	}
+	if m.Layers[0].Tile(6, 6) != 2 {
+		t.Fatal("edit did not change the layer")
+	}
+	r.SetTile(0, 5, 5, 0)
+	r.SetTile(0, 6, 6, 0)
+	if r.Len() != 3 {
+		t.Fatalf("%d chunks after removing tiles, want 3", r.Len())
+	}
+	r.SetTile(0, 20, -20, 1)
+	if r.Len() != 4 {
+		t.Fatalf("%d chunks after adding a tile, want 4", r.Len())
+	}
+
+	// Only the chunk of the tile at (0, 0), from X=0 to X=32, is within the
+	// frustum.
+	box := func(x0, x1, z0, z1 float64) lmath.Frustum {
+		return lmath.Frustum{
+			{Normal: lmath.Vec3{X: 1}, D: -x0},
+			{Normal: lmath.Vec3{X: -1}, D: x1},
+			{Normal: lmath.Vec3{Z: 1}, D: -z0},
+			{Normal: lmath.Vec3{Z: -1}, D: z1},
+			{Normal: lmath.Vec3{Y: 1}, D: 1},
+			{Normal: lmath.Vec3{Y: -1}, D: 1},
+		}
+	}
+	if v := r.Visible(box(1, 100, 0, 1000)); len(v) != 1 || v[0] != r.Objects()[2] {
+		t.Fatalf("visible %v", v)
+	}
+	if v := r.Visible(box(-1000, 1000, -1000, 2000)); len(v) != 4 {
+		t.Fatalf("%d visible objects, want 4", len(v))
+	}
+}
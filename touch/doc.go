@@ -0,0 +1,23 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//go:generate stringer -type=State,Phase -output=stringers.go
+
+// Package touch implements touch screen related data types and gestures.
+//
+// Each finger touching the screen is a contact, identified by an ID that is
+// unique for as long as the finger touches the screen. The raw contact events
+// can be fed into a Recognizer, which recognizes simple gestures (tap, pan and
+// pinch) useful for 2D and user interface code:
+//
+//  r := touch.NewRecognizer()
+//  for _, g := range r.Handle(ev) {
+//      switch g := g.(type) {
+//      case touch.Tap:
+//          fmt.Println("Tapped at", g.X, g.Y)
+//      case touch.Pinch:
+//          camera.Zoom *= g.Scale
+//      }
+//  }
+//
+package touch // import "azul3d.org/engine/touch"
@@ -0,0 +1,51 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package touch
+
+import (
+	"fmt"
+	"time"
+)
+
+// State represents the state of a single touch contact.
+type State uint8
+
+// Touch contact state constants. Down implies the contact has just begun, Move
+// that it has moved, and Up that it has ended. Cancelled implies the contact
+// was ended by the system (e.g. because the window lost focus) and should not
+// trigger any action. The InvalidState is declared to help users detect
+// uninitialized variables.
+const (
+	InvalidState State = iota
+	Down
+	Move
+	Up
+	Cancelled
+)
+
+// Event represents a single touch contact event.
+type Event struct {
+	T time.Time
+
+	// The ID of the contact, unique among the current contacts. IDs may be
+	// reused once the contact has ended.
+	ID int
+
+	// The position of the contact, in window pixels (with the origin at the
+	// top-left of the window).
+	X, Y float64
+
+	State State
+}
+
+// Time implements the window.Event interface.
+func (e Event) Time() time.Time {
+	return e.T
+}
+
+// String returns a string representation of this event.
+func (e Event) String() string {
+	return fmt.Sprintf("Event(ID=%v, X=%f, Y=%f, State=%v, Time=%v)", e.ID, e.X, e.Y, e.State, e.T)
+}
@@ -0,0 +1,238 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package touch
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Phase represents the phase of a continuous gesture.
+type Phase uint8
+
+// Gesture phase constants. The InvalidPhase is declared to help users detect
+// uninitialized variables.
+const (
+	InvalidPhase Phase = iota
+	Began
+	Changed
+	Ended
+)
+
+// Gesture is a gesture recognized by a Recognizer. It is one of the Tap, Pan
+// or Pinch types.
+type Gesture interface {
+	Time() time.Time
+}
+
+// Tap is a gesture where a single contact touched and left the screen quickly
+// without moving.
+type Tap struct {
+	T    time.Time
+	X, Y float64
+}
+
+// Time implements the Gesture interface.
+func (g Tap) Time() time.Time {
+	return g.T
+}
+
+// String returns a string representation of this gesture.
+func (g Tap) String() string {
+	return fmt.Sprintf("Tap(X=%f, Y=%f, Time=%v)", g.X, g.Y, g.T)
+}
+
+// Pan is a gesture where a single contact is dragged across the screen.
+type Pan struct {
+	T     time.Time
+	Phase Phase
+
+	// The current position of the contact.
+	X, Y float64
+
+	// The movement since the last Pan gesture.
+	DX, DY float64
+}
+
+// Time implements the Gesture interface.
+func (g Pan) Time() time.Time {
+	return g.T
+}
+
+// String returns a string representation of this gesture.
+func (g Pan) String() string {
+	return fmt.Sprintf("Pan(Phase=%v, X=%f, Y=%f, DX=%f, DY=%f, Time=%v)", g.Phase, g.X, g.Y, g.DX, g.DY, g.T)
+}
+
+// Pinch is a gesture where two contacts move closer together or further apart.
+type Pinch struct {
+	T     time.Time
+	Phase Phase
+
+	// The point in the middle of both contacts.
+	X, Y float64
+
+	// The change in distance between the contacts since the last Pinch
+	// gesture, as a factor (i.e. >1 when moving apart).
+	Scale float64
+}
+
+// Time implements the Gesture interface.
+func (g Pinch) Time() time.Time {
+	return g.T
+}
+
+// String returns a string representation of this gesture.
+func (g Pinch) String() string {
+	return fmt.Sprintf("Pinch(Phase=%v, X=%f, Y=%f, Scale=%f, Time=%v)", g.Phase, g.X, g.Y, g.Scale, g.T)
+}
+
+type contact struct {
+	startX, startY, x, y float64
+	start                time.Time
+}
+
+// Recognizer recognizes gestures from touch events. It is not safe for use
+// concurrently from multiple goroutines.
+type Recognizer struct {
+	// Slop is the distance in pixels a contact may move before it is no longer
+	// considered a tap, and starts a pan or pinch.
+	Slop float64
+
+	// TapTimeout is the longest duration a contact may last and still be
+	// considered a tap.
+	TapTimeout time.Duration
+
+	contacts      map[int]*contact
+	order         []int // IDs of the contacts, in the order they began.
+	multi         bool  // More than one contact since all contacts were up.
+	panning       bool
+	pinching      bool
+	startDistance float64
+	lastDistance  float64
+}
+
+// distance returns the distance between the first two contacts and the point
+// in the middle of them.
+func (r *Recognizer) distance() (d, mx, my float64) {
+	a, b := r.contacts[r.order[0]], r.contacts[r.order[1]]
+	return math.Hypot(b.x-a.x, b.y-a.y), (a.x + b.x) / 2, (a.y + b.y) / 2
+}
+
+// endAll ends any pan or pinch gesture in progress.
+func (r *Recognizer) endAll(t time.Time, gestures []Gesture) []Gesture {
+	if r.panning {
+		r.panning = false
+		c := r.contacts[r.order[0]]
+		gestures = append(gestures, Pan{T: t, Phase: Ended, X: c.x, Y: c.y})
+	}
+	if r.pinching {
+		r.pinching = false
+		_, mx, my := r.distance()
+		gestures = append(gestures, Pinch{T: t, Phase: Ended, X: mx, Y: my, Scale: 1})
+	}
+	return gestures
+}
+
+// Handle handles the given touch event, returning the gestures (if any) that
+// it caused to be recognized.
+func (r *Recognizer) Handle(ev Event) []Gesture {
+	var gestures []Gesture
+	switch ev.State {
+	case Down:
+		if _, ok := r.contacts[ev.ID]; ok {
+			// Duplicate down event, treat it as a move.
+			ev.State = Move
+			return r.Handle(ev)
+		}
+		gestures = r.endAll(ev.T, gestures)
+		r.contacts[ev.ID] = &contact{startX: ev.X, startY: ev.Y, x: ev.X, y: ev.Y, start: ev.T}
+		r.order = append(r.order, ev.ID)
+		if len(r.order) > 1 {
+			r.multi = true
+		}
+		if len(r.order) == 2 {
+			r.startDistance, _, _ = r.distance()
+			r.lastDistance = r.startDistance
+		}
+
+	case Move:
+		c, ok := r.contacts[ev.ID]
+		if !ok {
+			return nil
+		}
+		dx, dy := ev.X-c.x, ev.Y-c.y
+		c.x, c.y = ev.X, ev.Y
+		switch len(r.order) {
+		case 1:
+			if !r.panning && math.Hypot(c.x-c.startX, c.y-c.startY) > r.Slop {
+				r.panning = true
+				gestures = append(gestures, Pan{T: ev.T, Phase: Began, X: c.x, Y: c.y, DX: c.x - c.startX, DY: c.y - c.startY})
+			} else if r.panning {
+				gestures = append(gestures, Pan{T: ev.T, Phase: Changed, X: c.x, Y: c.y, DX: dx, DY: dy})
+			}
+		case 2:
+			d, mx, my := r.distance()
+			if !r.pinching && math.Abs(d-r.startDistance) > r.Slop {
+				r.pinching = true
+				gestures = append(gestures, Pinch{T: ev.T, Phase: Began, X: mx, Y: my, Scale: safeRatio(d, r.startDistance)})
+				r.lastDistance = d
+			} else if r.pinching {
+				gestures = append(gestures, Pinch{T: ev.T, Phase: Changed, X: mx, Y: my, Scale: safeRatio(d, r.lastDistance)})
+				r.lastDistance = d
+			}
+		}
+
+	case Up, Cancelled:
+		c, ok := r.contacts[ev.ID]
+		if !ok {
+			return nil
+		}
+		c.x, c.y = ev.X, ev.Y
+		tap := ev.State == Up && !r.multi && !r.panning &&
+			math.Hypot(c.x-c.startX, c.y-c.startY) <= r.Slop &&
+			ev.T.Sub(c.start) <= r.TapTimeout
+		gestures = r.endAll(ev.T, gestures)
+		if tap {
+			gestures = append(gestures, Tap{T: ev.T, X: c.x, Y: c.y})
+		}
+
+		// Remove the contact.
+		delete(r.contacts, ev.ID)
+		for i, id := range r.order {
+			if id == ev.ID {
+				r.order = append(r.order[:i], r.order[i+1:]...)
+				break
+			}
+		}
+		if len(r.order) == 0 {
+			r.multi = false
+		}
+		if len(r.order) == 2 {
+			r.startDistance, _, _ = r.distance()
+			r.lastDistance = r.startDistance
+		}
+	}
+	return gestures
+}
+
+// safeRatio returns a/b, or 1 if b is zero.
+func safeRatio(a, b float64) float64 {
+	if b == 0 {
+		return 1
+	}
+	return a / b
+}
+
+// NewRecognizer returns a new gesture recognizer with a Slop of 10 pixels and
+// a TapTimeout of 300ms.
+func NewRecognizer() *Recognizer {
+	return &Recognizer{
+		Slop:       10,
+		TapTimeout: 300 * time.Millisecond,
+		contacts:   make(map[int]*contact),
+	}
+}
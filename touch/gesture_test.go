@@ -0,0 +1,70 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package touch
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecognizerTap(t *testing.T) {
+	r := NewRecognizer()
+	now := time.Now()
+	r.Handle(Event{T: now, ID: 1, X: 10, Y: 10, State: Down})
+	r.Handle(Event{T: now, ID: 1, X: 12, Y: 11, State: Move})
+	g := r.Handle(Event{T: now.Add(100 * time.Millisecond), ID: 1, X: 12, Y: 11, State: Up})
+	if len(g) != 1 {
+		t.Fatalf("got %v, want a single tap", g)
+	}
+	if tap, ok := g[0].(Tap); !ok || tap.X != 12 || tap.Y != 11 {
+		t.Fatalf("got %v, want Tap at 12, 11", g[0])
+	}
+
+	// Too slow to be a tap.
+	r.Handle(Event{T: now, ID: 1, State: Down})
+	if g := r.Handle(Event{T: now.Add(time.Second), ID: 1, State: Up}); len(g) != 0 {
+		t.Fatalf("got %v, want no gestures", g)
+	}
+}
+
+func TestRecognizerPan(t *testing.T) {
+	r := NewRecognizer()
+	now := time.Now()
+	r.Handle(Event{T: now, ID: 1, X: 0, Y: 0, State: Down})
+	g := r.Handle(Event{T: now, ID: 1, X: 20, Y: 0, State: Move})
+	if len(g) != 1 || g[0].(Pan).Phase != Began || g[0].(Pan).DX != 20 {
+		t.Fatalf("got %v, want Pan Began with DX=20", g)
+	}
+	g = r.Handle(Event{T: now, ID: 1, X: 25, Y: 5, State: Move})
+	if p := g[0].(Pan); p.Phase != Changed || p.DX != 5 || p.DY != 5 {
+		t.Fatalf("got %v, want Pan Changed with DX=5, DY=5", p)
+	}
+	g = r.Handle(Event{T: now, ID: 1, X: 25, Y: 5, State: Up})
+	if len(g) != 1 || g[0].(Pan).Phase != Ended {
+		t.Fatalf("got %v, want Pan Ended and no tap", g)
+	}
+}
+
+func TestRecognizerPinch(t *testing.T) {
+	r := NewRecognizer()
+	now := time.Now()
+	r.Handle(Event{T: now, ID: 1, X: 0, Y: 0, State: Down})
+	r.Handle(Event{T: now, ID: 2, X: 100, Y: 0, State: Down})
+	g := r.Handle(Event{T: now, ID: 2, X: 200, Y: 0, State: Move})
+	if p := g[0].(Pinch); p.Phase != Began || p.Scale != 2 || p.X != 100 {
+		t.Fatalf("got %v, want Pinch Began with Scale=2, X=100", p)
+	}
+	g = r.Handle(Event{T: now, ID: 1, X: 100, Y: 0, State: Move})
+	if p := g[0].(Pinch); p.Phase != Changed || p.Scale != 0.5 {
+		t.Fatalf("got %v, want Pinch Changed with Scale=0.5", p)
+	}
+	g = r.Handle(Event{T: now, ID: 1, State: Up})
+	if len(g) != 1 || g[0].(Pinch).Phase != Ended {
+		t.Fatalf("got %v, want Pinch Ended and no tap", g)
+	}
+	if g := r.Handle(Event{T: now, ID: 2, State: Up}); len(g) != 0 {
+		t.Fatalf("got %v, want no gestures", g)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tween
+
+// Coroutine is a function which is resumed once each frame with the time
+// elapsed since the previous frame, in seconds. It returns true while it is
+// still running, and false once it has finished (after which it is not
+// resumed again).
+type Coroutine func(dt float64) bool
+
+// Wait returns a coroutine which waits for the given number of seconds.
+func Wait(seconds float64) Coroutine {
+	var elapsed float64
+	return func(dt float64) bool {
+		elapsed += dt
+		return elapsed < seconds
+	}
+}
+
+// WaitUntil returns a coroutine which waits until cond returns true. The
+// condition is checked each time the coroutine is resumed.
+func WaitUntil(cond func() bool) Coroutine {
+	return func(dt float64) bool {
+		return !cond()
+	}
+}
+
+// Call returns a coroutine which calls fn once and then finishes immediately.
+func Call(fn func()) Coroutine {
+	return func(dt float64) bool {
+		fn()
+		return false
+	}
+}
+
+// Sequence returns a coroutine which runs the given coroutines one after
+// another. When one finishes the next is resumed within the same frame (with
+// a dt of zero), such that e.g. calls do not delay those which follow them.
+func Sequence(cs ...Coroutine) Coroutine {
+	i := 0
+	return func(dt float64) bool {
+		for i < len(cs) {
+			if cs[i](dt) {
+				return true
+			}
+			i++
+			dt = 0
+		}
+		return false
+	}
+}
+
+// Parallel returns a coroutine which runs all of the given coroutines each
+// frame, finishing once all of them have.
+func Parallel(cs ...Coroutine) Coroutine {
+	running := append([]Coroutine(nil), cs...)
+	return func(dt float64) bool {
+		n := 0
+		for _, c := range running {
+			if c(dt) {
+				running[n] = c
+				n++
+			}
+		}
+		running = running[:n]
+		return n > 0
+	}
+}
+
+// Race returns a coroutine which runs all of the given coroutines each frame,
+// finishing as soon as any one of them has.
+func Race(cs ...Coroutine) Coroutine {
+	return func(dt float64) bool {
+		for _, c := range cs {
+			if !c(dt) {
+				return false
+			}
+		}
+		return len(cs) > 0
+	}
+}
+
+// Repeat returns a coroutine which runs the coroutines returned by fn one
+// after another, the given number of times (or forever, if times < 0).
+func Repeat(times int, fn func() Coroutine) Coroutine {
+	var cur Coroutine
+	return func(dt float64) bool {
+		for times != 0 {
+			if cur == nil {
+				cur = fn()
+			}
+			if cur(dt) {
+				return true
+			}
+			cur = nil
+			if times > 0 {
+				times--
+			}
+			dt = 0
+		}
+		return false
+	}
+}
@@ -0,0 +1,31 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package tween implements coroutines and tweens for gameplay scripting.
+//
+// A Coroutine is a function which is resumed once each frame with the time
+// elapsed since the last one, until it reports that it has finished. A
+// Scheduler runs many coroutines, and they compose (see Sequence and
+// Parallel) to express timed behaviour without hand written state machines:
+//
+//  sched := tween.NewScheduler()
+//  sched.Start(tween.Sequence(
+//      tween.MoveTo(door.Transform, openPos, 1.5, curve.InOutCubic),
+//      tween.Wait(3),
+//      tween.Call(func() { playSound("creak") }),
+//      tween.Parallel(
+//          tween.MoveTo(door.Transform, closedPos, 1.5, curve.InOutCubic),
+//          tween.FadeTo(&light.Color, 0, 1.5, curve.Linear),
+//      ),
+//  ))
+//  for {
+//      sched.Update(dt)
+//      ...
+//  }
+//
+// Tweens interpolate a value to a target over a duration, using an easing
+// function of the lmath/curve package. The value the tween starts from is
+// read when it is first resumed, so tweens may be sequenced one after another
+// on the same value.
+package tween // import "azul3d.org/engine/tween"
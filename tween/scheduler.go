@@ -0,0 +1,116 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tween
+
+// Handle is a handle to a coroutine started by a scheduler.
+type Handle struct {
+	c       Coroutine
+	done    bool
+	stopped bool
+	paused  bool
+}
+
+// Done tells if the coroutine has finished or was stopped.
+func (h *Handle) Done() bool {
+	return h.done || h.stopped
+}
+
+// Stop stops the coroutine, it will not be resumed again.
+func (h *Handle) Stop() {
+	h.stopped = true
+}
+
+// Pause pauses or unpauses the coroutine. Paused coroutines are not resumed
+// (and hence time does not pass for them).
+func (h *Handle) Pause(paused bool) {
+	h.paused = paused
+}
+
+// Paused tells if the coroutine is paused.
+func (h *Handle) Paused() bool {
+	return h.paused
+}
+
+// Scheduler runs coroutines, resuming each of them once per call to Update.
+//
+// A scheduler and it's coroutines are not safe for access from multiple
+// goroutines concurrently: they are intended to be updated from the game
+// loop.
+type Scheduler struct {
+	// Scale scales the time passed to coroutines, e.g. zero to pause all of
+	// them or 0.5 for slow motion.
+	Scale float64
+
+	running []*Handle
+	started []*Handle
+}
+
+// Start starts the given coroutine, which is first resumed by the next call
+// to Update (coroutines started during an Update are first resumed by the
+// one that follows it).
+func (s *Scheduler) Start(c Coroutine) *Handle {
+	h := &Handle{c: c}
+	s.started = append(s.started, h)
+	return h
+}
+
+// Len returns the number of coroutines which have not yet finished.
+func (s *Scheduler) Len() int {
+	n := 0
+	for _, h := range s.running {
+		if !h.Done() {
+			n++
+		}
+	}
+	for _, h := range s.started {
+		if !h.Done() {
+			n++
+		}
+	}
+	return n
+}
+
+// Update resumes each running coroutine, in the order they were started,
+// with the given time (scaled by s.Scale) in seconds. Coroutines which finish
+// are removed.
+func (s *Scheduler) Update(dt float64) {
+	dt *= s.Scale
+	s.running = append(s.running, s.started...)
+	s.started = s.started[:0]
+
+	n := 0
+	for _, h := range s.running {
+		if h.Done() {
+			continue
+		}
+		if !h.paused && !h.c(dt) {
+			h.done = true
+			continue
+		}
+		s.running[n] = h
+		n++
+	}
+	for i := n; i < len(s.running); i++ {
+		s.running[i] = nil
+	}
+	s.running = s.running[:n]
+}
+
+// StopAll stops all of the coroutines of the scheduler.
+func (s *Scheduler) StopAll() {
+	for _, h := range s.running {
+		h.Stop()
+	}
+	for _, h := range s.started {
+		h.Stop()
+	}
+	s.running = nil
+	s.started = nil
+}
+
+// NewScheduler returns a new scheduler with a time scale of one.
+func NewScheduler() *Scheduler {
+	return &Scheduler{Scale: 1}
+}
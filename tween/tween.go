@@ -0,0 +1,137 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tween
+
+import (
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/lmath"
+	"azul3d.org/engine/lmath/curve"
+)
+
+// Tween returns a coroutine which calls fn each frame with the eased progress
+// (see the curve package) of the given duration in seconds, ending with a
+// call of fn(1). The first call to fn is made with the progress after the
+// first frame. A nil ease is linear.
+func Tween(duration float64, ease curve.Func, fn func(t float64)) Coroutine {
+	if ease == nil {
+		ease = curve.Linear
+	}
+	var elapsed float64
+	return func(dt float64) bool {
+		elapsed += dt
+		if duration <= 0 || elapsed >= duration {
+			fn(1)
+			return false
+		}
+		fn(ease(elapsed / duration))
+		return true
+	}
+}
+
+// lazy returns a tween whose start function is called when it is first
+// resumed, and returns the function called with it's progress.
+func lazy(duration float64, ease curve.Func, start func() func(t float64)) Coroutine {
+	var fn func(t float64)
+	tw := Tween(duration, ease, func(t float64) {
+		fn(t)
+	})
+	return func(dt float64) bool {
+		if fn == nil {
+			fn = start()
+		}
+		return tw(dt)
+	}
+}
+
+// Float returns a tween which interpolates *v to the given value.
+func Float(v *float64, to, duration float64, ease curve.Func) Coroutine {
+	return lazy(duration, ease, func() func(t float64) {
+		from := *v
+		return func(t float64) {
+			*v = lmath.Lerp(from, to, t)
+		}
+	})
+}
+
+// Vec3 returns a tween which interpolates *v to the given vector.
+func Vec3(v *lmath.Vec3, to lmath.Vec3, duration float64, ease curve.Func) Coroutine {
+	return lazy(duration, ease, func() func(t float64) {
+		from := *v
+		return func(t float64) {
+			*v = from.Lerp(to, t)
+		}
+	})
+}
+
+// Color returns a tween which interpolates each component of *c to the given
+// color.
+func Color(c *gfx.Color, to gfx.Color, duration float64, ease curve.Func) Coroutine {
+	return lazy(duration, ease, func() func(t float64) {
+		from := *c
+		return func(t float64) {
+			lerp := func(a, b float32) float32 {
+				return float32(lmath.Lerp(float64(a), float64(b), t))
+			}
+			*c = gfx.Color{
+				R: lerp(from.R, to.R),
+				G: lerp(from.G, to.G),
+				B: lerp(from.B, to.B),
+				A: lerp(from.A, to.A),
+			}
+		}
+	})
+}
+
+// FadeTo returns a tween which interpolates the alpha of *c to the given
+// value, leaving it's other components untouched.
+func FadeTo(c *gfx.Color, alpha float32, duration float64, ease curve.Func) Coroutine {
+	return lazy(duration, ease, func() func(t float64) {
+		from := c.A
+		return func(t float64) {
+			c.A = float32(lmath.Lerp(float64(from), float64(alpha), t))
+		}
+	})
+}
+
+// MoveTo returns a tween which moves the transform to the given position.
+func MoveTo(tr *gfx.Transform, pos lmath.Vec3, duration float64, ease curve.Func) Coroutine {
+	return lazy(duration, ease, func() func(t float64) {
+		from := tr.Pos()
+		return func(t float64) {
+			tr.SetPos(from.Lerp(pos, t))
+		}
+	})
+}
+
+// MoveBy returns a tween which moves the transform by the given offset.
+func MoveBy(tr *gfx.Transform, offset lmath.Vec3, duration float64, ease curve.Func) Coroutine {
+	return lazy(duration, ease, func() func(t float64) {
+		from := tr.Pos()
+		return func(t float64) {
+			tr.SetPos(from.Add(offset.MulScalar(t)))
+		}
+	})
+}
+
+// ScaleTo returns a tween which scales the transform to the given scale.
+func ScaleTo(tr *gfx.Transform, scale lmath.Vec3, duration float64, ease curve.Func) Coroutine {
+	return lazy(duration, ease, func() func(t float64) {
+		from := tr.Scale()
+		return func(t float64) {
+			tr.SetScale(from.Lerp(scale, t))
+		}
+	})
+}
+
+// RotateTo returns a tween which rotates the transform to the given
+// orientation, using spherical linear interpolation.
+func RotateTo(tr *gfx.Transform, q lmath.Quat, duration float64, ease curve.Func) Coroutine {
+	return lazy(duration, ease, func() func(t float64) {
+		from := tr.Quat()
+		return func(t float64) {
+			tr.SetQuat(from.Slerp(q, t))
+		}
+	})
+}
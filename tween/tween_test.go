@@ -0,0 +1,84 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tween
+
+import (
+	"reflect"
+	"testing"
+
+	"azul3d.org/engine/gfx"
+	"azul3d.org/engine/lmath"
+	"azul3d.org/engine/lmath/curve"
+)
+
+func TestScheduler(t *testing.T) {
+	s := NewScheduler()
+	var log []string
+	say := func(msg string) Coroutine {
+		return Call(func() { log = append(log, msg) })
+	}
+	h := s.Start(Sequence(say("a"), Wait(1), say("b"), Parallel(Wait(0.5), Wait(1)), say("c")))
+	stopped := s.Start(Sequence(Wait(0.25), say("never")))
+	if s.Len() != 2 {
+		t.Fatalf("%d coroutines", s.Len())
+	}
+	s.Update(0.1)
+	stopped.Stop()
+	if !reflect.DeepEqual(log, []string{"a"}) {
+		t.Fatalf("log %v", log)
+	}
+	for i := 0; i < 10; i++ {
+		s.Update(0.25)
+	}
+	if !reflect.DeepEqual(log, []string{"a", "b", "c"}) || !h.Done() || s.Len() != 0 {
+		t.Fatalf("log %v", log)
+	}
+
+	// Paused coroutines and time scale.
+	var n int
+	h = s.Start(Repeat(-1, func() Coroutine {
+		return Sequence(Wait(1), Call(func() { n++ }))
+	}))
+	s.Update(1)
+	h.Pause(true)
+	s.Update(1)
+	h.Pause(false)
+	s.Scale = 2
+	s.Update(1)
+	if n != 2 {
+		t.Fatalf("repeated %d times, want 2", n)
+	}
+	s.StopAll()
+	if !h.Done() || s.Len() != 0 {
+		t.Fatal("not stopped")
+	}
+}
+
+func TestTween(t *testing.T) {
+	s := NewScheduler()
+	tr := gfx.NewTransform()
+	col := gfx.Color{R: 1, G: 1, B: 1, A: 1}
+	var f float64
+	s.Start(Sequence(
+		MoveTo(tr, lmath.Vec3{X: 10, Y: 0, Z: 0}, 1, curve.Linear),
+		MoveBy(tr, lmath.Vec3{X: 0, Y: 4, Z: 0}, 1, nil),
+	))
+	s.Start(FadeTo(&col, 0, 2, curve.Linear))
+	s.Start(Float(&f, 8, 1, curve.InQuad))
+
+	s.Update(0.5)
+	if p := tr.Pos(); p != (lmath.Vec3{X: 5, Y: 0, Z: 0}) || col.A != 0.75 || f != 2 {
+		t.Fatalf("pos %v alpha %v f %v", p, col.A, f)
+	}
+	s.Update(0.5)
+	s.Update(0.5)
+	if p := tr.Pos(); p != (lmath.Vec3{X: 10, Y: 2, Z: 0}) || col.A != 0.25 || f != 8 {
+		t.Fatalf("pos %v alpha %v f %v", p, col.A, f)
+	}
+	s.Update(5)
+	if p := tr.Pos(); p != (lmath.Vec3{X: 10, Y: 4, Z: 0}) || col != (gfx.Color{R: 1, G: 1, B: 1, A: 0}) || s.Len() != 0 {
+		t.Fatalf("pos %v color %v", p, col)
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Dir is a Source which reads the loose files of a directory of the OS file
+// system.
+type Dir string
+
+func (d Dir) path(name string) string {
+	return filepath.Join(string(d), filepath.FromSlash(clean(name)))
+}
+
+// Open implements the Source interface.
+func (d Dir) Open(name string) (io.ReadCloser, error) {
+	return os.Open(d.path(name))
+}
+
+// Stat implements the Source interface.
+func (d Dir) Stat(name string) (os.FileInfo, error) {
+	return os.Stat(d.path(name))
+}
+
+// ReadDir implements the Source interface.
+func (d Dir) ReadDir(name string) ([]os.FileInfo, error) {
+	return ioutil.ReadDir(d.path(name))
+}
+
+// dirInfo is the os.FileInfo of a directory which has no information of it's
+// own, e.g. a mount point or a directory implied by the files of a Zip.
+type dirInfo string
+
+func (d dirInfo) Name() string       { return string(d) }
+func (d dirInfo) Size() int64        { return 0 }
+func (d dirInfo) Mode() os.FileMode  { return os.ModeDir | 0555 }
+func (d dirInfo) ModTime() time.Time { return time.Time{} }
+func (d dirInfo) IsDir() bool        { return true }
+func (d dirInfo) Sys() interface{}   { return nil }
@@ -0,0 +1,27 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package vfs implements a virtual file system of mounted sources.
+//
+// Sources -- directories of the OS file system, zip archives, or (with Go
+// 1.16 or later) any fs.FS such as files embedded with go:embed -- are
+// mounted into a FS at a mount point and with a priority. Paths are resolved
+// against the sources with the highest priority first, such that a shipped
+// game may read it's assets from a packed archive while loose files override
+// them during development:
+//
+//  data, err := vfs.OpenZip("data.zip")
+//  if err != nil {
+//      // Handle error.
+//  }
+//  defer data.Close()
+//
+//  fs := vfs.New()
+//  fs.Mount("/", data, 0)
+//  fs.Mount("/", vfs.Dir("data"), 1) // Loose files, if any.
+//  fs.Mount("/mods", vfs.Dir("mods"), 0)
+//
+//  assets := asset.NewManager(fs)
+//
+package vfs // import "azul3d.org/engine/vfs"
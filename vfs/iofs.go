@@ -0,0 +1,59 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build go1.16
+
+package vfs
+
+import (
+	"io"
+	"io/fs"
+	"os"
+)
+
+// ioFS is a Source which reads the files of an fs.FS.
+type ioFS struct {
+	fsys fs.FS
+}
+
+// Open implements the Source interface.
+func (f *ioFS) Open(name string) (io.ReadCloser, error) {
+	return f.fsys.Open(clean(name))
+}
+
+// Stat implements the Source interface.
+func (f *ioFS) Stat(name string) (os.FileInfo, error) {
+	return fs.Stat(f.fsys, clean(name))
+}
+
+// ReadDir implements the Source interface.
+func (f *ioFS) ReadDir(name string) ([]os.FileInfo, error) {
+	entries, err := fs.ReadDir(f.fsys, clean(name))
+	if err != nil {
+		return nil, err
+	}
+	list := make([]os.FileInfo, 0, len(entries))
+	for _, e := range entries {
+		fi, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, fi)
+	}
+	return list, nil
+}
+
+// IOFS returns a Source which reads the files of the given fs.FS, such as the
+// files embedded into the program with a go:embed directive:
+//
+//  //go:embed data
+//  var data embed.FS
+//  ...
+//  sub, _ := fs.Sub(data, "data")
+//  v := vfs.New()
+//  v.Mount("/", vfs.IOFS(sub), 0)
+//
+func IOFS(fsys fs.FS) Source {
+	return &ioFS{fsys: fsys}
+}
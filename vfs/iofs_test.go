@@ -0,0 +1,40 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build go1.16
+
+package vfs
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestIOFS(t *testing.T) {
+	embedded := IOFS(fstest.MapFS{
+		"a.txt":       {Data: []byte("embedded")},
+		"sub/b.txt":   {Data: []byte("b")},
+		"sub/c/d.txt": {Data: []byte("d")},
+	})
+	fs := New()
+	fs.Mount("/", embedded, 0)
+	fs.Mount("/", newZip(t, map[string]string{"a.txt": "packed"}), 1)
+
+	data, err := fs.ReadFile("sub/b.txt")
+	if err != nil || string(data) != "b" {
+		t.Fatalf("ReadFile = %q, %v", data, err)
+	}
+	data, err = fs.ReadFile("a.txt")
+	if err != nil || string(data) != "packed" {
+		t.Fatalf("ReadFile = %q, %v", data, err)
+	}
+	infos, err := fs.ReadDir("/sub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := names(infos); !reflect.DeepEqual(n, []string{"b.txt", "c"}) {
+		t.Fatalf("ReadDir = %v", n)
+	}
+}
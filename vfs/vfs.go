@@ -0,0 +1,226 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Source is a source of files which can be mounted into a FS (e.g. a Dir or
+// a Zip archive). Names are slash-separated paths relative to the root of
+// the source, without a leading slash; the root itself is named ".".
+type Source interface {
+	// Open opens the named file for reading.
+	Open(name string) (io.ReadCloser, error)
+
+	// Stat returns information about the named file or directory.
+	Stat(name string) (os.FileInfo, error)
+
+	// ReadDir returns the entries of the named directory.
+	ReadDir(name string) ([]os.FileInfo, error)
+}
+
+// mount is a source mounted into a FS.
+type mount struct {
+	point    string
+	src      Source
+	priority int
+}
+
+// rel returns the name of the file relative to the mount's source, and
+// whether the file is below the mount point at all.
+func (m *mount) rel(name string) (string, bool) {
+	if m.point == "." {
+		return name, true
+	}
+	if name == m.point {
+		return ".", true
+	}
+	if strings.HasPrefix(name, m.point+"/") {
+		return name[len(m.point)+1:], true
+	}
+	return "", false
+}
+
+// FS is a virtual file system, which resolves paths across the sources
+// mounted into it. Sources may overlap: when several of them hold a file,
+// the one with the highest priority wins -- and of those with the same
+// priority, the one mounted last. This way, a shipped game can read it's
+// files from a packed archive, while a development build mounts the loose
+// files over it:
+//
+//  fs := vfs.New()
+//  fs.Mount("/", data, 0) // e.g. a Zip.
+//  if dev {
+//      fs.Mount("/", vfs.Dir("assets"), 1)
+//  }
+//
+// FS implements the asset.FileSystem interface, such that assets may be
+// loaded through it.
+//
+// A FS is safe for use from multiple goroutines concurrently.
+type FS struct {
+	access sync.RWMutex
+	mounts []*mount
+}
+
+// clean returns the clean form of the given slash-separated path, without a
+// leading slash ("." for the root).
+func clean(name string) string {
+	name = path.Clean("/" + name)
+	if name == "/" {
+		return "."
+	}
+	return name[1:]
+}
+
+// Mount mounts the source at the given mount point (a directory path, e.g.
+// "/" or "textures"), with the given priority.
+func (fs *FS) Mount(point string, src Source, priority int) {
+	m := &mount{point: clean(point), src: src, priority: priority}
+	fs.access.Lock()
+	defer fs.access.Unlock()
+
+	// Keep the mounts in the order they are searched in.
+	i := sort.Search(len(fs.mounts), func(i int) bool {
+		return fs.mounts[i].priority <= priority
+	})
+	fs.mounts = append(fs.mounts, nil)
+	copy(fs.mounts[i+1:], fs.mounts[i:])
+	fs.mounts[i] = m
+}
+
+// Unmount unmounts the source from every mount point it is mounted at.
+func (fs *FS) Unmount(src Source) {
+	fs.access.Lock()
+	defer fs.access.Unlock()
+	mounts := fs.mounts[:0]
+	for _, m := range fs.mounts {
+		if m.src != src {
+			mounts = append(mounts, m)
+		}
+	}
+	for i := len(mounts); i < len(fs.mounts); i++ {
+		fs.mounts[i] = nil
+	}
+	fs.mounts = mounts
+}
+
+// snapshot returns the mounts, in the order they are searched in.
+func (fs *FS) snapshot() []*mount {
+	fs.access.RLock()
+	defer fs.access.RUnlock()
+	return append([]*mount(nil), fs.mounts...)
+}
+
+// Resolve returns the source which the named file is opened from, and the
+// name of the file within that source.
+func (fs *FS) Resolve(name string) (src Source, rel string, err error) {
+	name = clean(name)
+	for _, m := range fs.snapshot() {
+		r, ok := m.rel(name)
+		if !ok {
+			continue
+		}
+		if _, err := m.src.Stat(r); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, "", err
+		}
+		return m.src, r, nil
+	}
+	return nil, "", &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+}
+
+// Open opens the named file from the source with the highest priority that
+// holds it.
+func (fs *FS) Open(name string) (io.ReadCloser, error) {
+	src, rel, err := fs.Resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return src.Open(rel)
+}
+
+// Stat returns information about the named file or directory, from the
+// source with the highest priority that holds it.
+func (fs *FS) Stat(name string) (os.FileInfo, error) {
+	src, rel, err := fs.Resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return src.Stat(rel)
+}
+
+// ReadFile reads the whole named file.
+func (fs *FS) ReadFile(name string) ([]byte, error) {
+	f, err := fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
+
+// ReadDir returns the entries of the named directory across every source
+// that holds it, sorted by name. Where several sources hold an entry of the
+// same name, the one which would be opened is returned. Mount points below
+// the directory are included as directories.
+func (fs *FS) ReadDir(name string) ([]os.FileInfo, error) {
+	name = clean(name)
+	entries := make(map[string]os.FileInfo)
+	found := false
+	for _, m := range fs.snapshot() {
+		r, ok := m.rel(name)
+		if !ok {
+			// A mount point below the directory is an entry of it.
+			if name == "." || strings.HasPrefix(m.point, name+"/") {
+				child := strings.TrimPrefix(m.point, name+"/")
+				child = strings.SplitN(child, "/", 2)[0]
+				if _, ok := entries[child]; !ok {
+					entries[child] = dirInfo(child)
+				}
+				found = true
+			}
+			continue
+		}
+		infos, err := m.src.ReadDir(r)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		found = true
+		for _, fi := range infos {
+			if _, ok := entries[fi.Name()]; !ok {
+				entries[fi.Name()] = fi
+			}
+		}
+	}
+	if !found {
+		return nil, &os.PathError{Op: "readdir", Path: name, Err: os.ErrNotExist}
+	}
+	list := make([]os.FileInfo, 0, len(entries))
+	for _, fi := range entries {
+		list = append(list, fi)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name() < list[j].Name()
+	})
+	return list, nil
+}
+
+// New returns a new virtual file system, with nothing mounted.
+func New() *FS {
+	return &FS{}
+}
@@ -0,0 +1,190 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"azul3d.org/engine/asset"
+)
+
+var _ asset.FileSystem = New()
+
+// newZip returns a zip archive of the given files, by name.
+func newZip(t *testing.T, files map[string]string) *Zip {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, data := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	z, err := NewZip(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return z
+}
+
+// names returns the names of the file infos.
+func names(infos []os.FileInfo) []string {
+	var n []string
+	for _, fi := range infos {
+		n = append(n, fi.Name())
+	}
+	return n
+}
+
+func TestZip(t *testing.T) {
+	z := newZip(t, map[string]string{
+		"a.txt":          "a",
+		"textures/b.png": "b",
+		"empty/":         "",
+	})
+	fi, err := z.Stat("/textures/b.png")
+	if err != nil || fi.IsDir() || fi.Size() != 1 {
+		t.Fatalf("Stat = %v, %v", fi, err)
+	}
+	if fi, err := z.Stat("textures"); err != nil || !fi.IsDir() {
+		t.Fatalf("Stat of implied directory = %v, %v", fi, err)
+	}
+	infos, err := z.ReadDir(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := names(infos); !reflect.DeepEqual(n, []string{"a.txt", "empty", "textures"}) {
+		t.Fatalf("ReadDir = %v", n)
+	}
+	if _, err := z.Open("missing.txt"); !os.IsNotExist(err) {
+		t.Fatalf("Open of missing file: %v", err)
+	}
+	if _, err := z.Open("textures"); err == nil {
+		t.Fatal("opened a directory")
+	}
+}
+
+func TestFS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "a.txt"), []byte("loose"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	packed := newZip(t, map[string]string{
+		"a.txt":          "packed",
+		"textures/b.png": "b",
+	})
+	mod := newZip(t, map[string]string{"c.txt": "mod"})
+
+	fs := New()
+	fs.Mount("/", packed, 0)
+	read := func(name string) string {
+		data, err := fs.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	if s := read("a.txt"); s != "packed" {
+		t.Fatalf("read %q", s)
+	}
+
+	// Loose files of a higher priority override the archive.
+	loose := Dir(dir)
+	fs.Mount("/", loose, 1)
+	if s := read("/a.txt"); s != "loose" {
+		t.Fatalf("read %q, want the loose file", s)
+	}
+	if s := read("textures/../textures/b.png"); s != "b" {
+		t.Fatalf("read %q", s)
+	}
+
+	// Of the same priority, the last mounted wins.
+	fs.Mount("/", newZip(t, map[string]string{"a.txt": "patch"}), 1)
+	if s := read("a.txt"); s != "patch" {
+		t.Fatalf("read %q, want the last mounted", s)
+	}
+
+	// Mount points.
+	fs.Mount("mods/one", mod, 0)
+	if s := read("mods/one/c.txt"); s != "mod" {
+		t.Fatalf("read %q", s)
+	}
+	if _, err := fs.Open("c.txt"); !os.IsNotExist(err) {
+		t.Fatalf("Open outside of the mount point: %v", err)
+	}
+	infos, err := fs.ReadDir("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := names(infos); !reflect.DeepEqual(n, []string{"a.txt", "mods", "textures"}) {
+		t.Fatalf("ReadDir = %v", n)
+	}
+	if fi, err := fs.Stat("mods/one"); err != nil || !fi.IsDir() {
+		t.Fatalf("Stat of mount point = %v, %v", fi, err)
+	}
+
+	src, rel, err := fs.Resolve("mods/one/c.txt")
+	if err != nil || src != mod || rel != "c.txt" {
+		t.Fatalf("Resolve = %v, %q, %v", src, rel, err)
+	}
+
+	fs.Unmount(mod)
+	if _, err := fs.Open("mods/one/c.txt"); !os.IsNotExist(err) {
+		t.Fatalf("Open after Unmount: %v", err)
+	}
+	if _, err := fs.ReadDir("mods"); !os.IsNotExist(err) {
+		t.Fatalf("ReadDir after Unmount: %v", err)
+	}
+}
+
+func TestOpenZip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	p := filepath.Join(dir, "data.zip")
+	f, err := os.Create(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	zf, err := w.Create("sub/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	zf.Write([]byte("hello"))
+	w.Close()
+	f.Close()
+
+	z, err := OpenZip(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer z.Close()
+	fs := New()
+	fs.Mount("/", z, 0)
+	data, err := fs.ReadFile("sub/a.txt")
+	if err != nil || string(data) != "hello" {
+		t.Fatalf("ReadFile = %q, %v", data, err)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path"
+	"sort"
+)
+
+// Zip is a Source which reads the files of a zip archive.
+type Zip struct {
+	closer io.Closer
+	files  map[string]*zip.File
+	dirs   map[string]map[string]os.FileInfo
+}
+
+// Open implements the Source interface.
+func (z *Zip) Open(name string) (io.ReadCloser, error) {
+	name = clean(name)
+	f, ok := z.files[name]
+	if !ok {
+		if _, ok := z.dirs[name]; ok {
+			return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrInvalid}
+		}
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	return f.Open()
+}
+
+// Stat implements the Source interface.
+func (z *Zip) Stat(name string) (os.FileInfo, error) {
+	name = clean(name)
+	if f, ok := z.files[name]; ok {
+		return f.FileInfo(), nil
+	}
+	if _, ok := z.dirs[name]; ok {
+		return dirInfo(path.Base(name)), nil
+	}
+	return nil, &os.PathError{Op: "stat", Path: name, Err: os.ErrNotExist}
+}
+
+// ReadDir implements the Source interface.
+func (z *Zip) ReadDir(name string) ([]os.FileInfo, error) {
+	name = clean(name)
+	entries, ok := z.dirs[name]
+	if !ok {
+		return nil, &os.PathError{Op: "readdir", Path: name, Err: os.ErrNotExist}
+	}
+	list := make([]os.FileInfo, 0, len(entries))
+	for _, fi := range entries {
+		list = append(list, fi)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name() < list[j].Name()
+	})
+	return list, nil
+}
+
+// Close closes the archive file, if it was opened by OpenZip.
+func (z *Zip) Close() error {
+	if z.closer == nil {
+		return nil
+	}
+	return z.closer.Close()
+}
+
+// addDir adds the named directory, and each of it's parents, to the index.
+func (z *Zip) addDir(name string) {
+	if _, ok := z.dirs[name]; ok {
+		return
+	}
+	z.dirs[name] = make(map[string]os.FileInfo)
+	if name == "." {
+		return
+	}
+	parent := path.Dir(name)
+	z.addDir(parent)
+	z.dirs[parent][path.Base(name)] = dirInfo(path.Base(name))
+}
+
+// NewZip returns a Source which reads the zip archive of the given size from
+// r.
+func NewZip(r io.ReaderAt, size int64) (*Zip, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	z := &Zip{
+		files: make(map[string]*zip.File, len(zr.File)),
+		dirs:  make(map[string]map[string]os.FileInfo),
+	}
+	z.addDir(".")
+	for _, f := range zr.File {
+		name := clean(f.Name)
+		if f.FileInfo().IsDir() {
+			z.addDir(name)
+			continue
+		}
+		dir := path.Dir(name)
+		z.addDir(dir)
+		z.files[name] = f
+		z.dirs[dir][path.Base(name)] = f.FileInfo()
+	}
+	return z, nil
+}
+
+// OpenZip opens the named zip archive of the OS file system. The returned
+// Zip should be closed once it is no longer mounted.
+func OpenZip(name string) (*Zip, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	z, err := NewZip(f, fi.Size())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	z.closer = f
+	return z, nil
+}